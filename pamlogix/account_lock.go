@@ -0,0 +1,106 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+var (
+	ErrAccountUnderReview    = runtime.NewError("account under review", PERMISSION_DENIED_ERROR_CODE)                             // PERMISSION_DENIED
+	ErrAccountNotLocked      = runtime.NewError("account not locked", FAILED_PRECONDITION_ERROR_CODE)                             // FAILED_PRECONDITION
+	ErrAccountLockServerOnly = runtime.NewError("account locks are only available to server calls", PERMISSION_DENIED_ERROR_CODE) // PERMISSION_DENIED
+)
+
+const (
+	accountLockStorageCollection = "account_locks"
+	accountLockStorageKey        = "lock"
+)
+
+// AccountLock records why a user's economy was locked while their account is reviewed, e.g. after anti-cheat flagged
+// them. A locked user keeps their state but can't change it through any economy RPC until they are unlocked, or their
+// account is purged.
+type AccountLock struct {
+	UserId      string `json:"user_id"`
+	Reason      string `json:"reason,omitempty"`
+	Source      string `json:"source,omitempty"`
+	LockTimeSec int64  `json:"lock_time_sec"`
+}
+
+// AccountLockRequest is the request payload to lock a user's economy pending review.
+type AccountLockRequest struct {
+	UserId string `json:"user_id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AccountUnlockRequest is the request payload to lift a user's economy lock, or to purge their locked account.
+type AccountUnlockRequest struct {
+	UserId string `json:"user_id"`
+}
+
+// readAccountLock returns the user's economy lock, or nil if they aren't locked.
+func readAccountLock(ctx context.Context, nk runtime.NakamaModule, userID string) (*AccountLock, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: accountLockStorageCollection, Key: accountLockStorageKey, UserID: userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		if object.UserId != userID {
+			continue
+		}
+		lock := &AccountLock{}
+		if err := json.Unmarshal([]byte(object.Value), lock); err != nil {
+			return nil, err
+		}
+		return lock, nil
+	}
+	return nil, nil
+}
+
+// writeAccountLock stores the lock under the user so it goes with their account if it is purged. Clients can neither
+// read nor remove it.
+func writeAccountLock(ctx context.Context, nk runtime.NakamaModule, lock *AccountLock) error {
+	value, err := json.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      accountLockStorageCollection,
+			Key:             accountLockStorageKey,
+			UserID:          lock.UserId,
+			Value:           string(value),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+	return err
+}
+
+// registerAccountLockedRpc registers an RPC which grants, spends, bids or claims, so users whose economy is locked
+// can't call it. Every RPC which changes a user's economy is registered through it.
+func registerAccountLockedRpc(initializer runtime.Initializer, id string, fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) error {
+	return initializer.RegisterRpc(id, withAccountLockRpc(fn))
+}
+
+func withAccountLockRpc(fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		userID, _ := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if userID == "" {
+			return fn(ctx, logger, db, nk, payload)
+		}
+		lock, err := readAccountLock(ctx, nk, userID)
+		if err != nil {
+			logger.Error("Failed to read account lock of user %s: %v", userID, err)
+			return "", ErrInternal
+		}
+		if lock != nil {
+			return "", ErrAccountUnderReview
+		}
+		return fn(ctx, logger, db, nk, payload)
+	}
+}
@@ -0,0 +1,194 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountLock_BlocksEconomyRpcsUntilUnlocked(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	baseSystem := NewBaseSystem(nil)
+
+	rpc := func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		return "ok", nil
+	}
+	call := func(userID string) (string, error) {
+		ctx := ctx
+		if userID != "" {
+			ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_USER_ID, userID)
+		}
+		return withAccountLockRpc(rpc)(ctx, logger, nil, nk, "")
+	}
+
+	lock, err := baseSystem.LockAccount(ctx, logger, nk, "cheater", "impossible currency gain", "anomaly_detection")
+	require.NoError(t, err)
+	assert.Equal(t, "anomaly_detection", lock.Source)
+
+	// Locking again keeps the original reason
+	lock, err = baseSystem.LockAccount(ctx, logger, nk, "cheater", "another", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "impossible currency gain", lock.Reason)
+
+	_, err = call("cheater")
+	assert.ErrorIs(t, err, ErrAccountUnderReview)
+
+	// Other users and server calls aren't affected
+	response, err := call("user1")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", response)
+	_, err = call("")
+	require.NoError(t, err)
+
+	require.NoError(t, baseSystem.UnlockAccount(ctx, logger, nk, "cheater"))
+	_, err = call("cheater")
+	require.NoError(t, err)
+	assert.ErrorIs(t, baseSystem.UnlockAccount(ctx, logger, nk, "cheater"), ErrAccountNotLocked)
+}
+
+func TestAccountLock_PurgeOnlyLockedAccounts(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := &pamlogixImpl{systems: map[SystemType]System{SystemTypeBase: NewBaseSystem(nil)}}
+	purge := rpcBaseAccountPurge(p)
+
+	_, err := purge(ctx, logger, nil, nk, `{"user_id":"user1"}`)
+	assert.ErrorIs(t, err, ErrAccountNotLocked)
+
+	// Players can't purge accounts themselves
+	userCtx := context.WithValue(ctx, runtime.RUNTIME_CTX_USER_ID, "user2")
+	_, err = purge(userCtx, logger, nil, nk, `{"user_id":"user1"}`)
+	assert.ErrorIs(t, err, ErrAccountLockServerOnly)
+
+	_, err = rpcBaseAccountLock(p)(ctx, logger, nil, nk, `{"user_id":"user1","reason":"chargeback fraud"}`)
+	require.NoError(t, err)
+
+	nk.MockNakamaModule.On("AccountDeleteId", ctx, "user1", false).Return(nil).Once()
+	_, err = purge(ctx, logger, nil, nk, `{"user_id":"user1"}`)
+	require.NoError(t, err)
+	nk.MockNakamaModule.AssertExpectations(t)
+}
+
+func TestAccountLock_LocksEveryEconomyRpc(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	require.NoError(t, writeAccountLock(ctx, nk, &AccountLock{UserId: "cheater"}))
+	userCtx := context.WithValue(ctx, runtime.RUNTIME_CTX_USER_ID, "cheater")
+
+	// The RPCs a locked user may still call. They only read, change state outside the economy, or are server calls,
+	// which the lock doesn't apply to.
+	unlocked := map[string]bool{
+		// Reads
+		RpcId_RPC_ID_ACHIEVEMENTS_GET.String():             true,
+		RpcId_RPC_ID_BASE_SYNC.String():                    true,
+		RpcIdBaseNotificationPreferencesGet:                true,
+		RpcIdBaseContentCalendarGet:                        true,
+		RpcIdBaseDataExport:                                true,
+		RpcId_RPC_ID_ECONOMY_DONATION_GET.String():         true,
+		RpcId_RPC_ID_ECONOMY_STORE_GET.String():            true,
+		RpcId_RPC_ID_ECONOMY_PLACEMENT_STATUS.String():     true,
+		RpcIdEconomyCanAfford:                              true,
+		RpcIdEconomyModifierJobGet:                         true,
+		RpcIdEconomyCurrencies:                             true,
+		RpcIdEconomyPlacementList:                          true,
+		RpcIdEconomyEventLogList:                           true,
+		RpcIdEconomySummary:                                true,
+		RpcIdEconomySubscriptionList:                       true,
+		RpcId_RPC_ID_EVENT_LEADERBOARD_LIST.String():       true,
+		RpcId_RPC_ID_EVENT_LEADERBOARD_GET.String():        true,
+		RpcIdEventLeaderboardGlobalGet:                     true,
+		RpcIdEventLeaderboardRollPreview:                   true,
+		RpcIdEventLeaderboardStats:                         true,
+		RpcIdLeaderboardsTournamentList:                    true,
+		RpcIdLeaderboardsTournamentStandings:               true,
+		RpcId_RPC_ID_ENERGY_GET.String():                   true,
+		RpcIdEnergyGiftList:                                true,
+		RpcId_RPC_ID_INVENTORY_LIST.String():               true,
+		RpcId_RPC_ID_INVENTORY_LIST_INVENTORY.String():     true,
+		RpcId_RPC_ID_STATS_GET.String():                    true,
+		RpcId_RPC_ID_TUTORIALS_GET.String():                true,
+		RpcId_RPC_ID_UNLOCKABLES_GET.String():              true,
+		RpcId_RPC_ID_AUCTIONS_GET_TEMPLATES.String():       true,
+		RpcIdAuctionsGetTemplate:                           true,
+		RpcId_RPC_ID_AUCTIONS_LIST.String():                true,
+		RpcId_RPC_ID_AUCTIONS_LIST_BIDS.String():           true,
+		RpcId_RPC_ID_AUCTIONS_LIST_CREATED.String():        true,
+		RpcIdAuctionsListHistory:                           true,
+		RpcIdAuctionsPriceHistory:                          true,
+		RpcSocketId_RPC_SOCKET_ID_AUCTIONS_FOLLOW.String(): true,
+		RpcId_RPC_ID_STREAKS_LIST.String():                 true,
+		RpcId_RPC_ID_PROGRESSIONS_GET.String():             true,
+		RpcId_RPC_ID_TEAMS_LIST.String():                   true,
+		RpcId_RPC_ID_TEAMS_SEARCH.String():                 true,
+		RpcIdTeamsGet:                                      true,
+		RpcIdTeamsTreasuryGet:                              true,
+		// State outside the economy
+		RpcId_RPC_ID_ACHIEVEMENTS_UPDATE.String():                   true,
+		RpcId_RPC_ID_BASE_RATE_APP.String():                         true,
+		RpcId_RPC_ID_BASE_SET_DEVICE_PREFS.String():                 true,
+		RpcIdBaseNotificationPreferencesSet:                         true,
+		RpcId_RPC_ID_EVENT_LEADERBOARD_UPDATE.String():              true,
+		RpcId_RPC_ID_EVENT_LEADERBOARD_DEBUG_FILL.String():          true,
+		RpcId_RPC_ID_EVENT_LEADERBOARD_DEBUG_RANDOM_SCORES.String(): true,
+		RpcId_RPC_ID_STATS_UPDATE.String():                          true,
+		RpcId_RPC_ID_TUTORIALS_ACCEPT.String():                      true,
+		RpcId_RPC_ID_TUTORIALS_DECLINE.String():                     true,
+		RpcId_RPC_ID_TUTORIALS_ABANDON.String():                     true,
+		RpcId_RPC_ID_TUTORIALS_UPDATE.String():                      true,
+		RpcId_RPC_ID_TUTORIALS_RESET.String():                       true,
+		RpcId_RPC_ID_UNLOCKABLES_UNLOCK_START.String():              true,
+		RpcId_RPC_ID_UNLOCKABLES_QUEUE_ADD.String():                 true,
+		RpcId_RPC_ID_UNLOCKABLES_QUEUE_REMOVE.String():              true,
+		RpcId_RPC_ID_UNLOCKABLES_QUEUE_SET.String():                 true,
+		RpcId_RPC_ID_STREAKS_UPDATE.String():                        true,
+		RpcId_RPC_ID_STREAKS_RESET.String():                         true,
+		RpcId_RPC_ID_PROGRESSIONS_UPDATE.String():                   true,
+		RpcId_RPC_ID_PROGRESSIONS_RESET.String():                    true,
+		RpcId_RPC_ID_TEAMS_CREATE.String():                          true,
+		RpcId_RPC_ID_TEAMS_WRITE_CHAT_MESSAGE.String():              true,
+		RpcIdTeamsUpdate: true,
+		// Server calls
+		RpcIdBaseAccountLock:                   true,
+		RpcIdBaseAccountUnlock:                 true,
+		RpcIdBaseAccountPurge:                  true,
+		RpcIdBaseStorageSweep:                  true,
+		RpcIdEconomyPurchaseTransactionsExport: true,
+		RpcIdEconomyModifierJobStart:           true,
+		RpcIdEconomySnapshotCreate:             true,
+		RpcIdEconomySnapshotList:               true,
+		RpcIdEconomySnapshotRestore:            true,
+		RpcIdEventLeaderboardCleanup:           true,
+	}
+
+	// Without systems the RPCs which aren't locked fail on their own, or panic, but never as under review
+	p := &pamlogixImpl{systems: make(map[SystemType]System)}
+	recorder := &rpcRecordingInitializer{rpcs: make(map[string]func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error))}
+	for systemType := SystemTypeBase; systemType <= SystemTypeChallenges; systemType++ {
+		require.NoError(t, p.registerSystemRpcs_Json(recorder, systemType))
+	}
+	locked := func(rpc func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) (locked bool) {
+		defer func() {
+			if recover() != nil {
+				locked = false
+			}
+		}()
+		_, err := rpc(userCtx, logger, nil, nk, "{}")
+		return errors.Is(err, ErrAccountUnderReview)
+	}
+
+	for id, rpc := range recorder.rpcs {
+		assert.Equal(t, !unlocked[id], locked(rpc), "RPC %s", id)
+	}
+	for id := range unlocked {
+		assert.Contains(t, recorder.rpcs, id)
+	}
+}
@@ -125,6 +125,37 @@ func (s *NakamaAchievementsSystem) calculateNextResetTime(cronExpr string, now t
 	return nextReset.Unix(), nil
 }
 
+// autoClaimsForUser returns the achievements to auto claim for the user, leaving out those whose auto claim rule doesn't
+// hold for them.
+func (s *NakamaAchievementsSystem) autoClaimsForUser(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, achievementIDs []string) []string {
+	rules := make([]*Rule, 0)
+	for _, id := range achievementIDs {
+		if achConfig, found := s.config.Achievements[id]; found && achConfig.AutoClaimRule != "" {
+			if rule, err := compiledRule(achConfig.AutoClaimRule); err == nil {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	if len(rules) == 0 {
+		return achievementIDs
+	}
+
+	env, err := LoadRuleEnv(ctx, logger, nk, s.pamlogix, userID, rules...)
+	if err != nil {
+		logger.Error("Failed to load auto claim rule state for user %s: %v", userID, err)
+		env = nil
+	}
+	autoClaims := make([]string, 0, len(achievementIDs))
+	for _, id := range achievementIDs {
+		achConfig, found := s.config.Achievements[id]
+		if found && achConfig.AutoClaimRule != "" && (env == nil || !evalRule(logger, achConfig.AutoClaimRule, env)) {
+			continue
+		}
+		autoClaims = append(autoClaims, id)
+	}
+	return autoClaims
+}
+
 // Helper function to check achievement preconditions
 func (s *NakamaAchievementsSystem) checkPreconditions(achievementList *AchievementList, preconditionIDs []string) bool {
 	if len(preconditionIDs) == 0 {
@@ -845,6 +876,7 @@ func (s *NakamaAchievementsSystem) UpdateAchievements(ctx context.Context, logge
 	}
 
 	// Process auto-claim achievements
+	achsToAutoClaim = s.autoClaimsForUser(ctx, logger, nk, userID, achsToAutoClaim)
 	if len(achsToAutoClaim) > 0 {
 		claimAchievements, claimRepeatAchievements, errClaim := s.ClaimAchievements(ctx, logger, nk, userID, achsToAutoClaim, false)
 		if errClaim != nil {
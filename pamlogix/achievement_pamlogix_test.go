@@ -125,10 +125,22 @@ func (m *mockEconomySystem) RewardRoll(ctx context.Context, logger runtime.Logge
 	return r, nil
 }
 
+func (m *mockEconomySystem) RewardRollN(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, rewardConfig *EconomyConfigReward, count int64) (*Reward, error) {
+	return m.RewardRoll(ctx, logger, nk, userID, rewardConfig)
+}
+
 func (m *mockEconomySystem) RewardGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, metadata map[string]interface{}, ignoreLimits bool) (map[string]*InventoryItem, map[string]*InventoryItem, map[string]int64, error) {
 	return nil, nil, nil, nil
 }
 
+func (m *mockEconomySystem) RewardGrantDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, metadata map[string]interface{}, ignoreLimits bool) (map[string]*InventoryItem, map[string]*InventoryItem, map[string]int64, error) {
+	return nil, nil, nil, nil
+}
+
+func (m *mockEconomySystem) RewardGrantBulk(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, rewards map[string]*Reward, metadata map[string]interface{}, ignoreLimits bool) (map[string]*EconomyRewardGrantResult, error) {
+	return nil, nil
+}
+
 func (m *mockEconomySystem) RewardCreate() (rewardConfig *EconomyConfigReward) {
 	return nil
 }
@@ -137,6 +149,10 @@ func (m *mockEconomySystem) RewardConvert(contents *AvailableRewards) (rewardCon
 	return nil
 }
 
+func (m *mockEconomySystem) RewardConvertReverse(rewardConfig *EconomyConfigReward) (contents *AvailableRewards) {
+	return nil
+}
+
 func (m *mockEconomySystem) DonationClaim(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, donationClaims map[string]*EconomyDonationClaimRequestDetails) (*EconomyDonationsList, error) {
 	return nil, nil
 }
@@ -145,7 +161,7 @@ func (m *mockEconomySystem) DonationGet(ctx context.Context, logger runtime.Logg
 	return nil, nil
 }
 
-func (m *mockEconomySystem) DonationGive(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, donationID, fromUserID string) (*EconomyDonation, map[string]int64, *Inventory, []*ActiveRewardModifier, *Reward, int64, error) {
+func (m *mockEconomySystem) DonationGive(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, recipientID, donationID, contributorID string) (*EconomyDonation, map[string]int64, *Inventory, []*ActiveRewardModifier, *Reward, int64, error) {
 	return nil, nil, nil, nil, nil, 0, nil
 }
 
@@ -157,10 +173,26 @@ func (m *mockEconomySystem) List(ctx context.Context, logger runtime.Logger, nk
 	return nil, nil, nil, 0, nil
 }
 
-func (m *mockEconomySystem) Grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, modifiers []*RewardModifier, walletMetadata map[string]interface{}) (map[string]int64, []*ActiveRewardModifier, int64, error) {
+func (m *mockEconomySystem) Grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, itemInstances map[string]*RewardInventoryItem, modifiers []*RewardModifier, walletMetadata map[string]interface{}) (map[string]int64, []*ActiveRewardModifier, int64, error) {
 	return nil, nil, 0, nil
 }
 
+func (m *mockEconomySystem) GrantDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, itemInstances map[string]*RewardInventoryItem, modifiers []*RewardModifier, walletMetadata map[string]interface{}) (map[string]int64, []*ActiveRewardModifier, int64, error) {
+	return nil, nil, 0, nil
+}
+
+func (m *mockEconomySystem) Debit(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, walletMetadata map[string]interface{}) (map[string]int64, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) DebitDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, walletMetadata map[string]interface{}) (map[string]int64, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) RewardGrantCapped(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, source string, reward *Reward, metadata map[string]interface{}) (map[string]int64, error) {
+	return nil, nil
+}
+
 func (m *mockEconomySystem) UnmarshalWallet(account *api.Account) (map[string]int64, error) {
 	return nil, nil
 }
@@ -169,6 +201,30 @@ func (m *mockEconomySystem) PurchaseIntent(ctx context.Context, logger runtime.L
 	return nil
 }
 
+func (m *mockEconomySystem) PurchaseIntentCancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, itemID string) error {
+	return nil
+}
+
+func (m *mockEconomySystem) TeamPurchaseItem(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID, itemID string) (map[string]int64, *Inventory, *Reward, error) {
+	return nil, nil, nil, nil
+}
+
+func (m *mockEconomySystem) CanAfford(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, cost *Cost) (*Cost, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) ListCurrencies(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (map[string]*EconomyConfigCurrency, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) PurchaseIntentsCleanup(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	return 0, nil
+}
+
+func (m *mockEconomySystem) CompactModifiers(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	return 0, nil
+}
+
 func (m *mockEconomySystem) PurchaseItem(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, userID, itemID string, store EconomyStoreType, receipt string) (map[string]int64, *Inventory, *Reward, bool, error) {
 	return nil, nil, nil, false, nil
 }
@@ -177,10 +233,70 @@ func (m *mockEconomySystem) PurchaseRestore(ctx context.Context, logger runtime.
 	return nil
 }
 
+func (m *mockEconomySystem) PurchaseTransactionsExport(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, startTimeSec, endTimeSec int64, format string, limit int, cursor string) (*EconomyPurchaseTransactionsExport, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) StartModifierJob(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, request *EconomyModifierJobRequest) (*EconomyModifierJob, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) GetModifierJob(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, jobID string) (*EconomyModifierJob, error) {
+	return nil, nil
+}
+
 func (m *mockEconomySystem) PlacementStatus(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, rewardID, placementID string, retryCount int) (*EconomyPlacementStatus, error) {
 	return nil, nil
 }
 
+func (m *mockEconomySystem) SnapshotCreate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, reason string) (*EconomySnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) SnapshotList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomySnapshotList, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) SnapshotRestore(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, snapshotID string) (*EconomySnapshot, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) EventLogList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, afterSequence int64, limit int) (*EconomyEventLogList, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) Summary(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomySummary, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) SubscriptionPurchase(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, subscriptionID string, store EconomyStoreType, receipt string) (*EconomySubscription, *Reward, error) {
+	return nil, nil, nil
+}
+
+func (m *mockEconomySystem) SubscriptionList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomySubscriptionList, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) SubscriptionCancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, subscriptionID string) (*EconomySubscription, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) SubscriptionsCheck(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	return 0, nil
+}
+
+func (m *mockEconomySystem) PurchaseGrantsReconcile(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomyPurchaseGrantsReconcile, error) {
+	return nil, nil
+}
+
+func (m *mockEconomySystem) PurchaseGrantsRetry(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	return 0, nil
+}
+
+func (m *mockEconomySystem) ListPlacements(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomyPlacementList, error) {
+	return nil, nil
+}
+
 func (m *mockEconomySystem) PlacementStart(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, placementID string, metadata map[string]string) (*EconomyPlacementStatus, error) {
 	return nil, nil
 }
@@ -206,6 +322,9 @@ func (m *mockEconomySystem) SetOnPlacementReward(fn OnReward[*EconomyPlacementIn
 func (m *mockEconomySystem) SetOnStoreItemReward(fn OnReward[*EconomyConfigStoreItem]) {
 }
 
+func (m *mockEconomySystem) SetPurchaseValidator(store EconomyStoreType, fn EconomyPurchaseValidatorFn) {
+}
+
 type mockPamlogix struct {
 	mock.Mock
 	economy *mockEconomySystem
@@ -227,13 +346,19 @@ func (m *mockPamlogix) GetStreaksSystem() StreaksSystem                     { re
 func (m *mockPamlogix) GetTeamsSystem() TeamsSystem                         { return nil }
 func (m *mockPamlogix) GetTutorialsSystem() TutorialsSystem                 { return nil }
 func (m *mockPamlogix) GetUnlockablesSystem() UnlockablesSystem             { return nil }
+func (m *mockPamlogix) GetChallengesSystem() ChallengesSystem               { return nil }
 
 // Set methods
-func (m *mockPamlogix) SetPersonalizer(p Personalizer)                {}
-func (m *mockPamlogix) AddPersonalizer(p Personalizer)                {}
-func (m *mockPamlogix) AddPublisher(p Publisher)                      {}
-func (m *mockPamlogix) SetAfterAuthenticate(fn AfterAuthenticateFn)   {}
-func (m *mockPamlogix) SetCollectionResolver(fn CollectionResolverFn) {}
+func (m *mockPamlogix) SetPersonalizer(p Personalizer) {}
+func (m *mockPamlogix) AddPersonalizer(p Personalizer) {}
+func (m *mockPamlogix) AddPublisher(p Publisher)       {}
+func (m *mockPamlogix) SendPublisherEvents(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, events []*PublisherEvent) {
+}
+func (m *mockPamlogix) SetAfterAuthenticate(fn AfterAuthenticateFn)                 {}
+func (m *mockPamlogix) SetCollectionResolver(fn CollectionResolverFn)               {}
+func (m *mockPamlogix) SetTextModeration(fn TextModerationFn)                       {}
+func (m *mockPamlogix) SetJsonPolicy(policy *JsonPolicy)                            {}
+func (m *mockPamlogix) RegisterJob(name string, defaultIntervalSec int64, fn JobFn) {}
 
 // Logger stub for tests
 // Implements runtime.Logger, logs to testing.T
@@ -30,6 +30,9 @@ type AchievementsConfigAchievement struct {
 	TotalReward          *EconomyConfigReward                         `json:"total_reward,omitempty"`
 	SubAchievements      map[string]*AchievementsConfigSubAchievement `json:"sub_achievements,omitempty"`
 	AdditionalProperties map[string]string                            `json:"additional_properties,omitempty"`
+	// AutoClaimRule limits auto claims to users for whom the rule holds, such as `stats.wins >= 10`. Others claim the
+	// achievement themselves. See Rule for what it can read.
+	AutoClaimRule string `json:"auto_claim_rule,omitempty"`
 }
 
 type AchievementsConfigSubAchievement struct {
@@ -47,6 +50,13 @@ type AchievementsConfigSubAchievement struct {
 	AdditionalProperties map[string]string    `json:"additional_properties,omitempty"`
 }
 
+// AchievementListDetails is a list of achievements with the display metadata of the items their rewards offer, keyed
+// by item ID.
+type AchievementListDetails struct {
+	*AchievementList
+	ItemDetails map[string]*RewardItemDetails `json:"item_details,omitempty"`
+}
+
 // An AchievementsSystem is a gameplay system which represents one-off, repeat, preconditioned, and sub-achievements.
 type AchievementsSystem interface {
 	System
@@ -0,0 +1,242 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// AuctionBidRetractionCollectionKey holds how bids on each auction which allows retractions can be retracted.
+const AuctionBidRetractionCollectionKey = "auction_bid_retractions"
+
+// auctionBidRetraction is how bids on an auction can be retracted, stored apart from the auction when it's created.
+type auctionBidRetraction struct {
+	WindowSec int64                              `json:"window_sec"`
+	Penalty   *AuctionsConfigAuctionConditionFee `json:"penalty,omitempty"`
+	// BidStart is the auction's first next bid, which it goes back to when no earlier bid can be restored.
+	BidStart *AuctionBidAmount `json:"bid_start,omitempty"`
+}
+
+func (a *AuctionsPamlogix) saveBidRetraction(ctx context.Context, nk runtime.NakamaModule, auctionID string, retraction *auctionBidRetraction) error {
+	data, err := json.Marshal(retraction)
+	if err != nil {
+		return err
+	}
+
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      AuctionBidRetractionCollectionKey,
+			Key:             auctionID,
+			UserID:          "",
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+
+	return err
+}
+
+// readBidRetraction returns how bids on an auction can be retracted, or nil if they can't.
+func (a *AuctionsPamlogix) readBidRetraction(ctx context.Context, nk runtime.NakamaModule, auctionID string) (*auctionBidRetraction, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: AuctionBidRetractionCollectionKey,
+			Key:        auctionID,
+			UserID:     "",
+		},
+	})
+	if err != nil || len(objects) == 0 {
+		return nil, err
+	}
+
+	var retraction auctionBidRetraction
+	if err := json.Unmarshal([]byte(objects[0].Value), &retraction); err != nil {
+		return nil, err
+	}
+	return &retraction, nil
+}
+
+// canRetractBid reports whether the user's high bid is recent enough to retract, and the auction isn't so close to its
+// end that bids would extend it.
+func canRetractBid(auction *Auction, retraction *auctionBidRetraction, userID string, currentTime int64) bool {
+	if retraction == nil || auction.Bid == nil || auction.Bid.UserId != userID || !auction.HasStarted || auction.HasEnded {
+		return false
+	}
+	if currentTime-auction.Bid.CreateTimeSec > retraction.WindowSec {
+		return false
+	}
+	return auction.ExtensionThresholdSec <= 0 || auction.EndTimeSec-currentTime > auction.ExtensionThresholdSec
+}
+
+// retractionPenalty is the part of a retracted bid kept as the penalty, never more than the bid in each currency.
+func retractionPenalty(bid *AuctionBidAmount, penalty *AuctionsConfigAuctionConditionFee) *AuctionBidAmount {
+	taken := &AuctionBidAmount{Currencies: make(map[string]int64)}
+	if penalty == nil {
+		return taken
+	}
+	for currency, amount := range bid.GetCurrencies() {
+		penaltyAmount := int64(float64(amount) * penalty.Percentage)
+		if penalty.Fixed != nil {
+			penaltyAmount += penalty.Fixed.Currencies[currency]
+		}
+		if penaltyAmount = min(max(penaltyAmount, 0), amount); penaltyAmount > 0 {
+			taken.Currencies[currency] = penaltyAmount
+		}
+	}
+	return taken
+}
+
+func (a *AuctionsPamlogix) RetractBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID, auctionID, version string) (*Auction, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: AuctionCollectionKey,
+			Key:        auctionID,
+			UserID:     "",
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to read auction %s: %v", auctionID, err)
+		return nil, ErrInternal
+	}
+
+	if len(objects) == 0 {
+		return nil, ErrAuctionNotFound
+	}
+
+	var auction Auction
+	if err := json.Unmarshal([]byte(objects[0].Value), &auction); err != nil {
+		logger.Error("Failed to unmarshal auction %s: %v", auctionID, err)
+		return nil, ErrInternal
+	}
+
+	if auction.Version != version {
+		return nil, ErrAuctionVersionMismatch
+	}
+	storageVersion := objects[0].Version
+
+	currentTime := time.Now().Unix()
+	a.updateAuctionState(&auction, currentTime, userID)
+
+	retraction, err := a.readBidRetraction(ctx, nk, auctionID)
+	if err != nil {
+		logger.Error("Failed to read bid retraction of auction %s: %v", auctionID, err)
+		return nil, ErrInternal
+	}
+	if !canRetractBid(&auction, retraction, userID, currentTime) {
+		return nil, ErrAuctionCannotRetract
+	}
+
+	// The latest earlier bid whose bidder can still cover it leads again, its bidder paying for it once more
+	retracted := auction.Bid
+	var restored *AuctionBid
+	bidHistory := make([]*AuctionBid, 0, len(auction.BidHistory))
+	for _, bid := range auction.BidHistory {
+		if bid.UserId == retracted.UserId && bid.CreateTimeSec == retracted.CreateTimeSec {
+			continue
+		}
+		if restored == nil && bid.UserId != userID && bid.CreateTimeSec <= retracted.CreateTimeSec {
+			if teamID, err := a.bidTeam(ctx, nk, auctionID, bid); err != nil {
+				logger.Error("Failed to read team bids of auction %s: %v", auctionID, err)
+			} else if teamID != "" || a.checkUserFunds(ctx, logger, nk, bid.UserId, bid.Bid) == nil {
+				if err := a.chargeBid(ctx, logger, nk, auctionID, teamID, bid); err == nil {
+					restored = bid
+				}
+			}
+		}
+		bidHistory = append(bidHistory, bid)
+	}
+	auction.BidHistory = bidHistory
+	auction.Bid = restored
+	if restored != nil {
+		auction.BidNext = a.calculateNextBid(restored.Bid, nil)
+	} else {
+		auction.BidFirst = nil
+		auction.BidNext = &AuctionBidAmount{Currencies: make(map[string]int64)}
+		for currency, amount := range retraction.BidStart.GetCurrencies() {
+			auction.BidNext.Currencies[currency] = amount
+		}
+	}
+	auction.UpdateTimeSec = currentTime
+
+	if reserve, err := a.readReserve(ctx, nk, auctionID); err != nil {
+		logger.Error("Failed to read reserve of auction %s: %v", auctionID, err)
+	} else if reserve != nil {
+		auction.ReserveNotMet = restored == nil || !bidMeetsReserve(restored.Bid, reserve)
+	}
+
+	// The auction is saved only if no bid was placed on it meanwhile, and otherwise the restored bid is given back
+	a.updateAuctionState(&auction, currentTime, userID)
+	if err := a.saveAuctionVersion(ctx, nk, &auction, storageVersion); err != nil {
+		logger.Error("Failed to save auction after bid retraction: %v", err)
+		if restored != nil {
+			if err := a.refundBid(ctx, logger, nk, auctionID, restored, restored.Bid.Currencies, "retraction_failed"); err != nil {
+				logger.Error("Failed to return restored bid to user %s: %v", restored.UserId, err)
+			}
+		}
+		return nil, ErrAuctionVersionMismatch
+	}
+
+	// Refund the bid less the penalty, which goes to the fee sink when one is set
+	penalty := retractionPenalty(retracted.Bid, retraction.Penalty)
+	refund, _ := auctionProceeds(retracted.Bid, penalty)
+	if err := a.refundRetractedBid(ctx, logger, nk, &auction, retracted, refund, penalty); err != nil {
+		logger.Error("Failed to refund retracted bid of auction %s to user %s: %v", auctionID, retracted.UserId, err)
+		// Continue despite error as the bid is already retracted
+	}
+
+	if err := a.removeFromUserBidsIndex(ctx, nk, userID, auctionID); err != nil {
+		logger.Error("Failed to remove auction from user bids index: %v", err)
+	}
+	if restored != nil {
+		if err := a.addToUserBidsIndex(ctx, nk, restored.UserId, auctionID); err != nil {
+			logger.Error("Failed to add auction to restored bidder's index: %v", err)
+		}
+	}
+
+	a.sendBidRetractionNotification(ctx, logger, nk, &auction)
+
+	return &auction, nil
+}
+
+// refundRetractedBid refunds a retracted bid less its penalty to whoever paid for it, and credits the penalty to the fee
+// sink.
+func (a *AuctionsPamlogix) refundRetractedBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auction *Auction, retracted *AuctionBid, refund, penalty *AuctionBidAmount) error {
+	if len(refund.Currencies) > 0 {
+		if err := a.refundBid(ctx, logger, nk, auction.Id, retracted, refund.Currencies, "retracted"); err != nil {
+			logger.Error("Failed to refund retracted bid of user %s: %v", retracted.UserId, err)
+			return err
+		}
+	}
+
+	if sinkUserID := a.feeSinkUserID(); sinkUserID != "" && len(penalty.Currencies) > 0 {
+		if _, _, err := nk.WalletUpdate(ctx, sinkUserID, penalty.Currencies, auctionProceedsMetadata(auction, "auction_retraction_penalty"), true); err != nil {
+			// The bidder is refunded, so the retraction goes ahead without the penalty reaching the sink
+			logger.Error("Failed to credit retraction penalty of auction %s to fee sink %s: %v", auction.Id, sinkUserID, err)
+		}
+	}
+	return nil
+}
+
+// sendBidRetractionNotification sends the auction's restored bid to its followers, and lets the restored bidder know
+// they lead again.
+func (a *AuctionsPamlogix) sendBidRetractionNotification(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auction *Auction) {
+	a.streamBidUpdate(logger, nk, auction)
+
+	if auction.Bid == nil {
+		return
+	}
+	content := map[string]interface{}{
+		"auction_id": auction.Id,
+		"bid_amount": auction.Bid.Bid.Currencies,
+		"type":       "auction_bid_restored",
+	}
+	vars := map[string]string{
+		"auction_id": auction.Id,
+	}
+	if err := sendTemplatedNotification(ctx, logger, nk, a.pamlogix, auction.Bid.UserId, NotificationEventAuctionBidRestored, vars, content); err != nil {
+		logger.Error("Failed to send bid restored notification to user %s: %v", auction.Bid.UserId, err)
+	}
+}
@@ -0,0 +1,165 @@
+package pamlogix
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	defaultAuctionBidderCacheSec = 60
+	// auctionBidderCacheMaxSize is how many bidders are cached before expired entries are dropped.
+	auctionBidderCacheMaxSize = 10000
+)
+
+type auctionBidder struct {
+	displayName string
+	avatarUrl   string
+	expireTime  time.Time
+}
+
+// auctionBidderCache keeps the display names and avatars of recent bidders for a short while, so listing the same
+// popular auctions again doesn't look up the same accounts each time.
+type auctionBidderCache struct {
+	mu      sync.Mutex
+	bidders map[string]*auctionBidder
+}
+
+func newAuctionBidderCache() *auctionBidderCache {
+	return &auctionBidderCache{
+		bidders: make(map[string]*auctionBidder),
+	}
+}
+
+// get returns the cached bidders among the user IDs, and the user IDs that aren't cached or have expired. A nil cache
+// has none cached.
+func (c *auctionBidderCache) get(userIDs []string, now time.Time) (map[string]*auctionBidder, []string) {
+	if c == nil {
+		return make(map[string]*auctionBidder, len(userIDs)), userIDs
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	found := make(map[string]*auctionBidder, len(userIDs))
+	missing := make([]string, 0)
+	for _, userID := range userIDs {
+		if bidder, ok := c.bidders[userID]; ok && now.Before(bidder.expireTime) {
+			found[userID] = bidder
+			continue
+		}
+		missing = append(missing, userID)
+	}
+	return found, missing
+}
+
+func (c *auctionBidderCache) put(bidders map[string]*auctionBidder, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.bidders)+len(bidders) > auctionBidderCacheMaxSize {
+		for userID, bidder := range c.bidders {
+			if !now.Before(bidder.expireTime) {
+				delete(c.bidders, userID)
+			}
+		}
+	}
+	for userID, bidder := range bidders {
+		if len(c.bidders) >= auctionBidderCacheMaxSize {
+			break
+		}
+		c.bidders[userID] = bidder
+	}
+}
+
+func (a *AuctionsPamlogix) bidderCacheTTL() time.Duration {
+	if a.config != nil && a.config.BidderCacheSec > 0 {
+		return time.Duration(a.config.BidderCacheSec) * time.Second
+	}
+	return defaultAuctionBidderCacheSec * time.Second
+}
+
+// EnrichBidders sets the display name and avatar of the bidders in the current bid, first bid and bid history of the
+// auctions, looking up the accounts not cached in one batch.
+func (a *AuctionsPamlogix) EnrichBidders(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctions []*Auction) error {
+	bids := make([]*AuctionBid, 0)
+	for _, auction := range auctions {
+		if auction == nil {
+			continue
+		}
+		if auction.Bid != nil {
+			bids = append(bids, auction.Bid)
+		}
+		if auction.BidFirst != nil {
+			bids = append(bids, auction.BidFirst)
+		}
+		for _, bid := range auction.BidHistory {
+			if bid != nil {
+				bids = append(bids, bid)
+			}
+		}
+	}
+	if len(bids) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(bids))
+	userIDs := make([]string, 0, len(bids))
+	for _, bid := range bids {
+		if bid.UserId != "" && !seen[bid.UserId] {
+			seen[bid.UserId] = true
+			userIDs = append(userIDs, bid.UserId)
+		}
+	}
+
+	now := time.Now()
+	bidders, missing := a.bidderCache.get(userIDs, now)
+	if len(missing) > 0 {
+		accounts, err := nk.AccountsGetId(ctx, missing)
+		if err != nil {
+			logger.Error("Failed to get accounts of %d auction bidders: %v", len(missing), err)
+			return err
+		}
+		fetched := make(map[string]*auctionBidder, len(accounts))
+		expireTime := now.Add(a.bidderCacheTTL())
+		for _, account := range accounts {
+			user := account.GetUser()
+			if user == nil {
+				continue
+			}
+			displayName := user.DisplayName
+			if displayName == "" {
+				displayName = user.Username
+			}
+			bidder := &auctionBidder{
+				displayName: displayName,
+				avatarUrl:   user.AvatarUrl,
+				expireTime:  expireTime,
+			}
+			fetched[user.Id] = bidder
+			bidders[user.Id] = bidder
+		}
+		a.bidderCache.put(fetched, now)
+	}
+
+	for _, bid := range bids {
+		if bidder, found := bidders[bid.UserId]; found {
+			bid.DisplayName = bidder.displayName
+			bid.AvatarUrl = bidder.avatarUrl
+		}
+	}
+	return nil
+}
+
+// enrichAuctionBidders sets the bidders' display names and avatars on a listing when the request asks for them. The
+// listing is still returned without them if the accounts can't be read.
+func enrichAuctionBidders(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctionsSystem AuctionsSystem, auctionList *AuctionList, include bool) {
+	if !include || auctionList == nil {
+		return
+	}
+	if err := auctionsSystem.EnrichBidders(ctx, logger, nk, auctionList.Auctions); err != nil {
+		logger.Warn("Failed to add bidder details to auction list: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// AuctionFeeLedgerCollectionKey holds the fees taken from auction proceeds, one object per UTC day keyed by its date.
+const AuctionFeeLedgerCollectionKey = "auction_fee_ledger"
+
+// auctionFeeLedger tallies the fees taken from the auctions claimed in a day.
+type auctionFeeLedger struct {
+	Currencies    map[string]int64 `json:"currencies"`
+	Auctions      int64            `json:"auctions"`
+	UpdateTimeSec int64            `json:"update_time_sec"`
+}
+
+func validateAuctionFeeSink(sink *AuctionsConfigFeeSink) error {
+	if sink == nil {
+		return nil
+	}
+	if _, err := uuid.Parse(sink.UserId); err != nil {
+		return fmt.Errorf("invalid fee sink user ID %q: %w", sink.UserId, err)
+	}
+	return nil
+}
+
+// auctionProceeds splits the reward of a sold auction into the proceeds credited to the creator and the fee. The fee
+// is capped at the reward in each currency, so a fixed fee never costs the creator more than the auction raised.
+func auctionProceeds(reward, fee *AuctionBidAmount) (*AuctionBidAmount, *AuctionBidAmount) {
+	proceeds := &AuctionBidAmount{Currencies: make(map[string]int64, len(reward.GetCurrencies()))}
+	taken := &AuctionBidAmount{Currencies: make(map[string]int64, len(fee.GetCurrencies()))}
+	for currency, amount := range reward.GetCurrencies() {
+		feeAmount := min(max(fee.GetCurrencies()[currency], 0), max(amount, 0))
+		if feeAmount > 0 {
+			taken.Currencies[currency] = feeAmount
+		}
+		if amount-feeAmount > 0 {
+			proceeds.Currencies[currency] = amount - feeAmount
+		}
+	}
+	return proceeds, taken
+}
+
+// creditProceeds credits the proceeds of a sold auction to its creator, and the fee to the fee sink when one is set. A
+// failed credit is reversed, leaving neither wallet changed.
+func (a *AuctionsPamlogix) creditProceeds(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auction *Auction, proceeds, fee *AuctionBidAmount) error {
+	if len(proceeds.Currencies) > 0 {
+		if _, _, err := nk.WalletUpdate(ctx, auction.UserId, proceeds.Currencies, auctionProceedsMetadata(auction, "auction_proceeds"), true); err != nil {
+			logger.Error("Failed to credit proceeds of auction %s to user %s: %v", auction.Id, auction.UserId, err)
+			return err
+		}
+	}
+
+	sinkUserID := a.feeSinkUserID()
+	if sinkUserID == "" || len(fee.Currencies) == 0 {
+		return nil
+	}
+	if _, _, err := nk.WalletUpdate(ctx, sinkUserID, fee.Currencies, auctionProceedsMetadata(auction, "auction_fee"), true); err != nil {
+		logger.Error("Failed to credit fee of auction %s to fee sink %s: %v", auction.Id, sinkUserID, err)
+		a.reverseProceeds(ctx, logger, nk, auction, proceeds, nil)
+		return err
+	}
+	return nil
+}
+
+// reverseProceeds takes back the proceeds and fee credited for an auction whose claim couldn't be saved, so claiming
+// it again doesn't credit them twice.
+func (a *AuctionsPamlogix) reverseProceeds(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auction *Auction, proceeds, fee *AuctionBidAmount) {
+	if len(proceeds.GetCurrencies()) > 0 {
+		if _, _, err := nk.WalletUpdate(ctx, auction.UserId, scaleAmounts(proceeds.Currencies, -1), auctionProceedsMetadata(auction, "auction_proceeds_reversal"), true); err != nil {
+			logger.Error("Failed to reverse proceeds of auction %s from user %s: %v", auction.Id, auction.UserId, err)
+		}
+	}
+	if sinkUserID := a.feeSinkUserID(); sinkUserID != "" && len(fee.GetCurrencies()) > 0 {
+		if _, _, err := nk.WalletUpdate(ctx, sinkUserID, scaleAmounts(fee.Currencies, -1), auctionProceedsMetadata(auction, "auction_fee_reversal"), true); err != nil {
+			logger.Error("Failed to reverse fee of auction %s from fee sink %s: %v", auction.Id, sinkUserID, err)
+		}
+	}
+}
+
+func auctionProceedsMetadata(auction *Auction, source string) map[string]interface{} {
+	return map[string]interface{}{
+		"source":     source,
+		"auction_id": auction.Id,
+	}
+}
+
+func (a *AuctionsPamlogix) feeSinkUserID() string {
+	if a.config.FeeSink == nil {
+		return ""
+	}
+	return a.config.FeeSink.UserId
+}
+
+// recordFee adds the fee of a claimed auction to the fee ledger of the day it was claimed.
+func (a *AuctionsPamlogix) recordFee(ctx context.Context, nk runtime.NakamaModule, fee *AuctionBidAmount, currentTime int64) error {
+	if len(fee.GetCurrencies()) == 0 {
+		return nil
+	}
+	key := auctionFeeLedgerKey(currentTime)
+
+	// Every claim of the day updates the same object, so hold its lock for the read-modify-write
+	return withStorageLock(ctx, nk, AuctionFeeLedgerCollectionKey+":"+key, func() error {
+		ledger, err := readAuctionFeeLedger(ctx, nk, key)
+		if err != nil {
+			return err
+		}
+		for currency, amount := range fee.Currencies {
+			ledger.Currencies[currency] += amount
+		}
+		ledger.Auctions++
+		ledger.UpdateTimeSec = currentTime
+
+		data, err := json.Marshal(ledger)
+		if err != nil {
+			return err
+		}
+		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+			Collection:      AuctionFeeLedgerCollectionKey,
+			Key:             key,
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		}})
+		return err
+	})
+}
+
+func readAuctionFeeLedger(ctx context.Context, nk runtime.NakamaModule, key string) (*auctionFeeLedger, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: AuctionFeeLedgerCollectionKey, Key: key}})
+	if err != nil {
+		return nil, err
+	}
+	ledger := &auctionFeeLedger{}
+	if len(objects) > 0 {
+		if err := json.Unmarshal([]byte(objects[0].Value), ledger); err != nil {
+			return nil, err
+		}
+	}
+	if ledger.Currencies == nil {
+		ledger.Currencies = make(map[string]int64)
+	}
+	return ledger, nil
+}
+
+func auctionFeeLedgerKey(timeSec int64) string {
+	return time.Unix(timeSec, 0).UTC().Format(time.DateOnly)
+}
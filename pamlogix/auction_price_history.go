@@ -0,0 +1,190 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	// AuctionPriceHistoryCollectionKey holds the recent sales of each item sold at auction, keyed by item ID.
+	AuctionPriceHistoryCollectionKey = "auction_price_history"
+
+	defaultAuctionPriceHistoryRetentionSec    = 30 * 24 * 60 * 60
+	defaultAuctionPriceHistoryMaxSalesPerItem = 500
+	maxAuctionPriceHistoryWindows             = 10
+)
+
+var defaultAuctionPriceHistoryWindowsSec = []int64{24 * 60 * 60, 7 * 24 * 60 * 60, 30 * 24 * 60 * 60}
+
+// auctionSale is the winning bid of an auction of one item ID.
+type auctionSale struct {
+	Count       int64            `json:"count"`
+	Currencies  map[string]int64 `json:"currencies"`
+	SaleTimeSec int64            `json:"sale_time_sec"`
+}
+
+// auctionPriceHistory is the recent sales of an item, oldest first.
+type auctionPriceHistory struct {
+	Sales []*auctionSale `json:"sales"`
+}
+
+func (a *AuctionsPamlogix) priceHistoryRetentionSec() int64 {
+	if a.config.PriceHistory != nil && a.config.PriceHistory.RetentionSec > 0 {
+		return a.config.PriceHistory.RetentionSec
+	}
+	return defaultAuctionPriceHistoryRetentionSec
+}
+
+func (a *AuctionsPamlogix) priceHistoryMaxSalesPerItem() int {
+	if a.config.PriceHistory != nil && a.config.PriceHistory.MaxSalesPerItem > 0 {
+		return a.config.PriceHistory.MaxSalesPerItem
+	}
+	return defaultAuctionPriceHistoryMaxSalesPerItem
+}
+
+// recordSale adds the winning bid of a claimed auction to its item's price history. Only auctions of a single item ID
+// are recorded, as the bid on a mix of items can't be split between them.
+func (a *AuctionsPamlogix) recordSale(ctx context.Context, nk runtime.NakamaModule, auction *Auction, currentTime int64) error {
+	if auction.GetBid().GetBid() == nil || len(auction.GetReward().GetItems()) == 0 {
+		return nil
+	}
+	itemID := ""
+	sale := &auctionSale{
+		Currencies:  auction.Bid.Bid.Currencies,
+		SaleTimeSec: currentTime,
+	}
+	for _, item := range auction.Reward.Items {
+		if itemID != "" && item.Id != itemID {
+			return nil
+		}
+		itemID = item.Id
+		sale.Count += max(item.Count, 1)
+	}
+
+	// Every sale of the item updates the same object, so hold its lock for the read-modify-write
+	return withStorageLock(ctx, nk, AuctionPriceHistoryCollectionKey+":"+itemID, func() error {
+		history, err := readAuctionPriceHistory(ctx, nk, itemID)
+		if err != nil {
+			return err
+		}
+		cutoff := currentTime - a.priceHistoryRetentionSec()
+		sales := make([]*auctionSale, 0, len(history.Sales)+1)
+		for _, recorded := range history.Sales {
+			if recorded.SaleTimeSec >= cutoff {
+				sales = append(sales, recorded)
+			}
+		}
+		sales = append(sales, sale)
+		if maxSales := a.priceHistoryMaxSalesPerItem(); len(sales) > maxSales {
+			sales = sales[len(sales)-maxSales:]
+		}
+		history.Sales = sales
+
+		data, err := json.Marshal(history)
+		if err != nil {
+			return err
+		}
+		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection:      AuctionPriceHistoryCollectionKey,
+				Key:             itemID,
+				Value:           string(data),
+				PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+				PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+			},
+		})
+		return err
+	})
+}
+
+func readAuctionPriceHistory(ctx context.Context, nk runtime.NakamaModule, itemID string) (*auctionPriceHistory, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: AuctionPriceHistoryCollectionKey, Key: itemID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	history := &auctionPriceHistory{}
+	if len(objects) == 0 {
+		return history, nil
+	}
+	if err := json.Unmarshal([]byte(objects[0].Value), history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetPriceHistory summarizes the prices an item sold for at auction over each window, by default the windows in the
+// price history config.
+func (a *AuctionsPamlogix) GetPriceHistory(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, itemID string, windowsSec []int64) (*AuctionPriceHistory, error) {
+	if itemID == "" || len(windowsSec) > maxAuctionPriceHistoryWindows {
+		return nil, ErrBadInput
+	}
+	for _, windowSec := range windowsSec {
+		if windowSec <= 0 {
+			return nil, ErrBadInput
+		}
+	}
+	if len(windowsSec) == 0 {
+		windowsSec = defaultAuctionPriceHistoryWindowsSec
+		if a.config.PriceHistory != nil && len(a.config.PriceHistory.WindowsSec) > 0 {
+			windowsSec = a.config.PriceHistory.WindowsSec
+		}
+	}
+
+	history, err := readAuctionPriceHistory(ctx, nk, itemID)
+	if err != nil {
+		logger.Error("Failed to read price history of item %s: %v", itemID, err)
+		return nil, ErrInternal
+	}
+
+	now := time.Now().Unix()
+	priceHistory := &AuctionPriceHistory{
+		ItemId:         itemID,
+		Windows:        make([]*AuctionPriceWindow, 0, len(windowsSec)),
+		CurrentTimeSec: now,
+	}
+	for _, windowSec := range windowsSec {
+		priceHistory.Windows = append(priceHistory.Windows, history.window(now, windowSec))
+	}
+	return priceHistory, nil
+}
+
+// window summarizes the sales within the window before now. Prices are per unit sold.
+func (h *auctionPriceHistory) window(now, windowSec int64) *AuctionPriceWindow {
+	window := &AuctionPriceWindow{WindowSec: windowSec}
+	unitPrices := make(map[string][]int64)
+	for _, sale := range h.Sales {
+		if sale.SaleTimeSec < now-windowSec || sale.Count <= 0 {
+			continue
+		}
+		window.Sales++
+		window.UnitsSold += sale.Count
+		for currency, amount := range sale.Currencies {
+			unitPrices[currency] = append(unitPrices[currency], amount/sale.Count)
+		}
+	}
+	if window.Sales == 0 {
+		return window
+	}
+
+	window.Median = make(map[string]int64, len(unitPrices))
+	window.Min = make(map[string]int64, len(unitPrices))
+	window.Max = make(map[string]int64, len(unitPrices))
+	for currency, prices := range unitPrices {
+		sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+		middle := len(prices) / 2
+		if len(prices)%2 == 0 {
+			window.Median[currency] = (prices[middle-1] + prices[middle]) / 2
+		} else {
+			window.Median[currency] = prices[middle]
+		}
+		window.Min[currency] = prices[0]
+		window.Max[currency] = prices[len(prices)-1]
+	}
+	return window
+}
@@ -0,0 +1,178 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// AuctionTeamBidsCollectionKey holds which bids on each auction were paid from a team's treasury.
+const AuctionTeamBidsCollectionKey = "auction_team_bids"
+
+// auctionTeamBidsAttempts is how many times a team bid is recorded when other team bids on the auction keep being
+// recorded first.
+const auctionTeamBidsAttempts = 5
+
+// auctionTeamBid is a bid paid from a team's treasury, told apart from the auction's other bids by its bidder and time.
+type auctionTeamBid struct {
+	UserId        string `json:"user_id"`
+	TeamId        string `json:"team_id"`
+	CreateTimeSec int64  `json:"create_time_sec"`
+}
+
+// readTeamBids returns the auction's team bids and the storage version they were read at, or storageLockVersionNone
+// when none were recorded yet.
+func (a *AuctionsPamlogix) readTeamBids(ctx context.Context, nk runtime.NakamaModule, auctionID string) ([]*auctionTeamBid, string, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: AuctionTeamBidsCollectionKey,
+			Key:        auctionID,
+			UserID:     "",
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(objects) == 0 {
+		return nil, storageLockVersionNone, nil
+	}
+
+	var teamBids []*auctionTeamBid
+	if err := json.Unmarshal([]byte(objects[0].Value), &teamBids); err != nil {
+		return nil, "", err
+	}
+	return teamBids, objects[0].Version, nil
+}
+
+// saveTeamBids writes the auction's team bids if they are still at the version they were read at.
+func (a *AuctionsPamlogix) saveTeamBids(ctx context.Context, nk runtime.NakamaModule, auctionID string, teamBids []*auctionTeamBid, version string) error {
+	data, err := json.Marshal(teamBids)
+	if err != nil {
+		return err
+	}
+
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      AuctionTeamBidsCollectionKey,
+			Key:             auctionID,
+			UserID:          "",
+			Value:           string(data),
+			Version:         version,
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+
+	return err
+}
+
+// bidTeam returns the team whose treasury paid for the bid, or "" if the bidder paid for it.
+func (a *AuctionsPamlogix) bidTeam(ctx context.Context, nk runtime.NakamaModule, auctionID string, bid *AuctionBid) (string, error) {
+	teamBids, _, err := a.readTeamBids(ctx, nk, auctionID)
+	if err != nil {
+		return "", err
+	}
+	for _, teamBid := range teamBids {
+		if teamBid.UserId == bid.UserId && teamBid.CreateTimeSec == bid.CreateTimeSec {
+			return teamBid.TeamId, nil
+		}
+	}
+	return "", nil
+}
+
+func (a *AuctionsPamlogix) teamsSystem() TeamsSystem {
+	if a.pamlogix == nil {
+		return nil
+	}
+	return a.pamlogix.GetTeamsSystem()
+}
+
+// chargeBid takes the bid from its bidder, or from the team's treasury when a team is given.
+func (a *AuctionsPamlogix) chargeBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctionID, teamID string, bid *AuctionBid) error {
+	if teamID == "" {
+		metadata := map[string]interface{}{
+			"source":     "auction_bid",
+			"auction_id": auctionID,
+		}
+		return chargeCost(ctx, logger, nk, a.pamlogix, bid.UserId, &Cost{Currencies: bid.Bid.Currencies}, metadata, false)
+	}
+
+	cost := &Cost{Currencies: bid.Bid.Currencies}
+	metadata := map[string]string{
+		"auction_id": auctionID,
+	}
+	if err := chargeTeamCost(ctx, logger, nk, a.pamlogix, bid.UserId, teamID, cost, "auction_bid", metadata); err != nil {
+		return err
+	}
+
+	if err := a.recordTeamBid(ctx, nk, auctionID, teamID, bid); err != nil {
+		// Without the record the bid would be refunded to the bidder, so it's returned to the treasury instead
+		logger.Error("Failed to record team bid on auction %s: %v", auctionID, err)
+		metadata["reason"] = "failed"
+		_ = refundTeamCost(ctx, logger, nk, a.pamlogix, bid.UserId, teamID, cost, "auction_bid_refund", metadata)
+		return ErrInternal
+	}
+	return nil
+}
+
+// recordTeamBid adds the bid to the auction's team bids. A rejected write means another team bid was recorded first,
+// and the team bids are read again.
+func (a *AuctionsPamlogix) recordTeamBid(ctx context.Context, nk runtime.NakamaModule, auctionID, teamID string, bid *AuctionBid) error {
+	var err error
+	for attempt := 0; attempt < auctionTeamBidsAttempts; attempt++ {
+		var teamBids []*auctionTeamBid
+		var version string
+		teamBids, version, err = a.readTeamBids(ctx, nk, auctionID)
+		if err != nil {
+			return err
+		}
+		for _, teamBid := range teamBids {
+			if teamBid.UserId == bid.UserId && teamBid.CreateTimeSec == bid.CreateTimeSec {
+				// Restored bids are charged again, but were recorded when they were first placed
+				return nil
+			}
+		}
+
+		teamBids = append(teamBids, &auctionTeamBid{UserId: bid.UserId, TeamId: teamID, CreateTimeSec: bid.CreateTimeSec})
+		err = a.saveTeamBids(ctx, nk, auctionID, teamBids, version)
+		if !errors.Is(err, runtime.ErrStorageRejectedVersion) {
+			return err
+		}
+	}
+	return err
+}
+
+// refundBid returns the currencies to whoever paid for the bid, its bidder or their team's treasury.
+func (a *AuctionsPamlogix) refundBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctionID string, bid *AuctionBid, currencies map[string]int64, reason string) error {
+	teamID, err := a.bidTeam(ctx, nk, auctionID, bid)
+	if err != nil {
+		logger.Error("Failed to read team bids of auction %s: %v", auctionID, err)
+		return err
+	}
+	if teamID == "" {
+		metadata := map[string]interface{}{
+			"source":     "auction_bid_return",
+			"reason":     reason,
+			"auction_id": auctionID,
+		}
+		return refundCost(ctx, logger, nk, a.pamlogix, bid.UserId, &Cost{Currencies: currencies}, metadata)
+	}
+
+	metadata := map[string]string{
+		"auction_id": auctionID,
+		"reason":     reason,
+	}
+	return refundTeamCost(ctx, logger, nk, a.pamlogix, bid.UserId, teamID, &Cost{Currencies: currencies}, "auction_bid_refund", metadata)
+}
+
+func (a *AuctionsPamlogix) TeamBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID, teamID, auctionID, version string, bid *AuctionBidAmount) (*Auction, error) {
+	if teamID == "" {
+		return nil, ErrBadInput
+	}
+	if a.teamsSystem() == nil {
+		return nil, ErrTeamTreasuryDisabled
+	}
+	return a.placeBid(ctx, logger, nk, userID, sessionID, teamID, auctionID, version, bid)
+}
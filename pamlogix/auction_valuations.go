@@ -0,0 +1,246 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	// AuctionValuationsCollectionKey holds the item valuations aggregated from the auction price history.
+	AuctionValuationsCollectionKey = "auction_valuations"
+	auctionValuationsKey           = "valuations"
+
+	defaultAuctionValuationWindowSec   = 7 * 24 * 60 * 60
+	defaultAuctionValuationMinSales    = 3
+	defaultAuctionValuationIntervalSec = 60 * 60
+)
+
+// auctionValuation is the value of one unit of an item.
+type auctionValuation struct {
+	Currencies map[string]int64 `json:"currencies"`
+	// Sales is how many recent sales the value is the average of. Zero means it's the item's store price.
+	Sales int `json:"sales,omitempty"`
+}
+
+// auctionValuations is the value of each item with recent sales or a store price, as of the last aggregation.
+type auctionValuations struct {
+	Items         map[string]*auctionValuation `json:"items"`
+	UpdateTimeSec int64                        `json:"update_time_sec"`
+}
+
+func (c *AuctionsConfigValuation) windowSec() int64 {
+	if c.WindowSec > 0 {
+		return c.WindowSec
+	}
+	return defaultAuctionValuationWindowSec
+}
+
+func (c *AuctionsConfigValuation) minSales() int {
+	if c.MinSales > 0 {
+		return c.MinSales
+	}
+	return defaultAuctionValuationMinSales
+}
+
+// aggregateValuations averages the recent sales in each item's price history into its valuation. Items without enough
+// recent sales are valued at their store price, when a store item sells them on their own.
+func (a *AuctionsPamlogix) aggregateValuations(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (*auctionValuations, error) {
+	valuationConfig := a.config.Valuation
+	if valuationConfig == nil {
+		return nil, nil
+	}
+	now := time.Now().Unix()
+	cutoff := now - valuationConfig.windowSec()
+
+	valuations := &auctionValuations{
+		Items:         a.storeValuations(),
+		UpdateTimeSec: now,
+	}
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageList(ctx, "", "", AuctionPriceHistoryCollectionKey, 100, cursor)
+		if err != nil {
+			logger.Error("Failed to list auction price history: %v", err)
+			return nil, ErrInternal
+		}
+		for _, obj := range objects {
+			var history auctionPriceHistory
+			if err := json.Unmarshal([]byte(obj.Value), &history); err != nil {
+				logger.Error("Failed to unmarshal price history of item %s: %v", obj.Key, err)
+				continue
+			}
+			if valuation := history.valuation(cutoff, valuationConfig.minSales()); valuation != nil {
+				valuations.Items[obj.Key] = valuation
+			}
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	data, err := json.Marshal(valuations)
+	if err != nil {
+		logger.Error("Failed to marshal auction valuations: %v", err)
+		return nil, ErrInternal
+	}
+	if _, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      AuctionValuationsCollectionKey,
+			Key:             auctionValuationsKey,
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	}); err != nil {
+		logger.Error("Failed to write auction valuations: %v", err)
+		return nil, ErrInternal
+	}
+	return valuations, nil
+}
+
+// valuation is the average price paid per unit in the sales since the cutoff, so larger sales weigh more, or nil with
+// fewer than the minimum sales.
+func (h *auctionPriceHistory) valuation(cutoff int64, minSales int) *auctionValuation {
+	var count int64
+	currencies := make(map[string]int64)
+	sales := 0
+	for _, sale := range h.Sales {
+		if sale.SaleTimeSec < cutoff || sale.Count <= 0 {
+			continue
+		}
+		sales++
+		count += sale.Count
+		for currency, amount := range sale.Currencies {
+			currencies[currency] += amount
+		}
+	}
+	if sales < minSales {
+		return nil
+	}
+	valuation := &auctionValuation{Currencies: make(map[string]int64, len(currencies)), Sales: sales}
+	for currency, amount := range currencies {
+		valuation.Currencies[currency] = amount / count
+	}
+	return valuation
+}
+
+// storeValuations returns the per unit price of the items store items sell on their own for a fixed count. An item
+// sold by several store items takes the price of the first by store item ID.
+func (a *AuctionsPamlogix) storeValuations() map[string]*auctionValuation {
+	valuations := make(map[string]*auctionValuation)
+	if a.pamlogix == nil {
+		return valuations
+	}
+	economySystem := a.pamlogix.GetEconomySystem()
+	if economySystem == nil {
+		return valuations
+	}
+	economyConfig, ok := economySystem.GetConfig().(*EconomyConfig)
+	if !ok || economyConfig == nil {
+		return valuations
+	}
+
+	storeItemIDs := make([]string, 0, len(economyConfig.StoreItems))
+	for storeItemID := range economyConfig.StoreItems {
+		storeItemIDs = append(storeItemIDs, storeItemID)
+	}
+	sort.Strings(storeItemIDs)
+	for _, storeItemID := range storeItemIDs {
+		storeItem := economyConfig.StoreItems[storeItemID]
+		if storeItem == nil || storeItem.Disabled || storeItem.Cost == nil || len(storeItem.Cost.Currencies) == 0 || storeItem.Reward == nil {
+			continue
+		}
+		guaranteed := storeItem.Reward.Guaranteed
+		if guaranteed == nil || len(storeItem.Reward.Weighted) > 0 || len(guaranteed.Items) != 1 || len(guaranteed.ItemSets) > 0 || len(guaranteed.Currencies) > 0 {
+			continue
+		}
+		for itemID, item := range guaranteed.Items {
+			if item == nil || item.Min <= 0 || (item.Max != 0 && item.Max != item.Min) {
+				continue
+			}
+			if _, found := valuations[itemID]; found {
+				continue
+			}
+			valuation := &auctionValuation{Currencies: make(map[string]int64, len(storeItem.Cost.Currencies))}
+			for currency, amount := range storeItem.Cost.Currencies {
+				valuation.Currencies[currency] = amount / item.Min
+			}
+			valuations[itemID] = valuation
+		}
+	}
+	return valuations
+}
+
+// readValuations returns the valuations saved by the last aggregation, or nil before the first one.
+func (a *AuctionsPamlogix) readValuations(ctx context.Context, nk runtime.NakamaModule) (*auctionValuations, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: AuctionValuationsCollectionKey, Key: auctionValuationsKey},
+	})
+	if err != nil || len(objects) == 0 {
+		return nil, err
+	}
+	valuations := &auctionValuations{}
+	if err := json.Unmarshal([]byte(objects[0].Value), valuations); err != nil {
+		return nil, err
+	}
+	return valuations, nil
+}
+
+// estimateValues sets the estimated value of each auction whose items all have a valuation. Estimates are only shown
+// to players, and auctions are listed without them if the valuations can't be read.
+func (a *AuctionsPamlogix) estimateValues(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctions []*Auction) {
+	if a.config.Valuation == nil || len(auctions) == 0 {
+		return
+	}
+	valuations, err := a.readValuations(ctx, nk)
+	if err != nil {
+		logger.Warn("Failed to read auction valuations: %v", err)
+		return
+	}
+	if valuations == nil {
+		return
+	}
+	for _, auction := range auctions {
+		auction.EstimatedValue = valuations.estimate(auction.GetReward())
+	}
+}
+
+// estimate returns the value of the reward's items, or nil if any of them has no valuation.
+func (v *auctionValuations) estimate(reward *AuctionReward) *AuctionBidAmount {
+	if len(reward.GetItems()) == 0 {
+		return nil
+	}
+	value := &AuctionBidAmount{Currencies: make(map[string]int64)}
+	for _, item := range reward.Items {
+		valuation, found := v.Items[item.Id]
+		if !found {
+			return nil
+		}
+		for currency, amount := range valuation.Currencies {
+			value.Currencies[currency] += amount * max(item.Count, 1)
+		}
+	}
+	return value
+}
+
+// startValuations aggregates the auction valuations every interval for the life of the server.
+func (a *AuctionsPamlogix) startValuations(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) {
+	intervalSec := a.config.Valuation.IntervalSec
+	if intervalSec <= 0 {
+		intervalSec = defaultAuctionValuationIntervalSec
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		_, _ = a.aggregateValuations(ctx, logger, nk)
+		ticker := time.NewTicker(time.Duration(intervalSec) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			_, _ = a.aggregateValuations(ctx, logger, nk)
+		}
+	}()
+}
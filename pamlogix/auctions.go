@@ -8,31 +8,110 @@ import (
 )
 
 var (
-	ErrAuctionTemplateNotFound  = runtime.NewError("auction template not found", INVALID_ARGUMENT_ERROR_CODE)     // INVALID_ARGUMENT
-	ErrAuctionConditionNotFound = runtime.NewError("auction condition not found", INVALID_ARGUMENT_ERROR_CODE)    // INVALID_ARGUMENT
-	ErrAuctionItemsInvalid      = runtime.NewError("auction items invalid", INVALID_ARGUMENT_ERROR_CODE)          // INVALID_ARGUMENT
-	ErrAuctionNotFound          = runtime.NewError("auction not found", INVALID_ARGUMENT_ERROR_CODE)              // INVALID_ARGUMENT
-	ErrAuctionVersionMismatch   = runtime.NewError("auction version mismatch", INVALID_ARGUMENT_ERROR_CODE)       // INVALID_ARGUMENT
-	ErrAuctionOwnBid            = runtime.NewError("cannot bid on own auction", INVALID_ARGUMENT_ERROR_CODE)      // INVALID_ARGUMENT
-	ErrAuctionAlreadyBid        = runtime.NewError("already high bidder on auction", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
-	ErrAuctionNotStarted        = runtime.NewError("auction not started", INVALID_ARGUMENT_ERROR_CODE)            // INVALID_ARGUMENT
-	ErrAuctionEnded             = runtime.NewError("auction ended", INVALID_ARGUMENT_ERROR_CODE)                  // INVALID_ARGUMENT
-	ErrAuctionBidInsufficient   = runtime.NewError("auction bid insufficient", INVALID_ARGUMENT_ERROR_CODE)       // INVALID_ARGUMENT
-	ErrAuctionBidInvalid        = runtime.NewError("auction bid invalid", INVALID_ARGUMENT_ERROR_CODE)            // INVALID_ARGUMENT
-	ErrAuctionCannotClaim       = runtime.NewError("auction cannot be claimed", INVALID_ARGUMENT_ERROR_CODE)      // INVALID_ARGUMENT
-	ErrAuctionCannotCancel      = runtime.NewError("auction cannot be cancelled", INVALID_ARGUMENT_ERROR_CODE)    // INVALID_ARGUMENT
+	ErrAuctionTemplateNotFound  = runtime.NewError("auction template not found", INVALID_ARGUMENT_ERROR_CODE)       // INVALID_ARGUMENT
+	ErrAuctionConditionNotFound = runtime.NewError("auction condition not found", INVALID_ARGUMENT_ERROR_CODE)      // INVALID_ARGUMENT
+	ErrAuctionItemsInvalid      = runtime.NewError("auction items invalid", INVALID_ARGUMENT_ERROR_CODE)            // INVALID_ARGUMENT
+	ErrAuctionNotFound          = runtime.NewError("auction not found", INVALID_ARGUMENT_ERROR_CODE)                // INVALID_ARGUMENT
+	ErrAuctionVersionMismatch   = runtime.NewError("auction version mismatch", INVALID_ARGUMENT_ERROR_CODE)         // INVALID_ARGUMENT
+	ErrAuctionOwnBid            = runtime.NewError("cannot bid on own auction", INVALID_ARGUMENT_ERROR_CODE)        // INVALID_ARGUMENT
+	ErrAuctionAlreadyBid        = runtime.NewError("already high bidder on auction", INVALID_ARGUMENT_ERROR_CODE)   // INVALID_ARGUMENT
+	ErrAuctionNotStarted        = runtime.NewError("auction not started", INVALID_ARGUMENT_ERROR_CODE)              // INVALID_ARGUMENT
+	ErrAuctionEnded             = runtime.NewError("auction ended", INVALID_ARGUMENT_ERROR_CODE)                    // INVALID_ARGUMENT
+	ErrAuctionBidInsufficient   = runtime.NewError("auction bid insufficient", INVALID_ARGUMENT_ERROR_CODE)         // INVALID_ARGUMENT
+	ErrAuctionBidInvalid        = runtime.NewError("auction bid invalid", INVALID_ARGUMENT_ERROR_CODE)              // INVALID_ARGUMENT
+	ErrAuctionCannotClaim       = runtime.NewError("auction cannot be claimed", INVALID_ARGUMENT_ERROR_CODE)        // INVALID_ARGUMENT
+	ErrAuctionCannotCancel      = runtime.NewError("auction cannot be cancelled", INVALID_ARGUMENT_ERROR_CODE)      // INVALID_ARGUMENT
+	ErrAuctionReserveNotMet     = runtime.NewError("auction reserve not met", INVALID_ARGUMENT_ERROR_CODE)          // INVALID_ARGUMENT
+	ErrAuctionNoBuyout          = runtime.NewError("auction has no buyout", INVALID_ARGUMENT_ERROR_CODE)            // INVALID_ARGUMENT
+	ErrAuctionCannotRetract     = runtime.NewError("bid cannot be retracted", FAILED_PRECONDITION_ERROR_CODE)       // FAILED_PRECONDITION
+	ErrAuctionQueryInvalid      = runtime.NewError("invalid auction query", INVALID_ARGUMENT_ERROR_CODE)            // INVALID_ARGUMENT
+	ErrAuctionListingLimit      = runtime.NewError("auction listing limit reached", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	ErrAuctionListingCooldown   = runtime.NewError("auction listing on cooldown", FAILED_PRECONDITION_ERROR_CODE)   // FAILED_PRECONDITION
 )
 
 // AuctionsConfig is the data definition for the AuctionsSystem type.
 type AuctionsConfig struct {
 	Auctions map[string]*AuctionsConfigAuction `json:"auctions,omitempty"`
+
+	// ArchiveAfterSec is how long a settled auction is kept in the active collection before it is archived. Zero disables archival.
+	ArchiveAfterSec int64 `json:"archive_after_sec,omitempty"`
+	// HistoryMaxPerUser caps the number of archived auctions retained in each user's history.
+	HistoryMaxPerUser int `json:"history_max_per_user,omitempty"`
+	// ItemPropertyLimits validates the string properties of items listed in an auction, which are shown to other players.
+	ItemPropertyLimits *TextLimits `json:"item_property_limits,omitempty"`
+	// ListingLimits caps how many auctions each user can list. Auctions created by the server with an override config
+	// count towards the limits too.
+	ListingLimits *AuctionsConfigListingLimits `json:"listing_limits,omitempty"`
+	// Valuation shows an estimated value on listed auctions, helping players judge whether a bid is reasonable. Nil
+	// lists auctions without one.
+	Valuation *AuctionsConfigValuation `json:"valuation,omitempty"`
+	// PriceHistory sets how long the prices items sold for are kept. Nil keeps the defaults.
+	PriceHistory *AuctionsConfigPriceHistory `json:"price_history,omitempty"`
+	// FeeSink credits the fees taken from auction proceeds to an account. Nil only tallies them in the fee ledger.
+	FeeSink *AuctionsConfigFeeSink `json:"fee_sink,omitempty"`
+	// BidderCacheSec is how long the display names and avatars of bidders are cached for listings that ask for them.
+	// The default is 60 seconds.
+	BidderCacheSec int64 `json:"bidder_cache_sec,omitempty"`
+	// SearchIndex registers a storage index over listed auctions, so filtered listings don't read every auction. Nil
+	// filters the auction index in memory.
+	SearchIndex *AuctionsConfigSearchIndex `json:"search_index,omitempty"`
+}
+
+// AuctionsConfigSearchIndex configures the storage index over listed auctions.
+type AuctionsConfigSearchIndex struct {
+	// MaxEntries is the most auctions kept in the index. The default is 100,000.
+	MaxEntries int `json:"max_entries,omitempty"`
+}
+
+// AuctionsConfigFeeSink sets the account the fees taken from auction proceeds are credited to. The fees are tallied in
+// the fee ledger either way.
+type AuctionsConfigFeeSink struct {
+	// UserId is the account credited with the fees, such as a treasury account the game's economy is balanced from.
+	UserId string `json:"user_id,omitempty"`
+}
+
+// AuctionsConfigPriceHistory sets how the price history of the items sold at auction is kept and summarized.
+type AuctionsConfigPriceHistory struct {
+	// RetentionSec is how long each sale is kept. The default is 30 days, and valuations can't look back further.
+	RetentionSec int64 `json:"retention_sec,omitempty"`
+	// MaxSalesPerItem caps the sales kept for each item, dropping the oldest first. The default is 500.
+	MaxSalesPerItem int `json:"max_sales_per_item,omitempty"`
+	// WindowsSec are the windows the price history is summarized over when a request names none. The default is a
+	// day, a week and 30 days.
+	WindowsSec []int64 `json:"windows_sec,omitempty"`
+}
+
+// AuctionsConfigValuation values auctioned items by the average price they recently sold for at auction, falling back
+// to the price of a store item selling them on their own. Sales are aggregated by a job on every server.
+type AuctionsConfigValuation struct {
+	// WindowSec is how far back sales count. The default is a week.
+	WindowSec int64 `json:"window_sec,omitempty"`
+	// MinSales is how many recent sales an item needs before they're used over its store price. The default is three.
+	MinSales int `json:"min_sales,omitempty"`
+	// IntervalSec is how often the sales are aggregated. The default is an hour.
+	IntervalSec int64 `json:"interval_sec,omitempty"`
+}
+
+// AuctionsConfigListingLimits caps the auctions a user lists. Zero disables a limit.
+type AuctionsConfigListingLimits struct {
+	// MaxActive is how many of the user's auctions can run at once. An auction stops counting once it ends or is
+	// cancelled, even before it's claimed.
+	MaxActive int `json:"max_active,omitempty"`
+	// CooldownSec is how long a user waits between listing auctions.
+	CooldownSec int64 `json:"cooldown_sec,omitempty"`
+	// MaxPerDay is how many auctions a user can list in any 24 hours, cancelled ones included.
+	MaxPerDay int `json:"max_per_day,omitempty"`
 }
 
 type AuctionsConfigAuction struct {
+	// Category groups templates so clients can page through one kind at a time, e.g. "weapons".
+	Category        string                                     `json:"category,omitempty"`
 	Items           []string                                   `json:"items,omitempty"`
 	ItemSets        []string                                   `json:"item_sets,omitempty"`
 	Conditions      map[string]*AuctionsConfigAuctionCondition `json:"conditions,omitempty"`
 	BidHistoryCount int                                        `json:"bid_history_count,omitempty"`
+
+	AllowedItems map[string]bool `json:"-"` // Auto-computed from Items when the config is read.
 }
 
 type AuctionsConfigAuctionCondition struct {
@@ -44,14 +123,28 @@ type AuctionsConfigAuctionCondition struct {
 	ExtensionSec          int64                                       `json:"extension_sec,omitempty"`
 	ExtensionMaxSec       int64                                       `json:"extension_max_sec,omitempty"`
 	Fee                   *AuctionsConfigAuctionConditionFee          `json:"fee,omitempty"`
+	// ReservePrice is the lowest final bid the auction sells for. It is never shown to bidders, who only see whether
+	// the current bid meets it; an auction ending below it is unsold, returning the items and refunding the bidder.
+	ReservePrice *AuctionsConfigAuctionConditionBid `json:"reserve_price,omitempty"`
+	// Buyout is the price the auction can be bought for straight away, ending it. It is shown on the auction and
+	// withdrawn once bids reach it. A buyout sells the auction whatever its reserve price.
+	Buyout *AuctionsConfigAuctionConditionBid `json:"buyout,omitempty"`
+	// BidRetraction lets bidders take back their bid shortly after placing it, for a penalty. Nil doesn't allow it.
+	BidRetraction *AuctionsConfigAuctionConditionBidRetraction `json:"bid_retraction,omitempty"`
 }
 
-type AuctionsConfigAuctionConditionCost struct {
-	Currencies map[string]int64 `json:"currencies,omitempty"`
-	Energies   map[string]int64 `json:"energies,omitempty"`
-	Items      map[string]int64 `json:"items,omitempty"`
+// AuctionsConfigAuctionConditionBidRetraction sets when and at what cost a bid can be retracted.
+type AuctionsConfigAuctionConditionBidRetraction struct {
+	// WindowSec is how long after placing a bid the bidder can retract it. Bids can't be retracted once the auction is
+	// within its extension threshold of ending, so retractions can't be used to snipe.
+	WindowSec int64 `json:"window_sec,omitempty"`
+	// Penalty is kept from the refunded bid, as a percentage of it and a fixed amount, and is never more than the bid.
+	// It's credited to the fee sink when one is set.
+	Penalty *AuctionsConfigAuctionConditionFee `json:"penalty,omitempty"`
 }
 
+type AuctionsConfigAuctionConditionCost = Cost
+
 type AuctionsConfigAuctionConditionBid struct {
 	Currencies map[string]int64 `json:"currencies,omitempty"`
 }
@@ -66,6 +159,152 @@ type AuctionsConfigAuctionConditionFee struct {
 	Fixed      *AuctionsConfigAuctionConditionBid `json:"fixed,omitempty"`
 }
 
+// AuctionHistoryEntry is a single record in a user's archived auction history index.
+type AuctionHistoryEntry struct {
+	AuctionId      string `json:"auction_id"`
+	ArchiveTimeSec int64  `json:"archive_time_sec"`
+}
+
+// AuctionListHistoryRequest is the request payload to list a user's archived auctions.
+type AuctionListHistoryRequest struct {
+	Limit  int64  `json:"limit,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+	// IncludeBidders fetches the display name and avatar of each bidder in the returned auctions.
+	IncludeBidders bool `json:"include_bidders,omitempty"`
+}
+
+// AuctionBuyoutRequest is the request payload to buy an auction out at its buyout price.
+type AuctionBuyoutRequest struct {
+	Id string `json:"id"`
+	// Version is the last seen version of the auction.
+	Version string `json:"version"`
+}
+
+// AuctionTeamBidRequest is the request payload to bid on an auction on behalf of the user's team.
+type AuctionTeamBidRequest struct {
+	Id string `json:"id"`
+	// Version is the last seen version of the auction.
+	Version string            `json:"version"`
+	TeamId  string            `json:"team_id"`
+	Bid     *AuctionBidAmount `json:"bid"`
+}
+
+// AuctionRetractBidRequest is the request payload to retract the user's high bid on an auction.
+type AuctionRetractBidRequest struct {
+	Id string `json:"id"`
+	// Version is the last seen version of the auction.
+	Version string `json:"version"`
+}
+
+// AuctionPriceHistoryRequest is the request payload to summarize the prices an item sold for at auction.
+type AuctionPriceHistoryRequest struct {
+	ItemId     string  `json:"item_id"`
+	WindowsSec []int64 `json:"windows_sec,omitempty"`
+}
+
+// AuctionPriceHistory summarizes the prices an item sold for at auction over one or more windows.
+type AuctionPriceHistory struct {
+	ItemId         string                `json:"item_id"`
+	Windows        []*AuctionPriceWindow `json:"windows"`
+	CurrentTimeSec int64                 `json:"current_time_sec"`
+}
+
+// AuctionPriceWindow summarizes the sales of an item within a window. Prices are per unit sold, by currency, and are
+// left out when the item didn't sell in the window.
+type AuctionPriceWindow struct {
+	WindowSec int64            `json:"window_sec"`
+	Sales     int              `json:"sales"`
+	UnitsSold int64            `json:"units_sold"`
+	Median    map[string]int64 `json:"median,omitempty"`
+	Min       map[string]int64 `json:"min,omitempty"`
+	Max       map[string]int64 `json:"max,omitempty"`
+}
+
+// AuctionClaimOutcome is the result of a single claim attempted as part of a batch claim.
+type AuctionClaimOutcome struct {
+	AuctionId string `json:"auction_id"`
+	Claimed   bool   `json:"claimed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// AuctionClaimAllBids is the consolidated result of claiming every won auction for a user.
+type AuctionClaimAllBids struct {
+	Claims   []*AuctionClaimBid     `json:"claims"`
+	Outcomes []*AuctionClaimOutcome `json:"outcomes"`
+}
+
+// AuctionClaimAllCreated is the consolidated result of claiming every ended auction a user created.
+type AuctionClaimAllCreated struct {
+	Claims   []*AuctionClaimCreated `json:"claims"`
+	Outcomes []*AuctionClaimOutcome `json:"outcomes"`
+}
+
+// AuctionListingAllowance is how many more auctions a user can list under the configured listing limits. Remaining
+// counts are -1 when there is no limit.
+type AuctionListingAllowance struct {
+	ActiveListings          int   `json:"active_listings"`
+	RemainingActiveListings int   `json:"remaining_active_listings"`
+	DailyListings           int   `json:"daily_listings"`
+	RemainingDailyListings  int   `json:"remaining_daily_listings"`
+	NextListingTimeSec      int64 `json:"next_listing_time_sec,omitempty"`
+	CanCreate               bool  `json:"can_create"`
+}
+
+// AuctionTemplatesDetails is the auction templates together with the user's remaining listing allowance, which is
+// left out when no listing limits are configured.
+type AuctionTemplatesDetails struct {
+	*AuctionTemplates
+	Allowance *AuctionListingAllowance `json:"allowance,omitempty"`
+}
+
+// AuctionTemplatesRequest is the request payload to page through the auction templates, optionally of one category.
+type AuctionTemplatesRequest struct {
+	Category string `json:"category,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	Cursor   string `json:"cursor,omitempty"`
+}
+
+// AuctionTemplateConditionSummary is what a player needs to pick between the conditions of a template. The bid
+// increment, fee and extension rules are left to the template details.
+type AuctionTemplateConditionSummary struct {
+	DurationSec int64                                `json:"duration_sec,omitempty"`
+	ListingCost *AuctionTemplateConditionListingCost `json:"listing_cost,omitempty"`
+	BidStart    *AuctionBidAmount                    `json:"bid_start,omitempty"`
+}
+
+// AuctionTemplateSummary is an auction template as listed, with only a summary of its conditions.
+type AuctionTemplateSummary struct {
+	Id         string                                      `json:"id"`
+	Category   string                                      `json:"category,omitempty"`
+	Items      []string                                    `json:"items,omitempty"`
+	ItemSets   []string                                    `json:"item_sets,omitempty"`
+	Conditions map[string]*AuctionTemplateConditionSummary `json:"conditions,omitempty"`
+}
+
+// AuctionTemplateList is one page of auction template summaries, ordered by template ID, with the user's remaining
+// listing allowance.
+type AuctionTemplateList struct {
+	Templates []*AuctionTemplateSummary `json:"templates"`
+	// Cursor is the next page's cursor, kept for one release. Use NextCursor.
+	Cursor     string                   `json:"cursor,omitempty"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+	PrevCursor string                   `json:"prev_cursor,omitempty"`
+	Allowance  *AuctionListingAllowance `json:"allowance,omitempty"`
+}
+
+// AuctionTemplateGetRequest is the request payload to get the details of one auction template.
+type AuctionTemplateGetRequest struct {
+	Id string `json:"id"`
+}
+
+// AuctionTemplateDetails is one auction template in full, with the user's remaining listing allowance.
+type AuctionTemplateDetails struct {
+	*AuctionTemplate
+	Id        string                   `json:"id"`
+	Category  string                   `json:"category,omitempty"`
+	Allowance *AuctionListingAllowance `json:"allowance,omitempty"`
+}
+
 type OnAuctionReward[T any] func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sourceID string, source *Auction, reward T) (T, error)
 
 // The AuctionsSystem provides a gameplay system for Auctions and their listing, bidding, and timers.
@@ -74,25 +313,60 @@ type OnAuctionReward[T any] func(ctx context.Context, logger runtime.Logger, nk
 type AuctionsSystem interface {
 	System
 
-	// GetTemplates lists all available auction configurations that can be used to create auction listings.
-	GetTemplates(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AuctionTemplates, error)
+	// GetTemplates lists all available auction configurations that can be used to create auction listings, and how many
+	// more auctions the user can list.
+	GetTemplates(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AuctionTemplatesDetails, error)
 
-	// List auctions based on provided criteria.
+	// ListTemplates pages through summaries of the auction templates, optionally only those of one category.
+	ListTemplates(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, category string, limit int, cursor string) (*AuctionTemplateList, error)
+
+	// GetTemplate returns one auction template with the full details of its conditions.
+	GetTemplate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, templateID string) (*AuctionTemplateDetails, error)
+
+	// List auctions matching the query, in the order of the sort keys. The query holds "field:value" clauses separated
+	// by spaces, filtering by "item_id", "item_set", "seller", "ending_within" seconds, and the next bid's price in a
+	// "currency" between "min_price" and "max_price". Sort keys are "end_time_sec", "create_time_sec" and "price",
+	// descending when prefixed with "-"; sorting by price needs a currency in the query.
 	List(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, query string, sort []string, limit int, cursor string) (*AuctionList, error)
 
 	// Bid on an active auction.
 	Bid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID, auctionID, version string, bid *AuctionBidAmount, marshaler *protojson.MarshalOptions) (*Auction, error)
 
-	// ClaimBid claims a completed auction as the successful bidder.
+	// Buyout buys an active auction at its buyout price. The auction ends straight away with the buyer as the winning
+	// bidder, the current bidder is refunded, and the buyer can claim it. It returns ErrAuctionNoBuyout when the
+	// auction has no buyout, or bids have reached it.
+	Buyout(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID, auctionID, version string) (*Auction, error)
+
+	// RetractBid takes back the user's high bid, if the auction allows retractions and the bid is recent enough. The bid
+	// is refunded less the penalty, and the latest earlier bid whose bidder can still cover it becomes the high bid
+	// again. It returns ErrAuctionCannotRetract when the bid can't be retracted.
+	RetractBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID, auctionID, version string) (*Auction, error)
+
+	// TeamBid bids on an active auction on behalf of the user's team, paid from the team's treasury within the user's
+	// spend limits. Team bids which are outbid, retracted or end below the reserve are refunded to the treasury, and a
+	// winning team bid is claimed by the user who placed it.
+	TeamBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID, teamID, auctionID, version string, bid *AuctionBidAmount) (*Auction, error)
+
+	// ClaimBid claims a completed auction as the successful bidder. If the winning bid didn't meet the auction's reserve
+	// price the bid is refunded instead and ErrAuctionReserveNotMet is returned.
 	ClaimBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, auctionID string) (*AuctionClaimBid, error)
 
 	// ClaimCreated claims a completed auction as the auction creator.
 	ClaimCreated(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, auctionID string) (*AuctionClaimCreated, error)
 
+	// ClaimAllBids claims every ended auction the user has won and not yet claimed.
+	ClaimAllBids(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AuctionClaimAllBids, error)
+
+	// ClaimAllCreated claims every ended auction the user has created and not yet claimed.
+	ClaimAllCreated(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AuctionClaimAllCreated, error)
+
 	// Cancel an active auction before it reaches its scheduled end time.
 	Cancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, auctionID string) (*AuctionCancel, error)
 
-	// Create a new auction based on supplied parameters and available configuration.
+	// Create a new auction based on supplied parameters and available configuration. It returns ErrAuctionListingLimit
+	// or ErrAuctionListingCooldown when the user's listing limits don't allow another auction yet. Items listed by
+	// instance ID are moved from the user's inventory into escrow until the auction is cancelled or claimed, when they
+	// go back to the creator or on to the winner.
 	Create(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, templateID, conditionID string, instanceIDs []string, startTimeSec int64, items []*InventoryItem, overrideConfig *AuctionsConfigAuction) (*Auction, error)
 
 	// ListBids returns auctions the user has successfully bid on.
@@ -101,6 +375,21 @@ type AuctionsSystem interface {
 	// ListCreated returns auctions the user has created.
 	ListCreated(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, limit int, cursor string) (*AuctionList, error)
 
+	// ListHistory returns archived auctions the user created or won, most recently archived first.
+	ListHistory(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, limit int, cursor string) (*AuctionList, error)
+
+	// GetPriceHistory summarizes the prices an item sold for at auction over each window, with the median, lowest and
+	// highest price per unit. No windows summarizes the configured ones.
+	GetPriceHistory(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, itemID string, windowsSec []int64) (*AuctionPriceHistory, error)
+
+	// EnrichBidders sets the display name and avatar of every bidder in the auctions' bids and bid histories, which
+	// are otherwise left out to keep listings small.
+	EnrichBidders(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctions []*Auction) error
+
+	// ArchiveSettled moves settled auctions older than the configured archive age out of the active collection and
+	// returns the number of auctions archived. Intended to be called from a scheduled job.
+	ArchiveSettled(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error)
+
 	// Follow ensures users receive real-time updates for auctions they have an interest in.
 	Follow(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID string, auctionIDs []string) (*AuctionList, error)
 
@@ -2,43 +2,108 @@ package pamlogix
 
 import (
 	"context"
-	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/heroiclabs/nakama-common/runtime"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
-	AuctionCollectionKey  = "auctions"
-	AuctionIndexKey       = "auction_index"
-	AuctionBidsKey        = "auction_bids"
-	AuctionUserCreatedKey = "auction_user_created"
-	AuctionUserBidsKey    = "auction_user_bids"
+	AuctionCollectionKey        = "auctions"
+	AuctionArchiveCollectionKey = "auctions_archive"
+	AuctionReserveCollectionKey = "auction_reserves"
+	AuctionIndexKey             = "auction_index"
+	AuctionBidsKey              = "auction_bids"
+	AuctionUserCreatedKey       = "auction_user_created"
+	AuctionUserBidsKey          = "auction_user_bids"
+	AuctionUserHistoryKey       = "auction_user_history"
+	AuctionUserListingsKey      = "auction_user_listings"
+
+	defaultAuctionHistoryMaxPerUser = 100
+	// defaultAuctionArchiveIntervalSec is how often settled auctions are archived unless the base config schedules it.
+	defaultAuctionArchiveIntervalSec = 60 * 60
+	// auctionHistoryAttempts is how many times an archived auction is added to a user's history when other archives
+	// keep updating it first.
+	auctionHistoryAttempts = 5
+	// Template lists are paged so games with hundreds of templates don't send them all at once.
+	defaultAuctionTemplatesPageSize = 20
+	maxAuctionTemplatesPageSize     = 100
+	// auctionListingWindowSec is the rolling window of the daily listing limit.
+	auctionListingWindowSec = 24 * 60 * 60
+
+	auctionIndexLockName = AuctionCollectionKey + ":" + AuctionIndexKey
+
+	// auctionWonEvent is the publisher event sent when the winner of an auction claims it.
+	auctionWonEvent = "auction_won"
 )
 
 // AuctionsPamlogix implements the AuctionsSystem interface
 type AuctionsPamlogix struct {
-	config   *AuctionsConfig
-	pamlogix Pamlogix
+	config      *AuctionsConfig
+	pamlogix    Pamlogix
+	bidderCache *auctionBidderCache
 
 	onClaimBid           OnAuctionReward[*AuctionReward]
 	onClaimCreated       OnAuctionReward[*AuctionBidAmount]
 	onClaimCreatedFailed OnAuctionReward[*AuctionReward]
 	onCancel             OnAuctionReward[*AuctionReward]
+
+	// idGenerator makes auction IDs and versionGenerator versions auctions by their content, defaulting to random
+	// UUIDs and content hashes.
+	idGenerator      IDGeneratorFn
+	versionGenerator VersionGeneratorFn
+
+	// searchIndex is set once the storage index over listed auctions is registered.
+	searchIndex bool
 }
 
 // NewNakamaAuctionsSystem creates a new auctions system instance
 func NewNakamaAuctionsSystem(config *AuctionsConfig) AuctionsSystem {
+	// Pre-compute the items each template allows for fast lookup
+	if config != nil {
+		for _, auctionConfig := range config.Auctions {
+			if auctionConfig == nil || len(auctionConfig.Items) == 0 {
+				continue
+			}
+			auctionConfig.AllowedItems = make(map[string]bool, len(auctionConfig.Items))
+			for _, itemID := range auctionConfig.Items {
+				auctionConfig.AllowedItems[itemID] = true
+			}
+		}
+	}
+
 	return &AuctionsPamlogix{
-		config: config,
+		config:      config,
+		bidderCache: newAuctionBidderCache(),
 	}
 }
 
+// SetIDGenerator replaces how auction IDs are made, so tests can make them deterministic. A nil generator restores
+// random UUIDs.
+func (a *AuctionsPamlogix) SetIDGenerator(fn IDGeneratorFn) {
+	a.idGenerator = fn
+}
+
+// SetVersionGenerator replaces how auction versions are made from their content. A nil generator restores content
+// hashes.
+func (a *AuctionsPamlogix) SetVersionGenerator(fn VersionGeneratorFn) {
+	a.versionGenerator = fn
+}
+
+func (a *AuctionsPamlogix) newID() string {
+	if a.idGenerator != nil {
+		return a.idGenerator()
+	}
+	return newUUID()
+}
+
 // GetType returns the system type
 func (a *AuctionsPamlogix) GetType() SystemType {
 	return SystemTypeAuctions
@@ -54,86 +119,223 @@ func (a *AuctionsPamlogix) SetPamlogix(pl Pamlogix) {
 	a.pamlogix = pl
 }
 
-// GetTemplates lists all available auction configurations that can be used to create auction listings
-func (a *AuctionsPamlogix) GetTemplates(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AuctionTemplates, error) {
+// GetTemplates lists all available auction configurations that can be used to create auction listings, and how many
+// more auctions the user can list
+func (a *AuctionsPamlogix) GetTemplates(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AuctionTemplatesDetails, error) {
 	templates := &AuctionTemplates{
 		Templates: make(map[string]*AuctionTemplate),
 	}
+	for templateID, auctionConfig := range a.config.Auctions {
+		templates.Templates[templateID] = auctionTemplate(auctionConfig)
+	}
+
+	allowance, err := a.templateAllowance(ctx, logger, nk, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &AuctionTemplatesDetails{AuctionTemplates: templates, Allowance: allowance}, nil
+}
+
+// ListTemplates pages through summaries of the auction templates, ordered by template ID so cursors stay stable
+func (a *AuctionsPamlogix) ListTemplates(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, category string, limit int, cursor string) (*AuctionTemplateList, error) {
+	offset, err := decodeOffsetCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit, err = pageLimit(limit, defaultAuctionTemplatesPageSize, maxAuctionTemplatesPageSize)
+	if err != nil {
+		return nil, err
+	}
 
+	templateIDs := make([]string, 0, len(a.config.Auctions))
 	for templateID, auctionConfig := range a.config.Auctions {
-		template := &AuctionTemplate{
-			Items:           auctionConfig.Items,
-			ItemSets:        auctionConfig.ItemSets,
-			Conditions:      make(map[string]*AuctionTemplateCondition),
-			BidHistoryCount: int32(auctionConfig.BidHistoryCount),
-		}
-
-		for conditionID, condition := range auctionConfig.Conditions {
-			templateCondition := &AuctionTemplateCondition{
-				DurationSec:           condition.DurationSec,
-				ExtensionThresholdSec: condition.ExtensionThresholdSec,
-				ExtensionSec:          condition.ExtensionSec,
-				ExtensionMaxSec:       condition.ExtensionMaxSec,
-			}
+		if category == "" || auctionConfig.Category == category {
+			templateIDs = append(templateIDs, templateID)
+		}
+	}
+	sort.Strings(templateIDs)
 
-			if condition.ListingCost != nil {
-				templateCondition.ListingCost = &AuctionTemplateConditionListingCost{
-					Currencies: condition.ListingCost.Currencies,
-					Items:      condition.ListingCost.Items,
-					Energies:   condition.ListingCost.Energies,
-				}
-			}
+	start, end, nextCursor, prevCursor := offsetPage(offset, limit, len(templateIDs))
+	list := &AuctionTemplateList{
+		Templates:  make([]*AuctionTemplateSummary, 0, end-start),
+		Cursor:     nextCursor,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}
+	for _, templateID := range templateIDs[start:end] {
+		list.Templates = append(list.Templates, auctionTemplateSummary(templateID, a.config.Auctions[templateID]))
+	}
 
-			if condition.BidStart != nil {
-				templateCondition.BidStart = &AuctionBidAmount{
-					Currencies: condition.BidStart.Currencies,
-				}
-			}
+	allowance, err := a.templateAllowance(ctx, logger, nk, userID)
+	if err != nil {
+		return nil, err
+	}
+	list.Allowance = allowance
+	return list, nil
+}
 
-			if condition.BidIncrement != nil {
-				templateCondition.BidIncrement = &AuctionTemplateConditionBidIncrement{
-					Percentage: condition.BidIncrement.Percentage,
-				}
-				if condition.BidIncrement.Fixed != nil {
-					templateCondition.BidIncrement.Fixed = &AuctionBidAmount{
-						Currencies: condition.BidIncrement.Fixed.Currencies,
-					}
+// GetTemplate returns one auction template with the full details of its conditions
+func (a *AuctionsPamlogix) GetTemplate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, templateID string) (*AuctionTemplateDetails, error) {
+	auctionConfig, found := a.config.Auctions[templateID]
+	if !found {
+		return nil, ErrAuctionTemplateNotFound
+	}
+
+	allowance, err := a.templateAllowance(ctx, logger, nk, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &AuctionTemplateDetails{
+		AuctionTemplate: auctionTemplate(auctionConfig),
+		Id:              templateID,
+		Category:        auctionConfig.Category,
+		Allowance:       allowance,
+	}, nil
+}
+
+// templateAllowance returns the user's remaining listing allowance to show with the templates, or nil when no
+// listing limits are configured.
+func (a *AuctionsPamlogix) templateAllowance(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AuctionListingAllowance, error) {
+	if a.config.ListingLimits == nil {
+		return nil, nil
+	}
+	allowance, _, err := a.listingAllowance(ctx, logger, nk, userID, time.Now().Unix())
+	return allowance, err
+}
+
+// auctionTemplate converts an auction configuration to the template clients are shown, leaving out the reserve price.
+func auctionTemplate(auctionConfig *AuctionsConfigAuction) *AuctionTemplate {
+	template := &AuctionTemplate{
+		Items:           auctionConfig.Items,
+		ItemSets:        auctionConfig.ItemSets,
+		Conditions:      make(map[string]*AuctionTemplateCondition),
+		BidHistoryCount: int32(auctionConfig.BidHistoryCount),
+	}
+
+	for conditionID, condition := range auctionConfig.Conditions {
+		templateCondition := &AuctionTemplateCondition{
+			DurationSec:           condition.DurationSec,
+			ListingCost:           auctionTemplateListingCost(condition),
+			BidStart:              auctionTemplateBidStart(condition),
+			ExtensionThresholdSec: condition.ExtensionThresholdSec,
+			ExtensionSec:          condition.ExtensionSec,
+			ExtensionMaxSec:       condition.ExtensionMaxSec,
+		}
+
+		if condition.BidIncrement != nil {
+			templateCondition.BidIncrement = &AuctionTemplateConditionBidIncrement{
+				Percentage: condition.BidIncrement.Percentage,
+			}
+			if condition.BidIncrement.Fixed != nil {
+				templateCondition.BidIncrement.Fixed = &AuctionBidAmount{
+					Currencies: condition.BidIncrement.Fixed.Currencies,
 				}
 			}
+		}
 
-			if condition.Fee != nil {
-				templateCondition.Fee = &AuctionFee{
-					Percentage: condition.Fee.Percentage,
-				}
-				if condition.Fee.Fixed != nil {
-					templateCondition.Fee.Fixed = &AuctionBidAmount{
-						Currencies: condition.Fee.Fixed.Currencies,
-					}
+		if condition.Fee != nil {
+			templateCondition.Fee = &AuctionFee{
+				Percentage: condition.Fee.Percentage,
+			}
+			if condition.Fee.Fixed != nil {
+				templateCondition.Fee.Fixed = &AuctionBidAmount{
+					Currencies: condition.Fee.Fixed.Currencies,
 				}
 			}
+		}
+
+		template.Conditions[conditionID] = templateCondition
+	}
+
+	return template
+}
 
-			template.Conditions[conditionID] = templateCondition
+// auctionTemplateSummary converts an auction configuration to its entry in the template list.
+func auctionTemplateSummary(templateID string, auctionConfig *AuctionsConfigAuction) *AuctionTemplateSummary {
+	summary := &AuctionTemplateSummary{
+		Id:         templateID,
+		Category:   auctionConfig.Category,
+		Items:      auctionConfig.Items,
+		ItemSets:   auctionConfig.ItemSets,
+		Conditions: make(map[string]*AuctionTemplateConditionSummary, len(auctionConfig.Conditions)),
+	}
+	for conditionID, condition := range auctionConfig.Conditions {
+		summary.Conditions[conditionID] = &AuctionTemplateConditionSummary{
+			DurationSec: condition.DurationSec,
+			ListingCost: auctionTemplateListingCost(condition),
+			BidStart:    auctionTemplateBidStart(condition),
 		}
+	}
+	return summary
+}
 
-		templates.Templates[templateID] = template
+func auctionTemplateListingCost(condition *AuctionsConfigAuctionCondition) *AuctionTemplateConditionListingCost {
+	if condition.ListingCost == nil {
+		return nil
+	}
+	return &AuctionTemplateConditionListingCost{
+		Currencies: condition.ListingCost.Currencies,
+		Items:      condition.ListingCost.Items,
+		Energies:   condition.ListingCost.Energies,
 	}
+}
 
-	return templates, nil
+func auctionTemplateBidStart(condition *AuctionsConfigAuctionCondition) *AuctionBidAmount {
+	if condition.BidStart == nil {
+		return nil
+	}
+	return &AuctionBidAmount{
+		Currencies: condition.BidStart.Currencies,
+	}
 }
 
 // List auctions based on provided criteria
 func (a *AuctionsPamlogix) List(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, query string, sort []string, limit int, cursor string) (*AuctionList, error) {
 	// Parse cursor for pagination
-	var offset int
-	if cursor != "" {
-		var err error
-		offset, err = strconv.Atoi(cursor)
-		if err != nil {
-			return nil, ErrBadInput
-		}
+	offset, err := decodeOffsetCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	search, err := parseAuctionSearch(query, sort)
+	if err != nil {
+		return nil, err
+	}
+	if !search.empty() {
+		return a.search(ctx, logger, nk, userID, search, offset, limit)
+	}
+
+	auctionIDs, err := a.readAuctionIndex(ctx, logger, nk)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply pagination
+	start, end, nextCursor, prevCursor := offsetPage(offset, limit, len(auctionIDs))
+	auctions, err := a.readAuctions(ctx, logger, nk, auctionIDs[start:end])
+	if err != nil {
+		return nil, err
 	}
 
-	// Read auction index to get list of active auctions
+	// Update auction state based on current time
+	currentTime := time.Now().Unix()
+	for _, auction := range auctions {
+		a.updateAuctionState(auction, currentTime, userID)
+	}
+
+	a.estimateValues(ctx, logger, nk, auctions)
+
+	return &AuctionList{
+		Auctions:   auctions,
+		Cursor:     nextCursor,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}, nil
+}
+
+// readAuctionIndex returns the IDs of the listed auctions, sorted so cursors point at the same place from one page to
+// the next.
+func (a *AuctionsPamlogix) readAuctionIndex(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) ([]string, error) {
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
 			Collection: AuctionCollectionKey,
@@ -157,69 +359,51 @@ func (a *AuctionsPamlogix) List(ctx context.Context, logger runtime.Logger, nk r
 		for auctionID := range index {
 			auctionIDs = append(auctionIDs, auctionID)
 		}
+		slices.Sort(auctionIDs)
 	}
+	return auctionIDs, nil
+}
 
-	// Apply pagination
-	if offset >= len(auctionIDs) {
-		return &AuctionList{
-			Auctions: []*Auction{},
-			Cursor:   "",
-		}, nil
-	}
-
-	end := offset + limit
-	if end > len(auctionIDs) {
-		end = len(auctionIDs)
-	}
-
-	paginatedIDs := auctionIDs[offset:end]
-
-	// Read auction data
+// readAuctions reads the auctions with the given IDs, skipping any which no longer exist.
+func (a *AuctionsPamlogix) readAuctions(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctionIDs []string) ([]*Auction, error) {
 	var auctions []*Auction
-	if len(paginatedIDs) > 0 {
-		reads := make([]*runtime.StorageRead, len(paginatedIDs))
-		for i, auctionID := range paginatedIDs {
-			reads[i] = &runtime.StorageRead{
-				Collection: AuctionCollectionKey,
-				Key:        auctionID,
-				UserID:     "",
-			}
-		}
-
-		objects, err := nk.StorageRead(ctx, reads)
-		if err != nil {
-			logger.Error("Failed to read auctions: %v", err)
-			return nil, ErrInternal
-		}
-
-		currentTime := time.Now().Unix()
-		for _, obj := range objects {
-			var auction Auction
-			if err := json.Unmarshal([]byte(obj.Value), &auction); err != nil {
-				logger.Error("Failed to unmarshal auction %s: %v", obj.Key, err)
-				continue
-			}
+	if len(auctionIDs) == 0 {
+		return auctions, nil
+	}
 
-			// Update auction state based on current time
-			a.updateAuctionState(&auction, currentTime, userID)
-			auctions = append(auctions, &auction)
+	reads := make([]*runtime.StorageRead, len(auctionIDs))
+	for i, auctionID := range auctionIDs {
+		reads[i] = &runtime.StorageRead{
+			Collection: AuctionCollectionKey,
+			Key:        auctionID,
+			UserID:     "",
 		}
 	}
 
-	// Determine next cursor
-	var nextCursor string
-	if end < len(auctionIDs) {
-		nextCursor = strconv.Itoa(end)
+	objects, err := nk.StorageRead(ctx, reads)
+	if err != nil {
+		logger.Error("Failed to read auctions: %v", err)
+		return nil, ErrInternal
 	}
 
-	return &AuctionList{
-		Auctions: auctions,
-		Cursor:   nextCursor,
-	}, nil
+	for _, obj := range objects {
+		var auction Auction
+		if err := json.Unmarshal([]byte(obj.Value), &auction); err != nil {
+			logger.Error("Failed to unmarshal auction %s: %v", obj.Key, err)
+			continue
+		}
+		auctions = append(auctions, &auction)
+	}
+	return auctions, nil
 }
 
 // Bid on an active auction
 func (a *AuctionsPamlogix) Bid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID, auctionID, version string, bid *AuctionBidAmount, marshaler *protojson.MarshalOptions) (*Auction, error) {
+	return a.placeBid(ctx, logger, nk, userID, sessionID, "", auctionID, version, bid)
+}
+
+// placeBid bids on an active auction, paid by the user, or from the team's treasury when a team is given.
+func (a *AuctionsPamlogix) placeBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID, teamID, auctionID, version string, bid *AuctionBidAmount) (*Auction, error) {
 	// Read current auction state
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
@@ -247,6 +431,7 @@ func (a *AuctionsPamlogix) Bid(ctx context.Context, logger runtime.Logger, nk ru
 	if auction.Version != version {
 		return nil, ErrAuctionVersionMismatch
 	}
+	storageVersion := objects[0].Version
 
 	currentTime := time.Now().Unix()
 	a.updateAuctionState(&auction, currentTime, userID)
@@ -256,20 +441,40 @@ func (a *AuctionsPamlogix) Bid(ctx context.Context, logger runtime.Logger, nk ru
 		return nil, err
 	}
 
-	// Check if user has sufficient funds using the economy system
-	if err := a.checkUserFunds(ctx, logger, nk, userID, bid); err != nil {
-		return nil, err
+	// Check if user has sufficient funds using the economy system, the team's treasury checks its own when charged
+	if teamID == "" {
+		if err := a.checkUserFunds(ctx, logger, nk, userID, bid); err != nil {
+			return nil, err
+		}
 	}
 
 	// Process the bid
-	if err := a.processBid(ctx, logger, nk, &auction, userID, bid, currentTime, nil); err != nil {
+	outbid := auction.Bid
+	if err := a.processBid(ctx, logger, nk, &auction, userID, teamID, bid, currentTime, nil); err != nil {
 		return nil, err
 	}
+	a.extendAuction(&auction, currentTime)
 
-	// Save updated auction
-	if err := a.saveAuction(ctx, nk, &auction); err != nil {
+	// Once bids reach the buyout price it is withdrawn, as buying out would cost no more than outbidding
+	if auction.Buyout != nil && bidReachesAmount(bid, auction.Buyout) {
+		auction.Buyout = nil
+	}
+
+	// Bids only go up, so the reserve is read until a bid meets it and never again
+	if auction.ReserveNotMet {
+		reserve, err := a.readReserve(ctx, nk, auctionID)
+		if err != nil {
+			logger.Error("Failed to read reserve of auction %s: %v", auctionID, err)
+		} else {
+			auction.ReserveNotMet = !bidMeetsReserve(bid, reserve)
+		}
+	}
+
+	// The auction is saved only if no other bid was placed on it meanwhile, and otherwise the bid is undone
+	if err := a.saveAuctionVersion(ctx, nk, &auction, storageVersion); err != nil {
 		logger.Error("Failed to save auction after bid: %v", err)
-		return nil, ErrInternal
+		a.undoBid(ctx, logger, nk, auctionID, auction.Bid, outbid, "bid_failed")
+		return nil, ErrAuctionVersionMismatch
 	}
 
 	// Add to user's bid auctions index
@@ -287,9 +492,8 @@ func (a *AuctionsPamlogix) Bid(ctx context.Context, logger runtime.Logger, nk ru
 	return &auction, nil
 }
 
-// ClaimBid claims a completed auction as the successful bidder
-func (a *AuctionsPamlogix) ClaimBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, auctionID string) (*AuctionClaimBid, error) {
-	// Read auction
+// Buyout buys an active auction at its buyout price, ending it with the buyer as the winning bidder
+func (a *AuctionsPamlogix) Buyout(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sessionID, auctionID, version string) (*Auction, error) {
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
 			Collection: AuctionCollectionKey,
@@ -312,51 +516,96 @@ func (a *AuctionsPamlogix) ClaimBid(ctx context.Context, logger runtime.Logger,
 		return nil, ErrInternal
 	}
 
+	if auction.Version != version {
+		return nil, ErrAuctionVersionMismatch
+	}
+	storageVersion := objects[0].Version
+
 	currentTime := time.Now().Unix()
 	a.updateAuctionState(&auction, currentTime, userID)
 
-	// Validate claim
-	if !auction.HasEnded {
+	if auction.UserId == userID {
+		return nil, ErrAuctionOwnBid
+	}
+	if !auction.HasStarted {
 		return nil, ErrAuctionNotStarted
 	}
+	if auction.HasEnded {
+		return nil, ErrAuctionEnded
+	}
+	if auction.Buyout == nil {
+		return nil, ErrAuctionNoBuyout
+	}
+	buyout := auction.Buyout
 
-	if auction.Bid == nil || auction.Bid.UserId != userID {
-		return nil, ErrAuctionCannotClaim
+	// The buyer's own bid is refunded as part of the buyout, so only the difference is needed up front
+	required := buyout
+	if auction.Bid != nil && auction.Bid.UserId == userID {
+		required = &AuctionBidAmount{Currencies: make(map[string]int64, len(buyout.Currencies))}
+		for currency, amount := range buyout.Currencies {
+			required.Currencies[currency] = amount - auction.Bid.GetBid().GetCurrencies()[currency]
+		}
+	}
+	if err := a.checkUserFunds(ctx, logger, nk, userID, required); err != nil {
+		return nil, err
 	}
 
-	if auction.WinnerClaimSec > 0 {
-		return nil, ErrAuctionCannotClaim
+	// The buyout is placed as the winning bid, refunding the current bidder
+	outbid := auction.Bid
+	if err := a.processBid(ctx, logger, nk, &auction, userID, "", buyout, currentTime, nil); err != nil {
+		return nil, err
 	}
 
-	// Mark as claimed
-	auction.WinnerClaimSec = currentTime
-	auction.CanClaim = false
+	// End the auction now, sold whatever its reserve
+	auction.EndTimeSec = currentTime
+	auction.Buyout = nil
+	auction.BidNext = nil
+	auction.ReserveNotMet = false
+	a.updateAuctionState(&auction, currentTime, userID)
 
-	// Grant items to winner
-	reward := auction.Reward
-	if a.onClaimBid != nil {
-		customReward, err := a.onClaimBid(ctx, logger, nk, userID, auctionID, &auction, reward)
-		if err != nil {
-			logger.Error("Custom claim bid reward failed: %v", err)
-			return nil, err
-		}
-		reward = customReward
+	// The auction is saved only if nothing changed it meanwhile, and otherwise the buyout and the outbid refund are undone
+	if err := a.saveAuctionVersion(ctx, nk, &auction, storageVersion); err != nil {
+		logger.Error("Failed to save auction after buyout: %v", err)
+		a.undoBid(ctx, logger, nk, auctionID, auction.Bid, outbid, "buyout_failed")
+		return nil, ErrAuctionVersionMismatch
 	}
 
-	// Save updated auction
-	if err := a.saveAuction(ctx, nk, &auction); err != nil {
-		logger.Error("Failed to save auction after claim: %v", err)
-		return nil, ErrInternal
+	if err := a.addToUserBidsIndex(ctx, nk, userID, auctionID); err != nil {
+		logger.Error("Failed to add auction to user bids index: %v", err)
+		// Don't return error as the buyout was placed successfully
 	}
 
-	return &AuctionClaimBid{
-		Auction: &auction,
-		Reward:  reward,
-	}, nil
+	// Followers see the auction end with the buyout as its final bid
+	a.sendBidNotification(ctx, logger, nk, &auction, sessionID)
+
+	return &auction, nil
 }
 
-// ClaimCreated claims a completed auction as the auction creator
-func (a *AuctionsPamlogix) ClaimCreated(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, auctionID string) (*AuctionClaimCreated, error) {
+// undoBid returns a bid or buyout that couldn't be saved to whoever paid for it, and takes back the bid refunded to the
+// bidder it outbid, who leads the auction again.
+func (a *AuctionsPamlogix) undoBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctionID string, bid, outbid *AuctionBid, reason string) {
+	if err := a.refundBid(ctx, logger, nk, auctionID, bid, bid.Bid.Currencies, reason); err != nil {
+		logger.Error("Failed to return bid to user %s: %v", bid.UserId, err)
+	}
+	if outbid == nil {
+		return
+	}
+
+	teamID, err := a.bidTeam(ctx, nk, auctionID, outbid)
+	if err != nil {
+		logger.Error("Failed to read team bids of auction %s: %v", auctionID, err)
+		return
+	}
+	if err := a.chargeBid(ctx, logger, nk, auctionID, teamID, outbid); err != nil {
+		logger.Error("Failed to take back outbid refund from user %s: %v", outbid.UserId, err)
+	}
+	if err := a.addToUserBidsIndex(ctx, nk, outbid.UserId, auctionID); err != nil {
+		logger.Error("Failed to add auction back to outbid bidder's index: %v", err)
+	}
+}
+
+// ClaimBid claims a completed auction as the successful bidder
+func (a *AuctionsPamlogix) ClaimBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, auctionID string) (*AuctionClaimBid, error) {
 	// Read auction
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
@@ -384,24 +633,232 @@ func (a *AuctionsPamlogix) ClaimCreated(ctx context.Context, logger runtime.Logg
 	a.updateAuctionState(&auction, currentTime, userID)
 
 	// Validate claim
-	if auction.UserId != userID {
-		return nil, ErrAuctionCannotClaim
-	}
-
 	if !auction.HasEnded {
 		return nil, ErrAuctionNotStarted
 	}
 
-	if auction.OwnerClaimSec > 0 {
+	if auction.Bid == nil || auction.Bid.UserId != userID {
+		return nil, ErrAuctionCannotClaim
+	}
+
+	if auction.WinnerClaimSec > 0 {
 		return nil, ErrAuctionCannotClaim
 	}
 
+	if auction.ReserveNotMet {
+		if err := a.settleUnmetReserve(ctx, logger, nk, &auction, currentTime, userID); err != nil {
+			return nil, err
+		}
+		return nil, ErrAuctionReserveNotMet
+	}
+
 	// Mark as claimed
-	auction.OwnerClaimSec = currentTime
+	auction.WinnerClaimSec = currentTime
+	auction.CanClaim = false
+
+	// Grant items to winner
+	reward := auction.Reward
+	if a.onClaimBid != nil {
+		customReward, err := a.onClaimBid(ctx, logger, nk, userID, auctionID, &auction, reward)
+		if err != nil {
+			logger.Error("Custom claim bid reward failed: %v", err)
+			return nil, err
+		}
+		reward = customReward
+	}
+
+	// Save updated auction
+	if err := a.saveAuction(ctx, nk, &auction); err != nil {
+		logger.Error("Failed to save auction after claim: %v", err)
+		return nil, ErrInternal
+	}
+	logEconomyEvents(ctx, logger, nk, a.pamlogix, auctionSettlementEvent(userID, &auction, "winner", auctionBidCurrencies(auction.GetBid().GetBid()), auctionRewardItems(reward), nil))
+	if err := a.recordSale(ctx, nk, &auction, currentTime); err != nil {
+		logger.Warn("Failed to record sale of auction %s: %v", auctionID, err)
+	}
+	if a.pamlogix != nil {
+		a.pamlogix.SendPublisherEvents(ctx, logger, nk, userID, []*PublisherEvent{{
+			Name:      auctionWonEvent,
+			Id:        auctionID,
+			Timestamp: currentTime,
+			System:    a,
+			SourceId:  auctionID,
+			Source:    &auction,
+		}})
+	}
+
+	// Items listed from the creator's inventory move from escrow to the winner's inventory as they are. Other items
+	// configured as unlockables, such as crates, go into the winner's unlock queue instead of the reward. This runs once
+	// the claim is saved so a failed claim can't hand out items that a retry would hand out again.
+	if _, escrowed := a.releaseEscrow(ctx, logger, nk, auctionID, userID); !escrowed {
+		reward = a.routeRewardToUnlockables(ctx, logger, nk, userID, reward)
+	}
+
+	return &AuctionClaimBid{
+		Auction: &auction,
+		Reward:  reward,
+	}, nil
+}
+
+// auctionSettlementEvent is the economy event logged when a side of an auction claims it. The winner's event carries
+// the winning bid and the items won, the creator's event the proceeds received and any items returned, and the fee
+// sink's event the fee credited to it.
+func auctionSettlementEvent(userID string, auction *Auction, role string, currencies, items, fee map[string]int64) *EconomyEvent {
+	metadata := map[string]interface{}{
+		"auction_id": auction.Id,
+		"role":       role,
+	}
+	if len(fee) > 0 {
+		metadata["fee"] = fee
+	}
+	return &EconomyEvent{
+		Type:       EconomyEventTypeSettlement,
+		UserId:     userID,
+		Currencies: currencies,
+		Items:      items,
+		Metadata:   metadata,
+	}
+}
+
+func auctionBidCurrencies(amount *AuctionBidAmount) map[string]int64 {
+	if amount == nil {
+		return nil
+	}
+	return amount.Currencies
+}
+
+func auctionRewardItems(reward *AuctionReward) map[string]int64 {
+	if reward == nil || len(reward.Items) == 0 {
+		return nil
+	}
+	items := make(map[string]int64, len(reward.Items))
+	for _, item := range reward.Items {
+		items[item.Id] += item.Count
+	}
+	return items
+}
+
+// routeRewardToUnlockables returns the reward without the items that were placed in the user's unlockables.
+func (a *AuctionsPamlogix) routeRewardToUnlockables(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *AuctionReward) *AuctionReward {
+	if reward == nil || len(reward.Items) == 0 {
+		return reward
+	}
+
+	items := make(map[string]int64, len(reward.Items))
+	for _, item := range reward.Items {
+		items[item.Id] += item.Count
+	}
+	remaining, err := routeItemsToUnlockables(ctx, logger, nk, a.pamlogix, userID, UnlockableGrantSourceAuction, items)
+	if err != nil {
+		logger.Error("Failed to place auction items in unlockables for user %s: %v", userID, err)
+		return reward
+	}
+
+	routed := make(map[string]int64, len(items))
+	for itemID, count := range items {
+		if routedCount := count - remaining[itemID]; routedCount > 0 {
+			routed[itemID] = routedCount
+		}
+	}
+	if len(routed) == 0 {
+		return reward
+	}
+
+	remainingReward := &AuctionReward{Items: make([]*InventoryItem, 0, len(reward.Items))}
+	for _, item := range reward.Items {
+		take := min(item.Count, routed[item.Id])
+		routed[item.Id] -= take
+		if item.Count-take <= 0 {
+			continue
+		}
+		remainingItem := proto.Clone(item).(*InventoryItem)
+		remainingItem.Count = item.Count - take
+		remainingReward.Items = append(remainingReward.Items, remainingItem)
+	}
+	return remainingReward
+}
+
+// inventorySystem returns the inventory system the auctions' items are escrowed in, or nil if there is none.
+func (a *AuctionsPamlogix) inventorySystem() InventorySystem {
+	if a.pamlogix == nil {
+		return nil
+	}
+	return a.pamlogix.GetInventorySystem()
+}
+
+// releaseEscrow moves the items escrowed for the auction into the user's inventory. It reports whether the auction's
+// items were escrowed at all; items which fail to move are logged and stay in escrow.
+func (a *AuctionsPamlogix) releaseEscrow(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctionID, userID string) ([]*InventoryItem, bool) {
+	inventorySystem := a.inventorySystem()
+	if inventorySystem == nil {
+		return nil, false
+	}
+	items, err := inventorySystem.UnlockItems(ctx, logger, nk, auctionID, userID)
+	if err != nil {
+		if errors.Is(err, ErrInventoryEscrowNotFound) {
+			return nil, false
+		}
+		logger.Error("Failed to release escrowed items of auction %s to user %s: %v", auctionID, userID, err)
+		return nil, true
+	}
+	return items, true
+}
+
+// ClaimCreated claims a completed auction as the auction creator
+func (a *AuctionsPamlogix) ClaimCreated(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, auctionID string) (*AuctionClaimCreated, error) {
+	// Read auction
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: AuctionCollectionKey,
+			Key:        auctionID,
+			UserID:     "",
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to read auction %s: %v", auctionID, err)
+		return nil, ErrInternal
+	}
+
+	if len(objects) == 0 {
+		return nil, ErrAuctionNotFound
+	}
+
+	var auction Auction
+	if err := json.Unmarshal([]byte(objects[0].Value), &auction); err != nil {
+		logger.Error("Failed to unmarshal auction %s: %v", auctionID, err)
+		return nil, ErrInternal
+	}
+
+	currentTime := time.Now().Unix()
+	a.updateAuctionState(&auction, currentTime, userID)
+
+	// Validate claim
+	if auction.UserId != userID {
+		return nil, ErrAuctionCannotClaim
+	}
+
+	if !auction.HasEnded {
+		return nil, ErrAuctionNotStarted
+	}
+
+	if auction.OwnerClaimSec > 0 {
+		return nil, ErrAuctionCannotClaim
+	}
+
+	// An auction ending below its reserve is unsold, so the bidder is refunded and the items go back to the creator
+	if auction.ReserveNotMet && auction.Bid != nil {
+		if err := a.settleUnmetReserve(ctx, logger, nk, &auction, currentTime, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Mark as claimed
+	auction.OwnerClaimSec = currentTime
 	auction.CanClaim = false
 
 	var reward *AuctionBidAmount
 	var fee *AuctionBidAmount
+	var proceeds *AuctionBidAmount
 	var returnedItems []*InventoryItem
 
 	if auction.Bid != nil {
@@ -417,6 +874,11 @@ func (a *AuctionsPamlogix) ClaimCreated(ctx context.Context, logger runtime.Logg
 			}
 			reward = customReward
 		}
+
+		proceeds, fee = auctionProceeds(reward, fee)
+		if err := a.creditProceeds(ctx, logger, nk, &auction, proceeds, fee); err != nil {
+			return nil, ErrInternal
+		}
 	} else {
 		// Failed auction - return items
 		returnedItems = auction.Reward.Items
@@ -434,17 +896,86 @@ func (a *AuctionsPamlogix) ClaimCreated(ctx context.Context, logger runtime.Logg
 	// Save updated auction
 	if err := a.saveAuction(ctx, nk, &auction); err != nil {
 		logger.Error("Failed to save auction after claim: %v", err)
+		a.reverseProceeds(ctx, logger, nk, &auction, proceeds, fee)
 		return nil, ErrInternal
 	}
+	if auction.Bid == nil {
+		// Unsold items listed from the creator's inventory go back to it
+		a.releaseEscrow(ctx, logger, nk, auctionID, userID)
+	}
+	logEconomyEvents(ctx, logger, nk, a.pamlogix, auctionSettlementEvent(userID, &auction, "creator", auctionBidCurrencies(proceeds), auctionRewardItems(&AuctionReward{Items: returnedItems}), auctionBidCurrencies(fee)))
+	if sinkUserID := a.feeSinkUserID(); sinkUserID != "" && len(fee.GetCurrencies()) > 0 {
+		logEconomyEvents(ctx, logger, nk, a.pamlogix, auctionSettlementEvent(sinkUserID, &auction, "fee_sink", fee.Currencies, nil, nil))
+	}
+	if err := a.recordFee(ctx, nk, fee, currentTime); err != nil {
+		logger.Warn("Failed to record fee of auction %s: %v", auctionID, err)
+	}
 
 	return &AuctionClaimCreated{
 		Auction:       &auction,
 		Reward:        reward,
 		Fee:           fee,
 		ReturnedItems: returnedItems,
+		Proceeds:      proceeds,
 	}, nil
 }
 
+// ClaimAllBids claims every ended auction the user has won and not yet claimed
+func (a *AuctionsPamlogix) ClaimAllBids(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AuctionClaimAllBids, error) {
+	auctions, err := a.readUserIndexAuctions(ctx, logger, nk, AuctionUserBidsKey, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AuctionClaimAllBids{
+		Claims:   make([]*AuctionClaimBid, 0),
+		Outcomes: make([]*AuctionClaimOutcome, 0),
+	}
+	for _, auction := range auctions {
+		if !auction.HasEnded || auction.Bid == nil || auction.Bid.UserId != userID || auction.WinnerClaimSec > 0 {
+			continue
+		}
+
+		claim, err := a.ClaimBid(ctx, logger, nk, userID, auction.Id)
+		result.Outcomes = append(result.Outcomes, auctionClaimOutcome(auction.Id, err))
+		if err != nil {
+			logger.Warn("Failed to claim auction %s for bidder %s: %v", auction.Id, userID, err)
+			continue
+		}
+		result.Claims = append(result.Claims, claim)
+	}
+
+	return result, nil
+}
+
+// ClaimAllCreated claims every ended auction the user has created and not yet claimed
+func (a *AuctionsPamlogix) ClaimAllCreated(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AuctionClaimAllCreated, error) {
+	auctions, err := a.readUserIndexAuctions(ctx, logger, nk, AuctionUserCreatedKey, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AuctionClaimAllCreated{
+		Claims:   make([]*AuctionClaimCreated, 0),
+		Outcomes: make([]*AuctionClaimOutcome, 0),
+	}
+	for _, auction := range auctions {
+		if !auction.HasEnded || auction.UserId != userID || auction.OwnerClaimSec > 0 {
+			continue
+		}
+
+		claim, err := a.ClaimCreated(ctx, logger, nk, userID, auction.Id)
+		result.Outcomes = append(result.Outcomes, auctionClaimOutcome(auction.Id, err))
+		if err != nil {
+			logger.Warn("Failed to claim auction %s for creator %s: %v", auction.Id, userID, err)
+			continue
+		}
+		result.Claims = append(result.Claims, claim)
+	}
+
+	return result, nil
+}
+
 // Cancel an active auction before it reaches its scheduled end time
 func (a *AuctionsPamlogix) Cancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, auctionID string) (*AuctionCancel, error) {
 	// Read auction
@@ -490,7 +1021,7 @@ func (a *AuctionsPamlogix) Cancel(ctx context.Context, logger runtime.Logger, nk
 
 	// Return bid to current bidder if any
 	if auction.Bid != nil {
-		if err := a.returnBidToUser(ctx, logger, nk, auction.Bid.UserId, auction.Bid.Bid); err != nil {
+		if err := a.refundBid(ctx, logger, nk, auction.Id, auction.Bid, auction.Bid.Bid.Currencies, "cancelled"); err != nil {
 			logger.Error("Failed to return bid to user %s when cancelling auction: %v", auction.Bid.UserId, err)
 			// Continue with cancellation despite error, but log it
 		} else {
@@ -520,6 +1051,9 @@ func (a *AuctionsPamlogix) Cancel(ctx context.Context, logger runtime.Logger, nk
 		return nil, ErrInternal
 	}
 
+	// Items listed from the creator's inventory go back to it
+	a.releaseEscrow(ctx, logger, nk, auctionID, userID)
+
 	// Remove from active auctions index
 	if err := a.removeFromIndex(ctx, nk, auctionID); err != nil {
 		logger.Error("Failed to remove auction from index: %v", err)
@@ -556,18 +1090,62 @@ func (a *AuctionsPamlogix) Create(ctx context.Context, logger runtime.Logger, nk
 		return nil, ErrAuctionConditionNotFound
 	}
 
-	// Validate items
-	if len(items) == 0 {
-		return nil, ErrAuctionItemsInvalid
+	// Check the user's listing limits before anything is charged
+	var listingTimes []int64
+	if a.config.ListingLimits != nil {
+		allowance, times, err := a.listingAllowance(ctx, logger, nk, userID, time.Now().Unix())
+		if err != nil {
+			return nil, err
+		}
+		if allowance.RemainingActiveListings == 0 || allowance.RemainingDailyListings == 0 {
+			return nil, ErrAuctionListingLimit
+		}
+		if allowance.NextListingTimeSec > 0 {
+			return nil, ErrAuctionListingCooldown
+		}
+		listingTimes = times
+	}
+
+	// Items listed from the user's inventory are moved into escrow under the auction's ID, so they can't be consumed or
+	// listed again while the auction runs
+	auctionID := a.newID()
+	escrowed := false
+	if len(items) == 0 && len(instanceIDs) > 0 {
+		inventorySystem := a.inventorySystem()
+		if inventorySystem == nil {
+			return nil, ErrAuctionItemsInvalid
+		}
+		lockedItems, err := inventorySystem.LockItems(ctx, logger, nk, userID, auctionID, instanceIDs)
+		if err != nil {
+			return nil, err
+		}
+		items = lockedItems
+		escrowed = true
 	}
 
 	// Validate items against template
 	if err := a.validateItems(items, config); err != nil {
+		if escrowed {
+			a.releaseEscrow(ctx, logger, nk, auctionID, userID)
+		}
 		return nil, err
 	}
 
+	// Listed item properties are visible to every bidder, so run them through text moderation
+	for _, item := range items {
+		for key, value := range item.StringProperties {
+			moderated, err := moderateText(ctx, logger, nk, a.pamlogix, userID, TextFieldAuctionItemProperty, value, a.config.ItemPropertyLimits)
+			if err != nil {
+				if escrowed {
+					a.releaseEscrow(ctx, logger, nk, auctionID, userID)
+				}
+				return nil, err
+			}
+			item.StringProperties[key] = moderated
+		}
+	}
+
 	// Create auction
-	auctionID := uuid.New().String()
 	currentTime := time.Now().Unix()
 
 	if startTimeSec == 0 {
@@ -580,7 +1158,6 @@ func (a *AuctionsPamlogix) Create(ctx context.Context, logger runtime.Logger, nk
 		Id:                    auctionID,
 		UserId:                userID,
 		Reward:                &AuctionReward{Items: items},
-		Version:               a.generateVersion(),
 		DurationSec:           condition.DurationSec,
 		OriginalDurationSec:   condition.DurationSec,
 		ExtensionThresholdSec: condition.ExtensionThresholdSec,
@@ -631,15 +1208,63 @@ func (a *AuctionsPamlogix) Create(ctx context.Context, logger runtime.Logger, nk
 		}
 	}
 
+	// The reserve price is stored apart from the auction so it is never sent to bidders
+	reserve := condition.ReservePrice
+	if reserve != nil && len(reserve.Currencies) == 0 {
+		reserve = nil
+	}
+	auction.ReserveNotMet = reserve != nil
+
+	if condition.Buyout != nil && len(condition.Buyout.Currencies) > 0 {
+		auction.Buyout = &AuctionBidAmount{Currencies: make(map[string]int64, len(condition.Buyout.Currencies))}
+		for currency, amount := range condition.Buyout.Currencies {
+			auction.Buyout.Currencies[currency] = amount
+		}
+	}
+
 	// Update state
 	a.updateAuctionState(auction, currentTime, userID)
 
+	// Charge the listing cost, giving it back if the auction can't be saved
+	listingMetadata := map[string]interface{}{
+		"source":     "auction_listing",
+		"auction_id": auctionID,
+	}
+	if err := chargeCost(ctx, logger, nk, a.pamlogix, userID, condition.ListingCost, listingMetadata, false); err != nil {
+		if escrowed {
+			a.releaseEscrow(ctx, logger, nk, auctionID, userID)
+		}
+		return nil, err
+	}
+
 	// Save auction
 	if err := a.saveAuction(ctx, nk, auction); err != nil {
 		logger.Error("Failed to save new auction: %v", err)
+		_ = refundCost(ctx, logger, nk, a.pamlogix, userID, condition.ListingCost, listingMetadata)
+		if escrowed {
+			a.releaseEscrow(ctx, logger, nk, auctionID, userID)
+		}
 		return nil, ErrInternal
 	}
 
+	if reserve != nil {
+		if err := a.saveReserve(ctx, nk, auctionID, reserve); err != nil {
+			logger.Error("Failed to save auction reserve: %v", err)
+			return nil, ErrInternal
+		}
+	}
+
+	if retraction := condition.BidRetraction; retraction != nil && retraction.WindowSec > 0 {
+		if err := a.saveBidRetraction(ctx, nk, auctionID, &auctionBidRetraction{
+			WindowSec: retraction.WindowSec,
+			Penalty:   retraction.Penalty,
+			BidStart:  proto.Clone(auction.BidNext).(*AuctionBidAmount),
+		}); err != nil {
+			logger.Error("Failed to save auction bid retraction: %v", err)
+			return nil, ErrInternal
+		}
+	}
+
 	// Add to index
 	if err := a.addToIndex(ctx, nk, auctionID); err != nil {
 		logger.Error("Failed to add auction to index: %v", err)
@@ -652,19 +1277,21 @@ func (a *AuctionsPamlogix) Create(ctx context.Context, logger runtime.Logger, nk
 		// Don't return error as the auction was created successfully
 	}
 
+	if a.config.ListingLimits != nil {
+		if err := a.writeListingTimes(ctx, nk, userID, append(listingTimes, currentTime)); err != nil {
+			logger.Error("Failed to record auction listing time: %v", err)
+		}
+	}
+
 	return auction, nil
 }
 
 // ListBids returns auctions the user has successfully bid on
 func (a *AuctionsPamlogix) ListBids(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, limit int, cursor string) (*AuctionList, error) {
 	// Parse cursor for pagination
-	var offset int
-	if cursor != "" {
-		var err error
-		offset, err = strconv.Atoi(cursor)
-		if err != nil {
-			return nil, ErrBadInput
-		}
+	offset, err := decodeOffsetCursor(cursor)
+	if err != nil {
+		return nil, err
 	}
 
 	// Read user's bid auctions index
@@ -692,22 +1319,13 @@ func (a *AuctionsPamlogix) ListBids(ctx context.Context, logger runtime.Logger,
 		for auctionID := range index {
 			auctionIDs = append(auctionIDs, auctionID)
 		}
+		// Sorted so cursors point at the same place from one page to the next
+		slices.Sort(auctionIDs)
 	}
 
 	// Apply pagination
-	if offset >= len(auctionIDs) {
-		return &AuctionList{
-			Auctions: []*Auction{},
-			Cursor:   "",
-		}, nil
-	}
-
-	end := offset + limit
-	if end > len(auctionIDs) {
-		end = len(auctionIDs)
-	}
-
-	paginatedIDs := auctionIDs[offset:end]
+	start, end, nextCursor, prevCursor := offsetPage(offset, limit, len(auctionIDs))
+	paginatedIDs := auctionIDs[start:end]
 
 	// Read auction data
 	var auctions []*Auction
@@ -741,28 +1359,22 @@ func (a *AuctionsPamlogix) ListBids(ctx context.Context, logger runtime.Logger,
 		}
 	}
 
-	// Determine next cursor
-	var nextCursor string
-	if end < len(auctionIDs) {
-		nextCursor = strconv.Itoa(end)
-	}
+	a.estimateValues(ctx, logger, nk, auctions)
 
 	return &AuctionList{
-		Auctions: auctions,
-		Cursor:   nextCursor,
+		Auctions:   auctions,
+		Cursor:     nextCursor,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
 	}, nil
 }
 
 // ListCreated returns auctions the user has created
 func (a *AuctionsPamlogix) ListCreated(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, limit int, cursor string) (*AuctionList, error) {
 	// Parse cursor for pagination
-	var offset int
-	if cursor != "" {
-		var err error
-		offset, err = strconv.Atoi(cursor)
-		if err != nil {
-			return nil, ErrBadInput
-		}
+	offset, err := decodeOffsetCursor(cursor)
+	if err != nil {
+		return nil, err
 	}
 
 	// Read user's created auctions index
@@ -790,22 +1402,13 @@ func (a *AuctionsPamlogix) ListCreated(ctx context.Context, logger runtime.Logge
 		for auctionID := range index {
 			auctionIDs = append(auctionIDs, auctionID)
 		}
+		// Sorted so cursors point at the same place from one page to the next
+		slices.Sort(auctionIDs)
 	}
 
 	// Apply pagination
-	if offset >= len(auctionIDs) {
-		return &AuctionList{
-			Auctions: []*Auction{},
-			Cursor:   "",
-		}, nil
-	}
-
-	end := offset + limit
-	if end > len(auctionIDs) {
-		end = len(auctionIDs)
-	}
-
-	paginatedIDs := auctionIDs[offset:end]
+	start, end, nextCursor, prevCursor := offsetPage(offset, limit, len(auctionIDs))
+	paginatedIDs := auctionIDs[start:end]
 
 	// Read auction data
 	var auctions []*Auction
@@ -839,15 +1442,13 @@ func (a *AuctionsPamlogix) ListCreated(ctx context.Context, logger runtime.Logge
 		}
 	}
 
-	// Determine next cursor
-	var nextCursor string
-	if end < len(auctionIDs) {
-		nextCursor = strconv.Itoa(end)
-	}
+	a.estimateValues(ctx, logger, nk, auctions)
 
 	return &AuctionList{
-		Auctions: auctions,
-		Cursor:   nextCursor,
+		Auctions:   auctions,
+		Cursor:     nextCursor,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
 	}, nil
 }
 
@@ -894,6 +1495,7 @@ func (a *AuctionsPamlogix) Follow(ctx context.Context, logger runtime.Logger, nk
 			logger.Info("User %s joined auction %s notification stream", userID, auction.Id)
 		}
 	}
+	a.estimateValues(ctx, logger, nk, auctions)
 
 	return &AuctionList{
 		Auctions: auctions,
@@ -901,209 +1503,144 @@ func (a *AuctionsPamlogix) Follow(ctx context.Context, logger runtime.Logger, nk
 	}, nil
 }
 
-// SetOnClaimBid sets a custom reward function which will run after an auction's reward is claimed by the winning bidder
-func (a *AuctionsPamlogix) SetOnClaimBid(fn OnAuctionReward[*AuctionReward]) {
-	a.onClaimBid = fn
-}
-
-// SetOnClaimCreated sets a custom reward function which will run after an auction's winning bid is claimed by the auction creator
-func (a *AuctionsPamlogix) SetOnClaimCreated(fn OnAuctionReward[*AuctionBidAmount]) {
-	a.onClaimCreated = fn
-}
-
-// SetOnClaimCreatedFailed sets a custom reward function which will run after a failed auction is claimed by the auction creator
-func (a *AuctionsPamlogix) SetOnClaimCreatedFailed(fn OnAuctionReward[*AuctionReward]) {
-	a.onClaimCreatedFailed = fn
-}
-
-// SetOnCancel sets a custom reward function which will run after an auction is cancelled by the auction creator
-func (a *AuctionsPamlogix) SetOnCancel(fn OnAuctionReward[*AuctionReward]) {
-	a.onCancel = fn
-}
-
-// Helper methods
-
-// checkUserFunds verifies that a user has sufficient currency to place a bid
-func (a *AuctionsPamlogix) checkUserFunds(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, bid *AuctionBidAmount) error {
-	if a.pamlogix == nil {
-		logger.Warn("Cannot check user funds: no Pamlogix instance available")
-		return ErrInternal
-	}
-
-	economySystem := a.pamlogix.GetEconomySystem()
-	if economySystem == nil {
-		logger.Warn("Cannot check user funds: no EconomySystem available")
-		return ErrInternal
-	}
-
-	// Get user's account to check wallet
-	account, err := nk.AccountGetId(ctx, userID)
+// ListHistory returns archived auctions the user created or won, most recently archived first
+func (a *AuctionsPamlogix) ListHistory(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, limit int, cursor string) (*AuctionList, error) {
+	// Parse cursor for pagination
+	offset, err := decodeOffsetCursor(cursor)
 	if err != nil {
-		logger.Error("Failed to get user account: %v", err)
-		return ErrInternal
+		return nil, err
 	}
 
-	// Get wallet from account
-	wallet, err := economySystem.UnmarshalWallet(account)
+	entries, _, err := a.readUserHistory(ctx, nk, userID)
 	if err != nil {
-		logger.Error("Failed to unmarshal wallet: %v", err)
-		return ErrInternal
+		logger.Error("Failed to read user auction history index: %v", err)
+		return nil, ErrInternal
 	}
 
-	// Check if user has enough of each required currency
-	for currencyID, amount := range bid.Currencies {
-		if wallet[currencyID] < amount {
-			logger.Debug("User %s does not have enough of currency %s (has %d, needs %d)", userID, currencyID, wallet[currencyID], amount)
-			return ErrCurrencyInsufficient
+	// Apply pagination
+	start, end, nextCursor, prevCursor := offsetPage(offset, limit, len(entries))
+	paginatedEntries := entries[start:end]
+
+	reads := make([]*runtime.StorageRead, len(paginatedEntries))
+	for i, entry := range paginatedEntries {
+		reads[i] = &runtime.StorageRead{
+			Collection: AuctionArchiveCollectionKey,
+			Key:        entry.AuctionId,
+			UserID:     userID,
 		}
 	}
 
-	return nil
-}
-
-// returnBidToUser returns currency to a user when they are outbid
-func (a *AuctionsPamlogix) returnBidToUser(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, bid *AuctionBidAmount) error {
-	if a.pamlogix == nil {
-		logger.Warn("Cannot return bid: no Pamlogix instance available")
-		return ErrInternal
-	}
-
-	economySystem := a.pamlogix.GetEconomySystem()
-	if economySystem == nil {
-		logger.Warn("Cannot return bid: no EconomySystem available")
-		return ErrInternal
-	}
-
-	// Grant the bid amount back to the user
-	metadata := map[string]interface{}{
-		"source": "auction_bid_return",
-		"reason": "outbid",
-	}
-
-	_, _, _, err := economySystem.Grant(ctx, logger, nk, userID, bid.Currencies, nil, nil, metadata)
+	objects, err := nk.StorageRead(ctx, reads)
 	if err != nil {
-		logger.Error("Failed to return bid currencies to user %s: %v", userID, err)
-		return err
-	}
-
-	logger.Info("Returned bid to user %s: %v", userID, bid.Currencies)
-	return nil
-}
-
-// deductBidFromUser deducts currency from a user when they place a bid
-func (a *AuctionsPamlogix) deductBidFromUser(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, bid *AuctionBidAmount) error {
-	if a.pamlogix == nil {
-		logger.Warn("Cannot deduct bid: no Pamlogix instance available")
-		return ErrInternal
-	}
-
-	economySystem := a.pamlogix.GetEconomySystem()
-	if economySystem == nil {
-		logger.Warn("Cannot deduct bid: no EconomySystem available")
-		return ErrInternal
+		logger.Error("Failed to read archived auctions: %v", err)
+		return nil, ErrInternal
 	}
 
-	// Convert to negative values for deduction
-	deductCurrencies := make(map[string]int64)
-	for currencyID, amount := range bid.Currencies {
-		deductCurrencies[currencyID] = -amount
-	}
+	// Storage reads are not returned in request order, so restore the history ordering
+	archived := make(map[string]*Auction, len(objects))
+	currentTime := time.Now().Unix()
+	for _, obj := range objects {
+		var auction Auction
+		if err := json.Unmarshal([]byte(obj.Value), &auction); err != nil {
+			logger.Error("Failed to unmarshal archived auction %s: %v", obj.Key, err)
+			continue
+		}
 
-	// Deduct the bid amount from the user
-	metadata := map[string]interface{}{
-		"source": "auction_bid",
-		"reason": "bid_placed",
+		a.updateAuctionState(&auction, currentTime, userID)
+		archived[auction.Id] = &auction
 	}
 
-	_, _, _, err := economySystem.Grant(ctx, logger, nk, userID, deductCurrencies, nil, nil, metadata)
-	if err != nil {
-		logger.Error("Failed to deduct bid currencies from user %s: %v", userID, err)
-		return err
+	auctions := make([]*Auction, 0, len(archived))
+	for _, entry := range paginatedEntries {
+		if auction, found := archived[entry.AuctionId]; found {
+			auctions = append(auctions, auction)
+		}
 	}
 
-	logger.Info("Deducted bid from user %s: %v", userID, bid.Currencies)
-	return nil
+	return &AuctionList{
+		Auctions:   auctions,
+		Cursor:     nextCursor,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}, nil
 }
 
-func (a *AuctionsPamlogix) chargeListingCost(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, listingCost *AuctionsConfigAuctionConditionCost) error {
-	if a.pamlogix == nil {
-		logger.Warn("Cannot charge listing cost: no Pamlogix instance available")
-		return ErrInternal
+// ArchiveSettled moves settled auctions older than the configured archive age out of the active collection
+func (a *AuctionsPamlogix) ArchiveSettled(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	if a.config.ArchiveAfterSec <= 0 {
+		return 0, nil
 	}
 
-	// Charge currencies if any
-	if len(listingCost.Currencies) > 0 {
-		economySystem := a.pamlogix.GetEconomySystem()
-		if economySystem == nil {
-			logger.Warn("Cannot charge listing cost currencies: no EconomySystem available")
-			return ErrInternal
+	archivedCount := 0
+	currentTime := time.Now().Unix()
+	cursor := ""
+	for {
+		// Cancelled auctions are no longer present in any index, so walk the collection itself
+		objects, nextCursor, err := nk.StorageList(ctx, "", "", AuctionCollectionKey, 100, cursor)
+		if err != nil {
+			logger.Error("Failed to list auctions for archival: %v", err)
+			return archivedCount, ErrInternal
 		}
 
-		// Convert to negative values for deduction
-		deductCurrencies := make(map[string]int64)
-		for currencyID, amount := range listingCost.Currencies {
-			deductCurrencies[currencyID] = -amount
-		}
+		for _, obj := range objects {
+			if isAuctionIndexKey(obj.Key) {
+				continue
+			}
+
+			var auction Auction
+			if err := json.Unmarshal([]byte(obj.Value), &auction); err != nil {
+				logger.Error("Failed to unmarshal auction %s: %v", obj.Key, err)
+				continue
+			}
+
+			a.updateAuctionState(&auction, currentTime, "")
+			if !a.shouldArchive(&auction, currentTime) {
+				continue
+			}
 
-		// Charge the currency listing cost
-		metadata := map[string]interface{}{
-			"source": "auction_listing",
-			"reason": "listing_cost_currencies",
+			if err := a.archiveAuction(ctx, logger, nk, &auction, currentTime); err != nil {
+				logger.Error("Failed to archive auction %s: %v", auction.Id, err)
+				continue
+			}
+			archivedCount++
 		}
 
-		_, _, _, err := economySystem.Grant(ctx, logger, nk, userID, deductCurrencies, nil, nil, metadata)
-		if err != nil {
-			logger.Error("Failed to charge listing cost currencies from user %s: %v", userID, err)
-			return err
+		if nextCursor == "" {
+			break
 		}
+		cursor = nextCursor
 	}
 
-	// Charge energies if any
-	if len(listingCost.Energies) > 0 {
-		energySystem := a.pamlogix.GetEnergySystem()
-		if energySystem == nil {
-			logger.Warn("Cannot charge listing cost energies: no EnergySystem available")
-			return ErrInternal
-		}
+	logger.Info("Archived %d settled auctions", archivedCount)
+	return archivedCount, nil
+}
 
-		// Convert to int32 and negative values for deduction
-		deductEnergies := make(map[string]int32)
-		for energyID, amount := range listingCost.Energies {
-			deductEnergies[energyID] = -int32(amount)
-		}
+// SetOnClaimBid sets a custom reward function which will run after an auction's reward is claimed by the winning bidder
+func (a *AuctionsPamlogix) SetOnClaimBid(fn OnAuctionReward[*AuctionReward]) {
+	a.onClaimBid = fn
+}
 
-		// Charge the energy listing cost
-		_, _, err := energySystem.Spend(ctx, logger, nk, userID, deductEnergies)
-		if err != nil {
-			logger.Error("Failed to charge listing cost energies from user %s: %v", userID, err)
-			return err
-		}
-	}
+// SetOnClaimCreated sets a custom reward function which will run after an auction's winning bid is claimed by the auction creator
+func (a *AuctionsPamlogix) SetOnClaimCreated(fn OnAuctionReward[*AuctionBidAmount]) {
+	a.onClaimCreated = fn
+}
 
-	// Charge items if any
-	if len(listingCost.Items) > 0 {
-		inventorySystem := a.pamlogix.GetInventorySystem()
-		if inventorySystem == nil {
-			logger.Warn("Cannot charge listing cost items: no InventorySystem available")
-			return ErrInternal
-		}
+// SetOnClaimCreatedFailed sets a custom reward function which will run after a failed auction is claimed by the auction creator
+func (a *AuctionsPamlogix) SetOnClaimCreatedFailed(fn OnAuctionReward[*AuctionReward]) {
+	a.onClaimCreatedFailed = fn
+}
 
-		// Convert to negative values for deduction
-		deductItems := make(map[string]int64)
-		for itemID, amount := range listingCost.Items {
-			deductItems[itemID] = -amount
-		}
+// SetOnCancel sets a custom reward function which will run after an auction is cancelled by the auction creator
+func (a *AuctionsPamlogix) SetOnCancel(fn OnAuctionReward[*AuctionReward]) {
+	a.onCancel = fn
+}
 
-		// Charge the item listing cost
-		_, _, _, _, err := inventorySystem.GrantItems(ctx, logger, nk, userID, deductItems, false)
-		if err != nil {
-			logger.Error("Failed to charge listing cost items from user %s: %v", userID, err)
-			return err
-		}
-	}
+// Helper methods
 
-	logger.Info("Charged listing cost from user %s: currencies=%v, energies=%v, items=%v", userID, listingCost.Currencies, listingCost.Energies, listingCost.Items)
-	return nil
+// checkUserFunds verifies that a user has sufficient currency to place a bid, failing with ErrCurrencyInsufficient like
+// any other cost the user can't afford.
+func (a *AuctionsPamlogix) checkUserFunds(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, bid *AuctionBidAmount) error {
+	return chargeCost(ctx, logger, nk, a.pamlogix, userID, &Cost{Currencies: bid.Currencies}, nil, true)
 }
 
 func (a *AuctionsPamlogix) updateAuctionState(auction *Auction, currentTime int64, userID string) {
@@ -1162,10 +1699,10 @@ func (a *AuctionsPamlogix) validateBid(auction *Auction, userID string, bid *Auc
 	return nil
 }
 
-func (a *AuctionsPamlogix) processBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auction *Auction, userID string, bid *AuctionBidAmount, currentTime int64, bidIncrement *AuctionsConfigAuctionConditionBidIncrement) error {
+func (a *AuctionsPamlogix) processBid(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auction *Auction, userID, teamID string, bid *AuctionBidAmount, currentTime int64, bidIncrement *AuctionsConfigAuctionConditionBidIncrement) error {
 	// Return previous bid to previous bidder if any
 	if auction.Bid != nil {
-		if err := a.returnBidToUser(ctx, logger, nk, auction.Bid.UserId, auction.Bid.Bid); err != nil {
+		if err := a.refundBid(ctx, logger, nk, auction.Id, auction.Bid, auction.Bid.Bid.Currencies, "outbid"); err != nil {
 			logger.Error("Failed to return bid to previous bidder %s: %v", auction.Bid.UserId, err)
 			// Continue despite error as the new bid should still be processed
 		}
@@ -1176,12 +1713,6 @@ func (a *AuctionsPamlogix) processBid(ctx context.Context, logger runtime.Logger
 		}
 	}
 
-	// Deduct the bid amount from the new bidder
-	if err := a.deductBidFromUser(ctx, logger, nk, userID, bid); err != nil {
-		logger.Error("Failed to deduct bid from user %s: %v", userID, err)
-		return err
-	}
-
 	// Set new bid
 	newBid := &AuctionBid{
 		UserId:        userID,
@@ -1189,6 +1720,12 @@ func (a *AuctionsPamlogix) processBid(ctx context.Context, logger runtime.Logger
 		CreateTimeSec: currentTime,
 	}
 
+	// Deduct the bid amount from the new bidder, or their team
+	if err := a.chargeBid(ctx, logger, nk, auction.Id, teamID, newBid); err != nil {
+		logger.Error("Failed to deduct bid from user %s: %v", userID, err)
+		return err
+	}
+
 	if auction.BidFirst == nil {
 		auction.BidFirst = newBid
 	}
@@ -1214,7 +1751,11 @@ func (a *AuctionsPamlogix) processBid(ctx context.Context, logger runtime.Logger
 	// Calculate next bid amount
 	auction.BidNext = a.calculateNextBid(bid, bidIncrement)
 
-	// Check for extension
+	return nil
+}
+
+// extendAuction extends an auction bid on close to its end, up to its maximum extension.
+func (a *AuctionsPamlogix) extendAuction(auction *Auction, currentTime int64) {
 	if auction.ExtensionThresholdSec > 0 && auction.ExtensionSec > 0 {
 		timeToEnd := auction.EndTimeSec - currentTime
 		if timeToEnd <= auction.ExtensionThresholdSec {
@@ -1234,11 +1775,6 @@ func (a *AuctionsPamlogix) processBid(ctx context.Context, logger runtime.Logger
 			}
 		}
 	}
-
-	// Update version
-	auction.Version = a.generateVersion()
-
-	return nil
 }
 
 func (a *AuctionsPamlogix) calculateNextBid(currentBid *AuctionBidAmount, bidIncrement *AuctionsConfigAuctionConditionBidIncrement) *AuctionBidAmount {
@@ -1310,90 +1846,592 @@ func (a *AuctionsPamlogix) calculateFee(bidAmount *AuctionBidAmount, feeConfig *
 		fee.Currencies[currency] = feeAmount
 	}
 
-	return fee
+	return fee
+}
+
+func (a *AuctionsPamlogix) validateItems(items []*InventoryItem, config *AuctionsConfigAuction) error {
+	if len(items) == 0 {
+		return ErrAuctionItemsInvalid
+	}
+
+	// If no restrictions are configured, allow any items
+	if len(config.Items) == 0 && len(config.ItemSets) == 0 {
+		return nil
+	}
+
+	// Check each item against allowed items and item sets
+	for _, item := range items {
+		if item == nil || item.Id == "" {
+			return ErrAuctionItemsInvalid
+		}
+
+		itemAllowed := false
+
+		// Check against allowed individual items, scanning them if the config wasn't read through the constructor
+		if config.AllowedItems != nil {
+			itemAllowed = config.AllowedItems[item.Id]
+		} else {
+			itemAllowed = slices.Contains(config.Items, item.Id)
+		}
+
+		// If not found in individual items, check against item sets
+		if !itemAllowed {
+			// Get inventory system to check item sets
+			if a.pamlogix != nil {
+				inventorySystem := a.pamlogix.GetInventorySystem()
+				if inventorySystem != nil {
+					// Get the inventory config to access the pre-computed item sets
+					inventoryConfig, ok := inventorySystem.GetConfig().(*InventoryConfig)
+					if ok && inventoryConfig != nil && inventoryConfig.ItemSets != nil {
+						// Check if item belongs to any allowed item set
+						for _, allowedSetID := range config.ItemSets {
+							if itemsInSet, exists := inventoryConfig.ItemSets[allowedSetID]; exists {
+								if itemsInSet[item.Id] {
+									itemAllowed = true
+									break
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+
+		if !itemAllowed {
+			return ErrAuctionItemsInvalid
+		}
+	}
+
+	return nil
+}
+
+func (a *AuctionsPamlogix) saveAuction(ctx context.Context, nk runtime.NakamaModule, auction *Auction) error {
+	return a.saveAuctionVersion(ctx, nk, auction, "")
+}
+
+// saveAuctionVersion saves the auction only if its storage object is still at the given version, so a change made to
+// the auction since it was read isn't overwritten. An empty version saves it whatever its version.
+func (a *AuctionsPamlogix) saveAuctionVersion(ctx context.Context, nk runtime.NakamaModule, auction *Auction, storageVersion string) error {
+	// Every save versions the auction by its new content, so bids placed against a stale read are rejected
+	version, err := auctionVersion(auction, a.versionGenerator)
+	if err != nil {
+		return err
+	}
+	auction.Version = version
+
+	data, err := json.Marshal(auction)
+	if err != nil {
+		return err
+	}
+
+	return retryNakama(ctx, idempotentCall, func() error {
+		_, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection: AuctionCollectionKey,
+				Key:        auction.Id,
+				UserID:     "",
+				Value:      string(data),
+				Version:    storageVersion,
+			},
+		})
+		return err
+	})
+}
+
+func (a *AuctionsPamlogix) saveReserve(ctx context.Context, nk runtime.NakamaModule, auctionID string, reserve *AuctionsConfigAuctionConditionBid) error {
+	data, err := json.Marshal(reserve)
+	if err != nil {
+		return err
+	}
+
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      AuctionReserveCollectionKey,
+			Key:             auctionID,
+			UserID:          "",
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+
+	return err
+}
+
+// readReserve returns the reserve price of an auction, or nil if it has none.
+func (a *AuctionsPamlogix) readReserve(ctx context.Context, nk runtime.NakamaModule, auctionID string) (*AuctionsConfigAuctionConditionBid, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: AuctionReserveCollectionKey,
+			Key:        auctionID,
+			UserID:     "",
+		},
+	})
+	if err != nil || len(objects) == 0 {
+		return nil, err
+	}
+
+	var reserve AuctionsConfigAuctionConditionBid
+	if err := json.Unmarshal([]byte(objects[0].Value), &reserve); err != nil {
+		return nil, err
+	}
+	return &reserve, nil
+}
+
+// bidMeetsReserve reports whether a bid is at least the reserve price in every currency of the reserve.
+func bidMeetsReserve(bid *AuctionBidAmount, reserve *AuctionsConfigAuctionConditionBid) bool {
+	if reserve == nil {
+		return true
+	}
+	for currencyID, amount := range reserve.Currencies {
+		if bid.GetCurrencies()[currencyID] < amount {
+			return false
+		}
+	}
+	return true
+}
+
+// bidReachesAmount reports whether a bid is at least the amount in every currency of the amount.
+func bidReachesAmount(bid, amount *AuctionBidAmount) bool {
+	for currencyID, required := range amount.GetCurrencies() {
+		if bid.GetCurrencies()[currencyID] < required {
+			return false
+		}
+	}
+	return true
+}
+
+// settleUnmetReserve ends an auction whose final bid is below its reserve price unsold: the bid is refunded and
+// removed, leaving the items for the creator to claim back. The settled auction is saved.
+func (a *AuctionsPamlogix) settleUnmetReserve(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auction *Auction, currentTime int64, userID string) error {
+	bidderID := auction.Bid.UserId
+	if err := a.refundBid(ctx, logger, nk, auction.Id, auction.Bid, auction.Bid.Bid.Currencies, "reserve_not_met"); err != nil {
+		logger.Error("Failed to refund bid to user %s for auction %s below reserve: %v", bidderID, auction.Id, err)
+		return ErrInternal
+	}
+
+	auction.Bid = nil
+	a.updateAuctionState(auction, currentTime, userID)
+	if err := a.saveAuction(ctx, nk, auction); err != nil {
+		logger.Error("Failed to save auction %s after refunding bid below reserve: %v", auction.Id, err)
+		return ErrInternal
+	}
+
+	if err := a.removeFromUserBidsIndex(ctx, nk, bidderID, auction.Id); err != nil {
+		logger.Error("Failed to remove auction below reserve from bidder's index: %v", err)
+	}
+
+	logger.Info("Auction %s ended below its reserve, refunded bid to user %s", auction.Id, bidderID)
+	return nil
+}
+
+func (a *AuctionsPamlogix) addToIndex(ctx context.Context, nk runtime.NakamaModule, auctionID string) error {
+	// Every auction shares the index, so updates to it are serialised across nodes
+	return withStorageLock(ctx, nk, auctionIndexLockName, func() error {
+		// Read current index
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+			{
+				Collection: AuctionCollectionKey,
+				Key:        AuctionIndexKey,
+				UserID:     "",
+			},
+		})
+
+		var index map[string]bool
+		if len(objects) > 0 {
+			if err := json.Unmarshal([]byte(objects[0].Value), &index); err != nil {
+				return err
+			}
+		} else {
+			index = make(map[string]bool)
+		}
+
+		// Add auction to index
+		index[auctionID] = true
+
+		// Save updated index
+		data, err := json.Marshal(index)
+		if err != nil {
+			return err
+		}
+
+		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection: AuctionCollectionKey,
+				Key:        AuctionIndexKey,
+				UserID:     "",
+				Value:      string(data),
+			},
+		})
+
+		return err
+	})
+}
+
+func (a *AuctionsPamlogix) removeFromIndex(ctx context.Context, nk runtime.NakamaModule, auctionID string) error {
+	// Every auction shares the index, so updates to it are serialised across nodes
+	return withStorageLock(ctx, nk, auctionIndexLockName, func() error {
+		// Read current index
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+			{
+				Collection: AuctionCollectionKey,
+				Key:        AuctionIndexKey,
+				UserID:     "",
+			},
+		})
+
+		if len(objects) == 0 {
+			return nil // Index doesn't exist
+		}
+
+		var index map[string]bool
+		if err := json.Unmarshal([]byte(objects[0].Value), &index); err != nil {
+			return err
+		}
+
+		// Remove auction from index
+		delete(index, auctionID)
+
+		// Save updated index
+		data, err := json.Marshal(index)
+		if err != nil {
+			return err
+		}
+
+		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection: AuctionCollectionKey,
+				Key:        AuctionIndexKey,
+				UserID:     "",
+				Value:      string(data),
+			},
+		})
+
+		return err
+	})
+}
+
+// isAuctionSettled reports whether an auction has ended and nothing remains to be claimed on it.
+func isAuctionSettled(auction *Auction) bool {
+	if auction.CancelTimeSec > 0 {
+		return true
+	}
+	if !auction.HasEnded || auction.OwnerClaimSec == 0 {
+		return false
+	}
+	return auction.Bid == nil || auction.WinnerClaimSec > 0
+}
+
+// auctionSettledTimeSec returns the time at which the last settlement action on an auction happened.
+func auctionSettledTimeSec(auction *Auction) int64 {
+	settled := auction.EndTimeSec
+	for _, t := range []int64{auction.CancelTimeSec, auction.OwnerClaimSec, auction.WinnerClaimSec} {
+		if t > settled {
+			settled = t
+		}
+	}
+	return settled
+}
+
+// isAuctionIndexKey reports whether a key in the auctions collection holds an index rather than an auction.
+func isAuctionIndexKey(key string) bool {
+	return key == AuctionIndexKey || key == AuctionBidsKey ||
+		strings.HasPrefix(key, AuctionUserCreatedKey+"_") || strings.HasPrefix(key, AuctionUserBidsKey+"_")
+}
+
+// pruneAuctionHistory splits history entries into those retained under the cap and those to be discarded.
+func pruneAuctionHistory(entries []*AuctionHistoryEntry, maxEntries int) ([]*AuctionHistoryEntry, []*AuctionHistoryEntry) {
+	if maxEntries <= 0 || len(entries) <= maxEntries {
+		return entries, nil
+	}
+	return entries[:maxEntries], entries[maxEntries:]
+}
+
+func (a *AuctionsPamlogix) shouldArchive(auction *Auction, currentTime int64) bool {
+	if a.config.ArchiveAfterSec <= 0 || !isAuctionSettled(auction) {
+		return false
+	}
+	return currentTime-auctionSettledTimeSec(auction) >= a.config.ArchiveAfterSec
+}
+
+func (a *AuctionsPamlogix) historyMaxPerUser() int {
+	if a.config.HistoryMaxPerUser > 0 {
+		return a.config.HistoryMaxPerUser
+	}
+	return defaultAuctionHistoryMaxPerUser
+}
+
+// archiveAuction copies an auction into each participant's history and removes it from active storage and indexes.
+func (a *AuctionsPamlogix) archiveAuction(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auction *Auction, currentTime int64) error {
+	data, err := json.Marshal(auction)
+	if err != nil {
+		return err
+	}
+
+	participants := []string{auction.UserId}
+	if auction.Bid != nil && auction.Bid.UserId != auction.UserId {
+		participants = append(participants, auction.Bid.UserId)
+	}
+
+	writes := make([]*runtime.StorageWrite, 0, len(participants))
+	for _, participant := range participants {
+		writes = append(writes, &runtime.StorageWrite{
+			Collection:      AuctionArchiveCollectionKey,
+			Key:             auction.Id,
+			UserID:          participant,
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		})
+	}
+	if _, err := nk.StorageWrite(ctx, writes); err != nil {
+		return err
+	}
+
+	for _, participant := range participants {
+		if err := a.addToUserHistory(ctx, nk, participant, auction.Id, currentTime); err != nil {
+			logger.Error("Failed to add auction %s to user %s history: %v", auction.Id, participant, err)
+		}
+	}
+
+	if err := nk.StorageDelete(ctx, []*runtime.StorageDelete{
+		{
+			Collection: AuctionCollectionKey,
+			Key:        auction.Id,
+			UserID:     "",
+		},
+		{
+			Collection: AuctionReserveCollectionKey,
+			Key:        auction.Id,
+			UserID:     "",
+		},
+	}); err != nil {
+		return err
+	}
+
+	if err := a.removeFromIndex(ctx, nk, auction.Id); err != nil {
+		logger.Error("Failed to remove archived auction from index: %v", err)
+	}
+	if err := a.removeFromUserCreatedIndex(ctx, nk, auction.UserId, auction.Id); err != nil {
+		logger.Error("Failed to remove archived auction from user created index: %v", err)
+	}
+	if auction.Bid != nil {
+		if err := a.removeFromUserBidsIndex(ctx, nk, auction.Bid.UserId, auction.Id); err != nil {
+			logger.Error("Failed to remove archived auction from user bids index: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// readUserIndexAuctions reads every auction referenced by one of the user's auction indexes, with its state updated
+// for the given user.
+func (a *AuctionsPamlogix) readUserIndexAuctions(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, indexKeyPrefix, userID string) ([]*Auction, error) {
+	indexKey := fmt.Sprintf("%s_%s", indexKeyPrefix, userID)
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: AuctionCollectionKey,
+			Key:        indexKey,
+			UserID:     "",
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to read user auctions index %s: %v", indexKey, err)
+		return nil, ErrInternal
+	}
+	if len(objects) == 0 {
+		return []*Auction{}, nil
+	}
+
+	var index map[string]bool
+	if err := json.Unmarshal([]byte(objects[0].Value), &index); err != nil {
+		logger.Error("Failed to unmarshal user auctions index %s: %v", indexKey, err)
+		return nil, ErrInternal
+	}
+	if len(index) == 0 {
+		return []*Auction{}, nil
+	}
+
+	reads := make([]*runtime.StorageRead, 0, len(index))
+	for auctionID := range index {
+		reads = append(reads, &runtime.StorageRead{
+			Collection: AuctionCollectionKey,
+			Key:        auctionID,
+			UserID:     "",
+		})
+	}
+
+	objects, err = nk.StorageRead(ctx, reads)
+	if err != nil {
+		logger.Error("Failed to read user auctions: %v", err)
+		return nil, ErrInternal
+	}
+
+	currentTime := time.Now().Unix()
+	auctions := make([]*Auction, 0, len(objects))
+	for _, obj := range objects {
+		var auction Auction
+		if err := json.Unmarshal([]byte(obj.Value), &auction); err != nil {
+			logger.Error("Failed to unmarshal auction %s: %v", obj.Key, err)
+			continue
+		}
+		a.updateAuctionState(&auction, currentTime, userID)
+		auctions = append(auctions, &auction)
+	}
+
+	return auctions, nil
+}
+
+// claimableAuctions returns the ended auctions the user can claim as the winner and as the creator. An auction the
+// user both won and created can't exist, since creators can't bid on their own auctions.
+func (a *AuctionsPamlogix) claimableAuctions(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (won, created []*Auction, err error) {
+	bids, err := a.readUserIndexAuctions(ctx, logger, nk, AuctionUserBidsKey, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, auction := range bids {
+		if auction.CanClaim && auction.Bid != nil && auction.Bid.UserId == userID {
+			won = append(won, auction)
+		}
+	}
+
+	listed, err := a.readUserIndexAuctions(ctx, logger, nk, AuctionUserCreatedKey, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, auction := range listed {
+		if auction.CanClaim && auction.UserId == userID {
+			created = append(created, auction)
+		}
+	}
+	return won, created, nil
+}
+
+// auctionClaimOutcome builds the per-auction outcome reported by the batch claim operations.
+func auctionClaimOutcome(auctionID string, err error) *AuctionClaimOutcome {
+	outcome := &AuctionClaimOutcome{
+		AuctionId: auctionID,
+		Claimed:   err == nil,
+	}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+	return outcome
 }
 
-func (a *AuctionsPamlogix) validateItems(items []*InventoryItem, config *AuctionsConfigAuction) error {
-	if len(items) == 0 {
-		return ErrAuctionItemsInvalid
+// readUserHistory returns the user's archived auction entries and the storage version they were read at, or
+// storageLockVersionNone when the user has no history yet.
+func (a *AuctionsPamlogix) readUserHistory(ctx context.Context, nk runtime.NakamaModule, userID string) ([]*AuctionHistoryEntry, string, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: AuctionArchiveCollectionKey,
+			Key:        AuctionUserHistoryKey,
+			UserID:     userID,
+		},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(objects) == 0 {
+		return nil, storageLockVersionNone, nil
 	}
 
-	// If no restrictions are configured, allow any items
-	if len(config.Items) == 0 && len(config.ItemSets) == 0 {
-		return nil
+	var entries []*AuctionHistoryEntry
+	if err := json.Unmarshal([]byte(objects[0].Value), &entries); err != nil {
+		return nil, "", err
 	}
 
-	// Check each item against allowed items and item sets
-	for _, item := range items {
-		if item == nil || item.Id == "" {
-			return ErrAuctionItemsInvalid
+	return entries, objects[0].Version, nil
+}
+
+// addToUserHistory adds an archived auction to the front of the user's history. A rejected write means the history
+// was updated since it was read, and it is read again.
+func (a *AuctionsPamlogix) addToUserHistory(ctx context.Context, nk runtime.NakamaModule, userID, auctionID string, currentTime int64) error {
+	var pruned []*AuctionHistoryEntry
+	var err error
+	for attempt := 0; attempt < auctionHistoryAttempts; attempt++ {
+		var entries []*AuctionHistoryEntry
+		var version string
+		entries, version, err = a.readUserHistory(ctx, nk, userID)
+		if err != nil {
+			return err
 		}
 
-		itemAllowed := false
+		// Most recently archived auctions are kept at the front of the history
+		entries = append([]*AuctionHistoryEntry{{AuctionId: auctionID, ArchiveTimeSec: currentTime}}, entries...)
+		entries, pruned = pruneAuctionHistory(entries, a.historyMaxPerUser())
 
-		// Check against allowed individual items
-		for _, allowedItem := range config.Items {
-			if item.Id == allowedItem {
-				itemAllowed = true
-				break
-			}
+		var data []byte
+		data, err = json.Marshal(entries)
+		if err != nil {
+			return err
 		}
 
-		// If not found in individual items, check against item sets
-		if !itemAllowed {
-			// Get inventory system to check item sets
-			if a.pamlogix != nil {
-				inventorySystem := a.pamlogix.GetInventorySystem()
-				if inventorySystem != nil {
-					// Get the inventory config to access the pre-computed item sets
-					inventoryConfig, ok := inventorySystem.GetConfig().(*InventoryConfig)
-					if ok && inventoryConfig != nil && inventoryConfig.ItemSets != nil {
-						// Check if item belongs to any allowed item set
-						for _, allowedSetID := range config.ItemSets {
-							if itemsInSet, exists := inventoryConfig.ItemSets[allowedSetID]; exists {
-								if itemsInSet[item.Id] {
-									itemAllowed = true
-									break
-								}
-							}
-						}
-					}
-				}
-			}
+		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection:      AuctionArchiveCollectionKey,
+				Key:             AuctionUserHistoryKey,
+				UserID:          userID,
+				Value:           string(data),
+				Version:         version,
+				PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+				PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+			},
+		})
+		if !errors.Is(err, runtime.ErrStorageRejectedVersion) {
+			break
 		}
+	}
+	if err != nil {
+		return err
+	}
 
-		if !itemAllowed {
-			return ErrAuctionItemsInvalid
+	if len(pruned) == 0 {
+		return nil
+	}
+
+	deletes := make([]*runtime.StorageDelete, len(pruned))
+	for i, entry := range pruned {
+		deletes[i] = &runtime.StorageDelete{
+			Collection: AuctionArchiveCollectionKey,
+			Key:        entry.AuctionId,
+			UserID:     userID,
 		}
 	}
 
-	return nil
+	return nk.StorageDelete(ctx, deletes)
 }
 
-func (a *AuctionsPamlogix) saveAuction(ctx context.Context, nk runtime.NakamaModule, auction *Auction) error {
-	data, err := json.Marshal(auction)
+// auctionVersion versions the auction by its content, by default a hash of it, so every change to it yields a new
+// version however close together updates land. The fields worked out for each viewer on read are left out, as they
+// aren't part of its state.
+func auctionVersion(auction *Auction, generator VersionGeneratorFn) (string, error) {
+	content := proto.Clone(auction).(*Auction)
+	content.Version = ""
+	content.CurrentTimeSec = 0
+	content.HasStarted = false
+	content.HasEnded = false
+	content.CanBid = false
+	content.CanClaim = false
+	content.CanCancel = false
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(content)
 	if err != nil {
-		return err
+		return "", err
 	}
-
-	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-		{
-			Collection: AuctionCollectionKey,
-			Key:        auction.Id,
-			UserID:     "",
-			Value:      string(data),
-		},
-	})
-
-	return err
+	if generator == nil {
+		generator = contentHashVersion
+	}
+	return generator(data), nil
 }
 
-func (a *AuctionsPamlogix) addToIndex(ctx context.Context, nk runtime.NakamaModule, auctionID string) error {
-	// Read current index
+func (a *AuctionsPamlogix) addToUserCreatedIndex(ctx context.Context, nk runtime.NakamaModule, userID, auctionID string) error {
+	// Read current user's created auctions index
+	userCreatedKey := fmt.Sprintf("%s_%s", AuctionUserCreatedKey, userID)
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
 			Collection: AuctionCollectionKey,
-			Key:        AuctionIndexKey,
+			Key:        userCreatedKey,
 			UserID:     "",
 		},
 	})
@@ -1407,7 +2445,7 @@ func (a *AuctionsPamlogix) addToIndex(ctx context.Context, nk runtime.NakamaModu
 		index = make(map[string]bool)
 	}
 
-	// Add auction to index
+	// Add auction to user's index
 	index[auctionID] = true
 
 	// Save updated index
@@ -1419,7 +2457,7 @@ func (a *AuctionsPamlogix) addToIndex(ctx context.Context, nk runtime.NakamaModu
 	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
 		{
 			Collection: AuctionCollectionKey,
-			Key:        AuctionIndexKey,
+			Key:        userCreatedKey,
 			UserID:     "",
 			Value:      string(data),
 		},
@@ -1428,12 +2466,13 @@ func (a *AuctionsPamlogix) addToIndex(ctx context.Context, nk runtime.NakamaModu
 	return err
 }
 
-func (a *AuctionsPamlogix) removeFromIndex(ctx context.Context, nk runtime.NakamaModule, auctionID string) error {
-	// Read current index
+func (a *AuctionsPamlogix) removeFromUserCreatedIndex(ctx context.Context, nk runtime.NakamaModule, userID, auctionID string) error {
+	// Read current user's created auctions index
+	userCreatedKey := fmt.Sprintf("%s_%s", AuctionUserCreatedKey, userID)
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
 			Collection: AuctionCollectionKey,
-			Key:        AuctionIndexKey,
+			Key:        userCreatedKey,
 			UserID:     "",
 		},
 	})
@@ -1447,7 +2486,7 @@ func (a *AuctionsPamlogix) removeFromIndex(ctx context.Context, nk runtime.Nakam
 		return err
 	}
 
-	// Remove auction from index
+	// Remove auction from user's index
 	delete(index, auctionID)
 
 	// Save updated index
@@ -1459,7 +2498,7 @@ func (a *AuctionsPamlogix) removeFromIndex(ctx context.Context, nk runtime.Nakam
 	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
 		{
 			Collection: AuctionCollectionKey,
-			Key:        AuctionIndexKey,
+			Key:        userCreatedKey,
 			UserID:     "",
 			Value:      string(data),
 		},
@@ -1468,89 +2507,95 @@ func (a *AuctionsPamlogix) removeFromIndex(ctx context.Context, nk runtime.Nakam
 	return err
 }
 
-func (a *AuctionsPamlogix) generateVersion() string {
-	return fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%d", time.Now().UnixNano()))))
-}
-
-func (a *AuctionsPamlogix) addToUserCreatedIndex(ctx context.Context, nk runtime.NakamaModule, userID, auctionID string) error {
-	// Read current user's created auctions index
-	userCreatedKey := fmt.Sprintf("%s_%s", AuctionUserCreatedKey, userID)
-	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
-		{
-			Collection: AuctionCollectionKey,
-			Key:        userCreatedKey,
-			UserID:     "",
-		},
-	})
+// listingAllowance works out how many more auctions the user can list under the listing limits. Active listings are
+// counted from the user's created auctions index, while listing times are kept apart so cancelled auctions still count
+// towards the daily limit. It also returns the listing times of the last day.
+func (a *AuctionsPamlogix) listingAllowance(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, now int64) (*AuctionListingAllowance, []int64, error) {
+	limits := a.config.ListingLimits
+	allowance := &AuctionListingAllowance{
+		RemainingActiveListings: -1,
+		RemainingDailyListings:  -1,
+	}
 
-	var index map[string]bool
-	if len(objects) > 0 {
-		if err := json.Unmarshal([]byte(objects[0].Value), &index); err != nil {
-			return err
+	if limits.MaxActive > 0 {
+		created, err := a.readUserIndexAuctions(ctx, logger, nk, AuctionUserCreatedKey, userID)
+		if err != nil {
+			return nil, nil, err
 		}
-	} else {
-		index = make(map[string]bool)
+		for _, auction := range created {
+			if !auction.HasEnded {
+				allowance.ActiveListings++
+			}
+		}
+		allowance.RemainingActiveListings = max(limits.MaxActive-allowance.ActiveListings, 0)
 	}
 
-	// Add auction to user's index
-	index[auctionID] = true
-
-	// Save updated index
-	data, err := json.Marshal(index)
+	listingTimes, err := a.readListingTimes(ctx, nk, userID, now)
 	if err != nil {
-		return err
+		logger.Error("Failed to read auction listing times: %v", err)
+		return nil, nil, ErrInternal
+	}
+	allowance.DailyListings = len(listingTimes)
+	if limits.MaxPerDay > 0 {
+		allowance.RemainingDailyListings = max(limits.MaxPerDay-allowance.DailyListings, 0)
+		if allowance.RemainingDailyListings == 0 {
+			// The oldest listing of the last day has to drop out of the window first
+			allowance.NextListingTimeSec = listingTimes[len(listingTimes)-limits.MaxPerDay] + auctionListingWindowSec
+		}
+	}
+	if limits.CooldownSec > 0 && len(listingTimes) > 0 {
+		allowance.NextListingTimeSec = max(allowance.NextListingTimeSec, listingTimes[len(listingTimes)-1]+limits.CooldownSec)
+	}
+	if allowance.NextListingTimeSec <= now {
+		allowance.NextListingTimeSec = 0
 	}
 
-	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-		{
-			Collection: AuctionCollectionKey,
-			Key:        userCreatedKey,
-			UserID:     "",
-			Value:      string(data),
-		},
-	})
-
-	return err
+	allowance.CanCreate = allowance.RemainingActiveListings != 0 && allowance.RemainingDailyListings != 0 && allowance.NextListingTimeSec == 0
+	return allowance, listingTimes, nil
 }
 
-func (a *AuctionsPamlogix) removeFromUserCreatedIndex(ctx context.Context, nk runtime.NakamaModule, userID, auctionID string) error {
-	// Read current user's created auctions index
-	userCreatedKey := fmt.Sprintf("%s_%s", AuctionUserCreatedKey, userID)
+// readListingTimes returns when the user listed auctions over the last day, oldest first.
+func (a *AuctionsPamlogix) readListingTimes(ctx context.Context, nk runtime.NakamaModule, userID string, now int64) ([]int64, error) {
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
 			Collection: AuctionCollectionKey,
-			Key:        userCreatedKey,
+			Key:        fmt.Sprintf("%s_%s", AuctionUserListingsKey, userID),
 			UserID:     "",
 		},
 	})
-
+	if err != nil {
+		return nil, err
+	}
 	if len(objects) == 0 {
-		return nil // Index doesn't exist
+		return []int64{}, nil
 	}
 
-	var index map[string]bool
-	if err := json.Unmarshal([]byte(objects[0].Value), &index); err != nil {
-		return err
+	var stored []int64
+	if err := json.Unmarshal([]byte(objects[0].Value), &stored); err != nil {
+		return nil, err
 	}
+	listingTimes := make([]int64, 0, len(stored))
+	for _, listingTime := range stored {
+		if listingTime > now-auctionListingWindowSec {
+			listingTimes = append(listingTimes, listingTime)
+		}
+	}
+	return listingTimes, nil
+}
 
-	// Remove auction from user's index
-	delete(index, auctionID)
-
-	// Save updated index
-	data, err := json.Marshal(index)
+func (a *AuctionsPamlogix) writeListingTimes(ctx context.Context, nk runtime.NakamaModule, userID string, listingTimes []int64) error {
+	data, err := json.Marshal(listingTimes)
 	if err != nil {
 		return err
 	}
-
 	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
 		{
 			Collection: AuctionCollectionKey,
-			Key:        userCreatedKey,
+			Key:        fmt.Sprintf("%s_%s", AuctionUserListingsKey, userID),
 			UserID:     "",
 			Value:      string(data),
 		},
 	})
-
 	return err
 }
 
@@ -1637,50 +2682,7 @@ func (a *AuctionsPamlogix) removeFromUserBidsIndex(ctx context.Context, nk runti
 }
 
 func (a *AuctionsPamlogix) sendBidNotification(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auction *Auction, sessionID string) {
-	// Create the bid notification payload
-	bidNotification := &AuctionNotificationBid{
-		Id:                    auction.Id,
-		Version:               auction.Version,
-		Bid:                   auction.Bid,
-		BidNext:               auction.BidNext,
-		ExtensionAddedSec:     auction.ExtensionAddedSec,
-		ExtensionRemainingSec: auction.ExtensionRemainingSec,
-		UpdateTimeSec:         auction.UpdateTimeSec,
-		EndTimeSec:            auction.EndTimeSec,
-		CurrentTimeSec:        auction.CurrentTimeSec,
-	}
-
-	// Marshal the notification to JSON for stream data
-	notificationData, err := json.Marshal(bidNotification)
-	if err != nil {
-		logger.Error("Failed to marshal bid notification for auction %s: %v", auction.Id, err)
-		return
-	}
-
-	// Send real-time notification via stream to auction followers
-	// Stream mode 1 is typically used for custom application streams
-	// Subject is the auction ID, subcontext can be "bid_updates"
-	streamMode := uint8(1)
-	subject := auction.Id
-	subcontext := "auction_bid_updates"
-	label := "auction_notifications"
-
-	// Get list of users following this auction stream
-	presences, err := nk.StreamUserList(streamMode, subject, subcontext, label, true, true)
-	if err != nil {
-		logger.Error("Failed to get auction followers for auction %s: %v", auction.Id, err)
-		return
-	}
-
-	if len(presences) > 0 {
-		// Send the notification to all followers via stream
-		err = nk.StreamSend(streamMode, subject, subcontext, label, string(notificationData), presences, true)
-		if err != nil {
-			logger.Error("Failed to send stream notification for auction %s: %v", auction.Id, err)
-		} else {
-			logger.Info("Sent bid notification for auction %s to %d followers", auction.Id, len(presences))
-		}
-	}
+	a.streamBidUpdate(logger, nk, auction)
 
 	// Also send persistent notifications to interested users
 	// Send to auction creator (unless they are the bidder)
@@ -1691,8 +2693,12 @@ func (a *AuctionsPamlogix) sendBidNotification(ctx context.Context, logger runti
 			"bidder_id":  auction.Bid.UserId,
 			"type":       "auction_bid",
 		}
+		vars := map[string]string{
+			"auction_id": auction.Id,
+			"bidder_id":  auction.Bid.UserId,
+		}
 
-		err = nk.NotificationSend(ctx, auction.UserId, "New bid on your auction", content, 1001, "", true)
+		err := sendTemplatedNotification(ctx, logger, nk, a.pamlogix, auction.UserId, NotificationEventAuctionNewBid, vars, content)
 		if err != nil {
 			logger.Error("Failed to send notification to auction creator %s: %v", auction.UserId, err)
 		}
@@ -1707,8 +2713,11 @@ func (a *AuctionsPamlogix) sendBidNotification(ctx context.Context, logger runti
 				"bid_amount": auction.Bid.Bid.Currencies,
 				"type":       "auction_outbid",
 			}
+			vars := map[string]string{
+				"auction_id": auction.Id,
+			}
 
-			err = nk.NotificationSend(ctx, previousBid.UserId, "You have been outbid", content, 1002, "", true)
+			err := sendTemplatedNotification(ctx, logger, nk, a.pamlogix, previousBid.UserId, NotificationEventAuctionOutbid, vars, content)
 			if err != nil {
 				logger.Error("Failed to send outbid notification to user %s: %v", previousBid.UserId, err)
 			}
@@ -1732,3 +2741,51 @@ func (a *AuctionsPamlogix) followAuctionForUser(ctx context.Context, logger runt
 		logger.Info("User %s automatically joined auction %s notification stream", userID, auctionID)
 	}
 }
+
+// streamBidUpdate sends the auction's current bid to the users following it.
+func (a *AuctionsPamlogix) streamBidUpdate(logger runtime.Logger, nk runtime.NakamaModule, auction *Auction) {
+	// Create the bid notification payload
+	bidNotification := &AuctionNotificationBid{
+		Id:                    auction.Id,
+		Version:               auction.Version,
+		Bid:                   auction.Bid,
+		BidNext:               auction.BidNext,
+		ExtensionAddedSec:     auction.ExtensionAddedSec,
+		ExtensionRemainingSec: auction.ExtensionRemainingSec,
+		UpdateTimeSec:         auction.UpdateTimeSec,
+		EndTimeSec:            auction.EndTimeSec,
+		CurrentTimeSec:        auction.CurrentTimeSec,
+	}
+
+	// Marshal the notification to JSON for stream data
+	notificationData, err := json.Marshal(bidNotification)
+	if err != nil {
+		logger.Error("Failed to marshal bid notification for auction %s: %v", auction.Id, err)
+		return
+	}
+
+	// Send real-time notification via stream to auction followers
+	// Stream mode 1 is typically used for custom application streams
+	// Subject is the auction ID, subcontext can be "bid_updates"
+	streamMode := uint8(1)
+	subject := auction.Id
+	subcontext := "auction_bid_updates"
+	label := "auction_notifications"
+
+	// Get list of users following this auction stream
+	presences, err := nk.StreamUserList(streamMode, subject, subcontext, label, true, true)
+	if err != nil {
+		logger.Error("Failed to get auction followers for auction %s: %v", auction.Id, err)
+		return
+	}
+
+	if len(presences) > 0 {
+		// Send the notification to all followers via stream
+		err = nk.StreamSend(streamMode, subject, subcontext, label, string(notificationData), presences, true)
+		if err != nil {
+			logger.Error("Failed to send stream notification for auction %s: %v", auction.Id, err)
+		} else {
+			logger.Info("Sent bid notification for auction %s to %d followers", auction.Id, len(presences))
+		}
+	}
+}
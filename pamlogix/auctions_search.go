@@ -0,0 +1,319 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	auctionSearchIndexName              = "auction_search"
+	defaultAuctionSearchIndexMaxEntries = 100000
+	// maxAuctionSearchResults caps the auctions a filtered listing reads and pages through, the best matches first.
+	maxAuctionSearchResults = 1000
+	auctionSearchPageSize   = 100
+)
+
+// auctionSearch holds the filters and sort keys parsed from an auction listing's query.
+type auctionSearch struct {
+	itemID          string
+	itemSet         string
+	sellerID        string
+	currency        string
+	minPrice        int64
+	maxPrice        int64
+	hasMinPrice     bool
+	hasMaxPrice     bool
+	endingWithinSec int64
+	sortKeys        []auctionSortKey
+}
+
+// auctionSortKey orders auctions by one field, ascending unless descending is set.
+type auctionSortKey struct {
+	field      string
+	descending bool
+}
+
+// registerSearchIndex creates the storage index over listed auctions, if the config enables it.
+func (a *AuctionsPamlogix) registerSearchIndex(initializer runtime.Initializer) error {
+	if a.config == nil || a.config.SearchIndex == nil {
+		return nil
+	}
+	maxEntries := a.config.SearchIndex.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultAuctionSearchIndexMaxEntries
+	}
+	fields := []string{"id", "user_id", "reward", "end_time_sec", "cancel_time_sec"}
+	if err := initializer.RegisterStorageIndex(auctionSearchIndexName, AuctionCollectionKey, "", fields, nil, maxEntries, false); err != nil {
+		return err
+	}
+	a.searchIndex = true
+	return nil
+}
+
+// parseAuctionSearch reads the query's "field:value" clauses, separated by spaces, and the sort keys. Values holding
+// spaces are quoted.
+func parseAuctionSearch(query string, sortKeys []string) (*auctionSearch, error) {
+	search := &auctionSearch{}
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		colon := strings.Index(rest, ":")
+		if colon <= 0 || strings.ContainsAny(rest[:colon], " \t") {
+			return nil, runtime.NewError(fmt.Sprintf("invalid auction query clause %q", rest), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		field := rest[:colon]
+		rest = rest[colon+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.Index(rest[1:], `"`)
+			if closing < 0 {
+				return nil, runtime.NewError("unterminated string in auction query", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+			}
+			value = rest[1 : closing+1]
+			rest = rest[closing+2:]
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				end = len(rest)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		if value == "" {
+			return nil, runtime.NewError(fmt.Sprintf("auction query field %q has no value", field), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+
+		switch field {
+		case "item_id":
+			search.itemID = value
+		case "item_set":
+			search.itemSet = value
+		case "seller":
+			search.sellerID = value
+		case "currency":
+			search.currency = value
+		case "min_price", "max_price", "ending_within":
+			number, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || number < 0 {
+				return nil, runtime.NewError(fmt.Sprintf("auction query field %q needs a whole number, not %q", field, value), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+			}
+			switch field {
+			case "min_price":
+				search.minPrice = number
+				search.hasMinPrice = true
+			case "max_price":
+				search.maxPrice = number
+				search.hasMaxPrice = true
+			default:
+				search.endingWithinSec = number
+			}
+		default:
+			return nil, runtime.NewError(fmt.Sprintf("unknown auction query field %q", field), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		rest = strings.TrimSpace(rest)
+	}
+
+	for _, sortKey := range sortKeys {
+		key := auctionSortKey{field: strings.TrimPrefix(sortKey, "-"), descending: strings.HasPrefix(sortKey, "-")}
+		switch key.field {
+		case "end_time_sec", "create_time_sec", "price":
+		default:
+			return nil, runtime.NewError(fmt.Sprintf("unknown auction sort key %q", sortKey), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		search.sortKeys = append(search.sortKeys, key)
+	}
+
+	// Prices are per currency, so a price range or order needs to know which one
+	if search.currency == "" && (search.hasMinPrice || search.hasMaxPrice || slices.ContainsFunc(search.sortKeys, func(key auctionSortKey) bool {
+		return key.field == "price"
+	})) {
+		return nil, ErrAuctionQueryInvalid
+	}
+	if search.hasMinPrice && search.hasMaxPrice && search.minPrice > search.maxPrice {
+		return nil, ErrAuctionQueryInvalid
+	}
+	return search, nil
+}
+
+// empty reports whether the search neither filters nor sorts, so listings page through the auction index as is.
+func (s *auctionSearch) empty() bool {
+	return s.itemID == "" && s.itemSet == "" && s.sellerID == "" && s.currency == "" && !s.hasMinPrice && !s.hasMaxPrice &&
+		s.endingWithinSec == 0 && len(s.sortKeys) == 0
+}
+
+// matches reports whether the auction passes every filter of the search.
+func (s *auctionSearch) matches(auction *Auction, currentTime int64) bool {
+	if s.sellerID != "" && auction.UserId != s.sellerID {
+		return false
+	}
+	if s.itemID != "" || s.itemSet != "" {
+		if auction.Reward == nil || !slices.ContainsFunc(auction.Reward.Items, func(item *InventoryItem) bool {
+			return (s.itemID == "" || item.Id == s.itemID) && (s.itemSet == "" || slices.Contains(item.ItemSets, s.itemSet))
+		}) {
+			return false
+		}
+	}
+	if s.endingWithinSec > 0 {
+		if auction.CancelTimeSec > 0 || auction.EndTimeSec <= currentTime || auction.EndTimeSec > currentTime+s.endingWithinSec {
+			return false
+		}
+	}
+	if s.currency != "" {
+		price, found := auctionPrice(auction, s.currency)
+		if !found || (s.hasMinPrice && price < s.minPrice) || (s.hasMaxPrice && price > s.maxPrice) {
+			return false
+		}
+	}
+	return true
+}
+
+// sort orders the auctions by the sort keys, then by ID so pages stay stable.
+func (s *auctionSearch) sort(auctions []*Auction) {
+	sort.Slice(auctions, func(i, j int) bool {
+		for _, key := range s.sortKeys {
+			var left, right int64
+			switch key.field {
+			case "end_time_sec":
+				left, right = auctions[i].EndTimeSec, auctions[j].EndTimeSec
+			case "create_time_sec":
+				left, right = auctions[i].CreateTimeSec, auctions[j].CreateTimeSec
+			case "price":
+				leftPrice, leftFound := auctionPrice(auctions[i], s.currency)
+				rightPrice, rightFound := auctionPrice(auctions[j], s.currency)
+				if leftFound != rightFound {
+					// Auctions without a price in the currency go last either way
+					return leftFound
+				}
+				left, right = leftPrice, rightPrice
+			}
+			if left != right {
+				return (left < right) != key.descending
+			}
+		}
+		return auctions[i].Id < auctions[j].Id
+	})
+}
+
+// indexQuery builds the storage index query narrowing the auctions to those the search may match. Prices aren't
+// indexed since they move with every bid, so they are only checked in memory.
+func (s *auctionSearch) indexQuery(currentTime int64) string {
+	// Every auction has an end time, which leaves out the index objects sharing the collection
+	clauses := []string{"+value.end_time_sec:>0"}
+	if s.sellerID != "" {
+		clauses = append(clauses, "+value.user_id:"+strconv.Quote(s.sellerID))
+	}
+	if s.itemID != "" {
+		clauses = append(clauses, "+value.reward.items.id:"+strconv.Quote(s.itemID))
+	}
+	if s.itemSet != "" {
+		clauses = append(clauses, "+value.reward.items.item_sets:"+strconv.Quote(s.itemSet))
+	}
+	if s.endingWithinSec > 0 {
+		clauses = append(clauses,
+			"+value.end_time_sec:>"+strconv.FormatInt(currentTime, 10),
+			"+value.end_time_sec:<="+strconv.FormatInt(currentTime+s.endingWithinSec, 10),
+			"-value.cancel_time_sec:>0")
+	}
+	return strings.Join(clauses, " ")
+}
+
+// auctionPrice returns what a bid on the auction costs now in the currency: the next bid, or the winning bid once
+// there is no next one.
+func auctionPrice(auction *Auction, currency string) (int64, bool) {
+	amount := auction.BidNext
+	if amount == nil && auction.Bid != nil {
+		amount = auction.Bid.Bid
+	}
+	if amount == nil {
+		return 0, false
+	}
+	price, found := amount.Currencies[currency]
+	return price, found
+}
+
+// search pages through the auctions matching the search, in its order.
+func (a *AuctionsPamlogix) search(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, search *auctionSearch, offset, limit int) (*AuctionList, error) {
+	currentTime := time.Now().Unix()
+
+	var candidates []*Auction
+	if a.searchIndex {
+		var err error
+		candidates, err = a.searchIndexCandidates(ctx, logger, nk, search.indexQuery(currentTime))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		auctionIDs, err := a.readAuctionIndex(ctx, logger, nk)
+		if err != nil {
+			return nil, err
+		}
+		candidates, err = a.readAuctions(ctx, logger, nk, auctionIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The index is updated as auctions are written, so every candidate is checked again against the stored auction
+	matched := make([]*Auction, 0, len(candidates))
+	for _, auction := range candidates {
+		if search.matches(auction, currentTime) {
+			matched = append(matched, auction)
+		}
+	}
+	search.sort(matched)
+	if len(matched) > maxAuctionSearchResults {
+		matched = matched[:maxAuctionSearchResults]
+	}
+
+	start, end, nextCursor, prevCursor := offsetPage(offset, limit, len(matched))
+	auctions := matched[start:end]
+	for _, auction := range auctions {
+		a.updateAuctionState(auction, currentTime, userID)
+	}
+	a.estimateValues(ctx, logger, nk, auctions)
+
+	return &AuctionList{
+		Auctions:   auctions,
+		Cursor:     nextCursor,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}, nil
+}
+
+// searchIndexCandidates reads the auctions the storage index holds for the query, up to the most a search pages
+// through.
+func (a *AuctionsPamlogix) searchIndexCandidates(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, query string) ([]*Auction, error) {
+	auctions := make([]*Auction, 0)
+	cursor := ""
+	for len(auctions) < maxAuctionSearchResults {
+		objects, nextCursor, err := nk.StorageIndexList(ctx, "", auctionSearchIndexName, query, auctionSearchPageSize, nil, cursor)
+		if err != nil {
+			logger.Error("Failed to query auction search index: %v", err)
+			return nil, ErrInternal
+		}
+		for _, object := range objects.GetObjects() {
+			if isAuctionIndexKey(object.Key) {
+				continue
+			}
+			auction := &Auction{}
+			if err := json.Unmarshal([]byte(object.Value), auction); err != nil {
+				logger.Error("Failed to unmarshal auction %s: %v", object.Key, err)
+				continue
+			}
+			auctions = append(auctions, auction)
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+	return auctions, nil
+}
@@ -1,11 +1,18 @@
 package pamlogix
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 // Mock implementations for testing
@@ -86,6 +93,9 @@ func (m *MockPamlogix) AddPublisher(publisher Publisher) {
 	m.Called(publisher)
 }
 
+func (m *MockPamlogix) SendPublisherEvents(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, events []*PublisherEvent) {
+}
+
 func (m *MockPamlogix) SetAfterAuthenticate(fn AfterAuthenticateFn) {
 	m.Called(fn)
 }
@@ -94,6 +104,18 @@ func (m *MockPamlogix) SetCollectionResolver(fn CollectionResolverFn) {
 	m.Called(fn)
 }
 
+func (m *MockPamlogix) SetTextModeration(fn TextModerationFn) {
+	m.Called(fn)
+}
+
+func (m *MockPamlogix) SetJsonPolicy(policy *JsonPolicy) {
+	m.Called(policy)
+}
+
+func (m *MockPamlogix) RegisterJob(name string, defaultIntervalSec int64, fn JobFn) {
+	m.Called(name, defaultIntervalSec, fn)
+}
+
 func (m *MockPamlogix) GetBaseSystem() BaseSystem {
 	args := m.Called()
 	return args.Get(0).(BaseSystem)
@@ -357,3 +379,1221 @@ func TestAuctionItemSetValidationEmptyItems(t *testing.T) {
 	assert.Error(t, err, "Expected error for item with empty ID")
 	assert.Equal(t, ErrAuctionItemsInvalid, err)
 }
+
+func TestAuctionAllowedItemsPrecomputed(t *testing.T) {
+	config := &AuctionsConfig{
+		Auctions: map[string]*AuctionsConfigAuction{
+			"weapons": {Items: []string{"sword_basic", "axe_basic"}},
+			"any":     {},
+		},
+	}
+	auctionsSystem := NewNakamaAuctionsSystem(config).(*AuctionsPamlogix)
+
+	assert.Equal(t, map[string]bool{"sword_basic": true, "axe_basic": true}, config.Auctions["weapons"].AllowedItems)
+	assert.Nil(t, config.Auctions["any"].AllowedItems)
+
+	assert.NoError(t, auctionsSystem.validateItems([]*InventoryItem{{Id: "axe_basic", Count: 1}}, config.Auctions["weapons"]))
+	assert.Equal(t, ErrAuctionItemsInvalid, auctionsSystem.validateItems([]*InventoryItem{{Id: "potion", Count: 1}}, config.Auctions["weapons"]))
+	assert.NoError(t, auctionsSystem.validateItems([]*InventoryItem{{Id: "potion", Count: 1}}, config.Auctions["any"]))
+}
+
+func TestAuctionValuations(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	p.GetEconomySystem().(*NakamaEconomySystem).config.StoreItems = map[string]*EconomyConfigStoreItem{
+		"potion_pack": {
+			Cost:   &EconomyConfigStoreItemCost{Currencies: map[string]int64{benchCurrency: 50}},
+			Reward: &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{Items: map[string]*EconomyConfigRewardItem{"potion": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 5, Max: 5}}}}},
+		},
+		"sword_offer": {
+			Cost:   &EconomyConfigStoreItemCost{Currencies: map[string]int64{benchCurrency: 1000}},
+			Reward: &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{Items: map[string]*EconomyConfigRewardItem{"sword": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 1, Max: 1}}}}},
+		},
+	}
+	auctions := p.GetAuctionsSystem().(*AuctionsPamlogix)
+	auctions.config.Valuation = &AuctionsConfigValuation{MinSales: 2, WindowSec: 3600}
+
+	sold := func(auctionID string, count, bid, saleTimeSec int64) {
+		auction := &Auction{
+			Id:     auctionID,
+			Reward: &AuctionReward{Items: []*InventoryItem{{Id: "sword", Count: count}}},
+			Bid:    &AuctionBid{UserId: "winner", Bid: &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: bid}}},
+		}
+		require.NoError(t, auctions.recordSale(ctx, nk, auction, saleTimeSec))
+	}
+	now := time.Now().Unix()
+	sold("auction1", 1, 100, now)
+	sold("auction2", 2, 300, now)
+	sold("auction3", 1, 5000, now-7200)
+
+	valuations, err := auctions.aggregateValuations(ctx, logger, nk)
+	require.NoError(t, err)
+
+	// Recent sales outweigh the store price, averaged per unit sold, and sales before the window don't count
+	require.Contains(t, valuations.Items, "sword")
+	assert.Equal(t, map[string]int64{benchCurrency: 133}, valuations.Items["sword"].Currencies)
+	assert.Equal(t, 2, valuations.Items["sword"].Sales)
+	require.Contains(t, valuations.Items, "potion")
+	assert.Equal(t, map[string]int64{benchCurrency: 10}, valuations.Items["potion"].Currencies)
+
+	listed := []*Auction{
+		{Id: "a", Reward: &AuctionReward{Items: []*InventoryItem{{Id: "potion", Count: 3}, {Id: "sword", Count: 1}}}},
+		{Id: "b", Reward: &AuctionReward{Items: []*InventoryItem{{Id: "shield", Count: 1}}}},
+	}
+	auctions.estimateValues(ctx, logger, nk, listed)
+	require.NotNil(t, listed[0].EstimatedValue)
+	assert.Equal(t, map[string]int64{benchCurrency: 163}, listed[0].EstimatedValue.Currencies)
+	assert.Nil(t, listed[1].EstimatedValue)
+}
+
+func TestAuctionPriceHistory(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	auctions := NewNakamaAuctionsSystem(&AuctionsConfig{
+		PriceHistory: &AuctionsConfigPriceHistory{RetentionSec: 7200, MaxSalesPerItem: 4, WindowsSec: []int64{600, 3600}},
+	}).(*AuctionsPamlogix)
+
+	now := time.Now().Unix()
+	sold := func(auctionID string, items []*InventoryItem, bid, saleTimeSec int64) {
+		auction := &Auction{
+			Id:     auctionID,
+			Reward: &AuctionReward{Items: items},
+			Bid:    &AuctionBid{UserId: "winner", Bid: &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: bid}}},
+		}
+		require.NoError(t, auctions.recordSale(ctx, nk, auction, saleTimeSec))
+	}
+	sold("old", []*InventoryItem{{Id: "sword", Count: 1}}, 9000, now-9000)
+	sold("hour", []*InventoryItem{{Id: "sword", Count: 1}}, 400, now-1800)
+	sold("recent1", []*InventoryItem{{Id: "sword", Count: 1}}, 100, now-60)
+	sold("recent2", []*InventoryItem{{Id: "sword", Count: 2}}, 300, now-30)
+	sold("recent3", []*InventoryItem{{Id: "sword", Count: 1}}, 200, now)
+	sold("mixed", []*InventoryItem{{Id: "sword", Count: 1}, {Id: "potion", Count: 1}}, 50, now)
+
+	// The sale past the retention is pruned, and mixed auctions aren't recorded
+	history, err := readAuctionPriceHistory(ctx, nk, "sword")
+	require.NoError(t, err)
+	assert.Len(t, history.Sales, 4)
+	potionHistory, err := readAuctionPriceHistory(ctx, nk, "potion")
+	require.NoError(t, err)
+	assert.Empty(t, potionHistory.Sales)
+
+	priceHistory, err := auctions.GetPriceHistory(ctx, logger, nk, "sword", nil)
+	require.NoError(t, err)
+	require.Len(t, priceHistory.Windows, 2)
+	recent := priceHistory.Windows[0]
+	assert.Equal(t, int64(600), recent.WindowSec)
+	assert.Equal(t, 3, recent.Sales)
+	assert.Equal(t, int64(4), recent.UnitsSold)
+	assert.Equal(t, int64(150), recent.Median[benchCurrency])
+	assert.Equal(t, int64(100), recent.Min[benchCurrency])
+	assert.Equal(t, int64(200), recent.Max[benchCurrency])
+	hour := priceHistory.Windows[1]
+	assert.Equal(t, 4, hour.Sales)
+	assert.Equal(t, int64(175), hour.Median[benchCurrency])
+	assert.Equal(t, int64(400), hour.Max[benchCurrency])
+
+	empty, err := auctions.GetPriceHistory(ctx, logger, nk, "shield", []int64{60})
+	require.NoError(t, err)
+	require.Len(t, empty.Windows, 1)
+	assert.Zero(t, empty.Windows[0].Sales)
+	assert.Nil(t, empty.Windows[0].Median)
+
+	_, err = auctions.GetPriceHistory(ctx, logger, nk, "", nil)
+	assert.ErrorIs(t, err, ErrBadInput)
+	_, err = auctions.GetPriceHistory(ctx, logger, nk, "sword", []int64{-1})
+	assert.ErrorIs(t, err, ErrBadInput)
+}
+
+func TestAuctionArchiveEligibility(t *testing.T) {
+	auctionsSystem := &AuctionsPamlogix{
+		config: &AuctionsConfig{ArchiveAfterSec: 3600},
+	}
+	now := int64(100000)
+
+	tests := []struct {
+		name          string
+		auction       *Auction
+		expectSettled bool
+		expectArchive bool
+	}{
+		{
+			name:          "Active auction is not settled",
+			auction:       &Auction{EndTimeSec: now + 60},
+			expectSettled: false,
+			expectArchive: false,
+		},
+		{
+			name:          "Ended auction not yet claimed by owner",
+			auction:       &Auction{EndTimeSec: now - 7200, HasEnded: true},
+			expectSettled: false,
+			expectArchive: false,
+		},
+		{
+			name:          "Failed auction claimed by owner long ago",
+			auction:       &Auction{EndTimeSec: now - 7200, HasEnded: true, OwnerClaimSec: now - 7000},
+			expectSettled: true,
+			expectArchive: true,
+		},
+		{
+			name: "Won auction with unclaimed winner reward",
+			auction: &Auction{EndTimeSec: now - 7200, HasEnded: true, OwnerClaimSec: now - 7000,
+				Bid: &AuctionBid{UserId: "bidder"}},
+			expectSettled: false,
+			expectArchive: false,
+		},
+		{
+			name: "Won auction settled recently",
+			auction: &Auction{EndTimeSec: now - 7200, HasEnded: true, OwnerClaimSec: now - 7000,
+				Bid: &AuctionBid{UserId: "bidder"}, WinnerClaimSec: now - 60},
+			expectSettled: true,
+			expectArchive: false,
+		},
+		{
+			name:          "Cancelled auction",
+			auction:       &Auction{EndTimeSec: now + 3600, HasEnded: true, CancelTimeSec: now - 3600},
+			expectSettled: true,
+			expectArchive: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectSettled, isAuctionSettled(tt.auction))
+			assert.Equal(t, tt.expectArchive, auctionsSystem.shouldArchive(tt.auction, now))
+		})
+	}
+
+	// Archival is disabled when no archive age is configured
+	disabled := &AuctionsPamlogix{config: &AuctionsConfig{}}
+	assert.False(t, disabled.shouldArchive(&Auction{HasEnded: true, CancelTimeSec: 1}, now))
+}
+
+func TestPruneAuctionHistory(t *testing.T) {
+	entries := []*AuctionHistoryEntry{
+		{AuctionId: "a3", ArchiveTimeSec: 3},
+		{AuctionId: "a2", ArchiveTimeSec: 2},
+		{AuctionId: "a1", ArchiveTimeSec: 1},
+	}
+
+	kept, pruned := pruneAuctionHistory(entries, 2)
+	assert.Len(t, kept, 2)
+	assert.Equal(t, "a3", kept[0].AuctionId)
+	assert.Len(t, pruned, 1)
+	assert.Equal(t, "a1", pruned[0].AuctionId)
+
+	kept, pruned = pruneAuctionHistory(entries, 5)
+	assert.Len(t, kept, 3)
+	assert.Empty(t, pruned)
+}
+
+// archivingNakama archives an auction into a user's history just before the first write of it, as a concurrent
+// archive would.
+type archivingNakama struct {
+	*benchNakama
+	archive func()
+}
+
+func (n *archivingNakama) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	for _, write := range writes {
+		if write.Collection == AuctionArchiveCollectionKey && write.Key == AuctionUserHistoryKey && n.archive != nil {
+			archive := n.archive
+			n.archive = nil
+			archive()
+		}
+	}
+	return n.benchNakama.StorageWrite(ctx, writes)
+}
+
+func TestAuctionHistory_ConcurrentArchive(t *testing.T) {
+	ctx := context.Background()
+	nk := &archivingNakama{benchNakama: newBenchNakama()}
+	auctions := newBenchPamlogix().GetAuctionsSystem().(*AuctionsPamlogix)
+
+	nk.archive = func() {
+		require.NoError(t, auctions.addToUserHistory(ctx, nk, "seller", "auction_2", 2))
+	}
+	require.NoError(t, auctions.addToUserHistory(ctx, nk, "seller", "auction_1", 1))
+
+	// Neither archive overwrites the other's entry
+	entries, _, err := auctions.readUserHistory(ctx, nk, "seller")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "auction_1", entries[0].AuctionId)
+	assert.Equal(t, "auction_2", entries[1].AuctionId)
+}
+
+func TestAuctionClaimOutcome(t *testing.T) {
+	outcome := auctionClaimOutcome("auction_1", nil)
+	assert.Equal(t, "auction_1", outcome.AuctionId)
+	assert.True(t, outcome.Claimed)
+	assert.Empty(t, outcome.Error)
+
+	outcome = auctionClaimOutcome("auction_2", ErrAuctionCannotClaim)
+	assert.Equal(t, "auction_2", outcome.AuctionId)
+	assert.False(t, outcome.Claimed)
+	assert.Equal(t, ErrAuctionCannotClaim.Error(), outcome.Error)
+}
+
+func TestAuctionVersion(t *testing.T) {
+	auction := &Auction{Id: "auction_1", UserId: "owner", EndTimeSec: 1000, CurrentTimeSec: 10, CanBid: true}
+	version, err := auctionVersion(auction, nil)
+	require.NoError(t, err)
+
+	// The same content always hashes to the same version, whatever the viewer saw on read
+	viewed := proto.Clone(auction).(*Auction)
+	viewed.Version = "stale"
+	viewed.CurrentTimeSec = 20
+	viewed.CanBid = false
+	viewedVersion, err := auctionVersion(viewed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, version, viewedVersion)
+
+	bid := proto.Clone(auction).(*Auction)
+	bid.Bid = &AuctionBid{UserId: "bidder1", Bid: &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}}}
+	bidVersion, err := auctionVersion(bid, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, version, bidVersion)
+}
+
+func TestAuctionDeterministicIDs(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"basic": {
+				DurationSec: 3600,
+				BidStart:    &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+			},
+		},
+	}
+
+	// Two runs with the same generators create the same auction
+	create := func() *Auction {
+		auctions := newBenchPamlogix().GetAuctionsSystem().(*AuctionsPamlogix)
+		auctions.SetIDGenerator(NewSequentialIDGenerator(1))
+		auctions.SetVersionGenerator(func(content []byte) string { return "v1" })
+		created, err := auctions.Create(ctx, logger, newBenchNakama(), "owner", "", "basic", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+		require.NoError(t, err)
+		return created
+	}
+	first := create()
+	second := create()
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", first.Id)
+	assert.Equal(t, first.Id, second.Id)
+	assert.Equal(t, "v1", first.Version)
+	assert.Equal(t, first.Version, second.Version)
+}
+
+func TestAuctionReservePrice(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	auctions := p.GetAuctionsSystem()
+
+	for _, userID := range []string{"bidder1", "bidder2"} {
+		_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 100}, nil, false)
+		require.NoError(t, err)
+	}
+
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"reserve": {
+				DurationSec:  3600,
+				BidStart:     &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+				ReservePrice: &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 50}},
+			},
+		},
+	}
+	created, err := auctions.Create(ctx, logger, nk, "owner", "", "reserve", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+	require.NoError(t, err)
+	assert.True(t, created.ReserveNotMet)
+
+	// The reserve price itself is kept out of the auction bidders see
+	reserve, err := auctions.(*AuctionsPamlogix).readReserve(ctx, nk, created.Id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), reserve.Currencies[benchCurrency])
+
+	auction, err := auctions.Bid(ctx, logger, nk, "bidder1", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}}, nil)
+	require.NoError(t, err)
+	assert.True(t, auction.ReserveNotMet)
+	assert.NotEqual(t, created.Version, auction.Version)
+
+	// A bid against the version read before the last one is stale
+	_, err = auctions.Bid(ctx, logger, nk, "bidder2", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 30}}, nil)
+	assert.ErrorIs(t, err, ErrAuctionVersionMismatch)
+
+	endAuction := func(auctionID string) {
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: AuctionCollectionKey, Key: auctionID}})
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		var stored Auction
+		require.NoError(t, json.Unmarshal([]byte(objects[0].Value), &stored))
+		stored.EndTimeSec = time.Now().Unix() - 1
+		require.NoError(t, auctions.(*AuctionsPamlogix).saveAuction(ctx, nk, &stored))
+	}
+
+	t.Run("ends unsold below reserve", func(t *testing.T) {
+		endAuction(created.Id)
+
+		_, err := auctions.ClaimBid(ctx, logger, nk, "bidder1", created.Id)
+		assert.ErrorIs(t, err, ErrAuctionReserveNotMet)
+
+		wallet, err := userWallet(ctx, nk, "bidder1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), wallet[benchCurrency])
+
+		claim, err := auctions.ClaimCreated(ctx, logger, nk, "owner", created.Id)
+		require.NoError(t, err)
+		assert.True(t, claim.Auction.ReserveNotMet)
+		assert.Nil(t, claim.Reward)
+		require.Len(t, claim.ReturnedItems, 1)
+		assert.Equal(t, "sword", claim.ReturnedItems[0].Id)
+	})
+
+	t.Run("sells once a bid meets reserve", func(t *testing.T) {
+		created, err := auctions.Create(ctx, logger, nk, "owner", "", "reserve", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+		require.NoError(t, err)
+
+		auction, err := auctions.Bid(ctx, logger, nk, "bidder2", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 60}}, nil)
+		require.NoError(t, err)
+		assert.False(t, auction.ReserveNotMet)
+
+		endAuction(created.Id)
+
+		won, err := auctions.ClaimBid(ctx, logger, nk, "bidder2", created.Id)
+		require.NoError(t, err)
+		require.Len(t, won.Reward.Items, 1)
+
+		claim, err := auctions.ClaimCreated(ctx, logger, nk, "owner", created.Id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(60), claim.Reward.Currencies[benchCurrency])
+		assert.Empty(t, claim.ReturnedItems)
+	})
+}
+
+func TestAuctionBuyout(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	auctions := newBenchPamlogix().GetAuctionsSystem()
+
+	for _, userID := range []string{"bidder1", "buyer"} {
+		_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 100}, nil, false)
+		require.NoError(t, err)
+	}
+
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"buyout": {
+				DurationSec:  3600,
+				BidStart:     &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+				ReservePrice: &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 90}},
+				Buyout:       &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 50}},
+			},
+		},
+	}
+	create := func() *Auction {
+		created, err := auctions.Create(ctx, logger, nk, "owner", "", "buyout", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+		require.NoError(t, err)
+		require.NotNil(t, created.Buyout)
+		assert.Equal(t, int64(50), created.Buyout.Currencies[benchCurrency])
+		return created
+	}
+
+	t.Run("ends the auction and refunds the bidder", func(t *testing.T) {
+		created := create()
+		auction, err := auctions.Bid(ctx, logger, nk, "bidder1", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}}, nil)
+		require.NoError(t, err)
+
+		_, err = auctions.Buyout(ctx, logger, nk, "owner", "", created.Id, auction.Version)
+		assert.ErrorIs(t, err, ErrAuctionOwnBid)
+		_, err = auctions.Buyout(ctx, logger, nk, "buyer", "", created.Id, created.Version)
+		assert.ErrorIs(t, err, ErrAuctionVersionMismatch)
+
+		bought, err := auctions.Buyout(ctx, logger, nk, "buyer", "", created.Id, auction.Version)
+		require.NoError(t, err)
+		assert.True(t, bought.HasEnded)
+		assert.True(t, bought.CanClaim)
+		assert.False(t, bought.ReserveNotMet)
+		assert.Nil(t, bought.Buyout)
+		assert.Equal(t, "buyer", bought.Bid.UserId)
+
+		wallet, err := userWallet(ctx, nk, "bidder1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), wallet[benchCurrency])
+		wallet, err = userWallet(ctx, nk, "buyer")
+		require.NoError(t, err)
+		assert.Equal(t, int64(50), wallet[benchCurrency])
+
+		_, err = auctions.Bid(ctx, logger, nk, "bidder1", "", created.Id, bought.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 60}}, nil)
+		assert.ErrorIs(t, err, ErrAuctionEnded)
+
+		won, err := auctions.ClaimBid(ctx, logger, nk, "buyer", created.Id)
+		require.NoError(t, err)
+		require.Len(t, won.Reward.Items, 1)
+	})
+
+	t.Run("is withdrawn once bids reach it", func(t *testing.T) {
+		created := create()
+		auction, err := auctions.Bid(ctx, logger, nk, "bidder1", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 50}}, nil)
+		require.NoError(t, err)
+		assert.Nil(t, auction.Buyout)
+
+		_, err = auctions.Buyout(ctx, logger, nk, "buyer", "", created.Id, auction.Version)
+		assert.ErrorIs(t, err, ErrAuctionNoBuyout)
+	})
+}
+
+func TestAuctionBidRetraction(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	auctions := newBenchPamlogix().GetAuctionsSystem()
+
+	for _, userID := range []string{"bidder1", "bidder2"} {
+		_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 100}, nil, false)
+		require.NoError(t, err)
+	}
+
+	retraction := &AuctionsConfigAuctionConditionBidRetraction{
+		WindowSec: 60,
+		Penalty: &AuctionsConfigAuctionConditionFee{
+			Percentage: 0.1,
+			Fixed:      &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 1}},
+		},
+	}
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"retractable": {
+				DurationSec:   3600,
+				BidStart:      &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+				ReservePrice:  &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 25}},
+				BidRetraction: retraction,
+			},
+			// Every bid is within the extension threshold of the end, even once extended
+			"closing": {DurationSec: 3600, ExtensionThresholdSec: 7200, ExtensionSec: 60, BidRetraction: retraction},
+			"final":   {DurationSec: 3600},
+		},
+	}
+	wallet := func(userID string) int64 {
+		wallet, err := userWallet(ctx, nk, userID)
+		require.NoError(t, err)
+		return wallet[benchCurrency]
+	}
+
+	created, err := auctions.Create(ctx, logger, nk, "owner", "", "retractable", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+	require.NoError(t, err)
+	auction, err := auctions.Bid(ctx, logger, nk, "bidder1", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}}, nil)
+	require.NoError(t, err)
+	auction, err = auctions.Bid(ctx, logger, nk, "bidder2", "", created.Id, auction.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 30}}, nil)
+	require.NoError(t, err)
+	assert.False(t, auction.ReserveNotMet)
+
+	// Only the high bidder can retract
+	_, err = auctions.RetractBid(ctx, logger, nk, "bidder1", "", created.Id, auction.Version)
+	assert.ErrorIs(t, err, ErrAuctionCannotRetract)
+
+	// The retracted bid is refunded less 10% and 1, and the earlier bid leads again
+	auction, err = auctions.RetractBid(ctx, logger, nk, "bidder2", "", created.Id, auction.Version)
+	require.NoError(t, err)
+	require.NotNil(t, auction.Bid)
+	assert.Equal(t, "bidder1", auction.Bid.UserId)
+	assert.Equal(t, int64(20), auction.Bid.Bid.Currencies[benchCurrency])
+	assert.Len(t, auction.BidHistory, 1)
+	assert.True(t, auction.ReserveNotMet)
+	assert.Equal(t, int64(96), wallet("bidder2"))
+	assert.Equal(t, int64(80), wallet("bidder1"))
+
+	// With no earlier bid left, the auction goes back to its starting bid
+	auction, err = auctions.RetractBid(ctx, logger, nk, "bidder1", "", created.Id, auction.Version)
+	require.NoError(t, err)
+	assert.Nil(t, auction.Bid)
+	assert.Nil(t, auction.BidFirst)
+	assert.Equal(t, int64(10), auction.BidNext.Currencies[benchCurrency])
+	assert.Equal(t, int64(97), wallet("bidder1"))
+
+	for _, conditionID := range []string{"closing", "final"} {
+		created, err := auctions.Create(ctx, logger, nk, "owner", "", conditionID, nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+		require.NoError(t, err)
+		auction, err := auctions.Bid(ctx, logger, nk, "bidder2", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 10}}, nil)
+		require.NoError(t, err)
+		_, err = auctions.RetractBid(ctx, logger, nk, "bidder2", "", created.Id, auction.Version)
+		assert.ErrorIs(t, err, ErrAuctionCannotRetract, conditionID)
+	}
+}
+
+// biddingNakama places a bid on an auction just before the first conditional write of it, as a concurrent bid would.
+type biddingNakama struct {
+	*benchNakama
+	bid func()
+}
+
+func (n *biddingNakama) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	for _, write := range writes {
+		if write.Collection == AuctionCollectionKey && write.Version != "" && n.bid != nil {
+			bid := n.bid
+			n.bid = nil
+			bid()
+		}
+	}
+	return n.benchNakama.StorageWrite(ctx, writes)
+}
+
+func TestAuctionBidRetraction_ConcurrentBid(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := &biddingNakama{benchNakama: newBenchNakama()}
+	auctions := newBenchPamlogix().GetAuctionsSystem()
+
+	for _, userID := range []string{"bidder1", "bidder2", "bidder3"} {
+		_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 100}, nil, false)
+		require.NoError(t, err)
+	}
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"retractable": {
+				DurationSec:   3600,
+				BidStart:      &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+				BidRetraction: &AuctionsConfigAuctionConditionBidRetraction{WindowSec: 60},
+			},
+		},
+	}
+	wallet := func(userID string) int64 {
+		wallet, err := userWallet(ctx, nk, userID)
+		require.NoError(t, err)
+		return wallet[benchCurrency]
+	}
+
+	created, err := auctions.Create(ctx, logger, nk, "owner", "", "retractable", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+	require.NoError(t, err)
+	auction, err := auctions.Bid(ctx, logger, nk, "bidder1", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}}, nil)
+	require.NoError(t, err)
+	auction, err = auctions.Bid(ctx, logger, nk, "bidder2", "", created.Id, auction.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 30}}, nil)
+	require.NoError(t, err)
+
+	// A bid placed while the retraction is in progress wins, and the retraction moves no money
+	nk.bid = func() {
+		_, err := auctions.Bid(ctx, logger, nk.benchNakama, "bidder3", "", created.Id, auction.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 40}}, nil)
+		require.NoError(t, err)
+	}
+	_, err = auctions.RetractBid(ctx, logger, nk, "bidder2", "", created.Id, auction.Version)
+	assert.ErrorIs(t, err, ErrAuctionVersionMismatch)
+
+	assert.Equal(t, int64(100), wallet("bidder1"))
+	assert.Equal(t, int64(100), wallet("bidder2"))
+	assert.Equal(t, int64(60), wallet("bidder3"))
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: AuctionCollectionKey, Key: created.Id}})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	var saved Auction
+	require.NoError(t, json.Unmarshal([]byte(objects[0].Value), &saved))
+	assert.Equal(t, "bidder3", saved.Bid.UserId)
+}
+
+func TestAuctionBid_RefundsOverMaxBalance(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	auctions := p.GetAuctionsSystem()
+
+	for _, userID := range []string{"bidder1", "bidder2"} {
+		_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 100}, nil, false)
+		require.NoError(t, err)
+	}
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"default": {
+				DurationSec: 3600,
+				BidStart:    &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+			},
+		},
+	}
+
+	created, err := auctions.Create(ctx, logger, nk, "owner", "", "default", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+	require.NoError(t, err)
+	auction, err := auctions.Bid(ctx, logger, nk, "bidder1", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 50}}, nil)
+	require.NoError(t, err)
+
+	// The outbid bidder gets their whole bid back, even if it takes them over the max balance meanwhile
+	p.GetEconomySystem().(*NakamaEconomySystem).config.Currencies = map[string]*EconomyConfigCurrency{
+		benchCurrency: {MaxBalance: 80},
+	}
+	_, err = auctions.Bid(ctx, logger, nk, "bidder2", "", created.Id, auction.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 60}}, nil)
+	require.NoError(t, err)
+
+	wallet, err := userWallet(ctx, nk, "bidder1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), wallet[benchCurrency])
+}
+
+func TestAuctionBid_ConcurrentBid(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := &biddingNakama{benchNakama: newBenchNakama()}
+	auctions := newBenchPamlogix().GetAuctionsSystem()
+
+	for _, userID := range []string{"bidder1", "bidder2", "bidder3"} {
+		_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 100}, nil, false)
+		require.NoError(t, err)
+	}
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"default": {
+				DurationSec: 3600,
+				BidStart:    &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+			},
+		},
+	}
+	wallet := func(userID string) int64 {
+		wallet, err := userWallet(ctx, nk, userID)
+		require.NoError(t, err)
+		return wallet[benchCurrency]
+	}
+
+	created, err := auctions.Create(ctx, logger, nk, "owner", "", "default", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+	require.NoError(t, err)
+	auction, err := auctions.Bid(ctx, logger, nk, "bidder1", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}}, nil)
+	require.NoError(t, err)
+
+	// Of two bids against the same version, the one saved first wins and the other moves no money
+	nk.bid = func() {
+		_, err := auctions.Bid(ctx, logger, nk.benchNakama, "bidder2", "", created.Id, auction.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 30}}, nil)
+		require.NoError(t, err)
+	}
+	_, err = auctions.Bid(ctx, logger, nk, "bidder3", "", created.Id, auction.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 40}}, nil)
+	assert.ErrorIs(t, err, ErrAuctionVersionMismatch)
+
+	assert.Equal(t, int64(100), wallet("bidder1"))
+	assert.Equal(t, int64(70), wallet("bidder2"))
+	assert.Equal(t, int64(100), wallet("bidder3"))
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: AuctionCollectionKey, Key: created.Id}})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	var saved Auction
+	require.NoError(t, json.Unmarshal([]byte(objects[0].Value), &saved))
+	assert.Equal(t, "bidder2", saved.Bid.UserId)
+}
+
+func TestAuctionBuyout_ConcurrentBid(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := &biddingNakama{benchNakama: newBenchNakama()}
+	auctions := newBenchPamlogix().GetAuctionsSystem()
+
+	for _, userID := range []string{"bidder1", "bidder2", "buyer"} {
+		_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 100}, nil, false)
+		require.NoError(t, err)
+	}
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"buyout": {
+				DurationSec: 3600,
+				BidStart:    &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+				Buyout:      &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 50}},
+			},
+		},
+	}
+	wallet := func(userID string) int64 {
+		wallet, err := userWallet(ctx, nk, userID)
+		require.NoError(t, err)
+		return wallet[benchCurrency]
+	}
+
+	created, err := auctions.Create(ctx, logger, nk, "owner", "", "buyout", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+	require.NoError(t, err)
+	auction, err := auctions.Bid(ctx, logger, nk, "bidder1", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}}, nil)
+	require.NoError(t, err)
+
+	// A bid placed while the buyout is in progress wins, and the buyout moves no money
+	nk.bid = func() {
+		_, err := auctions.Bid(ctx, logger, nk.benchNakama, "bidder2", "", created.Id, auction.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 30}}, nil)
+		require.NoError(t, err)
+	}
+	_, err = auctions.Buyout(ctx, logger, nk, "buyer", "", created.Id, auction.Version)
+	assert.ErrorIs(t, err, ErrAuctionVersionMismatch)
+
+	assert.Equal(t, int64(100), wallet("buyer"))
+	assert.Equal(t, int64(100), wallet("bidder1"))
+	assert.Equal(t, int64(70), wallet("bidder2"))
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: AuctionCollectionKey, Key: created.Id}})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	var saved Auction
+	require.NoError(t, json.Unmarshal([]byte(objects[0].Value), &saved))
+	assert.Equal(t, "bidder2", saved.Bid.UserId)
+	assert.False(t, saved.HasEnded)
+}
+
+func TestAuctionTeamBid(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newGroupsNakama()
+	p, teamsSystem := newTreasuryPamlogix()
+	auctions := p.GetAuctionsSystem()
+
+	_, err := teamsSystem.Create(context.WithValue(ctx, runtime.RUNTIME_CTX_USER_ID, "officer"), logger, nk, &TeamCreateRequest{Name: "dragons"})
+	require.NoError(t, err)
+	nk.roles["dragons"]["member"] = api.UserGroupList_UserGroup_MEMBER
+	for _, userID := range []string{"officer", "bidder"} {
+		_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 100}, nil, false)
+		require.NoError(t, err)
+	}
+	_, err = teamsSystem.DepositTreasury(ctx, logger, nk, "officer", "dragons", map[string]int64{benchCurrency: 100})
+	require.NoError(t, err)
+	treasuryCoins := func() int64 {
+		treasury, err := teamsSystem.GetTreasury(ctx, logger, nk, "officer", "dragons")
+		require.NoError(t, err)
+		return treasury.Currencies[benchCurrency]
+	}
+
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"standard": {DurationSec: 3600, BidStart: &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}}},
+		},
+	}
+	created, err := auctions.Create(ctx, logger, nk, "owner", "", "standard", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+	require.NoError(t, err)
+
+	// Team bids are held to the bidder's role limit and need them to be in the team
+	_, err = auctions.TeamBid(ctx, logger, nk, "member", "", "dragons", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}})
+	assert.ErrorIs(t, err, ErrTeamTreasurySpendLimit)
+	_, err = auctions.TeamBid(ctx, logger, nk, "bidder", "", "dragons", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}})
+	assert.ErrorIs(t, err, ErrTeamNotMember)
+
+	// The team's treasury pays for the bid rather than the officer
+	auction, err := auctions.TeamBid(ctx, logger, nk, "officer", "", "dragons", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 20}})
+	require.NoError(t, err)
+	assert.Equal(t, "officer", auction.Bid.UserId)
+	assert.Equal(t, int64(80), treasuryCoins())
+	wallet, err := userWallet(ctx, nk, "officer")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), wallet[benchCurrency])
+
+	// Once outbid, the team bid goes back to the treasury
+	auction, err = auctions.Bid(ctx, logger, nk, "bidder", "", created.Id, auction.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 30}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), treasuryCoins())
+	treasury, err := teamsSystem.GetTreasury(ctx, logger, nk, "officer", "dragons")
+	require.NoError(t, err)
+	assert.Equal(t, "auction_bid_refund", treasury.Ledger[0].Source)
+	assert.Equal(t, map[string]string{"auction_id": created.Id, "reason": "outbid"}, treasury.Ledger[0].Metadata)
+	assert.Equal(t, int64(50), treasury.SpendAllowance[benchCurrency])
+
+	// Outbidding a user refunds their own wallet
+	_, err = auctions.TeamBid(ctx, logger, nk, "officer", "", "dragons", created.Id, auction.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 40}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), treasuryCoins())
+	wallet, err = userWallet(ctx, nk, "bidder")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), wallet[benchCurrency])
+}
+
+// teamBiddingNakama records a team bid on an auction just before the first write of its team bids, as a concurrent
+// team bid would.
+type teamBiddingNakama struct {
+	*benchNakama
+	bid func()
+}
+
+func (n *teamBiddingNakama) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	for _, write := range writes {
+		if write.Collection == AuctionTeamBidsCollectionKey && n.bid != nil {
+			bid := n.bid
+			n.bid = nil
+			bid()
+		}
+	}
+	return n.benchNakama.StorageWrite(ctx, writes)
+}
+
+func TestAuctionTeamBid_ConcurrentRecord(t *testing.T) {
+	ctx := context.Background()
+	nk := &teamBiddingNakama{benchNakama: newBenchNakama()}
+	auctions := newBenchPamlogix().GetAuctionsSystem().(*AuctionsPamlogix)
+
+	first := &AuctionBid{UserId: "officer1", CreateTimeSec: 100}
+	second := &AuctionBid{UserId: "officer2", CreateTimeSec: 101}
+	nk.bid = func() {
+		require.NoError(t, auctions.recordTeamBid(ctx, nk, "auction", "wolves", second))
+	}
+	require.NoError(t, auctions.recordTeamBid(ctx, nk, "auction", "dragons", first))
+
+	// Both team bids are kept, so either is refunded to its treasury rather than its bidder
+	teamID, err := auctions.bidTeam(ctx, nk, "auction", first)
+	require.NoError(t, err)
+	assert.Equal(t, "dragons", teamID)
+	teamID, err = auctions.bidTeam(ctx, nk, "auction", second)
+	require.NoError(t, err)
+	assert.Equal(t, "wolves", teamID)
+}
+
+func TestAuctionListingLimits(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	auctions := newBenchPamlogix().GetAuctionsSystem().(*AuctionsPamlogix)
+	auctions.config.ListingLimits = &AuctionsConfigListingLimits{MaxActive: 2, MaxPerDay: 3}
+
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{"short": {DurationSec: 3600}},
+	}
+	create := func() (*Auction, error) {
+		return auctions.Create(ctx, logger, nk, "seller", "", "short", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+	}
+
+	templates, err := auctions.GetTemplates(ctx, logger, nk, "seller")
+	require.NoError(t, err)
+	require.NotNil(t, templates.Allowance)
+	assert.Equal(t, 2, templates.Allowance.RemainingActiveListings)
+	assert.Equal(t, 3, templates.Allowance.RemainingDailyListings)
+	assert.True(t, templates.Allowance.CanCreate)
+
+	first, err := create()
+	require.NoError(t, err)
+	_, err = create()
+	require.NoError(t, err)
+	_, err = create()
+	assert.ErrorIs(t, err, ErrAuctionListingLimit)
+
+	// Cancelling frees an active listing but still counts towards the daily limit
+	_, err = auctions.Cancel(ctx, logger, nk, "seller", first.Id)
+	require.NoError(t, err)
+	_, err = create()
+	require.NoError(t, err)
+
+	templates, err = auctions.GetTemplates(ctx, logger, nk, "seller")
+	require.NoError(t, err)
+	assert.Equal(t, 2, templates.Allowance.ActiveListings)
+	assert.Equal(t, 0, templates.Allowance.RemainingDailyListings)
+	assert.Greater(t, templates.Allowance.NextListingTimeSec, time.Now().Unix())
+	assert.False(t, templates.Allowance.CanCreate)
+
+	t.Run("cooldown", func(t *testing.T) {
+		auctions.config.ListingLimits = &AuctionsConfigListingLimits{CooldownSec: 60}
+		_, err := auctions.Create(ctx, logger, nk, "other", "", "short", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+		require.NoError(t, err)
+		_, err = auctions.Create(ctx, logger, nk, "other", "", "short", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+		assert.ErrorIs(t, err, ErrAuctionListingCooldown)
+
+		templates, err := auctions.GetTemplates(ctx, logger, nk, "other")
+		require.NoError(t, err)
+		assert.Equal(t, -1, templates.Allowance.RemainingActiveListings)
+		assert.False(t, templates.Allowance.CanCreate)
+	})
+}
+
+func TestAuctionListTemplates(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	auctions := newBenchPamlogix().GetAuctionsSystem().(*AuctionsPamlogix)
+
+	condition := &AuctionsConfigAuctionCondition{
+		DurationSec:  3600,
+		BidStart:     &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+		BidIncrement: &AuctionsConfigAuctionConditionBidIncrement{Percentage: 0.1},
+		ReservePrice: &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 50}},
+	}
+	auctions.config.Auctions = map[string]*AuctionsConfigAuction{
+		"axe":    {Category: "weapons", Conditions: map[string]*AuctionsConfigAuctionCondition{"short": condition}},
+		"bow":    {Category: "weapons", Conditions: map[string]*AuctionsConfigAuctionCondition{"short": condition}},
+		"cloak":  {Category: "armor", Conditions: map[string]*AuctionsConfigAuctionCondition{"short": condition}},
+		"dagger": {Category: "weapons", Conditions: map[string]*AuctionsConfigAuctionCondition{"short": condition}},
+	}
+
+	page, err := auctions.ListTemplates(ctx, logger, nk, "seller", "weapons", 2, "")
+	require.NoError(t, err)
+	require.Len(t, page.Templates, 2)
+	assert.Equal(t, "axe", page.Templates[0].Id)
+	assert.Equal(t, "bow", page.Templates[1].Id)
+	assert.Equal(t, &AuctionTemplateConditionSummary{DurationSec: 3600, BidStart: &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 10}}}, page.Templates[0].Conditions["short"])
+	require.NotEmpty(t, page.Cursor)
+
+	page, err = auctions.ListTemplates(ctx, logger, nk, "seller", "weapons", 2, page.Cursor)
+	require.NoError(t, err)
+	require.Len(t, page.Templates, 1)
+	assert.Equal(t, "dagger", page.Templates[0].Id)
+	assert.Empty(t, page.Cursor)
+
+	page, err = auctions.ListTemplates(ctx, logger, nk, "seller", "", 0, "")
+	require.NoError(t, err)
+	assert.Len(t, page.Templates, 4)
+
+	// The details of one template include the rules left out of the summary, but never the reserve price
+	details, err := auctions.GetTemplate(ctx, logger, nk, "seller", "bow")
+	require.NoError(t, err)
+	assert.Equal(t, "weapons", details.Category)
+	assert.Equal(t, 0.1, details.Conditions["short"].BidIncrement.Percentage)
+
+	_, err = auctions.GetTemplate(ctx, logger, nk, "seller", "missing")
+	assert.ErrorIs(t, err, ErrAuctionTemplateNotFound)
+}
+
+func TestAuctionClaimCreated_Proceeds(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	auctions := newBenchPamlogix().GetAuctionsSystem().(*AuctionsPamlogix)
+	sinkUserID := uuid.New().String()
+	auctions.config.FeeSink = &AuctionsConfigFeeSink{UserId: sinkUserID}
+
+	_, _, err := nk.WalletUpdate(ctx, "bidder", map[string]int64{benchCurrency: 100}, nil, false)
+	require.NoError(t, err)
+
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"taxed": {
+				DurationSec: 3600,
+				Fee: &AuctionsConfigAuctionConditionFee{
+					Percentage: 0.1,
+					Fixed:      &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 2}},
+				},
+			},
+		},
+	}
+	created, err := auctions.Create(ctx, logger, nk, "owner", "", "taxed", nil, 0, []*InventoryItem{{Id: "sword", Count: 1}}, template)
+	require.NoError(t, err)
+	auction, err := auctions.Bid(ctx, logger, nk, "bidder", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 50}}, nil)
+	require.NoError(t, err)
+
+	auction.EndTimeSec = time.Now().Unix() - 1
+	require.NoError(t, auctions.saveAuction(ctx, nk, auction))
+
+	// The creator is credited the winning bid less the fee, which goes to the fee sink
+	claim, err := auctions.ClaimCreated(ctx, logger, nk, "owner", created.Id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), claim.Reward.Currencies[benchCurrency])
+	assert.Equal(t, int64(7), claim.Fee.Currencies[benchCurrency])
+	assert.Equal(t, int64(43), claim.Proceeds.Currencies[benchCurrency])
+
+	wallet, err := userWallet(ctx, nk, "owner")
+	require.NoError(t, err)
+	assert.Equal(t, int64(43), wallet[benchCurrency])
+	wallet, err = userWallet(ctx, nk, sinkUserID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), wallet[benchCurrency])
+
+	ledger, err := readAuctionFeeLedger(ctx, nk, auctionFeeLedgerKey(time.Now().Unix()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), ledger.Currencies[benchCurrency])
+	assert.Equal(t, int64(1), ledger.Auctions)
+
+	// Claiming again credits nothing more
+	_, err = auctions.ClaimCreated(ctx, logger, nk, "owner", created.Id)
+	assert.ErrorIs(t, err, ErrAuctionCannotClaim)
+	wallet, err = userWallet(ctx, nk, "owner")
+	require.NoError(t, err)
+	assert.Equal(t, int64(43), wallet[benchCurrency])
+
+	// A fixed fee above the reward takes no more than the reward
+	proceeds, fee := auctionProceeds(&AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 3}}, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 5}})
+	assert.Empty(t, proceeds.Currencies)
+	assert.Equal(t, int64(3), fee.Currencies[benchCurrency])
+
+	assert.Error(t, validateAuctionFeeSink(&AuctionsConfigFeeSink{UserId: "treasury"}))
+	assert.NoError(t, validateAuctionFeeSink(&AuctionsConfigFeeSink{UserId: sinkUserID}))
+}
+
+func TestAuctionEnrichBidders(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := NewMockNakama(t)
+	nk.On("AccountsGetId", mock.Anything, []string{"bidder1", "bidder2"}).Return([]*api.Account{
+		{User: &api.User{Id: "bidder1", Username: "bidder_one", DisplayName: "Bidder One", AvatarUrl: "https://example.com/1.png"}},
+		{User: &api.User{Id: "bidder2", Username: "bidder_two"}},
+	}, nil).Once()
+
+	auctionsSystem := NewNakamaAuctionsSystem(&AuctionsConfig{}).(*AuctionsPamlogix)
+	newAuction := func() *Auction {
+		return &Auction{
+			Id:         "auction1",
+			Bid:        &AuctionBid{UserId: "bidder1"},
+			BidFirst:   &AuctionBid{UserId: "bidder2"},
+			BidHistory: []*AuctionBid{{UserId: "bidder1"}, {UserId: "bidder2"}},
+		}
+	}
+
+	auction := newAuction()
+	require.NoError(t, auctionsSystem.EnrichBidders(ctx, logger, nk, []*Auction{auction}))
+	assert.Equal(t, "Bidder One", auction.Bid.DisplayName)
+	assert.Equal(t, "https://example.com/1.png", auction.Bid.AvatarUrl)
+	// Bidders without a display name are shown by their username
+	assert.Equal(t, "bidder_two", auction.BidFirst.DisplayName)
+	assert.Equal(t, "Bidder One", auction.BidHistory[0].DisplayName)
+	assert.Equal(t, "bidder_two", auction.BidHistory[1].DisplayName)
+
+	// The bidders are cached, so enriching again doesn't look them up
+	auction = newAuction()
+	require.NoError(t, auctionsSystem.EnrichBidders(ctx, logger, nk, []*Auction{auction}))
+	assert.Equal(t, "Bidder One", auction.Bid.DisplayName)
+	nk.AssertNumberOfCalls(t, "AccountsGetId", 1)
+
+	// Listings are left as they are unless the request asks for bidders
+	auction = newAuction()
+	enrichAuctionBidders(ctx, logger, nk, auctionsSystem, &AuctionList{Auctions: []*Auction{auction}}, false)
+	assert.Empty(t, auction.Bid.DisplayName)
+}
+
+func TestAuctionListSearch(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	auctions := newBenchPamlogix().GetAuctionsSystem()
+
+	create := func(ownerID, itemID string, durationSec, bidStart int64) *Auction {
+		template := &AuctionsConfigAuction{
+			Conditions: map[string]*AuctionsConfigAuctionCondition{
+				"default": {
+					DurationSec: durationSec,
+					BidStart:    &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: bidStart}},
+				},
+			},
+		}
+		created, err := auctions.Create(ctx, logger, nk, ownerID, "", "default", nil, 0, []*InventoryItem{{Id: itemID, Count: 1}}, template)
+		require.NoError(t, err)
+		return created
+	}
+	cheapSword := create("owner1", "sword", 600, 10)
+	dearSword := create("owner2", "sword", 7200, 80)
+	potion := create("owner1", "potion", 3600, 40)
+
+	ids := func(list *AuctionList) []string {
+		result := make([]string, 0, len(list.Auctions))
+		for _, auction := range list.Auctions {
+			result = append(result, auction.Id)
+		}
+		return result
+	}
+
+	_, err := auctions.List(ctx, logger, nk, "buyer", "item_id:sword", []string{"-price"}, 10, "")
+	require.ErrorIs(t, err, ErrAuctionQueryInvalid)
+
+	list, err := auctions.List(ctx, logger, nk, "buyer", "item_id:sword currency:"+benchCurrency, []string{"-price"}, 10, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{dearSword.Id, cheapSword.Id}, ids(list))
+
+	list, err = auctions.List(ctx, logger, nk, "buyer", "currency:"+benchCurrency+" min_price:20 max_price:50", nil, 10, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{potion.Id}, ids(list))
+
+	list, err = auctions.List(ctx, logger, nk, "buyer", `seller:"owner1"`, []string{"end_time_sec"}, 1, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{cheapSword.Id}, ids(list))
+	list, err = auctions.List(ctx, logger, nk, "buyer", `seller:"owner1"`, []string{"end_time_sec"}, 1, list.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{potion.Id}, ids(list))
+	assert.Empty(t, list.NextCursor)
+
+	list, err = auctions.List(ctx, logger, nk, "buyer", "ending_within:3600", []string{"-end_time_sec"}, 10, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{potion.Id, cheapSword.Id}, ids(list))
+
+	for _, query := range []string{"rarity:epic", "item_id:", "min_price:ten currency:coins", `seller:"owner1`, "currency:coins min_price:50 max_price:10"} {
+		_, err = auctions.List(ctx, logger, nk, "buyer", query, nil, 10, "")
+		assert.Error(t, err, query)
+	}
+	_, err = auctions.List(ctx, logger, nk, "buyer", "", []string{"name"}, 10, "")
+	assert.Error(t, err)
+}
+
+func TestAuctionListUsesSearchIndex(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	auctions := newBenchPamlogix().GetAuctionsSystem().(*AuctionsPamlogix)
+	auctions.searchIndex = true
+
+	query := `+value.end_time_sec:>0 +value.user_id:"owner1" +value.reward.items.item_sets:"weapons"`
+	nk.MockNakamaModule.On("StorageIndexList", ctx, "", auctionSearchIndexName, query, auctionSearchPageSize, mock.Anything, "").
+		Return(&api.StorageObjects{Objects: []*api.StorageObject{
+			{Key: "a", Value: `{"id":"a","user_id":"owner1","end_time_sec":4102444800,"reward":{"items":[{"id":"sword","item_sets":["weapons"]}]}}`},
+			// The index may lag behind a write which changed the auction
+			{Key: "b", Value: `{"id":"b","user_id":"owner2","end_time_sec":4102444800,"reward":{"items":[{"id":"sword","item_sets":["weapons"]}]}}`},
+		}}, "", nil).Once()
+
+	list, err := auctions.List(ctx, logger, nk, "buyer", "seller:owner1 item_set:weapons", nil, 10, "")
+	require.NoError(t, err)
+	require.Len(t, list.Auctions, 1)
+	assert.Equal(t, "a", list.Auctions[0].Id)
+	nk.MockNakamaModule.AssertExpectations(t)
+}
+
+func TestAuctionEscrowsListedItems(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	auctions := p.GetAuctionsSystem().(*AuctionsPamlogix)
+	inventory := p.GetInventorySystem()
+
+	_, _, err := nk.WalletUpdate(ctx, "bidder", map[string]int64{benchCurrency: 100}, nil, false)
+	require.NoError(t, err)
+
+	template := &AuctionsConfigAuction{
+		Conditions: map[string]*AuctionsConfigAuctionCondition{
+			"basic": {
+				DurationSec: 3600,
+				BidStart:    &AuctionsConfigAuctionConditionBid{Currencies: map[string]int64{benchCurrency: 10}},
+			},
+		},
+	}
+	listSword := func() (string, *Auction) {
+		_, newItems, _, _, err := inventory.GrantItems(ctx, logger, nk, "seller", map[string]int64{"sword": 1}, false)
+		require.NoError(t, err)
+		require.Len(t, newItems, 1)
+		var instanceID string
+		for id := range newItems {
+			instanceID = id
+		}
+
+		created, err := auctions.Create(ctx, logger, nk, "seller", "", "basic", []string{instanceID}, 0, nil, template)
+		require.NoError(t, err)
+		require.Len(t, created.Reward.Items, 1)
+		assert.Equal(t, "sword", created.Reward.Items[0].Id)
+		assert.Equal(t, "Sword", created.Reward.Items[0].Name)
+		return instanceID, created
+	}
+	hasItem := func(userID, instanceID string) bool {
+		userInventory, err := inventory.ListInventoryItems(ctx, logger, nk, userID, "")
+		require.NoError(t, err)
+		_, found := userInventory.Items[instanceID]
+		return found
+	}
+
+	t.Run("returns items on cancel", func(t *testing.T) {
+		instanceID, created := listSword()
+		assert.False(t, hasItem("seller", instanceID))
+
+		// Escrowed items can't be listed twice
+		_, err := auctions.Create(ctx, logger, nk, "seller", "", "basic", []string{instanceID}, 0, nil, template)
+		assert.ErrorIs(t, err, ErrInventoryItemNotFound)
+
+		_, err = auctions.Cancel(ctx, logger, nk, "seller", created.Id)
+		require.NoError(t, err)
+		assert.True(t, hasItem("seller", instanceID))
+	})
+
+	t.Run("transfers items to the winner", func(t *testing.T) {
+		instanceID, created := listSword()
+		_, err := auctions.Bid(ctx, logger, nk, "bidder", "", created.Id, created.Version, &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 10}}, nil)
+		require.NoError(t, err)
+
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: AuctionCollectionKey, Key: created.Id}})
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		var stored Auction
+		require.NoError(t, json.Unmarshal([]byte(objects[0].Value), &stored))
+		stored.EndTimeSec = time.Now().Unix() - 1
+		require.NoError(t, auctions.saveAuction(ctx, nk, &stored))
+
+		_, err = auctions.ClaimBid(ctx, logger, nk, "bidder", created.Id)
+		require.NoError(t, err)
+		assert.True(t, hasItem("bidder", instanceID))
+		assert.False(t, hasItem("seller", instanceID))
+	})
+}
@@ -22,6 +22,7 @@ var (
 	ErrSessionUser        = runtime.NewError("user ID in session", INVALID_ARGUMENT_ERROR_CODE)
 	ErrSystemNotAvailable = runtime.NewError("system not available", INTERNAL_ERROR_CODE)
 	ErrSystemNotFound     = runtime.NewError("system not found", INTERNAL_ERROR_CODE)
+	ErrSystemNotInRollout = runtime.NewError("system not available to this user", PERMISSION_DENIED_ERROR_CODE)
 )
 
 // The BaseSystem provides various small features which aren't large enough to be in their own gameplay systems.
@@ -36,8 +37,56 @@ type BaseSystem interface {
 
 	// Sync processes an operation to update the server with offline state changes.
 	Sync(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, req *SyncRequest) (resp *SyncResponse, err error)
+
+	// SendNotification sends the notification for a system event to a user, rendered from the event's template in the
+	// recipient's language with the given variables filled in.
+	SendNotification(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, event string, vars map[string]string, content map[string]interface{}) error
+
+	// GetNotificationPreferences returns the user's notification preferences.
+	GetNotificationPreferences(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*NotificationPreferences, error)
+
+	// SetNotificationPreferences replaces the user's notification preferences.
+	SetNotificationPreferences(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, prefs *NotificationPreferences) (*NotificationPreferences, error)
+
+	// FlushNotificationDigest sends the user's pending digest now unless they're in their quiet hours. Game code can
+	// call it on a schedule so digests don't wait for the user's next notification.
+	FlushNotificationDigest(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) error
+
+	// FeatureFlags returns which client features are enabled, derived from the loaded gameplay systems and the
+	// configured kill switches.
+	FeatureFlags() map[string]bool
+
+	// GetContentCalendar returns the upcoming and running events, sales and seasons of the content calendar.
+	GetContentCalendar() *ContentCalendar
+
+	// LockAccount freezes the user's economy pending review, so every grant, spend, bid and claim RPC they call fails
+	// with ErrAccountUnderReview. Anomaly detection in game code calls it when it flags a user.
+	LockAccount(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, reason, source string) (*AccountLock, error)
+
+	// GetAccountLock returns the user's economy lock, or nil if they aren't locked.
+	GetAccountLock(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AccountLock, error)
+
+	// UnlockAccount lifts the user's economy lock after a review clears them.
+	UnlockAccount(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) error
+
+	// PurgeAccount deletes a locked user's account and all of its state after a review confirms cheating.
+	PurgeAccount(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) error
+
+	// ExportData compiles the user's wallet history, inventory, donations, auctions and event participation into a
+	// storage object they can read for a limited time, for their data access request.
+	ExportData(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*DataExportReceipt, error)
 }
 
+// Feature flag names returned to clients with the sync response. Store sections are flagged per store item category,
+// as FeatureFlagStoreSectionPrefix followed by the category.
+const (
+	FeatureFlagAuctions          = "auctions"
+	FeatureFlagEventLeaderboards = "event_leaderboards"
+	FeatureFlagStore             = "store"
+
+	FeatureFlagStoreSectionPrefix = "store_section."
+)
+
 // BaseSystemConfig is the data definition for the BaseSystem type.
 type BaseSystemConfig struct {
 	RateAppSmtpAddr          string `json:"rate_app_smtp_addr,omitempty"`            // "smtp.gmail.com"
@@ -50,6 +99,69 @@ type BaseSystemConfig struct {
 	RateAppSmtpPort          int    `json:"rate_app_smtp_port,omitempty"`            // 587
 
 	RateAppTemplate string `json:"rate_app_template"` // HTML email template
+
+	// KillSwitches turns off features by flag name even when their systems are loaded, e.g. {"auctions": true}. The
+	// "store" switch turns off every store section.
+	KillSwitches map[string]bool `json:"kill_switches,omitempty"`
+	// Rollouts limits the RPCs of gameplay systems to some users during a soft launch, keyed by system name, e.g.
+	// "auctions". Systems without a rollout are open to everyone.
+	Rollouts map[string]*BaseSystemConfigRollout `json:"rollouts,omitempty"`
+
+	// NotificationTemplates overrides the notification sent for each system event, keyed by event name.
+	NotificationTemplates map[string]*NotificationTemplate `json:"notification_templates,omitempty"`
+	// NotificationDigestIntervalSec is how long held back notifications wait before they're sent as one digest. The
+	// default is one hour.
+	NotificationDigestIntervalSec int64 `json:"notification_digest_interval_sec,omitempty"`
+	// NotificationDispatch queues notifications and sends them in batches in the background, so RPCs don't wait on
+	// them. Nil sends each notification before the call making it returns.
+	NotificationDispatch *BaseSystemConfigNotificationDispatch `json:"notification_dispatch,omitempty"`
+
+	// StorageSweepIntervalSec is how often expired storage objects are deleted, such as old placement statuses, expired
+	// purchase intents and ended modifiers. Zero leaves sweeping to the storage sweep RPC, unless Jobs schedules it.
+	StorageSweepIntervalSec int64 `json:"storage_sweep_interval_sec,omitempty"`
+	// Jobs tunes the schedule of the background jobs, keyed by job name, e.g. "storage_sweep", "modifier_compaction",
+	// "auction_archive" and "event_leaderboard_cleanup". Each run is made by one server of the cluster.
+	Jobs map[string]*BaseSystemConfigJob `json:"jobs,omitempty"`
+
+	// DataExportTTLSec is how long a player's data export can be downloaded before it's deleted. The default is seven
+	// days.
+	DataExportTTLSec int64 `json:"data_export_ttl_sec,omitempty"`
+
+	// LogLevels sets the lowest level each system logs at, keyed by system name, e.g. {"auctions": "debug"}. The
+	// "default" key applies to systems without their own. Levels are "debug", "info", "warn" and "error".
+	LogLevels map[string]string `json:"log_levels,omitempty"`
+
+	// ContentCalendar schedules upcoming events, sales and seasons, keyed by entry ID. Clients list it to show what's
+	// coming soon, and the systems prepare the content it refers to ahead of its start.
+	ContentCalendar map[string]*ContentCalendarConfigEntry `json:"content_calendar,omitempty"`
+	// ContentCalendarLeadSec is how long before an entry starts its event leaderboards are created. The default is one
+	// hour.
+	ContentCalendarLeadSec int64 `json:"content_calendar_lead_sec,omitempty"`
+	// ContentCalendarIntervalSec is how often each server prepares the content calendar. The default is five minutes.
+	ContentCalendarIntervalSec int64 `json:"content_calendar_interval_sec,omitempty"`
+
+	// StoragePermissions overrides the permissions of the systems' storage collections, keyed by collection, e.g.
+	// {"inventory": {"read": 2}} to let players inspect each other's inventories. Collections without an entry keep
+	// the permissions the systems write them with.
+	StoragePermissions map[string]*BaseSystemConfigStoragePermissions `json:"storage_permissions,omitempty"`
+}
+
+// BaseSystemConfigJob is the schedule of a background job. A job without an interval or cron schedule, its own or set
+// here, doesn't run.
+type BaseSystemConfigJob struct {
+	// IntervalSec runs the job every interval, aligned to multiples of the interval.
+	IntervalSec int64 `json:"interval_sec,omitempty"`
+	// Cron runs the job on a cron schedule instead of an interval, e.g. "0 4 * * *" for 4am UTC every day.
+	Cron string `json:"cron,omitempty"`
+	// Disabled stops the job running on this deployment.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// BaseSyncResponse is the sync RPC response: the synced state plus the feature flags clients use to hide features the
+// server has turned off.
+type BaseSyncResponse struct {
+	*SyncResponse
+	FeatureFlags map[string]bool `json:"feature_flags"`
 }
 
 type AfterAuthenticateFn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, session *api.Session) error
@@ -64,11 +176,29 @@ type Pamlogix interface {
 
 	AddPublisher(publisher Publisher)
 
+	// SendPublisherEvents sends events to every publisher. Game code can send its own events this way, such as a player
+	// reaching a level, for systems listening on them like incentive milestones.
+	SendPublisherEvents(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, events []*PublisherEvent)
+
 	SetAfterAuthenticate(fn AfterAuthenticateFn)
 
-	// SetCollectionResolver sets a function that may change the storage collection target for Pamlogix systems. Not typically used.
+	// SetCollectionResolver sets a function that may change the storage collection target for Pamlogix systems. It's
+	// applied to all storage the systems use in RPCs and background work, before any namespace prefix. Not typically used.
 	SetCollectionResolver(fn CollectionResolverFn)
 
+	// SetTextModeration sets a function that checks user-supplied text, such as team chat messages and auction listings,
+	// before it is stored or sent.
+	SetTextModeration(fn TextModerationFn)
+
+	// SetJsonPolicy sets how the JSON RPCs name and populate the fields of their payloads, e.g. camelCase for clients
+	// built on protojson. Requests are accepted in either casing.
+	SetJsonPolicy(policy *JsonPolicy)
+
+	// RegisterJob adds a background job, such as a periodic cleanup of game-specific storage. Each scheduled run is made
+	// by whichever server of the cluster claims it first. The base config's jobs, keyed by name, override the default
+	// interval or disable it; with neither the job doesn't run.
+	RegisterJob(name string, defaultIntervalSec int64, fn JobFn)
+
 	GetAchievementsSystem() AchievementsSystem
 	GetBaseSystem() BaseSystem
 	GetEconomySystem() EconomySystem
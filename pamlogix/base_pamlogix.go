@@ -3,20 +3,50 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/smtp"
+	"time"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
-type BasePamlogix struct{}
+var _ BaseSystem = (*BasePamlogix)(nil)
+
+type BasePamlogix struct {
+	config   *BaseSystemConfig
+	pamlogix Pamlogix
+	// dispatcher sends notifications in the background when the config sets up notification dispatch.
+	dispatcher *notificationDispatcher
+}
+
+func NewBaseSystem(config *BaseSystemConfig) *BasePamlogix {
+	if config == nil {
+		config = &BaseSystemConfig{}
+	}
+	return &BasePamlogix{
+		config: config,
+	}
+}
+
+// SetPamlogix sets the Pamlogix instance for this base system
+func (b *BasePamlogix) SetPamlogix(pl Pamlogix) {
+	b.pamlogix = pl
+}
 
 func (b *BasePamlogix) GetType() SystemType {
 	return SystemTypeBase
 }
 
 func (b *BasePamlogix) GetConfig() any {
-	return &BaseSystemConfig{}
+	return b.config
+}
+
+// startNotificationDispatcher sends the notifications made from now on in batches in the background.
+func (b *BasePamlogix) startNotificationDispatcher(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) {
+	dispatcher := newNotificationDispatcher(b.config.NotificationDispatch)
+	dispatcher.start(ctx, logger, nk)
+	b.dispatcher = dispatcher
 }
 
 // RateApp uses the SMTP configuration to receive feedback from players via email.
@@ -46,7 +76,7 @@ func (b *BasePamlogix) RateApp(ctx context.Context, logger runtime.Logger, nk ru
 }
 
 // SetDevicePrefs sets push notification tokens on a user's account so push messages can be received.
-func (b *BasePamlogix) SetDevicePrefs(ctx context.Context, db *sql.DB, userID, deviceID, pushTokenAndroid, pushTokenIos string, preferences map[string]bool) error {
+func (b *BasePamlogix) SetDevicePrefs(ctx context.Context, logger runtime.Logger, db *sql.DB, userID, deviceID, pushTokenAndroid, pushTokenIos string, preferences map[string]bool) error {
 	// Implement your logic here
 	return nil
 }
@@ -56,3 +86,179 @@ func (b *BasePamlogix) Sync(ctx context.Context, logger runtime.Logger, nk runti
 	// Implement your logic here
 	return &SyncResponse{}, nil
 }
+
+// SendNotification sends the notification for a system event to a user, using the configured template for the event
+// or the default one, unless the user's preferences mute or defer it.
+func (b *BasePamlogix) SendNotification(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, event string, vars map[string]string, content map[string]interface{}) error {
+	return sendNotification(ctx, logger, nk, b.config, b.dispatcher, userID, event, vars, content)
+}
+
+// GetNotificationPreferences returns the user's notification preferences.
+func (b *BasePamlogix) GetNotificationPreferences(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*NotificationPreferences, error) {
+	state, err := readNotificationState(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to read notification preferences for user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	return state.preferences, nil
+}
+
+// SetNotificationPreferences replaces the user's notification preferences.
+func (b *BasePamlogix) SetNotificationPreferences(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, prefs *NotificationPreferences) (*NotificationPreferences, error) {
+	if prefs == nil {
+		prefs = &NotificationPreferences{}
+	}
+	if err := validateNotificationPreferences(prefs); err != nil {
+		return nil, err
+	}
+
+	value, err := json.Marshal(prefs)
+	if err != nil {
+		logger.Error("Failed to marshal notification preferences: %v", err)
+		return nil, ErrPayloadEncode
+	}
+	if _, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      notificationsStorageCollection,
+			Key:             notificationPreferencesStorageKey,
+			UserID:          userID,
+			Value:           string(value),
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	}); err != nil {
+		logger.Error("Failed to write notification preferences for user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+
+	return prefs, nil
+}
+
+// FlushNotificationDigest sends the user's pending digest now unless they're in their quiet hours.
+func (b *BasePamlogix) FlushNotificationDigest(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) error {
+	state, err := readNotificationState(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to read notification digest for user %s: %v", userID, err)
+		return ErrInternal
+	}
+	if state.preferences.QuietHours.contains(time.Now()) {
+		return nil
+	}
+	return flushNotificationDigest(ctx, logger, nk, b.config, b.dispatcher, userID, state)
+}
+
+// LockAccount freezes the user's economy pending review. Locking a locked user keeps the original lock.
+func (b *BasePamlogix) LockAccount(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, reason, source string) (*AccountLock, error) {
+	lock, err := b.GetAccountLock(ctx, logger, nk, userID)
+	if err != nil {
+		return nil, err
+	}
+	if lock != nil {
+		return lock, nil
+	}
+
+	lock = &AccountLock{
+		UserId:      userID,
+		Reason:      reason,
+		Source:      source,
+		LockTimeSec: time.Now().Unix(),
+	}
+	if err := writeAccountLock(ctx, nk, lock); err != nil {
+		logger.Error("Failed to write account lock for user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	logger.Warn("Locked economy of user %s pending review: %s (%s)", userID, reason, source)
+	return lock, nil
+}
+
+// GetAccountLock returns the user's economy lock, or nil if they aren't locked.
+func (b *BasePamlogix) GetAccountLock(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*AccountLock, error) {
+	lock, err := readAccountLock(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to read account lock of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	return lock, nil
+}
+
+// UnlockAccount lifts the user's economy lock.
+func (b *BasePamlogix) UnlockAccount(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) error {
+	lock, err := b.GetAccountLock(ctx, logger, nk, userID)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return ErrAccountNotLocked
+	}
+
+	if err := nk.StorageDelete(ctx, []*runtime.StorageDelete{
+		{Collection: accountLockStorageCollection, Key: accountLockStorageKey, UserID: userID},
+	}); err != nil {
+		logger.Error("Failed to delete account lock of user %s: %v", userID, err)
+		return ErrInternal
+	}
+	logger.Info("Unlocked economy of user %s", userID)
+	return nil
+}
+
+// PurgeAccount deletes a locked user's account. Only locked accounts can be purged, so a mistyped user ID can't
+// delete a player in good standing.
+func (b *BasePamlogix) PurgeAccount(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) error {
+	lock, err := b.GetAccountLock(ctx, logger, nk, userID)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return ErrAccountNotLocked
+	}
+
+	if err := nk.AccountDeleteId(ctx, userID, false); err != nil {
+		logger.Error("Failed to purge account of user %s: %v", userID, err)
+		return ErrInternal
+	}
+	logger.Warn("Purged locked account of user %s: %s (%s)", userID, lock.Reason, lock.Source)
+	return nil
+}
+
+// FeatureFlags returns which client features are enabled. A feature is enabled when the system behind it is loaded and
+// its kill switch isn't set, so clients can hide what the server won't serve.
+func (b *BasePamlogix) FeatureFlags() map[string]bool {
+	flags := map[string]bool{
+		FeatureFlagAuctions:          false,
+		FeatureFlagEventLeaderboards: false,
+		FeatureFlagStore:             false,
+	}
+	if b.pamlogix == nil {
+		return flags
+	}
+
+	flags[FeatureFlagAuctions] = b.pamlogix.GetAuctionsSystem() != nil && !b.killed(FeatureFlagAuctions)
+	flags[FeatureFlagEventLeaderboards] = b.pamlogix.GetEventLeaderboardsSystem() != nil && !b.killed(FeatureFlagEventLeaderboards)
+
+	economySystem := b.pamlogix.GetEconomySystem()
+	if economySystem == nil {
+		return flags
+	}
+	economyConfig, ok := economySystem.GetConfig().(*EconomyConfig)
+	if !ok || economyConfig == nil {
+		return flags
+	}
+
+	// A store section is active while at least one of its items is enabled
+	storeKilled := b.killed(FeatureFlagStore)
+	for _, storeItem := range economyConfig.StoreItems {
+		if storeItem == nil || storeItem.Category == "" {
+			continue
+		}
+		flag := FeatureFlagStoreSectionPrefix + storeItem.Category
+		active := !storeItem.Disabled && !storeKilled && !b.killed(flag)
+		flags[flag] = flags[flag] || active
+		flags[FeatureFlagStore] = flags[FeatureFlagStore] || active
+	}
+
+	return flags
+}
+
+func (b *BasePamlogix) killed(flag string) bool {
+	return b.config != nil && b.config.KillSwitches[flag]
+}
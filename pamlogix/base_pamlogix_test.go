@@ -0,0 +1,144 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newFeatureFlagsPamlogix(killSwitches map[string]bool) *pamlogixImpl {
+	p := &pamlogixImpl{
+		systems: make(map[SystemType]System),
+	}
+
+	baseSystem := NewBaseSystem(&BaseSystemConfig{KillSwitches: killSwitches})
+	baseSystem.SetPamlogix(p)
+	p.systems[SystemTypeBase] = baseSystem
+	p.systems[SystemTypeAuctions] = NewNakamaAuctionsSystem(&AuctionsConfig{})
+	p.systems[SystemTypeEconomy] = NewNakamaEconomySystem(&EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"gems_small": {Category: "currency"},
+			"gems_large": {Category: "currency", Disabled: true},
+			"bundle":     {Category: "bundles", Disabled: true},
+			"skin":       {Category: "cosmetics"},
+		},
+	})
+
+	return p
+}
+
+func TestBaseFeatureFlags(t *testing.T) {
+	flags := newFeatureFlagsPamlogix(nil).GetBaseSystem().FeatureFlags()
+
+	assert.Equal(t, map[string]bool{
+		FeatureFlagAuctions:                         true,
+		FeatureFlagEventLeaderboards:                false,
+		FeatureFlagStore:                            true,
+		FeatureFlagStoreSectionPrefix + "currency":  true,
+		FeatureFlagStoreSectionPrefix + "bundles":   false,
+		FeatureFlagStoreSectionPrefix + "cosmetics": true,
+	}, flags)
+}
+
+func TestBaseFeatureFlags_KillSwitches(t *testing.T) {
+	flags := newFeatureFlagsPamlogix(map[string]bool{
+		FeatureFlagAuctions:                         true,
+		FeatureFlagStoreSectionPrefix + "cosmetics": true,
+	}).GetBaseSystem().FeatureFlags()
+
+	assert.False(t, flags[FeatureFlagAuctions])
+	assert.False(t, flags[FeatureFlagStoreSectionPrefix+"cosmetics"])
+	assert.True(t, flags[FeatureFlagStoreSectionPrefix+"currency"])
+	assert.True(t, flags[FeatureFlagStore])
+
+	// The store switch turns off every section
+	flags = newFeatureFlagsPamlogix(map[string]bool{FeatureFlagStore: true}).GetBaseSystem().FeatureFlags()
+	assert.False(t, flags[FeatureFlagStore])
+	assert.False(t, flags[FeatureFlagStoreSectionPrefix+"currency"])
+}
+
+func TestRpcBaseSync_IncludesFeatureFlags(t *testing.T) {
+	p := newFeatureFlagsPamlogix(nil)
+	ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, "user1")
+
+	out, err := rpcBaseSync(p)(ctx, &mockLogger{}, nil, NewMockNakama(t), "{}")
+	require.NoError(t, err)
+
+	var resp struct {
+		FeatureFlags map[string]bool `json:"feature_flags"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &resp))
+	assert.True(t, resp.FeatureFlags[FeatureFlagAuctions])
+	assert.False(t, resp.FeatureFlags[FeatureFlagEventLeaderboards])
+}
+
+type testWalletLedgerItem struct {
+	id        string
+	userID    string
+	changeset map[string]int64
+}
+
+func (i *testWalletLedgerItem) GetID() string                       { return i.id }
+func (i *testWalletLedgerItem) GetUserID() string                   { return i.userID }
+func (i *testWalletLedgerItem) GetCreateTime() int64                { return 1700000000 }
+func (i *testWalletLedgerItem) GetUpdateTime() int64                { return 1700000000 }
+func (i *testWalletLedgerItem) GetChangeset() map[string]int64      { return i.changeset }
+func (i *testWalletLedgerItem) GetMetadata() map[string]interface{} { return nil }
+
+func TestBaseExportData(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	baseSystem := NewBaseSystem(&BaseSystemConfig{})
+	baseSystem.SetPamlogix(p)
+	p.systems[SystemTypeBase] = baseSystem
+
+	const userID = "player1"
+	_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 50}, nil, true)
+	require.NoError(t, err)
+	_, _, _, _, err = p.GetInventorySystem().GrantItems(ctx, logger, nk, userID, map[string]int64{"sword": 1}, false)
+	require.NoError(t, err)
+	nk.On("WalletLedgerList", mock.Anything, userID, dataExportLedgerPageSize, "").Return([]runtime.WalletLedgerItem{
+		&testWalletLedgerItem{id: "ledger1", userID: userID, changeset: map[string]int64{benchCurrency: 50}},
+	}, "", nil).Once()
+
+	receipt, err := baseSystem.ExportData(ctx, logger, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, dataExportStorageCollection, receipt.Collection)
+	assert.Equal(t, receipt.CreateTimeSec+defaultDataExportTTLSec, receipt.ExpiryTimeSec)
+
+	// The export is stored for the user alone to read
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: receipt.Collection, Key: receipt.Key, UserID: userID}})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, int32(runtime.STORAGE_PERMISSION_OWNER_READ), objects[0].PermissionRead)
+
+	var export DataExport
+	require.NoError(t, json.Unmarshal([]byte(objects[0].Value), &export))
+	assert.Equal(t, userID, export.UserId)
+	assert.Equal(t, int64(50), export.Wallet[benchCurrency])
+	require.Len(t, export.WalletLedger, 1)
+	assert.Equal(t, "ledger1", export.WalletLedger[0].Id)
+	require.NotNil(t, export.Inventory)
+	assert.Len(t, export.Inventory.Items, 1)
+	require.NotNil(t, export.Auctions)
+
+	// Asking again within the cooldown hands back the same export without compiling another
+	again, err := baseSystem.ExportData(ctx, logger, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, receipt, again)
+	nk.AssertNumberOfCalls(t, "WalletLedgerList", 1)
+
+	// The export is deleted once it expires
+	rules := baseSystem.storageTTLRules()
+	require.Len(t, rules, 1)
+	expired, err := rules[0].expired(objects[0].Value, receipt.ExpiryTimeSec)
+	require.NoError(t, err)
+	assert.True(t, expired)
+}
@@ -0,0 +1,313 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type storageOpCountsCtxKey struct{}
+
+// storageOpCounts counts the storage calls made against a benchNakama, either in total or for one RPC call.
+type storageOpCounts struct {
+	reads   atomic.Int64
+	writes  atomic.Int64
+	deletes atomic.Int64
+	lists   atomic.Int64
+}
+
+func (c *storageOpCounts) total() int64 {
+	return c.reads.Load() + c.writes.Load() + c.deletes.Load() + c.lists.Load()
+}
+
+// withStorageOpCounts returns a context whose storage calls are also counted in c, so concurrent callers sharing the
+// same benchNakama can each see their own op counts.
+func withStorageOpCounts(ctx context.Context, c *storageOpCounts) context.Context {
+	return context.WithValue(ctx, storageOpCountsCtxKey{}, c)
+}
+
+// benchNakama is an in-memory Nakama module for benchmarks and load simulation. It keeps storage objects and wallets
+// in maps guarded by a mutex, honours storage object versions the way Nakama does, and counts every storage call.
+type benchNakama struct {
+	*MockNakamaModule
+
+	mu      sync.Mutex
+	objects map[string]*api.StorageObject
+	wallets map[string]map[string]int64
+
+	counts storageOpCounts
+}
+
+func newBenchNakama() *benchNakama {
+	return &benchNakama{
+		MockNakamaModule: &MockNakamaModule{},
+		objects:          make(map[string]*api.StorageObject),
+		wallets:          make(map[string]map[string]int64),
+	}
+}
+
+func benchStorageKey(collection, key, userID string) string {
+	return collection + "/" + userID + "/" + key
+}
+
+func (m *benchNakama) count(ctx context.Context, op func(c *storageOpCounts) *atomic.Int64) {
+	op(&m.counts).Add(1)
+	if c, ok := ctx.Value(storageOpCountsCtxKey{}).(*storageOpCounts); ok {
+		op(c).Add(1)
+	}
+}
+
+func (m *benchNakama) StorageRead(ctx context.Context, reads []*runtime.StorageRead) ([]*api.StorageObject, error) {
+	m.count(ctx, func(c *storageOpCounts) *atomic.Int64 { return &c.reads })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	objects := make([]*api.StorageObject, 0, len(reads))
+	for _, read := range reads {
+		if object, found := m.objects[benchStorageKey(read.Collection, read.Key, read.UserID)]; found {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+func (m *benchNakama) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	m.count(ctx, func(c *storageOpCounts) *atomic.Int64 { return &c.writes })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkWrites(writes); err != nil {
+		return nil, err
+	}
+	return m.applyWrites(writes), nil
+}
+
+// checkWrites checks every version first so a rejected batch writes nothing, as in Nakama. The caller holds m.mu.
+func (m *benchNakama) checkWrites(writes []*runtime.StorageWrite) error {
+	for _, write := range writes {
+		existing, found := m.objects[benchStorageKey(write.Collection, write.Key, write.UserID)]
+		switch {
+		case write.Version == "":
+		case write.Version == storageLockVersionNone && found:
+			return runtime.ErrStorageRejectedVersion
+		case write.Version != storageLockVersionNone && (!found || existing.Version != write.Version):
+			return runtime.ErrStorageRejectedVersion
+		}
+	}
+	return nil
+}
+
+// applyWrites stores the objects. The caller holds m.mu.
+func (m *benchNakama) applyWrites(writes []*runtime.StorageWrite) []*api.StorageObjectAck {
+	acks := make([]*api.StorageObjectAck, 0, len(writes))
+	for _, write := range writes {
+		version := uuid.New().String()
+		m.objects[benchStorageKey(write.Collection, write.Key, write.UserID)] = &api.StorageObject{
+			Collection:      write.Collection,
+			Key:             write.Key,
+			UserId:          write.UserID,
+			Value:           write.Value,
+			Version:         version,
+			PermissionRead:  int32(write.PermissionRead),
+			PermissionWrite: int32(write.PermissionWrite),
+		}
+		acks = append(acks, &api.StorageObjectAck{
+			Collection: write.Collection,
+			Key:        write.Key,
+			UserId:     write.UserID,
+			Version:    version,
+		})
+	}
+	return acks
+}
+
+func (m *benchNakama) StorageDelete(ctx context.Context, deletes []*runtime.StorageDelete) error {
+	m.count(ctx, func(c *storageOpCounts) *atomic.Int64 { return &c.deletes })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkDeletes(deletes); err != nil {
+		return err
+	}
+	m.applyDeletes(deletes)
+	return nil
+}
+
+// checkDeletes checks every version first so a rejected batch deletes nothing. The caller holds m.mu.
+func (m *benchNakama) checkDeletes(deletes []*runtime.StorageDelete) error {
+	for _, del := range deletes {
+		key := benchStorageKey(del.Collection, del.Key, del.UserID)
+		if existing, found := m.objects[key]; found && del.Version != "" && existing.Version != del.Version {
+			return errors.New("storage delete rejected - version check failed")
+		}
+	}
+	return nil
+}
+
+// applyDeletes removes the objects. The caller holds m.mu.
+func (m *benchNakama) applyDeletes(deletes []*runtime.StorageDelete) {
+	for _, del := range deletes {
+		delete(m.objects, benchStorageKey(del.Collection, del.Key, del.UserID))
+	}
+}
+
+func (m *benchNakama) StorageList(ctx context.Context, callerID, userID, collection string, limit int, cursor string) ([]*api.StorageObject, string, error) {
+	m.count(ctx, func(c *storageOpCounts) *atomic.Int64 { return &c.lists })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	matches := make([]*api.StorageObject, 0)
+	for _, object := range m.objects {
+		if object.Collection == collection && (userID == "" || object.UserId == userID) {
+			matches = append(matches, object)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].UserId != matches[j].UserId {
+			return matches[i].UserId < matches[j].UserId
+		}
+		return matches[i].Key < matches[j].Key
+	})
+
+	// The cursor is simply the offset of the next page
+	offset, _ := strconv.Atoi(cursor)
+	if offset > len(matches) {
+		offset = len(matches)
+	}
+	end := offset + limit
+	if limit <= 0 || end > len(matches) {
+		end = len(matches)
+	}
+	next := ""
+	if end < len(matches) {
+		next = strconv.Itoa(end)
+	}
+	return matches[offset:end], next, nil
+}
+
+func (m *benchNakama) WalletUpdate(ctx context.Context, userID string, changeset map[string]int64, metadata map[string]interface{}, updateLedger bool) (updated map[string]int64, previous map[string]int64, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wallet, found := m.wallets[userID]
+	if !found {
+		wallet = make(map[string]int64)
+		m.wallets[userID] = wallet
+	}
+
+	previous = make(map[string]int64, len(wallet))
+	for currencyID, amount := range wallet {
+		previous[currencyID] = amount
+	}
+	for currencyID, amount := range changeset {
+		if wallet[currencyID]+amount < 0 {
+			return nil, nil, errors.New("wallet update rejected negative value at path '" + currencyID + "'")
+		}
+	}
+	for currencyID, amount := range changeset {
+		wallet[currencyID] += amount
+	}
+
+	updated = make(map[string]int64, len(wallet))
+	for currencyID, amount := range wallet {
+		updated[currencyID] = amount
+	}
+	return updated, previous, nil
+}
+
+// MultiUpdate applies the wallet updates and storage writes together, writing nothing if any of them is rejected.
+func (m *benchNakama) MultiUpdate(ctx context.Context, accountUpdates []*runtime.AccountUpdate, storageWrites []*runtime.StorageWrite, storageDeletes []*runtime.StorageDelete, walletUpdates []*runtime.WalletUpdate, updateLedger bool) ([]*api.StorageObjectAck, []*runtime.WalletUpdateResult, error) {
+	m.count(ctx, func(c *storageOpCounts) *atomic.Int64 { return &c.writes })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkWrites(storageWrites); err != nil {
+		return nil, nil, err
+	}
+	if err := m.checkDeletes(storageDeletes); err != nil {
+		return nil, nil, err
+	}
+	balances := make(map[string]map[string]int64, len(walletUpdates))
+	for _, update := range walletUpdates {
+		balance, found := balances[update.UserID]
+		if !found {
+			balance = make(map[string]int64)
+			for currencyID, amount := range m.wallets[update.UserID] {
+				balance[currencyID] = amount
+			}
+			balances[update.UserID] = balance
+		}
+		for currencyID, amount := range update.Changeset {
+			balance[currencyID] += amount
+			if balance[currencyID] < 0 {
+				return nil, nil, errors.New("wallet update rejected negative value at path '" + currencyID + "'")
+			}
+		}
+	}
+
+	results := make([]*runtime.WalletUpdateResult, 0, len(walletUpdates))
+	for _, update := range walletUpdates {
+		wallet, found := m.wallets[update.UserID]
+		if !found {
+			wallet = make(map[string]int64)
+			m.wallets[update.UserID] = wallet
+		}
+		previous := make(map[string]int64, len(wallet))
+		for currencyID, amount := range wallet {
+			previous[currencyID] = amount
+		}
+		for currencyID, amount := range update.Changeset {
+			wallet[currencyID] += amount
+		}
+		updated := make(map[string]int64, len(wallet))
+		for currencyID, amount := range wallet {
+			updated[currencyID] = amount
+		}
+		results = append(results, &runtime.WalletUpdateResult{UserID: update.UserID, Updated: updated, Previous: previous})
+	}
+	m.applyDeletes(storageDeletes)
+	return m.applyWrites(storageWrites), results, nil
+}
+
+func (m *benchNakama) AccountGetId(ctx context.Context, userID string) (*api.Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wallet, err := json.Marshal(m.wallets[userID])
+	if err != nil {
+		return nil, err
+	}
+	return &api.Account{
+		User:   &api.User{Id: userID},
+		Wallet: string(wallet),
+	}, nil
+}
+
+func (m *benchNakama) StreamUserJoin(mode uint8, subject, subcontext, label, userID, sessionID string, hidden, persistence bool, status string) (bool, error) {
+	return true, nil
+}
+
+func (m *benchNakama) StreamUserList(mode uint8, subject, subcontext, label string, includeHidden, includeNotHidden bool) ([]runtime.Presence, error) {
+	return nil, nil
+}
+
+func (m *benchNakama) StreamSend(mode uint8, subject, subcontext, label, data string, presences []runtime.Presence, reliable bool) error {
+	return nil
+}
+
+func (m *benchNakama) NotificationSend(ctx context.Context, userID, subject string, content map[string]interface{}, code int, sender string, persistent bool) error {
+	return nil
+}
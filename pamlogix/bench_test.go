@@ -0,0 +1,275 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const benchCurrency = "coins"
+
+// benchRewardConfig is a reward table shaped like the ones in configs/economy.json: a guaranteed currency amount plus
+// one weighted roll between an item and more currency.
+var benchRewardConfig = &EconomyConfigReward{
+	Guaranteed: &EconomyConfigRewardContents{
+		Currencies: map[string]*EconomyConfigRewardCurrency{
+			benchCurrency: {EconomyConfigRewardRangeInt64{Min: 10, Max: 100, Multiple: 10}},
+		},
+	},
+	Weighted: []*EconomyConfigRewardContents{
+		{
+			Items: map[string]*EconomyConfigRewardItem{
+				"potion": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 1, Max: 3}},
+			},
+			Weight: 60,
+		},
+		{
+			Currencies: map[string]*EconomyConfigRewardCurrency{
+				benchCurrency: {EconomyConfigRewardRangeInt64{Min: 50, Max: 50}},
+			},
+			Weight: 40,
+		},
+	},
+	MaxRolls: 1,
+}
+
+// newBenchPamlogix wires the economy, inventory and auctions systems together the way Init does.
+func newBenchPamlogix() *pamlogixImpl {
+	p := &pamlogixImpl{
+		systems: make(map[SystemType]System),
+	}
+
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{})
+	inventorySystem := NewNakamaInventorySystem(&InventoryConfig{
+		Items: map[string]*InventoryConfigItem{
+			"potion": {Name: "Potion", Category: "consumable", Stackable: true, Consumable: true},
+			"sword":  {Name: "Sword", Category: "weapon"},
+		},
+	})
+	auctionsSystem := NewNakamaAuctionsSystem(&AuctionsConfig{})
+
+	economySystem.SetPamlogix(p)
+	inventorySystem.SetPamlogix(p)
+	auctionsSystem.(*AuctionsPamlogix).SetPamlogix(p)
+	p.systems[SystemTypeEconomy] = economySystem
+	p.systems[SystemTypeInventory] = inventorySystem
+	p.systems[SystemTypeAuctions] = auctionsSystem
+
+	return p
+}
+
+// seedBenchAuction stores an open auction selling a sword, as Create would leave it, without going through the
+// listing flow.
+func seedBenchAuction(ctx context.Context, nk runtime.NakamaModule, auctionID, ownerID string, durationSec int64) error {
+	now := time.Now().Unix()
+	auction := &Auction{
+		Id:     auctionID,
+		UserId: ownerID,
+		Reward: &AuctionReward{
+			Items: []*InventoryItem{{Id: "sword", Count: 1}},
+		},
+		Version:       "v1",
+		BidNext:       &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 10}},
+		DurationSec:   durationSec,
+		CreateTimeSec: now,
+		UpdateTimeSec: now,
+		StartTimeSec:  now - 1,
+		EndTimeSec:    now + durationSec,
+	}
+
+	data, err := json.Marshal(auction)
+	if err != nil {
+		return err
+	}
+
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection: AuctionCollectionKey,
+			Key:        auctionID,
+			Value:      string(data),
+		},
+	})
+	return err
+}
+
+func reportStorageOps(b *testing.B, ops *storageOpCounts) {
+	n := float64(b.N)
+	b.ReportMetric(float64(ops.reads.Load())/n, "reads/op")
+	b.ReportMetric(float64(ops.writes.Load())/n, "writes/op")
+	b.ReportMetric(float64(ops.deletes.Load())/n, "deletes/op")
+	b.ReportMetric(float64(ops.lists.Load())/n, "lists/op")
+}
+
+func BenchmarkRewardRoll(b *testing.B) {
+	p := newBenchPamlogix()
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ops := &storageOpCounts{}
+	ctx := withStorageOpCounts(context.Background(), ops)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.GetEconomySystem().RewardRoll(ctx, logger, nk, "user1", benchRewardConfig); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	reportStorageOps(b, ops)
+}
+
+func BenchmarkRewardGrant(b *testing.B) {
+	p := newBenchPamlogix()
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ops := &storageOpCounts{}
+	ctx := withStorageOpCounts(context.Background(), ops)
+
+	reward := &Reward{
+		Currencies: map[string]int64{benchCurrency: 100},
+		Items:      map[string]int64{"potion": 1},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := p.GetEconomySystem().RewardGrant(ctx, logger, nk, "user1", reward, nil, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	reportStorageOps(b, ops)
+}
+
+func BenchmarkAuctionBid(b *testing.B) {
+	p := newBenchPamlogix()
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ctx := context.Background()
+
+	// Two bidders take turns outbidding each other, so every bid after the first also refunds the previous one
+	bidders := []string{"bidder1", "bidder2"}
+	for _, userID := range bidders {
+		if _, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 1 << 40}, nil, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := seedBenchAuction(ctx, nk, "auction1", "owner", 3600); err != nil {
+		b.Fatal(err)
+	}
+
+	version := "v1"
+	next := &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 10}}
+	ops := &storageOpCounts{}
+	ctx = withStorageOpCounts(ctx, ops)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		auction, err := p.GetAuctionsSystem().Bid(ctx, logger, nk, bidders[i%len(bidders)], "session", "auction1", version, next, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		version = auction.Version
+		next = auction.BidNext
+
+		// Keep bids from growing past the funded wallets on long runs
+		if next.Currencies[benchCurrency] > 1<<30 {
+			b.StopTimer()
+			version, next = "v1", &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 10}}
+			if err := seedBenchAuction(context.Background(), nk, "auction1", "owner", 3600); err != nil {
+				b.Fatal(err)
+			}
+			b.StartTimer()
+		}
+	}
+	b.StopTimer()
+
+	reportStorageOps(b, ops)
+}
+
+// TestStorageOpBudgets fails when a change adds storage round trips to the hottest economy and auction paths. Raise
+// a budget only when the extra calls are intended.
+func TestStorageOpBudgets(t *testing.T) {
+	logger := &mockLogger{}
+
+	tests := []struct {
+		name   string
+		budget int64
+		run    func(ctx context.Context, p *pamlogixImpl, nk *benchNakama) error
+	}{
+		{
+			name:   "RewardRoll",
+			budget: 0,
+			run: func(ctx context.Context, p *pamlogixImpl, nk *benchNakama) error {
+				_, err := p.GetEconomySystem().RewardRoll(ctx, logger, nk, "user1", benchRewardConfig)
+				return err
+			},
+		},
+		{
+			name:   "RewardGrant",
+			budget: 2,
+			run: func(ctx context.Context, p *pamlogixImpl, nk *benchNakama) error {
+				_, _, _, err := p.GetEconomySystem().RewardGrant(ctx, logger, nk, "user1", &Reward{
+					Currencies: map[string]int64{benchCurrency: 100},
+					Items:      map[string]int64{"potion": 1},
+				}, nil, false)
+				return err
+			},
+		},
+		{
+			name:   "RewardGrantWithModifiers",
+			budget: 3,
+			run: func(ctx context.Context, p *pamlogixImpl, nk *benchNakama) error {
+				_, _, _, err := p.GetEconomySystem().RewardGrant(ctx, logger, nk, "user1", &Reward{
+					Currencies:      map[string]int64{benchCurrency: 100},
+					Items:           map[string]int64{"potion": 1},
+					Energies:        map[string]int32{"lives": 1},
+					EnergyModifiers: []*RewardEnergyModifier{{Id: "lives", Operator: "add", Value: 1, DurationSec: 600}},
+					RewardModifiers: []*RewardModifier{{Id: benchCurrency, Type: "currency", Operator: "multiplier", Value: 2, DurationSec: 600}},
+				}, nil, false)
+				return err
+			},
+		},
+		{
+			name:   "AuctionBid",
+			budget: 8,
+			run: func(ctx context.Context, p *pamlogixImpl, nk *benchNakama) error {
+				// Outbid an existing bidder so the refund path is included
+				setup := context.Background()
+				for _, userID := range []string{"bidder1", "bidder2"} {
+					if _, _, err := nk.WalletUpdate(setup, userID, map[string]int64{benchCurrency: 1000}, nil, false); err != nil {
+						return err
+					}
+				}
+				if err := seedBenchAuction(setup, nk, "auction1", "owner", 3600); err != nil {
+					return err
+				}
+				auction, err := p.GetAuctionsSystem().Bid(setup, logger, nk, "bidder1", "session1", "auction1", "v1", &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 10}}, nil)
+				if err != nil {
+					return err
+				}
+
+				_, err = p.GetAuctionsSystem().Bid(ctx, logger, nk, "bidder2", "session2", "auction1", auction.Version, auction.BidNext, nil)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := &storageOpCounts{}
+			err := tt.run(withStorageOpCounts(context.Background(), ops), newBenchPamlogix(), newBenchNakama())
+			require.NoError(t, err)
+			assert.LessOrEqual(t, ops.total(), tt.budget, fmt.Sprintf("%d reads, %d writes, %d deletes, %d lists", ops.reads.Load(), ops.writes.Load(), ops.deletes.Load(), ops.lists.Load()))
+		})
+	}
+}
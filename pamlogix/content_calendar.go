@@ -0,0 +1,189 @@
+package pamlogix
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	defaultContentCalendarLeadSec     = 3600
+	defaultContentCalendarIntervalSec = 300
+)
+
+// Types of content calendar entries.
+const (
+	ContentCalendarEntryTypeEvent  = "event"
+	ContentCalendarEntryTypeSale   = "sale"
+	ContentCalendarEntryTypeSeason = "season"
+)
+
+// ContentCalendarConfigEntry is the data definition for an upcoming event, sale or season in the content calendar.
+type ContentCalendarConfigEntry struct {
+	Type        string `json:"type,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// StartTimeSec and EndTimeSec are when the entry runs. A zero end time runs it until it's removed.
+	StartTimeSec int64 `json:"start_time_sec,omitempty"`
+	EndTimeSec   int64 `json:"end_time_sec,omitempty"`
+	// AnnounceTimeSec is when clients start listing the entry as coming soon. Zero lists it straight away.
+	AnnounceTimeSec int64 `json:"announce_time_sec,omitempty"`
+	// EventLeaderboards are created ahead of the entry's start, so the first players to join don't wait on it.
+	EventLeaderboards []string `json:"event_leaderboards,omitempty"`
+	// StoreItems are only in the store while the entry, or another entry listing them, runs.
+	StoreItems           []string          `json:"store_items,omitempty"`
+	AdditionalProperties map[string]string `json:"additional_properties,omitempty"`
+}
+
+// ContentCalendar is the announced content which hasn't ended yet, soonest first.
+type ContentCalendar struct {
+	Entries        []*ContentCalendarEntry `json:"entries"`
+	CurrentTimeSec int64                   `json:"current_time_sec"`
+}
+
+// ContentCalendarEntry is an event, sale or season in the content calendar, and whether it's running.
+type ContentCalendarEntry struct {
+	Id                   string            `json:"id"`
+	Type                 string            `json:"type,omitempty"`
+	Name                 string            `json:"name,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	StartTimeSec         int64             `json:"start_time_sec,omitempty"`
+	EndTimeSec           int64             `json:"end_time_sec,omitempty"`
+	Active               bool              `json:"active"`
+	EventLeaderboards    []string          `json:"event_leaderboards,omitempty"`
+	StoreItems           []string          `json:"store_items,omitempty"`
+	AdditionalProperties map[string]string `json:"additional_properties,omitempty"`
+}
+
+// active reports whether the entry is running at the given time.
+func (c *ContentCalendarConfigEntry) active(now int64) bool {
+	return c.StartTimeSec <= now && !c.ended(now)
+}
+
+func (c *ContentCalendarConfigEntry) ended(now int64) bool {
+	return c.EndTimeSec > 0 && c.EndTimeSec <= now
+}
+
+// GetContentCalendar returns the entries of the content calendar which are announced and haven't ended.
+func (b *BasePamlogix) GetContentCalendar() *ContentCalendar {
+	now := time.Now().Unix()
+	calendar := &ContentCalendar{
+		Entries:        make([]*ContentCalendarEntry, 0, len(b.config.ContentCalendar)),
+		CurrentTimeSec: now,
+	}
+	for entryID, entry := range b.config.ContentCalendar {
+		if entry.AnnounceTimeSec > now || entry.ended(now) {
+			continue
+		}
+		calendar.Entries = append(calendar.Entries, &ContentCalendarEntry{
+			Id:                   entryID,
+			Type:                 entry.Type,
+			Name:                 entry.Name,
+			Description:          entry.Description,
+			StartTimeSec:         entry.StartTimeSec,
+			EndTimeSec:           entry.EndTimeSec,
+			Active:               entry.active(now),
+			EventLeaderboards:    entry.EventLeaderboards,
+			StoreItems:           entry.StoreItems,
+			AdditionalProperties: entry.AdditionalProperties,
+		})
+	}
+	sort.Slice(calendar.Entries, func(i, j int) bool {
+		if calendar.Entries[i].StartTimeSec != calendar.Entries[j].StartTimeSec {
+			return calendar.Entries[i].StartTimeSec < calendar.Entries[j].StartTimeSec
+		}
+		return calendar.Entries[i].Id < calendar.Entries[j].Id
+	})
+	return calendar
+}
+
+// contentCalendarConfig returns the base config when it schedules any content, or nil.
+func contentCalendarConfig(pl Pamlogix) *BaseSystemConfig {
+	if pl == nil {
+		return nil
+	}
+	baseSystem := pl.GetBaseSystem()
+	if baseSystem == nil {
+		return nil
+	}
+	baseConfig, ok := baseSystem.GetConfig().(*BaseSystemConfig)
+	if !ok || baseConfig == nil || len(baseConfig.ContentCalendar) == 0 {
+		return nil
+	}
+	return baseConfig
+}
+
+// contentCalendarOffSaleItems returns the store items scheduled by the content calendar which no running entry has on
+// sale. Store items the calendar doesn't mention are always on sale.
+func contentCalendarOffSaleItems(pl Pamlogix, now int64) map[string]bool {
+	baseConfig := contentCalendarConfig(pl)
+	if baseConfig == nil {
+		return nil
+	}
+	offSale := make(map[string]bool)
+	onSale := make(map[string]bool)
+	for _, entry := range baseConfig.ContentCalendar {
+		for _, itemID := range entry.StoreItems {
+			if entry.active(now) {
+				onSale[itemID] = true
+			} else {
+				offSale[itemID] = true
+			}
+		}
+	}
+	for itemID := range onSale {
+		delete(offSale, itemID)
+	}
+	return offSale
+}
+
+// prepareContentCalendar creates the event leaderboards of the entries starting within the lead time, or already
+// running. Entries which fail are logged and the rest are still prepared.
+func (p *pamlogixImpl) prepareContentCalendar(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) {
+	baseConfig := contentCalendarConfig(p)
+	if baseConfig == nil {
+		return
+	}
+	eventLeaderboards, ok := p.systems[SystemTypeEventLeaderboards].(*NakamaEventLeaderboardsSystem)
+	if !ok {
+		return
+	}
+	leadSec := baseConfig.ContentCalendarLeadSec
+	if leadSec <= 0 {
+		leadSec = defaultContentCalendarLeadSec
+	}
+
+	now := time.Now().Unix()
+	for entryID, entry := range baseConfig.ContentCalendar {
+		if entry.StartTimeSec-leadSec > now || entry.ended(now) {
+			continue
+		}
+		for _, eventLeaderboardID := range entry.EventLeaderboards {
+			// Every server runs the calendar, so only one of them prepares each event leaderboard at a time
+			err := withStorageLock(ctx, nk, "content_calendar_"+eventLeaderboardID, func() error {
+				return eventLeaderboards.prepareEventLeaderboard(ctx, logger, nk, eventLeaderboardID)
+			})
+			if err != nil {
+				logger.Error("Failed to prepare event leaderboard %s of content calendar entry %s: %v", eventLeaderboardID, entryID, err)
+			}
+		}
+	}
+}
+
+// startContentCalendar prepares the content calendar every interval for the life of the server.
+func (p *pamlogixImpl) startContentCalendar(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, intervalSec int64) {
+	if intervalSec <= 0 {
+		intervalSec = defaultContentCalendarIntervalSec
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		p.prepareContentCalendar(ctx, logger, nk)
+		ticker := time.NewTicker(time.Duration(intervalSec) * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			p.prepareContentCalendar(ctx, logger, nk)
+		}
+	}()
+}
@@ -0,0 +1,110 @@
+package pamlogix
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetContentCalendar(t *testing.T) {
+	now := time.Now().Unix()
+	baseSystem := NewBaseSystem(&BaseSystemConfig{
+		ContentCalendar: map[string]*ContentCalendarConfigEntry{
+			"summer_sale":  {Type: ContentCalendarEntryTypeSale, StartTimeSec: now + 3600, EndTimeSec: now + 7200, StoreItems: []string{"bundle"}},
+			"season_2":     {Type: ContentCalendarEntryTypeSeason, StartTimeSec: now - 60},
+			"secret_event": {Type: ContentCalendarEntryTypeEvent, StartTimeSec: now + 86400, AnnounceTimeSec: now + 3600},
+			"spring_sale":  {Type: ContentCalendarEntryTypeSale, StartTimeSec: now - 7200, EndTimeSec: now - 3600},
+		},
+	})
+
+	calendar := baseSystem.GetContentCalendar()
+	require.Len(t, calendar.Entries, 2)
+	assert.Equal(t, "season_2", calendar.Entries[0].Id)
+	assert.True(t, calendar.Entries[0].Active)
+	assert.Equal(t, "summer_sale", calendar.Entries[1].Id)
+	assert.False(t, calendar.Entries[1].Active)
+	assert.Equal(t, []string{"bundle"}, calendar.Entries[1].StoreItems)
+	assert.GreaterOrEqual(t, calendar.CurrentTimeSec, now)
+}
+
+func TestContentCalendar_StoreItems(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	now := time.Now().Unix()
+
+	p := newBenchPamlogix()
+	p.systems[SystemTypeBase] = NewBaseSystem(&BaseSystemConfig{
+		ContentCalendar: map[string]*ContentCalendarConfigEntry{
+			"summer_sale": {Type: ContentCalendarEntryTypeSale, StartTimeSec: now + 3600, StoreItems: []string{"bundle", "skin"}},
+			"flash_sale":  {Type: ContentCalendarEntryTypeSale, StartTimeSec: now - 60, EndTimeSec: now + 60, StoreItems: []string{"skin"}},
+		},
+	})
+	economySystem := p.GetEconomySystem().(*NakamaEconomySystem)
+	economySystem.config.StoreItems = map[string]*EconomyConfigStoreItem{
+		"bundle": {Cost: &EconomyConfigStoreItemCost{Currencies: map[string]int64{benchCurrency: 10}}},
+		"skin":   {Cost: &EconomyConfigStoreItemCost{Currencies: map[string]int64{benchCurrency: 10}}},
+		"coins":  {Cost: &EconomyConfigStoreItemCost{Currencies: map[string]int64{benchCurrency: 10}}},
+	}
+
+	// The bundle waits for the summer sale, while the skin is already on sale in the flash sale
+	storeItems, _, _, _, err := economySystem.List(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	assert.Contains(t, storeItems, "coins")
+	assert.Contains(t, storeItems, "skin")
+	assert.NotContains(t, storeItems, "bundle")
+
+	err = economySystem.PurchaseIntent(ctx, logger, nk, "user1", "bundle", EconomyStoreType_ECONOMY_STORE_TYPE_APPLE_APPSTORE, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not on sale")
+}
+
+func TestPrepareContentCalendar(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	nk.MockNakamaModule.On("LeaderboardCreate", mock.Anything, mock.AnythingOfType("string"), false, "desc", "best", "", mock.Anything, false).Return(nil)
+	now := time.Now().Unix()
+
+	config := getTestEventLeaderboardsConfig()
+	eventLeaderboards := NewNakamaEventLeaderboardsSystem(config)
+	p := &pamlogixImpl{systems: map[SystemType]System{
+		SystemTypeEventLeaderboards: eventLeaderboards,
+		SystemTypeBase: NewBaseSystem(&BaseSystemConfig{
+			ContentCalendar: map[string]*ContentCalendarConfigEntry{
+				"weekend_event": {Type: ContentCalendarEntryTypeEvent, StartTimeSec: now + 600, EventLeaderboards: []string{"test_event"}},
+				"later_event":   {Type: ContentCalendarEntryTypeEvent, StartTimeSec: now + 86400, EventLeaderboards: []string{"other_event"}},
+			},
+		}),
+	}}
+	eventLeaderboards.SetPamlogix(p)
+
+	// Preparing twice leaves one open cohort, and the event outside the lead time isn't touched
+	p.prepareContentCalendar(ctx, logger, nk)
+	p.prepareContentCalendar(ctx, logger, nk)
+
+	objects, _, err := nk.StorageList(ctx, "", "", eventLeaderboardsStorageCollection, 100, "")
+	require.NoError(t, err)
+	cohorts := 0
+	for _, object := range objects {
+		if strings.HasPrefix(object.Key, eventLeaderboardCohortPrefix) {
+			cohorts++
+		}
+	}
+	assert.Equal(t, 1, cohorts)
+	nk.MockNakamaModule.AssertNumberOfCalls(t, "LeaderboardCreate", 1)
+
+	// The prepared cohort is the one the first player joins
+	cohortID := eventLeaderboards.findAvailableCohort(ctx, logger, nk, "test_event", 0, config.EventLeaderboards["test_event"].CohortSize, "user1")
+	assert.NotEmpty(t, cohortID)
+
+	locks, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: storageLockCollection, Key: "content_calendar_test_event"}})
+	require.NoError(t, err)
+	assert.Empty(t, locks)
+}
@@ -0,0 +1,432 @@
+package pamlogix
+
+import (
+	"context"
+	"errors"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// Cost is the price of an action in any gameplay system: currencies from the wallet, items from the inventory and
+// energies. The cost config types of each system are aliases of it, and every system charges and refunds costs
+// through chargeCost and refundCost so an unaffordable cost fails the same way everywhere. Costs paid from a team's
+// treasury go through chargeTeamCost and refundTeamCost.
+type Cost struct {
+	Currencies map[string]int64 `json:"currencies,omitempty"`
+	Items      map[string]int64 `json:"items,omitempty"`
+	Energies   map[string]int64 `json:"energies,omitempty"`
+}
+
+// costFromReward converts a reward config used as a cost, like the event leaderboard reroll and participation costs.
+// The maximum of each guaranteed currency and item is charged, whatever its sign.
+func costFromReward(reward *EconomyConfigReward) *Cost {
+	if reward == nil || reward.Guaranteed == nil {
+		return nil
+	}
+
+	cost := &Cost{}
+	if len(reward.Guaranteed.Currencies) > 0 {
+		cost.Currencies = make(map[string]int64, len(reward.Guaranteed.Currencies))
+		for currencyID, currency := range reward.Guaranteed.Currencies {
+			cost.Currencies[currencyID] = abs64(currency.Max)
+		}
+	}
+	if len(reward.Guaranteed.Items) > 0 {
+		cost.Items = make(map[string]int64, len(reward.Guaranteed.Items))
+		for itemID, item := range reward.Guaranteed.Items {
+			cost.Items[itemID] = abs64(item.Max)
+		}
+	}
+	return cost
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// validate rejects costs from untrusted input which would grant rather than charge.
+func (c *Cost) validate() error {
+	if c == nil {
+		return nil
+	}
+	for _, amounts := range []map[string]int64{c.Currencies, c.Items, c.Energies} {
+		for id, amount := range amounts {
+			if id == "" || amount < 0 {
+				return runtime.NewError("cost amounts must be positive", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cost) isEmpty() bool {
+	return c == nil || (len(c.Currencies) == 0 && len(c.Items) == 0 && len(c.Energies) == 0)
+}
+
+// multiply returns the cost of paying this cost count times.
+func (c *Cost) multiply(count int64) *Cost {
+	if c == nil {
+		return nil
+	}
+	return &Cost{
+		Currencies: scaleAmounts(c.Currencies, count),
+		Items:      scaleAmounts(c.Items, count),
+		Energies:   scaleAmounts(c.Energies, count),
+	}
+}
+
+// add returns the cost of paying both this cost and the other.
+func (c *Cost) add(other *Cost) *Cost {
+	sum := &Cost{}
+	for _, cost := range []*Cost{c, other} {
+		if cost == nil {
+			continue
+		}
+		sum.Currencies = addAmounts(sum.Currencies, cost.Currencies)
+		sum.Items = addAmounts(sum.Items, cost.Items)
+		sum.Energies = addAmounts(sum.Energies, cost.Energies)
+	}
+	return sum
+}
+
+func addAmounts(sum, amounts map[string]int64) map[string]int64 {
+	if len(amounts) == 0 {
+		return sum
+	}
+	if sum == nil {
+		sum = make(map[string]int64, len(amounts))
+	}
+	for id, amount := range amounts {
+		sum[id] += amount
+	}
+	return sum
+}
+
+func scaleAmounts(amounts map[string]int64, factor int64) map[string]int64 {
+	if len(amounts) == 0 {
+		return nil
+	}
+	scaled := make(map[string]int64, len(amounts))
+	for id, amount := range amounts {
+		scaled[id] = amount * factor
+	}
+	return scaled
+}
+
+func energyAmounts(amounts map[string]int64) map[string]int32 {
+	converted := make(map[string]int32, len(amounts))
+	for energyID, amount := range amounts {
+		converted[energyID] = int32(amount)
+	}
+	return converted
+}
+
+// err returns the affordability error for a cost shortfall, as returned by checkCost.
+func (c *Cost) err() error {
+	switch {
+	case c.isEmpty():
+		return nil
+	case len(c.Currencies) > 0:
+		return ErrCurrencyInsufficient
+	case len(c.Items) > 0:
+		return ErrItemsInsufficient
+	default:
+		return ErrEnergyInsufficient
+	}
+}
+
+// checkCost returns how much of the cost the user is missing, or nil when they can afford it. Systems the cost needs
+// but which aren't loaded are reported with ErrSystemNotAvailable.
+func checkCost(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID string, cost *Cost) (*Cost, error) {
+	if cost.isEmpty() {
+		return nil, nil
+	}
+
+	missing := &Cost{}
+
+	if len(cost.Currencies) > 0 {
+		wallet, err := userWallet(ctx, nk, userID)
+		if err != nil {
+			logger.Error("Failed to get wallet of user %s: %v", userID, err)
+			return nil, err
+		}
+		for currencyID, amount := range cost.Currencies {
+			if balance := wallet[currencyID]; balance < amount {
+				if missing.Currencies == nil {
+					missing.Currencies = make(map[string]int64)
+				}
+				missing.Currencies[currencyID] = amount - balance
+			}
+		}
+	}
+
+	if len(cost.Items) > 0 {
+		inventorySystem := costInventorySystem(pl)
+		if inventorySystem == nil {
+			logger.Warn("Cannot check item cost: no InventorySystem available")
+			return nil, ErrSystemNotAvailable
+		}
+		inventory, err := inventorySystem.ListInventoryItems(ctx, logger, nk, userID, "")
+		if err != nil {
+			logger.Error("Failed to get inventory of user %s: %v", userID, err)
+			return nil, err
+		}
+		owned := make(map[string]int64, len(cost.Items))
+		for _, item := range inventory.Items {
+			if _, found := cost.Items[item.Id]; found {
+				owned[item.Id] += item.Count
+			}
+		}
+		for itemID, amount := range cost.Items {
+			if owned[itemID] < amount {
+				if missing.Items == nil {
+					missing.Items = make(map[string]int64)
+				}
+				missing.Items[itemID] = amount - owned[itemID]
+			}
+		}
+	}
+
+	if len(cost.Energies) > 0 {
+		energySystem := costEnergySystem(pl)
+		if energySystem == nil {
+			logger.Warn("Cannot check energy cost: no EnergySystem available")
+			return nil, ErrSystemNotAvailable
+		}
+		energies, err := energySystem.Get(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Failed to get energies of user %s: %v", userID, err)
+			return nil, err
+		}
+		for energyID, amount := range cost.Energies {
+			var current int64
+			if energy, found := energies[energyID]; found {
+				current = int64(energy.Current)
+			}
+			if current < amount {
+				if missing.Energies == nil {
+					missing.Energies = make(map[string]int64)
+				}
+				missing.Energies[energyID] = amount - current
+			}
+		}
+	}
+
+	if missing.isEmpty() {
+		return nil, nil
+	}
+	return missing, nil
+}
+
+// chargeCost takes the cost from the user: currencies first, then items, then energies. An unaffordable cost returns
+// ErrCurrencyInsufficient, ErrItemsInsufficient or ErrEnergyInsufficient without charging anything, and if a later part
+// fails to charge the parts already taken are refunded. With dryRun the cost is only checked, so a nil error means the
+// whole cost would be taken.
+func chargeCost(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID string, cost *Cost, metadata map[string]interface{}, dryRun bool) error {
+	if cost.isEmpty() {
+		return nil
+	}
+
+	missing, err := checkCost(ctx, logger, nk, pl, userID, cost)
+	if err != nil {
+		return err
+	}
+	if missing != nil {
+		logger.Debug("User %s cannot afford cost, missing currencies=%v items=%v energies=%v", userID, missing.Currencies, missing.Items, missing.Energies)
+		return missing.err()
+	}
+	if dryRun {
+		return nil
+	}
+
+	charged := &Cost{}
+
+	if len(cost.Currencies) > 0 {
+		if err := retryNakama(ctx, nonIdempotentCall, func() error {
+			_, _, err := nk.WalletUpdate(ctx, userID, scaleAmounts(cost.Currencies, -1), metadata, false)
+			return err
+		}); err != nil {
+			logger.Error("Failed to charge currencies from user %s: %v", userID, err)
+			return ErrCurrencyInsufficient
+		}
+		charged.Currencies = cost.Currencies
+	}
+
+	if len(cost.Items) > 0 {
+		if err := debitItems(ctx, logger, nk, costInventorySystem(pl), userID, cost.Items); err != nil {
+			logger.Error("Failed to charge items from user %s: %v", userID, err)
+			_ = refundCost(ctx, logger, nk, pl, userID, charged, metadata)
+			return err
+		}
+		charged.Items = cost.Items
+	}
+
+	if len(cost.Energies) > 0 {
+		if _, _, err := costEnergySystem(pl).Spend(ctx, logger, nk, userID, energyAmounts(cost.Energies)); err != nil {
+			logger.Error("Failed to charge energies from user %s: %v", userID, err)
+			_ = refundCost(ctx, logger, nk, pl, userID, charged, metadata)
+			if err == ErrBadInput || errors.Is(err, ErrEnergyInsufficient) {
+				return ErrEnergyInsufficient
+			}
+			return err
+		}
+	}
+
+	if len(cost.Currencies) > 0 || len(cost.Items) > 0 {
+		logEconomyEvents(ctx, logger, nk, pl, &EconomyEvent{
+			Type:       EconomyEventTypeSpend,
+			UserId:     userID,
+			Currencies: cost.Currencies,
+			Items:      cost.Items,
+			Metadata:   economyEventMetadata(metadata),
+		})
+	}
+
+	return nil
+}
+
+// refundCost gives a charged cost back to the user. Items are returned regardless of inventory limits since the user
+// held them before. Every part is attempted, and the first error is returned.
+func refundCost(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID string, cost *Cost, metadata map[string]interface{}) error {
+	if cost.isEmpty() {
+		return nil
+	}
+
+	var firstErr error
+
+	if len(cost.Currencies) > 0 {
+		refundMetadata := make(map[string]interface{}, len(metadata)+1)
+		for key, value := range metadata {
+			refundMetadata[key] = value
+		}
+		refundMetadata["refund"] = true
+		if _, _, err := nk.WalletUpdate(ctx, userID, cost.Currencies, refundMetadata, false); err != nil {
+			logger.Error("Failed to refund currencies %v to user %s: %v", cost.Currencies, userID, err)
+			firstErr = err
+		}
+	}
+
+	if len(cost.Items) > 0 {
+		if inventorySystem := costInventorySystem(pl); inventorySystem == nil {
+			logger.Error("Failed to refund items %v to user %s: no InventorySystem available", cost.Items, userID)
+			if firstErr == nil {
+				firstErr = ErrSystemNotAvailable
+			}
+		} else if _, _, _, _, err := inventorySystem.GrantItems(ctx, logger, nk, userID, cost.Items, true); err != nil {
+			logger.Error("Failed to refund items %v to user %s: %v", cost.Items, userID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if len(cost.Energies) > 0 {
+		if energySystem := costEnergySystem(pl); energySystem == nil {
+			logger.Error("Failed to refund energies %v to user %s: no EnergySystem available", cost.Energies, userID)
+			if firstErr == nil {
+				firstErr = ErrSystemNotAvailable
+			}
+		} else if _, err := energySystem.Grant(ctx, logger, nk, userID, energyAmounts(cost.Energies), nil); err != nil {
+			logger.Error("Failed to refund energies %v to user %s: %v", cost.Energies, userID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// chargeTeamCost takes the cost from a team's treasury on behalf of the member, within their role's daily spend limits,
+// and logs the spend against the member like chargeCost. Treasuries only hold currencies, so costs with items or
+// energies are rejected with ErrBadInput.
+func chargeTeamCost(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID, teamID string, cost *Cost, source string, metadata map[string]string) error {
+	if cost.isEmpty() {
+		return nil
+	}
+	if len(cost.Items) > 0 || len(cost.Energies) > 0 {
+		return ErrBadInput
+	}
+
+	teamsSystem := costTeamsSystem(pl)
+	if teamsSystem == nil {
+		logger.Warn("Cannot charge team cost: no TeamsSystem available")
+		return ErrSystemNotAvailable
+	}
+	if _, err := teamsSystem.SpendTreasury(ctx, logger, nk, userID, teamID, cost.Currencies, source, metadata); err != nil {
+		return err
+	}
+
+	eventMetadata := make(map[string]interface{}, len(metadata)+2)
+	for key, value := range metadata {
+		eventMetadata[key] = value
+	}
+	eventMetadata["source"] = source
+	eventMetadata["team_id"] = teamID
+	logEconomyEvents(ctx, logger, nk, pl, &EconomyEvent{
+		Type:       EconomyEventTypeSpend,
+		UserId:     userID,
+		Currencies: cost.Currencies,
+		Metadata:   eventMetadata,
+	})
+
+	return nil
+}
+
+// refundTeamCost gives a cost charged with chargeTeamCost back to the team's treasury.
+func refundTeamCost(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID, teamID string, cost *Cost, source string, metadata map[string]string) error {
+	if cost.isEmpty() {
+		return nil
+	}
+
+	teamsSystem := costTeamsSystem(pl)
+	if teamsSystem == nil {
+		logger.Error("Failed to refund currencies %v to team %s: no TeamsSystem available", cost.Currencies, teamID)
+		return ErrSystemNotAvailable
+	}
+	if _, err := teamsSystem.RefundTreasury(ctx, logger, nk, userID, teamID, cost.Currencies, source, metadata); err != nil {
+		logger.Error("Failed to refund currencies %v to team %s: %v", cost.Currencies, teamID, err)
+		return err
+	}
+	return nil
+}
+
+// debitItems takes items from the user. The built-in inventory removes them across instances and deletes emptied ones;
+// other inventory systems are granted the negative amounts.
+func debitItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, inventorySystem InventorySystem, userID string, items map[string]int64) error {
+	if nakamaInventory, ok := inventorySystem.(*NakamaInventorySystem); ok {
+		return nakamaInventory.debitItems(ctx, logger, nk, userID, items)
+	}
+	_, _, _, notGranted, err := inventorySystem.GrantItems(ctx, logger, nk, userID, scaleAmounts(items, -1), false)
+	if err != nil {
+		return err
+	}
+	if len(notGranted) > 0 {
+		return ErrItemsInsufficient
+	}
+	return nil
+}
+
+func costInventorySystem(pl Pamlogix) InventorySystem {
+	if pl == nil {
+		return nil
+	}
+	return pl.GetInventorySystem()
+}
+
+func costEnergySystem(pl Pamlogix) EnergySystem {
+	if pl == nil {
+		return nil
+	}
+	return pl.GetEnergySystem()
+}
+
+func costTeamsSystem(pl Pamlogix) TeamsSystem {
+	if pl == nil {
+		return nil
+	}
+	return pl.GetTeamsSystem()
+}
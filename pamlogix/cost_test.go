@@ -0,0 +1,150 @@
+package pamlogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCostTestPamlogix is the bench Pamlogix with an energy system, funded with 100 coins, 3 potions and 3 lives.
+func newCostTestPamlogix(t *testing.T, ctx context.Context, nk *benchNakama, userID string) *pamlogixImpl {
+	p := newBenchPamlogix()
+	energySystem := NewNakamaEnergySystem(&EnergyConfig{
+		Energies: map[string]*EnergyConfigEnergy{
+			"lives": {StartCount: 3, MaxCount: 5, RefillCount: 1, RefillTimeSec: 600},
+		},
+	})
+	energySystem.SetPamlogix(p)
+	p.systems[SystemTypeEnergy] = energySystem
+
+	_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 100}, nil, false)
+	require.NoError(t, err)
+	_, _, _, _, err = p.GetInventorySystem().GrantItems(ctx, &mockLogger{}, nk, userID, map[string]int64{"potion": 3}, false)
+	require.NoError(t, err)
+	return p
+}
+
+func TestChargeCost_ChargesEveryPart(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	userID := "user1"
+	p := newCostTestPamlogix(t, ctx, nk, userID)
+
+	cost := &Cost{
+		Currencies: map[string]int64{benchCurrency: 40},
+		Items:      map[string]int64{"potion": 2},
+		Energies:   map[string]int64{"lives": 1},
+	}
+	require.NoError(t, chargeCost(ctx, logger, nk, p, userID, cost, nil, false))
+
+	wallet, err := userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), wallet[benchCurrency])
+
+	missing, err := checkCost(ctx, logger, nk, p, userID, &Cost{Items: map[string]int64{"potion": 2}, Energies: map[string]int64{"lives": 3}})
+	require.NoError(t, err)
+	assert.Equal(t, &Cost{Items: map[string]int64{"potion": 1}, Energies: map[string]int64{"lives": 1}}, missing)
+
+	require.NoError(t, refundCost(ctx, logger, nk, p, userID, cost, nil))
+	missing, err = checkCost(ctx, logger, nk, p, userID, &Cost{
+		Currencies: map[string]int64{benchCurrency: 100},
+		Items:      map[string]int64{"potion": 3},
+		Energies:   map[string]int64{"lives": 3},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestChargeCost_UnaffordableChargesNothing(t *testing.T) {
+	tests := []struct {
+		name    string
+		cost    *Cost
+		wantErr error
+		missing *Cost
+	}{
+		{
+			name:    "currency",
+			cost:    &Cost{Currencies: map[string]int64{benchCurrency: 150}, Items: map[string]int64{"potion": 1}},
+			wantErr: ErrCurrencyInsufficient,
+			missing: &Cost{Currencies: map[string]int64{benchCurrency: 50}},
+		},
+		{
+			name:    "item",
+			cost:    &Cost{Currencies: map[string]int64{benchCurrency: 10}, Items: map[string]int64{"potion": 5, "sword": 1}},
+			wantErr: ErrItemsInsufficient,
+			missing: &Cost{Items: map[string]int64{"potion": 2, "sword": 1}},
+		},
+		{
+			name:    "energy",
+			cost:    &Cost{Currencies: map[string]int64{benchCurrency: 10}, Energies: map[string]int64{"lives": 4}},
+			wantErr: ErrEnergyInsufficient,
+			missing: &Cost{Energies: map[string]int64{"lives": 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			logger := &mockLogger{}
+			nk := newBenchNakama()
+			userID := "user1"
+			p := newCostTestPamlogix(t, ctx, nk, userID)
+
+			missing, err := checkCost(ctx, logger, nk, p, userID, tt.cost)
+			require.NoError(t, err)
+			assert.Equal(t, tt.missing, missing)
+
+			assert.ErrorIs(t, chargeCost(ctx, logger, nk, p, userID, tt.cost, nil, false), tt.wantErr)
+
+			wallet, err := userWallet(ctx, nk, userID)
+			require.NoError(t, err)
+			assert.Equal(t, int64(100), wallet[benchCurrency])
+		})
+	}
+}
+
+func TestCostFromReward(t *testing.T) {
+	cost := costFromReward(&EconomyConfigReward{
+		Guaranteed: &EconomyConfigRewardContents{
+			Currencies: map[string]*EconomyConfigRewardCurrency{
+				"gems": {EconomyConfigRewardRangeInt64{Min: 10, Max: 50}},
+			},
+			Items: map[string]*EconomyConfigRewardItem{
+				"ticket": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: -2, Max: -2}},
+			},
+		},
+	})
+	assert.Equal(t, &Cost{Currencies: map[string]int64{"gems": 50}, Items: map[string]int64{"ticket": 2}}, cost)
+
+	assert.Nil(t, costFromReward(nil))
+	assert.True(t, costFromReward(&EconomyConfigReward{}).isEmpty())
+}
+
+func TestRpcEconomyCanAfford(t *testing.T) {
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	userID := "user1"
+	ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, userID)
+	p := newCostTestPamlogix(t, ctx, nk, userID)
+	rpc := rpcEconomyCanAfford_Json(p)
+
+	response, err := rpc(ctx, logger, nil, nk, `{"cost":{"currencies":{"coins":100},"items":{"potion":3},"energies":{"lives":3}}}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"can_afford":true}`, response)
+
+	response, err = rpc(ctx, logger, nil, nk, `{"cost":{"currencies":{"coins":120},"items":{"potion":1,"sword":1}}}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"can_afford":false,"missing":{"currencies":{"coins":20},"items":{"sword":1}}}`, response)
+
+	// Nothing was charged by the checks
+	wallet, err := userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), wallet[benchCurrency])
+
+	_, err = rpc(ctx, logger, nil, nk, `{"cost":{"currencies":{"coins":-10}}}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,203 @@
+package pamlogix
+
+import "time"
+
+// Countdown is the server clock at the time of a response together with the seconds left until its deadlines. Clients
+// run timers from these instead of comparing timestamps against a device clock, which may be skewed or set by hand. A
+// countdown is left out when the deadline doesn't apply or has already passed.
+type Countdown struct {
+	ServerTimeSec           int64 `json:"server_time_sec"`
+	SecondsUntilEnd         int64 `json:"seconds_until_end,omitempty"`
+	SecondsUntilClaimExpiry int64 `json:"seconds_until_claim_expiry,omitempty"`
+	SecondsUntilNextReset   int64 `json:"seconds_until_next_reset,omitempty"`
+}
+
+// newCountdown counts down from now to the end, claim expiry and next reset times, any of which may be 0 if unset.
+func newCountdown(now, endTimeSec, claimExpiryTimeSec, nextResetTimeSec int64) Countdown {
+	return Countdown{
+		ServerTimeSec:           now,
+		SecondsUntilEnd:         secondsUntil(now, endTimeSec),
+		SecondsUntilClaimExpiry: secondsUntil(now, claimExpiryTimeSec),
+		SecondsUntilNextReset:   secondsUntil(now, nextResetTimeSec),
+	}
+}
+
+func secondsUntil(now, timeSec int64) int64 {
+	if timeSec <= now {
+		return 0
+	}
+	return timeSec - now
+}
+
+// TimedEventLeaderboard is an event leaderboard with its countdowns. The claim expiry is when the rewards of an ended
+// event can no longer be claimed.
+type TimedEventLeaderboard struct {
+	*EventLeaderboard
+	Countdown
+	// ItemDetails is the display metadata of the items offered by the event's rewards, keyed by item ID.
+	ItemDetails map[string]*RewardItemDetails `json:"item_details,omitempty"`
+}
+
+// TimedEventLeaderboards is the list of event leaderboards with their countdowns.
+type TimedEventLeaderboards struct {
+	EventLeaderboards []*TimedEventLeaderboard `json:"event_leaderboards"`
+	ServerTimeSec     int64                    `json:"server_time_sec"`
+	// ItemDetails is the display metadata of the items offered by the events' rewards, keyed by item ID.
+	ItemDetails map[string]*RewardItemDetails `json:"item_details,omitempty"`
+}
+
+// newTimedEventLeaderboard counts down from the time the event leaderboard was built at.
+func newTimedEventLeaderboard(eventLeaderboard *EventLeaderboard) *TimedEventLeaderboard {
+	now := eventLeaderboard.GetCurrentTimeSec()
+	if now == 0 {
+		now = time.Now().Unix()
+	}
+	return &TimedEventLeaderboard{
+		EventLeaderboard: eventLeaderboard,
+		Countdown:        newCountdown(now, eventLeaderboard.GetEndTimeSec(), eventLeaderboard.GetExpiryTimeSec(), 0),
+	}
+}
+
+func newTimedEventLeaderboards(eventLeaderboards []*EventLeaderboard) *TimedEventLeaderboards {
+	now := time.Now().Unix()
+	timed := &TimedEventLeaderboards{
+		EventLeaderboards: make([]*TimedEventLeaderboard, 0, len(eventLeaderboards)),
+		ServerTimeSec:     now,
+	}
+	for _, eventLeaderboard := range eventLeaderboards {
+		timed.EventLeaderboards = append(timed.EventLeaderboards, newTimedEventLeaderboard(eventLeaderboard))
+	}
+	return timed
+}
+
+// TimedStreak is a streak with its countdowns. Rewards which can be claimed expire when the streak ends.
+type TimedStreak struct {
+	*Streak
+	Countdown
+}
+
+// TimedStreaksList is the streaks of a user with their countdowns.
+type TimedStreaksList struct {
+	Streaks       map[string]*TimedStreak `json:"streaks"`
+	ServerTimeSec int64                   `json:"server_time_sec"`
+}
+
+func newTimedStreaksList(streaks map[string]*Streak) *TimedStreaksList {
+	now := time.Now().Unix()
+	timed := &TimedStreaksList{
+		Streaks:       make(map[string]*TimedStreak, len(streaks)),
+		ServerTimeSec: now,
+	}
+	for id, streak := range streaks {
+		timed.Streaks[id] = &TimedStreak{
+			Streak:    streak,
+			Countdown: newStreakCountdown(streak, now),
+		}
+	}
+	return timed
+}
+
+func newStreakCountdown(streak *Streak, now int64) Countdown {
+	var claimExpiryTimeSec int64
+	if streak.GetCanClaim() {
+		claimExpiryTimeSec = streak.GetEndTimeSec()
+	}
+	return newCountdown(now, streak.GetEndTimeSec(), claimExpiryTimeSec, streak.GetResetTimeSec())
+}
+
+// TimedStreakClaim is a streak claim with the countdowns of the streak.
+type TimedStreakClaim struct {
+	*StreakClaim
+	Countdown
+}
+
+// TimedStreakClaims is the outcome of a claim of streaks with their countdowns.
+type TimedStreakClaims struct {
+	Streaks       map[string]*TimedStreakClaim `json:"streaks"`
+	ServerTimeSec int64                        `json:"server_time_sec"`
+}
+
+func newTimedStreakClaims(claims map[string]*StreakClaim) *TimedStreakClaims {
+	now := time.Now().Unix()
+	timed := &TimedStreakClaims{
+		Streaks:       make(map[string]*TimedStreakClaim, len(claims)),
+		ServerTimeSec: now,
+	}
+	for id, claim := range claims {
+		timed.Streaks[id] = &TimedStreakClaim{
+			StreakClaim: claim,
+			Countdown:   newStreakCountdown(claim.Streak, now),
+		}
+	}
+	return timed
+}
+
+// TimedUnlockable is an unlockable with its countdowns. It ends when its unlock completes.
+type TimedUnlockable struct {
+	*Unlockable
+	Countdown
+}
+
+// TimedUnlockablesList is the unlockables of a user with their countdowns.
+type TimedUnlockablesList struct {
+	*UnlockablesList
+	Unlockables   []*TimedUnlockable `json:"unlockables"`
+	Overflow      *TimedUnlockable   `json:"overflow,omitempty"`
+	ServerTimeSec int64              `json:"server_time_sec"`
+}
+
+func newTimedUnlockable(unlockable *Unlockable, now int64) *TimedUnlockable {
+	if unlockable == nil {
+		return nil
+	}
+	return &TimedUnlockable{
+		Unlockable: unlockable,
+		Countdown:  newCountdown(now, unlockable.GetUnlockCompleteTimeSec(), 0, 0),
+	}
+}
+
+func newTimedUnlockablesList(unlockables *UnlockablesList) *TimedUnlockablesList {
+	now := time.Now().Unix()
+	timed := &TimedUnlockablesList{
+		UnlockablesList: unlockables,
+		Unlockables:     make([]*TimedUnlockable, 0, len(unlockables.GetUnlockables())),
+		Overflow:        newTimedUnlockable(unlockables.GetOverflow(), now),
+		ServerTimeSec:   now,
+	}
+	for _, unlockable := range unlockables.GetUnlockables() {
+		timed.Unlockables = append(timed.Unlockables, newTimedUnlockable(unlockable, now))
+	}
+	return timed
+}
+
+// TimedActiveRewardModifier is an active reward modifier with its countdowns.
+type TimedActiveRewardModifier struct {
+	*ActiveRewardModifier
+	Countdown
+}
+
+// TimedEconomyList is the store listing with countdowns on the active reward modifiers. Store items have no time
+// windows of their own, so the listing carries the server time for clients to schedule their next refresh.
+type TimedEconomyList struct {
+	*EconomyList
+	ActiveRewardModifiers []*TimedActiveRewardModifier `json:"active_reward_modifiers"`
+	ServerTimeSec         int64                        `json:"server_time_sec"`
+	// ItemDetails is the display metadata of the items offered by the store items' rewards, keyed by item ID.
+	ItemDetails map[string]*RewardItemDetails `json:"item_details,omitempty"`
+}
+
+func newTimedEconomyList(list *EconomyList) *TimedEconomyList {
+	now := time.Now().Unix()
+	timed := &TimedEconomyList{
+		EconomyList:           list,
+		ActiveRewardModifiers: make([]*TimedActiveRewardModifier, 0, len(list.GetActiveRewardModifiers())),
+		ServerTimeSec:         now,
+	}
+	for _, modifier := range list.GetActiveRewardModifiers() {
+		timed.ActiveRewardModifiers = append(timed.ActiveRewardModifiers, &TimedActiveRewardModifier{
+			ActiveRewardModifier: modifier,
+			Countdown:            newCountdown(now, modifier.GetEndTimeSec(), 0, 0),
+		})
+	}
+	return timed
+}
@@ -0,0 +1,51 @@
+package pamlogix
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountdown(t *testing.T) {
+	countdown := newCountdown(1000, 1600, 900, 0)
+	assert.Equal(t, Countdown{ServerTimeSec: 1000, SecondsUntilEnd: 600}, countdown)
+
+	eventLeaderboard := &EventLeaderboard{Id: "weekly", CurrentTimeSec: 1000, EndTimeSec: 1600, ExpiryTimeSec: 2200}
+	data, err := json.Marshal(newTimedEventLeaderboard(eventLeaderboard))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"weekly","current_time_sec":1000,"end_time_sec":1600,"expiry_time_sec":2200,
+		"server_time_sec":1000,"seconds_until_end":600,"seconds_until_claim_expiry":1200}`, string(data))
+}
+
+func TestCountdown_WrappedLists(t *testing.T) {
+	unlockables := &UnlockablesList{
+		Unlockables: []*Unlockable{{Id: "chest", UnlockCompleteTimeSec: 1 << 40}},
+		Slots:       2,
+	}
+	timed := newTimedUnlockablesList(unlockables)
+	require.Len(t, timed.Unlockables, 1)
+	assert.Equal(t, int64(1<<40)-timed.ServerTimeSec, timed.Unlockables[0].SecondsUntilEnd)
+
+	// The wrapped list replaces the list of the embedded response, also when the JSON policy renames fields
+	p := &pamlogixImpl{}
+	p.SetJsonPolicy(&JsonPolicy{Naming: JsonNamingCamelCase})
+	data, err := marshalRpcJson(p, timed)
+	require.NoError(t, err)
+	var decoded struct {
+		Unlockables []map[string]any `json:"unlockables"`
+		Slots       int              `json:"slots"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded.Unlockables, 1)
+	assert.Contains(t, decoded.Unlockables[0], "secondsUntilEnd")
+	assert.Contains(t, decoded.Unlockables[0], "unlockCompleteTimeSec")
+	assert.Equal(t, 2, decoded.Slots)
+
+	streaks := newTimedStreaksList(map[string]*Streak{
+		"daily": {Id: "daily", CanClaim: true, EndTimeSec: 1 << 40, ResetTimeSec: 1 << 39},
+	})
+	assert.Equal(t, streaks.Streaks["daily"].SecondsUntilEnd, streaks.Streaks["daily"].SecondsUntilClaimExpiry)
+	assert.Equal(t, int64(1<<39)-streaks.ServerTimeSec, streaks.Streaks["daily"].SecondsUntilNextReset)
+}
@@ -0,0 +1,264 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	dataExportStorageCollection = "data_exports"
+	dataExportStorageKey        = "export"
+	defaultDataExportTTLSec     = 7 * 24 * 60 * 60
+	// dataExportCooldownSec is how long a user's last export is handed back rather than compiling another.
+	dataExportCooldownSec = 60 * 60
+	// dataExportMaxLedgerEntries bounds the wallet history compiled into an export.
+	dataExportMaxLedgerEntries = 10000
+	dataExportLedgerPageSize   = 100
+	dataExportAuctionsPageSize = 100
+)
+
+// DataExport is the pamlogix data kept about a user, compiled for their data access request. Sections of systems the
+// server doesn't run are left out.
+type DataExport struct {
+	UserId            string                         `json:"user_id"`
+	CreateTimeSec     int64                          `json:"create_time_sec"`
+	ExpiryTimeSec     int64                          `json:"expiry_time_sec"`
+	Wallet            map[string]int64               `json:"wallet,omitempty"`
+	WalletLedger      []*DataExportWalletLedgerEntry `json:"wallet_ledger,omitempty"`
+	Inventory         *Inventory                     `json:"inventory,omitempty"`
+	Donations         *EconomyDonationsList          `json:"donations,omitempty"`
+	Auctions          *DataExportAuctions            `json:"auctions,omitempty"`
+	EventLeaderboards []*EventLeaderboard            `json:"event_leaderboards,omitempty"`
+}
+
+// DataExportWalletLedgerEntry is one change to the user's wallet.
+type DataExportWalletLedgerEntry struct {
+	Id            string                 `json:"id"`
+	Changeset     map[string]int64       `json:"changeset"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	CreateTimeSec int64                  `json:"create_time_sec"`
+}
+
+// DataExportAuctions are the auctions the user created, bid on, or has in their archived history.
+type DataExportAuctions struct {
+	Created []*Auction `json:"created,omitempty"`
+	Bids    []*Auction `json:"bids,omitempty"`
+	History []*Auction `json:"history,omitempty"`
+}
+
+// DataExportReceipt locates a user's export, which they can read from storage until it expires.
+type DataExportReceipt struct {
+	Collection    string `json:"collection"`
+	Key           string `json:"key"`
+	CreateTimeSec int64  `json:"create_time_sec"`
+	ExpiryTimeSec int64  `json:"expiry_time_sec"`
+}
+
+func (b *BasePamlogix) dataExportTTLSec() int64 {
+	if b.config.DataExportTTLSec > 0 {
+		return b.config.DataExportTTLSec
+	}
+	return defaultDataExportTTLSec
+}
+
+// ExportData compiles the user's pamlogix data into a storage object only they can read, and notifies them once it's
+// ready. An export made in the last hour is handed back rather than compiling another.
+func (b *BasePamlogix) ExportData(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*DataExportReceipt, error) {
+	now := time.Now().Unix()
+
+	previous, err := readDataExport(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to read data export of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	if previous != nil && previous.ExpiryTimeSec > now && now-previous.CreateTimeSec < dataExportCooldownSec {
+		return dataExportReceipt(previous), nil
+	}
+
+	export, err := b.compileDataExport(ctx, logger, nk, userID)
+	if err != nil {
+		logger.Error("Failed to compile data export of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	export.CreateTimeSec = now
+	export.ExpiryTimeSec = now + b.dataExportTTLSec()
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		logger.Error("Failed to marshal data export of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	if _, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      dataExportStorageCollection,
+		Key:             dataExportStorageKey,
+		UserID:          userID,
+		Value:           string(data),
+		PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}}); err != nil {
+		logger.Error("Failed to write data export of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+
+	receipt := dataExportReceipt(export)
+	content := map[string]interface{}{
+		"collection":      receipt.Collection,
+		"key":             receipt.Key,
+		"expiry_time_sec": receipt.ExpiryTimeSec,
+	}
+	if err := b.SendNotification(ctx, logger, nk, userID, NotificationEventDataExportReady, nil, content); err != nil {
+		logger.Warn("Failed to send data export notification to user %s: %v", userID, err)
+	}
+	logger.Info("Exported data of user %s", userID)
+	return receipt, nil
+}
+
+// compileDataExport reads the user's data from each system that keeps some.
+func (b *BasePamlogix) compileDataExport(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*DataExport, error) {
+	export := &DataExport{UserId: userID}
+
+	account, err := nk.AccountGetId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Wallet != "" {
+		if err := json.Unmarshal([]byte(account.Wallet), &export.Wallet); err != nil {
+			return nil, err
+		}
+	}
+	if export.WalletLedger, err = exportWalletLedger(ctx, nk, userID); err != nil {
+		return nil, err
+	}
+
+	if b.pamlogix == nil {
+		return export, nil
+	}
+	if inventorySystem := b.pamlogix.GetInventorySystem(); inventorySystem != nil {
+		if export.Inventory, err = inventorySystem.ListInventoryItems(ctx, logger, nk, userID, ""); err != nil {
+			return nil, err
+		}
+	}
+	if economySystem := b.pamlogix.GetEconomySystem(); economySystem != nil {
+		donations, err := economySystem.DonationGet(ctx, logger, nk, []string{userID})
+		if err != nil {
+			return nil, err
+		}
+		export.Donations = donations.GetUserDonations()[userID]
+	}
+	if auctionsSystem := b.pamlogix.GetAuctionsSystem(); auctionsSystem != nil {
+		if export.Auctions, err = exportAuctions(ctx, logger, nk, auctionsSystem, userID); err != nil {
+			return nil, err
+		}
+	}
+	if eventLeaderboardsSystem := b.pamlogix.GetEventLeaderboardsSystem(); eventLeaderboardsSystem != nil {
+		if export.EventLeaderboards, err = eventLeaderboardsSystem.ListEventLeaderboard(ctx, logger, nk, userID, true, nil); err != nil {
+			return nil, err
+		}
+	}
+	return export, nil
+}
+
+// exportWalletLedger returns the user's wallet history, up to dataExportMaxLedgerEntries entries.
+func exportWalletLedger(ctx context.Context, nk runtime.NakamaModule, userID string) ([]*DataExportWalletLedgerEntry, error) {
+	entries := make([]*DataExportWalletLedgerEntry, 0)
+	cursor := ""
+	for len(entries) < dataExportMaxLedgerEntries {
+		items, nextCursor, err := nk.WalletLedgerList(ctx, userID, dataExportLedgerPageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			entries = append(entries, &DataExportWalletLedgerEntry{
+				Id:            item.GetID(),
+				Changeset:     item.GetChangeset(),
+				Metadata:      item.GetMetadata(),
+				CreateTimeSec: item.GetCreateTime(),
+			})
+		}
+		if nextCursor == "" || len(items) == 0 {
+			break
+		}
+		cursor = nextCursor
+	}
+	return entries, nil
+}
+
+// exportAuctions returns every page of the auctions the user created, bid on and has archived.
+func exportAuctions(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, auctionsSystem AuctionsSystem, userID string) (*DataExportAuctions, error) {
+	listAll := func(list func(cursor string) (*AuctionList, error)) ([]*Auction, error) {
+		auctions := make([]*Auction, 0)
+		cursor := ""
+		for {
+			page, err := list(cursor)
+			if err != nil {
+				return nil, err
+			}
+			auctions = append(auctions, page.GetAuctions()...)
+			if page.GetCursor() == "" || page.GetCursor() == cursor {
+				return auctions, nil
+			}
+			cursor = page.GetCursor()
+		}
+	}
+
+	auctions := &DataExportAuctions{}
+	var err error
+	if auctions.Created, err = listAll(func(cursor string) (*AuctionList, error) {
+		return auctionsSystem.ListCreated(ctx, logger, nk, userID, dataExportAuctionsPageSize, cursor)
+	}); err != nil {
+		return nil, err
+	}
+	if auctions.Bids, err = listAll(func(cursor string) (*AuctionList, error) {
+		return auctionsSystem.ListBids(ctx, logger, nk, userID, dataExportAuctionsPageSize, cursor)
+	}); err != nil {
+		return nil, err
+	}
+	if auctions.History, err = listAll(func(cursor string) (*AuctionList, error) {
+		return auctionsSystem.ListHistory(ctx, logger, nk, userID, dataExportAuctionsPageSize, cursor)
+	}); err != nil {
+		return nil, err
+	}
+	return auctions, nil
+}
+
+func readDataExport(ctx context.Context, nk runtime.NakamaModule, userID string) (*DataExport, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: dataExportStorageCollection,
+		Key:        dataExportStorageKey,
+		UserID:     userID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	export := &DataExport{}
+	if err := json.Unmarshal([]byte(objects[0].Value), export); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+func dataExportReceipt(export *DataExport) *DataExportReceipt {
+	return &DataExportReceipt{
+		Collection:    dataExportStorageCollection,
+		Key:           dataExportStorageKey,
+		CreateTimeSec: export.CreateTimeSec,
+		ExpiryTimeSec: export.ExpiryTimeSec,
+	}
+}
+
+// storageTTLRules expires data exports once they're no longer available to download.
+func (b *BasePamlogix) storageTTLRules() []*storageTTLRule {
+	return []*storageTTLRule{
+		{
+			Name:        "data exports",
+			Collection:  dataExportStorageCollection,
+			ExpiryField: "expiry_time_sec",
+		},
+	}
+}
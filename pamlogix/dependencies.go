@@ -0,0 +1,156 @@
+package pamlogix
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// systemDependency lists the gameplay systems a system calls into. Required systems must be configured for it to work
+// at all, while optional ones only enable features, e.g. economy grants items when inventory is loaded.
+type systemDependency struct {
+	Required []SystemType
+	Optional []SystemType
+}
+
+// systemDependencies declares the dependencies of each gameplay system. Systems resolve each other through Pamlogix
+// when they are called, so the order only matters for what is set up during initialization, but dependencies are
+// always initialized first so that stays safe as systems grow.
+var systemDependencies = map[SystemType]systemDependency{
+	SystemTypeAchievements:      {Optional: []SystemType{SystemTypeEconomy}},
+	SystemTypeAuctions:          {Required: []SystemType{SystemTypeEconomy}, Optional: []SystemType{SystemTypeInventory}},
+	SystemTypeEconomy:           {Optional: []SystemType{SystemTypeInventory, SystemTypeEnergy, SystemTypeProgression, SystemTypeAchievements, SystemTypeStats}},
+	SystemTypeEnergy:            {Optional: []SystemType{SystemTypeEconomy}},
+	SystemTypeEventLeaderboards: {Optional: []SystemType{SystemTypeEconomy}},
+	SystemTypeIncentives:        {Required: []SystemType{SystemTypeEconomy}},
+	SystemTypeInventory:         {Optional: []SystemType{SystemTypeEconomy}},
+	SystemTypeLeaderboards:      {Optional: []SystemType{SystemTypeEconomy}},
+	SystemTypeProgression:       {Optional: []SystemType{SystemTypeEconomy, SystemTypeInventory, SystemTypeEnergy, SystemTypeAchievements, SystemTypeStats}},
+	SystemTypeStreaks:           {Required: []SystemType{SystemTypeEconomy}},
+	SystemTypeUnlockables:       {Required: []SystemType{SystemTypeEconomy}, Optional: []SystemType{SystemTypeInventory}},
+}
+
+// systemName returns the name of the system as it appears in configs and logs.
+func systemName(systemType SystemType) string {
+	if name, found := systemNames[systemType]; found {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", systemType)
+}
+
+// orderSystemConfigs validates the dependencies of the configured systems and returns the configs sorted so each
+// system comes after the systems it depends on. Systems without a dependency between them keep their argument order.
+func orderSystemConfigs(configs []SystemConfig) ([]SystemConfig, error) {
+	byType := make(map[SystemType]SystemConfig, len(configs))
+	for _, config := range configs {
+		if _, found := byType[config.GetType()]; found {
+			return nil, runtime.NewError(fmt.Sprintf("system %s configured more than once", systemName(config.GetType())), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		byType[config.GetType()] = config
+	}
+
+	for _, config := range configs {
+		for _, required := range systemDependencies[config.GetType()].Required {
+			if _, found := byType[required]; !found {
+				return nil, runtime.NewError(fmt.Sprintf("system %s requires system %s", systemName(config.GetType()), systemName(required)), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+			}
+		}
+	}
+
+	// Optional dependencies may point both ways, e.g. economy and inventory use each other, so only a cycle made
+	// entirely of required dependencies is an error. Otherwise the optional edge that closes the cycle is dropped.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[SystemType]int, len(configs))
+	ordered := make([]SystemConfig, 0, len(configs))
+	var path []SystemType
+	var pathRequired []bool
+	var visit func(systemType SystemType, required bool) error
+	visit = func(systemType SystemType, required bool) error {
+		switch state[systemType] {
+		case visited:
+			return nil
+		case visiting:
+			start := len(path) - 1
+			for path[start] != systemType {
+				start--
+			}
+			if !required {
+				return nil
+			}
+			for _, edgeRequired := range pathRequired[start+1:] {
+				if !edgeRequired {
+					return nil
+				}
+			}
+			names := make([]string, 0, len(path)-start+1)
+			for _, pathType := range path[start:] {
+				names = append(names, systemName(pathType))
+			}
+			names = append(names, systemName(systemType))
+			return runtime.NewError(fmt.Sprintf("system dependency cycle: %s", strings.Join(names, " -> ")), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		state[systemType] = visiting
+		path = append(path, systemType)
+		pathRequired = append(pathRequired, required)
+		dependency := systemDependencies[systemType]
+		for i, dependsOn := range append(append([]SystemType{}, dependency.Required...), dependency.Optional...) {
+			if _, found := byType[dependsOn]; !found {
+				continue
+			}
+			if err := visit(dependsOn, i < len(dependency.Required)); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		pathRequired = pathRequired[:len(pathRequired)-1]
+		state[systemType] = visited
+		ordered = append(ordered, byType[systemType])
+		return nil
+	}
+	for _, config := range configs {
+		if err := visit(config.GetType(), true); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// logSystemDependencies reports the initialization order and which optional dependencies are missing, so a system that
+// quietly runs with fewer features is visible in the init log.
+func logSystemDependencies(logger runtime.Logger, ordered []SystemConfig) {
+	loaded := make(map[SystemType]bool, len(ordered))
+	names := make([]string, 0, len(ordered))
+	for _, config := range ordered {
+		loaded[config.GetType()] = true
+		names = append(names, systemName(config.GetType()))
+	}
+	logger.Info("Initializing systems in dependency order: %s", strings.Join(names, ", "))
+
+	for _, config := range ordered {
+		dependency := systemDependencies[config.GetType()]
+		if len(dependency.Required) == 0 && len(dependency.Optional) == 0 {
+			continue
+		}
+		var required, optional, missing []string
+		for _, dependsOn := range dependency.Required {
+			required = append(required, systemName(dependsOn))
+		}
+		for _, dependsOn := range dependency.Optional {
+			if loaded[dependsOn] {
+				optional = append(optional, systemName(dependsOn))
+			} else {
+				missing = append(missing, systemName(dependsOn))
+			}
+		}
+		logger.Info("System %s dependencies: required [%s], optional [%s]", systemName(config.GetType()), strings.Join(required, ", "), strings.Join(optional, ", "))
+		if len(missing) > 0 {
+			logger.Warn("System %s runs without optional systems: %s", systemName(config.GetType()), strings.Join(missing, ", "))
+		}
+	}
+}
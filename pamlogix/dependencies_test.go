@@ -0,0 +1,30 @@
+package pamlogix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderSystemConfigs(t *testing.T) {
+	ordered, err := orderSystemConfigs([]SystemConfig{
+		WithAuctionsSystem("auctions.json", true),
+		WithBaseSystem("base.json", true),
+		WithEconomySystem("economy.json", true),
+		WithInventorySystem("inventory.json", true),
+	})
+	require.NoError(t, err)
+	types := make([]SystemType, 0, len(ordered))
+	for _, config := range ordered {
+		types = append(types, config.GetType())
+	}
+	// Economy and inventory depend on each other optionally, the first one reached is initialized last
+	assert.Equal(t, []SystemType{SystemTypeInventory, SystemTypeEconomy, SystemTypeAuctions, SystemTypeBase}, types)
+
+	_, err = orderSystemConfigs([]SystemConfig{WithStreaksSystem("streaks.json", true)})
+	assert.ErrorContains(t, err, "system streaks requires system economy")
+
+	_, err = orderSystemConfigs([]SystemConfig{WithStatsSystem("stats.json", true), WithStatsSystem("stats.json", false)})
+	assert.ErrorContains(t, err, "configured more than once")
+}
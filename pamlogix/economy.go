@@ -9,24 +9,35 @@ import (
 )
 
 var (
-	ErrEconomyNoItem            = runtime.NewError("item not found", INVALID_ARGUMENT_ERROR_CODE)                        // INVALID_ARGUMENT
-	ErrEconomyItemUnavailable   = runtime.NewError("item unavailable", INVALID_ARGUMENT_ERROR_CODE)                      // INVALID_ARGUMENT
-	ErrEconomyNoSku             = runtime.NewError("sku not found", INVALID_ARGUMENT_ERROR_CODE)                         // INVALID_ARGUMENT
-	ErrEconomySkuInvalid        = runtime.NewError("invalid sku", INVALID_ARGUMENT_ERROR_CODE)                           // INVALID_ARGUMENT
-	ErrEconomyNotEnoughCurrency = runtime.NewError("not enough currency for purchase", INVALID_ARGUMENT_ERROR_CODE)      // INVALID_ARGUMENT
-	ErrEconomyNotEnoughItem     = runtime.NewError("not enough item", INVALID_ARGUMENT_ERROR_CODE)                       // INVALID_ARGUMENT
-	ErrEconomyReceiptInvalid    = runtime.NewError("invalid receipt", INVALID_ARGUMENT_ERROR_CODE)                       // INVALID_ARGUMENT
-	ErrEconomyReceiptDuplicate  = runtime.NewError("duplicate receipt", INVALID_ARGUMENT_ERROR_CODE)                     // INVALID_ARGUMENT
-	ErrEconomyReceiptMismatch   = runtime.NewError("mismatched product receipt", INVALID_ARGUMENT_ERROR_CODE)            // INVALID_ARGUMENT
-	ErrEconomyNoPlacement       = runtime.NewError("placement not found", INVALID_ARGUMENT_ERROR_CODE)                   // INVALID_ARGUMENT
-	ErrEconomyNoDonation        = runtime.NewError("donation not found", INVALID_ARGUMENT_ERROR_CODE)                    // INVALID_ARGUMENT
-	ErrEconomyMaxDonation       = runtime.NewError("donation maximum contribution reached", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
-	ErrEconomyClaimedDonation   = runtime.NewError("donation already claimed", INVALID_ARGUMENT_ERROR_CODE)              // INVALID_ARGUMENT
+	ErrEconomyNoItem               = runtime.NewError("item not found", INVALID_ARGUMENT_ERROR_CODE)                                        // INVALID_ARGUMENT
+	ErrEconomyItemUnavailable      = runtime.NewError("item unavailable", INVALID_ARGUMENT_ERROR_CODE)                                      // INVALID_ARGUMENT
+	ErrEconomyNoSku                = runtime.NewError("sku not found", INVALID_ARGUMENT_ERROR_CODE)                                         // INVALID_ARGUMENT
+	ErrEconomySkuInvalid           = runtime.NewError("invalid sku", INVALID_ARGUMENT_ERROR_CODE)                                           // INVALID_ARGUMENT
+	ErrEconomyNotEnoughCurrency    = runtime.NewError("not enough currency for purchase", INVALID_ARGUMENT_ERROR_CODE)                      // INVALID_ARGUMENT
+	ErrEconomyNotEnoughItem        = runtime.NewError("not enough item", INVALID_ARGUMENT_ERROR_CODE)                                       // INVALID_ARGUMENT
+	ErrEconomyReceiptInvalid       = runtime.NewError("invalid receipt", INVALID_ARGUMENT_ERROR_CODE)                                       // INVALID_ARGUMENT
+	ErrEconomyReceiptDuplicate     = runtime.NewError("duplicate receipt", INVALID_ARGUMENT_ERROR_CODE)                                     // INVALID_ARGUMENT
+	ErrEconomyReceiptMismatch      = runtime.NewError("mismatched product receipt", INVALID_ARGUMENT_ERROR_CODE)                            // INVALID_ARGUMENT
+	ErrEconomyNoPlacement          = runtime.NewError("placement not found", INVALID_ARGUMENT_ERROR_CODE)                                   // INVALID_ARGUMENT
+	ErrEconomyPlacementLimit       = runtime.NewError("placement daily limit reached", FAILED_PRECONDITION_ERROR_CODE)                      // FAILED_PRECONDITION
+	ErrEconomyPlacementCooldown    = runtime.NewError("placement on cooldown", FAILED_PRECONDITION_ERROR_CODE)                              // FAILED_PRECONDITION
+	ErrEconomyNoDonation           = runtime.NewError("donation not found", INVALID_ARGUMENT_ERROR_CODE)                                    // INVALID_ARGUMENT
+	ErrEconomyMaxDonation          = runtime.NewError("donation maximum contribution reached", INVALID_ARGUMENT_ERROR_CODE)                 // INVALID_ARGUMENT
+	ErrEconomyClaimedDonation      = runtime.NewError("donation already claimed", INVALID_ARGUMENT_ERROR_CODE)                              // INVALID_ARGUMENT
+	ErrEconomyNoModifierJob        = runtime.NewError("modifier job not found", NOT_FOUND_ERROR_CODE)                                       // NOT_FOUND
+	ErrEconomyNoSnapshot           = runtime.NewError("economy snapshot not found", NOT_FOUND_ERROR_CODE)                                   // NOT_FOUND
+	ErrEconomySnapshotServerOnly   = runtime.NewError("economy snapshots are only available to server calls", PERMISSION_DENIED_ERROR_CODE) // PERMISSION_DENIED
+	ErrEconomyNoSubscription       = runtime.NewError("subscription not found", NOT_FOUND_ERROR_CODE)                                       // NOT_FOUND
+	ErrEconomyDebitServerOnly      = runtime.NewError("economy debits are only available to server calls", PERMISSION_DENIED_ERROR_CODE)    // PERMISSION_DENIED
+	ErrEconomyEventLogServerOnly   = runtime.NewError("economy event log is only available to server calls", PERMISSION_DENIED_ERROR_CODE)  // PERMISSION_DENIED
+	ErrEconomyPurchaseGrantPending = runtime.NewError("purchase reward not granted yet, it will be retried", UNAVAILABLE_ERROR_CODE)        // UNAVAILABLE
+	ErrEconomyBadIdempotencyKey    = runtime.NewError("invalid idempotency key", INVALID_ARGUMENT_ERROR_CODE)                               // INVALID_ARGUMENT
 
 	ErrInventoryNotInitialized = runtime.NewError("inventory not initialized for batch", INTERNAL_ERROR_CODE) // INTERNAL
 	ErrItemsNotConsumable      = runtime.NewError("items not consumable", INVALID_ARGUMENT_ERROR_CODE)        // INVALID_ARGUMENT
 	ErrItemsInsufficient       = runtime.NewError("insufficient items", FAILED_PRECONDITION_ERROR_CODE)       // FAILED_PRECONDITION
 	ErrCurrencyInsufficient    = runtime.NewError("insufficient currency", FAILED_PRECONDITION_ERROR_CODE)    // FAILED_PRECONDITION
+	ErrEnergyInsufficient      = runtime.NewError("insufficient energy", FAILED_PRECONDITION_ERROR_CODE)      // FAILED_PRECONDITION
 )
 
 // EconomyConfig is the data definition for the EconomySystem type.
@@ -36,6 +47,74 @@ type EconomyConfig struct {
 	StoreItems        map[string]*EconomyConfigStoreItem `json:"store_items,omitempty"`
 	Placements        map[string]*EconomyConfigPlacement `json:"placements,omitempty"`
 	AllowFakeReceipts bool                               `json:"allow_fake_receipts,omitempty"`
+	SandboxPurchases  string                             `json:"sandbox_purchases,omitempty"`
+	// CurrencyDailyCaps limits how much of each currency a user can receive per UTC day, keyed by grant source and then
+	// currency ID.
+	CurrencyDailyCaps map[string]map[string]int64 `json:"currency_daily_caps,omitempty"`
+	// Currencies describes how clients display each currency, keyed by currency ID, and caps the balances users hold.
+	Currencies map[string]*EconomyConfigCurrency `json:"currencies,omitempty"`
+	// MaxSnapshots is how many economy snapshots are kept per user before the oldest are deleted. Defaults to 10.
+	MaxSnapshots int `json:"max_snapshots,omitempty"`
+	// EventLog appends every grant, spend, purchase and auction settlement to the economy event log, which downstream
+	// services read with EventLogList.
+	EventLog bool `json:"event_log,omitempty"`
+	// Subscriptions are the store products billed on a recurring period, such as monthly passes.
+	Subscriptions *EconomyConfigSubscriptions `json:"subscriptions,omitempty"`
+}
+
+// EconomyConfigSubscriptions configures the subscriptions users can buy from the app stores.
+type EconomyConfigSubscriptions struct {
+	// Items are the subscriptions, keyed by subscription ID.
+	Items map[string]*EconomyConfigSubscription `json:"items,omitempty"`
+	// CheckIntervalSec is how often the subscription check job refreshes every user's subscriptions from the stores to
+	// grant renewals and end lapsed entitlements. Defaults to an hour.
+	CheckIntervalSec int64 `json:"check_interval_sec,omitempty"`
+}
+
+// EconomyConfigSubscription is a subscription sold in the app stores under a product ID.
+type EconomyConfigSubscription struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Sku is the store product ID of the subscription.
+	Sku string `json:"sku,omitempty"`
+	// Reward is granted when the user subscribes, and again each time the subscription renews.
+	Reward               *EconomyConfigReward `json:"reward,omitempty"`
+	AdditionalProperties map[string]string    `json:"additional_properties,omitempty"`
+}
+
+// EconomyConfigCurrency is the display metadata of a currency and the most of it a user can hold.
+type EconomyConfigCurrency struct {
+	Name string `json:"name,omitempty"`
+	Icon string `json:"icon,omitempty"`
+	// DecimalPlaces is how many digits of the stored amount are shown after the decimal point, for currencies kept in
+	// minor units such as cents.
+	DecimalPlaces int `json:"decimal_places,omitempty"`
+	// MaxBalance caps the user's balance. Rewards which would go over it are cut down to what fits, and zero means no
+	// cap. Currencies a user gets back, like refunds and auction proceeds, and direct grants aren't capped.
+	MaxBalance int64 `json:"max_balance,omitempty"`
+}
+
+// Grant sources that CurrencyDailyCaps can be configured for.
+const (
+	EconomyGrantSourcePlacement = "placement"
+	EconomyGrantSourceDonation  = "donation"
+	EconomyGrantSourceReferral  = "referral"
+)
+
+// How purchases validated against a store's sandbox environment are handled, set with SandboxPurchases.
+const (
+	// EconomySandboxPurchasesAllow grants sandbox purchases like any other purchase. This is the default.
+	EconomySandboxPurchasesAllow = "allow"
+	// EconomySandboxPurchasesIsolate grants sandbox purchases into a separate test wallet and inventory.
+	EconomySandboxPurchasesIsolate = "isolate"
+	// EconomySandboxPurchasesDeny rejects sandbox purchases.
+	EconomySandboxPurchasesDeny = "deny"
+)
+
+// EconomySandboxBalance is the test wallet and inventory sandbox purchases are granted into in isolate mode.
+type EconomySandboxBalance struct {
+	Wallet map[string]int64 `json:"wallet"`
+	Items  map[string]int64 `json:"items"`
 }
 
 type EconomyConfigDonation struct {
@@ -51,10 +130,7 @@ type EconomyConfigDonation struct {
 	AdditionalProperties     map[string]string          `json:"additional_properties,omitempty"`
 }
 
-type EconomyConfigDonationCost struct {
-	Currencies map[string]int64 `json:"currencies,omitempty"`
-	Items      map[string]int64 `json:"items,omitempty"`
-}
+type EconomyConfigDonationCost = Cost
 
 type EconomyConfigInitializeUser struct {
 	Currencies map[string]int64 `json:"currencies,omitempty"`
@@ -64,6 +140,10 @@ type EconomyConfigInitializeUser struct {
 type EconomyConfigPlacement struct {
 	Reward               *EconomyConfigReward `json:"reward,omitempty"`
 	AdditionalProperties map[string]string    `json:"additional_properties,omitempty"`
+	// MaxDailyCompletions limits how many times per UTC day a user can complete the placement. Zero means no limit.
+	MaxDailyCompletions int `json:"max_daily_completions,omitempty"`
+	// CooldownSec is how long after a completion the placement can't be started again.
+	CooldownSec int64 `json:"cooldown_sec,omitempty"`
 }
 
 type EconomyConfigReward struct {
@@ -162,6 +242,25 @@ type EconomyConfigStoreItem struct {
 	AdditionalProperties map[string]string           `json:"additional_properties,omitempty"`
 	Disabled             bool                        `json:"disabled,omitempty"`
 	Unavailable          bool                        `json:"unavailable,omitempty"`
+	// Conditions a user must meet to buy the item.
+	Conditions *EconomyConfigStoreItemConditions `json:"conditions,omitempty"`
+}
+
+// EconomyConfigStoreItemConditions gate a store item behind the user's progress in other systems. Conditions that refer
+// to a system which isn't loaded are never met.
+type EconomyConfigStoreItemConditions struct {
+	// Progressions the user must have unlocked.
+	Progressions []string `json:"progressions,omitempty"`
+	// Achievements the user must have completed.
+	Achievements []string `json:"achievements,omitempty"`
+	// MinStats are the lowest values of the user's public or private stats, by stat name.
+	MinStats map[string]int64 `json:"min_stats,omitempty"`
+	// Rule is an expression on the user's state which must hold, such as `stats.wins >= 10`. See Rule for what it can
+	// read. A rule which fails to evaluate isn't met, and one which fails to compile fails the config.
+	Rule string `json:"rule,omitempty"`
+	// Hidden leaves the item out of the store listing while its conditions aren't met, instead of listing it as
+	// unavailable.
+	Hidden bool `json:"hidden,omitempty"`
 }
 
 type EconomyConfigStoreItemCost struct {
@@ -169,6 +268,357 @@ type EconomyConfigStoreItemCost struct {
 	Sku        string           `json:"sku,omitempty"`
 }
 
+// Formats supported by the purchase transactions export.
+const (
+	EconomyExportFormatCSV    = "csv"
+	EconomyExportFormatNDJSON = "ndjson"
+)
+
+// EconomyPurchaseTransactionsExportRequest is the request payload to export purchase transactions for reconciliation.
+type EconomyPurchaseTransactionsExportRequest struct {
+	StartTimeSec int64  `json:"start_time_sec,omitempty"`
+	EndTimeSec   int64  `json:"end_time_sec,omitempty"`
+	Format       string `json:"format,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	Cursor       string `json:"cursor,omitempty"`
+}
+
+// EconomyPurchaseTransactionsExport is one page of exported purchase transactions. Data holds the rows in the
+// requested format, with the CSV header only included on the first page.
+type EconomyPurchaseTransactionsExport struct {
+	Format string `json:"format"`
+	Data   string `json:"data"`
+	Count  int    `json:"count"`
+	// Cursor is the next page's cursor, kept for one release. Use NextCursor.
+	Cursor     string `json:"cursor,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// EconomyModifierJobAction is what a modifier job does to each of its users.
+type EconomyModifierJobAction string
+
+const (
+	EconomyModifierJobActionGrant  EconomyModifierJobAction = "grant"
+	EconomyModifierJobActionRevoke EconomyModifierJobAction = "revoke"
+)
+
+// EconomyModifierJobStatus is the progress of a modifier job through its users.
+type EconomyModifierJobStatus string
+
+const (
+	EconomyModifierJobStatusRunning   EconomyModifierJobStatus = "running"
+	EconomyModifierJobStatusCompleted EconomyModifierJobStatus = "completed"
+	EconomyModifierJobStatusFailed    EconomyModifierJobStatus = "failed"
+)
+
+// EconomyModifierJobSegment picks the users of a modifier job other than by listing them.
+type EconomyModifierJobSegment struct {
+	// All targets every user account.
+	All bool `json:"all,omitempty"`
+	// GroupId targets the members of a Nakama group, such as a team.
+	GroupId string `json:"group_id,omitempty"`
+}
+
+// EconomyModifierJobRequest grants or revokes reward and energy modifiers for many users at once, e.g. a 2x XP weekend
+// for everyone.
+type EconomyModifierJobRequest struct {
+	Action  EconomyModifierJobAction   `json:"action"`
+	UserIds []string                   `json:"user_ids,omitempty"`
+	Segment *EconomyModifierJobSegment `json:"segment,omitempty"`
+	// RewardModifiers and EnergyModifiers are granted to each user by a grant job.
+	RewardModifiers []*RewardModifier       `json:"reward_modifiers,omitempty"`
+	EnergyModifiers []*RewardEnergyModifier `json:"energy_modifiers,omitempty"`
+	// ModifierIds are the reward and energy modifiers removed from each user by a revoke job.
+	ModifierIds []string `json:"modifier_ids,omitempty"`
+}
+
+// EconomyModifierJob tracks a modifier job as it fans out over its users in batches.
+type EconomyModifierJob struct {
+	Id      string                     `json:"id"`
+	Request *EconomyModifierJobRequest `json:"request"`
+	Status  EconomyModifierJobStatus   `json:"status"`
+	// Processed counts the users the job has reached so far, of which Failed couldn't be updated.
+	Processed int `json:"processed"`
+	Failed    int `json:"failed"`
+	// FailedUserIds holds the first users which couldn't be updated, so they can be retried with a new job.
+	FailedUserIds   []string `json:"failed_user_ids,omitempty"`
+	Error           string   `json:"error,omitempty"`
+	Cursor          string   `json:"cursor,omitempty"`
+	CreateTimeSec   int64    `json:"create_time_sec"`
+	UpdateTimeSec   int64    `json:"update_time_sec"`
+	CompleteTimeSec int64    `json:"complete_time_sec,omitempty"`
+}
+
+// EconomyModifierJobGetRequest is the request payload to check the progress of a modifier job.
+type EconomyModifierJobGetRequest struct {
+	Id string `json:"id"`
+}
+
+// EconomySnapshot is a point-in-time copy of a user's wallet, inventory and modifiers, which support can restore to
+// roll the user back after a bad grant or loss.
+type EconomySnapshot struct {
+	Id            string                   `json:"id"`
+	UserId        string                   `json:"user_id"`
+	Reason        string                   `json:"reason,omitempty"`
+	CreateTimeSec int64                    `json:"create_time_sec"`
+	Wallet        map[string]int64         `json:"wallet"`
+	Objects       []*EconomySnapshotObject `json:"objects,omitempty"`
+}
+
+// EconomySnapshotObject is a copy of one of the user's inventory or modifier storage objects.
+type EconomySnapshotObject struct {
+	Collection      string `json:"collection"`
+	Key             string `json:"key"`
+	Value           string `json:"value"`
+	PermissionRead  int    `json:"permission_read"`
+	PermissionWrite int    `json:"permission_write"`
+}
+
+// EconomyRewardGrantResult is the outcome of one user's grant in a bulk reward grant. Err is set when nothing was
+// granted to the user.
+type EconomyRewardGrantResult struct {
+	NewItems          map[string]*InventoryItem `json:"new_items,omitempty"`
+	UpdatedItems      map[string]*InventoryItem `json:"updated_items,omitempty"`
+	NotGrantedItemIDs map[string]int64          `json:"not_granted_item_ids,omitempty"`
+	Err               error                     `json:"-"`
+}
+
+// EconomySnapshotList is a user's snapshots, newest first. The stored objects are left out to keep the list small.
+type EconomySnapshotList struct {
+	Snapshots []*EconomySnapshot `json:"snapshots"`
+}
+
+// EconomySnapshotCreateRequest is the request payload to snapshot a user's economy.
+type EconomySnapshotCreateRequest struct {
+	UserId string `json:"user_id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// EconomySnapshotListRequest is the request payload to list a user's economy snapshots.
+type EconomySnapshotListRequest struct {
+	UserId string `json:"user_id"`
+}
+
+// EconomySnapshotRestoreRequest is the request payload to roll a user's economy back to a snapshot.
+type EconomySnapshotRestoreRequest struct {
+	UserId     string `json:"user_id"`
+	SnapshotId string `json:"snapshot_id"`
+}
+
+// Types of economy event in the event log.
+const (
+	EconomyEventTypeGrant      = "grant"
+	EconomyEventTypeSpend      = "spend"
+	EconomyEventTypePurchase   = "purchase"
+	EconomyEventTypeSettlement = "settlement"
+)
+
+// EconomyEvent is one record of the economy event log. Sequence numbers start at 1 and increase by one with every
+// event, so a consumer which remembers the last sequence it read never misses or repeats an event.
+type EconomyEvent struct {
+	Sequence   int64                  `json:"seq"`
+	Type       string                 `json:"type"`
+	UserId     string                 `json:"user_id"`
+	Currencies map[string]int64       `json:"currencies,omitempty"`
+	Items      map[string]int64       `json:"items,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	// Memo is the note the change was made with, such as a support ticket ID or a campaign name. See
+	// WithTransactionMemo.
+	Memo          string `json:"memo,omitempty"`
+	CreateTimeSec int64  `json:"create_time_sec"`
+}
+
+// EconomyEventLogList is a page of the economy event log, oldest first.
+type EconomyEventLogList struct {
+	Events []*EconomyEvent `json:"events"`
+	// NextSequence is the sequence to read after for the next page. It equals LatestSequence once the consumer has
+	// caught up.
+	NextSequence int64 `json:"next_sequence"`
+	// LatestSequence is the sequence of the newest event in the log.
+	LatestSequence int64 `json:"latest_sequence"`
+}
+
+// EconomyEventLogListRequest is the request payload to read the economy event log.
+type EconomyEventLogListRequest struct {
+	AfterSequence int64 `json:"after_sequence,omitempty"`
+	Limit         int   `json:"limit,omitempty"`
+}
+
+// EconomySummary is a compact view of the user's economy across systems, for a game HUD to show in one call.
+type EconomySummary struct {
+	Wallet map[string]int64 `json:"wallet"`
+	// RewardModifiers and EnergyModifiers are the user's modifiers which haven't expired yet.
+	RewardModifiers []*ActiveRewardModifier `json:"reward_modifiers,omitempty"`
+	EnergyModifiers []*ActiveRewardModifier `json:"energy_modifiers,omitempty"`
+	// PurchaseIntents are the store purchases the user started and hasn't completed or cancelled.
+	PurchaseIntents []*EconomySummaryPurchaseIntent `json:"purchase_intents,omitempty"`
+	// Donations are the user's donations with contributions waiting to be claimed.
+	Donations []*EconomySummaryDonation `json:"donations,omitempty"`
+	// Auctions are the ended auctions the user can claim, as the winner or the creator.
+	Auctions []*EconomySummaryAuction `json:"auctions,omitempty"`
+	// Placements are the placements the user started viewing and hasn't finished.
+	Placements    []*EconomyPlacementStatus `json:"placements,omitempty"`
+	ServerTimeSec int64                     `json:"server_time_sec"`
+}
+
+// EconomySummaryPurchaseIntent is a pending purchase intent.
+type EconomySummaryPurchaseIntent struct {
+	ItemId        string `json:"item_id"`
+	StoreType     string `json:"store_type,omitempty"`
+	ExpireTimeSec int64  `json:"expire_time_sec,omitempty"`
+}
+
+// EconomySummaryDonation is a donation with contributions the user can claim.
+type EconomySummaryDonation struct {
+	DonationId     string `json:"donation_id"`
+	ClaimableCount int64  `json:"claimable_count"`
+	ExpireTimeSec  int64  `json:"expire_time_sec,omitempty"`
+}
+
+// EconomySummaryAuction is an ended auction the user can claim.
+type EconomySummaryAuction struct {
+	AuctionId string `json:"auction_id"`
+	// Role is "winner" when the user won the auction, or "creator" when they listed it.
+	Role       string `json:"role"`
+	EndTimeSec int64  `json:"end_time_sec"`
+}
+
+// EconomyGrantInstancesRequest is the JSON request payload for the economy grant RPC. It extends EconomyGrantRequest
+// with item instances so granted items can carry string and numeric properties, and a dry run flag to preview the
+// wallet the grant would leave without granting anything.
+type EconomyGrantInstancesRequest struct {
+	*EconomyGrantRequest
+	ItemInstances map[string]*RewardInventoryItem `json:"item_instances,omitempty"`
+	DryRun        bool                            `json:"dry_run,omitempty"`
+}
+
+// EconomySubscription is a user's subscription as last validated with the store.
+type EconomySubscription struct {
+	SubscriptionId        string `json:"subscription_id"`
+	StoreType             string `json:"store_type"`
+	OriginalTransactionId string `json:"original_transaction_id,omitempty"`
+	// Active is whether the user is entitled to the subscription now.
+	Active bool `json:"active"`
+	// Cancelled is set once the user cancels. The subscription stays active until it expires, and renewals aren't
+	// rewarded until the user subscribes again.
+	Cancelled     bool  `json:"cancelled,omitempty"`
+	Sandbox       bool  `json:"sandbox,omitempty"`
+	StartTimeSec  int64 `json:"start_time_sec"`
+	ExpiryTimeSec int64 `json:"expiry_time_sec"`
+	// RewardedExpiryTimeSec is the expiry time of the latest period the reward was granted for.
+	RewardedExpiryTimeSec int64 `json:"rewarded_expiry_time_sec,omitempty"`
+	CheckTimeSec          int64 `json:"check_time_sec"`
+}
+
+// EconomySubscriptionList is the user's subscriptions, keyed by subscription ID.
+type EconomySubscriptionList struct {
+	Subscriptions map[string]*EconomySubscription `json:"subscriptions"`
+	ServerTimeSec int64                           `json:"server_time_sec"`
+}
+
+// EconomySubscriptionPurchaseRequest is the request payload to subscribe with a store receipt.
+type EconomySubscriptionPurchaseRequest struct {
+	SubscriptionId string           `json:"subscription_id"`
+	StoreType      EconomyStoreType `json:"store_type"`
+	Receipt        string           `json:"receipt"`
+}
+
+// EconomySubscriptionPurchaseAck is the subscription a receipt started or renewed, and the reward granted for it.
+type EconomySubscriptionPurchaseAck struct {
+	Subscription *EconomySubscription `json:"subscription"`
+	Reward       *Reward              `json:"reward,omitempty"`
+}
+
+// EconomySubscriptionCancelRequest is the request payload to cancel a subscription.
+type EconomySubscriptionCancelRequest struct {
+	SubscriptionId string `json:"subscription_id"`
+}
+
+// EconomyPendingGrant is the reward of a validated purchase which hasn't been granted yet. It's kept, keyed by the
+// purchase's transaction ID, until the grant succeeds.
+type EconomyPendingGrant struct {
+	TransactionId string                 `json:"transaction_id"`
+	ItemId        string                 `json:"item_id"`
+	StoreType     string                 `json:"store_type"`
+	Reward        *Reward                `json:"reward"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	CreateTimeSec int64                  `json:"create_time_sec"`
+	// Attempts is how many times granting the reward failed.
+	Attempts           int    `json:"attempts,omitempty"`
+	LastAttemptTimeSec int64  `json:"last_attempt_time_sec,omitempty"`
+	LastError          string `json:"last_error,omitempty"`
+}
+
+// EconomyPurchaseGrantsReconcile is the outcome of retrying a user's pending purchase grants.
+type EconomyPurchaseGrantsReconcile struct {
+	Granted []*EconomyPendingGrant `json:"granted"`
+	Pending []*EconomyPendingGrant `json:"pending"`
+	// Wallet is the user's wallet after the grants, set when any were granted.
+	Wallet map[string]int64 `json:"wallet,omitempty"`
+}
+
+// EconomyDebitRequest is the request payload for the server-only debit RPC.
+type EconomyDebitRequest struct {
+	UserId     string                 `json:"user_id"`
+	Currencies map[string]int64       `json:"currencies,omitempty"`
+	Items      map[string]int64       `json:"items,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	DryRun     bool                   `json:"dry_run,omitempty"`
+}
+
+// EconomyTeamPurchaseRequest is the request payload to buy a store item with a team's treasury.
+type EconomyTeamPurchaseRequest struct {
+	ItemId string `json:"item_id,omitempty"`
+	TeamId string `json:"team_id,omitempty"`
+}
+
+// EconomyPurchaseIntentCancelRequest is the request payload to cancel a pending purchase intent.
+type EconomyPurchaseIntentCancelRequest struct {
+	ItemId string `json:"item_id,omitempty"`
+}
+
+// EconomyCanAffordRequest is the request payload to check whether the user can afford a cost before spending it.
+type EconomyCanAffordRequest struct {
+	Cost *Cost `json:"cost,omitempty"`
+}
+
+// EconomyCanAfford is the result of an affordability check. Missing holds how much of each currency, item and energy
+// the user lacks, and is only set when they can't afford the cost.
+type EconomyCanAfford struct {
+	CanAfford bool  `json:"can_afford"`
+	Missing   *Cost `json:"missing,omitempty"`
+}
+
+// EconomyCurrencies is the response to the currencies RPC, keyed by currency ID.
+type EconomyCurrencies struct {
+	Currencies map[string]*EconomyConfigCurrency `json:"currencies"`
+}
+
+// EconomyPlacementList is the status of every configured placement for a user, keyed by placement ID.
+type EconomyPlacementList struct {
+	Placements map[string]*EconomyPlacementSummary `json:"placements"`
+	// RemainingCurrencyCaps is how much more of each capped currency placement rewards can grant today, shared by all
+	// placements.
+	RemainingCurrencyCaps map[string]int64 `json:"remaining_currency_caps,omitempty"`
+	// DailyResetTimeSec is when the daily completion limits and currency caps next reset, at UTC midnight.
+	DailyResetTimeSec int64 `json:"daily_reset_time_sec"`
+	ServerTimeSec     int64 `json:"server_time_sec"`
+}
+
+// EconomyPlacementSummary is a placement's latest status for a user, along with what remains of its daily limits.
+type EconomyPlacementSummary struct {
+	// Status of the user's latest view of the placement, nil if they never started it.
+	Status *EconomyPlacementStatus `json:"status,omitempty"`
+	// Available is whether the placement can be started now.
+	Available bool `json:"available"`
+	// RemainingDailyCompletions is how many more times the placement can be completed today, or -1 when unlimited.
+	RemainingDailyCompletions int `json:"remaining_daily_completions"`
+	// AvailableTimeSec is when the placement can next be started, set while it's on cooldown or at its daily limit.
+	AvailableTimeSec     int64             `json:"available_time_sec,omitempty"`
+	AdditionalProperties map[string]string `json:"additional_properties,omitempty"`
+}
+
 // EconomyPlacementInfo contains information about a placement instance.
 type EconomyPlacementInfo struct {
 	// Placement configuration.
@@ -190,20 +640,40 @@ type EconomySystem interface {
 	// RewardConvert transforms a wire representation of a reward into an equivalent configuration representation.
 	RewardConvert(contents *AvailableRewards) (rewardConfig *EconomyConfigReward)
 
+	// RewardConvertReverse transforms a reward configuration into the equivalent wire representation, the inverse of
+	// RewardConvert.
+	RewardConvertReverse(rewardConfig *EconomyConfigReward) (contents *AvailableRewards)
+
 	// RewardRoll takes a reward configuration and rolls an actual reward from it, applying all appropriate rules.
 	RewardRoll(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, rewardConfig *EconomyConfigReward) (reward *Reward, err error)
 
-	// RewardGrant updates a user's economy, inventory, and/or energy models with the contents of a rolled reward.
+	// RewardRollN rolls a reward configuration count times and combines the rolls into one reward, so a reward earned
+	// many times over can be granted at once.
+	RewardRollN(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, rewardConfig *EconomyConfigReward, count int64) (reward *Reward, err error)
+
+	// RewardGrant updates a user's economy, inventory, and/or energy models with the contents of a rolled reward. A grant
+	// whose metadata carries an idempotency key, under EconomyMetadataIdempotencyKey, is applied once per user, and
+	// repeating it returns the first grant's outcome. Currencies are cut down to the max balances of their currencies.
 	RewardGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, metadata map[string]interface{}, ignoreLimits bool) (newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, err error)
 
+	// RewardGrantDryRun validates a reward grant and computes its result, including the currencies and items which would
+	// be cut off by max balances and inventory limits, without writing anything.
+	RewardGrantDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, metadata map[string]interface{}, ignoreLimits bool) (newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, err error)
+
+	// RewardGrantBulk grants many users their rolled rewards, keyed by user ID, writing the wallets and storage of a batch
+	// of users in one call rather than one RewardGrant each. A user whose grant fails doesn't stop the others; their
+	// error is reported in their result.
+	RewardGrantBulk(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, rewards map[string]*Reward, metadata map[string]interface{}, ignoreLimits bool) (results map[string]*EconomyRewardGrantResult, err error)
+
 	// DonationClaim will claim donation rewards for a user and the given donation IDs.
 	DonationClaim(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, donationClaims map[string]*EconomyDonationClaimRequestDetails) (donationsList *EconomyDonationsList, err error)
 
 	// DonationGet will get all donations for the given list of user IDs.
 	DonationGet(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userIDs []string) (donationsList *EconomyDonationsByUserList, err error)
 
-	// DonationGive will contribute to a particular donation for a user ID.
-	DonationGive(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, donationID, fromUserID string) (donation *EconomyDonation, updatedWallet map[string]int64, updatedInventory *Inventory, rewardModifiers []*ActiveRewardModifier, contributorReward *Reward, timestamp int64, err error)
+	// DonationGive contributes one unit from the contributor to the recipient's donation request, charging the
+	// contributor the donation cost. The returned wallet, inventory and modifiers are the contributor's.
+	DonationGive(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, recipientID, donationID, contributorID string) (donation *EconomyDonation, updatedWallet map[string]int64, updatedInventory *Inventory, rewardModifiers []*ActiveRewardModifier, contributorReward *Reward, timestamp int64, err error)
 
 	// DonationRequest will create a donation request for a given donation ID and user ID.
 	DonationRequest(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, donationID string) (donation *EconomyDonation, success bool, err error)
@@ -211,8 +681,27 @@ type EconomySystem interface {
 	// List will get the defined store items and placements within the economy system.
 	List(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (storeItems map[string]*EconomyConfigStoreItem, placements map[string]*EconomyConfigPlacement, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error)
 
-	// Grant will add currencies, and reward modifiers to a user's economy by ID.
-	Grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, modifiers []*RewardModifier, walletMetadata map[string]interface{}) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error)
+	// Grant will add currencies, items, and reward modifiers to a user's economy by ID. Item instances, keyed by item ID,
+	// set the string and numeric properties of the granted items. Negative amounts are applied without an affordability
+	// check of the whole grant; use Debit to take from a user. An idempotency key in the wallet metadata, under
+	// EconomyMetadataIdempotencyKey, makes retries of the grant apply it once.
+	Grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, itemInstances map[string]*RewardInventoryItem, modifiers []*RewardModifier, walletMetadata map[string]interface{}) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error)
+
+	// GrantDryRun validates a Grant without writing anything, and returns the wallet the grant would leave the user with.
+	GrantDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, itemInstances map[string]*RewardInventoryItem, modifiers []*RewardModifier, walletMetadata map[string]interface{}) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error)
+
+	// RewardGrantCapped grants a reward after clamping its currencies to what remains of the user's daily cap for the
+	// given grant source, and returns how much of each currency was cut off. The reward is updated in place, and the
+	// allowed amounts count towards the cap only once the grant is written.
+	RewardGrantCapped(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, source string, reward *Reward, metadata map[string]interface{}) (capped map[string]int64, err error)
+
+	// Debit takes currencies and items from a user. Amounts must be positive, and the whole debit is checked against
+	// the user's wallet and inventory first, so it either takes everything or fails with ErrCurrencyInsufficient or
+	// ErrItemsInsufficient and takes nothing. Systems deducting from a user use it rather than granting negative amounts.
+	Debit(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, walletMetadata map[string]interface{}) (updatedWallet map[string]int64, err error)
+
+	// DebitDryRun validates a Debit without writing anything, and returns the wallet the debit would leave the user with.
+	DebitDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, walletMetadata map[string]interface{}) (updatedWallet map[string]int64, err error)
 
 	// UnmarshalWallet unmarshals and returns the account's wallet as a map[string]int64.
 	UnmarshalWallet(account *api.Account) (wallet map[string]int64, err error)
@@ -220,15 +709,95 @@ type EconomySystem interface {
 	// PurchaseIntent will create a purchase intent for a particular store item for a user ID.
 	PurchaseIntent(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, itemID string, store EconomyStoreType, sku string) (err error)
 
-	// PurchaseItem will validate a purchase and give the user ID the appropriate rewards.
+	// PurchaseIntentCancel will cancel a user's pending purchase intent for a store item.
+	PurchaseIntentCancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, itemID string) (err error)
+
+	// CanAfford checks a cost against the user's wallet, inventory and energies without charging it, and returns what
+	// the user is missing, or nil when they can afford it.
+	CanAfford(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, cost *Cost) (missing *Cost, err error)
+
+	// ListCurrencies returns the display metadata and balance caps of the currencies, keyed by currency ID.
+	ListCurrencies(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (currencies map[string]*EconomyConfigCurrency, err error)
+
+	// PurchaseIntentsCleanup removes expired purchase intents across all users and returns the number removed. Intended to
+	// be called from a scheduled job.
+	PurchaseIntentsCleanup(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (removed int, err error)
+
+	// CompactModifiers removes the ended reward and energy modifiers from every user's active modifiers, and returns the
+	// number of users' modifiers rewritten. Intended to be called from a scheduled job.
+	CompactModifiers(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (compacted int, err error)
+
+	// PurchaseItem will validate a purchase and give the user ID the appropriate rewards. Store items priced only in
+	// virtual currencies are paid for from the user's wallet, without a receipt.
 	PurchaseItem(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, userID, itemID string, store EconomyStoreType, receipt string) (updatedWallet map[string]int64, updatedInventory *Inventory, reward *Reward, isSandboxPurchase bool, err error)
 
+	// TeamPurchaseItem buys a store item priced only in virtual currencies for the user, paid from their team's treasury
+	// within their role's daily spend limits. The reward is granted to the user.
+	TeamPurchaseItem(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID, itemID string) (updatedWallet map[string]int64, updatedInventory *Inventory, reward *Reward, err error)
+
 	// PurchaseRestore will process a restore attempt for the given user, based on a set of restore receipts.
 	PurchaseRestore(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, store EconomyStoreType, receipts []string) (err error)
 
+	// PurchaseTransactionsExport exports one page of purchase transactions across all users recorded within the given
+	// time range, for reconciliation against store payout reports. An end time of 0 leaves the range open.
+	PurchaseTransactionsExport(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, startTimeSec, endTimeSec int64, format string, limit int, cursor string) (export *EconomyPurchaseTransactionsExport, err error)
+
+	// StartModifierJob starts a job which grants or revokes reward and energy modifiers for a list of users or a segment.
+	// The job runs in the background in batches, and its progress is read with GetModifierJob.
+	StartModifierJob(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, request *EconomyModifierJobRequest) (job *EconomyModifierJob, err error)
+
+	// GetModifierJob returns the progress of a modifier job.
+	GetModifierJob(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, jobID string) (job *EconomyModifierJob, err error)
+
+	// SnapshotCreate copies the user's wallet, inventory and modifiers into a new snapshot, deleting the user's oldest
+	// snapshots beyond the configured maximum.
+	SnapshotCreate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, reason string) (snapshot *EconomySnapshot, err error)
+
+	// SnapshotList returns the user's economy snapshots, newest first.
+	SnapshotList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (snapshots *EconomySnapshotList, err error)
+
+	// SnapshotRestore rolls the user's wallet, inventory and modifiers back to a snapshot. The state it replaces is
+	// snapshotted first and returned, so the restore can itself be undone.
+	SnapshotRestore(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, snapshotID string) (backup *EconomySnapshot, err error)
+
+	// EventLogList returns up to limit events of the economy event log with sequence numbers after afterSequence,
+	// oldest first. Events are only logged when EventLog is enabled in the economy config.
+	EventLogList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, afterSequence int64, limit int) (events *EconomyEventLogList, err error)
+
+	// Summary returns the user's balances, active modifiers, pending purchase intents, claimable donations and auctions
+	// and placements in progress.
+	Summary(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (summary *EconomySummary, err error)
+
+	// SubscriptionPurchase validates a subscription receipt with the store, records the user's subscription and grants
+	// its reward for the period the receipt covers, unless that period was already rewarded.
+	SubscriptionPurchase(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, subscriptionID string, store EconomyStoreType, receipt string) (subscription *EconomySubscription, reward *Reward, err error)
+
+	// SubscriptionList refreshes the user's subscriptions from the stores, granting the rewards of any renewals, and
+	// returns them.
+	SubscriptionList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (subscriptions *EconomySubscriptionList, err error)
+
+	// SubscriptionCancel records that the user cancelled the subscription in the store. It stays active until it
+	// expires, and renewals aren't rewarded until the user subscribes again.
+	SubscriptionCancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, subscriptionID string) (subscription *EconomySubscription, err error)
+
+	// SubscriptionsCheck refreshes every user's subscriptions from the stores, granting renewals and ending lapsed
+	// entitlements, and returns the number of subscriptions checked. Intended to be called from a scheduled job.
+	SubscriptionsCheck(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (checked int, err error)
+
+	// PurchaseGrantsReconcile retries granting the rewards of the user's purchases which were validated but not
+	// granted, and returns those granted now and those still pending.
+	PurchaseGrantsReconcile(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (reconcile *EconomyPurchaseGrantsReconcile, err error)
+
+	// PurchaseGrantsRetry retries every user's pending purchase grants and returns the number granted. Intended to be
+	// called from a scheduled job.
+	PurchaseGrantsRetry(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (granted int, err error)
+
 	// PlacementStatus will get the status of a specified placement.
 	PlacementStatus(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, rewardID, placementID string, retryCount int) (resp *EconomyPlacementStatus, err error)
 
+	// ListPlacements returns the status, remaining daily limits and cooldown of every configured placement for a user.
+	ListPlacements(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (placements *EconomyPlacementList, err error)
+
 	// PlacementStart will indicate that a user ID has begun viewing an ad placement.
 	PlacementStart(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, placementID string, metadata map[string]string) (resp *EconomyPlacementStatus, err error)
 
@@ -249,4 +818,12 @@ type EconomySystem interface {
 
 	// SetOnStoreItemReward sets a custom reward function which will run after store item's reward is rolled.
 	SetOnStoreItemReward(fn OnReward[*EconomyConfigStoreItem])
+
+	// SetPurchaseValidator sets the function which validates receipts of a store Nakama can't validate itself, such as
+	// Steam, PlayStation Network or Xbox. Purchases from such a store are rejected until it has a validator.
+	SetPurchaseValidator(store EconomyStoreType, fn EconomyPurchaseValidatorFn)
 }
+
+// EconomyPurchaseValidatorFn validates a store receipt, typically by calling the platform's web API or a webhook, and
+// returns the purchases it proves. An error rejects the purchase.
+type EconomyPurchaseValidatorFn func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, store EconomyStoreType, receipt string) (*api.ValidatePurchaseResponse, error)
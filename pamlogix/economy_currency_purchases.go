@@ -0,0 +1,201 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// TeamPurchaseItem buys a store item priced only in virtual currencies from a team's treasury, on behalf of the user.
+func (e *NakamaEconomySystem) TeamPurchaseItem(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID, itemID string) (updatedWallet map[string]int64, updatedInventory *Inventory, reward *Reward, err error) {
+	if userID == "" {
+		return nil, nil, nil, runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	if teamID == "" {
+		return nil, nil, nil, runtime.NewError("team ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	if itemID == "" {
+		return nil, nil, nil, runtime.NewError("item ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	if e.config == nil || e.config.StoreItems == nil {
+		return nil, nil, nil, runtime.NewError("store items configuration not found", NOT_FOUND_ERROR_CODE) // NOT_FOUND
+	}
+	storeItem, exists := e.config.StoreItems[itemID]
+	if !exists {
+		return nil, nil, nil, runtime.NewError(fmt.Sprintf("store item %s not found", itemID), NOT_FOUND_ERROR_CODE) // NOT_FOUND
+	}
+	if storeItem.Unavailable {
+		return nil, nil, nil, runtime.NewError(fmt.Sprintf("store item %s is unavailable", itemID), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+	if storeItem.Disabled {
+		return nil, nil, nil, runtime.NewError(fmt.Sprintf("store item %s is disabled", itemID), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+	if err := e.checkStoreItemConditions(ctx, logger, nk, userID, itemID, storeItem); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Treasuries only hold currencies, so items sold for real money can't be bought with one
+	if storeItem.Cost == nil || storeItem.Cost.Sku != "" || len(storeItem.Cost.Currencies) == 0 {
+		return nil, nil, nil, runtime.NewError(fmt.Sprintf("store item %s is not priced in currencies", itemID), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+
+	return e.purchaseItemWithCurrencies(ctx, logger, nk, userID, teamID, itemID, storeItem, "", nil)
+}
+
+// purchaseItemWithCurrencies buys a store item priced in virtual currencies: the cost is taken from the user's wallet
+// in a single update, or from the team's treasury when a team is given, and the rolled reward is granted to the user,
+// kept pending and retried if the grant fails since the purchase has been paid for.
+func (e *NakamaEconomySystem) purchaseItemWithCurrencies(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID, itemID string, storeItem *EconomyConfigStoreItem, intentVersion string, purchaseIntent map[string]interface{}) (updatedWallet map[string]int64, updatedInventory *Inventory, reward *Reward, err error) {
+	cost := &Cost{Currencies: make(map[string]int64, len(storeItem.Cost.Currencies))}
+	for currencyID, amount := range storeItem.Cost.Currencies {
+		if amount < 0 {
+			logger.Error("Store item %s has a negative cost in currency %s", itemID, currencyID)
+			return nil, nil, nil, runtime.NewError("store item cost is invalid", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+		}
+		if amount > 0 {
+			cost.Currencies[currencyID] = amount
+		}
+	}
+
+	// Roll the reward before charging, so a reward which fails to roll costs nothing
+	if storeItem.Reward != nil {
+		reward, err = e.RewardRoll(ctx, logger, nk, userID, storeItem.Reward)
+		if err != nil {
+			logger.Error("Failed to roll reward: %v", err)
+			return nil, nil, nil, runtime.NewError("failed to generate reward", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+	}
+
+	transactionID := e.newID()
+	pl, _ := e.pamlogix.(Pamlogix)
+	if teamID == "" {
+		metadata := map[string]interface{}{
+			"source":         "store_purchase",
+			"item_id":        itemID,
+			"transaction_id": transactionID,
+		}
+		if err := chargeCost(ctx, logger, nk, pl, userID, cost, metadata, false); err != nil {
+			return nil, nil, nil, err
+		}
+
+		// Intents are only read for wallet purchases. The intent is consumed at the version it was read at, so a
+		// concurrent purchase with the same intent gets its cost back rather than buying the item twice
+		if purchaseIntent != nil {
+			if err := consumePurchaseIntent(ctx, nk, userID, itemID, intentVersion, purchaseIntent); err != nil {
+				logger.Error("Failed to consume purchase intent of user %s for item %s: %v", userID, itemID, err)
+				metadata["reason"] = "intent_not_consumed"
+				_ = refundCost(ctx, logger, nk, pl, userID, cost, metadata)
+				if errors.Is(err, runtime.ErrStorageRejectedVersion) {
+					return nil, nil, nil, runtime.NewError("purchase intent already consumed", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+				}
+				return nil, nil, nil, runtime.NewError("failed to consume purchase intent", INTERNAL_ERROR_CODE) // INTERNAL
+			}
+		}
+	} else {
+		metadata := map[string]string{
+			"item_id":        itemID,
+			"transaction_id": transactionID,
+		}
+		if err := chargeTeamCost(ctx, logger, nk, pl, userID, teamID, cost, "store_purchase", metadata); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// Record the purchase transaction
+	transaction := map[string]interface{}{
+		"id":             transactionID,
+		"user_id":        userID,
+		"item_id":        itemID,
+		"currencies":     storeItem.Cost.Currencies,
+		"timestamp":      time.Now().Unix(),
+		"transaction_id": transactionID,
+		"intent_exists":  purchaseIntent != nil,
+		"reward":         reward,
+	}
+	if teamID != "" {
+		transaction["team_id"] = teamID
+	}
+	transactionData, _ := json.Marshal(transaction)
+	if _, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      purchaseTransactionsCollection,
+			Key:             transactionID,
+			UserID:          userID,
+			Value:           string(transactionData),
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	}); err != nil {
+		logger.Error("Failed to store purchase transaction: %v", err)
+		// Continue anyway as we still want to grant the rewards
+	}
+
+	// The purchase is logged on its own, as charging the cost logged what was spent
+	purchaseMetadata := map[string]interface{}{
+		"transaction_id": transactionID,
+		"item_id":        itemID,
+	}
+	if teamID != "" {
+		purchaseMetadata["team_id"] = teamID
+	}
+	e.logEvents(ctx, logger, nk, &EconomyEvent{
+		Type:     EconomyEventTypePurchase,
+		UserId:   userID,
+		Metadata: purchaseMetadata,
+	})
+
+	updatedInventory = &Inventory{Items: make(map[string]*InventoryItem)}
+	if reward != nil {
+		newItems, updatedItems, err := e.grantPurchaseReward(ctx, logger, nk, userID, &EconomyPendingGrant{
+			TransactionId: transactionID,
+			ItemId:        itemID,
+			Reward:        reward,
+			CreateTimeSec: time.Now().Unix(),
+		})
+		if err != nil {
+			logger.Error("Failed to grant reward: %v", err)
+			return nil, nil, nil, ErrEconomyPurchaseGrantPending
+		}
+		for id, item := range newItems {
+			updatedInventory.Items[id] = item
+		}
+		for id, item := range updatedItems {
+			updatedInventory.Items[id] = item
+		}
+	}
+
+	updatedWallet, err = userWallet(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to get wallet: %v", err)
+		updatedWallet = make(map[string]int64)
+	}
+	return updatedWallet, updatedInventory, reward, nil
+}
+
+// consumePurchaseIntent marks a purchase intent as consumed by a purchase, if it's still at the version it was read at.
+func consumePurchaseIntent(ctx context.Context, nk runtime.NakamaModule, userID, itemID, intentVersion string, purchaseIntent map[string]interface{}) error {
+	purchaseIntent["is_consumed"] = true
+	purchaseIntent["verified_at"] = time.Now().Unix()
+
+	intentData, err := json.Marshal(purchaseIntent)
+	if err != nil {
+		return err
+	}
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      purchaseIntentsCollection,
+			Key:             fmt.Sprintf("purchase_intent:%s:%s", userID, itemID),
+			UserID:          userID,
+			Value:           string(intentData),
+			Version:         intentVersion,
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ, // Only owner can read
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,   // Only server can write
+		},
+	})
+	return err
+}
@@ -0,0 +1,237 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	economyEventLogStorageCollection = "economy_event_log"
+	// economyEventLogHeadKey holds the sequence of the newest event. Events are written together with the head under
+	// its version, so two requests appending at once can't take the same sequence.
+	economyEventLogHeadKey = "head"
+	// economyEventLogAppendAttempts is how many times an append is tried when other requests keep taking the next
+	// sequence first.
+	economyEventLogAppendAttempts = 5
+	economyEventLogDefaultLimit   = 100
+	economyEventLogMaxLimit       = 100
+)
+
+// maxTransactionMemoLength caps a memo, since it's copied into every event of the transaction.
+const maxTransactionMemoLength = 256
+
+type transactionMemoCtxKey struct{}
+
+// WithTransactionMemo returns a context whose grants, consumes and spends carry the memo into the economy event log,
+// such as the support ticket ID of a manual grant or the name of a campaign, so each change can be traced back to why
+// it was made. Memos longer than 256 characters are cut short.
+func WithTransactionMemo(ctx context.Context, memo string) context.Context {
+	memo = strings.TrimSpace(memo)
+	if runes := []rune(memo); len(runes) > maxTransactionMemoLength {
+		memo = string(runes[:maxTransactionMemoLength])
+	}
+	return context.WithValue(ctx, transactionMemoCtxKey{}, memo)
+}
+
+// TransactionMemo returns the memo the context carries, or "" if it has none.
+func TransactionMemo(ctx context.Context) string {
+	memo, _ := ctx.Value(transactionMemoCtxKey{}).(string)
+	return memo
+}
+
+type economyEventLogHead struct {
+	Sequence int64 `json:"seq"`
+}
+
+// economyEventLogKey zero-pads the sequence so storage lists events in order.
+func economyEventLogKey(sequence int64) string {
+	return fmt.Sprintf("%020d", sequence)
+}
+
+// EventLogList returns up to limit events of the economy event log after the given sequence, oldest first.
+func (e *NakamaEconomySystem) EventLogList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, afterSequence int64, limit int) (*EconomyEventLogList, error) {
+	if afterSequence < 0 || limit < 0 {
+		return nil, ErrBadInput
+	}
+	if limit == 0 {
+		limit = economyEventLogDefaultLimit
+	}
+	limit = min(limit, economyEventLogMaxLimit)
+
+	head, _, err := readEconomyEventLogHead(ctx, nk)
+	if err != nil {
+		logger.Error("Failed to read economy event log head: %v", err)
+		return nil, ErrInternal
+	}
+
+	list := &EconomyEventLogList{
+		Events:         make([]*EconomyEvent, 0),
+		NextSequence:   afterSequence,
+		LatestSequence: head.Sequence,
+	}
+	last := min(afterSequence+int64(limit), head.Sequence)
+	if last <= afterSequence {
+		list.NextSequence = max(afterSequence, head.Sequence)
+		return list, nil
+	}
+
+	reads := make([]*runtime.StorageRead, 0, last-afterSequence)
+	for sequence := afterSequence + 1; sequence <= last; sequence++ {
+		reads = append(reads, &runtime.StorageRead{Collection: economyEventLogStorageCollection, Key: economyEventLogKey(sequence)})
+	}
+	objects, err := nk.StorageRead(ctx, reads)
+	if err != nil {
+		logger.Error("Failed to read economy event log: %v", err)
+		return nil, ErrInternal
+	}
+	events := make(map[string]*api.StorageObject, len(objects))
+	for _, object := range objects {
+		events[object.Key] = object
+	}
+	for _, read := range reads {
+		object, found := events[read.Key]
+		if !found {
+			continue
+		}
+		event := &EconomyEvent{}
+		if err := json.Unmarshal([]byte(object.Value), event); err != nil {
+			logger.Warn("Failed to unmarshal economy event %s: %v", object.Key, err)
+			continue
+		}
+		list.Events = append(list.Events, event)
+	}
+	list.NextSequence = last
+	return list, nil
+}
+
+// logEvents appends the events to the economy event log when it's enabled. Logging never fails the operation which
+// produced the events, so errors are only logged.
+func (e *NakamaEconomySystem) logEvents(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, events ...*EconomyEvent) {
+	if e.config == nil || !e.config.EventLog || len(events) == 0 {
+		return
+	}
+	if memo := TransactionMemo(ctx); memo != "" {
+		for _, event := range events {
+			if event.Memo == "" {
+				event.Memo = memo
+			}
+		}
+	}
+	if err := appendEconomyEvents(ctx, nk, events); err != nil {
+		logger.Error("Failed to append %d events to the economy event log: %v", len(events), err)
+	}
+}
+
+// logEconomyEvents appends the events to the economy event log of the built-in economy system, for systems outside the
+// economy which move currencies and items.
+func logEconomyEvents(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, events ...*EconomyEvent) {
+	if pl == nil {
+		return
+	}
+	if economySystem, ok := pl.GetEconomySystem().(*NakamaEconomySystem); ok {
+		economySystem.logEvents(ctx, logger, nk, events...)
+	}
+}
+
+// appendEconomyEvents gives the events the next sequence numbers and writes them together with the new head.
+func appendEconomyEvents(ctx context.Context, nk runtime.NakamaModule, events []*EconomyEvent) error {
+	createTimeSec := time.Now().Unix()
+	var err error
+	for attempt := 0; attempt < economyEventLogAppendAttempts; attempt++ {
+		var head *economyEventLogHead
+		var version string
+		head, version, err = readEconomyEventLogHead(ctx, nk)
+		if err != nil {
+			return err
+		}
+
+		writes := make([]*runtime.StorageWrite, 0, len(events)+1)
+		for i, event := range events {
+			event.Sequence = head.Sequence + int64(i) + 1
+			if event.CreateTimeSec == 0 {
+				event.CreateTimeSec = createTimeSec
+			}
+			eventData, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			writes = append(writes, &runtime.StorageWrite{
+				Collection:      economyEventLogStorageCollection,
+				Key:             economyEventLogKey(event.Sequence),
+				Value:           string(eventData),
+				Version:         storageLockVersionNone,
+				PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+				PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+			})
+		}
+		headData, err := json.Marshal(&economyEventLogHead{Sequence: head.Sequence + int64(len(events))})
+		if err != nil {
+			return err
+		}
+		writes = append(writes, &runtime.StorageWrite{
+			Collection:      economyEventLogStorageCollection,
+			Key:             economyEventLogHeadKey,
+			Value:           string(headData),
+			Version:         version,
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		})
+
+		// A rejected write means another request appended first, and the head is read again
+		if _, err = nk.StorageWrite(ctx, writes); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// readEconomyEventLogHead returns the head of the event log and its version, or storageLockVersionNone when nothing
+// was logged yet.
+func readEconomyEventLogHead(ctx context.Context, nk runtime.NakamaModule) (*economyEventLogHead, string, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: economyEventLogStorageCollection, Key: economyEventLogHeadKey},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(objects) == 0 {
+		return &economyEventLogHead{}, storageLockVersionNone, nil
+	}
+	head := &economyEventLogHead{}
+	if err := json.Unmarshal([]byte(objects[0].Value), head); err != nil {
+		return nil, "", err
+	}
+	return head, objects[0].Version, nil
+}
+
+// economyEventItems returns the amounts of the items which were granted, leaving out any that weren't.
+func economyEventItems(items, notGranted map[string]int64) map[string]int64 {
+	if len(items) == 0 {
+		return nil
+	}
+	granted := make(map[string]int64, len(items))
+	for itemID, count := range items {
+		if count -= notGranted[itemID]; count > 0 {
+			granted[itemID] = count
+		}
+	}
+	return granted
+}
+
+// economyEventMetadata copies wallet metadata into event metadata, since callers may change their map afterwards.
+func economyEventMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if len(metadata) == 0 {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,252 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventLog_GrantsAndSpends(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economySystem := newBenchPamlogix().GetEconomySystem().(*NakamaEconomySystem)
+	economySystem.config.EventLog = true
+
+	_, _, _, err := economySystem.Grant(ctx, logger, nk, "user1", map[string]int64{benchCurrency: 100}, nil, nil, nil, map[string]interface{}{"source": "quest"})
+	require.NoError(t, err)
+	_, err = economySystem.Debit(ctx, logger, nk, "user1", map[string]int64{benchCurrency: 40}, nil, nil)
+	require.NoError(t, err)
+	// Dry runs and failed spends aren't logged
+	_, err = economySystem.DebitDryRun(ctx, logger, nk, "user1", map[string]int64{benchCurrency: 10}, nil, nil)
+	require.NoError(t, err)
+	_, err = economySystem.Debit(ctx, logger, nk, "user1", map[string]int64{benchCurrency: 1000}, nil, nil)
+	require.ErrorIs(t, err, ErrCurrencyInsufficient)
+
+	list, err := economySystem.EventLogList(ctx, logger, nk, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, list.Events, 2)
+	assert.Equal(t, int64(2), list.LatestSequence)
+	assert.Equal(t, int64(2), list.NextSequence)
+
+	assert.Equal(t, int64(1), list.Events[0].Sequence)
+	assert.Equal(t, EconomyEventTypeGrant, list.Events[0].Type)
+	assert.Equal(t, "user1", list.Events[0].UserId)
+	assert.Equal(t, map[string]int64{benchCurrency: 100}, list.Events[0].Currencies)
+	assert.Equal(t, "quest", list.Events[0].Metadata["source"])
+	assert.Positive(t, list.Events[0].CreateTimeSec)
+
+	assert.Equal(t, int64(2), list.Events[1].Sequence)
+	assert.Equal(t, EconomyEventTypeSpend, list.Events[1].Type)
+	assert.Equal(t, map[string]int64{benchCurrency: 40}, list.Events[1].Currencies)
+}
+
+func TestEventLog_Purchase(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economySystem := newBenchPamlogix().GetEconomySystem().(*NakamaEconomySystem)
+	economySystem.config.EventLog = true
+	economySystem.config.StoreItems = map[string]*EconomyConfigStoreItem{
+		"coin_pack": {
+			Cost: &EconomyConfigStoreItemCost{Sku: "com.example.coinpack"},
+			Reward: &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+				Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 100, Max: 100}}},
+			}},
+		},
+	}
+	nk.MockNakamaModule.On("PurchaseValidateApple", ctx, "user1", "receipt", true, []string(nil)).Return(&api.ValidatePurchaseResponse{
+		ValidatedPurchases: []*api.ValidatedPurchase{{ProductId: "com.example.coinpack", TransactionId: "transaction1", Environment: api.StoreEnvironment_PRODUCTION}},
+	}, nil)
+
+	_, _, _, _, err := economySystem.PurchaseItem(ctx, logger, nil, nk, "user1", "coin_pack", EconomyStoreType_ECONOMY_STORE_TYPE_APPLE_APPSTORE, "receipt")
+	require.NoError(t, err)
+
+	list, err := economySystem.EventLogList(ctx, logger, nk, 0, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, list.Events)
+	assert.Equal(t, EconomyEventTypePurchase, list.Events[0].Type)
+	assert.Equal(t, "coin_pack", list.Events[0].Metadata["item_id"])
+	assert.Equal(t, "ECONOMY_STORE_TYPE_APPLE_APPSTORE", list.Events[0].Metadata["store_type"])
+}
+
+func TestEventLog_TransactionMemo(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	economySystem := p.GetEconomySystem().(*NakamaEconomySystem)
+	economySystem.config.EventLog = true
+	inventorySystem := p.GetInventorySystem()
+
+	supportCtx := WithTransactionMemo(ctx, "  ticket-4521 ")
+	assert.Equal(t, "ticket-4521", TransactionMemo(supportCtx))
+
+	_, _, _, err := economySystem.Grant(supportCtx, logger, nk, "user1", map[string]int64{benchCurrency: 50}, nil, nil, nil, nil)
+	require.NoError(t, err)
+	_, _, _, _, err = inventorySystem.GrantItems(supportCtx, logger, nk, "user1", map[string]int64{"potion": 3}, false)
+	require.NoError(t, err)
+	_, _, _, err = inventorySystem.ConsumeItems(WithTransactionMemo(ctx, "campaign-spring"), logger, nk, "user1", map[string]int64{"potion": 2}, nil, false)
+	require.NoError(t, err)
+	// Changes made without a memo carry none
+	_, _, _, err = economySystem.Grant(ctx, logger, nk, "user1", map[string]int64{benchCurrency: 5}, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	list, err := economySystem.EventLogList(ctx, logger, nk, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, list.Events, 4)
+	assert.Equal(t, "ticket-4521", list.Events[0].Memo)
+
+	assert.Equal(t, EconomyEventTypeGrant, list.Events[1].Type)
+	assert.Equal(t, map[string]int64{"potion": 3}, list.Events[1].Items)
+	assert.Equal(t, "ticket-4521", list.Events[1].Memo)
+
+	assert.Equal(t, EconomyEventTypeSpend, list.Events[2].Type)
+	assert.Equal(t, map[string]int64{"potion": 2}, list.Events[2].Items)
+	assert.Equal(t, "campaign-spring", list.Events[2].Memo)
+
+	assert.Empty(t, list.Events[3].Memo)
+}
+
+func TestEventLog_ListPages(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economySystem := newBenchPamlogix().GetEconomySystem().(*NakamaEconomySystem)
+	economySystem.config.EventLog = true
+
+	for i := 0; i < 5; i++ {
+		_, _, _, err := economySystem.Grant(ctx, logger, nk, "user1", map[string]int64{benchCurrency: int64(i + 1)}, nil, nil, nil, nil)
+		require.NoError(t, err)
+	}
+
+	// A consumer tails the log by reading after the last sequence it saw
+	sequences := make([]int64, 0, 5)
+	after := int64(0)
+	for {
+		list, err := economySystem.EventLogList(ctx, logger, nk, after, 2)
+		require.NoError(t, err)
+		if len(list.Events) == 0 {
+			assert.Equal(t, after, list.NextSequence)
+			break
+		}
+		for _, event := range list.Events {
+			sequences = append(sequences, event.Sequence)
+		}
+		after = list.NextSequence
+	}
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, sequences)
+
+	list, err := economySystem.EventLogList(ctx, logger, nk, 3, 100)
+	require.NoError(t, err)
+	require.Len(t, list.Events, 2)
+	assert.Equal(t, map[string]int64{benchCurrency: 4}, list.Events[0].Currencies)
+
+	_, err = economySystem.EventLogList(ctx, logger, nk, -1, 0)
+	assert.ErrorIs(t, err, ErrBadInput)
+}
+
+func TestEventLog_AppendRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	nk := newBenchNakama()
+
+	require.NoError(t, appendEconomyEvents(ctx, nk, []*EconomyEvent{{Type: EconomyEventTypeGrant, UserId: "user1"}}))
+
+	// Another request appends between this one reading the head and writing, so the first write is rejected
+	conflicting := &conflictingEventLogNakama{benchNakama: nk}
+	require.NoError(t, appendEconomyEvents(ctx, conflicting, []*EconomyEvent{
+		{Type: EconomyEventTypeSpend, UserId: "user2"},
+		{Type: EconomyEventTypeSpend, UserId: "user2"},
+	}))
+
+	list, err := NewNakamaEconomySystem(&EconomyConfig{}).EventLogList(ctx, &mockLogger{}, nk, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, list.Events, 4)
+	for i, event := range list.Events {
+		assert.Equal(t, int64(i+1), event.Sequence)
+	}
+	assert.Equal(t, "user3", list.Events[1].UserId)
+	assert.Equal(t, "user2", list.Events[2].UserId)
+	assert.Equal(t, "user2", list.Events[3].UserId)
+}
+
+func TestEventLog_Disabled(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{})
+
+	_, _, _, err := economySystem.Grant(ctx, logger, nk, "user1", map[string]int64{benchCurrency: 100}, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	list, err := economySystem.EventLogList(ctx, logger, nk, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, list.Events)
+	assert.Zero(t, list.LatestSequence)
+}
+
+// conflictingEventLogNakama appends an event of its own the first time the event log is written to.
+type conflictingEventLogNakama struct {
+	*benchNakama
+	conflicted bool
+}
+
+func (n *conflictingEventLogNakama) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	if !n.conflicted {
+		n.conflicted = true
+		if err := appendEconomyEvents(ctx, n.benchNakama, []*EconomyEvent{{Type: EconomyEventTypeGrant, UserId: "user3"}}); err != nil {
+			return nil, err
+		}
+	}
+	return n.benchNakama.StorageWrite(ctx, writes)
+}
+
+func TestEventLog_AuctionSettlement(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	economySystem := p.GetEconomySystem().(*NakamaEconomySystem)
+	economySystem.config.EventLog = true
+
+	now := time.Now().Unix()
+	auctionData, err := json.Marshal(&Auction{
+		Id:            "auction1",
+		UserId:        "seller",
+		Reward:        &AuctionReward{Items: []*InventoryItem{{Id: "sword", Count: 1}}},
+		Bid:           &AuctionBid{UserId: "buyer", Bid: &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 50}}},
+		StartTimeSec:  now - 100,
+		EndTimeSec:    now - 1,
+		CreateTimeSec: now - 100,
+	})
+	require.NoError(t, err)
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: AuctionCollectionKey, Key: "auction1", Value: string(auctionData)}})
+	require.NoError(t, err)
+
+	_, err = p.GetAuctionsSystem().ClaimBid(ctx, logger, nk, "buyer", "auction1")
+	require.NoError(t, err)
+	_, err = p.GetAuctionsSystem().ClaimCreated(ctx, logger, nk, "seller", "auction1")
+	require.NoError(t, err)
+
+	list, err := economySystem.EventLogList(ctx, logger, nk, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, list.Events, 2)
+
+	assert.Equal(t, EconomyEventTypeSettlement, list.Events[0].Type)
+	assert.Equal(t, "buyer", list.Events[0].UserId)
+	assert.Equal(t, map[string]int64{"sword": 1}, list.Events[0].Items)
+	assert.Equal(t, map[string]int64{benchCurrency: 50}, list.Events[0].Currencies)
+	assert.Equal(t, "winner", list.Events[0].Metadata["role"])
+
+	assert.Equal(t, EconomyEventTypeSettlement, list.Events[1].Type)
+	assert.Equal(t, "seller", list.Events[1].UserId)
+	assert.Equal(t, map[string]int64{benchCurrency: 50}, list.Events[1].Currencies)
+	assert.Equal(t, "auction1", list.Events[1].Metadata["auction_id"])
+	assert.Equal(t, "creator", list.Events[1].Metadata["role"])
+}
@@ -0,0 +1,98 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// EconomyMetadataIdempotencyKey is the metadata key of a grant's idempotency key. A grant carrying a key the user was
+// already granted with is applied once, and repeating it returns the outcome of the first grant.
+const EconomyMetadataIdempotencyKey = "idempotency_key"
+
+const (
+	// grantTransactionKeyPrefix keeps grant records apart from other records in the transactions collection.
+	grantTransactionKeyPrefix = "grant_"
+	// maxIdempotencyKeyLength leaves room for the prefix within Nakama's storage key length.
+	maxIdempotencyKeyLength = 100
+)
+
+// economyGrantTransaction records a grant made with an idempotency key, and its outcome.
+type economyGrantTransaction struct {
+	IdempotencyKey    string                    `json:"idempotency_key"`
+	NewItems          map[string]*InventoryItem `json:"new_items,omitempty"`
+	UpdatedItems      map[string]*InventoryItem `json:"updated_items,omitempty"`
+	NotGrantedItemIDs map[string]int64          `json:"not_granted_item_ids,omitempty"`
+	CreateTimeSec     int64                     `json:"create_time_sec"`
+}
+
+// grantIdempotencyKey returns the idempotency key of the grant's metadata, or "" if it has none.
+func grantIdempotencyKey(metadata map[string]interface{}) (string, error) {
+	value, found := metadata[EconomyMetadataIdempotencyKey]
+	if !found || value == nil {
+		return "", nil
+	}
+	key, ok := value.(string)
+	if !ok || len(key) > maxIdempotencyKeyLength {
+		return "", ErrEconomyBadIdempotencyKey
+	}
+	return key, nil
+}
+
+// idempotentRewardGrant grants the reward unless the user was already granted with the idempotency key, in which case
+// the first grant's outcome is returned. The key is locked meanwhile, so concurrent retries grant once.
+func (e *NakamaEconomySystem) idempotentRewardGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, idempotencyKey string, reward *Reward, metadata map[string]interface{}, ignoreLimits, capBalances bool) (newItems, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, err error) {
+	storageKey := grantTransactionKeyPrefix + idempotencyKey
+	lockName := fmt.Sprintf("%s:%s:%s", transactionsStorageCollection, userID, storageKey)
+	err = withStorageLock(ctx, nk, lockName, func() error {
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+			{
+				Collection: transactionsStorageCollection,
+				Key:        storageKey,
+				UserID:     userID,
+			},
+		})
+		if err != nil {
+			logger.Error("Failed to read grant transaction %s of user %s: %v", idempotencyKey, userID, err)
+			return ErrInternal
+		}
+		if len(objects) > 0 {
+			transaction := &economyGrantTransaction{}
+			if err := json.Unmarshal([]byte(objects[0].Value), transaction); err != nil {
+				logger.Error("Failed to unmarshal grant transaction %s of user %s: %v", idempotencyKey, userID, err)
+				return ErrInternal
+			}
+			logger.Debug("Skipped repeated grant %s to user %s", idempotencyKey, userID)
+			newItems, updatedItems, notGrantedItemIDs = transaction.NewItems, transaction.UpdatedItems, transaction.NotGrantedItemIDs
+			return nil
+		}
+
+		// The grant's record is written with the grant, so a grant that fails part way can be retried with its key
+		newItems, updatedItems, notGrantedItemIDs, err = e.rewardGrant(ctx, logger, nk, userID, reward, metadata, ignoreLimits, capBalances, false, func(newItems, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64) (*runtime.StorageWrite, error) {
+			data, err := json.Marshal(&economyGrantTransaction{
+				IdempotencyKey:    idempotencyKey,
+				NewItems:          newItems,
+				UpdatedItems:      updatedItems,
+				NotGrantedItemIDs: notGrantedItemIDs,
+				CreateTimeSec:     time.Now().Unix(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &runtime.StorageWrite{
+				Collection:      transactionsStorageCollection,
+				Key:             storageKey,
+				UserID:          userID,
+				Value:           string(data),
+				Version:         storageLockVersionNone,
+				PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+				PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+			}, nil
+		})
+		return err
+	})
+	return newItems, updatedItems, notGrantedItemIDs, err
+}
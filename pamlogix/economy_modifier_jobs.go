@@ -0,0 +1,305 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	economyModifierJobsStorageCollection = "economy_modifier_jobs"
+	// modifierJobBatchSize is how many users a modifier job updates before it saves its progress.
+	modifierJobBatchSize = 100
+	// maxModifierJobFailedUserIds caps the failed users kept on a job so it stays a small storage object.
+	maxModifierJobFailedUserIds = 100
+	// systemUserID is the Nakama system user, which owns no economy and is skipped by jobs over all users.
+	systemUserID = "00000000-0000-0000-0000-000000000000"
+)
+
+// StartModifierJob starts a job which grants or revokes reward and energy modifiers for a list of users or a segment.
+func (e *NakamaEconomySystem) StartModifierJob(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, request *EconomyModifierJobRequest) (*EconomyModifierJob, error) {
+	if err := validateModifierJobRequest(request); err != nil {
+		return nil, err
+	}
+	if request.Segment != nil && request.Segment.All && db == nil {
+		return nil, runtime.NewError("modifier jobs for all users need a database", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+
+	now := time.Now().Unix()
+	job := &EconomyModifierJob{
+		Id:            e.newID(),
+		Request:       request,
+		Status:        EconomyModifierJobStatusRunning,
+		CreateTimeSec: now,
+		UpdateTimeSec: now,
+	}
+	if err := writeModifierJob(ctx, nk, job); err != nil {
+		logger.Error("Failed to write modifier job: %v", err)
+		return nil, ErrInternal
+	}
+
+	// The job outlives the RPC which started it, and reports its progress through storage on its own copy
+	started := *job
+	go e.runModifierJob(context.WithoutCancel(ctx), logger, db, nk, job)
+
+	return &started, nil
+}
+
+// GetModifierJob returns the progress of a modifier job.
+func (e *NakamaEconomySystem) GetModifierJob(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, jobID string) (*EconomyModifierJob, error) {
+	if jobID == "" {
+		return nil, ErrBadInput
+	}
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: economyModifierJobsStorageCollection, Key: jobID},
+	})
+	if err != nil {
+		logger.Error("Failed to read modifier job %s: %v", jobID, err)
+		return nil, ErrInternal
+	}
+	if len(objects) == 0 {
+		return nil, ErrEconomyNoModifierJob
+	}
+
+	job := &EconomyModifierJob{}
+	if err := json.Unmarshal([]byte(objects[0].Value), job); err != nil {
+		logger.Error("Failed to unmarshal modifier job %s: %v", jobID, err)
+		return nil, ErrInternal
+	}
+	return job, nil
+}
+
+func validateModifierJobRequest(request *EconomyModifierJobRequest) error {
+	if request == nil {
+		return ErrBadInput
+	}
+	segment := request.Segment
+	hasSegment := segment != nil && (segment.All || segment.GroupId != "")
+	if hasSegment == (len(request.UserIds) > 0) {
+		return runtime.NewError("modifier job needs either user IDs or a segment", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	if segment != nil && segment.All && segment.GroupId != "" {
+		return runtime.NewError("modifier job segment can't be both all users and a group", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	switch request.Action {
+	case EconomyModifierJobActionGrant:
+		if len(request.RewardModifiers) == 0 && len(request.EnergyModifiers) == 0 {
+			return runtime.NewError("modifier grant job has no modifiers", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		for _, modifier := range request.RewardModifiers {
+			if modifier == nil || modifier.Id == "" {
+				return runtime.NewError("modifier grant job has a reward modifier without an ID", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+			}
+		}
+		for _, modifier := range request.EnergyModifiers {
+			if modifier == nil || modifier.Id == "" {
+				return runtime.NewError("modifier grant job has an energy modifier without an ID", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+			}
+		}
+	case EconomyModifierJobActionRevoke:
+		if len(request.ModifierIds) == 0 {
+			return runtime.NewError("modifier revoke job has no modifier IDs", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+	default:
+		return runtime.NewError("unknown modifier job action", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	return nil
+}
+
+// runModifierJob updates the job's users batch by batch, saving the progress after each one.
+func (e *NakamaEconomySystem) runModifierJob(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, job *EconomyModifierJob) {
+	for {
+		userIDs, cursor, err := modifierJobUsers(ctx, db, nk, job.Request, job.Cursor)
+		if err != nil {
+			logger.Error("Modifier job %s failed to list users: %v", job.Id, err)
+			job.Status = EconomyModifierJobStatusFailed
+			job.Error = err.Error()
+		} else {
+			for _, userID := range userIDs {
+				if err := applyModifierJob(ctx, nk, userID, job.Request); err != nil {
+					logger.Warn("Modifier job %s failed for user %s: %v", job.Id, userID, err)
+					job.Failed++
+					if len(job.FailedUserIds) < maxModifierJobFailedUserIds {
+						job.FailedUserIds = append(job.FailedUserIds, userID)
+					}
+				}
+				job.Processed++
+			}
+			job.Cursor = cursor
+			if cursor == "" {
+				job.Status = EconomyModifierJobStatusCompleted
+			}
+		}
+
+		now := time.Now().Unix()
+		job.UpdateTimeSec = now
+		if job.Status != EconomyModifierJobStatusRunning {
+			job.CompleteTimeSec = now
+		}
+		if err := writeModifierJob(ctx, nk, job); err != nil {
+			logger.Error("Failed to write progress of modifier job %s: %v", job.Id, err)
+		}
+		if job.Status != EconomyModifierJobStatusRunning {
+			logger.Info("Modifier job %s %s after %d users, %d failed", job.Id, job.Status, job.Processed, job.Failed)
+			return
+		}
+	}
+}
+
+// modifierJobUsers returns the next batch of the job's users and the cursor to the batch after it, which is empty once
+// all users are listed.
+func modifierJobUsers(ctx context.Context, db *sql.DB, nk runtime.NakamaModule, request *EconomyModifierJobRequest, cursor string) ([]string, string, error) {
+	switch {
+	case len(request.UserIds) > 0:
+		offset := 0
+		if cursor != "" {
+			var err error
+			if offset, err = strconv.Atoi(cursor); err != nil {
+				return nil, "", err
+			}
+		}
+		end := min(offset+modifierJobBatchSize, len(request.UserIds))
+		if end == len(request.UserIds) {
+			return request.UserIds[offset:end], "", nil
+		}
+		return request.UserIds[offset:end], strconv.Itoa(end), nil
+
+	case request.Segment.GroupId != "":
+		groupUsers, nextCursor, err := nk.GroupUsersList(ctx, request.Segment.GroupId, modifierJobBatchSize, nil, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		userIDs := make([]string, 0, len(groupUsers))
+		for _, groupUser := range groupUsers {
+			// Users who only asked to join aren't members yet
+			if groupUser.GetState().GetValue() == int32(api.GroupUserList_GroupUser_JOIN_REQUEST) {
+				continue
+			}
+			userIDs = append(userIDs, groupUser.GetUser().GetId())
+		}
+		return userIDs, nextCursor, nil
+
+	default:
+		after := cursor
+		if after == "" {
+			after = systemUserID
+		}
+		rows, err := db.QueryContext(ctx, "SELECT id FROM users WHERE id > $1 ORDER BY id LIMIT $2", after, modifierJobBatchSize)
+		if err != nil {
+			return nil, "", err
+		}
+		defer rows.Close()
+		userIDs := make([]string, 0, modifierJobBatchSize)
+		for rows.Next() {
+			var userID string
+			if err := rows.Scan(&userID); err != nil {
+				return nil, "", err
+			}
+			userIDs = append(userIDs, userID)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, "", err
+		}
+		if len(userIDs) < modifierJobBatchSize {
+			return userIDs, "", nil
+		}
+		return userIDs, userIDs[len(userIDs)-1], nil
+	}
+}
+
+// applyModifierJob grants or revokes the job's modifiers for one user.
+func applyModifierJob(ctx context.Context, nk runtime.NakamaModule, userID string, request *EconomyModifierJobRequest) error {
+	var writes []*runtime.StorageWrite
+	if request.Action == EconomyModifierJobActionGrant {
+		reward := &Reward{RewardModifiers: request.RewardModifiers, EnergyModifiers: request.EnergyModifiers}
+		var err error
+		if writes, err = rewardModifierWrites(ctx, nk, userID, reward, rewardGrantStorageReads(userID, reward, false)); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if writes, err = revokeModifierWrites(ctx, nk, userID, request.ModifierIds); err != nil {
+			return err
+		}
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	_, err := nk.StorageWrite(ctx, writes)
+	return err
+}
+
+// revokeModifierWrites returns the writes that remove the modifiers from the user's active energy and reward modifiers.
+func revokeModifierWrites(ctx context.Context, nk runtime.NakamaModule, userID string, modifierIDs []string) ([]*runtime.StorageWrite, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: userModifiersStorageCollection, Key: userID + "_energy_modifiers", UserID: userID},
+		{Collection: userModifiersStorageCollection, Key: userID + "_reward_modifiers", UserID: userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revoked := make(map[string]bool, len(modifierIDs))
+	for _, modifierID := range modifierIDs {
+		revoked[modifierID] = true
+	}
+
+	writes := make([]*runtime.StorageWrite, 0, len(objects))
+	for _, object := range objects {
+		if object.UserId != userID {
+			continue
+		}
+		activeModifiers := make([]*ActiveRewardModifier, 0)
+		if err := json.Unmarshal([]byte(object.Value), &activeModifiers); err != nil {
+			return nil, err
+		}
+		kept := make([]*ActiveRewardModifier, 0, len(activeModifiers))
+		for _, modifier := range activeModifiers {
+			if !revoked[modifier.Id] {
+				kept = append(kept, modifier)
+			}
+		}
+		if len(kept) == len(activeModifiers) {
+			continue
+		}
+
+		modifiersData, err := json.Marshal(kept)
+		if err != nil {
+			return nil, err
+		}
+		writes = append(writes, &runtime.StorageWrite{
+			Collection:      userModifiersStorageCollection,
+			Key:             object.Key,
+			UserID:          userID,
+			Value:           string(modifiersData),
+			Version:         object.Version,
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
+		})
+	}
+	return writes, nil
+}
+
+// writeModifierJob stores the job as a system object which clients can't read.
+func writeModifierJob(ctx context.Context, nk runtime.NakamaModule, job *EconomyModifierJob) error {
+	value, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      economyModifierJobsStorageCollection,
+			Key:             job.Id,
+			Value:           string(value),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+	return err
+}
@@ -0,0 +1,93 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitForModifierJob(t *testing.T, economySystem *NakamaEconomySystem, nk runtime.NakamaModule, jobID string) *EconomyModifierJob {
+	var job *EconomyModifierJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = economySystem.GetModifierJob(context.Background(), &mockLogger{}, nk, jobID)
+		require.NoError(t, err)
+		return job.Status != EconomyModifierJobStatusRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func readActiveRewardModifiers(t *testing.T, nk runtime.NakamaModule, userID string) []*ActiveRewardModifier {
+	objects, err := nk.StorageRead(context.Background(), []*runtime.StorageRead{
+		{Collection: userModifiersStorageCollection, Key: userID + "_reward_modifiers", UserID: userID},
+	})
+	require.NoError(t, err)
+	modifiers := make([]*ActiveRewardModifier, 0)
+	if len(objects) > 0 {
+		require.NoError(t, json.Unmarshal([]byte(objects[0].Value), &modifiers))
+	}
+	return modifiers
+}
+
+func TestModifierJob_GrantAndRevokeInBatches(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{})
+
+	userIDs := make([]string, 0, modifierJobBatchSize+50)
+	for i := 0; i < modifierJobBatchSize+50; i++ {
+		userIDs = append(userIDs, fmt.Sprintf("user%d", i))
+	}
+
+	job, err := economySystem.StartModifierJob(ctx, logger, nil, nk, &EconomyModifierJobRequest{
+		Action:          EconomyModifierJobActionGrant,
+		UserIds:         userIDs,
+		RewardModifiers: []*RewardModifier{{Id: "xp", Type: "currency", Operator: "multiplier", Value: 2, DurationSec: 3600}},
+	})
+	require.NoError(t, err)
+	job = waitForModifierJob(t, economySystem, nk, job.Id)
+	assert.Equal(t, EconomyModifierJobStatusCompleted, job.Status)
+	assert.Equal(t, len(userIDs), job.Processed)
+	assert.Zero(t, job.Failed)
+
+	modifiers := readActiveRewardModifiers(t, nk, "user120")
+	require.Len(t, modifiers, 1)
+	assert.Equal(t, "xp", modifiers[0].Id)
+	assert.NotZero(t, modifiers[0].EndTimeSec)
+
+	job, err = economySystem.StartModifierJob(ctx, logger, nil, nk, &EconomyModifierJobRequest{
+		Action:      EconomyModifierJobActionRevoke,
+		UserIds:     userIDs,
+		ModifierIds: []string{"xp"},
+	})
+	require.NoError(t, err)
+	job = waitForModifierJob(t, economySystem, nk, job.Id)
+	assert.Equal(t, EconomyModifierJobStatusCompleted, job.Status)
+	assert.Empty(t, readActiveRewardModifiers(t, nk, "user120"))
+}
+
+func TestModifierJob_ValidatesRequest(t *testing.T) {
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{})
+	nk := newBenchNakama()
+
+	for name, request := range map[string]*EconomyModifierJobRequest{
+		"no targets":        {Action: EconomyModifierJobActionRevoke, ModifierIds: []string{"xp"}},
+		"users and segment": {Action: EconomyModifierJobActionRevoke, ModifierIds: []string{"xp"}, UserIds: []string{"user1"}, Segment: &EconomyModifierJobSegment{GroupId: "team1"}},
+		"no modifiers":      {Action: EconomyModifierJobActionGrant, UserIds: []string{"user1"}},
+		"unknown action":    {Action: "double", UserIds: []string{"user1"}, ModifierIds: []string{"xp"}},
+		"all without db":    {Action: EconomyModifierJobActionRevoke, ModifierIds: []string{"xp"}, Segment: &EconomyModifierJobSegment{All: true}},
+	} {
+		_, err := economySystem.StartModifierJob(context.Background(), &mockLogger{}, nil, nk, request)
+		assert.Error(t, err, name)
+	}
+
+	_, err := economySystem.GetModifierJob(context.Background(), &mockLogger{}, nk, "missing")
+	assert.ErrorIs(t, err, ErrEconomyNoModifierJob)
+}
@@ -1,18 +1,27 @@
 package pamlogix
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"math"
 	"math/rand/v2"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -20,6 +29,25 @@ const (
 	donationsStorageCollection       = "donations"
 	transactionsStorageCollection    = "transactions"
 	placementStatusStorageCollection = "placement_status"
+	purchaseFlagsStorageCollection   = "purchase_flags"
+	purchaseTransactionsCollection   = "purchase_transactions"
+	purchaseIntentsCollection        = "purchase_intents"
+	sandboxStorageCollection         = "economy_sandbox"
+	sandboxBalanceStorageKey         = "balance"
+	currencyGrantCapsCollection      = "currency_grant_caps"
+
+	// currencyGrantCapAttempts is how many times a capped grant is tried when other grants from the same source keep
+	// updating the cap counter first.
+	currencyGrantCapAttempts = 5
+
+	// Purchase intents expire an hour after they are created.
+	purchaseIntentExpirySec = 3600
+	// Placement statuses are deleted a week after they were last updated, unless a cooldown needs them for longer.
+	placementStatusTTLSec = 7 * 24 * 3600
+
+	// donationGivenEvent is the publisher event sent when a user contributes to another user's donation request, with
+	// the units given as its value.
+	donationGivenEvent = "donation_given"
 )
 
 // NakamaEconomySystem implements the EconomySystem interface using Nakama as the backend.
@@ -30,10 +58,30 @@ type NakamaEconomySystem struct {
 	onDonationClaimReward       OnReward[*EconomyConfigDonation]
 	onDonationContributorReward OnReward[*EconomyConfigDonation]
 	pamlogix                    interface{}
+
+	// purchaseValidators validate the receipts of stores Nakama can't validate itself, keyed by store type.
+	purchaseValidatorsMu sync.RWMutex
+	purchaseValidators   map[EconomyStoreType]EconomyPurchaseValidatorFn
+
+	// storeItemIDsBySku is built with the system, so purchases and restores find the store item a store product ID
+	// belongs to without scanning the store.
+	storeItemIDsBySku map[string]string
+
+	// idGenerator makes the IDs of transactions, rewards and item instances, defaulting to random UUIDs.
+	idGenerator IDGeneratorFn
 }
 
 func NewNakamaEconomySystem(config *EconomyConfig) *NakamaEconomySystem {
-	return &NakamaEconomySystem{config: config}
+	// Pre-compute the store item of each SKU for fast lookup
+	storeItemIDsBySku := make(map[string]string)
+	if config != nil {
+		for itemID, item := range config.StoreItems {
+			if item != nil && item.Cost != nil && item.Cost.Sku != "" {
+				storeItemIDsBySku[item.Cost.Sku] = itemID
+			}
+		}
+	}
+	return &NakamaEconomySystem{config: config, storeItemIDsBySku: storeItemIDsBySku}
 }
 
 func (e *NakamaEconomySystem) GetType() SystemType {
@@ -48,6 +96,19 @@ func (e *NakamaEconomySystem) SetPamlogix(p interface{}) {
 	e.pamlogix = p
 }
 
+// SetIDGenerator replaces how the economy makes IDs, so tests can make them deterministic. A nil generator restores
+// random UUIDs.
+func (e *NakamaEconomySystem) SetIDGenerator(fn IDGeneratorFn) {
+	e.idGenerator = fn
+}
+
+func (e *NakamaEconomySystem) newID() string {
+	if e.idGenerator != nil {
+		return e.idGenerator()
+	}
+	return newUUID()
+}
+
 func (e *NakamaEconomySystem) RewardCreate() (rewardConfig *EconomyConfigReward) {
 	// Returns a new, empty reward config for further customization.
 	return &EconomyConfigReward{}
@@ -59,347 +120,349 @@ func (e *NakamaEconomySystem) RewardConvert(contents *AvailableRewards) (rewardC
 	}
 
 	rewardConfig = &EconomyConfigReward{
+		Guaranteed:     rewardContentsFromAvailable(contents.GetGuaranteed()),
 		MaxRolls:       contents.GetMaxRolls(),
 		MaxRepeatRolls: contents.GetMaxRepeatRolls(),
 		TotalWeight:    contents.GetTotalWeight(),
 	}
 
-	// Process guaranteed rewards if present
-	if guaranteed := contents.GetGuaranteed(); guaranteed != nil {
-		rewardConfig.Guaranteed = &EconomyConfigRewardContents{
-			Items:           make(map[string]*EconomyConfigRewardItem),
-			Currencies:      make(map[string]*EconomyConfigRewardCurrency),
-			Energies:        make(map[string]*EconomyConfigRewardEnergy),
-			EnergyModifiers: make([]*EconomyConfigRewardEnergyModifier, 0),
-			RewardModifiers: make([]*EconomyConfigRewardRewardModifier, 0),
-		}
-
-		// Convert currencies
-		for k, v := range guaranteed.GetCurrencies() {
-			if v.GetCount() != nil {
-				rewardConfig.Guaranteed.Currencies[k] = &EconomyConfigRewardCurrency{
-					EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{
-						Min:      v.GetCount().GetMin(),
-						Max:      v.GetCount().GetMax(),
-						Multiple: v.GetCount().GetMultiple(),
-					},
-				}
-			}
+	if weightedRewards := contents.GetWeighted(); len(weightedRewards) > 0 {
+		rewardConfig.Weighted = make([]*EconomyConfigRewardContents, len(weightedRewards))
+		for i, weighted := range weightedRewards {
+			rewardConfig.Weighted[i] = rewardContentsFromAvailable(weighted)
 		}
+	}
 
-		// Convert items
-		for k, v := range guaranteed.GetItems() {
-			if v.GetCount() != nil {
-				item := &EconomyConfigRewardItem{
-					EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{
-						Min:      v.GetCount().GetMin(),
-						Max:      v.GetCount().GetMax(),
-						Multiple: v.GetCount().GetMultiple(),
-					},
-				}
+	return rewardConfig
+}
 
-				// Convert string properties if present
-				if len(v.GetStringProperties()) > 0 {
-					item.StringProperties = make(map[string]*EconomyConfigRewardStringProperty)
-					for propKey, propVal := range v.GetStringProperties() {
-						stringProp := &EconomyConfigRewardStringProperty{
-							TotalWeight: propVal.GetTotalWeight(),
-							Options:     make(map[string]*EconomyConfigRewardStringPropertyOption),
-						}
+func (e *NakamaEconomySystem) RewardConvertReverse(rewardConfig *EconomyConfigReward) (contents *AvailableRewards) {
+	return rewardConfigToAvailable(rewardConfig)
+}
+
+// rewardConfigToAvailable converts a reward configuration into its wire representation. It is the exact inverse of
+// RewardConvert, so the preview sent to clients describes everything RewardRoll can produce from the configuration.
+func rewardConfigToAvailable(rewardConfig *EconomyConfigReward) *AvailableRewards {
+	if rewardConfig == nil {
+		return nil
+	}
+
+	availableRewards := &AvailableRewards{
+		Guaranteed:     rewardContentsToAvailable(rewardConfig.Guaranteed),
+		MaxRolls:       rewardConfig.MaxRolls,
+		MaxRepeatRolls: rewardConfig.MaxRepeatRolls,
+		TotalWeight:    rewardConfig.TotalWeight,
+	}
+
+	if len(rewardConfig.Weighted) > 0 {
+		availableRewards.Weighted = make([]*AvailableRewardsContents, len(rewardConfig.Weighted))
+		for i, weighted := range rewardConfig.Weighted {
+			availableRewards.Weighted[i] = rewardContentsToAvailable(weighted)
+		}
+	}
+
+	return availableRewards
+}
+
+// rewardContentsFromAvailable converts one group of wire reward contents into its configuration representation.
+func rewardContentsFromAvailable(contents *AvailableRewardsContents) *EconomyConfigRewardContents {
+	if contents == nil {
+		return nil
+	}
+
+	configContents := &EconomyConfigRewardContents{
+		Weight: contents.GetWeight(),
+	}
+
+	if len(contents.GetCurrencies()) > 0 {
+		configContents.Currencies = make(map[string]*EconomyConfigRewardCurrency, len(contents.GetCurrencies()))
+		for k, v := range contents.GetCurrencies() {
+			if v.GetCount() == nil {
+				continue
+			}
+			configContents.Currencies[k] = &EconomyConfigRewardCurrency{
+				EconomyConfigRewardRangeInt64: rangeInt64FromAvailable(v.GetCount()),
+			}
+		}
+	}
+
+	if len(contents.GetItems()) > 0 {
+		configContents.Items = make(map[string]*EconomyConfigRewardItem, len(contents.GetItems()))
+		for k, v := range contents.GetItems() {
+			if v.GetCount() == nil {
+				continue
+			}
+			item := &EconomyConfigRewardItem{
+				EconomyConfigRewardRangeInt64: rangeInt64FromAvailable(v.GetCount()),
+			}
 
+			if len(v.GetStringProperties()) > 0 {
+				item.StringProperties = make(map[string]*EconomyConfigRewardStringProperty, len(v.GetStringProperties()))
+				for propKey, propVal := range v.GetStringProperties() {
+					stringProp := &EconomyConfigRewardStringProperty{
+						TotalWeight: propVal.GetTotalWeight(),
+					}
+					if len(propVal.GetOptions()) > 0 {
+						stringProp.Options = make(map[string]*EconomyConfigRewardStringPropertyOption, len(propVal.GetOptions()))
 						for optKey, optVal := range propVal.GetOptions() {
 							stringProp.Options[optKey] = &EconomyConfigRewardStringPropertyOption{
 								Weight: optVal.GetWeight(),
 							}
 						}
-						item.StringProperties[propKey] = stringProp
 					}
+					item.StringProperties[propKey] = stringProp
 				}
+			}
 
-				// Convert numeric properties if present
-				if len(v.GetNumericProperties()) > 0 {
-					item.NumericProperties = make(map[string]*EconomyConfigRewardRangeFloat64)
-					for propKey, propVal := range v.GetNumericProperties() {
-						item.NumericProperties[propKey] = &EconomyConfigRewardRangeFloat64{
-							Min:      propVal.GetMin(),
-							Max:      propVal.GetMax(),
-							Multiple: propVal.GetMultiple(),
-						}
+			if len(v.GetNumericProperties()) > 0 {
+				item.NumericProperties = make(map[string]*EconomyConfigRewardRangeFloat64, len(v.GetNumericProperties()))
+				for propKey, propVal := range v.GetNumericProperties() {
+					item.NumericProperties[propKey] = &EconomyConfigRewardRangeFloat64{
+						Min:      propVal.GetMin(),
+						Max:      propVal.GetMax(),
+						Multiple: propVal.GetMultiple(),
 					}
 				}
-
-				rewardConfig.Guaranteed.Items[k] = item
 			}
+
+			configContents.Items[k] = item
 		}
+	}
 
-		// Convert energies
-		for k, v := range guaranteed.GetEnergies() {
-			if v.GetCount() != nil {
-				rewardConfig.Guaranteed.Energies[k] = &EconomyConfigRewardEnergy{
-					EconomyConfigRewardRangeInt32: EconomyConfigRewardRangeInt32{
-						Min:      v.GetCount().GetMin(),
-						Max:      v.GetCount().GetMax(),
-						Multiple: v.GetCount().GetMultiple(),
-					},
-				}
+	if len(contents.GetEnergies()) > 0 {
+		configContents.Energies = make(map[string]*EconomyConfigRewardEnergy, len(contents.GetEnergies()))
+		for k, v := range contents.GetEnergies() {
+			if v.GetCount() == nil {
+				continue
+			}
+			configContents.Energies[k] = &EconomyConfigRewardEnergy{
+				EconomyConfigRewardRangeInt32: EconomyConfigRewardRangeInt32{
+					Min:      v.GetCount().GetMin(),
+					Max:      v.GetCount().GetMax(),
+					Multiple: v.GetCount().GetMultiple(),
+				},
 			}
 		}
+	}
 
-		// Convert item sets
-		if len(guaranteed.GetItemSets()) > 0 {
-			rewardConfig.Guaranteed.ItemSets = make([]*EconomyConfigRewardItemSet, len(guaranteed.GetItemSets()))
-			for i, itemSet := range guaranteed.GetItemSets() {
-				var min, max, multiple int64
-				if itemSet.GetCount() != nil {
-					min = itemSet.GetCount().GetMin()
-					max = itemSet.GetCount().GetMax()
-					multiple = itemSet.GetCount().GetMultiple()
-				}
-				configItemSet := &EconomyConfigRewardItemSet{
-					EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{
-						Min:      min,
-						Max:      max,
-						Multiple: multiple,
-					},
-					MaxRepeats: itemSet.GetMaxRepeats(),
-					Set:        itemSet.GetSet(),
-				}
-				rewardConfig.Guaranteed.ItemSets[i] = configItemSet
+	if len(contents.GetItemSets()) > 0 {
+		configContents.ItemSets = make([]*EconomyConfigRewardItemSet, len(contents.GetItemSets()))
+		for i, itemSet := range contents.GetItemSets() {
+			configContents.ItemSets[i] = &EconomyConfigRewardItemSet{
+				EconomyConfigRewardRangeInt64: rangeInt64FromAvailable(itemSet.GetCount()),
+				MaxRepeats:                    itemSet.GetMaxRepeats(),
+				Set:                           itemSet.GetSet(),
 			}
 		}
+	}
 
-		// Convert energy modifiers
-		if len(guaranteed.GetEnergyModifiers()) > 0 {
-			rewardConfig.Guaranteed.EnergyModifiers = make([]*EconomyConfigRewardEnergyModifier, len(guaranteed.GetEnergyModifiers()))
-			for i, modifier := range guaranteed.GetEnergyModifiers() {
-				configModifier := &EconomyConfigRewardEnergyModifier{
-					Id:       modifier.GetId(),
-					Operator: modifier.GetOperator(),
-				}
-
-				if modifier.GetValue() != nil {
-					configModifier.Value = &EconomyConfigRewardRangeInt64{
-						Min:      modifier.GetValue().GetMin(),
-						Max:      modifier.GetValue().GetMax(),
-						Multiple: modifier.GetValue().GetMultiple(),
-					}
-				}
-
-				if modifier.GetDurationSec() != nil {
-					configModifier.DurationSec = &EconomyConfigRewardRangeUInt64{
-						Min:      modifier.GetDurationSec().GetMin(),
-						Max:      modifier.GetDurationSec().GetMax(),
-						Multiple: modifier.GetDurationSec().GetMultiple(),
-					}
-				}
+	if len(contents.GetEnergyModifiers()) > 0 {
+		configContents.EnergyModifiers = make([]*EconomyConfigRewardEnergyModifier, len(contents.GetEnergyModifiers()))
+		for i, modifier := range contents.GetEnergyModifiers() {
+			configContents.EnergyModifiers[i] = &EconomyConfigRewardEnergyModifier{
+				Id:          modifier.GetId(),
+				Operator:    modifier.GetOperator(),
+				Value:       rangeInt64PtrFromAvailable(modifier.GetValue()),
+				DurationSec: rangeUInt64PtrFromAvailable(modifier.GetDurationSec()),
+			}
+		}
+	}
 
-				rewardConfig.Guaranteed.EnergyModifiers[i] = configModifier
+	if len(contents.GetRewardModifiers()) > 0 {
+		configContents.RewardModifiers = make([]*EconomyConfigRewardRewardModifier, len(contents.GetRewardModifiers()))
+		for i, modifier := range contents.GetRewardModifiers() {
+			configContents.RewardModifiers[i] = &EconomyConfigRewardRewardModifier{
+				Id:          modifier.GetId(),
+				Type:        modifier.GetType(),
+				Operator:    modifier.GetOperator(),
+				Value:       rangeInt64PtrFromAvailable(modifier.GetValue()),
+				DurationSec: rangeUInt64PtrFromAvailable(modifier.GetDurationSec()),
 			}
 		}
+	}
 
-		// Convert reward modifiers
-		if len(guaranteed.GetRewardModifiers()) > 0 {
-			rewardConfig.Guaranteed.RewardModifiers = make([]*EconomyConfigRewardRewardModifier, len(guaranteed.GetRewardModifiers()))
-			for i, modifier := range guaranteed.GetRewardModifiers() {
-				configModifier := &EconomyConfigRewardRewardModifier{
-					Id:       modifier.GetId(),
-					Type:     modifier.GetType(),
-					Operator: modifier.GetOperator(),
-				}
+	return configContents
+}
 
-				if modifier.GetValue() != nil {
-					configModifier.Value = &EconomyConfigRewardRangeInt64{
-						Min:      modifier.GetValue().GetMin(),
-						Max:      modifier.GetValue().GetMax(),
-						Multiple: modifier.GetValue().GetMultiple(),
-					}
-				}
+// rewardContentsToAvailable converts one group of configured reward contents into its wire representation.
+func rewardContentsToAvailable(contents *EconomyConfigRewardContents) *AvailableRewardsContents {
+	if contents == nil {
+		return nil
+	}
 
-				if modifier.GetDurationSec() != nil {
-					configModifier.DurationSec = &EconomyConfigRewardRangeUInt64{
-						Min:      modifier.GetDurationSec().GetMin(),
-						Max:      modifier.GetDurationSec().GetMax(),
-						Multiple: modifier.GetDurationSec().GetMultiple(),
-					}
-				}
+	availableContents := &AvailableRewardsContents{
+		Weight: contents.Weight,
+	}
 
-				rewardConfig.Guaranteed.RewardModifiers[i] = configModifier
+	if len(contents.Currencies) > 0 {
+		availableContents.Currencies = make(map[string]*AvailableRewardsCurrency, len(contents.Currencies))
+		for k, v := range contents.Currencies {
+			if v == nil {
+				continue
+			}
+			availableContents.Currencies[k] = &AvailableRewardsCurrency{
+				Count: rangeInt64ToAvailable(v.EconomyConfigRewardRangeInt64),
 			}
 		}
 	}
 
-	// Process weighted rewards if present
-	if weightedRewards := contents.GetWeighted(); len(weightedRewards) > 0 {
-		rewardConfig.Weighted = make([]*EconomyConfigRewardContents, len(weightedRewards))
-
-		for i, weighted := range weightedRewards {
-			weightedContent := &EconomyConfigRewardContents{
-				Weight:          weighted.GetWeight(),
-				Items:           make(map[string]*EconomyConfigRewardItem),
-				Currencies:      make(map[string]*EconomyConfigRewardCurrency),
-				Energies:        make(map[string]*EconomyConfigRewardEnergy),
-				EnergyModifiers: make([]*EconomyConfigRewardEnergyModifier, 0),
-				RewardModifiers: make([]*EconomyConfigRewardRewardModifier, 0),
-			}
-
-			// Convert currencies
-			for k, v := range weighted.GetCurrencies() {
-				if v.GetCount() != nil {
-					weightedContent.Currencies[k] = &EconomyConfigRewardCurrency{
-						EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{
-							Min:      v.GetCount().GetMin(),
-							Max:      v.GetCount().GetMax(),
-							Multiple: v.GetCount().GetMultiple(),
-						},
-					}
-				}
+	if len(contents.Items) > 0 {
+		availableContents.Items = make(map[string]*AvailableRewardsItem, len(contents.Items))
+		for k, v := range contents.Items {
+			if v == nil {
+				continue
+			}
+			item := &AvailableRewardsItem{
+				Count: rangeInt64ToAvailable(v.EconomyConfigRewardRangeInt64),
 			}
 
-			// Convert items
-			for k, v := range weighted.GetItems() {
-				if v.GetCount() != nil {
-					item := &EconomyConfigRewardItem{
-						EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{
-							Min:      v.GetCount().GetMin(),
-							Max:      v.GetCount().GetMax(),
-							Multiple: v.GetCount().GetMultiple(),
-						},
+			if len(v.StringProperties) > 0 {
+				item.StringProperties = make(map[string]*AvailableRewardsStringProperty, len(v.StringProperties))
+				for propKey, propVal := range v.StringProperties {
+					if propVal == nil {
+						continue
 					}
-
-					// Convert string properties if present
-					if len(v.GetStringProperties()) > 0 {
-						item.StringProperties = make(map[string]*EconomyConfigRewardStringProperty)
-						for propKey, propVal := range v.GetStringProperties() {
-							stringProp := &EconomyConfigRewardStringProperty{
-								TotalWeight: propVal.GetTotalWeight(),
-								Options:     make(map[string]*EconomyConfigRewardStringPropertyOption),
-							}
-
-							for optKey, optVal := range propVal.GetOptions() {
-								stringProp.Options[optKey] = &EconomyConfigRewardStringPropertyOption{
-									Weight: optVal.GetWeight(),
-								}
-							}
-							item.StringProperties[propKey] = stringProp
-						}
+					stringProp := &AvailableRewardsStringProperty{
+						TotalWeight: propVal.TotalWeight,
 					}
-
-					// Convert numeric properties if present
-					if len(v.GetNumericProperties()) > 0 {
-						item.NumericProperties = make(map[string]*EconomyConfigRewardRangeFloat64)
-						for propKey, propVal := range v.GetNumericProperties() {
-							item.NumericProperties[propKey] = &EconomyConfigRewardRangeFloat64{
-								Min:      propVal.GetMin(),
-								Max:      propVal.GetMax(),
-								Multiple: propVal.GetMultiple(),
+					if len(propVal.Options) > 0 {
+						stringProp.Options = make(map[string]*AvailableRewardsStringPropertyOption, len(propVal.Options))
+						for optKey, optVal := range propVal.Options {
+							if optVal == nil {
+								continue
+							}
+							stringProp.Options[optKey] = &AvailableRewardsStringPropertyOption{
+								Weight: optVal.Weight,
 							}
 						}
 					}
-
-					weightedContent.Items[k] = item
-				}
-			}
-
-			// Convert energies
-			for k, v := range weighted.GetEnergies() {
-				if v.GetCount() != nil {
-					weightedContent.Energies[k] = &EconomyConfigRewardEnergy{
-						EconomyConfigRewardRangeInt32: EconomyConfigRewardRangeInt32{
-							Min:      v.GetCount().GetMin(),
-							Max:      v.GetCount().GetMax(),
-							Multiple: v.GetCount().GetMultiple(),
-						},
-					}
+					item.StringProperties[propKey] = stringProp
 				}
 			}
 
-			// Convert item sets
-			if len(weighted.GetItemSets()) > 0 {
-				weightedContent.ItemSets = make([]*EconomyConfigRewardItemSet, len(weighted.GetItemSets()))
-				for j, itemSet := range weighted.GetItemSets() {
-					var min, max, multiple int64
-					if itemSet.GetCount() != nil {
-						min = itemSet.GetCount().GetMin()
-						max = itemSet.GetCount().GetMax()
-						multiple = itemSet.GetCount().GetMultiple()
+			if len(v.NumericProperties) > 0 {
+				item.NumericProperties = make(map[string]*RewardRangeDouble, len(v.NumericProperties))
+				for propKey, propVal := range v.NumericProperties {
+					if propVal == nil {
+						continue
 					}
-					configItemSet := &EconomyConfigRewardItemSet{
-						EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{
-							Min:      min,
-							Max:      max,
-							Multiple: multiple,
-						},
-						MaxRepeats: itemSet.GetMaxRepeats(),
-						Set:        itemSet.GetSet(),
+					item.NumericProperties[propKey] = &RewardRangeDouble{
+						Min:      propVal.Min,
+						Max:      propVal.Max,
+						Multiple: propVal.Multiple,
 					}
-					weightedContent.ItemSets[j] = configItemSet
 				}
 			}
 
-			// Convert energy modifiers
-			if len(weighted.GetEnergyModifiers()) > 0 {
-				weightedContent.EnergyModifiers = make([]*EconomyConfigRewardEnergyModifier, len(weighted.GetEnergyModifiers()))
-				for j, modifier := range weighted.GetEnergyModifiers() {
-					configModifier := &EconomyConfigRewardEnergyModifier{
-						Id:       modifier.GetId(),
-						Operator: modifier.GetOperator(),
-					}
+			availableContents.Items[k] = item
+		}
+	}
 
-					if modifier.GetValue() != nil {
-						configModifier.Value = &EconomyConfigRewardRangeInt64{
-							Min:      modifier.GetValue().GetMin(),
-							Max:      modifier.GetValue().GetMax(),
-							Multiple: modifier.GetValue().GetMultiple(),
-						}
-					}
+	if len(contents.Energies) > 0 {
+		availableContents.Energies = make(map[string]*AvailableRewardsEnergy, len(contents.Energies))
+		for k, v := range contents.Energies {
+			if v == nil {
+				continue
+			}
+			availableContents.Energies[k] = &AvailableRewardsEnergy{
+				Count: &RewardRangeInt32{
+					Min:      v.Min,
+					Max:      v.Max,
+					Multiple: v.Multiple,
+				},
+			}
+		}
+	}
 
-					if modifier.GetDurationSec() != nil {
-						configModifier.DurationSec = &EconomyConfigRewardRangeUInt64{
-							Min:      modifier.GetDurationSec().GetMin(),
-							Max:      modifier.GetDurationSec().GetMax(),
-							Multiple: modifier.GetDurationSec().GetMultiple(),
-						}
-					}
+	if len(contents.ItemSets) > 0 {
+		availableContents.ItemSets = make([]*AvailableRewardsItemSet, len(contents.ItemSets))
+		for i, itemSet := range contents.ItemSets {
+			availableContents.ItemSets[i] = &AvailableRewardsItemSet{
+				Count:      rangeInt64ToAvailable(itemSet.EconomyConfigRewardRangeInt64),
+				MaxRepeats: itemSet.MaxRepeats,
+				Set:        itemSet.Set,
+			}
+		}
+	}
 
-					weightedContent.EnergyModifiers[j] = configModifier
-				}
+	if len(contents.EnergyModifiers) > 0 {
+		availableContents.EnergyModifiers = make([]*AvailableRewardsEnergyModifier, len(contents.EnergyModifiers))
+		for i, modifier := range contents.EnergyModifiers {
+			availableModifier := &AvailableRewardsEnergyModifier{
+				Id:          modifier.Id,
+				Operator:    modifier.Operator,
+				DurationSec: rangeUInt64ToAvailable(modifier.DurationSec),
+			}
+			if modifier.Value != nil {
+				availableModifier.Value = rangeInt64ToAvailable(*modifier.Value)
+			}
+			availableContents.EnergyModifiers[i] = availableModifier
+		}
+	}
+
+	if len(contents.RewardModifiers) > 0 {
+		availableContents.RewardModifiers = make([]*AvailableRewardsRewardModifier, len(contents.RewardModifiers))
+		for i, modifier := range contents.RewardModifiers {
+			availableModifier := &AvailableRewardsRewardModifier{
+				Id:          modifier.Id,
+				Type:        modifier.Type,
+				Operator:    modifier.Operator,
+				DurationSec: rangeUInt64ToAvailable(modifier.DurationSec),
 			}
+			if modifier.Value != nil {
+				availableModifier.Value = rangeInt64ToAvailable(*modifier.Value)
+			}
+			availableContents.RewardModifiers[i] = availableModifier
+		}
+	}
 
-			// Convert reward modifiers
-			if len(weighted.GetRewardModifiers()) > 0 {
-				weightedContent.RewardModifiers = make([]*EconomyConfigRewardRewardModifier, len(weighted.GetRewardModifiers()))
-				for j, modifier := range weighted.GetRewardModifiers() {
-					configModifier := &EconomyConfigRewardRewardModifier{
-						Id:       modifier.GetId(),
-						Type:     modifier.GetType(),
-						Operator: modifier.GetOperator(),
-					}
+	return availableContents
+}
 
-					if modifier.GetValue() != nil {
-						configModifier.Value = &EconomyConfigRewardRangeInt64{
-							Min:      modifier.GetValue().GetMin(),
-							Max:      modifier.GetValue().GetMax(),
-							Multiple: modifier.GetValue().GetMultiple(),
-						}
-					}
+func rangeInt64FromAvailable(r *RewardRangeInt64) EconomyConfigRewardRangeInt64 {
+	return EconomyConfigRewardRangeInt64{
+		Min:      r.GetMin(),
+		Max:      r.GetMax(),
+		Multiple: r.GetMultiple(),
+	}
+}
 
-					if modifier.GetDurationSec() != nil {
-						configModifier.DurationSec = &EconomyConfigRewardRangeUInt64{
-							Min:      modifier.GetDurationSec().GetMin(),
-							Max:      modifier.GetDurationSec().GetMax(),
-							Multiple: modifier.GetDurationSec().GetMultiple(),
-						}
-					}
+func rangeInt64PtrFromAvailable(r *RewardRangeInt64) *EconomyConfigRewardRangeInt64 {
+	if r == nil {
+		return nil
+	}
+	configRange := rangeInt64FromAvailable(r)
+	return &configRange
+}
 
-					weightedContent.RewardModifiers[j] = configModifier
-				}
-			}
+func rangeUInt64PtrFromAvailable(r *RewardRangeUInt64) *EconomyConfigRewardRangeUInt64 {
+	if r == nil {
+		return nil
+	}
+	return &EconomyConfigRewardRangeUInt64{
+		Min:      r.GetMin(),
+		Max:      r.GetMax(),
+		Multiple: r.GetMultiple(),
+	}
+}
 
-			rewardConfig.Weighted[i] = weightedContent
-		}
+func rangeInt64ToAvailable(r EconomyConfigRewardRangeInt64) *RewardRangeInt64 {
+	return &RewardRangeInt64{
+		Min:      r.Min,
+		Max:      r.Max,
+		Multiple: r.Multiple,
 	}
+}
 
-	return rewardConfig
+func rangeUInt64ToAvailable(r *EconomyConfigRewardRangeUInt64) *RewardRangeUInt64 {
+	if r == nil {
+		return nil
+	}
+	return &RewardRangeUInt64{
+		Min:      r.Min,
+		Max:      r.Max,
+		Multiple: r.Multiple,
+	}
 }
 
 func (e *NakamaEconomySystem) RewardRoll(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, rewardConfig *EconomyConfigReward) (reward *Reward, err error) {
@@ -424,79 +487,165 @@ func (e *NakamaEconomySystem) RewardRoll(ctx context.Context, logger runtime.Log
 
 	// Process weighted rewards if available
 	if len(rewardConfig.Weighted) > 0 && rewardConfig.MaxRolls > 0 {
-		// Calculate total weight if not provided
-		totalWeight := rewardConfig.TotalWeight
-		if totalWeight == 0 {
-			for _, contentGroup := range rewardConfig.Weighted {
-				totalWeight += contentGroup.Weight
-			}
+		weights := make([]int64, len(rewardConfig.Weighted))
+		for i, contentGroup := range rewardConfig.Weighted {
+			weights[i] = contentGroup.Weight
 		}
 
-		if totalWeight <= 0 {
+		selected := rollWeightedIndices(weights, rewardConfig.TotalWeight, rewardConfig.MaxRolls, rewardConfig.MaxRepeatRolls, func(n int64) int64 {
+			return e.randomInt64(0, n-1)
+		})
+		if selected == nil {
 			logger.Warn("Total weight for weighted rewards is zero or negative, skipping weighted rolls")
-		} else {
-			// Track which indices have been rolled to avoid repeats if configured
-			rolledIndices := make(map[int]bool)
+		}
 
-			// Perform rolls
-			for roll := int64(0); roll < rewardConfig.MaxRolls; roll++ {
-				// If we've already used all available options with repeat protection
-				if len(rolledIndices) >= len(rewardConfig.Weighted) && rewardConfig.MaxRepeatRolls == 0 {
-					break
-				}
+		for _, selectedIndex := range selected {
+			if err = e.processRewardContents(reward, rewardConfig.Weighted[selectedIndex]); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-				// Choose a random reward group
-				randVal := e.randomInt64(0, totalWeight)
-				var cumulativeWeight int64 = 0
-				var selectedIndex int = -1
+	return reward, nil
+}
 
-				for i, contentGroup := range rewardConfig.Weighted {
-					cumulativeWeight += contentGroup.Weight
-					if randVal < cumulativeWeight {
-						selectedIndex = i
-						break
-					}
-				}
+func (e *NakamaEconomySystem) RewardRollN(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, rewardConfig *EconomyConfigReward, count int64) (reward *Reward, err error) {
+	if count <= 0 {
+		return nil, runtime.NewError("reward roll count must be positive", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
 
-				if selectedIndex == -1 {
-					logger.Warn("Failed to select a weighted reward, possibly due to weight calculation error")
-					continue
-				}
+	for i := int64(0); i < count; i++ {
+		roll, err := e.RewardRoll(ctx, logger, nk, userID, rewardConfig)
+		if err != nil {
+			return nil, err
+		}
+		if reward == nil {
+			reward = roll
+			continue
+		}
+		mergeRewards(reward, roll)
+	}
+	return reward, nil
+}
 
-				// Handle repeat protection
-				if rolledIndices[selectedIndex] {
-					// Skip if we've already hit our repeat limit
-					if rewardConfig.MaxRepeatRolls > 0 {
-						repeatCount := e.getRepeatCount(rolledIndices, selectedIndex)
-						if repeatCount >= rewardConfig.MaxRepeatRolls {
-							// Try again with this roll
-							roll--
-							continue
-						}
-					}
-				}
+// mergeRewards adds the contents of the source reward to the target.
+func mergeRewards(target, source *Reward) {
+	if source == nil {
+		return
+	}
+	target.Items = mergeRewardAmounts(target.Items, source.Items)
+	target.Currencies = mergeRewardAmounts(target.Currencies, source.Currencies)
+	if len(source.Energies) > 0 && target.Energies == nil {
+		target.Energies = make(map[string]int32, len(source.Energies))
+	}
+	for id, count := range source.Energies {
+		target.Energies[id] += count
+	}
+	target.EnergyModifiers = append(target.EnergyModifiers, source.EnergyModifiers...)
+	target.RewardModifiers = append(target.RewardModifiers, source.RewardModifiers...)
+	if len(source.ItemInstances) > 0 && target.ItemInstances == nil {
+		target.ItemInstances = make(map[string]*RewardInventoryItem, len(source.ItemInstances))
+	}
+	for id, item := range source.ItemInstances {
+		target.ItemInstances[id] = item
+	}
+}
+
+func mergeRewardAmounts(target, source map[string]int64) map[string]int64 {
+	if len(source) > 0 && target == nil {
+		target = make(map[string]int64, len(source))
+	}
+	for id, count := range source {
+		target[id] += count
+	}
+	return target
+}
 
-				// Mark this index as rolled
-				rolledIndices[selectedIndex] = true
+// selectWeightedStringOption picks one of the string property options in proportion to its weight. Options are
+// walked in sorted order so the selection doesn't depend on map iteration order, and the weights themselves are used
+// as the total so a valid configuration always selects a value. randN must return a value in [0, n).
+func selectWeightedStringOption(options map[string]*EconomyConfigRewardStringPropertyOption, randN func(n int64) int64) string {
+	values := make([]string, 0, len(options))
+	var totalWeight int64
+	for value, option := range options {
+		if option == nil || option.Weight <= 0 {
+			continue
+		}
+		values = append(values, value)
+		totalWeight += option.Weight
+	}
+	if totalWeight <= 0 {
+		return ""
+	}
+	sort.Strings(values)
 
-				// Process the selected reward group
-				if err = e.processRewardContents(reward, rewardConfig.Weighted[selectedIndex]); err != nil {
-					return nil, err
-				}
-			}
+	randVal := randN(totalWeight)
+	for _, value := range values {
+		weight := options[value].Weight
+		if randVal < weight {
+			return value
 		}
+		randVal -= weight
 	}
 
-	return reward, nil
+	return ""
 }
 
-// Helper function to count repeats of a specific index
-func (e *NakamaEconomySystem) getRepeatCount(rolledIndices map[int]bool, index int) int64 {
-	count := int64(0)
-	if rolledIndices[index] {
-		count = 1
+// rollWeightedIndices performs up to maxRolls weighted selections over the given weights and returns the selected
+// indices in roll order. Each index can be selected at most maxRepeatRolls times, or once if maxRepeatRolls is 0, and
+// indices which have reached the limit are removed from the pool rather than rerolled. A totalWeight larger than the
+// sum of the weights leaves a chance for a roll to select nothing. randN must return a value in [0, n).
+func rollWeightedIndices(weights []int64, totalWeight, maxRolls, maxRepeatRolls int64, randN func(n int64) int64) []int {
+	var sumWeight int64
+	for _, weight := range weights {
+		if weight > 0 {
+			sumWeight += weight
+		}
+	}
+	if sumWeight <= 0 {
+		return nil
+	}
+
+	// Weight which selects nothing, if the configured total weight exceeds the weights of the groups
+	var emptyWeight int64
+	if totalWeight > sumWeight {
+		emptyWeight = totalWeight - sumWeight
+	}
+
+	maxRepeats := maxRepeatRolls
+	if maxRepeats <= 0 {
+		maxRepeats = 1
+	}
+
+	counts := make([]int64, len(weights))
+	selected := make([]int, 0, maxRolls)
+	for roll := int64(0); roll < maxRolls; roll++ {
+		// Only groups which haven't reached their repeat limit remain in the pool
+		var poolWeight int64
+		for i, weight := range weights {
+			if weight > 0 && counts[i] < maxRepeats {
+				poolWeight += weight
+			}
+		}
+		if poolWeight <= 0 {
+			break
+		}
+
+		randVal := randN(poolWeight + emptyWeight)
+		for i, weight := range weights {
+			if weight <= 0 || counts[i] >= maxRepeats {
+				continue
+			}
+			if randVal < weight {
+				counts[i]++
+				selected = append(selected, i)
+				break
+			}
+			randVal -= weight
+		}
 	}
-	return count
+
+	return selected
 }
 
 // Helper function to process a single reward contents group
@@ -530,23 +679,14 @@ func (e *NakamaEconomySystem) processRewardContents(reward *Reward, contents *Ec
 
 			// Roll string properties
 			for propKey, propConfig := range itemReward.StringProperties {
-				if propConfig.TotalWeight > 0 && len(propConfig.Options) > 0 {
-					// Select a random property value based on weights
-					randValue := e.randomInt64(0, propConfig.TotalWeight)
-					var cumulativeWeight int64 = 0
-					var selectedValue string
-
-					for value, option := range propConfig.Options {
-						cumulativeWeight += option.Weight
-						if randValue < cumulativeWeight {
-							selectedValue = value
-							break
-						}
-					}
-
-					if selectedValue != "" {
-						reward.ItemInstances[itemID].StringProperties[propKey] = selectedValue
-					}
+				if propConfig == nil {
+					continue
+				}
+				selectedValue := selectWeightedStringOption(propConfig.Options, func(n int64) int64 {
+					return e.randomInt64(0, n-1)
+				})
+				if selectedValue != "" {
+					reward.ItemInstances[itemID].StringProperties[propKey] = selectedValue
 				}
 			}
 
@@ -714,6 +854,31 @@ func (e *NakamaEconomySystem) rollRangeFloat64(min, max, multiple float64) float
 }
 
 func (e *NakamaEconomySystem) RewardGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, metadata map[string]interface{}, ignoreLimits bool) (newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, err error) {
+	idempotencyKey, err := grantIdempotencyKey(metadata)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if idempotencyKey != "" && reward != nil && userID != "" {
+		return e.idempotentRewardGrant(ctx, logger, nk, userID, idempotencyKey, reward, metadata, ignoreLimits, true)
+	}
+	return e.rewardGrant(ctx, logger, nk, userID, reward, metadata, ignoreLimits, true, false, nil)
+}
+
+func (e *NakamaEconomySystem) RewardGrantDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, metadata map[string]interface{}, ignoreLimits bool) (newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, err error) {
+	if _, err := grantIdempotencyKey(metadata); err != nil {
+		return nil, nil, nil, err
+	}
+	return e.rewardGrant(ctx, logger, nk, userID, reward, metadata, ignoreLimits, true, true, nil)
+}
+
+// rewardGrantRecord returns a storage write recording the outcome of a grant, which is written together with the grant.
+type rewardGrantRecord func(newItems, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64) (*runtime.StorageWrite, error)
+
+// rewardGrant applies the reward grant, without checking its idempotency key. With capBalances its currencies are cut
+// down to their max balances, which only applies to rewards and not to currencies the user gets back. If record is
+// given, the wallet update, the storage writes and the record are written in one atomic call, so either all of them
+// are applied or none are.
+func (e *NakamaEconomySystem) rewardGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, metadata map[string]interface{}, ignoreLimits, capBalances, dryRun bool, record rewardGrantRecord) (newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, err error) {
 	if reward == nil {
 		return nil, nil, nil, runtime.NewError("reward is nil", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
@@ -727,121 +892,149 @@ func (e *NakamaEconomySystem) RewardGrant(ctx context.Context, logger runtime.Lo
 	updatedItems = make(map[string]*InventoryItem)
 	notGrantedItemIDs = make(map[string]int64)
 
-	// Transaction to ensure atomicity
-	//err = nk.WalletUpdate(ctx, userID, reward.Currencies, metadata, false)
-	_, _, err = nk.WalletUpdate(ctx, userID, reward.Currencies, metadata, false)
+	// Energies go through the energy system when it is available, so energy maximums, overfill and refill timers are
+	// respected, and otherwise the stored energies are updated directly
+	var energySystem EnergySystem
+	if pamlogixInst, ok := e.pamlogix.(interface{ GetEnergySystem() EnergySystem }); ok {
+		energySystem = pamlogixInst.GetEnergySystem()
+	}
+	energyWrite, grantEnergies := e.rewardEnergyWrite(ctx, logger, nk, userID, reward, energySystem)
 
-	if err != nil {
-		logger.Error("Failed to update wallet: %v", err)
-		return nil, nil, nil, runtime.NewError("Failed to update wallet", INTERNAL_ERROR_CODE) // INTERNAL
+	// Duplicates are converted first so the currencies they're converted into count towards max balances
+	if err := e.applyDuplicateConversions(ctx, logger, nk, userID, reward); err != nil {
+		return nil, nil, nil, err
+	}
+	if capBalances {
+		if err := e.applyCurrencyMaxBalances(ctx, logger, nk, userID, reward); err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
-	// Process inventory items
-	if len(reward.Items) > 0 {
-		// Use the inventory system to grant items with proper limit checking
-		if e.pamlogix != nil {
-			inventorySystem := e.pamlogix.(interface{ GetInventorySystem() InventorySystem }).GetInventorySystem()
-			if inventorySystem != nil {
-				// Grant items through the inventory system which handles limits properly
-				_, grantedNewItems, grantedUpdatedItems, notGrantedItems, err := inventorySystem.GrantItems(ctx, logger, nk, userID, reward.Items, ignoreLimits)
-				if err != nil {
-					logger.Error("Failed to grant items through inventory system: %v", err)
-					return nil, nil, nil, runtime.NewError("Failed to grant items", INTERNAL_ERROR_CODE) // INTERNAL
-				}
+	if dryRun {
+		// Deductions in a reward must not take a balance below zero, which the wallet update would reject
+		if _, err := rewardGrantWallet(ctx, nk, userID, reward.Currencies); err != nil {
+			logger.Debug("Dry run of reward grant for user %s failed: %v", userID, err)
+			return nil, nil, nil, err
+		}
+	} else if record == nil {
+		// Transaction to ensure atomicity
+		err = retryNakama(ctx, nonIdempotentCall, func() error {
+			_, _, err := nk.WalletUpdate(ctx, userID, reward.Currencies, metadata, false)
+			return err
+		})
 
-				// Merge results
-				for itemID, item := range grantedNewItems {
-					newItems[itemID] = item
-				}
-				for itemID, item := range grantedUpdatedItems {
-					updatedItems[itemID] = item
-				}
-				for itemID, count := range notGrantedItems {
-					notGrantedItemIDs[itemID] = count
-				}
+		if err != nil {
+			logger.Error("Failed to update wallet: %v", err)
+			return nil, nil, nil, runtime.NewError("Failed to update wallet", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+	}
 
-				// Handle item instances with properties if they exist
-				if reward.ItemInstances != nil {
-					// For items that were granted, we need to update their properties if specified
-					instanceUpdates := make(map[string]*InventoryUpdateItemProperties)
-
-					for itemID, itemInstance := range reward.ItemInstances {
-						// Only update if the item was actually granted
-						var grantedItem *InventoryItem
-						if newItem, exists := grantedNewItems[itemID]; exists {
-							grantedItem = newItem
-						} else if updatedItem, exists := grantedUpdatedItems[itemID]; exists {
-							grantedItem = updatedItem
-						}
+	// Items, energies and modifiers stored by the economy are read with one call and written with one call
+	var writes []*runtime.StorageWrite
 
-						if grantedItem != nil && grantedItem.InstanceId != "" {
-							// Prepare property updates
-							if len(itemInstance.StringProperties) > 0 || len(itemInstance.NumericProperties) > 0 {
-								instanceUpdates[grantedItem.InstanceId] = &InventoryUpdateItemProperties{
-									StringProperties:  itemInstance.StringProperties,
-									NumericProperties: itemInstance.NumericProperties,
-								}
-							}
-						}
-					}
-
-					// Apply property updates if any
-					if len(instanceUpdates) > 0 {
-						_, err = inventorySystem.UpdateItems(ctx, logger, nk, userID, instanceUpdates)
-						if err != nil {
-							logger.Error("Failed to update item properties: %v", err)
-							// Continue execution, don't fail the entire operation
-						}
-					}
-				}
-			} else {
-				logger.Warn("Inventory system not available, falling back to direct storage")
-				// Fallback to the original direct storage method
-				err = e.grantItemsDirectly(ctx, logger, nk, userID, reward, newItems, updatedItems, ignoreLimits)
-				if err != nil {
-					return nil, nil, nil, err
-				}
-			}
-		} else {
-			logger.Warn("Pamlogix instance not available, falling back to direct storage")
-			// Fallback to the original direct storage method
-			err = e.grantItemsDirectly(ctx, logger, nk, userID, reward, newItems, updatedItems, ignoreLimits)
-			if err != nil {
-				return nil, nil, nil, err
-			}
+	if len(reward.Items) > 0 {
+		itemWrites, err := e.rewardItemWrites(ctx, logger, nk, userID, reward, newItems, updatedItems, notGrantedItemIDs, ignoreLimits, dryRun)
+		if err != nil {
+			return nil, nil, nil, err
 		}
+		writes = append(writes, itemWrites...)
+	}
+
+	if energyWrite != nil {
+		writes = append(writes, energyWrite)
 	}
 
-	// Process energy updates
-	if len(reward.Energies) > 0 {
-		err = e.updateEnergies(ctx, nk, userID, reward.Energies)
+	reads := rewardGrantStorageReads(userID, reward, energySystem == nil)
+	if len(reads) > 0 {
+		modifierWrites, err := rewardModifierWrites(ctx, nk, userID, reward, reads)
 		if err != nil {
-			logger.Error("Failed to update energies: %v", err)
+			logger.Error("Failed to apply energies and modifiers: %v", err)
 			// Continue execution, don't fail the entire operation
 		}
+		writes = append(writes, modifierWrites...)
 	}
 
-	// Process energy modifiers
-	if len(reward.EnergyModifiers) > 0 {
-		err = e.applyEnergyModifiers(ctx, nk, userID, reward.EnergyModifiers)
+	if record != nil && !dryRun {
+		recordWrite, err := record(newItems, updatedItems, notGrantedItemIDs)
 		if err != nil {
-			logger.Error("Failed to apply energy modifiers: %v", err)
-			// Continue execution, don't fail the entire operation
+			logger.Error("Failed to record reward grant for user %s: %v", userID, err)
+			return nil, nil, nil, ErrInternal
+		}
+		writes = append(writes, recordWrite)
+
+		var walletUpdates []*runtime.WalletUpdate
+		if len(reward.Currencies) > 0 {
+			walletUpdates = []*runtime.WalletUpdate{{UserID: userID, Changeset: reward.Currencies, Metadata: metadata}}
+		}
+		if err := retryNakama(ctx, nonIdempotentCall, func() error {
+			_, _, err := nk.MultiUpdate(ctx, nil, writes, nil, walletUpdates, false)
+			return err
+		}); err != nil {
+			logger.Error("Failed to write granted reward: %v", err)
+			if errors.Is(err, runtime.ErrStorageRejectedVersion) {
+				// Nothing was granted, so callers can read the record's object again and retry
+				return nil, nil, nil, err
+			}
+			return nil, nil, nil, runtime.NewError("Failed to grant reward", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+	} else if len(writes) > 0 && !dryRun {
+		if _, err := nk.StorageWrite(ctx, writes); err != nil {
+			logger.Error("Failed to write granted reward: %v", err)
+			return nil, nil, nil, runtime.NewError("Failed to grant reward", INTERNAL_ERROR_CODE) // INTERNAL
 		}
 	}
 
-	// Process reward modifiers
-	if len(reward.RewardModifiers) > 0 {
-		err = e.applyRewardModifiers(ctx, nk, userID, reward.RewardModifiers)
-		if err != nil {
-			logger.Error("Failed to apply reward modifiers: %v", err)
+	// Other energy systems write their own storage, so energies are granted once the rest of the reward is written
+	if grantEnergies && !dryRun {
+		if _, err := energySystem.Grant(ctx, logger, nk, userID, reward.Energies, nil); err != nil {
+			logger.Error("Failed to update energies: %v", err)
 			// Continue execution, don't fail the entire operation
 		}
 	}
 
+	if !dryRun && (len(reward.Currencies) > 0 || len(reward.Items) > 0) {
+		e.logEvents(ctx, logger, nk, &EconomyEvent{
+			Type:       EconomyEventTypeGrant,
+			UserId:     userID,
+			Currencies: reward.Currencies,
+			Items:      economyEventItems(reward.Items, notGrantedItemIDs),
+			Metadata:   economyEventMetadata(metadata),
+		})
+	}
+
 	return newItems, updatedItems, notGrantedItemIDs, nil
 }
 
+// rewardEnergyWrite adds the reward's energies to the user's energies and returns the storage write which saves them,
+// so they're written with the rest of the reward. The currencies overflowing energies convert into are added to the
+// reward's currencies. If the energy system can't prepare its grant, grantEnergies reports the energies are to be
+// granted through it once the reward is written.
+func (e *NakamaEconomySystem) rewardEnergyWrite(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, energySystem EnergySystem) (write *runtime.StorageWrite, grantEnergies bool) {
+	if len(reward.Energies) == 0 || energySystem == nil {
+		return nil, false
+	}
+	preparer, ok := energySystem.(interface {
+		prepareGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, amounts map[string]int32, modifiers []*RewardEnergyModifier) (map[string]*Energy, *runtime.StorageWrite, map[string]int64, error)
+	})
+	if !ok {
+		return nil, true
+	}
+
+	_, write, overflowCurrencies, err := preparer.prepareGrant(ctx, logger, nk, userID, reward.Energies, nil)
+	if err != nil {
+		logger.Error("Failed to update energies: %v", err)
+		// Continue execution, don't fail the entire operation
+		return nil, false
+	}
+	if len(overflowCurrencies) > 0 && reward.Currencies == nil {
+		reward.Currencies = make(map[string]int64, len(overflowCurrencies))
+	}
+	for currencyID, amount := range overflowCurrencies {
+		reward.Currencies[currencyID] += amount
+	}
+	return write, false
+}
+
 // Helper function to retrieve a user's inventory
 func (e *NakamaEconomySystem) getInventory(ctx context.Context, nk runtime.NakamaModule, userID string) (*Inventory, error) {
 	// Query the storage for all inventory items
@@ -877,190 +1070,306 @@ func (e *NakamaEconomySystem) getInventory(ctx context.Context, nk runtime.Nakam
 	return inventory, nil
 }
 
-// Helper function to update energy values
-func (e *NakamaEconomySystem) updateEnergies(ctx context.Context, nk runtime.NakamaModule, userID string, energies map[string]int32) error {
-	// Get current energy values
-	energyStorageObj, err := nk.StorageRead(ctx, []*runtime.StorageRead{
-		{
-			Collection: "energy",
-			Key:        "user_energies",
-			UserID:     userID,
-		},
-	})
+// rewardItemWrites grants the reward's items in memory and returns the storage writes that persist them. Item instance
+// properties are applied to the granted items before they are written.
+func (e *NakamaEconomySystem) rewardItemWrites(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, newItems, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, ignoreLimits, dryRun bool) ([]*runtime.StorageWrite, error) {
+	var inventorySystem InventorySystem
+	if pamlogixInst, ok := e.pamlogix.(interface{ GetInventorySystem() InventorySystem }); ok {
+		inventorySystem = pamlogixInst.GetInventorySystem()
+	}
+	if inventorySystem == nil {
+		logger.Warn("Inventory system not available, falling back to direct storage")
+		return e.grantItemsDirectly(ctx, logger, nk, userID, reward, newItems, updatedItems)
+	}
 
-	var userEnergies map[string]int32
-	if err != nil || len(energyStorageObj) == 0 {
-		// No existing energies, create new map
-		userEnergies = make(map[string]int32)
-	} else {
-		// Parse existing energies
-		err = json.Unmarshal([]byte(energyStorageObj[0].Value), &userEnergies)
+	preparer, ok := inventorySystem.(interface {
+		prepareGrantItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, itemIDs map[string]int64, ignoreLimits bool) (*Inventory, map[string]*InventoryItem, map[string]*InventoryItem, map[string]int64, map[string]*InventoryItem, error)
+	})
+	if !ok {
+		// Other inventory implementations write their own storage, so their grants can't be previewed
+		if dryRun {
+			return nil, runtime.NewError("dry run needs the built-in inventory system", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+		}
+		_, grantedNewItems, grantedUpdatedItems, notGrantedItems, err := inventorySystem.GrantItems(ctx, logger, nk, userID, reward.Items, ignoreLimits)
 		if err != nil {
-			return err
+			logger.Error("Failed to grant items through inventory system: %v", err)
+			return nil, runtime.NewError("Failed to grant items", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+		mergeGrantedItems(newItems, updatedItems, notGrantedItemIDs, grantedNewItems, grantedUpdatedItems, notGrantedItems)
+
+		if instanceUpdates := rewardInstanceProperties(reward.ItemInstances, grantedNewItems, grantedUpdatedItems); len(instanceUpdates) > 0 {
+			if _, err := inventorySystem.UpdateItems(ctx, logger, nk, userID, instanceUpdates); err != nil {
+				logger.Error("Failed to update item properties: %v", err)
+				// Continue execution, don't fail the entire operation
+			}
 		}
+		return nil, nil
 	}
 
-	// Update energy values
-	for energyID, amount := range energies {
-		userEnergies[energyID] += amount
+	_, grantedNewItems, grantedUpdatedItems, notGrantedItems, pending, err := preparer.prepareGrantItems(ctx, logger, nk, userID, reward.Items, ignoreLimits)
+	if err != nil {
+		logger.Error("Failed to grant items through inventory system: %v", err)
+		return nil, runtime.NewError("Failed to grant items", INTERNAL_ERROR_CODE) // INTERNAL
+	}
+	mergeGrantedItems(newItems, updatedItems, notGrantedItemIDs, grantedNewItems, grantedUpdatedItems, notGrantedItems)
+
+	instanceUpdates := rewardInstanceProperties(reward.ItemInstances, grantedNewItems, grantedUpdatedItems)
+	for _, item := range pending {
+		if props, found := instanceUpdates[item.InstanceId]; found {
+			applyInventoryItemProperties(item, props)
+		}
 	}
 
-	// Store updated energies
-	energyData, err := json.Marshal(userEnergies)
+	writes, err := inventoryItemWrites(userID, pending)
 	if err != nil {
-		return err
+		logger.Error("Failed to marshal inventory item: %v", err)
+		return nil, ErrInternal
 	}
+	return writes, nil
+}
 
-	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-		{
-			Collection:      "energy",
-			Key:             "user_energies",
-			UserID:          userID,
-			Value:           string(energyData),
-			Version:         energyStorageObj[0].Version,
-			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
-			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
-		},
-	})
+func mergeGrantedItems(newItems, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, grantedNewItems, grantedUpdatedItems map[string]*InventoryItem, notGrantedItems map[string]int64) {
+	for key, item := range grantedNewItems {
+		newItems[key] = item
+	}
+	for key, item := range grantedUpdatedItems {
+		updatedItems[key] = item
+	}
+	for itemID, count := range notGrantedItems {
+		notGrantedItemIDs[itemID] = count
+	}
+}
 
-	return err
+// rewardInstanceProperties matches the reward's item instance properties, keyed by item ID, to the granted inventory
+// items, keyed by instance ID.
+func rewardInstanceProperties(itemInstances map[string]*RewardInventoryItem, grantedItems ...map[string]*InventoryItem) map[string]*InventoryUpdateItemProperties {
+	updates := make(map[string]*InventoryUpdateItemProperties)
+	for _, items := range grantedItems {
+		for _, item := range items {
+			itemInstance := itemInstances[item.Id]
+			if itemInstance == nil || item.InstanceId == "" {
+				continue
+			}
+			if len(itemInstance.StringProperties) > 0 || len(itemInstance.NumericProperties) > 0 {
+				updates[item.InstanceId] = &InventoryUpdateItemProperties{
+					StringProperties:  itemInstance.StringProperties,
+					NumericProperties: itemInstance.NumericProperties,
+				}
+			}
+		}
+	}
+	return updates
 }
 
-// Helper function to apply energy modifiers
-func (e *NakamaEconomySystem) applyEnergyModifiers(ctx context.Context, nk runtime.NakamaModule, userID string, modifiers []*RewardEnergyModifier) error {
-	// Get current modifiers
-	modifiersObj, err := nk.StorageRead(ctx, []*runtime.StorageRead{
-		{
-			Collection: userModifiersStorageCollection,
-			Key:        userID + "_energy_modifiers",
-			UserID:     userID,
-		},
-	})
+func applyInventoryItemProperties(item *InventoryItem, props *InventoryUpdateItemProperties) {
+	if len(props.StringProperties) > 0 && item.StringProperties == nil {
+		item.StringProperties = make(map[string]string, len(props.StringProperties))
+	}
+	for key, value := range props.StringProperties {
+		item.StringProperties[key] = value
+	}
+	if len(props.NumericProperties) > 0 && item.NumericProperties == nil {
+		item.NumericProperties = make(map[string]float64, len(props.NumericProperties))
+	}
+	for key, value := range props.NumericProperties {
+		item.NumericProperties[key] = value
+	}
+}
 
-	var activeModifiers []*ActiveRewardModifier
-	var version string
+// rewardGrantStorageReads lists the storage objects RewardGrant updates directly: the stored energies when there is
+// no energy system, and the active energy and reward modifiers.
+func rewardGrantStorageReads(userID string, reward *Reward, directEnergies bool) []*runtime.StorageRead {
+	reads := make([]*runtime.StorageRead, 0, 3)
+	if len(reward.Energies) > 0 && directEnergies {
+		reads = append(reads, &runtime.StorageRead{Collection: energyStorageCollection, Key: userEnergyStorageKey, UserID: userID})
+	}
+	if len(reward.EnergyModifiers) > 0 {
+		reads = append(reads, &runtime.StorageRead{Collection: userModifiersStorageCollection, Key: userID + "_energy_modifiers", UserID: userID})
+	}
+	if len(reward.RewardModifiers) > 0 {
+		reads = append(reads, &runtime.StorageRead{Collection: userModifiersStorageCollection, Key: userID + "_reward_modifiers", UserID: userID})
+	}
+	return reads
+}
 
-	if err != nil || len(modifiersObj) == 0 {
-		// No existing modifiers
-		activeModifiers = make([]*ActiveRewardModifier, 0)
-	} else {
-		// Parse existing modifiers
-		err = json.Unmarshal([]byte(modifiersObj[0].Value), &activeModifiers)
+// rewardModifierWrites reads the objects listed by rewardGrantStorageReads in one call and returns the writes that add
+// the reward's energies, energy modifiers and reward modifiers to them.
+func rewardModifierWrites(ctx context.Context, nk runtime.NakamaModule, userID string, reward *Reward, reads []*runtime.StorageRead) ([]*runtime.StorageWrite, error) {
+	objects, err := nk.StorageRead(ctx, reads)
+	if err != nil {
+		return nil, err
+	}
+	return rewardModifierObjectWrites(userID, reward, reads, objects, time.Now().Unix())
+}
+
+// rewardModifierObjectWrites returns the writes that add the reward's energies, energy modifiers and reward modifiers
+// to the user's objects, already read for the reads listed by rewardGrantStorageReads. The objects must all belong to
+// the user.
+func rewardModifierObjectWrites(userID string, reward *Reward, reads []*runtime.StorageRead, objects []*api.StorageObject, now int64) ([]*runtime.StorageWrite, error) {
+	findObject := func(collection, key string) *api.StorageObject {
+		for _, object := range objects {
+			if object.Collection == collection && object.Key == key {
+				return object
+			}
+		}
+		return nil
+	}
+
+	writes := make([]*runtime.StorageWrite, 0, len(reads))
+	for _, read := range reads {
+		object := findObject(read.Collection, read.Key)
+
+		var write *runtime.StorageWrite
+		var err error
+		switch read.Key {
+		case userEnergyStorageKey:
+			write, err = energiesGrantWrite(userID, object, reward.Energies, now)
+		case userID + "_energy_modifiers":
+			added := make([]*ActiveRewardModifier, 0, len(reward.EnergyModifiers))
+			for _, modifier := range reward.EnergyModifiers {
+				added = append(added, &ActiveRewardModifier{
+					Id:           modifier.Id,
+					Operator:     modifier.Operator,
+					Value:        modifier.Value,
+					StartTimeSec: now,
+					EndTimeSec:   modifierEndTimeSec(now, int64(modifier.DurationSec)),
+				})
+			}
+			write, err = activeModifiersWrite(userID, read.Key, object, added)
+		case userID + "_reward_modifiers":
+			added := make([]*ActiveRewardModifier, 0, len(reward.RewardModifiers))
+			for _, modifier := range reward.RewardModifiers {
+				added = append(added, &ActiveRewardModifier{
+					Id:           modifier.Id,
+					Type:         modifier.Type,
+					Operator:     modifier.Operator,
+					Value:        modifier.Value,
+					StartTimeSec: now,
+					EndTimeSec:   modifierEndTimeSec(now, int64(modifier.DurationSec)),
+				})
+			}
+			write, err = activeModifiersWrite(userID, read.Key, object, added)
+		}
 		if err != nil {
-			return err
+			return nil, err
 		}
-		version = modifiersObj[0].Version
+		writes = append(writes, write)
 	}
 
-	// Current timestamp
-	now := time.Now().Unix()
+	return writes, nil
+}
 
-	// Add new modifiers
-	for _, modifier := range modifiers {
-		var expiryTime int64 = 0
-		if modifier.DurationSec > 0 {
-			expiryTime = now + int64(modifier.DurationSec)
+// energiesGrantWrite adds energies to the user's stored energies. Without an energy system the only known cap is the
+// stored maximum.
+func energiesGrantWrite(userID string, object *api.StorageObject, energies map[string]int32, now int64) (*runtime.StorageWrite, error) {
+	energyList := &EnergyList{}
+	var version string
+	if object != nil {
+		if err := json.Unmarshal([]byte(object.Value), energyList); err != nil {
+			return nil, err
 		}
+		version = object.Version
+	}
+	if energyList.Energies == nil {
+		energyList.Energies = make(map[string]*Energy)
+	}
 
-		activeModifier := &ActiveRewardModifier{
-			Id:           modifier.Id,
-			Operator:     modifier.Operator,
-			Value:        modifier.Value,
-			StartTimeSec: now,
-			EndTimeSec:   expiryTime,
+	for energyID, amount := range energies {
+		energy, found := energyList.Energies[energyID]
+		if !found {
+			energy = &Energy{
+				Id:             energyID,
+				CurrentTimeSec: now,
+			}
+			energyList.Energies[energyID] = energy
 		}
-
-		activeModifiers = append(activeModifiers, activeModifier)
+		energy.Current = capEnergyGrant(energy.Current, amount, energy.Max)
 	}
 
-	// Store updated modifiers
-	modifiersData, err := json.Marshal(activeModifiers)
+	energyData, err := json.Marshal(energyList)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-		{
-			Collection:      userModifiersStorageCollection,
-			Key:             userID + "_energy_modifiers",
-			UserID:          userID,
-			Value:           string(modifiersData),
-			Version:         version,
-			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
-			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
-		},
-	})
-
-	return err
+	return &runtime.StorageWrite{
+		Collection:      energyStorageCollection,
+		Key:             userEnergyStorageKey,
+		UserID:          userID,
+		Value:           string(energyData),
+		Version:         version,
+		PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
+	}, nil
 }
 
-// Helper function to apply reward modifiers
-func (e *NakamaEconomySystem) applyRewardModifiers(ctx context.Context, nk runtime.NakamaModule, userID string, modifiers []*RewardModifier) error {
-	// Get current modifiers
-	modifiersObj, err := nk.StorageRead(ctx, []*runtime.StorageRead{
-		{
-			Collection: userModifiersStorageCollection,
-			Key:        userID + "_reward_modifiers",
-			UserID:     userID,
-		},
-	})
+// capEnergyGrant adds amount to current, limiting the result to max when a positive max is known. Energy which is
+// already above the max, from an earlier overfill, is never reduced by a grant.
+func capEnergyGrant(current, amount, max int32) int32 {
+	updated := current + amount
+	if max > 0 && amount > 0 && updated > max {
+		updated = max
+		if current > max {
+			updated = current
+		}
+	}
+	if updated < 0 {
+		updated = 0
+	}
+	return updated
+}
 
-	var activeModifiers []*ActiveRewardModifier
+// activeModifiersWrite appends modifiers to the active modifiers stored under key.
+func activeModifiersWrite(userID, key string, object *api.StorageObject, added []*ActiveRewardModifier) (*runtime.StorageWrite, error) {
+	activeModifiers := make([]*ActiveRewardModifier, 0, len(added))
 	var version string
-
-	if err != nil || len(modifiersObj) == 0 {
-		// No existing modifiers
-		activeModifiers = make([]*ActiveRewardModifier, 0)
-	} else {
-		// Parse existing modifiers
-		err = json.Unmarshal([]byte(modifiersObj[0].Value), &activeModifiers)
-		if err != nil {
-			return err
+	if object != nil {
+		if err := json.Unmarshal([]byte(object.Value), &activeModifiers); err != nil {
+			return nil, err
 		}
-		version = modifiersObj[0].Version
+		version = object.Version
 	}
+	activeModifiers = append(activeModifiers, added...)
 
-	// Current timestamp
-	now := time.Now().Unix()
-
-	// Add new modifiers
-	for _, modifier := range modifiers {
-		var expiryTime int64 = 0
-		if modifier.DurationSec > 0 {
-			expiryTime = now + int64(modifier.DurationSec)
-		}
+	modifiersData, err := json.Marshal(activeModifiers)
+	if err != nil {
+		return nil, err
+	}
 
-		activeModifier := &ActiveRewardModifier{
-			Id:           modifier.Id,
-			Type:         modifier.Type,
-			Operator:     modifier.Operator,
-			Value:        modifier.Value,
-			EndTimeSec:   expiryTime,
-			StartTimeSec: now,
-		}
+	return &runtime.StorageWrite{
+		Collection:      userModifiersStorageCollection,
+		Key:             key,
+		UserID:          userID,
+		Value:           string(modifiersData),
+		Version:         version,
+		PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
+	}, nil
+}
 
-		activeModifiers = append(activeModifiers, activeModifier)
+// modifierEndTimeSec is when a modifier granted now expires, or zero if it has no duration.
+func modifierEndTimeSec(now, durationSec int64) int64 {
+	if durationSec <= 0 {
+		return 0
 	}
+	return now + durationSec
+}
 
-	// Store updated modifiers
-	modifiersData, err := json.Marshal(activeModifiers)
+// grantDonationClaimReward grants the combined recipient reward of a donation claim, after routing items to
+// unlockables and currency caps, and records it on the donation.
+func (e *NakamaEconomySystem) grantDonationClaimReward(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, donationID string, donation *EconomyDonation, reward *Reward, metadata map[string]interface{}) {
+	grantReward, err := e.routeRewardItemsToUnlockables(ctx, logger, nk, userID, UnlockableGrantSourceDonation, reward)
 	if err != nil {
-		return err
+		logger.Error("Failed to place donation items in unlockables for user %s: %v", userID, err)
+		grantReward = reward
 	}
+	if _, err := e.RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourceDonation, grantReward, metadata); err != nil {
+		logger.Error("Failed to grant recipient reward for donation %s: %v", donationID, err)
+		return
+	}
+	reward.Currencies = grantReward.Currencies
 
-	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-		{
-			Collection:      userModifiersStorageCollection,
-			Key:             userID + "_reward_modifiers",
-			UserID:          userID,
-			Value:           string(modifiersData),
-			Version:         version,
-			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
-			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
-		},
-	})
-
-	return err
+	// Store the reward in the donation
+	donation.RecipientRewards = append(donation.RecipientRewards, reward)
 }
 
 func (e *NakamaEconomySystem) DonationClaim(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, donationClaims map[string]*EconomyDonationClaimRequestDetails) (donationsList *EconomyDonationsList, err error) {
@@ -1073,6 +1382,18 @@ func (e *NakamaEconomySystem) DonationClaim(ctx context.Context, logger runtime.
 		return nil, runtime.NewError("donation claims is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
 
+	// Contributors update the same donation records, so hold their locks until the claims are written
+	lockNames := make([]string, 0, len(donationClaims))
+	for donationID := range donationClaims {
+		lockNames = append(lockNames, donationLockName(userID, donationID))
+	}
+	release, err := acquireStorageLocks(ctx, nk, lockNames...)
+	if err != nil {
+		logger.Warn("Failed to lock donations for user %s: %v", userID, err)
+		return nil, err
+	}
+	defer release()
+
 	// Get all user donations
 	userDonations, err := e.getUserDonations(ctx, nk, userID)
 	if err != nil {
@@ -1181,37 +1502,28 @@ func (e *NakamaEconomySystem) DonationClaim(ctx context.Context, logger runtime.
 		if e.config != nil && e.config.Donations != nil {
 			donationConfig, configExists := e.config.Donations[donationID]
 			if configExists && donationConfig.RecipientReward != nil {
-				// Roll recipient reward for the claimed amount
-				for i := int64(0); i < totalClaimAmount; i++ {
-					reward, rollErr := e.RewardRoll(ctx, logger, nk, userID, donationConfig.RecipientReward)
-					if rollErr != nil {
-						logger.Error("Failed to roll recipient reward for donation %s: %v", donationID, rollErr)
-						continue
-					}
+				// Roll the recipient reward once per claimed unit, and grant the rolls together in one go
+				reward, rollErr := e.RewardRollN(ctx, logger, nk, userID, donationConfig.RecipientReward, totalClaimAmount)
+				if rollErr != nil {
+					logger.Error("Failed to roll recipient reward for donation %s: %v", donationID, rollErr)
+				}
 
-					// Apply custom reward function if configured
-					if e.onDonationClaimReward != nil {
-						reward, rollErr = e.onDonationClaimReward(ctx, logger, nk, userID, donationID, donationConfig, donationConfig.RecipientReward, reward)
-						if rollErr != nil {
-							logger.Error("Error in donation claim reward callback: %v", rollErr)
-						}
+				// Apply custom reward function if configured
+				if reward != nil && e.onDonationClaimReward != nil {
+					reward, rollErr = e.onDonationClaimReward(ctx, logger, nk, userID, donationID, donationConfig, donationConfig.RecipientReward, reward)
+					if rollErr != nil {
+						logger.Error("Error in donation claim reward callback: %v", rollErr)
 					}
+				}
 
-					// Grant the reward
-					if reward != nil {
-						_, _, _, grantErr := e.RewardGrant(ctx, logger, nk, userID, reward, map[string]interface{}{
-							"donation_id":  donationID,
-							"claim_amount": totalClaimAmount,
-							"claimed_from": donorsToClaimFrom,
-							"reason":       "donation_claim_reward",
-						}, false)
-						if grantErr != nil {
-							logger.Error("Failed to grant recipient reward for donation %s: %v", donationID, grantErr)
-						} else {
-							// Store the reward in the donation
-							donation.RecipientRewards = append(donation.RecipientRewards, reward)
-						}
-					}
+				// Grant the reward
+				if reward != nil {
+					e.grantDonationClaimReward(ctx, logger, nk, userID, donationID, donation, reward, map[string]interface{}{
+						"donation_id":  donationID,
+						"claim_amount": totalClaimAmount,
+						"claimed_from": donorsToClaimFrom,
+						"reason":       "donation_claim_reward",
+					})
 				}
 			}
 		}
@@ -1313,20 +1625,20 @@ func (e *NakamaEconomySystem) DonationGet(ctx context.Context, logger runtime.Lo
 	return donationsList, nil
 }
 
-func (e *NakamaEconomySystem) DonationGive(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, donationID, fromUserID string) (donation *EconomyDonation, updatedWallet map[string]int64, updatedInventory *Inventory, rewardModifiers []*ActiveRewardModifier, contributorReward *Reward, timestamp int64, err error) {
+func (e *NakamaEconomySystem) DonationGive(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, recipientID, donationID, contributorID string) (donation *EconomyDonation, updatedWallet map[string]int64, updatedInventory *Inventory, rewardModifiers []*ActiveRewardModifier, contributorReward *Reward, timestamp int64, err error) {
 	// Validate inputs
-	if userID == "" {
-		return nil, nil, nil, nil, nil, 0, runtime.NewError("contributor user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
-	}
-	if fromUserID == "" {
+	if recipientID == "" {
 		return nil, nil, nil, nil, nil, 0, runtime.NewError("recipient user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
+	if contributorID == "" {
+		return nil, nil, nil, nil, nil, 0, runtime.NewError("contributor user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
 	if donationID == "" {
 		return nil, nil, nil, nil, nil, 0, runtime.NewError("donation ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
 
 	// Prevent self-donation
-	if userID == fromUserID {
+	if recipientID == contributorID {
 		return nil, nil, nil, nil, nil, 0, runtime.NewError("cannot contribute to own donation", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
 
@@ -1340,13 +1652,21 @@ func (e *NakamaEconomySystem) DonationGive(ctx context.Context, logger runtime.L
 		return nil, nil, nil, nil, nil, 0, runtime.NewError(fmt.Sprintf("donation config not found for ID: %s", donationID), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
 
+	// The recipient and other contributors update the same record, so hold its lock for the read-modify-write
+	release, err := acquireStorageLocks(ctx, nk, donationLockName(recipientID, donationID))
+	if err != nil {
+		logger.Warn("Failed to lock donation %s for user %s: %v", donationID, recipientID, err)
+		return nil, nil, nil, nil, nil, 0, err
+	}
+	defer release()
+
 	// Get the recipient's donation request
 	key := fmt.Sprintf("donation:%s", donationID)
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
 			Collection: donationsStorageCollection,
 			Key:        key,
-			UserID:     userID,
+			UserID:     recipientID,
 		},
 	})
 
@@ -1374,7 +1694,7 @@ func (e *NakamaEconomySystem) DonationGive(ctx context.Context, logger runtime.L
 	// Check contributor's contribution limit
 	contributorCount := int64(0)
 	for _, contributor := range donationData.Contributors {
-		if contributor.UserId == userID {
+		if contributor.UserId == contributorID {
 			contributorCount += contributor.Count
 		}
 	}
@@ -1401,59 +1721,17 @@ func (e *NakamaEconomySystem) DonationGive(ctx context.Context, logger runtime.L
 		return nil, nil, nil, nil, nil, 0, runtime.NewError("cannot contribute to this donation", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
 	}
 
-	// Deduct cost from contributor if configured
-	if donationConfig.Cost != nil {
-		// Deduct currencies
-		if len(donationConfig.Cost.Currencies) > 0 {
-			costCurrencies := make(map[string]int64)
-			for currencyID, amount := range donationConfig.Cost.Currencies {
-				costCurrencies[currencyID] = -amount * contributionAmount
-			}
-
-			_, _, err = nk.WalletUpdate(ctx, userID, costCurrencies, map[string]interface{}{
-				"donation_give": donationID,
-				"recipient":     userID,
-				"reason":        "donation_contribution",
-			}, false)
-			if err != nil {
-				logger.Error("Failed to deduct currency cost for donation: %v", err)
-				return nil, nil, nil, nil, nil, 0, runtime.NewError("insufficient funds for donation", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
-			}
-		}
-
-		// Deduct items
-		if len(donationConfig.Cost.Items) > 0 {
-			if e.pamlogix == nil {
-				return nil, nil, nil, nil, nil, 0, runtime.NewError("inventory system not available", INTERNAL_ERROR_CODE) // INTERNAL
-			}
-
-			inventorySystem := e.pamlogix.(interface{ GetInventorySystem() InventorySystem }).GetInventorySystem()
-			if inventorySystem == nil {
-				return nil, nil, nil, nil, nil, 0, runtime.NewError("inventory system not available", INTERNAL_ERROR_CODE) // INTERNAL
-			}
-
-			// Create negative amounts for deduction
-			deductItems := make(map[string]int64)
-			for itemID, amount := range donationConfig.Cost.Items {
-				deductItems[itemID] = -amount * contributionAmount
-			}
-
-			_, _, _, notGrantedItems, err := inventorySystem.GrantItems(ctx, logger, nk, userID, deductItems, false)
-			if err != nil || len(notGrantedItems) > 0 {
-				// Rollback currency if item deduction failed
-				if len(donationConfig.Cost.Currencies) > 0 {
-					rollbackCurrencies := make(map[string]int64)
-					for currencyID, amount := range donationConfig.Cost.Currencies {
-						rollbackCurrencies[currencyID] = amount * contributionAmount
-					}
-					_, _, _ = nk.WalletUpdate(ctx, userID, rollbackCurrencies, map[string]interface{}{
-						"reason": "rollback_donation_cost",
-					}, false)
-				}
-				logger.Error("Failed to deduct item cost for donation: err=%v, not_granted=%v", err, notGrantedItems)
-				return nil, nil, nil, nil, nil, 0, runtime.NewError("insufficient items for donation", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
-			}
-		}
+	// Charge the contributor the cost of each unit they give
+	pl, _ := e.pamlogix.(Pamlogix)
+	contributionCost := donationConfig.Cost.multiply(contributionAmount)
+	contributionMetadata := map[string]interface{}{
+		"donation_give": donationID,
+		"recipient":     recipientID,
+		"reason":        "donation_contribution",
+	}
+	if err = chargeCost(ctx, logger, nk, pl, contributorID, contributionCost, contributionMetadata, false); err != nil {
+		logger.Error("Failed to charge donation cost to user %s: %v", contributorID, err)
+		return nil, nil, nil, nil, nil, 0, err
 	}
 
 	// Update donation progress
@@ -1462,7 +1740,7 @@ func (e *NakamaEconomySystem) DonationGive(ctx context.Context, logger runtime.L
 	// Add or update contributor record
 	contributorFound := false
 	for i, contributor := range donationData.Contributors {
-		if contributor.UserId == fromUserID {
+		if contributor.UserId == contributorID {
 			donationData.Contributors[i].Count += contributionAmount
 			contributorFound = true
 			break
@@ -1470,7 +1748,7 @@ func (e *NakamaEconomySystem) DonationGive(ctx context.Context, logger runtime.L
 	}
 	if !contributorFound {
 		donationData.Contributors = append(donationData.Contributors, &EconomyDonationContributor{
-			UserId: fromUserID,
+			UserId: contributorID,
 			Count:  contributionAmount,
 		})
 	}
@@ -1478,83 +1756,113 @@ func (e *NakamaEconomySystem) DonationGive(ctx context.Context, logger runtime.L
 	// Check if donation is now fulfilled
 	donationFulfilled := donationData.Count >= donationData.MaxCount
 
-	// Prepare contributor reward if donation is fulfilled
+	// Save updated donation
+	donationBytes, err := json.Marshal(&donationData)
+	if err != nil {
+		logger.Error("Failed to marshal donation data: %v", err)
+		return nil, nil, nil, nil, nil, 0, runtime.NewError("failed to update donation", INTERNAL_ERROR_CODE) // INTERNAL
+	}
+
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      donationsStorageCollection,
+			Key:             key,
+			UserID:          recipientID,
+			Value:           string(donationBytes),
+			Version:         objects[0].Version,
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to update donation: %v", err)
+		_ = refundCost(ctx, logger, nk, pl, contributorID, contributionCost, contributionMetadata)
+		return nil, nil, nil, nil, nil, 0, runtime.NewError("failed to update donation", INTERNAL_ERROR_CODE) // INTERNAL
+	}
+
+	// Reward the contributor who fulfilled the donation, once their contribution is saved
 	if donationFulfilled && donationConfig.ContributorReward != nil {
 		// Roll the contributor reward
-		contributorReward, err = e.RewardRoll(ctx, logger, nk, fromUserID, donationConfig.ContributorReward)
+		contributorReward, err = e.RewardRoll(ctx, logger, nk, contributorID, donationConfig.ContributorReward)
 		if err != nil {
 			logger.Error("Failed to roll contributor reward: %v", err)
-			// Continue anyway, we'll still update the donation
+			// Continue anyway, the contribution is already saved
 		} else if contributorReward != nil {
 			// Apply custom reward function if configured
 			if e.onDonationContributorReward != nil {
-				contributorReward, err = e.onDonationContributorReward(ctx, logger, nk, fromUserID, donationID, donationConfig, donationConfig.ContributorReward, contributorReward)
+				contributorReward, err = e.onDonationContributorReward(ctx, logger, nk, contributorID, donationID, donationConfig, donationConfig.ContributorReward, contributorReward)
 				if err != nil {
 					logger.Error("Error in donation contributor reward callback: %v", err)
 				}
 			}
 
 			// Grant the contributor reward
-			_, _, _, err = e.RewardGrant(ctx, logger, nk, fromUserID, contributorReward, map[string]interface{}{
+			grantReward, routeErr := e.routeRewardItemsToUnlockables(ctx, logger, nk, contributorID, UnlockableGrantSourceDonation, contributorReward)
+			if routeErr != nil {
+				logger.Error("Failed to place donation items in unlockables for user %s: %v", contributorID, routeErr)
+				grantReward = contributorReward
+			}
+			_, err = e.RewardGrantCapped(ctx, logger, nk, contributorID, EconomyGrantSourceDonation, grantReward, map[string]interface{}{
 				"donation_id": donationID,
-				"recipient":   userID,
+				"recipient":   recipientID,
 				"reason":      "donation_contribution_reward",
-			}, false)
+			})
 			if err != nil {
 				logger.Error("Failed to grant contributor reward: %v", err)
 				// Continue anyway
 			}
+			contributorReward.Currencies = grantReward.Currencies
 		}
 	}
 
-	// Save updated donation
-	donationBytes, err := json.Marshal(&donationData)
+	if pl != nil {
+		pl.SendPublisherEvents(ctx, logger, nk, contributorID, []*PublisherEvent{{
+			Name:      donationGivenEvent,
+			Id:        donationID,
+			Timestamp: now,
+			Metadata:  map[string]string{"recipient": recipientID},
+			Value:     strconv.FormatInt(contributionAmount, 10),
+			System:    e,
+			SourceId:  donationID,
+			Source:    donationConfig,
+		}})
+	}
+
+	// Let the requester know their donation is complete
+	if donationFulfilled {
+		if err := sendTemplatedNotification(ctx, logger, nk, pl, recipientID, NotificationEventDonationFulfilled, map[string]string{
+			"donation_id": donationID,
+		}, map[string]interface{}{
+			"donation_id": donationID,
+			"type":        "donation_fulfilled",
+		}); err != nil {
+			logger.Error("Failed to send donation fulfilled notification to user %s: %v", recipientID, err)
+		}
+	}
+
+	//TODO: test performance for updated wallet, inventory, reward modifiers
+
+	// Get updated wallet
+	updatedWallet, err = userWallet(ctx, nk, contributorID)
 	if err != nil {
-		logger.Error("Failed to marshal donation data: %v", err)
-		return nil, nil, nil, nil, nil, 0, runtime.NewError("failed to update donation", INTERNAL_ERROR_CODE) // INTERNAL
-	}
-
-	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-		{
-			Collection:      donationsStorageCollection,
-			Key:             key,
-			UserID:          userID,
-			Value:           string(donationBytes),
-			Version:         objects[0].Version,
-			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
-			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
-		},
-	})
-	if err != nil {
-		logger.Error("Failed to update donation: %v", err)
-		return nil, nil, nil, nil, nil, 0, runtime.NewError("failed to update donation", INTERNAL_ERROR_CODE) // INTERNAL
-	}
-
-	//TODO: test performance for updated wallet, inventory, reward modifiers
-
-	// Get updated wallet
-	account, err := nk.AccountGetId(ctx, fromUserID)
-	if err != nil {
-		logger.Error("Failed to get account: %v", err)
-	} else {
-		updatedWallet, _ = e.UnmarshalWallet(account)
+		logger.Error("Failed to get wallet: %v", err)
 	}
 
 	// Get updated inventory
-	inventory, err := e.getInventory(ctx, nk, fromUserID)
+	inventory, err := e.getInventory(ctx, nk, contributorID)
 	if err != nil {
 		logger.Error("Failed to get inventory: %v", err)
 	} else {
 		updatedInventory = inventory
 	}
 
-	// Get active reward modifiers
+	// Get the contributor's active reward modifiers
 	rewardModifiers = make([]*ActiveRewardModifier, 0)
 	modifiersObj, _ := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
 			Collection: userModifiersStorageCollection,
-			Key:        userID + "_reward_modifiers",
-			UserID:     userID,
+			Key:        contributorID + "_reward_modifiers",
+			UserID:     contributorID,
 		},
 	})
 	if len(modifiersObj) > 0 {
@@ -1564,7 +1872,7 @@ func (e *NakamaEconomySystem) DonationGive(ctx context.Context, logger runtime.L
 	timestamp = time.Now().Unix()
 
 	logger.Info("User %s contributed %d to donation %s for user %s (fulfilled=%v)",
-		userID, contributionAmount, donationID, fromUserID, donationFulfilled)
+		contributorID, contributionAmount, donationID, recipientID, donationFulfilled)
 
 	return &donationData, updatedWallet, updatedInventory, rewardModifiers, contributorReward, timestamp, nil
 }
@@ -1598,11 +1906,7 @@ func (e *NakamaEconomySystem) executeDonationRequestTransaction(ctx context.Cont
 		return existingDonation, false, nil
 	}
 
-	// Step 3: Transaction execution with compensation tracking
-	var compensationActions []func() error
-	var currencyDeducted, itemsDeducted bool
-
-	// Create the donation object first (for consistent state)
+	// Step 3: Create the donation object first (for consistent state)
 	now := time.Now().Unix()
 	donation = &EconomyDonation{
 		UserId:                      userID,
@@ -1622,73 +1926,26 @@ func (e *NakamaEconomySystem) executeDonationRequestTransaction(ctx context.Cont
 		AdditionalProperties:        donationConfig.AdditionalProperties,
 	}
 
-	// Defer cleanup function to handle compensation on failure
-	defer func() {
-		if err != nil && len(compensationActions) > 0 {
-			logger.Warn("Transaction failed, executing compensation actions")
-			for i := len(compensationActions) - 1; i >= 0; i-- {
-				if compensateErr := compensationActions[i](); compensateErr != nil {
-					logger.Error("Compensation action failed: %v", compensateErr)
-				}
-			}
-		}
-	}()
-
-	// Step 4: Execute cost deductions with compensation tracking
-	if donationConfig.Cost != nil {
-		// Deduct currencies first
-		if len(donationConfig.Cost.Currencies) > 0 {
-			costCurrencies := make(map[string]int64)
-			rollbackCurrencies := make(map[string]int64)
-
-			for currencyID, amount := range donationConfig.Cost.Currencies {
-				costCurrencies[currencyID] = -amount
-				rollbackCurrencies[currencyID] = amount
-			}
-
-			_, _, err = nk.WalletUpdate(ctx, userID, costCurrencies, map[string]interface{}{
-				"donation_request": donationID,
-				"reason":           "donation_cost",
-			}, false)
-			if err != nil {
-				logger.Error("Failed to deduct currency cost: %v", err)
-				return nil, false, runtime.NewError("Insufficient funds for donation request", FAILED_PRECONDITION_ERROR_CODE)
-			}
-
-			currencyDeducted = true
-			// Add compensation action for currency rollback
-			compensationActions = append(compensationActions, func() error {
-				_, _, rollbackErr := nk.WalletUpdate(ctx, userID, rollbackCurrencies, map[string]interface{}{
-					"donation_request": donationID,
-					"reason":           "rollback_donation_cost",
-				}, false)
-				return rollbackErr
-			})
-		}
-
-		// Deduct items second
-		if len(donationConfig.Cost.Items) > 0 {
-			err = e.deductItemCosts(ctx, logger, nk, userID, donationID, donationConfig.Cost.Items, &compensationActions)
-			if err != nil {
-				logger.Error("Failed to deduct item costs: %v", err)
-				return nil, false, err
-			}
-			itemsDeducted = true
-		}
+	// Step 4: Charge the donation cost
+	pl, _ := e.pamlogix.(Pamlogix)
+	costMetadata := map[string]interface{}{
+		"donation_request": donationID,
+		"reason":           "donation_cost",
+	}
+	if err = chargeCost(ctx, logger, nk, pl, userID, donationConfig.Cost, costMetadata, false); err != nil {
+		logger.Error("Failed to charge donation request cost to user %s: %v", userID, err)
+		return nil, false, err
 	}
 
-	// Step 5: Store the donation (final atomic operation)
+	// Step 5: Store the donation, giving the cost back if it can't be stored
 	err = e.storeDonation(ctx, logger, nk, userID, donationID, donation)
 	if err != nil {
 		logger.Error("Failed to store donation: %v", err)
+		_ = refundCost(ctx, logger, nk, pl, userID, donationConfig.Cost, costMetadata)
 		return nil, false, runtime.NewError("Failed to create donation request", INTERNAL_ERROR_CODE)
 	}
 
-	// Step 6: Success - clear compensation actions since transaction completed
-	compensationActions = nil
-
-	logger.Info("Successfully created donation request for user %s, donation %s (currency_deducted=%v, items_deducted=%v)",
-		userID, donationID, currencyDeducted, itemsDeducted)
+	logger.Info("Successfully created donation request for user %s, donation %s", userID, donationID)
 	return donation, true, nil
 }
 
@@ -1748,34 +2005,6 @@ func (e *NakamaEconomySystem) checkExistingDonation(ctx context.Context, nk runt
 	return nil, nil // Expired donation, can create new one
 }
 
-// deductItemCosts handles item cost deduction with compensation tracking
-func (e *NakamaEconomySystem) deductItemCosts(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, donationID string, costItems map[string]int64, compensationActions *[]func() error) error {
-	inventorySystem := e.pamlogix.(interface{ GetInventorySystem() InventorySystem }).GetInventorySystem()
-
-	// Create negative amounts for deduction and positive for rollback
-	deductItems := make(map[string]int64)
-	rollbackItems := make(map[string]int64)
-
-	for itemID, amount := range costItems {
-		deductItems[itemID] = -amount
-		rollbackItems[itemID] = amount
-	}
-
-	_, _, _, notGrantedItems, err := inventorySystem.GrantItems(ctx, logger, nk, userID, deductItems, false)
-	if err != nil || len(notGrantedItems) > 0 {
-		logger.Error("Failed to deduct item cost (insufficient items): err=%v, not_granted=%v", err, notGrantedItems)
-		return runtime.NewError("Insufficient items for donation request", FAILED_PRECONDITION_ERROR_CODE)
-	}
-
-	// Add compensation action for item rollback
-	*compensationActions = append(*compensationActions, func() error {
-		_, _, _, _, rollbackErr := inventorySystem.GrantItems(ctx, logger, nk, userID, rollbackItems, true) // Use ignoreLimits for rollback
-		return rollbackErr
-	})
-
-	return nil
-}
-
 // storeDonation performs the final storage operation
 func (e *NakamaEconomySystem) storeDonation(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, donationID string, donation *EconomyDonation) error {
 	key := fmt.Sprintf("donation:%s", donationID)
@@ -1806,6 +2035,11 @@ func (e *NakamaEconomySystem) List(ctx context.Context, logger runtime.Logger, n
 	}
 	storeItems = e.config.StoreItems
 	placements = e.config.Placements
+	if userID != "" {
+		if storeItems, err = e.storeItemsForUser(ctx, logger, nk, userID); err != nil {
+			return nil, nil, nil, 0, err
+		}
+	}
 
 	// Optionally, fetch active reward modifiers for the user
 	rewardModifiers = []*ActiveRewardModifier{}
@@ -1824,8 +2058,139 @@ func (e *NakamaEconomySystem) List(ctx context.Context, logger runtime.Logger, n
 	return
 }
 
+// storeItemsForUser returns the store items as the user sees them. Items whose conditions the user doesn't meet are
+// left out if they're hidden, or listed as unavailable otherwise. Items the content calendar has off sale are left out.
+func (e *NakamaEconomySystem) storeItemsForUser(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (map[string]*EconomyConfigStoreItem, error) {
+	conditions := make([]*EconomyConfigStoreItemConditions, 0)
+	for _, item := range e.config.StoreItems {
+		if item.Conditions != nil {
+			conditions = append(conditions, item.Conditions)
+		}
+	}
+	pl, _ := e.pamlogix.(Pamlogix)
+	offSale := contentCalendarOffSaleItems(pl, time.Now().Unix())
+	if len(conditions) == 0 && len(offSale) == 0 {
+		return e.config.StoreItems, nil
+	}
+
+	state := &storeItemConditionState{logger: logger, env: &RuleEnv{}}
+	if len(conditions) > 0 {
+		var err error
+		if state, err = e.loadStoreItemConditionState(ctx, logger, nk, userID, conditions...); err != nil {
+			logger.Error("Failed to load store item conditions state for user %s: %v", userID, err)
+			return nil, err
+		}
+	}
+
+	storeItems := make(map[string]*EconomyConfigStoreItem, len(e.config.StoreItems))
+	for itemID, item := range e.config.StoreItems {
+		if offSale[itemID] {
+			continue
+		}
+		if state.met(item.Conditions) {
+			storeItems[itemID] = item
+			continue
+		}
+		if item.Conditions.Hidden {
+			continue
+		}
+		unavailable := *item
+		unavailable.Unavailable = true
+		storeItems[itemID] = &unavailable
+	}
+	return storeItems, nil
+}
+
+// checkStoreItemConditions returns an error unless the store item is on sale and the user meets its conditions.
+func (e *NakamaEconomySystem) checkStoreItemConditions(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, itemID string, storeItem *EconomyConfigStoreItem) error {
+	pl, _ := e.pamlogix.(Pamlogix)
+	if contentCalendarOffSaleItems(pl, time.Now().Unix())[itemID] {
+		return runtime.NewError(fmt.Sprintf("store item %s is not on sale", itemID), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+	if storeItem.Conditions == nil {
+		return nil
+	}
+	state, err := e.loadStoreItemConditionState(ctx, logger, nk, userID, storeItem.Conditions)
+	if err != nil {
+		logger.Error("Failed to load store item conditions state for user %s: %v", userID, err)
+		return err
+	}
+	if !state.met(storeItem.Conditions) {
+		return runtime.NewError(fmt.Sprintf("store item %s conditions not met", itemID), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+	return nil
+}
+
+// storeItemConditionState is the user's progress in other systems which store item conditions are checked against.
+type storeItemConditionState struct {
+	logger runtime.Logger
+	env    *RuleEnv
+}
+
+// loadStoreItemConditionState reads the user's state from only the systems the conditions refer to.
+func (e *NakamaEconomySystem) loadStoreItemConditionState(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, conditions ...*EconomyConfigStoreItemConditions) (*storeItemConditionState, error) {
+	var needs ruleNeeds
+	for _, condition := range conditions {
+		needs.progressions = needs.progressions || len(condition.Progressions) > 0
+		needs.achievements = needs.achievements || len(condition.Achievements) > 0
+		needs.stats = needs.stats || len(condition.MinStats) > 0
+		if condition.Rule != "" {
+			// A rule which doesn't compile isn't met, which is logged when it's checked
+			if rule, err := compiledRule(condition.Rule); err == nil {
+				needs.add(rule.needs)
+			}
+		}
+	}
+
+	pl, _ := e.pamlogix.(Pamlogix)
+	env, err := loadRuleEnv(ctx, logger, nk, pl, userID, needs)
+	if err != nil {
+		return nil, err
+	}
+	return &storeItemConditionState{logger: logger, env: env}, nil
+}
+
+// met reports whether the user meets every one of the conditions.
+func (s *storeItemConditionState) met(conditions *EconomyConfigStoreItemConditions) bool {
+	if conditions == nil {
+		return true
+	}
+	for _, progressionID := range conditions.Progressions {
+		if progression, found := s.env.Progressions[progressionID]; !found || !progression.Unlocked {
+			return false
+		}
+	}
+	for _, achievementID := range conditions.Achievements {
+		if achievement, found := s.env.Achievements[achievementID]; !found || !achievementCompleted(achievement) {
+			return false
+		}
+	}
+	for name, minValue := range conditions.MinStats {
+		stat, found := s.env.Stats.GetPublic()[name]
+		if !found {
+			stat = s.env.Stats.GetPrivate()[name]
+		}
+		if stat.GetValue() < minValue {
+			return false
+		}
+	}
+	if conditions.Rule != "" && !evalRule(s.logger, conditions.Rule, s.env) {
+		return false
+	}
+	return true
+}
+
 // Grant will add currencies, and reward modifiers to a user's economy by ID.
-func (e *NakamaEconomySystem) Grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, modifiers []*RewardModifier, walletMetadata map[string]interface{}) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error) {
+func (e *NakamaEconomySystem) Grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, itemInstances map[string]*RewardInventoryItem, modifiers []*RewardModifier, walletMetadata map[string]interface{}) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error) {
+	return e.grant(ctx, logger, nk, userID, currencies, items, itemInstances, modifiers, walletMetadata, false)
+}
+
+func (e *NakamaEconomySystem) GrantDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, itemInstances map[string]*RewardInventoryItem, modifiers []*RewardModifier, walletMetadata map[string]interface{}) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error) {
+	return e.grant(ctx, logger, nk, userID, currencies, items, itemInstances, modifiers, walletMetadata, true)
+}
+
+// grant makes a Grant, or with dryRun only validates it and returns the wallet it would leave the user with.
+func (e *NakamaEconomySystem) grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, itemInstances map[string]*RewardInventoryItem, modifiers []*RewardModifier, walletMetadata map[string]interface{}, dryRun bool) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error) {
 	if userID == "" {
 		err = runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE)
 		return
@@ -1834,27 +2199,38 @@ func (e *NakamaEconomySystem) Grant(ctx context.Context, logger runtime.Logger,
 	timestamp = time.Now().Unix()
 	reward := &Reward{
 		Currencies:      currencies,
-		Items:           items,
+		Items:           grantItemsWithInstances(items, itemInstances),
+		ItemInstances:   itemInstances,
 		RewardModifiers: modifiers,
 		GrantTimeSec:    timestamp,
 	}
 
-	_, _, _, err = e.RewardGrant(ctx, logger, nk, userID, reward, walletMetadata, false)
+	// Granted currencies aren't capped by max balances, as they include currencies given back to the user
+	idempotencyKey, err := grantIdempotencyKey(walletMetadata)
 	if err != nil {
-		logger.Error("Failed to grant reward: %v", err)
 		return nil, nil, 0, err
 	}
-
-	// Fetch updated wallet
-	account, err := nk.AccountGetId(ctx, userID)
+	switch {
+	case dryRun:
+		_, _, _, err = e.rewardGrant(ctx, logger, nk, userID, reward, walletMetadata, false, false, true, nil)
+	case idempotencyKey != "":
+		_, _, _, err = e.idempotentRewardGrant(ctx, logger, nk, userID, idempotencyKey, reward, walletMetadata, false, false)
+	default:
+		_, _, _, err = e.rewardGrant(ctx, logger, nk, userID, reward, walletMetadata, false, false, false, nil)
+	}
 	if err != nil {
-		logger.Error("Failed to get account: %v", err)
+		logger.Error("Failed to grant reward: %v", err)
 		return nil, nil, 0, err
 	}
-	logger.Info("Updated wallet: %v", account)
-	updatedWallet, err = e.UnmarshalWallet(account)
+
+	// Fetch updated wallet, which the wallet update above has normally cached for this request
+	if dryRun {
+		updatedWallet, err = rewardGrantWallet(ctx, nk, userID, reward.Currencies)
+	} else {
+		updatedWallet, err = userWallet(ctx, nk, userID)
+	}
 	if err != nil {
-		logger.Error("Failed to unmarshal wallet: %v", err)
+		logger.Error("Failed to get wallet: %v", err)
 		return nil, nil, 0, err
 	}
 
@@ -1872,18 +2248,305 @@ func (e *NakamaEconomySystem) Grant(ctx context.Context, logger runtime.Logger,
 	return
 }
 
-func (e *NakamaEconomySystem) UnmarshalWallet(account *api.Account) (wallet map[string]int64, err error) {
-	if account == nil || account.Wallet == "" {
-		return map[string]int64{}, nil
+// Debit takes currencies and items from a user, all or nothing.
+func (e *NakamaEconomySystem) Debit(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, walletMetadata map[string]interface{}) (map[string]int64, error) {
+	return e.debit(ctx, logger, nk, userID, currencies, items, walletMetadata, false)
+}
+
+func (e *NakamaEconomySystem) DebitDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, walletMetadata map[string]interface{}) (map[string]int64, error) {
+	return e.debit(ctx, logger, nk, userID, currencies, items, walletMetadata, true)
+}
+
+// debit makes a Debit, or with dryRun only validates it and returns the wallet it would leave the user with.
+func (e *NakamaEconomySystem) debit(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, walletMetadata map[string]interface{}, dryRun bool) (map[string]int64, error) {
+	if userID == "" {
+		return nil, runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	cost := &Cost{Currencies: currencies, Items: items}
+	if err := cost.validate(); err != nil {
+		return nil, err
+	}
+
+	pl, _ := e.pamlogix.(Pamlogix)
+	if err := chargeCost(ctx, logger, nk, pl, userID, cost, walletMetadata, dryRun); err != nil {
+		logger.Debug("Failed to debit user %s: %v", userID, err)
+		return nil, err
+	}
+
+	var (
+		wallet map[string]int64
+		err    error
+	)
+	if dryRun {
+		wallet, err = rewardGrantWallet(ctx, nk, userID, scaleAmounts(currencies, -1))
+	} else {
+		wallet, err = userWallet(ctx, nk, userID)
 	}
-	fmt.Println()
-	err = json.Unmarshal([]byte(account.Wallet), &wallet)
 	if err != nil {
+		logger.Error("Failed to get wallet: %v", err)
 		return nil, err
 	}
 	return wallet, nil
 }
 
+// currencyGrantCapState is the amount of each currency granted to a user from a source since the cap last reset.
+type currencyGrantCapState struct {
+	ResetTimeSec int64            `json:"reset_time_sec"`
+	Granted      map[string]int64 `json:"granted"`
+}
+
+// RewardGrantCapped grants a reward after clamping its currencies to what remains of the user's daily cap for the
+// source. The cap counter is written together with the grant, and both are tried again when another grant from the
+// same source gets in first.
+func (e *NakamaEconomySystem) RewardGrantCapped(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, source string, reward *Reward, metadata map[string]interface{}) (capped map[string]int64, err error) {
+	if reward == nil {
+		return nil, runtime.NewError("reward is nil", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	var caps map[string]int64
+	if e.config != nil {
+		caps = e.config.CurrencyDailyCaps[source]
+	}
+	if len(caps) == 0 || len(reward.Currencies) == 0 {
+		_, _, _, err = e.RewardGrant(ctx, logger, nk, userID, reward, metadata, false)
+		return nil, err
+	}
+
+	currencies := reward.Currencies
+	for attempt := 0; attempt < currencyGrantCapAttempts; attempt++ {
+		reward.Currencies = currencies
+		var capWrite *runtime.StorageWrite
+		capped, capWrite, err = e.currencyGrantCaps(ctx, logger, nk, userID, source, caps, reward)
+		if err != nil {
+			reward.Currencies = currencies
+			return nil, err
+		}
+
+		grantMetadata := metadata
+		if len(capped) > 0 {
+			grantMetadata = make(map[string]interface{}, len(metadata)+1)
+			for key, value := range metadata {
+				grantMetadata[key] = value
+			}
+			grantMetadata["capped_currencies"] = capped
+		}
+
+		// A rejected cap counter means another grant from the source was written first, and the caps are read again
+		_, _, _, err = e.rewardGrant(ctx, logger, nk, userID, reward, grantMetadata, false, true, false, func(map[string]*InventoryItem, map[string]*InventoryItem, map[string]int64) (*runtime.StorageWrite, error) {
+			return capWrite, nil
+		})
+		if err == nil {
+			if len(capped) > 0 {
+				logger.Info("Capped %s currency grant for user %s: %v", source, userID, capped)
+			}
+			return capped, nil
+		}
+		if !errors.Is(err, runtime.ErrStorageRejectedVersion) {
+			break
+		}
+	}
+
+	reward.Currencies = currencies
+	logger.Error("Failed to grant %s reward with currency caps to user %s: %v", source, userID, err)
+	return nil, runtime.NewError("Failed to grant reward", INTERNAL_ERROR_CODE) // INTERNAL
+}
+
+// currencyGrantCaps clamps the currencies in a reward to what remains of the user's daily cap for the source, and
+// returns the conditional write which counts the allowed amounts towards the cap.
+func (e *NakamaEconomySystem) currencyGrantCaps(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, source string, caps map[string]int64, reward *Reward) (capped map[string]int64, write *runtime.StorageWrite, err error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: currencyGrantCapsCollection,
+			Key:        source,
+			UserID:     userID,
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to read currency grant caps: %v", err)
+		return nil, nil, ErrInternal
+	}
+
+	state := &currencyGrantCapState{}
+	version := storageLockVersionNone
+	if len(objects) > 0 {
+		if err := json.Unmarshal([]byte(objects[0].Value), state); err != nil {
+			logger.Error("Failed to unmarshal currency grant caps: %v", err)
+			return nil, nil, ErrInternal
+		}
+		version = objects[0].Version
+	}
+
+	now := time.Now().UTC()
+	if state.ResetTimeSec <= now.Unix() || state.Granted == nil {
+		state.ResetTimeSec = nextUTCDay(now)
+		state.Granted = make(map[string]int64)
+	}
+
+	allowed, capped := clampCurrencyGrant(caps, state.Granted, reward.Currencies)
+	for currencyID, amount := range allowed {
+		if _, ok := caps[currencyID]; ok && amount > 0 {
+			state.Granted[currencyID] += amount
+		}
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		logger.Error("Failed to marshal currency grant caps: %v", err)
+		return nil, nil, ErrInternal
+	}
+
+	reward.Currencies = allowed
+	return capped, &runtime.StorageWrite{
+		Collection:      currencyGrantCapsCollection,
+		Key:             source,
+		UserID:          userID,
+		Value:           string(data),
+		Version:         version,
+		PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}, nil
+}
+
+// ListCurrencies returns the display metadata and balance caps of the currencies, keyed by currency ID.
+func (e *NakamaEconomySystem) ListCurrencies(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (map[string]*EconomyConfigCurrency, error) {
+	currencies := make(map[string]*EconomyConfigCurrency)
+	if e.config == nil {
+		return currencies, nil
+	}
+	for currencyID, currency := range e.config.Currencies {
+		if currency != nil {
+			currencies[currencyID] = currency
+		}
+	}
+	return currencies, nil
+}
+
+// applyCurrencyMaxBalances clamps the currencies in a reward so no balance goes over the max balance of its currency.
+func (e *NakamaEconomySystem) applyCurrencyMaxBalances(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward) error {
+	if e.config == nil || len(e.config.Currencies) == 0 || len(reward.Currencies) == 0 {
+		return nil
+	}
+	maxBalances := make(map[string]int64)
+	for currencyID, amount := range reward.Currencies {
+		if currency := e.config.Currencies[currencyID]; currency != nil && currency.MaxBalance > 0 && amount > 0 {
+			maxBalances[currencyID] = currency.MaxBalance
+		}
+	}
+	if len(maxBalances) == 0 {
+		return nil
+	}
+
+	wallet, err := userWallet(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to read wallet: %v", err)
+		return ErrInternal
+	}
+
+	allowed, capped := clampCurrencyGrant(maxBalances, wallet, reward.Currencies)
+	reward.Currencies = allowed
+	if len(capped) > 0 {
+		logger.Info("Capped currency grant at max balance for user %s: %v", userID, capped)
+	}
+	return nil
+}
+
+// rewardGrantWallet returns the wallet the user would have after the currencies are granted, without updating it.
+// Deductions the wallet can't cover return ErrCurrencyInsufficient.
+func rewardGrantWallet(ctx context.Context, nk runtime.NakamaModule, userID string, currencies map[string]int64) (map[string]int64, error) {
+	wallet, err := userWallet(ctx, nk, userID)
+	if err != nil {
+		return nil, err
+	}
+	for currencyID, amount := range currencies {
+		wallet[currencyID] += amount
+		if wallet[currencyID] < 0 {
+			return nil, ErrCurrencyInsufficient
+		}
+	}
+	return wallet, nil
+}
+
+// clampCurrencyGrant limits each requested currency amount to what remains of its cap after the amounts already granted.
+// It returns the amounts allowed and how much of each request was cut off. Currencies without a cap and deductions pass
+// through unchanged.
+func clampCurrencyGrant(caps, granted, requested map[string]int64) (allowed, capped map[string]int64) {
+	allowed = make(map[string]int64, len(requested))
+	capped = make(map[string]int64)
+	for currencyID, amount := range requested {
+		limit, ok := caps[currencyID]
+		if !ok || amount <= 0 {
+			allowed[currencyID] = amount
+			continue
+		}
+
+		remaining := limit - granted[currencyID]
+		if remaining < 0 {
+			remaining = 0
+		}
+		if amount > remaining {
+			capped[currencyID] = amount - remaining
+			amount = remaining
+		}
+		if amount > 0 {
+			allowed[currencyID] = amount
+		}
+	}
+	return allowed, capped
+}
+
+// routeRewardItemsToUnlockables returns a copy of the reward without the items that were placed in the user's
+// unlockables because their inventory config routes grants from the source there.
+func (e *NakamaEconomySystem) routeRewardItemsToUnlockables(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, source string, reward *Reward) (*Reward, error) {
+	pl, ok := e.pamlogix.(Pamlogix)
+	if !ok || reward == nil || len(reward.Items) == 0 {
+		return reward, nil
+	}
+
+	remaining, err := routeItemsToUnlockables(ctx, logger, nk, pl, userID, source, reward.Items)
+	if err != nil {
+		return nil, err
+	}
+	if maps.Equal(remaining, reward.Items) {
+		return reward, nil
+	}
+
+	routedReward := proto.Clone(reward).(*Reward)
+	routedReward.Items = remaining
+	for itemID := range routedReward.ItemInstances {
+		if _, found := remaining[itemID]; !found {
+			delete(routedReward.ItemInstances, itemID)
+		}
+	}
+	return routedReward, nil
+}
+
+// grantItemsWithInstances merges item instance specs into the item counts to grant. Items already present in the counts
+// keep their count, otherwise the instance count is used, defaulting to a single item.
+func grantItemsWithInstances(items map[string]int64, itemInstances map[string]*RewardInventoryItem) map[string]int64 {
+	if len(itemInstances) == 0 {
+		return items
+	}
+
+	merged := make(map[string]int64, len(items)+len(itemInstances))
+	for itemID, count := range items {
+		merged[itemID] = count
+	}
+	for itemID, instance := range itemInstances {
+		if _, found := merged[itemID]; found || instance == nil {
+			continue
+		}
+		count := instance.Count
+		if count <= 0 {
+			count = 1
+		}
+		merged[itemID] = count
+	}
+	return merged
+}
+
+func (e *NakamaEconomySystem) UnmarshalWallet(account *api.Account) (wallet map[string]int64, err error) {
+	return unmarshalAccountWallet(account)
+}
+
 /*
 PurchaseIntent should prepare and possibly reserve a store item for purchase by a user.
 
@@ -1919,6 +2582,10 @@ func (e *NakamaEconomySystem) PurchaseIntent(ctx context.Context, logger runtime
 		return runtime.NewError(fmt.Sprintf("store item %s is disabled", itemID), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
 	}
 
+	if err := e.checkStoreItemConditions(ctx, logger, nk, userID, itemID, storeItem); err != nil {
+		return err
+	}
+
 	// Check if the SKU is valid for this item
 	if storeItem.Cost != nil && storeItem.Cost.Sku != "" && storeItem.Cost.Sku != sku {
 		return runtime.NewError(fmt.Sprintf("invalid SKU for item %s", itemID), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
@@ -1929,10 +2596,10 @@ func (e *NakamaEconomySystem) PurchaseIntent(ctx context.Context, logger runtime
 	purchaseIntent := map[string]interface{}{
 		"user_id":     userID,
 		"item_id":     itemID,
-		"store_type":  string(store),
+		"store_type":  store.String(),
 		"sku":         sku,
 		"created_at":  now,
-		"expires_at":  now + 3600, // Intent expires in 1 hour
+		"expires_at":  now + purchaseIntentExpirySec,
 		"status":      "pending",
 		"price":       0,  // Default price
 		"currency":    "", // Default currency
@@ -1959,7 +2626,7 @@ func (e *NakamaEconomySystem) PurchaseIntent(ctx context.Context, logger runtime
 	intentKey := fmt.Sprintf("purchase_intent:%s:%s", userID, itemID)
 	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
 		{
-			Collection:      "purchase_intents",
+			Collection:      purchaseIntentsCollection,
 			Key:             intentKey,
 			UserID:          userID,
 			Value:           string(intentData),
@@ -1978,17 +2645,214 @@ func (e *NakamaEconomySystem) PurchaseIntent(ctx context.Context, logger runtime
 	return nil
 }
 
+// CanAfford checks the cost with the same rules the systems charge costs with, so clients can validate a purchase,
+// auction listing or event entry up front.
+func (e *NakamaEconomySystem) CanAfford(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, cost *Cost) (missing *Cost, err error) {
+	if userID == "" {
+		return nil, runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	if err := cost.validate(); err != nil {
+		return nil, err
+	}
+
+	pl, _ := e.pamlogix.(Pamlogix)
+	return checkCost(ctx, logger, nk, pl, userID, cost)
+}
+
+// PurchaseIntentCancel removes a user's pending purchase intent for a store item.
+func (e *NakamaEconomySystem) PurchaseIntentCancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, itemID string) (err error) {
+	if userID == "" {
+		return runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	if itemID == "" {
+		return runtime.NewError("item ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	intentKey := fmt.Sprintf("purchase_intent:%s:%s", userID, itemID)
+	intentObjs, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: purchaseIntentsCollection,
+			Key:        intentKey,
+			UserID:     userID,
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to read purchase intent: %v", err)
+		return ErrInternal
+	}
+	if len(intentObjs) == 0 {
+		return runtime.NewError(fmt.Sprintf("purchase intent for item %s not found", itemID), NOT_FOUND_ERROR_CODE) // NOT_FOUND
+	}
+
+	var purchaseIntent map[string]interface{}
+	if err := json.Unmarshal([]byte(intentObjs[0].Value), &purchaseIntent); err != nil {
+		logger.Error("Failed to unmarshal purchase intent: %v", err)
+		return ErrInternal
+	}
+
+	if isConsumed, ok := purchaseIntent["is_consumed"].(bool); ok && isConsumed {
+		return runtime.NewError("purchase intent already consumed", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+
+	if err := e.removePurchaseIntent(ctx, nk, intentObjs[0]); err != nil {
+		logger.Error("Failed to cancel purchase intent: %v", err)
+		return ErrInternal
+	}
+
+	logger.Info("Cancelled purchase intent for user %s, item %s", userID, itemID)
+	return nil
+}
+
+// PurchaseIntentsCleanup removes expired purchase intents across all users, and returns the number of intents removed.
+func (e *NakamaEconomySystem) PurchaseIntentsCleanup(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	removedCount, err := sweepExpiredStorage(ctx, logger, nk, e.purchaseIntentsTTLRule(), time.Now().Unix())
+	if err != nil {
+		logger.Error("Failed to list purchase intents for cleanup: %v", err)
+		return removedCount, ErrInternal
+	}
+
+	logger.Info("Removed %d expired purchase intents", removedCount)
+	return removedCount, nil
+}
+
+// CompactModifiers removes the ended modifiers from every user's active modifiers. Modifiers which have all ended are
+// left for the storage sweep to delete. Writes are conditional on the version listed, so modifiers granted during the
+// compaction are kept and compacted next time.
+func (e *NakamaEconomySystem) CompactModifiers(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	now := time.Now().Unix()
+	compacted := 0
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageList(ctx, "", "", userModifiersStorageCollection, storageSweepBatchSize, cursor)
+		if err != nil {
+			logger.Error("Failed to list modifiers for compaction: %v", err)
+			return compacted, ErrInternal
+		}
+
+		writes := make([]*runtime.StorageWrite, 0)
+		for _, object := range objects {
+			var modifiers []*ActiveRewardModifier
+			if err := json.Unmarshal([]byte(object.Value), &modifiers); err != nil {
+				logger.Debug("Skipped compacting modifiers %s of user %s: %v", object.Key, object.UserId, err)
+				continue
+			}
+			active := make([]*ActiveRewardModifier, 0, len(modifiers))
+			for _, modifier := range modifiers {
+				if modifier.EndTimeSec == 0 || modifier.EndTimeSec > now {
+					active = append(active, modifier)
+				}
+			}
+			if len(active) == len(modifiers) || len(active) == 0 {
+				continue
+			}
+			data, err := json.Marshal(active)
+			if err != nil {
+				return compacted, ErrInternal
+			}
+			writes = append(writes, &runtime.StorageWrite{
+				Collection:      userModifiersStorageCollection,
+				Key:             object.Key,
+				UserID:          object.UserId,
+				Value:           string(data),
+				Version:         object.Version,
+				PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+				PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
+			})
+		}
+		compacted += writeStorageBatch(ctx, logger, nk, writes)
+
+		if nextCursor == "" || nextCursor == cursor {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	if compacted > 0 {
+		logger.Info("Compacted the modifiers of %d users", compacted)
+	}
+	return compacted, nil
+}
+
+// storageTTLRules expires purchase intents, placement statuses and modifiers which have all ended.
+func (e *NakamaEconomySystem) storageTTLRules() []*storageTTLRule {
+	return []*storageTTLRule{
+		e.purchaseIntentsTTLRule(),
+		{
+			Name:        "placement statuses",
+			Collection:  placementStatusStorageCollection,
+			ExpiryField: "timestamp",
+			TTLSec:      e.placementStatusTTLSec(),
+		},
+		{
+			Name:        "modifiers",
+			Collection:  userModifiersStorageCollection,
+			ExpiryField: "end_time_sec",
+		},
+	}
+}
+
+// purchaseIntentsTTLRule expires purchase intents.
+func (e *NakamaEconomySystem) purchaseIntentsTTLRule() *storageTTLRule {
+	return &storageTTLRule{
+		Name:        "purchase intents",
+		Collection:  purchaseIntentsCollection,
+		ExpiryField: "expires_at",
+	}
+}
+
+// placementStatusTTLSec is how long a placement status is kept after it was last updated: a week, or the longest
+// placement cooldown if that's longer, so statuses aren't deleted while they still limit the user.
+func (e *NakamaEconomySystem) placementStatusTTLSec() int64 {
+	ttlSec := int64(placementStatusTTLSec)
+	for _, placement := range e.config.Placements {
+		if placement != nil {
+			ttlSec = max(ttlSec, placement.CooldownSec)
+		}
+	}
+	return ttlSec
+}
+
+// removePurchaseIntent deletes a purchase intent, provided it hasn't changed since it was read.
+func (e *NakamaEconomySystem) removePurchaseIntent(ctx context.Context, nk runtime.NakamaModule, obj *api.StorageObject) error {
+	return nk.StorageDelete(ctx, []*runtime.StorageDelete{
+		{
+			Collection: purchaseIntentsCollection,
+			Key:        obj.Key,
+			UserID:     obj.UserId,
+			Version:    obj.Version,
+		},
+	})
+}
+
+// purchaseIntentExpired reports whether a purchase intent's expiry time has passed. Intents without an expiry never expire.
+func purchaseIntentExpired(purchaseIntent map[string]interface{}, currentTime int64) bool {
+	expiresAt, ok := purchaseIntent["expires_at"].(float64)
+	if !ok || expiresAt <= 0 {
+		return false
+	}
+	return int64(expiresAt) <= currentTime
+}
+
 //TODO: test later when we have a real store
 /*
 PurchaseItem validates a purchase and gives the user the appropriate rewards.
 
 - It validates the purchase receipt or transaction with the appropriate store provider (Apple, Google, etc.)
 
+- It checks the validated product ID against the store item's configured SKU, flagging the account on a mismatch.
+
 - Upon successful validation, it grants the user the purchased items, currencies, or rewards.
 
 - It updates the user's inventory and wallet, and returns the updated state.
 
 - If the purchase is invalid, it does not grant any rewards and returns an error.
+
+- If granting the rewards of a valid purchase fails, the grant is kept pending and retried, and
+ErrEconomyPurchaseGrantPending is returned.
+
+- Store items priced only in virtual currencies, with no SKU, need no receipt: their cost is taken from the user's
+wallet instead, failing with ErrCurrencyInsufficient if they can't afford it.
 */
 func (e *NakamaEconomySystem) PurchaseItem(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, userID, itemID string, store EconomyStoreType, receipt string) (updatedWallet map[string]int64, updatedInventory *Inventory, reward *Reward, isSandboxPurchase bool, err error) {
 	if userID == "" {
@@ -1999,10 +2863,6 @@ func (e *NakamaEconomySystem) PurchaseItem(ctx context.Context, logger runtime.L
 		return nil, nil, nil, false, runtime.NewError("item ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
 
-	if receipt == "" {
-		return nil, nil, nil, false, runtime.NewError("receipt is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
-	}
-
 	// Initialize return values
 	updatedWallet = make(map[string]int64)
 	isSandboxPurchase = false
@@ -2026,11 +2886,15 @@ func (e *NakamaEconomySystem) PurchaseItem(ctx context.Context, logger runtime.L
 		return nil, nil, nil, false, runtime.NewError(fmt.Sprintf("store item %s is disabled", itemID), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
 	}
 
+	if err := e.checkStoreItemConditions(ctx, logger, nk, userID, itemID, storeItem); err != nil {
+		return nil, nil, nil, false, err
+	}
+
 	// Check for a purchase intent
 	intentKey := fmt.Sprintf("purchase_intent:%s:%s", userID, itemID)
 	intentObjs, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
-			Collection: "purchase_intents",
+			Collection: purchaseIntentsCollection,
 			Key:        intentKey,
 			UserID:     userID,
 		},
@@ -2048,9 +2912,28 @@ func (e *NakamaEconomySystem) PurchaseItem(ctx context.Context, logger runtime.L
 			if ok && isConsumed {
 				return nil, nil, nil, false, runtime.NewError("purchase intent already consumed", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
 			}
+
+			// Expired intents are left for PurchaseIntentsCleanup to remove
+			if purchaseIntentExpired(purchaseIntent, time.Now().Unix()) {
+				return nil, nil, nil, false, runtime.NewError("purchase intent expired", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+			}
 		}
 	}
 
+	// Store items priced only in virtual currencies are paid from the wallet rather than with a receipt
+	if storeItem.Cost != nil && storeItem.Cost.Sku == "" && len(storeItem.Cost.Currencies) > 0 {
+		var intentVersion string
+		if purchaseIntent != nil {
+			intentVersion = intentObjs[0].Version
+		}
+		updatedWallet, updatedInventory, reward, err = e.purchaseItemWithCurrencies(ctx, logger, nk, userID, "", itemID, storeItem, intentVersion, purchaseIntent)
+		return updatedWallet, updatedInventory, reward, false, err
+	}
+
+	if receipt == "" {
+		return nil, nil, nil, false, runtime.NewError("receipt is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
 	// Validate the receipt with the appropriate store using Nakama's built-in validation
 	var validationResponse *api.ValidatePurchaseResponse
 	var validPurchase bool
@@ -2073,6 +2956,14 @@ func (e *NakamaEconomySystem) PurchaseItem(ctx context.Context, logger runtime.L
 		}
 		validPurchase = validationResponse != nil && len(validationResponse.ValidatedPurchases) > 0
 
+	case EconomyStoreType_ECONOMY_STORE_TYPE_STEAM, EconomyStoreType_ECONOMY_STORE_TYPE_PLAYSTATION, EconomyStoreType_ECONOMY_STORE_TYPE_XBOX:
+		// Console and PC stores are validated by the validator set for the store
+		validationResponse, err = e.validateStorePurchase(ctx, logger, nk, userID, store, receipt)
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+		validPurchase = true
+
 	default:
 		return nil, nil, nil, false, runtime.NewError(fmt.Sprintf("unsupported store type: %s", store), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
@@ -2081,57 +2972,63 @@ func (e *NakamaEconomySystem) PurchaseItem(ctx context.Context, logger runtime.L
 		return nil, nil, nil, false, runtime.NewError("invalid receipt", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
 
+	// Make sure the receipt is for the product configured on this store item, so a receipt for a cheaper
+	// product can't be used to claim a more expensive one.
+	if storeItem.Cost == nil || storeItem.Cost.Sku == "" {
+		return nil, nil, nil, false, runtime.NewError(fmt.Sprintf("store item %s has no sku configured", itemID), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+	validatedPurchase := findValidatedPurchase(validationResponse.ValidatedPurchases, storeItem.Cost.Sku)
+	if validatedPurchase == nil {
+		e.flagPurchaseMismatch(ctx, logger, nk, userID, itemID, store, storeItem.Cost.Sku, validationResponse.ValidatedPurchases)
+		return nil, nil, nil, false, runtime.NewError("receipt does not match store item", PERMISSION_DENIED_ERROR_CODE) // PERMISSION_DENIED
+	}
+
 	// Check if the purchase was made in a sandbox environment
-	if validationResponse != nil && len(validationResponse.ValidatedPurchases) > 0 {
-		isSandboxPurchase = validationResponse.ValidatedPurchases[0].Environment == api.StoreEnvironment_SANDBOX
+	isSandboxPurchase = validatedPurchase.Environment == api.StoreEnvironment_SANDBOX
+	sandboxMode := e.sandboxPurchasesMode()
+	if isSandboxPurchase && sandboxMode == EconomySandboxPurchasesDeny {
+		logger.Warn("Rejected sandbox purchase of %s for user %s", itemID, userID)
+		return nil, nil, nil, true, runtime.NewError("sandbox purchases are not accepted", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
 	}
+	isolateSandbox := isSandboxPurchase && sandboxMode == EconomySandboxPurchasesIsolate
 
-	// Mark intent as consumed if it exists
+	// Mark intent as consumed if it exists. Failing to is logged, as the receipt has already been paid for
 	if purchaseIntent != nil {
-		purchaseIntent["is_consumed"] = true
-		purchaseIntent["verified_at"] = time.Now().Unix()
+		if err := consumePurchaseIntent(ctx, nk, userID, itemID, intentObjs[0].Version, purchaseIntent); err != nil {
+			logger.Error("Failed to update purchase intent: %v", err)
+		}
+	}
 
-		intentData, err := json.Marshal(purchaseIntent)
+	// Roll the reward up front so the transaction records exactly what was granted
+	if storeItem.Reward != nil {
+		reward, err = e.RewardRoll(ctx, logger, nk, userID, storeItem.Reward)
 		if err != nil {
-			logger.Error("Failed to marshal updated purchase intent: %v", err)
-			// Continue anyway as this is not critical
-		} else {
-			_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-				{
-					Collection:      "purchase_intents",
-					Key:             intentKey,
-					UserID:          userID,
-					Value:           string(intentData),
-					Version:         intentObjs[0].Version,
-					PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ, // Only owner can read
-					PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,   // Only server can write
-				},
-			})
-			if err != nil {
-				logger.Error("Failed to update purchase intent: %v", err)
-				// Continue anyway as this is not critical
-			}
+			logger.Error("Failed to roll reward: %v", err)
+			return nil, nil, nil, isSandboxPurchase, runtime.NewError("failed to generate reward", INTERNAL_ERROR_CODE) // INTERNAL
 		}
 	}
 
 	// Record the purchase transaction
-	transactionID := uuid.New().String()
+	transactionID := e.newID()
 	transaction := map[string]interface{}{
 		"id":            transactionID,
 		"user_id":       userID,
 		"item_id":       itemID,
-		"store_type":    string(store),
+		"store_type":    store.String(),
 		"receipt":       receipt,
+		"receipt_hash":  hashReceipt(receipt),
 		"timestamp":     time.Now().Unix(),
 		"sandbox":       isSandboxPurchase,
 		"validation":    validationResponse,
 		"intent_exists": purchaseIntent != nil,
+		"reward":        reward,
+		"isolated":      isolateSandbox,
 	}
 
 	transactionData, _ := json.Marshal(transaction)
 	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
 		{
-			Collection:      "purchase_transactions",
+			Collection:      purchaseTransactionsCollection,
 			Key:             transactionID,
 			UserID:          userID,
 			Value:           string(transactionData),
@@ -2145,34 +3042,50 @@ func (e *NakamaEconomySystem) PurchaseItem(ctx context.Context, logger runtime.L
 		// Continue anyway as we still want to grant the rewards
 	}
 
-	// Grant the rewards from the store item
-	if storeItem.Reward != nil {
-		// Roll the reward
-		reward, err = e.RewardRoll(ctx, logger, nk, userID, storeItem.Reward)
+	// Sandbox purchases in isolate mode only touch the test wallet and inventory
+	if isolateSandbox {
+		if reward == nil {
+			return map[string]int64{}, &Inventory{Items: make(map[string]*InventoryItem)}, nil, true, nil
+		}
+		balance, err := e.grantSandboxReward(ctx, nk, userID, reward)
 		if err != nil {
-			logger.Error("Failed to roll reward: %v", err)
-			return nil, nil, nil, isSandboxPurchase, runtime.NewError("failed to generate reward", INTERNAL_ERROR_CODE) // INTERNAL
+			logger.Error("Failed to grant sandbox reward: %v", err)
+			return nil, nil, nil, true, runtime.NewError("failed to grant reward", INTERNAL_ERROR_CODE) // INTERNAL
 		}
+		return balance.Wallet, sandboxBalanceInventory(balance), reward, true, nil
+	}
 
-		// Grant the reward to the user
-		metadata := map[string]interface{}{
+	// The purchase is logged on its own, and what it grants is logged as a grant carrying the transaction ID
+	e.logEvents(ctx, logger, nk, &EconomyEvent{
+		Type:   EconomyEventTypePurchase,
+		UserId: userID,
+		Metadata: map[string]interface{}{
 			"transaction_id": transactionID,
 			"item_id":        itemID,
-			"store_type":     string(store),
-		}
+			"store_type":     store.String(),
+			"sandbox":        isSandboxPurchase,
+		},
+	})
 
-		newItems, updatedItems, _, err := e.RewardGrant(ctx, logger, nk, userID, reward, metadata, false)
+	// Grant the rewards from the store item
+	if storeItem.Reward != nil {
+		// The user has paid, so a grant which fails is kept pending and retried rather than lost
+		newItems, updatedItems, err := e.grantPurchaseReward(ctx, logger, nk, userID, &EconomyPendingGrant{
+			TransactionId: transactionID,
+			ItemId:        itemID,
+			StoreType:     store.String(),
+			Reward:        reward,
+			CreateTimeSec: time.Now().Unix(),
+		})
 		if err != nil {
 			logger.Error("Failed to grant reward: %v", err)
-			return nil, nil, nil, isSandboxPurchase, runtime.NewError("failed to grant reward", INTERNAL_ERROR_CODE) // INTERNAL
+			return nil, nil, nil, isSandboxPurchase, ErrEconomyPurchaseGrantPending
 		}
 
 		// Get updated wallet
-		account, err := nk.AccountGetId(ctx, userID)
+		updatedWallet, err = userWallet(ctx, nk, userID)
 		if err != nil {
-			logger.Error("Failed to get account: %v", err)
-		} else {
-			updatedWallet, _ = e.UnmarshalWallet(account)
+			logger.Error("Failed to get wallet: %v", err)
 		}
 
 		// Construct updated inventory from new and updated items
@@ -2189,14 +3102,153 @@ func (e *NakamaEconomySystem) PurchaseItem(ctx context.Context, logger runtime.L
 		for k, v := range updatedItems {
 			updatedInventory.Items[k] = v
 		}
+	}
+
+	return updatedWallet, updatedInventory, reward, isSandboxPurchase, nil
+}
+
+// donationLockName is the storage lock guarding a user's donation record.
+func donationLockName(userID, donationID string) string {
+	return fmt.Sprintf("%s:%s:%s", donationsStorageCollection, userID, donationID)
+}
+
+// sandboxPurchasesMode returns how sandbox purchases are handled, allowing them when it isn't configured.
+func (e *NakamaEconomySystem) sandboxPurchasesMode() string {
+	if e.config == nil {
+		return EconomySandboxPurchasesAllow
+	}
+	switch e.config.SandboxPurchases {
+	case EconomySandboxPurchasesIsolate, EconomySandboxPurchasesDeny:
+		return e.config.SandboxPurchases
+	default:
+		return EconomySandboxPurchasesAllow
+	}
+}
+
+// grantSandboxReward adds the currencies and items of a reward to the user's sandbox balance, which is kept apart
+// from their real wallet and inventory.
+func (e *NakamaEconomySystem) grantSandboxReward(ctx context.Context, nk runtime.NakamaModule, userID string, reward *Reward) (*EconomySandboxBalance, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: sandboxStorageCollection,
+			Key:        sandboxBalanceStorageKey,
+			UserID:     userID,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// Trigger any custom reward handlers
-		if e.onStoreItemReward != nil {
-			e.onStoreItemReward(ctx, logger, nk, userID, itemID, storeItem, storeItem.Reward, reward)
+	balance := &EconomySandboxBalance{}
+	var version string
+	if len(objects) > 0 {
+		if err := json.Unmarshal([]byte(objects[0].Value), balance); err != nil {
+			return nil, err
 		}
+		version = objects[0].Version
+	}
+	if balance.Wallet == nil {
+		balance.Wallet = make(map[string]int64)
+	}
+	if balance.Items == nil {
+		balance.Items = make(map[string]int64)
 	}
 
-	return updatedWallet, updatedInventory, reward, isSandboxPurchase, nil
+	for currencyID, amount := range reward.Currencies {
+		balance.Wallet[currencyID] += amount
+	}
+	for itemID, count := range reward.Items {
+		balance.Items[itemID] += count
+	}
+
+	data, err := json.Marshal(balance)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      sandboxStorageCollection,
+			Key:             sandboxBalanceStorageKey,
+			UserID:          userID,
+			Value:           string(data),
+			Version:         version,
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	return balance, nil
+}
+
+func sandboxBalanceInventory(balance *EconomySandboxBalance) *Inventory {
+	inventory := &Inventory{
+		Items: make(map[string]*InventoryItem, len(balance.Items)),
+	}
+	for itemID, count := range balance.Items {
+		inventory.Items[itemID] = &InventoryItem{
+			Id:    itemID,
+			Count: count,
+		}
+	}
+	return inventory
+}
+
+// findValidatedPurchase returns the validated purchase matching the given product ID, or nil if there is none.
+func findValidatedPurchase(purchases []*api.ValidatedPurchase, productID string) *api.ValidatedPurchase {
+	for _, purchase := range purchases {
+		if purchase != nil && purchase.ProductId == productID {
+			return purchase
+		}
+	}
+	return nil
+}
+
+// flagPurchaseMismatch records a receipt whose validated products don't match the store item being purchased, so
+// the account can be reviewed later. Failures to write the flag are logged but don't change the outcome.
+func (e *NakamaEconomySystem) flagPurchaseMismatch(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, itemID string, store EconomyStoreType, expectedSku string, purchases []*api.ValidatedPurchase) {
+	productIDs := make([]string, 0, len(purchases))
+	transactionIDs := make([]string, 0, len(purchases))
+	for _, purchase := range purchases {
+		if purchase == nil {
+			continue
+		}
+		productIDs = append(productIDs, purchase.ProductId)
+		transactionIDs = append(transactionIDs, purchase.TransactionId)
+	}
+
+	logger.Warn("Purchase receipt for user %s does not match store item %s: expected sku %s, got %v", userID, itemID, expectedSku, productIDs)
+
+	flag := map[string]interface{}{
+		"reason":          "sku_mismatch",
+		"user_id":         userID,
+		"item_id":         itemID,
+		"store_type":      store.String(),
+		"expected_sku":    expectedSku,
+		"product_ids":     productIDs,
+		"transaction_ids": transactionIDs,
+		"timestamp":       time.Now().Unix(),
+	}
+	flagData, err := json.Marshal(flag)
+	if err != nil {
+		logger.Error("Failed to marshal purchase flag: %v", err)
+		return
+	}
+
+	if _, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      purchaseFlagsStorageCollection,
+			Key:             e.newID(),
+			UserID:          userID,
+			Value:           string(flagData),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	}); err != nil {
+		logger.Error("Failed to store purchase flag: %v", err)
+	}
 }
 
 //TODO: test later when we have a real store
@@ -2278,6 +3330,17 @@ func (e *NakamaEconomySystem) PurchaseRestore(ctx context.Context, logger runtim
 				}
 			}
 
+		case EconomyStoreType_ECONOMY_STORE_TYPE_STEAM, EconomyStoreType_ECONOMY_STORE_TYPE_PLAYSTATION, EconomyStoreType_ECONOMY_STORE_TYPE_XBOX:
+			validationResponse, err = e.validateStorePurchase(ctx, logger, nk, userID, store, receipt)
+			if err != nil {
+				logger.Error("Failed to validate %s receipt: %v", store, err)
+				continue
+			}
+
+			validPurchase = true
+			transactionID = validationResponse.ValidatedPurchases[0].TransactionId
+			productID = validationResponse.ValidatedPurchases[0].ProductId
+
 		default:
 			logger.Error("Unsupported store type for restore: %s", store)
 			continue
@@ -2288,6 +3351,12 @@ func (e *NakamaEconomySystem) PurchaseRestore(ctx context.Context, logger runtim
 			continue
 		}
 
+		// Sandbox receipts are only restored when sandbox purchases grant real rewards
+		if validationResponse.ValidatedPurchases[0].Environment == api.StoreEnvironment_SANDBOX && e.sandboxPurchasesMode() != EconomySandboxPurchasesAllow {
+			logger.Warn("Skip sandbox receipt during restore for user %s", userID)
+			continue
+		}
+
 		// Skip if we've already processed this transaction
 		if transactionID != "" && existingTransactions[transactionID] {
 			logger.Info("Skip already processed transaction during restore: %s", transactionID)
@@ -2297,13 +3366,9 @@ func (e *NakamaEconomySystem) PurchaseRestore(ctx context.Context, logger runtim
 		// Find the corresponding store item if possible
 		var storeItem *EconomyConfigStoreItem
 		if productID != "" && e.config != nil && e.config.StoreItems != nil {
-			for itemID, item := range e.config.StoreItems {
-				// Check if this item has the matching SKU
-				if item.Cost != nil && item.Cost.Sku == productID {
-					storeItem = item
-					productID = itemID // Use our internal item ID
-					break
-				}
+			if itemID, found := e.storeItemIDsBySku[productID]; found {
+				storeItem = e.config.StoreItems[itemID]
+				productID = itemID // Use our internal item ID
 			}
 		}
 
@@ -2314,13 +3379,14 @@ func (e *NakamaEconomySystem) PurchaseRestore(ctx context.Context, logger runtim
 		}
 
 		// Record the restored purchase
-		restoreID := uuid.New().String()
+		restoreID := e.newID()
 		restoreRecord := map[string]interface{}{
 			"id":              restoreID,
 			"user_id":         userID,
 			"item_id":         productID,
-			"store_type":      string(store),
+			"store_type":      store.String(),
 			"receipt":         receipt,
+			"receipt_hash":    hashReceipt(receipt),
 			"timestamp":       time.Now().Unix(),
 			"transaction_id":  transactionID,
 			"validation":      validationResponse,
@@ -2330,7 +3396,7 @@ func (e *NakamaEconomySystem) PurchaseRestore(ctx context.Context, logger runtim
 		restoreData, _ := json.Marshal(restoreRecord)
 		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
 			{
-				Collection:      "purchase_transactions",
+				Collection:      purchaseTransactionsCollection,
 				Key:             restoreID,
 				UserID:          userID,
 				Value:           string(restoreData),
@@ -2353,26 +3419,23 @@ func (e *NakamaEconomySystem) PurchaseRestore(ctx context.Context, logger runtim
 				continue
 			}
 
-			// Grant the reward to the user
-			metadata := map[string]interface{}{
-				"transaction_id": restoreID,
-				"item_id":        productID,
-				"store_type":     string(store),
-				"restored":       true,
-				"original_id":    transactionID,
-			}
-
-			_, _, _, err = e.RewardGrant(ctx, logger, nk, userID, reward, metadata, false)
+			// Grant the reward to the user, keeping it pending to be retried if the grant fails
+			_, _, err = e.grantPurchaseReward(ctx, logger, nk, userID, &EconomyPendingGrant{
+				TransactionId: restoreID,
+				ItemId:        productID,
+				StoreType:     store.String(),
+				Reward:        reward,
+				Metadata: map[string]interface{}{
+					"restored":    true,
+					"original_id": transactionID,
+				},
+				CreateTimeSec: time.Now().Unix(),
+			})
 			if err != nil {
 				logger.Error("Failed to grant reward for restore: %v", err)
 				continue
 			}
 
-			// Trigger any custom reward handlers
-			if e.onStoreItemReward != nil {
-				e.onStoreItemReward(ctx, logger, nk, userID, productID, storeItem, storeItem.Reward, reward)
-			}
-
 			logger.Info("Successfully restored purchase: %s for user: %s", transactionID, userID)
 		}
 	}
@@ -2380,12 +3443,189 @@ func (e *NakamaEconomySystem) PurchaseRestore(ctx context.Context, logger runtim
 	return nil
 }
 
+// purchaseTransactionRecord is the stored form of a purchase or restore transaction.
+type purchaseTransactionRecord struct {
+	Id             string          `json:"id"`
+	UserId         string          `json:"user_id"`
+	ItemId         string          `json:"item_id"`
+	StoreType      string          `json:"store_type"`
+	Receipt        string          `json:"receipt"`
+	ReceiptHash    string          `json:"receipt_hash"`
+	Timestamp      int64           `json:"timestamp"`
+	Sandbox        bool            `json:"sandbox"`
+	TransactionId  string          `json:"transaction_id"`
+	RestoreAttempt bool            `json:"restore_attempt"`
+	Reward         json.RawMessage `json:"reward"`
+}
+
+// purchaseTransactionExportRow is a single exported transaction. Receipts are only exported as hashes.
+type purchaseTransactionExportRow struct {
+	Id            string          `json:"id"`
+	UserId        string          `json:"user_id"`
+	ItemId        string          `json:"item_id"`
+	StoreType     string          `json:"store_type"`
+	ReceiptHash   string          `json:"receipt_hash"`
+	TransactionId string          `json:"transaction_id,omitempty"`
+	Sandbox       bool            `json:"sandbox"`
+	Restore       bool            `json:"restore"`
+	Timestamp     int64           `json:"timestamp"`
+	Reward        json.RawMessage `json:"reward,omitempty"`
+}
+
+var purchaseTransactionExportHeader = []string{"id", "user_id", "item_id", "store_type", "receipt_hash", "transaction_id", "sandbox", "restore", "timestamp", "reward"}
+
+// PurchaseTransactionsExport exports one page of purchase transactions across all users within a time range.
+func (e *NakamaEconomySystem) PurchaseTransactionsExport(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, startTimeSec, endTimeSec int64, format string, limit int, cursor string) (*EconomyPurchaseTransactionsExport, error) {
+	if format == "" {
+		format = EconomyExportFormatNDJSON
+	}
+	if format != EconomyExportFormatCSV && format != EconomyExportFormatNDJSON {
+		return nil, runtime.NewError(fmt.Sprintf("unsupported export format: %s", format), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	if endTimeSec > 0 && endTimeSec < startTimeSec {
+		return nil, runtime.NewError("end time is before start time", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	limit, err := pageLimit(limit, maxPageLimit, maxPageLimit)
+	if err != nil {
+		return nil, err
+	}
+	storageCursor, history, err := decodeNakamaCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty user ID lists the collection across all users
+	objects, nextStorageCursor, err := nk.StorageList(ctx, "", "", purchaseTransactionsCollection, limit, storageCursor)
+	if err != nil {
+		logger.Error("Failed to list purchase transactions: %v", err)
+		return nil, ErrInternal
+	}
+
+	rows := make([]*purchaseTransactionExportRow, 0, len(objects))
+	for _, obj := range objects {
+		var record purchaseTransactionRecord
+		if err := json.Unmarshal([]byte(obj.Value), &record); err != nil {
+			logger.Warn("Skipping unreadable purchase transaction %s: %v", obj.Key, err)
+			continue
+		}
+		if record.Timestamp < startTimeSec || (endTimeSec > 0 && record.Timestamp > endTimeSec) {
+			continue
+		}
+		rows = append(rows, purchaseTransactionExportRowFromRecord(&record, obj))
+	}
+
+	data, err := encodePurchaseTransactionExport(rows, format, storageCursor == "")
+	if err != nil {
+		logger.Error("Failed to encode purchase transactions export: %v", err)
+		return nil, ErrPayloadEncode
+	}
+
+	nextCursor, prevCursor := nakamaPageCursors(storageCursor, history, nextStorageCursor)
+	return &EconomyPurchaseTransactionsExport{
+		Format:     format,
+		Data:       data,
+		Count:      len(rows),
+		Cursor:     nextCursor,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}, nil
+}
+
+func purchaseTransactionExportRowFromRecord(record *purchaseTransactionRecord, obj *api.StorageObject) *purchaseTransactionExportRow {
+	row := &purchaseTransactionExportRow{
+		Id:            record.Id,
+		UserId:        record.UserId,
+		ItemId:        record.ItemId,
+		StoreType:     purchaseStoreTypeName(record.StoreType),
+		ReceiptHash:   record.ReceiptHash,
+		TransactionId: record.TransactionId,
+		Sandbox:       record.Sandbox,
+		Restore:       record.RestoreAttempt,
+		Timestamp:     record.Timestamp,
+	}
+	if row.Id == "" {
+		row.Id = obj.Key
+	}
+	if row.UserId == "" {
+		row.UserId = obj.UserId
+	}
+	// Transactions recorded before receipt hashes were stored still carry the raw receipt
+	if row.ReceiptHash == "" && record.Receipt != "" {
+		row.ReceiptHash = hashReceipt(record.Receipt)
+	}
+	if len(record.Reward) > 0 && string(record.Reward) != "null" {
+		row.Reward = record.Reward
+	}
+	return row
+}
+
+// purchaseStoreTypeName returns a stored store type by its enum name. Transactions recorded before store types were
+// stored by name hold the enum value as a single character.
+func purchaseStoreTypeName(storeType string) string {
+	if runes := []rune(storeType); len(runes) == 1 {
+		if name, found := EconomyStoreType_name[int32(runes[0])]; found {
+			return name
+		}
+	}
+	return storeType
+}
+
+func encodePurchaseTransactionExport(rows []*purchaseTransactionExportRow, format string, includeHeader bool) (string, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case EconomyExportFormatCSV:
+		writer := csv.NewWriter(&buf)
+		if includeHeader {
+			if err := writer.Write(purchaseTransactionExportHeader); err != nil {
+				return "", err
+			}
+		}
+		for _, row := range rows {
+			if err := writer.Write([]string{
+				row.Id,
+				row.UserId,
+				row.ItemId,
+				row.StoreType,
+				row.ReceiptHash,
+				row.TransactionId,
+				strconv.FormatBool(row.Sandbox),
+				strconv.FormatBool(row.Restore),
+				strconv.FormatInt(row.Timestamp, 10),
+				string(row.Reward),
+			}); err != nil {
+				return "", err
+			}
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return "", err
+		}
+	default:
+		encoder := json.NewEncoder(&buf)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// hashReceipt returns the hex encoded SHA-256 of a store receipt, used to match transactions against store reports
+// without exporting the receipt itself.
+func hashReceipt(receipt string) string {
+	sum := sha256.Sum256([]byte(receipt))
+	return hex.EncodeToString(sum[:])
+}
+
 // Helper function to get a user's existing transactions
 func (e *NakamaEconomySystem) getUserTransactions(ctx context.Context, nk runtime.NakamaModule, userID string) (map[string]bool, error) {
 	transactions := make(map[string]bool)
 
 	// List all purchase transactions for this user
-	objects, _, err := nk.StorageList(ctx, "", userID, "purchase_transactions", 100, "")
+	objects, _, err := nk.StorageList(ctx, "", userID, purchaseTransactionsCollection, 100, "")
 	if err != nil {
 		return transactions, err
 	}
@@ -2420,7 +3660,7 @@ func (e *NakamaEconomySystem) PlacementStatus(ctx context.Context, logger runtim
 	}
 
 	// Check if placement exists in configuration
-	_, ok := e.config.Placements[placementID]
+	placement, ok := e.config.Placements[placementID]
 	if !ok {
 		return nil, ErrEconomyNoPlacement
 	}
@@ -2435,19 +3675,16 @@ func (e *NakamaEconomySystem) PlacementStatus(ctx context.Context, logger runtim
 	})
 
 	status := &EconomyPlacementStatus{
-		RewardId:    rewardID,
-		PlacementId: placementID,
-		Success:     true,
-		Metadata:    make(map[string]string),
+		RewardId:      rewardID,
+		PlacementId:   placementID,
+		Success:       true,
+		Metadata:      make(map[string]string),
+		RewardPreview: e.placementRewardPreview(ctx, logger, nk, userID, placement),
 	}
 
 	// If placement data exists, unmarshal and use it
 	if err == nil && len(object) > 0 {
-		var placementData struct {
-			Status    string            `json:"status"`
-			Metadata  map[string]string `json:"metadata,omitempty"`
-			Timestamp int64             `json:"timestamp"`
-		}
+		var placementData placementState
 
 		if err := json.Unmarshal([]byte(object[0].Value), &placementData); err == nil {
 			// Set CreateTimeSec based on stored timestamp
@@ -2468,6 +3705,162 @@ func (e *NakamaEconomySystem) PlacementStatus(ctx context.Context, logger runtim
 	return status, nil
 }
 
+// placementState is a user's latest view of a placement, along with the completions counted towards its daily limit.
+type placementState struct {
+	Status    string            `json:"status"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Timestamp int64             `json:"timestamp"`
+	// DailyCompletions counts the completions until DailyResetTimeSec.
+	DailyCompletions    int   `json:"daily_completions,omitempty"`
+	DailyResetTimeSec   int64 `json:"daily_reset_time_sec,omitempty"`
+	LastCompleteTimeSec int64 `json:"last_complete_time_sec,omitempty"`
+}
+
+// completionsToday returns the completions counted towards the placement's daily limit at the given time.
+func (s *placementState) completionsToday(now int64) int {
+	if s.DailyResetTimeSec <= now {
+		return 0
+	}
+	return s.DailyCompletions
+}
+
+// complete records a completion of the placement at the given time.
+func (s *placementState) complete(now time.Time) {
+	if s.DailyResetTimeSec <= now.Unix() {
+		s.DailyCompletions = 0
+		s.DailyResetTimeSec = nextUTCDay(now)
+	}
+	s.DailyCompletions++
+	s.LastCompleteTimeSec = now.Unix()
+}
+
+// availableTimeSec returns when the placement can next be started, or 0 when it can be started now. It returns
+// ErrEconomyPlacementLimit or ErrEconomyPlacementCooldown alongside the time when it can't.
+func (s *placementState) availableTimeSec(placement *EconomyConfigPlacement, now int64) (int64, error) {
+	if placement.MaxDailyCompletions > 0 && s.completionsToday(now) >= placement.MaxDailyCompletions {
+		return s.DailyResetTimeSec, ErrEconomyPlacementLimit
+	}
+	if placement.CooldownSec > 0 && s.LastCompleteTimeSec > 0 && s.LastCompleteTimeSec+placement.CooldownSec > now {
+		return s.LastCompleteTimeSec + placement.CooldownSec, ErrEconomyPlacementCooldown
+	}
+	return 0, nil
+}
+
+// nextUTCDay returns the start of the UTC day after the given time, when daily limits reset.
+func nextUTCDay(now time.Time) int64 {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Unix()
+}
+
+// ListPlacements returns the status, remaining daily limits and cooldown of every configured placement for a user.
+func (e *NakamaEconomySystem) ListPlacements(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomyPlacementList, error) {
+	if userID == "" {
+		return nil, runtime.NewError("user ID must not be empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	now := time.Now()
+	list := &EconomyPlacementList{
+		Placements:        make(map[string]*EconomyPlacementSummary, len(e.config.Placements)),
+		DailyResetTimeSec: nextUTCDay(now),
+		ServerTimeSec:     now.Unix(),
+	}
+
+	reads := make([]*runtime.StorageRead, 0, len(e.config.Placements)+1)
+	for placementID := range e.config.Placements {
+		reads = append(reads, &runtime.StorageRead{
+			Collection: placementStatusStorageCollection,
+			Key:        userID + "_" + placementID,
+			UserID:     userID,
+		})
+	}
+	caps := e.config.CurrencyDailyCaps[EconomyGrantSourcePlacement]
+	if len(caps) > 0 {
+		reads = append(reads, &runtime.StorageRead{
+			Collection: currencyGrantCapsCollection,
+			Key:        EconomyGrantSourcePlacement,
+			UserID:     userID,
+		})
+	}
+
+	var objects []*api.StorageObject
+	if len(reads) > 0 {
+		var err error
+		if objects, err = nk.StorageRead(ctx, reads); err != nil {
+			logger.Error("Failed to read placements: %v", err)
+			return nil, ErrInternal
+		}
+	}
+
+	states := make(map[string]*placementState, len(objects))
+	capState := &currencyGrantCapState{}
+	for _, object := range objects {
+		if object.Collection == currencyGrantCapsCollection {
+			if err := json.Unmarshal([]byte(object.Value), capState); err != nil {
+				logger.Error("Failed to unmarshal currency grant caps: %v", err)
+				return nil, ErrInternal
+			}
+			continue
+		}
+		state := &placementState{}
+		if err := json.Unmarshal([]byte(object.Value), state); err != nil {
+			logger.Error("Failed to unmarshal placement %s: %v", object.Key, err)
+			return nil, ErrInternal
+		}
+		states[strings.TrimPrefix(object.Key, userID+"_")] = state
+	}
+
+	for placementID, placement := range e.config.Placements {
+		summary := &EconomyPlacementSummary{
+			Available:                 true,
+			RemainingDailyCompletions: -1,
+			AdditionalProperties:      placement.AdditionalProperties,
+		}
+		state, found := states[placementID]
+		if !found {
+			state = &placementState{}
+		} else {
+			summary.Status = placementStateStatus(placementID, state)
+		}
+
+		if placement.MaxDailyCompletions > 0 {
+			summary.RemainingDailyCompletions = max(placement.MaxDailyCompletions-state.completionsToday(now.Unix()), 0)
+		}
+		if availableTimeSec, err := state.availableTimeSec(placement, now.Unix()); err != nil {
+			summary.Available = false
+			summary.AvailableTimeSec = availableTimeSec
+		}
+		list.Placements[placementID] = summary
+	}
+
+	if len(caps) > 0 {
+		if capState.ResetTimeSec <= now.Unix() {
+			capState.Granted = nil
+		}
+		list.RemainingCurrencyCaps = make(map[string]int64, len(caps))
+		for currencyID, limit := range caps {
+			list.RemainingCurrencyCaps[currencyID] = max(limit-capState.Granted[currencyID], 0)
+		}
+	}
+
+	return list, nil
+}
+
+// placementStateStatus converts a stored placement state into the status of the user's latest view of it.
+func placementStateStatus(placementID string, state *placementState) *EconomyPlacementStatus {
+	status := &EconomyPlacementStatus{
+		PlacementId:   placementID,
+		CreateTimeSec: state.Timestamp,
+		Metadata:      state.Metadata,
+	}
+	switch state.Status {
+	case "completed":
+		status.Success = true
+		status.CompleteTimeSec = state.Timestamp
+	case "failed":
+		status.CompleteTimeSec = state.Timestamp
+	}
+	return status
+}
+
 // PlacementStart indicates that a user has begun viewing an ad placement.
 func (e *NakamaEconomySystem) PlacementStart(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, placementID string, metadata map[string]string) (*EconomyPlacementStatus, error) {
 	// Validate inputs
@@ -2479,29 +3872,57 @@ func (e *NakamaEconomySystem) PlacementStart(ctx context.Context, logger runtime
 	}
 
 	// Check if placement exists in configuration
-	_, ok := e.config.Placements[placementID]
+	placement, ok := e.config.Placements[placementID]
 	if !ok {
 		return nil, ErrEconomyNoPlacement
 	}
 
+	// Limited placements keep their completions across views, so check and carry them over
+	now := time.Now().Unix()
+	state := &placementState{}
+	var version string
+	if placement.MaxDailyCompletions > 0 || placement.CooldownSec > 0 {
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+			{
+				Collection: placementStatusStorageCollection,
+				Key:        userID + "_" + placementID,
+				UserID:     userID,
+			},
+		})
+		if err != nil {
+			logger.Error("Failed to read placement data: %v", err)
+			return nil, runtime.NewError("failed to read placement data", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+		if len(objects) > 0 {
+			if err := json.Unmarshal([]byte(objects[0].Value), state); err != nil {
+				return nil, runtime.NewError("invalid placement data", INTERNAL_ERROR_CODE) // INTERNAL
+			}
+			version = objects[0].Version
+		} else {
+			version = storageLockVersionNone
+		}
+		if _, err := state.availableTimeSec(placement, now); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate a random reward ID
-	rewardID := uuid.New().String()
+	rewardID := e.newID()
 
 	// Create placement status
-	now := time.Now().Unix()
 	status := &EconomyPlacementStatus{
 		RewardId:      rewardID,
 		PlacementId:   placementID,
 		CreateTimeSec: now,
 		Metadata:      metadata,
+		RewardPreview: e.placementRewardPreview(ctx, logger, nk, userID, placement),
 	}
 
 	// Store placement status
-	placementData, err := json.Marshal(map[string]interface{}{
-		"status":    "started",
-		"metadata":  metadata,
-		"timestamp": now,
-	})
+	state.Status = "started"
+	state.Metadata = metadata
+	state.Timestamp = now
+	placementData, err := json.Marshal(state)
 	if err != nil {
 		logger.Error("Failed to marshal placement data: %v", err)
 		return nil, runtime.NewError("failed to marshal placement data", INTERNAL_ERROR_CODE) // INTERNAL
@@ -2513,6 +3934,7 @@ func (e *NakamaEconomySystem) PlacementStart(ctx context.Context, logger runtime
 			Key:             userID + "_" + placementID,
 			UserID:          userID,
 			Value:           string(placementData),
+			Version:         version,
 			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,  // Owner read
 			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE, // Owner write
 		},
@@ -2556,11 +3978,7 @@ func (e *NakamaEconomySystem) PlacementSuccess(ctx context.Context, logger runti
 		return nil, nil, runtime.NewError("placement not started", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
 
-	var placementData struct {
-		Status    string            `json:"status"`
-		Metadata  map[string]string `json:"metadata,omitempty"`
-		Timestamp int64             `json:"timestamp"`
-	}
+	var placementData placementState
 
 	if err := json.Unmarshal([]byte(object[0].Value), &placementData); err != nil {
 		return nil, nil, runtime.NewError("invalid placement data", INTERNAL_ERROR_CODE) // INTERNAL
@@ -2578,6 +3996,7 @@ func (e *NakamaEconomySystem) PlacementSuccess(ctx context.Context, logger runti
 	}
 
 	var reward *Reward
+	var cappedCurrencies map[string]int64
 	if placement.Reward != nil {
 		var rollErr error
 		reward, rollErr = e.RewardRoll(ctx, logger, nk, userID, placement.Reward)
@@ -2594,20 +4013,21 @@ func (e *NakamaEconomySystem) PlacementSuccess(ctx context.Context, logger runti
 			}
 		}
 
-		// Grant the reward to the user
-		_, _, _, grantErr := e.RewardGrant(ctx, logger, nk, userID, reward, nil, false)
+		// Grant the reward to the user, with currencies clamped to the user's daily cap for ad placements
+		capped, grantErr := e.RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourcePlacement, reward, nil)
 		if grantErr != nil {
 			logger.Error("Failed to grant placement reward: %v", grantErr)
 			return reward, placementData.Metadata, grantErr
 		}
+		cappedCurrencies = capped
 	}
 
 	// Update placement status to completed
-	updatedData, _ := json.Marshal(map[string]interface{}{
-		"status":    "completed",
-		"metadata":  placementData.Metadata,
-		"timestamp": time.Now().Unix(),
-	})
+	completeTime := time.Now()
+	placementData.Status = "completed"
+	placementData.Timestamp = completeTime.Unix()
+	placementData.complete(completeTime)
+	updatedData, _ := json.Marshal(placementData)
 
 	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
 		{
@@ -2644,6 +4064,17 @@ func (e *NakamaEconomySystem) PlacementSuccess(ctx context.Context, logger runti
 		}
 	}
 
+	// Report any currency cut off by the daily cap alongside the placement metadata
+	if len(cappedCurrencies) > 0 {
+		resultMetadata := make(map[string]string, len(placementData.Metadata)+1)
+		for k, v := range placementData.Metadata {
+			resultMetadata[k] = v
+		}
+		cappedData, _ := json.Marshal(cappedCurrencies)
+		resultMetadata["capped_currencies"] = string(cappedData)
+		return reward, resultMetadata, nil
+	}
+
 	return reward, placementData.Metadata, nil
 }
 
@@ -2676,22 +4107,16 @@ func (e *NakamaEconomySystem) PlacementFail(ctx context.Context, logger runtime.
 		return nil, runtime.NewError("placement not started", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 	}
 
-	var placementData struct {
-		Status    string            `json:"status"`
-		Metadata  map[string]string `json:"metadata,omitempty"`
-		Timestamp int64             `json:"timestamp"`
-	}
+	var placementData placementState
 
 	if err := json.Unmarshal([]byte(object[0].Value), &placementData); err != nil {
 		return nil, runtime.NewError("invalid placement data", INTERNAL_ERROR_CODE) // INTERNAL
 	}
 
 	// Update placement status to failed
-	updatedData, _ := json.Marshal(map[string]interface{}{
-		"status":    "failed",
-		"metadata":  placementData.Metadata,
-		"timestamp": time.Now().Unix(),
-	})
+	placementData.Status = "failed"
+	placementData.Timestamp = time.Now().Unix()
+	updatedData, _ := json.Marshal(placementData)
 
 	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
 		{
@@ -2726,13 +4151,14 @@ func (e *NakamaEconomySystem) SetOnStoreItemReward(fn OnReward[*EconomyConfigSto
 	e.onStoreItemReward = fn
 }
 
-// grantItemsDirectly is a fallback method for granting items when the inventory system is not available
-func (e *NakamaEconomySystem) grantItemsDirectly(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, ignoreLimits bool) error {
+// grantItemsDirectly is a fallback method for granting items when the inventory system is not available. It returns the
+// storage writes for the granted items.
+func (e *NakamaEconomySystem) grantItemsDirectly(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem) ([]*runtime.StorageWrite, error) {
 	// Get current inventory to check for item updates vs new items
 	inventory, err := e.getInventory(ctx, nk, userID)
 	if err != nil {
 		logger.Error("Failed to retrieve inventory: %v", err)
-		return runtime.NewError("Failed to retrieve inventory", INTERNAL_ERROR_CODE) // INTERNAL
+		return nil, runtime.NewError("Failed to retrieve inventory", INTERNAL_ERROR_CODE) // INTERNAL
 	}
 
 	// Prepare item operations
@@ -2813,7 +4239,7 @@ func (e *NakamaEconomySystem) grantItemsDirectly(ctx context.Context, logger run
 					newItem.InstanceId = itemInstance.InstanceId
 				} else {
 					// Generate a new instance ID if none was provided
-					newItem.InstanceId = uuid.New().String()
+					newItem.InstanceId = e.newID()
 				}
 			}
 
@@ -2839,14 +4265,5 @@ func (e *NakamaEconomySystem) grantItemsDirectly(ctx context.Context, logger run
 		}
 	}
 
-	// Execute storage operations if we have any
-	if len(itemsToAdd) > 0 {
-		_, err = nk.StorageWrite(ctx, itemsToAdd)
-		if err != nil {
-			logger.Error("Failed to write inventory updates: %v", err)
-			return runtime.NewError("Failed to update inventory", INTERNAL_ERROR_CODE) // INTERNAL
-		}
-	}
-
-	return nil
+	return itemsToAdd, nil
 }
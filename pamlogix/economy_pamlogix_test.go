@@ -3,6 +3,7 @@ package pamlogix
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -171,6 +173,68 @@ func TestDonationRequest_ExistingDonation(t *testing.T) {
 	nk.AssertExpectations(t)
 }
 
+func TestDonationGive_ChargesContributor(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	economy := p.GetEconomySystem().(*NakamaEconomySystem)
+	economy.config.Donations = map[string]*EconomyConfigDonation{
+		"don1": {
+			Cost:                     &EconomyConfigDonationCost{Currencies: map[string]int64{benchCurrency: 5}},
+			DurationSec:              1000,
+			MaxCount:                 3,
+			UserContributionMaxCount: 1,
+		},
+	}
+	_, _, err := nk.WalletUpdate(ctx, "recipient", map[string]int64{benchCurrency: 20}, nil, false)
+	require.NoError(t, err)
+	_, _, err = nk.WalletUpdate(ctx, "contributor", map[string]int64{benchCurrency: 20}, nil, false)
+	require.NoError(t, err)
+
+	_, success, err := economy.DonationRequest(ctx, logger, nk, "recipient", "don1")
+	require.NoError(t, err)
+	require.True(t, success)
+	recipientWallet, err := userWallet(ctx, nk, "recipient")
+	require.NoError(t, err)
+
+	donation, wallet, _, _, _, _, err := economy.DonationGive(ctx, logger, nk, "recipient", "don1", "contributor")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), donation.Count)
+	require.Len(t, donation.Contributors, 1)
+	assert.Equal(t, "contributor", donation.Contributors[0].UserId)
+
+	// The wallet returned and charged is the contributor's, not the recipient's
+	assert.Equal(t, int64(15), wallet[benchCurrency])
+	updatedRecipientWallet, err := userWallet(ctx, nk, "recipient")
+	require.NoError(t, err)
+	assert.Equal(t, recipientWallet[benchCurrency], updatedRecipientWallet[benchCurrency])
+
+	// The contribution limit counts the contributor's own contributions
+	_, _, _, _, _, _, err = economy.DonationGive(ctx, logger, nk, "recipient", "don1", "contributor")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "contribution limit reached")
+	_, _, err = nk.WalletUpdate(ctx, "another", map[string]int64{benchCurrency: 20}, nil, false)
+	require.NoError(t, err)
+	donation, _, _, _, _, _, err = economy.DonationGive(ctx, logger, nk, "recipient", "don1", "another")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), donation.Count)
+}
+
+func TestDonationGive_InvalidArguments(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economy := NewNakamaEconomySystem(&EconomyConfig{Donations: map[string]*EconomyConfigDonation{"don1": {MaxCount: 1}}})
+
+	_, _, _, _, _, _, err := economy.DonationGive(ctx, logger, nk, "", "don1", "contributor")
+	assert.EqualError(t, err, "recipient user ID is empty")
+	_, _, _, _, _, _, err = economy.DonationGive(ctx, logger, nk, "recipient", "don1", "")
+	assert.EqualError(t, err, "contributor user ID is empty")
+	_, _, _, _, _, _, err = economy.DonationGive(ctx, logger, nk, "recipient", "don1", "recipient")
+	assert.EqualError(t, err, "cannot contribute to own donation")
+}
+
 func TestList_ReturnsConfig(t *testing.T) {
 	config := &EconomyConfig{
 		StoreItems: map[string]*EconomyConfigStoreItem{
@@ -217,7 +281,7 @@ func TestGrant_Success(t *testing.T) {
 	modifiers := []*RewardModifier{{Id: "mod1", Type: "bonus", Operator: "+", Value: 1}}
 	walletMetadata := map[string]interface{}{"meta": "data"}
 
-	updatedWallet, rewardModifiers, timestamp, err := economy.Grant(ctx, logger, nk, userID, currencies, items, modifiers, walletMetadata)
+	updatedWallet, rewardModifiers, timestamp, err := economy.Grant(ctx, logger, nk, userID, currencies, items, nil, modifiers, walletMetadata)
 	require.NoError(t, err)
 	assert.Equal(t, int64(100), updatedWallet["gold"])
 	assert.NotNil(t, rewardModifiers)
@@ -243,7 +307,7 @@ func TestGrant_Error(t *testing.T) {
 	modifiers := []*RewardModifier{{Id: "mod1", Type: "bonus", Operator: "+", Value: 1}}
 	walletMetadata := map[string]interface{}{"meta": "data"}
 
-	updatedWallet, rewardModifiers, timestamp, err := economy.Grant(ctx, logger, nk, userID, currencies, items, modifiers, walletMetadata)
+	updatedWallet, rewardModifiers, timestamp, err := economy.Grant(ctx, logger, nk, userID, currencies, items, nil, modifiers, walletMetadata)
 	assert.Error(t, err)
 	assert.Nil(t, updatedWallet)
 	assert.Nil(t, rewardModifiers)
@@ -252,6 +316,308 @@ func TestGrant_Error(t *testing.T) {
 	nk.AssertExpectations(t)
 }
 
+func TestGrant_ItemInstances(t *testing.T) {
+	economy := NewNakamaEconomySystem(&EconomyConfig{})
+	logger := &mockLogger{}
+	nk := NewMockNakama(t)
+	ctx := context.Background()
+	userID := "user1"
+
+	nk.On("WalletUpdate", ctx, userID, mock.Anything, mock.Anything, false).Return(map[string]int64{}, map[string]int64{}, nil)
+	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{}, nil)
+	nk.On("StorageList", ctx, "", userID, "inventory", 100, "").Return([]*api.StorageObject{}, "", nil)
+	nk.On("StorageWrite", ctx, mock.MatchedBy(func(writes []*runtime.StorageWrite) bool {
+		if len(writes) != 1 || writes[0].Key != "inventory:sword_rare" {
+			return false
+		}
+		item := &InventoryItem{}
+		if err := json.Unmarshal([]byte(writes[0].Value), item); err != nil {
+			return false
+		}
+		return item.Count == 2 && item.StringProperties["rarity"] == "epic" && item.NumericProperties["damage"] == 42
+	})).Return([]*api.StorageObjectAck{}, nil).Once()
+	nk.On("AccountGetId", ctx, userID).Return(&api.Account{Wallet: `{}`}, nil)
+
+	itemInstances := map[string]*RewardInventoryItem{
+		"sword_rare": {
+			Count:             2,
+			StringProperties:  map[string]string{"rarity": "epic"},
+			NumericProperties: map[string]float64{"damage": 42},
+		},
+	}
+
+	_, _, _, err := economy.Grant(ctx, logger, nk, userID, nil, nil, itemInstances, nil, nil)
+	require.NoError(t, err)
+
+	nk.AssertExpectations(t)
+}
+
+func TestRewardGrant_BatchesStorage(t *testing.T) {
+	p := newBenchPamlogix()
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ops := &storageOpCounts{}
+	ctx := withStorageOpCounts(context.Background(), ops)
+	userID := "user1"
+
+	reward := &Reward{
+		Items: map[string]int64{"sword": 1},
+		ItemInstances: map[string]*RewardInventoryItem{
+			"sword": {Count: 1, StringProperties: map[string]string{"rarity": "epic"}},
+		},
+		Energies:        map[string]int32{"lives": 2},
+		EnergyModifiers: []*RewardEnergyModifier{{Id: "lives", Operator: "add", Value: 1, DurationSec: 600}},
+		RewardModifiers: []*RewardModifier{{Id: "coins", Type: "currency", Operator: "multiplier", Value: 2}},
+	}
+
+	newItems, _, _, err := p.GetEconomySystem().RewardGrant(ctx, logger, nk, userID, reward, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), ops.reads.Load())
+	assert.Equal(t, int64(1), ops.writes.Load())
+
+	require.Len(t, newItems, 1)
+	for _, item := range newItems {
+		assert.Equal(t, "epic", item.StringProperties["rarity"])
+	}
+
+	objects, err := nk.StorageRead(context.Background(), []*runtime.StorageRead{
+		{Collection: energyStorageCollection, Key: userEnergyStorageKey, UserID: userID},
+		{Collection: userModifiersStorageCollection, Key: userID + "_energy_modifiers", UserID: userID},
+		{Collection: userModifiersStorageCollection, Key: userID + "_reward_modifiers", UserID: userID},
+	})
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+
+	energyList := &EnergyList{}
+	require.NoError(t, json.Unmarshal([]byte(objects[0].Value), energyList))
+	assert.Equal(t, int32(2), energyList.Energies["lives"].Current)
+
+	var rewardModifiers []*ActiveRewardModifier
+	require.NoError(t, json.Unmarshal([]byte(objects[2].Value), &rewardModifiers))
+	require.Len(t, rewardModifiers, 1)
+	assert.Equal(t, "currency", rewardModifiers[0].Type)
+	assert.Zero(t, rewardModifiers[0].EndTimeSec)
+}
+
+func TestRewardGrant_DuplicateConversion(t *testing.T) {
+	p := newBenchPamlogix()
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ctx := context.Background()
+	userID := "user1"
+	inventoryConfig := p.GetInventorySystem().GetConfig().(*InventoryConfig)
+	inventoryConfig.Items["hero"] = &InventoryConfigItem{
+		Name:   "Hero",
+		Unique: true,
+		DuplicateConversion: &InventoryConfigItemDuplicateConversion{
+			Currencies: map[string]int64{"shards": 50},
+			Items:      map[string]int64{"potion": 1},
+		},
+	}
+	inventoryConfig.Items["skin"] = &InventoryConfigItem{Name: "Skin", Unique: true}
+
+	// The first hero is granted and the second converted
+	reward := &Reward{Items: map[string]int64{"hero": 2}}
+	newItems, _, _, err := p.GetEconomySystem().RewardGrant(ctx, logger, nk, userID, reward, nil, false)
+	require.NoError(t, err)
+	assert.Len(t, newItems, 2)
+	assert.Equal(t, map[string]int64{"hero": 1}, reward.ConvertedItems)
+	assert.Equal(t, map[string]int64{"hero": 1, "potion": 1}, reward.Items)
+	assert.Equal(t, int64(50), reward.Currencies["shards"])
+
+	// An owned hero is converted in full, and a unique item without a conversion is dropped
+	reward = &Reward{Items: map[string]int64{"hero": 1, "skin": 1, "sword": 1}}
+	_, _, _, err = p.GetEconomySystem().RewardGrant(ctx, logger, nk, userID, reward, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"hero": 1}, reward.ConvertedItems)
+	assert.Equal(t, map[string]int64{"potion": 1, "skin": 1, "sword": 1}, reward.Items)
+
+	reward = &Reward{Items: map[string]int64{"skin": 1}}
+	_, _, _, err = p.GetEconomySystem().RewardGrant(ctx, logger, nk, userID, reward, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"skin": 1}, reward.ConvertedItems)
+	assert.Empty(t, reward.Items)
+
+	wallet, err := userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), wallet["shards"])
+	inventory, err := p.GetInventorySystem().ListInventoryItems(ctx, logger, nk, userID, "")
+	require.NoError(t, err)
+	counts := make(map[string]int64)
+	for _, item := range inventory.Items {
+		counts[item.Id] += item.Count
+	}
+	assert.Equal(t, map[string]int64{"hero": 1, "skin": 1, "potion": 2, "sword": 1}, counts)
+}
+
+func TestRewardGrantBulk(t *testing.T) {
+	p := newBenchPamlogix()
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ops := &storageOpCounts{}
+	ctx := withStorageOpCounts(context.Background(), ops)
+	economy := p.GetEconomySystem()
+
+	// The whole batch is read and written with one call each
+	results, err := economy.RewardGrantBulk(ctx, logger, nk, map[string]*Reward{
+		"user1": {
+			Currencies:      map[string]int64{benchCurrency: 100},
+			Items:           map[string]int64{"sword": 1},
+			RewardModifiers: []*RewardModifier{{Id: "coins", Type: "currency", Operator: "multiplier", Value: 2}},
+		},
+		"user2": {
+			Currencies:      map[string]int64{benchCurrency: 50},
+			RewardModifiers: []*RewardModifier{{Id: "coins", Type: "currency", Operator: "multiplier", Value: 2}},
+		},
+	}, nil, false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.NoError(t, results["user1"].Err)
+	assert.Len(t, results["user1"].NewItems, 1)
+	assert.NoError(t, results["user2"].Err)
+	assert.Equal(t, int64(1), ops.reads.Load())
+	assert.Equal(t, int64(1), ops.writes.Load())
+
+	wallet, err := userWallet(ctx, nk, "user2")
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), wallet[benchCurrency])
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: userModifiersStorageCollection, Key: "user1_reward_modifiers", UserID: "user1"},
+		{Collection: userModifiersStorageCollection, Key: "user2_reward_modifiers", UserID: "user2"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	// Users that fail don't stop the rest of the batch
+	results, err = economy.RewardGrantBulk(ctx, logger, nk, map[string]*Reward{
+		"user1": {Currencies: map[string]int64{benchCurrency: 10}},
+		"user3": {Currencies: map[string]int64{benchCurrency: -10}},
+		"user4": nil,
+	}, nil, false)
+	require.NoError(t, err)
+	assert.NoError(t, results["user1"].Err)
+	assert.Error(t, results["user3"].Err)
+	assert.Error(t, results["user4"].Err)
+
+	wallet, err = userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(110), wallet[benchCurrency])
+	wallet, err = userWallet(ctx, nk, "user3")
+	require.NoError(t, err)
+	assert.Zero(t, wallet[benchCurrency])
+}
+
+func TestCompactModifiers(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economy := newBenchPamlogix().GetEconomySystem()
+	now := time.Now().Unix()
+
+	_, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{Collection: userModifiersStorageCollection, Key: "user1_reward_modifiers", UserID: "user1", Value: fmt.Sprintf(`[{"id":"coins","end_time_sec":%d},{"id":"xp","end_time_sec":%d},{"id":"gems","end_time_sec":0}]`, now-60, now+60)},
+		{Collection: userModifiersStorageCollection, Key: "user2_reward_modifiers", UserID: "user2", Value: fmt.Sprintf(`[{"id":"coins","end_time_sec":%d}]`, now-60)},
+		{Collection: userModifiersStorageCollection, Key: "user3_energy_modifiers", UserID: "user3", Value: fmt.Sprintf(`[{"id":"lives","end_time_sec":%d}]`, now+60)},
+	})
+	require.NoError(t, err)
+
+	compacted, err := economy.CompactModifiers(ctx, logger, nk)
+	require.NoError(t, err)
+	assert.Equal(t, 1, compacted)
+
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: userModifiersStorageCollection, Key: "user1_reward_modifiers", UserID: "user1"},
+		{Collection: userModifiersStorageCollection, Key: "user2_reward_modifiers", UserID: "user2"},
+	})
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	var modifiers []*ActiveRewardModifier
+	require.NoError(t, json.Unmarshal([]byte(objects[0].Value), &modifiers))
+	require.Len(t, modifiers, 2)
+	assert.Equal(t, "xp", modifiers[0].Id)
+	assert.Equal(t, "gems", modifiers[1].Id)
+	// Modifiers which have all ended are left for the storage sweep
+	assert.Contains(t, objects[1].Value, `"coins"`)
+}
+
+func TestRewardGrant_DryRun(t *testing.T) {
+	p := newBenchPamlogix()
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ops := &storageOpCounts{}
+	ctx := withStorageOpCounts(context.Background(), ops)
+	userID := "user1"
+
+	_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 10}, nil, false)
+	require.NoError(t, err)
+
+	reward := &Reward{
+		Currencies:      map[string]int64{benchCurrency: 5},
+		Items:           map[string]int64{"sword": 1},
+		RewardModifiers: []*RewardModifier{{Id: "coins", Type: "currency", Operator: "multiplier", Value: 2}},
+	}
+	newItems, _, _, err := p.GetEconomySystem().RewardGrantDryRun(ctx, logger, nk, userID, reward, nil, false)
+	require.NoError(t, err)
+	require.Len(t, newItems, 1)
+	assert.Zero(t, ops.writes.Load())
+
+	wallet, err := userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), wallet[benchCurrency])
+	inventory, err := p.GetInventorySystem().ListInventoryItems(ctx, logger, nk, userID, "")
+	require.NoError(t, err)
+	assert.Empty(t, inventory.Items)
+
+	// The previewed wallet is returned by GrantDryRun, and deductions the wallet can't cover fail like a real grant would
+	updatedWallet, _, _, err := p.GetEconomySystem().GrantDryRun(ctx, logger, nk, userID, map[string]int64{benchCurrency: -4}, nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), updatedWallet[benchCurrency])
+	_, _, _, err = p.GetEconomySystem().GrantDryRun(ctx, logger, nk, userID, map[string]int64{benchCurrency: -40}, nil, nil, nil, nil)
+	assert.ErrorIs(t, err, ErrCurrencyInsufficient)
+
+	cost := &Cost{Currencies: map[string]int64{benchCurrency: 8}}
+	require.NoError(t, chargeCost(ctx, logger, nk, p, userID, cost, nil, true))
+	cost.Currencies[benchCurrency] = 11
+	assert.ErrorIs(t, chargeCost(ctx, logger, nk, p, userID, cost, nil, true), ErrCurrencyInsufficient)
+	wallet, err = userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), wallet[benchCurrency])
+}
+
+func TestDebit(t *testing.T) {
+	p := newBenchPamlogix()
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ctx := context.Background()
+	userID := "user1"
+
+	_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{benchCurrency: 10}, nil, false)
+	require.NoError(t, err)
+	_, _, _, _, err = p.GetInventorySystem().GrantItems(ctx, logger, nk, userID, map[string]int64{"potion": 3}, false)
+	require.NoError(t, err)
+
+	_, err = p.GetEconomySystem().Debit(ctx, logger, nk, userID, map[string]int64{benchCurrency: -5}, nil, nil)
+	assert.Error(t, err)
+
+	// The currency is affordable but the items aren't, so nothing is taken
+	_, err = p.GetEconomySystem().Debit(ctx, logger, nk, userID, map[string]int64{benchCurrency: 5}, map[string]int64{"potion": 4}, nil)
+	assert.ErrorIs(t, err, ErrItemsInsufficient)
+	wallet, err := userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), wallet[benchCurrency])
+
+	updatedWallet, err := p.GetEconomySystem().DebitDryRun(ctx, logger, nk, userID, map[string]int64{benchCurrency: 4}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), updatedWallet[benchCurrency])
+
+	updatedWallet, err = p.GetEconomySystem().Debit(ctx, logger, nk, userID, map[string]int64{benchCurrency: 5}, map[string]int64{"potion": 3}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), updatedWallet[benchCurrency])
+	inventory, err := p.GetInventorySystem().ListInventoryItems(ctx, logger, nk, userID, "")
+	require.NoError(t, err)
+	assert.Empty(t, inventory.Items)
+}
+
 func TestPurchaseItem_Success_AppleStore(t *testing.T) {
 	// Setup
 	config := &EconomyConfig{
@@ -304,6 +670,7 @@ func TestPurchaseItem_Success_AppleStore(t *testing.T) {
 	nk.On("PurchaseValidateApple", ctx, userID, receipt, true, []string(nil)).Return(validationResponse, nil)
 	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{}, nil)
 	nk.On("StorageWrite", ctx, mock.Anything).Return([]*api.StorageObjectAck{}, nil)
+	nk.On("StorageDelete", ctx, mock.Anything).Return(nil)
 	nk.On("WalletUpdate", ctx, userID, mock.Anything, mock.Anything, false).Return(
 		map[string]int64{"gold": 100}, map[string]int64{}, nil)
 	nk.On("AccountGetId", ctx, userID).Return(&api.Account{Wallet: `{"gold":100}`}, nil)
@@ -373,6 +740,7 @@ func TestPurchaseItem_Success_GooglePlay(t *testing.T) {
 	}(nil)).Return(validationResponse, nil)
 	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{}, nil)
 	nk.On("StorageWrite", ctx, mock.Anything).Return([]*api.StorageObjectAck{}, nil)
+	nk.On("StorageDelete", ctx, mock.Anything).Return(nil)
 	nk.On("WalletUpdate", ctx, userID, mock.Anything, mock.Anything, false).Return(
 		map[string]int64{"gems": 50}, map[string]int64{}, nil)
 	nk.On("AccountGetId", ctx, userID).Return(&api.Account{Wallet: `{"gems":50}`}, nil)
@@ -431,7 +799,7 @@ func TestPurchaseItem_WithPurchaseIntent(t *testing.T) {
 		Collection: "purchase_intents",
 		Key:        "purchase_intent:user3:vip_pass",
 		UserId:     userID,
-		Value:      `{"user_id":"user3","item_id":"vip_pass","store_type":"ECONOMY_STORE_TYPE_APPLE_APPSTORE","sku":"com.example.vippass","created_at":1625000000,"expires_at":1625003600,"status":"pending","is_consumed":false}`,
+		Value:      fmt.Sprintf(`{"user_id":"user3","item_id":"vip_pass","store_type":"ECONOMY_STORE_TYPE_APPLE_APPSTORE","sku":"com.example.vippass","created_at":%d,"expires_at":%d,"status":"pending","is_consumed":false}`, time.Now().Unix(), time.Now().Unix()+3600),
 		Version:    "v1",
 	}
 
@@ -455,6 +823,7 @@ func TestPurchaseItem_WithPurchaseIntent(t *testing.T) {
 	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{}, nil).Maybe()
 	nk.On("PurchaseValidateApple", ctx, userID, receipt, true, []string(nil)).Return(validationResponse, nil)
 	nk.On("StorageWrite", ctx, mock.Anything).Return([]*api.StorageObjectAck{}, nil).Maybe()
+	nk.On("StorageDelete", ctx, mock.Anything).Return(nil)
 	nk.On("WalletUpdate", ctx, userID, mock.Anything, mock.Anything, false).Return(
 		map[string]int64{}, map[string]int64{}, nil)
 	nk.On("AccountGetId", ctx, userID).Return(&api.Account{Wallet: `{}`}, nil)
@@ -475,6 +844,140 @@ func TestPurchaseItem_WithPurchaseIntent(t *testing.T) {
 	nk.AssertExpectations(t)
 }
 
+func TestPurchaseItem_SkuMismatch(t *testing.T) {
+	config := &EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"premium_pack": {
+				Name: "Premium Pack",
+				Cost: &EconomyConfigStoreItemCost{
+					Sku: "com.example.premiumpack",
+				},
+				Reward: &EconomyConfigReward{
+					Guaranteed: &EconomyConfigRewardContents{
+						Currencies: map[string]*EconomyConfigRewardCurrency{
+							"gold": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 100, Max: 100}},
+						},
+					},
+				},
+			},
+		},
+	}
+	economy := NewNakamaEconomySystem(config)
+	logger := &mockLogger{}
+	nk := NewMockNakama(t)
+	ctx := context.Background()
+	userID := "user1"
+	receipt := "cheaper_product_receipt"
+
+	validationResponse := &api.ValidatePurchaseResponse{
+		ValidatedPurchases: []*api.ValidatedPurchase{
+			{
+				ProductId:     "com.example.smallpack",
+				TransactionId: "transaction456",
+				Store:         api.StoreProvider_APPLE_APP_STORE,
+				Environment:   api.StoreEnvironment_PRODUCTION,
+			},
+		},
+	}
+
+	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{}, nil)
+	nk.On("PurchaseValidateApple", ctx, userID, receipt, true, []string(nil)).Return(validationResponse, nil)
+	nk.On("StorageWrite", ctx, mock.MatchedBy(func(writes []*runtime.StorageWrite) bool {
+		return len(writes) == 1 && writes[0].Collection == purchaseFlagsStorageCollection && writes[0].UserID == userID &&
+			strings.Contains(writes[0].Value, `"store_type":"ECONOMY_STORE_TYPE_APPLE_APPSTORE"`)
+	})).Return([]*api.StorageObjectAck{}, nil).Once()
+
+	wallet, inventory, reward, _, err := economy.PurchaseItem(ctx, logger, nil, nk, userID, "premium_pack", EconomyStoreType_ECONOMY_STORE_TYPE_APPLE_APPSTORE, receipt)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "receipt does not match store item")
+	assert.Nil(t, wallet)
+	assert.Nil(t, inventory)
+	assert.Nil(t, reward)
+	nk.AssertNotCalled(t, "WalletUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	nk.AssertExpectations(t)
+}
+
+func TestPurchaseItem_SandboxModes(t *testing.T) {
+	newConfig := func(mode string) *EconomyConfig {
+		return &EconomyConfig{
+			SandboxPurchases: mode,
+			StoreItems: map[string]*EconomyConfigStoreItem{
+				"premium_pack": {
+					Name: "Premium Pack",
+					Cost: &EconomyConfigStoreItemCost{
+						Sku: "com.example.premiumpack",
+					},
+					Reward: &EconomyConfigReward{
+						Guaranteed: &EconomyConfigRewardContents{
+							Currencies: map[string]*EconomyConfigRewardCurrency{
+								"gold": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 100, Max: 100}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	ctx := context.Background()
+	userID := "user1"
+	receipt := "sandbox_receipt"
+	validationResponse := &api.ValidatePurchaseResponse{
+		ValidatedPurchases: []*api.ValidatedPurchase{
+			{
+				ProductId:     "com.example.premiumpack",
+				TransactionId: "transaction789",
+				Store:         api.StoreProvider_APPLE_APP_STORE,
+				Environment:   api.StoreEnvironment_SANDBOX,
+			},
+		},
+	}
+
+	t.Run("deny", func(t *testing.T) {
+		economy := NewNakamaEconomySystem(newConfig(EconomySandboxPurchasesDeny))
+		nk := NewMockNakama(t)
+		nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{}, nil)
+		nk.On("PurchaseValidateApple", ctx, userID, receipt, true, []string(nil)).Return(validationResponse, nil)
+
+		wallet, inventory, reward, isSandbox, err := economy.PurchaseItem(ctx, &mockLogger{}, nil, nk, userID, "premium_pack", EconomyStoreType_ECONOMY_STORE_TYPE_APPLE_APPSTORE, receipt)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "sandbox purchases are not accepted")
+		assert.True(t, isSandbox)
+		assert.Nil(t, wallet)
+		assert.Nil(t, inventory)
+		assert.Nil(t, reward)
+		nk.AssertNotCalled(t, "StorageWrite", mock.Anything, mock.Anything)
+		nk.AssertNotCalled(t, "WalletUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("isolate", func(t *testing.T) {
+		economy := NewNakamaEconomySystem(newConfig(EconomySandboxPurchasesIsolate))
+		nk := NewMockNakama(t)
+		nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{}, nil)
+		nk.On("PurchaseValidateApple", ctx, userID, receipt, true, []string(nil)).Return(validationResponse, nil)
+		nk.On("StorageWrite", ctx, mock.MatchedBy(func(writes []*runtime.StorageWrite) bool {
+			return len(writes) == 1 && writes[0].Collection == purchaseTransactionsCollection &&
+				strings.Contains(writes[0].Value, `"isolated":true`)
+		})).Return([]*api.StorageObjectAck{}, nil).Once()
+		nk.On("StorageWrite", ctx, mock.MatchedBy(func(writes []*runtime.StorageWrite) bool {
+			return len(writes) == 1 && writes[0].Collection == sandboxStorageCollection && writes[0].UserID == userID &&
+				writes[0].Value == `{"wallet":{"gold":100},"items":{}}`
+		})).Return([]*api.StorageObjectAck{}, nil).Once()
+
+		wallet, inventory, reward, isSandbox, err := economy.PurchaseItem(ctx, &mockLogger{}, nil, nk, userID, "premium_pack", EconomyStoreType_ECONOMY_STORE_TYPE_APPLE_APPSTORE, receipt)
+
+		require.NoError(t, err)
+		assert.True(t, isSandbox)
+		assert.Equal(t, map[string]int64{"gold": 100}, wallet)
+		assert.Empty(t, inventory.Items)
+		require.NotNil(t, reward)
+		assert.Equal(t, int64(100), reward.Currencies["gold"])
+		nk.AssertNotCalled(t, "WalletUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		nk.AssertExpectations(t)
+	})
+}
+
 func TestPurchaseItem_InvalidReceipt(t *testing.T) {
 	config := &EconomyConfig{
 		StoreItems: map[string]*EconomyConfigStoreItem{
@@ -673,6 +1176,206 @@ func TestPurchaseItem_UnsupportedStore(t *testing.T) {
 	assert.Empty(t, wallet)
 }
 
+func TestPurchaseItem_ValidatorStores(t *testing.T) {
+	config := &EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"gems_pack": {
+				Name: "Gems Pack",
+				Cost: &EconomyConfigStoreItemCost{Sku: "gems_pack_sku"},
+				Reward: &EconomyConfigReward{
+					Guaranteed: &EconomyConfigRewardContents{
+						Currencies: map[string]*EconomyConfigRewardCurrency{
+							"gems": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 50, Max: 50}},
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	logger := &mockLogger{}
+	userID := "user1"
+
+	for _, store := range []EconomyStoreType{
+		EconomyStoreType_ECONOMY_STORE_TYPE_STEAM,
+		EconomyStoreType_ECONOMY_STORE_TYPE_PLAYSTATION,
+		EconomyStoreType_ECONOMY_STORE_TYPE_XBOX,
+	} {
+		t.Run(store.String(), func(t *testing.T) {
+			economy := NewNakamaEconomySystem(config)
+			nk := newBenchNakama()
+
+			// Without a validator the store can't be purchased from
+			_, _, _, _, err := economy.PurchaseItem(ctx, logger, nil, nk, userID, "gems_pack", store, "receipt1")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "no purchase validator")
+
+			economy.SetPurchaseValidator(store, func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, validatorStore EconomyStoreType, receipt string) (*api.ValidatePurchaseResponse, error) {
+				assert.Equal(t, store, validatorStore)
+				if receipt == "bad_receipt" {
+					return nil, errors.New("rejected by platform")
+				}
+				return &api.ValidatePurchaseResponse{
+					ValidatedPurchases: []*api.ValidatedPurchase{{
+						ProductId:     "gems_pack_sku",
+						TransactionId: "txn_" + receipt,
+						Environment:   api.StoreEnvironment_PRODUCTION,
+					}},
+				}, nil
+			})
+
+			wallet, _, reward, isSandbox, err := economy.PurchaseItem(ctx, logger, nil, nk, userID, "gems_pack", store, "receipt1")
+			require.NoError(t, err)
+			assert.False(t, isSandbox)
+			assert.Equal(t, int64(50), reward.Currencies["gems"])
+			assert.Equal(t, int64(50), wallet["gems"])
+
+			transactions, _, err := nk.StorageList(ctx, "", userID, purchaseTransactionsCollection, 100, "")
+			require.NoError(t, err)
+			assert.Len(t, transactions, 1)
+
+			// The same receipt can't be redeemed twice
+			_, _, _, _, err = economy.PurchaseItem(ctx, logger, nil, nk, userID, "gems_pack", store, "receipt1")
+			assert.ErrorIs(t, err, ErrEconomyReceiptDuplicate)
+
+			_, _, _, _, err = economy.PurchaseItem(ctx, logger, nil, nk, userID, "gems_pack", store, "bad_receipt")
+			assert.ErrorIs(t, err, ErrEconomyReceiptInvalid)
+
+			wallet, err = userWallet(ctx, nk, userID)
+			require.NoError(t, err)
+			assert.Equal(t, int64(50), wallet["gems"])
+		})
+	}
+}
+
+// subscriptionNakama keeps the subscriptions it validated by product ID, like Nakama does for the app stores.
+type subscriptionNakama struct {
+	*benchNakama
+	subscriptions map[string]*api.ValidatedSubscription
+}
+
+func (m *subscriptionNakama) SubscriptionValidateApple(ctx context.Context, userID, receipt string, persist bool, passwordOverride ...string) (*api.ValidateSubscriptionResponse, error) {
+	subscription, found := m.subscriptions[receipt]
+	if !found {
+		return nil, errors.New("invalid receipt")
+	}
+	return &api.ValidateSubscriptionResponse{ValidatedSubscription: subscription}, nil
+}
+
+func (m *subscriptionNakama) SubscriptionGetByProductId(ctx context.Context, userID, productID string) (*api.ValidatedSubscription, error) {
+	for _, subscription := range m.subscriptions {
+		if subscription.ProductId == productID {
+			return subscription, nil
+		}
+	}
+	return nil, errors.New("subscription not found")
+}
+
+func TestSubscriptions(t *testing.T) {
+	economy := NewNakamaEconomySystem(&EconomyConfig{
+		Subscriptions: &EconomyConfigSubscriptions{
+			Items: map[string]*EconomyConfigSubscription{
+				"monthly_pass": {
+					Name: "Monthly Pass",
+					Sku:  "com.example.monthly",
+					Reward: &EconomyConfigReward{
+						Guaranteed: &EconomyConfigRewardContents{
+							Currencies: map[string]*EconomyConfigRewardCurrency{
+								"gems": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 300, Max: 300}},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	ctx := context.Background()
+	logger := &mockLogger{}
+	userID := "user1"
+	now := time.Now()
+	validated := &api.ValidatedSubscription{
+		ProductId:             "com.example.monthly",
+		OriginalTransactionId: "txn1",
+		Environment:           api.StoreEnvironment_PRODUCTION,
+		ExpiryTime:            timestamppb.New(now.Add(30 * 24 * time.Hour)),
+		Active:                true,
+	}
+	nk := &subscriptionNakama{
+		benchNakama:   newBenchNakama(),
+		subscriptions: map[string]*api.ValidatedSubscription{"receipt1": validated},
+	}
+	apple := EconomyStoreType_ECONOMY_STORE_TYPE_APPLE_APPSTORE
+
+	_, _, err := economy.SubscriptionPurchase(ctx, logger, nk, userID, "yearly_pass", apple, "receipt1")
+	assert.ErrorIs(t, err, ErrEconomyNoSubscription)
+	_, _, err = economy.SubscriptionPurchase(ctx, logger, nk, userID, "monthly_pass", apple, "bad_receipt")
+	assert.ErrorIs(t, err, ErrEconomyReceiptInvalid)
+
+	subscription, reward, err := economy.SubscriptionPurchase(ctx, logger, nk, userID, "monthly_pass", apple, "receipt1")
+	require.NoError(t, err)
+	assert.True(t, subscription.Active)
+	assert.Equal(t, "txn1", subscription.OriginalTransactionId)
+	require.NotNil(t, reward)
+	assert.Equal(t, int64(300), reward.Currencies["gems"])
+
+	// The same period is only rewarded once
+	_, reward, err = economy.SubscriptionPurchase(ctx, logger, nk, userID, "monthly_pass", apple, "receipt1")
+	require.NoError(t, err)
+	assert.Nil(t, reward)
+
+	// A renewal reported by the store is rewarded by the check job
+	validated.ExpiryTime = timestamppb.New(now.Add(60 * 24 * time.Hour))
+	checked, err := economy.SubscriptionsCheck(ctx, logger, nk)
+	require.NoError(t, err)
+	assert.Equal(t, 1, checked)
+	wallet, err := userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), wallet["gems"])
+
+	// Listing refreshes without rewarding the period again
+	list, err := economy.SubscriptionList(ctx, logger, nk, userID)
+	require.NoError(t, err)
+	require.Contains(t, list.Subscriptions, "monthly_pass")
+	assert.Equal(t, validated.ExpiryTime.GetSeconds(), list.Subscriptions["monthly_pass"].ExpiryTimeSec)
+
+	// Renewals after a cancellation aren't rewarded, and the subscription lapses once it expires
+	subscription, err = economy.SubscriptionCancel(ctx, logger, nk, userID, "monthly_pass")
+	require.NoError(t, err)
+	assert.True(t, subscription.Cancelled)
+	assert.True(t, subscription.Active)
+	validated.ExpiryTime = timestamppb.New(now.Add(90 * 24 * time.Hour))
+	_, err = economy.SubscriptionsCheck(ctx, logger, nk)
+	require.NoError(t, err)
+	validated.ExpiryTime = timestamppb.New(now.Add(-time.Hour))
+	list, err = economy.SubscriptionList(ctx, logger, nk, userID)
+	require.NoError(t, err)
+	assert.False(t, list.Subscriptions["monthly_pass"].Active)
+
+	wallet, err = userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), wallet["gems"])
+
+	_, err = economy.SubscriptionCancel(ctx, logger, nk, "user2", "monthly_pass")
+	assert.ErrorIs(t, err, ErrEconomyNoSubscription)
+}
+
+func TestNewNakamaEconomySystem_StoreItemsBySku(t *testing.T) {
+	economy := NewNakamaEconomySystem(&EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"gems_small": {Cost: &EconomyConfigStoreItemCost{Sku: "com.example.gems_small"}},
+			"gems_large": {Cost: &EconomyConfigStoreItemCost{Sku: "com.example.gems_large"}},
+			"chest":      {Cost: &EconomyConfigStoreItemCost{Currencies: map[string]int64{"coins": 100}}},
+			"free":       {},
+		},
+	})
+
+	assert.Equal(t, map[string]string{
+		"com.example.gems_small": "gems_small",
+		"com.example.gems_large": "gems_large",
+	}, economy.storeItemIDsBySku)
+	assert.Empty(t, NewNakamaEconomySystem(nil).storeItemIDsBySku)
+}
+
 func TestPurchaseRestore_Success(t *testing.T) {
 	config := &EconomyConfig{}
 	economy := NewNakamaEconomySystem(config)
@@ -915,6 +1618,7 @@ func TestDonationClaim_SelectiveDonorClaiming(t *testing.T) {
 
 	// Mock storage write for updated donation
 	nk.On("StorageWrite", ctx, mock.Anything).Return([]*api.StorageObjectAck{}, nil)
+	nk.On("StorageDelete", ctx, mock.Anything).Return(nil)
 
 	// Mock wallet update for reward
 	nk.On("WalletUpdate", ctx, userID, mock.Anything, mock.Anything, false).Return(
@@ -1013,6 +1717,7 @@ func TestDonationClaim_ClaimAllAvailable(t *testing.T) {
 
 	// Mock storage write for updated donation
 	nk.On("StorageWrite", ctx, mock.Anything).Return([]*api.StorageObjectAck{}, nil)
+	nk.On("StorageDelete", ctx, mock.Anything).Return(nil)
 
 	// Test: Claim all available (empty donors map)
 	claimDetails := map[string]*EconomyDonationClaimRequestDetails{
@@ -1047,3 +1752,1093 @@ func TestDonationClaim_ClaimAllAvailable(t *testing.T) {
 
 	nk.AssertExpectations(t)
 }
+
+func TestRollWeightedIndices(t *testing.T) {
+	// Always picks the lowest remaining value, so the first group in the pool is selected each roll
+	first := func(n int64) int64 { return 0 }
+	// Always picks the highest value, which lands in the empty weight when there is any
+	last := func(n int64) int64 { return n - 1 }
+
+	countSelections := func(selected []int) map[int]int {
+		counts := make(map[int]int)
+		for _, index := range selected {
+			counts[index]++
+		}
+		return counts
+	}
+
+	testCases := []struct {
+		name           string
+		weights        []int64
+		totalWeight    int64
+		maxRolls       int64
+		maxRepeatRolls int64
+		randN          func(n int64) int64
+		expected       []int
+	}{
+		{name: "single roll", weights: []int64{10, 10}, maxRolls: 1, randN: first, expected: []int{0}},
+		{name: "no repeats by default", weights: []int64{10, 10}, maxRolls: 2, randN: first, expected: []int{0, 1}},
+		{name: "rolls capped by pool without repeats", weights: []int64{10, 10}, maxRolls: 5, randN: first, expected: []int{0, 1}},
+		{name: "repeats up to limit", weights: []int64{10, 10}, maxRolls: 3, maxRepeatRolls: 2, randN: first, expected: []int{0, 0, 1}},
+		{name: "repeat limit above rolls", weights: []int64{10, 10}, maxRolls: 3, maxRepeatRolls: 5, randN: first, expected: []int{0, 0, 0}},
+		{name: "rolls capped by pool with repeats", weights: []int64{10, 10}, maxRolls: 10, maxRepeatRolls: 2, randN: first, expected: []int{0, 0, 1, 1}},
+		{name: "zero weight groups are never selected", weights: []int64{0, 10}, maxRolls: 3, maxRepeatRolls: 3, randN: first, expected: []int{1, 1, 1}},
+		{name: "last group selected", weights: []int64{10, 10}, maxRolls: 2, randN: last, expected: []int{1, 0}},
+		{name: "empty weight selects nothing", weights: []int64{10, 10}, totalWeight: 100, maxRolls: 3, randN: last, expected: []int{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selected := rollWeightedIndices(tc.weights, tc.totalWeight, tc.maxRolls, tc.maxRepeatRolls, tc.randN)
+			assert.Equal(t, tc.expected, selected)
+		})
+	}
+
+	t.Run("no positive weights", func(t *testing.T) {
+		assert.Nil(t, rollWeightedIndices([]int64{0, -5}, 0, 3, 0, first))
+	})
+
+	t.Run("random rolls respect repeat limit", func(t *testing.T) {
+		economy := NewNakamaEconomySystem(nil)
+		randN := func(n int64) int64 { return economy.randomInt64(0, n-1) }
+		for i := 0; i < 200; i++ {
+			selected := rollWeightedIndices([]int64{1, 50, 100}, 0, 5, 2, randN)
+			assert.Len(t, selected, 5)
+			for _, count := range countSelections(selected) {
+				assert.LessOrEqual(t, count, 2)
+			}
+		}
+	})
+}
+
+func TestSelectWeightedStringOption(t *testing.T) {
+	options := map[string]*EconomyConfigRewardStringPropertyOption{
+		"common":    {Weight: 70},
+		"rare":      {Weight: 25},
+		"legendary": {Weight: 5},
+		"disabled":  {Weight: 0},
+	}
+
+	t.Run("deterministic boundaries", func(t *testing.T) {
+		// Sorted order is common, legendary, rare
+		assert.Equal(t, "common", selectWeightedStringOption(options, func(n int64) int64 { return 0 }))
+		assert.Equal(t, "common", selectWeightedStringOption(options, func(n int64) int64 { return 69 }))
+		assert.Equal(t, "legendary", selectWeightedStringOption(options, func(n int64) int64 { return 70 }))
+		assert.Equal(t, "rare", selectWeightedStringOption(options, func(n int64) int64 { return n - 1 }))
+	})
+
+	t.Run("no valid options", func(t *testing.T) {
+		assert.Empty(t, selectWeightedStringOption(nil, func(n int64) int64 { return 0 }))
+		assert.Empty(t, selectWeightedStringOption(map[string]*EconomyConfigRewardStringPropertyOption{"a": {Weight: 0}}, func(n int64) int64 { return 0 }))
+	})
+
+	t.Run("distribution matches weights", func(t *testing.T) {
+		economy := NewNakamaEconomySystem(nil)
+		randN := func(n int64) int64 { return economy.randomInt64(0, n-1) }
+
+		const samples = 100000
+		counts := make(map[string]int)
+		for i := 0; i < samples; i++ {
+			counts[selectWeightedStringOption(options, randN)]++
+		}
+
+		assert.Zero(t, counts[""])
+		assert.Zero(t, counts["disabled"])
+		assert.InDelta(t, 0.70, float64(counts["common"])/samples, 0.01)
+		assert.InDelta(t, 0.25, float64(counts["rare"])/samples, 0.01)
+		assert.InDelta(t, 0.05, float64(counts["legendary"])/samples, 0.01)
+	})
+}
+
+func getRoundTripRewardConfig() *EconomyConfigReward {
+	return &EconomyConfigReward{
+		Guaranteed: &EconomyConfigRewardContents{
+			Currencies: map[string]*EconomyConfigRewardCurrency{
+				"coins": {EconomyConfigRewardRangeInt64{Min: 100, Max: 200, Multiple: 10}},
+			},
+			Items: map[string]*EconomyConfigRewardItem{
+				"sword": {
+					EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 1, Max: 1},
+					StringProperties: map[string]*EconomyConfigRewardStringProperty{
+						"rarity": {
+							TotalWeight: 100,
+							Options: map[string]*EconomyConfigRewardStringPropertyOption{
+								"common": {Weight: 90},
+								"rare":   {Weight: 10},
+							},
+						},
+					},
+					NumericProperties: map[string]*EconomyConfigRewardRangeFloat64{
+						"damage": {Min: 1.5, Max: 3.5, Multiple: 0.5},
+					},
+				},
+			},
+			Energies: map[string]*EconomyConfigRewardEnergy{
+				"lives": {EconomyConfigRewardRangeInt32{Min: 1, Max: 3, Multiple: 1}},
+			},
+			ItemSets: []*EconomyConfigRewardItemSet{
+				{
+					EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 1, Max: 2},
+					MaxRepeats:                    1,
+					Set:                           []string{"weapon", "common"},
+				},
+			},
+			EnergyModifiers: []*EconomyConfigRewardEnergyModifier{
+				{
+					Id:          "lives",
+					Operator:    "infinite",
+					Value:       &EconomyConfigRewardRangeInt64{Min: 1, Max: 1},
+					DurationSec: &EconomyConfigRewardRangeUInt64{Min: 600, Max: 1200, Multiple: 60},
+				},
+			},
+			RewardModifiers: []*EconomyConfigRewardRewardModifier{
+				{
+					Id:          "coins",
+					Type:        "currency",
+					Operator:    "multiplier",
+					Value:       &EconomyConfigRewardRangeInt64{Min: 2, Max: 2},
+					DurationSec: &EconomyConfigRewardRangeUInt64{Min: 3600, Max: 3600},
+				},
+			},
+		},
+		Weighted: []*EconomyConfigRewardContents{
+			{
+				Weight: 75,
+				Currencies: map[string]*EconomyConfigRewardCurrency{
+					"gems": {EconomyConfigRewardRangeInt64{Min: 1, Max: 5}},
+				},
+			},
+			{
+				Weight: 25,
+				Items: map[string]*EconomyConfigRewardItem{
+					"shield": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 1, Max: 1}},
+				},
+			},
+		},
+		MaxRolls:       2,
+		MaxRepeatRolls: 1,
+		TotalWeight:    100,
+	}
+}
+
+const roundTripAvailableRewardsGolden = `{
+	"guaranteed": {
+		"currencies": {"coins": {"count": {"min": 100, "max": 200, "multiple": 10}}},
+		"items": {
+			"sword": {
+				"count": {"min": 1, "max": 1},
+				"string_properties": {
+					"rarity": {"total_weight": 100, "options": {"common": {"weight": 90}, "rare": {"weight": 10}}}
+				},
+				"numeric_properties": {"damage": {"min": 1.5, "max": 3.5, "multiple": 0.5}}
+			}
+		},
+		"energies": {"lives": {"count": {"min": 1, "max": 3, "multiple": 1}}},
+		"item_sets": [{"count": {"min": 1, "max": 2}, "max_repeats": 1, "set": ["weapon", "common"]}],
+		"energy_modifiers": [
+			{"id": "lives", "operator": "infinite", "value": {"min": 1, "max": 1}, "duration_sec": {"min": 600, "max": 1200, "multiple": 60}}
+		],
+		"reward_modifiers": [
+			{"id": "coins", "type": "currency", "operator": "multiplier", "value": {"min": 2, "max": 2}, "duration_sec": {"min": 3600, "max": 3600}}
+		]
+	},
+	"weighted": [
+		{"currencies": {"gems": {"count": {"min": 1, "max": 5}}}, "weight": 75},
+		{"items": {"shield": {"count": {"min": 1, "max": 1}}}, "weight": 25}
+	],
+	"max_rolls": 2,
+	"total_weight": 100,
+	"max_repeat_rolls": 1
+}`
+
+func TestRewardConvertRoundTrip(t *testing.T) {
+	economy := NewNakamaEconomySystem(nil)
+
+	t.Run("nil", func(t *testing.T) {
+		assert.Nil(t, economy.RewardConvert(nil))
+		assert.Nil(t, economy.RewardConvertReverse(nil))
+	})
+
+	t.Run("config to wire matches golden", func(t *testing.T) {
+		available := economy.RewardConvertReverse(getRoundTripRewardConfig())
+		data, err := json.Marshal(available)
+		require.NoError(t, err)
+		assert.JSONEq(t, roundTripAvailableRewardsGolden, string(data))
+	})
+
+	t.Run("config survives round trip", func(t *testing.T) {
+		config := getRoundTripRewardConfig()
+		assert.Equal(t, config, economy.RewardConvert(economy.RewardConvertReverse(config)))
+	})
+
+	t.Run("wire survives round trip", func(t *testing.T) {
+		available := &AvailableRewards{}
+		require.NoError(t, json.Unmarshal([]byte(roundTripAvailableRewardsGolden), available))
+		converted := economy.RewardConvertReverse(economy.RewardConvert(available))
+		assert.True(t, proto.Equal(available, converted), "expected %v, got %v", available, converted)
+	})
+
+	t.Run("energy modifier duration is kept", func(t *testing.T) {
+		config := economy.RewardConvert(economy.RewardConvertReverse(getRoundTripRewardConfig()))
+		require.Len(t, config.Guaranteed.EnergyModifiers, 1)
+		assert.Equal(t, &EconomyConfigRewardRangeUInt64{Min: 600, Max: 1200, Multiple: 60}, config.Guaranteed.EnergyModifiers[0].DurationSec)
+	})
+}
+
+func TestCapEnergyGrant(t *testing.T) {
+	assert.Equal(t, int32(5), capEnergyGrant(2, 3, 0), "no known max")
+	assert.Equal(t, int32(5), capEnergyGrant(2, 3, 10))
+	assert.Equal(t, int32(10), capEnergyGrant(8, 5, 10), "capped at max")
+	assert.Equal(t, int32(12), capEnergyGrant(12, 5, 10), "existing overfill is kept")
+	assert.Equal(t, int32(0), capEnergyGrant(2, -5, 10), "never negative")
+}
+
+func TestUpdateEnergies_NewUserWithoutEnergySystem(t *testing.T) {
+	economy := NewNakamaEconomySystem(&EconomyConfig{})
+	logger := &mockLogger{}
+	nk := NewMockNakama(t)
+	ctx := context.Background()
+	userID := "new_user"
+
+	nk.On("WalletUpdate", ctx, userID, mock.Anything, mock.Anything, false).Return(map[string]int64{}, map[string]int64{}, nil)
+	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{}, nil)
+	nk.On("StorageWrite", ctx, mock.MatchedBy(func(writes []*runtime.StorageWrite) bool {
+		if len(writes) != 1 || writes[0].Version != "" || writes[0].Key != userEnergyStorageKey {
+			return false
+		}
+		energyList := &EnergyList{}
+		if err := json.Unmarshal([]byte(writes[0].Value), energyList); err != nil {
+			return false
+		}
+		return energyList.Energies["lives"] != nil && energyList.Energies["lives"].Current == 3
+	})).Return([]*api.StorageObjectAck{}, nil)
+
+	require.NotPanics(t, func() {
+		_, _, _, err := economy.RewardGrant(ctx, logger, nk, userID, &Reward{Energies: map[string]int32{"lives": 3}}, nil, false)
+		require.NoError(t, err)
+	})
+	nk.AssertExpectations(t)
+}
+
+func TestPurchaseTransactionsExport(t *testing.T) {
+	gemsReward := func(amount int64) *EconomyConfigReward {
+		return &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+			Currencies: map[string]*EconomyConfigRewardCurrency{"gems": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: amount, Max: amount}}},
+		}}
+	}
+	economy := NewNakamaEconomySystem(&EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"gems_small": {Cost: &EconomyConfigStoreItemCost{Sku: "com.example.gems_small"}, Reward: gemsReward(100)},
+			"gems_large": {Cost: &EconomyConfigStoreItemCost{Sku: "com.example.gems_large"}, Reward: gemsReward(500)},
+		},
+	})
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	ctx := context.Background()
+
+	validated := func(productID string, environment api.StoreEnvironment) *api.ValidatePurchaseResponse {
+		return &api.ValidatePurchaseResponse{ValidatedPurchases: []*api.ValidatedPurchase{{
+			ProductId:     productID,
+			TransactionId: productID + "_transaction",
+			PurchaseTime:  timestamppb.Now(),
+			Environment:   environment,
+		}}}
+	}
+	nk.MockNakamaModule.On("PurchaseValidateApple", ctx, "user1", "receipt_one", true, []string(nil)).Return(
+		validated("com.example.gems_small", api.StoreEnvironment_PRODUCTION), nil)
+	nk.MockNakamaModule.On("PurchaseValidateGoogle", ctx, "user2", "receipt_two", true, []struct {
+		ClientEmail string
+		PrivateKey  string
+	}(nil)).Return(validated("com.example.gems_large", api.StoreEnvironment_SANDBOX), nil)
+
+	// The exported purchases are recorded by the purchases themselves
+	now := time.Now().Unix()
+	_, _, _, _, err := economy.PurchaseItem(ctx, logger, nil, nk, "user1", "gems_small", EconomyStoreType_ECONOMY_STORE_TYPE_APPLE_APPSTORE, "receipt_one")
+	require.NoError(t, err)
+	_, _, _, _, err = economy.PurchaseItem(ctx, logger, nil, nk, "user2", "gems_large", EconomyStoreType_ECONOMY_STORE_TYPE_GOOGLE_PLAY, "receipt_two")
+	require.NoError(t, err)
+
+	objects, _, err := nk.StorageList(ctx, "", "user1", purchaseTransactionsCollection, 10, "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Contains(t, objects[0].Value, `"store_type":"ECONOMY_STORE_TYPE_APPLE_APPSTORE"`)
+
+	// Older transactions stored the store type as its enum value, and the oldest none at all
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{Collection: purchaseTransactionsCollection, Key: "tx3", UserID: "user3", Value: `{"id":"tx3","user_id":"user3","item_id":"gems_small","store_type":"\u0002","receipt":"receipt_three","timestamp":1000,"sandbox":false}`},
+		{Collection: purchaseTransactionsCollection, Key: "tx4", UserID: "user4", Value: `{"id":"tx4","user_id":"user4","item_id":"gems_small","timestamp":5000}`},
+	})
+	require.NoError(t, err)
+
+	t.Run("ndjson", func(t *testing.T) {
+		export, err := economy.PurchaseTransactionsExport(ctx, logger, nk, now-60, 0, "", 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, EconomyExportFormatNDJSON, export.Format)
+		assert.Equal(t, 2, export.Count)
+		assert.Empty(t, export.NextCursor)
+		assert.Empty(t, export.PrevCursor)
+
+		lines := strings.Split(strings.TrimSpace(export.Data), "\n")
+		require.Len(t, lines, 2)
+		var first, second purchaseTransactionExportRow
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+		assert.Equal(t, "user1", first.UserId)
+		assert.Equal(t, "ECONOMY_STORE_TYPE_APPLE_APPSTORE", first.StoreType)
+		assert.Equal(t, hashReceipt("receipt_one"), first.ReceiptHash)
+		assert.False(t, first.Sandbox)
+		var reward Reward
+		require.NoError(t, json.Unmarshal(first.Reward, &reward))
+		assert.Equal(t, int64(100), reward.Currencies["gems"])
+		assert.Equal(t, "user2", second.UserId)
+		assert.Equal(t, "ECONOMY_STORE_TYPE_GOOGLE_PLAY", second.StoreType)
+		assert.True(t, second.Sandbox)
+		assert.NotContains(t, export.Data, "receipt_one")
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		export, err := economy.PurchaseTransactionsExport(ctx, logger, nk, 500, 6000, EconomyExportFormatCSV, 0, "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, export.Count)
+
+		lines := strings.Split(strings.TrimSpace(export.Data), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "id,user_id,item_id,store_type,receipt_hash,transaction_id,sandbox,restore,timestamp,reward", lines[0])
+		assert.Equal(t, "tx3,user3,gems_small,ECONOMY_STORE_TYPE_GOOGLE_PLAY,"+hashReceipt("receipt_three")+",,false,false,1000,", lines[1])
+		assert.Equal(t, "tx4,user4,gems_small,,,,false,false,5000,", lines[2])
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		export, err := economy.PurchaseTransactionsExport(ctx, logger, nk, 0, 0, "", 2, "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, export.Count)
+		assert.Equal(t, export.NextCursor, export.Cursor)
+		storageCursor, _, err := decodeNakamaCursor(export.NextCursor)
+		require.NoError(t, err)
+		assert.Equal(t, "2", storageCursor)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err := economy.PurchaseTransactionsExport(ctx, logger, nk, 0, 0, "xml", 0, "")
+		assert.Error(t, err)
+		_, err = economy.PurchaseTransactionsExport(ctx, logger, nk, 2000, 1000, EconomyExportFormatCSV, 0, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestPurchaseItem_ExpiredIntent(t *testing.T) {
+	config := &EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"premium_pack": {
+				Name: "Premium Pack",
+				Cost: &EconomyConfigStoreItemCost{
+					Sku: "com.example.premiumpack",
+				},
+			},
+		},
+	}
+	economy := NewNakamaEconomySystem(config)
+	nk := NewMockNakama(t)
+	ctx := context.Background()
+	userID := "user1"
+
+	expiredIntent := fmt.Sprintf(`{"item_id":"premium_pack","is_consumed":false,"expires_at":%d}`, time.Now().Unix()-10)
+	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{
+		{Collection: purchaseIntentsCollection, Key: "purchase_intent:user1:premium_pack", UserId: userID, Value: expiredIntent, Version: "v1"},
+	}, nil)
+
+	_, _, _, _, err := economy.PurchaseItem(ctx, &mockLogger{}, nil, nk, userID, "premium_pack", EconomyStoreType_ECONOMY_STORE_TYPE_APPLE_APPSTORE, "receipt")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "purchase intent expired")
+	nk.AssertNotCalled(t, "PurchaseValidateApple", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPurchaseIntentCancel(t *testing.T) {
+	economy := NewNakamaEconomySystem(&EconomyConfig{})
+	ctx := context.Background()
+	userID := "user1"
+	intentKey := "purchase_intent:user1:premium_pack"
+
+	t.Run("deletes the intent", func(t *testing.T) {
+		nk := NewMockNakama(t)
+		nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{
+			{Collection: purchaseIntentsCollection, Key: intentKey, UserId: userID, Value: `{"item_id":"premium_pack","is_consumed":false}`, Version: "v1"},
+		}, nil)
+		nk.On("StorageDelete", ctx, []*runtime.StorageDelete{
+			{Collection: purchaseIntentsCollection, Key: intentKey, UserID: userID, Version: "v1"},
+		}).Return(nil).Once()
+
+		err := economy.PurchaseIntentCancel(ctx, &mockLogger{}, nk, userID, "premium_pack")
+
+		require.NoError(t, err)
+		nk.AssertExpectations(t)
+	})
+
+	t.Run("rejects consumed intents", func(t *testing.T) {
+		nk := NewMockNakama(t)
+		nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{
+			{Collection: purchaseIntentsCollection, Key: intentKey, UserId: userID, Value: `{"item_id":"premium_pack","is_consumed":true}`, Version: "v1"},
+		}, nil)
+
+		err := economy.PurchaseIntentCancel(ctx, &mockLogger{}, nk, userID, "premium_pack")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already consumed")
+		nk.AssertNotCalled(t, "StorageDelete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing intent", func(t *testing.T) {
+		nk := NewMockNakama(t)
+		nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{}, nil)
+
+		err := economy.PurchaseIntentCancel(ctx, &mockLogger{}, nk, userID, "premium_pack")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestPurchaseIntentsCleanup(t *testing.T) {
+	economy := NewNakamaEconomySystem(&EconomyConfig{})
+	nk := NewMockNakama(t)
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	nk.On("StorageList", ctx, "", "", purchaseIntentsCollection, 100, "").Return([]*api.StorageObject{
+		{Key: "purchase_intent:user1:a", UserId: "user1", Version: "v1", Value: fmt.Sprintf(`{"is_consumed":false,"expires_at":%d}`, now-60)},
+		{Key: "purchase_intent:user2:b", UserId: "user2", Version: "v2", Value: fmt.Sprintf(`{"is_consumed":true,"expires_at":%d}`, now-60)},
+		{Key: "purchase_intent:user3:c", UserId: "user3", Version: "v3", Value: fmt.Sprintf(`{"is_consumed":false,"expires_at":%d}`, now+600)},
+	}, "", nil)
+	nk.On("StorageDelete", ctx, mock.MatchedBy(func(deletes []*runtime.StorageDelete) bool {
+		return len(deletes) == 2 && deletes[0].UserID == "user1" && deletes[1].UserID == "user2"
+	})).Return(nil).Once()
+
+	removed, err := economy.PurchaseIntentsCleanup(ctx, &mockLogger{}, nk)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	nk.AssertExpectations(t)
+}
+
+func TestClampCurrencyGrant(t *testing.T) {
+	caps := map[string]int64{"coins": 100, "gems": 10}
+	granted := map[string]int64{"coins": 80, "gems": 12}
+	requested := map[string]int64{"coins": 50, "gems": 5, "energy": 7}
+
+	allowed, capped := clampCurrencyGrant(caps, granted, requested)
+
+	assert.Equal(t, map[string]int64{"coins": 20, "energy": 7}, allowed)
+	assert.Equal(t, map[string]int64{"coins": 30, "gems": 5}, capped)
+}
+
+// racingCapNakama counts a grant from another request towards the cap before the first multi update, as a concurrent
+// grant from the same source would.
+type racingCapNakama struct {
+	*benchNakama
+	raced bool
+}
+
+func (n *racingCapNakama) MultiUpdate(ctx context.Context, accountUpdates []*runtime.AccountUpdate, storageWrites []*runtime.StorageWrite, storageDeletes []*runtime.StorageDelete, walletUpdates []*runtime.WalletUpdate, updateLedger bool) ([]*api.StorageObjectAck, []*runtime.WalletUpdateResult, error) {
+	if !n.raced {
+		n.raced = true
+		for _, write := range storageWrites {
+			if write.Collection == currencyGrantCapsCollection {
+				value := fmt.Sprintf(`{"reset_time_sec":%d,"granted":{"%s":95}}`, time.Now().Unix()+3600, benchCurrency)
+				if _, err := n.benchNakama.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: write.Collection, Key: write.Key, UserID: write.UserID, Value: value}}); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+	return n.benchNakama.MultiUpdate(ctx, accountUpdates, storageWrites, storageDeletes, walletUpdates, updateLedger)
+}
+
+func TestRewardGrantCapped(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	userID := "user1"
+
+	newEconomy := func() *NakamaEconomySystem {
+		economy := newBenchPamlogix().GetEconomySystem().(*NakamaEconomySystem)
+		economy.config.CurrencyDailyCaps = map[string]map[string]int64{
+			EconomyGrantSourcePlacement: {benchCurrency: 100},
+		}
+		return economy
+	}
+	setGranted := func(t *testing.T, nk runtime.NakamaModule, resetTimeSec, granted int64) {
+		value := fmt.Sprintf(`{"reset_time_sec":%d,"granted":{"%s":%d}}`, resetTimeSec, benchCurrency, granted)
+		_, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: currencyGrantCapsCollection, Key: EconomyGrantSourcePlacement, UserID: userID, Value: value}})
+		require.NoError(t, err)
+	}
+	granted := func(t *testing.T, nk runtime.NakamaModule) int64 {
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: currencyGrantCapsCollection, Key: EconomyGrantSourcePlacement, UserID: userID}})
+		require.NoError(t, err)
+		if len(objects) == 0 {
+			return 0
+		}
+		state := &currencyGrantCapState{}
+		require.NoError(t, json.Unmarshal([]byte(objects[0].Value), state))
+		return state.Granted[benchCurrency]
+	}
+	balance := func(t *testing.T, nk runtime.NakamaModule) int64 {
+		wallet, err := userWallet(ctx, nk, userID)
+		require.NoError(t, err)
+		return wallet[benchCurrency]
+	}
+
+	t.Run("clamps against today's grants", func(t *testing.T) {
+		nk := newBenchNakama()
+		setGranted(t, nk, time.Now().Unix()+3600, 90)
+
+		reward := &Reward{Currencies: map[string]int64{benchCurrency: 25, "gems": 3}}
+		capped, err := newEconomy().RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourcePlacement, reward, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{benchCurrency: 15}, capped)
+		assert.Equal(t, map[string]int64{benchCurrency: 10, "gems": 3}, reward.Currencies)
+		assert.Equal(t, int64(10), balance(t, nk))
+		assert.Equal(t, int64(100), granted(t, nk))
+	})
+
+	t.Run("resets on a new day", func(t *testing.T) {
+		nk := newBenchNakama()
+		setGranted(t, nk, time.Now().Unix()-10, 100)
+
+		reward := &Reward{Currencies: map[string]int64{benchCurrency: 25}}
+		capped, err := newEconomy().RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourcePlacement, reward, nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, capped)
+		assert.Equal(t, int64(25), balance(t, nk))
+		assert.Equal(t, int64(25), granted(t, nk))
+	})
+
+	t.Run("a failed grant doesn't count towards the cap", func(t *testing.T) {
+		nk := &failingMultiUpdateNakama{benchNakama: newBenchNakama()}
+		setGranted(t, nk, time.Now().Unix()+3600, 90)
+
+		reward := &Reward{Currencies: map[string]int64{benchCurrency: 25}}
+		_, err := newEconomy().RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourcePlacement, reward, nil)
+
+		require.Error(t, err)
+		assert.Equal(t, map[string]int64{benchCurrency: 25}, reward.Currencies)
+		assert.Equal(t, int64(0), balance(t, nk))
+		assert.Equal(t, int64(90), granted(t, nk))
+	})
+
+	t.Run("retries when another grant updates the cap first", func(t *testing.T) {
+		nk := &racingCapNakama{benchNakama: newBenchNakama()}
+		setGranted(t, nk, time.Now().Unix()+3600, 90)
+
+		reward := &Reward{Currencies: map[string]int64{benchCurrency: 25}}
+		capped, err := newEconomy().RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourcePlacement, reward, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{benchCurrency: 20}, capped)
+		assert.Equal(t, int64(5), balance(t, nk))
+		assert.Equal(t, int64(100), granted(t, nk))
+	})
+
+	t.Run("sources without caps are untouched", func(t *testing.T) {
+		nk := newBenchNakama()
+
+		reward := &Reward{Currencies: map[string]int64{benchCurrency: 500}}
+		capped, err := newEconomy().RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourceReferral, reward, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, capped)
+		assert.Equal(t, int64(500), balance(t, nk))
+		assert.Equal(t, int64(0), granted(t, nk))
+	})
+}
+
+func TestStoreItemConditions(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+
+	progressionSystem := NewNakamaProgressionSystem(&ProgressionConfig{
+		Progressions: map[string]*ProgressionConfigProgression{
+			"chapter1": {Preconditions: &ProgressionPreconditionsBlock{Direct: &ProgressionPreconditions{Counts: map[string]int64{"levels": 3}}}},
+		},
+	})
+	progressionSystem.SetPamlogix(p)
+	p.systems[SystemTypeProgression] = progressionSystem
+	statsSystem := NewStatsSystem(&StatsConfig{})
+	p.systems[SystemTypeStats] = statsSystem
+
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"starter": {Name: "Starter pack"},
+			"veteran": {Name: "Veteran pack", Conditions: &EconomyConfigStoreItemConditions{
+				Progressions: []string{"chapter1"},
+				MinStats:     map[string]int64{"wins": 5},
+			}},
+			"secret": {Name: "Secret pack", Conditions: &EconomyConfigStoreItemConditions{Progressions: []string{"chapter1"}, Hidden: true}},
+		},
+	})
+	economySystem.SetPamlogix(p)
+	p.systems[SystemTypeEconomy] = economySystem
+
+	require.NoError(t, progressionSystem.saveUserProgressions(ctx, logger, nk, "user1", map[string]*SyncProgressionUpdate{
+		"chapter1": {Counts: map[string]int64{"levels": 3}},
+	}))
+	_, err := statsSystem.Update(ctx, logger, nk, "user1", []*StatUpdate{{Name: "wins", Value: 10, Operator: StatUpdateOperator_STAT_UPDATE_OPERATOR_SET}}, nil)
+	require.NoError(t, err)
+
+	storeItems, _, _, _, err := economySystem.List(ctx, logger, nk, "user2")
+	require.NoError(t, err)
+	assert.NotContains(t, storeItems, "secret")
+	assert.False(t, storeItems["starter"].Unavailable)
+	assert.True(t, storeItems["veteran"].Unavailable)
+	assert.False(t, economySystem.config.StoreItems["veteran"].Unavailable)
+
+	err = economySystem.PurchaseIntent(ctx, logger, nk, "user2", "veteran", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, "")
+	assert.ErrorContains(t, err, "conditions not met")
+
+	storeItems, _, _, _, err = economySystem.List(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	assert.Len(t, storeItems, 3)
+	assert.False(t, storeItems["veteran"].Unavailable)
+	require.NoError(t, economySystem.PurchaseIntent(ctx, logger, nk, "user1", "veteran", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, ""))
+}
+
+func TestStoreItemConditions_Rule(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	statsSystem := NewStatsSystem(&StatsConfig{})
+	p.systems[SystemTypeStats] = statsSystem
+
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"veteran": {Name: "Veteran pack", Conditions: &EconomyConfigStoreItemConditions{Rule: `stats.wins >= 10 && wallet.coins < 100`}},
+			// A rule which doesn't compile is never met
+			"broken": {Name: "Broken pack", Conditions: &EconomyConfigStoreItemConditions{Rule: `stats.wins >=`}},
+		},
+	})
+	economySystem.SetPamlogix(p)
+	p.systems[SystemTypeEconomy] = economySystem
+
+	_, err := statsSystem.Update(ctx, logger, nk, "user1", []*StatUpdate{{Name: "wins", Value: 10, Operator: StatUpdateOperator_STAT_UPDATE_OPERATOR_SET}}, nil)
+	require.NoError(t, err)
+
+	storeItems, _, _, _, err := economySystem.List(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	assert.False(t, storeItems["veteran"].Unavailable)
+	assert.True(t, storeItems["broken"].Unavailable)
+	require.NoError(t, economySystem.PurchaseIntent(ctx, logger, nk, "user1", "veteran", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, ""))
+
+	_, _, err = nk.WalletUpdate(ctx, "user1", map[string]int64{"coins": 100}, nil, false)
+	require.NoError(t, err)
+	err = economySystem.PurchaseIntent(ctx, logger, nk, "user1", "veteran", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, "")
+	assert.ErrorContains(t, err, "conditions not met")
+}
+
+func TestCurrencyMaxBalances(t *testing.T) {
+	economy := NewNakamaEconomySystem(&EconomyConfig{
+		Currencies: map[string]*EconomyConfigCurrency{
+			"coins": {Name: "Coins", Icon: "icons/coins.png", MaxBalance: 1000},
+			"gems":  {Name: "Gems", DecimalPlaces: 2},
+		},
+	})
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	userID := "user1"
+
+	_, _, err := nk.WalletUpdate(ctx, userID, map[string]int64{"coins": 950}, nil, false)
+	require.NoError(t, err)
+
+	reward := &Reward{Currencies: map[string]int64{"coins": 100, "gems": 5000}}
+	_, _, _, err = economy.RewardGrant(ctx, logger, nk, userID, reward, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"coins": 50, "gems": 5000}, reward.Currencies)
+
+	wallet, err := userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), wallet["coins"])
+
+	// Spending below the cap is never blocked
+	_, _, _, err = economy.RewardGrant(ctx, logger, nk, userID, &Reward{Currencies: map[string]int64{"coins": -200}}, nil, false)
+	require.NoError(t, err)
+
+	p := &pamlogixImpl{systems: map[SystemType]System{SystemTypeEconomy: economy}}
+	response, err := rpcEconomyCurrencies_Json(p)(context.WithValue(ctx, runtime.RUNTIME_CTX_USER_ID, userID), logger, nil, nk, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"currencies":{"coins":{"name":"Coins","icon":"icons/coins.png","max_balance":1000},"gems":{"name":"Gems","decimal_places":2}}}`, response)
+}
+
+func TestDonationClaim_GrantsClaimedUnitsTogether(t *testing.T) {
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	economy := p.GetEconomySystem().(*NakamaEconomySystem)
+	economy.config.Donations = map[string]*EconomyConfigDonation{
+		"don1": {
+			MaxCount: 50,
+			RecipientReward: &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+				Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 10}}},
+				Items:      map[string]*EconomyConfigRewardItem{"potion": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 1}}},
+			}},
+		},
+	}
+
+	// Claiming 2 units costs as many storage calls as claiming 20
+	claim := func(userID string, count int64) int64 {
+		donation := &EconomyDonation{
+			UserId:       userID,
+			Id:           "don1",
+			Count:        count,
+			MaxCount:     50,
+			Contributors: []*EconomyDonationContributor{{UserId: "donor", Count: count}},
+		}
+		donationData, err := json.Marshal(donation)
+		require.NoError(t, err)
+		_, err = nk.StorageWrite(context.Background(), []*runtime.StorageWrite{{Collection: donationsStorageCollection, Key: "donation:don1", UserID: userID, Value: string(donationData)}})
+		require.NoError(t, err)
+
+		counts := &storageOpCounts{}
+		ctx := withStorageOpCounts(context.Background(), counts)
+		donations, err := economy.DonationClaim(ctx, logger, nk, userID, map[string]*EconomyDonationClaimRequestDetails{"don1": {}})
+		require.NoError(t, err)
+		require.Len(t, donations.Donations, 1)
+		require.Len(t, donations.Donations[0].RecipientRewards, 1)
+		assert.Equal(t, count*10, donations.Donations[0].RecipientRewards[0].Currencies[benchCurrency])
+
+		wallet, err := userWallet(ctx, nk, userID)
+		require.NoError(t, err)
+		assert.Equal(t, count*10, wallet[benchCurrency])
+		return counts.total()
+	}
+
+	assert.Equal(t, claim("recipient1", 2), claim("recipient2", 20))
+}
+
+func TestPurchaseGrantsReconcile_RetriesFailedGrant(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economy := newBenchPamlogix().GetEconomySystem().(*NakamaEconomySystem)
+
+	// Taking currency the user doesn't have makes the wallet update fail. The grant was kept pending before store types
+	// were stored by name
+	grant := &EconomyPendingGrant{
+		TransactionId: "transaction1",
+		ItemId:        "starter_pack",
+		StoreType:     string(rune(EconomyStoreType_ECONOMY_STORE_TYPE_GOOGLE_PLAY)),
+		Reward:        &Reward{Currencies: map[string]int64{benchCurrency: -10}},
+		CreateTimeSec: time.Now().Unix(),
+	}
+	_, _, err := economy.grantPurchaseReward(ctx, logger, nk, "user1", grant)
+	require.Error(t, err)
+
+	reconcile, err := economy.PurchaseGrantsReconcile(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	assert.Empty(t, reconcile.Granted)
+	require.Len(t, reconcile.Pending, 1)
+	assert.Equal(t, "transaction1", reconcile.Pending[0].TransactionId)
+	assert.Equal(t, "ECONOMY_STORE_TYPE_GOOGLE_PLAY", reconcile.Pending[0].StoreType)
+	assert.Equal(t, 2, reconcile.Pending[0].Attempts)
+	assert.NotEmpty(t, reconcile.Pending[0].LastError)
+
+	_, _, err = nk.WalletUpdate(ctx, "user1", map[string]int64{benchCurrency: 50}, nil, false)
+	require.NoError(t, err)
+	reconcile, err = economy.PurchaseGrantsReconcile(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	require.Len(t, reconcile.Granted, 1)
+	assert.Empty(t, reconcile.Pending)
+	assert.Equal(t, int64(40), reconcile.Wallet[benchCurrency])
+
+	// Once granted it is never granted again
+	granted, err := economy.PurchaseGrantsRetry(ctx, logger, nk)
+	require.NoError(t, err)
+	assert.Zero(t, granted)
+	wallet, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(40), wallet[benchCurrency])
+}
+
+func TestRewardGrant_IdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economy := newBenchPamlogix().GetEconomySystem().(*NakamaEconomySystem)
+
+	metadata := map[string]interface{}{EconomyMetadataIdempotencyKey: "request-1"}
+	reward := func() *Reward {
+		return &Reward{Currencies: map[string]int64{benchCurrency: 10}, Items: map[string]int64{"sword": 1}}
+	}
+
+	newItems, _, _, err := economy.RewardGrant(ctx, logger, nk, "user1", reward(), metadata, false)
+	require.NoError(t, err)
+	require.Len(t, newItems, 1)
+
+	// A retry of the same grant returns its outcome without granting again
+	retriedItems, _, _, err := economy.RewardGrant(ctx, logger, nk, "user1", reward(), metadata, false)
+	require.NoError(t, err)
+	assert.Equal(t, newItems, retriedItems)
+	wallet, _, _, err := economy.Grant(ctx, logger, nk, "user1", map[string]int64{benchCurrency: 10}, nil, nil, nil, metadata)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), wallet[benchCurrency])
+	inventory, err := newBenchPamlogix().GetInventorySystem().ListInventoryItems(ctx, logger, nk, "user1", "")
+	require.NoError(t, err)
+	assert.Len(t, inventory.Items, 1)
+
+	// Keys are per user, and other keys grant again
+	_, _, _, err = economy.RewardGrant(ctx, logger, nk, "user2", reward(), metadata, false)
+	require.NoError(t, err)
+	wallet, _, _, err = economy.Grant(ctx, logger, nk, "user1", map[string]int64{benchCurrency: 10}, nil, nil, nil, map[string]interface{}{EconomyMetadataIdempotencyKey: "request-2"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), wallet[benchCurrency])
+
+	_, _, _, err = economy.RewardGrant(ctx, logger, nk, "user1", reward(), map[string]interface{}{EconomyMetadataIdempotencyKey: 42}, false)
+	assert.ErrorIs(t, err, ErrEconomyBadIdempotencyKey)
+}
+
+// failingMultiUpdateNakama rejects the first multi update, as a storage write failing part way through a grant would.
+type failingMultiUpdateNakama struct {
+	*benchNakama
+	failed bool
+}
+
+func (n *failingMultiUpdateNakama) MultiUpdate(ctx context.Context, accountUpdates []*runtime.AccountUpdate, storageWrites []*runtime.StorageWrite, storageDeletes []*runtime.StorageDelete, walletUpdates []*runtime.WalletUpdate, updateLedger bool) ([]*api.StorageObjectAck, []*runtime.WalletUpdateResult, error) {
+	if !n.failed {
+		n.failed = true
+		return nil, nil, errors.New("storage write rejected")
+	}
+	return n.benchNakama.MultiUpdate(ctx, accountUpdates, storageWrites, storageDeletes, walletUpdates, updateLedger)
+}
+
+func TestRewardGrant_IdempotencyKeyRetryAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := &failingMultiUpdateNakama{benchNakama: newBenchNakama()}
+	p := newBenchPamlogix()
+	economy := p.GetEconomySystem().(*NakamaEconomySystem)
+
+	metadata := map[string]interface{}{EconomyMetadataIdempotencyKey: "request-1"}
+	reward := func() *Reward {
+		return &Reward{Currencies: map[string]int64{benchCurrency: 10}, Items: map[string]int64{"sword": 1}}
+	}
+
+	// A failed write grants nothing and records nothing, so the grant can be retried with the same key
+	_, _, _, err := economy.RewardGrant(ctx, logger, nk, "user1", reward(), metadata, false)
+	require.Error(t, err)
+	wallet, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Zero(t, wallet[benchCurrency])
+
+	newItems, _, _, err := economy.RewardGrant(ctx, logger, nk, "user1", reward(), metadata, false)
+	require.NoError(t, err)
+	require.Len(t, newItems, 1)
+	retriedItems, _, _, err := economy.RewardGrant(ctx, logger, nk, "user1", reward(), metadata, false)
+	require.NoError(t, err)
+	assert.Equal(t, newItems, retriedItems)
+
+	wallet, err = userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), wallet[benchCurrency])
+	inventory, err := p.GetInventorySystem().ListInventoryItems(ctx, logger, nk, "user1", "")
+	require.NoError(t, err)
+	assert.Len(t, inventory.Items, 1)
+}
+
+func TestPurchaseItem_VirtualCurrency(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"potion_pack": {
+				Name: "Potion pack",
+				Cost: &EconomyConfigStoreItemCost{Currencies: map[string]int64{benchCurrency: 30}},
+				Reward: &EconomyConfigReward{
+					Guaranteed: &EconomyConfigRewardContents{
+						Items: map[string]*EconomyConfigRewardItem{
+							"potion": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 2, Max: 2}},
+						},
+					},
+				},
+			},
+		},
+	})
+	economySystem.SetPamlogix(p)
+	p.systems[SystemTypeEconomy] = economySystem
+
+	_, _, err := nk.WalletUpdate(ctx, "user1", map[string]int64{benchCurrency: 50}, nil, false)
+	require.NoError(t, err)
+	require.NoError(t, economySystem.PurchaseIntent(ctx, logger, nk, "user1", "potion_pack", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, ""))
+
+	// No receipt is needed, the cost comes from the wallet and the intent is consumed
+	wallet, inventory, reward, isSandbox, err := economySystem.PurchaseItem(ctx, logger, nil, nk, "user1", "potion_pack", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, "")
+	require.NoError(t, err)
+	assert.False(t, isSandbox)
+	assert.Equal(t, int64(20), wallet[benchCurrency])
+	assert.Equal(t, int64(2), reward.Items["potion"])
+	require.Len(t, inventory.Items, 1)
+	for _, item := range inventory.Items {
+		assert.Equal(t, "potion", item.Id)
+		assert.Equal(t, int64(2), item.Count)
+	}
+
+	objects, _, err := nk.StorageList(ctx, "", "user1", purchaseTransactionsCollection, 10, "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Contains(t, objects[0].Value, `"item_id":"potion_pack"`)
+
+	_, _, _, _, err = economySystem.PurchaseItem(ctx, logger, nil, nk, "user1", "potion_pack", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, "")
+	assert.ErrorContains(t, err, "purchase intent already consumed")
+
+	// Without enough currency nothing is charged or granted
+	require.NoError(t, economySystem.PurchaseIntent(ctx, logger, nk, "user1", "potion_pack", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, ""))
+	_, _, _, _, err = economySystem.PurchaseItem(ctx, logger, nil, nk, "user1", "potion_pack", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, "")
+	assert.ErrorIs(t, err, ErrCurrencyInsufficient)
+	balance, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), balance[benchCurrency])
+}
+
+// purchasingNakama makes a purchase just before the first write of a purchase intent, as a concurrent purchase with
+// the same intent would.
+type purchasingNakama struct {
+	*benchNakama
+	purchase func()
+}
+
+func (n *purchasingNakama) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	for _, write := range writes {
+		if write.Collection == purchaseIntentsCollection && n.purchase != nil {
+			purchase := n.purchase
+			n.purchase = nil
+			purchase()
+		}
+	}
+	return n.benchNakama.StorageWrite(ctx, writes)
+}
+
+func TestPurchaseItem_VirtualCurrencyConcurrentIntent(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := &purchasingNakama{benchNakama: newBenchNakama()}
+	p := newBenchPamlogix()
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{
+		StoreItems: map[string]*EconomyConfigStoreItem{
+			"potion_pack": {
+				Cost: &EconomyConfigStoreItemCost{Currencies: map[string]int64{benchCurrency: 30}},
+				Reward: &EconomyConfigReward{
+					Guaranteed: &EconomyConfigRewardContents{
+						Items: map[string]*EconomyConfigRewardItem{
+							"potion": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 2, Max: 2}},
+						},
+					},
+				},
+			},
+		},
+	})
+	economySystem.SetPamlogix(p)
+	p.systems[SystemTypeEconomy] = economySystem
+
+	_, _, err := nk.WalletUpdate(ctx, "user1", map[string]int64{benchCurrency: 100}, nil, false)
+	require.NoError(t, err)
+	require.NoError(t, economySystem.PurchaseIntent(ctx, logger, nk, "user1", "potion_pack", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, ""))
+
+	nk.purchase = func() {
+		_, _, _, _, err := economySystem.PurchaseItem(ctx, logger, nil, nk, "user1", "potion_pack", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, "")
+		require.NoError(t, err)
+	}
+	_, _, _, _, err = economySystem.PurchaseItem(ctx, logger, nil, nk, "user1", "potion_pack", EconomyStoreType_ECONOMY_STORE_TYPE_UNSPECIFIED, "")
+	assert.ErrorContains(t, err, "purchase intent already consumed")
+
+	// Only the purchase which consumed the intent is paid for and granted
+	wallet, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(70), wallet[benchCurrency])
+	inventory, err := p.GetInventorySystem().ListInventoryItems(ctx, logger, nk, "user1", "")
+	require.NoError(t, err)
+	require.Len(t, inventory.Items, 1)
+	for _, item := range inventory.Items {
+		assert.Equal(t, int64(2), item.Count)
+	}
+	objects, _, err := nk.StorageList(ctx, "", "user1", purchaseTransactionsCollection, 10, "")
+	require.NoError(t, err)
+	assert.Len(t, objects, 1)
+}
+
+func TestTeamPurchaseItem(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newGroupsNakama()
+	p, teamsSystem := newTreasuryPamlogix()
+	economySystem := p.GetEconomySystem().(*NakamaEconomySystem)
+	economySystem.config.EventLog = true
+	economySystem.config.StoreItems = map[string]*EconomyConfigStoreItem{
+		"potion_pack": {
+			Cost: &EconomyConfigStoreItemCost{Currencies: map[string]int64{benchCurrency: 8}},
+			Reward: &EconomyConfigReward{
+				Guaranteed: &EconomyConfigRewardContents{
+					Items: map[string]*EconomyConfigRewardItem{
+						"potion": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 2, Max: 2}},
+					},
+				},
+			},
+		},
+		"coin_pack": {Cost: &EconomyConfigStoreItemCost{Sku: "com.example.coinpack"}},
+	}
+
+	_, err := teamsSystem.Create(context.WithValue(ctx, runtime.RUNTIME_CTX_USER_ID, "owner"), logger, nk, &TeamCreateRequest{Name: "dragons"})
+	require.NoError(t, err)
+	nk.roles["dragons"]["member"] = api.UserGroupList_UserGroup_MEMBER
+	_, _, err = nk.WalletUpdate(ctx, "owner", map[string]int64{benchCurrency: 100}, nil, false)
+	require.NoError(t, err)
+	_, err = teamsSystem.DepositTreasury(ctx, logger, nk, "owner", "dragons", map[string]int64{benchCurrency: 100})
+	require.NoError(t, err)
+
+	// The treasury pays and the member gets the reward
+	wallet, inventory, reward, err := economySystem.TeamPurchaseItem(ctx, logger, nk, "member", "dragons", "potion_pack")
+	require.NoError(t, err)
+	assert.Empty(t, wallet)
+	assert.Equal(t, int64(2), reward.Items["potion"])
+	require.Len(t, inventory.Items, 1)
+	treasury, err := teamsSystem.GetTreasury(ctx, logger, nk, "member", "dragons")
+	require.NoError(t, err)
+	assert.Equal(t, int64(92), treasury.Currencies[benchCurrency])
+	assert.Equal(t, "store_purchase", treasury.Ledger[0].Source)
+	assert.Equal(t, "potion_pack", treasury.Ledger[0].Metadata["item_id"])
+
+	// The spend and the purchase are both logged against the member
+	list, err := economySystem.EventLogList(ctx, logger, nk, 0, 0)
+	require.NoError(t, err)
+	var spend, purchase *EconomyEvent
+	for _, event := range list.Events {
+		switch event.Type {
+		case EconomyEventTypeSpend:
+			spend = event
+		case EconomyEventTypePurchase:
+			purchase = event
+		}
+	}
+	require.NotNil(t, spend)
+	assert.Equal(t, "member", spend.UserId)
+	assert.Equal(t, map[string]int64{benchCurrency: 8}, spend.Currencies)
+	assert.Equal(t, "dragons", spend.Metadata["team_id"])
+	assert.Equal(t, "store_purchase", spend.Metadata["source"])
+	require.NotNil(t, purchase)
+	assert.Equal(t, "dragons", purchase.Metadata["team_id"])
+
+	// Purchases are held to the member's daily spend limit
+	_, _, _, err = economySystem.TeamPurchaseItem(ctx, logger, nk, "member", "dragons", "potion_pack")
+	assert.ErrorIs(t, err, ErrTeamTreasurySpendLimit)
+	_, _, _, err = economySystem.TeamPurchaseItem(ctx, logger, nk, "stranger", "dragons", "potion_pack")
+	assert.ErrorIs(t, err, ErrTeamNotMember)
+
+	// Items sold for real money can't be bought with a treasury
+	_, _, _, err = economySystem.TeamPurchaseItem(ctx, logger, nk, "owner", "dragons", "coin_pack")
+	assert.ErrorContains(t, err, "not priced in currencies")
+	_, _, _, err = economySystem.TeamPurchaseItem(ctx, logger, nk, "owner", "", "potion_pack")
+	assert.ErrorContains(t, err, "team ID is empty")
+}
@@ -0,0 +1,86 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// placementRewardPreview returns the rewards the placement can grant the user, with their active reward modifiers
+// applied, so clients can show what watching earns. It's nil for a placement without a reward.
+func (e *NakamaEconomySystem) placementRewardPreview(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, placement *EconomyConfigPlacement) *AvailableRewards {
+	if placement == nil || placement.Reward == nil {
+		return nil
+	}
+	preview := e.RewardConvertReverse(placement.Reward)
+	if preview == nil {
+		return nil
+	}
+
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: userModifiersStorageCollection,
+			Key:        userID + "_reward_modifiers",
+			UserID:     userID,
+		},
+	})
+	if err != nil {
+		// The preview is still useful without the modifiers
+		logger.Warn("Failed to read reward modifiers of user %s for placement preview: %v", userID, err)
+		return preview
+	}
+	if len(objects) == 0 {
+		return preview
+	}
+	var modifiers []*ActiveRewardModifier
+	if err := json.Unmarshal([]byte(objects[0].Value), &modifiers); err != nil {
+		logger.Warn("Failed to unmarshal reward modifiers of user %s for placement preview: %v", userID, err)
+		return preview
+	}
+
+	now := time.Now().Unix()
+	for _, modifier := range modifiers {
+		if modifier.EndTimeSec > 0 && modifier.EndTimeSec <= now {
+			continue
+		}
+		applyRewardPreviewModifier(preview.Guaranteed, modifier)
+		for _, contents := range preview.Weighted {
+			applyRewardPreviewModifier(contents, modifier)
+		}
+	}
+	return preview
+}
+
+// applyRewardPreviewModifier applies an "add" or "multiplier" modifier to the range of the currency or item it targets.
+// Other modifiers don't change what the preview shows.
+func applyRewardPreviewModifier(contents *AvailableRewardsContents, modifier *ActiveRewardModifier) {
+	if contents == nil {
+		return
+	}
+
+	var count *RewardRangeInt64
+	switch modifier.Type {
+	case "currency":
+		if currency := contents.Currencies[modifier.Id]; currency != nil {
+			count = currency.Count
+		}
+	case "item":
+		if item := contents.Items[modifier.Id]; item != nil {
+			count = item.Count
+		}
+	}
+	if count == nil {
+		return
+	}
+
+	switch modifier.Operator {
+	case "add":
+		count.Min += modifier.Value
+		count.Max += modifier.Value
+	case "multiplier":
+		count.Min *= modifier.Value
+		count.Max *= modifier.Value
+	}
+}
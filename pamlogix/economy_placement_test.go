@@ -106,6 +106,9 @@ func TestPlacementStart_Success(t *testing.T) {
 	placementID := "ad_placement1"
 	metadata := map[string]string{"platform": "android"}
 
+	// Mock storage read of the reward modifiers for the preview
+	nk.On("StorageRead", mock.Anything, mock.Anything).Return([]*api.StorageObject{}, nil)
+
 	// Mock storage write
 	nk.On("StorageWrite", mock.Anything, mock.Anything).Return([]*api.StorageObjectAck{
 		{
@@ -306,3 +309,114 @@ func TestPlacementFail_PlacementNotFound(t *testing.T) {
 	assert.Equal(t, ErrEconomyNoPlacement, err)
 	assert.Nil(t, metadata)
 }
+
+// Tests for ListPlacements
+
+func TestListPlacements_Limits(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	userID := "user1"
+
+	coins := &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+		Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 30}}},
+	}}
+	economy := NewNakamaEconomySystem(&EconomyConfig{
+		Placements: map[string]*EconomyConfigPlacement{
+			"daily":    {Reward: coins, MaxDailyCompletions: 1},
+			"cooldown": {Reward: coins, CooldownSec: 3600},
+			"open":     {Reward: coins, AdditionalProperties: map[string]string{"slot": "shop"}},
+		},
+		CurrencyDailyCaps: map[string]map[string]int64{
+			EconomyGrantSourcePlacement: {benchCurrency: 100},
+		},
+	})
+	economy.SetPamlogix(p)
+	p.systems[SystemTypeEconomy] = economy
+
+	list, err := economy.ListPlacements(ctx, logger, nk, userID)
+	require.NoError(t, err)
+	require.Len(t, list.Placements, 3)
+	assert.Nil(t, list.Placements["daily"].Status)
+	assert.True(t, list.Placements["daily"].Available)
+	assert.Equal(t, 1, list.Placements["daily"].RemainingDailyCompletions)
+	assert.Equal(t, -1, list.Placements["open"].RemainingDailyCompletions)
+	assert.Equal(t, map[string]string{"slot": "shop"}, list.Placements["open"].AdditionalProperties)
+	assert.Equal(t, map[string]int64{benchCurrency: 100}, list.RemainingCurrencyCaps)
+
+	for _, placementID := range []string{"daily", "cooldown"} {
+		status, err := economy.PlacementStart(ctx, logger, nk, userID, placementID, nil)
+		require.NoError(t, err)
+		_, _, err = economy.PlacementSuccess(ctx, logger, nk, userID, status.RewardId, placementID)
+		require.NoError(t, err)
+	}
+
+	_, err = economy.PlacementStart(ctx, logger, nk, userID, "daily", nil)
+	assert.Equal(t, ErrEconomyPlacementLimit, err)
+	_, err = economy.PlacementStart(ctx, logger, nk, userID, "cooldown", nil)
+	assert.Equal(t, ErrEconomyPlacementCooldown, err)
+
+	list, err = economy.ListPlacements(ctx, logger, nk, userID)
+	require.NoError(t, err)
+
+	daily := list.Placements["daily"]
+	require.NotNil(t, daily.Status)
+	assert.True(t, daily.Status.Success)
+	assert.False(t, daily.Available)
+	assert.Equal(t, 0, daily.RemainingDailyCompletions)
+	assert.Equal(t, list.DailyResetTimeSec, daily.AvailableTimeSec)
+
+	cooldown := list.Placements["cooldown"]
+	assert.False(t, cooldown.Available)
+	assert.Equal(t, -1, cooldown.RemainingDailyCompletions)
+	assert.Equal(t, cooldown.Status.CompleteTimeSec+3600, cooldown.AvailableTimeSec)
+
+	assert.True(t, list.Placements["open"].Available)
+	assert.Equal(t, map[string]int64{benchCurrency: 40}, list.RemainingCurrencyCaps)
+}
+
+func TestPlacementRewardPreview(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	userID := "user1"
+
+	economy := NewNakamaEconomySystem(&EconomyConfig{
+		Placements: map[string]*EconomyConfigPlacement{
+			"video": {Reward: &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+				Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 10, Max: 20}}},
+				Items:      map[string]*EconomyConfigRewardItem{"potion": {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 1, Max: 1}}},
+			}}},
+			"empty": {},
+		},
+	})
+	economy.SetPamlogix(p)
+	p.systems[SystemTypeEconomy] = economy
+
+	status, err := economy.PlacementStart(ctx, logger, nk, userID, "video", nil)
+	require.NoError(t, err)
+	require.NotNil(t, status.RewardPreview)
+	assert.Equal(t, int64(10), status.RewardPreview.Guaranteed.Currencies[benchCurrency].Count.Min)
+	assert.Equal(t, int64(20), status.RewardPreview.Guaranteed.Currencies[benchCurrency].Count.Max)
+
+	// The user's active modifiers change the preview, not the placement's reward
+	_, _, _, err = economy.Grant(ctx, logger, nk, userID, nil, nil, nil, []*RewardModifier{
+		{Id: benchCurrency, Type: "currency", Operator: "multiplier", Value: 2, DurationSec: 600},
+		{Id: "potion", Type: "item", Operator: "add", Value: 1, DurationSec: 600},
+	}, nil)
+	require.NoError(t, err)
+
+	status, err = economy.PlacementStatus(ctx, logger, nk, userID, status.RewardId, "video", 0)
+	require.NoError(t, err)
+	require.NotNil(t, status.RewardPreview)
+	assert.Equal(t, int64(20), status.RewardPreview.Guaranteed.Currencies[benchCurrency].Count.Min)
+	assert.Equal(t, int64(40), status.RewardPreview.Guaranteed.Currencies[benchCurrency].Count.Max)
+	assert.Equal(t, int64(2), status.RewardPreview.Guaranteed.Items["potion"].Count.Max)
+	assert.Equal(t, int64(10), economy.config.Placements["video"].Reward.Guaranteed.Currencies[benchCurrency].Min)
+
+	status, err = economy.PlacementStart(ctx, logger, nk, userID, "empty", nil)
+	require.NoError(t, err)
+	assert.Nil(t, status.RewardPreview)
+}
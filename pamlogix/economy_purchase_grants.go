@@ -0,0 +1,224 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	purchasePendingGrantsCollection = "purchase_pending_grants"
+
+	defaultPurchaseGrantRetryIntervalSec = 300
+)
+
+// purchaseGrantLockName is the storage lock held while the reward of a purchase is granted, so it's granted once even
+// when a retry runs alongside the purchase.
+func purchaseGrantLockName(userID, transactionID string) string {
+	return fmt.Sprintf("%s:%s:%s", purchasePendingGrantsCollection, userID, transactionID)
+}
+
+// writePendingGrant stores the grant so it's retried until it succeeds.
+func writePendingGrant(ctx context.Context, nk runtime.NakamaModule, userID string, grant *EconomyPendingGrant) error {
+	data, err := json.Marshal(grant)
+	if err != nil {
+		return err
+	}
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      purchasePendingGrantsCollection,
+			Key:             grant.TransactionId,
+			UserID:          userID,
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+	return err
+}
+
+// grantPurchaseReward grants the reward of a validated purchase. The grant is stored as pending first, so a grant
+// which fails is retried by the purchase grant job or when the user reconciles their purchases.
+func (e *NakamaEconomySystem) grantPurchaseReward(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, grant *EconomyPendingGrant) (newItems, updatedItems map[string]*InventoryItem, err error) {
+	locked := false
+	err = withStorageLock(ctx, nk, purchaseGrantLockName(userID, grant.TransactionId), func() error {
+		locked = true
+		stored := true
+		if err := writePendingGrant(ctx, nk, userID, grant); err != nil {
+			// Continue anyway, the grant can still succeed now
+			logger.Error("Failed to store pending grant of transaction %s: %v", grant.TransactionId, err)
+			stored = false
+		}
+		newItems, updatedItems, err = e.applyPendingGrant(ctx, logger, nk, userID, grant, stored)
+		return err
+	})
+	if err != nil && !locked {
+		// Nothing was granted, so the grant is left for a retry
+		if writeErr := writePendingGrant(ctx, nk, userID, grant); writeErr != nil {
+			logger.Error("Failed to store pending grant of transaction %s: %v", grant.TransactionId, writeErr)
+		}
+	}
+	return newItems, updatedItems, err
+}
+
+// retryPendingGrant grants the stored pending grant, if it's still pending. It returns the grant, or nil when it was
+// already granted.
+func (e *NakamaEconomySystem) retryPendingGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, transactionID string) (grant *EconomyPendingGrant, newItems, updatedItems map[string]*InventoryItem, err error) {
+	err = withStorageLock(ctx, nk, purchaseGrantLockName(userID, transactionID), func() error {
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+			{
+				Collection: purchasePendingGrantsCollection,
+				Key:        transactionID,
+				UserID:     userID,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if len(objects) == 0 {
+			return nil
+		}
+		grant = &EconomyPendingGrant{}
+		if err := json.Unmarshal([]byte(objects[0].Value), grant); err != nil {
+			return err
+		}
+		// Grants kept pending before store types were stored by name hold the enum value
+		grant.StoreType = purchaseStoreTypeName(grant.StoreType)
+		newItems, updatedItems, err = e.applyPendingGrant(ctx, logger, nk, userID, grant, true)
+		return err
+	})
+	return grant, newItems, updatedItems, err
+}
+
+// applyPendingGrant grants the reward, then deletes the stored grant, or records the failed attempt on it so it's
+// retried. The caller holds the grant's lock.
+func (e *NakamaEconomySystem) applyPendingGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, grant *EconomyPendingGrant, stored bool) (newItems, updatedItems map[string]*InventoryItem, err error) {
+	metadata := map[string]interface{}{
+		"transaction_id": grant.TransactionId,
+		"item_id":        grant.ItemId,
+		"store_type":     grant.StoreType,
+	}
+	for key, value := range grant.Metadata {
+		metadata[key] = value
+	}
+	if grant.Attempts > 0 {
+		metadata["grant_attempt"] = grant.Attempts + 1
+	}
+
+	newItems, updatedItems, _, err = e.RewardGrant(ctx, logger, nk, userID, grant.Reward, metadata, false)
+	if err != nil {
+		grant.Attempts++
+		grant.LastAttemptTimeSec = time.Now().Unix()
+		grant.LastError = err.Error()
+		if stored {
+			if writeErr := writePendingGrant(ctx, nk, userID, grant); writeErr != nil {
+				logger.Warn("Failed to record attempt on pending grant of transaction %s: %v", grant.TransactionId, writeErr)
+			}
+		}
+		return nil, nil, err
+	}
+
+	if stored {
+		if err := nk.StorageDelete(ctx, []*runtime.StorageDelete{
+			{
+				Collection: purchasePendingGrantsCollection,
+				Key:        grant.TransactionId,
+				UserID:     userID,
+			},
+		}); err != nil {
+			// The reward is granted, a retry must not grant it again
+			logger.Error("Failed to delete granted pending grant of transaction %s: %v", grant.TransactionId, err)
+		}
+	}
+
+	if e.onStoreItemReward != nil && e.config != nil {
+		if storeItem, found := e.config.StoreItems[grant.ItemId]; found {
+			e.onStoreItemReward(ctx, logger, nk, userID, grant.ItemId, storeItem, storeItem.Reward, grant.Reward)
+		}
+	}
+	return newItems, updatedItems, nil
+}
+
+func (e *NakamaEconomySystem) PurchaseGrantsReconcile(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomyPurchaseGrantsReconcile, error) {
+	if userID == "" {
+		return nil, runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	result := &EconomyPurchaseGrantsReconcile{
+		Granted: make([]*EconomyPendingGrant, 0),
+		Pending: make([]*EconomyPendingGrant, 0),
+	}
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageList(ctx, "", userID, purchasePendingGrantsCollection, 100, cursor)
+		if err != nil {
+			logger.Error("Failed to list pending grants of user %s: %v", userID, err)
+			return nil, ErrInternal
+		}
+		for _, object := range objects {
+			grant, _, _, err := e.retryPendingGrant(ctx, logger, nk, userID, object.Key)
+			switch {
+			case err != nil:
+				logger.Warn("Failed to retry pending grant of transaction %s for user %s: %v", object.Key, userID, err)
+				if grant == nil {
+					grant = &EconomyPendingGrant{}
+					if err := json.Unmarshal([]byte(object.Value), grant); err != nil {
+						continue
+					}
+					grant.StoreType = purchaseStoreTypeName(grant.StoreType)
+				}
+				result.Pending = append(result.Pending, grant)
+			case grant != nil:
+				result.Granted = append(result.Granted, grant)
+			}
+		}
+		if nextCursor == "" || nextCursor == cursor {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	if len(result.Granted) > 0 {
+		wallet, err := userWallet(ctx, nk, userID)
+		if err != nil {
+			logger.Error("Failed to get wallet: %v", err)
+		}
+		result.Wallet = wallet
+	}
+	return result, nil
+}
+
+// PurchaseGrantsRetry retries every user's pending purchase grants. Grants which fail again are left for the next run.
+func (e *NakamaEconomySystem) PurchaseGrantsRetry(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	granted := 0
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageList(ctx, "", "", purchasePendingGrantsCollection, storageSweepBatchSize, cursor)
+		if err != nil {
+			logger.Error("Failed to list pending grants for retry: %v", err)
+			return granted, ErrInternal
+		}
+		for _, object := range objects {
+			grant, _, _, err := e.retryPendingGrant(ctx, logger, nk, object.UserId, object.Key)
+			if err != nil {
+				logger.Warn("Failed to retry pending grant of transaction %s for user %s: %v", object.Key, object.UserId, err)
+				continue
+			}
+			if grant != nil {
+				granted++
+			}
+		}
+		if nextCursor == "" || nextCursor == cursor {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	if granted > 0 {
+		logger.Info("Granted %d pending purchase rewards", granted)
+	}
+	return granted, nil
+}
@@ -0,0 +1,167 @@
+package pamlogix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// purchaseReceiptsCollection holds a system-owned record of every transaction redeemed through a purchase validator,
+// which is how a receipt is kept from being redeemed twice. Nakama keeps the same record for the stores it validates.
+const purchaseReceiptsCollection = "purchase_receipts"
+
+func (e *NakamaEconomySystem) SetPurchaseValidator(store EconomyStoreType, fn EconomyPurchaseValidatorFn) {
+	e.purchaseValidatorsMu.Lock()
+	defer e.purchaseValidatorsMu.Unlock()
+	if e.purchaseValidators == nil {
+		e.purchaseValidators = make(map[EconomyStoreType]EconomyPurchaseValidatorFn)
+	}
+	if fn == nil {
+		delete(e.purchaseValidators, store)
+		return
+	}
+	e.purchaseValidators[store] = fn
+}
+
+// validateStorePurchase validates the receipt with the store's validator and records the transactions it proves. Like
+// Nakama's own validation, transactions redeemed before are marked SeenBefore, and a receipt whose every transaction
+// was redeemed before is rejected.
+func (e *NakamaEconomySystem) validateStorePurchase(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, store EconomyStoreType, receipt string) (*api.ValidatePurchaseResponse, error) {
+	e.purchaseValidatorsMu.RLock()
+	validator := e.purchaseValidators[store]
+	e.purchaseValidatorsMu.RUnlock()
+	if validator == nil {
+		return nil, runtime.NewError(fmt.Sprintf("no purchase validator for store type: %s", store), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+
+	response, err := validator(ctx, logger, nk, userID, store, receipt)
+	if err != nil {
+		logger.Warn("Purchase validator for store type %s rejected receipt of user %s: %v", store, userID, err)
+		return nil, ErrEconomyReceiptInvalid
+	}
+	if response == nil || len(response.ValidatedPurchases) == 0 {
+		return nil, ErrEconomyReceiptInvalid
+	}
+
+	purchases, redeemed := 0, 0
+	for _, purchase := range response.ValidatedPurchases {
+		if purchase == nil {
+			continue
+		}
+		purchases++
+		if purchase.TransactionId == "" {
+			logger.Warn("Purchase validator for store type %s returned a purchase without a transaction ID", store)
+			return nil, ErrEconomyReceiptInvalid
+		}
+		seenBefore, err := recordStoreTransaction(ctx, nk, userID, store, receipt, purchase)
+		if err != nil {
+			logger.Error("Failed to record transaction %s of store type %s: %v", purchase.TransactionId, store, err)
+			return nil, ErrInternal
+		}
+		purchase.SeenBefore = seenBefore
+		if seenBefore {
+			redeemed++
+		}
+	}
+	if purchases == 0 {
+		return nil, ErrEconomyReceiptInvalid
+	}
+	if redeemed == purchases {
+		return nil, ErrEconomyReceiptDuplicate
+	}
+	return response, nil
+}
+
+// recordStoreTransaction claims the transaction for the user, and returns true when it was already claimed.
+func recordStoreTransaction(ctx context.Context, nk runtime.NakamaModule, userID string, store EconomyStoreType, receipt string, purchase *api.ValidatedPurchase) (bool, error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"user_id":        userID,
+		"store_type":     store.String(),
+		"product_id":     purchase.ProductId,
+		"transaction_id": purchase.TransactionId,
+		"receipt_hash":   hashReceipt(receipt),
+		"timestamp":      time.Now().Unix(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	key := fmt.Sprintf("%s:%s", store.String(), purchase.TransactionId)
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: purchaseReceiptsCollection,
+		Key:        key,
+	}})
+	if err != nil {
+		return false, err
+	}
+	if len(objects) > 0 {
+		return true, nil
+	}
+
+	// The write only succeeds if nobody claimed the transaction since it was read
+	if _, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      purchaseReceiptsCollection,
+		Key:             key,
+		Value:           string(data),
+		Version:         storageLockVersionNone,
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}}); err != nil {
+		return true, nil
+	}
+	return false, nil
+}
+
+// NewWebhookPurchaseValidator returns a purchase validator which posts the user ID, store type and receipt as JSON to
+// the URL, with the given headers. The webhook answers with a ValidatePurchaseResponse in its JSON form, and any
+// status other than 200 rejects the receipt.
+func NewWebhookPurchaseValidator(url string, headers map[string]string, timeout time.Duration) EconomyPurchaseValidatorFn {
+	client := &http.Client{Timeout: timeout}
+	return func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, store EconomyStoreType, receipt string) (*api.ValidatePurchaseResponse, error) {
+		body, err := json.Marshal(map[string]string{
+			"user_id":    userID,
+			"store_type": store.String(),
+			"receipt":    receipt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("purchase webhook returned status %d: %s", resp.StatusCode, respBody)
+		}
+
+		response := &api.ValidatePurchaseResponse{}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(respBody, response); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+}
@@ -0,0 +1,186 @@
+package pamlogix
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// rewardGrantBulkBatchSize is how many users a bulk reward grant reads and writes together.
+const rewardGrantBulkBatchSize = 100
+
+// rewardGrantBulkUser is one user's grant in a bulk reward grant, prepared but not yet written.
+type rewardGrantBulkUser struct {
+	userID string
+	reward *Reward
+	result *EconomyRewardGrantResult
+	writes []*runtime.StorageWrite
+}
+
+// RewardGrantBulk grants many users their rolled rewards. Each batch of users has its modifiers read in one call, and
+// its wallet updates and storage writes made in one atomic call. If a batch is rejected, e.g. because one user can't
+// cover a deduction, its users are written one by one so only the failing users miss out. Inventory systems other than
+// the built-in one write their items as each user is prepared. Energies granted through the energy system are granted
+// once the batch is written.
+func (e *NakamaEconomySystem) RewardGrantBulk(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, rewards map[string]*Reward, metadata map[string]interface{}, ignoreLimits bool) (map[string]*EconomyRewardGrantResult, error) {
+	userIDs := make([]string, 0, len(rewards))
+	for userID := range rewards {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	var energySystem EnergySystem
+	if pamlogixInst, ok := e.pamlogix.(interface{ GetEnergySystem() EnergySystem }); ok {
+		energySystem = pamlogixInst.GetEnergySystem()
+	}
+
+	results := make(map[string]*EconomyRewardGrantResult, len(rewards))
+	for start := 0; start < len(userIDs); start += rewardGrantBulkBatchSize {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		end := min(start+rewardGrantBulkBatchSize, len(userIDs))
+		batch := make([]*rewardGrantBulkUser, 0, end-start)
+		for _, userID := range userIDs[start:end] {
+			user := &rewardGrantBulkUser{
+				userID: userID,
+				reward: rewards[userID],
+				result: &EconomyRewardGrantResult{
+					NewItems:          make(map[string]*InventoryItem),
+					UpdatedItems:      make(map[string]*InventoryItem),
+					NotGrantedItemIDs: make(map[string]int64),
+				},
+			}
+			results[userID] = user.result
+			batch = append(batch, user)
+		}
+		e.rewardGrantBulkBatch(ctx, logger, nk, batch, metadata, ignoreLimits, energySystem)
+	}
+
+	return results, nil
+}
+
+// rewardGrantBulkBatch prepares and writes the grants of one batch of users, setting the error of those that fail.
+func (e *NakamaEconomySystem) rewardGrantBulkBatch(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, batch []*rewardGrantBulkUser, metadata map[string]interface{}, ignoreLimits bool, energySystem EnergySystem) {
+	prepared := make([]*rewardGrantBulkUser, 0, len(batch))
+	for _, user := range batch {
+		switch {
+		case user.reward == nil:
+			user.result.Err = runtime.NewError("reward is nil", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		case user.userID == "":
+			user.result.Err = runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		default:
+			if user.result.Err = e.applyDuplicateConversions(ctx, logger, nk, user.userID, user.reward); user.result.Err == nil {
+				user.result.Err = e.applyCurrencyMaxBalances(ctx, logger, nk, user.userID, user.reward)
+			}
+		}
+		if user.result.Err != nil {
+			continue
+		}
+		if len(user.reward.Items) > 0 {
+			itemWrites, err := e.rewardItemWrites(ctx, logger, nk, user.userID, user.reward, user.result.NewItems, user.result.UpdatedItems, user.result.NotGrantedItemIDs, ignoreLimits, false)
+			if err != nil {
+				user.result.Err = err
+				continue
+			}
+			user.writes = append(user.writes, itemWrites...)
+		}
+		prepared = append(prepared, user)
+	}
+
+	// Energies and modifiers of the whole batch are read with one call
+	reads := make([]*runtime.StorageRead, 0)
+	userReads := make(map[string][]*runtime.StorageRead, len(prepared))
+	for _, user := range prepared {
+		if r := rewardGrantStorageReads(user.userID, user.reward, energySystem == nil); len(r) > 0 {
+			userReads[user.userID] = r
+			reads = append(reads, r...)
+		}
+	}
+	if len(reads) > 0 {
+		objects, err := nk.StorageRead(ctx, reads)
+		if err != nil {
+			logger.Error("Failed to read energies and modifiers of %d users: %v", len(userReads), err)
+			// Continue execution, the users still get their currencies and items
+		} else {
+			userObjects := make(map[string][]*api.StorageObject, len(userReads))
+			for _, object := range objects {
+				userObjects[object.UserId] = append(userObjects[object.UserId], object)
+			}
+			now := time.Now().Unix()
+			for _, user := range prepared {
+				if r, found := userReads[user.userID]; found {
+					modifierWrites, err := rewardModifierObjectWrites(user.userID, user.reward, r, userObjects[user.userID], now)
+					if err != nil {
+						logger.Error("Failed to apply energies and modifiers for user %s: %v", user.userID, err)
+						continue
+					}
+					user.writes = append(user.writes, modifierWrites...)
+				}
+			}
+		}
+	}
+
+	if len(prepared) == 0 {
+		return
+	}
+	if err := rewardGrantBulkWrite(ctx, nk, prepared, metadata); err != nil {
+		logger.Warn("Failed to write bulk reward grant of %d users, writing them one by one: %v", len(prepared), err)
+		written := make([]*rewardGrantBulkUser, 0, len(prepared))
+		for _, user := range prepared {
+			if err := rewardGrantBulkWrite(ctx, nk, []*rewardGrantBulkUser{user}, metadata); err != nil {
+				logger.Error("Failed to grant reward to user %s: %v", user.userID, err)
+				user.result.Err = runtime.NewError("Failed to grant reward", INTERNAL_ERROR_CODE) // INTERNAL
+				continue
+			}
+			written = append(written, user)
+		}
+		prepared = written
+	}
+
+	events := make([]*EconomyEvent, 0, len(prepared))
+	for _, user := range prepared {
+		if len(user.reward.Energies) > 0 && energySystem != nil {
+			if _, err := energySystem.Grant(ctx, logger, nk, user.userID, user.reward.Energies, nil); err != nil {
+				logger.Error("Failed to update energies for user %s: %v", user.userID, err)
+				// Continue execution, don't fail the entire operation
+			}
+		}
+		if len(user.reward.Currencies) > 0 || len(user.reward.Items) > 0 {
+			events = append(events, &EconomyEvent{
+				Type:       EconomyEventTypeGrant,
+				UserId:     user.userID,
+				Currencies: user.reward.Currencies,
+				Items:      economyEventItems(user.reward.Items, user.result.NotGrantedItemIDs),
+				Metadata:   economyEventMetadata(metadata),
+			})
+		}
+	}
+	e.logEvents(ctx, logger, nk, events...)
+}
+
+// rewardGrantBulkWrite makes the wallet updates and storage writes of the users in one atomic call.
+func rewardGrantBulkWrite(ctx context.Context, nk runtime.NakamaModule, users []*rewardGrantBulkUser, metadata map[string]interface{}) error {
+	walletUpdates := make([]*runtime.WalletUpdate, 0, len(users))
+	writes := make([]*runtime.StorageWrite, 0)
+	for _, user := range users {
+		if len(user.reward.Currencies) > 0 {
+			walletUpdates = append(walletUpdates, &runtime.WalletUpdate{
+				UserID:    user.userID,
+				Changeset: user.reward.Currencies,
+				Metadata:  metadata,
+			})
+		}
+		writes = append(writes, user.writes...)
+	}
+	if len(walletUpdates) == 0 && len(writes) == 0 {
+		return nil
+	}
+	return retryNakama(ctx, nonIdempotentCall, func() error {
+		_, _, err := nk.MultiUpdate(ctx, nil, writes, nil, walletUpdates, false)
+		return err
+	})
+}
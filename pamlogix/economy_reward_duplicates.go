@@ -0,0 +1,95 @@
+package pamlogix
+
+import (
+	"context"
+	"sort"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// applyDuplicateConversions replaces the duplicates of unique items in a reward with their duplicate conversions. An
+// item is a duplicate when the user already owns it, or when the reward grants more than one. The reward is updated in
+// place and the converted items are reported in its ConvertedItems. Items granted by a conversion aren't converted
+// again.
+func (e *NakamaEconomySystem) applyDuplicateConversions(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward) error {
+	if len(reward.Items) == 0 {
+		return nil
+	}
+	var inventorySystem InventorySystem
+	if pamlogixInst, ok := e.pamlogix.(interface{ GetInventorySystem() InventorySystem }); ok {
+		inventorySystem = pamlogixInst.GetInventorySystem()
+	}
+	if inventorySystem == nil {
+		return nil
+	}
+	inventoryConfig, ok := inventorySystem.GetConfig().(*InventoryConfig)
+	if !ok || inventoryConfig == nil {
+		return nil
+	}
+
+	uniqueItemIDs := make([]string, 0)
+	for itemID, count := range reward.Items {
+		if itemConfig := inventoryConfig.Items[itemID]; itemConfig != nil && itemConfig.Unique && count > 0 {
+			uniqueItemIDs = append(uniqueItemIDs, itemID)
+		}
+	}
+	if len(uniqueItemIDs) == 0 {
+		return nil
+	}
+	sort.Strings(uniqueItemIDs)
+
+	inventory, err := inventorySystem.ListInventoryItems(ctx, logger, nk, userID, "")
+	if err != nil {
+		logger.Error("Failed to read inventory of user %s: %v", userID, err)
+		return ErrInternal
+	}
+	owned := make(map[string]bool)
+	for _, item := range inventory.GetItems() {
+		if item.Count > 0 {
+			owned[item.Id] = true
+		}
+	}
+
+	convertedCurrencies := make(map[string]int64)
+	convertedItems := make(map[string]int64)
+	for _, itemID := range uniqueItemIDs {
+		duplicates := reward.Items[itemID]
+		if !owned[itemID] {
+			duplicates--
+		}
+		if duplicates <= 0 {
+			continue
+		}
+
+		if reward.Items[itemID] -= duplicates; reward.Items[itemID] <= 0 {
+			delete(reward.Items, itemID)
+		}
+		if reward.ConvertedItems == nil {
+			reward.ConvertedItems = make(map[string]int64)
+		}
+		reward.ConvertedItems[itemID] += duplicates
+
+		if conversion := inventoryConfig.Items[itemID].DuplicateConversion; conversion != nil {
+			for currencyID, amount := range conversion.Currencies {
+				convertedCurrencies[currencyID] += amount * duplicates
+			}
+			for convertedItemID, count := range conversion.Items {
+				convertedItems[convertedItemID] += count * duplicates
+			}
+		}
+	}
+
+	if len(convertedCurrencies) > 0 && reward.Currencies == nil {
+		reward.Currencies = make(map[string]int64, len(convertedCurrencies))
+	}
+	for currencyID, amount := range convertedCurrencies {
+		reward.Currencies[currencyID] += amount
+	}
+	for itemID, count := range convertedItems {
+		reward.Items[itemID] += count
+	}
+	if len(reward.ConvertedItems) > 0 {
+		logger.Debug("Converted duplicate unique items for user %s: %v", userID, reward.ConvertedItems)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	economySnapshotsStorageCollection = "economy_snapshots"
+	// defaultMaxEconomySnapshots is how many snapshots are kept per user when the config doesn't say.
+	defaultMaxEconomySnapshots  = 10
+	economySnapshotListPageSize = 100
+)
+
+// SnapshotCreate copies the user's wallet, inventory and modifiers into a new snapshot.
+func (e *NakamaEconomySystem) SnapshotCreate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, reason string) (*EconomySnapshot, error) {
+	if userID == "" {
+		return nil, ErrBadInput
+	}
+
+	wallet, err := userWallet(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to read wallet of user %s for snapshot: %v", userID, err)
+		return nil, ErrInternal
+	}
+	objects, err := readEconomySnapshotObjects(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to read economy of user %s for snapshot: %v", userID, err)
+		return nil, ErrInternal
+	}
+
+	// Version 7 IDs sort by creation time, so storage lists the user's snapshots oldest first
+	snapshotID, err := uuid.NewV7()
+	if err != nil {
+		logger.Error("Failed to generate snapshot ID: %v", err)
+		return nil, ErrInternal
+	}
+	snapshot := &EconomySnapshot{
+		Id:            snapshotID.String(),
+		UserId:        userID,
+		Reason:        reason,
+		CreateTimeSec: time.Now().Unix(),
+		Wallet:        wallet,
+		Objects:       make([]*EconomySnapshotObject, 0, len(objects)),
+	}
+	for _, object := range objects {
+		snapshot.Objects = append(snapshot.Objects, &EconomySnapshotObject{
+			Collection:      object.Collection,
+			Key:             object.Key,
+			Value:           object.Value,
+			PermissionRead:  int(object.PermissionRead),
+			PermissionWrite: int(object.PermissionWrite),
+		})
+	}
+
+	snapshotData, err := json.Marshal(snapshot)
+	if err != nil {
+		logger.Error("Failed to marshal economy snapshot: %v", err)
+		return nil, ErrInternal
+	}
+	if _, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      economySnapshotsStorageCollection,
+		Key:             snapshot.Id,
+		UserID:          userID,
+		Value:           string(snapshotData),
+		Version:         storageLockVersionNone,
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}}); err != nil {
+		logger.Error("Failed to write economy snapshot of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	logger.Info("Created economy snapshot %s of user %s: %s", snapshot.Id, userID, reason)
+
+	// Snapshots roll: the oldest beyond the maximum make way for the new one
+	if err := e.pruneSnapshots(ctx, nk, userID); err != nil {
+		logger.Warn("Failed to delete old economy snapshots of user %s: %v", userID, err)
+	}
+
+	return snapshot, nil
+}
+
+// SnapshotList returns the user's economy snapshots, newest first.
+func (e *NakamaEconomySystem) SnapshotList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomySnapshotList, error) {
+	if userID == "" {
+		return nil, ErrBadInput
+	}
+
+	objects, err := listEconomySnapshots(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to list economy snapshots of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+
+	list := &EconomySnapshotList{Snapshots: make([]*EconomySnapshot, 0, len(objects))}
+	for i := len(objects) - 1; i >= 0; i-- {
+		snapshot := &EconomySnapshot{}
+		if err := json.Unmarshal([]byte(objects[i].Value), snapshot); err != nil {
+			logger.Warn("Failed to unmarshal economy snapshot %s: %v", objects[i].Key, err)
+			continue
+		}
+		snapshot.Objects = nil
+		list.Snapshots = append(list.Snapshots, snapshot)
+	}
+	return list, nil
+}
+
+// SnapshotRestore rolls the user's wallet, inventory and modifiers back to a snapshot, after snapshotting the state
+// it replaces.
+func (e *NakamaEconomySystem) SnapshotRestore(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, snapshotID string) (*EconomySnapshot, error) {
+	if userID == "" || snapshotID == "" {
+		return nil, ErrBadInput
+	}
+
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: economySnapshotsStorageCollection, Key: snapshotID, UserID: userID},
+	})
+	if err != nil {
+		logger.Error("Failed to read economy snapshot %s: %v", snapshotID, err)
+		return nil, ErrInternal
+	}
+	if len(objects) == 0 {
+		return nil, ErrEconomyNoSnapshot
+	}
+	snapshot := &EconomySnapshot{}
+	if err := json.Unmarshal([]byte(objects[0].Value), snapshot); err != nil {
+		logger.Error("Failed to unmarshal economy snapshot %s: %v", snapshotID, err)
+		return nil, ErrInternal
+	}
+
+	backup, err := e.SnapshotCreate(ctx, logger, nk, userID, "before restoring snapshot "+snapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Objects the user gained since the snapshot are deleted, and the rest are put back as they were
+	restored := make(map[string]bool, len(snapshot.Objects))
+	writes := make([]*runtime.StorageWrite, 0, len(snapshot.Objects))
+	for _, object := range snapshot.Objects {
+		restored[object.Collection+"/"+object.Key] = true
+		writes = append(writes, &runtime.StorageWrite{
+			Collection:      object.Collection,
+			Key:             object.Key,
+			UserID:          userID,
+			Value:           object.Value,
+			PermissionRead:  object.PermissionRead,
+			PermissionWrite: object.PermissionWrite,
+		})
+	}
+	deletes := make([]*runtime.StorageDelete, 0)
+	for _, object := range backup.Objects {
+		if !restored[object.Collection+"/"+object.Key] {
+			deletes = append(deletes, &runtime.StorageDelete{Collection: object.Collection, Key: object.Key, UserID: userID})
+		}
+	}
+	if len(deletes) > 0 {
+		if err := nk.StorageDelete(ctx, deletes); err != nil {
+			logger.Error("Failed to delete economy objects of user %s restoring snapshot %s: %v", userID, snapshotID, err)
+			return nil, ErrInternal
+		}
+	}
+	if len(writes) > 0 {
+		if _, err := nk.StorageWrite(ctx, writes); err != nil {
+			logger.Error("Failed to write economy objects of user %s restoring snapshot %s: %v", userID, snapshotID, err)
+			return nil, ErrInternal
+		}
+	}
+
+	changeset := make(map[string]int64)
+	for currencyID, amount := range snapshot.Wallet {
+		if amount != backup.Wallet[currencyID] {
+			changeset[currencyID] = amount - backup.Wallet[currencyID]
+		}
+	}
+	for currencyID, amount := range backup.Wallet {
+		if _, found := snapshot.Wallet[currencyID]; !found && amount != 0 {
+			changeset[currencyID] = -amount
+		}
+	}
+	if len(changeset) > 0 {
+		metadata := map[string]interface{}{"snapshot_id": snapshotID, "backup_snapshot_id": backup.Id}
+		if _, _, err := nk.WalletUpdate(ctx, userID, changeset, metadata, true); err != nil {
+			logger.Error("Failed to update wallet of user %s restoring snapshot %s: %v", userID, snapshotID, err)
+			return nil, ErrInternal
+		}
+	}
+
+	logger.Warn("Restored economy of user %s to snapshot %s, previous state saved as %s", userID, snapshotID, backup.Id)
+	return backup, nil
+}
+
+// readEconomySnapshotObjects returns the user's inventory and modifier storage objects.
+func readEconomySnapshotObjects(ctx context.Context, nk runtime.NakamaModule, userID string) ([]*api.StorageObject, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: userModifiersStorageCollection, Key: userID + "_energy_modifiers", UserID: userID},
+		{Collection: userModifiersStorageCollection, Key: userID + "_reward_modifiers", UserID: userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor := ""
+	for {
+		items, nextCursor, err := nk.StorageList(ctx, "", userID, inventoryStorageCollection, defaultInventoryPageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, items...)
+		if nextCursor == "" {
+			return objects, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// listEconomySnapshots returns the user's snapshot storage objects, oldest first.
+func listEconomySnapshots(ctx context.Context, nk runtime.NakamaModule, userID string) ([]*api.StorageObject, error) {
+	objects := make([]*api.StorageObject, 0)
+	cursor := ""
+	for {
+		page, nextCursor, err := nk.StorageList(ctx, "", userID, economySnapshotsStorageCollection, economySnapshotListPageSize, cursor)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, page...)
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+	return objects, nil
+}
+
+// pruneSnapshots deletes the user's oldest snapshots beyond the configured maximum.
+func (e *NakamaEconomySystem) pruneSnapshots(ctx context.Context, nk runtime.NakamaModule, userID string) error {
+	maxSnapshots := defaultMaxEconomySnapshots
+	if e.config != nil && e.config.MaxSnapshots > 0 {
+		maxSnapshots = e.config.MaxSnapshots
+	}
+
+	objects, err := listEconomySnapshots(ctx, nk, userID)
+	if err != nil || len(objects) <= maxSnapshots {
+		return err
+	}
+	deletes := make([]*runtime.StorageDelete, 0, len(objects)-maxSnapshots)
+	for _, object := range objects[:len(objects)-maxSnapshots] {
+		deletes = append(deletes, &runtime.StorageDelete{Collection: economySnapshotsStorageCollection, Key: object.Key, UserID: userID})
+	}
+	return nk.StorageDelete(ctx, deletes)
+}
@@ -0,0 +1,86 @@
+package pamlogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{})
+
+	_, _, err := nk.WalletUpdate(ctx, "user1", map[string]int64{benchCurrency: 100}, nil, false)
+	require.NoError(t, err)
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{Collection: inventoryStorageCollection, Key: "inventory:sword", UserID: "user1", Value: `{"id":"sword","count":1}`},
+		{Collection: userModifiersStorageCollection, Key: "user1_reward_modifiers", UserID: "user1", Value: `[{"id":"xp"}]`},
+	})
+	require.NoError(t, err)
+
+	snapshot, err := economySystem.SnapshotCreate(ctx, logger, nk, "user1", "before grant")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{benchCurrency: 100}, snapshot.Wallet)
+	assert.Len(t, snapshot.Objects, 2)
+
+	// A bad grant adds currency and items and replaces the modifiers, and the sword gets destroyed
+	_, _, err = nk.WalletUpdate(ctx, "user1", map[string]int64{benchCurrency: 9000, "gems": 50}, nil, false)
+	require.NoError(t, err)
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{Collection: inventoryStorageCollection, Key: "inventory:potion", UserID: "user1", Value: `{"id":"potion","count":99}`},
+		{Collection: userModifiersStorageCollection, Key: "user1_reward_modifiers", UserID: "user1", Value: `[]`},
+	})
+	require.NoError(t, err)
+	require.NoError(t, nk.StorageDelete(ctx, []*runtime.StorageDelete{{Collection: inventoryStorageCollection, Key: "inventory:sword", UserID: "user1"}}))
+
+	backup, err := economySystem.SnapshotRestore(ctx, logger, nk, "user1", snapshot.Id)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{benchCurrency: 9100, "gems": 50}, backup.Wallet)
+
+	wallet, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), wallet[benchCurrency])
+	assert.Zero(t, wallet["gems"])
+
+	objects, _, err := nk.StorageList(ctx, "", "user1", inventoryStorageCollection, 100, "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "inventory:sword", objects[0].Key)
+	assert.Len(t, readActiveRewardModifiers(t, nk, "user1"), 1)
+
+	// The state replaced by the restore is kept, newest first
+	list, err := economySystem.SnapshotList(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	require.Len(t, list.Snapshots, 2)
+	assert.Equal(t, backup.Id, list.Snapshots[0].Id)
+	assert.Equal(t, snapshot.Id, list.Snapshots[1].Id)
+	assert.Nil(t, list.Snapshots[0].Objects)
+
+	_, err = economySystem.SnapshotRestore(ctx, logger, nk, "user1", "missing")
+	assert.ErrorIs(t, err, ErrEconomyNoSnapshot)
+}
+
+func TestSnapshotCreate_KeepsMaxSnapshots(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	economySystem := NewNakamaEconomySystem(&EconomyConfig{MaxSnapshots: 2})
+
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		snapshot, err := economySystem.SnapshotCreate(ctx, logger, nk, "user1", "")
+		require.NoError(t, err)
+		ids = append(ids, snapshot.Id)
+	}
+
+	list, err := economySystem.SnapshotList(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	require.Len(t, list.Snapshots, 2)
+	assert.Equal(t, ids[2], list.Snapshots[0].Id)
+	assert.Equal(t, ids[1], list.Snapshots[1].Id)
+}
@@ -0,0 +1,313 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	subscriptionsStorageCollection = "economy_subscriptions"
+
+	defaultSubscriptionCheckIntervalSec = 3600
+	// subscriptionCheckGraceSec is how long after expiring a subscription is still checked for renewals, covering the
+	// stores' billing retry periods.
+	subscriptionCheckGraceSec = 3 * 24 * 3600
+)
+
+// subscriptionCheckIntervalSec returns how often the subscription check job runs by default, or 0 when no
+// subscriptions are configured.
+func subscriptionCheckIntervalSec(config *EconomyConfig) int64 {
+	if config == nil || config.Subscriptions == nil || len(config.Subscriptions.Items) == 0 {
+		return 0
+	}
+	if config.Subscriptions.CheckIntervalSec > 0 {
+		return config.Subscriptions.CheckIntervalSec
+	}
+	return defaultSubscriptionCheckIntervalSec
+}
+
+func (e *NakamaEconomySystem) subscriptionConfig(subscriptionID string) *EconomyConfigSubscription {
+	if e.config == nil || e.config.Subscriptions == nil {
+		return nil
+	}
+	return e.config.Subscriptions.Items[subscriptionID]
+}
+
+func (e *NakamaEconomySystem) SubscriptionPurchase(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, subscriptionID string, store EconomyStoreType, receipt string) (*EconomySubscription, *Reward, error) {
+	if userID == "" {
+		return nil, nil, runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	if receipt == "" {
+		return nil, nil, runtime.NewError("receipt is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	config := e.subscriptionConfig(subscriptionID)
+	if config == nil {
+		return nil, nil, ErrEconomyNoSubscription
+	}
+	if config.Sku == "" {
+		return nil, nil, runtime.NewError(fmt.Sprintf("subscription %s has no sku configured", subscriptionID), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+
+	var response *api.ValidateSubscriptionResponse
+	var err error
+	switch store {
+	case EconomyStoreType_ECONOMY_STORE_TYPE_APPLE_APPSTORE:
+		response, err = nk.SubscriptionValidateApple(ctx, userID, receipt, true)
+	case EconomyStoreType_ECONOMY_STORE_TYPE_GOOGLE_PLAY:
+		response, err = nk.SubscriptionValidateGoogle(ctx, userID, receipt, true)
+	default:
+		return nil, nil, runtime.NewError(fmt.Sprintf("unsupported store type for subscriptions: %s", store), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	if err != nil {
+		logger.Warn("Failed to validate subscription receipt of user %s: %v", userID, err)
+		return nil, nil, ErrEconomyReceiptInvalid
+	}
+	validated := response.GetValidatedSubscription()
+	if validated == nil {
+		return nil, nil, ErrEconomyReceiptInvalid
+	}
+	if validated.ProductId != config.Sku {
+		logger.Warn("Subscription receipt of user %s is for product %s, expected %s", userID, validated.ProductId, config.Sku)
+		return nil, nil, ErrEconomyReceiptMismatch
+	}
+	if validated.Environment == api.StoreEnvironment_SANDBOX && e.sandboxPurchasesMode() == EconomySandboxPurchasesDeny {
+		logger.Warn("Rejected sandbox subscription %s for user %s", subscriptionID, userID)
+		return nil, nil, runtime.NewError("sandbox purchases are not accepted", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+
+	now := time.Now().Unix()
+	subscription, version, err := readSubscription(ctx, nk, userID, subscriptionID)
+	if err != nil {
+		logger.Error("Failed to read subscription %s of user %s: %v", subscriptionID, userID, err)
+		return nil, nil, ErrInternal
+	}
+	if subscription == nil {
+		subscription = &EconomySubscription{
+			SubscriptionId: subscriptionID,
+			StartTimeSec:   now,
+		}
+	}
+	// Subscribing again resumes the rewards of a cancelled subscription
+	subscription.Cancelled = false
+	subscription.StoreType = store.String()
+	applyValidatedSubscription(subscription, validated, now)
+
+	reward, err := e.saveSubscription(ctx, logger, nk, userID, config, subscription, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return subscription, reward, nil
+}
+
+func (e *NakamaEconomySystem) SubscriptionList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomySubscriptionList, error) {
+	if userID == "" {
+		return nil, runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	now := time.Now().Unix()
+	list := &EconomySubscriptionList{
+		Subscriptions: make(map[string]*EconomySubscription),
+		ServerTimeSec: now,
+	}
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageList(ctx, "", userID, subscriptionsStorageCollection, 100, cursor)
+		if err != nil {
+			logger.Error("Failed to list subscriptions of user %s: %v", userID, err)
+			return nil, ErrInternal
+		}
+		for _, object := range objects {
+			subscription := &EconomySubscription{}
+			if err := json.Unmarshal([]byte(object.Value), subscription); err != nil {
+				logger.Warn("Skipped unreadable subscription %s of user %s: %v", object.Key, userID, err)
+				continue
+			}
+			// A failed refresh still lists the subscription as it was last seen
+			if _, err := e.refreshSubscription(ctx, logger, nk, userID, subscription, object.Version, now); err != nil {
+				logger.Warn("Failed to refresh subscription %s of user %s: %v", object.Key, userID, err)
+			}
+			list.Subscriptions[subscription.SubscriptionId] = subscription
+		}
+		if nextCursor == "" || nextCursor == cursor {
+			break
+		}
+		cursor = nextCursor
+	}
+	return list, nil
+}
+
+func (e *NakamaEconomySystem) SubscriptionCancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, subscriptionID string) (*EconomySubscription, error) {
+	if userID == "" {
+		return nil, runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	subscription, version, err := readSubscription(ctx, nk, userID, subscriptionID)
+	if err != nil {
+		logger.Error("Failed to read subscription %s of user %s: %v", subscriptionID, userID, err)
+		return nil, ErrInternal
+	}
+	if subscription == nil {
+		return nil, ErrEconomyNoSubscription
+	}
+	if subscription.Cancelled {
+		return subscription, nil
+	}
+
+	subscription.Cancelled = true
+	if _, err := writeSubscription(ctx, nk, userID, subscription, version); err != nil {
+		logger.Warn("Failed to cancel subscription %s of user %s: %v", subscriptionID, userID, err)
+		return nil, ErrStorageLockBusy
+	}
+	return subscription, nil
+}
+
+// SubscriptionsCheck refreshes the subscriptions which are active or expired recently enough that the store may still
+// renew them. Subscriptions changed while the check runs are left for the next check.
+func (e *NakamaEconomySystem) SubscriptionsCheck(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	now := time.Now().Unix()
+	checked := 0
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageList(ctx, "", "", subscriptionsStorageCollection, storageSweepBatchSize, cursor)
+		if err != nil {
+			logger.Error("Failed to list subscriptions for check: %v", err)
+			return checked, ErrInternal
+		}
+		for _, object := range objects {
+			subscription := &EconomySubscription{}
+			if err := json.Unmarshal([]byte(object.Value), subscription); err != nil {
+				logger.Debug("Skipped checking subscription %s of user %s: %v", object.Key, object.UserId, err)
+				continue
+			}
+			if !subscription.Active && subscription.ExpiryTimeSec+subscriptionCheckGraceSec < now {
+				continue
+			}
+			if _, err := e.refreshSubscription(ctx, logger, nk, object.UserId, subscription, object.Version, now); err != nil {
+				logger.Warn("Failed to check subscription %s of user %s: %v", object.Key, object.UserId, err)
+				continue
+			}
+			checked++
+		}
+		if nextCursor == "" || nextCursor == cursor {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	logger.Info("Checked %d subscriptions", checked)
+	return checked, nil
+}
+
+// refreshSubscription updates the subscription from the store's latest validation, which Nakama keeps up to date from
+// the store notifications, and grants the reward of a renewal.
+func (e *NakamaEconomySystem) refreshSubscription(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, subscription *EconomySubscription, version string, now int64) (*Reward, error) {
+	config := e.subscriptionConfig(subscription.SubscriptionId)
+	if config == nil || config.Sku == "" {
+		return nil, nil
+	}
+	validated, err := nk.SubscriptionGetByProductId(ctx, userID, config.Sku)
+	if err != nil {
+		return nil, err
+	}
+	if validated == nil {
+		return nil, nil
+	}
+	applyValidatedSubscription(subscription, validated, now)
+	return e.saveSubscription(ctx, logger, nk, userID, config, subscription, version)
+}
+
+// applyValidatedSubscription copies the store's view of the subscription. A refunded subscription is never active.
+func applyValidatedSubscription(subscription *EconomySubscription, validated *api.ValidatedSubscription, now int64) {
+	subscription.OriginalTransactionId = validated.OriginalTransactionId
+	subscription.Sandbox = validated.Environment == api.StoreEnvironment_SANDBOX
+	if validated.ExpiryTime != nil {
+		subscription.ExpiryTimeSec = validated.ExpiryTime.GetSeconds()
+	}
+	subscription.Active = validated.RefundTime == nil && subscription.ExpiryTimeSec > now
+	subscription.CheckTimeSec = now
+}
+
+// saveSubscription writes the subscription and grants its reward when the current period hasn't been rewarded yet.
+// The write is conditional on the version read, so a period is only ever rewarded once even when the check job and the
+// user refresh the subscription at the same time.
+func (e *NakamaEconomySystem) saveSubscription(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, config *EconomyConfigSubscription, subscription *EconomySubscription, version string) (*Reward, error) {
+	grant := config.Reward != nil && subscription.Active && !subscription.Cancelled &&
+		subscription.ExpiryTimeSec > subscription.RewardedExpiryTimeSec &&
+		(!subscription.Sandbox || e.sandboxPurchasesMode() == EconomySandboxPurchasesAllow)
+	if grant {
+		subscription.RewardedExpiryTimeSec = subscription.ExpiryTimeSec
+	}
+
+	if version == "" {
+		version = storageLockVersionNone
+	}
+	if _, err := writeSubscription(ctx, nk, userID, subscription, version); err != nil {
+		logger.Warn("Failed to write subscription %s of user %s: %v", subscription.SubscriptionId, userID, err)
+		return nil, ErrStorageLockBusy
+	}
+	if !grant {
+		return nil, nil
+	}
+
+	reward, err := e.RewardRoll(ctx, logger, nk, userID, config.Reward)
+	if err != nil {
+		logger.Error("Failed to roll subscription reward: %v", err)
+		return nil, ErrInternal
+	}
+	metadata := map[string]interface{}{
+		"subscription_id": subscription.SubscriptionId,
+		"store_type":      subscription.StoreType,
+		"expiry_time_sec": subscription.ExpiryTimeSec,
+	}
+	if _, _, _, err := e.RewardGrant(ctx, logger, nk, userID, reward, metadata, false); err != nil {
+		logger.Error("Failed to grant subscription reward: %v", err)
+		return nil, ErrInternal
+	}
+	return reward, nil
+}
+
+func readSubscription(ctx context.Context, nk runtime.NakamaModule, userID, subscriptionID string) (*EconomySubscription, string, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: subscriptionsStorageCollection,
+		Key:        subscriptionID,
+		UserID:     userID,
+	}})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(objects) == 0 {
+		return nil, "", nil
+	}
+	subscription := &EconomySubscription{}
+	if err := json.Unmarshal([]byte(objects[0].Value), subscription); err != nil {
+		return nil, "", err
+	}
+	return subscription, objects[0].Version, nil
+}
+
+func writeSubscription(ctx context.Context, nk runtime.NakamaModule, userID string, subscription *EconomySubscription, version string) (string, error) {
+	data, err := json.Marshal(subscription)
+	if err != nil {
+		return "", err
+	}
+	acks, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      subscriptionsStorageCollection,
+		Key:             subscription.SubscriptionId,
+		UserID:          userID,
+		Value:           string(data),
+		Version:         version,
+		PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}})
+	if err != nil {
+		return "", err
+	}
+	if len(acks) == 0 {
+		return "", nil
+	}
+	return acks[0].Version, nil
+}
@@ -0,0 +1,166 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// Summary collects the user's economy state from the wallet, modifiers, purchase intents, donations, auctions and
+// placements.
+func (e *NakamaEconomySystem) Summary(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomySummary, error) {
+	if userID == "" {
+		return nil, runtime.NewError("user ID is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	now := time.Now().Unix()
+	summary := &EconomySummary{ServerTimeSec: now}
+
+	wallet, err := userWallet(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to read wallet of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	summary.Wallet = wallet
+
+	// Modifiers and placement states are read with one call
+	reads := []*runtime.StorageRead{
+		{Collection: userModifiersStorageCollection, Key: userID + "_reward_modifiers", UserID: userID},
+		{Collection: userModifiersStorageCollection, Key: userID + "_energy_modifiers", UserID: userID},
+	}
+	if e.config != nil {
+		for placementID := range e.config.Placements {
+			reads = append(reads, &runtime.StorageRead{Collection: placementStatusStorageCollection, Key: userID + "_" + placementID, UserID: userID})
+		}
+	}
+	objects, err := nk.StorageRead(ctx, reads)
+	if err != nil {
+		logger.Error("Failed to read modifiers and placements of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	for _, object := range objects {
+		switch object.Collection {
+		case userModifiersStorageCollection:
+			modifiers := make([]*ActiveRewardModifier, 0)
+			if err := json.Unmarshal([]byte(object.Value), &modifiers); err != nil {
+				logger.Warn("Failed to unmarshal modifiers %s of user %s: %v", object.Key, userID, err)
+				continue
+			}
+			active := make([]*ActiveRewardModifier, 0, len(modifiers))
+			for _, modifier := range modifiers {
+				if modifier.EndTimeSec == 0 || modifier.EndTimeSec > now {
+					active = append(active, modifier)
+				}
+			}
+			if object.Key == userID+"_reward_modifiers" {
+				summary.RewardModifiers = active
+			} else {
+				summary.EnergyModifiers = active
+			}
+		case placementStatusStorageCollection:
+			state := &placementState{}
+			if err := json.Unmarshal([]byte(object.Value), state); err != nil {
+				logger.Warn("Failed to unmarshal placement %s of user %s: %v", object.Key, userID, err)
+				continue
+			}
+			if state.Status == "started" {
+				summary.Placements = append(summary.Placements, placementStateStatus(strings.TrimPrefix(object.Key, userID+"_"), state))
+			}
+		}
+	}
+	sort.Slice(summary.Placements, func(i, j int) bool {
+		return summary.Placements[i].PlacementId < summary.Placements[j].PlacementId
+	})
+
+	if summary.PurchaseIntents, err = e.summaryPurchaseIntents(ctx, nk, userID, now); err != nil {
+		logger.Error("Failed to list purchase intents of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+
+	donations, err := e.getUserDonations(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to get donations of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	for donationID, donation := range donations {
+		if donation.ExpireTimeSec > 0 && donation.ExpireTimeSec <= now {
+			continue
+		}
+		if claimable := donation.Count - donation.ClaimCount; claimable > 0 {
+			summary.Donations = append(summary.Donations, &EconomySummaryDonation{
+				DonationId:     donationID,
+				ClaimableCount: claimable,
+				ExpireTimeSec:  donation.ExpireTimeSec,
+			})
+		}
+	}
+	sort.Slice(summary.Donations, func(i, j int) bool {
+		return summary.Donations[i].DonationId < summary.Donations[j].DonationId
+	})
+
+	// Auctions are only summarised when the built-in auctions system is in use
+	var auctionsSystem AuctionsSystem
+	if pamlogixInst, ok := e.pamlogix.(interface{ GetAuctionsSystem() AuctionsSystem }); ok {
+		auctionsSystem = pamlogixInst.GetAuctionsSystem()
+	}
+	if auctions, ok := auctionsSystem.(interface {
+		claimableAuctions(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (won, created []*Auction, err error)
+	}); ok {
+		won, created, err := auctions.claimableAuctions(ctx, logger, nk, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, auction := range won {
+			summary.Auctions = append(summary.Auctions, &EconomySummaryAuction{AuctionId: auction.Id, Role: "winner", EndTimeSec: auction.EndTimeSec})
+		}
+		for _, auction := range created {
+			summary.Auctions = append(summary.Auctions, &EconomySummaryAuction{AuctionId: auction.Id, Role: "creator", EndTimeSec: auction.EndTimeSec})
+		}
+		sort.Slice(summary.Auctions, func(i, j int) bool {
+			return summary.Auctions[i].EndTimeSec < summary.Auctions[j].EndTimeSec
+		})
+	}
+
+	return summary, nil
+}
+
+// summaryPurchaseIntents returns the user's purchase intents which are neither consumed nor expired.
+func (e *NakamaEconomySystem) summaryPurchaseIntents(ctx context.Context, nk runtime.NakamaModule, userID string, now int64) ([]*EconomySummaryPurchaseIntent, error) {
+	var intents []*EconomySummaryPurchaseIntent
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageList(ctx, "", userID, purchaseIntentsCollection, 100, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range objects {
+			var purchaseIntent map[string]interface{}
+			if err := json.Unmarshal([]byte(object.Value), &purchaseIntent); err != nil {
+				continue
+			}
+			if consumed, _ := purchaseIntent["is_consumed"].(bool); consumed || purchaseIntentExpired(purchaseIntent, now) {
+				continue
+			}
+			intent := &EconomySummaryPurchaseIntent{}
+			intent.ItemId, _ = purchaseIntent["item_id"].(string)
+			storeType, _ := purchaseIntent["store_type"].(string)
+			intent.StoreType = purchaseStoreTypeName(storeType)
+			if expiresAt, ok := purchaseIntent["expires_at"].(float64); ok {
+				intent.ExpireTimeSec = int64(expiresAt)
+			}
+			intents = append(intents, intent)
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+	sort.Slice(intents, func(i, j int) bool {
+		return intents[i].ItemId < intents[j].ItemId
+	})
+	return intents, nil
+}
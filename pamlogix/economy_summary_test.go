@@ -0,0 +1,79 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	economySystem := p.GetEconomySystem().(*NakamaEconomySystem)
+	economySystem.config.Placements = map[string]*EconomyConfigPlacement{
+		"watching": {},
+		"finished": {},
+	}
+	now := time.Now().Unix()
+
+	_, _, err := nk.WalletUpdate(ctx, "user1", map[string]int64{benchCurrency: 100}, nil, false)
+	require.NoError(t, err)
+
+	auctionData, err := json.Marshal(&Auction{
+		Id:           "auction1",
+		UserId:       "seller",
+		Bid:          &AuctionBid{UserId: "user1", Bid: &AuctionBidAmount{Currencies: map[string]int64{benchCurrency: 10}}},
+		StartTimeSec: now - 100,
+		EndTimeSec:   now - 1,
+	})
+	require.NoError(t, err)
+
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{Collection: userModifiersStorageCollection, Key: "user1_reward_modifiers", UserID: "user1",
+			Value: `[{"id":"xp","end_time_sec":` + strconv.FormatInt(now+60, 10) + `},{"id":"expired","end_time_sec":` + strconv.FormatInt(now-60, 10) + `}]`},
+		{Collection: placementStatusStorageCollection, Key: "user1_watching", UserID: "user1", Value: `{"status":"started","timestamp":` + strconv.FormatInt(now, 10) + `}`},
+		{Collection: placementStatusStorageCollection, Key: "user1_finished", UserID: "user1", Value: `{"status":"completed","timestamp":` + strconv.FormatInt(now, 10) + `}`},
+		{Collection: purchaseIntentsCollection, Key: "purchase_intent:user1:gems", UserID: "user1",
+			Value: `{"item_id":"gems","store_type":"\u0001","is_consumed":false,"expires_at":` + strconv.FormatInt(now+600, 10) + `}`},
+		{Collection: purchaseIntentsCollection, Key: "purchase_intent:user1:coins", UserID: "user1", Value: `{"item_id":"coins","is_consumed":true}`},
+		{Collection: donationsStorageCollection, Key: "donation:hearts", UserID: "user1", Value: `{"id":"hearts","count":3,"claim_count":1}`},
+		{Collection: donationsStorageCollection, Key: "donation:claimed", UserID: "user1", Value: `{"id":"claimed","count":2,"claim_count":2}`},
+		{Collection: AuctionCollectionKey, Key: "auction1", Value: string(auctionData)},
+		{Collection: AuctionCollectionKey, Key: AuctionUserBidsKey + "_user1", Value: `{"auction1":true}`},
+	})
+	require.NoError(t, err)
+
+	summary, err := economySystem.Summary(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int64{benchCurrency: 100}, summary.Wallet)
+	require.Len(t, summary.RewardModifiers, 1)
+	assert.Equal(t, "xp", summary.RewardModifiers[0].Id)
+	assert.Equal(t, now+60, summary.RewardModifiers[0].EndTimeSec)
+	assert.Empty(t, summary.EnergyModifiers)
+
+	require.Len(t, summary.PurchaseIntents, 1)
+	assert.Equal(t, "gems", summary.PurchaseIntents[0].ItemId)
+	assert.Equal(t, "ECONOMY_STORE_TYPE_APPLE_APPSTORE", summary.PurchaseIntents[0].StoreType)
+	assert.Equal(t, now+600, summary.PurchaseIntents[0].ExpireTimeSec)
+
+	require.Len(t, summary.Donations, 1)
+	assert.Equal(t, "hearts", summary.Donations[0].DonationId)
+	assert.Equal(t, int64(2), summary.Donations[0].ClaimableCount)
+
+	require.Len(t, summary.Auctions, 1)
+	assert.Equal(t, "auction1", summary.Auctions[0].AuctionId)
+	assert.Equal(t, "winner", summary.Auctions[0].Role)
+
+	require.Len(t, summary.Placements, 1)
+	assert.Equal(t, "watching", summary.Placements[0].PlacementId)
+	assert.GreaterOrEqual(t, summary.ServerTimeSec, now)
+}
@@ -6,6 +6,15 @@ import (
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
+var (
+	ErrEnergyGiftNotAllowed   = runtime.NewError("energy can't be gifted", INVALID_ARGUMENT_ERROR_CODE)                             // INVALID_ARGUMENT
+	ErrEnergyGiftNotConnected = runtime.NewError("recipient is not a friend or teammate", PERMISSION_DENIED_ERROR_CODE)             // PERMISSION_DENIED
+	ErrEnergyGiftAlreadySent  = runtime.NewError("energy already gifted to this user today", FAILED_PRECONDITION_ERROR_CODE)        // FAILED_PRECONDITION
+	ErrEnergyGiftSendLimit    = runtime.NewError("daily energy gift send limit reached", FAILED_PRECONDITION_ERROR_CODE)            // FAILED_PRECONDITION
+	ErrEnergyGiftReceiveLimit = runtime.NewError("recipient can't receive more energy gifts today", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	ErrEnergyGiftNotFound     = runtime.NewError("energy gift not found", NOT_FOUND_ERROR_CODE)                                     // NOT_FOUND
+)
+
 // EnergyConfig is the data definition for the EnergySystem type.
 type EnergyConfig struct {
 	Energies map[string]*EnergyConfigEnergy `json:"energies,omitempty"`
@@ -20,6 +29,87 @@ type EnergyConfigEnergy struct {
 	Implicit             bool                 `json:"implicit,omitempty"`
 	Reward               *EconomyConfigReward `json:"reward,omitempty"`
 	AdditionalProperties map[string]string    `json:"additional_properties,omitempty"`
+	Gift                 *EnergyConfigGift    `json:"gift,omitempty"`
+	// RefillOffer is the refill offered when a user doesn't have enough of the energy to spend. Nil offers none.
+	RefillOffer *EnergyConfigRefillOffer `json:"refill_offer,omitempty"`
+	// OverflowCurrencies converts energy granted beyond the max and overfill into these currencies, by amount for each
+	// unit of energy. Without them the overflow is discarded.
+	OverflowCurrencies map[string]int64 `json:"overflow_currencies,omitempty"`
+}
+
+// EnergyConfigRefillOffer is a refill of an energy a user can buy to make a spend they don't have enough energy for.
+type EnergyConfigRefillOffer struct {
+	// Amount is how much of the energy the refill adds. Zero fills the energy up to its max.
+	Amount int32 `json:"amount,omitempty"`
+	Cost   *Cost `json:"cost,omitempty"`
+}
+
+// EnergyConfigGift allows users to gift an energy to their friends and teammates. A user can gift each recipient once
+// a day, and the gift waits in the recipient's mailbox until they claim it. Daily limits reset at midnight UTC.
+type EnergyConfigGift struct {
+	Amount int32 `json:"amount,omitempty"`
+	// MaxSendsPerDay and MaxReceivesPerDay cap how many gifts of this energy a user sends and receives a day. Zero is
+	// unlimited.
+	MaxSendsPerDay    int `json:"max_sends_per_day,omitempty"`
+	MaxReceivesPerDay int `json:"max_receives_per_day,omitempty"`
+	// ExpirySec is how long an unclaimed gift stays in the mailbox. Zero keeps it until it's claimed.
+	ExpirySec int64 `json:"expiry_sec,omitempty"`
+}
+
+// EnergyGift is an energy gift waiting in a user's mailbox.
+type EnergyGift struct {
+	Id            string `json:"id"`
+	EnergyId      string `json:"energy_id"`
+	SenderId      string `json:"sender_id"`
+	Amount        int32  `json:"amount"`
+	CreateTimeSec int64  `json:"create_time_sec"`
+	ExpireTimeSec int64  `json:"expire_time_sec,omitempty"`
+}
+
+// EnergyRefillOffer is the refill of an energy a user can buy right now, and what it costs.
+type EnergyRefillOffer struct {
+	EnergyId string `json:"energy_id"`
+	Amount   int32  `json:"amount"`
+	Cost     *Cost  `json:"cost,omitempty"`
+}
+
+// EnergyInsufficientError is returned by Spend when the user doesn't have enough of an energy with a refill offer, so
+// clients can show the offer. It matches ErrEnergyInsufficient, and RPC clients receive it as a FAILED_PRECONDITION
+// error whose message is the error as JSON.
+type EnergyInsufficientError struct {
+	EnergyId    string             `json:"energy_id"`
+	Current     int32              `json:"current"`
+	Required    int32              `json:"required"`
+	RefillOffer *EnergyRefillOffer `json:"refill_offer"`
+}
+
+// EnergySpendWithRefillRequest is the request payload to spend energies, buying the refills offered for any the user
+// doesn't have enough of.
+type EnergySpendWithRefillRequest struct {
+	Amounts map[string]int32 `json:"amounts"`
+}
+
+// EnergySpendWithRefill is the result of a spend which bought refills.
+type EnergySpendWithRefill struct {
+	Energies map[string]*Energy   `json:"energies"`
+	Reward   *Reward              `json:"reward,omitempty"`
+	Refills  []*EnergyRefillOffer `json:"refills,omitempty"`
+}
+
+// EnergyGiftSendRequest is the request payload to gift an energy to a friend or teammate.
+type EnergyGiftSendRequest struct {
+	RecipientId string `json:"recipient_id"`
+	EnergyId    string `json:"energy_id"`
+}
+
+// EnergyGiftList is the energy gifts in a user's mailbox.
+type EnergyGiftList struct {
+	Gifts []*EnergyGift `json:"gifts"`
+}
+
+// EnergyGiftClaimRequest is the request payload to claim energy gifts. No IDs claims every gift in the mailbox.
+type EnergyGiftClaimRequest struct {
+	Ids []string `json:"ids,omitempty"`
 }
 
 // The EnergySystem provides a gameplay system for Energy timers.
@@ -35,9 +125,23 @@ type EnergySystem interface {
 	// Spend will deduct the amounts from each energy for a user by ID.
 	Spend(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, amounts map[string]int32) (energies map[string]*Energy, reward *Reward, err error)
 
-	// Grant will add the amounts to each energy (while applying any energy modifiers) for a user by ID.
+	// SpendWithRefill spends the amounts like Spend, first buying the refill offered for each energy the user doesn't
+	// have enough of. The refills and the spend succeed or fail together.
+	SpendWithRefill(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, amounts map[string]int32) (result *EnergySpendWithRefill, err error)
+
+	// Grant will add the amounts to each energy (while applying any energy modifiers) for a user by ID. Energy beyond
+	// the max and overfill is converted into the energy's overflow currencies, or discarded without them.
 	Grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, amounts map[string]int32, modifiers []*RewardEnergyModifier) (energies map[string]*Energy, err error)
 
+	// SendGift gifts the configured amount of an energy to a friend or teammate of the user, within the daily limits.
+	SendGift(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, recipientID, energyID string) (gift *EnergyGift, err error)
+
+	// ListGifts returns the unclaimed energy gifts in the user's mailbox.
+	ListGifts(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (gifts []*EnergyGift, err error)
+
+	// ClaimGifts grants the user the energy gifts with the given IDs, or every gift in their mailbox if none are given.
+	ClaimGifts(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, giftIDs []string) (energies map[string]*Energy, err error)
+
 	// SetOnSpendReward sets a custom reward function which will run after an energy reward's value has been rolled.
 	SetOnSpendReward(fn OnReward[*EnergyConfigEnergy])
 }
@@ -0,0 +1,328 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	energyGiftStorageCollection = "energy_gifts"
+	energyGiftMailboxStorageKey = "mailbox"
+	energyGiftSentStorageKey    = "sent"
+
+	energyGiftsSentMetric    = "pamlogix_energy_gifts_sent"
+	energyGiftsClaimedMetric = "pamlogix_energy_gifts_claimed"
+
+	energyGiftListLimit = 100
+)
+
+// energyGiftMailbox holds the gifts a user hasn't claimed yet, and how many of each energy they've received today.
+type energyGiftMailbox struct {
+	Gifts    []*EnergyGift  `json:"gifts,omitempty"`
+	Day      int64          `json:"day"`
+	Received map[string]int `json:"received,omitempty"`
+}
+
+// energyGiftsSent holds who a user has gifted each energy to today.
+type energyGiftsSent struct {
+	Day        int64               `json:"day"`
+	Recipients map[string][]string `json:"recipients,omitempty"`
+}
+
+// SendGift gifts the configured amount of an energy to a friend or teammate of the user, within the daily limits.
+func (e *NakamaEnergySystem) SendGift(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, recipientID, energyID string) (*EnergyGift, error) {
+	giftConfig := e.giftConfig(energyID)
+	if giftConfig == nil {
+		return nil, ErrEnergyGiftNotAllowed
+	}
+	if recipientID == "" || recipientID == userID {
+		return nil, ErrBadInput
+	}
+
+	now := time.Now()
+	day := energyGiftDay(now)
+
+	sent := &energyGiftsSent{}
+	if err := readEnergyGiftObject(ctx, nk, userID, energyGiftSentStorageKey, sent); err != nil {
+		logger.Error("Failed to read energy gifts sent by user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	if sent.Day != day || sent.Recipients == nil {
+		sent.Day = day
+		sent.Recipients = make(map[string][]string)
+	}
+	if slices.Contains(sent.Recipients[energyID], recipientID) {
+		return nil, ErrEnergyGiftAlreadySent
+	}
+	if giftConfig.MaxSendsPerDay > 0 && len(sent.Recipients[energyID]) >= giftConfig.MaxSendsPerDay {
+		return nil, ErrEnergyGiftSendLimit
+	}
+
+	connected, err := energyGiftConnected(ctx, nk, userID, recipientID)
+	if err != nil {
+		logger.Error("Failed to check if users %s and %s are friends or teammates: %v", userID, recipientID, err)
+		return nil, ErrInternal
+	}
+	if !connected {
+		return nil, ErrEnergyGiftNotConnected
+	}
+
+	gift := &EnergyGift{
+		Id:            uuid.NewString(),
+		EnergyId:      energyID,
+		SenderId:      userID,
+		Amount:        giftConfig.Amount,
+		CreateTimeSec: now.Unix(),
+	}
+	if giftConfig.ExpirySec > 0 {
+		gift.ExpireTimeSec = now.Unix() + giftConfig.ExpirySec
+	}
+
+	// Senders deliver to the mailbox concurrently, and the recipient claims from it, so it's changed under a lock.
+	err = withStorageLock(ctx, nk, energyGiftLockName(recipientID), func() error {
+		mailbox := &energyGiftMailbox{}
+		if err := readEnergyGiftObject(ctx, nk, recipientID, energyGiftMailboxStorageKey, mailbox); err != nil {
+			logger.Error("Failed to read energy gift mailbox of user %s: %v", recipientID, err)
+			return ErrInternal
+		}
+		if mailbox.Day != day || mailbox.Received == nil {
+			mailbox.Day = day
+			mailbox.Received = make(map[string]int)
+		}
+		if giftConfig.MaxReceivesPerDay > 0 && mailbox.Received[energyID] >= giftConfig.MaxReceivesPerDay {
+			return ErrEnergyGiftReceiveLimit
+		}
+		mailbox.Gifts = append(unexpiredEnergyGifts(mailbox.Gifts, now.Unix()), gift)
+		mailbox.Received[energyID]++
+		if err := writeEnergyGiftObject(ctx, nk, recipientID, energyGiftMailboxStorageKey, mailbox); err != nil {
+			logger.Error("Failed to write energy gift mailbox of user %s: %v", recipientID, err)
+			return ErrInternal
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sent.Recipients[energyID] = append(sent.Recipients[energyID], recipientID)
+	if err := writeEnergyGiftObject(ctx, nk, userID, energyGiftSentStorageKey, sent); err != nil {
+		// The gift is delivered, so only the sender's daily count is off.
+		logger.Error("Failed to write energy gifts sent by user %s: %v", userID, err)
+	}
+
+	nk.MetricsCounterAdd(energyGiftsSentMetric, map[string]string{"energy_id": energyID}, 1)
+
+	vars := map[string]string{"energy_id": energyID, "amount": strconv.Itoa(int(gift.Amount))}
+	content := map[string]interface{}{"gift_id": gift.Id, "energy_id": energyID, "sender_id": userID, "amount": gift.Amount}
+	if err := sendTemplatedNotification(ctx, logger, nk, e.pamlogix, recipientID, NotificationEventEnergyGift, vars, content); err != nil {
+		logger.Warn("Failed to send energy gift notification to user %s: %v", recipientID, err)
+	}
+
+	return gift, nil
+}
+
+// ListGifts returns the unclaimed energy gifts in the user's mailbox.
+func (e *NakamaEnergySystem) ListGifts(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) ([]*EnergyGift, error) {
+	mailbox := &energyGiftMailbox{}
+	if err := readEnergyGiftObject(ctx, nk, userID, energyGiftMailboxStorageKey, mailbox); err != nil {
+		logger.Error("Failed to read energy gift mailbox of user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+	gifts := unexpiredEnergyGifts(mailbox.Gifts, time.Now().Unix())
+	if gifts == nil {
+		gifts = []*EnergyGift{}
+	}
+	return gifts, nil
+}
+
+// ClaimGifts grants the user the energy gifts with the given IDs, or every gift in their mailbox if none are given.
+func (e *NakamaEnergySystem) ClaimGifts(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, giftIDs []string) (map[string]*Energy, error) {
+	var claimed []*EnergyGift
+	err := withStorageLock(ctx, nk, energyGiftLockName(userID), func() error {
+		mailbox := &energyGiftMailbox{}
+		if err := readEnergyGiftObject(ctx, nk, userID, energyGiftMailboxStorageKey, mailbox); err != nil {
+			logger.Error("Failed to read energy gift mailbox of user %s: %v", userID, err)
+			return ErrInternal
+		}
+
+		gifts := unexpiredEnergyGifts(mailbox.Gifts, time.Now().Unix())
+		remaining := make([]*EnergyGift, 0, len(gifts))
+		for _, gift := range gifts {
+			if len(giftIDs) == 0 || slices.Contains(giftIDs, gift.Id) {
+				claimed = append(claimed, gift)
+			} else {
+				remaining = append(remaining, gift)
+			}
+		}
+		if len(giftIDs) > 0 && len(claimed) != len(giftIDs) {
+			return ErrEnergyGiftNotFound
+		}
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		// The gifts leave the mailbox before they're granted, so a failed grant can't be claimed twice.
+		mailbox.Gifts = remaining
+		if err := writeEnergyGiftObject(ctx, nk, userID, energyGiftMailboxStorageKey, mailbox); err != nil {
+			logger.Error("Failed to write energy gift mailbox of user %s: %v", userID, err)
+			return ErrInternal
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(claimed) == 0 {
+		return e.Get(ctx, logger, nk, userID)
+	}
+
+	amounts := make(map[string]int32)
+	for _, gift := range claimed {
+		amounts[gift.EnergyId] += gift.Amount
+	}
+	energies, err := e.Grant(ctx, logger, nk, userID, amounts, nil)
+	if err != nil {
+		logger.Error("Failed to grant %d claimed energy gifts to user %s: %v", len(claimed), userID, err)
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	for _, gift := range claimed {
+		counts[gift.EnergyId]++
+	}
+	for energyID, count := range counts {
+		nk.MetricsCounterAdd(energyGiftsClaimedMetric, map[string]string{"energy_id": energyID}, count)
+	}
+
+	return energies, nil
+}
+
+// giftConfig returns the gift config of the energy, or nil if it can't be gifted.
+func (e *NakamaEnergySystem) giftConfig(energyID string) *EnergyConfigGift {
+	if e.config == nil {
+		return nil
+	}
+	energyConfig, found := e.config.Energies[energyID]
+	if !found || energyConfig.Gift == nil || energyConfig.Gift.Amount <= 0 {
+		return nil
+	}
+	return energyConfig.Gift
+}
+
+// energyGiftConnected reports whether the users are mutual friends or members of the same team.
+func energyGiftConnected(ctx context.Context, nk runtime.NakamaModule, userID, recipientID string) (bool, error) {
+	state := int(api.Friend_FRIEND)
+	cursor := ""
+	for {
+		friends, next, err := nk.FriendsList(ctx, userID, energyGiftListLimit, &state, cursor)
+		if err != nil {
+			return false, err
+		}
+		for _, friend := range friends {
+			if friend.GetUser().GetId() == recipientID {
+				return true, nil
+			}
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	teamIDs, err := energyGiftTeamIDs(ctx, nk, userID)
+	if err != nil || len(teamIDs) == 0 {
+		return false, err
+	}
+	recipientTeamIDs, err := energyGiftTeamIDs(ctx, nk, recipientID)
+	if err != nil {
+		return false, err
+	}
+	for teamID := range recipientTeamIDs {
+		if teamIDs[teamID] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// energyGiftTeamIDs returns the teams the user is a member of, leaving out ones they've only asked to join.
+func energyGiftTeamIDs(ctx context.Context, nk runtime.NakamaModule, userID string) (map[string]bool, error) {
+	teamIDs := make(map[string]bool)
+	cursor := ""
+	for {
+		userGroups, next, err := nk.UserGroupsList(ctx, userID, energyGiftListLimit, nil, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, userGroup := range userGroups {
+			if userGroup.GetState().GetValue() <= int32(api.UserGroupList_UserGroup_MEMBER) {
+				teamIDs[userGroup.GetGroup().GetId()] = true
+			}
+		}
+		if next == "" {
+			return teamIDs, nil
+		}
+		cursor = next
+	}
+}
+
+func unexpiredEnergyGifts(gifts []*EnergyGift, now int64) []*EnergyGift {
+	var unexpired []*EnergyGift
+	for _, gift := range gifts {
+		if gift.ExpireTimeSec == 0 || gift.ExpireTimeSec > now {
+			unexpired = append(unexpired, gift)
+		}
+	}
+	return unexpired
+}
+
+// energyGiftDay is the UTC day number the daily gift limits are counted in.
+func energyGiftDay(now time.Time) int64 {
+	return now.UTC().Unix() / 86400
+}
+
+func energyGiftLockName(userID string) string {
+	return fmt.Sprintf("%s:%s", energyGiftStorageCollection, userID)
+}
+
+func readEnergyGiftObject(ctx context.Context, nk runtime.NakamaModule, userID, key string, value any) error {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: energyGiftStorageCollection, Key: key, UserID: userID},
+	})
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if object.UserId == userID && object.Key == key {
+			return json.Unmarshal([]byte(object.Value), value)
+		}
+	}
+	return nil
+}
+
+// writeEnergyGiftObject stores gift state under the user. Clients can read it but only the server changes it.
+func writeEnergyGiftObject(ctx context.Context, nk runtime.NakamaModule, userID, key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      energyGiftStorageCollection,
+			Key:             key,
+			UserID:          userID,
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+	return err
+}
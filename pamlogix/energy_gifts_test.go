@@ -0,0 +1,122 @@
+package pamlogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// energyGiftNakama adds friendships and recorded metrics to the tournament fake's teams and notifications.
+type energyGiftNakama struct {
+	*tournamentNakama
+	friends map[string][]string
+	metrics map[string]int64
+}
+
+func newEnergyGiftNakama() *energyGiftNakama {
+	return &energyGiftNakama{
+		tournamentNakama: newTournamentNakama(),
+		friends:          make(map[string][]string),
+		metrics:          make(map[string]int64),
+	}
+}
+
+func (n *energyGiftNakama) FriendsList(ctx context.Context, userID string, limit int, state *int, cursor string) ([]*api.Friend, string, error) {
+	var friends []*api.Friend
+	for _, friendID := range n.friends[userID] {
+		friends = append(friends, &api.Friend{User: &api.User{Id: friendID}})
+	}
+	return friends, "", nil
+}
+
+func (n *energyGiftNakama) MetricsCounterAdd(name string, tags map[string]string, delta int64) {
+	n.metrics[name] += delta
+}
+
+func newTestGiftEnergySystem() *NakamaEnergySystem {
+	return NewNakamaEnergySystem(&EnergyConfig{
+		Energies: map[string]*EnergyConfigEnergy{
+			"lives": {
+				StartCount:  5,
+				MaxCount:    5,
+				MaxOverfill: 5,
+				Gift:        &EnergyConfigGift{Amount: 1, MaxSendsPerDay: 2, MaxReceivesPerDay: 2},
+			},
+			"stamina": {StartCount: 10, MaxCount: 10},
+		},
+	})
+}
+
+func TestEnergySendGift_Limits(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newEnergyGiftNakama()
+	nk.friends["user1"] = []string{"user2", "user3", "user4"}
+	nk.friends["user5"] = []string{"user2"}
+	nk.friends["user6"] = []string{"user2"}
+	energySystem := newTestGiftEnergySystem()
+
+	_, err := energySystem.SendGift(ctx, logger, nk, "user1", "user2", "stamina")
+	assert.ErrorIs(t, err, ErrEnergyGiftNotAllowed)
+	_, err = energySystem.SendGift(ctx, logger, nk, "user1", "stranger", "lives")
+	assert.ErrorIs(t, err, ErrEnergyGiftNotConnected)
+
+	gift, err := energySystem.SendGift(ctx, logger, nk, "user1", "user2", "lives")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), gift.Amount)
+	_, err = energySystem.SendGift(ctx, logger, nk, "user1", "user2", "lives")
+	assert.ErrorIs(t, err, ErrEnergyGiftAlreadySent)
+
+	_, err = energySystem.SendGift(ctx, logger, nk, "user1", "user3", "lives")
+	require.NoError(t, err)
+	_, err = energySystem.SendGift(ctx, logger, nk, "user1", "user4", "lives")
+	assert.ErrorIs(t, err, ErrEnergyGiftSendLimit)
+
+	// user2 already received one gift today
+	_, err = energySystem.SendGift(ctx, logger, nk, "user5", "user2", "lives")
+	require.NoError(t, err)
+	_, err = energySystem.SendGift(ctx, logger, nk, "user6", "user2", "lives")
+	assert.ErrorIs(t, err, ErrEnergyGiftReceiveLimit)
+
+	assert.Equal(t, int64(3), nk.metrics[energyGiftsSentMetric])
+	require.Len(t, nk.sent, 3)
+	assert.Equal(t, 1202, nk.sent[0].code)
+	assert.Equal(t, "user2", nk.sent[0].userID)
+}
+
+func TestEnergyClaimGifts_FromTeammates(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newEnergyGiftNakama()
+	nk.teams["team1"] = []string{"user1", "user2", "user3"}
+	energySystem := newTestGiftEnergySystem()
+
+	first, err := energySystem.SendGift(ctx, logger, nk, "user1", "user3", "lives")
+	require.NoError(t, err)
+	_, err = energySystem.SendGift(ctx, logger, nk, "user2", "user3", "lives")
+	require.NoError(t, err)
+
+	gifts, err := energySystem.ListGifts(ctx, logger, nk, "user3")
+	require.NoError(t, err)
+	assert.Len(t, gifts, 2)
+
+	_, err = energySystem.ClaimGifts(ctx, logger, nk, "user3", []string{"unknown"})
+	assert.ErrorIs(t, err, ErrEnergyGiftNotFound)
+
+	energies, err := energySystem.ClaimGifts(ctx, logger, nk, "user3", []string{first.Id})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), energies["lives"].Current)
+
+	// Claiming without IDs takes the rest of the mailbox
+	energies, err = energySystem.ClaimGifts(ctx, logger, nk, "user3", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), energies["lives"].Current)
+
+	gifts, err = energySystem.ListGifts(ctx, logger, nk, "user3")
+	require.NoError(t, err)
+	assert.Empty(t, gifts)
+	assert.Equal(t, int64(2), nk.metrics[energyGiftsClaimedMetric])
+}
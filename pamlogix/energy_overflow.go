@@ -0,0 +1,52 @@
+package pamlogix
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+func validateEnergyOverflowCurrencies(config *EnergyConfig) error {
+	for energyID, energyConfig := range config.Energies {
+		if energyConfig == nil {
+			continue
+		}
+		for currency, amount := range energyConfig.OverflowCurrencies {
+			if currency == "" || amount <= 0 {
+				return fmt.Errorf("overflow currencies of energy %s must be positive", energyID)
+			}
+		}
+	}
+	return nil
+}
+
+// addOverflowCurrencies adds what the overflowing amount of an energy converts into to currencies. Nothing is added
+// for an energy which discards its overflow.
+func addOverflowCurrencies(currencies map[string]int64, energyConfig *EnergyConfigEnergy, overflow int32) {
+	if overflow <= 0 {
+		return
+	}
+	for currency, amount := range energyConfig.OverflowCurrencies {
+		currencies[currency] += amount * int64(overflow)
+	}
+}
+
+// grantOverflowCurrencies grants the currencies overflowing energies converted into through the economy, so the
+// currencies' max balances apply. Reward grants add them to the reward instead. The energies are already saved by
+// then, so a failure is logged rather than failing the grant.
+func (e *NakamaEnergySystem) grantOverflowCurrencies(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64) {
+	if len(currencies) == 0 {
+		return
+	}
+	if e.pamlogix == nil || e.pamlogix.GetEconomySystem() == nil {
+		logger.Warn("Cannot grant energy overflow currencies: no EconomySystem available")
+		return
+	}
+	metadata := map[string]interface{}{
+		"source": "energy_overflow",
+	}
+	if _, _, _, err := e.pamlogix.GetEconomySystem().RewardGrant(ctx, logger, nk, userID, &Reward{Currencies: currencies}, metadata, false); err != nil {
+		logger.Error("Failed to grant energy overflow currencies %v to user %s: %v", currencies, userID, err)
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"math"
+	"strconv"
 	"time"
 
+	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
 const (
 	energyStorageCollection = "energy"
 	userEnergyStorageKey    = "user_energies"
+
+	// energySpentEvent is the publisher event sent for each energy a user spends, with the amount spent as its value.
+	energySpentEvent = "energy_spent"
 )
 
 // NakamaEnergySystem implements the EnergySystem interface using Nakama as the backend.
@@ -96,6 +101,12 @@ func (e *NakamaEnergySystem) Get(ctx context.Context, logger runtime.Logger, nk
 
 // Spend will deduct the amounts from each energy for a user by ID.
 func (e *NakamaEnergySystem) Spend(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, amounts map[string]int32) (map[string]*Energy, *Reward, error) {
+	return e.spend(ctx, logger, nk, userID, amounts, nil)
+}
+
+// spend deducts the amounts from each energy after adding any refills the user bought for the spend, saving both
+// together.
+func (e *NakamaEnergySystem) spend(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, amounts, refills map[string]int32) (map[string]*Energy, *Reward, error) {
 	if e.config == nil || len(e.config.Energies) == 0 {
 		// No energies are configured
 		return make(map[string]*Energy), nil, ErrSystemNotAvailable
@@ -107,7 +118,14 @@ func (e *NakamaEnergySystem) Spend(ctx context.Context, logger runtime.Logger, n
 		return nil, nil, err
 	}
 
+	for id, amount := range refills {
+		if energy, exists := energies[id]; exists {
+			energy.Current += amount
+		}
+	}
+
 	// Validate and apply the spend amounts
+	now := time.Now().Unix()
 	for id, amount := range amounts {
 		_, exists := e.config.Energies[id]
 		if !exists {
@@ -124,22 +142,19 @@ func (e *NakamaEnergySystem) Spend(ctx context.Context, logger runtime.Logger, n
 		// Check if user has enough energy to spend
 		if energy.Current < amount {
 			logger.Warn("Insufficient energy to spend: %s (have: %d, need: %d)", id, energy.Current, amount)
+			if offer := e.refillOffer(id, energy); offer != nil && refills == nil {
+				return nil, nil, &EnergyInsufficientError{EnergyId: id, Current: energy.Current, Required: amount, RefillOffer: offer}
+			}
 			return nil, nil, ErrBadInput
 		}
 
-		// Deduct the energy
+		// Deduct the energy. Refilling starts once it drops below its max, and otherwise keeps its progress
+		wasFull := energy.Current >= energy.Max
 		energy.Current -= amount
-
-		// If we reach 0 and there's a refill timer, set the start refill time
-		if energy.Current == 0 && energy.RefillSec > 0 {
-			now := time.Now().Unix()
+		if wasFull && energy.Current < energy.Max {
 			energy.StartRefillTimeSec = now
-			energy.NextRefillTimeSec = now + energy.RefillSec
-			energy.MaxRefillTimeSec = now + (energy.RefillSec * int64((energy.Max / energy.Refill)))
-			if energy.Max%energy.Refill > 0 {
-				energy.MaxRefillTimeSec += energy.RefillSec
-			}
 		}
+		e.calculateRefillTimes(energy, now)
 	}
 
 	// Save the updated energy values
@@ -148,6 +163,22 @@ func (e *NakamaEnergySystem) Spend(ctx context.Context, logger runtime.Logger, n
 		return nil, nil, ErrInternal
 	}
 
+	if e.pamlogix != nil {
+		events := make([]*PublisherEvent, 0, len(amounts))
+		for id, amount := range amounts {
+			events = append(events, &PublisherEvent{
+				Name:      energySpentEvent,
+				Id:        id,
+				Timestamp: now,
+				Value:     strconv.Itoa(int(amount)),
+				System:    e,
+				SourceId:  id,
+				Source:    e.config.Energies[id],
+			})
+		}
+		e.pamlogix.SendPublisherEvents(ctx, logger, nk, userID, events)
+	}
+
 	// Process reward if applicable
 	var reward *Reward = nil
 
@@ -183,10 +214,32 @@ func (e *NakamaEnergySystem) Grant(ctx context.Context, logger runtime.Logger, n
 		return make(map[string]*Energy), nil
 	}
 
+	energies, write, overflowCurrencies, err := e.prepareGrant(ctx, logger, nk, userID, amounts, modifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the updated energy values. The whole list is written, so a retry leaves the same state
+	if err := retryNakama(ctx, idempotentCall, func() error {
+		_, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{write})
+		return err
+	}); err != nil {
+		logger.Error("Failed to save user energies: %v", err)
+		return nil, ErrInternal
+	}
+
+	e.grantOverflowCurrencies(ctx, logger, nk, userID, overflowCurrencies)
+
+	return energies, nil
+}
+
+// prepareGrant adds the amounts to the user's energies like Grant, without saving them. It returns the storage write
+// which saves them, and the currencies their overflow converts into, so a reward can write them with its own grant.
+func (e *NakamaEnergySystem) prepareGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, amounts map[string]int32, modifiers []*RewardEnergyModifier) (map[string]*Energy, *runtime.StorageWrite, map[string]int64, error) {
 	// Fetch current energy values
 	energies, err := e.Get(ctx, logger, nk, userID)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	now := time.Now().Unix()
@@ -226,6 +279,7 @@ func (e *NakamaEnergySystem) Grant(ctx context.Context, logger runtime.Logger, n
 	}
 
 	// Apply the grant amounts
+	overflowCurrencies := make(map[string]int64)
 	for id, amount := range amounts {
 		energyConfig, exists := e.config.Energies[id]
 		if !exists {
@@ -267,6 +321,7 @@ func (e *NakamaEnergySystem) Grant(ctx context.Context, logger runtime.Logger, n
 
 		energy.Current += modifiedAmount
 		if energy.Current > maxWithOverfill {
+			addOverflowCurrencies(overflowCurrencies, energyConfig, min(energy.Current-maxWithOverfill, modifiedAmount))
 			energy.Current = maxWithOverfill
 		}
 
@@ -282,13 +337,12 @@ func (e *NakamaEnergySystem) Grant(ctx context.Context, logger runtime.Logger, n
 		}
 	}
 
-	// Save the updated energy values
-	if err := e.saveUserEnergies(ctx, logger, nk, userID, energies); err != nil {
-		logger.Error("Failed to save user energies: %v", err)
-		return nil, ErrInternal
+	write, err := userEnergiesWrite(userID, energies)
+	if err != nil {
+		logger.Error("Failed to marshal user energies: %v", err)
+		return nil, nil, nil, ErrInternal
 	}
-
-	return energies, nil
+	return energies, write, overflowCurrencies, nil
 }
 
 // SetOnSpendReward sets a custom reward function which will run after an energy reward's value has been rolled.
@@ -301,12 +355,16 @@ func (e *NakamaEnergySystem) SetOnSpendReward(fn OnReward[*EnergyConfigEnergy])
 // getUserEnergies fetches the stored energy data for a user from Nakama storage.
 func (e *NakamaEnergySystem) getUserEnergies(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (map[string]*Energy, error) {
 	// Read from storage
-	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
-		{
-			Collection: energyStorageCollection,
-			Key:        userEnergyStorageKey,
-			UserID:     userID,
-		},
+	var objects []*api.StorageObject
+	err := retryNakama(ctx, idempotentCall, func() (err error) {
+		objects, err = nk.StorageRead(ctx, []*runtime.StorageRead{
+			{
+				Collection: energyStorageCollection,
+				Key:        userEnergyStorageKey,
+				UserID:     userID,
+			},
+		})
+		return err
 	})
 
 	if err != nil {
@@ -337,27 +395,16 @@ func (e *NakamaEnergySystem) getUserEnergies(ctx context.Context, logger runtime
 
 // saveUserEnergies stores the updated energy data for a user in Nakama storage.
 func (e *NakamaEnergySystem) saveUserEnergies(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, energies map[string]*Energy) error {
-	// Marshal the energy data
-	energyList := &EnergyList{
-		Energies: energies,
-	}
-
-	data, err := json.Marshal(energyList)
+	write, err := userEnergiesWrite(userID, energies)
 	if err != nil {
 		logger.Error("Failed to marshal user energies: %v", err)
 		return err
 	}
 
-	// Write to storage
-	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-		{
-			Collection:      energyStorageCollection,
-			Key:             userEnergyStorageKey,
-			UserID:          userID,
-			Value:           string(data),
-			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
-			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
-		},
+	// Write to storage. The whole list is written, so a retry leaves the same state
+	err = retryNakama(ctx, idempotentCall, func() error {
+		_, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{write})
+		return err
 	})
 
 	if err != nil {
@@ -368,6 +415,25 @@ func (e *NakamaEnergySystem) saveUserEnergies(ctx context.Context, logger runtim
 	return nil
 }
 
+// userEnergiesWrite returns the storage write which saves the user's energies.
+func userEnergiesWrite(userID string, energies map[string]*Energy) (*runtime.StorageWrite, error) {
+	data, err := json.Marshal(&EnergyList{
+		Energies: energies,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &runtime.StorageWrite{
+		Collection:      energyStorageCollection,
+		Key:             userEnergyStorageKey,
+		UserID:          userID,
+		Value:           string(data),
+		PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
+	}, nil
+}
+
 // applyRefills calculates and applies any energy refills that should have occurred since the last check.
 func (e *NakamaEnergySystem) applyRefills(energy *Energy, now int64) {
 	// If already at max, no refills needed
@@ -411,44 +477,37 @@ func (e *NakamaEnergySystem) applyRefills(energy *Energy, now int64) {
 	energy.StartRefillTimeSec += refillCount * energy.RefillSec
 }
 
-// calculateRefillTimes updates the next refill time and max refill time for an energy.
+// calculateRefillTimes updates the next refill time, the seconds until it and the max refill time for an energy.
 func (e *NakamaEnergySystem) calculateRefillTimes(energy *Energy, now int64) {
+	energy.NextRefillTimeSec = 0
+	energy.NextRefillSec = 0
+	energy.MaxRefillTimeSec = 0
+
 	// If already at max, no refills needed
 	if energy.Current >= energy.Max {
-		energy.NextRefillTimeSec = 0
-		energy.MaxRefillTimeSec = 0
 		return
 	}
 
 	// Check for infinite energy modifier
 	if hasInfiniteEnergyModifier(energy) {
-		energy.NextRefillTimeSec = 0
-		energy.MaxRefillTimeSec = 0
 		return
 	}
 
 	// If no refill rate, can't calculate refill times
 	if energy.RefillSec <= 0 || energy.Refill <= 0 {
-		energy.NextRefillTimeSec = 0
-		energy.MaxRefillTimeSec = 0
 		return
 	}
 
 	// Calculate time since last refill
 	timeSinceLastRefill := now - energy.StartRefillTimeSec
 
-	// Calculate time until next refill
+	// Calculate time until next refill. A refill due right now has already been applied, so the next is a full
+	// refill period away
 	timeUntilNextRefill := energy.RefillSec - (timeSinceLastRefill % energy.RefillSec)
-	if timeUntilNextRefill == energy.RefillSec {
-		timeUntilNextRefill = 0
-	}
 
 	// Set next refill time
-	if timeUntilNextRefill > 0 {
-		energy.NextRefillTimeSec = now + timeUntilNextRefill
-	} else {
-		energy.NextRefillTimeSec = now
-	}
+	energy.NextRefillTimeSec = now + timeUntilNextRefill
+	energy.NextRefillSec = timeUntilNextRefill
 
 	// Calculate max refill time (time until fully refilled)
 	refillsNeeded := int64(0)
@@ -656,9 +656,6 @@ func TestEnergySystem_RefillTimeCalculations(t *testing.T) {
 	// Calculate expected times for debugging
 	timeSinceLastRefill := now - energy3.StartRefillTimeSec
 	timeUntilNextRefill := energy3.RefillSec - (timeSinceLastRefill % energy3.RefillSec)
-	if timeUntilNextRefill == energy3.RefillSec {
-		timeUntilNextRefill = 0
-	}
 	expectedNextRefillTime := now + timeUntilNextRefill
 
 	// Calculate refills needed
@@ -675,9 +672,9 @@ func TestEnergySystem_RefillTimeCalculations(t *testing.T) {
 	t.Logf("refillsNeededCalc: %d", refillsNeededCalc)
 	t.Logf("expectedMaxRefillTimeCalc: %d", expectedMaxRefillTimeCalc)
 
-	// When StartRefillTimeSec equals now, the next refill is immediately (now + 300 seconds)
-	// But since timeUntilNextRefill is 0 (as there's no elapsed time), NextRefillTimeSec is now
-	assert.Equal(t, now, energy3.NextRefillTimeSec)
+	// When StartRefillTimeSec equals now, the next refill is a full refill period away
+	assert.Equal(t, now+300, energy3.NextRefillTimeSec)
+	assert.Equal(t, int64(300), energy3.NextRefillSec)
 
 	// 8 refills needed for full (5 + 2 = 7, 7 + 2 = 9, 9 + 2 = 11, 11 + 2 = 13, 13 + 2 = 15, 15 + 2 = 17, 17 + 2 = 19, 19 + 2 = 21 > 20)
 	// Using ceiling division: (Max - Current + Refill - 1) / Refill = (20 - 5 + 2 - 1) / 2 = 16 / 2 = 8 refills
@@ -712,3 +709,150 @@ func TestEnergySystem_RefillTimeCalculations(t *testing.T) {
 	expectedMaxRefillTime = energy4.NextRefillTimeSec + energy4.RefillSec*(refillsNeeded-1)
 	assert.Equal(t, expectedMaxRefillTime, energy4.MaxRefillTimeSec)
 }
+
+func TestEnergySystem_SpendWithRefill(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+
+	p := newBenchPamlogix()
+	energySystem := NewNakamaEnergySystem(&EnergyConfig{
+		Energies: map[string]*EnergyConfigEnergy{
+			"lives": {
+				StartCount:  2,
+				MaxCount:    5,
+				RefillOffer: &EnergyConfigRefillOffer{Cost: &Cost{Currencies: map[string]int64{benchCurrency: 30}}},
+			},
+			"tickets": {StartCount: 1, MaxCount: 5},
+		},
+	})
+	energySystem.SetPamlogix(p)
+	p.systems[SystemTypeEnergy] = energySystem
+
+	// Spending more than the user has reports the refill they can buy
+	_, _, err := energySystem.Spend(ctx, logger, nk, "user1", map[string]int32{"lives": 4})
+	var insufficient *EnergyInsufficientError
+	require.ErrorAs(t, err, &insufficient)
+	assert.ErrorIs(t, err, ErrEnergyInsufficient)
+	assert.Equal(t, int32(2), insufficient.Current)
+	assert.Equal(t, int32(4), insufficient.Required)
+	assert.Equal(t, &EnergyRefillOffer{EnergyId: "lives", Amount: 3, Cost: &Cost{Currencies: map[string]int64{benchCurrency: 30}}}, insufficient.RefillOffer)
+
+	rpcErr, ok := energyRpcError(err).(*runtime.Error)
+	require.True(t, ok)
+	assert.Equal(t, FAILED_PRECONDITION_ERROR_CODE, int(rpcErr.Code))
+	assert.Contains(t, rpcErr.Message, `"refill_offer":{"energy_id":"lives","amount":3`)
+
+	// Without the currency nothing is bought or spent
+	_, err = energySystem.SpendWithRefill(ctx, logger, nk, "user1", map[string]int32{"lives": 4})
+	assert.ErrorIs(t, err, ErrCurrencyInsufficient)
+
+	_, _, err = nk.WalletUpdate(ctx, "user1", map[string]int64{benchCurrency: 50}, nil, false)
+	require.NoError(t, err)
+
+	// An energy without an offer can't be refilled, so the other energy's refill isn't bought either
+	_, err = energySystem.SpendWithRefill(ctx, logger, nk, "user1", map[string]int32{"lives": 4, "tickets": 2})
+	assert.ErrorIs(t, err, ErrEnergyInsufficient)
+	wallet, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), wallet[benchCurrency])
+
+	result, err := energySystem.SpendWithRefill(ctx, logger, nk, "user1", map[string]int32{"lives": 4, "tickets": 1})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), result.Energies["lives"].Current)
+	assert.Equal(t, int32(0), result.Energies["tickets"].Current)
+	require.Len(t, result.Refills, 1)
+	assert.Equal(t, "lives", result.Refills[0].EnergyId)
+
+	wallet, err = userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), wallet[benchCurrency])
+
+	assert.Error(t, validateEnergyRefillOffers(&EnergyConfig{Energies: map[string]*EnergyConfigEnergy{
+		"lives": {RefillOffer: &EnergyConfigRefillOffer{Cost: &Cost{Energies: map[string]int64{"lives": 1}}}},
+	}}))
+}
+
+// Test that spending from full starts refilling then, rather than when the energy was last refilled
+func TestEnergySystem_SpendFromMaxStartsRefill(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+
+	energySystem := NewNakamaEnergySystem(&EnergyConfig{
+		Energies: map[string]*EnergyConfigEnergy{
+			"lives": {StartCount: 5, MaxCount: 5, RefillCount: 1, RefillTimeSec: 600},
+		},
+	})
+
+	// The energy has been full since it last refilled an hour ago
+	now := time.Now().Unix()
+	energyData, err := json.Marshal(&EnergyList{Energies: map[string]*Energy{
+		"lives": {Id: "lives", Current: 5, Max: 5, Refill: 1, RefillSec: 600, StartRefillTimeSec: now - 3600},
+	}})
+	require.NoError(t, err)
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{Collection: energyStorageCollection, Key: userEnergyStorageKey, UserID: "user1", Value: string(energyData)},
+	})
+	require.NoError(t, err)
+
+	energies, _, err := energySystem.Spend(ctx, logger, nk, "user1", map[string]int32{"lives": 2})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), energies["lives"].Current)
+	assert.Equal(t, int64(600), energies["lives"].NextRefillSec)
+
+	energies, err = energySystem.Get(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), energies["lives"].Current)
+	assert.InDelta(t, 600, energies["lives"].NextRefillSec, 1)
+	assert.InDelta(t, now+1200, energies["lives"].MaxRefillTimeSec, 1)
+}
+
+// Test that granted energy beyond the max and overfill is converted into currency or discarded
+func TestEnergySystem_GrantOverflow(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+
+	p := newBenchPamlogix()
+	energySystem := NewNakamaEnergySystem(&EnergyConfig{
+		Energies: map[string]*EnergyConfigEnergy{
+			"lives":   {StartCount: 3, MaxCount: 5, OverflowCurrencies: map[string]int64{benchCurrency: 10}},
+			"tickets": {StartCount: 1, MaxCount: 5, MaxOverfill: 2},
+		},
+	})
+	energySystem.SetPamlogix(p)
+	p.systems[SystemTypeEnergy] = energySystem
+
+	// Reward grants go through the energy system
+	_, _, _, err := p.GetEconomySystem().RewardGrant(ctx, logger, nk, "user1", &Reward{
+		Energies: map[string]int32{"lives": 4, "tickets": 10},
+	}, nil, false)
+	require.NoError(t, err)
+
+	energies, err := energySystem.Get(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), energies["lives"].Current)
+	assert.Equal(t, int32(7), energies["tickets"].Current)
+
+	wallet, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), wallet[benchCurrency])
+
+	// Overflow currencies are held to their max balance like any other granted currency, also when energies are
+	// granted directly
+	p.GetEconomySystem().(*NakamaEconomySystem).config.Currencies = map[string]*EconomyConfigCurrency{
+		benchCurrency: {MaxBalance: 35},
+	}
+	_, err = energySystem.Grant(ctx, logger, nk, "user1", map[string]int32{"lives": 1}, nil)
+	require.NoError(t, err)
+	_, _, _, err = p.GetEconomySystem().RewardGrant(ctx, logger, nk, "user1", &Reward{Energies: map[string]int32{"lives": 1}}, nil, false)
+	require.NoError(t, err)
+	wallet, err = userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(35), wallet[benchCurrency])
+
+	assert.Error(t, validateEnergyOverflowCurrencies(&EnergyConfig{Energies: map[string]*EnergyConfigEnergy{
+		"lives": {OverflowCurrencies: map[string]int64{benchCurrency: 0}},
+	}}))
+}
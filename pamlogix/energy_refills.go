@@ -0,0 +1,132 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+func (e *EnergyInsufficientError) Error() string {
+	return fmt.Sprintf("insufficient energy %s: have %d, need %d", e.EnergyId, e.Current, e.Required)
+}
+
+func (e *EnergyInsufficientError) Is(target error) bool {
+	return target == ErrEnergyInsufficient
+}
+
+// energyRpcError converts an EnergyInsufficientError to the error RPC clients receive, with its details and refill
+// offer as the JSON message. Other errors are returned as they are.
+func energyRpcError(err error) error {
+	var insufficient *EnergyInsufficientError
+	if !errors.As(err, &insufficient) {
+		return err
+	}
+	data, marshalErr := json.Marshal(insufficient)
+	if marshalErr != nil {
+		return ErrEnergyInsufficient
+	}
+	return runtime.NewError(string(data), FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+}
+
+func validateEnergyRefillOffers(config *EnergyConfig) error {
+	for energyID, energyConfig := range config.Energies {
+		if energyConfig == nil || energyConfig.RefillOffer == nil {
+			continue
+		}
+		if energyConfig.RefillOffer.Amount < 0 {
+			return fmt.Errorf("refill offer of energy %s has a negative amount", energyID)
+		}
+		if err := energyConfig.RefillOffer.Cost.validate(); err != nil {
+			return fmt.Errorf("refill offer of energy %s: %w", energyID, err)
+		}
+		if cost := energyConfig.RefillOffer.Cost; cost != nil && cost.Energies[energyID] > 0 {
+			return fmt.Errorf("refill offer of energy %s costs the same energy", energyID)
+		}
+	}
+	return nil
+}
+
+// refillOffer returns the refill of the energy the user can buy right now, or nil when the energy has no offer or is
+// already full.
+func (e *NakamaEnergySystem) refillOffer(energyID string, energy *Energy) *EnergyRefillOffer {
+	energyConfig := e.config.Energies[energyID]
+	if energyConfig == nil || energyConfig.RefillOffer == nil {
+		return nil
+	}
+	amount := energyConfig.RefillOffer.Amount
+	if amount <= 0 {
+		amount = energy.Max - energy.Current
+	}
+	if amount <= 0 {
+		return nil
+	}
+	return &EnergyRefillOffer{
+		EnergyId: energyID,
+		Amount:   amount,
+		Cost:     energyConfig.RefillOffer.Cost,
+	}
+}
+
+// SpendWithRefill spends the amounts like Spend, first buying the refill offered for each energy the user doesn't
+// have enough of. Nothing is bought when an energy short of its amount has no offer, or its refill doesn't cover the
+// spend, and the refills are refunded if the spend fails.
+func (e *NakamaEnergySystem) SpendWithRefill(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, amounts map[string]int32) (*EnergySpendWithRefill, error) {
+	if e.config == nil || len(e.config.Energies) == 0 {
+		return nil, ErrSystemNotAvailable
+	}
+
+	energies, err := e.Get(ctx, logger, nk, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	refills := make(map[string]int32)
+	offers := make([]*EnergyRefillOffer, 0)
+	cost := &Cost{}
+	for id, amount := range amounts {
+		energy, exists := energies[id]
+		if !exists {
+			logger.Warn("Attempted to spend non-existent energy: %s", id)
+			return nil, ErrBadInput
+		}
+		if energy.Current >= amount {
+			continue
+		}
+		offer := e.refillOffer(id, energy)
+		if offer == nil || energy.Current+offer.Amount < amount {
+			logger.Warn("Insufficient energy to spend even with a refill: %s (have: %d, need: %d)", id, energy.Current, amount)
+			return nil, ErrEnergyInsufficient
+		}
+		refills[id] = offer.Amount
+		offers = append(offers, offer)
+		cost = cost.add(offer.Cost)
+	}
+	sort.Slice(offers, func(i, j int) bool {
+		return offers[i].EnergyId < offers[j].EnergyId
+	})
+
+	metadata := map[string]interface{}{
+		"source": "energy_refill",
+	}
+	if err := chargeCost(ctx, logger, nk, e.pamlogix, userID, cost, metadata, false); err != nil {
+		return nil, err
+	}
+
+	energies, reward, err := e.spend(ctx, logger, nk, userID, amounts, refills)
+	if err != nil {
+		if refundErr := refundCost(ctx, logger, nk, e.pamlogix, userID, cost, metadata); refundErr != nil {
+			logger.Error("Failed to refund energy refills of user %s: %v", userID, refundErr)
+		}
+		return nil, err
+	}
+
+	return &EnergySpendWithRefill{
+		Energies: energies,
+		Reward:   reward,
+		Refills:  offers,
+	}, nil
+}
@@ -7,10 +7,16 @@ const (
 	NOT_FOUND_ERROR_CODE = 5
 	// PERMISSION_DENIED_ERROR_CODE represents an error for insufficient permissions.
 	PERMISSION_DENIED_ERROR_CODE = 7
+	// RESOURCE_EXHAUSTED_ERROR_CODE represents an error for a resource, such as a connection pool, being exhausted.
+	RESOURCE_EXHAUSTED_ERROR_CODE = 8
 	// FAILED_PRECONDITION_ERROR_CODE represents an error for a failed precondition.
 	FAILED_PRECONDITION_ERROR_CODE = 9
+	// ABORTED_ERROR_CODE represents an error for an operation aborted by a concurrent update.
+	ABORTED_ERROR_CODE = 10
 	// UNIMPLEMENTED_ERROR_CODE represents an error for an unimplemented feature.
 	UNIMPLEMENTED_ERROR_CODE = 12
 	// INTERNAL_ERROR_CODE represents an internal server error.
 	INTERNAL_ERROR_CODE = 13
+	// UNAVAILABLE_ERROR_CODE represents an error for a service which is temporarily unavailable.
+	UNAVAILABLE_ERROR_CODE = 14
 )
@@ -0,0 +1,287 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// defaultCohortMergeIntervalSec is how often the cohort merge job runs while any event merges cohorts, so cohorts are
+// merged soon after they are found under-filled within the merge window.
+const defaultCohortMergeIntervalSec = 60
+
+// errCohortMergeStale is returned when a cohort picked for a merge changed before it was locked.
+var errCohortMergeStale = errors.New("cohort changed before merge")
+
+// cohortMergeIntervalSec returns how often the cohort merge job runs by default, or 0 when no event merges cohorts.
+func cohortMergeIntervalSec(config *EventLeaderboardsConfig) int64 {
+	if config == nil {
+		return 0
+	}
+	for _, eventConfig := range config.EventLeaderboards {
+		if eventConfig != nil && eventConfig.CohortMergeWindowSec > 0 {
+			return defaultCohortMergeIntervalSec
+		}
+	}
+	return 0
+}
+
+// cohortMergeMinSize returns the size below which a cohort of the event is merged.
+func cohortMergeMinSize(config *EventLeaderboardsConfigLeaderboard) int {
+	switch {
+	case config.CohortMergeMinSize > 0:
+		return config.CohortMergeMinSize
+	case config.MinCohortSize > 0:
+		return config.MinCohortSize
+	default:
+		return config.CohortSize / 2
+	}
+}
+
+// cohortMergeable reports whether the cohort is open and still within the event's merge window. The window starts
+// with the event, or with the cohort itself for events without a fixed start.
+func cohortMergeable(config *EventLeaderboardsConfigLeaderboard, cohort *EventLeaderboardCohortState, now int64) bool {
+	if cohort.CancelTimeSec > 0 || cohort.ArchiveTimeSec > 0 || cohort.MergedIntoID != "" {
+		return false
+	}
+	if cohort.EndTimeSec > 0 && now >= cohort.EndTimeSec {
+		return false
+	}
+	startTimeSec := cohort.StartTimeSec
+	if startTimeSec == 0 {
+		startTimeSec = cohort.CreateTimeSec
+	}
+	return now < startTimeSec+config.CohortMergeWindowSec
+}
+
+func (e *NakamaEventLeaderboardsSystem) MergeEventLeaderboardCohorts(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error {
+	// Cohorts picked by custom selection are grouped by the game, so they are left as they are
+	if e.config == nil || e.onEventLeaderboardCohortSelection != nil {
+		return nil
+	}
+
+	now := time.Now().Unix()
+	for eventLeaderboardID, config := range e.config.EventLeaderboards {
+		if config.CohortMergeWindowSec <= 0 || !e.isEventActive(config, now) {
+			continue
+		}
+		minSize := cohortMergeMinSize(config)
+		if minSize <= 1 {
+			continue
+		}
+
+		cohorts, err := e.getAllCohortsForEvent(ctx, logger, nk, eventLeaderboardID)
+		if err != nil {
+			logger.Error("Failed to get cohorts for event %s: %v", eventLeaderboardID, err)
+			return err
+		}
+
+		byTier := make(map[int32][]*EventLeaderboardCohortState)
+		for _, cohort := range cohorts {
+			if cohortMergeable(config, cohort, now) {
+				byTier[cohort.Tier] = append(byTier[cohort.Tier], cohort)
+			}
+		}
+
+		merged := 0
+		for _, tierCohorts := range byTier {
+			merged += e.mergeTierCohorts(ctx, logger, nk, eventLeaderboardID, tierCohorts, minSize)
+		}
+		if merged > 0 {
+			logger.Info("Merged %d under-filled cohorts of event %s", merged, eventLeaderboardID)
+		}
+	}
+
+	return nil
+}
+
+// mergeTierCohorts merges each under-filled cohort of a tier, smallest first, into the fullest cohort with room for all
+// its users and the same matchmaker properties. It returns the number of cohorts merged.
+func (e *NakamaEventLeaderboardsSystem) mergeTierCohorts(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID string, cohorts []*EventLeaderboardCohortState, minSize int) int {
+	sort.Slice(cohorts, func(i, j int) bool {
+		if len(cohorts[i].UserIDs) != len(cohorts[j].UserIDs) {
+			return len(cohorts[i].UserIDs) < len(cohorts[j].UserIDs)
+		}
+		return cohorts[i].ID < cohorts[j].ID
+	})
+
+	merged := 0
+	mergedAway := make(map[string]bool)
+	for _, source := range cohorts {
+		if mergedAway[source.ID] || len(source.UserIDs) == 0 || len(source.UserIDs) >= minSize {
+			continue
+		}
+
+		var target *EventLeaderboardCohortState
+		for i := len(cohorts) - 1; i >= 0; i-- {
+			candidate := cohorts[i]
+			if candidate.ID == source.ID || mergedAway[candidate.ID] {
+				continue
+			}
+			if len(candidate.UserIDs)+len(source.UserIDs) > candidate.MaxSize {
+				continue
+			}
+			if !reflect.DeepEqual(candidate.MatchmakerProperties, source.MatchmakerProperties) {
+				continue
+			}
+			if target == nil || len(candidate.UserIDs) > len(target.UserIDs) {
+				target = candidate
+			}
+		}
+		if target == nil {
+			continue
+		}
+
+		moved, err := e.mergeCohort(ctx, logger, nk, eventLeaderboardID, source.ID, target.ID)
+		if err != nil {
+			if !errors.Is(err, errCohortMergeStale) {
+				logger.Error("Failed to merge cohort %s into cohort %s: %v", source.ID, target.ID, err)
+			}
+			continue
+		}
+		mergedAway[source.ID] = true
+		target.UserIDs = append(target.UserIDs, moved...)
+		merged++
+	}
+	return merged
+}
+
+// mergeCohort moves the users of the source cohort and their scores to the target cohort, then marks the source
+// merged and deletes its backing leaderboard. Both cohorts are locked so no one joins either meanwhile, and every step
+// can be repeated, so a merge that fails part way is finished by the next run. It returns the users moved.
+func (e *NakamaEventLeaderboardsSystem) mergeCohort(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID, sourceID, targetID string) ([]string, error) {
+	release, err := acquireStorageLocks(ctx, nk, eventLeaderboardCohortPrefix+sourceID, eventLeaderboardCohortPrefix+targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	moved, err := e.moveCohortUsers(ctx, logger, nk, eventLeaderboardID, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userID := range moved {
+		if err := sendTemplatedNotification(ctx, logger, nk, e.pamlogix, userID, NotificationEventEventCohortMerged, map[string]string{
+			"event_leaderboard_id": eventLeaderboardID,
+		}, map[string]interface{}{
+			"event_leaderboard_id": eventLeaderboardID,
+			"cohort_id":            targetID,
+			"previous_cohort_id":   sourceID,
+		}); err != nil {
+			logger.Error("Failed to send cohort merged notification to user %s: %v", userID, err)
+		}
+	}
+
+	logger.Info("Merged cohort %s of event %s into cohort %s, moving %d users", sourceID, eventLeaderboardID, targetID, len(moved))
+	return moved, nil
+}
+
+// moveCohortUsers does the work of mergeCohort, with both cohorts locked by the caller.
+func (e *NakamaEventLeaderboardsSystem) moveCohortUsers(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID, sourceID, targetID string) ([]string, error) {
+	source, err := e.getCohortState(ctx, logger, nk, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := e.getCohortState(ctx, logger, nk, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if source.MergedIntoID != "" || source.CancelTimeSec > 0 || target.MergedIntoID != "" || target.CancelTimeSec > 0 {
+		return nil, errCohortMergeStale
+	}
+
+	// Only users still in the source cohort move, with their scores. Some may already be in the target cohort if
+	// an earlier merge failed part way.
+	targetUsers := make(map[string]bool, len(target.UserIDs))
+	for _, userID := range target.UserIDs {
+		targetUsers[userID] = true
+	}
+	var moved, joining []string
+	userStates := make(map[string]*EventLeaderboardUserState, len(source.UserIDs))
+	for _, userID := range source.UserIDs {
+		userState, err := e.getUserState(ctx, logger, nk, userID)
+		if err != nil {
+			return nil, err
+		}
+		userEventState, exists := userState.EventLeaderboards[eventLeaderboardID]
+		if !exists || userEventState.CohortID != sourceID {
+			// The user already moved on to another cohort
+			continue
+		}
+		userStates[userID] = userState
+		moved = append(moved, userID)
+		if !targetUsers[userID] {
+			joining = append(joining, userID)
+		}
+	}
+	if len(target.UserIDs)+len(joining) > target.MaxSize {
+		return nil, errCohortMergeStale
+	}
+
+	// Scores are copied first, so users never see their new cohort without them
+	sourceBackingID := e.getBackingLeaderboardID(eventLeaderboardID, sourceID)
+	targetBackingID := e.getBackingLeaderboardID(eventLeaderboardID, targetID)
+	records, err := listCohortRecords(ctx, nk, sourceBackingID)
+	if err != nil {
+		return nil, err
+	}
+	setOperator, _ := eventLeaderboardOperator("set")
+	for _, record := range records {
+		if userStates[record.OwnerId] == nil {
+			continue
+		}
+		var metadata map[string]interface{}
+		if record.Metadata != "" {
+			if err := json.Unmarshal([]byte(record.Metadata), &metadata); err != nil {
+				logger.Warn("Failed to unmarshal metadata of user %s in cohort %s: %v", record.OwnerId, sourceID, err)
+			}
+		}
+		if _, err := nk.LeaderboardRecordWrite(ctx, targetBackingID, record.OwnerId, record.GetUsername().GetValue(), record.Score, record.Subscore, metadata, setOperator); err != nil {
+			return nil, err
+		}
+	}
+
+	target.UserIDs = append(target.UserIDs, joining...)
+	if err := e.writeCohortState(ctx, nk, target); err != nil {
+		return nil, err
+	}
+	for _, userID := range moved {
+		userState := userStates[userID]
+		userState.EventLeaderboards[eventLeaderboardID].CohortID = targetID
+		if err := e.saveUserState(ctx, logger, nk, userID, userState); err != nil {
+			return nil, err
+		}
+	}
+
+	// There is nothing left to archive once the users and their scores moved
+	source.MergedIntoID = targetID
+	source.ArchiveTimeSec = time.Now().Unix()
+	if err := e.writeCohortState(ctx, nk, source); err != nil {
+		return nil, err
+	}
+	if err := nk.LeaderboardDelete(ctx, sourceBackingID); err != nil {
+		logger.Warn("Failed to delete backing leaderboard of merged cohort %s: %v", sourceID, err)
+	}
+	return moved, nil
+}
+
+func (e *NakamaEventLeaderboardsSystem) writeCohortState(ctx context.Context, nk runtime.NakamaModule, cohort *EventLeaderboardCohortState) error {
+	data, err := json.Marshal(cohort)
+	if err != nil {
+		return err
+	}
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection: eventLeaderboardsStorageCollection,
+			Key:        eventLeaderboardCohortPrefix + cohort.ID,
+			Value:      string(data),
+		},
+	})
+	return err
+}
@@ -0,0 +1,77 @@
+package pamlogix
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// validateRewardChoices checks that no reward tier offers an empty choice, one with neither guaranteed nor weighted
+// contents, which clients couldn't show.
+func (e *NakamaEventLeaderboardsSystem) validateRewardChoices() error {
+	for eventLeaderboardID, config := range e.config.EventLeaderboards {
+		for tier, rewardTiers := range config.RewardTiers {
+			for _, rewardTier := range rewardTiers {
+				for i, choice := range rewardTier.Choices {
+					if choice == nil || (choice.Guaranteed == nil && len(choice.Weighted) == 0) {
+						return fmt.Errorf("event leaderboard %s reward tier %q of tier %s has no reward for choice %d", eventLeaderboardID, rewardTier.Name, tier, i)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// eventLeaderboardRewardChoice returns the reward the user chose from the reward tier's choices, or nil when it offers
+// none. A choice is required when the tier offers any, and rejected when it doesn't.
+func eventLeaderboardRewardChoice(rewardTier *EventLeaderboardsConfigLeaderboardRewardTier, choice *int) (*EconomyConfigReward, error) {
+	var choices []*EconomyConfigReward
+	if rewardTier != nil {
+		choices = rewardTier.Choices
+	}
+	if len(choices) == 0 {
+		if choice != nil {
+			return nil, ErrEventLeaderboardRewardChoice
+		}
+		return nil, nil
+	}
+	if choice == nil || *choice < 0 || *choice >= len(choices) {
+		return nil, ErrEventLeaderboardRewardChoice
+	}
+	return choices[*choice], nil
+}
+
+// rewardChoicesPreview returns the reward tier's choices as clients see them, in the order they're chosen by.
+func (e *NakamaEventLeaderboardsSystem) rewardChoicesPreview(rewardTier *EventLeaderboardsConfigLeaderboardRewardTier) []*AvailableRewards {
+	economySystem := e.pamlogix.GetEconomySystem()
+	if economySystem == nil {
+		return nil
+	}
+	choices := make([]*AvailableRewards, 0, len(rewardTier.Choices))
+	for _, choice := range rewardTier.Choices {
+		choices = append(choices, economySystem.RewardConvertReverse(choice))
+	}
+	return choices
+}
+
+// rollClaimReward rolls each of the reward configs and merges them into the one reward the claim grants.
+func (e *NakamaEventLeaderboardsSystem) rollClaimReward(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, economySystem EconomySystem, userID string, rewardConfigs ...*EconomyConfigReward) (*Reward, error) {
+	var reward *Reward
+	for _, rewardConfig := range rewardConfigs {
+		if rewardConfig == nil {
+			continue
+		}
+		rolled, err := economySystem.RewardRoll(ctx, logger, nk, userID, rewardConfig)
+		if err != nil {
+			return nil, err
+		}
+		if reward == nil {
+			reward = rolled
+		} else {
+			mergeRewards(reward, rolled)
+		}
+	}
+	return reward, nil
+}
@@ -0,0 +1,81 @@
+package pamlogix
+
+import (
+	"context"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// eventLeaderboardHistoryMaxEntries is how many claimed events the lifetime stats keep in their history.
+const eventLeaderboardHistoryMaxEntries = 20
+
+// lifetimeStats returns the user's lifetime stats, creating them on first use.
+func (s *EventLeaderboardUserState) lifetimeStats() *EventLeaderboardLifetimeStats {
+	if s.Lifetime == nil {
+		s.Lifetime = &EventLeaderboardLifetimeStats{}
+	}
+	return s.Lifetime
+}
+
+// recordJoin counts an event the user rolled into for the first time in its current iteration.
+func (s *EventLeaderboardLifetimeStats) recordJoin(tier int32) {
+	s.EventsJoined++
+	s.HighestTier = max(s.HighestTier, tier)
+}
+
+// recordTier raises the highest tier the user reached, e.g. after a promotion at the end of an event.
+func (s *EventLeaderboardLifetimeStats) recordTier(tier int32) {
+	s.HighestTier = max(s.HighestTier, tier)
+}
+
+// recordClaim adds a claimed event's rank and reward, if it had one, to the stats and their history.
+func (s *EventLeaderboardLifetimeStats) recordClaim(eventLeaderboardID string, userEventState *EventLeaderboardUserEventState, rank int64, reward *Reward, now int64) {
+	s.EventsClaimed++
+	if rank > 0 && (s.BestRank == 0 || rank < s.BestRank) {
+		s.BestRank = rank
+	}
+	s.HighestTier = max(s.HighestTier, userEventState.Tier)
+
+	participation := &EventLeaderboardParticipation{
+		EventLeaderboardId: eventLeaderboardID,
+		CohortId:           userEventState.CohortID,
+		Tier:               userEventState.Tier,
+		Rank:               rank,
+		ClaimTimeSec:       now,
+	}
+	if reward != nil {
+		if len(reward.Currencies) > 0 {
+			if s.RewardCurrencies == nil {
+				s.RewardCurrencies = make(map[string]int64, len(reward.Currencies))
+			}
+			participation.RewardCurrencies = reward.Currencies
+			for currencyID, amount := range reward.Currencies {
+				s.RewardCurrencies[currencyID] += amount
+			}
+		}
+		if len(reward.Items) > 0 {
+			if s.RewardItems == nil {
+				s.RewardItems = make(map[string]int64, len(reward.Items))
+			}
+			participation.RewardItems = reward.Items
+			for itemID, count := range reward.Items {
+				s.RewardItems[itemID] += count
+			}
+		}
+	}
+
+	s.History = append([]*EventLeaderboardParticipation{participation}, s.History...)
+	if len(s.History) > eventLeaderboardHistoryMaxEntries {
+		s.History = s.History[:eventLeaderboardHistoryMaxEntries]
+	}
+}
+
+// GetLifetimeStats returns the user's results across every event leaderboard they've played.
+func (e *NakamaEventLeaderboardsSystem) GetLifetimeStats(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EventLeaderboardLifetimeStats, error) {
+	userState, err := e.getUserState(ctx, logger, nk, userID)
+	if err != nil {
+		logger.Error("Failed to get user state: %v", err)
+		return nil, ErrInternal
+	}
+	return userState.lifetimeStats(), nil
+}
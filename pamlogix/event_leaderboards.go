@@ -7,9 +7,14 @@ import (
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
+var ErrEventLeaderboardRewardChoice = runtime.NewError("invalid event leaderboard reward choice", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+
 // EventLeaderboardsConfig is the data definition for the EventLeaderboardsSystem type.
 type EventLeaderboardsConfig struct {
 	EventLeaderboards map[string]*EventLeaderboardsConfigLeaderboard `json:"event_leaderboards,omitempty"`
+	// ArchiveRetentionDays deletes archived cohorts and their standings snapshots this many days after they were
+	// archived, in the storage sweep, after which their users no longer see the cohort's scores. Zero keeps them forever.
+	ArchiveRetentionDays int `json:"archive_retention_days,omitempty"`
 }
 
 type EventLeaderboardsConfigLeaderboard struct {
@@ -30,15 +35,40 @@ type EventLeaderboardsConfigLeaderboard struct {
 	EndTimeSec           int64                                                      `json:"end_time_sec,omitempty"`
 	Duration             int64                                                      `json:"duration,omitempty"`
 
-	// Reroll configuration
+	// Reroll configuration. MaxRerolls caps the rerolls of each event iteration, and is reset along with the windowed
+	// limit when the reset schedule starts a new iteration.
 	MaxRerolls        int                  `json:"max_rerolls,omitempty"`
 	RerollCost        *EconomyConfigReward `json:"reroll_cost,omitempty"`
 	ParticipationCost *EconomyConfigReward `json:"participation_cost,omitempty"`
+	// RerollCooldownSec is the minimum time between two rerolls of the same user.
+	RerollCooldownSec int64 `json:"reroll_cooldown_sec,omitempty"`
+	// MaxRerollsPerWindow limits the rerolls within any RerollWindowSec long period, e.g. 1 every 21600 for one reroll
+	// per six hours.
+	MaxRerollsPerWindow int   `json:"max_rerolls_per_window,omitempty"`
+	RerollWindowSec     int64 `json:"reroll_window_sec,omitempty"`
+
+	// MinCohortSize cancels cohorts with fewer users when the event ends, refunding their participation costs
+	MinCohortSize int `json:"min_cohort_size,omitempty"`
+
+	// CohortMergeWindowSec merges under-filled cohorts of the same tier into fuller ones during this long after the
+	// event starts, so early joiners aren't left in tiny cohorts. Scores move with their users. Zero disables merging.
+	CohortMergeWindowSec int64 `json:"cohort_merge_window_sec,omitempty"`
+	// CohortMergeMinSize is the size below which a cohort is merged. It defaults to MinCohortSize, or half the cohort
+	// size when that isn't set.
+	CohortMergeMinSize int `json:"cohort_merge_min_size,omitempty"`
 
 	// Target score configuration
 	TargetScore int64 `json:"target_score,omitempty"`
 	WinnerCount int   `json:"winner_count,omitempty"`
 
+	// CleanupAfterDays deletes the backing leaderboard of each cohort this many days after the claim window closes,
+	// keeping a snapshot of its final standings. Zero keeps backing leaderboards forever.
+	CleanupAfterDays int `json:"cleanup_after_days,omitempty"`
+
+	// Global ranking configuration, aggregating best scores across all cohorts of the event
+	GlobalRanking     bool `json:"global_ranking,omitempty"`
+	GlobalRankingSize int  `json:"global_ranking_size,omitempty"`
+
 	BackingId           string `json:"-"`
 	CalculatedBackingId string `json:"-"`
 }
@@ -49,6 +79,8 @@ type EventLeaderboardsConfigLeaderboardRewardTier struct {
 	RankMin    int                  `json:"rank_min,omitempty"`
 	Reward     *EconomyConfigReward `json:"reward,omitempty"`
 	TierChange int                  `json:"tier_change,omitempty"`
+	// Choices are rewards the user picks one of when claiming, by its index, in addition to Reward.
+	Choices []*EconomyConfigReward `json:"choices,omitempty"`
 }
 
 type EventLeaderboardsConfigChangeZone struct {
@@ -70,11 +102,32 @@ type EventLeaderboardsSystem interface {
 	// RollEventLeaderboard places the user into a new cohort for the specified event leaderboard if possible.
 	RollEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, tier *int, matchmakerProperties map[string]interface{}) (eventLeaderboard *EventLeaderboard, err error)
 
+	// PreviewRollEventLeaderboard returns the cohort the user would join and the cost they would pay by rolling the
+	// event leaderboard, without charging anything or joining a cohort.
+	PreviewRollEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, tier *int) (preview *EventLeaderboardRollPreview, err error)
+
 	// UpdateEventLeaderboard updates the user's score in the specified event leaderboard, and returns the user's updated cohort information.
+	// Scores are always written with the event's configured operator.
 	UpdateEventLeaderboard(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, userID, username, eventLeaderboardID string, score, subscore int64, metadata map[string]interface{}, conditionalMetadataUpdate bool) (eventLeaderboard *EventLeaderboard, err error)
 
-	// ClaimEventLeaderboard claims the user's reward for the given event leaderboard.
-	ClaimEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string) (eventLeaderboard *EventLeaderboard, err error)
+	// GetEventLeaderboardGlobalRanking returns the best scores across all cohorts of the specified event leaderboard.
+	GetEventLeaderboardGlobalRanking(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, limit int) (ranking *EventLeaderboardGlobalRanking, err error)
+
+	// ClaimEventLeaderboard claims the user's reward for the given event leaderboard. The choice is the index of the
+	// reward chosen from the claimed reward tier's choices, and must be given exactly when the tier offers any.
+	ClaimEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, choice *int) (eventLeaderboard *EventLeaderboard, err error)
+
+	// GetLifetimeStats returns the user's results across every event leaderboard they've played, which game code can
+	// also use as matchmaking properties or to segment players.
+	GetLifetimeStats(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (stats *EventLeaderboardLifetimeStats, err error)
+
+	// CleanupEventLeaderboards deletes the backing leaderboards of events past their configured cleanup delay, keeping a
+	// snapshot of each cohort's final standings.
+	CleanupEventLeaderboards(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (err error)
+
+	// MergeEventLeaderboardCohorts merges the under-filled cohorts of events within their configured merge window into
+	// fuller cohorts of the same tier, moving their users' scores and notifying them.
+	MergeEventLeaderboardCohorts(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (err error)
 
 	// SetOnEventLeaderboardsReward sets a custom reward function which will run after an event leaderboard's reward is rolled.
 	SetOnEventLeaderboardsReward(fn OnReward[*EventLeaderboardsConfigLeaderboard])
@@ -86,9 +139,81 @@ type EventLeaderboardsSystem interface {
 	DebugFill(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, targetCount int) (eventLeaderboard *EventLeaderboard, err error)
 
 	// DebugRandomScores assigns random scores to the participants of the user's current cohort, except to the user themselves.
+	// The operator overrides the event's configured operator when given.
 	DebugRandomScores(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, scoreMin, scoreMax, subscoreMin, subscoreMax int64, operator *int) (eventLeaderboard *EventLeaderboard, err error)
 }
 
+// EventLeaderboardGlobalRanking is the aggregate ranking of an event leaderboard across all of its cohorts.
+type EventLeaderboardGlobalRanking struct {
+	Id             string                   `json:"id,omitempty"`
+	BackingId      string                   `json:"backing_id,omitempty"`
+	Scores         []*EventLeaderboardScore `json:"scores,omitempty"`
+	OwnerScore     *EventLeaderboardScore   `json:"owner_score,omitempty"`
+	CurrentTimeSec int64                    `json:"current_time_sec,omitempty"`
+}
+
+// EventLeaderboardGlobalRankingRequest is the request payload to get an event leaderboard's global ranking.
+type EventLeaderboardGlobalRankingRequest struct {
+	Id    string `json:"id,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// EventLeaderboardRollPreview is what rolling an event leaderboard would do for the user, for clients to confirm before
+// the cost is charged.
+type EventLeaderboardRollPreview struct {
+	Id string `json:"id"`
+	// IsReroll is whether the user already has a cohort, in which case the reroll cost applies.
+	IsReroll bool `json:"is_reroll"`
+	// CanRoll is false when the user has used up their rerolls or is waiting out the reroll cooldown.
+	CanRoll           bool  `json:"can_roll"`
+	NextRerollTimeSec int64 `json:"next_reroll_time_sec,omitempty"`
+	Tier              int32 `json:"tier"`
+	// CohortId is the cohort the user would join, empty when a new cohort would be created for them or when cohorts are
+	// selected by a custom function.
+	CohortId      string `json:"cohort_id,omitempty"`
+	CohortSize    int    `json:"cohort_size"`
+	MaxCohortSize int    `json:"max_cohort_size"`
+	// TimeRemainingSec is how long the event runs for after the roll, zero when it has no end.
+	TimeRemainingSec int64 `json:"time_remaining_sec,omitempty"`
+	EndTimeSec       int64 `json:"end_time_sec,omitempty"`
+	// Cost is what the roll charges, and MissingCost the part of it the user can't afford.
+	Cost           *Cost `json:"cost,omitempty"`
+	MissingCost    *Cost `json:"missing_cost,omitempty"`
+	CurrentTimeSec int64 `json:"current_time_sec"`
+}
+
+// EventLeaderboardRollPreviewRequest is the request payload to preview rolling an event leaderboard.
+type EventLeaderboardRollPreviewRequest struct {
+	Id   string `json:"id,omitempty"`
+	Tier *int   `json:"tier,omitempty"`
+}
+
+// EventLeaderboardLifetimeStats are a user's results across every event leaderboard they've played.
+type EventLeaderboardLifetimeStats struct {
+	// EventsJoined counts the events the user rolled into, and EventsClaimed the ones they claimed once they ended.
+	EventsJoined  int64 `json:"events_joined"`
+	EventsClaimed int64 `json:"events_claimed"`
+	// BestRank is the best rank the user claimed an event with, zero before their first claim.
+	BestRank    int64 `json:"best_rank,omitempty"`
+	HighestTier int32 `json:"highest_tier"`
+	// RewardCurrencies and RewardItems total what the user was granted for their ranks.
+	RewardCurrencies map[string]int64 `json:"reward_currencies,omitempty"`
+	RewardItems      map[string]int64 `json:"reward_items,omitempty"`
+	// History is the user's most recently claimed events, newest first.
+	History []*EventLeaderboardParticipation `json:"history,omitempty"`
+}
+
+// EventLeaderboardParticipation is the result of one event leaderboard the user claimed.
+type EventLeaderboardParticipation struct {
+	EventLeaderboardId string           `json:"event_leaderboard_id"`
+	CohortId           string           `json:"cohort_id,omitempty"`
+	Tier               int32            `json:"tier"`
+	Rank               int64            `json:"rank"`
+	RewardCurrencies   map[string]int64 `json:"reward_currencies,omitempty"`
+	RewardItems        map[string]int64 `json:"reward_items,omitempty"`
+	ClaimTimeSec       int64            `json:"claim_time_sec"`
+}
+
 type EventLeaderboardCohortConfig struct {
 	// Force a new cohort even if cohort selection did not find an appropriate one.
 	ForceNewCohort bool `json:"force_new_cohort,omitempty"`
@@ -8,9 +8,10 @@ import (
 	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
@@ -18,7 +19,15 @@ const (
 	eventLeaderboardsStorageCollection = "event_leaderboards"
 	eventLeaderboardUserStateKey       = "user_state"
 	eventLeaderboardCohortPrefix       = "cohort_"
+	eventLeaderboardStandingsPrefix    = "standings_"
 	eventLeaderboardBackingPrefix      = "backing_"
+	eventLeaderboardGlobalSuffix       = "global"
+
+	defaultEventLeaderboardGlobalRankingSize = 100
+
+	// eventLeaderboardClaimedEvent is the publisher event sent when a user claims an event leaderboard, with their rank
+	// as its value.
+	eventLeaderboardClaimedEvent = "event_leaderboard_claimed"
 )
 
 // NakamaEventLeaderboardsSystem implements the EventLeaderboardsSystem interface using Nakama as the backend.
@@ -27,8 +36,15 @@ type NakamaEventLeaderboardsSystem struct {
 	onEventLeaderboardsReward         OnReward[*EventLeaderboardsConfigLeaderboard]
 	onEventLeaderboardCohortSelection OnEventLeaderboardCohortSelection
 	pamlogix                          Pamlogix
+
+	// idGenerator makes cohort IDs, defaulting to random UUIDs.
+	idGenerator IDGeneratorFn
+	// globalRankings holds the IDs of the global ranking leaderboards created by this node, so each is created once.
+	globalRankings sync.Map
 }
 
+var _ EventLeaderboardsSystem = (*NakamaEventLeaderboardsSystem)(nil)
+
 // NewNakamaEventLeaderboardsSystem creates a new instance of the event leaderboards system with the given configuration.
 func NewNakamaEventLeaderboardsSystem(config *EventLeaderboardsConfig) *NakamaEventLeaderboardsSystem {
 	return &NakamaEventLeaderboardsSystem{
@@ -41,6 +57,19 @@ func (e *NakamaEventLeaderboardsSystem) SetPamlogix(pl Pamlogix) {
 	e.pamlogix = pl
 }
 
+// SetIDGenerator replaces how cohort IDs are made, so tests can make them deterministic. A nil generator restores
+// random UUIDs.
+func (e *NakamaEventLeaderboardsSystem) SetIDGenerator(fn IDGeneratorFn) {
+	e.idGenerator = fn
+}
+
+func (e *NakamaEventLeaderboardsSystem) newID() string {
+	if e.idGenerator != nil {
+		return e.idGenerator()
+	}
+	return newUUID()
+}
+
 // GetType returns the system type for the event leaderboards system.
 func (e *NakamaEventLeaderboardsSystem) GetType() SystemType {
 	return SystemTypeEventLeaderboards
@@ -54,6 +83,8 @@ func (e *NakamaEventLeaderboardsSystem) GetConfig() any {
 // EventLeaderboardUserState represents the user's state for event leaderboards
 type EventLeaderboardUserState struct {
 	EventLeaderboards map[string]*EventLeaderboardUserEventState `json:"event_leaderboards,omitempty"`
+	// Lifetime is kept with the events' state so it's saved together with the roll or claim that changes it.
+	Lifetime *EventLeaderboardLifetimeStats `json:"lifetime,omitempty"`
 }
 
 // EventLeaderboardUserEventState represents the user's state for a specific event leaderboard
@@ -64,9 +95,11 @@ type EventLeaderboardUserEventState struct {
 	LastResetTimeSec int64                  `json:"last_reset_time_sec,omitempty"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
 
-	// Reroll tracking
+	// Reroll tracking, for the event iteration starting at LastResetTimeSec
 	RerollCount    int32 `json:"reroll_count,omitempty"`
 	LastRerollTime int64 `json:"last_reroll_time,omitempty"`
+	// RerollTimesSec are the times of the user's rerolls within the configured reroll window
+	RerollTimesSec []int64 `json:"reroll_times_sec,omitempty"`
 
 	// Target score tracking
 	HasReachedTarget bool  `json:"has_reached_target,omitempty"`
@@ -74,6 +107,9 @@ type EventLeaderboardUserEventState struct {
 
 	// Participation tracking
 	TotalParticipation int32 `json:"total_participation,omitempty"`
+
+	// ParticipationCostPaid is what the user paid to join the event, refunded if their cohort is cancelled
+	ParticipationCostPaid *Cost `json:"participation_cost_paid,omitempty"`
 }
 
 // EventLeaderboardCohortState represents the state of a cohort
@@ -87,6 +123,20 @@ type EventLeaderboardCohortState struct {
 	UserIDs              []string               `json:"user_ids,omitempty"`
 	MatchmakerProperties map[string]interface{} `json:"matchmaker_properties,omitempty"`
 	MaxSize              int                    `json:"max_size,omitempty"`
+	CancelTimeSec        int64                  `json:"cancel_time_sec,omitempty"`
+	// ArchiveTimeSec is when the cohort's standings were snapshotted and its backing leaderboard deleted.
+	ArchiveTimeSec int64 `json:"archive_time_sec,omitempty"`
+	// MergedIntoID is the cohort this one's users were moved to when it was merged for being under-filled.
+	MergedIntoID string `json:"merged_into_id,omitempty"`
+}
+
+// EventLeaderboardCohortStandings is the final standings of a cohort, kept once its backing leaderboard is deleted.
+type EventLeaderboardCohortStandings struct {
+	CohortID           string                   `json:"cohort_id,omitempty"`
+	EventLeaderboardID string                   `json:"event_leaderboard_id,omitempty"`
+	Tier               int32                    `json:"tier,omitempty"`
+	Scores             []*EventLeaderboardScore `json:"scores,omitempty"`
+	SnapshotTimeSec    int64                    `json:"snapshot_time_sec,omitempty"`
 }
 
 // ListEventLeaderboard returns available event leaderboards for the user.
@@ -140,7 +190,7 @@ func (e *NakamaEventLeaderboardsSystem) ListEventLeaderboard(ctx context.Context
 }
 
 // GetEventLeaderboard returns a specified event leaderboard's cohort for the user.
-func (e *NakamaEventLeaderboardsSystem) GetEventLeaderboard(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, userID, eventLeaderboardID string) (*EventLeaderboard, error) {
+func (e *NakamaEventLeaderboardsSystem) GetEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string) (*EventLeaderboard, error) {
 	config, exists := e.config.EventLeaderboards[eventLeaderboardID]
 	if !exists {
 		return nil, ErrBadInput
@@ -165,7 +215,7 @@ func (e *NakamaEventLeaderboardsSystem) GetEventLeaderboard(ctx context.Context,
 }
 
 // RollEventLeaderboard places the user into a new cohort for the specified event leaderboard if possible.
-func (e *NakamaEventLeaderboardsSystem) RollEventLeaderboard(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, userID, eventLeaderboardID string, tier *int, matchmakerProperties map[string]interface{}) (*EventLeaderboard, error) {
+func (e *NakamaEventLeaderboardsSystem) RollEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, tier *int, matchmakerProperties map[string]interface{}) (*EventLeaderboard, error) {
 	config, exists := e.config.EventLeaderboards[eventLeaderboardID]
 	if !exists {
 		return nil, ErrBadInput
@@ -194,69 +244,30 @@ func (e *NakamaEventLeaderboardsSystem) RollEventLeaderboard(ctx context.Context
 	}
 	userEventState := userState.EventLeaderboards[eventLeaderboardID]
 
-	// Check reroll limits
-	if config.MaxRerolls > 0 && userEventState.RerollCount >= int32(config.MaxRerolls) {
-		return nil, ErrBadInput
-	}
-
 	// Check if user already has an active cohort and this is a reroll
 	isReroll := userEventState.CohortID != ""
 
-	// Handle reroll cost
-	if isReroll && config.RerollCost != nil {
-		// Check affordability
-		canAfford, err := e.checkUserCanAffordReward(ctx, logger, nk, userID, config.RerollCost)
-		if err != nil {
-			logger.Error("Failed to check reroll cost affordability: %v", err)
-			return nil, ErrInternal
-		}
-		if !canAfford {
-			return nil, ErrBadInput
-		}
-
-		// Deduct cost
-		err = e.deductRewardCost(ctx, logger, nk, userID, config.RerollCost)
-		if err != nil {
-			logger.Error("Failed to deduct reroll cost: %v", err)
-			return nil, ErrInternal
-		}
+	// Check reroll limits
+	e.resetEventIterationRerolls(nk, config, userEventState, now)
+	if config.MaxRerolls > 0 && userEventState.RerollCount >= int32(config.MaxRerolls) {
+		return nil, ErrBadInput
 	}
-
-	// Handle participation cost for first time joining
-	if !isReroll && config.ParticipationCost != nil {
-		// Check affordability
-		canAfford, err := e.checkUserCanAffordReward(ctx, logger, nk, userID, config.ParticipationCost)
-		if err != nil {
-			logger.Error("Failed to check participation cost affordability: %v", err)
-			return nil, ErrInternal
-		}
-		if !canAfford {
-			return nil, ErrBadInput
-		}
-
-		// Deduct cost
-		err = e.deductRewardCost(ctx, logger, nk, userID, config.ParticipationCost)
-		if err != nil {
-			logger.Error("Failed to deduct participation cost: %v", err)
-			return nil, ErrInternal
-		}
+	if nextRerollTimeSec := eventLeaderboardNextRerollTimeSec(config, userEventState, now); isReroll && nextRerollTimeSec > 0 {
+		logger.Debug("User %s cannot reroll event leaderboard %s until %d", userID, eventLeaderboardID, nextRerollTimeSec)
+		return nil, ErrBadInput
 	}
 
-	// Determine user tier
-	userTier := int32(0)
-	if tier != nil {
-		userTier = int32(*tier)
-	} else if userEventState.Tier > 0 {
-		userTier = userEventState.Tier
+	// Charge the reroll cost, or the participation cost when joining for the first time
+	cost, costReason := eventLeaderboardRollCost(config, isReroll)
+	if err := chargeCost(ctx, logger, nk, e.pamlogix, userID, cost, map[string]interface{}{
+		"source":               "event_leaderboard_cost",
+		"reason":               costReason,
+		"event_leaderboard_id": eventLeaderboardID,
+	}, false); err != nil {
+		return nil, err
 	}
 
-	// Ensure tier is within bounds
-	if userTier < 0 {
-		userTier = 0
-	}
-	if config.Tiers > 0 && userTier >= int32(config.Tiers) {
-		userTier = int32(config.Tiers - 1)
-	}
+	userTier := eventLeaderboardRollTier(config, userEventState, tier)
 
 	// Find or create a cohort
 	cohortID, err := e.findOrCreateCohort(ctx, logger, nk, eventLeaderboardID, config, userID, userTier, matchmakerProperties)
@@ -276,9 +287,17 @@ func (e *NakamaEventLeaderboardsSystem) RollEventLeaderboard(ctx context.Context
 	if isReroll {
 		userEventState.RerollCount++
 		userEventState.LastRerollTime = now
+		if config.MaxRerollsPerWindow > 0 && config.RerollWindowSec > 0 {
+			userEventState.RerollTimesSec = append(eventLeaderboardWindowRerolls(config, userEventState, now), now)
+		}
 	} else {
 		// First time joining this event, increment participation
 		userEventState.TotalParticipation++
+		userState.lifetimeStats().recordJoin(userTier)
+		userEventState.ParticipationCostPaid = nil
+		if !cost.isEmpty() {
+			userEventState.ParticipationCostPaid = cost
+		}
 	}
 
 	// Save user state
@@ -291,6 +310,106 @@ func (e *NakamaEventLeaderboardsSystem) RollEventLeaderboard(ctx context.Context
 	return e.buildEventLeaderboard(ctx, logger, nk, userID, eventLeaderboardID, config, userState, true, now)
 }
 
+// PreviewRollEventLeaderboard works out the cohort and cost of rolling the event leaderboard the way
+// RollEventLeaderboard does, without writing anything.
+func (e *NakamaEventLeaderboardsSystem) PreviewRollEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, tier *int) (*EventLeaderboardRollPreview, error) {
+	config, exists := e.config.EventLeaderboards[eventLeaderboardID]
+	if !exists {
+		return nil, ErrBadInput
+	}
+
+	now := time.Now().Unix()
+	if !e.isEventActive(config, now) {
+		return nil, ErrBadInput
+	}
+
+	userState, err := e.getUserState(ctx, logger, nk, userID)
+	if err != nil {
+		logger.Error("Failed to get user state: %v", err)
+		return nil, ErrInternal
+	}
+	userEventState := userState.EventLeaderboards[eventLeaderboardID]
+	if userEventState == nil {
+		userEventState = &EventLeaderboardUserEventState{}
+	}
+	isReroll := userEventState.CohortID != ""
+
+	preview := &EventLeaderboardRollPreview{
+		Id:             eventLeaderboardID,
+		IsReroll:       isReroll,
+		CanRoll:        true,
+		Tier:           eventLeaderboardRollTier(config, userEventState, tier),
+		MaxCohortSize:  config.CohortSize,
+		EndTimeSec:     config.EndTimeSec,
+		CurrentTimeSec: now,
+	}
+	if config.EndTimeSec > 0 {
+		preview.TimeRemainingSec = config.EndTimeSec - now
+	}
+
+	e.resetEventIterationRerolls(nk, config, userEventState, now)
+	if config.MaxRerolls > 0 && userEventState.RerollCount >= int32(config.MaxRerolls) {
+		preview.CanRoll = false
+	}
+	if isReroll {
+		preview.NextRerollTimeSec = eventLeaderboardNextRerollTimeSec(config, userEventState, now)
+		if preview.NextRerollTimeSec > 0 {
+			preview.CanRoll = false
+		}
+	}
+
+	cost, _ := eventLeaderboardRollCost(config, isReroll)
+	if !cost.isEmpty() {
+		preview.Cost = cost
+		if preview.MissingCost, err = checkCost(ctx, logger, nk, e.pamlogix, userID, cost); err != nil {
+			return nil, err
+		}
+	}
+
+	// A custom selection function may pick any cohort, so only the default selection can be previewed
+	if e.onEventLeaderboardCohortSelection == nil {
+		if cohortID := e.findAvailableCohort(ctx, logger, nk, eventLeaderboardID, preview.Tier, config.CohortSize, userID); cohortID != "" {
+			cohortState, err := e.getCohortState(ctx, logger, nk, cohortID)
+			if err != nil {
+				logger.Error("Failed to get cohort %s state: %v", cohortID, err)
+				return nil, ErrInternal
+			}
+			preview.CohortId = cohortID
+			preview.CohortSize = len(cohortState.UserIDs)
+		}
+	}
+
+	return preview, nil
+}
+
+// eventLeaderboardRollCost returns the reroll cost for users who already have a cohort, or the participation cost when
+// joining for the first time, along with the reason recorded when it's charged.
+func eventLeaderboardRollCost(config *EventLeaderboardsConfigLeaderboard, isReroll bool) (*Cost, string) {
+	if isReroll {
+		return costFromReward(config.RerollCost), "reroll_cost"
+	}
+	return costFromReward(config.ParticipationCost), "participation_cost"
+}
+
+// eventLeaderboardRollTier returns the tier a roll places the user in: the requested tier, or else the user's current
+// tier, within the configured tiers.
+func eventLeaderboardRollTier(config *EventLeaderboardsConfigLeaderboard, userEventState *EventLeaderboardUserEventState, tier *int) int32 {
+	userTier := int32(0)
+	if tier != nil {
+		userTier = int32(*tier)
+	} else if userEventState.Tier > 0 {
+		userTier = userEventState.Tier
+	}
+
+	if userTier < 0 {
+		userTier = 0
+	}
+	if config.Tiers > 0 && userTier >= int32(config.Tiers) {
+		userTier = int32(config.Tiers - 1)
+	}
+	return userTier
+}
+
 // UpdateEventLeaderboard updates the user's score in the specified event leaderboard, and returns the user's updated cohort information.
 func (e *NakamaEventLeaderboardsSystem) UpdateEventLeaderboard(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, userID, username, eventLeaderboardID string, score, subscore int64, metadata map[string]interface{}, conditionalMetadataUpdate bool) (*EventLeaderboard, error) {
 	//TODO: check to create table for event leaderboards if needed
@@ -401,13 +520,23 @@ func (e *NakamaEventLeaderboardsSystem) UpdateEventLeaderboard(ctx context.Conte
 		// If no existing record, use the provided metadata as is
 	}
 
-	// Submit score to backing leaderboard
-	_, err = nk.LeaderboardRecordWrite(ctx, backingID, userID, username, score, subscore, finalMetadata, nil)
+	// Submit score to backing leaderboard, with the configured operator even if the cohort's leaderboard predates it
+	operator, _ := eventLeaderboardOperator(config.Operator)
+	record, err := nk.LeaderboardRecordWrite(ctx, backingID, userID, username, score, subscore, finalMetadata, operator)
 	if err != nil {
 		logger.Error("Failed to write leaderboard record: %v", err)
 		return nil, ErrInternal
 	}
 
+	// Mirror the resulting cohort score into the global ranking, which keeps each user's best score
+	if config.GlobalRanking && record != nil {
+		globalID := e.ensureGlobalLeaderboard(ctx, logger, nk, eventLeaderboardID, config)
+		bestOperator, _ := eventLeaderboardOperator("best")
+		if _, err := nk.LeaderboardRecordWrite(ctx, globalID, userID, username, record.Score, record.Subscore, finalMetadata, bestOperator); err != nil {
+			logger.Error("Failed to write global leaderboard record for event %s: %v", eventLeaderboardID, err)
+		}
+	}
+
 	// Check for target score achievement
 	if config.TargetScore > 0 && !userEventState.HasReachedTarget {
 		if score >= config.TargetScore {
@@ -444,8 +573,46 @@ func (e *NakamaEventLeaderboardsSystem) UpdateEventLeaderboard(ctx context.Conte
 	return e.buildEventLeaderboard(ctx, logger, nk, userID, eventLeaderboardID, config, userState, true, now)
 }
 
+// GetEventLeaderboardGlobalRanking returns the best scores across all cohorts of the specified event leaderboard.
+func (e *NakamaEventLeaderboardsSystem) GetEventLeaderboardGlobalRanking(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, limit int) (*EventLeaderboardGlobalRanking, error) {
+	config, exists := e.config.EventLeaderboards[eventLeaderboardID]
+	if !exists || !config.GlobalRanking {
+		return nil, ErrBadInput
+	}
+
+	maxSize := config.GlobalRankingSize
+	if maxSize <= 0 {
+		maxSize = defaultEventLeaderboardGlobalRankingSize
+	}
+	if limit <= 0 || limit > maxSize {
+		limit = maxSize
+	}
+
+	globalID := e.ensureGlobalLeaderboard(ctx, logger, nk, eventLeaderboardID, config)
+	records, ownerRecords, _, _, err := nk.LeaderboardRecordsList(ctx, globalID, []string{userID}, limit, "", 0)
+	if err != nil {
+		logger.Error("Failed to get global leaderboard records for event %s: %v", eventLeaderboardID, err)
+		return nil, ErrInternal
+	}
+
+	ranking := &EventLeaderboardGlobalRanking{
+		Id:             eventLeaderboardID,
+		BackingId:      globalID,
+		Scores:         make([]*EventLeaderboardScore, 0, len(records)),
+		CurrentTimeSec: time.Now().Unix(),
+	}
+	for _, record := range records {
+		ranking.Scores = append(ranking.Scores, eventLeaderboardScoreFromRecord(record))
+	}
+	if len(ownerRecords) > 0 {
+		ranking.OwnerScore = eventLeaderboardScoreFromRecord(ownerRecords[0])
+	}
+
+	return ranking, nil
+}
+
 // ClaimEventLeaderboard claims the user's reward for the given event leaderboard.
-func (e *NakamaEventLeaderboardsSystem) ClaimEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string) (*EventLeaderboard, error) {
+func (e *NakamaEventLeaderboardsSystem) ClaimEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, choice *int) (*EventLeaderboard, error) {
 	config, exists := e.config.EventLeaderboards[eventLeaderboardID]
 	if !exists {
 		return nil, ErrBadInput
@@ -493,22 +660,28 @@ func (e *NakamaEventLeaderboardsSystem) ClaimEventLeaderboard(ctx context.Contex
 
 	// Find applicable reward tier
 	rewardTier := e.findRewardTier(config, userEventState.Tier, int32(userRank))
+	choiceReward, err := eventLeaderboardRewardChoice(rewardTier, choice)
+	if err != nil {
+		return nil, err
+	}
 	if rewardTier == nil {
 		// No reward for this rank
 		userEventState.ClaimTimeSec = now
+		userState.lifetimeStats().recordClaim(eventLeaderboardID, userEventState, userRank, nil, now)
 		if err := e.saveUserState(ctx, logger, nk, userID, userState); err != nil {
 			logger.Error("Failed to save user state: %v", err)
 			return nil, ErrInternal
 		}
+		e.publishClaim(ctx, logger, nk, userID, eventLeaderboardID, config, userRank, now)
 		return e.buildEventLeaderboard(ctx, logger, nk, userID, eventLeaderboardID, config, userState, true, now)
 	}
 
-	// Process reward
+	// Process reward, along with the reward chosen from the tier's choices
 	var reward *Reward
-	if rewardTier.Reward != nil {
+	if rewardTier.Reward != nil || choiceReward != nil {
 		economySystem := e.pamlogix.GetEconomySystem()
 		if economySystem != nil {
-			reward, err = economySystem.RewardRoll(ctx, logger, nk, userID, rewardTier.Reward)
+			reward, err = e.rollClaimReward(ctx, logger, nk, economySystem, userID, rewardTier.Reward, choiceReward)
 			if err != nil {
 				logger.Error("Failed to roll reward: %v", err)
 				return nil, ErrInternal
@@ -548,17 +721,35 @@ func (e *NakamaEventLeaderboardsSystem) ClaimEventLeaderboard(ctx context.Contex
 
 	// Mark as claimed
 	userEventState.ClaimTimeSec = now
+	userState.lifetimeStats().recordClaim(eventLeaderboardID, userEventState, userRank, reward, now)
 
 	// Save user state
 	if err := e.saveUserState(ctx, logger, nk, userID, userState); err != nil {
 		logger.Error("Failed to save user state: %v", err)
 		return nil, ErrInternal
 	}
+	e.publishClaim(ctx, logger, nk, userID, eventLeaderboardID, config, userRank, now)
 
 	// Return the updated event leaderboard
 	return e.buildEventLeaderboard(ctx, logger, nk, userID, eventLeaderboardID, config, userState, true, now)
 }
 
+// publishClaim sends the publisher event for a saved claim, whether or not the user's rank earned a reward.
+func (e *NakamaEventLeaderboardsSystem) publishClaim(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, config *EventLeaderboardsConfigLeaderboard, rank int64, now int64) {
+	if e.pamlogix == nil {
+		return
+	}
+	e.pamlogix.SendPublisherEvents(ctx, logger, nk, userID, []*PublisherEvent{{
+		Name:      eventLeaderboardClaimedEvent,
+		Id:        eventLeaderboardID,
+		Timestamp: now,
+		Value:     strconv.FormatInt(rank, 10),
+		System:    e,
+		SourceId:  eventLeaderboardID,
+		Source:    config,
+	}})
+}
+
 // SetOnEventLeaderboardsReward sets a custom reward function which will run after an event leaderboard's reward is rolled.
 func (e *NakamaEventLeaderboardsSystem) SetOnEventLeaderboardsReward(fn OnReward[*EventLeaderboardsConfigLeaderboard]) {
 	e.onEventLeaderboardsReward = fn
@@ -606,11 +797,12 @@ func (e *NakamaEventLeaderboardsSystem) DebugFill(ctx context.Context, logger ru
 	}
 
 	// Add dummy users
+	operator, _ := eventLeaderboardOperator(config.Operator)
 	for i := currentCount; i < targetCount; i++ {
 		dummyUserID := fmt.Sprintf("dummy_%s_%d", userEventState.CohortID, i)
 		dummyUsername := fmt.Sprintf("Bot%d", i+1)
 
-		_, err = nk.LeaderboardRecordWrite(ctx, backingID, dummyUserID, dummyUsername, 0, 0, map[string]interface{}{}, nil)
+		_, err = nk.LeaderboardRecordWrite(ctx, backingID, dummyUserID, dummyUsername, 0, 0, map[string]interface{}{}, operator)
 		if err != nil {
 			logger.Error("Failed to write dummy leaderboard record: %v", err)
 			continue
@@ -628,6 +820,13 @@ func (e *NakamaEventLeaderboardsSystem) DebugRandomScores(ctx context.Context, l
 		return nil, ErrBadInput
 	}
 
+	// Debug scores may override the operator, otherwise they are written like real ones
+	if operator == nil || *operator == int(api.Operator_NO_OVERRIDE) {
+		operator, _ = eventLeaderboardOperator(config.Operator)
+	} else if _, valid := api.Operator_name[int32(*operator)]; !valid {
+		return nil, ErrBadInput
+	}
+
 	// Get user state
 	userState, err := e.getUserState(ctx, logger, nk, userID)
 	if err != nil {
@@ -667,7 +866,7 @@ func (e *NakamaEventLeaderboardsSystem) DebugRandomScores(ctx context.Context, l
 			username = record.Username.Value
 		}
 
-		_, err = nk.LeaderboardRecordWrite(ctx, backingID, record.OwnerId, username, score, subscore, map[string]interface{}{}, nil)
+		_, err = nk.LeaderboardRecordWrite(ctx, backingID, record.OwnerId, username, score, subscore, map[string]interface{}{}, operator)
 		if err != nil {
 			logger.Error("Failed to write random leaderboard record: %v", err)
 			continue
@@ -699,8 +898,17 @@ func (e *NakamaEventLeaderboardsSystem) ProcessEventEnd(ctx context.Context, log
 		return err
 	}
 
-	// Process each cohort
+	// Process each cohort, cancelling the ones which never filled up to the minimum size
 	for _, cohort := range cohorts {
+		if cohort.CancelTimeSec > 0 || cohort.MergedIntoID != "" {
+			continue
+		}
+		if config.MinCohortSize > 0 && len(cohort.UserIDs) < config.MinCohortSize {
+			if err := e.cancelCohort(ctx, logger, nk, eventLeaderboardID, cohort.ID); err != nil {
+				logger.Error("Failed to cancel undersized cohort %s: %v", cohort.ID, err)
+			}
+			continue
+		}
 		err := e.processCohortTierChanges(ctx, logger, nk, eventLeaderboardID, config, cohort)
 		if err != nil {
 			logger.Error("Failed to process tier changes for cohort %s: %v", cohort.ID, err)
@@ -711,31 +919,282 @@ func (e *NakamaEventLeaderboardsSystem) ProcessEventEnd(ctx context.Context, log
 	return nil
 }
 
-// Helper function to get all cohorts for an event
-func (e *NakamaEventLeaderboardsSystem) getAllCohortsForEvent(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID string) ([]*EventLeaderboardCohortState, error) {
-	// List all cohort storage objects for this event
-	objects, _, err := nk.StorageList(ctx, "", "", eventLeaderboardsStorageCollection, 100, "")
+// CancelEventLeaderboard cancels every cohort of an event, refunding the participation cost each user paid and
+// notifying them. Cohorts already cancelled are skipped, so it is safe to call again after a partial failure. The event
+// should also be ended or removed from the config so no one joins it afterwards.
+func (e *NakamaEventLeaderboardsSystem) CancelEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID string) error {
+	if _, exists := e.config.EventLeaderboards[eventLeaderboardID]; !exists {
+		return ErrBadInput
+	}
+
+	cohorts, err := e.getAllCohortsForEvent(ctx, logger, nk, eventLeaderboardID)
 	if err != nil {
-		return nil, err
+		logger.Error("Failed to get cohorts for event %s: %v", eventLeaderboardID, err)
+		return err
 	}
 
-	var cohorts []*EventLeaderboardCohortState
-	for _, obj := range objects {
-		if strings.HasPrefix(obj.Key, eventLeaderboardCohortPrefix) {
-			var cohort EventLeaderboardCohortState
-			if err := json.Unmarshal([]byte(obj.Value), &cohort); err != nil {
-				logger.Error("Failed to unmarshal cohort state: %v", err)
+	for _, cohort := range cohorts {
+		if cohort.CancelTimeSec > 0 || cohort.MergedIntoID != "" {
+			continue
+		}
+		if err := e.cancelCohort(ctx, logger, nk, eventLeaderboardID, cohort.ID); err != nil {
+			logger.Error("Failed to cancel cohort %s: %v", cohort.ID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cancelCohort marks the cohort cancelled, then takes each member out of it and refunds their participation cost. The
+// cohort is marked first under its lock, so concurrent cancellations refund each user once.
+func (e *NakamaEventLeaderboardsSystem) cancelCohort(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID, cohortID string) error {
+	var userIDs []string
+	err := withStorageLock(ctx, nk, eventLeaderboardCohortPrefix+cohortID, func() error {
+		cohortState, err := e.getCohortState(ctx, logger, nk, cohortID)
+		if err != nil {
+			return err
+		}
+		if cohortState.CancelTimeSec > 0 {
+			return nil
+		}
+		cohortState.CancelTimeSec = time.Now().Unix()
+
+		data, err := json.Marshal(cohortState)
+		if err != nil {
+			return err
+		}
+		if _, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection: eventLeaderboardsStorageCollection,
+				Key:        eventLeaderboardCohortPrefix + cohortID,
+				Value:      string(data),
+			},
+		}); err != nil {
+			return err
+		}
+
+		userIDs = cohortState.UserIDs
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		userState, err := e.getUserState(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Failed to get user state for cohort cancellation: %v", err)
+			continue
+		}
+		userEventState, exists := userState.EventLeaderboards[eventLeaderboardID]
+		if !exists || userEventState.CohortID != cohortID {
+			// The user already moved on to another cohort
+			continue
+		}
+
+		// Clear the paid cost before refunding it so a retry can't refund it twice
+		refund := userEventState.ParticipationCostPaid
+		userEventState.CohortID = ""
+		userEventState.ParticipationCostPaid = nil
+		if err := e.saveUserState(ctx, logger, nk, userID, userState); err != nil {
+			logger.Error("Failed to save user state during cohort cancellation: %v", err)
+			continue
+		}
+		if err := refundCost(ctx, logger, nk, e.pamlogix, userID, refund, map[string]interface{}{
+			"source":               "event_leaderboard_cost",
+			"reason":               "cohort_cancelled",
+			"event_leaderboard_id": eventLeaderboardID,
+		}); err != nil {
+			logger.Error("Failed to refund participation cost to user %s: %v", userID, err)
+		}
+
+		if err := sendTemplatedNotification(ctx, logger, nk, e.pamlogix, userID, NotificationEventEventCancelled, map[string]string{
+			"event_leaderboard_id": eventLeaderboardID,
+		}, map[string]interface{}{
+			"event_leaderboard_id": eventLeaderboardID,
+			"cohort_id":            cohortID,
+			"refund":               refund,
+		}); err != nil {
+			logger.Error("Failed to send event cancelled notification to user %s: %v", userID, err)
+		}
+	}
+
+	logger.Info("Cancelled cohort %s of event %s with %d users", cohortID, eventLeaderboardID, len(userIDs))
+	return nil
+}
+
+// CleanupEventLeaderboards archives the cohorts of every event whose claim window closed more than its configured
+// cleanup delay ago. Each cohort's final standings are snapshotted before its backing leaderboard is deleted, and
+// archived cohorts are skipped, so it is safe to call periodically and again after a partial failure.
+func (e *NakamaEventLeaderboardsSystem) CleanupEventLeaderboards(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error {
+	now := time.Now().Unix()
+	for eventLeaderboardID, config := range e.config.EventLeaderboards {
+		if !e.isEventArchivable(config, now) {
+			continue
+		}
+
+		cohorts, err := e.getAllCohortsForEvent(ctx, logger, nk, eventLeaderboardID)
+		if err != nil {
+			logger.Error("Failed to get cohorts for event %s: %v", eventLeaderboardID, err)
+			return err
+		}
+
+		archived := 0
+		for _, cohort := range cohorts {
+			if cohort.ArchiveTimeSec > 0 {
 				continue
 			}
-
-			// Only include cohorts for this event
-			if cohort.EventLeaderboardID == eventLeaderboardID {
-				cohorts = append(cohorts, &cohort)
+			if err := e.archiveCohort(ctx, logger, nk, eventLeaderboardID, cohort.ID); err != nil {
+				logger.Error("Failed to archive cohort %s: %v", cohort.ID, err)
+				return err
 			}
+			archived++
+		}
+		if archived > 0 {
+			logger.Info("Archived %d cohorts of event %s", archived, eventLeaderboardID)
 		}
 	}
 
-	return cohorts, nil
+	return nil
+}
+
+// storageTTLRules expires archived cohorts and their standings once the archive retention passes.
+func (e *NakamaEventLeaderboardsSystem) storageTTLRules() []*storageTTLRule {
+	if e.config == nil || e.config.ArchiveRetentionDays <= 0 {
+		return nil
+	}
+	retentionSec := int64(e.config.ArchiveRetentionDays) * 86400
+	return []*storageTTLRule{
+		{
+			Name:        "event leaderboard cohorts",
+			Collection:  eventLeaderboardsStorageCollection,
+			KeyPrefix:   eventLeaderboardCohortPrefix,
+			ExpiryField: "archive_time_sec",
+			TTLSec:      retentionSec,
+		},
+		{
+			Name:        "event leaderboard standings",
+			Collection:  eventLeaderboardsStorageCollection,
+			KeyPrefix:   eventLeaderboardStandingsPrefix,
+			ExpiryField: "snapshot_time_sec",
+			TTLSec:      retentionSec,
+		},
+	}
+}
+
+// archiveCohort persists the cohort's standings, then deletes its backing leaderboard and marks the cohort archived.
+// The leaderboard is only deleted once the snapshot is stored.
+func (e *NakamaEventLeaderboardsSystem) archiveCohort(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID, cohortID string) error {
+	return withStorageLock(ctx, nk, eventLeaderboardCohortPrefix+cohortID, func() error {
+		cohortState, err := e.getCohortState(ctx, logger, nk, cohortID)
+		if err != nil {
+			return err
+		}
+		if cohortState.ArchiveTimeSec > 0 {
+			return nil
+		}
+
+		backingID := e.getBackingLeaderboardID(eventLeaderboardID, cohortID)
+		records, err := listCohortRecords(ctx, nk, backingID)
+		if err != nil {
+			return err
+		}
+		now := time.Now().Unix()
+		standings := &EventLeaderboardCohortStandings{
+			CohortID:           cohortID,
+			EventLeaderboardID: eventLeaderboardID,
+			Tier:               cohortState.Tier,
+			Scores:             make([]*EventLeaderboardScore, 0, len(records)),
+			SnapshotTimeSec:    now,
+		}
+		for _, record := range records {
+			standings.Scores = append(standings.Scores, eventLeaderboardScoreFromRecord(record))
+		}
+		standingsData, err := json.Marshal(standings)
+		if err != nil {
+			return err
+		}
+		if _, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection: eventLeaderboardsStorageCollection,
+				Key:        eventLeaderboardStandingsPrefix + cohortID,
+				Value:      string(standingsData),
+			},
+		}); err != nil {
+			return err
+		}
+
+		if err := nk.LeaderboardDelete(ctx, backingID); err != nil {
+			return err
+		}
+
+		cohortState.ArchiveTimeSec = now
+		cohortData, err := json.Marshal(cohortState)
+		if err != nil {
+			return err
+		}
+		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection: eventLeaderboardsStorageCollection,
+				Key:        eventLeaderboardCohortPrefix + cohortID,
+				Value:      string(cohortData),
+			},
+		})
+		return err
+	})
+}
+
+// getCohortStandings returns the snapshot of an archived cohort's standings, or nil if it hasn't been archived.
+func (e *NakamaEventLeaderboardsSystem) getCohortStandings(ctx context.Context, nk runtime.NakamaModule, cohortID string) (*EventLeaderboardCohortStandings, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: eventLeaderboardsStorageCollection,
+			Key:        eventLeaderboardStandingsPrefix + cohortID,
+		},
+	})
+	if err != nil || len(objects) == 0 {
+		return nil, err
+	}
+
+	var standings EventLeaderboardCohortStandings
+	if err := json.Unmarshal([]byte(objects[0].Value), &standings); err != nil {
+		return nil, err
+	}
+	return &standings, nil
+}
+
+// Helper function to get all cohorts for an event
+func (e *NakamaEventLeaderboardsSystem) getAllCohortsForEvent(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID string) ([]*EventLeaderboardCohortState, error) {
+	// List all cohort storage objects for this event. The collection also holds standings snapshots, so page through it
+	var cohorts []*EventLeaderboardCohortState
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageList(ctx, "", "", eventLeaderboardsStorageCollection, 100, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objects {
+			if strings.HasPrefix(obj.Key, eventLeaderboardCohortPrefix) {
+				var cohort EventLeaderboardCohortState
+				if err := json.Unmarshal([]byte(obj.Value), &cohort); err != nil {
+					logger.Error("Failed to unmarshal cohort state: %v", err)
+					continue
+				}
+
+				// Only include cohorts for this event
+				if cohort.EventLeaderboardID == eventLeaderboardID {
+					cohorts = append(cohorts, &cohort)
+				}
+			}
+		}
+
+		if nextCursor == "" || len(objects) == 0 {
+			return cohorts, nil
+		}
+		cursor = nextCursor
+	}
 }
 
 // Helper function to process tier changes for a cohort based on change zones
@@ -748,71 +1207,91 @@ func (e *NakamaEventLeaderboardsSystem) processCohortTierChanges(ctx context.Con
 		return nil
 	}
 
-	// Get leaderboard records for this cohort
-	backingID := e.getBackingLeaderboardID(eventLeaderboardID, cohort.ID)
-	records, _, _, _, err := nk.LeaderboardRecordsList(ctx, backingID, nil, 100, "", 0)
+	records, err := listCohortRecords(ctx, nk, e.getBackingLeaderboardID(eventLeaderboardID, cohort.ID))
 	if err != nil {
 		logger.Error("Failed to get leaderboard records for cohort %s: %v", cohort.ID, err)
 		return err
 	}
 
-	if len(records) == 0 {
-		return nil
+	for userID, tierChange := range computeCohortTierChanges(records, cohort.UserIDs, changeZone) {
+		if err := e.applyTierChange(ctx, logger, nk, userID, eventLeaderboardID, tierChange); err != nil {
+			logger.Error("Failed to apply tier change %d to user %s: %v", tierChange, userID, err)
+		}
 	}
 
-	// Calculate promotion and demotion thresholds
-	totalParticipants := len(records)
-	promotionCount := int(float64(totalParticipants) * changeZone.Promotion)
-	demotionCount := int(float64(totalParticipants) * changeZone.Demotion)
+	return nil
+}
 
-	// Process promotions (top performers)
-	for i := 0; i < promotionCount && i < len(records); i++ {
-		record := records[i]
-		err := e.applyTierChange(ctx, logger, nk, record.OwnerId, eventLeaderboardID, 1) // +1 tier
+// listCohortRecords pages through every record of a cohort's backing leaderboard, in rank order.
+func listCohortRecords(ctx context.Context, nk runtime.NakamaModule, backingID string) ([]*api.LeaderboardRecord, error) {
+	var records []*api.LeaderboardRecord
+	cursor := ""
+	for {
+		page, _, nextCursor, _, err := nk.LeaderboardRecordsList(ctx, backingID, nil, 100, cursor, 0)
 		if err != nil {
-			logger.Error("Failed to promote user %s: %v", record.OwnerId, err)
+			return nil, err
 		}
+		records = append(records, page...)
+		if nextCursor == "" || len(page) == 0 {
+			return records, nil
+		}
+		cursor = nextCursor
 	}
+}
 
-	// Process demotions (bottom performers)
-	demotionStartIndex := len(records) - demotionCount
-	for i := demotionStartIndex; i < len(records); i++ {
-		record := records[i]
+// computeCohortTierChanges works out the tier change for each cohort member from the cohort's records, which must be
+// in rank order. The top of the ranking is promoted and the bottom demoted according to the change zone fractions,
+// with the two zones never overlapping. Idle members, those with no record or a zero score, are never promoted and
+// are demoted separately only when the change zone has DemoteIdle set.
+func computeCohortTierChanges(records []*api.LeaderboardRecord, cohortUserIDs []string, changeZone *EventLeaderboardsConfigChangeZone) map[string]int {
+	changes := make(map[string]int)
+	if changeZone == nil {
+		return changes
+	}
 
-		// Check if user should be demoted for being idle
-		shouldDemoteIdle := changeZone.DemoteIdle && record.Score == 0
+	totalParticipants := len(records)
+	promotionCount := int(float64(totalParticipants) * changeZone.Promotion)
+	demotionCount := int(float64(totalParticipants) * changeZone.Demotion)
+	if promotionCount > totalParticipants {
+		promotionCount = totalParticipants
+	}
+	if demotionCount > totalParticipants-promotionCount {
+		demotionCount = totalParticipants - promotionCount
+	}
 
-		if shouldDemoteIdle || i >= demotionStartIndex {
-			err := e.applyTierChange(ctx, logger, nk, record.OwnerId, eventLeaderboardID, -1) // -1 tier
-			if err != nil {
-				logger.Error("Failed to demote user %s: %v", record.OwnerId, err)
-			}
+	// Rank based promotions and demotions
+	for i := 0; i < promotionCount; i++ {
+		if records[i].Score != 0 {
+			changes[records[i].OwnerId] = 1
 		}
 	}
+	for i := totalParticipants - demotionCount; i < totalParticipants; i++ {
+		changes[records[i].OwnerId] = -1
+	}
 
-	// Handle idle demotion for users who didn't submit any scores
-	if changeZone.DemoteIdle {
-		for _, userID := range cohort.UserIDs {
-			// Check if user has a record
-			hasRecord := false
-			for _, record := range records {
-				if record.OwnerId == userID {
-					hasRecord = true
-					break
-				}
-			}
+	if !changeZone.DemoteIdle {
+		return changes
+	}
 
-			if !hasRecord {
-				// User didn't participate, demote them
-				err := e.applyTierChange(ctx, logger, nk, userID, eventLeaderboardID, -1)
-				if err != nil {
-					logger.Error("Failed to demote idle user %s: %v", userID, err)
-				}
-			}
+	// Idle demotions, for members who never submitted a score
+	active := make(map[string]bool, len(records))
+	for _, record := range records {
+		if record.Score != 0 {
+			active[record.OwnerId] = true
+		}
+	}
+	for _, userID := range cohortUserIDs {
+		if !active[userID] {
+			changes[userID] = -1
+		}
+	}
+	for _, record := range records {
+		if !active[record.OwnerId] {
+			changes[record.OwnerId] = -1
 		}
 	}
 
-	return nil
+	return changes
 }
 
 // Helper function to apply tier change to a user
@@ -861,6 +1340,7 @@ func (e *NakamaEventLeaderboardsSystem) applyTierChange(ctx context.Context, log
 	// Update tier
 	oldTier := userEventState.Tier
 	userEventState.Tier = newTier
+	userState.lifetimeStats().recordTier(newTier)
 
 	// Reset cohort to force new assignment in next event
 	userEventState.CohortID = ""
@@ -922,6 +1402,53 @@ func (e *NakamaEventLeaderboardsSystem) saveUserState(ctx context.Context, logge
 	return err
 }
 
+// resetEventIterationRerolls clears the user's reroll tracking when the event's reset schedule has started a new
+// iteration since they last rolled.
+func (e *NakamaEventLeaderboardsSystem) resetEventIterationRerolls(nk runtime.NakamaModule, config *EventLeaderboardsConfigLeaderboard, userEventState *EventLeaderboardUserEventState, now int64) {
+	if config.ResetSchedule == "" {
+		return
+	}
+	iterationStartSec, err := nk.CronPrev(config.ResetSchedule, now)
+	if err != nil || iterationStartSec <= userEventState.LastResetTimeSec {
+		return
+	}
+
+	userEventState.LastResetTimeSec = iterationStartSec
+	userEventState.RerollCount = 0
+	userEventState.LastRerollTime = 0
+	userEventState.RerollTimesSec = nil
+}
+
+// eventLeaderboardWindowRerolls returns the times of the user's rerolls that still count towards the reroll window.
+func eventLeaderboardWindowRerolls(config *EventLeaderboardsConfigLeaderboard, userEventState *EventLeaderboardUserEventState, now int64) []int64 {
+	var rerolls []int64
+	for _, rerollTimeSec := range userEventState.RerollTimesSec {
+		if rerollTimeSec > now-config.RerollWindowSec {
+			rerolls = append(rerolls, rerollTimeSec)
+		}
+	}
+	return rerolls
+}
+
+// eventLeaderboardNextRerollTimeSec returns when the reroll cooldown and windowed limit next let the user reroll, or
+// zero if they can reroll now. The lifetime MaxRerolls cap is checked separately.
+func eventLeaderboardNextRerollTimeSec(config *EventLeaderboardsConfigLeaderboard, userEventState *EventLeaderboardUserEventState, now int64) int64 {
+	var nextRerollTimeSec int64
+	if config.RerollCooldownSec > 0 && userEventState.LastRerollTime > 0 && now < userEventState.LastRerollTime+config.RerollCooldownSec {
+		nextRerollTimeSec = userEventState.LastRerollTime + config.RerollCooldownSec
+	}
+	if config.MaxRerollsPerWindow > 0 && config.RerollWindowSec > 0 {
+		// The oldest reroll holding the user at the limit has to leave the window first
+		rerolls := eventLeaderboardWindowRerolls(config, userEventState, now)
+		if len(rerolls) >= config.MaxRerollsPerWindow {
+			if windowTimeSec := rerolls[len(rerolls)-config.MaxRerollsPerWindow] + config.RerollWindowSec; windowTimeSec > nextRerollTimeSec {
+				nextRerollTimeSec = windowTimeSec
+			}
+		}
+	}
+	return nextRerollTimeSec
+}
+
 func (e *NakamaEventLeaderboardsSystem) isEventActive(config *EventLeaderboardsConfigLeaderboard, now int64) bool {
 	if config.StartTimeSec > 0 && now < config.StartTimeSec {
 		return false
@@ -935,19 +1462,69 @@ func (e *NakamaEventLeaderboardsSystem) isEventActive(config *EventLeaderboardsC
 func (e *NakamaEventLeaderboardsSystem) isEventClaimable(config *EventLeaderboardsConfigLeaderboard, now int64) bool {
 	if config.EndTimeSec > 0 && now >= config.EndTimeSec {
 		// Event has ended, check if it's still within claim window
-		claimWindow := int64(86400) // 24 hours default
-		if config.Duration > 0 {
-			claimWindow = config.Duration
-		}
-		return now < config.EndTimeSec+claimWindow
+		return now < eventLeaderboardClaimEndTimeSec(config)
 	}
 	return false
 }
 
+// eventLeaderboardClaimEndTimeSec is when the claim window of an ended event closes.
+func eventLeaderboardClaimEndTimeSec(config *EventLeaderboardsConfigLeaderboard) int64 {
+	claimWindow := int64(86400) // 24 hours default
+	if config.Duration > 0 {
+		claimWindow = config.Duration
+	}
+	return config.EndTimeSec + claimWindow
+}
+
+// isEventArchivable reports whether the cohorts of an event are due to be archived, which is never unless the event
+// ends and has a cleanup delay configured.
+func (e *NakamaEventLeaderboardsSystem) isEventArchivable(config *EventLeaderboardsConfigLeaderboard, now int64) bool {
+	if config.EndTimeSec == 0 || config.CleanupAfterDays <= 0 {
+		return false
+	}
+	return now >= eventLeaderboardClaimEndTimeSec(config)+int64(config.CleanupAfterDays)*86400
+}
+
+// eventLeaderboardOperator returns the Nakama override operator for a configured operator name. An empty name writes
+// with the backing leaderboard's own operator. Returns false for names Nakama doesn't know.
+func eventLeaderboardOperator(operator string) (*int, bool) {
+	var value api.Operator
+	switch operator {
+	case "":
+		return nil, true
+	case "best":
+		value = api.Operator_BEST
+	case "set":
+		value = api.Operator_SET
+	case "incr", "increment":
+		value = api.Operator_INCREMENT
+	case "decr", "decrement":
+		value = api.Operator_DECREMENT
+	default:
+		return nil, false
+	}
+	operatorValue := int(value)
+	return &operatorValue, true
+}
+
+// validateOperators checks that every event leaderboard is configured with an operator Nakama supports.
+func (e *NakamaEventLeaderboardsSystem) validateOperators() error {
+	for eventLeaderboardID, config := range e.config.EventLeaderboards {
+		if _, ok := eventLeaderboardOperator(config.Operator); !ok {
+			return fmt.Errorf("event leaderboard %s has unknown operator %q", eventLeaderboardID, config.Operator)
+		}
+	}
+	return nil
+}
+
 func (e *NakamaEventLeaderboardsSystem) getBackingLeaderboardID(eventLeaderboardID, cohortID string) string {
 	return fmt.Sprintf("%s%s_%s", eventLeaderboardBackingPrefix, eventLeaderboardID, cohortID)
 }
 
+func (e *NakamaEventLeaderboardsSystem) getGlobalLeaderboardID(eventLeaderboardID string) string {
+	return e.getBackingLeaderboardID(eventLeaderboardID, eventLeaderboardGlobalSuffix)
+}
+
 func (e *NakamaEventLeaderboardsSystem) findOrCreateCohort(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID string, config *EventLeaderboardsConfigLeaderboard, userID string, tier int32, matchmakerProperties map[string]interface{}) (string, error) {
 	// Use custom cohort selection if available
 	if e.onEventLeaderboardCohortSelection != nil {
@@ -986,47 +1563,50 @@ func (e *NakamaEventLeaderboardsSystem) findOrCreateCohort(ctx context.Context,
 
 // addUserToCohort adds a user to an existing cohort
 func (e *NakamaEventLeaderboardsSystem) addUserToCohort(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, cohortID, userID string) error {
-	// Get current cohort state
-	cohortState, err := e.getCohortState(ctx, logger, nk, cohortID)
-	if err != nil {
-		return err
-	}
+	// Users joining from different nodes must not overfill the cohort or drop each other from it
+	return withStorageLock(ctx, nk, eventLeaderboardCohortPrefix+cohortID, func() error {
+		// Get current cohort state
+		cohortState, err := e.getCohortState(ctx, logger, nk, cohortID)
+		if err != nil {
+			return err
+		}
 
-	// Check if user is already in the cohort
-	for _, existingUserID := range cohortState.UserIDs {
-		if existingUserID == userID {
-			// User is already in this cohort
-			return nil
+		// Check if user is already in the cohort
+		for _, existingUserID := range cohortState.UserIDs {
+			if existingUserID == userID {
+				// User is already in this cohort
+				return nil
+			}
 		}
-	}
 
-	// Check if cohort has space
-	if len(cohortState.UserIDs) >= cohortState.MaxSize {
-		return fmt.Errorf("cohort %s is full", cohortID)
-	}
+		// Check if cohort has space
+		if len(cohortState.UserIDs) >= cohortState.MaxSize {
+			return fmt.Errorf("cohort %s is full", cohortID)
+		}
 
-	// Add user to cohort
-	cohortState.UserIDs = append(cohortState.UserIDs, userID)
+		// Add user to cohort
+		cohortState.UserIDs = append(cohortState.UserIDs, userID)
 
-	// Save updated cohort state
-	data, err := json.Marshal(cohortState)
-	if err != nil {
-		return err
-	}
+		// Save updated cohort state
+		data, err := json.Marshal(cohortState)
+		if err != nil {
+			return err
+		}
 
-	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-		{
-			Collection: eventLeaderboardsStorageCollection,
-			Key:        eventLeaderboardCohortPrefix + cohortID,
-			Value:      string(data),
-		},
-	})
-	if err != nil {
-		return err
-	}
+		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection: eventLeaderboardsStorageCollection,
+				Key:        eventLeaderboardCohortPrefix + cohortID,
+				Value:      string(data),
+			},
+		})
+		if err != nil {
+			return err
+		}
 
-	logger.Debug("Added user %s to cohort %s (new size: %d)", userID, cohortID, len(cohortState.UserIDs))
-	return nil
+		logger.Debug("Added user %s to cohort %s (new size: %d)", userID, cohortID, len(cohortState.UserIDs))
+		return nil
+	})
 }
 
 func (e *NakamaEventLeaderboardsSystem) findAvailableCohort(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID string, tier int32, maxSize int, userID string) string {
@@ -1078,9 +1658,9 @@ func (e *NakamaEventLeaderboardsSystem) findAvailableCohort(ctx context.Context,
 			continue // Cohort is full
 		}
 
-		// Check if cohort is still active (not ended)
+		// Check if cohort is still active (not ended or cancelled)
 		now := time.Now().Unix()
-		if cohortState.EndTimeSec > 0 && now >= cohortState.EndTimeSec {
+		if (cohortState.EndTimeSec > 0 && now >= cohortState.EndTimeSec) || cohortState.CancelTimeSec > 0 || cohortState.MergedIntoID != "" {
 			continue // Cohort has ended
 		}
 
@@ -1095,8 +1675,27 @@ func (e *NakamaEventLeaderboardsSystem) findAvailableCohort(ctx context.Context,
 	return ""
 }
 
+// prepareEventLeaderboard creates an empty first tier cohort of the event leaderboard, with its backing and global
+// leaderboards, unless one with room is already open. The content calendar calls it ahead of the event's start.
+func (e *NakamaEventLeaderboardsSystem) prepareEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID string) error {
+	config, exists := e.config.EventLeaderboards[eventLeaderboardID]
+	if !exists {
+		return ErrBadInput
+	}
+	if cohortID := e.findAvailableCohort(ctx, logger, nk, eventLeaderboardID, 0, config.CohortSize, ""); cohortID != "" {
+		return nil
+	}
+
+	cohortID, err := e.createCohort(ctx, logger, nk, eventLeaderboardID, config, 0, []string{}, nil)
+	if err != nil {
+		return err
+	}
+	logger.Info("Prepared cohort %s of event leaderboard %s", cohortID, eventLeaderboardID)
+	return nil
+}
+
 func (e *NakamaEventLeaderboardsSystem) createCohort(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID string, config *EventLeaderboardsConfigLeaderboard, tier int32, userIDs []string, matchmakerProperties map[string]interface{}) (string, error) {
-	cohortID := uuid.New().String()
+	cohortID := e.newID()
 	now := time.Now().Unix()
 
 	cohortState := &EventLeaderboardCohortState{
@@ -1150,9 +1749,36 @@ func (e *NakamaEventLeaderboardsSystem) createCohort(ctx context.Context, logger
 		logger.Debug("Leaderboard creation failed (might already exist): %v", err)
 	}
 
+	if config.GlobalRanking {
+		e.ensureGlobalLeaderboard(ctx, logger, nk, eventLeaderboardID, config)
+	}
+
 	return cohortID, nil
 }
 
+// ensureGlobalLeaderboard creates the event's global ranking leaderboard the first time this node needs it, whether
+// for a new cohort, a score or a ranking read, so events which enable the ranking after their cohorts exist get one
+// too. It returns the leaderboard's ID.
+func (e *NakamaEventLeaderboardsSystem) ensureGlobalLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, eventLeaderboardID string, config *EventLeaderboardsConfigLeaderboard) string {
+	globalID := e.getGlobalLeaderboardID(eventLeaderboardID)
+	if _, created := e.globalRankings.Load(globalID); created {
+		return globalID
+	}
+
+	sortOrder := "desc"
+	if config.Ascending {
+		sortOrder = "asc"
+	}
+	// The global ranking always keeps each user's best score regardless of the cohort operator. Creating a leaderboard
+	// which already exists succeeds.
+	if err := nk.LeaderboardCreate(ctx, globalID, false, sortOrder, "best", config.ResetSchedule, nil, false); err != nil {
+		logger.Error("Failed to create global leaderboard for event %s: %v", eventLeaderboardID, err)
+		return globalID
+	}
+	e.globalRankings.Store(globalID, struct{}{})
+	return globalID
+}
+
 func (e *NakamaEventLeaderboardsSystem) buildEventLeaderboard(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, eventLeaderboardID string, config *EventLeaderboardsConfigLeaderboard, userState *EventLeaderboardUserState, withScores bool, now int64) (*EventLeaderboard, error) {
 	eventLeaderboard := &EventLeaderboard{
 		Id:                   eventLeaderboardID,
@@ -1170,6 +1796,10 @@ func (e *NakamaEventLeaderboardsSystem) buildEventLeaderboard(ctx context.Contex
 		ChangeZones:          make(map[int32]*EventLeaderboardChangeZone),
 	}
 
+	if config.EndTimeSec > 0 {
+		eventLeaderboard.ExpiryTimeSec = eventLeaderboardClaimEndTimeSec(config)
+	}
+
 	// Set state flags
 	eventLeaderboard.IsActive = e.isEventActive(config, now)
 	eventLeaderboard.CanClaim = false
@@ -1189,6 +1819,8 @@ func (e *NakamaEventLeaderboardsSystem) buildEventLeaderboard(ctx context.Contex
 		}
 
 		// Enhanced reroll logic
+		e.resetEventIterationRerolls(nk, config, userEventState, now)
+		eventLeaderboard.RerollCount = userEventState.RerollCount
 		if eventLeaderboard.IsActive {
 			// Can roll if no cohort or if rerolls are available
 			if userEventState.CohortID == "" {
@@ -1196,7 +1828,8 @@ func (e *NakamaEventLeaderboardsSystem) buildEventLeaderboard(ctx context.Contex
 			} else {
 				// Check reroll limits
 				if config.MaxRerolls == 0 || userEventState.RerollCount < int32(config.MaxRerolls) {
-					eventLeaderboard.CanRoll = true
+					eventLeaderboard.NextRerollTimeSec = eventLeaderboardNextRerollTimeSec(config, userEventState, now)
+					eventLeaderboard.CanRoll = eventLeaderboard.NextRerollTimeSec == 0
 				} else {
 					eventLeaderboard.CanRoll = false
 				}
@@ -1231,14 +1864,12 @@ func (e *NakamaEventLeaderboardsSystem) buildEventLeaderboard(ctx context.Contex
 				if rewardTier.Reward != nil {
 					economySystem := e.pamlogix.GetEconomySystem()
 					if economySystem != nil {
-						availableRewards := economySystem.RewardConvert(nil)
-						if availableRewards != nil {
-							// This would need proper implementation to convert EconomyConfigReward to AvailableRewards
-							// For now, we'll leave it nil
-							tier.AvailableRewards = nil
-						}
+						tier.AvailableRewards = economySystem.RewardConvertReverse(rewardTier.Reward)
 					}
 				}
+				if len(rewardTier.Choices) > 0 {
+					tier.RewardChoices = e.rewardChoicesPreview(rewardTier)
+				}
 
 				eventLeaderboardRewardTiers.RewardTiers = append(eventLeaderboardRewardTiers.RewardTiers, tier)
 			}
@@ -1263,8 +1894,20 @@ func (e *NakamaEventLeaderboardsSystem) buildEventLeaderboard(ctx context.Contex
 	if withScores && hasUserState && userEventState.CohortID != "" {
 		backingID := e.getBackingLeaderboardID(eventLeaderboardID, userEventState.CohortID)
 
-		records, _, _, _, err := nk.LeaderboardRecordsList(ctx, backingID, nil, 100, "", 0)
-		if err != nil {
+		var standings *EventLeaderboardCohortStandings
+		if e.isEventArchivable(config, now) {
+			var err error
+			if standings, err = e.getCohortStandings(ctx, nk, userEventState.CohortID); err != nil {
+				logger.Error("Failed to get standings of cohort %s: %v", userEventState.CohortID, err)
+			}
+		}
+
+		if standings != nil {
+			// The backing leaderboard has been deleted, so the scores come from the snapshot
+			eventLeaderboard.Scores = standings.Scores
+			eventLeaderboard.Count = int64(len(standings.Scores))
+			eventLeaderboard.MaxCount = int64(config.CohortSize)
+		} else if records, _, _, _, err := nk.LeaderboardRecordsList(ctx, backingID, nil, 100, "", 0); err != nil {
 			logger.Error("Failed to get leaderboard records: %v", err)
 		} else {
 			eventLeaderboard.Scores = make([]*EventLeaderboardScore, 0, len(records))
@@ -1272,34 +1915,7 @@ func (e *NakamaEventLeaderboardsSystem) buildEventLeaderboard(ctx context.Contex
 			eventLeaderboard.MaxCount = int64(config.CohortSize)
 
 			for _, record := range records {
-				username := record.OwnerId // Default to user ID
-				if record.Username != nil {
-					username = record.Username.Value
-				}
-
-				// Convert timestamps to Unix seconds
-				createTime := int64(0)
-				if record.CreateTime != nil {
-					createTime = record.CreateTime.Seconds
-				}
-				updateTime := int64(0)
-				if record.UpdateTime != nil {
-					updateTime = record.UpdateTime.Seconds
-				}
-
-				score := &EventLeaderboardScore{
-					Id:            record.OwnerId,
-					Username:      username,
-					DisplayName:   username, // Could be enhanced with actual display names
-					CreateTimeSec: createTime,
-					UpdateTimeSec: updateTime,
-					Rank:          record.Rank,
-					Score:         record.Score,
-					Subscore:      record.Subscore,
-					NumScores:     int64(record.NumScore),
-					Metadata:      record.Metadata,
-				}
-				eventLeaderboard.Scores = append(eventLeaderboard.Scores, score)
+				eventLeaderboard.Scores = append(eventLeaderboard.Scores, eventLeaderboardScoreFromRecord(record))
 			}
 		}
 	}
@@ -1312,160 +1928,47 @@ func (e *NakamaEventLeaderboardsSystem) buildEventLeaderboard(ctx context.Contex
 	return eventLeaderboard, nil
 }
 
-func (e *NakamaEventLeaderboardsSystem) findRewardTier(config *EventLeaderboardsConfigLeaderboard, tier int32, rank int32) *EventLeaderboardsConfigLeaderboardRewardTier {
-	tierStr := strconv.Itoa(int(tier))
-	rewardTiers, exists := config.RewardTiers[tierStr]
-	if !exists {
-		return nil
+// eventLeaderboardScoreFromRecord converts a backing leaderboard record into an event leaderboard score.
+func eventLeaderboardScoreFromRecord(record *api.LeaderboardRecord) *EventLeaderboardScore {
+	username := record.OwnerId // Default to user ID
+	if record.Username != nil {
+		username = record.Username.Value
 	}
 
-	for _, rewardTier := range rewardTiers {
-		if rank >= int32(rewardTier.RankMin) && rank <= int32(rewardTier.RankMax) {
-			return rewardTier
-		}
+	// Convert timestamps to Unix seconds
+	createTime := int64(0)
+	if record.CreateTime != nil {
+		createTime = record.CreateTime.Seconds
 	}
-
-	return nil
-}
-
-// Helper function to check if user can afford a reward cost
-func (e *NakamaEventLeaderboardsSystem) checkUserCanAffordReward(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, rewardConfig *EconomyConfigReward) (bool, error) {
-	if rewardConfig == nil || rewardConfig.Guaranteed == nil {
-		return true, nil
+	updateTime := int64(0)
+	if record.UpdateTime != nil {
+		updateTime = record.UpdateTime.Seconds
 	}
 
-	// Check currencies
-	if rewardConfig.Guaranteed.Currencies != nil && len(rewardConfig.Guaranteed.Currencies) > 0 {
-		economySystem := e.pamlogix.GetEconomySystem()
-		if economySystem == nil {
-			return false, ErrSystemNotAvailable
-		}
-
-		// Get user's account to check wallet
-		account, err := nk.AccountGetId(ctx, userID)
-		if err != nil {
-			logger.Error("Failed to get user account: %v", err)
-			return false, err
-		}
-
-		// Get wallet from account
-		wallet, err := economySystem.UnmarshalWallet(account)
-		if err != nil {
-			logger.Error("Failed to unmarshal wallet: %v", err)
-			return false, err
-		}
-
-		// Check if user has enough of each required currency
-		for currencyID, currencyReward := range rewardConfig.Guaranteed.Currencies {
-			// Use the maximum amount as the cost
-			amount := currencyReward.Max
-			if amount < 0 {
-				amount = -amount // Convert negative to positive for affordability check
-			}
-			if wallet[currencyID] < amount {
-				logger.Debug("User %s does not have enough of currency %s (has %d, needs %d)", userID, currencyID, wallet[currencyID], amount)
-				return false, nil
-			}
-		}
+	return &EventLeaderboardScore{
+		Id:            record.OwnerId,
+		Username:      username,
+		DisplayName:   username, // Could be enhanced with actual display names
+		CreateTimeSec: createTime,
+		UpdateTimeSec: updateTime,
+		Rank:          record.Rank,
+		Score:         record.Score,
+		Subscore:      record.Subscore,
+		NumScores:     int64(record.NumScore),
+		Metadata:      record.Metadata,
 	}
-
-	// Check items
-	if rewardConfig.Guaranteed.Items != nil && len(rewardConfig.Guaranteed.Items) > 0 {
-		inventorySystem := e.pamlogix.GetInventorySystem()
-		if inventorySystem == nil {
-			return false, ErrSystemNotAvailable
-		}
-
-		// Get user's inventory
-		inventory, err := inventorySystem.ListInventoryItems(ctx, logger, nk, userID, "")
-		if err != nil {
-			logger.Error("Failed to get user inventory: %v", err)
-			return false, err
-		}
-
-		// Check if user has enough of each required item
-		for itemID, itemReward := range rewardConfig.Guaranteed.Items {
-			// Use the maximum amount as the cost
-			amount := itemReward.Max
-			if amount < 0 {
-				amount = -amount // Convert negative to positive for affordability check
-			}
-
-			found := false
-			for _, item := range inventory.Items {
-				if item.Id == itemID && item.Count >= amount {
-					found = true
-					break
-				}
-			}
-
-			if !found {
-				logger.Debug("User %s does not have enough of item %s", userID, itemID)
-				return false, nil
-			}
-		}
-	}
-
-	return true, nil
 }
 
-// Helper function to deduct reward cost from user
-func (e *NakamaEventLeaderboardsSystem) deductRewardCost(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, rewardConfig *EconomyConfigReward) error {
-	if rewardConfig == nil || rewardConfig.Guaranteed == nil {
+func (e *NakamaEventLeaderboardsSystem) findRewardTier(config *EventLeaderboardsConfigLeaderboard, tier int32, rank int32) *EventLeaderboardsConfigLeaderboardRewardTier {
+	tierStr := strconv.Itoa(int(tier))
+	rewardTiers, exists := config.RewardTiers[tierStr]
+	if !exists {
 		return nil
 	}
 
-	// Deduct currencies
-	if rewardConfig.Guaranteed.Currencies != nil && len(rewardConfig.Guaranteed.Currencies) > 0 {
-		economySystem := e.pamlogix.GetEconomySystem()
-		if economySystem == nil {
-			return ErrSystemNotAvailable
-		}
-
-		// Convert to negative values for deduction
-		deductCurrencies := make(map[string]int64)
-		for currencyID, currencyReward := range rewardConfig.Guaranteed.Currencies {
-			// Use the maximum amount as the cost
-			amount := currencyReward.Max
-			if amount < 0 {
-				amount = -amount // Convert negative to positive, then make negative for deduction
-			}
-			deductCurrencies[currencyID] = -amount
-		}
-
-		// Deduct currencies
-		_, _, _, err := economySystem.Grant(ctx, logger, nk, userID, deductCurrencies, nil, nil, map[string]interface{}{
-			"source": "event_leaderboard_cost",
-		})
-		if err != nil {
-			logger.Error("Failed to deduct currencies: %v", err)
-			return err
-		}
-	}
-
-	// Deduct items
-	if rewardConfig.Guaranteed.Items != nil && len(rewardConfig.Guaranteed.Items) > 0 {
-		inventorySystem := e.pamlogix.GetInventorySystem()
-		if inventorySystem == nil {
-			return ErrSystemNotAvailable
-		}
-
-		// Convert to negative values for deduction
-		deductItems := make(map[string]int64)
-		for itemID, itemReward := range rewardConfig.Guaranteed.Items {
-			// Use the maximum amount as the cost
-			amount := itemReward.Max
-			if amount < 0 {
-				amount = -amount // Convert negative to positive, then make negative for deduction
-			}
-			deductItems[itemID] = -amount
-		}
-
-		// Deduct items
-		_, _, _, _, err := inventorySystem.GrantItems(ctx, logger, nk, userID, deductItems, false)
-		if err != nil {
-			logger.Error("Failed to deduct items: %v", err)
-			return err
+	for _, rewardTier := range rewardTiers {
+		if rank >= int32(rewardTier.RankMin) && rank <= int32(rewardTier.RankMax) {
+			return rewardTier
 		}
 	}
 
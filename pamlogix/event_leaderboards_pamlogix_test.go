@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"strconv"
 	"testing"
 	"time"
 
@@ -114,7 +115,7 @@ func createTestMockPamlogix(t *testing.T) *MockPamlogix {
 	mockEconomy.On("RewardConvert", mock.Anything).Return(&EconomyConfigReward{})
 	mockEconomy.On("UnmarshalWallet", mock.Anything).Return(map[string]int64{"coins": 200, "gems": 100}, nil)
 
-	// Add mock expectation for Grant method (used for deducting participation costs)
+	// Add mock expectation for Grant method
 	mockEconomy.On("Grant",
 		mock.Anything, // context (use Anything to avoid type issues)
 		mock.AnythingOfType("*pamlogix.mockLogger"),
@@ -213,6 +214,10 @@ func TestRollEventLeaderboard_FirstTime(t *testing.T) {
 	}
 	nk.On("AccountGetId", ctx, userID).Return(account, nil)
 
+	// Mock wallet update for charging the participation cost
+	nk.On("WalletUpdate", ctx, userID, map[string]int64{"coins": -100}, mock.Anything, false).Return(
+		map[string]int64{"coins": 100, "gems": 100}, map[string]int64{"coins": 200, "gems": 100}, nil)
+
 	// Mock storage write for user state
 	nk.On("StorageWrite", ctx, mock.Anything).Return([]*api.StorageObjectAck{}, nil)
 
@@ -273,6 +278,10 @@ func TestRollEventLeaderboard_Reroll(t *testing.T) {
 	}
 	nk.On("AccountGetId", ctx, userID).Return(account, nil)
 
+	// Mock wallet update for charging the reroll cost
+	nk.On("WalletUpdate", ctx, userID, map[string]int64{"gems": -50}, mock.Anything, false).Return(
+		map[string]int64{"coins": 200, "gems": 50}, map[string]int64{"coins": 200, "gems": 100}, nil)
+
 	// Mock storage write for user state
 	nk.On("StorageWrite", ctx, mock.Anything).Return([]*api.StorageObjectAck{}, nil)
 
@@ -330,6 +339,192 @@ func TestRollEventLeaderboard_RerollLimitExceeded(t *testing.T) {
 	nk.AssertExpectations(t)
 }
 
+func TestRollEventLeaderboard_RerollCooldown(t *testing.T) {
+	config := getTestEventLeaderboardsConfig()
+	config.EventLeaderboards["test_event"].RerollCooldownSec = 3600
+	system := NewNakamaEventLeaderboardsSystem(config)
+	mockPamlogix := createTestMockPamlogix(t)
+	system.SetPamlogix(mockPamlogix)
+
+	logger := &mockLogger{}
+	nk := NewMockNakama(t)
+	ctx := context.Background()
+	userID := "user1"
+
+	// Mock existing user state with a reroll a minute ago
+	existingState := &EventLeaderboardUserState{
+		EventLeaderboards: map[string]*EventLeaderboardUserEventState{
+			"test_event": {
+				CohortID:       "existing_cohort",
+				RerollCount:    1,
+				LastRerollTime: time.Now().Unix() - 60,
+			},
+		},
+	}
+	stateData, _ := json.Marshal(existingState)
+	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{
+		{Value: string(stateData)},
+	}, nil)
+
+	eventLeaderboard, err := system.RollEventLeaderboard(ctx, logger, nk, userID, "test_event", nil, nil)
+	assert.Equal(t, ErrBadInput, err)
+	assert.Nil(t, eventLeaderboard)
+
+	nk.AssertExpectations(t)
+}
+
+func TestPreviewRollEventLeaderboard(t *testing.T) {
+	config := getTestEventLeaderboardsConfig()
+	config.EventLeaderboards["test_event"].RerollCooldownSec = 3600
+	system := NewNakamaEventLeaderboardsSystem(config)
+	system.SetPamlogix(newBenchPamlogix())
+
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	ctx := context.Background()
+
+	_, _, err := nk.WalletUpdate(ctx, "user1", map[string]int64{"coins": 40}, nil, false)
+	require.NoError(t, err)
+	cohortData, _ := json.Marshal(&EventLeaderboardCohortState{
+		ID:                 "cohort1",
+		EventLeaderboardID: "test_event",
+		UserIDs:            []string{"user2", "user3"},
+		MaxSize:            10,
+	})
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{Collection: eventLeaderboardsStorageCollection, Key: eventLeaderboardCohortPrefix + "cohort1", Value: string(cohortData)},
+	})
+	require.NoError(t, err)
+
+	// Joining for the first time shows the participation cost and the cohort with room for the user
+	preview, err := system.PreviewRollEventLeaderboard(ctx, logger, nk, "user1", "test_event", nil)
+	require.NoError(t, err)
+	assert.False(t, preview.IsReroll)
+	assert.True(t, preview.CanRoll)
+	assert.Equal(t, int32(0), preview.Tier)
+	assert.Equal(t, "cohort1", preview.CohortId)
+	assert.Equal(t, 2, preview.CohortSize)
+	assert.Equal(t, 10, preview.MaxCohortSize)
+	assert.InDelta(t, 3600, preview.TimeRemainingSec, 5)
+	assert.Equal(t, map[string]int64{"coins": 100}, preview.Cost.Currencies)
+	require.NotNil(t, preview.MissingCost)
+	assert.Equal(t, map[string]int64{"coins": 60}, preview.MissingCost.Currencies)
+
+	// Nothing is charged or joined
+	wallet, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(40), wallet["coins"])
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: eventLeaderboardsStorageCollection, Key: eventLeaderboardUserStateKey, UserID: "user1"}})
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+
+	// A user who just rerolled sees the reroll cost and the end of the cooldown, and the requested tier
+	stateData, _ := json.Marshal(&EventLeaderboardUserState{
+		EventLeaderboards: map[string]*EventLeaderboardUserEventState{
+			"test_event": {CohortID: "cohort0", RerollCount: 1, LastRerollTime: time.Now().Unix() - 60},
+		},
+	})
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{Collection: eventLeaderboardsStorageCollection, Key: eventLeaderboardUserStateKey, UserID: "user1", Value: string(stateData)},
+	})
+	require.NoError(t, err)
+
+	tier := 2
+	preview, err = system.PreviewRollEventLeaderboard(ctx, logger, nk, "user1", "test_event", &tier)
+	require.NoError(t, err)
+	assert.True(t, preview.IsReroll)
+	assert.False(t, preview.CanRoll)
+	assert.Positive(t, preview.NextRerollTimeSec)
+	assert.Equal(t, int32(2), preview.Tier)
+	assert.Empty(t, preview.CohortId)
+	assert.Zero(t, preview.CohortSize)
+	assert.Equal(t, map[string]int64{"gems": 50}, preview.Cost.Currencies)
+}
+
+func TestEventLeaderboardNextRerollTimeSec(t *testing.T) {
+	now := int64(100000)
+	config := &EventLeaderboardsConfigLeaderboard{
+		MaxRerollsPerWindow: 2,
+		RerollWindowSec:     21600,
+	}
+
+	// One reroll in the window leaves room for another
+	state := &EventLeaderboardUserEventState{RerollTimesSec: []int64{now - 100}}
+	assert.Equal(t, int64(0), eventLeaderboardNextRerollTimeSec(config, state, now))
+
+	// At the limit, the older of the two rerolls has to leave the window
+	state.RerollTimesSec = []int64{now - 30000, now - 500, now - 100}
+	assert.Equal(t, now-500+21600, eventLeaderboardNextRerollTimeSec(config, state, now))
+
+	// A longer cooldown wins over the window
+	config.RerollCooldownSec = 86400
+	state.LastRerollTime = now - 100
+	assert.Equal(t, now-100+86400, eventLeaderboardNextRerollTimeSec(config, state, now))
+}
+
+func TestResetEventIterationRerolls(t *testing.T) {
+	system := NewNakamaEventLeaderboardsSystem(getTestEventLeaderboardsConfig())
+	nk := NewMockNakama(t)
+	now := time.Now().Unix()
+	config := &EventLeaderboardsConfigLeaderboard{ResetSchedule: "0 0 * * 1"}
+	nk.On("CronPrev", "0 0 * * 1", now).Return(now-3600, nil)
+
+	state := &EventLeaderboardUserEventState{
+		RerollCount:      3,
+		LastRerollTime:   now - 7200,
+		RerollTimesSec:   []int64{now - 7200},
+		LastResetTimeSec: now - 86400*7,
+	}
+	system.resetEventIterationRerolls(nk, config, state, now)
+	assert.Equal(t, int32(0), state.RerollCount)
+	assert.Empty(t, state.RerollTimesSec)
+	assert.Equal(t, now-3600, state.LastResetTimeSec)
+
+	// Rerolls within the same iteration are kept
+	state.RerollCount = 1
+	system.resetEventIterationRerolls(nk, config, state, now)
+	assert.Equal(t, int32(1), state.RerollCount)
+
+	nk.AssertExpectations(t)
+}
+
+func TestEventLeaderboardLifetimeStats(t *testing.T) {
+	userState := &EventLeaderboardUserState{}
+	stats := userState.lifetimeStats()
+	assert.Same(t, stats, userState.lifetimeStats())
+
+	stats.recordJoin(1)
+	stats.recordJoin(0)
+	stats.recordTier(2)
+	assert.Equal(t, int64(2), stats.EventsJoined)
+	assert.Equal(t, int32(2), stats.HighestTier)
+
+	// Claims add up rewards and keep the best rank, with the latest first in the history
+	stats.recordClaim("weekly", &EventLeaderboardUserEventState{CohortID: "c1", Tier: 1}, 5, &Reward{
+		Currencies: map[string]int64{"coins": 100},
+		Items:      map[string]int64{"sword": 1},
+	}, 1000)
+	stats.recordClaim("daily", &EventLeaderboardUserEventState{CohortID: "c2", Tier: 3}, 2, &Reward{
+		Currencies: map[string]int64{"coins": 50, "gems": 5},
+	}, 2000)
+	stats.recordClaim("daily", &EventLeaderboardUserEventState{CohortID: "c3", Tier: 0}, 40, nil, 3000)
+	assert.Equal(t, int64(3), stats.EventsClaimed)
+	assert.Equal(t, int64(2), stats.BestRank)
+	assert.Equal(t, int32(3), stats.HighestTier)
+	assert.Equal(t, map[string]int64{"coins": 150, "gems": 5}, stats.RewardCurrencies)
+	assert.Equal(t, map[string]int64{"sword": 1}, stats.RewardItems)
+	require.Len(t, stats.History, 3)
+	assert.Equal(t, "c3", stats.History[0].CohortId)
+	assert.Equal(t, "weekly", stats.History[2].EventLeaderboardId)
+
+	// Only the most recent claims are kept in the history
+	for i := 0; i < eventLeaderboardHistoryMaxEntries; i++ {
+		stats.recordClaim("daily", &EventLeaderboardUserEventState{}, 10, nil, int64(4000+i))
+	}
+	assert.Len(t, stats.History, eventLeaderboardHistoryMaxEntries)
+	assert.Equal(t, int64(4000+eventLeaderboardHistoryMaxEntries-1), stats.History[0].ClaimTimeSec)
+}
+
 func TestUpdateEventLeaderboard_TargetScoreAchievement(t *testing.T) {
 	config := getTestEventLeaderboardsConfig()
 	system := NewNakamaEventLeaderboardsSystem(config)
@@ -426,7 +621,7 @@ func TestClaimEventLeaderboard_Success(t *testing.T) {
 	nk.On("LeaderboardRecordsList", ctx, mock.AnythingOfType("string"), mock.Anything, 100, "", int64(0)).Return(
 		[]*api.LeaderboardRecord{record}, []*api.LeaderboardRecord{}, "", "", nil)
 
-	eventLeaderboard, err := system.ClaimEventLeaderboard(ctx, logger, nk, userID, "ended_event")
+	eventLeaderboard, err := system.ClaimEventLeaderboard(ctx, logger, nk, userID, "ended_event", nil)
 	require.NoError(t, err)
 	assert.NotNil(t, eventLeaderboard)
 	assert.True(t, eventLeaderboard.ClaimTimeSec > 0)
@@ -434,6 +629,95 @@ func TestClaimEventLeaderboard_Success(t *testing.T) {
 	nk.AssertExpectations(t)
 }
 
+func TestClaimEventLeaderboard_RewardChoice(t *testing.T) {
+	now := time.Now().Unix()
+	system := NewNakamaEventLeaderboardsSystem(&EventLeaderboardsConfig{
+		EventLeaderboards: map[string]*EventLeaderboardsConfigLeaderboard{
+			"choice_event": {
+				Operator:     "best",
+				StartTimeSec: now - 7200,
+				EndTimeSec:   now - 3600,
+				CohortSize:   5,
+				RewardTiers: map[string][]*EventLeaderboardsConfigLeaderboardRewardTier{
+					"0": {
+						{
+							Name:    "Winner",
+							RankMin: 1,
+							RankMax: 1,
+							Reward: &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+								Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 10, Max: 10}}},
+							}},
+							Choices: []*EconomyConfigReward{
+								{Guaranteed: &EconomyConfigRewardContents{
+									Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 100, Max: 100}}},
+								}},
+								{Guaranteed: &EconomyConfigRewardContents{
+									Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {EconomyConfigRewardRangeInt64: EconomyConfigRewardRangeInt64{Min: 500, Max: 500}}},
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	system.SetPamlogix(newBenchPamlogix())
+	require.NoError(t, system.validateRewardChoices())
+
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	ctx := context.Background()
+	userID := "user1"
+
+	stateData, err := json.Marshal(&EventLeaderboardUserState{
+		EventLeaderboards: map[string]*EventLeaderboardUserEventState{
+			"choice_event": {CohortID: "test_cohort"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{Collection: eventLeaderboardsStorageCollection, Key: eventLeaderboardUserStateKey, UserID: userID, Value: string(stateData)},
+	})
+	require.NoError(t, err)
+
+	record := &api.LeaderboardRecord{OwnerId: userID, Username: wrapperspb.String("testuser"), Score: 1000, Rank: 1}
+	nk.MockNakamaModule.On("LeaderboardRecordsList", ctx, mock.AnythingOfType("string"), mock.Anything, mock.Anything, "", int64(0)).Return(
+		[]*api.LeaderboardRecord{record}, []*api.LeaderboardRecord{}, "", "", nil)
+
+	// The tier's choices are previewed in the order they're chosen by
+	eventLeaderboard, err := system.GetEventLeaderboard(ctx, logger, nk, userID, "choice_event")
+	require.NoError(t, err)
+	choices := eventLeaderboard.RewardTiers[0].RewardTiers[0].RewardChoices
+	require.Len(t, choices, 2)
+	assert.Equal(t, int64(500), choices[1].Guaranteed.Currencies[benchCurrency].Count.Min)
+
+	// The tier offers choices, so one in range is required
+	_, err = system.ClaimEventLeaderboard(ctx, logger, nk, userID, "choice_event", nil)
+	assert.ErrorIs(t, err, ErrEventLeaderboardRewardChoice)
+	invalid := 2
+	_, err = system.ClaimEventLeaderboard(ctx, logger, nk, userID, "choice_event", &invalid)
+	assert.ErrorIs(t, err, ErrEventLeaderboardRewardChoice)
+
+	choice := 1
+	eventLeaderboard, err = system.ClaimEventLeaderboard(ctx, logger, nk, userID, "choice_event", &choice)
+	require.NoError(t, err)
+	assert.True(t, eventLeaderboard.ClaimTimeSec > 0)
+
+	wallet, err := userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(510), wallet[benchCurrency])
+
+	for _, choice := range []*EconomyConfigReward{nil, {}} {
+		assert.Error(t, NewNakamaEventLeaderboardsSystem(&EventLeaderboardsConfig{
+			EventLeaderboards: map[string]*EventLeaderboardsConfigLeaderboard{
+				"choice_event": {RewardTiers: map[string][]*EventLeaderboardsConfigLeaderboardRewardTier{
+					"0": {{Name: "Winner", Choices: []*EconomyConfigReward{choice}}},
+				}},
+			},
+		}).validateRewardChoices())
+	}
+}
+
 func TestProcessEventEnd_TierChanges(t *testing.T) {
 	config := getTestEventLeaderboardsConfig()
 	system := NewNakamaEventLeaderboardsSystem(config)
@@ -650,35 +934,71 @@ func (m *MockEconomySystem) RewardRoll(ctx context.Context, logger runtime.Logge
 	return args.Get(0).(*Reward), args.Error(1)
 }
 
+func (m *MockEconomySystem) RewardRollN(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, rewardConfig *EconomyConfigReward, count int64) (*Reward, error) {
+	args := m.Called(ctx, logger, nk, userID, rewardConfig, count)
+	return args.Get(0).(*Reward), args.Error(1)
+}
+
 func (m *MockEconomySystem) RewardGrant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, metadata map[string]interface{}, ignoreLimits bool) (newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, err error) {
 	args := m.Called(ctx, logger, nk, userID, reward, metadata, ignoreLimits)
 	return args.Get(0).(map[string]*InventoryItem), args.Get(1).(map[string]*InventoryItem), args.Get(2).(map[string]int64), args.Error(3)
 }
 
+func (m *MockEconomySystem) RewardGrantDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, reward *Reward, metadata map[string]interface{}, ignoreLimits bool) (newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, err error) {
+	args := m.Called(ctx, logger, nk, userID, reward, metadata, ignoreLimits)
+	return args.Get(0).(map[string]*InventoryItem), args.Get(1).(map[string]*InventoryItem), args.Get(2).(map[string]int64), args.Error(3)
+}
+
+func (m *MockEconomySystem) RewardGrantBulk(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, rewards map[string]*Reward, metadata map[string]interface{}, ignoreLimits bool) (map[string]*EconomyRewardGrantResult, error) {
+	args := m.Called(ctx, logger, nk, rewards, metadata, ignoreLimits)
+	return args.Get(0).(map[string]*EconomyRewardGrantResult), args.Error(1)
+}
+
 func (m *MockEconomySystem) RewardConvert(contents *AvailableRewards) *EconomyConfigReward {
 	args := m.Called(contents)
 	return args.Get(0).(*EconomyConfigReward)
 }
 
+func (m *MockEconomySystem) RewardGrantCapped(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, source string, reward *Reward, metadata map[string]interface{}) (map[string]int64, error) {
+	return nil, nil
+}
 func (m *MockEconomySystem) UnmarshalWallet(account *api.Account) (map[string]int64, error) {
 	args := m.Called(account)
 	return args.Get(0).(map[string]int64), args.Error(1)
 }
 
-func (m *MockEconomySystem) Grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, modifiers []*RewardModifier, metadata map[string]interface{}) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error) {
+func (m *MockEconomySystem) Debit(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, metadata map[string]interface{}) (map[string]int64, error) {
+	args := m.Called(ctx, logger, nk, userID, currencies, items, metadata)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockEconomySystem) DebitDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, metadata map[string]interface{}) (map[string]int64, error) {
+	args := m.Called(ctx, logger, nk, userID, currencies, items, metadata)
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockEconomySystem) Grant(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, itemInstances map[string]*RewardInventoryItem, modifiers []*RewardModifier, metadata map[string]interface{}) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error) {
+	args := m.Called(ctx, logger, nk, userID, currencies, items, modifiers, metadata)
+	return args.Get(0).(map[string]int64), args.Get(1).([]*ActiveRewardModifier), args.Get(2).(int64), args.Error(3)
+}
+
+func (m *MockEconomySystem) GrantDryRun(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, currencies map[string]int64, items map[string]int64, itemInstances map[string]*RewardInventoryItem, modifiers []*RewardModifier, metadata map[string]interface{}) (updatedWallet map[string]int64, rewardModifiers []*ActiveRewardModifier, timestamp int64, err error) {
 	args := m.Called(ctx, logger, nk, userID, currencies, items, modifiers, metadata)
 	return args.Get(0).(map[string]int64), args.Get(1).([]*ActiveRewardModifier), args.Get(2).(int64), args.Error(3)
 }
 
 // Stub implementations for other required interface methods
 func (m *MockEconomySystem) RewardCreate() *EconomyConfigReward { return nil }
+func (m *MockEconomySystem) RewardConvertReverse(rewardConfig *EconomyConfigReward) *AvailableRewards {
+	return nil
+}
 func (m *MockEconomySystem) DonationClaim(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, donationClaims map[string]*EconomyDonationClaimRequestDetails) (*EconomyDonationsList, error) {
 	return nil, nil
 }
 func (m *MockEconomySystem) DonationGet(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userIDs []string) (*EconomyDonationsByUserList, error) {
 	return nil, nil
 }
-func (m *MockEconomySystem) DonationGive(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, donationID, fromUserID string) (*EconomyDonation, map[string]int64, *Inventory, []*ActiveRewardModifier, *Reward, int64, error) {
+func (m *MockEconomySystem) DonationGive(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, recipientID, donationID, contributorID string) (*EconomyDonation, map[string]int64, *Inventory, []*ActiveRewardModifier, *Reward, int64, error) {
 	return nil, nil, nil, nil, nil, 0, nil
 }
 func (m *MockEconomySystem) DonationRequest(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, donationID string) (*EconomyDonation, bool, error) {
@@ -690,15 +1010,78 @@ func (m *MockEconomySystem) List(ctx context.Context, logger runtime.Logger, nk
 func (m *MockEconomySystem) PurchaseIntent(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, itemID string, store EconomyStoreType, sku string) error {
 	return nil
 }
+func (m *MockEconomySystem) PurchaseIntentCancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, itemID string) error {
+	return nil
+}
+func (m *MockEconomySystem) TeamPurchaseItem(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID, itemID string) (map[string]int64, *Inventory, *Reward, error) {
+	return nil, nil, nil, nil
+}
+func (m *MockEconomySystem) CanAfford(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, cost *Cost) (*Cost, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) ListCurrencies(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (map[string]*EconomyConfigCurrency, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) PurchaseIntentsCleanup(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	return 0, nil
+}
+func (m *MockEconomySystem) CompactModifiers(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	return 0, nil
+}
 func (m *MockEconomySystem) PurchaseItem(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, userID, itemID string, store EconomyStoreType, receipt string) (map[string]int64, *Inventory, *Reward, bool, error) {
 	return nil, nil, nil, false, nil
 }
 func (m *MockEconomySystem) PurchaseRestore(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, store EconomyStoreType, receipts []string) error {
 	return nil
 }
+func (m *MockEconomySystem) PurchaseTransactionsExport(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, startTimeSec, endTimeSec int64, format string, limit int, cursor string) (*EconomyPurchaseTransactionsExport, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) StartModifierJob(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, request *EconomyModifierJobRequest) (*EconomyModifierJob, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) GetModifierJob(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, jobID string) (*EconomyModifierJob, error) {
+	return nil, nil
+}
 func (m *MockEconomySystem) PlacementStatus(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, rewardID, placementID string, retryCount int) (*EconomyPlacementStatus, error) {
 	return nil, nil
 }
+func (m *MockEconomySystem) SnapshotCreate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, reason string) (*EconomySnapshot, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) SnapshotList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomySnapshotList, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) SnapshotRestore(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, snapshotID string) (*EconomySnapshot, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) EventLogList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, afterSequence int64, limit int) (*EconomyEventLogList, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) Summary(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomySummary, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) SubscriptionPurchase(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, subscriptionID string, store EconomyStoreType, receipt string) (*EconomySubscription, *Reward, error) {
+	return nil, nil, nil
+}
+func (m *MockEconomySystem) SubscriptionList(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomySubscriptionList, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) SubscriptionCancel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, subscriptionID string) (*EconomySubscription, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) SubscriptionsCheck(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	return 0, nil
+}
+func (m *MockEconomySystem) PurchaseGrantsReconcile(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomyPurchaseGrantsReconcile, error) {
+	return nil, nil
+}
+func (m *MockEconomySystem) PurchaseGrantsRetry(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (int, error) {
+	return 0, nil
+}
+func (m *MockEconomySystem) ListPlacements(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*EconomyPlacementList, error) {
+	return nil, nil
+}
 func (m *MockEconomySystem) PlacementStart(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, placementID string, metadata map[string]string) (*EconomyPlacementStatus, error) {
 	return nil, nil
 }
@@ -712,3 +1095,461 @@ func (m *MockEconomySystem) SetOnDonationClaimReward(fn OnReward[*EconomyConfigD
 func (m *MockEconomySystem) SetOnDonationContributorReward(fn OnReward[*EconomyConfigDonation]) {}
 func (m *MockEconomySystem) SetOnPlacementReward(fn OnReward[*EconomyPlacementInfo])            {}
 func (m *MockEconomySystem) SetOnStoreItemReward(fn OnReward[*EconomyConfigStoreItem])          {}
+func (m *MockEconomySystem) SetPurchaseValidator(store EconomyStoreType, fn EconomyPurchaseValidatorFn) {
+}
+
+func TestEventLeaderboardGlobalRanking(t *testing.T) {
+	config := getTestEventLeaderboardsConfig()
+	eventConfig := config.EventLeaderboards["test_event"]
+	eventConfig.TargetScore = 0
+	eventConfig.GlobalRanking = true
+	eventConfig.GlobalRankingSize = 5
+	system := NewNakamaEventLeaderboardsSystem(config)
+	mockPamlogix := createTestMockPamlogix(t)
+	mockPamlogix.On("GetEventLeaderboardsSystem").Return(system)
+	system.SetPamlogix(mockPamlogix)
+
+	logger := &mockLogger{}
+	nk := NewMockNakama(t)
+	ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, "user1")
+	cohortID := "backing_test_event_test_cohort"
+	globalID := "backing_test_event_global"
+
+	stateData, _ := json.Marshal(&EventLeaderboardUserState{
+		EventLeaderboards: map[string]*EventLeaderboardUserEventState{
+			"test_event": {CohortID: "test_cohort"},
+		},
+	})
+	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{{Value: string(stateData)}}, nil)
+
+	// The global leaderboard is created on first use even though the user's cohort already exists, and only once
+	nk.On("LeaderboardCreate", ctx, globalID, false, "desc", "best", "", mock.Anything, false).Return(nil).Once()
+
+	// The global ranking mirrors the cohort's resulting score rather than the submitted one, keeping the best
+	best := int(api.Operator_BEST)
+	nk.On("LeaderboardRecordWrite", ctx, cohortID, "user1", "testuser", int64(500), int64(3), mock.Anything, mock.Anything).Return(
+		&api.LeaderboardRecord{OwnerId: "user1", Score: 700, Subscore: 3}, nil).Once()
+	nk.On("LeaderboardRecordWrite", ctx, globalID, "user1", "testuser", int64(700), int64(3), mock.Anything,
+		mock.MatchedBy(func(operator *int) bool { return operator != nil && *operator == best })).Return(&api.LeaderboardRecord{}, nil).Once()
+	nk.On("LeaderboardRecordsList", ctx, cohortID, mock.Anything, mock.Anything, "", int64(0)).Return(
+		[]*api.LeaderboardRecord{}, []*api.LeaderboardRecord{}, "", "", nil)
+
+	_, err := system.UpdateEventLeaderboard(ctx, logger, nil, nk, "user1", "testuser", "test_event", 500, 3, map[string]interface{}{}, false)
+	require.NoError(t, err)
+
+	records := []*api.LeaderboardRecord{
+		{OwnerId: "user2", Username: wrapperspb.String("other"), Score: 900, Rank: 1},
+		{OwnerId: "user1", Username: wrapperspb.String("testuser"), Score: 700, Rank: 2},
+	}
+	// Limits of zero or beyond the configured size are clamped to it
+	nk.On("LeaderboardRecordsList", ctx, globalID, []string{"user1"}, 5, "", int64(0)).Return(
+		records, records[1:], "", "", nil).Times(3)
+	nk.On("LeaderboardRecordsList", ctx, globalID, []string{"user1"}, 2, "", int64(0)).Return(
+		records, records[1:], "", "", nil).Once()
+
+	for _, limit := range []int{0, 50, 2} {
+		ranking, err := system.GetEventLeaderboardGlobalRanking(ctx, logger, nk, "user1", "test_event", limit)
+		require.NoError(t, err)
+		assert.Equal(t, globalID, ranking.BackingId)
+		require.Len(t, ranking.Scores, 2)
+		require.NotNil(t, ranking.OwnerScore)
+		assert.Equal(t, int64(700), ranking.OwnerScore.Score)
+	}
+
+	_, err = system.GetEventLeaderboardGlobalRanking(ctx, logger, nk, "user1", "ended_event", 0)
+	assert.ErrorIs(t, err, ErrBadInput)
+
+	rpc := rpcEventLeaderboardsGlobalGet(mockPamlogix)
+	response, err := rpc(ctx, logger, nil, nk, `{"id":"test_event"}`)
+	require.NoError(t, err)
+	var ranking EventLeaderboardGlobalRanking
+	require.NoError(t, json.Unmarshal([]byte(response), &ranking))
+	assert.Equal(t, "test_event", ranking.Id)
+	require.Len(t, ranking.Scores, 2)
+	assert.Equal(t, "user2", ranking.Scores[0].Id)
+
+	_, err = rpc(ctx, logger, nil, nk, `{}`)
+	assert.ErrorIs(t, err, ErrBadInput)
+
+	nk.AssertExpectations(t)
+}
+
+func TestDebugRandomScores_UsesOperator(t *testing.T) {
+	best, set := int(api.Operator_BEST), int(api.Operator_SET)
+	tests := []struct {
+		name     string
+		operator *int
+		want     int
+	}{
+		{name: "configured", operator: nil, want: best},
+		{name: "override", operator: &set, want: set},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			system := NewNakamaEventLeaderboardsSystem(getTestEventLeaderboardsConfig())
+			system.SetPamlogix(createTestMockPamlogix(t))
+
+			logger := &mockLogger{}
+			nk := NewMockNakama(t)
+			ctx := context.Background()
+			userID := "user1"
+
+			stateData, _ := json.Marshal(&EventLeaderboardUserState{
+				EventLeaderboards: map[string]*EventLeaderboardUserEventState{
+					"test_event": {CohortID: "test_cohort"},
+				},
+			})
+			nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{{Value: string(stateData)}}, nil)
+
+			records := []*api.LeaderboardRecord{
+				{OwnerId: userID, Username: wrapperspb.String("testuser"), Rank: 1},
+				{OwnerId: "user2", Username: wrapperspb.String("other"), Rank: 2},
+			}
+			nk.On("LeaderboardRecordsList", ctx, mock.AnythingOfType("string"), mock.Anything, 100, "", int64(0)).Return(
+				records, []*api.LeaderboardRecord{}, "", "", nil)
+			nk.On("LeaderboardRecordWrite", ctx, "backing_test_event_test_cohort", "user2", "other", int64(5), int64(0), mock.Anything,
+				mock.MatchedBy(func(operator *int) bool { return operator != nil && *operator == tt.want })).Return(&api.LeaderboardRecord{}, nil).Once()
+
+			_, err := system.DebugRandomScores(ctx, logger, nk, userID, "test_event", 5, 5, 0, 0, tt.operator)
+			require.NoError(t, err)
+			nk.AssertExpectations(t)
+		})
+	}
+
+	invalid := 9
+	system := NewNakamaEventLeaderboardsSystem(getTestEventLeaderboardsConfig())
+	_, err := system.DebugRandomScores(context.Background(), &mockLogger{}, NewMockNakama(t), "user1", "test_event", 5, 5, 0, 0, &invalid)
+	assert.ErrorIs(t, err, ErrBadInput)
+}
+
+func TestEventLeaderboardOperator(t *testing.T) {
+	operator, ok := eventLeaderboardOperator("incr")
+	require.True(t, ok)
+	assert.Equal(t, int(api.Operator_INCREMENT), *operator)
+
+	operator, ok = eventLeaderboardOperator("")
+	assert.True(t, ok)
+	assert.Nil(t, operator)
+
+	_, ok = eventLeaderboardOperator("sum")
+	assert.False(t, ok)
+
+	config := getTestEventLeaderboardsConfig()
+	assert.NoError(t, NewNakamaEventLeaderboardsSystem(config).validateOperators())
+	config.EventLeaderboards["test_event"].Operator = "sum"
+	assert.Error(t, NewNakamaEventLeaderboardsSystem(config).validateOperators())
+}
+
+func TestComputeCohortTierChanges(t *testing.T) {
+	zone := &EventLeaderboardsConfigChangeZone{Promotion: 0.2, Demotion: 0.3}
+
+	t.Run("ranked zones without idle demotion", func(t *testing.T) {
+		records := []*api.LeaderboardRecord{
+			{OwnerId: "user1", Score: 500},
+			{OwnerId: "user2", Score: 400},
+			{OwnerId: "user3", Score: 300},
+			{OwnerId: "user4", Score: 200},
+			{OwnerId: "user5", Score: 100},
+		}
+		changes := computeCohortTierChanges(records, []string{"user1", "user2", "user3", "user4", "user5", "user6"}, zone)
+		assert.Equal(t, map[string]int{"user1": 1, "user5": -1}, changes)
+	})
+
+	t.Run("idle members only demoted when enabled", func(t *testing.T) {
+		records := []*api.LeaderboardRecord{
+			{OwnerId: "user1", Score: 500},
+			{OwnerId: "user2", Score: 400},
+			{OwnerId: "user3", Score: 300},
+			{OwnerId: "user4", Score: 200},
+			{OwnerId: "user5", Score: 100},
+			{OwnerId: "user6", Score: 0},
+			{OwnerId: "user7", Score: 0},
+			{OwnerId: "user8", Score: 0},
+			{OwnerId: "user9", Score: 0},
+			{OwnerId: "user10", Score: 0},
+		}
+		members := []string{"user1", "user2", "user3", "user4", "user5", "user6", "user7", "user8", "user9", "user10", "user11"}
+
+		changes := computeCohortTierChanges(records, members, zone)
+		assert.Equal(t, map[string]int{"user1": 1, "user2": 1, "user8": -1, "user9": -1, "user10": -1}, changes)
+
+		idleZone := &EventLeaderboardsConfigChangeZone{Promotion: 0.2, Demotion: 0.3, DemoteIdle: true}
+		changes = computeCohortTierChanges(records, members, idleZone)
+		assert.Equal(t, map[string]int{
+			"user1": 1, "user2": 1,
+			"user6": -1, "user7": -1, "user8": -1, "user9": -1, "user10": -1, "user11": -1,
+		}, changes)
+	})
+
+	t.Run("tiny cohorts", func(t *testing.T) {
+		assert.Empty(t, computeCohortTierChanges(nil, nil, zone))
+
+		single := []*api.LeaderboardRecord{{OwnerId: "user1", Score: 100}}
+		assert.Empty(t, computeCohortTierChanges(single, []string{"user1"}, zone))
+
+		// Zones never overlap, promotion takes precedence
+		wide := &EventLeaderboardsConfigChangeZone{Promotion: 1, Demotion: 1}
+		assert.Equal(t, map[string]int{"user1": 1}, computeCohortTierChanges(single, []string{"user1"}, wide))
+
+		pair := []*api.LeaderboardRecord{{OwnerId: "user1", Score: 100}, {OwnerId: "user2", Score: 50}}
+		half := &EventLeaderboardsConfigChangeZone{Promotion: 0.5, Demotion: 0.9}
+		assert.Equal(t, map[string]int{"user1": 1, "user2": -1}, computeCohortTierChanges(pair, []string{"user1", "user2"}, half))
+	})
+
+	t.Run("idle records are never promoted", func(t *testing.T) {
+		records := []*api.LeaderboardRecord{{OwnerId: "user1", Score: 0}, {OwnerId: "user2", Score: 0}}
+		changes := computeCohortTierChanges(records, []string{"user1", "user2"}, &EventLeaderboardsConfigChangeZone{Promotion: 0.5})
+		assert.Empty(t, changes)
+	})
+}
+
+func TestProcessEventEnd_PagesThroughRecords(t *testing.T) {
+	config := getTestEventLeaderboardsConfig()
+	config.EventLeaderboards["test_event"].EndTimeSec = time.Now().Unix() - 100
+	config.EventLeaderboards["test_event"].ChangeZones["0"] = &EventLeaderboardsConfigChangeZone{Demotion: 0.01}
+	system := NewNakamaEventLeaderboardsSystem(config)
+	system.SetPamlogix(createTestMockPamlogix(t))
+
+	logger := &mockLogger{}
+	nk := NewMockNakama(t)
+	ctx := context.Background()
+
+	cohortData, _ := json.Marshal(&EventLeaderboardCohortState{ID: "big_cohort", EventLeaderboardID: "test_event"})
+	nk.On("StorageList", ctx, "", "", eventLeaderboardsStorageCollection, 100, "").Return([]*api.StorageObject{
+		{Key: "cohort_big_cohort", Value: string(cohortData)},
+	}, "", nil)
+
+	firstPage := make([]*api.LeaderboardRecord, 100)
+	for i := range firstPage {
+		firstPage[i] = &api.LeaderboardRecord{OwnerId: "user" + strconv.Itoa(i), Score: int64(1000 - i)}
+	}
+	secondPage := []*api.LeaderboardRecord{{OwnerId: "last_user", Score: 1}}
+	nk.On("LeaderboardRecordsList", ctx, mock.AnythingOfType("string"), mock.Anything, 100, "", int64(0)).Return(
+		firstPage, []*api.LeaderboardRecord{}, "page2", "", nil).Once()
+	nk.On("LeaderboardRecordsList", ctx, mock.AnythingOfType("string"), mock.Anything, 100, "page2", int64(0)).Return(
+		secondPage, []*api.LeaderboardRecord{}, "", "", nil).Once()
+
+	// With 101 records and a 1% demotion zone only the last ranked user, on the second page, is demoted
+	nk.On("StorageRead", ctx, mock.MatchedBy(func(reads []*runtime.StorageRead) bool {
+		return len(reads) == 1 && reads[0].UserID == "last_user"
+	})).Return([]*api.StorageObject{}, nil).Once()
+	nk.On("StorageWrite", ctx, mock.MatchedBy(func(writes []*runtime.StorageWrite) bool {
+		return len(writes) == 1 && writes[0].UserID == "last_user"
+	})).Return([]*api.StorageObjectAck{}, nil).Once()
+
+	require.NoError(t, system.ProcessEventEnd(ctx, logger, nk, "test_event"))
+	nk.AssertExpectations(t)
+}
+
+func TestProcessEventEnd_CancelsUndersizedCohortAndRefunds(t *testing.T) {
+	now := time.Now().Unix()
+	config := &EventLeaderboardsConfig{
+		EventLeaderboards: map[string]*EventLeaderboardsConfigLeaderboard{
+			"test_event": {
+				CohortSize:    10,
+				MinCohortSize: 3,
+				StartTimeSec:  now - 7200,
+				EndTimeSec:    now - 60,
+			},
+		},
+	}
+	system := NewNakamaEventLeaderboardsSystem(config)
+	system.SetPamlogix(newBenchPamlogix())
+
+	logger := &mockLogger{}
+	nk := newNotificationNakama()
+	ctx := context.Background()
+
+	cohort := &EventLeaderboardCohortState{ID: "cohort1", EventLeaderboardID: "test_event", UserIDs: []string{"user1", "user2"}, MaxSize: 10}
+	data, err := json.Marshal(cohort)
+	require.NoError(t, err)
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: eventLeaderboardsStorageCollection, Key: eventLeaderboardCohortPrefix + cohort.ID, Value: string(data)}})
+	require.NoError(t, err)
+
+	// user1 paid to join, user2 joined for free
+	for userID, paid := range map[string]*Cost{"user1": {Currencies: map[string]int64{"coins": 100}}, "user2": nil} {
+		require.NoError(t, system.saveUserState(ctx, logger, nk, userID, &EventLeaderboardUserState{
+			EventLeaderboards: map[string]*EventLeaderboardUserEventState{
+				"test_event": {CohortID: cohort.ID, TotalParticipation: 1, ParticipationCostPaid: paid},
+			},
+		}))
+	}
+
+	require.NoError(t, system.ProcessEventEnd(ctx, logger, nk, "test_event"))
+
+	wallet, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), wallet["coins"])
+
+	userState, err := system.getUserState(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	assert.Empty(t, userState.EventLeaderboards["test_event"].CohortID)
+	assert.Nil(t, userState.EventLeaderboards["test_event"].ParticipationCostPaid)
+
+	cancelled, err := system.getCohortState(ctx, logger, nk, cohort.ID)
+	require.NoError(t, err)
+	assert.NotZero(t, cancelled.CancelTimeSec)
+
+	require.Len(t, nk.sent, 2)
+	for _, sent := range nk.sent {
+		assert.Equal(t, defaultNotificationTemplates[NotificationEventEventCancelled].Code, sent.code)
+	}
+
+	// Cancelling the whole event afterwards doesn't refund anyone twice
+	require.NoError(t, system.CancelEventLeaderboard(ctx, logger, nk, "test_event"))
+	wallet, err = userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), wallet["coins"])
+	assert.Len(t, nk.sent, 2)
+}
+
+func TestCleanupEventLeaderboards_SnapshotsBeforeDeleting(t *testing.T) {
+	now := time.Now().Unix()
+	config := &EventLeaderboardsConfig{
+		EventLeaderboards: map[string]*EventLeaderboardsConfigLeaderboard{
+			"old_event": {
+				CohortSize:       10,
+				StartTimeSec:     now - 5*86400,
+				EndTimeSec:       now - 3*86400,
+				Duration:         3600,
+				CleanupAfterDays: 2,
+			},
+			"recent_event": {
+				CohortSize:       10,
+				StartTimeSec:     now - 2*86400,
+				EndTimeSec:       now - 86400,
+				CleanupAfterDays: 2,
+			},
+		},
+	}
+	system := NewNakamaEventLeaderboardsSystem(config)
+	system.SetPamlogix(newBenchPamlogix())
+
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	ctx := context.Background()
+
+	for _, cohort := range []*EventLeaderboardCohortState{
+		{ID: "old_cohort", EventLeaderboardID: "old_event", UserIDs: []string{"user1", "user2"}},
+		{ID: "recent_cohort", EventLeaderboardID: "recent_event", UserIDs: []string{"user3"}},
+	} {
+		data, err := json.Marshal(cohort)
+		require.NoError(t, err)
+		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: eventLeaderboardsStorageCollection, Key: eventLeaderboardCohortPrefix + cohort.ID, Value: string(data)}})
+		require.NoError(t, err)
+	}
+	require.NoError(t, system.saveUserState(ctx, logger, nk, "user1", &EventLeaderboardUserState{
+		EventLeaderboards: map[string]*EventLeaderboardUserEventState{"old_event": {CohortID: "old_cohort"}},
+	}))
+
+	backingID := system.getBackingLeaderboardID("old_event", "old_cohort")
+	nk.MockNakamaModule.On("LeaderboardRecordsList", ctx, backingID, mock.Anything, 100, "", int64(0)).Return([]*api.LeaderboardRecord{
+		{OwnerId: "user2", Score: 90, Rank: 1},
+		{OwnerId: "user1", Score: 40, Rank: 2},
+	}, []*api.LeaderboardRecord{}, "", "", nil).Once()
+	nk.MockNakamaModule.On("LeaderboardDelete", ctx, backingID).Return(nil).Once()
+
+	// Archived cohorts aren't archived again
+	require.NoError(t, system.CleanupEventLeaderboards(ctx, logger, nk))
+	require.NoError(t, system.CleanupEventLeaderboards(ctx, logger, nk))
+	nk.MockNakamaModule.AssertExpectations(t)
+
+	archived, err := system.getCohortState(ctx, logger, nk, "old_cohort")
+	require.NoError(t, err)
+	assert.NotZero(t, archived.ArchiveTimeSec)
+	recent, err := system.getCohortState(ctx, logger, nk, "recent_cohort")
+	require.NoError(t, err)
+	assert.Zero(t, recent.ArchiveTimeSec)
+
+	// The standings are served from the snapshot once the backing leaderboard is gone
+	eventLeaderboard, err := system.GetEventLeaderboard(ctx, logger, nk, "user1", "old_event")
+	require.NoError(t, err)
+	require.Len(t, eventLeaderboard.Scores, 2)
+	assert.Equal(t, "user2", eventLeaderboard.Scores[0].Id)
+	assert.Equal(t, int64(40), eventLeaderboard.Scores[1].Score)
+}
+
+func TestMergeEventLeaderboardCohorts_MovesUsersAndScores(t *testing.T) {
+	now := time.Now().Unix()
+	config := &EventLeaderboardsConfig{
+		EventLeaderboards: map[string]*EventLeaderboardsConfigLeaderboard{
+			"test_event": {
+				CohortSize:           10,
+				StartTimeSec:         now - 60,
+				EndTimeSec:           now + 3600,
+				CohortMergeWindowSec: 600,
+				CohortMergeMinSize:   3,
+			},
+		},
+	}
+	system := NewNakamaEventLeaderboardsSystem(config)
+	system.SetPamlogix(newBenchPamlogix())
+
+	logger := &mockLogger{}
+	nk := newNotificationNakama()
+	ctx := context.Background()
+
+	cohorts := []*EventLeaderboardCohortState{
+		{ID: "tiny", EventLeaderboardID: "test_event", StartTimeSec: now - 60, UserIDs: []string{"user1"}, MaxSize: 10},
+		{ID: "small", EventLeaderboardID: "test_event", StartTimeSec: now - 60, UserIDs: []string{"user2", "user3"}, MaxSize: 10},
+		{ID: "full", EventLeaderboardID: "test_event", StartTimeSec: now - 60, UserIDs: []string{"user4", "user5", "user6", "user7", "user8"}, MaxSize: 10},
+		// The only cohort of its tier has nowhere to go
+		{ID: "other_tier", EventLeaderboardID: "test_event", Tier: 1, StartTimeSec: now - 60, UserIDs: []string{"user9"}, MaxSize: 10},
+	}
+	for _, cohort := range cohorts {
+		data, err := json.Marshal(cohort)
+		require.NoError(t, err)
+		_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: eventLeaderboardsStorageCollection, Key: eventLeaderboardCohortPrefix + cohort.ID, Value: string(data)}})
+		require.NoError(t, err)
+		for _, userID := range cohort.UserIDs {
+			require.NoError(t, system.saveUserState(ctx, logger, nk, userID, &EventLeaderboardUserState{
+				EventLeaderboards: map[string]*EventLeaderboardUserEventState{"test_event": {CohortID: cohort.ID, Tier: cohort.Tier}},
+			}))
+		}
+	}
+
+	fullBackingID := system.getBackingLeaderboardID("test_event", "full")
+	for cohortID, records := range map[string][]*api.LeaderboardRecord{
+		"tiny":  {{OwnerId: "user1", Score: 40, Username: wrapperspb.String("one")}},
+		"small": {{OwnerId: "user2", Score: 70, Subscore: 2, Metadata: `{"level":3}`}},
+	} {
+		backingID := system.getBackingLeaderboardID("test_event", cohortID)
+		nk.MockNakamaModule.On("LeaderboardRecordsList", ctx, backingID, mock.Anything, 100, "", int64(0)).Return(records, []*api.LeaderboardRecord{}, "", "", nil).Once()
+		nk.MockNakamaModule.On("LeaderboardDelete", ctx, backingID).Return(nil).Once()
+	}
+	setOperator, _ := eventLeaderboardOperator("set")
+	nk.MockNakamaModule.On("LeaderboardRecordWrite", ctx, fullBackingID, "user1", "one", int64(40), int64(0), map[string]interface{}(nil), setOperator).Return(&api.LeaderboardRecord{}, nil).Once()
+	nk.MockNakamaModule.On("LeaderboardRecordWrite", ctx, fullBackingID, "user2", "", int64(70), int64(2), map[string]interface{}{"level": float64(3)}, setOperator).Return(&api.LeaderboardRecord{}, nil).Once()
+
+	// Merged cohorts aren't merged again
+	require.NoError(t, system.MergeEventLeaderboardCohorts(ctx, logger, nk))
+	require.NoError(t, system.MergeEventLeaderboardCohorts(ctx, logger, nk))
+	nk.MockNakamaModule.AssertExpectations(t)
+
+	full, err := system.getCohortState(ctx, logger, nk, "full")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user1", "user2", "user3", "user4", "user5", "user6", "user7", "user8"}, full.UserIDs)
+	for _, cohortID := range []string{"tiny", "small"} {
+		merged, err := system.getCohortState(ctx, logger, nk, cohortID)
+		require.NoError(t, err)
+		assert.Equal(t, "full", merged.MergedIntoID)
+	}
+	otherTier, err := system.getCohortState(ctx, logger, nk, "other_tier")
+	require.NoError(t, err)
+	assert.Empty(t, otherTier.MergedIntoID)
+
+	for _, userID := range []string{"user1", "user2", "user3"} {
+		userState, err := system.getUserState(ctx, logger, nk, userID)
+		require.NoError(t, err)
+		assert.Equal(t, "full", userState.EventLeaderboards["test_event"].CohortID)
+	}
+
+	require.Len(t, nk.sent, 3)
+	for _, sent := range nk.sent {
+		assert.Equal(t, defaultNotificationTemplates[NotificationEventEventCohortMerged].Code, sent.code)
+	}
+
+	// New users join the fuller cohort rather than a merged one
+	assert.Equal(t, "full", system.findAvailableCohort(ctx, logger, nk, "test_event", 0, 10, "user10"))
+}
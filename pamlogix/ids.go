@@ -0,0 +1,42 @@
+package pamlogix
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// IDGeneratorFn returns a new unique ID for a record a system creates, such as an auction, a purchase transaction or
+// an event leaderboard cohort.
+type IDGeneratorFn func() string
+
+// VersionGeneratorFn returns the version of a record from its serialised content.
+type VersionGeneratorFn func(content []byte) string
+
+// newUUID is the default ID generator.
+func newUUID() string {
+	return uuid.New().String()
+}
+
+// contentHashVersion is the default version generator, so every change to a record yields a new version.
+func contentHashVersion(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
+
+// NewSequentialIDGenerator returns an ID generator which hands out UUIDs counting up from the seed, so tests get the
+// same IDs on every run.
+func NewSequentialIDGenerator(seed uint64) IDGeneratorFn {
+	var mu sync.Mutex
+	next := seed
+	return func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		var id uuid.UUID
+		binary.BigEndian.PutUint64(id[8:], next)
+		next++
+		return id.String()
+	}
+}
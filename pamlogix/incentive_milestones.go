@@ -0,0 +1,278 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	// Referrals are kept apart from the incentives collection, which is scanned for incentive codes.
+	incentiveReferralsStorageCollection = "incentive_referrals"
+	userIncentiveReferralsStorageKey    = "referrals"
+)
+
+// incentiveReferral is an incentive claimed by a recipient, kept on the recipient so their publisher events can be
+// matched against its milestones.
+type incentiveReferral struct {
+	Code         string `json:"code"`
+	IncentiveId  string `json:"incentive_id"`
+	SenderId     string `json:"sender_id"`
+	ClaimTimeSec int64  `json:"claim_time_sec"`
+	// Milestones holds the time each reached milestone was reached at, by milestone ID.
+	Milestones map[string]int64 `json:"milestones,omitempty"`
+}
+
+// incentiveMilestone is a milestone reached by a recipient, still to be rewarded.
+type incentiveMilestone struct {
+	referral    *incentiveReferral
+	milestoneID string
+	config      *IncentivesConfigMilestone
+}
+
+// IncentiveMilestonesPublisher listens for publisher events which complete the milestones of incentives claimed by
+// the user, and grants their rewards to the user and the incentive's sender.
+type IncentiveMilestonesPublisher struct {
+	Incentives *NakamaIncentivesSystem
+}
+
+func (p *IncentiveMilestonesPublisher) Authenticate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, created bool) {
+	// No-op
+}
+
+func (p *IncentiveMilestonesPublisher) Send(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, events []*PublisherEvent) {
+	if p.Incentives == nil {
+		return
+	}
+	if err := p.Incentives.reachMilestones(ctx, logger, nk, userID, events); err != nil {
+		logger.Error("Failed to process incentive milestones for user %s: %v", userID, err)
+	}
+}
+
+// hasMilestones reports whether any incentive has milestones, so events aren't matched needlessly.
+func (i *NakamaIncentivesSystem) hasMilestones() bool {
+	if i.config == nil {
+		return false
+	}
+	for _, incentive := range i.config.Incentives {
+		if len(incentive.Milestones) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// recordReferral keeps the claim of an incentive with milestones on the recipient.
+func (i *NakamaIncentivesSystem) recordReferral(ctx context.Context, nk runtime.NakamaModule, userID, senderID, code string, incentive *Incentive, now int64) error {
+	return withStorageLock(ctx, nk, incentiveReferralsLockName(userID), func() error {
+		referrals, err := readIncentiveReferrals(ctx, nk, userID)
+		if err != nil {
+			return err
+		}
+		referrals[code] = &incentiveReferral{
+			Code:         code,
+			IncentiveId:  incentive.Id,
+			SenderId:     senderID,
+			ClaimTimeSec: now,
+		}
+		return writeIncentiveReferrals(ctx, nk, userID, referrals)
+	})
+}
+
+// reachMilestones records the milestones the events complete for the user's referrals, then grants their rewards. The
+// milestones are recorded under the user's referrals lock before anything is granted, so each is rewarded once.
+func (i *NakamaIncentivesSystem) reachMilestones(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, events []*PublisherEvent) error {
+	if len(events) == 0 || !i.hasMilestones() {
+		return nil
+	}
+
+	// Most events complete nothing, so check before taking the lock
+	referrals, err := readIncentiveReferrals(ctx, nk, userID)
+	if err != nil {
+		return err
+	}
+	if len(i.pendingMilestones(referrals, events)) == 0 {
+		return nil
+	}
+
+	var reached []*incentiveMilestone
+	err = withStorageLock(ctx, nk, incentiveReferralsLockName(userID), func() error {
+		referrals, err := readIncentiveReferrals(ctx, nk, userID)
+		if err != nil {
+			return err
+		}
+		reached = i.pendingMilestones(referrals, events)
+		if len(reached) == 0 {
+			return nil
+		}
+
+		now := time.Now().Unix()
+		for _, milestone := range reached {
+			if milestone.referral.Milestones == nil {
+				milestone.referral.Milestones = make(map[string]int64)
+			}
+			milestone.referral.Milestones[milestone.milestoneID] = now
+		}
+		return writeIncentiveReferrals(ctx, nk, userID, referrals)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, milestone := range reached {
+		i.rewardMilestone(ctx, logger, nk, userID, milestone)
+	}
+	return nil
+}
+
+// pendingMilestones returns the milestones of the referrals which the events complete and which aren't reached yet.
+func (i *NakamaIncentivesSystem) pendingMilestones(referrals map[string]*incentiveReferral, events []*PublisherEvent) []*incentiveMilestone {
+	pending := make([]*incentiveMilestone, 0)
+	for _, referral := range referrals {
+		incentiveConfig, found := i.config.Incentives[referral.IncentiveId]
+		if !found {
+			continue
+		}
+		for milestoneID, milestoneConfig := range incentiveConfig.Milestones {
+			if _, reached := referral.Milestones[milestoneID]; reached || milestoneConfig == nil {
+				continue
+			}
+			for _, event := range events {
+				if milestoneConfig.matches(event) {
+					pending = append(pending, &incentiveMilestone{referral: referral, milestoneID: milestoneID, config: milestoneConfig})
+					break
+				}
+			}
+		}
+	}
+	return pending
+}
+
+// matches reports whether the event completes the milestone.
+func (m *IncentivesConfigMilestone) matches(event *PublisherEvent) bool {
+	if event == nil || m.EventName == "" || event.Name != m.EventName {
+		return false
+	}
+	if m.EventId != "" && event.Id != m.EventId {
+		return false
+	}
+	if m.MinValue != 0 {
+		value, err := strconv.ParseFloat(event.Value, 64)
+		if err != nil || value < float64(m.MinValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// rewardMilestone grants a reached milestone's rewards to the recipient and the sender, and lets the sender know. Reward
+// failures are logged, as the milestone is already recorded and the event that reached it won't be sent again.
+func (i *NakamaIncentivesSystem) rewardMilestone(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, milestone *incentiveMilestone) {
+	referral := milestone.referral
+	metadata := map[string]interface{}{
+		"incentive_code": referral.Code,
+		"milestone_id":   milestone.milestoneID,
+	}
+
+	incentiveConfig := i.config.Incentives[referral.IncentiveId]
+	if _, err := i.grantIncentiveReward(ctx, logger, nk, userID, referral.IncentiveId, incentiveConfig, milestone.config.RecipientReward, i.onRecipientReward, metadata); err != nil {
+		logger.Error("Failed to grant milestone %s reward to recipient %s: %v", milestone.milestoneID, userID, err)
+	}
+
+	metadata["recipient_id"] = userID
+	senderReward, err := i.grantIncentiveReward(ctx, logger, nk, referral.SenderId, referral.IncentiveId, incentiveConfig, milestone.config.SenderReward, i.onSenderReward, metadata)
+	if err != nil {
+		logger.Error("Failed to grant milestone %s reward to sender %s: %v", milestone.milestoneID, referral.SenderId, err)
+	}
+
+	name := milestone.config.Name
+	if name == "" {
+		name = milestone.milestoneID
+	}
+	content := map[string]interface{}{
+		"incentive_code": referral.Code,
+		"recipient_id":   userID,
+		"milestone_id":   milestone.milestoneID,
+		"milestone":      name,
+	}
+	if senderReward != nil {
+		content["reward"] = senderReward
+	}
+	if err := sendTemplatedNotification(ctx, logger, nk, i.pamlogix, referral.SenderId, NotificationEventReferralMilestone, map[string]string{"milestone": name}, content); err != nil {
+		logger.Warn("Failed to notify sender %s of referral milestone: %v", referral.SenderId, err)
+	}
+}
+
+// grantIncentiveReward rolls and grants an incentive reward to a user, capped as a referral grant. It returns nil when
+// there's no reward to grant.
+func (i *NakamaIncentivesSystem) grantIncentiveReward(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, incentiveID string, incentiveConfig *IncentivesConfigIncentive, rewardConfig *EconomyConfigReward, onReward OnReward[*IncentivesConfigIncentive], metadata map[string]interface{}) (*Reward, error) {
+	if rewardConfig == nil || userID == "" {
+		return nil, nil
+	}
+	if i.pamlogix == nil || i.pamlogix.GetEconomySystem() == nil {
+		return nil, runtime.NewError("economy system not available", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	}
+	economySystem := i.pamlogix.GetEconomySystem()
+
+	reward, err := economySystem.RewardRoll(ctx, logger, nk, userID, rewardConfig)
+	if err != nil {
+		return nil, err
+	}
+	if onReward != nil {
+		if reward, err = onReward(ctx, logger, nk, userID, incentiveID, incentiveConfig, rewardConfig, reward); err != nil {
+			return nil, err
+		}
+	}
+	if reward == nil {
+		return nil, nil
+	}
+
+	if _, err = economySystem.RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourceReferral, reward, metadata); err != nil {
+		return nil, err
+	}
+	return reward, nil
+}
+
+func readIncentiveReferrals(ctx context.Context, nk runtime.NakamaModule, userID string) (map[string]*incentiveReferral, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: incentiveReferralsStorageCollection,
+		Key:        userIncentiveReferralsStorageKey,
+		UserID:     userID,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	referrals := make(map[string]*incentiveReferral)
+	if len(objects) > 0 && objects[0].Value != "" {
+		if err := json.Unmarshal([]byte(objects[0].Value), &referrals); err != nil {
+			return nil, err
+		}
+	}
+	return referrals, nil
+}
+
+func writeIncentiveReferrals(ctx context.Context, nk runtime.NakamaModule, userID string, referrals map[string]*incentiveReferral) error {
+	data, err := json.Marshal(referrals)
+	if err != nil {
+		return err
+	}
+
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      incentiveReferralsStorageCollection,
+		Key:             userIncentiveReferralsStorageKey,
+		UserID:          userID,
+		Value:           string(data),
+		PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}})
+	return err
+}
+
+func incentiveReferralsLockName(userID string) string {
+	return fmt.Sprintf("%s:%s", incentiveReferralsStorageCollection, userID)
+}
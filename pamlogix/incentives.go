@@ -22,6 +22,22 @@ type IncentivesConfigIncentive struct {
 	MaxConcurrent        int                    `json:"max_concurrent,omitempty"`
 	ExpiryDurationSec    int64                  `json:"expiry_duration_sec,omitempty"`
 	AdditionalProperties map[string]interface{} `json:"additional_properties,omitempty"`
+	// Milestones stage further rewards after the recipient's claim, keyed by milestone ID.
+	Milestones map[string]*IncentivesConfigMilestone `json:"milestones,omitempty"`
+}
+
+// IncentivesConfigMilestone is a stage a recipient reaches after claiming an incentive, such as reaching a level or
+// completing the tutorial. It's reached by the first publisher event for the recipient which matches it, and splits
+// its rewards between the recipient and the sender, who is notified of the invitee's progress.
+type IncentivesConfigMilestone struct {
+	Name      string `json:"name,omitempty"`
+	EventName string `json:"event_name,omitempty"`
+	// EventId limits the milestone to events for one source, e.g. a tutorial ID. Empty matches any.
+	EventId string `json:"event_id,omitempty"`
+	// MinValue requires the event's value to be a number of at least this, e.g. the level reached. Zero matches any.
+	MinValue        int64                `json:"min_value,omitempty"`
+	RecipientReward *EconomyConfigReward `json:"recipient_reward,omitempty"`
+	SenderReward    *EconomyConfigReward `json:"sender_reward,omitempty"`
 }
 
 // The IncentivesSystem provides a gameplay system which can create and claim incentives and their associated rewards.
@@ -263,10 +263,10 @@ func (i *NakamaIncentivesSystem) SenderClaim(ctx context.Context, logger runtime
 
 			// Grant reward to sender
 			if reward != nil {
-				_, _, _, err = economySystem.RewardGrant(ctx, logger, nk, userID, reward, map[string]interface{}{
+				_, err = economySystem.RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourceReferral, reward, map[string]interface{}{
 					"incentive_code": code,
 					"recipient_id":   recipientID,
-				}, false)
+				})
 				if err != nil {
 					logger.Error("Failed to grant sender reward: %v", err)
 					continue
@@ -441,10 +441,10 @@ func (i *NakamaIncentivesSystem) RecipientClaim(ctx context.Context, logger runt
 
 		// Grant reward to recipient
 		if reward != nil {
-			_, _, _, err = economySystem.RewardGrant(ctx, logger, nk, userID, reward, map[string]interface{}{
+			_, err = economySystem.RewardGrantCapped(ctx, logger, nk, userID, EconomyGrantSourceReferral, reward, map[string]interface{}{
 				"incentive_code": code,
 				"sender_id":      senderID,
-			}, false)
+			})
 			if err != nil {
 				logger.Error("Failed to grant recipient reward: %v", err)
 				return nil, runtime.NewError("failed to grant reward", INTERNAL_ERROR_CODE) // INTERNAL
@@ -476,6 +476,13 @@ func (i *NakamaIncentivesSystem) RecipientClaim(ctx context.Context, logger runt
 		return nil, runtime.NewError("failed to save incentive", INTERNAL_ERROR_CODE) // INTERNAL
 	}
 
+	// Keep the claim on the recipient, so their progress can reach the incentive's milestones
+	if len(incentiveConfig.Milestones) > 0 {
+		if err := i.recordReferral(ctx, nk, userID, senderID, code, incentiveData, now); err != nil {
+			logger.Error("Failed to record incentive %s milestones for recipient %s: %v", code, userID, err)
+		}
+	}
+
 	// Return incentive info
 	return &IncentiveInfo{
 		Id:               incentiveData.Id,
@@ -645,21 +652,11 @@ func (i *NakamaIncentivesSystem) convertRewardConfigToAvailableRewards(rewardCon
 	// Use the economy system to do the conversion if available
 	if i.pamlogix != nil {
 		if economySystem := i.pamlogix.GetEconomySystem(); economySystem != nil {
-			// This is a reverse operation - we need to build AvailableRewards from EconomyConfigReward
-			// For now, we'll create a basic structure
-			return &AvailableRewards{
-				MaxRolls:       rewardConfig.MaxRolls,
-				TotalWeight:    rewardConfig.TotalWeight,
-				MaxRepeatRolls: rewardConfig.MaxRepeatRolls,
-			}
+			return economySystem.RewardConvertReverse(rewardConfig)
 		}
 	}
 
-	return &AvailableRewards{
-		MaxRolls:       rewardConfig.MaxRolls,
-		TotalWeight:    rewardConfig.TotalWeight,
-		MaxRepeatRolls: rewardConfig.MaxRepeatRolls,
-	}
+	return rewardConfigToAvailable(rewardConfig)
 }
 
 // mergeRewards combines two rewards into one.
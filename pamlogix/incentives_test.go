@@ -979,3 +979,73 @@ func TestNakamaIncentivesSystem_FullWorkflow(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot delete claimed incentive")
 }
+
+func TestNakamaIncentivesSystem_Milestones(t *testing.T) {
+	coins := func(amount int64) *EconomyConfigReward {
+		return &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+			Currencies: map[string]*EconomyConfigRewardCurrency{
+				benchCurrency: {EconomyConfigRewardRangeInt64{Min: amount, Max: amount}},
+			},
+		}}
+	}
+	system := NewNakamaIncentivesSystem(&IncentivesConfig{
+		Incentives: map[string]*IncentivesConfigIncentive{
+			"invite": {
+				Type:            IncentiveType_INCENTIVE_TYPE_INVITE,
+				RecipientReward: coins(10),
+				Milestones: map[string]*IncentivesConfigMilestone{
+					"level_5":  {Name: "level 5", EventName: "level_reached", MinValue: 5, RecipientReward: coins(50), SenderReward: coins(100)},
+					"tutorial": {EventName: tutorialCompletedEvent, EventId: "intro", SenderReward: coins(20)},
+				},
+			},
+		},
+	})
+	p := newBenchPamlogix()
+	system.SetPamlogix(p)
+	p.AddPublisher(&IncentiveMilestonesPublisher{Incentives: system})
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ctx := context.Background()
+
+	incentives, err := system.SenderCreate(ctx, logger, nk, "sender1", "invite")
+	require.NoError(t, err)
+	require.Len(t, incentives, 1)
+	_, err = system.RecipientClaim(ctx, logger, nk, "recipient1", incentives[0].Code)
+	require.NoError(t, err)
+
+	balances := func() (int64, int64) {
+		recipient, err := userWallet(ctx, nk, "recipient1")
+		require.NoError(t, err)
+		sender, err := userWallet(ctx, nk, "sender1")
+		require.NoError(t, err)
+		return recipient[benchCurrency], sender[benchCurrency]
+	}
+	recipient, sender := balances()
+	assert.Equal(t, int64(10), recipient)
+	assert.Zero(t, sender)
+
+	// Events of other users, and events below the milestone's value, reach nothing
+	p.SendPublisherEvents(ctx, logger, nk, "sender1", []*PublisherEvent{{Name: "level_reached", Value: "5"}})
+	p.SendPublisherEvents(ctx, logger, nk, "recipient1", []*PublisherEvent{{Name: "level_reached", Value: "3"}})
+	recipient, sender = balances()
+	assert.Equal(t, int64(10), recipient)
+	assert.Zero(t, sender)
+
+	// Each milestone is rewarded once, to both sides
+	p.SendPublisherEvents(ctx, logger, nk, "recipient1", []*PublisherEvent{{Name: "level_reached", Value: "5"}})
+	p.SendPublisherEvents(ctx, logger, nk, "recipient1", []*PublisherEvent{{Name: "level_reached", Value: "6"}})
+	recipient, sender = balances()
+	assert.Equal(t, int64(60), recipient)
+	assert.Equal(t, int64(100), sender)
+
+	p.SendPublisherEvents(ctx, logger, nk, "recipient1", []*PublisherEvent{{Name: tutorialCompletedEvent, Id: "other"}})
+	p.SendPublisherEvents(ctx, logger, nk, "recipient1", []*PublisherEvent{{Name: tutorialCompletedEvent, Id: "intro"}})
+	recipient, sender = balances()
+	assert.Equal(t, int64(60), recipient)
+	assert.Equal(t, int64(120), sender)
+
+	referrals, err := readIncentiveReferrals(ctx, nk, "recipient1")
+	require.NoError(t, err)
+	require.Contains(t, referrals, incentives[0].Code)
+	assert.Len(t, referrals[incentives[0].Code].Milestones, 2)
+}
@@ -6,10 +6,20 @@ import (
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
+var (
+	ErrInventoryQueryInvalid   = runtime.NewError("invalid inventory query", INVALID_ARGUMENT_ERROR_CODE)            // INVALID_ARGUMENT
+	ErrInventoryItemNotFound   = runtime.NewError("inventory item not found", NOT_FOUND_ERROR_CODE)                  // NOT_FOUND
+	ErrInventoryEscrowExists   = runtime.NewError("inventory escrow already exists", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	ErrInventoryEscrowNotFound = runtime.NewError("inventory escrow not found", NOT_FOUND_ERROR_CODE)                // NOT_FOUND
+)
+
 type InventoryConfig struct {
-	Items    map[string]*InventoryConfigItem `json:"items,omitempty"`
-	Limits   *InventoryConfigLimits          `json:"limits,omitempty"`
-	ItemSets map[string]map[string]bool      `json:"-"` // Auto-computed when the config is read or personalized.
+	Items  map[string]*InventoryConfigItem `json:"items,omitempty"`
+	Limits *InventoryConfigLimits          `json:"limits,omitempty"`
+	// PropertyIndex registers a storage index over the properties of item instances, so queries don't read the whole
+	// inventory. Without it queries filter the user's full inventory.
+	PropertyIndex *InventoryConfigPropertyIndex `json:"property_index,omitempty"`
+	ItemSets      map[string]map[string]bool    `json:"-"` // Auto-computed when the config is read or personalized.
 
 	ConfigSource ConfigSource[*InventoryConfigItem] `json:"-"` // Not included in serialization, set dynamically.
 }
@@ -27,6 +37,76 @@ type InventoryConfigItem struct {
 	NumericProperties map[string]float64   `json:"numeric_properties,omitempty"`
 	Disabled          bool                 `json:"disabled,omitempty"`
 	KeepZero          bool                 `json:"keep_zero,omitempty"`
+	// Unlockable places grants of this item from auctions or donations into the unlockables system instead of the
+	// inventory, e.g. so a won crate goes into the unlock queue.
+	Unlockable *InventoryConfigItemUnlockable `json:"unlockable,omitempty"`
+	// Unique items are owned at most once. Rewards granting one the user already owns, or more than one, grant its
+	// DuplicateConversion for each extra instead, or nothing when it's not set.
+	Unique              bool                                    `json:"unique,omitempty"`
+	DuplicateConversion *InventoryConfigItemDuplicateConversion `json:"duplicate_conversion,omitempty"`
+	// Icon and Rarity are shown by clients alongside the name, e.g. when the item is offered in a reward preview.
+	Icon   string `json:"icon,omitempty"`
+	Rarity string `json:"rarity,omitempty"`
+	// Localized overrides the name and description by the user's language tag, e.g. "de" or "pt-BR".
+	Localized map[string]*InventoryConfigItemText `json:"localized,omitempty"`
+}
+
+// InventoryConfigItemText is a localized name and description for an InventoryConfigItem.
+type InventoryConfigItemText struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// InventoryConfigItemDuplicateConversion is what each duplicate of a unique item is converted into, e.g. shards of a
+// character the user already owns.
+type InventoryConfigItemDuplicateConversion struct {
+	Currencies map[string]int64 `json:"currencies,omitempty"`
+	Items      map[string]int64 `json:"items,omitempty"`
+}
+
+// InventoryConfigItemUnlockable is the data definition for routing an item's grants into the unlockables system.
+type InventoryConfigItemUnlockable struct {
+	// ID of the unlockable to create for each item granted.
+	Id string `json:"id"`
+	// Sources limits which grants are routed, any of "auction" and "donation". Empty means every source.
+	Sources []string `json:"sources,omitempty"`
+}
+
+// InventoryConfigPropertyIndex configures the storage index over item instance properties.
+type InventoryConfigPropertyIndex struct {
+	// MaxEntries is the most item instances kept in the index, across all users. The default is 1,000,000.
+	MaxEntries int `json:"max_entries,omitempty"`
+}
+
+// InventoryQueryOperator compares an item property against a filter value.
+type InventoryQueryOperator string
+
+const (
+	InventoryQueryOperatorEqual          InventoryQueryOperator = "=="
+	InventoryQueryOperatorNotEqual       InventoryQueryOperator = "!="
+	InventoryQueryOperatorGreater        InventoryQueryOperator = ">"
+	InventoryQueryOperatorGreaterOrEqual InventoryQueryOperator = ">="
+	InventoryQueryOperatorLess           InventoryQueryOperator = "<"
+	InventoryQueryOperatorLessOrEqual    InventoryQueryOperator = "<="
+)
+
+// InventoryPropertyFilter matches items by one of their string or numeric properties, e.g. rarity == "epic" or
+// power > 50. String properties only support equality.
+type InventoryPropertyFilter struct {
+	Property     string                 `json:"property"`
+	Operator     InventoryQueryOperator `json:"operator"`
+	Numeric      bool                   `json:"numeric,omitempty"`
+	StringValue  string                 `json:"string_value,omitempty"`
+	NumericValue float64                `json:"numeric_value,omitempty"`
+}
+
+// InventoryEscrow holds item instances taken out of a user's inventory until they're released back to the owner or
+// transferred to another user. The items are keyed by the storage key they had in the owner's inventory.
+type InventoryEscrow struct {
+	Id            string                    `json:"id"`
+	OwnerId       string                    `json:"owner_id"`
+	Items         map[string]*InventoryItem `json:"items"`
+	CreateTimeSec int64                     `json:"create_time_sec"`
 }
 
 type InventoryConfigLimits struct {
@@ -46,6 +126,9 @@ type InventorySystem interface {
 	// ListInventoryItems will return the items which are part of a user's inventory by ID.
 	ListInventoryItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, category string) (inventory *Inventory, err error)
 
+	// QueryInventoryItems will return the items in a user's inventory whose properties match all the filters.
+	QueryInventoryItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, category string, filters []*InventoryPropertyFilter) (inventory *Inventory, err error)
+
 	// ConsumeItems will deduct the item(s) from the user's inventory and run the consume reward for each one, if defined.
 	ConsumeItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, itemIDs, instanceIDs map[string]int64, overConsume bool) (updatedInventory *Inventory, rewards map[string][]*Reward, instanceRewards map[string][]*Reward, err error)
 
@@ -55,6 +138,14 @@ type InventorySystem interface {
 	// UpdateItems will update the properties which are stored on each item by instance ID for a user.
 	UpdateItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, instanceIDs map[string]*InventoryUpdateItemProperties) (updatedInventory *Inventory, err error)
 
+	// LockItems moves the item instances out of a user's inventory into an escrow by ID, such as the items listed in an
+	// auction, so they can't be consumed or traded until the escrow is released.
+	LockItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, escrowID string, instanceIDs []string) (items []*InventoryItem, err error)
+
+	// UnlockItems moves the item instances held in an escrow into a user's inventory, either back to their owner or on
+	// to another user, and removes the escrow.
+	UnlockItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, escrowID, userID string) (items []*InventoryItem, err error)
+
 	// SetOnConsumeReward sets a custom reward function which will run after an inventory items' consume reward is rolled.
 	SetOnConsumeReward(fn OnReward[*InventoryConfigItem])
 
@@ -0,0 +1,175 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const inventoryEscrowCollection = "inventory_escrow"
+
+func (i *NakamaInventorySystem) LockItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, escrowID string, instanceIDs []string) ([]*InventoryItem, error) {
+	if userID == "" || escrowID == "" || len(instanceIDs) == 0 {
+		return nil, ErrBadInput
+	}
+
+	reads := make([]*runtime.StorageRead, 0, len(instanceIDs)+1)
+	reads = append(reads, &runtime.StorageRead{
+		Collection: inventoryEscrowCollection,
+		Key:        escrowID,
+	})
+	seen := make(map[string]bool, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		if instanceID == "" || seen[instanceID] {
+			return nil, ErrBadInput
+		}
+		seen[instanceID] = true
+		reads = append(reads, &runtime.StorageRead{
+			Collection: inventoryStorageCollection,
+			Key:        instanceID,
+			UserID:     userID,
+		})
+	}
+
+	objects, err := nk.StorageRead(ctx, reads)
+	if err != nil {
+		logger.Error("Failed to read inventory items to lock for user %s: %v", userID, err)
+		return nil, ErrInternal
+	}
+
+	escrow := &InventoryEscrow{
+		Id:            escrowID,
+		OwnerId:       userID,
+		Items:         make(map[string]*InventoryItem, len(instanceIDs)),
+		CreateTimeSec: time.Now().Unix(),
+	}
+	deletes := make([]*runtime.StorageDelete, 0, len(instanceIDs))
+	for _, object := range objects {
+		if object.Collection == inventoryEscrowCollection {
+			return nil, ErrInventoryEscrowExists
+		}
+		if object.UserId != userID || !seen[object.Key] {
+			continue
+		}
+		item := &InventoryItem{}
+		if err := json.Unmarshal([]byte(object.Value), item); err != nil {
+			logger.Error("Failed to unmarshal inventory item %s: %v", object.Key, err)
+			return nil, ErrInternal
+		}
+		if item.Id == "" {
+			item.Id = object.Key
+		}
+		escrow.Items[object.Key] = item
+		// Each item is only taken as it was read, so one consumed or changed meanwhile fails the lock
+		deletes = append(deletes, &runtime.StorageDelete{
+			Collection: inventoryStorageCollection,
+			Key:        object.Key,
+			UserID:     userID,
+			Version:    object.Version,
+		})
+	}
+	if len(escrow.Items) != len(instanceIDs) {
+		return nil, ErrInventoryItemNotFound
+	}
+
+	data, err := json.Marshal(escrow)
+	if err != nil {
+		logger.Error("Failed to marshal inventory escrow %s: %v", escrowID, err)
+		return nil, ErrInternal
+	}
+	if _, _, err := nk.MultiUpdate(ctx, nil, []*runtime.StorageWrite{
+		{
+			Collection:      inventoryEscrowCollection,
+			Key:             escrowID,
+			Value:           string(data),
+			Version:         storageLockVersionNone,
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	}, deletes, nil, false); err != nil {
+		logger.Error("Failed to move inventory items of user %s into escrow %s: %v", userID, escrowID, err)
+		return nil, ErrInternal
+	}
+
+	items := make([]*InventoryItem, 0, len(instanceIDs))
+	for _, instanceID := range instanceIDs {
+		items = append(items, i.escrowedItem(escrow.Items[instanceID]))
+	}
+	return items, nil
+}
+
+func (i *NakamaInventorySystem) UnlockItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, escrowID, userID string) ([]*InventoryItem, error) {
+	if userID == "" || escrowID == "" {
+		return nil, ErrBadInput
+	}
+
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: inventoryEscrowCollection,
+			Key:        escrowID,
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to read inventory escrow %s: %v", escrowID, err)
+		return nil, ErrInternal
+	}
+	if len(objects) == 0 {
+		return nil, ErrInventoryEscrowNotFound
+	}
+
+	escrow := &InventoryEscrow{}
+	if err := json.Unmarshal([]byte(objects[0].Value), escrow); err != nil {
+		logger.Error("Failed to unmarshal inventory escrow %s: %v", escrowID, err)
+		return nil, ErrInternal
+	}
+
+	now := time.Now().Unix()
+	for _, item := range escrow.Items {
+		if userID != escrow.OwnerId {
+			item.OwnedTimeSec = now
+		}
+		item.UpdateTimeSec = now
+	}
+	writes, err := inventoryItemWrites(userID, escrow.Items)
+	if err != nil {
+		logger.Error("Failed to marshal escrowed inventory items of escrow %s: %v", escrowID, err)
+		return nil, ErrInternal
+	}
+
+	// The escrow is only removed as it was read, so the items are released once
+	if _, _, err := nk.MultiUpdate(ctx, nil, writes, []*runtime.StorageDelete{
+		{
+			Collection: inventoryEscrowCollection,
+			Key:        escrowID,
+			Version:    objects[0].Version,
+		},
+	}, nil, false); err != nil {
+		logger.Error("Failed to release inventory escrow %s to user %s: %v", escrowID, userID, err)
+		return nil, ErrInternal
+	}
+
+	items := make([]*InventoryItem, 0, len(escrow.Items))
+	for _, write := range writes {
+		items = append(items, i.escrowedItem(escrow.Items[write.Key]))
+	}
+	return items, nil
+}
+
+// escrowedItem fills in the item's details from its config, as they are when the item is read from an inventory.
+func (i *NakamaInventorySystem) escrowedItem(item *InventoryItem) *InventoryItem {
+	if i.config == nil {
+		return item
+	}
+	if configItem, found := i.config.Items[item.Id]; found {
+		item.Name = configItem.Name
+		item.Description = configItem.Description
+		item.Category = configItem.Category
+		item.ItemSets = configItem.ItemSets
+		item.MaxCount = configItem.MaxCount
+		item.Stackable = configItem.Stackable
+		item.Consumable = configItem.Consumable
+	}
+	return item
+}
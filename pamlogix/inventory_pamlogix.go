@@ -3,9 +3,11 @@ package pamlogix
 import (
 	"context"
 	"encoding/json"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
@@ -36,6 +38,8 @@ type NakamaInventorySystem struct {
 	onConsumeReward OnReward[*InventoryConfigItem]
 	configSource    ConfigSource[*InventoryConfigItem]
 	pamlogix        Pamlogix
+	// propertyIndex is set once the storage index over item properties is registered.
+	propertyIndex bool
 }
 
 // NewNakamaInventorySystem creates a new instance of the inventory system with the given configuration.
@@ -190,6 +194,7 @@ func (i *NakamaInventorySystem) ConsumeItems(ctx context.Context, logger runtime
 	// Prepare storage operations
 	var storageWrites []*runtime.StorageWrite
 	var storageDeletes []*runtime.StorageDelete
+	consumed := make(map[string]int64, len(itemIDs)+len(instanceIDs))
 
 	// Process item ID-based consumption
 	for itemID, count := range itemIDs {
@@ -295,6 +300,7 @@ func (i *NakamaInventorySystem) ConsumeItems(ctx context.Context, logger runtime
 				})
 			}
 		}
+		consumed[itemID] += count - remainingToConsume
 
 		//i.processAndStoreRewards(ctx, logger, nk, userID, itemID, itemID, configItem, count, rewards)
 		reward, err := i.processItemReward(ctx, logger, nk, userID, itemID, configItem)
@@ -343,6 +349,7 @@ func (i *NakamaInventorySystem) ConsumeItems(ctx context.Context, logger runtime
 		}
 
 		// Consume the items
+		consumed[foundItem.Id] += min(count, max(foundItem.Count, 0))
 		foundItem.Count -= count
 		foundItem.UpdateTimeSec = time.Now().Unix()
 
@@ -408,14 +415,150 @@ func (i *NakamaInventorySystem) ConsumeItems(ctx context.Context, logger runtime
 		}
 	}
 
+	if consumedItems := economyEventItems(consumed, nil); len(consumedItems) > 0 {
+		logEconomyEvents(ctx, logger, nk, i.pamlogix, &EconomyEvent{
+			Type:     EconomyEventTypeSpend,
+			UserId:   userID,
+			Items:    consumedItems,
+			Metadata: map[string]interface{}{"source": "inventory_consume"},
+		})
+	}
+
 	return userInventory, rewards, instanceRewards, nil
 }
 
+// debitItems takes items from a user's inventory by ID across all their instances, without the consume rewards or the
+// consumable check of ConsumeItems. Instances left empty are deleted unless their item keeps zero counts. Nothing is
+// taken unless the user holds every item in full.
+func (i *NakamaInventorySystem) debitItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, itemIDs map[string]int64) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	userInventory, err := i.getUserInventoryWithOptions(ctx, logger, nk, userID, &InventoryLoadOptions{
+		PageSize:     defaultInventoryPageSize,
+		LoadAllPages: true,
+	})
+	if err != nil {
+		logger.Error("Failed to get user inventory: %v", err)
+		return ErrInternal
+	}
+
+	// Instances are taken from in key order, so the same inventory is always debited the same way
+	keys := make([]string, 0, len(userInventory.Items))
+	for key := range userInventory.Items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pending := make(map[string]*InventoryItem)
+	var storageDeletes []*runtime.StorageDelete
+	for itemID, count := range itemIDs {
+		if count <= 0 {
+			continue
+		}
+		keepZero := false
+		if configItem, found := i.config.Items[itemID]; found {
+			keepZero = configItem.KeepZero
+		}
+
+		remaining := count
+		for _, key := range keys {
+			item := userInventory.Items[key]
+			if remaining <= 0 {
+				break
+			}
+			if item.Id != itemID || item.Count <= 0 {
+				continue
+			}
+
+			taken := remaining
+			if taken > item.Count {
+				taken = item.Count
+			}
+			item.Count -= taken
+			item.UpdateTimeSec = time.Now().Unix()
+			remaining -= taken
+
+			storageKey := key
+			if item.InstanceId != "" {
+				storageKey = item.InstanceId
+			}
+			if item.Count <= 0 && !keepZero {
+				storageDeletes = append(storageDeletes, &runtime.StorageDelete{
+					Collection: inventoryStorageCollection,
+					Key:        storageKey,
+					UserID:     userID,
+				})
+			} else {
+				pending[storageKey] = item
+			}
+		}
+		if remaining > 0 {
+			logger.Debug("Insufficient items to debit: %s (missing: %d)", itemID, remaining)
+			return ErrItemsInsufficient
+		}
+	}
+
+	if len(pending) > 0 {
+		storageWrites, err := inventoryItemWrites(userID, pending)
+		if err != nil {
+			logger.Error("Failed to marshal inventory item: %v", err)
+			return ErrInternal
+		}
+		if _, err = nk.StorageWrite(ctx, storageWrites); err != nil {
+			logger.Error("Failed to write inventory updates: %v", err)
+			return ErrInternal
+		}
+	}
+	if len(storageDeletes) > 0 {
+		if err = nk.StorageDelete(ctx, storageDeletes); err != nil {
+			logger.Error("Failed to delete inventory items: %v", err)
+			return ErrInternal
+		}
+	}
+
+	return nil
+}
+
 // GrantItems will add the item(s) to a user's inventory by ID.
 func (i *NakamaInventorySystem) GrantItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, itemIDs map[string]int64, ignoreLimits bool) (updatedInventory *Inventory, newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, err error) {
+	updatedInventory, newItems, updatedItems, notGrantedItemIDs, pending, err := i.prepareGrantItems(ctx, logger, nk, userID, itemIDs, ignoreLimits)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// Write changes to storage if there are any
+	if len(pending) > 0 {
+		storageOps, err := inventoryItemWrites(userID, pending)
+		if err != nil {
+			logger.Error("Failed to marshal inventory item: %v", err)
+			return nil, nil, nil, nil, ErrInternal
+		}
+		if _, err = nk.StorageWrite(ctx, storageOps); err != nil {
+			logger.Error("Failed to update inventory storage: %v", err)
+			return nil, nil, nil, nil, ErrInternal
+		}
+
+		if grantedItems := economyEventItems(itemIDs, notGrantedItemIDs); len(grantedItems) > 0 {
+			logEconomyEvents(ctx, logger, nk, i.pamlogix, &EconomyEvent{
+				Type:     EconomyEventTypeGrant,
+				UserId:   userID,
+				Items:    grantedItems,
+				Metadata: map[string]interface{}{"source": "inventory_grant"},
+			})
+		}
+	}
+
+	return updatedInventory, newItems, updatedItems, notGrantedItemIDs, nil
+}
+
+// prepareGrantItems applies an item grant to the user's inventory in memory, without writing it. It returns the items
+// that must be stored, keyed by storage key, so callers can write them together with other storage objects.
+func (i *NakamaInventorySystem) prepareGrantItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, itemIDs map[string]int64, ignoreLimits bool) (updatedInventory *Inventory, newItems map[string]*InventoryItem, updatedItems map[string]*InventoryItem, notGrantedItemIDs map[string]int64, pending map[string]*InventoryItem, err error) {
 	if i.config == nil || len(i.config.Items) == 0 {
 		// No items are configured
-		return &Inventory{Items: make(map[string]*InventoryItem)}, make(map[string]*InventoryItem), make(map[string]*InventoryItem), make(map[string]int64), nil
+		return &Inventory{Items: make(map[string]*InventoryItem)}, make(map[string]*InventoryItem), make(map[string]*InventoryItem), make(map[string]int64), nil, nil
 	}
 
 	// Initialize return values
@@ -436,7 +579,7 @@ func (i *NakamaInventorySystem) GrantItems(ctx context.Context, logger runtime.L
 
 	// If no valid items, return early without loading inventory
 	if len(validItemIDs) == 0 {
-		return &Inventory{Items: make(map[string]*InventoryItem)}, newItems, updatedItems, notGrantedItemIDs, nil
+		return &Inventory{Items: make(map[string]*InventoryItem)}, newItems, updatedItems, notGrantedItemIDs, nil, nil
 	}
 
 	loadOptions := &InventoryLoadOptions{
@@ -447,11 +590,11 @@ func (i *NakamaInventorySystem) GrantItems(ctx context.Context, logger runtime.L
 	userInventory, err := i.getUserInventoryWithOptions(ctx, logger, nk, userID, loadOptions)
 	if err != nil {
 		logger.Error("Failed to get user inventory: %v", err)
-		return nil, nil, nil, nil, ErrInternal
+		return nil, nil, nil, nil, nil, ErrInternal
 	}
 
 	// Process each valid item to grant
-	var storageOps []*runtime.StorageWrite
+	pending = make(map[string]*InventoryItem)
 	now := time.Now().Unix()
 
 	for itemID, count := range validItemIDs {
@@ -483,14 +626,11 @@ func (i *NakamaInventorySystem) GrantItems(ctx context.Context, logger runtime.L
 				limitExceeded := false
 				for _, setID := range configItem.ItemSets {
 					if setLimit, exists := i.config.Limits.ItemSets[setID]; exists && setLimit > 0 {
-						// Count items in this set
+						// Count items in this set, using the pre-computed set members
 						setCount := int64(0)
 						for _, item := range userInventory.Items {
-							for _, itemSetID := range item.ItemSets {
-								if itemSetID == setID {
-									setCount += item.Count
-									break
-								}
+							if i.config.ItemSets[setID][item.Id] {
+								setCount += item.Count
 							}
 						}
 						if setCount+count > setLimit {
@@ -564,27 +704,12 @@ func (i *NakamaInventorySystem) GrantItems(ctx context.Context, logger runtime.L
 
 			updatedItems[existingKey] = existingItem
 
-			// Prepare storage update
-			itemData, err := json.Marshal(existingItem)
-			if err != nil {
-				logger.Error("Failed to marshal inventory item: %v", err)
-				return nil, nil, nil, nil, ErrInternal
-			}
-
 			// Determine storage key: use instance ID if it exists, otherwise fall back to itemID
 			storageKey := existingKey
 			if existingItem.InstanceId != "" {
 				storageKey = existingItem.InstanceId
 			}
-
-			storageOps = append(storageOps, &runtime.StorageWrite{
-				Collection:      inventoryStorageCollection,
-				Key:             storageKey,
-				UserID:          userID,
-				Value:           string(itemData),
-				PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
-				PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
-			})
+			pending[storageKey] = existingItem
 		} else {
 			// Create new item(s)
 			// For non-stackable items with count > 1, create multiple instances
@@ -613,22 +738,7 @@ func (i *NakamaInventorySystem) GrantItems(ctx context.Context, logger runtime.L
 
 					userInventory.Items[inventoryKey] = newItem
 					newItems[inventoryKey] = newItem
-
-					// Prepare storage update
-					itemData, err := json.Marshal(newItem)
-					if err != nil {
-						logger.Error("Failed to marshal new inventory item: %v", err)
-						return nil, nil, nil, nil, ErrInternal
-					}
-
-					storageOps = append(storageOps, &runtime.StorageWrite{
-						Collection:      inventoryStorageCollection,
-						Key:             storageKey,
-						UserID:          userID,
-						Value:           string(itemData),
-						PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
-						PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
-					})
+					pending[storageKey] = newItem
 				}
 			} else {
 				// Create single item (for stackable items or count = 1)
@@ -655,36 +765,39 @@ func (i *NakamaInventorySystem) GrantItems(ctx context.Context, logger runtime.L
 
 				userInventory.Items[inventoryKey] = newItem
 				newItems[inventoryKey] = newItem
-
-				// Prepare storage update
-				itemData, err := json.Marshal(newItem)
-				if err != nil {
-					logger.Error("Failed to marshal new inventory item: %v", err)
-					return nil, nil, nil, nil, ErrInternal
-				}
-
-				storageOps = append(storageOps, &runtime.StorageWrite{
-					Collection:      inventoryStorageCollection,
-					Key:             storageKey,
-					UserID:          userID,
-					Value:           string(itemData),
-					PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
-					PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
-				})
+				pending[storageKey] = newItem
 			}
 		}
 	}
 
-	// Write changes to storage if there are any
-	if len(storageOps) > 0 {
-		_, err = nk.StorageWrite(ctx, storageOps)
+	return userInventory, newItems, updatedItems, notGrantedItemIDs, pending, nil
+}
+
+// inventoryItemWrites builds the storage writes for inventory items keyed by storage key, in key order.
+func inventoryItemWrites(userID string, items map[string]*InventoryItem) ([]*runtime.StorageWrite, error) {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	writes := make([]*runtime.StorageWrite, 0, len(keys))
+	for _, key := range keys {
+		itemData, err := json.Marshal(items[key])
 		if err != nil {
-			logger.Error("Failed to update inventory storage: %v", err)
-			return nil, nil, nil, nil, ErrInternal
+			return nil, err
 		}
+		writes = append(writes, &runtime.StorageWrite{
+			Collection:      inventoryStorageCollection,
+			Key:             key,
+			UserID:          userID,
+			Value:           string(itemData),
+			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
+		})
 	}
 
-	return userInventory, newItems, updatedItems, notGrantedItemIDs, nil
+	return writes, nil
 }
 
 // UpdateItems will update the properties which are stored on each item by instance ID for a user.
@@ -894,7 +1007,12 @@ func (i *NakamaInventorySystem) getUserInventoryWithOptions(ctx context.Context,
 
 	for hasMore {
 		// Retrieve inventory objects from storage with pagination
-		objects, nextCursor, err := nk.StorageList(ctx, "", userID, inventoryStorageCollection, pageSize, cursor)
+		var objects []*api.StorageObject
+		var nextCursor string
+		err := retryNakama(ctx, idempotentCall, func() (err error) {
+			objects, nextCursor, err = nk.StorageList(ctx, "", userID, inventoryStorageCollection, pageSize, cursor)
+			return err
+		})
 		if err != nil {
 			logger.Error("Failed to read inventory from storage: %v", err)
 			return inventory, err
@@ -993,7 +1111,11 @@ func (i *NakamaInventorySystem) getUserInventorySpecificItems(ctx context.Contex
 	}
 
 	// Perform batch read operation
-	objects, err := nk.StorageRead(ctx, storageReadIDs)
+	var objects []*api.StorageObject
+	err := retryNakama(ctx, idempotentCall, func() (err error) {
+		objects, err = nk.StorageRead(ctx, storageReadIDs)
+		return err
+	})
 	if err != nil {
 		logger.Error("Failed to read specific inventory items from storage: %v", err)
 		return inventory, err
@@ -0,0 +1,259 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	inventoryPropertyIndexName              = "inventory_properties"
+	defaultInventoryPropertyIndexMaxEntries = 1000000
+)
+
+// registerPropertyIndex creates the storage index over item instance properties, if the config enables it.
+func (i *NakamaInventorySystem) registerPropertyIndex(initializer runtime.Initializer) error {
+	if i.config == nil || i.config.PropertyIndex == nil {
+		return nil
+	}
+	maxEntries := i.config.PropertyIndex.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultInventoryPropertyIndexMaxEntries
+	}
+	fields := []string{"id", "string_properties", "numeric_properties"}
+	if err := initializer.RegisterStorageIndex(inventoryPropertyIndexName, inventoryStorageCollection, "", fields, nil, maxEntries, false); err != nil {
+		return err
+	}
+	i.propertyIndex = true
+	return nil
+}
+
+// QueryInventoryItems will return the items in a user's inventory whose properties match all the filters.
+func (i *NakamaInventorySystem) QueryInventoryItems(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, category string, filters []*InventoryPropertyFilter) (*Inventory, error) {
+	for _, filter := range filters {
+		if err := filter.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if len(filters) == 0 || !i.propertyIndex {
+		inventory, err := i.ListInventoryItems(ctx, logger, nk, userID, category)
+		if err != nil {
+			return nil, err
+		}
+		return filterInventory(inventory, filters), nil
+	}
+	if i.config == nil || len(i.config.Items) == 0 {
+		return &Inventory{Items: make(map[string]*InventoryItem)}, nil
+	}
+
+	inventory := &Inventory{Items: make(map[string]*InventoryItem)}
+	query := inventoryIndexQuery(userID, filters)
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageIndexList(ctx, "", inventoryPropertyIndexName, query, maxInventoryPageSize, nil, cursor)
+		if err != nil {
+			logger.Error("Failed to query inventory property index: %v", err)
+			return nil, ErrInternal
+		}
+		for _, object := range objects.GetObjects() {
+			if object.UserId != userID {
+				continue
+			}
+			item := &InventoryItem{}
+			if err := json.Unmarshal([]byte(object.Value), item); err != nil {
+				logger.Error("Failed to unmarshal inventory item: %v", err)
+				continue
+			}
+			if item.Id == "" {
+				item.Id = object.Key
+			}
+			configItem, found := i.config.Items[item.Id]
+			if !found || configItem.Disabled || (category != "" && configItem.Category != category) {
+				continue
+			}
+			item.Name = configItem.Name
+			item.Description = configItem.Description
+			item.Category = configItem.Category
+			item.ItemSets = configItem.ItemSets
+			item.MaxCount = configItem.MaxCount
+			item.Stackable = configItem.Stackable
+			item.Consumable = configItem.Consumable
+
+			key := object.Key
+			if item.InstanceId != "" {
+				key = item.InstanceId
+			}
+			inventory.Items[key] = item
+		}
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	// The index is updated as objects are written, check again so results always reflect the stored properties
+	return filterInventory(inventory, filters), nil
+}
+
+// filterInventory drops the items which don't match all the filters.
+func filterInventory(inventory *Inventory, filters []*InventoryPropertyFilter) *Inventory {
+	if len(filters) == 0 {
+		return inventory
+	}
+	for key, item := range inventory.Items {
+		for _, filter := range filters {
+			if !filter.matches(item) {
+				delete(inventory.Items, key)
+				break
+			}
+		}
+	}
+	return inventory
+}
+
+func (f *InventoryPropertyFilter) validate() error {
+	// Property names go into index queries as field names, so they can't hold query syntax
+	if f == nil || f.Property == "" || strings.ContainsAny(f.Property, " \t\":+-()") {
+		return ErrInventoryQueryInvalid
+	}
+	switch f.Operator {
+	case InventoryQueryOperatorEqual, InventoryQueryOperatorNotEqual:
+	case InventoryQueryOperatorGreater, InventoryQueryOperatorGreaterOrEqual, InventoryQueryOperatorLess, InventoryQueryOperatorLessOrEqual:
+		if !f.Numeric {
+			return runtime.NewError(fmt.Sprintf("inventory query operator %s needs a numeric value", f.Operator), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+	default:
+		return runtime.NewError(fmt.Sprintf("unknown inventory query operator %q", f.Operator), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	return nil
+}
+
+// matches reports whether the item has the property and it compares true. Items without the property never match,
+// even for "!=".
+func (f *InventoryPropertyFilter) matches(item *InventoryItem) bool {
+	if !f.Numeric {
+		value, found := item.StringProperties[f.Property]
+		if !found {
+			return false
+		}
+		if f.Operator == InventoryQueryOperatorNotEqual {
+			return value != f.StringValue
+		}
+		return value == f.StringValue
+	}
+
+	value, found := item.NumericProperties[f.Property]
+	if !found {
+		return false
+	}
+	switch f.Operator {
+	case InventoryQueryOperatorEqual:
+		return value == f.NumericValue
+	case InventoryQueryOperatorNotEqual:
+		return value != f.NumericValue
+	case InventoryQueryOperatorGreater:
+		return value > f.NumericValue
+	case InventoryQueryOperatorGreaterOrEqual:
+		return value >= f.NumericValue
+	case InventoryQueryOperatorLess:
+		return value < f.NumericValue
+	case InventoryQueryOperatorLessOrEqual:
+		return value <= f.NumericValue
+	}
+	return false
+}
+
+// inventoryIndexQuery builds the storage index query for the user's items matching the filters.
+func inventoryIndexQuery(userID string, filters []*InventoryPropertyFilter) string {
+	clauses := []string{"+user_id:" + strconv.Quote(userID)}
+	for _, filter := range filters {
+		if !filter.Numeric {
+			field := "value.string_properties." + filter.Property
+			prefix := "+"
+			if filter.Operator == InventoryQueryOperatorNotEqual {
+				prefix = "-"
+			}
+			clauses = append(clauses, prefix+field+":"+strconv.Quote(filter.StringValue))
+			continue
+		}
+
+		field := "value.numeric_properties." + filter.Property
+		value := strconv.FormatFloat(filter.NumericValue, 'f', -1, 64)
+		switch filter.Operator {
+		case InventoryQueryOperatorEqual:
+			clauses = append(clauses, "+"+field+":>="+value, "+"+field+":<="+value)
+		case InventoryQueryOperatorNotEqual:
+			// Matches are narrowed in memory since the index can't express "has the property and differs"
+			continue
+		default:
+			clauses = append(clauses, "+"+field+":"+string(filter.Operator)+value)
+		}
+	}
+	return strings.Join(clauses, " ")
+}
+
+// ParseInventoryQuery reads filters written as clauses separated by spaces or "&&", e.g. `rarity=="epic" power>50`.
+// Quoted values compare against string properties and bare numbers against numeric ones.
+func ParseInventoryQuery(query string) ([]*InventoryPropertyFilter, error) {
+	filters := make([]*InventoryPropertyFilter, 0)
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, "&&"))
+		if rest == "" {
+			break
+		}
+
+		end := strings.IndexAny(rest, "=!<>")
+		if end <= 0 {
+			return nil, runtime.NewError(fmt.Sprintf("invalid inventory query clause %q", rest), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		filter := &InventoryPropertyFilter{Property: strings.TrimSpace(rest[:end])}
+		rest = rest[end:]
+
+		for _, operator := range []InventoryQueryOperator{
+			InventoryQueryOperatorEqual, InventoryQueryOperatorNotEqual, InventoryQueryOperatorGreaterOrEqual,
+			InventoryQueryOperatorLessOrEqual, InventoryQueryOperatorGreater, InventoryQueryOperatorLess,
+		} {
+			if strings.HasPrefix(rest, string(operator)) {
+				filter.Operator = operator
+				rest = strings.TrimSpace(rest[len(operator):])
+				break
+			}
+		}
+		if filter.Operator == "" {
+			return nil, runtime.NewError(fmt.Sprintf("invalid inventory query operator in %q", rest), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+
+		if strings.HasPrefix(rest, `"`) {
+			closing := strings.Index(rest[1:], `"`)
+			if closing < 0 {
+				return nil, runtime.NewError("unterminated string in inventory query", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+			}
+			filter.StringValue = rest[1 : closing+1]
+			rest = rest[closing+2:]
+		} else {
+			end := strings.IndexAny(rest, " \t&")
+			if end < 0 {
+				end = len(rest)
+			}
+			value, err := strconv.ParseFloat(rest[:end], 64)
+			if err != nil {
+				return nil, runtime.NewError(fmt.Sprintf("inventory query value %q is neither quoted nor a number", rest[:end]), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+			}
+			filter.Numeric = true
+			filter.NumericValue = value
+			rest = rest[end:]
+		}
+
+		if err := filter.validate(); err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+		rest = strings.TrimSpace(rest)
+	}
+	return filters, nil
+}
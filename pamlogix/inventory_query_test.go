@@ -0,0 +1,86 @@
+package pamlogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueryInventorySystem() *NakamaInventorySystem {
+	return NewNakamaInventorySystem(&InventoryConfig{
+		Items: map[string]*InventoryConfigItem{
+			"dragon_blade": {Category: "weapon", StringProperties: map[string]string{"rarity": "epic"}, NumericProperties: map[string]float64{"power": 80}},
+			"iron_sword":   {Category: "weapon", StringProperties: map[string]string{"rarity": "common"}, NumericProperties: map[string]float64{"power": 20}},
+			"phoenix_bow":  {Category: "weapon", StringProperties: map[string]string{"rarity": "epic"}, NumericProperties: map[string]float64{"power": 40}},
+		},
+	})
+}
+
+func TestParseInventoryQuery(t *testing.T) {
+	filters, err := ParseInventoryQuery(`rarity=="epic power" && power>50 level<=3`)
+	require.NoError(t, err)
+	assert.Equal(t, []*InventoryPropertyFilter{
+		{Property: "rarity", Operator: InventoryQueryOperatorEqual, StringValue: "epic power"},
+		{Property: "power", Operator: InventoryQueryOperatorGreater, Numeric: true, NumericValue: 50},
+		{Property: "level", Operator: InventoryQueryOperatorLessOrEqual, Numeric: true, NumericValue: 3},
+	}, filters)
+
+	for _, query := range []string{`rarity>"epic"`, `rarity==epic`, `=="epic"`, `rarity=="epic`, `power~5`} {
+		_, err := ParseInventoryQuery(query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestQueryInventoryItems_FiltersWithoutIndex(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	inventorySystem := newTestQueryInventorySystem()
+
+	_, _, _, _, err := inventorySystem.GrantItems(ctx, logger, nk, "user1", map[string]int64{"dragon_blade": 1, "iron_sword": 1, "phoenix_bow": 1}, false)
+	require.NoError(t, err)
+
+	filters, err := ParseInventoryQuery(`rarity=="epic" power>50`)
+	require.NoError(t, err)
+	inventory, err := inventorySystem.QueryInventoryItems(ctx, logger, nk, "user1", "", filters)
+	require.NoError(t, err)
+	require.Len(t, inventory.Items, 1)
+	for _, item := range inventory.Items {
+		assert.Equal(t, "dragon_blade", item.Id)
+	}
+
+	filters, err = ParseInventoryQuery(`rarity!="common"`)
+	require.NoError(t, err)
+	inventory, err = inventorySystem.QueryInventoryItems(ctx, logger, nk, "user1", "weapon", filters)
+	require.NoError(t, err)
+	assert.Len(t, inventory.Items, 2)
+}
+
+func TestQueryInventoryItems_UsesPropertyIndex(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	inventorySystem := newTestQueryInventorySystem()
+	inventorySystem.propertyIndex = true
+
+	filters, err := ParseInventoryQuery(`rarity=="epic" power>=40`)
+	require.NoError(t, err)
+	query := `+user_id:"user1" +value.string_properties.rarity:"epic" +value.numeric_properties.power:>=40`
+	nk.MockNakamaModule.On("StorageIndexList", ctx, "", inventoryPropertyIndexName, query, maxInventoryPageSize, mock.Anything, "").
+		Return(&api.StorageObjects{Objects: []*api.StorageObject{
+			{Key: "a", UserId: "user1", Value: `{"id":"phoenix_bow","instance_id":"a","string_properties":{"rarity":"epic"},"numeric_properties":{"power":40}}`},
+			// The index may lag behind a write which lowered the power
+			{Key: "b", UserId: "user1", Value: `{"id":"dragon_blade","instance_id":"b","string_properties":{"rarity":"epic"},"numeric_properties":{"power":10}}`},
+		}}, "", nil).Once()
+
+	inventory, err := inventorySystem.QueryInventoryItems(ctx, logger, nk, "user1", "", filters)
+	require.NoError(t, err)
+	require.Contains(t, inventory.Items, "a")
+	assert.Len(t, inventory.Items, 1)
+	assert.Equal(t, "weapon", inventory.Items["a"].Category)
+	nk.MockNakamaModule.AssertExpectations(t)
+}
@@ -0,0 +1,280 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const jobsStorageCollection = "pamlogix_jobs"
+
+// Names of the built-in scheduled jobs, used as keys of the base config's jobs.
+const (
+	JobStorageSweep            = "storage_sweep"
+	JobModifierCompaction      = "modifier_compaction"
+	JobAuctionArchive          = "auction_archive"
+	JobEventLeaderboardCleanup = "event_leaderboard_cleanup"
+	JobEventLeaderboardMerge   = "event_leaderboard_merge"
+	JobSubscriptionCheck       = "subscription_check"
+	JobPurchaseGrantRetry      = "purchase_grant_retry"
+)
+
+// JobFn is the work of a scheduled job. Errors are logged and recorded against the run.
+type JobFn func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error
+
+type scheduledJob struct {
+	name               string
+	defaultIntervalSec int64
+	fn                 JobFn
+}
+
+// jobRun is the stored record of a job's latest run. Its version is what lets only one server claim each run.
+type jobRun struct {
+	ScheduledTimeSec int64  `json:"scheduled_time_sec"`
+	StartTimeSec     int64  `json:"start_time_sec"`
+	EndTimeSec       int64  `json:"end_time_sec,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// jobScheduler runs registered jobs on their interval or cron schedule. Every server schedules every job, and the
+// first to claim each scheduled time runs it.
+type jobScheduler struct {
+	mu      sync.Mutex
+	config  map[string]*BaseSystemConfigJob
+	jobs    map[string]*scheduledJob
+	started bool
+
+	ctx    context.Context
+	logger runtime.Logger
+	nk     runtime.NakamaModule
+}
+
+func newJobScheduler(config map[string]*BaseSystemConfigJob) *jobScheduler {
+	return &jobScheduler{
+		config: config,
+		jobs:   make(map[string]*scheduledJob),
+	}
+}
+
+// register adds a job, starting it straight away if the scheduler already started. A job registered again under the
+// same name is ignored.
+func (s *jobScheduler) register(name string, defaultIntervalSec int64, fn JobFn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.jobs[name]; found {
+		return
+	}
+	job := &scheduledJob{name: name, defaultIntervalSec: defaultIntervalSec, fn: fn}
+	s.jobs[name] = job
+	if s.started {
+		s.startJob(job)
+	}
+}
+
+// start runs the registered jobs, and those registered later, for the life of the server.
+func (s *jobScheduler) start(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.ctx = context.WithoutCancel(ctx)
+	s.logger = logger
+	s.nk = nk
+	s.started = true
+
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.startJob(s.jobs[name])
+	}
+}
+
+// startJob schedules the job in the background. The caller holds s.mu.
+func (s *jobScheduler) startJob(job *scheduledJob) {
+	ctx, nk := s.ctx, s.nk
+	logger := s.logger.WithField("job", job.name)
+	if _, scheduled, err := s.nextRunTimeSec(job, time.Now().Unix()); err != nil {
+		logger.Error("Invalid schedule of job %s: %v", job.name, err)
+		return
+	} else if !scheduled {
+		logger.Debug("Job %s is not scheduled", job.name)
+		return
+	}
+
+	go func() {
+		for {
+			nextTimeSec, _, err := s.nextRunTimeSec(job, time.Now().Unix())
+			if err != nil {
+				logger.Error("Failed to schedule job %s: %v", job.name, err)
+				return
+			}
+			timer := time.NewTimer(time.Until(time.Unix(nextTimeSec, 0)))
+			<-timer.C
+			runScheduledJob(ctx, logger, nk, job, nextTimeSec)
+		}
+	}()
+}
+
+// nextRunTimeSec returns when the job next runs after now, or false if it's disabled or has no schedule. Interval
+// schedules are aligned to multiples of the interval so every server picks the same times.
+func (s *jobScheduler) nextRunTimeSec(job *scheduledJob, now int64) (int64, bool, error) {
+	intervalSec := job.defaultIntervalSec
+	if config := s.config[job.name]; config != nil {
+		if config.Disabled {
+			return 0, false, nil
+		}
+		if config.Cron != "" {
+			nextTimeSec, err := s.nk.CronNext(config.Cron, now)
+			if err != nil {
+				return 0, false, err
+			}
+			return nextTimeSec, true, nil
+		}
+		if config.IntervalSec > 0 {
+			intervalSec = config.IntervalSec
+		}
+	}
+	if intervalSec <= 0 {
+		return 0, false, nil
+	}
+	return (now/intervalSec + 1) * intervalSec, true, nil
+}
+
+// runScheduledJob runs the job for its scheduled time, unless another server already claimed that run.
+func runScheduledJob(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, job *scheduledJob, scheduledTimeSec int64) {
+	run := &jobRun{
+		ScheduledTimeSec: scheduledTimeSec,
+		StartTimeSec:     time.Now().Unix(),
+	}
+	version, claimed, err := claimJobRun(ctx, nk, job.name, run)
+	if err != nil {
+		logger.Error("Failed to claim run of job %s: %v", job.name, err)
+		return
+	}
+	if !claimed {
+		logger.Debug("Run of job %s at %d was claimed by another server", job.name, scheduledTimeSec)
+		return
+	}
+
+	start := time.Now()
+	if err := job.fn(ctx, logger, nk); err != nil {
+		logger.Error("Job %s failed: %v", job.name, err)
+		run.Error = err.Error()
+	} else {
+		logger.Info("Ran job %s in %v", job.name, time.Since(start))
+	}
+	run.EndTimeSec = time.Now().Unix()
+	if _, err := writeJobRun(ctx, nk, job.name, run, version); err != nil {
+		logger.Warn("Failed to record run of job %s: %v", job.name, err)
+	}
+}
+
+// claimJobRun records the run as the job's latest, provided no server has claimed the same or a later scheduled time.
+// It returns the version of the stored run.
+func claimJobRun(ctx context.Context, nk runtime.NakamaModule, name string, run *jobRun) (string, bool, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{
+		Collection: jobsStorageCollection,
+		Key:        name,
+	}})
+	if err != nil {
+		return "", false, err
+	}
+
+	version := storageLockVersionNone
+	if len(objects) > 0 {
+		latest := &jobRun{}
+		if err := json.Unmarshal([]byte(objects[0].Value), latest); err != nil {
+			return "", false, err
+		}
+		if latest.ScheduledTimeSec >= run.ScheduledTimeSec {
+			return "", false, nil
+		}
+		version = objects[0].Version
+	}
+
+	// A rejected write means another server claimed the run first
+	newVersion, err := writeJobRun(ctx, nk, name, run, version)
+	if err != nil {
+		return "", false, nil
+	}
+	return newVersion, true, nil
+}
+
+func writeJobRun(ctx context.Context, nk runtime.NakamaModule, name string, run *jobRun, version string) (string, error) {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return "", err
+	}
+	acks, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{{
+		Collection:      jobsStorageCollection,
+		Key:             name,
+		Value:           string(data),
+		Version:         version,
+		PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+		PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+	}})
+	if err != nil {
+		return "", err
+	}
+	if len(acks) == 0 {
+		return "", nil
+	}
+	return acks[0].Version, nil
+}
+
+// registerBuiltinJobs registers the jobs of the loaded systems. The storage sweep runs every StorageSweepIntervalSec,
+// the subscription check every CheckIntervalSec of the economy's subscriptions, the purchase grant retry every five
+// minutes, the auction archive every hour and the cohort merge every minute while any event merges cohorts by default,
+// and the rest only once the base config schedules them.
+func (p *pamlogixImpl) registerBuiltinJobs(baseConfig *BaseSystemConfig) {
+	var storageSweepIntervalSec int64
+	if baseConfig != nil {
+		storageSweepIntervalSec = baseConfig.StorageSweepIntervalSec
+	}
+	p.RegisterJob(JobStorageSweep, storageSweepIntervalSec, func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error {
+		_, err := p.sweepStorage(ctx, logger, nk)
+		return err
+	})
+	if economySystem := p.GetEconomySystem(); economySystem != nil {
+		p.RegisterJob(JobModifierCompaction, 0, func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error {
+			_, err := economySystem.CompactModifiers(ctx, logger, nk)
+			return err
+		})
+		economyConfig, _ := economySystem.GetConfig().(*EconomyConfig)
+		p.RegisterJob(JobSubscriptionCheck, subscriptionCheckIntervalSec(economyConfig), func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error {
+			_, err := economySystem.SubscriptionsCheck(ctx, logger, nk)
+			return err
+		})
+		p.RegisterJob(JobPurchaseGrantRetry, defaultPurchaseGrantRetryIntervalSec, func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error {
+			_, err := economySystem.PurchaseGrantsRetry(ctx, logger, nk)
+			return err
+		})
+	}
+	if auctionsSystem := p.GetAuctionsSystem(); auctionsSystem != nil {
+		p.RegisterJob(JobAuctionArchive, defaultAuctionArchiveIntervalSec, func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error {
+			_, err := auctionsSystem.ArchiveSettled(ctx, logger, nk)
+			return err
+		})
+	}
+	if eventLeaderboardsSystem := p.GetEventLeaderboardsSystem(); eventLeaderboardsSystem != nil {
+		p.RegisterJob(JobEventLeaderboardCleanup, 0, eventLeaderboardsSystem.CleanupEventLeaderboards)
+		eventLeaderboardsConfig, _ := eventLeaderboardsSystem.GetConfig().(*EventLeaderboardsConfig)
+		p.RegisterJob(JobEventLeaderboardMerge, cohortMergeIntervalSec(eventLeaderboardsConfig), eventLeaderboardsSystem.MergeEventLeaderboardCohorts)
+	}
+}
+
+// RegisterJob adds a job run on a schedule across the cluster.
+func (p *pamlogixImpl) RegisterJob(name string, defaultIntervalSec int64, fn JobFn) {
+	if p.jobs == nil {
+		p.jobs = newJobScheduler(nil)
+	}
+	p.jobs.register(name, defaultIntervalSec, fn)
+}
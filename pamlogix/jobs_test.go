@@ -0,0 +1,98 @@
+package pamlogix
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobSchedulerNextRunTimeSec(t *testing.T) {
+	nk := NewMockNakama(t)
+	scheduler := newJobScheduler(map[string]*BaseSystemConfigJob{
+		"tuned":    {IntervalSec: 600},
+		"disabled": {Disabled: true, IntervalSec: 600},
+		"nightly":  {Cron: "0 4 * * *"},
+	})
+	scheduler.nk = nk
+	now := int64(1_700_000_123)
+
+	// Intervals are aligned so every server picks the same times
+	next, scheduled, err := scheduler.nextRunTimeSec(&scheduledJob{name: "default", defaultIntervalSec: 3600}, now)
+	require.NoError(t, err)
+	assert.True(t, scheduled)
+	assert.Equal(t, int64(1_700_002_800), next)
+
+	next, scheduled, err = scheduler.nextRunTimeSec(&scheduledJob{name: "tuned", defaultIntervalSec: 3600}, now)
+	require.NoError(t, err)
+	assert.True(t, scheduled)
+	assert.Equal(t, int64(1_700_000_400), next)
+
+	_, scheduled, err = scheduler.nextRunTimeSec(&scheduledJob{name: "disabled", defaultIntervalSec: 3600}, now)
+	require.NoError(t, err)
+	assert.False(t, scheduled)
+
+	_, scheduled, err = scheduler.nextRunTimeSec(&scheduledJob{name: "unscheduled"}, now)
+	require.NoError(t, err)
+	assert.False(t, scheduled)
+
+	nk.On("CronNext", "0 4 * * *", now).Return(int64(1_700_020_800), nil)
+	next, scheduled, err = scheduler.nextRunTimeSec(&scheduledJob{name: "nightly"}, now)
+	require.NoError(t, err)
+	assert.True(t, scheduled)
+	assert.Equal(t, int64(1_700_020_800), next)
+}
+
+func TestRunScheduledJob(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+
+	runs := 0
+	job := &scheduledJob{name: "cleanup", fn: func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error {
+		runs++
+		if runs > 1 {
+			return errors.New("cleanup failed")
+		}
+		return nil
+	}}
+
+	// Each scheduled time is run once, however many servers reach it
+	runScheduledJob(ctx, logger, nk, job, 1000)
+	runScheduledJob(ctx, logger, nk, job, 1000)
+	assert.Equal(t, 1, runs)
+
+	// Failures are recorded against the run
+	runScheduledJob(ctx, logger, nk, job, 2000)
+	assert.Equal(t, 2, runs)
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: jobsStorageCollection, Key: "cleanup"}})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Contains(t, objects[0].Value, `"scheduled_time_sec":2000`)
+	assert.Contains(t, objects[0].Value, `"error":"cleanup failed"`)
+
+	// An earlier time than the last run is never claimed
+	runScheduledJob(ctx, logger, nk, job, 1500)
+	assert.Equal(t, 2, runs)
+}
+
+func TestRegisterBuiltinJobs(t *testing.T) {
+	p := newBenchPamlogix()
+	p.registerBuiltinJobs(&BaseSystemConfig{StorageSweepIntervalSec: 300})
+
+	assert.ElementsMatch(t, []string{JobStorageSweep, JobModifierCompaction, JobSubscriptionCheck, JobPurchaseGrantRetry, JobAuctionArchive}, slices.Collect(maps.Keys(p.jobs.jobs)))
+	assert.Equal(t, int64(300), p.jobs.jobs[JobStorageSweep].defaultIntervalSec)
+	assert.Zero(t, p.jobs.jobs[JobModifierCompaction].defaultIntervalSec)
+	assert.Equal(t, int64(defaultPurchaseGrantRetryIntervalSec), p.jobs.jobs[JobPurchaseGrantRetry].defaultIntervalSec)
+	assert.Equal(t, int64(defaultAuctionArchiveIntervalSec), p.jobs.jobs[JobAuctionArchive].defaultIntervalSec)
+	// Subscriptions are only checked by default once some are configured
+	assert.Zero(t, p.jobs.jobs[JobSubscriptionCheck].defaultIntervalSec)
+	assert.Equal(t, int64(defaultSubscriptionCheckIntervalSec), subscriptionCheckIntervalSec(&EconomyConfig{
+		Subscriptions: &EconomyConfigSubscriptions{Items: map[string]*EconomyConfigSubscription{"monthly_pass": {}}},
+	}))
+}
@@ -0,0 +1,327 @@
+package pamlogix
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// JsonNaming selects the casing of field names in the payloads of the JSON RPCs.
+type JsonNaming int
+
+const (
+	// JsonNamingSnakeCase uses the field names of the proto definitions, e.g. "store_items". It's the default.
+	JsonNamingSnakeCase JsonNaming = iota
+	// JsonNamingCamelCase uses the lowerCamelCase names protojson and most client SDKs use, e.g. "storeItems".
+	JsonNamingCamelCase
+)
+
+// JsonPolicy controls how the JSON RPCs encode their responses. Requests are accepted in either casing whatever the
+// policy, so clients can move between casings without a coordinated release.
+type JsonPolicy struct {
+	// Naming is the casing of field names in responses.
+	Naming JsonNaming
+	// EmitUnpopulated writes fields which hold their zero value, like the protojson option of the same name, so clients
+	// always see every field of a response. Lists are written as [] and maps as {}.
+	EmitUnpopulated bool
+	// FieldNames renames individual fields in responses, and accepts the new name in requests, keyed by the snake_case
+	// name of the field, e.g. {"user_id": "userID"}. It takes precedence over Naming.
+	FieldNames map[string]string
+}
+
+// isDefault reports whether the policy encodes exactly what encoding/json does with the struct tags.
+func (p *JsonPolicy) isDefault() bool {
+	return p == nil || (p.Naming == JsonNamingSnakeCase && !p.EmitUnpopulated && len(p.FieldNames) == 0)
+}
+
+// fieldName returns the name the policy writes for a field given its snake_case name.
+func (p *JsonPolicy) fieldName(name string) string {
+	if rename, found := p.FieldNames[name]; found {
+		return rename
+	}
+	if p.Naming == JsonNamingCamelCase {
+		return snakeToCamel(name)
+	}
+	return name
+}
+
+// marshalRpcJson encodes a JSON RPC response with the JSON policy set on Pamlogix.
+func marshalRpcJson(pl Pamlogix, v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	policy := jsonPolicyOf(pl)
+	if policy.isDefault() {
+		return data, nil
+	}
+
+	value, err := decodeGenericJson(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(policy.encodeValue(value, reflect.TypeOf(v)))
+}
+
+// unmarshalRpcJson decodes a JSON RPC request, accepting both snake_case and camelCase field names as well as the
+// names set in the policy.
+func unmarshalRpcJson(pl Pamlogix, payload string, v any) error {
+	value, err := decodeGenericJson([]byte(payload))
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(jsonPolicyOf(pl).decodeValue(value, reflect.TypeOf(v)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func jsonPolicyOf(pl Pamlogix) *JsonPolicy {
+	if holder, ok := pl.(interface{ getJsonPolicy() *JsonPolicy }); ok {
+		return holder.getJsonPolicy()
+	}
+	return nil
+}
+
+// decodeGenericJson decodes into maps and slices, keeping numbers as written so large int64 values aren't rounded.
+func decodeGenericJson(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// encodeValue renames the fields of the structs in a decoded value of the given type, and fills in unpopulated fields.
+// Keys of maps, such as item or currency IDs, are left as they are.
+func (p *JsonPolicy) encodeValue(value any, t reflect.Type) any {
+	t = derefType(t)
+	if t == nil || t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return value
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		encoded := make(map[string]any, len(object))
+		known := make(map[string]bool)
+		for _, field := range jsonFieldsOf(t) {
+			known[field.name] = true
+			if fieldValue, found := object[field.name]; found {
+				encoded[p.fieldName(field.name)] = p.encodeValue(fieldValue, field.typ)
+			} else if p.EmitUnpopulated {
+				encoded[p.fieldName(field.name)] = p.zeroValue(field.typ)
+			}
+		}
+		for key, fieldValue := range object {
+			if !known[key] {
+				encoded[key] = fieldValue
+			}
+		}
+		return encoded
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		for key, entry := range object {
+			object[key] = p.encodeValue(entry, t.Elem())
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		list, ok := value.([]any)
+		if !ok {
+			return value
+		}
+		for i, entry := range list {
+			list[i] = p.encodeValue(entry, t.Elem())
+		}
+		return list
+	}
+	return value
+}
+
+// zeroValue returns how an unpopulated field of the given type is written.
+func (p *JsonPolicy) zeroValue(t reflect.Type) any {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return ""
+		}
+		return []any{}
+	case reflect.Map:
+		return map[string]any{}
+	case reflect.Struct:
+		return p.encodeValue(map[string]any{}, t)
+	}
+	data, err := json.Marshal(reflect.Zero(t).Interface())
+	if err != nil {
+		return nil
+	}
+	value, err := decodeGenericJson(data)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// decodeValue maps the field names of a request onto the names in the struct tags of the given type. A field sent
+// under its exact name wins over one sent under another casing.
+func (p *JsonPolicy) decodeValue(value any, t reflect.Type) any {
+	t = derefType(t)
+	if t == nil || t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return value
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		fields := jsonFieldsOf(t)
+		aliases := make(map[string]jsonField, len(fields)*2)
+		for _, field := range fields {
+			aliases[snakeToCamel(field.name)] = field
+			if p != nil {
+				if rename, found := p.FieldNames[field.name]; found {
+					aliases[rename] = field
+				}
+			}
+		}
+		decoded := make(map[string]any, len(object))
+		for _, field := range fields {
+			if fieldValue, found := object[field.name]; found {
+				decoded[field.name] = p.decodeValue(fieldValue, field.typ)
+			}
+		}
+		for key, fieldValue := range object {
+			field, found := aliases[key]
+			if !found {
+				if _, taken := decoded[key]; !taken {
+					decoded[key] = fieldValue
+				}
+				continue
+			}
+			if _, taken := decoded[field.name]; !taken {
+				decoded[field.name] = p.decodeValue(fieldValue, field.typ)
+			}
+		}
+		return decoded
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+		for key, entry := range object {
+			object[key] = p.decodeValue(entry, t.Elem())
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		list, ok := value.([]any)
+		if !ok {
+			return value
+		}
+		for i, entry := range list {
+			list[i] = p.decodeValue(entry, t.Elem())
+		}
+		return list
+	}
+	return value
+}
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	jsonFieldsCache     sync.Map
+)
+
+// jsonField is a field as encoding/json sees it.
+type jsonField struct {
+	name string
+	typ  reflect.Type
+}
+
+// jsonFieldsOf lists the fields encoding/json writes for a struct, including those promoted from embedded structs.
+func jsonFieldsOf(t reflect.Type) []jsonField {
+	if cached, found := jsonFieldsCache.Load(t); found {
+		return cached.([]jsonField)
+	}
+
+	fields := make([]jsonField, 0, t.NumField())
+	var promoted []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tag := structField.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if structField.Anonymous && name == "" {
+			if embedded := derefType(structField.Type); embedded.Kind() == reflect.Struct {
+				promoted = append(promoted, jsonFieldsOf(embedded)...)
+				continue
+			}
+		}
+		if !structField.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+		fields = append(fields, jsonField{name: name, typ: structField.Type})
+	}
+	// Like encoding/json, a field of the struct itself hides a promoted field with the same name
+	declared := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		declared[field.name] = true
+	}
+	for _, field := range promoted {
+		if !declared[field.name] {
+			fields = append(fields, field)
+		}
+	}
+
+	jsonFieldsCache.Store(t, fields)
+	return fields
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// snakeToCamel converts a snake_case name to lowerCamelCase the way protoc derives json_name, e.g. "store_items" to
+// "storeItems".
+func snakeToCamel(name string) string {
+	if !strings.Contains(name, "_") {
+		return name
+	}
+	var builder strings.Builder
+	builder.Grow(len(name))
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,53 @@
+package pamlogix
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonPolicy_Marshal(t *testing.T) {
+	gifts := &EnergyGiftList{Gifts: []*EnergyGift{{Id: "gift1", EnergyId: "lives", Amount: 1}}}
+
+	// Without a policy responses are exactly what the struct tags produce
+	p := &pamlogixImpl{}
+	data, err := marshalRpcJson(p, gifts)
+	require.NoError(t, err)
+	expected, err := json.Marshal(gifts)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(data))
+
+	p.SetJsonPolicy(&JsonPolicy{Naming: JsonNamingCamelCase, EmitUnpopulated: true, FieldNames: map[string]string{"sender_id": "from"}})
+	data, err = marshalRpcJson(p, gifts)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"gifts":[{"id":"gift1","energyId":"lives","from":"","amount":1,"createTimeSec":0,"expireTimeSec":0}]}`, string(data))
+
+	// Map keys are IDs, not field names, and keep their casing
+	data, err = marshalRpcJson(p, &EconomyDonationClaimRequestDetails{Donors: map[string]int64{"user_one": 2}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"donors":{"user_one":2}}`, string(data))
+}
+
+func TestJsonPolicy_UnmarshalEitherCasing(t *testing.T) {
+	p := &pamlogixImpl{}
+	p.SetJsonPolicy(&JsonPolicy{FieldNames: map[string]string{"recipient_id": "to"}})
+
+	for _, payload := range []string{
+		`{"recipient_id":"user2","energy_id":"lives"}`,
+		`{"recipientId":"user2","energyId":"lives"}`,
+		`{"to":"user2","energyId":"lives"}`,
+	} {
+		request := &EnergyGiftSendRequest{}
+		require.NoError(t, unmarshalRpcJson(p, payload, request), payload)
+		assert.Equal(t, &EnergyGiftSendRequest{RecipientId: "user2", EnergyId: "lives"}, request, payload)
+	}
+
+	// The exact field name wins when a client sends both
+	request := &EnergyGiftSendRequest{}
+	require.NoError(t, unmarshalRpcJson(p, `{"recipientId":"other","recipient_id":"user2"}`, request))
+	assert.Equal(t, "user2", request.RecipientId)
+
+	assert.Error(t, unmarshalRpcJson(p, ``, request))
+}
@@ -8,9 +8,18 @@ import (
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
+var (
+	ErrTournamentNotFound      = runtime.NewError("tournament not found", NOT_FOUND_ERROR_CODE)                      // NOT_FOUND
+	ErrTournamentNotActive     = runtime.NewError("tournament not active", FAILED_PRECONDITION_ERROR_CODE)           // FAILED_PRECONDITION
+	ErrTournamentAlreadyJoined = runtime.NewError("tournament already joined", FAILED_PRECONDITION_ERROR_CODE)       // FAILED_PRECONDITION
+	ErrTournamentNoTeam        = runtime.NewError("team tournament requires a team", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+)
+
 // LeaderboardsConfig is the data definition for the LeaderboardsSystem type.
 type LeaderboardsConfig struct {
 	Leaderboards []*LeaderboardsConfigLeaderboard `json:"leaderboards,omitempty"`
+	// Tournaments are created as Nakama tournaments when the system loads, keyed by tournament ID.
+	Tournaments map[string]*LeaderboardsConfigTournament `json:"tournaments,omitempty"`
 }
 
 type LeaderboardsConfigLeaderboard struct {
@@ -22,10 +31,104 @@ type LeaderboardsConfigLeaderboard struct {
 	Regions       []string `json:"regions,omitempty"`
 }
 
+// LeaderboardsConfigTournament is a tournament users or their teams register for, paying an entry cost, and which
+// rewards entrants by their final placement. A reset schedule runs it as recurring seasons.
+type LeaderboardsConfigTournament struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Category    int    `json:"category,omitempty"`
+	// SortOrder is "desc" or "asc", and Operator is "best", "set", "incr" or "decr", as for Nakama tournaments.
+	SortOrder string `json:"sort_order,omitempty"`
+	Operator  string `json:"operator,omitempty"`
+	// ResetSchedule is a CRON expression starting each season, which lasts DurationSec.
+	ResetSchedule string `json:"reset_schedule,omitempty"`
+	DurationSec   int    `json:"duration_sec,omitempty"`
+	StartTimeSec  int    `json:"start_time_sec,omitempty"`
+	EndTimeSec    int    `json:"end_time_sec,omitempty"`
+	MaxSize       int    `json:"max_size,omitempty"`
+	MaxNumScore   int    `json:"max_num_score,omitempty"`
+	// Authoritative tournaments only take scores from the server. Team tournaments always are.
+	Authoritative bool `json:"authoritative,omitempty"`
+	// Team tournaments are entered by a user on behalf of their team, and every team member is rewarded.
+	Team      bool                                  `json:"team,omitempty"`
+	EntryCost *Cost                                 `json:"entry_cost,omitempty"`
+	Rewards   []*LeaderboardsConfigTournamentReward `json:"rewards,omitempty"`
+}
+
+// LeaderboardsConfigTournamentReward is the reward for entrants placing from RankMin to RankMax, inclusive.
+type LeaderboardsConfigTournamentReward struct {
+	RankMin int64                `json:"rank_min,omitempty"`
+	RankMax int64                `json:"rank_max,omitempty"`
+	Reward  *EconomyConfigReward `json:"reward,omitempty"`
+}
+
+// Tournament is a configured tournament with the state of its current season.
+type Tournament struct {
+	Id             string                                `json:"id"`
+	Name           string                                `json:"name,omitempty"`
+	Description    string                                `json:"description,omitempty"`
+	Category       int                                   `json:"category,omitempty"`
+	Team           bool                                  `json:"team,omitempty"`
+	EntryCost      *Cost                                 `json:"entry_cost,omitempty"`
+	Rewards        []*LeaderboardsConfigTournamentReward `json:"rewards,omitempty"`
+	Size           int64                                 `json:"size"`
+	MaxSize        int64                                 `json:"max_size,omitempty"`
+	CanEnter       bool                                  `json:"can_enter"`
+	StartActiveSec int64                                 `json:"start_active_sec,omitempty"`
+	EndActiveSec   int64                                 `json:"end_active_sec,omitempty"`
+	NextResetSec   int64                                 `json:"next_reset_sec,omitempty"`
+}
+
+// TournamentList is the list of configured tournaments.
+type TournamentList struct {
+	Tournaments []*Tournament `json:"tournaments"`
+}
+
+// TournamentJoinRequest is the request payload to register the user, or their team, for a tournament.
+type TournamentJoinRequest struct {
+	Id string `json:"id"`
+}
+
+// TournamentStandingsRequest is the request payload to list a tournament's standings.
+type TournamentStandingsRequest struct {
+	Id     string `json:"id"`
+	Limit  int    `json:"limit,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// TournamentStandings is a page of a tournament's current standings. OwnerRecord is the caller's own record, or their
+// team's in team tournaments, when they have entered.
+type TournamentStandings struct {
+	Tournament  *Tournament              `json:"tournament"`
+	Records     []*api.LeaderboardRecord `json:"records"`
+	OwnerRecord *api.LeaderboardRecord   `json:"owner_record,omitempty"`
+	NextCursor  string                   `json:"next_cursor,omitempty"`
+	PrevCursor  string                   `json:"prev_cursor,omitempty"`
+}
+
 // The LeaderboardsSystem defines a collection of leaderboards which can be defined as global or regional with Nakama
-// server.
+// server, and the tournaments run on them.
 type LeaderboardsSystem interface {
 	System
+
+	// ListTournaments returns every configured tournament with the state of its current season.
+	ListTournaments(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*TournamentList, error)
+
+	// JoinTournament registers the user for a tournament, or their team for a team tournament, charging the entry cost.
+	JoinTournament(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, username, tournamentID string) (*Tournament, error)
+
+	// GetTournamentStandings returns a page of a tournament's standings along with the user's or their team's record.
+	GetTournamentStandings(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, tournamentID string, limit int, cursor string) (*TournamentStandings, error)
+
+	// WriteTournamentScore submits a score for the user, or for their team in a team tournament, which must have entered.
+	WriteTournamentScore(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, username, tournamentID string, score, subscore int64, metadata map[string]interface{}) (*api.LeaderboardRecord, error)
+
+	// ProcessTournamentEnd rewards the entrants of a season that has ended by their placement. It is registered as
+	// the Nakama tournament end hook.
+	ProcessTournamentEnd(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, tournament *api.Tournament, end, reset int64) error
+
+	// SetOnTournamentReward sets a custom reward function which will run after a tournament placement reward is rolled.
+	SetOnTournamentReward(fn OnReward[*LeaderboardsConfigTournament])
 }
 
 // ValidateWriteScoreFn is a function used to validate the leaderboard score input.
@@ -0,0 +1,384 @@
+package pamlogix
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	defaultTournamentSortOrder = "desc"
+	defaultTournamentOperator  = "best"
+
+	tournamentRewardPageSize = 100
+	teamMembersPageSize      = 100
+)
+
+// NakamaLeaderboardsSystem implements the LeaderboardsSystem interface using Nakama as the backend.
+type NakamaLeaderboardsSystem struct {
+	config             *LeaderboardsConfig
+	onTournamentReward OnReward[*LeaderboardsConfigTournament]
+	pamlogix           Pamlogix
+}
+
+// NewNakamaLeaderboardsSystem creates a new instance of the leaderboards system with the given configuration.
+func NewNakamaLeaderboardsSystem(config *LeaderboardsConfig) *NakamaLeaderboardsSystem {
+	return &NakamaLeaderboardsSystem{
+		config: config,
+	}
+}
+
+// SetPamlogix sets the Pamlogix instance for this leaderboards system
+func (l *NakamaLeaderboardsSystem) SetPamlogix(pl Pamlogix) {
+	l.pamlogix = pl
+}
+
+// GetType returns the system type for the leaderboards system.
+func (l *NakamaLeaderboardsSystem) GetType() SystemType {
+	return SystemTypeLeaderboards
+}
+
+// GetConfig returns the configuration for the leaderboards system.
+func (l *NakamaLeaderboardsSystem) GetConfig() any {
+	return l.config
+}
+
+// CreateTournaments creates the Nakama tournament behind each configured tournament. Tournaments which already exist
+// are left as they are.
+func (l *NakamaLeaderboardsSystem) CreateTournaments(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) error {
+	for tournamentID, config := range l.config.Tournaments {
+		sortOrder := config.SortOrder
+		if sortOrder == "" {
+			sortOrder = defaultTournamentSortOrder
+		}
+		operator := config.Operator
+		if operator == "" {
+			operator = defaultTournamentOperator
+		}
+
+		// Only the server can write a team's score, and every entrant must have joined to pay the entry cost
+		authoritative := config.Authoritative || config.Team
+		if err := nk.TournamentCreate(ctx, tournamentID, authoritative, sortOrder, operator, config.ResetSchedule, nil,
+			config.Name, config.Description, config.Category, config.StartTimeSec, config.EndTimeSec, config.DurationSec,
+			config.MaxSize, config.MaxNumScore, true, true); err != nil {
+			logger.Error("Failed to create tournament %s: %v", tournamentID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// ListTournaments returns every configured tournament with the state of its current season.
+func (l *NakamaLeaderboardsSystem) ListTournaments(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (*TournamentList, error) {
+	tournamentIDs := make([]string, 0, len(l.config.Tournaments))
+	for tournamentID := range l.config.Tournaments {
+		tournamentIDs = append(tournamentIDs, tournamentID)
+	}
+	sort.Strings(tournamentIDs)
+
+	list := &TournamentList{Tournaments: make([]*Tournament, 0, len(tournamentIDs))}
+	if len(tournamentIDs) == 0 {
+		return list, nil
+	}
+
+	tournaments, err := nk.TournamentsGetId(ctx, tournamentIDs)
+	if err != nil {
+		logger.Error("Failed to get tournaments: %v", err)
+		return nil, ErrInternal
+	}
+	byID := make(map[string]*api.Tournament, len(tournaments))
+	for _, tournament := range tournaments {
+		byID[tournament.Id] = tournament
+	}
+
+	for _, tournamentID := range tournamentIDs {
+		tournament, found := byID[tournamentID]
+		if !found {
+			logger.Warn("Configured tournament %s does not exist", tournamentID)
+			continue
+		}
+		list.Tournaments = append(list.Tournaments, newTournament(l.config.Tournaments[tournamentID], tournament))
+	}
+
+	return list, nil
+}
+
+// JoinTournament registers the user for a tournament, or their team for a team tournament, charging the entry cost.
+func (l *NakamaLeaderboardsSystem) JoinTournament(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, username, tournamentID string) (*Tournament, error) {
+	config, tournament, err := l.getTournament(ctx, logger, nk, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if !tournament.CanEnter {
+		return nil, ErrTournamentNotActive
+	}
+
+	ownerID, ownerName, err := l.tournamentOwner(ctx, logger, nk, config, userID, username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nakama ignores repeated joins, so check first to never charge the entry cost twice
+	_, ownerRecords, _, _, err := nk.TournamentRecordsList(ctx, tournamentID, []string{ownerID}, 1, "", 0)
+	if err != nil {
+		logger.Error("Failed to read tournament %s record of %s: %v", tournamentID, ownerID, err)
+		return nil, ErrInternal
+	}
+	if len(ownerRecords) > 0 {
+		return nil, ErrTournamentAlreadyJoined
+	}
+
+	entryMetadata := map[string]interface{}{
+		"source":        "tournament_entry",
+		"tournament_id": tournamentID,
+	}
+	if err := chargeCost(ctx, logger, nk, l.pamlogix, userID, config.EntryCost, entryMetadata, false); err != nil {
+		return nil, err
+	}
+
+	if err := nk.TournamentJoin(ctx, tournamentID, ownerID, ownerName); err != nil {
+		logger.Error("Failed to join tournament %s as %s: %v", tournamentID, ownerID, err)
+		_ = refundCost(ctx, logger, nk, l.pamlogix, userID, config.EntryCost, entryMetadata)
+		return nil, ErrInternal
+	}
+
+	tournament.Size++
+	return newTournament(config, tournament), nil
+}
+
+// GetTournamentStandings returns a page of a tournament's standings along with the user's or their team's record.
+func (l *NakamaLeaderboardsSystem) GetTournamentStandings(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, tournamentID string, limit int, cursor string) (*TournamentStandings, error) {
+	config, tournament, err := l.getTournament(ctx, logger, nk, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ownerIDs []string
+	ownerID, _, err := l.tournamentOwner(ctx, logger, nk, config, userID, "")
+	switch {
+	case err == nil:
+		ownerIDs = []string{ownerID}
+	case err != ErrTournamentNoTeam:
+		return nil, err
+	}
+
+	// Leaderboard record cursors page both ways already, so they're only wrapped
+	recordsCursor, _, err := decodeNakamaCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	records, ownerRecords, prevCursor, nextCursor, err := nk.TournamentRecordsList(ctx, tournamentID, ownerIDs, limit, recordsCursor, 0)
+	if err != nil {
+		logger.Error("Failed to list tournament %s records: %v", tournamentID, err)
+		return nil, ErrInternal
+	}
+
+	standings := &TournamentStandings{
+		Tournament: newTournament(config, tournament),
+		Records:    records,
+		NextCursor: wrapNakamaCursor(nextCursor),
+		PrevCursor: wrapNakamaCursor(prevCursor),
+	}
+	if len(ownerRecords) > 0 {
+		standings.OwnerRecord = ownerRecords[0]
+	}
+	return standings, nil
+}
+
+// WriteTournamentScore submits a score for the user, or for their team in a team tournament, which must have entered.
+func (l *NakamaLeaderboardsSystem) WriteTournamentScore(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, username, tournamentID string, score, subscore int64, metadata map[string]interface{}) (*api.LeaderboardRecord, error) {
+	config, found := l.config.Tournaments[tournamentID]
+	if !found {
+		return nil, ErrTournamentNotFound
+	}
+
+	ownerID, ownerName, err := l.tournamentOwner(ctx, logger, nk, config, userID, username)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := nk.TournamentRecordWrite(ctx, tournamentID, ownerID, ownerName, score, subscore, metadata, nil)
+	if err != nil {
+		logger.Error("Failed to write tournament %s score for %s: %v", tournamentID, ownerID, err)
+		return nil, err
+	}
+	return record, nil
+}
+
+// ProcessTournamentEnd rewards the entrants of a season that has ended by their placement. In team tournaments every
+// member of a placing team is rewarded.
+func (l *NakamaLeaderboardsSystem) ProcessTournamentEnd(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, tournament *api.Tournament, end, reset int64) error {
+	config, found := l.config.Tournaments[tournament.Id]
+	if !found || len(config.Rewards) == 0 {
+		return nil
+	}
+
+	var lastRank int64
+	for _, reward := range config.Rewards {
+		lastRank = max(lastRank, reward.RankMax)
+	}
+
+	// Records of the season that just ended expire at the next reset
+	cursor := ""
+	for {
+		records, _, _, nextCursor, err := nk.TournamentRecordsList(ctx, tournament.Id, nil, tournamentRewardPageSize, cursor, reset)
+		if err != nil {
+			logger.Error("Failed to list tournament %s records: %v", tournament.Id, err)
+			return err
+		}
+
+		for _, record := range records {
+			if record.Rank > lastRank {
+				return nil
+			}
+			reward := tournamentReward(config, record.Rank)
+			if reward == nil {
+				continue
+			}
+
+			userIDs := []string{record.OwnerId}
+			if config.Team {
+				if userIDs, err = teamMemberIDs(ctx, nk, record.OwnerId); err != nil {
+					logger.Error("Failed to list members of team %s: %v", record.OwnerId, err)
+					continue
+				}
+			}
+			for _, userID := range userIDs {
+				l.grantTournamentReward(ctx, logger, nk, userID, tournament.Id, config, record.Rank, reward)
+			}
+		}
+
+		if nextCursor == "" {
+			return nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// SetOnTournamentReward sets a custom reward function which will run after a tournament placement reward is rolled.
+func (l *NakamaLeaderboardsSystem) SetOnTournamentReward(fn OnReward[*LeaderboardsConfigTournament]) {
+	l.onTournamentReward = fn
+}
+
+func (l *NakamaLeaderboardsSystem) grantTournamentReward(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, tournamentID string, config *LeaderboardsConfigTournament, rank int64, rewardConfig *EconomyConfigReward) {
+	if l.pamlogix == nil || l.pamlogix.GetEconomySystem() == nil {
+		logger.Warn("Cannot grant tournament %s reward to user %s: no EconomySystem available", tournamentID, userID)
+		return
+	}
+	economySystem := l.pamlogix.GetEconomySystem()
+
+	reward, err := economySystem.RewardRoll(ctx, logger, nk, userID, rewardConfig)
+	if err != nil {
+		logger.Error("Failed to roll tournament %s reward for user %s: %v", tournamentID, userID, err)
+		return
+	}
+	if l.onTournamentReward != nil {
+		if reward, err = l.onTournamentReward(ctx, logger, nk, userID, tournamentID, config, rewardConfig, reward); err != nil {
+			logger.Error("Failed to apply custom tournament %s reward for user %s: %v", tournamentID, userID, err)
+			return
+		}
+	}
+	if reward == nil {
+		return
+	}
+
+	if _, _, _, err := economySystem.RewardGrant(ctx, logger, nk, userID, reward, map[string]interface{}{"tournament_id": tournamentID, "rank": rank}, true); err != nil {
+		logger.Error("Failed to grant tournament %s reward to user %s: %v", tournamentID, userID, err)
+		return
+	}
+
+	vars := map[string]string{"tournament": config.Name, "rank": strconv.FormatInt(rank, 10)}
+	content := map[string]interface{}{"tournament_id": tournamentID, "rank": rank, "reward": reward}
+	if err := sendTemplatedNotification(ctx, logger, nk, l.pamlogix, userID, NotificationEventTournamentReward, vars, content); err != nil {
+		logger.Warn("Failed to send tournament %s reward notification to user %s: %v", tournamentID, userID, err)
+	}
+}
+
+func (l *NakamaLeaderboardsSystem) getTournament(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, tournamentID string) (*LeaderboardsConfigTournament, *api.Tournament, error) {
+	config, found := l.config.Tournaments[tournamentID]
+	if !found {
+		return nil, nil, ErrTournamentNotFound
+	}
+
+	tournaments, err := nk.TournamentsGetId(ctx, []string{tournamentID})
+	if err != nil {
+		logger.Error("Failed to get tournament %s: %v", tournamentID, err)
+		return nil, nil, ErrInternal
+	}
+	if len(tournaments) == 0 {
+		return nil, nil, ErrTournamentNotFound
+	}
+	return config, tournaments[0], nil
+}
+
+// tournamentOwner returns who the user's records in a tournament belong to: the user, or their team in team tournaments.
+func (l *NakamaLeaderboardsSystem) tournamentOwner(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, config *LeaderboardsConfigTournament, userID, username string) (string, string, error) {
+	if !config.Team {
+		return userID, username, nil
+	}
+
+	userGroups, _, err := nk.UserGroupsList(ctx, userID, 10, nil, "")
+	if err != nil {
+		logger.Error("Failed to get teams of user %s: %v", userID, err)
+		return "", "", ErrInternal
+	}
+	for _, userGroup := range userGroups {
+		if userGroup.State != nil && userGroup.State.Value != int32(api.UserGroupList_UserGroup_JOIN_REQUEST) {
+			return userGroup.Group.Id, userGroup.Group.Name, nil
+		}
+	}
+	return "", "", ErrTournamentNoTeam
+}
+
+// teamMemberIDs returns the users of a team, leaving out pending join requests.
+func teamMemberIDs(ctx context.Context, nk runtime.NakamaModule, teamID string) ([]string, error) {
+	userIDs := make([]string, 0)
+	cursor := ""
+	for {
+		members, nextCursor, err := nk.GroupUsersList(ctx, teamID, teamMembersPageSize, nil, cursor)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if member.State != nil && member.State.Value == int32(api.GroupUserList_GroupUser_JOIN_REQUEST) {
+				continue
+			}
+			userIDs = append(userIDs, member.User.Id)
+		}
+		if nextCursor == "" {
+			return userIDs, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// tournamentReward returns the reward for a placement, or nil if it isn't rewarded.
+func tournamentReward(config *LeaderboardsConfigTournament, rank int64) *EconomyConfigReward {
+	for _, reward := range config.Rewards {
+		if rank >= reward.RankMin && rank <= reward.RankMax {
+			return reward.Reward
+		}
+	}
+	return nil
+}
+
+func newTournament(config *LeaderboardsConfigTournament, tournament *api.Tournament) *Tournament {
+	return &Tournament{
+		Id:             tournament.Id,
+		Name:           config.Name,
+		Description:    config.Description,
+		Category:       config.Category,
+		Team:           config.Team,
+		EntryCost:      config.EntryCost,
+		Rewards:        config.Rewards,
+		Size:           int64(tournament.Size),
+		MaxSize:        int64(tournament.MaxSize),
+		CanEnter:       tournament.CanEnter,
+		StartActiveSec: int64(tournament.StartActive),
+		EndActiveSec:   int64(tournament.EndActive),
+		NextResetSec:   int64(tournament.NextReset),
+	}
+}
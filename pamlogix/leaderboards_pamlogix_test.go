@@ -0,0 +1,193 @@
+package pamlogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// tournamentNakama keeps tournaments, their records in rank order, and team memberships in memory.
+type tournamentNakama struct {
+	*notificationNakama
+	tournaments map[string]*api.Tournament
+	records     map[string][]*api.LeaderboardRecord
+	teams       map[string][]string
+}
+
+func newTournamentNakama() *tournamentNakama {
+	return &tournamentNakama{
+		notificationNakama: newNotificationNakama(),
+		tournaments:        make(map[string]*api.Tournament),
+		records:            make(map[string][]*api.LeaderboardRecord),
+		teams:              make(map[string][]string),
+	}
+}
+
+func (n *tournamentNakama) TournamentsGetId(ctx context.Context, tournamentIDs []string) ([]*api.Tournament, error) {
+	tournaments := make([]*api.Tournament, 0, len(tournamentIDs))
+	for _, tournamentID := range tournamentIDs {
+		if tournament, found := n.tournaments[tournamentID]; found {
+			tournaments = append(tournaments, tournament)
+		}
+	}
+	return tournaments, nil
+}
+
+func (n *tournamentNakama) TournamentJoin(ctx context.Context, id, ownerID, username string) error {
+	n.records[id] = append(n.records[id], &api.LeaderboardRecord{LeaderboardId: id, OwnerId: ownerID, Username: wrapperspb.String(username)})
+	return nil
+}
+
+func (n *tournamentNakama) TournamentRecordsList(ctx context.Context, tournamentId string, ownerIDs []string, limit int, cursor string, overrideExpiry int64) ([]*api.LeaderboardRecord, []*api.LeaderboardRecord, string, string, error) {
+	records := n.records[tournamentId]
+	for i, record := range records {
+		record.Rank = int64(i + 1)
+	}
+	var ownerRecords []*api.LeaderboardRecord
+	for _, record := range records {
+		for _, ownerID := range ownerIDs {
+			if record.OwnerId == ownerID {
+				ownerRecords = append(ownerRecords, record)
+			}
+		}
+	}
+	return records, ownerRecords, "", "", nil
+}
+
+func (n *tournamentNakama) UserGroupsList(ctx context.Context, userID string, limit int, state *int, cursor string) ([]*api.UserGroupList_UserGroup, string, error) {
+	var userGroups []*api.UserGroupList_UserGroup
+	for teamID, userIDs := range n.teams {
+		for _, memberID := range userIDs {
+			if memberID == userID {
+				userGroups = append(userGroups, &api.UserGroupList_UserGroup{
+					Group: &api.Group{Id: teamID, Name: teamID},
+					State: wrapperspb.Int32(int32(api.UserGroupList_UserGroup_MEMBER)),
+				})
+			}
+		}
+	}
+	return userGroups, "", nil
+}
+
+func (n *tournamentNakama) GroupUsersList(ctx context.Context, id string, limit int, state *int, cursor string) ([]*api.GroupUserList_GroupUser, string, error) {
+	members := make([]*api.GroupUserList_GroupUser, 0, len(n.teams[id]))
+	for _, userID := range n.teams[id] {
+		members = append(members, &api.GroupUserList_GroupUser{
+			User:  &api.User{Id: userID},
+			State: wrapperspb.Int32(int32(api.GroupUserList_GroupUser_MEMBER)),
+		})
+	}
+	return members, "", nil
+}
+
+func newTestLeaderboardsSystem(p *pamlogixImpl) *NakamaLeaderboardsSystem {
+	leaderboardsSystem := NewNakamaLeaderboardsSystem(&LeaderboardsConfig{
+		Tournaments: map[string]*LeaderboardsConfigTournament{
+			"solo": {
+				Name:      "Solo Sprint",
+				EntryCost: &Cost{Currencies: map[string]int64{benchCurrency: 40}},
+			},
+			"teams": {
+				Name: "Team Cup",
+				Team: true,
+				Rewards: []*LeaderboardsConfigTournamentReward{
+					{RankMin: 1, RankMax: 1, Reward: &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+						Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {EconomyConfigRewardRangeInt64{Min: 100, Max: 100}}},
+					}}},
+					{RankMin: 2, RankMax: 3, Reward: &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+						Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {EconomyConfigRewardRangeInt64{Min: 10, Max: 10}}},
+					}}},
+				},
+			},
+		},
+	})
+	leaderboardsSystem.SetPamlogix(p)
+	p.systems[SystemTypeLeaderboards] = leaderboardsSystem
+	return leaderboardsSystem
+}
+
+func TestJoinTournament_ChargesEntryCostOnce(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newTournamentNakama()
+	nk.tournaments["solo"] = &api.Tournament{Id: "solo", CanEnter: true}
+	leaderboardsSystem := newTestLeaderboardsSystem(newBenchPamlogix())
+
+	_, _, err := nk.WalletUpdate(ctx, "user1", map[string]int64{benchCurrency: 100}, nil, false)
+	require.NoError(t, err)
+
+	tournament, err := leaderboardsSystem.JoinTournament(ctx, logger, nk, "user1", "player1", "solo")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tournament.Size)
+
+	_, err = leaderboardsSystem.JoinTournament(ctx, logger, nk, "user1", "player1", "solo")
+	assert.ErrorIs(t, err, ErrTournamentAlreadyJoined)
+
+	wallet, err := userWallet(ctx, nk, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), wallet[benchCurrency])
+
+	// Unaffordable entries don't join
+	_, err = leaderboardsSystem.JoinTournament(ctx, logger, nk, "user2", "player2", "solo")
+	assert.ErrorIs(t, err, ErrCurrencyInsufficient)
+	assert.Len(t, nk.records["solo"], 1)
+}
+
+func TestJoinTournament_TeamTournament(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newTournamentNakama()
+	nk.tournaments["teams"] = &api.Tournament{Id: "teams", CanEnter: true}
+	nk.teams["team1"] = []string{"user1", "user2"}
+	leaderboardsSystem := newTestLeaderboardsSystem(newBenchPamlogix())
+
+	_, err := leaderboardsSystem.JoinTournament(ctx, logger, nk, "user3", "player3", "teams")
+	assert.ErrorIs(t, err, ErrTournamentNoTeam)
+
+	_, err = leaderboardsSystem.JoinTournament(ctx, logger, nk, "user1", "player1", "teams")
+	require.NoError(t, err)
+	require.Len(t, nk.records["teams"], 1)
+	assert.Equal(t, "team1", nk.records["teams"][0].OwnerId)
+
+	// The team has entered, whichever member tries again
+	_, err = leaderboardsSystem.JoinTournament(ctx, logger, nk, "user2", "player2", "teams")
+	assert.ErrorIs(t, err, ErrTournamentAlreadyJoined)
+
+	nk.tournaments["teams"].CanEnter = false
+	_, err = leaderboardsSystem.JoinTournament(ctx, logger, nk, "user1", "player1", "teams")
+	assert.ErrorIs(t, err, ErrTournamentNotActive)
+}
+
+func TestProcessTournamentEnd_RewardsTeamMembersByRank(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newTournamentNakama()
+	nk.tournaments["teams"] = &api.Tournament{Id: "teams"}
+	nk.teams["team1"] = []string{"user1", "user2"}
+	nk.teams["team2"] = []string{"user3"}
+	nk.teams["team3"] = []string{"user4"}
+	nk.teams["team4"] = []string{"user5"}
+	for _, teamID := range []string{"team1", "team2", "team3", "team4"} {
+		require.NoError(t, nk.TournamentJoin(ctx, "teams", teamID, teamID))
+	}
+	leaderboardsSystem := newTestLeaderboardsSystem(newBenchPamlogix())
+
+	require.NoError(t, leaderboardsSystem.ProcessTournamentEnd(ctx, logger, nk, nk.tournaments["teams"], 0, 0))
+
+	for userID, want := range map[string]int64{"user1": 100, "user2": 100, "user3": 10, "user4": 10, "user5": 0} {
+		wallet, err := userWallet(ctx, nk, userID)
+		require.NoError(t, err)
+		assert.Equal(t, want, wallet[benchCurrency], userID)
+	}
+
+	require.Len(t, nk.sent, 4)
+	for _, notification := range nk.sent {
+		assert.Equal(t, 1303, notification.code)
+	}
+	assert.Equal(t, "user1", nk.sent[0].userID)
+	assert.Equal(t, int64(1), nk.sent[0].content["rank"])
+}
@@ -0,0 +1,242 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/require"
+)
+
+// Environment variables controlling TestLoadSimulation. The simulation only runs when PAMLOGIX_LOAD_USERS is set, e.g.
+//
+//	PAMLOGIX_LOAD_USERS=500 PAMLOGIX_LOAD_ROUNDS=20 go test ./pamlogix -run TestLoadSimulation -v
+const (
+	loadUsersEnv    = "PAMLOGIX_LOAD_USERS"
+	loadRoundsEnv   = "PAMLOGIX_LOAD_ROUNDS"
+	loadAuctionsEnv = "PAMLOGIX_LOAD_AUCTIONS"
+
+	defaultLoadRounds = 10
+)
+
+// loadOpStats collects the latency and storage op counts of every call to one RPC or system function.
+type loadOpStats struct {
+	durations []time.Duration
+	errors    int
+	reads     int64
+	writes    int64
+	deletes   int64
+	lists     int64
+}
+
+type loadRecorder struct {
+	mu  sync.Mutex
+	ops map[string]*loadOpStats
+}
+
+// record times fn, counting the storage calls it makes, and files the result under name.
+func (r *loadRecorder) record(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	counts := &storageOpCounts{}
+	start := time.Now()
+	err := fn(withStorageOpCounts(ctx, counts))
+	elapsed := time.Since(start)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, found := r.ops[name]
+	if !found {
+		stats = &loadOpStats{}
+		r.ops[name] = stats
+	}
+	stats.durations = append(stats.durations, elapsed)
+	if err != nil {
+		stats.errors++
+	}
+	stats.reads += counts.reads.Load()
+	stats.writes += counts.writes.Load()
+	stats.deletes += counts.deletes.Load()
+	stats.lists += counts.lists.Load()
+
+	return err
+}
+
+func (r *loadRecorder) report() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.ops))
+	for name := range r.ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "op\tcalls\terrors\tp50\tp95\tp99\tmax\treads/call\twrites/call\tdeletes/call\tlists/call\t")
+	for _, name := range names {
+		stats := r.ops[name]
+		sort.Slice(stats.durations, func(i, j int) bool { return stats.durations[i] < stats.durations[j] })
+		calls := float64(len(stats.durations))
+		fmt.Fprintf(w, "%s\t%d\t%d\t%v\t%v\t%v\t%v\t%.2f\t%.2f\t%.2f\t%.2f\t\n", name, len(stats.durations), stats.errors,
+			loadPercentile(stats.durations, 0.50), loadPercentile(stats.durations, 0.95), loadPercentile(stats.durations, 0.99),
+			stats.durations[len(stats.durations)-1],
+			float64(stats.reads)/calls, float64(stats.writes)/calls, float64(stats.deletes)/calls, float64(stats.lists)/calls)
+	}
+	_ = w.Flush()
+
+	return sb.String()
+}
+
+// loadPercentile returns the nearest-rank percentile of durations, which must be sorted.
+func loadPercentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(durations)) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(durations) {
+		rank = len(durations)
+	}
+	return durations[rank-1]
+}
+
+func loadEnvInt(t *testing.T, name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	require.NoError(t, err, "invalid %s", name)
+	require.Positive(t, n, "invalid %s", name)
+	return n
+}
+
+// TestLoadSimulation runs N virtual users against an in-memory test Nakama. Each round every user rolls and grants a
+// reward, then bids on a random auction through the bid RPC. Once the rounds are done the auctions end and the winners
+// claim them through the claim RPC. The latency percentiles and storage op counts of every call are logged at the end.
+func TestLoadSimulation(t *testing.T) {
+	if os.Getenv(loadUsersEnv) == "" {
+		t.Skipf("set %s to run the load simulation", loadUsersEnv)
+	}
+	users := loadEnvInt(t, loadUsersEnv, 0)
+	rounds := loadEnvInt(t, loadRoundsEnv, defaultLoadRounds)
+	auctions := loadEnvInt(t, loadAuctionsEnv, max(users/10, 1))
+
+	p := newBenchPamlogix()
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	recorder := &loadRecorder{ops: make(map[string]*loadOpStats)}
+	rpcBid := rpcAuctionsBid_Json(p)
+	rpcClaimBid := rpcAuctionsClaimBid_Json(p)
+
+	auctionIDs := make([]string, 0, auctions)
+	for i := 0; i < auctions; i++ {
+		auctionID := fmt.Sprintf("load-auction-%d", i)
+		require.NoError(t, seedBenchAuction(context.Background(), nk, auctionID, "load-seller", 3600))
+		auctionIDs = append(auctionIDs, auctionID)
+	}
+
+	userCtx := func(userID string) context.Context {
+		ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, userID)
+		return context.WithValue(ctx, runtime.RUNTIME_CTX_SESSION_ID, "session-"+userID)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for u := 0; u < users; u++ {
+		wg.Add(1)
+		go func(userID string) {
+			defer wg.Done()
+			ctx := userCtx(userID)
+			rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+			economySystem := p.GetEconomySystem()
+
+			for round := 0; round < rounds; round++ {
+				var reward *Reward
+				_ = recorder.record(ctx, "RewardRoll", func(ctx context.Context) (err error) {
+					reward, err = economySystem.RewardRoll(ctx, logger, nk, userID, benchRewardConfig)
+					return err
+				})
+				if reward != nil {
+					_ = recorder.record(ctx, "RewardGrant", func(ctx context.Context) error {
+						_, _, _, err := economySystem.RewardGrant(ctx, logger, nk, userID, reward, nil, false)
+						return err
+					})
+				}
+
+				// Bid the minimum on whatever version of the auction the client last saw
+				auctionID := auctionIDs[rng.Intn(len(auctionIDs))]
+				objects, err := nk.StorageRead(context.Background(), []*runtime.StorageRead{{Collection: AuctionCollectionKey, Key: auctionID}})
+				if err != nil || len(objects) == 0 {
+					continue
+				}
+				auction := &Auction{}
+				if err := json.Unmarshal([]byte(objects[0].Value), auction); err != nil {
+					continue
+				}
+				if auction.Bid != nil && auction.Bid.UserId == userID {
+					continue
+				}
+				payload, err := json.Marshal(&AuctionBidRequest{Id: auctionID, Version: auction.Version, Bid: auction.BidNext})
+				if err != nil {
+					continue
+				}
+				_ = recorder.record(ctx, RpcId_RPC_ID_AUCTIONS_BID.String(), func(ctx context.Context) error {
+					_, err := rpcBid(ctx, logger, nil, nk, string(payload))
+					return err
+				})
+			}
+		}(fmt.Sprintf("load-user-%d", u))
+	}
+	wg.Wait()
+
+	// End every auction, then let each winner claim what they won
+	winners := make(map[string][]string)
+	for _, auctionID := range auctionIDs {
+		objects, err := nk.StorageRead(context.Background(), []*runtime.StorageRead{{Collection: AuctionCollectionKey, Key: auctionID}})
+		require.NoError(t, err)
+		require.NotEmpty(t, objects)
+		auction := &Auction{}
+		require.NoError(t, json.Unmarshal([]byte(objects[0].Value), auction))
+		if auction.Bid == nil {
+			continue
+		}
+		auction.EndTimeSec = time.Now().Unix() - 1
+		data, err := json.Marshal(auction)
+		require.NoError(t, err)
+		_, err = nk.StorageWrite(context.Background(), []*runtime.StorageWrite{{Collection: AuctionCollectionKey, Key: auctionID, Value: string(data)}})
+		require.NoError(t, err)
+		winners[auction.Bid.UserId] = append(winners[auction.Bid.UserId], auctionID)
+	}
+
+	for userID, won := range winners {
+		wg.Add(1)
+		go func(userID string, won []string) {
+			defer wg.Done()
+			ctx := userCtx(userID)
+			for _, auctionID := range won {
+				payload := fmt.Sprintf(`{"id":%q}`, auctionID)
+				_ = recorder.record(ctx, RpcId_RPC_ID_AUCTIONS_CLAIM_BID.String(), func(ctx context.Context) error {
+					_, err := rpcClaimBid(ctx, logger, nil, nk, payload)
+					return err
+				})
+			}
+		}(userID, won)
+	}
+	wg.Wait()
+
+	t.Logf("%d users, %d rounds, %d auctions in %v\n%s", users, rounds, auctions, time.Since(start), recorder.report())
+}
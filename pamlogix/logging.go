@@ -0,0 +1,134 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// Fields every system logs with, so an operator can filter the server logs down to one system, RPC, user or entity.
+const (
+	LogFieldSystem   = "system"
+	LogFieldRpc      = "rpc"
+	LogFieldUserID   = "user_id"
+	LogFieldEntityID = "entity_id"
+)
+
+// LogLevelsDefault is the key in the base config log levels of the level used by systems without one of their own.
+const LogLevelsDefault = "default"
+
+type logLevel int
+
+const (
+	logLevelDebug logLevel = iota
+	logLevelInfo
+	logLevelWarn
+	logLevelError
+)
+
+// parseLogLevel reads a level name from the base config. Unknown names log everything, leaving it to Nakama's own
+// log level.
+func parseLogLevel(name string) logLevel {
+	switch strings.ToLower(name) {
+	case "info":
+		return logLevelInfo
+	case "warn", "warning":
+		return logLevelWarn
+	case "error":
+		return logLevelError
+	default:
+		return logLevelDebug
+	}
+}
+
+// levelLogger drops messages below its level. Nakama still applies its own log level on top, so it can only quieten
+// a system, and debug logging for one system needs Nakama at debug with a higher default level for the rest.
+type levelLogger struct {
+	runtime.Logger
+	level logLevel
+}
+
+func (l *levelLogger) Debug(format string, v ...interface{}) {
+	if l.level <= logLevelDebug {
+		l.Logger.Debug(format, v...)
+	}
+}
+
+func (l *levelLogger) Info(format string, v ...interface{}) {
+	if l.level <= logLevelInfo {
+		l.Logger.Info(format, v...)
+	}
+}
+
+func (l *levelLogger) Warn(format string, v ...interface{}) {
+	if l.level <= logLevelWarn {
+		l.Logger.Warn(format, v...)
+	}
+}
+
+func (l *levelLogger) Error(format string, v ...interface{}) {
+	l.Logger.Error(format, v...)
+}
+
+func (l *levelLogger) WithField(key string, v interface{}) runtime.Logger {
+	return &levelLogger{Logger: l.Logger.WithField(key, v), level: l.level}
+}
+
+func (l *levelLogger) WithFields(fields map[string]interface{}) runtime.Logger {
+	return &levelLogger{Logger: l.Logger.WithFields(fields), level: l.level}
+}
+
+// systemLogger returns the logger a system logs with: tagged with the system's name and filtered to the level the
+// base config sets for it.
+func (p *pamlogixImpl) systemLogger(logger runtime.Logger, systemType SystemType) runtime.Logger {
+	logger = logger.WithField(LogFieldSystem, systemNames[systemType])
+
+	baseSystem := p.GetBaseSystem()
+	if baseSystem == nil {
+		return logger
+	}
+	baseConfig, ok := baseSystem.GetConfig().(*BaseSystemConfig)
+	if !ok || baseConfig == nil || len(baseConfig.LogLevels) == 0 {
+		return logger
+	}
+	levelName, found := baseConfig.LogLevels[systemNames[systemType]]
+	if !found {
+		levelName = baseConfig.LogLevels[LogLevelsDefault]
+	}
+	if level := parseLogLevel(levelName); level > logLevelDebug {
+		return &levelLogger{Logger: logger, level: level}
+	}
+	return logger
+}
+
+// withEntityLogger tags the logger with the ID of the entity an RPC acts on, such as an auction or a team.
+func withEntityLogger(logger runtime.Logger, entityID string) runtime.Logger {
+	if entityID == "" {
+		return logger
+	}
+	return logger.WithField(LogFieldEntityID, entityID)
+}
+
+// loggingInitializer gives every RPC registered through it a logger tagged with its system, RPC ID and calling user,
+// at the system's log level. The system is also passed to the collection resolver through the context.
+type loggingInitializer struct {
+	runtime.Initializer
+	pamlogix   *pamlogixImpl
+	systemType SystemType
+}
+
+func (i *loggingInitializer) RegisterRpc(id string, fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) error {
+	return i.Initializer.RegisterRpc(id, withLoggingRpc(i.pamlogix, i.systemType, id, fn))
+}
+
+func withLoggingRpc(p *pamlogixImpl, systemType SystemType, rpcID string, fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		fields := map[string]interface{}{LogFieldRpc: rpcID}
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			fields[LogFieldUserID] = userID
+		}
+		return fn(withSystemType(ctx, systemType), p.systemLogger(logger, systemType).WithFields(fields), db, nk, payload)
+	}
+}
@@ -0,0 +1,93 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger keeps the level and fields of every message logged through it or the loggers derived from it.
+type recordingLogger struct {
+	fields  map[string]interface{}
+	entries *[]recordedLogEntry
+}
+
+type recordedLogEntry struct {
+	level  string
+	fields map[string]interface{}
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{fields: map[string]interface{}{}, entries: &[]recordedLogEntry{}}
+}
+
+func (l *recordingLogger) record(level string) {
+	*l.entries = append(*l.entries, recordedLogEntry{level: level, fields: l.fields})
+}
+
+func (l *recordingLogger) Debug(format string, v ...interface{}) { l.record("debug") }
+func (l *recordingLogger) Info(format string, v ...interface{})  { l.record("info") }
+func (l *recordingLogger) Warn(format string, v ...interface{})  { l.record("warn") }
+func (l *recordingLogger) Error(format string, v ...interface{}) { l.record("error") }
+func (l *recordingLogger) WithField(key string, v interface{}) runtime.Logger {
+	return l.WithFields(map[string]interface{}{key: v})
+}
+func (l *recordingLogger) WithFields(fields map[string]interface{}) runtime.Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &recordingLogger{fields: merged, entries: l.entries}
+}
+func (l *recordingLogger) Fields() map[string]interface{} { return l.fields }
+
+func TestSystemLogger_Levels(t *testing.T) {
+	p := newBenchPamlogix()
+	p.systems[SystemTypeBase] = NewBaseSystem(&BaseSystemConfig{
+		LogLevels: map[string]string{LogLevelsDefault: "warn", "auctions": "debug"},
+	})
+
+	logger := newRecordingLogger()
+	for _, systemType := range []SystemType{SystemTypeAuctions, SystemTypeEconomy} {
+		systemLogger := p.systemLogger(logger, systemType)
+		systemLogger.Debug("debug")
+		systemLogger.Info("info")
+		systemLogger.WithField(LogFieldEntityID, "id").Warn("warn")
+	}
+
+	levels := make(map[string][]string)
+	for _, entry := range *logger.entries {
+		system := entry.fields[LogFieldSystem].(string)
+		levels[system] = append(levels[system], entry.level)
+	}
+	assert.Equal(t, []string{"debug", "info", "warn"}, levels["auctions"])
+	assert.Equal(t, []string{"warn"}, levels["economy"])
+}
+
+func TestWithLoggingRpc_Fields(t *testing.T) {
+	p := newBenchPamlogix()
+	logger := newRecordingLogger()
+
+	rpc := withLoggingRpc(p, SystemTypeAuctions, RpcId_RPC_ID_AUCTIONS_BID.String(), func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		withEntityLogger(logger, "auction1").Info("bid placed")
+		return "", nil
+	})
+	ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, "user1")
+	_, err := rpc(ctx, logger, nil, nil, "")
+	require.NoError(t, err)
+
+	require.Len(t, *logger.entries, 1)
+	assert.Equal(t, map[string]interface{}{
+		LogFieldSystem:   "auctions",
+		LogFieldRpc:      RpcId_RPC_ID_AUCTIONS_BID.String(),
+		LogFieldUserID:   "user1",
+		LogFieldEntityID: "auction1",
+	}, (*logger.entries)[0].fields)
+}
@@ -0,0 +1,116 @@
+package pamlogix
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+var (
+	ErrTextTooLong           = runtime.NewError("text exceeds maximum length", INVALID_ARGUMENT_ERROR_CODE)      // INVALID_ARGUMENT
+	ErrTextInvalidCharacters = runtime.NewError("text contains invalid characters", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+)
+
+// Fields passed to the TextModerationFn to identify which user-supplied text is being checked.
+const (
+	TextFieldTeamChatMessage     = "team_chat_message"
+	TextFieldAuctionItemProperty = "auction_item_property"
+	TextFieldTeamProfile         = "team_profile"
+)
+
+// defaultTextAllowedCharacters rejects control characters other than tabs and line breaks.
+var defaultTextAllowedCharacters = regexp.MustCompile(`^[^\x00-\x08\x0B\x0C\x0E-\x1F\x7F]*$`)
+
+// TextModerationFn checks user-supplied text before it is stored or sent to other players, for example against a
+// profanity list or an external moderation service. It returns the text to use, which may be rewritten to mask words,
+// or an error to reject the text outright.
+type TextModerationFn func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, field, text string) (string, error)
+
+// TextLimits is the data definition for validating a user-supplied text field.
+type TextLimits struct {
+	// MaxLength is the maximum number of characters allowed. Zero means no limit.
+	MaxLength int `json:"max_length,omitempty"`
+	// AllowedCharacters is a regular expression character class, without the brackets, that every character must match,
+	// e.g. "\\p{L}\\p{N}\\p{P}\\p{Zs}". When empty any character other than control characters is allowed.
+	AllowedCharacters string `json:"allowed_characters,omitempty"`
+
+	// allowed is AllowedCharacters compiled when the config holding the limits is loaded.
+	allowed *regexp.Regexp
+}
+
+// compileTextLimits compiles the allowed characters of each of the limits, so a config with an invalid pattern fails
+// to load rather than failing every text it's meant to check. Nil limits are skipped.
+func compileTextLimits(limits ...*TextLimits) error {
+	for _, l := range limits {
+		if l == nil || l.AllowedCharacters == "" {
+			continue
+		}
+		allowed, err := l.compile()
+		if err != nil {
+			return err
+		}
+		l.allowed = allowed
+	}
+	return nil
+}
+
+func (l *TextLimits) compile() (*regexp.Regexp, error) {
+	allowed, err := regexp.Compile(fmt.Sprintf("^[%s]*$", l.AllowedCharacters))
+	if err != nil {
+		return nil, fmt.Errorf("invalid allowed characters %q: %w", l.AllowedCharacters, err)
+	}
+	return allowed, nil
+}
+
+// validateText checks the text against the length limit and character whitelist. Limits which weren't loaded with a
+// config have their allowed characters compiled on each call.
+func validateText(text string, limits *TextLimits) error {
+	allowed := defaultTextAllowedCharacters
+	if limits != nil {
+		if limits.MaxLength > 0 && utf8.RuneCountInString(text) > limits.MaxLength {
+			return ErrTextTooLong
+		}
+		if limits.allowed != nil {
+			allowed = limits.allowed
+		} else if limits.AllowedCharacters != "" {
+			var err error
+			if allowed, err = limits.compile(); err != nil {
+				return runtime.NewError(err.Error(), INTERNAL_ERROR_CODE) // INTERNAL
+			}
+		}
+	}
+
+	if !utf8.ValidString(text) || !allowed.MatchString(text) {
+		return ErrTextInvalidCharacters
+	}
+
+	return nil
+}
+
+// moderateText validates user-supplied text and then passes it through the text moderation function registered on
+// Pamlogix, if any. It returns the text that should be stored or sent.
+func moderateText(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID, field, text string, limits *TextLimits) (string, error) {
+	if err := validateText(text, limits); err != nil {
+		return "", err
+	}
+
+	moderator, ok := pl.(interface{ getTextModeration() TextModerationFn })
+	if !ok {
+		return text, nil
+	}
+	fn := moderator.getTextModeration()
+	if fn == nil {
+		return text, nil
+	}
+
+	moderated, err := fn(ctx, logger, nk, userID, field, text)
+	if err != nil {
+		logger.Warn("Text moderation rejected %s from user %s: %v", field, userID, err)
+		return "", err
+	}
+
+	return moderated, nil
+}
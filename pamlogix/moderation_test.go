@@ -0,0 +1,61 @@
+package pamlogix
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateText(t *testing.T) {
+	limits := &TextLimits{MaxLength: 5, AllowedCharacters: `\p{L}\p{N} `}
+
+	assert.NoError(t, validateText("héllo", limits))
+	assert.ErrorIs(t, validateText("hello!", limits), ErrTextTooLong)
+	assert.ErrorIs(t, validateText("hi!", limits), ErrTextInvalidCharacters)
+
+	// Without limits only control characters are rejected
+	assert.NoError(t, validateText("line one\nline two 🎉", nil))
+	assert.ErrorIs(t, validateText("bell\a", nil), ErrTextInvalidCharacters)
+
+	// Limits loaded with a config are compiled once, and an invalid pattern fails the load
+	require.NoError(t, compileTextLimits(limits, nil))
+	require.NotNil(t, limits.allowed)
+	assert.ErrorIs(t, validateText("hi!", limits), ErrTextInvalidCharacters)
+	assert.Error(t, compileTextLimits(&TextLimits{AllowedCharacters: `\p{Nope}`}))
+}
+
+func TestModerateText(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	p := &pamlogixImpl{}
+
+	text, err := moderateText(ctx, logger, nil, p, "user1", TextFieldTeamChatMessage, "hello darn world", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello darn world", text)
+
+	p.SetTextModeration(func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, field, text string) (string, error) {
+		if strings.Contains(text, "scam") {
+			return "", runtime.NewError("text rejected by moderation", INVALID_ARGUMENT_ERROR_CODE)
+		}
+		return strings.ReplaceAll(text, "darn", "****"), nil
+	})
+
+	text, err = moderateText(ctx, logger, nil, p, "user1", TextFieldTeamChatMessage, "hello darn world", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello **** world", text)
+
+	_, err = moderateText(ctx, logger, nil, p, "user1", TextFieldAuctionItemProperty, "free scam", nil)
+	assert.Error(t, err)
+
+	// Length limits are checked before the moderation hook runs
+	_, err = moderateText(ctx, logger, nil, p, "user1", TextFieldTeamChatMessage, "hello darn world", &TextLimits{MaxLength: 3})
+	assert.ErrorIs(t, err, ErrTextTooLong)
+
+	// Systems without a Pamlogix reference still get validation
+	_, err = moderateText(ctx, logger, nil, nil, "user1", TextFieldTeamChatMessage, "x\x00", nil)
+	assert.ErrorIs(t, err, ErrTextInvalidCharacters)
+}
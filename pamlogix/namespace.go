@@ -0,0 +1,305 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type systemTypeCtxKey struct{}
+
+// namespaceConfig is the SystemConfig returned by WithNamespace. It configures the Pamlogix instance rather than a
+// gameplay system, so Init takes it out before initializing systems.
+type namespaceConfig struct {
+	namespace string
+}
+
+func (nc *namespaceConfig) GetType() SystemType {
+	return SystemTypeUnknown
+}
+func (nc *namespaceConfig) GetConfigFile() string {
+	return ""
+}
+func (nc *namespaceConfig) GetRegister() bool {
+	return false
+}
+func (nc *namespaceConfig) GetExtra() any {
+	return nil
+}
+
+// WithNamespace runs the Pamlogix instance in its own namespace, so one Nakama module can host several independent
+// instances, e.g. one per game mode. Its RPC IDs and storage collections are prefixed with the namespace, see
+// NamespacedRpcId. Wallets, leaderboards and tournaments are shared by all instances, so instances with separate
+// economies should use distinct currency and leaderboard IDs.
+func WithNamespace(namespace string) SystemConfig {
+	return &namespaceConfig{namespace: namespace}
+}
+
+// NamespacedRpcId returns the ID clients call an RPC by when it's registered by a Pamlogix instance in the namespace.
+func NamespacedRpcId(namespace, id string) string {
+	if namespace == "" {
+		return id
+	}
+	return namespace + "_" + id
+}
+
+// namespaceFromConfigs returns the namespace set with WithNamespace and the gameplay system configs.
+func namespaceFromConfigs(configs []SystemConfig) (string, []SystemConfig) {
+	namespace := ""
+	systemConfigs := make([]SystemConfig, 0, len(configs))
+	for _, config := range configs {
+		if nc, ok := config.(*namespaceConfig); ok {
+			namespace = nc.namespace
+			continue
+		}
+		systemConfigs = append(systemConfigs, config)
+	}
+	return namespace, systemConfigs
+}
+
+func withSystemType(ctx context.Context, systemType SystemType) context.Context {
+	return context.WithValue(ctx, systemTypeCtxKey{}, systemType)
+}
+
+func systemTypeFromContext(ctx context.Context) SystemType {
+	systemType, _ := ctx.Value(systemTypeCtxKey{}).(SystemType)
+	return systemType
+}
+
+// resolveCollection returns the collection a system's storage is kept in, after the collection resolver and the
+// namespace. The system is the one whose RPC is running, or SystemTypeUnknown outside of RPCs.
+func (p *pamlogixImpl) resolveCollection(ctx context.Context, collection string) (string, error) {
+	if p.collectionResolver != nil {
+		resolved, err := p.collectionResolver(ctx, systemTypeFromContext(ctx), collection)
+		if err != nil {
+			return "", err
+		}
+		collection = resolved
+	}
+	if p.namespace != "" {
+		collection = p.namespace + "_" + collection
+	}
+	return collection, nil
+}
+
+// collectionResolverInitializer registers RPCs under the namespace and gives them, and the other hooks registered
+// through it, storage resolved by the collection resolver.
+type collectionResolverInitializer struct {
+	runtime.Initializer
+	pamlogix *pamlogixImpl
+}
+
+func (i *collectionResolverInitializer) RegisterRpc(id string, fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) error {
+	return i.Initializer.RegisterRpc(NamespacedRpcId(i.pamlogix.namespace, id), withCollectionResolverRpc(i.pamlogix, fn))
+}
+
+func (i *collectionResolverInitializer) RegisterTournamentEnd(fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, tournament *api.Tournament, end, reset int64) error) error {
+	return i.Initializer.RegisterTournamentEnd(func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, tournament *api.Tournament, end, reset int64) error {
+		return fn(ctx, logger, db, &collectionResolverNakama{NakamaModule: nk, pamlogix: i.pamlogix}, tournament, end, reset)
+	})
+}
+
+// RegisterStorageIndex indexes the namespaced collection. The collection resolver can't be applied, since it's set
+// after Init registers the indexes.
+func (i *collectionResolverInitializer) RegisterStorageIndex(name, collection, key string, fields []string, sortableFields []string, maxEntries int, indexOnly bool) error {
+	if namespace := i.pamlogix.namespace; namespace != "" {
+		name = namespace + "_" + name
+		collection = namespace + "_" + collection
+	}
+	return i.Initializer.RegisterStorageIndex(name, collection, key, fields, sortableFields, maxEntries, indexOnly)
+}
+
+func withCollectionResolverRpc(p *pamlogixImpl, fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		return fn(ctx, logger, db, &collectionResolverNakama{NakamaModule: nk, pamlogix: p}, payload)
+	}
+}
+
+// collectionResolverNakama stores the systems' storage in the collections the collection resolver and namespace
+// resolve them to. Objects read back carry the collection the systems asked for, so they can't tell the difference.
+// It's also where the storage permissions set in the base config are applied, as every system write goes through it.
+type collectionResolverNakama struct {
+	runtime.NakamaModule
+	pamlogix *pamlogixImpl
+}
+
+func (n *collectionResolverNakama) resolves() bool {
+	return n.pamlogix.collectionResolver != nil || n.pamlogix.namespace != ""
+}
+
+// rewritesWrites reports whether writes need changing, to resolve their collections or to apply the base config's
+// storage permissions.
+func (n *collectionResolverNakama) rewritesWrites() bool {
+	return n.resolves() || len(n.pamlogix.storagePermissions()) > 0
+}
+
+// resolveCollections resolves each collection once, returning the resolved collections keyed by the requested ones
+// and the requested ones keyed by the resolved ones.
+func (n *collectionResolverNakama) resolveCollections(ctx context.Context, collections []string) (map[string]string, map[string]string, error) {
+	resolved := make(map[string]string, len(collections))
+	requested := make(map[string]string, len(collections))
+	for _, collection := range collections {
+		if _, found := resolved[collection]; found {
+			continue
+		}
+		resolvedCollection, err := n.pamlogix.resolveCollection(ctx, collection)
+		if err != nil {
+			return nil, nil, err
+		}
+		resolved[collection] = resolvedCollection
+		requested[resolvedCollection] = collection
+	}
+	return resolved, requested, nil
+}
+
+func (n *collectionResolverNakama) resolveWrites(ctx context.Context, writes []*runtime.StorageWrite, deletes []*runtime.StorageDelete) ([]*runtime.StorageWrite, []*runtime.StorageDelete, map[string]string, error) {
+	collections := make([]string, 0, len(writes)+len(deletes))
+	for _, write := range writes {
+		collections = append(collections, write.Collection)
+	}
+	for _, del := range deletes {
+		collections = append(collections, del.Collection)
+	}
+	resolved, requested, err := n.resolveCollections(ctx, collections)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Callers may hold on to their writes, so they're copied rather than changed
+	var resolvedWrites []*runtime.StorageWrite
+	if writes != nil {
+		permissions := n.pamlogix.storagePermissions()
+		resolvedWrites = make([]*runtime.StorageWrite, 0, len(writes))
+		for _, write := range writes {
+			resolvedWrite := *write
+			permissions[write.Collection].apply(&resolvedWrite)
+			resolvedWrite.Collection = resolved[write.Collection]
+			resolvedWrites = append(resolvedWrites, &resolvedWrite)
+		}
+	}
+	var resolvedDeletes []*runtime.StorageDelete
+	if deletes != nil {
+		resolvedDeletes = make([]*runtime.StorageDelete, 0, len(deletes))
+		for _, del := range deletes {
+			resolvedDelete := *del
+			resolvedDelete.Collection = resolved[del.Collection]
+			resolvedDeletes = append(resolvedDeletes, &resolvedDelete)
+		}
+	}
+	return resolvedWrites, resolvedDeletes, requested, nil
+}
+
+func (n *collectionResolverNakama) StorageRead(ctx context.Context, reads []*runtime.StorageRead) ([]*api.StorageObject, error) {
+	if !n.resolves() {
+		return n.NakamaModule.StorageRead(ctx, reads)
+	}
+	collections := make([]string, 0, len(reads))
+	for _, read := range reads {
+		collections = append(collections, read.Collection)
+	}
+	resolved, requested, err := n.resolveCollections(ctx, collections)
+	if err != nil {
+		return nil, err
+	}
+	resolvedReads := make([]*runtime.StorageRead, 0, len(reads))
+	for _, read := range reads {
+		resolvedRead := *read
+		resolvedRead.Collection = resolved[read.Collection]
+		resolvedReads = append(resolvedReads, &resolvedRead)
+	}
+
+	objects, err := n.NakamaModule.StorageRead(ctx, resolvedReads)
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		if collection, found := requested[object.Collection]; found {
+			object.Collection = collection
+		}
+	}
+	return objects, nil
+}
+
+func (n *collectionResolverNakama) StorageList(ctx context.Context, callerID, userID, collection string, limit int, cursor string) ([]*api.StorageObject, string, error) {
+	if !n.resolves() {
+		return n.NakamaModule.StorageList(ctx, callerID, userID, collection, limit, cursor)
+	}
+	resolvedCollection, err := n.pamlogix.resolveCollection(ctx, collection)
+	if err != nil {
+		return nil, "", err
+	}
+	objects, nextCursor, err := n.NakamaModule.StorageList(ctx, callerID, userID, resolvedCollection, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, object := range objects {
+		object.Collection = collection
+	}
+	return objects, nextCursor, nil
+}
+
+func (n *collectionResolverNakama) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	if !n.rewritesWrites() {
+		return n.NakamaModule.StorageWrite(ctx, writes)
+	}
+	resolvedWrites, _, requested, err := n.resolveWrites(ctx, writes, nil)
+	if err != nil {
+		return nil, err
+	}
+	acks, err := n.NakamaModule.StorageWrite(ctx, resolvedWrites)
+	for _, ack := range acks {
+		if collection, found := requested[ack.Collection]; found {
+			ack.Collection = collection
+		}
+	}
+	return acks, err
+}
+
+func (n *collectionResolverNakama) StorageDelete(ctx context.Context, deletes []*runtime.StorageDelete) error {
+	if !n.resolves() {
+		return n.NakamaModule.StorageDelete(ctx, deletes)
+	}
+	_, resolvedDeletes, _, err := n.resolveWrites(ctx, nil, deletes)
+	if err != nil {
+		return err
+	}
+	return n.NakamaModule.StorageDelete(ctx, resolvedDeletes)
+}
+
+func (n *collectionResolverNakama) MultiUpdate(ctx context.Context, accountUpdates []*runtime.AccountUpdate, storageWrites []*runtime.StorageWrite, storageDeletes []*runtime.StorageDelete, walletUpdates []*runtime.WalletUpdate, updateLedger bool) ([]*api.StorageObjectAck, []*runtime.WalletUpdateResult, error) {
+	if !n.rewritesWrites() {
+		return n.NakamaModule.MultiUpdate(ctx, accountUpdates, storageWrites, storageDeletes, walletUpdates, updateLedger)
+	}
+	resolvedWrites, resolvedDeletes, requested, err := n.resolveWrites(ctx, storageWrites, storageDeletes)
+	if err != nil {
+		return nil, nil, err
+	}
+	acks, results, err := n.NakamaModule.MultiUpdate(ctx, accountUpdates, resolvedWrites, resolvedDeletes, walletUpdates, updateLedger)
+	for _, ack := range acks {
+		if collection, found := requested[ack.Collection]; found {
+			ack.Collection = collection
+		}
+	}
+	return acks, results, err
+}
+
+// StorageIndexList queries the namespaced index, see RegisterStorageIndex.
+func (n *collectionResolverNakama) StorageIndexList(ctx context.Context, callerID, indexName, query string, limit int, order []string, cursor string) (*api.StorageObjects, string, error) {
+	namespace := n.pamlogix.namespace
+	if namespace == "" {
+		return n.NakamaModule.StorageIndexList(ctx, callerID, indexName, query, limit, order, cursor)
+	}
+	objects, nextCursor, err := n.NakamaModule.StorageIndexList(ctx, callerID, namespace+"_"+indexName, query, limit, order, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if objects != nil {
+		for _, object := range objects.Objects {
+			object.Collection = strings.TrimPrefix(object.Collection, namespace+"_")
+		}
+	}
+	return objects, nextCursor, nil
+}
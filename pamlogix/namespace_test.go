@@ -0,0 +1,160 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionResolverNakama_Namespace(t *testing.T) {
+	ctx := context.Background()
+	storage := newBenchNakama()
+	arena := &collectionResolverNakama{NakamaModule: storage, pamlogix: &pamlogixImpl{namespace: "arena"}}
+	campaign := &collectionResolverNakama{NakamaModule: storage, pamlogix: &pamlogixImpl{namespace: "campaign"}}
+
+	write := &runtime.StorageWrite{Collection: "state", Key: "user_state", UserID: "user1", Value: `{"a":1}`}
+	acks, err := arena.StorageWrite(ctx, []*runtime.StorageWrite{write})
+	require.NoError(t, err)
+	require.Len(t, acks, 1)
+	assert.Equal(t, "state", acks[0].Collection)
+	assert.Equal(t, "state", write.Collection)
+	_, err = campaign.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: "state", Key: "user_state", UserID: "user1", Value: `{"a":2}`}})
+	require.NoError(t, err)
+
+	// Each instance keeps its own copy in its namespaced collection
+	objects, err := storage.StorageRead(ctx, []*runtime.StorageRead{{Collection: "arena_state", Key: "user_state", UserID: "user1"}})
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, `{"a":1}`, objects[0].Value)
+
+	read := []*runtime.StorageRead{{Collection: "state", Key: "user_state", UserID: "user1"}}
+	objects, err = campaign.StorageRead(ctx, read)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "state", objects[0].Collection)
+	assert.Equal(t, `{"a":2}`, objects[0].Value)
+
+	objects, _, err = arena.StorageList(ctx, "", "user1", "state", 100, "")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "state", objects[0].Collection)
+	assert.Equal(t, `{"a":1}`, objects[0].Value)
+
+	require.NoError(t, arena.StorageDelete(ctx, []*runtime.StorageDelete{{Collection: "state", Key: "user_state", UserID: "user1"}}))
+	objects, err = arena.StorageRead(ctx, read)
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+	objects, err = campaign.StorageRead(ctx, read)
+	require.NoError(t, err)
+	assert.Len(t, objects, 1)
+}
+
+func TestCollectionResolverNakama_Resolver(t *testing.T) {
+	storage := newBenchNakama()
+	pl := &pamlogixImpl{namespace: "arena"}
+	pl.SetCollectionResolver(func(ctx context.Context, systemType SystemType, collection string) (string, error) {
+		return systemNames[systemType] + "_" + collection, nil
+	})
+	nk := &collectionResolverNakama{NakamaModule: storage, pamlogix: pl}
+
+	ctx := withSystemType(context.Background(), SystemTypeTutorials)
+	_, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: tutorialsStorageCollection, Key: userTutorialsStorageKey, UserID: "user1", Value: `{}`}})
+	require.NoError(t, err)
+
+	// The resolver applies before the namespace
+	objects, err := storage.StorageRead(ctx, []*runtime.StorageRead{{Collection: "arena_tutorials_" + tutorialsStorageCollection, Key: userTutorialsStorageKey, UserID: "user1"}})
+	require.NoError(t, err)
+	assert.Len(t, objects, 1)
+}
+
+func TestCollectionResolverInitializer_RegisterRpc(t *testing.T) {
+	storage := newBenchNakama()
+	pl := &pamlogixImpl{namespace: "arena"}
+	initializer := &rpcRecordingInitializer{rpcs: make(map[string]func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error))}
+
+	var resolver runtime.Initializer = &collectionResolverInitializer{Initializer: initializer, pamlogix: pl}
+	require.NoError(t, resolver.RegisterRpc(RpcIdEconomySummary, func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		_, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: "state", Key: "rpc", Value: payload}})
+		return "", err
+	}))
+
+	rpc, found := initializer.rpcs[NamespacedRpcId("arena", RpcIdEconomySummary)]
+	require.True(t, found)
+	assert.Equal(t, "arena_"+RpcIdEconomySummary, NamespacedRpcId("arena", RpcIdEconomySummary))
+	assert.Equal(t, RpcIdEconomySummary, NamespacedRpcId("", RpcIdEconomySummary))
+
+	_, err := rpc(context.Background(), &mockLogger{}, nil, storage, "{}")
+	require.NoError(t, err)
+	objects, err := storage.StorageRead(context.Background(), []*runtime.StorageRead{{Collection: "arena_state", Key: "rpc"}})
+	require.NoError(t, err)
+	assert.Len(t, objects, 1)
+}
+
+func TestCollectionResolverNakama_StoragePermissions(t *testing.T) {
+	ctx := context.Background()
+	storage := newBenchNakama()
+	publicRead := runtime.STORAGE_PERMISSION_PUBLIC_READ
+	pl := &pamlogixImpl{systems: map[SystemType]System{
+		SystemTypeBase: NewBaseSystem(&BaseSystemConfig{
+			StoragePermissions: map[string]*BaseSystemConfigStoragePermissions{
+				inventoryStorageCollection: {Read: &publicRead},
+			},
+		}),
+	}}
+	nk := &collectionResolverNakama{NakamaModule: storage, pamlogix: pl}
+
+	inventoryWrite := &runtime.StorageWrite{Collection: inventoryStorageCollection, Key: "user_inventory", UserID: "user1", Value: `{}`, PermissionRead: runtime.STORAGE_PERMISSION_OWNER_READ, PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE}
+	_, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		inventoryWrite,
+		{Collection: statsStorageCollection, Key: userStatsStorageKey, UserID: "user1", Value: `{}`, PermissionRead: runtime.STORAGE_PERMISSION_OWNER_READ, PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, runtime.STORAGE_PERMISSION_OWNER_READ, inventoryWrite.PermissionRead)
+
+	// Only the configured permission of the configured collection changes
+	objects, err := storage.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: inventoryStorageCollection, Key: "user_inventory", UserID: "user1"},
+		{Collection: statsStorageCollection, Key: userStatsStorageKey, UserID: "user1"},
+	})
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	for _, object := range objects {
+		assert.Equal(t, int32(runtime.STORAGE_PERMISSION_OWNER_WRITE), object.PermissionWrite)
+		if object.Collection == inventoryStorageCollection {
+			assert.Equal(t, int32(runtime.STORAGE_PERMISSION_PUBLIC_READ), object.PermissionRead)
+		} else {
+			assert.Equal(t, int32(runtime.STORAGE_PERMISSION_OWNER_READ), object.PermissionRead)
+		}
+	}
+
+	invalidWrite := 2
+	assert.Error(t, validateStoragePermissions(map[string]*BaseSystemConfigStoragePermissions{inventoryStorageCollection: {Write: &invalidWrite}}))
+	assert.NoError(t, validateStoragePermissions(map[string]*BaseSystemConfigStoragePermissions{inventoryStorageCollection: {Read: &publicRead}}))
+}
+
+func TestNamespaceFromConfigs(t *testing.T) {
+	namespace, configs := namespaceFromConfigs([]SystemConfig{
+		WithEconomySystem("economy.json", true),
+		WithNamespace("arena"),
+		WithInventorySystem("inventory.json", true),
+	})
+	assert.Equal(t, "arena", namespace)
+	require.Len(t, configs, 2)
+	assert.Equal(t, SystemTypeEconomy, configs[0].GetType())
+	assert.Equal(t, SystemTypeInventory, configs[1].GetType())
+}
+
+// rpcRecordingInitializer keeps the RPCs registered through it by ID.
+type rpcRecordingInitializer struct {
+	runtime.Initializer
+	rpcs map[string]func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)
+}
+
+func (i *rpcRecordingInitializer) RegisterRpc(id string, fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) error {
+	i.rpcs[id] = fn
+	return nil
+}
@@ -0,0 +1,133 @@
+package pamlogix
+
+import (
+	"context"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	defaultNotificationDispatchQueueSize       = 10000
+	defaultNotificationDispatchBatchSize       = 100
+	defaultNotificationDispatchFlushIntervalMs = 200
+	defaultNotificationDispatchMaxRetries      = 3
+	notificationDispatchRetryBackoff           = 100 * time.Millisecond
+)
+
+// BaseSystemConfigNotificationDispatch sets how queued notifications are sent in the background. Zero values use the
+// defaults.
+type BaseSystemConfigNotificationDispatch struct {
+	// QueueSize is how many notifications can wait to be sent. Once it's full notifications are sent as they're made,
+	// so none are dropped. The default is 10000.
+	QueueSize int `json:"queue_size,omitempty"`
+	// BatchSize is the most notifications sent in one call. The default is 100.
+	BatchSize int `json:"batch_size,omitempty"`
+	// FlushIntervalMs is the longest a notification waits for its batch to fill. The default is 200 milliseconds.
+	FlushIntervalMs int64 `json:"flush_interval_ms,omitempty"`
+	// MaxRetries is how many more times a failed batch is sent, backing off between attempts. The default is three.
+	MaxRetries int `json:"max_retries,omitempty"`
+}
+
+// notificationDispatcher sends queued notifications in batches from a background goroutine, so the RPCs making them,
+// such as a bid outbidding someone, don't wait on the fan-out.
+type notificationDispatcher struct {
+	queue         chan *runtime.NotificationSend
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+}
+
+func newNotificationDispatcher(config *BaseSystemConfigNotificationDispatch) *notificationDispatcher {
+	d := &notificationDispatcher{
+		batchSize:     defaultNotificationDispatchBatchSize,
+		flushInterval: defaultNotificationDispatchFlushIntervalMs * time.Millisecond,
+		maxRetries:    defaultNotificationDispatchMaxRetries,
+	}
+	queueSize := defaultNotificationDispatchQueueSize
+	if config != nil {
+		if config.QueueSize > 0 {
+			queueSize = config.QueueSize
+		}
+		if config.BatchSize > 0 {
+			d.batchSize = config.BatchSize
+		}
+		if config.FlushIntervalMs > 0 {
+			d.flushInterval = time.Duration(config.FlushIntervalMs) * time.Millisecond
+		}
+		if config.MaxRetries > 0 {
+			d.maxRetries = config.MaxRetries
+		}
+	}
+	d.queue = make(chan *runtime.NotificationSend, queueSize)
+	return d
+}
+
+// enqueue queues the notification without blocking, and reports whether there was room for it.
+func (d *notificationDispatcher) enqueue(notification *runtime.NotificationSend) bool {
+	select {
+	case d.queue <- notification:
+		return true
+	default:
+		return false
+	}
+}
+
+// start sends the queued notifications for the life of the server.
+func (d *notificationDispatcher) start(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(d.flushInterval)
+		defer ticker.Stop()
+		batch := make([]*runtime.NotificationSend, 0, d.batchSize)
+		for {
+			select {
+			case notification := <-d.queue:
+				batch = append(batch, notification)
+				if len(batch) < d.batchSize {
+					continue
+				}
+			case <-ticker.C:
+				if len(batch) == 0 {
+					continue
+				}
+			}
+			d.send(ctx, logger, nk, batch)
+			batch = make([]*runtime.NotificationSend, 0, d.batchSize)
+		}
+	}()
+}
+
+// send sends a batch of notifications, retrying with a doubling backoff. A batch still failing after the last retry
+// is logged and dropped.
+func (d *notificationDispatcher) send(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, batch []*runtime.NotificationSend) {
+	backoff := notificationDispatchRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := nk.NotificationsSend(ctx, batch)
+		if err == nil {
+			return
+		}
+		if attempt >= d.maxRetries {
+			logger.Error("Failed to send %d notifications after %d attempts: %v", len(batch), attempt+1, err)
+			return
+		}
+		logger.Warn("Failed to send %d notifications, retrying in %v: %v", len(batch), backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// deliverNotification queues the notification with the dispatcher, or sends it straight away when there's no
+// dispatcher or its queue is full.
+func deliverNotification(ctx context.Context, nk runtime.NakamaModule, dispatcher *notificationDispatcher, userID, subject string, content map[string]interface{}, code int) error {
+	if dispatcher != nil && dispatcher.enqueue(&runtime.NotificationSend{
+		UserID:     userID,
+		Subject:    subject,
+		Content:    content,
+		Code:       code,
+		Persistent: true,
+	}) {
+		return nil
+	}
+	return nk.NotificationSend(ctx, userID, subject, content, code, "", true)
+}
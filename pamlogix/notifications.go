@@ -0,0 +1,491 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	notificationsStorageCollection       = "notification_settings"
+	notificationPreferencesStorageKey    = "preferences"
+	notificationDigestStorageKey         = "digest"
+	defaultNotificationDigestIntervalSec = 3600
+	notificationDigestMaxEntries         = 50
+)
+
+// Events with a notification template. Templates for these can be overridden in the base system config, and game code
+// can send any of them, or its own events, through the BaseSystem.
+const (
+	NotificationEventAuctionNewBid        = "auction_new_bid"
+	NotificationEventAuctionOutbid        = "auction_outbid"
+	NotificationEventAuctionWon           = "auction_won"
+	NotificationEventAuctionBidRestored   = "auction_bid_restored"
+	NotificationEventDonationFulfilled    = "donation_fulfilled"
+	NotificationEventEnergyFull           = "energy_full"
+	NotificationEventEnergyGift           = "energy_gift"
+	NotificationEventEventEnded           = "event_ended"
+	NotificationEventEventCancelled       = "event_cancelled"
+	NotificationEventEventCohortMerged    = "event_cohort_merged"
+	NotificationEventTournamentReward     = "tournament_reward"
+	NotificationEventProgressionMilestone = "progression_milestone"
+	NotificationEventReferralMilestone    = "referral_milestone"
+	NotificationEventDataExportReady      = "data_export_ready"
+
+	// NotificationEventDigest is the notification that delivers the batched low-priority and quiet hours notifications.
+	NotificationEventDigest = "notification_digest"
+)
+
+// Notification categories users can mute in their NotificationPreferences.
+const (
+	NotificationCategoryAuctions    = "auctions"
+	NotificationCategoryEconomy     = "economy"
+	NotificationCategoryEnergy      = "energy"
+	NotificationCategoryEvents      = "events"
+	NotificationCategoryProgression = "progression"
+	NotificationCategoryReferrals   = "referrals"
+)
+
+// Notification priorities. High priority notifications are sent straight away outside the user's quiet hours, low
+// priority ones always wait for the next digest.
+const (
+	NotificationPriorityHigh = "high"
+	NotificationPriorityLow  = "low"
+)
+
+// NotificationTemplate is the data definition for the notification sent on a system event. Titles and bodies may
+// reference the event's variables as "{{name}}", e.g. "{{auction_id}}".
+type NotificationTemplate struct {
+	Code     int    `json:"code,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Body     string `json:"body,omitempty"`
+	Category string `json:"category,omitempty"`
+	// Priority is "high" or "low". Empty means high.
+	Priority string `json:"priority,omitempty"`
+	// Localized overrides the title and body by the recipient's language tag, e.g. "de" or "pt-BR".
+	Localized map[string]*NotificationTemplateText `json:"localized,omitempty"`
+}
+
+// NotificationTemplateText is a localized title and body for a NotificationTemplate.
+type NotificationTemplateText struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+// defaultNotificationTemplates are used for every event the base system config doesn't override.
+var defaultNotificationTemplates = map[string]*NotificationTemplate{
+	NotificationEventAuctionNewBid: {
+		Code:     1001,
+		Title:    "New bid on your auction",
+		Body:     "Your auction received a new bid.",
+		Category: NotificationCategoryAuctions,
+		Priority: NotificationPriorityLow,
+	},
+	NotificationEventAuctionOutbid: {
+		Code:     1002,
+		Title:    "You have been outbid",
+		Body:     "Someone placed a higher bid on an auction you bid on.",
+		Category: NotificationCategoryAuctions,
+		Priority: NotificationPriorityHigh,
+	},
+	NotificationEventAuctionWon: {
+		Code:     1003,
+		Title:    "You won an auction",
+		Body:     "Your bid won the auction. Claim your reward!",
+		Category: NotificationCategoryAuctions,
+		Priority: NotificationPriorityHigh,
+	},
+	NotificationEventAuctionBidRestored: {
+		Code:     1004,
+		Title:    "You are the high bidder again",
+		Body:     "A higher bid was retracted, so your bid leads the auction again.",
+		Category: NotificationCategoryAuctions,
+		Priority: NotificationPriorityHigh,
+	},
+	NotificationEventDonationFulfilled: {
+		Code:     1101,
+		Title:    "Donation fulfilled",
+		Body:     "Your donation request has been fulfilled.",
+		Category: NotificationCategoryEconomy,
+		Priority: NotificationPriorityHigh,
+	},
+	NotificationEventEnergyFull: {
+		Code:     1201,
+		Title:    "Energy full",
+		Body:     "Your {{energy_id}} is full again.",
+		Category: NotificationCategoryEnergy,
+		Priority: NotificationPriorityLow,
+	},
+	NotificationEventEnergyGift: {
+		Code:     1202,
+		Title:    "You received a gift",
+		Body:     "A friend sent you {{amount}} {{energy_id}}. Claim it from your mailbox!",
+		Category: NotificationCategoryEnergy,
+		Priority: NotificationPriorityLow,
+	},
+	NotificationEventEventEnded: {
+		Code:     1301,
+		Title:    "Event ended",
+		Body:     "The event has ended. Claim your rewards!",
+		Category: NotificationCategoryEvents,
+		Priority: NotificationPriorityHigh,
+	},
+	NotificationEventEventCancelled: {
+		Code:     1302,
+		Title:    "Event cancelled",
+		Body:     "The event was cancelled. Any entry fee you paid has been refunded.",
+		Category: NotificationCategoryEvents,
+		Priority: NotificationPriorityHigh,
+	},
+	NotificationEventTournamentReward: {
+		Code:     1303,
+		Title:    "Tournament reward",
+		Body:     "You placed #{{rank}} in {{tournament}}. Your reward has been granted.",
+		Category: NotificationCategoryEvents,
+		Priority: NotificationPriorityHigh,
+	},
+	NotificationEventEventCohortMerged: {
+		Code:     1304,
+		Title:    "New event group",
+		Body:     "You've been moved to a fuller group for the event. Your score came with you.",
+		Category: NotificationCategoryEvents,
+		Priority: NotificationPriorityHigh,
+	},
+	NotificationEventProgressionMilestone: {
+		Code:     1401,
+		Title:    "Progression milestone",
+		Body:     "You reached {{progression}}. You're {{completion_percentage}}% of the way through.",
+		Category: NotificationCategoryProgression,
+		Priority: NotificationPriorityLow,
+	},
+	NotificationEventReferralMilestone: {
+		Code:     1501,
+		Title:    "Your friend is making progress",
+		Body:     "A friend you invited reached {{milestone}}. Your reward has been granted.",
+		Category: NotificationCategoryReferrals,
+		Priority: NotificationPriorityLow,
+	},
+	NotificationEventDataExportReady: {
+		Code:     1601,
+		Title:    "Your data export is ready",
+		Body:     "The copy of your game data you asked for is ready to download for the next few days.",
+		Priority: NotificationPriorityHigh,
+	},
+	NotificationEventDigest: {
+		Code:  1000,
+		Title: "You have {{count}} new notifications",
+	},
+}
+
+// notificationTemplate returns the configured template for the event, falling back to the default one.
+func notificationTemplate(templates map[string]*NotificationTemplate, event string) *NotificationTemplate {
+	if template, found := templates[event]; found && template != nil {
+		return template
+	}
+	return defaultNotificationTemplates[event]
+}
+
+// render returns the title and body for the language tag with the variables filled in. Localized text falls back to
+// the base language ("pt" for "pt-BR") and then to the template's own title and body.
+func (t *NotificationTemplate) render(langTag string, vars map[string]string) (string, string) {
+	title, body := t.Title, t.Body
+	if text := t.localizedText(langTag); text != nil {
+		if text.Title != "" {
+			title = text.Title
+		}
+		if text.Body != "" {
+			body = text.Body
+		}
+	}
+
+	if len(vars) == 0 {
+		return title, body
+	}
+	oldnew := make([]string, 0, len(vars)*2)
+	for name, value := range vars {
+		oldnew = append(oldnew, "{{"+name+"}}", value)
+	}
+	replacer := strings.NewReplacer(oldnew...)
+	return replacer.Replace(title), replacer.Replace(body)
+}
+
+func (t *NotificationTemplate) localizedText(langTag string) *NotificationTemplateText {
+	if langTag == "" || len(t.Localized) == 0 {
+		return nil
+	}
+	if text, found := t.Localized[langTag]; found {
+		return text
+	}
+	if base, _, found := strings.Cut(langTag, "-"); found {
+		return t.Localized[base]
+	}
+	return nil
+}
+
+// NotificationPreferences are a user's choices about which notifications they get and when.
+type NotificationPreferences struct {
+	MutedCategories []string                `json:"muted_categories,omitempty"`
+	QuietHours      *NotificationQuietHours `json:"quiet_hours,omitempty"`
+}
+
+// NotificationQuietHours is a daily do-not-disturb window in the user's local time, from StartHour up to but not
+// including EndHour. A window that starts later than it ends wraps past midnight, e.g. 22 to 7.
+type NotificationQuietHours struct {
+	StartHour        int `json:"start_hour"`
+	EndHour          int `json:"end_hour"`
+	UtcOffsetMinutes int `json:"utc_offset_minutes,omitempty"`
+}
+
+func (p *NotificationPreferences) muted(category string) bool {
+	if p == nil || category == "" {
+		return false
+	}
+	for _, muted := range p.MutedCategories {
+		if muted == category {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether t falls within the quiet hours.
+func (q *NotificationQuietHours) contains(t time.Time) bool {
+	if q == nil || q.StartHour == q.EndHour {
+		return false
+	}
+	hour := t.UTC().Add(time.Duration(q.UtcOffsetMinutes) * time.Minute).Hour()
+	if q.StartHour < q.EndHour {
+		return hour >= q.StartHour && hour < q.EndHour
+	}
+	return hour >= q.StartHour || hour < q.EndHour
+}
+
+func validateNotificationPreferences(prefs *NotificationPreferences) error {
+	for _, category := range prefs.MutedCategories {
+		if category == "" {
+			return runtime.NewError("muted category is empty", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+	}
+	if q := prefs.QuietHours; q != nil {
+		if q.StartHour < 0 || q.StartHour > 23 || q.EndHour < 0 || q.EndHour > 23 {
+			return runtime.NewError("quiet hours must be between 0 and 23", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		if q.UtcOffsetMinutes < -12*60 || q.UtcOffsetMinutes > 14*60 {
+			return runtime.NewError("quiet hours UTC offset is out of range", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+	}
+	return nil
+}
+
+// NotificationDigestEntry is a notification held back for the user's next digest.
+type NotificationDigestEntry struct {
+	Event         string                 `json:"event"`
+	Title         string                 `json:"title,omitempty"`
+	Body          string                 `json:"body,omitempty"`
+	Content       map[string]interface{} `json:"content,omitempty"`
+	CreateTimeSec int64                  `json:"create_time_sec"`
+}
+
+type notificationDigest struct {
+	Entries []*NotificationDigestEntry `json:"entries,omitempty"`
+}
+
+// notificationState is a user's notification preferences and pending digest, with the digest's storage version.
+type notificationState struct {
+	preferences   *NotificationPreferences
+	digest        *notificationDigest
+	digestVersion string
+}
+
+// due reports whether the digest has waited long enough since its oldest entry to be sent.
+func (d *notificationDigest) due(now time.Time, intervalSec int64) bool {
+	return len(d.Entries) > 0 && now.Unix()-d.Entries[0].CreateTimeSec >= intervalSec
+}
+
+func readNotificationState(ctx context.Context, nk runtime.NakamaModule, userID string) (*notificationState, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{Collection: notificationsStorageCollection, Key: notificationPreferencesStorageKey, UserID: userID},
+		{Collection: notificationsStorageCollection, Key: notificationDigestStorageKey, UserID: userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	state := &notificationState{
+		preferences:   &NotificationPreferences{},
+		digest:        &notificationDigest{},
+		digestVersion: storageLockVersionNone,
+	}
+	for _, object := range objects {
+		if object.UserId != userID {
+			continue
+		}
+		switch object.Key {
+		case notificationPreferencesStorageKey:
+			if err := json.Unmarshal([]byte(object.Value), state.preferences); err != nil {
+				return nil, err
+			}
+		case notificationDigestStorageKey:
+			if err := json.Unmarshal([]byte(object.Value), state.digest); err != nil {
+				return nil, err
+			}
+			state.digestVersion = object.Version
+		}
+	}
+	return state, nil
+}
+
+func writeNotificationDigest(ctx context.Context, nk runtime.NakamaModule, userID string, digest *notificationDigest, version string) error {
+	value, err := json.Marshal(digest)
+	if err != nil {
+		return err
+	}
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      notificationsStorageCollection,
+			Key:             notificationDigestStorageKey,
+			UserID:          userID,
+			Value:           string(value),
+			Version:         version,
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+	return err
+}
+
+func notificationDigestInterval(config *BaseSystemConfig) int64 {
+	if config == nil || config.NotificationDigestIntervalSec <= 0 {
+		return defaultNotificationDigestIntervalSec
+	}
+	return config.NotificationDigestIntervalSec
+}
+
+// sendTemplatedNotification sends the notification for an event to a user. When the base system is loaded it applies
+// the user's preferences and its templates, otherwise the default templates are sent straight away.
+func sendTemplatedNotification(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID, event string, vars map[string]string, content map[string]interface{}) error {
+	if pl != nil {
+		if baseSystem := pl.GetBaseSystem(); baseSystem != nil {
+			return baseSystem.SendNotification(ctx, logger, nk, userID, event, vars, content)
+		}
+	}
+	return sendNotification(ctx, logger, nk, nil, nil, userID, event, vars, content)
+}
+
+// sendNotification renders and sends the notification for an event. With a base config the user's preferences are
+// consulted first: muted categories are dropped, and low priority notifications or any sent during quiet hours are
+// added to the user's digest instead. The digest goes out once its oldest entry is older than the digest interval.
+// With a dispatcher the notification is queued for it rather than sent before returning.
+func sendNotification(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, config *BaseSystemConfig, dispatcher *notificationDispatcher, userID, event string, vars map[string]string, content map[string]interface{}) error {
+	var templates map[string]*NotificationTemplate
+	if config != nil {
+		templates = config.NotificationTemplates
+	}
+	template := notificationTemplate(templates, event)
+	if template == nil {
+		return runtime.NewError("notification template not found", NOT_FOUND_ERROR_CODE) // NOT_FOUND
+	}
+
+	if config == nil {
+		title, body := template.render(notificationLangTag(ctx, logger, nk, template, userID), vars)
+		return deliverNotification(ctx, nk, dispatcher, userID, title, notificationContent(content, body), template.Code)
+	}
+
+	state, err := readNotificationState(ctx, nk, userID)
+	if err != nil {
+		logger.Error("Failed to read notification preferences for user %s: %v", userID, err)
+		return err
+	}
+	if state.preferences.muted(template.Category) {
+		return nil
+	}
+
+	now := time.Now()
+	quiet := state.preferences.QuietHours.contains(now)
+	title, body := template.render(notificationLangTag(ctx, logger, nk, template, userID), vars)
+
+	if template.Priority != NotificationPriorityLow && !quiet {
+		if err := deliverNotification(ctx, nk, dispatcher, userID, title, notificationContent(content, body), template.Code); err != nil {
+			return err
+		}
+		if state.digest.due(now, notificationDigestInterval(config)) {
+			return flushNotificationDigest(ctx, logger, nk, config, dispatcher, userID, state)
+		}
+		return nil
+	}
+
+	state.digest.Entries = append(state.digest.Entries, &NotificationDigestEntry{
+		Event:         event,
+		Title:         title,
+		Body:          body,
+		Content:       content,
+		CreateTimeSec: now.Unix(),
+	})
+	if len(state.digest.Entries) > notificationDigestMaxEntries {
+		state.digest.Entries = state.digest.Entries[len(state.digest.Entries)-notificationDigestMaxEntries:]
+	}
+
+	if !quiet && state.digest.due(now, notificationDigestInterval(config)) {
+		return flushNotificationDigest(ctx, logger, nk, config, dispatcher, userID, state)
+	}
+	return writeNotificationDigest(ctx, nk, userID, state.digest, state.digestVersion)
+}
+
+// flushNotificationDigest sends every pending digest entry as one notification and clears the digest.
+func flushNotificationDigest(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, config *BaseSystemConfig, dispatcher *notificationDispatcher, userID string, state *notificationState) error {
+	if len(state.digest.Entries) == 0 {
+		return nil
+	}
+
+	// Clear the digest first so a concurrent send can't deliver the same entries twice
+	if err := writeNotificationDigest(ctx, nk, userID, &notificationDigest{}, state.digestVersion); err != nil {
+		return err
+	}
+
+	template := notificationTemplate(config.NotificationTemplates, NotificationEventDigest)
+	title, body := template.render(notificationLangTag(ctx, logger, nk, template, userID), map[string]string{
+		"count": strconv.Itoa(len(state.digest.Entries)),
+	})
+	content := map[string]interface{}{
+		"type":          NotificationEventDigest,
+		"notifications": state.digest.Entries,
+	}
+	return deliverNotification(ctx, nk, dispatcher, userID, title, notificationContent(content, body), template.Code)
+}
+
+// notificationLangTag looks up the recipient's language, but only when the template has a translation to choose from.
+func notificationLangTag(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, template *NotificationTemplate, userID string) string {
+	if len(template.Localized) == 0 {
+		return ""
+	}
+	return userLangTag(ctx, logger, nk, userID)
+}
+
+// userLangTag looks up the user's language tag, or returns "" when it can't be read.
+func userLangTag(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) string {
+	users, err := nk.UsersGetId(ctx, []string{userID}, nil)
+	if err != nil {
+		logger.Warn("Failed to get language of user %s: %v", userID, err)
+		return ""
+	}
+	if len(users) == 0 {
+		return ""
+	}
+	return users[0].LangTag
+}
+
+// notificationContent copies the content and adds the rendered body as "body".
+func notificationContent(content map[string]interface{}, body string) map[string]interface{} {
+	copied := make(map[string]interface{}, len(content)+1)
+	for key, value := range content {
+		copied[key] = value
+	}
+	if body != "" {
+		copied["body"] = body
+	}
+	return copied
+}
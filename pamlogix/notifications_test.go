@@ -0,0 +1,239 @@
+package pamlogix
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationTemplate_Render(t *testing.T) {
+	template := &NotificationTemplate{
+		Title: "Outbid on {{auction_id}}",
+		Body:  "Bid again on {{auction_id}}",
+		Localized: map[string]*NotificationTemplateText{
+			"de": {Title: "Überboten bei {{auction_id}}"},
+		},
+	}
+	vars := map[string]string{"auction_id": "a1"}
+
+	title, body := template.render("", vars)
+	assert.Equal(t, "Outbid on a1", title)
+	assert.Equal(t, "Bid again on a1", body)
+
+	// A regional tag falls back to its base language, and a missing localized body to the template's own
+	title, body = template.render("de-AT", vars)
+	assert.Equal(t, "Überboten bei a1", title)
+	assert.Equal(t, "Bid again on a1", body)
+
+	title, _ = template.render("fr", vars)
+	assert.Equal(t, "Outbid on a1", title)
+}
+
+// notificationNakama records the notifications sent through it and keeps storage in memory.
+type notificationNakama struct {
+	*benchNakama
+	langTags map[string]string
+	sent     []sentNotification
+}
+
+type sentNotification struct {
+	userID  string
+	subject string
+	content map[string]interface{}
+	code    int
+}
+
+func newNotificationNakama() *notificationNakama {
+	return &notificationNakama{benchNakama: newBenchNakama(), langTags: make(map[string]string)}
+}
+
+func (n *notificationNakama) NotificationSend(ctx context.Context, userID, subject string, content map[string]interface{}, code int, sender string, persistent bool) error {
+	n.sent = append(n.sent, sentNotification{userID: userID, subject: subject, content: content, code: code})
+	return nil
+}
+
+func (n *notificationNakama) UsersGetId(ctx context.Context, userIDs []string, facebookIDs []string) ([]*api.User, error) {
+	users := make([]*api.User, 0, len(userIDs))
+	for _, userID := range userIDs {
+		users = append(users, &api.User{Id: userID, LangTag: n.langTags[userID]})
+	}
+	return users, nil
+}
+
+func TestBaseSendNotification_UsesConfiguredTemplate(t *testing.T) {
+	nk := newNotificationNakama()
+	nk.langTags["user1"] = "es"
+	baseSystem := NewBaseSystem(&BaseSystemConfig{
+		NotificationTemplates: map[string]*NotificationTemplate{
+			NotificationEventAuctionOutbid: {
+				Code:  2002,
+				Title: "Outbid!",
+				Body:  "Auction {{auction_id}}",
+				Localized: map[string]*NotificationTemplateText{
+					"es": {Title: "¡Superado!", Body: "Subasta {{auction_id}}"},
+				},
+			},
+		},
+	})
+
+	err := baseSystem.SendNotification(context.Background(), &mockLogger{}, nk, "user1", NotificationEventAuctionOutbid, map[string]string{"auction_id": "a1"}, map[string]interface{}{"type": "auction_outbid"})
+	require.NoError(t, err)
+
+	require.Len(t, nk.sent, 1)
+	assert.Equal(t, sentNotification{
+		userID:  "user1",
+		subject: "¡Superado!",
+		content: map[string]interface{}{"type": "auction_outbid", "body": "Subasta a1"},
+		code:    2002,
+	}, nk.sent[0])
+}
+
+func TestBaseSendNotification_MutedCategory(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newNotificationNakama()
+	baseSystem := NewBaseSystem(&BaseSystemConfig{})
+
+	_, err := baseSystem.SetNotificationPreferences(ctx, logger, nk, "user1", &NotificationPreferences{MutedCategories: []string{NotificationCategoryAuctions}})
+	require.NoError(t, err)
+
+	require.NoError(t, baseSystem.SendNotification(ctx, logger, nk, "user1", NotificationEventAuctionOutbid, nil, nil))
+	require.NoError(t, baseSystem.SendNotification(ctx, logger, nk, "user1", NotificationEventDonationFulfilled, nil, nil))
+
+	require.Len(t, nk.sent, 1)
+	assert.Equal(t, defaultNotificationTemplates[NotificationEventDonationFulfilled].Code, nk.sent[0].code)
+}
+
+func TestBaseSendNotification_DigestsLowPriorityAndQuietHours(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newNotificationNakama()
+	baseSystem := NewBaseSystem(&BaseSystemConfig{NotificationDigestIntervalSec: 3600})
+
+	// Low priority notifications wait for the digest
+	require.NoError(t, baseSystem.SendNotification(ctx, logger, nk, "user1", NotificationEventEnergyFull, map[string]string{"energy_id": "lives"}, nil))
+	assert.Empty(t, nk.sent)
+
+	// Quiet hours covering the whole day hold back high priority notifications too
+	hour := time.Now().UTC().Hour()
+	_, err := baseSystem.SetNotificationPreferences(ctx, logger, nk, "user1", &NotificationPreferences{
+		QuietHours: &NotificationQuietHours{StartHour: hour, EndHour: (hour + 1) % 24},
+	})
+	require.NoError(t, err)
+	require.NoError(t, baseSystem.SendNotification(ctx, logger, nk, "user1", NotificationEventAuctionOutbid, nil, nil))
+	assert.Empty(t, nk.sent)
+
+	// Nothing is flushed during quiet hours
+	require.NoError(t, baseSystem.FlushNotificationDigest(ctx, logger, nk, "user1"))
+	assert.Empty(t, nk.sent)
+
+	_, err = baseSystem.SetNotificationPreferences(ctx, logger, nk, "user1", &NotificationPreferences{})
+	require.NoError(t, err)
+	require.NoError(t, baseSystem.FlushNotificationDigest(ctx, logger, nk, "user1"))
+
+	require.Len(t, nk.sent, 1)
+	assert.Equal(t, "You have 2 new notifications", nk.sent[0].subject)
+	entries, ok := nk.sent[0].content["notifications"].([]*NotificationDigestEntry)
+	require.True(t, ok)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Your lives is full again.", entries[0].Body)
+	assert.Equal(t, NotificationEventAuctionOutbid, entries[1].Event)
+
+	// The digest is cleared once sent
+	require.NoError(t, baseSystem.FlushNotificationDigest(ctx, logger, nk, "user1"))
+	assert.Len(t, nk.sent, 1)
+}
+
+func TestNotificationQuietHours_Contains(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 1, 1, hour, 30, 0, 0, time.UTC) }
+
+	overnight := &NotificationQuietHours{StartHour: 22, EndHour: 7}
+	assert.True(t, overnight.contains(at(23)))
+	assert.True(t, overnight.contains(at(3)))
+	assert.False(t, overnight.contains(at(7)))
+	assert.False(t, overnight.contains(at(12)))
+
+	// 13:30 UTC is 22:30 at UTC+9
+	offset := &NotificationQuietHours{StartHour: 22, EndHour: 7, UtcOffsetMinutes: 9 * 60}
+	assert.True(t, offset.contains(at(13)))
+
+	assert.False(t, (&NotificationQuietHours{StartHour: 5, EndHour: 5}).contains(at(5)))
+	assert.False(t, (*NotificationQuietHours)(nil).contains(at(5)))
+}
+
+func TestSetNotificationPreferences_Validation(t *testing.T) {
+	baseSystem := NewBaseSystem(&BaseSystemConfig{})
+	_, err := baseSystem.SetNotificationPreferences(context.Background(), &mockLogger{}, newNotificationNakama(), "user1", &NotificationPreferences{
+		QuietHours: &NotificationQuietHours{StartHour: 22, EndHour: 24},
+	})
+	require.Error(t, err)
+}
+
+func TestSendTemplatedNotification_DefaultsWithoutBaseSystem(t *testing.T) {
+	nk := NewMockNakama(t)
+	nk.On("NotificationSend", mock.Anything, "user1", "Donation fulfilled", mock.Anything, 1101, "", true).Return(nil).Once()
+
+	err := sendTemplatedNotification(context.Background(), &mockLogger{}, nk, nil, "user1", NotificationEventDonationFulfilled, nil, nil)
+	require.NoError(t, err)
+
+	err = sendTemplatedNotification(context.Background(), &mockLogger{}, nk, nil, "user1", "unknown_event", nil, nil)
+	require.Error(t, err)
+
+	nk.AssertNotCalled(t, "UsersGetId", mock.Anything, mock.Anything, mock.Anything)
+	nk.AssertExpectations(t)
+}
+
+// batchNotificationNakama records the notification batches sent through it, failing the first few.
+type batchNotificationNakama struct {
+	*notificationNakama
+	mu       sync.Mutex
+	failures int
+	batches  [][]*runtime.NotificationSend
+}
+
+func (n *batchNotificationNakama) NotificationsSend(ctx context.Context, notifications []*runtime.NotificationSend) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.failures > 0 {
+		n.failures--
+		return errors.New("unavailable")
+	}
+	n.batches = append(n.batches, notifications)
+	return nil
+}
+
+func TestNotificationDispatcher(t *testing.T) {
+	ctx := context.Background()
+	nk := &batchNotificationNakama{notificationNakama: newNotificationNakama(), failures: 1}
+	dispatcher := newNotificationDispatcher(&BaseSystemConfigNotificationDispatch{QueueSize: 2, BatchSize: 2, FlushIntervalMs: 10, MaxRetries: 1})
+
+	// Once the queue is full notifications are sent straight away rather than dropped
+	for _, userID := range []string{"user1", "user2", "user3"} {
+		require.NoError(t, deliverNotification(ctx, nk, dispatcher, userID, "Outbid", nil, 1002))
+	}
+	require.Len(t, nk.sent, 1)
+	assert.Equal(t, "user3", nk.sent[0].userID)
+
+	// The queued notifications go out in one batch, which is retried after the first attempt fails
+	dispatcher.start(ctx, &mockLogger{}, nk)
+	require.Eventually(t, func() bool {
+		nk.mu.Lock()
+		defer nk.mu.Unlock()
+		return len(nk.batches) == 1
+	}, time.Second, 10*time.Millisecond)
+	nk.mu.Lock()
+	defer nk.mu.Unlock()
+	require.Len(t, nk.batches[0], 2)
+	assert.Equal(t, "user1", nk.batches[0][0].UserID)
+	assert.Equal(t, "user2", nk.batches[0][1].UserID)
+	assert.True(t, nk.batches[0][0].Persistent)
+	assert.Equal(t, 0, nk.failures)
+}
@@ -0,0 +1,146 @@
+package pamlogix
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// List RPCs hand out opaque cursors: a versioned envelope around either an offset into a sorted list or a Nakama
+// cursor. Plain integer offsets and raw Nakama cursors handed out before the envelope are still accepted for one
+// release.
+const (
+	pageCursorPrefix = "p1."
+
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+
+	// maxPageCursorHistory is how many pages back a Nakama cursor list can go with its previous page cursors.
+	maxPageCursorHistory = 10
+)
+
+// pageCursor is the envelope of a list cursor.
+type pageCursor struct {
+	Offset int    `json:"o,omitempty"`
+	Nakama string `json:"n,omitempty"`
+	// History holds the Nakama cursors of the pages before this one, oldest first, so lists paged by Nakama cursors
+	// can go back.
+	History []string `json:"h,omitempty"`
+}
+
+func encodePageCursor(cursor *pageCursor) string {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return pageCursorPrefix + base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageCursor unwraps a cursor envelope, reporting false for a cursor handed out before envelopes were used.
+func decodePageCursor(cursor string) (*pageCursor, bool, error) {
+	encoded, found := strings.CutPrefix(cursor, pageCursorPrefix)
+	if !found {
+		return nil, false, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, true, ErrBadInput
+	}
+	decoded := &pageCursor{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		return nil, true, ErrBadInput
+	}
+	return decoded, true, nil
+}
+
+// pageLimit validates the limit of a list request, defaulting it when unset and capping it at the maximum page size.
+func pageLimit(limit, defaultLimit, maxLimit int) (int, error) {
+	switch {
+	case limit < 0:
+		return 0, ErrBadInput
+	case limit == 0:
+		return defaultLimit, nil
+	default:
+		return min(limit, maxLimit), nil
+	}
+}
+
+// decodeOffsetCursor returns the offset a cursor of an offset paged list points at.
+func decodeOffsetCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	decoded, ok, err := decodePageCursor(cursor)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		// A plain integer offset from before cursors were opaque
+		offset, err := strconv.Atoi(cursor)
+		if err != nil {
+			return 0, ErrBadInput
+		}
+		decoded = &pageCursor{Offset: offset}
+	}
+	if decoded.Offset < 0 {
+		return 0, ErrBadInput
+	}
+	return decoded.Offset, nil
+}
+
+// offsetPage returns the bounds of the page of a list of total entries starting at offset, along with the cursors of
+// the next and previous pages.
+func offsetPage(offset, limit, total int) (start, end int, nextCursor, prevCursor string) {
+	start = min(offset, total)
+	end = min(start+limit, total)
+	if end < total {
+		nextCursor = encodePageCursor(&pageCursor{Offset: end})
+	}
+	if start > 0 {
+		prevCursor = encodePageCursor(&pageCursor{Offset: max(start-limit, 0)})
+	}
+	return start, end, nextCursor, prevCursor
+}
+
+// decodeNakamaCursor returns the Nakama cursor a cursor of a Nakama paged list points at, and the Nakama cursors of
+// the pages before it.
+func decodeNakamaCursor(cursor string) (string, []string, error) {
+	if cursor == "" {
+		return "", nil, nil
+	}
+	decoded, ok, err := decodePageCursor(cursor)
+	if err != nil {
+		return "", nil, err
+	}
+	if !ok {
+		// A raw Nakama cursor from before cursors were wrapped
+		return cursor, nil, nil
+	}
+	return decoded.Nakama, decoded.History, nil
+}
+
+// nakamaPageCursors returns the cursors of the pages after and before the page fetched with the given Nakama cursor.
+// Only the last maxPageCursorHistory pages can be gone back to.
+func nakamaPageCursors(current string, history []string, nakamaNextCursor string) (nextCursor, prevCursor string) {
+	if nakamaNextCursor != "" {
+		nextHistory := append(append(make([]string, 0, len(history)+1), history...), current)
+		if len(nextHistory) > maxPageCursorHistory {
+			nextHistory = nextHistory[len(nextHistory)-maxPageCursorHistory:]
+		}
+		nextCursor = encodePageCursor(&pageCursor{Nakama: nakamaNextCursor, History: nextHistory})
+	}
+	if len(history) > 0 {
+		last := len(history) - 1
+		prevCursor = encodePageCursor(&pageCursor{Nakama: history[last], History: history[:last]})
+	}
+	return nextCursor, prevCursor
+}
+
+// wrapNakamaCursor wraps a Nakama cursor which already pages both ways, such as those of leaderboard records.
+func wrapNakamaCursor(nakamaCursor string) string {
+	if nakamaCursor == "" {
+		return ""
+	}
+	return encodePageCursor(&pageCursor{Nakama: nakamaCursor})
+}
@@ -0,0 +1,91 @@
+package pamlogix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffsetPagination(t *testing.T) {
+	start, end, next, prev := offsetPage(0, 10, 25)
+	assert.Equal(t, 0, start)
+	assert.Equal(t, 10, end)
+	assert.Empty(t, prev)
+
+	offset, err := decodeOffsetCursor(next)
+	require.NoError(t, err)
+	assert.Equal(t, 10, offset)
+
+	start, end, next, prev = offsetPage(20, 10, 25)
+	assert.Equal(t, 20, start)
+	assert.Equal(t, 25, end)
+	assert.Empty(t, next)
+	offset, err = decodeOffsetCursor(prev)
+	require.NoError(t, err)
+	assert.Equal(t, 10, offset)
+
+	// Plain integer offsets from before cursors were opaque are still accepted
+	offset, err = decodeOffsetCursor("15")
+	require.NoError(t, err)
+	assert.Equal(t, 15, offset)
+
+	for _, cursor := range []string{"abc", "-5", pageCursorPrefix + "!!", encodePageCursor(&pageCursor{Offset: -1})} {
+		_, err = decodeOffsetCursor(cursor)
+		assert.ErrorIs(t, err, ErrBadInput, cursor)
+	}
+}
+
+func TestNakamaPagination(t *testing.T) {
+	// Raw Nakama cursors from before cursors were wrapped are still accepted
+	cursor, history, err := decodeNakamaCursor("raw")
+	require.NoError(t, err)
+	assert.Equal(t, "raw", cursor)
+	assert.Empty(t, history)
+
+	// Walk forward three pages, then back to the first
+	next, prev := nakamaPageCursors("", nil, "page2")
+	assert.Empty(t, prev)
+	cursor, history, err = decodeNakamaCursor(next)
+	require.NoError(t, err)
+	assert.Equal(t, "page2", cursor)
+
+	next, _ = nakamaPageCursors(cursor, history, "page3")
+	cursor, history, err = decodeNakamaCursor(next)
+	require.NoError(t, err)
+	assert.Equal(t, "page3", cursor)
+
+	next, prev = nakamaPageCursors(cursor, history, "")
+	assert.Empty(t, next)
+	cursor, history, err = decodeNakamaCursor(prev)
+	require.NoError(t, err)
+	assert.Equal(t, "page2", cursor)
+
+	_, prev = nakamaPageCursors(cursor, history, "page3")
+	cursor, _, err = decodeNakamaCursor(prev)
+	require.NoError(t, err)
+	assert.Empty(t, cursor)
+
+	// Only the latest pages can be gone back to
+	history = nil
+	cursor = ""
+	for i := 0; i < maxPageCursorHistory+5; i++ {
+		next, _ = nakamaPageCursors(cursor, history, "more")
+		cursor, history, err = decodeNakamaCursor(next)
+		require.NoError(t, err)
+	}
+	assert.Len(t, history, maxPageCursorHistory)
+}
+
+func TestPageLimit(t *testing.T) {
+	limit, err := pageLimit(0, defaultPageLimit, maxPageLimit)
+	require.NoError(t, err)
+	assert.Equal(t, defaultPageLimit, limit)
+
+	limit, err = pageLimit(maxPageLimit+1, defaultPageLimit, maxPageLimit)
+	require.NoError(t, err)
+	assert.Equal(t, maxPageLimit, limit)
+
+	_, err = pageLimit(-1, defaultPageLimit, maxPageLimit)
+	assert.ErrorIs(t, err, ErrBadInput)
+}
@@ -2,9 +2,11 @@ package pamlogix
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"io"
 
+	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
@@ -14,32 +16,98 @@ type pamlogixImpl struct {
 	publishers         []Publisher
 	afterAuthenticate  AfterAuthenticateFn
 	collectionResolver CollectionResolverFn
+	namespace          string
+	textModeration     TextModerationFn
+	jsonPolicy         *JsonPolicy
 
 	// Store systems in a map by type
 	systems map[SystemType]System
+	// rpcIds are the IDs of the registered RPCs, reported by the version handshake
+	rpcIds []string
+	// jobs runs the background jobs
+	jobs *jobScheduler
 }
 
 // Init initializes a Pamlogix type with the configurations provided.
 func Init(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, initializer runtime.Initializer, configs ...SystemConfig) (Pamlogix, error) {
+	namespace, configs := namespaceFromConfigs(configs)
+
 	// Create a new pamlogix implementation
 	pl := &pamlogixImpl{
 		personalizers:      make([]Personalizer, 0),
 		publishers:         make([]Publisher, 0),
 		collectionResolver: nil,
+		namespace:          namespace,
 		afterAuthenticate:  nil,
 		systems:            make(map[SystemType]System),
 	}
 
-	// Initialize systems based on provided configs
-	for _, config := range configs {
+	// Storage is kept in the collections the collection resolver and namespace resolve to, both for RPCs and for the
+	// work done at startup and in the background
+	nk = &collectionResolverNakama{NakamaModule: nk, pamlogix: pl}
+	initializer = &collectionResolverInitializer{Initializer: initializer, pamlogix: pl}
+	// Every RPC gets its own wallet and storage cache, so the systems it calls don't re-read the same user's state
+	initializer = &requestCacheInitializer{Initializer: initializer}
+	// The version handshake tells clients which RPCs are registered
+	initializer = &rpcIdsInitializer{Initializer: initializer, pamlogix: pl}
+
+	// Initialize systems after the systems they depend on, failing startup if a required one isn't configured
+	ordered, err := orderSystemConfigs(configs)
+	if err != nil {
+		logger.Error("Invalid system configuration: %v", err)
+		return nil, err
+	}
+	logSystemDependencies(logger, ordered)
+	for _, config := range ordered {
 		if err := pl.initSystem(ctx, logger, nk, initializer, config); err != nil {
 			return nil, err
 		}
 	}
+	if err := initializer.RegisterRpc(RpcIdVersion, rpcVersion(pl)); err != nil {
+		return nil, err
+	}
 
-	// Register UnlockableRewardedVideoPublisher if Unlockables system is present
+	// Register UnlockableRewardedVideoPublisher if Unlockables system is present and doesn't disable it
 	if unlockables, ok := pl.systems[SystemTypeUnlockables].(UnlockablesSystem); ok {
-		pl.AddPublisher(&UnlockableRewardedVideoPublisher{Unlockables: unlockables})
+		var rewardedVideo *UnlockablesConfigRewardedVideo
+		if unlockablesConfig, ok := unlockables.GetConfig().(*UnlockablesConfig); ok && unlockablesConfig != nil {
+			rewardedVideo = unlockablesConfig.RewardedVideo
+		}
+		if rewardedVideo == nil || !rewardedVideo.Disabled {
+			pl.AddPublisher(&UnlockableRewardedVideoPublisher{Unlockables: unlockables, Config: rewardedVideo})
+		}
+	}
+	// Register IncentiveMilestonesPublisher if the Incentives system has milestones to reach
+	if incentives, ok := pl.systems[SystemTypeIncentives].(*NakamaIncentivesSystem); ok && incentives.hasMilestones() {
+		pl.AddPublisher(&IncentiveMilestonesPublisher{Incentives: incentives})
+	}
+	// Register StatsEventsPublisher if the Stats system has stats following other systems' events
+	if stats, ok := pl.systems[SystemTypeStats].(*NakamaStatsSystem); ok && stats.hasEventStats() {
+		pl.AddPublisher(&StatsEventsPublisher{Stats: stats})
+	}
+	// Send notifications in the background if the base config sets up notification dispatch
+	if base, ok := pl.systems[SystemTypeBase].(*BasePamlogix); ok && base.config.NotificationDispatch != nil {
+		base.startNotificationDispatcher(ctx, logger, nk)
+	}
+	// Run the background jobs, such as sweeping expired storage, on the schedules the base config sets
+	var jobsBaseConfig *BaseSystemConfig
+	if baseSystem := pl.GetBaseSystem(); baseSystem != nil {
+		jobsBaseConfig, _ = baseSystem.GetConfig().(*BaseSystemConfig)
+	}
+	if jobsBaseConfig != nil {
+		pl.jobs = newJobScheduler(jobsBaseConfig.Jobs)
+	} else {
+		pl.jobs = newJobScheduler(nil)
+	}
+	pl.registerBuiltinJobs(jobsBaseConfig)
+	pl.jobs.start(ctx, logger, nk)
+	// Aggregate recent auction sales into the valuations shown on listings
+	if auctions, ok := pl.systems[SystemTypeAuctions].(*AuctionsPamlogix); ok && auctions.config.Valuation != nil {
+		auctions.startValuations(ctx, logger, nk)
+	}
+	// Prepare the content the content calendar schedules ahead of its start
+	if baseConfig := contentCalendarConfig(pl); baseConfig != nil {
+		pl.startContentCalendar(ctx, logger, nk, baseConfig.ContentCalendarIntervalSec)
 	}
 
 	return pl, nil
@@ -75,7 +143,11 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 			logger.Error("Failed to parse Base system config: %v", err)
 			return err
 		}
-		system = &BasePamlogix{}
+		if err := validateStoragePermissions(baseConfig.StoragePermissions); err != nil {
+			logger.Error("Invalid Base system config: %v", err)
+			return err
+		}
+		system = NewBaseSystem(baseConfig)
 
 	case SystemTypeEnergy:
 		energyConfig := &EnergyConfig{}
@@ -83,6 +155,14 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 			logger.Error("Failed to parse Energy system config: %v", err)
 			return err
 		}
+		if err := validateEnergyRefillOffers(energyConfig); err != nil {
+			logger.Error("Invalid Energy system config: %v", err)
+			return err
+		}
+		if err := validateEnergyOverflowCurrencies(energyConfig); err != nil {
+			logger.Error("Invalid Energy system config: %v", err)
+			return err
+		}
 		system = NewNakamaEnergySystem(energyConfig)
 
 	case SystemTypeInventory:
@@ -91,7 +171,12 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 			logger.Error("Failed to parse Inventory system config: %v", err)
 			return err
 		}
-		system = NewNakamaInventorySystem(inventoryConfig)
+		inventorySystem := NewNakamaInventorySystem(inventoryConfig)
+		if err := inventorySystem.registerPropertyIndex(initializer); err != nil {
+			logger.Error("Failed to register inventory property index: %v", err)
+			return err
+		}
+		system = inventorySystem
 
 	case SystemTypeEconomy:
 		economyConfig := &EconomyConfig{}
@@ -99,6 +184,10 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 			logger.Error("Failed to parse Economy system config: %v", err)
 			return err
 		}
+		if err := validateStoreItemRules(economyConfig); err != nil {
+			logger.Error("Invalid Economy system config: %v", err)
+			return err
+		}
 		system = NewNakamaEconomySystem(economyConfig)
 
 	case SystemTypeAchievements:
@@ -107,15 +196,32 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 			logger.Error("Failed to parse Achievements system config: %v", err)
 			return err
 		}
+		if err := validateAutoClaimRules(achievementsConfig); err != nil {
+			logger.Error("Invalid Achievements system config: %v", err)
+			return err
+		}
 		system = NewNakamaAchievementsSystem(achievementsConfig)
 
 	case SystemTypeLeaderboards:
-		leaderboardConfig := &LeaderboardConfig{}
-		if err := json.Unmarshal(configBytes, leaderboardConfig); err != nil {
+		leaderboardsConfig := &LeaderboardsConfig{}
+		if err := json.Unmarshal(configBytes, leaderboardsConfig); err != nil {
 			logger.Error("Failed to parse Leaderboards system config: %v", err)
 			return err
 		}
-		// Create leaderboards system instance
+		leaderboardsSystem := NewNakamaLeaderboardsSystem(leaderboardsConfig)
+		if err := leaderboardsSystem.CreateTournaments(ctx, logger, nk); err != nil {
+			return err
+		}
+		// Placement rewards are granted as each tournament season ends
+		if len(leaderboardsConfig.Tournaments) > 0 {
+			if err := initializer.RegisterTournamentEnd(func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, tournament *api.Tournament, end, reset int64) error {
+				return leaderboardsSystem.ProcessTournamentEnd(ctx, logger, nk, tournament, end, reset)
+			}); err != nil {
+				logger.Error("Failed to register tournament end hook: %v", err)
+				return err
+			}
+		}
+		system = leaderboardsSystem
 
 	case SystemTypeStats:
 		statsConfig := &StatsConfig{}
@@ -131,6 +237,14 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 			logger.Error("Failed to parse Teams system config: %v", err)
 			return err
 		}
+		textLimits := []*TextLimits{teamsConfig.ChatMessageLimits}
+		if teamsConfig.ProfileLimits != nil {
+			textLimits = append(textLimits, teamsConfig.ProfileLimits.DescriptionBlockTitle, teamsConfig.ProfileLimits.DescriptionBlockBody)
+		}
+		if err := compileTextLimits(textLimits...); err != nil {
+			logger.Error("Invalid Teams system config: %v", err)
+			return err
+		}
 		teamsSystem := NewNakamaTeamsSystem(teamsConfig)
 
 		// Set custom validation function if provided
@@ -165,7 +279,16 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 			logger.Error("Failed to parse EventLeaderboards system config: %v", err)
 			return err
 		}
-		system = NewNakamaEventLeaderboardsSystem(eventLeaderboardsConfig)
+		eventLeaderboardsSystem := NewNakamaEventLeaderboardsSystem(eventLeaderboardsConfig)
+		if err := eventLeaderboardsSystem.validateOperators(); err != nil {
+			logger.Error("Invalid EventLeaderboards system config: %v", err)
+			return err
+		}
+		if err := eventLeaderboardsSystem.validateRewardChoices(); err != nil {
+			logger.Error("Invalid EventLeaderboards system config: %v", err)
+			return err
+		}
+		system = eventLeaderboardsSystem
 
 	case SystemTypeProgression:
 		progressionConfig := &ProgressionConfig{}
@@ -189,7 +312,20 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 			logger.Error("Failed to parse Auctions system config: %v", err)
 			return err
 		}
-		system = NewNakamaAuctionsSystem(auctionsConfig)
+		if err := validateAuctionFeeSink(auctionsConfig.FeeSink); err != nil {
+			logger.Error("Invalid Auctions system config: %v", err)
+			return err
+		}
+		if err := compileTextLimits(auctionsConfig.ItemPropertyLimits); err != nil {
+			logger.Error("Invalid Auctions system config: %v", err)
+			return err
+		}
+		auctionsSystem := NewNakamaAuctionsSystem(auctionsConfig).(*AuctionsPamlogix)
+		if err := auctionsSystem.registerSearchIndex(initializer); err != nil {
+			logger.Error("Failed to register auction search index: %v", err)
+			return err
+		}
+		system = auctionsSystem
 
 	case SystemTypeStreaks:
 		streaksConfig := &StreaksConfig{}
@@ -224,6 +360,12 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 		// Store the system
 		p.systems[config.GetType()] = system
 
+		// For base system, set the Pamlogix reference so feature flags can see which systems are loaded
+		if baseSystem, ok := system.(*BasePamlogix); ok {
+			baseSystem.SetPamlogix(p)
+			logger.Info("Set Pamlogix reference in base system for cross-system communication")
+		}
+
 		// For energy system, set the Pamlogix reference to enable cross-system communication
 		if energySystem, ok := system.(*NakamaEnergySystem); ok {
 			energySystem.SetPamlogix(p)
@@ -278,6 +420,12 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 			logger.Info("Set Pamlogix reference in event leaderboards system for cross-system communication")
 		}
 
+		// For leaderboards system, set the Pamlogix reference to enable cross-system communication
+		if leaderboardsSystem, ok := system.(*NakamaLeaderboardsSystem); ok {
+			leaderboardsSystem.SetPamlogix(p)
+			logger.Info("Set Pamlogix reference in leaderboards system for cross-system communication")
+		}
+
 		// For economy system, set the Pamlogix reference to enable cross-system communication
 		if economySystem, ok := system.(*NakamaEconomySystem); ok {
 			economySystem.SetPamlogix(p)
@@ -290,6 +438,10 @@ func (p *pamlogixImpl) initSystem(ctx context.Context, logger runtime.Logger, nk
 		//if err := p.registerSystemRpcs(initializer, config.GetType()); err != nil {
 		//	return err
 		//}
+		// Every RPC of the system logs tagged with the system and call, at the system's log level
+		initializer = &loggingInitializer{Initializer: initializer, pamlogix: p, systemType: config.GetType()}
+		// Every RPC of the system checks the system's rollout before it runs
+		initializer = &rolloutInitializer{Initializer: initializer, pamlogix: p, systemType: config.GetType()}
 		if err := p.registerSystemRpcs_Json(initializer, config.GetType()); err != nil {
 			return err
 		}
@@ -303,7 +455,7 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 	switch systemType {
 	case SystemTypeAchievements:
 		// Register Achievements system RPCs
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ACHIEVEMENTS_CLAIM.String(), rpcAchievementsClaim(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ACHIEVEMENTS_CLAIM.String(), rpcAchievementsClaim(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ACHIEVEMENTS_GET.String(), rpcAchievementsGet(p)); err != nil {
@@ -331,43 +483,43 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 
 	case SystemTypeEconomy:
 		// Register Economy system RPCs
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_DONATION_CLAIM.String(), rpcEconomyDonationClaim(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_DONATION_CLAIM.String(), rpcEconomyDonationClaim(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_DONATION_GIVE.String(), rpcEconomyDonationGive(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_DONATION_GIVE.String(), rpcEconomyDonationGive(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_DONATION_GET.String(), rpcEconomyDonationGet(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_DONATION_REQUEST.String(), rpcEconomyDonationRequest(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_DONATION_REQUEST.String(), rpcEconomyDonationRequest(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_STORE_GET.String(), rpcEconomyStoreGet(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_GRANT.String(), rpcEconomyGrant(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_GRANT.String(), rpcEconomyGrant(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PURCHASE_INTENT.String(), rpcEconomyPurchaseIntent(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PURCHASE_INTENT.String(), rpcEconomyPurchaseIntent(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PURCHASE_ITEM.String(), rpcEconomyPurchaseItem(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PURCHASE_ITEM.String(), rpcEconomyPurchaseItem(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PURCHASE_RESTORE.String(), rpcEconomyPurchaseRestore(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PURCHASE_RESTORE.String(), rpcEconomyPurchaseRestore(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PLACEMENT_STATUS.String(), rpcEconomyPlacementStatus(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PLACEMENT_START.String(), rpcEconomyPlacementStart(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PLACEMENT_START.String(), rpcEconomyPlacementStart(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PLACEMENT_SUCCESS.String(), rpcEconomyPlacementSuccess(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PLACEMENT_SUCCESS.String(), rpcEconomyPlacementSuccess(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PLACEMENT_FAIL.String(), rpcEconomyPlacementFail(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PLACEMENT_FAIL.String(), rpcEconomyPlacementFail(p)); err != nil {
 			return err
 		}
 	case SystemTypeEventLeaderboards:
@@ -378,13 +530,25 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_GET.String(), rpcEventLeaderboardsGet(p)); err != nil {
 			return err
 		}
+		if err := initializer.RegisterRpc(RpcIdEventLeaderboardGlobalGet, rpcEventLeaderboardsGlobalGet(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEventLeaderboardCleanup, rpcEventLeaderboardsCleanup(p)); err != nil {
+			return err
+		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_UPDATE.String(), rpcEventLeaderboardsUpdate(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_CLAIM.String(), rpcEventLeaderboardsClaim(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_EVENT_LEADERBOARD_CLAIM.String(), rpcEventLeaderboardsClaim(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_EVENT_LEADERBOARD_ROLL.String(), rpcEventLeaderboardsRoll(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEventLeaderboardRollPreview, rpcEventLeaderboardsRollPreview(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_ROLL.String(), rpcEventLeaderboardsRoll(p)); err != nil {
+		if err := initializer.RegisterRpc(RpcIdEventLeaderboardStats, rpcEventLeaderboardsStats(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_DEBUG_FILL.String(), rpcEventLeaderboardsDebugFill(p)); err != nil {
@@ -398,10 +562,10 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ENERGY_GET.String(), rpcEnergyGet(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ENERGY_SPEND.String(), rpcEnergySpend(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ENERGY_SPEND.String(), rpcEnergySpend(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ENERGY_GRANT.String(), rpcEnergyGrant(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ENERGY_GRANT.String(), rpcEnergyGrant(p)); err != nil {
 			return err
 		}
 
@@ -413,13 +577,13 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_INVENTORY_LIST_INVENTORY.String(), rpcInventoryListInventory(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_INVENTORY_CONSUME.String(), rpcInventoryConsume(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_INVENTORY_CONSUME.String(), rpcInventoryConsume(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_INVENTORY_GRANT.String(), rpcInventoryGrant(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_INVENTORY_GRANT.String(), rpcInventoryGrant(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_INVENTORY_UPDATE.String(), rpcInventoryUpdate(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_INVENTORY_UPDATE.String(), rpcInventoryUpdate(p)); err != nil {
 			return err
 		}
 
@@ -454,7 +618,7 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 
 	case SystemTypeUnlockables:
 		// Register Unlockables system RPCs
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_CREATE.String(), rpcUnlockablesCreate(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_UNLOCKABLES_CREATE.String(), rpcUnlockablesCreate(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_GET.String(), rpcUnlockablesGet(p)); err != nil {
@@ -463,13 +627,13 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_UNLOCK_START.String(), rpcUnlockablesUnlockStart(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_PURCHASE_UNLOCK.String(), rpcUnlockablesPurchaseUnlock(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_UNLOCKABLES_PURCHASE_UNLOCK.String(), rpcUnlockablesPurchaseUnlock(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_PURCHASE_SLOT.String(), rpcUnlockablesPurchaseSlot(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_UNLOCKABLES_PURCHASE_SLOT.String(), rpcUnlockablesPurchaseSlot(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_CLAIM.String(), rpcUnlockablesClaim(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_UNLOCKABLES_CLAIM.String(), rpcUnlockablesClaim(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_QUEUE_ADD.String(), rpcUnlockablesQueueAdd(p)); err != nil {
@@ -490,19 +654,19 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_LIST.String(), rpcAuctionsList(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_BID.String(), rpcAuctionsBid(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_BID.String(), rpcAuctionsBid(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_CLAIM_BID.String(), rpcAuctionsClaimBid(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_CLAIM_BID.String(), rpcAuctionsClaimBid(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_CLAIM_CREATED.String(), rpcAuctionsClaimCreated(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_CLAIM_CREATED.String(), rpcAuctionsClaimCreated(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_CANCEL.String(), rpcAuctionsCancel(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_CANCEL.String(), rpcAuctionsCancel(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_CREATE.String(), rpcAuctionsCreate(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_CREATE.String(), rpcAuctionsCreate(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_LIST_BIDS.String(), rpcAuctionsListBids(p)); err != nil {
@@ -541,7 +705,7 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_STREAKS_UPDATE.String(), rpcStreaksUpdate(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_STREAKS_CLAIM.String(), rpcStreaksClaim(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_STREAKS_CLAIM.String(), rpcStreaksClaim(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_STREAKS_RESET.String(), rpcStreaksReset(p)); err != nil {
@@ -553,7 +717,7 @@ func (p *pamlogixImpl) registerSystemRpcs(initializer runtime.Initializer, syste
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_PROGRESSIONS_GET.String(), rpcProgressionsGet(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_PROGRESSIONS_PURCHASE.String(), rpcProgressionsPurchase(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_PROGRESSIONS_PURCHASE.String(), rpcProgressionsPurchase(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_PROGRESSIONS_UPDATE.String(), rpcProgressionsUpdate(p)); err != nil {
@@ -612,6 +776,24 @@ func (p *pamlogixImpl) SetCollectionResolver(fn CollectionResolverFn) {
 	p.collectionResolver = fn
 }
 
+// SetTextModeration sets a function that checks user-supplied text before it is stored or sent.
+func (p *pamlogixImpl) SetTextModeration(fn TextModerationFn) {
+	p.textModeration = fn
+}
+
+func (p *pamlogixImpl) getTextModeration() TextModerationFn {
+	return p.textModeration
+}
+
+// SetJsonPolicy sets how the JSON RPCs name and populate the fields of their payloads.
+func (p *pamlogixImpl) SetJsonPolicy(policy *JsonPolicy) {
+	p.jsonPolicy = policy
+}
+
+func (p *pamlogixImpl) getJsonPolicy() *JsonPolicy {
+	return p.jsonPolicy
+}
+
 // System getter implementations
 func (p *pamlogixImpl) GetAchievementsSystem() AchievementsSystem {
 	if sys, ok := p.systems[SystemTypeAchievements].(AchievementsSystem); ok {
@@ -751,7 +933,7 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 	switch systemType {
 	case SystemTypeAchievements:
 		// Register Achievements system JSON RPCs
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ACHIEVEMENTS_CLAIM.String(), rpcAchievementsClaim_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ACHIEVEMENTS_CLAIM.String(), rpcAchievementsClaim_Json(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ACHIEVEMENTS_GET.String(), rpcAchievementsGet_Json(p)); err != nil {
@@ -772,46 +954,124 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_BASE_SYNC.String(), rpcBaseSync(p)); err != nil {
 			return err
 		}
+		if err := initializer.RegisterRpc(RpcIdBaseNotificationPreferencesGet, rpcBaseNotificationPreferencesGet(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdBaseNotificationPreferencesSet, rpcBaseNotificationPreferencesSet(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdBaseAccountLock, rpcBaseAccountLock(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdBaseAccountUnlock, rpcBaseAccountUnlock(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdBaseAccountPurge, rpcBaseAccountPurge(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdBaseStorageSweep, rpcBaseStorageSweep(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdBaseContentCalendarGet, rpcBaseContentCalendarGet(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdBaseDataExport, rpcBaseDataExport(p)); err != nil {
+			return err
+		}
 
 	case SystemTypeEconomy:
 		// Register Economy system JSON RPCs
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_DONATION_CLAIM.String(), rpcEconomyDonationClaim_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_DONATION_CLAIM.String(), rpcEconomyDonationClaim_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_DONATION_GIVE.String(), rpcEconomyDonationGive_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_DONATION_GIVE.String(), rpcEconomyDonationGive_Json(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_DONATION_GET.String(), rpcEconomyDonationGet_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_DONATION_REQUEST.String(), rpcEconomyDonationRequest_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_DONATION_REQUEST.String(), rpcEconomyDonationRequest_Json(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_STORE_GET.String(), rpcEconomyStoreGet_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_GRANT.String(), rpcEconomyGrant_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_GRANT.String(), rpcEconomyGrant_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PURCHASE_INTENT.String(), rpcEconomyPurchaseIntent_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PURCHASE_INTENT.String(), rpcEconomyPurchaseIntent_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PURCHASE_ITEM.String(), rpcEconomyPurchaseItem_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PURCHASE_ITEM.String(), rpcEconomyPurchaseItem_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PURCHASE_RESTORE.String(), rpcEconomyPurchaseRestore_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PURCHASE_RESTORE.String(), rpcEconomyPurchaseRestore_Json(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PLACEMENT_STATUS.String(), rpcEconomyPlacementStatus_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PLACEMENT_START.String(), rpcEconomyPlacementStart_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PLACEMENT_START.String(), rpcEconomyPlacementStart_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PLACEMENT_SUCCESS.String(), rpcEconomyPlacementSuccess_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ECONOMY_PLACEMENT_FAIL.String(), rpcEconomyPlacementFail_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomyPurchaseTransactionsExport, rpcEconomyPurchaseTransactionsExport_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdEconomyPurchaseIntentCancel, rpcEconomyPurchaseIntentCancel_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdEconomyTeamPurchase, rpcEconomyTeamPurchase_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomyCanAfford, rpcEconomyCanAfford_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomyModifierJobStart, rpcEconomyModifierJobStart_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomyModifierJobGet, rpcEconomyModifierJobGet_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomyCurrencies, rpcEconomyCurrencies_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomyPlacementList, rpcEconomyPlacementList_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomySnapshotCreate, rpcEconomySnapshotCreate_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomySnapshotList, rpcEconomySnapshotList_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PLACEMENT_SUCCESS.String(), rpcEconomyPlacementSuccess_Json(p)); err != nil {
+		if err := initializer.RegisterRpc(RpcIdEconomySnapshotRestore, rpcEconomySnapshotRestore_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ECONOMY_PLACEMENT_FAIL.String(), rpcEconomyPlacementFail_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcIdEconomyDebit, rpcEconomyDebit_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomyEventLogList, rpcEconomyEventLogList_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomySummary, rpcEconomySummary_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdEconomySubscriptionPurchase, rpcEconomySubscriptionPurchase_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEconomySubscriptionList, rpcEconomySubscriptionList_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdEconomySubscriptionCancel, rpcEconomySubscriptionCancel_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdEconomyPurchaseGrantsReconcile, rpcEconomyPurchaseGrantsReconcile_Json(p)); err != nil {
 			return err
 		}
 
@@ -823,13 +1083,25 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_GET.String(), rpcEventLeaderboardsGet(p)); err != nil {
 			return err
 		}
+		if err := initializer.RegisterRpc(RpcIdEventLeaderboardGlobalGet, rpcEventLeaderboardsGlobalGet(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEventLeaderboardCleanup, rpcEventLeaderboardsCleanup(p)); err != nil {
+			return err
+		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_UPDATE.String(), rpcEventLeaderboardsUpdate(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_CLAIM.String(), rpcEventLeaderboardsClaim(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_EVENT_LEADERBOARD_CLAIM.String(), rpcEventLeaderboardsClaim(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_EVENT_LEADERBOARD_ROLL.String(), rpcEventLeaderboardsRoll(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEventLeaderboardRollPreview, rpcEventLeaderboardsRollPreview(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_ROLL.String(), rpcEventLeaderboardsRoll(p)); err != nil {
+		if err := initializer.RegisterRpc(RpcIdEventLeaderboardStats, rpcEventLeaderboardsStats(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_EVENT_LEADERBOARD_DEBUG_FILL.String(), rpcEventLeaderboardsDebugFill(p)); err != nil {
@@ -839,15 +1111,39 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 			return err
 		}
 
+	case SystemTypeLeaderboards:
+		// Register Leaderboards system JSON RPCs
+		if err := initializer.RegisterRpc(RpcIdLeaderboardsTournamentList, rpcLeaderboardsTournamentList_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdLeaderboardsTournamentJoin, rpcLeaderboardsTournamentJoin_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdLeaderboardsTournamentStandings, rpcLeaderboardsTournamentStandings_Json(p)); err != nil {
+			return err
+		}
+
 	case SystemTypeEnergy:
 		// Register Energy system JSON RPCs
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_ENERGY_GET.String(), rpcEnergyGet_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ENERGY_SPEND.String(), rpcEnergySpend_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ENERGY_SPEND.String(), rpcEnergySpend_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_ENERGY_GRANT.String(), rpcEnergyGrant_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_ENERGY_GRANT.String(), rpcEnergyGrant_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcIdEnergyGiftSend, rpcEnergyGiftSend_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdEnergyGiftList, rpcEnergyGiftList_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdEnergyGiftClaim, rpcEnergyGiftClaim_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdEnergySpendWithRefill, rpcEnergySpendWithRefill_Json(p)); err != nil {
 			return err
 		}
 
@@ -859,13 +1155,13 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_INVENTORY_LIST_INVENTORY.String(), rpcInventoryListInventory_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_INVENTORY_CONSUME.String(), rpcInventoryConsume_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_INVENTORY_CONSUME.String(), rpcInventoryConsume_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_INVENTORY_GRANT.String(), rpcInventoryGrant_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_INVENTORY_GRANT.String(), rpcInventoryGrant_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_INVENTORY_UPDATE.String(), rpcInventoryUpdate_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_INVENTORY_UPDATE.String(), rpcInventoryUpdate_Json(p)); err != nil {
 			return err
 		}
 
@@ -901,7 +1197,7 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 
 	case SystemTypeUnlockables:
 		// Register Unlockables system JSON RPCs
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_CREATE.String(), rpcUnlockablesCreateJson(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_UNLOCKABLES_CREATE.String(), rpcUnlockablesCreateJson(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_GET.String(), rpcUnlockablesGetJson(p)); err != nil {
@@ -910,13 +1206,13 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_UNLOCK_START.String(), rpcUnlockablesUnlockStartJson(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_PURCHASE_UNLOCK.String(), rpcUnlockablesPurchaseUnlockJson(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_UNLOCKABLES_PURCHASE_UNLOCK.String(), rpcUnlockablesPurchaseUnlockJson(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_PURCHASE_SLOT.String(), rpcUnlockablesPurchaseSlotJson(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_UNLOCKABLES_PURCHASE_SLOT.String(), rpcUnlockablesPurchaseSlotJson(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_CLAIM.String(), rpcUnlockablesClaimJson(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_UNLOCKABLES_CLAIM.String(), rpcUnlockablesClaimJson(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_UNLOCKABLES_QUEUE_ADD.String(), rpcUnlockablesQueueAddJson(p)); err != nil {
@@ -934,22 +1230,34 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_GET_TEMPLATES.String(), rpcAuctionsGetTemplates_Json(p)); err != nil {
 			return err
 		}
+		if err := initializer.RegisterRpc(RpcIdAuctionsGetTemplate, rpcAuctionsGetTemplate_Json(p)); err != nil {
+			return err
+		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_LIST.String(), rpcAuctionsList_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_BID.String(), rpcAuctionsBid_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_BID.String(), rpcAuctionsBid_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdAuctionsBuyout, rpcAuctionsBuyout_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_CLAIM_BID.String(), rpcAuctionsClaimBid_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcIdAuctionsRetractBid, rpcAuctionsRetractBid_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_CLAIM_CREATED.String(), rpcAuctionsClaimCreated_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcIdAuctionsTeamBid, rpcAuctionsTeamBid_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_CANCEL.String(), rpcAuctionsCancel_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_CLAIM_BID.String(), rpcAuctionsClaimBid_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_CREATE.String(), rpcAuctionsCreate_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_CLAIM_CREATED.String(), rpcAuctionsClaimCreated_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_CANCEL.String(), rpcAuctionsCancel_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_AUCTIONS_CREATE.String(), rpcAuctionsCreate_Json(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_LIST_BIDS.String(), rpcAuctionsListBids_Json(p)); err != nil {
@@ -958,6 +1266,18 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_AUCTIONS_LIST_CREATED.String(), rpcAuctionsListCreated_Json(p)); err != nil {
 			return err
 		}
+		if err := initializer.RegisterRpc(RpcIdAuctionsListHistory, rpcAuctionsListHistory_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdAuctionsClaimAllBids, rpcAuctionsClaimAllBids_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdAuctionsClaimAllCreated, rpcAuctionsClaimAllCreated_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdAuctionsPriceHistory, rpcAuctionsPriceHistory_Json(p)); err != nil {
+			return err
+		}
 
 		// Register socket RPC with JSON suffix
 		if err := initializer.RegisterRpc(RpcSocketId_RPC_SOCKET_ID_AUCTIONS_FOLLOW.String(), rpcAuctionsFollow_Json(p)); err != nil {
@@ -972,7 +1292,7 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_STREAKS_UPDATE.String(), rpcStreaksUpdate_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_STREAKS_CLAIM.String(), rpcStreaksClaim_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_STREAKS_CLAIM.String(), rpcStreaksClaim_Json(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_STREAKS_RESET.String(), rpcStreaksReset_Json(p)); err != nil {
@@ -984,7 +1304,7 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_PROGRESSIONS_GET.String(), rpcProgressionsGet_Json(p)); err != nil {
 			return err
 		}
-		if err := initializer.RegisterRpc(RpcId_RPC_ID_PROGRESSIONS_PURCHASE.String(), rpcProgressionsPurchase_Json(p)); err != nil {
+		if err := registerAccountLockedRpc(initializer, RpcId_RPC_ID_PROGRESSIONS_PURCHASE.String(), rpcProgressionsPurchase_Json(p)); err != nil {
 			return err
 		}
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_PROGRESSIONS_UPDATE.String(), rpcProgressionsUpdate_Json(p)); err != nil {
@@ -1008,6 +1328,18 @@ func (p *pamlogixImpl) registerSystemRpcs_Json(initializer runtime.Initializer,
 		if err := initializer.RegisterRpc(RpcId_RPC_ID_TEAMS_WRITE_CHAT_MESSAGE.String(), rpcTeamsWriteChatMessage_Json(p)); err != nil {
 			return err
 		}
+		if err := initializer.RegisterRpc(RpcIdTeamsGet, rpcTeamsGet_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdTeamsUpdate, rpcTeamsUpdate_Json(p)); err != nil {
+			return err
+		}
+		if err := initializer.RegisterRpc(RpcIdTeamsTreasuryGet, rpcTeamsTreasuryGet_Json(p)); err != nil {
+			return err
+		}
+		if err := registerAccountLockedRpc(initializer, RpcIdTeamsTreasuryDeposit, rpcTeamsTreasuryDeposit_Json(p)); err != nil {
+			return err
+		}
 
 	// Add other system types as needed...
 
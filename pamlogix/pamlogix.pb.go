@@ -671,6 +671,12 @@ const (
 	EconomyStoreType_ECONOMY_STORE_TYPE_FBINSTANT EconomyStoreType = 3
 	// Discord Store.
 	EconomyStoreType_ECONOMY_STORE_TYPE_DISCORD EconomyStoreType = 4
+	// Steam.
+	EconomyStoreType_ECONOMY_STORE_TYPE_STEAM EconomyStoreType = 5
+	// PlayStation Network.
+	EconomyStoreType_ECONOMY_STORE_TYPE_PLAYSTATION EconomyStoreType = 6
+	// Xbox (Microsoft Store).
+	EconomyStoreType_ECONOMY_STORE_TYPE_XBOX EconomyStoreType = 7
 )
 
 // Enum value maps for EconomyStoreType.
@@ -681,6 +687,9 @@ var (
 		2: "ECONOMY_STORE_TYPE_GOOGLE_PLAY",
 		3: "ECONOMY_STORE_TYPE_FBINSTANT",
 		4: "ECONOMY_STORE_TYPE_DISCORD",
+		5: "ECONOMY_STORE_TYPE_STEAM",
+		6: "ECONOMY_STORE_TYPE_PLAYSTATION",
+		7: "ECONOMY_STORE_TYPE_XBOX",
 	}
 	EconomyStoreType_value = map[string]int32{
 		"ECONOMY_STORE_TYPE_UNSPECIFIED":    0,
@@ -688,6 +697,9 @@ var (
 		"ECONOMY_STORE_TYPE_GOOGLE_PLAY":    2,
 		"ECONOMY_STORE_TYPE_FBINSTANT":      3,
 		"ECONOMY_STORE_TYPE_DISCORD":        4,
+		"ECONOMY_STORE_TYPE_STEAM":          5,
+		"ECONOMY_STORE_TYPE_PLAYSTATION":    6,
+		"ECONOMY_STORE_TYPE_XBOX":           7,
 	}
 )
 
@@ -2473,8 +2485,10 @@ type Reward struct {
 	GrantTimeSec int64 `protobuf:"varint,6,opt,name=grant_time_sec,json=grantTimeSec,proto3" json:"grant_time_sec,omitempty"`
 	// The item instances granted. Indexed by item instance ID.
 	ItemInstances map[string]*RewardInventoryItem `protobuf:"bytes,7,rep,name=item_instances,json=itemInstances,proto3" json:"item_instances,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// The unique items which were already owned and converted into their duplicate rewards. Indexed by item ID.
+	ConvertedItems map[string]int64 `protobuf:"bytes,8,rep,name=converted_items,json=convertedItems,proto3" json:"converted_items,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Reward) Reset() {
@@ -2556,6 +2570,13 @@ func (x *Reward) GetItemInstances() map[string]*RewardInventoryItem {
 	return nil
 }
 
+func (x *Reward) GetConvertedItems() map[string]int64 {
+	if x != nil {
+		return x.ConvertedItems
+	}
+	return nil
+}
+
 // A list of rewards granted to the player.
 type RewardList struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -5700,7 +5721,9 @@ func (x *EventLeaderboardUpdate) GetConditionalMetadataUpdate() bool {
 type EventLeaderboardClaim struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Event leaderboard ID to claim.
-	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The index of the reward chosen from the claimed reward tier's choices, required when it offers any.
+	Choice        *wrapperspb.Int32Value `protobuf:"bytes,2,opt,name=choice,proto3" json:"choice,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -5742,6 +5765,13 @@ func (x *EventLeaderboardClaim) GetId() string {
 	return ""
 }
 
+func (x *EventLeaderboardClaim) GetChoice() *wrapperspb.Int32Value {
+	if x != nil {
+		return x.Choice
+	}
+	return nil
+}
+
 // Roll a new cohort for the specified event leaderboard.
 type EventLeaderboardRoll struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -5936,7 +5966,9 @@ type EventLeaderboardRewardTier struct {
 	// The available rewards for this range.
 	AvailableRewards *AvailableRewards `protobuf:"bytes,4,opt,name=available_rewards,json=availableRewards,proto3" json:"available_rewards,omitempty"`
 	// Change in tier for this rank range.
-	TierChange    int32 `protobuf:"varint,5,opt,name=tier_change,json=tierChange,proto3" json:"tier_change,omitempty"`
+	TierChange int32 `protobuf:"varint,5,opt,name=tier_change,json=tierChange,proto3" json:"tier_change,omitempty"`
+	// The rewards one of which is chosen when claiming, in addition to the available rewards.
+	RewardChoices []*AvailableRewards `protobuf:"bytes,6,rep,name=reward_choices,json=rewardChoices,proto3" json:"reward_choices,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -6006,6 +6038,13 @@ func (x *EventLeaderboardRewardTier) GetTierChange() int32 {
 	return 0
 }
 
+func (x *EventLeaderboardRewardTier) GetRewardChoices() []*AvailableRewards {
+	if x != nil {
+		return x.RewardChoices
+	}
+	return nil
+}
+
 // An event leaderboard's tier-specific set of rewards.
 type EventLeaderboardRewardTiers struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -6172,9 +6211,13 @@ type EventLeaderboard struct {
 	// Cohort ID the user belongs to for this active phase.
 	CohortId string `protobuf:"bytes,26,opt,name=cohort_id,json=cohortId,proto3" json:"cohort_id,omitempty"`
 	// Backing ID for underlying score tracking.
-	BackingId     string `protobuf:"bytes,27,opt,name=backing_id,json=backingId,proto3" json:"backing_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	BackingId string `protobuf:"bytes,27,opt,name=backing_id,json=backingId,proto3" json:"backing_id,omitempty"`
+	// Number of rerolls the user has used in the current event iteration.
+	RerollCount int32 `protobuf:"varint,28,opt,name=reroll_count,json=rerollCount,proto3" json:"reroll_count,omitempty"`
+	// The UNIX timestamp when a reroll cooldown or windowed limit next lets the user reroll, or zero if it doesn't hold them back.
+	NextRerollTimeSec int64 `protobuf:"varint,29,opt,name=next_reroll_time_sec,json=nextRerollTimeSec,proto3" json:"next_reroll_time_sec,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *EventLeaderboard) Reset() {
@@ -6396,6 +6439,20 @@ func (x *EventLeaderboard) GetBackingId() string {
 	return ""
 }
 
+func (x *EventLeaderboard) GetRerollCount() int32 {
+	if x != nil {
+		return x.RerollCount
+	}
+	return 0
+}
+
+func (x *EventLeaderboard) GetNextRerollTimeSec() int64 {
+	if x != nil {
+		return x.NextRerollTimeSec
+	}
+	return 0
+}
+
 // Several event leaderboards the user has access to, resulting from a listing operation.
 type EventLeaderboards struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -8726,6 +8783,10 @@ type AuctionBid struct {
 	Bid *AuctionBidAmount `protobuf:"bytes,2,opt,name=bid,proto3" json:"bid,omitempty"`
 	// The UNIX time (for gRPC clients) or ISO string (for REST clients) when the bid was placed.
 	CreateTimeSec int64 `protobuf:"varint,3,opt,name=create_time_sec,json=createTimeSec,proto3" json:"create_time_sec,omitempty"`
+	// Display name of the bidder, only set when requested.
+	DisplayName string `protobuf:"bytes,4,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// Avatar URL of the bidder, only set when requested.
+	AvatarUrl     string `protobuf:"bytes,5,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -8781,6 +8842,20 @@ func (x *AuctionBid) GetCreateTimeSec() int64 {
 	return 0
 }
 
+func (x *AuctionBid) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *AuctionBid) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
 // An individual auction listing.
 type Auction struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -8843,7 +8918,13 @@ type Auction struct {
 	// First bid placed on this auction.
 	BidFirst *AuctionBid `protobuf:"bytes,29,opt,name=bid_first,json=bidFirst,proto3" json:"bid_first,omitempty"`
 	// Most recent set of bids placed on this auction, ordered from newest to oldest retained.
-	BidHistory    []*AuctionBid `protobuf:"bytes,30,rep,name=bid_history,json=bidHistory,proto3" json:"bid_history,omitempty"`
+	BidHistory []*AuctionBid `protobuf:"bytes,30,rep,name=bid_history,json=bidHistory,proto3" json:"bid_history,omitempty"`
+	// Indicates the auction has a reserve price its current bid doesn't meet. An auction that ends this way is unsold.
+	ReserveNotMet bool `protobuf:"varint,31,opt,name=reserve_not_met,json=reserveNotMet,proto3" json:"reserve_not_met,omitempty"`
+	// Estimated value of the auctioned items, from recent sales of the same items or their store prices, if known.
+	EstimatedValue *AuctionBidAmount `protobuf:"bytes,32,opt,name=estimated_value,json=estimatedValue,proto3" json:"estimated_value,omitempty"`
+	// Price the auction can be bought out for straight away, ending it. Not set when the auction has no buyout.
+	Buyout        *AuctionBidAmount `protobuf:"bytes,33,opt,name=buyout,proto3" json:"buyout,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -9088,6 +9169,27 @@ func (x *Auction) GetBidHistory() []*AuctionBid {
 	return nil
 }
 
+func (x *Auction) GetReserveNotMet() bool {
+	if x != nil {
+		return x.ReserveNotMet
+	}
+	return false
+}
+
+func (x *Auction) GetEstimatedValue() *AuctionBidAmount {
+	if x != nil {
+		return x.EstimatedValue
+	}
+	return nil
+}
+
+func (x *Auction) GetBuyout() *AuctionBidAmount {
+	if x != nil {
+		return x.Buyout
+	}
+	return nil
+}
+
 // Notification payload containing a bid update for a followed auction.
 type AuctionNotificationBid struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -9339,6 +9441,8 @@ type AuctionClaimCreated struct {
 	Fee *AuctionBidAmount `protobuf:"bytes,3,opt,name=fee,proto3" json:"fee,omitempty"`
 	// Items returned in the event of a failed auction.
 	ReturnedItems []*InventoryItem `protobuf:"bytes,4,rep,name=returned_items,json=returnedItems,proto3" json:"returned_items,omitempty"`
+	// Currencies credited to the creator, the reward less the fee.
+	Proceeds      *AuctionBidAmount `protobuf:"bytes,5,opt,name=proceeds,proto3" json:"proceeds,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -9401,6 +9505,13 @@ func (x *AuctionClaimCreated) GetReturnedItems() []*InventoryItem {
 	return nil
 }
 
+func (x *AuctionClaimCreated) GetProceeds() *AuctionBidAmount {
+	if x != nil {
+		return x.Proceeds
+	}
+	return nil
+}
+
 // Result of cancelling an auction.
 type AuctionCancel struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -9461,8 +9572,12 @@ type AuctionList struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Auctions matching the requested filters.
 	Auctions []*Auction `protobuf:"bytes,1,rep,name=auctions,proto3" json:"auctions,omitempty"`
-	// Pagination cursor to fetch more results, or empty if there are no more.
-	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Deprecated: use next_cursor. Kept as the next page's cursor for one release.
+	Cursor string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Cursor of the next page, or empty if there are no more.
+	NextCursor string `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	// Cursor of the previous page, or empty on the first page.
+	PrevCursor    string `protobuf:"bytes,4,opt,name=prev_cursor,json=prevCursor,proto3" json:"prev_cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -9511,19 +9626,35 @@ func (x *AuctionList) GetCursor() string {
 	return ""
 }
 
+func (x *AuctionList) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *AuctionList) GetPrevCursor() string {
+	if x != nil {
+		return x.PrevCursor
+	}
+	return ""
+}
+
 // Request to list available auctions, optionally filtered based on given criteria.
 type AuctionListRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Formatted filter query.
+	// Filter query of "field:value" clauses, e.g. "item_id:sword currency:coins max_price:500".
 	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	// Custom sort criteria.
+	// Sort keys of "end_time_sec", "create_time_sec" or "price", descending when prefixed with "-".
 	Sort []string `protobuf:"bytes,2,rep,name=sort,proto3" json:"sort,omitempty"`
 	// Maximum number of auctions to return in a single response.
 	Limit int64 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
 	// Cursor to use for retrieving the next page of results.
-	Cursor        string `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Cursor string `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Fetches the display name and avatar of each bidder in the returned auctions.
+	IncludeBidders bool `protobuf:"varint,5,opt,name=include_bidders,json=includeBidders,proto3" json:"include_bidders,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *AuctionListRequest) Reset() {
@@ -9584,6 +9715,13 @@ func (x *AuctionListRequest) GetCursor() string {
 	return ""
 }
 
+func (x *AuctionListRequest) GetIncludeBidders() bool {
+	if x != nil {
+		return x.IncludeBidders
+	}
+	return false
+}
+
 // Request to place a bid on an active auction.
 type AuctionBidRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -9865,9 +10003,11 @@ type AuctionListBidsRequest struct {
 	// Maximum number of auctions to return in a single response.
 	Limit int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
 	// Cursor to use for retrieving the next page of results.
-	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Cursor string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Fetches the display name and avatar of each bidder in the returned auctions.
+	IncludeBidders bool `protobuf:"varint,3,opt,name=include_bidders,json=includeBidders,proto3" json:"include_bidders,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *AuctionListBidsRequest) Reset() {
@@ -9914,15 +10054,24 @@ func (x *AuctionListBidsRequest) GetCursor() string {
 	return ""
 }
 
+func (x *AuctionListBidsRequest) GetIncludeBidders() bool {
+	if x != nil {
+		return x.IncludeBidders
+	}
+	return false
+}
+
 // Request to retrieve a list of auctions the user has created.
 type AuctionListCreatedRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Maximum number of auctions to return in a single response.
 	Limit int64 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
 	// Cursor to use for retrieving the next page of results.
-	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Cursor string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Fetches the display name and avatar of each bidder in the returned auctions.
+	IncludeBidders bool `protobuf:"varint,3,opt,name=include_bidders,json=includeBidders,proto3" json:"include_bidders,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *AuctionListCreatedRequest) Reset() {
@@ -9969,13 +10118,22 @@ func (x *AuctionListCreatedRequest) GetCursor() string {
 	return ""
 }
 
+func (x *AuctionListCreatedRequest) GetIncludeBidders() bool {
+	if x != nil {
+		return x.IncludeBidders
+	}
+	return false
+}
+
 // Request to follow auctions the user has an interest in.
 type AuctionsFollowRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Auction IDs to follow.
-	Ids           []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Ids []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	// Fetches the display name and avatar of each bidder in the returned auctions.
+	IncludeBidders bool `protobuf:"varint,2,opt,name=include_bidders,json=includeBidders,proto3" json:"include_bidders,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *AuctionsFollowRequest) Reset() {
@@ -10015,6 +10173,13 @@ func (x *AuctionsFollowRequest) GetIds() []string {
 	return nil
 }
 
+func (x *AuctionsFollowRequest) GetIncludeBidders() bool {
+	if x != nil {
+		return x.IncludeBidders
+	}
+	return false
+}
+
 // Represents a request to retrieve available store items.
 type EconomyListRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -10443,7 +10608,9 @@ type EconomyPlacementStatus struct {
 	// The reward for completing the placement, if it was autocompleted as part of the status request.
 	Reward *Reward `protobuf:"bytes,6,opt,name=reward,proto3" json:"reward,omitempty"`
 	// Metadata associated with the placement, if any.
-	Metadata      map[string]string `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Metadata map[string]string `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The rewards the placement can grant on success, after the user's active reward modifiers.
+	RewardPreview *AvailableRewards `protobuf:"bytes,8,opt,name=reward_preview,json=rewardPreview,proto3" json:"reward_preview,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -10527,6 +10694,13 @@ func (x *EconomyPlacementStatus) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *EconomyPlacementStatus) GetRewardPreview() *AvailableRewards {
+	if x != nil {
+		return x.RewardPreview
+	}
+	return nil
+}
+
 // Response from granting currencies, reward modifiers, and/or items.
 // Contains updated wallet and inventory data, if changed.
 // Contains reward granted, if any.
@@ -10788,8 +10962,10 @@ type Energy struct {
 	AdditionalProperties map[string]string `protobuf:"bytes,11,rep,name=additional_properties,json=additionalProperties,proto3" json:"additional_properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	// The current UNIX timestamp in seconds.
 	CurrentTimeSec int64 `protobuf:"varint,12,opt,name=current_time_sec,json=currentTimeSec,proto3" json:"current_time_sec,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// The number of seconds until the count will increase, if it is not at max already.
+	NextRefillSec int64 `protobuf:"varint,13,opt,name=next_refill_sec,json=nextRefillSec,proto3" json:"next_refill_sec,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Energy) Reset() {
@@ -10906,6 +11082,13 @@ func (x *Energy) GetCurrentTimeSec() int64 {
 	return 0
 }
 
+func (x *Energy) GetNextRefillSec() int64 {
+	if x != nil {
+		return x.NextRefillSec
+	}
+	return 0
+}
+
 // One or more energy values for a user.
 type EnergyList struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -11999,8 +12182,12 @@ type TeamList struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The current page of teams returned in the list.
 	Teams []*Team `protobuf:"bytes,1,rep,name=teams,proto3" json:"teams,omitempty"`
-	// A cursor used to get the next page.
-	Cursor        string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Deprecated: use next_cursor. Kept as the next page's cursor for one release.
+	Cursor string `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Cursor of the next page, or empty if there are no more.
+	NextCursor string `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	// Cursor of the previous page, or empty on the first page.
+	PrevCursor    string `protobuf:"bytes,4,opt,name=prev_cursor,json=prevCursor,proto3" json:"prev_cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -12049,6 +12236,20 @@ func (x *TeamList) GetCursor() string {
 	return ""
 }
 
+func (x *TeamList) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *TeamList) GetPrevCursor() string {
+	if x != nil {
+		return x.PrevCursor
+	}
+	return ""
+}
+
 // A request to search for teams which the user wants to join.
 type TeamSearchRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -12258,8 +12459,10 @@ type Unlockable struct {
 	AdditionalProperties map[string]string `protobuf:"bytes,15,rep,name=additional_properties,json=additionalProperties,proto3" json:"additional_properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	// Additional time that has been added to speed up the unlockable's progress, if any.
 	AdvanceTimeSec int64 `protobuf:"varint,16,opt,name=advance_time_sec,json=advanceTimeSec,proto3" json:"advance_time_sec,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	// The part of the advance time which came from rewarded video placements.
+	RewardedVideoAdvanceTimeSec int64 `protobuf:"varint,17,opt,name=rewarded_video_advance_time_sec,json=rewardedVideoAdvanceTimeSec,proto3" json:"rewarded_video_advance_time_sec,omitempty"`
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
 }
 
 func (x *Unlockable) Reset() {
@@ -12404,6 +12607,13 @@ func (x *Unlockable) GetAdvanceTimeSec() int64 {
 	return 0
 }
 
+func (x *Unlockable) GetRewardedVideoAdvanceTimeSec() int64 {
+	if x != nil {
+		return x.RewardedVideoAdvanceTimeSec
+	}
+	return 0
+}
+
 // The cost to purchase an additional unlockable active slot.
 type UnlockableSlotCost struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -15583,7 +15793,7 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x05value\x18\x04 \x01(\x03R\x05value\x12$\n" +
 	"\x0estart_time_sec\x18\x05 \x01(\x03R\fstartTimeSec\x12 \n" +
 	"\fend_time_sec\x18\x06 \x01(\x03R\n" +
-	"endTimeSec\"\xd2\x05\n" +
+	"endTimeSec\"\xe4\x06\n" +
 	"\x06Reward\x121\n" +
 	"\x05items\x18\x01 \x03(\v2\x1b.pamlogix.Reward.ItemsEntryR\x05items\x12@\n" +
 	"\n" +
@@ -15593,7 +15803,8 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x10energy_modifiers\x18\x04 \x03(\v2\x1e.pamlogix.RewardEnergyModifierR\x0fenergyModifiers\x12C\n" +
 	"\x10reward_modifiers\x18\x05 \x03(\v2\x18.pamlogix.RewardModifierR\x0frewardModifiers\x12$\n" +
 	"\x0egrant_time_sec\x18\x06 \x01(\x03R\fgrantTimeSec\x12J\n" +
-	"\x0eitem_instances\x18\a \x03(\v2#.pamlogix.Reward.ItemInstancesEntryR\ritemInstances\x1a8\n" +
+	"\x0eitem_instances\x18\a \x03(\v2#.pamlogix.Reward.ItemInstancesEntryR\ritemInstances\x12M\n" +
+	"\x0fconverted_items\x18\b \x03(\v2$.pamlogix.Reward.ConvertedItemsEntryR\x0econvertedItems\x1a8\n" +
 	"\n" +
 	"ItemsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
@@ -15606,7 +15817,10 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1a_\n" +
 	"\x12ItemInstancesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x123\n" +
-	"\x05value\x18\x02 \x01(\v2\x1d.pamlogix.RewardInventoryItemR\x05value:\x028\x01\"8\n" +
+	"\x05value\x18\x02 \x01(\v2\x1d.pamlogix.RewardInventoryItemR\x05value:\x028\x01\x1aA\n" +
+	"\x13ConvertedItemsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"8\n" +
 	"\n" +
 	"RewardList\x12*\n" +
 	"\arewards\x18\x01 \x03(\v2\x10.pamlogix.RewardR\arewards\"R\n" +
@@ -15877,9 +16091,10 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x05score\x18\x02 \x01(\x03R\x05score\x12\x1a\n" +
 	"\bsubscore\x18\x03 \x01(\x03R\bsubscore\x12\x1a\n" +
 	"\bmetadata\x18\x04 \x01(\tR\bmetadata\x12>\n" +
-	"\x1bconditional_metadata_update\x18\x05 \x01(\bR\x19conditionalMetadataUpdate\"'\n" +
+	"\x1bconditional_metadata_update\x18\x05 \x01(\bR\x19conditionalMetadataUpdate\"\\\n" +
 	"\x15EventLeaderboardClaim\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"&\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x123\n" +
+	"\x06choice\x18\x02 \x01(\v2\x1b.google.protobuf.Int32ValueR\x06choice\"&\n" +
 	"\x14EventLeaderboardRoll\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"\xd6\x02\n" +
 	"\x15EventLeaderboardScore\x12\x0e\n" +
@@ -15896,21 +16111,22 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\n" +
 	"num_scores\x18\n" +
 	" \x01(\x03R\tnumScores\x12\x1a\n" +
-	"\bmetadata\x18\v \x01(\tR\bmetadata\"\xd0\x01\n" +
+	"\bmetadata\x18\v \x01(\tR\bmetadata\"\x93\x02\n" +
 	"\x1aEventLeaderboardRewardTier\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x19\n" +
 	"\brank_max\x18\x02 \x01(\x05R\arankMax\x12\x19\n" +
 	"\brank_min\x18\x03 \x01(\x05R\arankMin\x12G\n" +
 	"\x11available_rewards\x18\x04 \x01(\v2\x1a.pamlogix.AvailableRewardsR\x10availableRewards\x12\x1f\n" +
 	"\vtier_change\x18\x05 \x01(\x05R\n" +
-	"tierChange\"f\n" +
+	"tierChange\x12A\n" +
+	"\x0ereward_choices\x18\x06 \x03(\v2\x1a.pamlogix.AvailableRewardsR\rrewardChoices\"f\n" +
 	"\x1bEventLeaderboardRewardTiers\x12G\n" +
 	"\freward_tiers\x18\x01 \x03(\v2$.pamlogix.EventLeaderboardRewardTierR\vrewardTiers\"w\n" +
 	"\x1aEventLeaderboardChangeZone\x12\x1c\n" +
 	"\tpromotion\x18\x01 \x01(\x01R\tpromotion\x12\x1a\n" +
 	"\bdemotion\x18\x02 \x01(\x01R\bdemotion\x12\x1f\n" +
 	"\vdemote_idle\x18\x03 \x01(\bR\n" +
-	"demoteIdle\"\x85\v\n" +
+	"demoteIdle\"\xd9\v\n" +
 	"\x10EventLeaderboard\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
@@ -15941,7 +16157,9 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x10current_time_sec\x18\x19 \x01(\x03R\x0ecurrentTimeSec\x12\x1b\n" +
 	"\tcohort_id\x18\x1a \x01(\tR\bcohortId\x12\x1d\n" +
 	"\n" +
-	"backing_id\x18\x1b \x01(\tR\tbackingId\x1ae\n" +
+	"backing_id\x18\x1b \x01(\tR\tbackingId\x12!\n" +
+	"\freroll_count\x18\x1c \x01(\x05R\vrerollCount\x12/\n" +
+	"\x14next_reroll_time_sec\x18\x1d \x01(\x03R\x11nextRerollTimeSec\x1ae\n" +
 	"\x10RewardTiersEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\x05R\x03key\x12;\n" +
 	"\x05value\x18\x02 \x01(\v2%.pamlogix.EventLeaderboardRewardTiersR\x05value:\x028\x01\x1ad\n" +
@@ -16207,12 +16425,16 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
 	"\x05value\x18\x02 \x01(\v2\x19.pamlogix.AuctionTemplateR\x05value:\x028\x01\">\n" +
 	"\rAuctionReward\x12-\n" +
-	"\x05items\x18\x01 \x03(\v2\x17.pamlogix.InventoryItemR\x05items\"{\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.pamlogix.InventoryItemR\x05items\"\xbd\x01\n" +
 	"\n" +
 	"AuctionBid\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12,\n" +
 	"\x03bid\x18\x02 \x01(\v2\x1a.pamlogix.AuctionBidAmountR\x03bid\x12&\n" +
-	"\x0fcreate_time_sec\x18\x03 \x01(\x03R\rcreateTimeSec\"\xb8\t\n" +
+	"\x0fcreate_time_sec\x18\x03 \x01(\x03R\rcreateTimeSec\x12!\n" +
+	"\fdisplay_name\x18\x04 \x01(\tR\vdisplayName\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\x05 \x01(\tR\tavatarUrl\"\xd9\n" +
+	"\n" +
 	"\aAuction\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\tR\x06userId\x12/\n" +
@@ -16248,7 +16470,10 @@ const file_pamlogix_proto_rawDesc = "" +
 	"can_cancel\x18\x1c \x01(\bR\tcanCancel\x121\n" +
 	"\tbid_first\x18\x1d \x01(\v2\x14.pamlogix.AuctionBidR\bbidFirst\x125\n" +
 	"\vbid_history\x18\x1e \x03(\v2\x14.pamlogix.AuctionBidR\n" +
-	"bidHistory\"\xfd\x02\n" +
+	"bidHistory\x12&\n" +
+	"\x0freserve_not_met\x18\x1f \x01(\bR\rreserveNotMet\x12C\n" +
+	"\x0festimated_value\x18  \x01(\v2\x1a.pamlogix.AuctionBidAmountR\x0eestimatedValue\x122\n" +
+	"\x06buyout\x18! \x01(\v2\x1a.pamlogix.AuctionBidAmountR\x06buyout\"\xfd\x02\n" +
 	"\x16AuctionNotificationBid\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12&\n" +
@@ -16266,23 +16491,29 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\amessage\"o\n" +
 	"\x0fAuctionClaimBid\x12+\n" +
 	"\aauction\x18\x01 \x01(\v2\x11.pamlogix.AuctionR\aauction\x12/\n" +
-	"\x06reward\x18\x02 \x01(\v2\x17.pamlogix.AuctionRewardR\x06reward\"\xe4\x01\n" +
+	"\x06reward\x18\x02 \x01(\v2\x17.pamlogix.AuctionRewardR\x06reward\"\x9c\x02\n" +
 	"\x13AuctionClaimCreated\x12+\n" +
 	"\aauction\x18\x01 \x01(\v2\x11.pamlogix.AuctionR\aauction\x122\n" +
 	"\x06reward\x18\x02 \x01(\v2\x1a.pamlogix.AuctionBidAmountR\x06reward\x12,\n" +
 	"\x03fee\x18\x03 \x01(\v2\x1a.pamlogix.AuctionBidAmountR\x03fee\x12>\n" +
-	"\x0ereturned_items\x18\x04 \x03(\v2\x17.pamlogix.InventoryItemR\rreturnedItems\"m\n" +
+	"\x0ereturned_items\x18\x04 \x03(\v2\x17.pamlogix.InventoryItemR\rreturnedItems\x126\n" +
+	"\bproceeds\x18\x05 \x01(\v2\x1a.pamlogix.AuctionBidAmountR\bproceeds\"m\n" +
 	"\rAuctionCancel\x12+\n" +
 	"\aauction\x18\x01 \x01(\v2\x11.pamlogix.AuctionR\aauction\x12/\n" +
-	"\x06reward\x18\x02 \x01(\v2\x17.pamlogix.AuctionRewardR\x06reward\"T\n" +
+	"\x06reward\x18\x02 \x01(\v2\x17.pamlogix.AuctionRewardR\x06reward\"\x96\x01\n" +
 	"\vAuctionList\x12-\n" +
 	"\bauctions\x18\x01 \x03(\v2\x11.pamlogix.AuctionR\bauctions\x12\x16\n" +
-	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"l\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\x12\x1f\n" +
+	"\vprev_cursor\x18\x04 \x01(\tR\n" +
+	"prevCursor\"\x95\x01\n" +
 	"\x12AuctionListRequest\x12\x14\n" +
 	"\x05query\x18\x01 \x01(\tR\x05query\x12\x12\n" +
 	"\x04sort\x18\x02 \x03(\tR\x04sort\x12\x14\n" +
 	"\x05limit\x18\x03 \x01(\x03R\x05limit\x12\x16\n" +
-	"\x06cursor\x18\x04 \x01(\tR\x06cursor\"k\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\x12'\n" +
+	"\x0finclude_bidders\x18\x05 \x01(\bR\x0eincludeBidders\"k\n" +
 	"\x11AuctionBidRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12,\n" +
@@ -16298,15 +16529,18 @@ const file_pamlogix_proto_rawDesc = "" +
 	"templateId\x12!\n" +
 	"\fcondition_id\x18\x02 \x01(\tR\vconditionId\x12!\n" +
 	"\finstance_ids\x18\x03 \x03(\tR\vinstanceIds\x12$\n" +
-	"\x0estart_time_sec\x18\x04 \x01(\x03R\fstartTimeSec\"F\n" +
+	"\x0estart_time_sec\x18\x04 \x01(\x03R\fstartTimeSec\"o\n" +
 	"\x16AuctionListBidsRequest\x12\x14\n" +
 	"\x05limit\x18\x01 \x01(\x03R\x05limit\x12\x16\n" +
-	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"I\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12'\n" +
+	"\x0finclude_bidders\x18\x03 \x01(\bR\x0eincludeBidders\"r\n" +
 	"\x19AuctionListCreatedRequest\x12\x14\n" +
 	"\x05limit\x18\x01 \x01(\x03R\x05limit\x12\x16\n" +
-	"\x06cursor\x18\x02 \x01(\tR\x06cursor\")\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12'\n" +
+	"\x0finclude_bidders\x18\x03 \x01(\bR\x0eincludeBidders\"R\n" +
 	"\x15AuctionsFollowRequest\x12\x10\n" +
-	"\x03ids\x18\x01 \x03(\tR\x03ids\"O\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\x12'\n" +
+	"\x0finclude_bidders\x18\x02 \x01(\bR\x0eincludeBidders\"O\n" +
 	"\x12EconomyListRequest\x129\n" +
 	"\n" +
 	"store_type\x18\x01 \x01(\x0e2\x1a.pamlogix.EconomyStoreTypeR\tstoreType\"\xe2\x02\n" +
@@ -16346,7 +16580,7 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\bmetadata\x18\x02 \x03(\v24.pamlogix.EconomyPlacementStartRequest.MetadataEntryR\bmetadata\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xf9\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xbc\x03\n" +
 	"\x16EconomyPlacementStatus\x12\x1b\n" +
 	"\treward_id\x18\x01 \x01(\tR\brewardId\x12!\n" +
 	"\fplacement_id\x18\x02 \x01(\tR\vplacementId\x12&\n" +
@@ -16354,7 +16588,8 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x11complete_time_sec\x18\x04 \x01(\x03R\x0fcompleteTimeSec\x12\x18\n" +
 	"\asuccess\x18\x05 \x01(\bR\asuccess\x12(\n" +
 	"\x06reward\x18\x06 \x01(\v2\x10.pamlogix.RewardR\x06reward\x12J\n" +
-	"\bmetadata\x18\a \x03(\v2..pamlogix.EconomyPlacementStatus.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\a \x03(\v2..pamlogix.EconomyPlacementStatus.MetadataEntryR\bmetadata\x12A\n" +
+	"\x0ereward_preview\x18\b \x01(\v2\x1a.pamlogix.AvailableRewardsR\rrewardPreview\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xec\x02\n" +
@@ -16380,7 +16615,7 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\x05R\x05value\x12$\n" +
 	"\x0estart_time_sec\x18\x03 \x01(\x03R\fstartTimeSec\x12 \n" +
 	"\fend_time_sec\x18\x04 \x01(\x03R\n" +
-	"endTimeSec\"\xe3\x04\n" +
+	"endTimeSec\"\x8b\x05\n" +
 	"\x06Energy\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
 	"\acurrent\x18\x02 \x01(\x05R\acurrent\x12\x10\n" +
@@ -16395,7 +16630,8 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x11available_rewards\x18\n" +
 	" \x01(\v2\x1a.pamlogix.AvailableRewardsR\x10availableRewards\x12_\n" +
 	"\x15additional_properties\x18\v \x03(\v2*.pamlogix.Energy.AdditionalPropertiesEntryR\x14additionalProperties\x12(\n" +
-	"\x10current_time_sec\x18\f \x01(\x03R\x0ecurrentTimeSec\x1aG\n" +
+	"\x10current_time_sec\x18\f \x01(\x03R\x0ecurrentTimeSec\x12&\n" +
+	"\x0fnext_refill_sec\x18\r \x01(\x03R\rnextRefillSec\x1aG\n" +
 	"\x19AdditionalPropertiesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9b\x01\n" +
@@ -16487,10 +16723,14 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x0fTeamListRequest\x12\x16\n" +
 	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12\x1a\n" +
 	"\blocation\x18\x02 \x01(\tR\blocation\x12\x14\n" +
-	"\x05limit\x18\x03 \x01(\x05R\x05limit\"H\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"\x8a\x01\n" +
 	"\bTeamList\x12$\n" +
 	"\x05teams\x18\x01 \x03(\v2\x0e.pamlogix.TeamR\x05teams\x12\x16\n" +
-	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"Z\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\x12\x1f\n" +
+	"\vprev_cursor\x18\x04 \x01(\tR\n" +
+	"prevCursor\"Z\n" +
 	"\x11TeamSearchRequest\x12\x14\n" +
 	"\x05input\x18\x01 \x01(\tR\x05input\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x19\n" +
@@ -16509,7 +16749,7 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\x1a=\n" +
 	"\x0fCurrenciesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x96\x06\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xdc\x06\n" +
 	"\n" +
 	"Unlockable\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
@@ -16530,7 +16770,8 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x18unlock_complete_time_sec\x18\r \x01(\x03R\x15unlockCompleteTimeSec\x12\x1b\n" +
 	"\tcan_claim\x18\x0e \x01(\bR\bcanClaim\x12c\n" +
 	"\x15additional_properties\x18\x0f \x03(\v2..pamlogix.Unlockable.AdditionalPropertiesEntryR\x14additionalProperties\x12(\n" +
-	"\x10advance_time_sec\x18\x10 \x01(\x03R\x0eadvanceTimeSec\x1aG\n" +
+	"\x10advance_time_sec\x18\x10 \x01(\x03R\x0eadvanceTimeSec\x12D\n" +
+	"\x1frewarded_video_advance_time_sec\x18\x11 \x01(\x03R\x1brewardedVideoAdvanceTimeSec\x1aG\n" +
 	"\x19AdditionalPropertiesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9a\x02\n" +
@@ -16953,13 +17194,16 @@ const file_pamlogix_proto_rawDesc = "" +
 	"\x18STAT_UPDATE_OPERATOR_SET\x10\x01\x12\x1e\n" +
 	"\x1aSTAT_UPDATE_OPERATOR_DELTA\x10\x02\x12\x1c\n" +
 	"\x18STAT_UPDATE_OPERATOR_MIN\x10\x03\x12\x1c\n" +
-	"\x18STAT_UPDATE_OPERATOR_MAX\x10\x04*\xc3\x01\n" +
+	"\x18STAT_UPDATE_OPERATOR_MAX\x10\x04*\xa2\x02\n" +
 	"\x10EconomyStoreType\x12\"\n" +
 	"\x1eECONOMY_STORE_TYPE_UNSPECIFIED\x10\x00\x12%\n" +
 	"!ECONOMY_STORE_TYPE_APPLE_APPSTORE\x10\x01\x12\"\n" +
 	"\x1eECONOMY_STORE_TYPE_GOOGLE_PLAY\x10\x02\x12 \n" +
 	"\x1cECONOMY_STORE_TYPE_FBINSTANT\x10\x03\x12\x1e\n" +
-	"\x1aECONOMY_STORE_TYPE_DISCORD\x10\x04*J\n" +
+	"\x1aECONOMY_STORE_TYPE_DISCORD\x10\x04\x12\x1c\n" +
+	"\x18ECONOMY_STORE_TYPE_STEAM\x10\x05\x12\"\n" +
+	"\x1eECONOMY_STORE_TYPE_PLAYSTATION\x10\x06\x12\x1b\n" +
+	"\x17ECONOMY_STORE_TYPE_XBOX\x10\a*J\n" +
 	"\rIncentiveType\x12\x1e\n" +
 	"\x1aINCENTIVE_TYPE_UNSPECIFIED\x10\x00\x12\x19\n" +
 	"\x15INCENTIVE_TYPE_INVITE\x10\x01*\xb8\x01\n" +
@@ -16993,7 +17237,7 @@ func file_pamlogix_proto_rawDescGZIP() []byte {
 }
 
 var file_pamlogix_proto_enumTypes = make([]protoimpl.EnumInfo, 9)
-var file_pamlogix_proto_msgTypes = make([]protoimpl.MessageInfo, 305)
+var file_pamlogix_proto_msgTypes = make([]protoimpl.MessageInfo, 306)
 var file_pamlogix_proto_goTypes = []any{
 	(RpcId)(0),                                       // 0: pamlogix.RpcId
 	(RpcSocketId)(0),                                 // 1: pamlogix.RpcSocketId
@@ -17229,89 +17473,90 @@ var file_pamlogix_proto_goTypes = []any{
 	nil,                                              // 231: pamlogix.Reward.CurrenciesEntry
 	nil,                                              // 232: pamlogix.Reward.EnergiesEntry
 	nil,                                              // 233: pamlogix.Reward.ItemInstancesEntry
-	nil,                                              // 234: pamlogix.AvailableRewardsStringProperty.OptionsEntry
-	nil,                                              // 235: pamlogix.AvailableRewardsItem.NumericPropertiesEntry
-	nil,                                              // 236: pamlogix.AvailableRewardsItem.StringPropertiesEntry
-	nil,                                              // 237: pamlogix.AvailableRewardsContents.ItemsEntry
-	nil,                                              // 238: pamlogix.AvailableRewardsContents.CurrenciesEntry
-	nil,                                              // 239: pamlogix.AvailableRewardsContents.EnergiesEntry
-	nil,                                              // 240: pamlogix.Incentive.ClaimsEntry
-	nil,                                              // 241: pamlogix.Challenge.AdditionalPropertiesEntry
-	nil,                                              // 242: pamlogix.ChallengeTemplate.AdditionalPropertiesEntry
-	nil,                                              // 243: pamlogix.ChallengeTemplates.TemplatesEntry
-	nil,                                              // 244: pamlogix.EventLeaderboard.RewardTiersEntry
-	nil,                                              // 245: pamlogix.EventLeaderboard.ChangeZonesEntry
-	nil,                                              // 246: pamlogix.EventLeaderboard.AdditionalPropertiesEntry
-	nil,                                              // 247: pamlogix.EconomyDonation.AdditionalPropertiesEntry
-	nil,                                              // 248: pamlogix.EconomyDonationClaimRequestDetails.DonorsEntry
-	nil,                                              // 249: pamlogix.EconomyDonationClaimRequest.DonationsEntry
-	nil,                                              // 250: pamlogix.EconomyDonationClaimRewards.ClaimedRewardsEntry
-	nil,                                              // 251: pamlogix.EconomyDonationsByUserList.UserDonationsEntry
-	nil,                                              // 252: pamlogix.EconomyListStoreItemCost.CurrenciesEntry
-	nil,                                              // 253: pamlogix.EconomyListStoreItem.AdditionalPropertiesEntry
-	nil,                                              // 254: pamlogix.EconomyListPlacement.AdditionalPropertiesEntry
-	nil,                                              // 255: pamlogix.EconomyList.DonationsEntry
-	nil,                                              // 256: pamlogix.InventoryItem.StringPropertiesEntry
-	nil,                                              // 257: pamlogix.InventoryItem.NumericPropertiesEntry
-	nil,                                              // 258: pamlogix.InventoryGrantRequest.ItemsEntry
-	nil,                                              // 259: pamlogix.InventoryUpdateItemProperties.StringPropertiesEntry
-	nil,                                              // 260: pamlogix.InventoryUpdateItemProperties.NumericPropertiesEntry
-	nil,                                              // 261: pamlogix.InventoryUpdateItemsRequest.ItemUpdatesEntry
-	nil,                                              // 262: pamlogix.Inventory.ItemsEntry
-	nil,                                              // 263: pamlogix.InventoryConsumeRequest.ItemsEntry
-	nil,                                              // 264: pamlogix.InventoryConsumeRequest.InstancesEntry
-	nil,                                              // 265: pamlogix.InventoryConsumeRewards.RewardsEntry
-	nil,                                              // 266: pamlogix.InventoryConsumeRewards.InstanceRewardsEntry
-	nil,                                              // 267: pamlogix.InventoryList.ItemsEntry
-	nil,                                              // 268: pamlogix.AuctionBidAmount.CurrenciesEntry
-	nil,                                              // 269: pamlogix.AuctionTemplateConditionListingCost.CurrenciesEntry
-	nil,                                              // 270: pamlogix.AuctionTemplateConditionListingCost.ItemsEntry
-	nil,                                              // 271: pamlogix.AuctionTemplateConditionListingCost.EnergiesEntry
-	nil,                                              // 272: pamlogix.AuctionTemplate.ConditionsEntry
-	nil,                                              // 273: pamlogix.AuctionTemplates.TemplatesEntry
-	nil,                                              // 274: pamlogix.EconomyGrantRequest.CurrenciesEntry
-	nil,                                              // 275: pamlogix.EconomyGrantRequest.ItemsEntry
-	nil,                                              // 276: pamlogix.EconomyPlacementStartRequest.MetadataEntry
-	nil,                                              // 277: pamlogix.EconomyPlacementStatus.MetadataEntry
-	nil,                                              // 278: pamlogix.EconomyUpdateAck.WalletEntry
-	nil,                                              // 279: pamlogix.EconomyPurchaseAck.WalletEntry
-	nil,                                              // 280: pamlogix.Energy.AdditionalPropertiesEntry
-	nil,                                              // 281: pamlogix.EnergyList.EnergiesEntry
-	nil,                                              // 282: pamlogix.EnergySpendRequest.AmountsEntry
-	nil,                                              // 283: pamlogix.EnergyGrantRequest.AmountsEntry
-	nil,                                              // 284: pamlogix.Tutorial.AdditionalPropertiesEntry
-	nil,                                              // 285: pamlogix.TutorialList.TutorialsEntry
-	nil,                                              // 286: pamlogix.UnlockableCost.ItemsEntry
-	nil,                                              // 287: pamlogix.UnlockableCost.CurrenciesEntry
-	nil,                                              // 288: pamlogix.Unlockable.AdditionalPropertiesEntry
-	nil,                                              // 289: pamlogix.UnlockableSlotCost.ItemsEntry
-	nil,                                              // 290: pamlogix.UnlockableSlotCost.CurrenciesEntry
-	nil,                                              // 291: pamlogix.SubAchievement.AdditionalPropertiesEntry
-	nil,                                              // 292: pamlogix.Achievement.SubAchievementsEntry
-	nil,                                              // 293: pamlogix.Achievement.AdditionalPropertiesEntry
-	nil,                                              // 294: pamlogix.AchievementList.AchievementsEntry
-	nil,                                              // 295: pamlogix.AchievementList.RepeatAchievementsEntry
-	nil,                                              // 296: pamlogix.AchievementsUpdateAck.AchievementsEntry
-	nil,                                              // 297: pamlogix.AchievementsUpdateAck.RepeatAchievementsEntry
-	nil,                                              // 298: pamlogix.AchievementsUpdateRequest.AchievementsEntry
-	nil,                                              // 299: pamlogix.StreaksList.StreaksEntry
-	nil,                                              // 300: pamlogix.StreaksUpdateRequest.UpdatesEntry
-	nil,                                              // 301: pamlogix.SyncInventoryItem.StringPropertiesEntry
-	nil,                                              // 302: pamlogix.SyncInventoryItem.NumericPropertiesEntry
-	nil,                                              // 303: pamlogix.SyncInventory.ItemsEntry
-	nil,                                              // 304: pamlogix.SyncEconomy.CurrenciesEntry
-	nil,                                              // 305: pamlogix.SyncAchievements.AchievementsEntry
-	nil,                                              // 306: pamlogix.SyncEnergy.EnergiesEntry
-	nil,                                              // 307: pamlogix.SyncEventLeaderboards.EventLeaderboardsEntry
-	nil,                                              // 308: pamlogix.SyncProgressionUpdate.CountsEntry
-	nil,                                              // 309: pamlogix.SyncProgressions.ProgressionsEntry
-	nil,                                              // 310: pamlogix.SyncTutorials.UpdatesEntry
-	nil,                                              // 311: pamlogix.SyncUnlockables.UpdatesEntry
-	nil,                                              // 312: pamlogix.SyncStreaks.UpdatesEntry
-	nil,                                              // 313: pamlogix.SyncResponse.WalletEntry
-	(*structpb.Struct)(nil),                          // 314: google.protobuf.Struct
-	(*wrapperspb.Int32Value)(nil),                    // 315: google.protobuf.Int32Value
-	(*descriptorpb.EnumValueOptions)(nil),            // 316: google.protobuf.EnumValueOptions
+	nil,                                              // 234: pamlogix.Reward.ConvertedItemsEntry
+	nil,                                              // 235: pamlogix.AvailableRewardsStringProperty.OptionsEntry
+	nil,                                              // 236: pamlogix.AvailableRewardsItem.NumericPropertiesEntry
+	nil,                                              // 237: pamlogix.AvailableRewardsItem.StringPropertiesEntry
+	nil,                                              // 238: pamlogix.AvailableRewardsContents.ItemsEntry
+	nil,                                              // 239: pamlogix.AvailableRewardsContents.CurrenciesEntry
+	nil,                                              // 240: pamlogix.AvailableRewardsContents.EnergiesEntry
+	nil,                                              // 241: pamlogix.Incentive.ClaimsEntry
+	nil,                                              // 242: pamlogix.Challenge.AdditionalPropertiesEntry
+	nil,                                              // 243: pamlogix.ChallengeTemplate.AdditionalPropertiesEntry
+	nil,                                              // 244: pamlogix.ChallengeTemplates.TemplatesEntry
+	nil,                                              // 245: pamlogix.EventLeaderboard.RewardTiersEntry
+	nil,                                              // 246: pamlogix.EventLeaderboard.ChangeZonesEntry
+	nil,                                              // 247: pamlogix.EventLeaderboard.AdditionalPropertiesEntry
+	nil,                                              // 248: pamlogix.EconomyDonation.AdditionalPropertiesEntry
+	nil,                                              // 249: pamlogix.EconomyDonationClaimRequestDetails.DonorsEntry
+	nil,                                              // 250: pamlogix.EconomyDonationClaimRequest.DonationsEntry
+	nil,                                              // 251: pamlogix.EconomyDonationClaimRewards.ClaimedRewardsEntry
+	nil,                                              // 252: pamlogix.EconomyDonationsByUserList.UserDonationsEntry
+	nil,                                              // 253: pamlogix.EconomyListStoreItemCost.CurrenciesEntry
+	nil,                                              // 254: pamlogix.EconomyListStoreItem.AdditionalPropertiesEntry
+	nil,                                              // 255: pamlogix.EconomyListPlacement.AdditionalPropertiesEntry
+	nil,                                              // 256: pamlogix.EconomyList.DonationsEntry
+	nil,                                              // 257: pamlogix.InventoryItem.StringPropertiesEntry
+	nil,                                              // 258: pamlogix.InventoryItem.NumericPropertiesEntry
+	nil,                                              // 259: pamlogix.InventoryGrantRequest.ItemsEntry
+	nil,                                              // 260: pamlogix.InventoryUpdateItemProperties.StringPropertiesEntry
+	nil,                                              // 261: pamlogix.InventoryUpdateItemProperties.NumericPropertiesEntry
+	nil,                                              // 262: pamlogix.InventoryUpdateItemsRequest.ItemUpdatesEntry
+	nil,                                              // 263: pamlogix.Inventory.ItemsEntry
+	nil,                                              // 264: pamlogix.InventoryConsumeRequest.ItemsEntry
+	nil,                                              // 265: pamlogix.InventoryConsumeRequest.InstancesEntry
+	nil,                                              // 266: pamlogix.InventoryConsumeRewards.RewardsEntry
+	nil,                                              // 267: pamlogix.InventoryConsumeRewards.InstanceRewardsEntry
+	nil,                                              // 268: pamlogix.InventoryList.ItemsEntry
+	nil,                                              // 269: pamlogix.AuctionBidAmount.CurrenciesEntry
+	nil,                                              // 270: pamlogix.AuctionTemplateConditionListingCost.CurrenciesEntry
+	nil,                                              // 271: pamlogix.AuctionTemplateConditionListingCost.ItemsEntry
+	nil,                                              // 272: pamlogix.AuctionTemplateConditionListingCost.EnergiesEntry
+	nil,                                              // 273: pamlogix.AuctionTemplate.ConditionsEntry
+	nil,                                              // 274: pamlogix.AuctionTemplates.TemplatesEntry
+	nil,                                              // 275: pamlogix.EconomyGrantRequest.CurrenciesEntry
+	nil,                                              // 276: pamlogix.EconomyGrantRequest.ItemsEntry
+	nil,                                              // 277: pamlogix.EconomyPlacementStartRequest.MetadataEntry
+	nil,                                              // 278: pamlogix.EconomyPlacementStatus.MetadataEntry
+	nil,                                              // 279: pamlogix.EconomyUpdateAck.WalletEntry
+	nil,                                              // 280: pamlogix.EconomyPurchaseAck.WalletEntry
+	nil,                                              // 281: pamlogix.Energy.AdditionalPropertiesEntry
+	nil,                                              // 282: pamlogix.EnergyList.EnergiesEntry
+	nil,                                              // 283: pamlogix.EnergySpendRequest.AmountsEntry
+	nil,                                              // 284: pamlogix.EnergyGrantRequest.AmountsEntry
+	nil,                                              // 285: pamlogix.Tutorial.AdditionalPropertiesEntry
+	nil,                                              // 286: pamlogix.TutorialList.TutorialsEntry
+	nil,                                              // 287: pamlogix.UnlockableCost.ItemsEntry
+	nil,                                              // 288: pamlogix.UnlockableCost.CurrenciesEntry
+	nil,                                              // 289: pamlogix.Unlockable.AdditionalPropertiesEntry
+	nil,                                              // 290: pamlogix.UnlockableSlotCost.ItemsEntry
+	nil,                                              // 291: pamlogix.UnlockableSlotCost.CurrenciesEntry
+	nil,                                              // 292: pamlogix.SubAchievement.AdditionalPropertiesEntry
+	nil,                                              // 293: pamlogix.Achievement.SubAchievementsEntry
+	nil,                                              // 294: pamlogix.Achievement.AdditionalPropertiesEntry
+	nil,                                              // 295: pamlogix.AchievementList.AchievementsEntry
+	nil,                                              // 296: pamlogix.AchievementList.RepeatAchievementsEntry
+	nil,                                              // 297: pamlogix.AchievementsUpdateAck.AchievementsEntry
+	nil,                                              // 298: pamlogix.AchievementsUpdateAck.RepeatAchievementsEntry
+	nil,                                              // 299: pamlogix.AchievementsUpdateRequest.AchievementsEntry
+	nil,                                              // 300: pamlogix.StreaksList.StreaksEntry
+	nil,                                              // 301: pamlogix.StreaksUpdateRequest.UpdatesEntry
+	nil,                                              // 302: pamlogix.SyncInventoryItem.StringPropertiesEntry
+	nil,                                              // 303: pamlogix.SyncInventoryItem.NumericPropertiesEntry
+	nil,                                              // 304: pamlogix.SyncInventory.ItemsEntry
+	nil,                                              // 305: pamlogix.SyncEconomy.CurrenciesEntry
+	nil,                                              // 306: pamlogix.SyncAchievements.AchievementsEntry
+	nil,                                              // 307: pamlogix.SyncEnergy.EnergiesEntry
+	nil,                                              // 308: pamlogix.SyncEventLeaderboards.EventLeaderboardsEntry
+	nil,                                              // 309: pamlogix.SyncProgressionUpdate.CountsEntry
+	nil,                                              // 310: pamlogix.SyncProgressions.ProgressionsEntry
+	nil,                                              // 311: pamlogix.SyncTutorials.UpdatesEntry
+	nil,                                              // 312: pamlogix.SyncUnlockables.UpdatesEntry
+	nil,                                              // 313: pamlogix.SyncStreaks.UpdatesEntry
+	nil,                                              // 314: pamlogix.SyncResponse.WalletEntry
+	(*structpb.Struct)(nil),                          // 315: google.protobuf.Struct
+	(*wrapperspb.Int32Value)(nil),                    // 316: google.protobuf.Int32Value
+	(*descriptorpb.EnumValueOptions)(nil),            // 317: google.protobuf.EnumValueOptions
 }
 var file_pamlogix_proto_depIdxs = []int32{
 	207, // 0: pamlogix.ProgressionCost.items:type_name -> pamlogix.ProgressionCost.ItemsEntry
@@ -17343,7 +17588,7 @@ var file_pamlogix_proto_depIdxs = []int32{
 	4,   // 26: pamlogix.StatUpdate.operator:type_name -> pamlogix.StatUpdateOperator
 	19,  // 27: pamlogix.StatUpdateRequest.public:type_name -> pamlogix.StatUpdate
 	19,  // 28: pamlogix.StatUpdateRequest.private:type_name -> pamlogix.StatUpdate
-	314, // 29: pamlogix.Stat.additional_properties:type_name -> google.protobuf.Struct
+	315, // 29: pamlogix.Stat.additional_properties:type_name -> google.protobuf.Struct
 	225, // 30: pamlogix.StatList.public:type_name -> pamlogix.StatList.PublicEntry
 	226, // 31: pamlogix.StatList.private:type_name -> pamlogix.StatList.PrivateEntry
 	227, // 32: pamlogix.DevicePrefsRequest.preferences:type_name -> pamlogix.DevicePrefsRequest.PreferencesEntry
@@ -17355,286 +17600,293 @@ var file_pamlogix_proto_depIdxs = []int32{
 	26,  // 38: pamlogix.Reward.energy_modifiers:type_name -> pamlogix.RewardEnergyModifier
 	27,  // 39: pamlogix.Reward.reward_modifiers:type_name -> pamlogix.RewardModifier
 	233, // 40: pamlogix.Reward.item_instances:type_name -> pamlogix.Reward.ItemInstancesEntry
-	29,  // 41: pamlogix.RewardList.rewards:type_name -> pamlogix.Reward
-	234, // 42: pamlogix.AvailableRewardsStringProperty.options:type_name -> pamlogix.AvailableRewardsStringProperty.OptionsEntry
-	32,  // 43: pamlogix.AvailableRewardsItem.count:type_name -> pamlogix.RewardRangeInt64
-	235, // 44: pamlogix.AvailableRewardsItem.numeric_properties:type_name -> pamlogix.AvailableRewardsItem.NumericPropertiesEntry
-	236, // 45: pamlogix.AvailableRewardsItem.string_properties:type_name -> pamlogix.AvailableRewardsItem.StringPropertiesEntry
-	32,  // 46: pamlogix.AvailableRewardsItemSet.count:type_name -> pamlogix.RewardRangeInt64
-	32,  // 47: pamlogix.AvailableRewardsCurrency.count:type_name -> pamlogix.RewardRangeInt64
-	31,  // 48: pamlogix.AvailableRewardsEnergy.count:type_name -> pamlogix.RewardRangeInt32
-	32,  // 49: pamlogix.AvailableRewardsEnergyModifier.value:type_name -> pamlogix.RewardRangeInt64
-	33,  // 50: pamlogix.AvailableRewardsEnergyModifier.duration_sec:type_name -> pamlogix.RewardRangeUInt64
-	32,  // 51: pamlogix.AvailableRewardsRewardModifier.value:type_name -> pamlogix.RewardRangeInt64
-	33,  // 52: pamlogix.AvailableRewardsRewardModifier.duration_sec:type_name -> pamlogix.RewardRangeUInt64
-	237, // 53: pamlogix.AvailableRewardsContents.items:type_name -> pamlogix.AvailableRewardsContents.ItemsEntry
-	38,  // 54: pamlogix.AvailableRewardsContents.item_sets:type_name -> pamlogix.AvailableRewardsItemSet
-	238, // 55: pamlogix.AvailableRewardsContents.currencies:type_name -> pamlogix.AvailableRewardsContents.CurrenciesEntry
-	239, // 56: pamlogix.AvailableRewardsContents.energies:type_name -> pamlogix.AvailableRewardsContents.EnergiesEntry
-	41,  // 57: pamlogix.AvailableRewardsContents.energy_modifiers:type_name -> pamlogix.AvailableRewardsEnergyModifier
-	42,  // 58: pamlogix.AvailableRewardsContents.reward_modifiers:type_name -> pamlogix.AvailableRewardsRewardModifier
-	43,  // 59: pamlogix.AvailableRewards.guaranteed:type_name -> pamlogix.AvailableRewardsContents
-	43,  // 60: pamlogix.AvailableRewards.weighted:type_name -> pamlogix.AvailableRewardsContents
-	29,  // 61: pamlogix.IncentiveClaim.reward:type_name -> pamlogix.Reward
-	6,   // 62: pamlogix.Incentive.type:type_name -> pamlogix.IncentiveType
-	44,  // 63: pamlogix.Incentive.recipient_rewards:type_name -> pamlogix.AvailableRewards
-	44,  // 64: pamlogix.Incentive.sender_rewards:type_name -> pamlogix.AvailableRewards
-	29,  // 65: pamlogix.Incentive.rewards:type_name -> pamlogix.Reward
-	240, // 66: pamlogix.Incentive.claims:type_name -> pamlogix.Incentive.ClaimsEntry
-	314, // 67: pamlogix.Incentive.additional_properties:type_name -> google.protobuf.Struct
-	46,  // 68: pamlogix.IncentiveList.incentives:type_name -> pamlogix.Incentive
-	6,   // 69: pamlogix.IncentiveInfo.type:type_name -> pamlogix.IncentiveType
-	44,  // 70: pamlogix.IncentiveInfo.available_rewards:type_name -> pamlogix.AvailableRewards
-	29,  // 71: pamlogix.IncentiveInfo.reward:type_name -> pamlogix.Reward
-	44,  // 72: pamlogix.ChallengeRewardTier.available_rewards:type_name -> pamlogix.AvailableRewards
-	7,   // 73: pamlogix.ChallengeScore.state:type_name -> pamlogix.ChallengeState
-	61,  // 74: pamlogix.Challenge.reward_tiers:type_name -> pamlogix.ChallengeRewardTier
-	44,  // 75: pamlogix.Challenge.available_rewards:type_name -> pamlogix.AvailableRewards
-	241, // 76: pamlogix.Challenge.additional_properties:type_name -> pamlogix.Challenge.AdditionalPropertiesEntry
-	62,  // 77: pamlogix.Challenge.scores:type_name -> pamlogix.ChallengeScore
-	7,   // 78: pamlogix.Challenge.state:type_name -> pamlogix.ChallengeState
-	29,  // 79: pamlogix.Challenge.reward:type_name -> pamlogix.Reward
-	63,  // 80: pamlogix.ChallengesList.challenges:type_name -> pamlogix.Challenge
-	61,  // 81: pamlogix.ChallengeTemplate.reward_tiers:type_name -> pamlogix.ChallengeRewardTier
-	67,  // 82: pamlogix.ChallengeTemplate.players:type_name -> pamlogix.ChallengeMaxMinPlayers
-	68,  // 83: pamlogix.ChallengeTemplate.duration:type_name -> pamlogix.ChallengeMinMaxDuration
-	242, // 84: pamlogix.ChallengeTemplate.additional_properties:type_name -> pamlogix.ChallengeTemplate.AdditionalPropertiesEntry
-	243, // 85: pamlogix.ChallengeTemplates.templates:type_name -> pamlogix.ChallengeTemplates.TemplatesEntry
-	44,  // 86: pamlogix.EventLeaderboardRewardTier.available_rewards:type_name -> pamlogix.AvailableRewards
-	77,  // 87: pamlogix.EventLeaderboardRewardTiers.reward_tiers:type_name -> pamlogix.EventLeaderboardRewardTier
-	44,  // 88: pamlogix.EventLeaderboard.available_rewards:type_name -> pamlogix.AvailableRewards
-	244, // 89: pamlogix.EventLeaderboard.reward_tiers:type_name -> pamlogix.EventLeaderboard.RewardTiersEntry
-	245, // 90: pamlogix.EventLeaderboard.change_zones:type_name -> pamlogix.EventLeaderboard.ChangeZonesEntry
-	29,  // 91: pamlogix.EventLeaderboard.reward:type_name -> pamlogix.Reward
-	246, // 92: pamlogix.EventLeaderboard.additional_properties:type_name -> pamlogix.EventLeaderboard.AdditionalPropertiesEntry
-	76,  // 93: pamlogix.EventLeaderboard.scores:type_name -> pamlogix.EventLeaderboardScore
-	314, // 94: pamlogix.EventLeaderboard.matchmaker_properties:type_name -> google.protobuf.Struct
-	80,  // 95: pamlogix.EventLeaderboards.event_leaderboards:type_name -> pamlogix.EventLeaderboard
-	315, // 96: pamlogix.EventLeaderboardDebugRandomScoresRequest.operator:type_name -> google.protobuf.Int32Value
-	44,  // 97: pamlogix.EconomyDonation.recipient_available_rewards:type_name -> pamlogix.AvailableRewards
-	84,  // 98: pamlogix.EconomyDonation.contributors:type_name -> pamlogix.EconomyDonationContributor
-	44,  // 99: pamlogix.EconomyDonation.contributor_available_rewards:type_name -> pamlogix.AvailableRewards
-	29,  // 100: pamlogix.EconomyDonation.recipient_rewards:type_name -> pamlogix.Reward
-	247, // 101: pamlogix.EconomyDonation.additional_properties:type_name -> pamlogix.EconomyDonation.AdditionalPropertiesEntry
-	85,  // 102: pamlogix.EconomyDonationAck.donation:type_name -> pamlogix.EconomyDonation
-	85,  // 103: pamlogix.EconomyDonationsList.donations:type_name -> pamlogix.EconomyDonation
-	248, // 104: pamlogix.EconomyDonationClaimRequestDetails.donors:type_name -> pamlogix.EconomyDonationClaimRequestDetails.DonorsEntry
-	249, // 105: pamlogix.EconomyDonationClaimRequest.donations:type_name -> pamlogix.EconomyDonationClaimRequest.DonationsEntry
-	87,  // 106: pamlogix.EconomyDonationClaimRewards.donations:type_name -> pamlogix.EconomyDonationsList
-	250, // 107: pamlogix.EconomyDonationClaimRewards.claimed_rewards:type_name -> pamlogix.EconomyDonationClaimRewards.ClaimedRewardsEntry
-	251, // 108: pamlogix.EconomyDonationsByUserList.user_donations:type_name -> pamlogix.EconomyDonationsByUserList.UserDonationsEntry
-	252, // 109: pamlogix.EconomyListStoreItemCost.currencies:type_name -> pamlogix.EconomyListStoreItemCost.CurrenciesEntry
-	95,  // 110: pamlogix.EconomyListStoreItem.cost:type_name -> pamlogix.EconomyListStoreItemCost
-	44,  // 111: pamlogix.EconomyListStoreItem.available_rewards:type_name -> pamlogix.AvailableRewards
-	253, // 112: pamlogix.EconomyListStoreItem.additional_properties:type_name -> pamlogix.EconomyListStoreItem.AdditionalPropertiesEntry
-	29,  // 113: pamlogix.EconomyListPlacement.reward:type_name -> pamlogix.Reward
-	44,  // 114: pamlogix.EconomyListPlacement.available_rewards:type_name -> pamlogix.AvailableRewards
-	254, // 115: pamlogix.EconomyListPlacement.additional_properties:type_name -> pamlogix.EconomyListPlacement.AdditionalPropertiesEntry
-	96,  // 116: pamlogix.EconomyList.store_items:type_name -> pamlogix.EconomyListStoreItem
-	97,  // 117: pamlogix.EconomyList.placements:type_name -> pamlogix.EconomyListPlacement
-	255, // 118: pamlogix.EconomyList.donations:type_name -> pamlogix.EconomyList.DonationsEntry
-	28,  // 119: pamlogix.EconomyList.active_reward_modifiers:type_name -> pamlogix.ActiveRewardModifier
-	44,  // 120: pamlogix.InventoryItem.consume_available_rewards:type_name -> pamlogix.AvailableRewards
-	256, // 121: pamlogix.InventoryItem.string_properties:type_name -> pamlogix.InventoryItem.StringPropertiesEntry
-	257, // 122: pamlogix.InventoryItem.numeric_properties:type_name -> pamlogix.InventoryItem.NumericPropertiesEntry
-	258, // 123: pamlogix.InventoryGrantRequest.items:type_name -> pamlogix.InventoryGrantRequest.ItemsEntry
-	259, // 124: pamlogix.InventoryUpdateItemProperties.string_properties:type_name -> pamlogix.InventoryUpdateItemProperties.StringPropertiesEntry
-	260, // 125: pamlogix.InventoryUpdateItemProperties.numeric_properties:type_name -> pamlogix.InventoryUpdateItemProperties.NumericPropertiesEntry
-	261, // 126: pamlogix.InventoryUpdateItemsRequest.item_updates:type_name -> pamlogix.InventoryUpdateItemsRequest.ItemUpdatesEntry
-	262, // 127: pamlogix.Inventory.items:type_name -> pamlogix.Inventory.ItemsEntry
-	263, // 128: pamlogix.InventoryConsumeRequest.items:type_name -> pamlogix.InventoryConsumeRequest.ItemsEntry
-	264, // 129: pamlogix.InventoryConsumeRequest.instances:type_name -> pamlogix.InventoryConsumeRequest.InstancesEntry
-	104, // 130: pamlogix.InventoryConsumeRewards.inventory:type_name -> pamlogix.Inventory
-	265, // 131: pamlogix.InventoryConsumeRewards.rewards:type_name -> pamlogix.InventoryConsumeRewards.RewardsEntry
-	266, // 132: pamlogix.InventoryConsumeRewards.instance_rewards:type_name -> pamlogix.InventoryConsumeRewards.InstanceRewardsEntry
-	104, // 133: pamlogix.InventoryUpdateAck.inventory:type_name -> pamlogix.Inventory
-	267, // 134: pamlogix.InventoryList.items:type_name -> pamlogix.InventoryList.ItemsEntry
-	268, // 135: pamlogix.AuctionBidAmount.currencies:type_name -> pamlogix.AuctionBidAmount.CurrenciesEntry
-	109, // 136: pamlogix.AuctionFee.fixed:type_name -> pamlogix.AuctionBidAmount
-	269, // 137: pamlogix.AuctionTemplateConditionListingCost.currencies:type_name -> pamlogix.AuctionTemplateConditionListingCost.CurrenciesEntry
-	270, // 138: pamlogix.AuctionTemplateConditionListingCost.items:type_name -> pamlogix.AuctionTemplateConditionListingCost.ItemsEntry
-	271, // 139: pamlogix.AuctionTemplateConditionListingCost.energies:type_name -> pamlogix.AuctionTemplateConditionListingCost.EnergiesEntry
-	109, // 140: pamlogix.AuctionTemplateConditionBidIncrement.fixed:type_name -> pamlogix.AuctionBidAmount
-	111, // 141: pamlogix.AuctionTemplateCondition.listing_cost:type_name -> pamlogix.AuctionTemplateConditionListingCost
-	109, // 142: pamlogix.AuctionTemplateCondition.bid_start:type_name -> pamlogix.AuctionBidAmount
-	112, // 143: pamlogix.AuctionTemplateCondition.bid_increment:type_name -> pamlogix.AuctionTemplateConditionBidIncrement
-	110, // 144: pamlogix.AuctionTemplateCondition.fee:type_name -> pamlogix.AuctionFee
-	272, // 145: pamlogix.AuctionTemplate.conditions:type_name -> pamlogix.AuctionTemplate.ConditionsEntry
-	273, // 146: pamlogix.AuctionTemplates.templates:type_name -> pamlogix.AuctionTemplates.TemplatesEntry
-	99,  // 147: pamlogix.AuctionReward.items:type_name -> pamlogix.InventoryItem
-	109, // 148: pamlogix.AuctionBid.bid:type_name -> pamlogix.AuctionBidAmount
-	116, // 149: pamlogix.Auction.reward:type_name -> pamlogix.AuctionReward
-	110, // 150: pamlogix.Auction.fee:type_name -> pamlogix.AuctionFee
-	117, // 151: pamlogix.Auction.bid:type_name -> pamlogix.AuctionBid
-	109, // 152: pamlogix.Auction.bid_next:type_name -> pamlogix.AuctionBidAmount
-	117, // 153: pamlogix.Auction.bid_first:type_name -> pamlogix.AuctionBid
-	117, // 154: pamlogix.Auction.bid_history:type_name -> pamlogix.AuctionBid
-	117, // 155: pamlogix.AuctionNotificationBid.bid:type_name -> pamlogix.AuctionBid
-	109, // 156: pamlogix.AuctionNotificationBid.bid_next:type_name -> pamlogix.AuctionBidAmount
-	119, // 157: pamlogix.StreamEnvelope.auction_bid:type_name -> pamlogix.AuctionNotificationBid
-	118, // 158: pamlogix.AuctionClaimBid.auction:type_name -> pamlogix.Auction
-	116, // 159: pamlogix.AuctionClaimBid.reward:type_name -> pamlogix.AuctionReward
-	118, // 160: pamlogix.AuctionClaimCreated.auction:type_name -> pamlogix.Auction
-	109, // 161: pamlogix.AuctionClaimCreated.reward:type_name -> pamlogix.AuctionBidAmount
-	109, // 162: pamlogix.AuctionClaimCreated.fee:type_name -> pamlogix.AuctionBidAmount
-	99,  // 163: pamlogix.AuctionClaimCreated.returned_items:type_name -> pamlogix.InventoryItem
-	118, // 164: pamlogix.AuctionCancel.auction:type_name -> pamlogix.Auction
-	116, // 165: pamlogix.AuctionCancel.reward:type_name -> pamlogix.AuctionReward
-	118, // 166: pamlogix.AuctionList.auctions:type_name -> pamlogix.Auction
-	109, // 167: pamlogix.AuctionBidRequest.bid:type_name -> pamlogix.AuctionBidAmount
-	5,   // 168: pamlogix.EconomyListRequest.store_type:type_name -> pamlogix.EconomyStoreType
-	274, // 169: pamlogix.EconomyGrantRequest.currencies:type_name -> pamlogix.EconomyGrantRequest.CurrenciesEntry
-	27,  // 170: pamlogix.EconomyGrantRequest.reward_modifiers:type_name -> pamlogix.RewardModifier
-	275, // 171: pamlogix.EconomyGrantRequest.items:type_name -> pamlogix.EconomyGrantRequest.ItemsEntry
-	5,   // 172: pamlogix.EconomyPurchaseIntentRequest.store_type:type_name -> pamlogix.EconomyStoreType
-	5,   // 173: pamlogix.EconomyPurchaseRequest.store_type:type_name -> pamlogix.EconomyStoreType
-	5,   // 174: pamlogix.EconomyPurchaseRestoreRequest.store_type:type_name -> pamlogix.EconomyStoreType
-	276, // 175: pamlogix.EconomyPlacementStartRequest.metadata:type_name -> pamlogix.EconomyPlacementStartRequest.MetadataEntry
-	29,  // 176: pamlogix.EconomyPlacementStatus.reward:type_name -> pamlogix.Reward
-	277, // 177: pamlogix.EconomyPlacementStatus.metadata:type_name -> pamlogix.EconomyPlacementStatus.MetadataEntry
-	278, // 178: pamlogix.EconomyUpdateAck.wallet:type_name -> pamlogix.EconomyUpdateAck.WalletEntry
-	104, // 179: pamlogix.EconomyUpdateAck.inventory:type_name -> pamlogix.Inventory
-	29,  // 180: pamlogix.EconomyUpdateAck.reward:type_name -> pamlogix.Reward
-	28,  // 181: pamlogix.EconomyUpdateAck.active_reward_modifiers:type_name -> pamlogix.ActiveRewardModifier
-	279, // 182: pamlogix.EconomyPurchaseAck.wallet:type_name -> pamlogix.EconomyPurchaseAck.WalletEntry
-	104, // 183: pamlogix.EconomyPurchaseAck.inventory:type_name -> pamlogix.Inventory
-	29,  // 184: pamlogix.EconomyPurchaseAck.reward:type_name -> pamlogix.Reward
-	144, // 185: pamlogix.Energy.modifiers:type_name -> pamlogix.EnergyModifier
-	44,  // 186: pamlogix.Energy.available_rewards:type_name -> pamlogix.AvailableRewards
-	280, // 187: pamlogix.Energy.additional_properties:type_name -> pamlogix.Energy.AdditionalPropertiesEntry
-	281, // 188: pamlogix.EnergyList.energies:type_name -> pamlogix.EnergyList.EnergiesEntry
-	282, // 189: pamlogix.EnergySpendRequest.amounts:type_name -> pamlogix.EnergySpendRequest.AmountsEntry
-	146, // 190: pamlogix.EnergySpendReward.energies:type_name -> pamlogix.EnergyList
-	29,  // 191: pamlogix.EnergySpendReward.reward:type_name -> pamlogix.Reward
-	283, // 192: pamlogix.EnergyGrantRequest.amounts:type_name -> pamlogix.EnergyGrantRequest.AmountsEntry
-	26,  // 193: pamlogix.EnergyGrantRequest.modifiers:type_name -> pamlogix.RewardEnergyModifier
-	150, // 194: pamlogix.LeaderboardConfigList.leaderboard_configs:type_name -> pamlogix.LeaderboardConfig
-	8,   // 195: pamlogix.Tutorial.state:type_name -> pamlogix.TutorialState
-	284, // 196: pamlogix.Tutorial.additional_properties:type_name -> pamlogix.Tutorial.AdditionalPropertiesEntry
-	285, // 197: pamlogix.TutorialList.tutorials:type_name -> pamlogix.TutorialList.TutorialsEntry
-	160, // 198: pamlogix.TeamList.teams:type_name -> pamlogix.Team
-	286, // 199: pamlogix.UnlockableCost.items:type_name -> pamlogix.UnlockableCost.ItemsEntry
-	287, // 200: pamlogix.UnlockableCost.currencies:type_name -> pamlogix.UnlockableCost.CurrenciesEntry
-	166, // 201: pamlogix.Unlockable.start_cost:type_name -> pamlogix.UnlockableCost
-	166, // 202: pamlogix.Unlockable.cost:type_name -> pamlogix.UnlockableCost
-	29,  // 203: pamlogix.Unlockable.reward:type_name -> pamlogix.Reward
-	44,  // 204: pamlogix.Unlockable.available_rewards:type_name -> pamlogix.AvailableRewards
-	288, // 205: pamlogix.Unlockable.additional_properties:type_name -> pamlogix.Unlockable.AdditionalPropertiesEntry
-	289, // 206: pamlogix.UnlockableSlotCost.items:type_name -> pamlogix.UnlockableSlotCost.ItemsEntry
-	290, // 207: pamlogix.UnlockableSlotCost.currencies:type_name -> pamlogix.UnlockableSlotCost.CurrenciesEntry
-	167, // 208: pamlogix.UnlockablesList.unlockables:type_name -> pamlogix.Unlockable
-	167, // 209: pamlogix.UnlockablesList.overflow:type_name -> pamlogix.Unlockable
-	168, // 210: pamlogix.UnlockablesList.slot_cost:type_name -> pamlogix.UnlockableSlotCost
-	169, // 211: pamlogix.UnlockablesReward.unlockables:type_name -> pamlogix.UnlockablesList
-	29,  // 212: pamlogix.UnlockablesReward.reward:type_name -> pamlogix.Reward
-	44,  // 213: pamlogix.UnlockablesReward.available_rewards:type_name -> pamlogix.AvailableRewards
-	29,  // 214: pamlogix.SubAchievement.reward:type_name -> pamlogix.Reward
-	44,  // 215: pamlogix.SubAchievement.available_rewards:type_name -> pamlogix.AvailableRewards
-	291, // 216: pamlogix.SubAchievement.additional_properties:type_name -> pamlogix.SubAchievement.AdditionalPropertiesEntry
-	44,  // 217: pamlogix.Achievement.available_rewards:type_name -> pamlogix.AvailableRewards
-	29,  // 218: pamlogix.Achievement.reward:type_name -> pamlogix.Reward
-	44,  // 219: pamlogix.Achievement.available_total_reward:type_name -> pamlogix.AvailableRewards
-	29,  // 220: pamlogix.Achievement.total_reward:type_name -> pamlogix.Reward
-	292, // 221: pamlogix.Achievement.sub_achievements:type_name -> pamlogix.Achievement.SubAchievementsEntry
-	293, // 222: pamlogix.Achievement.additional_properties:type_name -> pamlogix.Achievement.AdditionalPropertiesEntry
-	294, // 223: pamlogix.AchievementList.achievements:type_name -> pamlogix.AchievementList.AchievementsEntry
-	295, // 224: pamlogix.AchievementList.repeat_achievements:type_name -> pamlogix.AchievementList.RepeatAchievementsEntry
-	296, // 225: pamlogix.AchievementsUpdateAck.achievements:type_name -> pamlogix.AchievementsUpdateAck.AchievementsEntry
-	297, // 226: pamlogix.AchievementsUpdateAck.repeat_achievements:type_name -> pamlogix.AchievementsUpdateAck.RepeatAchievementsEntry
-	298, // 227: pamlogix.AchievementsUpdateRequest.achievements:type_name -> pamlogix.AchievementsUpdateRequest.AchievementsEntry
-	44,  // 228: pamlogix.StreakAvailableReward.reward:type_name -> pamlogix.AvailableRewards
-	29,  // 229: pamlogix.StreakReward.reward:type_name -> pamlogix.Reward
-	182, // 230: pamlogix.Streak.rewards:type_name -> pamlogix.StreakAvailableReward
-	182, // 231: pamlogix.Streak.available_rewards:type_name -> pamlogix.StreakAvailableReward
-	183, // 232: pamlogix.Streak.claimed_rewards:type_name -> pamlogix.StreakReward
-	299, // 233: pamlogix.StreaksList.streaks:type_name -> pamlogix.StreaksList.StreaksEntry
-	300, // 234: pamlogix.StreaksUpdateRequest.updates:type_name -> pamlogix.StreaksUpdateRequest.UpdatesEntry
-	301, // 235: pamlogix.SyncInventoryItem.string_properties:type_name -> pamlogix.SyncInventoryItem.StringPropertiesEntry
-	302, // 236: pamlogix.SyncInventoryItem.numeric_properties:type_name -> pamlogix.SyncInventoryItem.NumericPropertiesEntry
-	303, // 237: pamlogix.SyncInventory.items:type_name -> pamlogix.SyncInventory.ItemsEntry
-	304, // 238: pamlogix.SyncEconomy.currencies:type_name -> pamlogix.SyncEconomy.CurrenciesEntry
-	28,  // 239: pamlogix.SyncEconomy.modifiers:type_name -> pamlogix.ActiveRewardModifier
-	305, // 240: pamlogix.SyncAchievements.achievements:type_name -> pamlogix.SyncAchievements.AchievementsEntry
-	306, // 241: pamlogix.SyncEnergy.energies:type_name -> pamlogix.SyncEnergy.EnergiesEntry
-	144, // 242: pamlogix.SyncEnergy.modifiers:type_name -> pamlogix.EnergyModifier
-	307, // 243: pamlogix.SyncEventLeaderboards.event_leaderboards:type_name -> pamlogix.SyncEventLeaderboards.EventLeaderboardsEntry
-	308, // 244: pamlogix.SyncProgressionUpdate.counts:type_name -> pamlogix.SyncProgressionUpdate.CountsEntry
-	9,   // 245: pamlogix.SyncProgressionUpdate.cost:type_name -> pamlogix.ProgressionCost
-	309, // 246: pamlogix.SyncProgressions.progressions:type_name -> pamlogix.SyncProgressions.ProgressionsEntry
-	310, // 247: pamlogix.SyncTutorials.updates:type_name -> pamlogix.SyncTutorials.UpdatesEntry
-	311, // 248: pamlogix.SyncUnlockables.updates:type_name -> pamlogix.SyncUnlockables.UpdatesEntry
-	183, // 249: pamlogix.SyncStreakUpdate.claimed_rewards:type_name -> pamlogix.StreakReward
-	312, // 250: pamlogix.SyncStreaks.updates:type_name -> pamlogix.SyncStreaks.UpdatesEntry
-	190, // 251: pamlogix.SyncRequest.inventory:type_name -> pamlogix.SyncInventory
-	191, // 252: pamlogix.SyncRequest.economy:type_name -> pamlogix.SyncEconomy
-	193, // 253: pamlogix.SyncRequest.achievements:type_name -> pamlogix.SyncAchievements
-	195, // 254: pamlogix.SyncRequest.energy:type_name -> pamlogix.SyncEnergy
-	197, // 255: pamlogix.SyncRequest.event_leaderboards:type_name -> pamlogix.SyncEventLeaderboards
-	199, // 256: pamlogix.SyncRequest.progressions:type_name -> pamlogix.SyncProgressions
-	20,  // 257: pamlogix.SyncRequest.stats:type_name -> pamlogix.StatUpdateRequest
-	200, // 258: pamlogix.SyncRequest.tutorials:type_name -> pamlogix.SyncTutorials
-	202, // 259: pamlogix.SyncRequest.unlockables:type_name -> pamlogix.SyncUnlockables
-	204, // 260: pamlogix.SyncRequest.streaks:type_name -> pamlogix.SyncStreaks
-	313, // 261: pamlogix.SyncResponse.wallet:type_name -> pamlogix.SyncResponse.WalletEntry
-	104, // 262: pamlogix.SyncResponse.inventory:type_name -> pamlogix.Inventory
-	177, // 263: pamlogix.SyncResponse.achievements:type_name -> pamlogix.AchievementList
-	146, // 264: pamlogix.SyncResponse.energy:type_name -> pamlogix.EnergyList
-	80,  // 265: pamlogix.SyncResponse.event_leaderboards:type_name -> pamlogix.EventLeaderboard
-	14,  // 266: pamlogix.SyncResponse.progressions:type_name -> pamlogix.ProgressionList
-	22,  // 267: pamlogix.SyncResponse.stats:type_name -> pamlogix.StatList
-	153, // 268: pamlogix.SyncResponse.tutorials:type_name -> pamlogix.TutorialList
-	169, // 269: pamlogix.SyncResponse.unlockables:type_name -> pamlogix.UnlockablesList
-	28,  // 270: pamlogix.SyncResponse.active_reward_modifiers:type_name -> pamlogix.ActiveRewardModifier
-	185, // 271: pamlogix.SyncResponse.streaks:type_name -> pamlogix.StreaksList
-	12,  // 272: pamlogix.ProgressionList.ProgressionsEntry.value:type_name -> pamlogix.Progression
-	13,  // 273: pamlogix.ProgressionList.DeltasEntry.value:type_name -> pamlogix.ProgressionDelta
-	12,  // 274: pamlogix.ProgressionGetRequest.ProgressionsEntry.value:type_name -> pamlogix.Progression
-	21,  // 275: pamlogix.StatList.PublicEntry.value:type_name -> pamlogix.Stat
-	21,  // 276: pamlogix.StatList.PrivateEntry.value:type_name -> pamlogix.Stat
-	25,  // 277: pamlogix.Reward.ItemInstancesEntry.value:type_name -> pamlogix.RewardInventoryItem
-	35,  // 278: pamlogix.AvailableRewardsStringProperty.OptionsEntry.value:type_name -> pamlogix.AvailableRewardsStringPropertyOption
-	34,  // 279: pamlogix.AvailableRewardsItem.NumericPropertiesEntry.value:type_name -> pamlogix.RewardRangeDouble
-	36,  // 280: pamlogix.AvailableRewardsItem.StringPropertiesEntry.value:type_name -> pamlogix.AvailableRewardsStringProperty
-	37,  // 281: pamlogix.AvailableRewardsContents.ItemsEntry.value:type_name -> pamlogix.AvailableRewardsItem
-	39,  // 282: pamlogix.AvailableRewardsContents.CurrenciesEntry.value:type_name -> pamlogix.AvailableRewardsCurrency
-	40,  // 283: pamlogix.AvailableRewardsContents.EnergiesEntry.value:type_name -> pamlogix.AvailableRewardsEnergy
-	45,  // 284: pamlogix.Incentive.ClaimsEntry.value:type_name -> pamlogix.IncentiveClaim
-	69,  // 285: pamlogix.ChallengeTemplates.TemplatesEntry.value:type_name -> pamlogix.ChallengeTemplate
-	78,  // 286: pamlogix.EventLeaderboard.RewardTiersEntry.value:type_name -> pamlogix.EventLeaderboardRewardTiers
-	79,  // 287: pamlogix.EventLeaderboard.ChangeZonesEntry.value:type_name -> pamlogix.EventLeaderboardChangeZone
-	88,  // 288: pamlogix.EconomyDonationClaimRequest.DonationsEntry.value:type_name -> pamlogix.EconomyDonationClaimRequestDetails
-	30,  // 289: pamlogix.EconomyDonationClaimRewards.ClaimedRewardsEntry.value:type_name -> pamlogix.RewardList
-	87,  // 290: pamlogix.EconomyDonationsByUserList.UserDonationsEntry.value:type_name -> pamlogix.EconomyDonationsList
-	85,  // 291: pamlogix.EconomyList.DonationsEntry.value:type_name -> pamlogix.EconomyDonation
-	102, // 292: pamlogix.InventoryUpdateItemsRequest.ItemUpdatesEntry.value:type_name -> pamlogix.InventoryUpdateItemProperties
-	99,  // 293: pamlogix.Inventory.ItemsEntry.value:type_name -> pamlogix.InventoryItem
-	30,  // 294: pamlogix.InventoryConsumeRewards.RewardsEntry.value:type_name -> pamlogix.RewardList
-	30,  // 295: pamlogix.InventoryConsumeRewards.InstanceRewardsEntry.value:type_name -> pamlogix.RewardList
-	99,  // 296: pamlogix.InventoryList.ItemsEntry.value:type_name -> pamlogix.InventoryItem
-	113, // 297: pamlogix.AuctionTemplate.ConditionsEntry.value:type_name -> pamlogix.AuctionTemplateCondition
-	114, // 298: pamlogix.AuctionTemplates.TemplatesEntry.value:type_name -> pamlogix.AuctionTemplate
-	145, // 299: pamlogix.EnergyList.EnergiesEntry.value:type_name -> pamlogix.Energy
-	152, // 300: pamlogix.TutorialList.TutorialsEntry.value:type_name -> pamlogix.Tutorial
-	175, // 301: pamlogix.Achievement.SubAchievementsEntry.value:type_name -> pamlogix.SubAchievement
-	176, // 302: pamlogix.AchievementList.AchievementsEntry.value:type_name -> pamlogix.Achievement
-	176, // 303: pamlogix.AchievementList.RepeatAchievementsEntry.value:type_name -> pamlogix.Achievement
-	176, // 304: pamlogix.AchievementsUpdateAck.AchievementsEntry.value:type_name -> pamlogix.Achievement
-	176, // 305: pamlogix.AchievementsUpdateAck.RepeatAchievementsEntry.value:type_name -> pamlogix.Achievement
-	184, // 306: pamlogix.StreaksList.StreaksEntry.value:type_name -> pamlogix.Streak
-	189, // 307: pamlogix.SyncInventory.ItemsEntry.value:type_name -> pamlogix.SyncInventoryItem
-	192, // 308: pamlogix.SyncAchievements.AchievementsEntry.value:type_name -> pamlogix.SyncAchievementsUpdate
-	194, // 309: pamlogix.SyncEnergy.EnergiesEntry.value:type_name -> pamlogix.SyncEnergyState
-	196, // 310: pamlogix.SyncEventLeaderboards.EventLeaderboardsEntry.value:type_name -> pamlogix.SyncEventLeaderboardUpdate
-	198, // 311: pamlogix.SyncProgressions.ProgressionsEntry.value:type_name -> pamlogix.SyncProgressionUpdate
-	201, // 312: pamlogix.SyncUnlockables.UpdatesEntry.value:type_name -> pamlogix.SyncUnlockableUpdate
-	203, // 313: pamlogix.SyncStreaks.UpdatesEntry.value:type_name -> pamlogix.SyncStreakUpdate
-	316, // 314: pamlogix.input:extendee -> google.protobuf.EnumValueOptions
-	316, // 315: pamlogix.output:extendee -> google.protobuf.EnumValueOptions
-	316, // [316:316] is the sub-list for method output_type
-	316, // [316:316] is the sub-list for method input_type
-	316, // [316:316] is the sub-list for extension type_name
-	314, // [314:316] is the sub-list for extension extendee
-	0,   // [0:314] is the sub-list for field type_name
+	234, // 41: pamlogix.Reward.converted_items:type_name -> pamlogix.Reward.ConvertedItemsEntry
+	29,  // 42: pamlogix.RewardList.rewards:type_name -> pamlogix.Reward
+	235, // 43: pamlogix.AvailableRewardsStringProperty.options:type_name -> pamlogix.AvailableRewardsStringProperty.OptionsEntry
+	32,  // 44: pamlogix.AvailableRewardsItem.count:type_name -> pamlogix.RewardRangeInt64
+	236, // 45: pamlogix.AvailableRewardsItem.numeric_properties:type_name -> pamlogix.AvailableRewardsItem.NumericPropertiesEntry
+	237, // 46: pamlogix.AvailableRewardsItem.string_properties:type_name -> pamlogix.AvailableRewardsItem.StringPropertiesEntry
+	32,  // 47: pamlogix.AvailableRewardsItemSet.count:type_name -> pamlogix.RewardRangeInt64
+	32,  // 48: pamlogix.AvailableRewardsCurrency.count:type_name -> pamlogix.RewardRangeInt64
+	31,  // 49: pamlogix.AvailableRewardsEnergy.count:type_name -> pamlogix.RewardRangeInt32
+	32,  // 50: pamlogix.AvailableRewardsEnergyModifier.value:type_name -> pamlogix.RewardRangeInt64
+	33,  // 51: pamlogix.AvailableRewardsEnergyModifier.duration_sec:type_name -> pamlogix.RewardRangeUInt64
+	32,  // 52: pamlogix.AvailableRewardsRewardModifier.value:type_name -> pamlogix.RewardRangeInt64
+	33,  // 53: pamlogix.AvailableRewardsRewardModifier.duration_sec:type_name -> pamlogix.RewardRangeUInt64
+	238, // 54: pamlogix.AvailableRewardsContents.items:type_name -> pamlogix.AvailableRewardsContents.ItemsEntry
+	38,  // 55: pamlogix.AvailableRewardsContents.item_sets:type_name -> pamlogix.AvailableRewardsItemSet
+	239, // 56: pamlogix.AvailableRewardsContents.currencies:type_name -> pamlogix.AvailableRewardsContents.CurrenciesEntry
+	240, // 57: pamlogix.AvailableRewardsContents.energies:type_name -> pamlogix.AvailableRewardsContents.EnergiesEntry
+	41,  // 58: pamlogix.AvailableRewardsContents.energy_modifiers:type_name -> pamlogix.AvailableRewardsEnergyModifier
+	42,  // 59: pamlogix.AvailableRewardsContents.reward_modifiers:type_name -> pamlogix.AvailableRewardsRewardModifier
+	43,  // 60: pamlogix.AvailableRewards.guaranteed:type_name -> pamlogix.AvailableRewardsContents
+	43,  // 61: pamlogix.AvailableRewards.weighted:type_name -> pamlogix.AvailableRewardsContents
+	29,  // 62: pamlogix.IncentiveClaim.reward:type_name -> pamlogix.Reward
+	6,   // 63: pamlogix.Incentive.type:type_name -> pamlogix.IncentiveType
+	44,  // 64: pamlogix.Incentive.recipient_rewards:type_name -> pamlogix.AvailableRewards
+	44,  // 65: pamlogix.Incentive.sender_rewards:type_name -> pamlogix.AvailableRewards
+	29,  // 66: pamlogix.Incentive.rewards:type_name -> pamlogix.Reward
+	241, // 67: pamlogix.Incentive.claims:type_name -> pamlogix.Incentive.ClaimsEntry
+	315, // 68: pamlogix.Incentive.additional_properties:type_name -> google.protobuf.Struct
+	46,  // 69: pamlogix.IncentiveList.incentives:type_name -> pamlogix.Incentive
+	6,   // 70: pamlogix.IncentiveInfo.type:type_name -> pamlogix.IncentiveType
+	44,  // 71: pamlogix.IncentiveInfo.available_rewards:type_name -> pamlogix.AvailableRewards
+	29,  // 72: pamlogix.IncentiveInfo.reward:type_name -> pamlogix.Reward
+	44,  // 73: pamlogix.ChallengeRewardTier.available_rewards:type_name -> pamlogix.AvailableRewards
+	7,   // 74: pamlogix.ChallengeScore.state:type_name -> pamlogix.ChallengeState
+	61,  // 75: pamlogix.Challenge.reward_tiers:type_name -> pamlogix.ChallengeRewardTier
+	44,  // 76: pamlogix.Challenge.available_rewards:type_name -> pamlogix.AvailableRewards
+	242, // 77: pamlogix.Challenge.additional_properties:type_name -> pamlogix.Challenge.AdditionalPropertiesEntry
+	62,  // 78: pamlogix.Challenge.scores:type_name -> pamlogix.ChallengeScore
+	7,   // 79: pamlogix.Challenge.state:type_name -> pamlogix.ChallengeState
+	29,  // 80: pamlogix.Challenge.reward:type_name -> pamlogix.Reward
+	63,  // 81: pamlogix.ChallengesList.challenges:type_name -> pamlogix.Challenge
+	61,  // 82: pamlogix.ChallengeTemplate.reward_tiers:type_name -> pamlogix.ChallengeRewardTier
+	67,  // 83: pamlogix.ChallengeTemplate.players:type_name -> pamlogix.ChallengeMaxMinPlayers
+	68,  // 84: pamlogix.ChallengeTemplate.duration:type_name -> pamlogix.ChallengeMinMaxDuration
+	243, // 85: pamlogix.ChallengeTemplate.additional_properties:type_name -> pamlogix.ChallengeTemplate.AdditionalPropertiesEntry
+	244, // 86: pamlogix.ChallengeTemplates.templates:type_name -> pamlogix.ChallengeTemplates.TemplatesEntry
+	316, // 87: pamlogix.EventLeaderboardClaim.choice:type_name -> google.protobuf.Int32Value
+	44,  // 88: pamlogix.EventLeaderboardRewardTier.available_rewards:type_name -> pamlogix.AvailableRewards
+	44,  // 89: pamlogix.EventLeaderboardRewardTier.reward_choices:type_name -> pamlogix.AvailableRewards
+	77,  // 90: pamlogix.EventLeaderboardRewardTiers.reward_tiers:type_name -> pamlogix.EventLeaderboardRewardTier
+	44,  // 91: pamlogix.EventLeaderboard.available_rewards:type_name -> pamlogix.AvailableRewards
+	245, // 92: pamlogix.EventLeaderboard.reward_tiers:type_name -> pamlogix.EventLeaderboard.RewardTiersEntry
+	246, // 93: pamlogix.EventLeaderboard.change_zones:type_name -> pamlogix.EventLeaderboard.ChangeZonesEntry
+	29,  // 94: pamlogix.EventLeaderboard.reward:type_name -> pamlogix.Reward
+	247, // 95: pamlogix.EventLeaderboard.additional_properties:type_name -> pamlogix.EventLeaderboard.AdditionalPropertiesEntry
+	76,  // 96: pamlogix.EventLeaderboard.scores:type_name -> pamlogix.EventLeaderboardScore
+	315, // 97: pamlogix.EventLeaderboard.matchmaker_properties:type_name -> google.protobuf.Struct
+	80,  // 98: pamlogix.EventLeaderboards.event_leaderboards:type_name -> pamlogix.EventLeaderboard
+	316, // 99: pamlogix.EventLeaderboardDebugRandomScoresRequest.operator:type_name -> google.protobuf.Int32Value
+	44,  // 100: pamlogix.EconomyDonation.recipient_available_rewards:type_name -> pamlogix.AvailableRewards
+	84,  // 101: pamlogix.EconomyDonation.contributors:type_name -> pamlogix.EconomyDonationContributor
+	44,  // 102: pamlogix.EconomyDonation.contributor_available_rewards:type_name -> pamlogix.AvailableRewards
+	29,  // 103: pamlogix.EconomyDonation.recipient_rewards:type_name -> pamlogix.Reward
+	248, // 104: pamlogix.EconomyDonation.additional_properties:type_name -> pamlogix.EconomyDonation.AdditionalPropertiesEntry
+	85,  // 105: pamlogix.EconomyDonationAck.donation:type_name -> pamlogix.EconomyDonation
+	85,  // 106: pamlogix.EconomyDonationsList.donations:type_name -> pamlogix.EconomyDonation
+	249, // 107: pamlogix.EconomyDonationClaimRequestDetails.donors:type_name -> pamlogix.EconomyDonationClaimRequestDetails.DonorsEntry
+	250, // 108: pamlogix.EconomyDonationClaimRequest.donations:type_name -> pamlogix.EconomyDonationClaimRequest.DonationsEntry
+	87,  // 109: pamlogix.EconomyDonationClaimRewards.donations:type_name -> pamlogix.EconomyDonationsList
+	251, // 110: pamlogix.EconomyDonationClaimRewards.claimed_rewards:type_name -> pamlogix.EconomyDonationClaimRewards.ClaimedRewardsEntry
+	252, // 111: pamlogix.EconomyDonationsByUserList.user_donations:type_name -> pamlogix.EconomyDonationsByUserList.UserDonationsEntry
+	253, // 112: pamlogix.EconomyListStoreItemCost.currencies:type_name -> pamlogix.EconomyListStoreItemCost.CurrenciesEntry
+	95,  // 113: pamlogix.EconomyListStoreItem.cost:type_name -> pamlogix.EconomyListStoreItemCost
+	44,  // 114: pamlogix.EconomyListStoreItem.available_rewards:type_name -> pamlogix.AvailableRewards
+	254, // 115: pamlogix.EconomyListStoreItem.additional_properties:type_name -> pamlogix.EconomyListStoreItem.AdditionalPropertiesEntry
+	29,  // 116: pamlogix.EconomyListPlacement.reward:type_name -> pamlogix.Reward
+	44,  // 117: pamlogix.EconomyListPlacement.available_rewards:type_name -> pamlogix.AvailableRewards
+	255, // 118: pamlogix.EconomyListPlacement.additional_properties:type_name -> pamlogix.EconomyListPlacement.AdditionalPropertiesEntry
+	96,  // 119: pamlogix.EconomyList.store_items:type_name -> pamlogix.EconomyListStoreItem
+	97,  // 120: pamlogix.EconomyList.placements:type_name -> pamlogix.EconomyListPlacement
+	256, // 121: pamlogix.EconomyList.donations:type_name -> pamlogix.EconomyList.DonationsEntry
+	28,  // 122: pamlogix.EconomyList.active_reward_modifiers:type_name -> pamlogix.ActiveRewardModifier
+	44,  // 123: pamlogix.InventoryItem.consume_available_rewards:type_name -> pamlogix.AvailableRewards
+	257, // 124: pamlogix.InventoryItem.string_properties:type_name -> pamlogix.InventoryItem.StringPropertiesEntry
+	258, // 125: pamlogix.InventoryItem.numeric_properties:type_name -> pamlogix.InventoryItem.NumericPropertiesEntry
+	259, // 126: pamlogix.InventoryGrantRequest.items:type_name -> pamlogix.InventoryGrantRequest.ItemsEntry
+	260, // 127: pamlogix.InventoryUpdateItemProperties.string_properties:type_name -> pamlogix.InventoryUpdateItemProperties.StringPropertiesEntry
+	261, // 128: pamlogix.InventoryUpdateItemProperties.numeric_properties:type_name -> pamlogix.InventoryUpdateItemProperties.NumericPropertiesEntry
+	262, // 129: pamlogix.InventoryUpdateItemsRequest.item_updates:type_name -> pamlogix.InventoryUpdateItemsRequest.ItemUpdatesEntry
+	263, // 130: pamlogix.Inventory.items:type_name -> pamlogix.Inventory.ItemsEntry
+	264, // 131: pamlogix.InventoryConsumeRequest.items:type_name -> pamlogix.InventoryConsumeRequest.ItemsEntry
+	265, // 132: pamlogix.InventoryConsumeRequest.instances:type_name -> pamlogix.InventoryConsumeRequest.InstancesEntry
+	104, // 133: pamlogix.InventoryConsumeRewards.inventory:type_name -> pamlogix.Inventory
+	266, // 134: pamlogix.InventoryConsumeRewards.rewards:type_name -> pamlogix.InventoryConsumeRewards.RewardsEntry
+	267, // 135: pamlogix.InventoryConsumeRewards.instance_rewards:type_name -> pamlogix.InventoryConsumeRewards.InstanceRewardsEntry
+	104, // 136: pamlogix.InventoryUpdateAck.inventory:type_name -> pamlogix.Inventory
+	268, // 137: pamlogix.InventoryList.items:type_name -> pamlogix.InventoryList.ItemsEntry
+	269, // 138: pamlogix.AuctionBidAmount.currencies:type_name -> pamlogix.AuctionBidAmount.CurrenciesEntry
+	109, // 139: pamlogix.AuctionFee.fixed:type_name -> pamlogix.AuctionBidAmount
+	270, // 140: pamlogix.AuctionTemplateConditionListingCost.currencies:type_name -> pamlogix.AuctionTemplateConditionListingCost.CurrenciesEntry
+	271, // 141: pamlogix.AuctionTemplateConditionListingCost.items:type_name -> pamlogix.AuctionTemplateConditionListingCost.ItemsEntry
+	272, // 142: pamlogix.AuctionTemplateConditionListingCost.energies:type_name -> pamlogix.AuctionTemplateConditionListingCost.EnergiesEntry
+	109, // 143: pamlogix.AuctionTemplateConditionBidIncrement.fixed:type_name -> pamlogix.AuctionBidAmount
+	111, // 144: pamlogix.AuctionTemplateCondition.listing_cost:type_name -> pamlogix.AuctionTemplateConditionListingCost
+	109, // 145: pamlogix.AuctionTemplateCondition.bid_start:type_name -> pamlogix.AuctionBidAmount
+	112, // 146: pamlogix.AuctionTemplateCondition.bid_increment:type_name -> pamlogix.AuctionTemplateConditionBidIncrement
+	110, // 147: pamlogix.AuctionTemplateCondition.fee:type_name -> pamlogix.AuctionFee
+	273, // 148: pamlogix.AuctionTemplate.conditions:type_name -> pamlogix.AuctionTemplate.ConditionsEntry
+	274, // 149: pamlogix.AuctionTemplates.templates:type_name -> pamlogix.AuctionTemplates.TemplatesEntry
+	99,  // 150: pamlogix.AuctionReward.items:type_name -> pamlogix.InventoryItem
+	109, // 151: pamlogix.AuctionBid.bid:type_name -> pamlogix.AuctionBidAmount
+	116, // 152: pamlogix.Auction.reward:type_name -> pamlogix.AuctionReward
+	110, // 153: pamlogix.Auction.fee:type_name -> pamlogix.AuctionFee
+	117, // 154: pamlogix.Auction.bid:type_name -> pamlogix.AuctionBid
+	109, // 155: pamlogix.Auction.bid_next:type_name -> pamlogix.AuctionBidAmount
+	117, // 156: pamlogix.Auction.bid_first:type_name -> pamlogix.AuctionBid
+	117, // 157: pamlogix.Auction.bid_history:type_name -> pamlogix.AuctionBid
+	109, // 158: pamlogix.Auction.estimated_value:type_name -> pamlogix.AuctionBidAmount
+	109, // 159: pamlogix.Auction.buyout:type_name -> pamlogix.AuctionBidAmount
+	117, // 160: pamlogix.AuctionNotificationBid.bid:type_name -> pamlogix.AuctionBid
+	109, // 161: pamlogix.AuctionNotificationBid.bid_next:type_name -> pamlogix.AuctionBidAmount
+	119, // 162: pamlogix.StreamEnvelope.auction_bid:type_name -> pamlogix.AuctionNotificationBid
+	118, // 163: pamlogix.AuctionClaimBid.auction:type_name -> pamlogix.Auction
+	116, // 164: pamlogix.AuctionClaimBid.reward:type_name -> pamlogix.AuctionReward
+	118, // 165: pamlogix.AuctionClaimCreated.auction:type_name -> pamlogix.Auction
+	109, // 166: pamlogix.AuctionClaimCreated.reward:type_name -> pamlogix.AuctionBidAmount
+	109, // 167: pamlogix.AuctionClaimCreated.fee:type_name -> pamlogix.AuctionBidAmount
+	99,  // 168: pamlogix.AuctionClaimCreated.returned_items:type_name -> pamlogix.InventoryItem
+	109, // 169: pamlogix.AuctionClaimCreated.proceeds:type_name -> pamlogix.AuctionBidAmount
+	118, // 170: pamlogix.AuctionCancel.auction:type_name -> pamlogix.Auction
+	116, // 171: pamlogix.AuctionCancel.reward:type_name -> pamlogix.AuctionReward
+	118, // 172: pamlogix.AuctionList.auctions:type_name -> pamlogix.Auction
+	109, // 173: pamlogix.AuctionBidRequest.bid:type_name -> pamlogix.AuctionBidAmount
+	5,   // 174: pamlogix.EconomyListRequest.store_type:type_name -> pamlogix.EconomyStoreType
+	275, // 175: pamlogix.EconomyGrantRequest.currencies:type_name -> pamlogix.EconomyGrantRequest.CurrenciesEntry
+	27,  // 176: pamlogix.EconomyGrantRequest.reward_modifiers:type_name -> pamlogix.RewardModifier
+	276, // 177: pamlogix.EconomyGrantRequest.items:type_name -> pamlogix.EconomyGrantRequest.ItemsEntry
+	5,   // 178: pamlogix.EconomyPurchaseIntentRequest.store_type:type_name -> pamlogix.EconomyStoreType
+	5,   // 179: pamlogix.EconomyPurchaseRequest.store_type:type_name -> pamlogix.EconomyStoreType
+	5,   // 180: pamlogix.EconomyPurchaseRestoreRequest.store_type:type_name -> pamlogix.EconomyStoreType
+	277, // 181: pamlogix.EconomyPlacementStartRequest.metadata:type_name -> pamlogix.EconomyPlacementStartRequest.MetadataEntry
+	29,  // 182: pamlogix.EconomyPlacementStatus.reward:type_name -> pamlogix.Reward
+	278, // 183: pamlogix.EconomyPlacementStatus.metadata:type_name -> pamlogix.EconomyPlacementStatus.MetadataEntry
+	44,  // 184: pamlogix.EconomyPlacementStatus.reward_preview:type_name -> pamlogix.AvailableRewards
+	279, // 185: pamlogix.EconomyUpdateAck.wallet:type_name -> pamlogix.EconomyUpdateAck.WalletEntry
+	104, // 186: pamlogix.EconomyUpdateAck.inventory:type_name -> pamlogix.Inventory
+	29,  // 187: pamlogix.EconomyUpdateAck.reward:type_name -> pamlogix.Reward
+	28,  // 188: pamlogix.EconomyUpdateAck.active_reward_modifiers:type_name -> pamlogix.ActiveRewardModifier
+	280, // 189: pamlogix.EconomyPurchaseAck.wallet:type_name -> pamlogix.EconomyPurchaseAck.WalletEntry
+	104, // 190: pamlogix.EconomyPurchaseAck.inventory:type_name -> pamlogix.Inventory
+	29,  // 191: pamlogix.EconomyPurchaseAck.reward:type_name -> pamlogix.Reward
+	144, // 192: pamlogix.Energy.modifiers:type_name -> pamlogix.EnergyModifier
+	44,  // 193: pamlogix.Energy.available_rewards:type_name -> pamlogix.AvailableRewards
+	281, // 194: pamlogix.Energy.additional_properties:type_name -> pamlogix.Energy.AdditionalPropertiesEntry
+	282, // 195: pamlogix.EnergyList.energies:type_name -> pamlogix.EnergyList.EnergiesEntry
+	283, // 196: pamlogix.EnergySpendRequest.amounts:type_name -> pamlogix.EnergySpendRequest.AmountsEntry
+	146, // 197: pamlogix.EnergySpendReward.energies:type_name -> pamlogix.EnergyList
+	29,  // 198: pamlogix.EnergySpendReward.reward:type_name -> pamlogix.Reward
+	284, // 199: pamlogix.EnergyGrantRequest.amounts:type_name -> pamlogix.EnergyGrantRequest.AmountsEntry
+	26,  // 200: pamlogix.EnergyGrantRequest.modifiers:type_name -> pamlogix.RewardEnergyModifier
+	150, // 201: pamlogix.LeaderboardConfigList.leaderboard_configs:type_name -> pamlogix.LeaderboardConfig
+	8,   // 202: pamlogix.Tutorial.state:type_name -> pamlogix.TutorialState
+	285, // 203: pamlogix.Tutorial.additional_properties:type_name -> pamlogix.Tutorial.AdditionalPropertiesEntry
+	286, // 204: pamlogix.TutorialList.tutorials:type_name -> pamlogix.TutorialList.TutorialsEntry
+	160, // 205: pamlogix.TeamList.teams:type_name -> pamlogix.Team
+	287, // 206: pamlogix.UnlockableCost.items:type_name -> pamlogix.UnlockableCost.ItemsEntry
+	288, // 207: pamlogix.UnlockableCost.currencies:type_name -> pamlogix.UnlockableCost.CurrenciesEntry
+	166, // 208: pamlogix.Unlockable.start_cost:type_name -> pamlogix.UnlockableCost
+	166, // 209: pamlogix.Unlockable.cost:type_name -> pamlogix.UnlockableCost
+	29,  // 210: pamlogix.Unlockable.reward:type_name -> pamlogix.Reward
+	44,  // 211: pamlogix.Unlockable.available_rewards:type_name -> pamlogix.AvailableRewards
+	289, // 212: pamlogix.Unlockable.additional_properties:type_name -> pamlogix.Unlockable.AdditionalPropertiesEntry
+	290, // 213: pamlogix.UnlockableSlotCost.items:type_name -> pamlogix.UnlockableSlotCost.ItemsEntry
+	291, // 214: pamlogix.UnlockableSlotCost.currencies:type_name -> pamlogix.UnlockableSlotCost.CurrenciesEntry
+	167, // 215: pamlogix.UnlockablesList.unlockables:type_name -> pamlogix.Unlockable
+	167, // 216: pamlogix.UnlockablesList.overflow:type_name -> pamlogix.Unlockable
+	168, // 217: pamlogix.UnlockablesList.slot_cost:type_name -> pamlogix.UnlockableSlotCost
+	169, // 218: pamlogix.UnlockablesReward.unlockables:type_name -> pamlogix.UnlockablesList
+	29,  // 219: pamlogix.UnlockablesReward.reward:type_name -> pamlogix.Reward
+	44,  // 220: pamlogix.UnlockablesReward.available_rewards:type_name -> pamlogix.AvailableRewards
+	29,  // 221: pamlogix.SubAchievement.reward:type_name -> pamlogix.Reward
+	44,  // 222: pamlogix.SubAchievement.available_rewards:type_name -> pamlogix.AvailableRewards
+	292, // 223: pamlogix.SubAchievement.additional_properties:type_name -> pamlogix.SubAchievement.AdditionalPropertiesEntry
+	44,  // 224: pamlogix.Achievement.available_rewards:type_name -> pamlogix.AvailableRewards
+	29,  // 225: pamlogix.Achievement.reward:type_name -> pamlogix.Reward
+	44,  // 226: pamlogix.Achievement.available_total_reward:type_name -> pamlogix.AvailableRewards
+	29,  // 227: pamlogix.Achievement.total_reward:type_name -> pamlogix.Reward
+	293, // 228: pamlogix.Achievement.sub_achievements:type_name -> pamlogix.Achievement.SubAchievementsEntry
+	294, // 229: pamlogix.Achievement.additional_properties:type_name -> pamlogix.Achievement.AdditionalPropertiesEntry
+	295, // 230: pamlogix.AchievementList.achievements:type_name -> pamlogix.AchievementList.AchievementsEntry
+	296, // 231: pamlogix.AchievementList.repeat_achievements:type_name -> pamlogix.AchievementList.RepeatAchievementsEntry
+	297, // 232: pamlogix.AchievementsUpdateAck.achievements:type_name -> pamlogix.AchievementsUpdateAck.AchievementsEntry
+	298, // 233: pamlogix.AchievementsUpdateAck.repeat_achievements:type_name -> pamlogix.AchievementsUpdateAck.RepeatAchievementsEntry
+	299, // 234: pamlogix.AchievementsUpdateRequest.achievements:type_name -> pamlogix.AchievementsUpdateRequest.AchievementsEntry
+	44,  // 235: pamlogix.StreakAvailableReward.reward:type_name -> pamlogix.AvailableRewards
+	29,  // 236: pamlogix.StreakReward.reward:type_name -> pamlogix.Reward
+	182, // 237: pamlogix.Streak.rewards:type_name -> pamlogix.StreakAvailableReward
+	182, // 238: pamlogix.Streak.available_rewards:type_name -> pamlogix.StreakAvailableReward
+	183, // 239: pamlogix.Streak.claimed_rewards:type_name -> pamlogix.StreakReward
+	300, // 240: pamlogix.StreaksList.streaks:type_name -> pamlogix.StreaksList.StreaksEntry
+	301, // 241: pamlogix.StreaksUpdateRequest.updates:type_name -> pamlogix.StreaksUpdateRequest.UpdatesEntry
+	302, // 242: pamlogix.SyncInventoryItem.string_properties:type_name -> pamlogix.SyncInventoryItem.StringPropertiesEntry
+	303, // 243: pamlogix.SyncInventoryItem.numeric_properties:type_name -> pamlogix.SyncInventoryItem.NumericPropertiesEntry
+	304, // 244: pamlogix.SyncInventory.items:type_name -> pamlogix.SyncInventory.ItemsEntry
+	305, // 245: pamlogix.SyncEconomy.currencies:type_name -> pamlogix.SyncEconomy.CurrenciesEntry
+	28,  // 246: pamlogix.SyncEconomy.modifiers:type_name -> pamlogix.ActiveRewardModifier
+	306, // 247: pamlogix.SyncAchievements.achievements:type_name -> pamlogix.SyncAchievements.AchievementsEntry
+	307, // 248: pamlogix.SyncEnergy.energies:type_name -> pamlogix.SyncEnergy.EnergiesEntry
+	144, // 249: pamlogix.SyncEnergy.modifiers:type_name -> pamlogix.EnergyModifier
+	308, // 250: pamlogix.SyncEventLeaderboards.event_leaderboards:type_name -> pamlogix.SyncEventLeaderboards.EventLeaderboardsEntry
+	309, // 251: pamlogix.SyncProgressionUpdate.counts:type_name -> pamlogix.SyncProgressionUpdate.CountsEntry
+	9,   // 252: pamlogix.SyncProgressionUpdate.cost:type_name -> pamlogix.ProgressionCost
+	310, // 253: pamlogix.SyncProgressions.progressions:type_name -> pamlogix.SyncProgressions.ProgressionsEntry
+	311, // 254: pamlogix.SyncTutorials.updates:type_name -> pamlogix.SyncTutorials.UpdatesEntry
+	312, // 255: pamlogix.SyncUnlockables.updates:type_name -> pamlogix.SyncUnlockables.UpdatesEntry
+	183, // 256: pamlogix.SyncStreakUpdate.claimed_rewards:type_name -> pamlogix.StreakReward
+	313, // 257: pamlogix.SyncStreaks.updates:type_name -> pamlogix.SyncStreaks.UpdatesEntry
+	190, // 258: pamlogix.SyncRequest.inventory:type_name -> pamlogix.SyncInventory
+	191, // 259: pamlogix.SyncRequest.economy:type_name -> pamlogix.SyncEconomy
+	193, // 260: pamlogix.SyncRequest.achievements:type_name -> pamlogix.SyncAchievements
+	195, // 261: pamlogix.SyncRequest.energy:type_name -> pamlogix.SyncEnergy
+	197, // 262: pamlogix.SyncRequest.event_leaderboards:type_name -> pamlogix.SyncEventLeaderboards
+	199, // 263: pamlogix.SyncRequest.progressions:type_name -> pamlogix.SyncProgressions
+	20,  // 264: pamlogix.SyncRequest.stats:type_name -> pamlogix.StatUpdateRequest
+	200, // 265: pamlogix.SyncRequest.tutorials:type_name -> pamlogix.SyncTutorials
+	202, // 266: pamlogix.SyncRequest.unlockables:type_name -> pamlogix.SyncUnlockables
+	204, // 267: pamlogix.SyncRequest.streaks:type_name -> pamlogix.SyncStreaks
+	314, // 268: pamlogix.SyncResponse.wallet:type_name -> pamlogix.SyncResponse.WalletEntry
+	104, // 269: pamlogix.SyncResponse.inventory:type_name -> pamlogix.Inventory
+	177, // 270: pamlogix.SyncResponse.achievements:type_name -> pamlogix.AchievementList
+	146, // 271: pamlogix.SyncResponse.energy:type_name -> pamlogix.EnergyList
+	80,  // 272: pamlogix.SyncResponse.event_leaderboards:type_name -> pamlogix.EventLeaderboard
+	14,  // 273: pamlogix.SyncResponse.progressions:type_name -> pamlogix.ProgressionList
+	22,  // 274: pamlogix.SyncResponse.stats:type_name -> pamlogix.StatList
+	153, // 275: pamlogix.SyncResponse.tutorials:type_name -> pamlogix.TutorialList
+	169, // 276: pamlogix.SyncResponse.unlockables:type_name -> pamlogix.UnlockablesList
+	28,  // 277: pamlogix.SyncResponse.active_reward_modifiers:type_name -> pamlogix.ActiveRewardModifier
+	185, // 278: pamlogix.SyncResponse.streaks:type_name -> pamlogix.StreaksList
+	12,  // 279: pamlogix.ProgressionList.ProgressionsEntry.value:type_name -> pamlogix.Progression
+	13,  // 280: pamlogix.ProgressionList.DeltasEntry.value:type_name -> pamlogix.ProgressionDelta
+	12,  // 281: pamlogix.ProgressionGetRequest.ProgressionsEntry.value:type_name -> pamlogix.Progression
+	21,  // 282: pamlogix.StatList.PublicEntry.value:type_name -> pamlogix.Stat
+	21,  // 283: pamlogix.StatList.PrivateEntry.value:type_name -> pamlogix.Stat
+	25,  // 284: pamlogix.Reward.ItemInstancesEntry.value:type_name -> pamlogix.RewardInventoryItem
+	35,  // 285: pamlogix.AvailableRewardsStringProperty.OptionsEntry.value:type_name -> pamlogix.AvailableRewardsStringPropertyOption
+	34,  // 286: pamlogix.AvailableRewardsItem.NumericPropertiesEntry.value:type_name -> pamlogix.RewardRangeDouble
+	36,  // 287: pamlogix.AvailableRewardsItem.StringPropertiesEntry.value:type_name -> pamlogix.AvailableRewardsStringProperty
+	37,  // 288: pamlogix.AvailableRewardsContents.ItemsEntry.value:type_name -> pamlogix.AvailableRewardsItem
+	39,  // 289: pamlogix.AvailableRewardsContents.CurrenciesEntry.value:type_name -> pamlogix.AvailableRewardsCurrency
+	40,  // 290: pamlogix.AvailableRewardsContents.EnergiesEntry.value:type_name -> pamlogix.AvailableRewardsEnergy
+	45,  // 291: pamlogix.Incentive.ClaimsEntry.value:type_name -> pamlogix.IncentiveClaim
+	69,  // 292: pamlogix.ChallengeTemplates.TemplatesEntry.value:type_name -> pamlogix.ChallengeTemplate
+	78,  // 293: pamlogix.EventLeaderboard.RewardTiersEntry.value:type_name -> pamlogix.EventLeaderboardRewardTiers
+	79,  // 294: pamlogix.EventLeaderboard.ChangeZonesEntry.value:type_name -> pamlogix.EventLeaderboardChangeZone
+	88,  // 295: pamlogix.EconomyDonationClaimRequest.DonationsEntry.value:type_name -> pamlogix.EconomyDonationClaimRequestDetails
+	30,  // 296: pamlogix.EconomyDonationClaimRewards.ClaimedRewardsEntry.value:type_name -> pamlogix.RewardList
+	87,  // 297: pamlogix.EconomyDonationsByUserList.UserDonationsEntry.value:type_name -> pamlogix.EconomyDonationsList
+	85,  // 298: pamlogix.EconomyList.DonationsEntry.value:type_name -> pamlogix.EconomyDonation
+	102, // 299: pamlogix.InventoryUpdateItemsRequest.ItemUpdatesEntry.value:type_name -> pamlogix.InventoryUpdateItemProperties
+	99,  // 300: pamlogix.Inventory.ItemsEntry.value:type_name -> pamlogix.InventoryItem
+	30,  // 301: pamlogix.InventoryConsumeRewards.RewardsEntry.value:type_name -> pamlogix.RewardList
+	30,  // 302: pamlogix.InventoryConsumeRewards.InstanceRewardsEntry.value:type_name -> pamlogix.RewardList
+	99,  // 303: pamlogix.InventoryList.ItemsEntry.value:type_name -> pamlogix.InventoryItem
+	113, // 304: pamlogix.AuctionTemplate.ConditionsEntry.value:type_name -> pamlogix.AuctionTemplateCondition
+	114, // 305: pamlogix.AuctionTemplates.TemplatesEntry.value:type_name -> pamlogix.AuctionTemplate
+	145, // 306: pamlogix.EnergyList.EnergiesEntry.value:type_name -> pamlogix.Energy
+	152, // 307: pamlogix.TutorialList.TutorialsEntry.value:type_name -> pamlogix.Tutorial
+	175, // 308: pamlogix.Achievement.SubAchievementsEntry.value:type_name -> pamlogix.SubAchievement
+	176, // 309: pamlogix.AchievementList.AchievementsEntry.value:type_name -> pamlogix.Achievement
+	176, // 310: pamlogix.AchievementList.RepeatAchievementsEntry.value:type_name -> pamlogix.Achievement
+	176, // 311: pamlogix.AchievementsUpdateAck.AchievementsEntry.value:type_name -> pamlogix.Achievement
+	176, // 312: pamlogix.AchievementsUpdateAck.RepeatAchievementsEntry.value:type_name -> pamlogix.Achievement
+	184, // 313: pamlogix.StreaksList.StreaksEntry.value:type_name -> pamlogix.Streak
+	189, // 314: pamlogix.SyncInventory.ItemsEntry.value:type_name -> pamlogix.SyncInventoryItem
+	192, // 315: pamlogix.SyncAchievements.AchievementsEntry.value:type_name -> pamlogix.SyncAchievementsUpdate
+	194, // 316: pamlogix.SyncEnergy.EnergiesEntry.value:type_name -> pamlogix.SyncEnergyState
+	196, // 317: pamlogix.SyncEventLeaderboards.EventLeaderboardsEntry.value:type_name -> pamlogix.SyncEventLeaderboardUpdate
+	198, // 318: pamlogix.SyncProgressions.ProgressionsEntry.value:type_name -> pamlogix.SyncProgressionUpdate
+	201, // 319: pamlogix.SyncUnlockables.UpdatesEntry.value:type_name -> pamlogix.SyncUnlockableUpdate
+	203, // 320: pamlogix.SyncStreaks.UpdatesEntry.value:type_name -> pamlogix.SyncStreakUpdate
+	317, // 321: pamlogix.input:extendee -> google.protobuf.EnumValueOptions
+	317, // 322: pamlogix.output:extendee -> google.protobuf.EnumValueOptions
+	323, // [323:323] is the sub-list for method output_type
+	323, // [323:323] is the sub-list for method input_type
+	323, // [323:323] is the sub-list for extension type_name
+	321, // [321:323] is the sub-list for extension extendee
+	0,   // [0:321] is the sub-list for field type_name
 }
 
 func init() { file_pamlogix_proto_init() }
@@ -17651,7 +17903,7 @@ func file_pamlogix_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pamlogix_proto_rawDesc), len(file_pamlogix_proto_rawDesc)),
 			NumEnums:      9,
-			NumMessages:   305,
+			NumMessages:   306,
 			NumExtensions: 2,
 			NumServices:   0,
 		},
@@ -28,6 +28,8 @@ type ProgressionConfigProgression struct {
 	Preconditions        *ProgressionPreconditionsBlock `json:"preconditions,omitempty"`
 	ResetSchedule        string                         `json:"reset_schedule,omitempty"`
 	Rewards              *EconomyConfigReward           `json:"rewards,omitempty"`
+	// Notify sends the user a "progression_milestone" notification when they purchase or complete the progression.
+	Notify bool `json:"notify,omitempty"`
 }
 
 // A ProgressionSystem is a gameplay system which represents a sequence of progression steps.
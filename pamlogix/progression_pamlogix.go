@@ -3,6 +3,7 @@ package pamlogix
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 	"time"
 
 	"github.com/heroiclabs/nakama-common/runtime"
@@ -14,6 +15,12 @@ const (
 	userProgressionStorageKey    = "user_progressions"
 )
 
+// Publisher event names for progression milestones.
+const (
+	progressionMilestonePurchased = "progression_purchased"
+	progressionMilestoneCompleted = "progression_completed"
+)
+
 // NakamaProgressionSystem implements the ProgressionSystem interface using Nakama as the backend.
 type NakamaProgressionSystem struct {
 	config     *ProgressionConfig
@@ -228,7 +235,13 @@ func (p *NakamaProgressionSystem) Purchase(ctx context.Context, logger runtime.L
 
 	// Return updated progressions
 	progressions, _, err = p.Get(ctx, logger, nk, userID, nil)
-	return progressions, err
+	if err != nil {
+		return nil, err
+	}
+
+	p.publishMilestone(ctx, logger, nk, userID, progressionID, progressionMilestonePurchased, progressionConfig, progressions)
+
+	return progressions, nil
 }
 
 // Update a specified progression, if that progression supports this operation.
@@ -407,7 +420,61 @@ func (p *NakamaProgressionSystem) Complete(ctx context.Context, logger runtime.L
 
 	// Return updated progressions
 	progressions, _, err = p.Get(ctx, logger, nk, userID, nil)
-	return progressions, reward, err
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.publishMilestone(ctx, logger, nk, userID, progressionID, progressionMilestoneCompleted, progressionConfig, progressions)
+
+	return progressions, reward, nil
+}
+
+// publishMilestone sends a publisher event for a purchased or completed progression, and notifies the user if the
+// progression is configured to. Both carry the share of all progressions the user has unlocked so far.
+func (p *NakamaProgressionSystem) publishMilestone(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, progressionID, milestone string, progressionConfig *ProgressionConfigProgression, progressions map[string]*Progression) {
+	completion := strconv.Itoa(progressionCompletionPercentage(progressions))
+
+	if pl, ok := p.pamlogix.(interface {
+		SendPublisherEvents(context.Context, runtime.Logger, runtime.NakamaModule, string, []*PublisherEvent)
+	}); ok {
+		pl.SendPublisherEvents(ctx, logger, nk, userID, []*PublisherEvent{{
+			Name:      milestone,
+			Id:        progressionID,
+			Timestamp: time.Now().Unix(),
+			Metadata: map[string]string{
+				"progression_id":        progressionID,
+				"category":              progressionConfig.Category,
+				"completion_percentage": completion,
+			},
+			Value:    completion,
+			System:   p,
+			SourceId: progressionID,
+			Source:   progressionConfig,
+		}})
+	}
+
+	if !progressionConfig.Notify {
+		return
+	}
+	vars := map[string]string{"progression": progressionConfig.Name, "completion_percentage": completion}
+	content := map[string]interface{}{"progression_id": progressionID, "milestone": milestone, "completion_percentage": completion}
+	if err := sendTemplatedNotification(ctx, logger, nk, p.pamlogix, userID, NotificationEventProgressionMilestone, vars, content); err != nil {
+		logger.Warn("Failed to send progression %s milestone notification to user %s: %v", progressionID, userID, err)
+	}
+}
+
+// progressionCompletionPercentage is the share of the configured progressions the user has unlocked, rounded down.
+func progressionCompletionPercentage(progressions map[string]*Progression) int {
+	if len(progressions) == 0 {
+		return 0
+	}
+	unlocked := 0
+	for _, progression := range progressions {
+		if progression.Unlocked {
+			unlocked++
+		}
+	}
+	return unlocked * 100 / len(progressions)
 }
 
 // Helper methods
@@ -938,3 +938,64 @@ func TestProgressionSystem_NilConfig(t *testing.T) {
 }
 
 // Mock implementations are available in other test files
+
+// recordingPublisher keeps the events sent to it.
+type recordingPublisher struct {
+	events []*PublisherEvent
+}
+
+func (r *recordingPublisher) Authenticate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, created bool) {
+}
+
+func (r *recordingPublisher) Send(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, events []*PublisherEvent) {
+	r.events = append(r.events, events...)
+}
+
+func TestProgressionSystem_Complete_PublishesMilestone(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newNotificationNakama()
+	publisher := &recordingPublisher{}
+	progressionSystem := NewNakamaProgressionSystem(&ProgressionConfig{
+		Progressions: map[string]*ProgressionConfigProgression{
+			"chapter1": {
+				Name:          "Chapter 1",
+				Category:      "story",
+				Notify:        true,
+				Preconditions: &ProgressionPreconditionsBlock{Direct: &ProgressionPreconditions{Counts: map[string]int64{"levels": 3}}},
+			},
+			"chapter2": {
+				Name:          "Chapter 2",
+				Category:      "story",
+				Preconditions: &ProgressionPreconditionsBlock{Direct: &ProgressionPreconditions{Counts: map[string]int64{"levels": 6}}},
+			},
+		},
+	})
+	progressionSystem.SetPamlogix(&pamlogixImpl{systems: make(map[SystemType]System), publishers: []Publisher{publisher}})
+
+	require.NoError(t, progressionSystem.saveUserProgressions(ctx, logger, nk, "user1", map[string]*SyncProgressionUpdate{
+		"chapter1": {Counts: map[string]int64{"levels": 3}},
+	}))
+
+	_, _, err := progressionSystem.Complete(ctx, logger, nk, "user1", "chapter1")
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 1)
+	event := publisher.events[0]
+	assert.Equal(t, "progression_completed", event.Name)
+	assert.Equal(t, "chapter1", event.SourceId)
+	assert.Equal(t, map[string]string{"progression_id": "chapter1", "category": "story", "completion_percentage": "50"}, event.Metadata)
+
+	require.Len(t, nk.sent, 1)
+	assert.Equal(t, 1401, nk.sent[0].code)
+	assert.Equal(t, "chapter1", nk.sent[0].content["progression_id"])
+}
+
+func TestProgressionCompletionPercentage(t *testing.T) {
+	assert.Equal(t, 0, progressionCompletionPercentage(nil))
+	assert.Equal(t, 33, progressionCompletionPercentage(map[string]*Progression{
+		"a": {Unlocked: true},
+		"b": {},
+		"c": {},
+	}))
+}
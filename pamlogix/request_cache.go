@@ -0,0 +1,26 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// requestCacheInitializer gives every RPC registered through it a fresh wallet and storage cache, shared by all the
+// systems the RPC calls into.
+type requestCacheInitializer struct {
+	runtime.Initializer
+}
+
+func (i *requestCacheInitializer) RegisterRpc(id string, fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) error {
+	return i.Initializer.RegisterRpc(id, withRequestCacheRpc(fn))
+}
+
+func withRequestCacheRpc(fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		ctx = withWalletCache(ctx, newWalletCache())
+		ctx = withStorageCache(ctx, newStorageCache())
+		return fn(ctx, logger, db, &storageCacheNakama{NakamaModule: &walletCacheNakama{NakamaModule: nk}}, payload)
+	}
+}
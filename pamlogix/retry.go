@@ -0,0 +1,94 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	nakamaRetryMaxAttempts = 3
+	nakamaRetryBaseBackoff = 25 * time.Millisecond
+	nakamaRetryMaxBackoff  = 400 * time.Millisecond
+)
+
+// nakamaCall says whether a Nakama call can be repeated safely, which decides the failures it's retried on.
+type nakamaCall int
+
+const (
+	// idempotentCall leaves the same state however many times it's made, such as a read, a list, a delete or a storage
+	// write of a whole object. It's retried on any transient failure.
+	idempotentCall nakamaCall = iota
+	// nonIdempotentCall applies a change on top of the current state, such as a wallet update. It's only retried when
+	// the failure shows the call wasn't applied, since repeating one that was would apply it twice.
+	nonIdempotentCall
+)
+
+// sqlStateError is implemented by the Postgres driver errors Nakama returns from failed queries.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// retryNakama makes a Nakama call, retrying transient failures with jittered exponential backoff. Any other error, or
+// the last transient one, is returned as it is.
+func retryNakama(ctx context.Context, call nakamaCall, fn func() error) error {
+	backoff := nakamaRetryBaseBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= nakamaRetryMaxAttempts || !retryableNakamaError(err, call) {
+			return err
+		}
+
+		// Equal jitter keeps at least half the backoff while spreading out callers that failed together
+		wait := backoff/2 + rand.N(backoff/2+1)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, nakamaRetryMaxBackoff)
+	}
+}
+
+// retryableNakamaError reports whether a failed call is worth making again.
+func retryableNakamaError(err error, call nakamaCall) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// These failures happen before the call is applied, or roll it back, so any call can be retried
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var runtimeErr *runtime.Error
+	if errors.As(err, &runtimeErr) {
+		return runtimeErr.Code == UNAVAILABLE_ERROR_CODE || runtimeErr.Code == RESOURCE_EXHAUSTED_ERROR_CODE
+	}
+	var sqlErr sqlStateError
+	if errors.As(err, &sqlErr) {
+		switch state := sqlErr.SQLState(); {
+		case state == "40001", state == "40P01", state == "53300":
+			// Serialization failures and deadlocks roll the transaction back, and too many connections never start one
+			return true
+		case len(state) == 5 && state[:2] == "08", state == "57P01":
+			// Connections lost partway through may have committed
+			return call == idempotentCall
+		default:
+			return false
+		}
+	}
+
+	// The remaining network failures may have happened after the call was applied
+	if call != idempotentCall {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,90 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSQLStateError string
+
+func (e testSQLStateError) Error() string    { return "sql error " + string(e) }
+func (e testSQLStateError) SQLState() string { return string(e) }
+
+func TestRetryNakama(t *testing.T) {
+	ctx := context.Background()
+	unavailable := runtime.NewError("unavailable", UNAVAILABLE_ERROR_CODE)
+
+	// Transient failures are retried until the call succeeds
+	calls := 0
+	err := retryNakama(ctx, idempotentCall, func() error {
+		calls++
+		if calls < 2 {
+			return unavailable
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// A call still failing after the last attempt returns its error
+	calls = 0
+	err = retryNakama(ctx, idempotentCall, func() error {
+		calls++
+		return unavailable
+	})
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, nakamaRetryMaxAttempts, calls)
+
+	// Other failures are returned straight away
+	calls = 0
+	err = retryNakama(ctx, idempotentCall, func() error {
+		calls++
+		return runtime.ErrStorageRejectedVersion
+	})
+	assert.ErrorIs(t, err, runtime.ErrStorageRejectedVersion)
+	assert.Equal(t, 1, calls)
+
+	// A cancelled context stops the retries
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	err = retryNakama(cancelled, idempotentCall, func() error {
+		calls++
+		return unavailable
+	})
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryableNakamaError(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		idempotent    bool
+		nonIdempotent bool
+	}{
+		{"unavailable", runtime.NewError("unavailable", UNAVAILABLE_ERROR_CODE), true, true},
+		{"resource exhausted", runtime.NewError("exhausted", RESOURCE_EXHAUSTED_ERROR_CODE), true, true},
+		{"bad input", ErrBadInput, false, false},
+		{"version conflict", runtime.ErrStorageRejectedVersion, false, false},
+		{"bad connection", driver.ErrBadConn, true, true},
+		{"serialization failure", testSQLStateError("40001"), true, true},
+		{"connection failure", testSQLStateError("08006"), true, false},
+		{"unique violation", testSQLStateError("23505"), false, false},
+		{"unexpected eof", io.ErrUnexpectedEOF, true, false},
+		{"deadline exceeded", context.DeadlineExceeded, false, false},
+		{"plain error", errors.New("user not found"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.idempotent, retryableNakamaError(tt.err, idempotentCall))
+			assert.Equal(t, tt.nonIdempotent, retryableNakamaError(tt.err, nonIdempotentCall))
+		})
+	}
+}
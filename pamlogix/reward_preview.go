@@ -0,0 +1,123 @@
+package pamlogix
+
+import (
+	"context"
+	"strings"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// RewardItemDetails is the display metadata of an item offered in a reward preview, resolved from the inventory
+// configuration so clients don't need their own copy of it.
+type RewardItemDetails struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Rarity      string `json:"rarity,omitempty"`
+}
+
+// rewardItemDetails resolves the items offered by reward previews to their display metadata, keyed by item ID and
+// translated into the user's language where the item has a translation. Items missing from the inventory configuration
+// are left out, and nil is returned when there's nothing to resolve.
+func rewardItemDetails(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID string, rewards ...*AvailableRewards) map[string]*RewardItemDetails {
+	if pl == nil || pl.GetInventorySystem() == nil {
+		return nil
+	}
+	inventoryConfig, ok := pl.GetInventorySystem().GetConfig().(*InventoryConfig)
+	if !ok || inventoryConfig == nil || len(inventoryConfig.Items) == 0 {
+		return nil
+	}
+
+	var details map[string]*RewardItemDetails
+	localized := false
+	for _, reward := range rewards {
+		if reward == nil {
+			continue
+		}
+		contents := append([]*AvailableRewardsContents{reward.Guaranteed}, reward.Weighted...)
+		for _, content := range contents {
+			for itemID := range content.GetItems() {
+				if _, found := details[itemID]; found {
+					continue
+				}
+				item, found := inventoryConfig.Items[itemID]
+				if !found || item == nil {
+					continue
+				}
+				if details == nil {
+					details = make(map[string]*RewardItemDetails)
+				}
+				details[itemID] = &RewardItemDetails{
+					Name:        item.Name,
+					Description: item.Description,
+					Category:    item.Category,
+					Icon:        item.Icon,
+					Rarity:      item.Rarity,
+				}
+				localized = localized || len(item.Localized) > 0
+			}
+		}
+	}
+
+	// Only look up the user's language when there's a translation to choose from
+	if !localized || userID == "" {
+		return details
+	}
+	langTag := userLangTag(ctx, logger, nk, userID)
+	for itemID, detail := range details {
+		text := inventoryConfig.Items[itemID].localizedText(langTag)
+		if text == nil {
+			continue
+		}
+		if text.Name != "" {
+			detail.Name = text.Name
+		}
+		if text.Description != "" {
+			detail.Description = text.Description
+		}
+	}
+	return details
+}
+
+// achievementListItemDetails resolves the items offered by the rewards of the achievements and their sub-achievements.
+func achievementListItemDetails(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID string, list *AchievementList) map[string]*RewardItemDetails {
+	rewards := make([]*AvailableRewards, 0)
+	for _, achievements := range []map[string]*Achievement{list.GetAchievements(), list.GetRepeatAchievements()} {
+		for _, achievement := range achievements {
+			rewards = append(rewards, achievement.GetAvailableRewards(), achievement.GetAvailableTotalReward())
+			for _, subAchievement := range achievement.GetSubAchievements() {
+				rewards = append(rewards, subAchievement.GetAvailableRewards())
+			}
+		}
+	}
+	return rewardItemDetails(ctx, logger, nk, pl, userID, rewards...)
+}
+
+// eventLeaderboardItemDetails resolves the items offered by the rewards of the event leaderboards and their tiers.
+func eventLeaderboardItemDetails(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID string, eventLeaderboards ...*EventLeaderboard) map[string]*RewardItemDetails {
+	rewards := make([]*AvailableRewards, 0)
+	for _, eventLeaderboard := range eventLeaderboards {
+		rewards = append(rewards, eventLeaderboard.GetAvailableRewards())
+		for _, tiers := range eventLeaderboard.GetRewardTiers() {
+			for _, tier := range tiers.GetRewardTiers() {
+				rewards = append(rewards, tier.GetAvailableRewards())
+			}
+		}
+	}
+	return rewardItemDetails(ctx, logger, nk, pl, userID, rewards...)
+}
+
+// localizedText returns the item's text for the language tag, falling back to the base language ("pt" for "pt-BR").
+func (i *InventoryConfigItem) localizedText(langTag string) *InventoryConfigItemText {
+	if langTag == "" || len(i.Localized) == 0 {
+		return nil
+	}
+	if text, found := i.Localized[langTag]; found {
+		return text
+	}
+	if base, _, found := strings.Cut(langTag, "-"); found {
+		return i.Localized[base]
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package pamlogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewardItemDetails(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newNotificationNakama()
+	nk.langTags["user1"] = "de-AT"
+
+	p := newBenchPamlogix()
+	inventorySystem := NewNakamaInventorySystem(&InventoryConfig{
+		Items: map[string]*InventoryConfigItem{
+			"potion": {Name: "Potion", Category: "consumable", Icon: "icons/potion.png", Rarity: "common",
+				Localized: map[string]*InventoryConfigItemText{"de": {Name: "Trank"}}},
+			"sword":  {Name: "Sword", Description: "A sharp blade", Category: "weapon", Rarity: "epic"},
+			"shield": {Name: "Shield"},
+		},
+	})
+	p.systems[SystemTypeInventory] = inventorySystem
+
+	rewards := rewardConfigToAvailable(&EconomyConfigReward{
+		Guaranteed: &EconomyConfigRewardContents{
+			Items: map[string]*EconomyConfigRewardItem{"potion": {}, "retired": {}},
+		},
+		Weighted: []*EconomyConfigRewardContents{
+			{Items: map[string]*EconomyConfigRewardItem{"sword": {}}},
+		},
+	})
+
+	details := rewardItemDetails(ctx, logger, nk, p, "user1", rewards, nil)
+	require.Len(t, details, 2)
+	assert.Equal(t, &RewardItemDetails{Name: "Trank", Category: "consumable", Icon: "icons/potion.png", Rarity: "common"}, details["potion"])
+	assert.Equal(t, &RewardItemDetails{Name: "Sword", Description: "A sharp blade", Category: "weapon", Rarity: "epic"}, details["sword"])
+
+	// Without a translation for the user's language the configured name is kept
+	details = rewardItemDetails(ctx, logger, nk, p, "user2", rewards)
+	assert.Equal(t, "Potion", details["potion"].Name)
+
+	assert.Nil(t, rewardItemDetails(ctx, logger, nk, p, "user1", rewardConfigToAvailable(&EconomyConfigReward{
+		Guaranteed: &EconomyConfigRewardContents{Currencies: map[string]*EconomyConfigRewardCurrency{benchCurrency: {}}},
+	})))
+}
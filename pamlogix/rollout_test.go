@@ -0,0 +1,74 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolloutIncludes(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	var none *BaseSystemConfigRollout
+	assert.True(t, none.includes("auctions", "user1", now))
+
+	allowlist := &BaseSystemConfigRollout{UserIDs: []string{"tester"}}
+	assert.True(t, allowlist.includes("auctions", "tester", now))
+	assert.False(t, allowlist.includes("auctions", "user1", now))
+
+	ended := &BaseSystemConfigRollout{EndTimeSec: 1000}
+	assert.True(t, ended.includes("auctions", "user1", now))
+	assert.False(t, ended.includes("auctions", "user1", now.Add(-time.Second)))
+
+	// A percentage lets in roughly that share of users, and every user let in stays in as it grows
+	half := &BaseSystemConfigRollout{Percentage: 50}
+	most := &BaseSystemConfigRollout{Percentage: 90}
+	included := 0
+	for i := 0; i < 1000; i++ {
+		userID := fmt.Sprintf("user%d", i)
+		if half.includes("auctions", userID, now) {
+			included++
+			assert.True(t, most.includes("auctions", userID, now))
+		}
+	}
+	assert.InDelta(t, 500, included, 75)
+}
+
+func TestRolloutRpc(t *testing.T) {
+	p := &pamlogixImpl{systems: make(map[SystemType]System)}
+	p.systems[SystemTypeBase] = NewBaseSystem(&BaseSystemConfig{
+		Rollouts: map[string]*BaseSystemConfigRollout{
+			"auctions": {UserIDs: []string{"tester"}},
+		},
+	})
+
+	rpc := func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		return "ok", nil
+	}
+	call := func(systemType SystemType, userID string) (string, error) {
+		ctx := context.Background()
+		if userID != "" {
+			ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_USER_ID, userID)
+		}
+		return withRolloutRpc(p, systemType, rpc)(ctx, &mockLogger{}, nil, nil, "")
+	}
+
+	_, err := call(SystemTypeAuctions, "user1")
+	assert.ErrorIs(t, err, ErrSystemNotInRollout)
+
+	response, err := call(SystemTypeAuctions, "tester")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", response)
+
+	// Server to server calls and systems without a rollout aren't restricted
+	_, err = call(SystemTypeAuctions, "")
+	assert.NoError(t, err)
+	_, err = call(SystemTypeEconomy, "user1")
+	assert.NoError(t, err)
+}
@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"time"
 
 	"github.com/heroiclabs/nakama-common/runtime"
@@ -18,7 +17,7 @@ func rpcAchievementsClaim_Json(p *pamlogixImpl) func(ctx context.Context, logger
 		}
 
 		request := &AchievementsClaimRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AchievementsClaimRequestJson: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -49,7 +48,7 @@ func rpcAchievementsClaim_Json(p *pamlogixImpl) func(ctx context.Context, logger
 			RepeatAchievements: repeatAchievements,
 		}
 
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -87,7 +86,10 @@ func rpcAchievementsGet_Json(p *pamlogixImpl) func(ctx context.Context, logger r
 		}
 
 		// Encode the response using JSON
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, &AchievementListDetails{
+			AchievementList: response,
+			ItemDetails:     achievementListItemDetails(ctx, logger, nk, p, userID, response),
+		})
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -106,7 +108,7 @@ func rpcAchievementsUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logge
 
 		// Parse the input request using JSON
 		request := &AchievementsUpdateRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AchievementsUpdateRequestJson: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -147,7 +149,7 @@ func rpcAchievementsUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logge
 		}
 
 		// Encode the response using JSON
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -175,7 +177,7 @@ func rpcAchievementsList_Json(p *pamlogixImpl) func(ctx context.Context, logger
 
 		// Try to parse optional filters, but continue even if parsing fails
 		if payload != "" {
-			if err := json.Unmarshal([]byte(payload), &request); err != nil {
+			if err := unmarshalRpcJson(p, payload, &request); err != nil {
 				logger.Warn("Failed to unmarshal AchievementsListRequestJson, proceeding without filters: %v", err)
 				// We don't return an error here, just proceed without filters
 			}
@@ -222,7 +224,10 @@ func rpcAchievementsList_Json(p *pamlogixImpl) func(ctx context.Context, logger
 		}
 
 		// Encode the response using JSON
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, &AchievementListDetails{
+			AchievementList: response,
+			ItemDetails:     achievementListItemDetails(ctx, logger, nk, p, userID, response),
+		})
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -246,7 +251,7 @@ func rpcAchievementsProgress_Json(p *pamlogixImpl) func(ctx context.Context, log
 			Progress      int64  `json:"progress"`
 			Absolute      bool   `json:"absolute,omitempty"` // If true, set progress to exact value rather than increment
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal AchievementsProgressRequestJson: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -316,7 +321,7 @@ func rpcAchievementsProgress_Json(p *pamlogixImpl) func(ctx context.Context, log
 					IsUpdated:   false,
 				}
 
-				responseData, err := json.Marshal(response)
+				responseData, err := marshalRpcJson(p, response)
 				if err != nil {
 					logger.Error("Failed to marshal response: %v", err)
 					return "", ErrPayloadEncode
@@ -385,7 +390,7 @@ func rpcAchievementsProgress_Json(p *pamlogixImpl) func(ctx context.Context, log
 		}
 
 		// Encode the response using JSON
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -407,7 +412,7 @@ func rpcAchievementDetails_Json(p *pamlogixImpl) func(ctx context.Context, logge
 		var request struct {
 			AchievementId string `json:"achievement_id"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal AchievementDetailsRequestJson: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -505,7 +510,7 @@ func rpcAchievementDetails_Json(p *pamlogixImpl) func(ctx context.Context, logge
 		}
 
 		// Encode the response using JSON
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -28,7 +28,7 @@ func rpcAuctionsGetTemplates(p *pamlogixImpl) func(ctx context.Context, logger r
 		}
 
 		marshaler := &protojson.MarshalOptions{}
-		responseData, err := marshaler.Marshal(templates)
+		responseData, err := marshaler.Marshal(templates.AuctionTemplates)
 		if err != nil {
 			logger.Error("Failed to marshal auction templates response: %v", err)
 			return "", ErrPayloadEncode
@@ -58,10 +58,9 @@ func rpcAuctionsList(p *pamlogixImpl) func(ctx context.Context, logger runtime.L
 			return "", ErrNoSessionUser
 		}
 
-		// Set default limit if not provided
-		limit := int(request.GetLimit())
-		if limit <= 0 {
-			limit = 20
+		limit, err := pageLimit(int(request.GetLimit()), defaultPageLimit, maxPageLimit)
+		if err != nil {
+			return "", err
 		}
 
 		auctionList, err := auctionsSystem.List(ctx, logger, nk, userID, request.GetQuery(), request.GetSort(), limit, request.GetCursor())
@@ -70,6 +69,8 @@ func rpcAuctionsList(p *pamlogixImpl) func(ctx context.Context, logger runtime.L
 			return "", err
 		}
 
+		enrichAuctionBidders(ctx, logger, nk, auctionsSystem, auctionList, request.GetIncludeBidders())
+
 		marshaler := &protojson.MarshalOptions{}
 		responseData, err := marshaler.Marshal(auctionList)
 		if err != nil {
@@ -293,10 +294,9 @@ func rpcAuctionsListBids(p *pamlogixImpl) func(ctx context.Context, logger runti
 			return "", ErrNoSessionUser
 		}
 
-		// Set default limit if not provided
-		limit := int(request.GetLimit())
-		if limit <= 0 {
-			limit = 20
+		limit, err := pageLimit(int(request.GetLimit()), defaultPageLimit, maxPageLimit)
+		if err != nil {
+			return "", err
 		}
 
 		auctionList, err := auctionsSystem.ListBids(ctx, logger, nk, userID, limit, request.GetCursor())
@@ -305,6 +305,8 @@ func rpcAuctionsListBids(p *pamlogixImpl) func(ctx context.Context, logger runti
 			return "", err
 		}
 
+		enrichAuctionBidders(ctx, logger, nk, auctionsSystem, auctionList, request.GetIncludeBidders())
+
 		marshaler := &protojson.MarshalOptions{}
 		responseData, err := marshaler.Marshal(auctionList)
 		if err != nil {
@@ -336,10 +338,9 @@ func rpcAuctionsListCreated(p *pamlogixImpl) func(ctx context.Context, logger ru
 			return "", ErrNoSessionUser
 		}
 
-		// Set default limit if not provided
-		limit := int(request.GetLimit())
-		if limit <= 0 {
-			limit = 20
+		limit, err := pageLimit(int(request.GetLimit()), defaultPageLimit, maxPageLimit)
+		if err != nil {
+			return "", err
 		}
 
 		auctionList, err := auctionsSystem.ListCreated(ctx, logger, nk, userID, limit, request.GetCursor())
@@ -348,6 +349,8 @@ func rpcAuctionsListCreated(p *pamlogixImpl) func(ctx context.Context, logger ru
 			return "", err
 		}
 
+		enrichAuctionBidders(ctx, logger, nk, auctionsSystem, auctionList, request.GetIncludeBidders())
+
 		marshaler := &protojson.MarshalOptions{}
 		responseData, err := marshaler.Marshal(auctionList)
 		if err != nil {
@@ -390,6 +393,8 @@ func rpcAuctionsFollow(p *pamlogixImpl) func(ctx context.Context, logger runtime
 			return "", err
 		}
 
+		enrichAuctionBidders(ctx, logger, nk, auctionsSystem, auctionList, request.GetIncludeBidders())
+
 		marshaler := &protojson.MarshalOptions{}
 		responseData, err := marshaler.Marshal(auctionList)
 		if err != nil {
@@ -3,12 +3,11 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
-// rpcAuctionsGetTemplates_Json handles the get templates RPC with JSON
+// rpcAuctionsGetTemplates_Json handles the get templates RPC with JSON, returning one page of template summaries
 func rpcAuctionsGetTemplates_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 		auctionsSystem := p.GetAuctionsSystem()
@@ -16,18 +15,27 @@ func rpcAuctionsGetTemplates_Json(p *pamlogixImpl) func(ctx context.Context, log
 			return "", runtime.NewError("auctions system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
 		}
 
+		// The request is optional, and without one the first page of every category is listed
+		request := &AuctionTemplatesRequest{}
+		if payload != "" {
+			if err := unmarshalRpcJson(p, payload, request); err != nil {
+				logger.Error("Failed to unmarshal AuctionTemplatesRequest: %v", err)
+				return "", ErrPayloadDecode
+			}
+		}
+
 		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
 		if !ok || userID == "" {
 			return "", ErrNoSessionUser
 		}
 
-		templates, err := auctionsSystem.GetTemplates(ctx, logger, nk, userID)
+		templates, err := auctionsSystem.ListTemplates(ctx, logger, nk, userID, request.Category, request.Limit, request.Cursor)
 		if err != nil {
 			logger.Error("Error getting auction templates: %v", err)
 			return "", err
 		}
 
-		responseData, err := json.Marshal(templates)
+		responseData, err := marshalRpcJson(p, templates)
 		if err != nil {
 			logger.Error("Failed to marshal auction templates response: %v", err)
 			return "", ErrPayloadEncode
@@ -37,6 +45,42 @@ func rpcAuctionsGetTemplates_Json(p *pamlogixImpl) func(ctx context.Context, log
 	}
 }
 
+// rpcAuctionsGetTemplate_Json handles the get template RPC with JSON, returning one template in full
+func rpcAuctionsGetTemplate_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		auctionsSystem := p.GetAuctionsSystem()
+		if auctionsSystem == nil {
+			return "", runtime.NewError("auctions system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		request := &AuctionTemplateGetRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal AuctionTemplateGetRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+		logger = withEntityLogger(logger, request.Id)
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		template, err := auctionsSystem.GetTemplate(ctx, logger, nk, userID, request.Id)
+		if err != nil {
+			logger.Error("Error getting auction template: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, template)
+		if err != nil {
+			logger.Error("Failed to marshal auction template response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
 // rpcAuctionsList_Json handles the list auctions RPC with JSON
 func rpcAuctionsList_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
@@ -46,7 +90,7 @@ func rpcAuctionsList_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 		}
 
 		request := &AuctionListRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AuctionListRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -56,10 +100,9 @@ func rpcAuctionsList_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 			return "", ErrNoSessionUser
 		}
 
-		// Set default limit if not provided
-		limit := int(request.GetLimit())
-		if limit <= 0 {
-			limit = 20
+		limit, err := pageLimit(int(request.GetLimit()), defaultPageLimit, maxPageLimit)
+		if err != nil {
+			return "", err
 		}
 
 		auctionList, err := auctionsSystem.List(ctx, logger, nk, userID, request.GetQuery(), request.GetSort(), limit, request.GetCursor())
@@ -68,7 +111,9 @@ func rpcAuctionsList_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 			return "", err
 		}
 
-		responseData, err := json.Marshal(auctionList)
+		enrichAuctionBidders(ctx, logger, nk, auctionsSystem, auctionList, request.GetIncludeBidders())
+
+		responseData, err := marshalRpcJson(p, auctionList)
 		if err != nil {
 			logger.Error("Failed to marshal auction list response: %v", err)
 			return "", ErrPayloadEncode
@@ -87,10 +132,11 @@ func rpcAuctionsBid_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 		}
 
 		request := &AuctionBidRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AuctionBidRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
+		logger = withEntityLogger(logger, request.GetId())
 
 		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
 		if !ok || userID == "" {
@@ -109,7 +155,7 @@ func rpcAuctionsBid_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 			return "", err
 		}
 
-		responseData, err := json.Marshal(auction)
+		responseData, err := marshalRpcJson(p, auction)
 		if err != nil {
 			logger.Error("Failed to marshal auction bid response: %v", err)
 			return "", ErrPayloadEncode
@@ -119,6 +165,129 @@ func rpcAuctionsBid_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 	}
 }
 
+// rpcAuctionsBuyout_Json handles the buyout RPC with JSON
+func rpcAuctionsBuyout_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		auctionsSystem := p.GetAuctionsSystem()
+		if auctionsSystem == nil {
+			return "", runtime.NewError("auctions system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		request := &AuctionBuyoutRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal AuctionBuyoutRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+		logger = withEntityLogger(logger, request.Id)
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		sessionID, ok := ctx.Value(runtime.RUNTIME_CTX_SESSION_ID).(string)
+		if !ok || sessionID == "" {
+			return "", ErrNoSessionID
+		}
+
+		auction, err := auctionsSystem.Buyout(ctx, logger, nk, userID, sessionID, request.Id, request.Version)
+		if err != nil {
+			logger.Error("Error buying out auction: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, auction)
+		if err != nil {
+			logger.Error("Failed to marshal auction buyout response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcAuctionsTeamBid_Json handles the team bid RPC with JSON
+func rpcAuctionsTeamBid_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		auctionsSystem := p.GetAuctionsSystem()
+		if auctionsSystem == nil {
+			return "", runtime.NewError("auctions system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		request := &AuctionTeamBidRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal AuctionTeamBidRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+		logger = withEntityLogger(logger, request.Id)
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		sessionID, ok := ctx.Value(runtime.RUNTIME_CTX_SESSION_ID).(string)
+		if !ok || sessionID == "" {
+			return "", ErrNoSessionID
+		}
+
+		auction, err := auctionsSystem.TeamBid(ctx, logger, nk, userID, sessionID, request.TeamId, request.Id, request.Version, request.Bid)
+		if err != nil {
+			logger.Error("Error placing team bid on auction: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, auction)
+		if err != nil {
+			logger.Error("Failed to marshal auction team bid response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcAuctionsRetractBid_Json handles the retract bid RPC with JSON
+func rpcAuctionsRetractBid_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		auctionsSystem := p.GetAuctionsSystem()
+		if auctionsSystem == nil {
+			return "", runtime.NewError("auctions system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		request := &AuctionRetractBidRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal AuctionRetractBidRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+		logger = withEntityLogger(logger, request.Id)
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		sessionID, ok := ctx.Value(runtime.RUNTIME_CTX_SESSION_ID).(string)
+		if !ok || sessionID == "" {
+			return "", ErrNoSessionID
+		}
+
+		auction, err := auctionsSystem.RetractBid(ctx, logger, nk, userID, sessionID, request.Id, request.Version)
+		if err != nil {
+			logger.Error("Error retracting auction bid: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, auction)
+		if err != nil {
+			logger.Error("Failed to marshal auction retract bid response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
 // rpcAuctionsClaimBid_Json handles the claim bid RPC with JSON
 func rpcAuctionsClaimBid_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
@@ -128,10 +297,11 @@ func rpcAuctionsClaimBid_Json(p *pamlogixImpl) func(ctx context.Context, logger
 		}
 
 		request := &AuctionClaimBidRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AuctionClaimBidRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
+		logger = withEntityLogger(logger, request.GetId())
 
 		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
 		if !ok || userID == "" {
@@ -144,7 +314,7 @@ func rpcAuctionsClaimBid_Json(p *pamlogixImpl) func(ctx context.Context, logger
 			return "", err
 		}
 
-		responseData, err := json.Marshal(claimResult)
+		responseData, err := marshalRpcJson(p, claimResult)
 		if err != nil {
 			logger.Error("Failed to marshal auction claim bid response: %v", err)
 			return "", ErrPayloadEncode
@@ -163,10 +333,11 @@ func rpcAuctionsClaimCreated_Json(p *pamlogixImpl) func(ctx context.Context, log
 		}
 
 		request := &AuctionClaimCreatedRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AuctionClaimCreatedRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
+		logger = withEntityLogger(logger, request.GetId())
 
 		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
 		if !ok || userID == "" {
@@ -179,7 +350,7 @@ func rpcAuctionsClaimCreated_Json(p *pamlogixImpl) func(ctx context.Context, log
 			return "", err
 		}
 
-		responseData, err := json.Marshal(claimResult)
+		responseData, err := marshalRpcJson(p, claimResult)
 		if err != nil {
 			logger.Error("Failed to marshal auction claim created response: %v", err)
 			return "", ErrPayloadEncode
@@ -198,10 +369,11 @@ func rpcAuctionsCancel_Json(p *pamlogixImpl) func(ctx context.Context, logger ru
 		}
 
 		request := &AuctionCancelRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AuctionCancelRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
+		logger = withEntityLogger(logger, request.GetId())
 
 		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
 		if !ok || userID == "" {
@@ -214,7 +386,7 @@ func rpcAuctionsCancel_Json(p *pamlogixImpl) func(ctx context.Context, logger ru
 			return "", err
 		}
 
-		responseData, err := json.Marshal(cancelResult)
+		responseData, err := marshalRpcJson(p, cancelResult)
 		if err != nil {
 			logger.Error("Failed to marshal auction cancel response: %v", err)
 			return "", ErrPayloadEncode
@@ -233,7 +405,7 @@ func rpcAuctionsCreate_Json(p *pamlogixImpl) func(ctx context.Context, logger ru
 		}
 
 		request := &AuctionCreateRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AuctionCreateRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -251,7 +423,7 @@ func rpcAuctionsCreate_Json(p *pamlogixImpl) func(ctx context.Context, logger ru
 			return "", err
 		}
 
-		responseData, err := json.Marshal(auction)
+		responseData, err := marshalRpcJson(p, auction)
 		if err != nil {
 			logger.Error("Failed to marshal auction create response: %v", err)
 			return "", ErrPayloadEncode
@@ -270,7 +442,7 @@ func rpcAuctionsListBids_Json(p *pamlogixImpl) func(ctx context.Context, logger
 		}
 
 		request := &AuctionListBidsRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AuctionListBidsRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -280,10 +452,9 @@ func rpcAuctionsListBids_Json(p *pamlogixImpl) func(ctx context.Context, logger
 			return "", ErrNoSessionUser
 		}
 
-		// Set default limit if not provided
-		limit := int(request.GetLimit())
-		if limit <= 0 {
-			limit = 20
+		limit, err := pageLimit(int(request.GetLimit()), defaultPageLimit, maxPageLimit)
+		if err != nil {
+			return "", err
 		}
 
 		auctionList, err := auctionsSystem.ListBids(ctx, logger, nk, userID, limit, request.GetCursor())
@@ -292,7 +463,9 @@ func rpcAuctionsListBids_Json(p *pamlogixImpl) func(ctx context.Context, logger
 			return "", err
 		}
 
-		responseData, err := json.Marshal(auctionList)
+		enrichAuctionBidders(ctx, logger, nk, auctionsSystem, auctionList, request.GetIncludeBidders())
+
+		responseData, err := marshalRpcJson(p, auctionList)
 		if err != nil {
 			logger.Error("Failed to marshal auction list bids response: %v", err)
 			return "", ErrPayloadEncode
@@ -311,7 +484,7 @@ func rpcAuctionsListCreated_Json(p *pamlogixImpl) func(ctx context.Context, logg
 		}
 
 		request := &AuctionListCreatedRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AuctionListCreatedRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -321,10 +494,9 @@ func rpcAuctionsListCreated_Json(p *pamlogixImpl) func(ctx context.Context, logg
 			return "", ErrNoSessionUser
 		}
 
-		// Set default limit if not provided
-		limit := int(request.GetLimit())
-		if limit <= 0 {
-			limit = 20
+		limit, err := pageLimit(int(request.GetLimit()), defaultPageLimit, maxPageLimit)
+		if err != nil {
+			return "", err
 		}
 
 		auctionList, err := auctionsSystem.ListCreated(ctx, logger, nk, userID, limit, request.GetCursor())
@@ -333,7 +505,9 @@ func rpcAuctionsListCreated_Json(p *pamlogixImpl) func(ctx context.Context, logg
 			return "", err
 		}
 
-		responseData, err := json.Marshal(auctionList)
+		enrichAuctionBidders(ctx, logger, nk, auctionsSystem, auctionList, request.GetIncludeBidders())
+
+		responseData, err := marshalRpcJson(p, auctionList)
 		if err != nil {
 			logger.Error("Failed to marshal auction list created response: %v", err)
 			return "", ErrPayloadEncode
@@ -343,6 +517,86 @@ func rpcAuctionsListCreated_Json(p *pamlogixImpl) func(ctx context.Context, logg
 	}
 }
 
+// rpcAuctionsListHistory_Json handles the list archived auction history RPC with JSON
+func rpcAuctionsListHistory_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		auctionsSystem := p.GetAuctionsSystem()
+		if auctionsSystem == nil {
+			return "", runtime.NewError("auctions system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		request := &AuctionListHistoryRequest{}
+		if payload != "" {
+			if err := unmarshalRpcJson(p, payload, request); err != nil {
+				logger.Error("Failed to unmarshal AuctionListHistoryRequest: %v", err)
+				return "", ErrPayloadDecode
+			}
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		limit, err := pageLimit(int(request.Limit), defaultPageLimit, maxPageLimit)
+		if err != nil {
+			return "", err
+		}
+
+		auctionList, err := auctionsSystem.ListHistory(ctx, logger, nk, userID, limit, request.Cursor)
+		if err != nil {
+			logger.Error("Error listing user auction history: %v", err)
+			return "", err
+		}
+
+		enrichAuctionBidders(ctx, logger, nk, auctionsSystem, auctionList, request.IncludeBidders)
+
+		responseData, err := marshalRpcJson(p, auctionList)
+		if err != nil {
+			logger.Error("Failed to marshal auction history response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcAuctionsPriceHistory_Json handles the item price history RPC with JSON
+func rpcAuctionsPriceHistory_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		auctionsSystem := p.GetAuctionsSystem()
+		if auctionsSystem == nil {
+			return "", runtime.NewError("auctions system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		request := &AuctionPriceHistoryRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal AuctionPriceHistoryRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+		logger = withEntityLogger(logger, request.ItemId)
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		priceHistory, err := auctionsSystem.GetPriceHistory(ctx, logger, nk, request.ItemId, request.WindowsSec)
+		if err != nil {
+			logger.Error("Error getting auction price history: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, priceHistory)
+		if err != nil {
+			logger.Error("Failed to marshal auction price history response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
 // rpcAuctionsFollow_Json handles the follow auctions RPC (for real-time updates) with JSON
 func rpcAuctionsFollow_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
@@ -352,7 +606,7 @@ func rpcAuctionsFollow_Json(p *pamlogixImpl) func(ctx context.Context, logger ru
 		}
 
 		request := &AuctionsFollowRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal AuctionsFollowRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -373,7 +627,9 @@ func rpcAuctionsFollow_Json(p *pamlogixImpl) func(ctx context.Context, logger ru
 			return "", err
 		}
 
-		responseData, err := json.Marshal(auctionList)
+		enrichAuctionBidders(ctx, logger, nk, auctionsSystem, auctionList, request.GetIncludeBidders())
+
+		responseData, err := marshalRpcJson(p, auctionList)
 		if err != nil {
 			logger.Error("Failed to marshal auction follow response: %v", err)
 			return "", ErrPayloadEncode
@@ -382,3 +638,61 @@ func rpcAuctionsFollow_Json(p *pamlogixImpl) func(ctx context.Context, logger ru
 		return string(responseData), nil
 	}
 }
+
+// rpcAuctionsClaimAllBids_Json handles the claim all won auctions RPC with JSON
+func rpcAuctionsClaimAllBids_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		auctionsSystem := p.GetAuctionsSystem()
+		if auctionsSystem == nil {
+			return "", runtime.NewError("auctions system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		claimResult, err := auctionsSystem.ClaimAllBids(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error claiming all auction bids: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, claimResult)
+		if err != nil {
+			logger.Error("Failed to marshal auction claim all bids response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcAuctionsClaimAllCreated_Json handles the claim all created auctions RPC with JSON
+func rpcAuctionsClaimAllCreated_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		auctionsSystem := p.GetAuctionsSystem()
+		if auctionsSystem == nil {
+			return "", runtime.NewError("auctions system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		claimResult, err := auctionsSystem.ClaimAllCreated(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error claiming all created auctions: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, claimResult)
+		if err != nil {
+			logger.Error("Failed to marshal auction claim all created response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
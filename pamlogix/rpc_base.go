@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -21,7 +20,7 @@ func rpcBaseRateApp(p *pamlogixImpl) func(ctx context.Context, logger runtime.Lo
 			Score   uint32 `json:"score"`
 			Message string `json:"message,omitempty"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal RateAppRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -54,7 +53,7 @@ func rpcBaseRateApp(p *pamlogixImpl) func(ctx context.Context, logger runtime.Lo
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -79,7 +78,7 @@ func rpcBaseSetDevicePrefs(p *pamlogixImpl) func(ctx context.Context, logger run
 			PushTokenIos     string          `json:"push_token_ios,omitempty"`
 			Preferences      map[string]bool `json:"preferences,omitempty"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal SetDevicePrefsRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -106,7 +105,7 @@ func rpcBaseSetDevicePrefs(p *pamlogixImpl) func(ctx context.Context, logger run
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -126,7 +125,7 @@ func rpcBaseSync(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logge
 
 		// Parse the input request
 		var request SyncRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal SyncRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -145,8 +144,252 @@ func rpcBaseSync(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logge
 			return "", err
 		}
 
+		// Encode the response along with the feature flags so clients can hide disabled features
+		responseData, err := marshalRpcJson(p, &BaseSyncResponse{
+			SyncResponse: resp,
+			FeatureFlags: baseSystem.FeatureFlags(),
+		})
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcBaseNotificationPreferencesGet handles the RPC to get the user's notification preferences
+func rpcBaseNotificationPreferencesGet(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		baseSystem := p.GetBaseSystem()
+		if baseSystem == nil {
+			return "", ErrSystemNotFound
+		}
+
+		// Extract user ID from session
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		prefs, err := baseSystem.GetNotificationPreferences(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error getting notification preferences: %v", err)
+			return "", err
+		}
+
 		// Encode the response
-		responseData, err := json.Marshal(resp)
+		responseData, err := marshalRpcJson(p, prefs)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcBaseNotificationPreferencesSet handles the RPC to replace the user's notification preferences
+func rpcBaseNotificationPreferencesSet(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		baseSystem := p.GetBaseSystem()
+		if baseSystem == nil {
+			return "", ErrSystemNotFound
+		}
+
+		// Parse the input request
+		var request NotificationPreferences
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
+			logger.Error("Failed to unmarshal NotificationPreferences: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		// Extract user ID from session
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		prefs, err := baseSystem.SetNotificationPreferences(ctx, logger, nk, userID, &request)
+		if err != nil {
+			logger.Error("Error setting notification preferences: %v", err)
+			return "", err
+		}
+
+		// Encode the response
+		responseData, err := marshalRpcJson(p, prefs)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcBaseAccountLock locks a user's economy pending review. Like the other account lock RPCs it is only available to
+// server to server calls, made with the runtime HTTP key rather than a user session.
+func rpcBaseAccountLock(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		baseSystem := p.GetBaseSystem()
+		if baseSystem == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrAccountLockServerOnly
+		}
+
+		var request AccountLockRequest
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
+			logger.Error("Failed to unmarshal AccountLockRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+		if request.UserId == "" {
+			return "", ErrBadInput
+		}
+
+		lock, err := baseSystem.LockAccount(ctx, logger, nk, request.UserId, request.Reason, "admin")
+		if err != nil {
+			logger.Error("Error locking account: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, lock)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcBaseAccountUnlock lifts a user's economy lock.
+func rpcBaseAccountUnlock(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		baseSystem := p.GetBaseSystem()
+		if baseSystem == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrAccountLockServerOnly
+		}
+
+		var request AccountUnlockRequest
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
+			logger.Error("Failed to unmarshal AccountUnlockRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+		if request.UserId == "" {
+			return "", ErrBadInput
+		}
+
+		if err := baseSystem.UnlockAccount(ctx, logger, nk, request.UserId); err != nil {
+			logger.Error("Error unlocking account: %v", err)
+			return "", err
+		}
+
+		return "{}", nil
+	}
+}
+
+// rpcBaseAccountPurge deletes a locked user's account.
+func rpcBaseAccountPurge(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		baseSystem := p.GetBaseSystem()
+		if baseSystem == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrAccountLockServerOnly
+		}
+
+		var request AccountUnlockRequest
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
+			logger.Error("Failed to unmarshal AccountUnlockRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+		if request.UserId == "" {
+			return "", ErrBadInput
+		}
+
+		if err := baseSystem.PurgeAccount(ctx, logger, nk, request.UserId); err != nil {
+			logger.Error("Error purging account: %v", err)
+			return "", err
+		}
+
+		return "{}", nil
+	}
+}
+
+// rpcBaseStorageSweep handles the server to server RPC deleting expired storage objects of every system, for servers
+// which sweep on an external schedule rather than StorageSweepIntervalSec.
+func rpcBaseStorageSweep(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrSessionUser
+		}
+
+		result, err := p.sweepStorage(ctx, logger, nk)
+		if err != nil {
+			logger.Error("Error sweeping expired storage: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, result)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcBaseContentCalendarGet handles the RPC listing the upcoming and running entries of the content calendar, for
+// "coming soon" screens.
+func rpcBaseContentCalendarGet(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		baseSystem := p.GetBaseSystem()
+		if baseSystem == nil {
+			return "", ErrSystemNotFound
+		}
+
+		responseData, err := marshalRpcJson(p, baseSystem.GetContentCalendar())
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcBaseDataExport compiles the caller's pamlogix data into an export they can download, for data access requests.
+func rpcBaseDataExport(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		baseSystem := p.GetBaseSystem()
+		if baseSystem == nil {
+			return "", ErrSystemNotFound
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		receipt, err := baseSystem.ExportData(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error exporting data: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, receipt)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -62,14 +62,14 @@ func rpcEconomyDonationGive(p *pamlogixImpl) func(ctx context.Context, logger ru
 		}
 
 		// Extract user ID from session
-		fromUserID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
-		if !ok || fromUserID == "" {
+		contributorID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || contributorID == "" {
 			logger.Error("No user ID in context")
 			return "", ErrNoSessionUser
 		}
 
 		// Call the economy system to give a donation
-		_, updatedWallet, updatedInventory, rewardModifiers, contributorReward, timestamp, err := p.GetEconomySystem().DonationGive(ctx, logger, nk, request.UserId, request.DonationId, fromUserID)
+		_, updatedWallet, updatedInventory, rewardModifiers, contributorReward, timestamp, err := p.GetEconomySystem().DonationGive(ctx, logger, nk, request.UserId, request.DonationId, contributorID)
 		if err != nil {
 			logger.Error("Error giving donation: %v", err)
 			return "", err
@@ -213,6 +213,7 @@ func rpcEconomyStoreGet(p *pamlogixImpl) func(ctx context.Context, logger runtim
 				Description:          item.Description,
 				Category:             item.Category,
 				Cost:                 cost,
+				AvailableRewards:     p.GetEconomySystem().RewardConvertReverse(item.Reward),
 				AdditionalProperties: item.AdditionalProperties,
 				Unavailable:          item.Unavailable,
 			})
@@ -266,7 +267,7 @@ func rpcEconomyGrant(p *pamlogixImpl) func(ctx context.Context, logger runtime.L
 		}
 
 		// Call the economy system to grant currencies and reward modifiers
-		updatedWallet, rewardModifiers, timestamp, err := p.GetEconomySystem().Grant(ctx, logger, nk, userID, request.Currencies, request.Items, request.RewardModifiers, nil)
+		updatedWallet, rewardModifiers, timestamp, err := p.GetEconomySystem().Grant(ctx, logger, nk, userID, request.Currencies, request.Items, nil, request.RewardModifiers, nil)
 		if err != nil {
 			logger.Error("Error granting economy items: %v", err)
 			return "", err
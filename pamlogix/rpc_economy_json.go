@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"time"
 
 	"github.com/heroiclabs/nakama-common/runtime"
@@ -18,7 +17,7 @@ func rpcEconomyDonationClaim_Json(p *pamlogixImpl) func(ctx context.Context, log
 
 		// Parse the input request
 		request := &EconomyDonationClaimRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyDonationClaimRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -38,7 +37,7 @@ func rpcEconomyDonationClaim_Json(p *pamlogixImpl) func(ctx context.Context, log
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(donationsList)
+		responseData, err := marshalRpcJson(p, donationsList)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -56,20 +55,20 @@ func rpcEconomyDonationGive_Json(p *pamlogixImpl) func(ctx context.Context, logg
 
 		// Parse the input request
 		request := &EconomyDonationGiveRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyDonationGiveRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
 
 		// Extract user ID from session
-		fromUserID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
-		if !ok || fromUserID == "" {
+		contributorID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || contributorID == "" {
 			logger.Error("No user ID in context")
 			return "", ErrNoSessionUser
 		}
 
 		// Call the economy system to give a donation
-		_, updatedWallet, updatedInventory, rewardModifiers, contributorReward, timestamp, err := p.GetEconomySystem().DonationGive(ctx, logger, nk, request.UserId, request.DonationId, fromUserID)
+		_, updatedWallet, updatedInventory, rewardModifiers, contributorReward, timestamp, err := p.GetEconomySystem().DonationGive(ctx, logger, nk, request.UserId, request.DonationId, contributorID)
 		if err != nil {
 			logger.Error("Error giving donation: %v", err)
 			return "", err
@@ -85,7 +84,7 @@ func rpcEconomyDonationGive_Json(p *pamlogixImpl) func(ctx context.Context, logg
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -103,7 +102,7 @@ func rpcEconomyDonationGet_Json(p *pamlogixImpl) func(ctx context.Context, logge
 
 		// Parse the input request
 		request := &EconomyDonationGetRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyDonationGetRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -116,7 +115,7 @@ func rpcEconomyDonationGet_Json(p *pamlogixImpl) func(ctx context.Context, logge
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(donationsList)
+		responseData, err := marshalRpcJson(p, donationsList)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -134,7 +133,7 @@ func rpcEconomyDonationRequest_Json(p *pamlogixImpl) func(ctx context.Context, l
 
 		// Parse the input request
 		request := &EconomyDonationRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyDonationRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -160,7 +159,7 @@ func rpcEconomyDonationRequest_Json(p *pamlogixImpl) func(ctx context.Context, l
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -178,7 +177,7 @@ func rpcEconomyStoreGet_Json(p *pamlogixImpl) func(ctx context.Context, logger r
 
 		// Parse the input request
 		request := &EconomyListRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyListRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -213,6 +212,7 @@ func rpcEconomyStoreGet_Json(p *pamlogixImpl) func(ctx context.Context, logger r
 				Description:          item.Description,
 				Category:             item.Category,
 				Cost:                 cost,
+				AvailableRewards:     p.GetEconomySystem().RewardConvertReverse(item.Reward),
 				AdditionalProperties: item.AdditionalProperties,
 				Unavailable:          item.Unavailable,
 			})
@@ -234,8 +234,15 @@ func rpcEconomyStoreGet_Json(p *pamlogixImpl) func(ctx context.Context, logger r
 			CurrentTimeSec:        timestamp,
 		}
 
+		timed := newTimedEconomyList(response)
+		availableRewards := make([]*AvailableRewards, 0, len(storeItemsSlice))
+		for _, item := range storeItemsSlice {
+			availableRewards = append(availableRewards, item.AvailableRewards)
+		}
+		timed.ItemDetails = rewardItemDetails(ctx, logger, nk, p, userID, availableRewards...)
+
 		// Encode the response
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, timed)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -252,9 +259,9 @@ func rpcEconomyGrant_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 		}
 
 		// Parse the input request
-		request := &EconomyGrantRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
-			logger.Error("Failed to unmarshal EconomyGrantRequest: %v", err)
+		request := &EconomyGrantInstancesRequest{EconomyGrantRequest: &EconomyGrantRequest{}}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomyGrantInstancesRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
 
@@ -266,7 +273,11 @@ func rpcEconomyGrant_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 		}
 
 		// Call the economy system to grant currencies and reward modifiers
-		updatedWallet, rewardModifiers, timestamp, err := p.GetEconomySystem().Grant(ctx, logger, nk, userID, request.Currencies, request.Items, request.RewardModifiers, nil)
+		grant := p.GetEconomySystem().Grant
+		if request.DryRun {
+			grant = p.GetEconomySystem().GrantDryRun
+		}
+		updatedWallet, rewardModifiers, timestamp, err := grant(ctx, logger, nk, userID, request.Currencies, request.Items, request.ItemInstances, request.RewardModifiers, nil)
 		if err != nil {
 			logger.Error("Error granting economy items: %v", err)
 			return "", err
@@ -280,7 +291,7 @@ func rpcEconomyGrant_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -298,7 +309,7 @@ func rpcEconomyPurchaseIntent_Json(p *pamlogixImpl) func(ctx context.Context, lo
 
 		// Parse the input request
 		request := &EconomyPurchaseIntentRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyPurchaseIntentRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -323,6 +334,95 @@ func rpcEconomyPurchaseIntent_Json(p *pamlogixImpl) func(ctx context.Context, lo
 	}
 }
 
+func rpcEconomyPurchaseIntentCancel_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		request := &EconomyPurchaseIntentCancelRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomyPurchaseIntentCancelRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		if err := p.GetEconomySystem().PurchaseIntentCancel(ctx, logger, nk, userID, request.ItemId); err != nil {
+			logger.Error("Error cancelling purchase intent: %v", err)
+			return "", err
+		}
+
+		return "", nil
+	}
+}
+
+func rpcEconomyCanAfford_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		request := &EconomyCanAffordRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomyCanAffordRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		missing, err := p.GetEconomySystem().CanAfford(ctx, logger, nk, userID, request.Cost)
+		if err != nil {
+			logger.Error("Error checking affordability: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, &EconomyCanAfford{CanAfford: missing == nil, Missing: missing})
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomyCurrencies_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		currencies, err := p.GetEconomySystem().ListCurrencies(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error listing currencies: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, &EconomyCurrencies{Currencies: currencies})
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
 func rpcEconomyPurchaseItem_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 		if p.GetEconomySystem() == nil {
@@ -331,7 +431,7 @@ func rpcEconomyPurchaseItem_Json(p *pamlogixImpl) func(ctx context.Context, logg
 
 		// Parse the input request
 		request := &EconomyPurchaseRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyPurchaseRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -358,7 +458,50 @@ func rpcEconomyPurchaseItem_Json(p *pamlogixImpl) func(ctx context.Context, logg
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomyTeamPurchase_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		// Parse the input request
+		request := &EconomyTeamPurchaseRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomyTeamPurchaseRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		// Extract user ID from session
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		updatedWallet, updatedInventory, reward, err := p.GetEconomySystem().TeamPurchaseItem(ctx, logger, nk, userID, request.TeamId, request.ItemId)
+		if err != nil {
+			logger.Error("Error processing team purchase: %v", err)
+			return "", err
+		}
+
+		response := &EconomyPurchaseAck{
+			Wallet:    updatedWallet,
+			Inventory: updatedInventory,
+			Reward:    reward,
+		}
+
+		// Encode the response
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -376,7 +519,7 @@ func rpcEconomyPurchaseRestore_Json(p *pamlogixImpl) func(ctx context.Context, l
 
 		// Parse the input request
 		request := &EconomyPurchaseRestoreRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyPurchaseRestoreRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -409,7 +552,7 @@ func rpcEconomyPlacementStatus_Json(p *pamlogixImpl) func(ctx context.Context, l
 
 		// Parse the input request
 		request := &EconomyPlacementStatusRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyPlacementStatusRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -429,7 +572,35 @@ func rpcEconomyPlacementStatus_Json(p *pamlogixImpl) func(ctx context.Context, l
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(status)
+		responseData, err := marshalRpcJson(p, status)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomyPlacementList_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		placements, err := p.GetEconomySystem().ListPlacements(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error listing placements: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, placements)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -447,7 +618,7 @@ func rpcEconomyPlacementStart_Json(p *pamlogixImpl) func(ctx context.Context, lo
 
 		// Parse the input request
 		request := &EconomyPlacementStartRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyPlacementStartRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -467,7 +638,7 @@ func rpcEconomyPlacementStart_Json(p *pamlogixImpl) func(ctx context.Context, lo
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(status)
+		responseData, err := marshalRpcJson(p, status)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -485,7 +656,7 @@ func rpcEconomyPlacementSuccess_Json(p *pamlogixImpl) func(ctx context.Context,
 
 		//use EconomyPlacementStatusRequest
 		request := &EconomyPlacementStatusRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyPlacementStatusRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -515,7 +686,7 @@ func rpcEconomyPlacementSuccess_Json(p *pamlogixImpl) func(ctx context.Context,
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -532,7 +703,7 @@ func rpcEconomyPlacementFail_Json(p *pamlogixImpl) func(ctx context.Context, log
 		}
 
 		request := &EconomyPlacementStatusRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EconomyPlacementStatusRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -560,7 +731,431 @@ func rpcEconomyPlacementFail_Json(p *pamlogixImpl) func(ctx context.Context, log
 		}
 
 		// Encode the response
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcEconomyPurchaseTransactionsExport_Json exports purchase transactions for finance reconciliation. It is only
+// available to server to server calls, made with the runtime HTTP key rather than a user session.
+func rpcEconomyPurchaseTransactionsExport_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", runtime.NewError("purchase transactions export is only available to server calls", PERMISSION_DENIED_ERROR_CODE) // PERMISSION_DENIED
+		}
+
+		request := &EconomyPurchaseTransactionsExportRequest{}
+		if payload != "" {
+			if err := unmarshalRpcJson(p, payload, request); err != nil {
+				logger.Error("Failed to unmarshal EconomyPurchaseTransactionsExportRequest: %v", err)
+				return "", ErrPayloadDecode
+			}
+		}
+
+		export, err := p.GetEconomySystem().PurchaseTransactionsExport(ctx, logger, nk, request.StartTimeSec, request.EndTimeSec, request.Format, request.Limit, request.Cursor)
+		if err != nil {
+			logger.Error("Error exporting purchase transactions: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, export)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomyModifierJobStart_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", runtime.NewError("modifier jobs are only available to server calls", PERMISSION_DENIED_ERROR_CODE) // PERMISSION_DENIED
+		}
+
+		request := &EconomyModifierJobRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomyModifierJobRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		job, err := p.GetEconomySystem().StartModifierJob(ctx, logger, db, nk, request)
+		if err != nil {
+			logger.Error("Error starting modifier job: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, job)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomyModifierJobGet_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", runtime.NewError("modifier jobs are only available to server calls", PERMISSION_DENIED_ERROR_CODE) // PERMISSION_DENIED
+		}
+
+		request := &EconomyModifierJobGetRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomyModifierJobGetRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		job, err := p.GetEconomySystem().GetModifierJob(ctx, logger, nk, request.Id)
+		if err != nil {
+			logger.Error("Error getting modifier job: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, job)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomySnapshotCreate_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrEconomySnapshotServerOnly
+		}
+
+		request := &EconomySnapshotCreateRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomySnapshotCreateRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		snapshot, err := p.GetEconomySystem().SnapshotCreate(ctx, logger, nk, request.UserId, request.Reason)
+		if err != nil {
+			logger.Error("Error creating economy snapshot: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, snapshot)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomySnapshotList_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrEconomySnapshotServerOnly
+		}
+
+		request := &EconomySnapshotListRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomySnapshotListRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		snapshots, err := p.GetEconomySystem().SnapshotList(ctx, logger, nk, request.UserId)
+		if err != nil {
+			logger.Error("Error listing economy snapshot: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, snapshots)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomySnapshotRestore_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrEconomySnapshotServerOnly
+		}
+
+		request := &EconomySnapshotRestoreRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomySnapshotRestoreRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		backup, err := p.GetEconomySystem().SnapshotRestore(ctx, logger, nk, request.UserId, request.SnapshotId)
+		if err != nil {
+			logger.Error("Error restoring economy snapshot: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, backup)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomyDebit_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrEconomyDebitServerOnly
+		}
+
+		request := &EconomyDebitRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomyDebitRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		debit := p.GetEconomySystem().Debit
+		if request.DryRun {
+			debit = p.GetEconomySystem().DebitDryRun
+		}
+		updatedWallet, err := debit(ctx, logger, nk, request.UserId, request.Currencies, request.Items, request.Metadata)
+		if err != nil {
+			logger.Error("Error debiting economy: %v", err)
+			return "", err
+		}
+
+		response := &EconomyUpdateAck{
+			Wallet:         updatedWallet,
+			CurrentTimeSec: time.Now().Unix(),
+		}
+
+		responseData, err := marshalRpcJson(p, response)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomyEventLogList_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrEconomyEventLogServerOnly
+		}
+
+		request := &EconomyEventLogListRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomyEventLogListRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		events, err := p.GetEconomySystem().EventLogList(ctx, logger, nk, request.AfterSequence, request.Limit)
+		if err != nil {
+			logger.Error("Error listing economy event log: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, events)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomySummary_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		summary, err := p.GetEconomySystem().Summary(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error getting economy summary: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, summary)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomySubscriptionPurchase_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		request := &EconomySubscriptionPurchaseRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomySubscriptionPurchaseRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		subscription, reward, err := p.GetEconomySystem().SubscriptionPurchase(ctx, logger, nk, userID, request.SubscriptionId, request.StoreType, request.Receipt)
+		if err != nil {
+			logger.Error("Error purchasing subscription: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, &EconomySubscriptionPurchaseAck{
+			Subscription: subscription,
+			Reward:       reward,
+		})
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomySubscriptionList_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		subscriptions, err := p.GetEconomySystem().SubscriptionList(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error listing subscriptions: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, subscriptions)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomySubscriptionCancel_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		request := &EconomySubscriptionCancelRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EconomySubscriptionCancelRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		subscription, err := p.GetEconomySystem().SubscriptionCancel(ctx, logger, nk, userID, request.SubscriptionId)
+		if err != nil {
+			logger.Error("Error cancelling subscription: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, subscription)
+		if err != nil {
+			logger.Error("Failed to marshal response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+func rpcEconomyPurchaseGrantsReconcile_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if p.GetEconomySystem() == nil {
+			return "", ErrSystemNotFound
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			logger.Error("No user ID in context")
+			return "", ErrNoSessionUser
+		}
+
+		reconcile, err := p.GetEconomySystem().PurchaseGrantsReconcile(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error reconciling purchase grants: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, reconcile)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
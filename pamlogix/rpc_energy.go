@@ -63,7 +63,7 @@ func rpcEnergySpend(p *pamlogixImpl) func(ctx context.Context, logger runtime.Lo
 		// Call the energy system to spend the energy
 		energies, reward, err := energySystem.Spend(ctx, logger, nk, userId, request.Amounts)
 		if err != nil {
-			return "", err
+			return "", energyRpcError(err)
 		}
 
 		// Create response with energies and reward
@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -31,7 +30,7 @@ func rpcEnergyGet_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime
 		}
 
 		// Marshal response to JSON
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal energies: %v", err)
 			return "", runtime.NewError("failed to marshal energies", INTERNAL_ERROR_CODE) // INTERNAL
@@ -55,7 +54,7 @@ func rpcEnergySpend_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 
 		// Parse the input request
 		request := &EnergySpendRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EnergySpendRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal energy spend request", INTERNAL_ERROR_CODE) // INTERNAL
 		}
@@ -63,7 +62,7 @@ func rpcEnergySpend_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 		// Call the energy system to spend the energy
 		energies, reward, err := energySystem.Spend(ctx, logger, nk, userId, request.Amounts)
 		if err != nil {
-			return "", err
+			return "", energyRpcError(err)
 		}
 
 		// Create response with energies and reward
@@ -75,7 +74,7 @@ func rpcEnergySpend_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 		}
 
 		// Marshal response to JSON
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal energy response: %v", err)
 			return "", runtime.NewError("failed to marshal energy response", INTERNAL_ERROR_CODE) // INTERNAL
@@ -85,6 +84,39 @@ func rpcEnergySpend_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 	}
 }
 
+func rpcEnergySpendWithRefill_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		energySystem := p.GetEnergySystem()
+		if energySystem == nil {
+			return "", runtime.NewError("energy system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userId, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userId == "" {
+			return "", runtime.NewError("user id not found in context", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+
+		request := &EnergySpendWithRefillRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EnergySpendWithRefillRequest: %v", err)
+			return "", runtime.NewError("failed to unmarshal energy spend with refill request", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		result, err := energySystem.SpendWithRefill(ctx, logger, nk, userId, request.Amounts)
+		if err != nil {
+			return "", energyRpcError(err)
+		}
+
+		data, err := marshalRpcJson(p, result)
+		if err != nil {
+			logger.Error("Failed to marshal energy spend with refill response: %v", err)
+			return "", runtime.NewError("failed to marshal energy spend with refill response", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		return string(data), nil
+	}
+}
+
 func rpcEnergyGrant_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
 		energySystem := p.GetEnergySystem()
@@ -99,7 +131,7 @@ func rpcEnergyGrant_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 
 		// Parse the input request
 		request := &EnergyGrantRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal EnergyGrantRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal energy grant request", INTERNAL_ERROR_CODE) // INTERNAL
 		}
@@ -117,7 +149,104 @@ func rpcEnergyGrant_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 		}
 
 		// Marshal the response
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
+		if err != nil {
+			logger.Error("Failed to marshal energies: %v", err)
+			return "", runtime.NewError("failed to marshal energies", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		return string(data), nil
+	}
+}
+
+func rpcEnergyGiftSend_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		energySystem := p.GetEnergySystem()
+		if energySystem == nil {
+			return "", runtime.NewError("energy system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userId, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userId == "" {
+			return "", runtime.NewError("user id not found in context", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+
+		request := &EnergyGiftSendRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal EnergyGiftSendRequest: %v", err)
+			return "", runtime.NewError("failed to unmarshal energy gift send request", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		gift, err := energySystem.SendGift(ctx, logger, nk, userId, request.RecipientId, request.EnergyId)
+		if err != nil {
+			logger.Error("Failed to send energy gift: %v", err)
+			return "", err
+		}
+
+		data, err := marshalRpcJson(p, gift)
+		if err != nil {
+			logger.Error("Failed to marshal energy gift: %v", err)
+			return "", runtime.NewError("failed to marshal energy gift", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		return string(data), nil
+	}
+}
+
+func rpcEnergyGiftList_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		energySystem := p.GetEnergySystem()
+		if energySystem == nil {
+			return "", runtime.NewError("energy system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userId, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userId == "" {
+			return "", runtime.NewError("user id not found in context", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+
+		gifts, err := energySystem.ListGifts(ctx, logger, nk, userId)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := marshalRpcJson(p, &EnergyGiftList{Gifts: gifts})
+		if err != nil {
+			logger.Error("Failed to marshal energy gifts: %v", err)
+			return "", runtime.NewError("failed to marshal energy gifts", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		return string(data), nil
+	}
+}
+
+func rpcEnergyGiftClaim_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		energySystem := p.GetEnergySystem()
+		if energySystem == nil {
+			return "", runtime.NewError("energy system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userId, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userId == "" {
+			return "", runtime.NewError("user id not found in context", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+
+		request := &EnergyGiftClaimRequest{}
+		if payload != "" {
+			if err := unmarshalRpcJson(p, payload, request); err != nil {
+				logger.Error("Failed to unmarshal EnergyGiftClaimRequest: %v", err)
+				return "", runtime.NewError("failed to unmarshal energy gift claim request", INTERNAL_ERROR_CODE) // INTERNAL
+			}
+		}
+
+		energies, err := energySystem.ClaimGifts(ctx, logger, nk, userId, request.Ids)
+		if err != nil {
+			logger.Error("Failed to claim energy gifts: %v", err)
+			return "", err
+		}
+
+		data, err := marshalRpcJson(p, &EnergyList{Energies: energies})
 		if err != nil {
 			logger.Error("Failed to marshal energies: %v", err)
 			return "", runtime.NewError("failed to marshal energies", INTERNAL_ERROR_CODE) // INTERNAL
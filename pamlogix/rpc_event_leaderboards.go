@@ -24,7 +24,7 @@ func rpcEventLeaderboardsList(pamlogix Pamlogix) func(context.Context, runtime.L
 		// Parse request
 		var req EventLeaderboardList
 		if payload != "" {
-			if err := json.Unmarshal([]byte(payload), &req); err != nil {
+			if err := unmarshalRpcJson(pamlogix, payload, &req); err != nil {
 				logger.Error("Failed to unmarshal event leaderboard list request: %v", err)
 				return "", ErrPayloadDecode
 			}
@@ -37,12 +37,10 @@ func rpcEventLeaderboardsList(pamlogix Pamlogix) func(context.Context, runtime.L
 			return "", err
 		}
 
-		// Build response
-		resp := &EventLeaderboards{
-			EventLeaderboards: eventLeaderboards,
-		}
+		response := newTimedEventLeaderboards(eventLeaderboards)
+		response.ItemDetails = eventLeaderboardItemDetails(ctx, logger, nk, pamlogix, userID, eventLeaderboards...)
 
-		respBytes, err := json.Marshal(resp)
+		respBytes, err := marshalRpcJson(pamlogix, response)
 		if err != nil {
 			logger.Error("Failed to marshal event leaderboards response: %v", err)
 			return "", ErrPayloadEncode
@@ -67,10 +65,11 @@ func rpcEventLeaderboardsGet(pamlogix Pamlogix) func(context.Context, runtime.Lo
 
 		// Parse request
 		var req EventLeaderboardGet
-		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		if err := unmarshalRpcJson(pamlogix, payload, &req); err != nil {
 			logger.Error("Failed to unmarshal event leaderboard get request: %v", err)
 			return "", ErrPayloadDecode
 		}
+		logger = withEntityLogger(logger, req.GetId())
 
 		if req.Id == "" {
 			return "", ErrBadInput
@@ -83,7 +82,10 @@ func rpcEventLeaderboardsGet(pamlogix Pamlogix) func(context.Context, runtime.Lo
 			return "", err
 		}
 
-		respBytes, err := json.Marshal(eventLeaderboard)
+		response := newTimedEventLeaderboard(eventLeaderboard)
+		response.ItemDetails = eventLeaderboardItemDetails(ctx, logger, nk, pamlogix, userID, eventLeaderboard)
+
+		respBytes, err := marshalRpcJson(pamlogix, response)
 		if err != nil {
 			logger.Error("Failed to marshal event leaderboard response: %v", err)
 			return "", ErrPayloadEncode
@@ -113,10 +115,11 @@ func rpcEventLeaderboardsUpdate(pamlogix Pamlogix) func(context.Context, runtime
 
 		// Parse request
 		var req EventLeaderboardUpdate
-		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		if err := unmarshalRpcJson(pamlogix, payload, &req); err != nil {
 			logger.Error("Failed to unmarshal event leaderboard update request: %v", err)
 			return "", ErrPayloadDecode
 		}
+		logger = withEntityLogger(logger, req.GetId())
 
 		if req.Id == "" {
 			return "", ErrBadInput
@@ -138,7 +141,7 @@ func rpcEventLeaderboardsUpdate(pamlogix Pamlogix) func(context.Context, runtime
 			return "", err
 		}
 
-		respBytes, err := json.Marshal(eventLeaderboard)
+		respBytes, err := marshalRpcJson(pamlogix, newTimedEventLeaderboard(eventLeaderboard))
 		if err != nil {
 			logger.Error("Failed to marshal event leaderboard response: %v", err)
 			return "", ErrPayloadEncode
@@ -148,6 +151,69 @@ func rpcEventLeaderboardsUpdate(pamlogix Pamlogix) func(context.Context, runtime
 	}
 }
 
+// rpcEventLeaderboardsGlobalGet handles the get event leaderboard global ranking RPC
+func rpcEventLeaderboardsGlobalGet(pamlogix Pamlogix) func(context.Context, runtime.Logger, *sql.DB, runtime.NakamaModule, string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok {
+			return "", ErrNoSessionUser
+		}
+
+		eventLeaderboardsSystem := pamlogix.GetEventLeaderboardsSystem()
+		if eventLeaderboardsSystem == nil {
+			return "", ErrSystemNotAvailable
+		}
+
+		// Parse request
+		var req EventLeaderboardGlobalRankingRequest
+		if err := unmarshalRpcJson(pamlogix, payload, &req); err != nil {
+			logger.Error("Failed to unmarshal event leaderboard global ranking request: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		if req.Id == "" {
+			return "", ErrBadInput
+		}
+
+		// Get global ranking
+		ranking, err := eventLeaderboardsSystem.GetEventLeaderboardGlobalRanking(ctx, logger, nk, userID, req.Id, req.Limit)
+		if err != nil {
+			logger.Error("Failed to get event leaderboard global ranking: %v", err)
+			return "", err
+		}
+
+		respBytes, err := marshalRpcJson(pamlogix, ranking)
+		if err != nil {
+			logger.Error("Failed to marshal event leaderboard global ranking response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(respBytes), nil
+	}
+}
+
+// rpcEventLeaderboardsCleanup handles the server-to-server RPC archiving ended event leaderboards, meant to be called
+// on a schedule
+func rpcEventLeaderboardsCleanup(pamlogix Pamlogix) func(context.Context, runtime.Logger, *sql.DB, runtime.NakamaModule, string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		if userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string); ok && userID != "" {
+			return "", ErrSessionUser
+		}
+
+		eventLeaderboardsSystem := pamlogix.GetEventLeaderboardsSystem()
+		if eventLeaderboardsSystem == nil {
+			return "", ErrSystemNotAvailable
+		}
+
+		if err := eventLeaderboardsSystem.CleanupEventLeaderboards(ctx, logger, nk); err != nil {
+			logger.Error("Failed to clean up event leaderboards: %v", err)
+			return "", err
+		}
+
+		return "{}", nil
+	}
+}
+
 // rpcEventLeaderboardsClaim handles the claim event leaderboard RPC
 func rpcEventLeaderboardsClaim(pamlogix Pamlogix) func(context.Context, runtime.Logger, *sql.DB, runtime.NakamaModule, string) (string, error) {
 	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
@@ -163,23 +229,30 @@ func rpcEventLeaderboardsClaim(pamlogix Pamlogix) func(context.Context, runtime.
 
 		// Parse request
 		var req EventLeaderboardClaim
-		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		if err := unmarshalRpcJson(pamlogix, payload, &req); err != nil {
 			logger.Error("Failed to unmarshal event leaderboard claim request: %v", err)
 			return "", ErrPayloadDecode
 		}
+		logger = withEntityLogger(logger, req.GetId())
 
 		if req.Id == "" {
 			return "", ErrBadInput
 		}
 
+		var choice *int
+		if req.Choice != nil {
+			choiceValue := int(req.Choice.Value)
+			choice = &choiceValue
+		}
+
 		// Claim event leaderboard
-		eventLeaderboard, err := eventLeaderboardsSystem.ClaimEventLeaderboard(ctx, logger, nk, userID, req.Id)
+		eventLeaderboard, err := eventLeaderboardsSystem.ClaimEventLeaderboard(ctx, logger, nk, userID, req.Id, choice)
 		if err != nil {
 			logger.Error("Failed to claim event leaderboard: %v", err)
 			return "", err
 		}
 
-		respBytes, err := json.Marshal(eventLeaderboard)
+		respBytes, err := marshalRpcJson(pamlogix, newTimedEventLeaderboard(eventLeaderboard))
 		if err != nil {
 			logger.Error("Failed to marshal event leaderboard response: %v", err)
 			return "", ErrPayloadEncode
@@ -204,10 +277,11 @@ func rpcEventLeaderboardsRoll(pamlogix Pamlogix) func(context.Context, runtime.L
 
 		// Parse request
 		var req EventLeaderboardRoll
-		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		if err := unmarshalRpcJson(pamlogix, payload, &req); err != nil {
 			logger.Error("Failed to unmarshal event leaderboard roll request: %v", err)
 			return "", ErrPayloadDecode
 		}
+		logger = withEntityLogger(logger, req.GetId())
 
 		if req.Id == "" {
 			return "", ErrBadInput
@@ -220,7 +294,7 @@ func rpcEventLeaderboardsRoll(pamlogix Pamlogix) func(context.Context, runtime.L
 			return "", err
 		}
 
-		respBytes, err := json.Marshal(eventLeaderboard)
+		respBytes, err := marshalRpcJson(pamlogix, newTimedEventLeaderboard(eventLeaderboard))
 		if err != nil {
 			logger.Error("Failed to marshal event leaderboard response: %v", err)
 			return "", ErrPayloadEncode
@@ -230,6 +304,76 @@ func rpcEventLeaderboardsRoll(pamlogix Pamlogix) func(context.Context, runtime.L
 	}
 }
 
+// rpcEventLeaderboardsRollPreview handles the RPC previewing the cohort and cost of rolling an event leaderboard
+func rpcEventLeaderboardsRollPreview(pamlogix Pamlogix) func(context.Context, runtime.Logger, *sql.DB, runtime.NakamaModule, string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok {
+			return "", ErrNoSessionUser
+		}
+
+		eventLeaderboardsSystem := pamlogix.GetEventLeaderboardsSystem()
+		if eventLeaderboardsSystem == nil {
+			return "", ErrSystemNotAvailable
+		}
+
+		// Parse request
+		var req EventLeaderboardRollPreviewRequest
+		if err := unmarshalRpcJson(pamlogix, payload, &req); err != nil {
+			logger.Error("Failed to unmarshal event leaderboard roll preview request: %v", err)
+			return "", ErrPayloadDecode
+		}
+		logger = withEntityLogger(logger, req.Id)
+
+		if req.Id == "" {
+			return "", ErrBadInput
+		}
+
+		preview, err := eventLeaderboardsSystem.PreviewRollEventLeaderboard(ctx, logger, nk, userID, req.Id, req.Tier)
+		if err != nil {
+			logger.Error("Failed to preview event leaderboard roll: %v", err)
+			return "", err
+		}
+
+		respBytes, err := marshalRpcJson(pamlogix, preview)
+		if err != nil {
+			logger.Error("Failed to marshal event leaderboard roll preview response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(respBytes), nil
+	}
+}
+
+// rpcEventLeaderboardsStats handles the RPC returning the user's lifetime event leaderboard stats
+func rpcEventLeaderboardsStats(pamlogix Pamlogix) func(context.Context, runtime.Logger, *sql.DB, runtime.NakamaModule, string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok {
+			return "", ErrNoSessionUser
+		}
+
+		eventLeaderboardsSystem := pamlogix.GetEventLeaderboardsSystem()
+		if eventLeaderboardsSystem == nil {
+			return "", ErrSystemNotAvailable
+		}
+
+		stats, err := eventLeaderboardsSystem.GetLifetimeStats(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Failed to get event leaderboard lifetime stats: %v", err)
+			return "", err
+		}
+
+		respBytes, err := marshalRpcJson(pamlogix, stats)
+		if err != nil {
+			logger.Error("Failed to marshal event leaderboard lifetime stats response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(respBytes), nil
+	}
+}
+
 // rpcEventLeaderboardsDebugFill handles the debug fill event leaderboard RPC
 func rpcEventLeaderboardsDebugFill(pamlogix Pamlogix) func(context.Context, runtime.Logger, *sql.DB, runtime.NakamaModule, string) (string, error) {
 	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
@@ -245,7 +389,7 @@ func rpcEventLeaderboardsDebugFill(pamlogix Pamlogix) func(context.Context, runt
 
 		// Parse request
 		var req EventLeaderboardDebugFillRequest
-		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		if err := unmarshalRpcJson(pamlogix, payload, &req); err != nil {
 			logger.Error("Failed to unmarshal event leaderboard debug fill request: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -261,7 +405,7 @@ func rpcEventLeaderboardsDebugFill(pamlogix Pamlogix) func(context.Context, runt
 			return "", err
 		}
 
-		respBytes, err := json.Marshal(eventLeaderboard)
+		respBytes, err := marshalRpcJson(pamlogix, newTimedEventLeaderboard(eventLeaderboard))
 		if err != nil {
 			logger.Error("Failed to marshal event leaderboard response: %v", err)
 			return "", ErrPayloadEncode
@@ -286,7 +430,7 @@ func rpcEventLeaderboardsDebugRandomScores(pamlogix Pamlogix) func(context.Conte
 
 		// Parse request
 		var req EventLeaderboardDebugRandomScoresRequest
-		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		if err := unmarshalRpcJson(pamlogix, payload, &req); err != nil {
 			logger.Error("Failed to unmarshal event leaderboard debug random scores request: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -308,7 +452,7 @@ func rpcEventLeaderboardsDebugRandomScores(pamlogix Pamlogix) func(context.Conte
 			return "", err
 		}
 
-		respBytes, err := json.Marshal(eventLeaderboard)
+		respBytes, err := marshalRpcJson(pamlogix, newTimedEventLeaderboard(eventLeaderboard))
 		if err != nil {
 			logger.Error("Failed to marshal event leaderboard response: %v", err)
 			return "", ErrPayloadEncode
@@ -0,0 +1,64 @@
+package pamlogix
+
+// RPC IDs for endpoints which are not part of the RpcId enum generated from pamlogix.proto. They are registered with
+// the JSON RPC handlers and follow the same naming scheme as the generated IDs.
+const (
+	RpcIdVersion = "RPC_ID_VERSION"
+
+	RpcIdBaseNotificationPreferencesGet = "RPC_ID_BASE_NOTIFICATION_PREFERENCES_GET"
+	RpcIdBaseNotificationPreferencesSet = "RPC_ID_BASE_NOTIFICATION_PREFERENCES_SET"
+	RpcIdBaseAccountLock                = "RPC_ID_BASE_ACCOUNT_LOCK"
+	RpcIdBaseAccountUnlock              = "RPC_ID_BASE_ACCOUNT_UNLOCK"
+	RpcIdBaseAccountPurge               = "RPC_ID_BASE_ACCOUNT_PURGE"
+	RpcIdBaseStorageSweep               = "RPC_ID_BASE_STORAGE_SWEEP"
+	RpcIdBaseContentCalendarGet         = "RPC_ID_BASE_CONTENT_CALENDAR_GET"
+	RpcIdBaseDataExport                 = "RPC_ID_BASE_DATA_EXPORT"
+
+	RpcIdAuctionsListHistory     = "RPC_ID_AUCTIONS_LIST_HISTORY"
+	RpcIdAuctionsClaimAllBids    = "RPC_ID_AUCTIONS_CLAIM_ALL_BIDS"
+	RpcIdAuctionsClaimAllCreated = "RPC_ID_AUCTIONS_CLAIM_ALL_CREATED"
+	RpcIdAuctionsGetTemplate     = "RPC_ID_AUCTIONS_GET_TEMPLATE"
+	RpcIdAuctionsPriceHistory    = "RPC_ID_AUCTIONS_PRICE_HISTORY"
+	RpcIdAuctionsBuyout          = "RPC_ID_AUCTIONS_BUYOUT"
+	RpcIdAuctionsRetractBid      = "RPC_ID_AUCTIONS_RETRACT_BID"
+	RpcIdAuctionsTeamBid         = "RPC_ID_AUCTIONS_TEAM_BID"
+
+	RpcIdEventLeaderboardGlobalGet   = "RPC_ID_EVENT_LEADERBOARD_GLOBAL_GET"
+	RpcIdEventLeaderboardCleanup     = "RPC_ID_EVENT_LEADERBOARD_CLEANUP"
+	RpcIdEventLeaderboardRollPreview = "RPC_ID_EVENT_LEADERBOARD_ROLL_PREVIEW"
+	RpcIdEventLeaderboardStats       = "RPC_ID_EVENT_LEADERBOARD_STATS"
+
+	RpcIdTeamsGet             = "RPC_ID_TEAMS_GET"
+	RpcIdTeamsUpdate          = "RPC_ID_TEAMS_UPDATE"
+	RpcIdTeamsTreasuryGet     = "RPC_ID_TEAMS_TREASURY_GET"
+	RpcIdTeamsTreasuryDeposit = "RPC_ID_TEAMS_TREASURY_DEPOSIT"
+
+	RpcIdLeaderboardsTournamentList      = "RPC_ID_LEADERBOARDS_TOURNAMENT_LIST"
+	RpcIdLeaderboardsTournamentJoin      = "RPC_ID_LEADERBOARDS_TOURNAMENT_JOIN"
+	RpcIdLeaderboardsTournamentStandings = "RPC_ID_LEADERBOARDS_TOURNAMENT_STANDINGS"
+
+	RpcIdEnergyGiftSend  = "RPC_ID_ENERGY_GIFT_SEND"
+	RpcIdEnergyGiftList  = "RPC_ID_ENERGY_GIFT_LIST"
+	RpcIdEnergyGiftClaim = "RPC_ID_ENERGY_GIFT_CLAIM"
+
+	RpcIdEnergySpendWithRefill = "RPC_ID_ENERGY_SPEND_WITH_REFILL"
+
+	RpcIdEconomyPurchaseTransactionsExport = "RPC_ID_ECONOMY_PURCHASE_TRANSACTIONS_EXPORT"
+	RpcIdEconomyPurchaseIntentCancel       = "RPC_ID_ECONOMY_PURCHASE_INTENT_CANCEL"
+	RpcIdEconomyCanAfford                  = "RPC_ID_ECONOMY_CAN_AFFORD"
+	RpcIdEconomyModifierJobStart           = "RPC_ID_ECONOMY_MODIFIER_JOB_START"
+	RpcIdEconomyModifierJobGet             = "RPC_ID_ECONOMY_MODIFIER_JOB_GET"
+	RpcIdEconomyCurrencies                 = "RPC_ID_ECONOMY_CURRENCIES"
+	RpcIdEconomyPlacementList              = "RPC_ID_ECONOMY_PLACEMENT_LIST"
+	RpcIdEconomySnapshotCreate             = "RPC_ID_ECONOMY_SNAPSHOT_CREATE"
+	RpcIdEconomySnapshotList               = "RPC_ID_ECONOMY_SNAPSHOT_LIST"
+	RpcIdEconomySnapshotRestore            = "RPC_ID_ECONOMY_SNAPSHOT_RESTORE"
+	RpcIdEconomyDebit                      = "RPC_ID_ECONOMY_DEBIT"
+	RpcIdEconomyEventLogList               = "RPC_ID_ECONOMY_EVENT_LOG_LIST"
+	RpcIdEconomySummary                    = "RPC_ID_ECONOMY_SUMMARY"
+	RpcIdEconomySubscriptionPurchase       = "RPC_ID_ECONOMY_SUBSCRIPTION_PURCHASE"
+	RpcIdEconomySubscriptionList           = "RPC_ID_ECONOMY_SUBSCRIPTION_LIST"
+	RpcIdEconomySubscriptionCancel         = "RPC_ID_ECONOMY_SUBSCRIPTION_CANCEL"
+	RpcIdEconomyPurchaseGrantsReconcile    = "RPC_ID_ECONOMY_PURCHASE_GRANTS_RECONCILE"
+	RpcIdEconomyTeamPurchase               = "RPC_ID_ECONOMY_TEAM_PURCHASE"
+)
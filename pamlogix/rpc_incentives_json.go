@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -32,7 +31,7 @@ func rpcIncentivesSenderListJson(p *pamlogixImpl) func(ctx context.Context, logg
 			Incentives: incentives,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal incentives: %v", err)
 			return "", runtime.NewError("failed to marshal incentives", INTERNAL_ERROR_CODE) // INTERNAL
@@ -55,7 +54,7 @@ func rpcIncentivesSenderCreateJson(p *pamlogixImpl) func(ctx context.Context, lo
 		}
 
 		request := &IncentiveSenderCreateRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal incentive create request: %v", err)
 			return "", runtime.NewError("failed to unmarshal incentive create request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -75,7 +74,7 @@ func rpcIncentivesSenderCreateJson(p *pamlogixImpl) func(ctx context.Context, lo
 			Incentives: incentives,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal incentives: %v", err)
 			return "", runtime.NewError("failed to marshal incentives", INTERNAL_ERROR_CODE) // INTERNAL
@@ -98,7 +97,7 @@ func rpcIncentivesSenderDeleteJson(p *pamlogixImpl) func(ctx context.Context, lo
 		}
 
 		request := &IncentiveSenderDeleteRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal incentive delete request: %v", err)
 			return "", runtime.NewError("failed to unmarshal incentive delete request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -118,7 +117,7 @@ func rpcIncentivesSenderDeleteJson(p *pamlogixImpl) func(ctx context.Context, lo
 			Incentives: incentives,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal incentives: %v", err)
 			return "", runtime.NewError("failed to marshal incentives", INTERNAL_ERROR_CODE) // INTERNAL
@@ -141,7 +140,7 @@ func rpcIncentivesSenderClaimJson(p *pamlogixImpl) func(ctx context.Context, log
 		}
 
 		request := &IncentiveSenderClaimRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal incentive claim request: %v", err)
 			return "", runtime.NewError("failed to unmarshal incentive claim request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -161,7 +160,7 @@ func rpcIncentivesSenderClaimJson(p *pamlogixImpl) func(ctx context.Context, log
 			Incentives: incentives,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal incentives: %v", err)
 			return "", runtime.NewError("failed to marshal incentives", INTERNAL_ERROR_CODE) // INTERNAL
@@ -184,7 +183,7 @@ func rpcIncentivesRecipientGetJson(p *pamlogixImpl) func(ctx context.Context, lo
 		}
 
 		request := &IncentiveRecipientGetRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal incentive get request: %v", err)
 			return "", runtime.NewError("failed to unmarshal incentive get request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -198,7 +197,7 @@ func rpcIncentivesRecipientGetJson(p *pamlogixImpl) func(ctx context.Context, lo
 			return "", err
 		}
 
-		data, err := json.Marshal(incentive)
+		data, err := marshalRpcJson(p, incentive)
 		if err != nil {
 			logger.Error("Failed to marshal incentive info: %v", err)
 			return "", runtime.NewError("failed to marshal incentive info", INTERNAL_ERROR_CODE) // INTERNAL
@@ -221,7 +220,7 @@ func rpcIncentivesRecipientClaimJson(p *pamlogixImpl) func(ctx context.Context,
 		}
 
 		request := &IncentiveRecipientClaimRequest{}
-		if err := json.Unmarshal([]byte(payload), request); err != nil {
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
 			logger.Error("Failed to unmarshal incentive claim request: %v", err)
 			return "", runtime.NewError("failed to unmarshal incentive claim request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -235,7 +234,7 @@ func rpcIncentivesRecipientClaimJson(p *pamlogixImpl) func(ctx context.Context,
 			return "", err
 		}
 
-		data, err := json.Marshal(incentive)
+		data, err := marshalRpcJson(p, incentive)
 		if err != nil {
 			logger.Error("Failed to marshal incentive info: %v", err)
 			return "", runtime.NewError("failed to marshal incentive info", INTERNAL_ERROR_CODE) // INTERNAL
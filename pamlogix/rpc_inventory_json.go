@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -18,7 +17,7 @@ func rpcInventoryList_Json(p *pamlogixImpl) func(ctx context.Context, logger run
 		var request struct {
 			Category string `json:"category,omitempty"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal InventoryListRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -48,7 +47,7 @@ func rpcInventoryList_Json(p *pamlogixImpl) func(ctx context.Context, logger run
 			ItemSets: itemSets,
 		}
 
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -67,8 +66,10 @@ func rpcInventoryListInventory_Json(p *pamlogixImpl) func(ctx context.Context, l
 
 		var request struct {
 			Category string `json:"category,omitempty"`
+			// Query filters items by their properties, e.g. `rarity=="epic" power>50`.
+			Query string `json:"query,omitempty"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal InventoryListInventoryRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -78,13 +79,18 @@ func rpcInventoryListInventory_Json(p *pamlogixImpl) func(ctx context.Context, l
 			return "", ErrNoSessionUser
 		}
 
-		inventoryItems, err := inventorySystem.ListInventoryItems(ctx, logger, nk, userID, request.Category)
+		filters, err := ParseInventoryQuery(request.Query)
+		if err != nil {
+			return "", err
+		}
+
+		inventoryItems, err := inventorySystem.QueryInventoryItems(ctx, logger, nk, userID, request.Category, filters)
 		if err != nil {
 			logger.Error("Error listing inventory items: %v", err)
 			return "", err
 		}
 
-		responseData, err := json.Marshal(inventoryItems)
+		responseData, err := marshalRpcJson(p, inventoryItems)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -102,7 +108,7 @@ func rpcInventoryConsume_Json(p *pamlogixImpl) func(ctx context.Context, logger
 		}
 
 		var request InventoryConsumeRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal InventoryConsumeRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -155,7 +161,7 @@ func rpcInventoryConsume_Json(p *pamlogixImpl) func(ctx context.Context, logger
 			InstanceRewards: responseInstanceRewards,
 		}
 
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -173,7 +179,7 @@ func rpcInventoryGrant_Json(p *pamlogixImpl) func(ctx context.Context, logger ru
 		}
 
 		var request InventoryGrantRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal InventoryGrantRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -194,7 +200,7 @@ func rpcInventoryGrant_Json(p *pamlogixImpl) func(ctx context.Context, logger ru
 			Inventory: updatedInventory,
 		}
 
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -212,7 +218,7 @@ func rpcInventoryUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logger r
 		}
 
 		var request InventoryUpdateItemsRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal InventoryUpdateItemsRequest: %v", err)
 			return "", ErrPayloadDecode
 		}
@@ -241,7 +247,7 @@ func rpcInventoryUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logger r
 			Inventory: updatedInventory,
 		}
 
-		responseData, err := json.Marshal(response)
+		responseData, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal response: %v", err)
 			return "", ErrPayloadEncode
@@ -0,0 +1,115 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const defaultTournamentStandingsLimit = 20
+
+// rpcLeaderboardsTournamentList_Json handles the list tournaments RPC with JSON
+func rpcLeaderboardsTournamentList_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		leaderboardsSystem := p.GetLeaderboardsSystem()
+		if leaderboardsSystem == nil {
+			return "", runtime.NewError("leaderboards system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		tournaments, err := leaderboardsSystem.ListTournaments(ctx, logger, nk, userID)
+		if err != nil {
+			logger.Error("Error listing tournaments: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, tournaments)
+		if err != nil {
+			logger.Error("Failed to marshal tournament list response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcLeaderboardsTournamentJoin_Json handles the join tournament RPC with JSON
+func rpcLeaderboardsTournamentJoin_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		leaderboardsSystem := p.GetLeaderboardsSystem()
+		if leaderboardsSystem == nil {
+			return "", runtime.NewError("leaderboards system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		request := &TournamentJoinRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal TournamentJoinRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+		username, _ := ctx.Value(runtime.RUNTIME_CTX_USERNAME).(string)
+
+		tournament, err := leaderboardsSystem.JoinTournament(ctx, logger, nk, userID, username, request.Id)
+		if err != nil {
+			logger.Error("Error joining tournament: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, tournament)
+		if err != nil {
+			logger.Error("Failed to marshal tournament join response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
+
+// rpcLeaderboardsTournamentStandings_Json handles the tournament standings RPC with JSON
+func rpcLeaderboardsTournamentStandings_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		leaderboardsSystem := p.GetLeaderboardsSystem()
+		if leaderboardsSystem == nil {
+			return "", runtime.NewError("leaderboards system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		request := &TournamentStandingsRequest{}
+		if err := unmarshalRpcJson(p, payload, request); err != nil {
+			logger.Error("Failed to unmarshal TournamentStandingsRequest: %v", err)
+			return "", ErrPayloadDecode
+		}
+
+		userID, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userID == "" {
+			return "", ErrNoSessionUser
+		}
+
+		limit, err := pageLimit(request.Limit, defaultTournamentStandingsLimit, maxPageLimit)
+		if err != nil {
+			return "", err
+		}
+
+		standings, err := leaderboardsSystem.GetTournamentStandings(ctx, logger, nk, userID, request.Id, limit, request.Cursor)
+		if err != nil {
+			logger.Error("Error getting tournament standings: %v", err)
+			return "", err
+		}
+
+		responseData, err := marshalRpcJson(p, standings)
+		if err != nil {
+			logger.Error("Failed to marshal tournament standings response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -22,7 +21,7 @@ func rpcProgressionsGet_Json(p *pamlogixImpl) func(ctx context.Context, logger r
 
 		var request ProgressionGetRequest
 		if payload != "" {
-			if err := json.Unmarshal([]byte(payload), &request); err != nil {
+			if err := unmarshalRpcJson(p, payload, &request); err != nil {
 				logger.Error("Failed to unmarshal ProgressionGetRequestJson: %v", err)
 				return "", runtime.NewError("failed to unmarshal progression get request", INVALID_ARGUMENT_ERROR_CODE)
 			}
@@ -38,7 +37,7 @@ func rpcProgressionsGet_Json(p *pamlogixImpl) func(ctx context.Context, logger r
 			Deltas:       deltas,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal progression response: %v", err)
 			return "", runtime.NewError("failed to marshal progression response", INTERNAL_ERROR_CODE)
@@ -61,7 +60,7 @@ func rpcProgressionsPurchase_Json(p *pamlogixImpl) func(ctx context.Context, log
 		}
 
 		var request ProgressionPurchaseRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal ProgressionPurchaseRequestJson: %v", err)
 			return "", runtime.NewError("failed to unmarshal progression purchase request", INVALID_ARGUMENT_ERROR_CODE)
 		}
@@ -79,7 +78,7 @@ func rpcProgressionsPurchase_Json(p *pamlogixImpl) func(ctx context.Context, log
 			Progressions: progressions,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal progression response: %v", err)
 			return "", runtime.NewError("failed to marshal progression response", INTERNAL_ERROR_CODE)
@@ -102,7 +101,7 @@ func rpcProgressionsUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logge
 		}
 
 		var request ProgressionUpdateRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal ProgressionUpdateRequestJson: %v", err)
 			return "", runtime.NewError("failed to unmarshal progression update request", INVALID_ARGUMENT_ERROR_CODE)
 		}
@@ -124,7 +123,7 @@ func rpcProgressionsUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logge
 			Progressions: progressions,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal progression response: %v", err)
 			return "", runtime.NewError("failed to marshal progression response", INTERNAL_ERROR_CODE)
@@ -147,7 +146,7 @@ func rpcProgressionsReset_Json(p *pamlogixImpl) func(ctx context.Context, logger
 		}
 
 		var request ProgressionResetRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal ProgressionResetRequestJson: %v", err)
 			return "", runtime.NewError("failed to unmarshal progression reset request", INVALID_ARGUMENT_ERROR_CODE)
 		}
@@ -165,7 +164,7 @@ func rpcProgressionsReset_Json(p *pamlogixImpl) func(ctx context.Context, logger
 			Progressions: progressions,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal progression response: %v", err)
 			return "", runtime.NewError("failed to marshal progression response", INTERNAL_ERROR_CODE)
@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -29,7 +28,7 @@ func rpcStatsGet_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.
 		if !ok || stats == nil {
 			stats = &StatList{Public: map[string]*Stat{}, Private: map[string]*Stat{}}
 		}
-		data, err := json.Marshal(stats)
+		data, err := marshalRpcJson(p, stats)
 		if err != nil {
 			logger.Error("Failed to marshal stats: %v", err)
 			return "", runtime.NewError("failed to marshal stats", INTERNAL_ERROR_CODE) // INTERNAL
@@ -51,7 +50,7 @@ func rpcStatsUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 		}
 
 		var req StatUpdateRequest
-		if err := json.Unmarshal([]byte(payload), &req); err != nil {
+		if err := unmarshalRpcJson(p, payload, &req); err != nil {
 			logger.Error("Failed to unmarshal StatUpdateRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal stat update request", INTERNAL_ERROR_CODE) // INTERNAL
 		}
@@ -60,7 +59,7 @@ func rpcStatsUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 		if err != nil {
 			return "", err
 		}
-		data, err := json.Marshal(stats)
+		data, err := marshalRpcJson(p, stats)
 		if err != nil {
 			logger.Error("Failed to marshal updated stats: %v", err)
 			return "", runtime.NewError("failed to marshal updated stats", INTERNAL_ERROR_CODE) // INTERNAL
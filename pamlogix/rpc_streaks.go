@@ -106,14 +106,17 @@ func rpcStreaksClaim(p *pamlogixImpl) func(ctx context.Context, logger runtime.L
 			return "", runtime.NewError("at least one streak id is required", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
 
-		streaks, err := streaksSystem.Claim(ctx, logger, nk, userID, request.Ids)
+		claims, err := streaksSystem.Claim(ctx, logger, nk, userID, request.Ids)
 		if err != nil {
 			return "", err
 		}
 
 		// Create response using protobuf StreaksList
 		response := &StreaksList{
-			Streaks: streaks,
+			Streaks: make(map[string]*Streak, len(claims)),
+		}
+		for id, claim := range claims {
+			response.Streaks[id] = claim.Streak
 		}
 
 		data, err := proto.Marshal(response)
@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -27,12 +26,7 @@ func rpcStreaksList_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 			return "", err
 		}
 
-		// Create response in the format expected by the JSON StreaksList
-		response := &StreaksList{
-			Streaks: streaks,
-		}
-
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, newTimedStreaksList(streaks))
 		if err != nil {
 			logger.Error("Failed to marshal streaks: %v", err)
 			return "", runtime.NewError("failed to marshal streaks", INTERNAL_ERROR_CODE) // INTERNAL
@@ -55,7 +49,7 @@ func rpcStreaksUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logger run
 		}
 
 		var request StreaksUpdateRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal StreaksUpdateRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal streaks update request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -69,12 +63,7 @@ func rpcStreaksUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logger run
 			return "", err
 		}
 
-		// Create response using JSON StreaksList
-		response := &StreaksList{
-			Streaks: streaks,
-		}
-
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, newTimedStreaksList(streaks))
 		if err != nil {
 			logger.Error("Failed to marshal streaks: %v", err)
 			return "", runtime.NewError("failed to marshal streaks", INTERNAL_ERROR_CODE) // INTERNAL
@@ -97,7 +86,7 @@ func rpcStreaksClaim_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 		}
 
 		var request StreaksClaimRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal StreaksClaimRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal streaks claim request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -106,17 +95,12 @@ func rpcStreaksClaim_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 			return "", runtime.NewError("at least one streak id is required", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
 
-		streaks, err := streaksSystem.Claim(ctx, logger, nk, userID, request.Ids)
+		claims, err := streaksSystem.Claim(ctx, logger, nk, userID, request.Ids)
 		if err != nil {
 			return "", err
 		}
 
-		// Create response using JSON StreaksList
-		response := &StreaksList{
-			Streaks: streaks,
-		}
-
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, newTimedStreakClaims(claims))
 		if err != nil {
 			logger.Error("Failed to marshal streaks: %v", err)
 			return "", runtime.NewError("failed to marshal streaks", INTERNAL_ERROR_CODE) // INTERNAL
@@ -139,7 +123,7 @@ func rpcStreaksReset_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 		}
 
 		var request StreaksResetRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal StreaksResetRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal streaks reset request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -153,12 +137,7 @@ func rpcStreaksReset_Json(p *pamlogixImpl) func(ctx context.Context, logger runt
 			return "", err
 		}
 
-		// Create response using JSON StreaksList
-		response := &StreaksList{
-			Streaks: streaks,
-		}
-
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, newTimedStreaksList(streaks))
 		if err != nil {
 			logger.Error("Failed to marshal streaks: %v", err)
 			return "", runtime.NewError("failed to marshal streaks", INTERNAL_ERROR_CODE) // INTERNAL
@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -16,7 +15,7 @@ func rpcTeamsCreate_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 		}
 
 		var request TeamCreateRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal TeamCreateRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal team create request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -26,7 +25,7 @@ func rpcTeamsCreate_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 			return "", err
 		}
 
-		data, err := json.Marshal(team)
+		data, err := marshalRpcJson(p, team)
 		if err != nil {
 			logger.Error("Failed to marshal team: %v", err)
 			return "", runtime.NewError("failed to marshal team", INTERNAL_ERROR_CODE) // INTERNAL
@@ -44,7 +43,7 @@ func rpcTeamsList_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime
 		}
 
 		var request TeamListRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal TeamListRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal team list request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -54,7 +53,7 @@ func rpcTeamsList_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime
 			return "", err
 		}
 
-		data, err := json.Marshal(teamList)
+		data, err := marshalRpcJson(p, newTeamListDetails(teamList))
 		if err != nil {
 			logger.Error("Failed to marshal team list: %v", err)
 			return "", runtime.NewError("failed to marshal team list", INTERNAL_ERROR_CODE) // INTERNAL
@@ -72,7 +71,7 @@ func rpcTeamsSearch_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 		}
 
 		var request TeamSearchRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal TeamSearchRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal team search request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -82,7 +81,7 @@ func rpcTeamsSearch_Json(p *pamlogixImpl) func(ctx context.Context, logger runti
 			return "", err
 		}
 
-		data, err := json.Marshal(teamList)
+		data, err := marshalRpcJson(p, newTeamListDetails(teamList))
 		if err != nil {
 			logger.Error("Failed to marshal team list: %v", err)
 			return "", runtime.NewError("failed to marshal team list", INTERNAL_ERROR_CODE) // INTERNAL
@@ -105,7 +104,7 @@ func rpcTeamsWriteChatMessage_Json(p *pamlogixImpl) func(ctx context.Context, lo
 		}
 
 		var request TeamWriteChatMessageRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal TeamWriteChatMessageRequest: %v", err)
 			return "", runtime.NewError("failed to unmarshal team write chat message request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -115,7 +114,7 @@ func rpcTeamsWriteChatMessage_Json(p *pamlogixImpl) func(ctx context.Context, lo
 			return "", err
 		}
 
-		data, err := json.Marshal(ack)
+		data, err := marshalRpcJson(p, ack)
 		if err != nil {
 			logger.Error("Failed to marshal channel message ack: %v", err)
 			return "", runtime.NewError("failed to marshal channel message ack", INTERNAL_ERROR_CODE) // INTERNAL
@@ -124,3 +123,134 @@ func rpcTeamsWriteChatMessage_Json(p *pamlogixImpl) func(ctx context.Context, lo
 		return string(data), nil
 	}
 }
+
+func rpcTeamsGet_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		teamsSystem := p.GetTeamsSystem()
+		if teamsSystem == nil {
+			return "", runtime.NewError("teams system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		var request TeamGetRequest
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
+			logger.Error("Failed to unmarshal TeamGetRequest: %v", err)
+			return "", runtime.NewError("failed to unmarshal team get request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		logger = withEntityLogger(logger, request.Id)
+
+		team, err := teamsSystem.Get(ctx, logger, nk, request.Id)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := marshalRpcJson(p, team)
+		if err != nil {
+			logger.Error("Failed to marshal team: %v", err)
+			return "", runtime.NewError("failed to marshal team", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		return string(data), nil
+	}
+}
+
+func rpcTeamsUpdate_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		teamsSystem := p.GetTeamsSystem()
+		if teamsSystem == nil {
+			return "", runtime.NewError("teams system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userId, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userId == "" {
+			return "", runtime.NewError("user id not found in context", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+
+		var request TeamUpdateRequest
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
+			logger.Error("Failed to unmarshal TeamUpdateRequest: %v", err)
+			return "", runtime.NewError("failed to unmarshal team update request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		logger = withEntityLogger(logger, request.Id)
+
+		team, err := teamsSystem.Update(ctx, logger, nk, userId, &request)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := marshalRpcJson(p, team)
+		if err != nil {
+			logger.Error("Failed to marshal team: %v", err)
+			return "", runtime.NewError("failed to marshal team", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		return string(data), nil
+	}
+}
+
+func rpcTeamsTreasuryGet_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		teamsSystem := p.GetTeamsSystem()
+		if teamsSystem == nil {
+			return "", runtime.NewError("teams system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userId, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userId == "" {
+			return "", runtime.NewError("user id not found in context", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+
+		var request TeamTreasuryGetRequest
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
+			logger.Error("Failed to unmarshal TeamTreasuryGetRequest: %v", err)
+			return "", runtime.NewError("failed to unmarshal team treasury get request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		logger = withEntityLogger(logger, request.Id)
+
+		treasury, err := teamsSystem.GetTreasury(ctx, logger, nk, userId, request.Id)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := marshalRpcJson(p, treasury)
+		if err != nil {
+			logger.Error("Failed to marshal team treasury: %v", err)
+			return "", runtime.NewError("failed to marshal team treasury", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		return string(data), nil
+	}
+}
+
+func rpcTeamsTreasuryDeposit_Json(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		teamsSystem := p.GetTeamsSystem()
+		if teamsSystem == nil {
+			return "", runtime.NewError("teams system not available", UNIMPLEMENTED_ERROR_CODE) // UNIMPLEMENTED
+		}
+
+		userId, ok := ctx.Value(runtime.RUNTIME_CTX_USER_ID).(string)
+		if !ok || userId == "" {
+			return "", runtime.NewError("user id not found in context", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+
+		var request TeamTreasuryDepositRequest
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
+			logger.Error("Failed to unmarshal TeamTreasuryDepositRequest: %v", err)
+			return "", runtime.NewError("failed to unmarshal team treasury deposit request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		logger = withEntityLogger(logger, request.Id)
+
+		treasury, err := teamsSystem.DepositTreasury(ctx, logger, nk, userId, request.Id, request.Currencies)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := marshalRpcJson(p, treasury)
+		if err != nil {
+			logger.Error("Failed to marshal team treasury: %v", err)
+			return "", runtime.NewError("failed to marshal team treasury", INTERNAL_ERROR_CODE) // INTERNAL
+		}
+
+		return string(data), nil
+	}
+}
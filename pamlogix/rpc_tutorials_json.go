@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -30,7 +29,7 @@ func rpcTutorialsGetJson(p *pamlogixImpl) func(ctx context.Context, logger runti
 			Tutorials: tutorials,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal tutorials: %v", err)
 			return "", runtime.NewError("failed to marshal tutorials", INTERNAL_ERROR_CODE) // INTERNAL
@@ -53,7 +52,7 @@ func rpcTutorialsAcceptJson(p *pamlogixImpl) func(ctx context.Context, logger ru
 		}
 
 		var request TutorialAcceptRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal TutorialAcceptRequestJson: %v", err)
 			return "", runtime.NewError("failed to unmarshal tutorial accept request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -67,7 +66,7 @@ func rpcTutorialsAcceptJson(p *pamlogixImpl) func(ctx context.Context, logger ru
 			return "", err
 		}
 
-		data, err := json.Marshal(tutorial)
+		data, err := marshalRpcJson(p, tutorial)
 		if err != nil {
 			logger.Error("Failed to marshal tutorial: %v", err)
 			return "", runtime.NewError("failed to marshal tutorial", INTERNAL_ERROR_CODE) // INTERNAL
@@ -90,7 +89,7 @@ func rpcTutorialsDeclineJson(p *pamlogixImpl) func(ctx context.Context, logger r
 		}
 
 		var request TutorialDeclineRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal TutorialDeclineRequestJson: %v", err)
 			return "", runtime.NewError("failed to unmarshal tutorial decline request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -104,7 +103,7 @@ func rpcTutorialsDeclineJson(p *pamlogixImpl) func(ctx context.Context, logger r
 			return "", err
 		}
 
-		data, err := json.Marshal(tutorial)
+		data, err := marshalRpcJson(p, tutorial)
 		if err != nil {
 			logger.Error("Failed to marshal tutorial: %v", err)
 			return "", runtime.NewError("failed to marshal tutorial", INTERNAL_ERROR_CODE) // INTERNAL
@@ -127,7 +126,7 @@ func rpcTutorialsAbandonJson(p *pamlogixImpl) func(ctx context.Context, logger r
 		}
 
 		var request TutorialAbandonRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal TutorialAbandonRequestJson: %v", err)
 			return "", runtime.NewError("failed to unmarshal tutorial abandon request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -141,7 +140,7 @@ func rpcTutorialsAbandonJson(p *pamlogixImpl) func(ctx context.Context, logger r
 			return "", err
 		}
 
-		data, err := json.Marshal(tutorial)
+		data, err := marshalRpcJson(p, tutorial)
 		if err != nil {
 			logger.Error("Failed to marshal tutorial: %v", err)
 			return "", runtime.NewError("failed to marshal tutorial", INTERNAL_ERROR_CODE) // INTERNAL
@@ -164,7 +163,7 @@ func rpcTutorialsUpdateJson(p *pamlogixImpl) func(ctx context.Context, logger ru
 		}
 
 		var request TutorialUpdateRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal TutorialUpdateRequestJson: %v", err)
 			return "", runtime.NewError("failed to unmarshal tutorial update request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -183,7 +182,7 @@ func rpcTutorialsUpdateJson(p *pamlogixImpl) func(ctx context.Context, logger ru
 			Tutorials: tutorials,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal tutorials: %v", err)
 			return "", runtime.NewError("failed to marshal tutorials", INTERNAL_ERROR_CODE) // INTERNAL
@@ -206,7 +205,7 @@ func rpcTutorialsResetJson(p *pamlogixImpl) func(ctx context.Context, logger run
 		}
 
 		var request TutorialResetRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal TutorialResetRequestJson: %v", err)
 			return "", runtime.NewError("failed to unmarshal tutorial reset request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -225,7 +224,7 @@ func rpcTutorialsResetJson(p *pamlogixImpl) func(ctx context.Context, logger run
 			Tutorials: tutorials,
 		}
 
-		data, err := json.Marshal(response)
+		data, err := marshalRpcJson(p, response)
 		if err != nil {
 			logger.Error("Failed to marshal tutorials: %v", err)
 			return "", runtime.NewError("failed to marshal tutorials", INTERNAL_ERROR_CODE) // INTERNAL
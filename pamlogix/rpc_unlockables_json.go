@@ -3,7 +3,6 @@ package pamlogix
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 
 	"github.com/heroiclabs/nakama-common/runtime"
 )
@@ -29,7 +28,7 @@ func rpcUnlockablesCreateJson(p *pamlogixImpl) func(ctx context.Context, logger
 		}
 
 		// Convert to JSON
-		data, err := json.Marshal(unlockables)
+		data, err := marshalRpcJson(p, newTimedUnlockablesList(unlockables))
 		if err != nil {
 			logger.Error("Failed to marshal unlockables to JSON: %v", err)
 			return "", runtime.NewError("failed to marshal unlockables", INTERNAL_ERROR_CODE) // INTERNAL
@@ -57,7 +56,7 @@ func rpcUnlockablesGetJson(p *pamlogixImpl) func(ctx context.Context, logger run
 		}
 
 		// Convert to JSON
-		data, err := json.Marshal(unlockables)
+		data, err := marshalRpcJson(p, newTimedUnlockablesList(unlockables))
 		if err != nil {
 			logger.Error("Failed to marshal unlockables to JSON: %v", err)
 			return "", runtime.NewError("failed to marshal unlockables", INTERNAL_ERROR_CODE) // INTERNAL
@@ -82,7 +81,7 @@ func rpcUnlockablesUnlockStartJson(p *pamlogixImpl) func(ctx context.Context, lo
 		var request struct {
 			InstanceId string `json:"instanceId"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal UnlockablesRequest from JSON: %v", err)
 			return "", runtime.NewError("failed to unmarshal unlockables request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -97,7 +96,7 @@ func rpcUnlockablesUnlockStartJson(p *pamlogixImpl) func(ctx context.Context, lo
 		}
 
 		// Convert to JSON
-		data, err := json.Marshal(unlockables)
+		data, err := marshalRpcJson(p, newTimedUnlockablesList(unlockables))
 		if err != nil {
 			logger.Error("Failed to marshal unlockables to JSON: %v", err)
 			return "", runtime.NewError("failed to marshal unlockables", INTERNAL_ERROR_CODE) // INTERNAL
@@ -122,7 +121,7 @@ func rpcUnlockablesPurchaseUnlockJson(p *pamlogixImpl) func(ctx context.Context,
 		var request struct {
 			InstanceId string `json:"instanceId"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal UnlockablesRequest from JSON: %v", err)
 			return "", runtime.NewError("failed to unmarshal unlockables request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -137,7 +136,7 @@ func rpcUnlockablesPurchaseUnlockJson(p *pamlogixImpl) func(ctx context.Context,
 		}
 
 		// Convert to JSON
-		data, err := json.Marshal(unlockables)
+		data, err := marshalRpcJson(p, newTimedUnlockablesList(unlockables))
 		if err != nil {
 			logger.Error("Failed to marshal unlockables to JSON: %v", err)
 			return "", runtime.NewError("failed to marshal unlockables", INTERNAL_ERROR_CODE) // INTERNAL
@@ -165,7 +164,7 @@ func rpcUnlockablesPurchaseSlotJson(p *pamlogixImpl) func(ctx context.Context, l
 		}
 
 		// Convert to JSON
-		data, err := json.Marshal(unlockables)
+		data, err := marshalRpcJson(p, newTimedUnlockablesList(unlockables))
 		if err != nil {
 			logger.Error("Failed to marshal unlockables to JSON: %v", err)
 			return "", runtime.NewError("failed to marshal unlockables", INTERNAL_ERROR_CODE) // INTERNAL
@@ -190,7 +189,7 @@ func rpcUnlockablesClaimJson(p *pamlogixImpl) func(ctx context.Context, logger r
 		var request struct {
 			InstanceId string `json:"instanceId"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal UnlockablesRequest from JSON: %v", err)
 			return "", runtime.NewError("failed to unmarshal unlockables request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -205,7 +204,7 @@ func rpcUnlockablesClaimJson(p *pamlogixImpl) func(ctx context.Context, logger r
 		}
 
 		// Convert to JSON
-		data, err := json.Marshal(reward)
+		data, err := marshalRpcJson(p, reward)
 		if err != nil {
 			logger.Error("Failed to marshal unlockables reward to JSON: %v", err)
 			return "", runtime.NewError("failed to marshal unlockables reward", INTERNAL_ERROR_CODE) // INTERNAL
@@ -230,7 +229,7 @@ func rpcUnlockablesQueueAddJson(p *pamlogixImpl) func(ctx context.Context, logge
 		var request struct {
 			InstanceIds []string `json:"instanceIds"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal UnlockablesQueueAddRequest from JSON: %v", err)
 			return "", runtime.NewError("failed to unmarshal unlockables queue add request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -245,7 +244,7 @@ func rpcUnlockablesQueueAddJson(p *pamlogixImpl) func(ctx context.Context, logge
 		}
 
 		// Convert to JSON
-		data, err := json.Marshal(unlockables)
+		data, err := marshalRpcJson(p, newTimedUnlockablesList(unlockables))
 		if err != nil {
 			logger.Error("Failed to marshal unlockables to JSON: %v", err)
 			return "", runtime.NewError("failed to marshal unlockables", INTERNAL_ERROR_CODE) // INTERNAL
@@ -270,7 +269,7 @@ func rpcUnlockablesQueueRemoveJson(p *pamlogixImpl) func(ctx context.Context, lo
 		var request struct {
 			InstanceIds []string `json:"instanceIds"`
 		}
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal UnlockablesQueueRemoveRequest from JSON: %v", err)
 			return "", runtime.NewError("failed to unmarshal unlockables queue remove request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -285,7 +284,7 @@ func rpcUnlockablesQueueRemoveJson(p *pamlogixImpl) func(ctx context.Context, lo
 		}
 
 		// Convert to JSON
-		data, err := json.Marshal(unlockables)
+		data, err := marshalRpcJson(p, newTimedUnlockablesList(unlockables))
 		if err != nil {
 			logger.Error("Failed to marshal unlockables to JSON: %v", err)
 			return "", runtime.NewError("failed to marshal unlockables", INTERNAL_ERROR_CODE) // INTERNAL
@@ -308,7 +307,7 @@ func rpcUnlockablesQueueSetJson(p *pamlogixImpl) func(ctx context.Context, logge
 		}
 
 		var request UnlockablesQueueSetRequest
-		if err := json.Unmarshal([]byte(payload), &request); err != nil {
+		if err := unmarshalRpcJson(p, payload, &request); err != nil {
 			logger.Error("Failed to unmarshal UnlockablesQueueSetRequest from JSON: %v", err)
 			return "", runtime.NewError("failed to unmarshal unlockables queue set request", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
 		}
@@ -319,7 +318,7 @@ func rpcUnlockablesQueueSetJson(p *pamlogixImpl) func(ctx context.Context, logge
 		}
 
 		// Convert to JSON
-		data, err := json.Marshal(unlockables)
+		data, err := marshalRpcJson(p, newTimedUnlockablesList(unlockables))
 		if err != nil {
 			logger.Error("Failed to marshal unlockables to JSON: %v", err)
 			return "", runtime.NewError("failed to marshal unlockables", INTERNAL_ERROR_CODE) // INTERNAL
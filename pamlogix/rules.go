@@ -0,0 +1,693 @@
+package pamlogix
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// maxRuleLength caps rule expressions, which are compiled once and cached for as long as the server runs.
+const maxRuleLength = 2048
+
+// Rule is a compiled condition on a user's state, such as `stats.wins >= 10 && progression.has("chapter2")`, so live
+// ops can gate content in config rather than in Go code. Rules combine values with `&&`, `||`, `!`, the comparisons
+// `==`, `!=`, `<`, `<=`, `>`, `>=`, the arithmetic `+`, `-`, `*`, `/` and parentheses. They can read:
+//
+//   - stats.<name>: the value of the user's public or private stat, or 0 if they have none.
+//   - wallet.<currency>: the user's balance of the currency.
+//   - now: the current Unix time in seconds.
+//   - progression.has(id): whether the user has unlocked the progression.
+//   - achievements.completed(id): whether the user has completed or claimed the achievement.
+//   - inventory.count(id): how many of the item the user holds across all its instances.
+//
+// Strings are quoted with either double or single quotes.
+//
+// Store item conditions and achievement auto claims evaluate rules. Personalizers don't, as they adjust configs when
+// systems load rather than per user, and there is no offer targeting to evaluate them in.
+type Rule struct {
+	expression string
+	root       ruleNode
+	needs      ruleNeeds
+}
+
+// RuleEnv is the user state rules are evaluated against. State the rules don't read is left unloaded.
+type RuleEnv struct {
+	NowSec       int64
+	Stats        *StatList
+	Wallet       map[string]int64
+	Progressions map[string]*Progression
+	Achievements map[string]*Achievement
+	Inventory    *Inventory
+}
+
+// ruleNeeds are the parts of the user state a rule reads.
+type ruleNeeds struct {
+	stats        bool
+	wallet       bool
+	progressions bool
+	achievements bool
+	inventory    bool
+}
+
+func (n *ruleNeeds) add(other ruleNeeds) {
+	n.stats = n.stats || other.stats
+	n.wallet = n.wallet || other.wallet
+	n.progressions = n.progressions || other.progressions
+	n.achievements = n.achievements || other.achievements
+	n.inventory = n.inventory || other.inventory
+}
+
+// CompileRule parses the expression into a rule, or returns an INVALID_ARGUMENT error describing what's wrong with it.
+func CompileRule(expression string) (*Rule, error) {
+	if len(expression) > maxRuleLength {
+		return nil, runtime.NewError(fmt.Sprintf("rule is longer than %d characters", maxRuleLength), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+	tokens, err := lexRule(expression)
+	if err != nil {
+		return nil, ruleError(expression, err.Error())
+	}
+	parser := &ruleParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, ruleError(expression, err.Error())
+	}
+	if token := parser.peek(); token.kind != ruleTokenEnd {
+		return nil, ruleError(expression, fmt.Sprintf("unexpected %q at offset %d", token.text, token.offset))
+	}
+	return &Rule{expression: expression, root: root, needs: parser.needs}, nil
+}
+
+func ruleError(expression, reason string) error {
+	return runtime.NewError(fmt.Sprintf("invalid rule %q: %s", expression, reason), INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+}
+
+// String returns the rule's expression.
+func (r *Rule) String() string {
+	return r.expression
+}
+
+// Eval reports whether the rule holds for the user state. Rules which don't yield true or false, or which compare
+// values of different types, return an error.
+func (r *Rule) Eval(env *RuleEnv) (bool, error) {
+	value, err := r.root.eval(env)
+	if err != nil {
+		return false, fmt.Errorf("rule %q: %w", r.expression, err)
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("rule %q yields %v, not true or false", r.expression, value)
+	}
+	return result, nil
+}
+
+var compiledRules sync.Map
+
+// compiledRule returns the rule for the expression, compiling it the first time it's used. Rules come from config, so
+// there are few of them and each is evaluated often.
+func compiledRule(expression string) (*Rule, error) {
+	if rule, found := compiledRules.Load(expression); found {
+		return rule.(*Rule), nil
+	}
+	rule, err := CompileRule(expression)
+	if err != nil {
+		return nil, err
+	}
+	compiledRules.Store(expression, rule)
+	return rule, nil
+}
+
+// LoadRuleEnv reads the user state the rules read, from the systems which are loaded. State kept by a system which
+// isn't loaded is left empty.
+func LoadRuleEnv(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID string, rules ...*Rule) (*RuleEnv, error) {
+	var needs ruleNeeds
+	for _, rule := range rules {
+		if rule != nil {
+			needs.add(rule.needs)
+		}
+	}
+	return loadRuleEnv(ctx, logger, nk, pl, userID, needs)
+}
+
+func loadRuleEnv(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID string, needs ruleNeeds) (*RuleEnv, error) {
+	env := &RuleEnv{NowSec: time.Now().Unix()}
+
+	var err error
+	if needs.wallet {
+		if env.Wallet, err = userWallet(ctx, nk, userID); err != nil {
+			return nil, err
+		}
+	}
+	if pl == nil {
+		return env, nil
+	}
+	if progressionSystem := pl.GetProgressionSystem(); needs.progressions && progressionSystem != nil {
+		if env.Progressions, _, err = progressionSystem.Get(ctx, logger, nk, userID, nil); err != nil {
+			return nil, err
+		}
+	}
+	if achievementsSystem := pl.GetAchievementsSystem(); needs.achievements && achievementsSystem != nil {
+		if env.Achievements, _, err = achievementsSystem.GetAchievements(ctx, logger, nk, userID); err != nil {
+			return nil, err
+		}
+	}
+	if statsSystem := pl.GetStatsSystem(); needs.stats && statsSystem != nil {
+		userStats, err := statsSystem.List(ctx, logger, nk, userID, []string{userID})
+		if err != nil {
+			return nil, err
+		}
+		env.Stats = userStats[userID]
+	}
+	if inventorySystem := pl.GetInventorySystem(); needs.inventory && inventorySystem != nil {
+		if env.Inventory, err = inventorySystem.ListInventoryItems(ctx, logger, nk, userID, ""); err != nil {
+			return nil, err
+		}
+	}
+	return env, nil
+}
+
+// validateStoreItemRules compiles the rules of the store items' conditions, so a rule which doesn't compile fails the
+// config rather than hiding the item from every user.
+func validateStoreItemRules(config *EconomyConfig) error {
+	for storeItemID, storeItem := range config.StoreItems {
+		if storeItem == nil || storeItem.Conditions == nil || storeItem.Conditions.Rule == "" {
+			continue
+		}
+		if _, err := CompileRule(storeItem.Conditions.Rule); err != nil {
+			return fmt.Errorf("conditions of store item %s: %w", storeItemID, err)
+		}
+	}
+	return nil
+}
+
+// validateAutoClaimRules compiles the achievements' auto claim rules, so a rule which doesn't compile fails the config
+// rather than leaving the achievement to be claimed by hand.
+func validateAutoClaimRules(config *AchievementsConfig) error {
+	for achievementID, achievement := range config.Achievements {
+		if achievement == nil || achievement.AutoClaimRule == "" {
+			continue
+		}
+		if _, err := CompileRule(achievement.AutoClaimRule); err != nil {
+			return fmt.Errorf("auto claim rule of achievement %s: %w", achievementID, err)
+		}
+	}
+	return nil
+}
+
+// evalRule reports whether the expression holds for the user state, treating expressions which fail to compile or
+// evaluate as not holding.
+func evalRule(logger runtime.Logger, expression string, env *RuleEnv) bool {
+	rule, err := compiledRule(expression)
+	if err != nil {
+		logger.Error("Failed to compile rule: %v", err)
+		return false
+	}
+	result, err := rule.Eval(env)
+	if err != nil {
+		logger.Warn("Failed to evaluate rule: %v", err)
+		return false
+	}
+	return result
+}
+
+// achievementCompleted reports whether the user has claimed the achievement or reached its max count.
+func achievementCompleted(achievement *Achievement) bool {
+	return achievement.ClaimTimeSec > 0 || (achievement.MaxCount > 0 && achievement.Count >= achievement.MaxCount)
+}
+
+type ruleTokenKind int
+
+const (
+	ruleTokenEnd ruleTokenKind = iota
+	ruleTokenNumber
+	ruleTokenString
+	ruleTokenIdent
+	ruleTokenOperator
+)
+
+type ruleToken struct {
+	kind   ruleTokenKind
+	text   string
+	offset int
+}
+
+// ruleOperators are the operators and punctuation of rules, longest first so "<=" isn't read as "<".
+var ruleOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")", ".", ","}
+
+func lexRule(expression string) ([]ruleToken, error) {
+	tokens := make([]ruleToken, 0)
+	for offset := 0; offset < len(expression); {
+		c := rune(expression[offset])
+		switch {
+		case unicode.IsSpace(c):
+			offset++
+		case c >= '0' && c <= '9':
+			end := offset
+			for end < len(expression) && (expression[end] >= '0' && expression[end] <= '9' || expression[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenNumber, text: expression[offset:end], offset: offset})
+			offset = end
+		case c == '"' || c == '\'':
+			closing := strings.IndexByte(expression[offset+1:], byte(c))
+			if closing < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", offset)
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenString, text: expression[offset+1 : offset+1+closing], offset: offset})
+			offset += closing + 2
+		case c == '_' || unicode.IsLetter(c):
+			end := offset
+			for end < len(expression) && (expression[end] == '_' || unicode.IsLetter(rune(expression[end])) || unicode.IsDigit(rune(expression[end]))) {
+				end++
+			}
+			tokens = append(tokens, ruleToken{kind: ruleTokenIdent, text: expression[offset:end], offset: offset})
+			offset = end
+		default:
+			matched := false
+			for _, operator := range ruleOperators {
+				if strings.HasPrefix(expression[offset:], operator) {
+					tokens = append(tokens, ruleToken{kind: ruleTokenOperator, text: operator, offset: offset})
+					offset += len(operator)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, offset)
+			}
+		}
+	}
+	return append(tokens, ruleToken{kind: ruleTokenEnd, offset: len(expression)}), nil
+}
+
+type ruleParser struct {
+	tokens   []ruleToken
+	position int
+	needs    ruleNeeds
+}
+
+func (p *ruleParser) peek() ruleToken {
+	return p.tokens[p.position]
+}
+
+func (p *ruleParser) next() ruleToken {
+	token := p.tokens[p.position]
+	if token.kind != ruleTokenEnd {
+		p.position++
+	}
+	return token
+}
+
+// accept consumes the next token if it's one of the operators.
+func (p *ruleParser) accept(operators ...string) (string, bool) {
+	token := p.peek()
+	if token.kind != ruleTokenOperator {
+		return "", false
+	}
+	for _, operator := range operators {
+		if token.text == operator {
+			p.position++
+			return operator, true
+		}
+	}
+	return "", false
+}
+
+func (p *ruleParser) expect(operator string) error {
+	if _, ok := p.accept(operator); !ok {
+		token := p.peek()
+		if token.kind == ruleTokenEnd {
+			return fmt.Errorf("expected %q at the end", operator)
+		}
+		return fmt.Errorf("expected %q at offset %d, not %q", operator, token.offset, token.text)
+	}
+	return nil
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleBinary{operator: "||", left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleBinary{operator: "&&", left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseComparison() (ruleNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	operator, ok := p.accept("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return &ruleBinary{operator: operator, left: left, right: right}, nil
+}
+
+func (p *ruleParser) parseAdditive() (ruleNode, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleBinary{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseMultiplicative() (ruleNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		operator, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleBinary{operator: operator, left: left, right: right}
+	}
+}
+
+func (p *ruleParser) parseUnary() (ruleNode, error) {
+	if operator, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &ruleUnary{operator: operator, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *ruleParser) parsePrimary() (ruleNode, error) {
+	token := p.next()
+	switch token.kind {
+	case ruleTokenNumber:
+		number, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", token.text, token.offset)
+		}
+		return &ruleLiteral{value: number}, nil
+	case ruleTokenString:
+		return &ruleLiteral{value: token.text}, nil
+	case ruleTokenIdent:
+		return p.parseName(token)
+	case ruleTokenOperator:
+		if token.text == "(" {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return node, nil
+		}
+		return nil, fmt.Errorf("unexpected %q at offset %d", token.text, token.offset)
+	default:
+		return nil, fmt.Errorf("unexpected end of rule")
+	}
+}
+
+// parseName reads a literal, a value of the user state or a function call starting with the identifier.
+func (p *ruleParser) parseName(token ruleToken) (ruleNode, error) {
+	switch token.text {
+	case "true":
+		return &ruleLiteral{value: true}, nil
+	case "false":
+		return &ruleLiteral{value: false}, nil
+	case "now":
+		return &ruleNow{}, nil
+	}
+
+	if err := p.expect("."); err != nil {
+		return nil, fmt.Errorf("unknown name %q at offset %d", token.text, token.offset)
+	}
+	member := p.next()
+	if member.kind != ruleTokenIdent {
+		return nil, fmt.Errorf("expected a name after %q at offset %d", token.text+".", member.offset)
+	}
+
+	switch token.text {
+	case "stats":
+		p.needs.stats = true
+		return &ruleStat{name: member.text}, nil
+	case "wallet":
+		p.needs.wallet = true
+		return &ruleWallet{currency: member.text}, nil
+	}
+
+	function := token.text + "." + member.text
+	switch function {
+	case "progression.has":
+		p.needs.progressions = true
+	case "achievements.completed":
+		p.needs.achievements = true
+	case "inventory.count":
+		p.needs.inventory = true
+	default:
+		return nil, fmt.Errorf("unknown name %q at offset %d", function, token.offset)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	argument, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return &ruleCall{function: function, argument: argument}, nil
+}
+
+// ruleNode is a node of a compiled rule, yielding a float64, a string or a bool.
+type ruleNode interface {
+	eval(env *RuleEnv) (any, error)
+}
+
+type ruleLiteral struct {
+	value any
+}
+
+func (n *ruleLiteral) eval(*RuleEnv) (any, error) {
+	return n.value, nil
+}
+
+type ruleNow struct{}
+
+func (n *ruleNow) eval(env *RuleEnv) (any, error) {
+	return float64(env.NowSec), nil
+}
+
+type ruleStat struct {
+	name string
+}
+
+func (n *ruleStat) eval(env *RuleEnv) (any, error) {
+	stat, found := env.Stats.GetPublic()[n.name]
+	if !found {
+		stat = env.Stats.GetPrivate()[n.name]
+	}
+	return float64(stat.GetValue()), nil
+}
+
+type ruleWallet struct {
+	currency string
+}
+
+func (n *ruleWallet) eval(env *RuleEnv) (any, error) {
+	return float64(env.Wallet[n.currency]), nil
+}
+
+type ruleCall struct {
+	function string
+	argument ruleNode
+}
+
+func (n *ruleCall) eval(env *RuleEnv) (any, error) {
+	value, err := n.argument.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s needs a string, not %v", n.function, value)
+	}
+
+	switch n.function {
+	case "progression.has":
+		progression, found := env.Progressions[id]
+		return found && progression.Unlocked, nil
+	case "achievements.completed":
+		achievement, found := env.Achievements[id]
+		return found && achievementCompleted(achievement), nil
+	default:
+		count := int64(0)
+		for _, item := range env.Inventory.GetItems() {
+			if item.Id == id {
+				count += item.Count
+			}
+		}
+		return float64(count), nil
+	}
+}
+
+type ruleUnary struct {
+	operator string
+	operand  ruleNode
+}
+
+func (n *ruleUnary) eval(env *RuleEnv) (any, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch operand := value.(type) {
+	case bool:
+		if n.operator == "!" {
+			return !operand, nil
+		}
+	case float64:
+		if n.operator == "-" {
+			return -operand, nil
+		}
+	}
+	return nil, fmt.Errorf("can't apply %q to %v", n.operator, value)
+}
+
+type ruleBinary struct {
+	operator string
+	left     ruleNode
+	right    ruleNode
+}
+
+func (n *ruleBinary) eval(env *RuleEnv) (any, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators only evaluate their right side when it decides the result
+	if n.operator == "&&" || n.operator == "||" {
+		leftBool, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%q needs true or false, not %v", n.operator, left)
+		}
+		if leftBool == (n.operator == "||") {
+			return leftBool, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		rightBool, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%q needs true or false, not %v", n.operator, right)
+		}
+		return rightBool, nil
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.operator {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+
+	switch leftValue := left.(type) {
+	case float64:
+		rightValue, ok := right.(float64)
+		if !ok {
+			break
+		}
+		switch n.operator {
+		case "<":
+			return leftValue < rightValue, nil
+		case "<=":
+			return leftValue <= rightValue, nil
+		case ">":
+			return leftValue > rightValue, nil
+		case ">=":
+			return leftValue >= rightValue, nil
+		case "+":
+			return leftValue + rightValue, nil
+		case "-":
+			return leftValue - rightValue, nil
+		case "*":
+			return leftValue * rightValue, nil
+		case "/":
+			if rightValue == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return leftValue / rightValue, nil
+		}
+	case string:
+		rightValue, ok := right.(string)
+		if !ok {
+			break
+		}
+		switch n.operator {
+		case "<":
+			return leftValue < rightValue, nil
+		case "<=":
+			return leftValue <= rightValue, nil
+		case ">":
+			return leftValue > rightValue, nil
+		case ">=":
+			return leftValue >= rightValue, nil
+		}
+	}
+	return nil, fmt.Errorf("can't apply %q to %v and %v", n.operator, left, right)
+}
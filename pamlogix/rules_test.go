@@ -0,0 +1,114 @@
+package pamlogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRule_Eval(t *testing.T) {
+	env := &RuleEnv{
+		NowSec: 1000,
+		Stats: &StatList{
+			Public:  map[string]*Stat{"wins": {Name: "wins", Value: 12}},
+			Private: map[string]*Stat{"losses": {Name: "losses", Value: 3}},
+		},
+		Wallet:       map[string]int64{"coins": 250},
+		Progressions: map[string]*Progression{"chapter1": {Unlocked: true}, "chapter2": {}},
+		Achievements: map[string]*Achievement{"first_win": {Count: 1, MaxCount: 1}, "hundred_wins": {Count: 12, MaxCount: 100}},
+		Inventory: &Inventory{Items: map[string]*InventoryItem{
+			"a": {Id: "potion", Count: 2},
+			"b": {Id: "potion", Count: 3},
+		}},
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`stats.wins >= 10 && progression.has("chapter1")`, true},
+		{`stats.wins >= 10 && progression.has('chapter2')`, false},
+		{`stats.losses == 3 && stats.unknown == 0`, true},
+		{`stats.wins / (stats.wins + stats.losses) > 0.75`, true},
+		{`wallet.coins - 50 >= 200 || false`, true},
+		{`!achievements.completed("hundred_wins") && achievements.completed("first_win")`, true},
+		{`inventory.count("potion") == 5 && inventory.count("sword") < 1`, true},
+		{`now > 999 && -stats.wins < 0`, true},
+		{`"gold" != 'silver'`, true},
+		// The right side isn't evaluated once the left side decides the result
+		{`false && 1 / 0 > 0`, false},
+		{`true || stats.wins`, true},
+	}
+	for _, test := range tests {
+		rule, err := CompileRule(test.expression)
+		require.NoError(t, err, test.expression)
+		result, err := rule.Eval(env)
+		require.NoError(t, err, test.expression)
+		assert.Equal(t, test.want, result, test.expression)
+	}
+}
+
+func TestRule_Errors(t *testing.T) {
+	for _, expression := range []string{``, `stats.wins >=`, `stats.wins > 1)`, `(true`, `level > 1`, `progression.unlocked("chapter1")`, `"open`, `stats.wins # 1`} {
+		_, err := CompileRule(expression)
+		var runtimeErr *runtime.Error
+		require.ErrorAs(t, err, &runtimeErr, expression)
+		assert.Equal(t, INVALID_ARGUMENT_ERROR_CODE, runtimeErr.Code, expression)
+	}
+
+	for _, expression := range []string{`stats.wins`, `stats.wins > "ten"`, `1 / 0 > 0`, `!stats.wins`, `progression.has(1)`} {
+		rule, err := CompileRule(expression)
+		require.NoError(t, err, expression)
+		_, err = rule.Eval(&RuleEnv{})
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestLoadRuleEnv_OnlyLoadsNeededState(t *testing.T) {
+	ctx := context.Background()
+	nk := newBenchNakama()
+	_, _, err := nk.WalletUpdate(ctx, "user1", map[string]int64{benchCurrency: 40}, nil, false)
+	require.NoError(t, err)
+	_, _, _, _, err = newBenchPamlogix().GetInventorySystem().GrantItems(ctx, &mockLogger{}, nk, "user1", map[string]int64{"potion": 2}, false)
+	require.NoError(t, err)
+
+	rule, err := CompileRule(`wallet.` + benchCurrency + ` >= 40`)
+	require.NoError(t, err)
+	env, err := LoadRuleEnv(ctx, &mockLogger{}, nk, newBenchPamlogix(), "user1", rule)
+	require.NoError(t, err)
+	assert.Equal(t, int64(40), env.Wallet[benchCurrency])
+	assert.Nil(t, env.Inventory)
+	result, err := rule.Eval(env)
+	require.NoError(t, err)
+	assert.True(t, result)
+
+	rule, err = CompileRule(`inventory.count("potion") == 2`)
+	require.NoError(t, err)
+	env, err = LoadRuleEnv(ctx, &mockLogger{}, nk, newBenchPamlogix(), "user1", rule)
+	require.NoError(t, err)
+	assert.Nil(t, env.Wallet)
+	result, err = rule.Eval(env)
+	require.NoError(t, err)
+	assert.True(t, result)
+}
+
+func TestValidateRules(t *testing.T) {
+	economyConfig := &EconomyConfig{StoreItems: map[string]*EconomyConfigStoreItem{
+		"pack":  {Conditions: &EconomyConfigStoreItemConditions{Rule: `stats.wins >= 10`}},
+		"plain": {},
+	}}
+	assert.NoError(t, validateStoreItemRules(economyConfig))
+	economyConfig.StoreItems["broken"] = &EconomyConfigStoreItem{Conditions: &EconomyConfigStoreItemConditions{Rule: `stats.wins >=`}}
+	assert.ErrorContains(t, validateStoreItemRules(economyConfig), "store item broken")
+
+	achievementsConfig := &AchievementsConfig{Achievements: map[string]*AchievementsConfigAchievement{
+		"veteran": {AutoClaimRule: `progression.has("chapter2")`},
+		"plain":   {},
+	}}
+	assert.NoError(t, validateAutoClaimRules(achievementsConfig))
+	achievementsConfig.Achievements["broken"] = &AchievementsConfigAchievement{AutoClaimRule: `progression.has(`}
+	assert.ErrorContains(t, validateAutoClaimRules(achievementsConfig), "achievement broken")
+}
@@ -11,6 +11,8 @@ type StatsConfig struct {
 	Whitelist    []string                    `json:"whitelist,omitempty"`
 	StatsPublic  map[string]*StatsConfigStat `json:"stats_public,omitempty"`
 	StatsPrivate map[string]*StatsConfigStat `json:"stats_private,omitempty"`
+	// EventStats are stats kept up to date from the activity of other systems, by stat name.
+	EventStats map[string]*StatsConfigEventStat `json:"event_stats,omitempty"`
 }
 
 type StatsConfigStat struct {
@@ -18,6 +20,18 @@ type StatsConfigStat struct {
 	AdditionalProperties map[string]interface{} `json:"additional_properties,omitempty"`
 }
 
+// StatsConfigEventStat increments a stat each time a publisher event with the given name is sent for the user. The
+// systems send auction_won, donation_given, event_leaderboard_claimed and energy_spent, and game code can send its own.
+type StatsConfigEventStat struct {
+	EventName string `json:"event_name,omitempty"`
+	// EventId limits the stat to events for one source, e.g. an energy ID. Empty matches any.
+	EventId string `json:"event_id,omitempty"`
+	// Public updates the user's public stat rather than their private one.
+	Public bool `json:"public,omitempty"`
+	// AddValue increments the stat by the event's value, e.g. the energy spent, rather than by one.
+	AddValue bool `json:"add_value,omitempty"`
+}
+
 type StatsSystem interface {
 	System
 
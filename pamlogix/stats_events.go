@@ -0,0 +1,88 @@
+package pamlogix
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// StatsEventsPublisher listens for publisher events sent by other systems and increments the stats configured to
+// follow them, so common stats such as auctions won are kept without game code updating them.
+type StatsEventsPublisher struct {
+	Stats *NakamaStatsSystem
+}
+
+func (p *StatsEventsPublisher) Authenticate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, created bool) {
+	// No-op
+}
+
+func (p *StatsEventsPublisher) Send(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, events []*PublisherEvent) {
+	if p.Stats == nil {
+		return
+	}
+	if err := p.Stats.updateEventStats(ctx, logger, nk, userID, events); err != nil {
+		logger.Error("Failed to update event stats for user %s: %v", userID, err)
+	}
+}
+
+// hasEventStats reports whether any stat follows publisher events, so events aren't matched needlessly.
+func (s *NakamaStatsSystem) hasEventStats() bool {
+	return s.config != nil && len(s.config.EventStats) > 0
+}
+
+// updateEventStats increments the stats which follow the events in a single update. Events matching no stat leave the
+// user's stats untouched.
+func (s *NakamaStatsSystem) updateEventStats(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, events []*PublisherEvent) error {
+	if len(events) == 0 || !s.hasEventStats() {
+		return nil
+	}
+
+	var publicStats, privateStats []*StatUpdate
+	for statName, eventStat := range s.config.EventStats {
+		if eventStat == nil {
+			continue
+		}
+		var delta int64
+		for _, event := range events {
+			if eventStat.matches(event) {
+				delta += eventStat.increment(event)
+			}
+		}
+		if delta == 0 {
+			continue
+		}
+		update := &StatUpdate{Name: statName, Value: delta, Operator: StatUpdateOperator_STAT_UPDATE_OPERATOR_DELTA}
+		if eventStat.Public {
+			publicStats = append(publicStats, update)
+		} else {
+			privateStats = append(privateStats, update)
+		}
+	}
+	if len(publicStats) == 0 && len(privateStats) == 0 {
+		return nil
+	}
+
+	_, err := s.Update(ctx, logger, nk, userID, publicStats, privateStats)
+	return err
+}
+
+// matches reports whether the event counts towards the stat.
+func (c *StatsConfigEventStat) matches(event *PublisherEvent) bool {
+	if event == nil || c.EventName == "" || event.Name != c.EventName {
+		return false
+	}
+	return c.EventId == "" || event.Id == c.EventId
+}
+
+// increment is how much the event adds to the stat. Events without a numeric value add nothing when the value is used.
+func (c *StatsConfigEventStat) increment(event *PublisherEvent) int64 {
+	if !c.AddValue {
+		return 1
+	}
+	value, err := strconv.ParseInt(event.Value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
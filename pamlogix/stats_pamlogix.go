@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
@@ -76,7 +77,11 @@ func (s *NakamaStatsSystem) List(ctx context.Context, logger runtime.Logger, nk
 	}
 
 	// Perform single batched storage read
-	objects, err := nk.StorageRead(ctx, storageReads)
+	var objects []*api.StorageObject
+	err := retryNakama(ctx, idempotentCall, func() (err error) {
+		objects, err = nk.StorageRead(ctx, storageReads)
+		return err
+	})
 	if err != nil {
 		logger.Error("Failed to batch read user stats: %v", err)
 		return nil, err
@@ -124,10 +129,14 @@ func (s *NakamaStatsSystem) Update(ctx context.Context, logger runtime.Logger, n
 		return nil, err
 	}
 	if stats == nil {
-		stats = &StatList{
-			Public:  make(map[string]*Stat),
-			Private: make(map[string]*Stat),
-		}
+		stats = &StatList{}
+	}
+	// Empty maps aren't stored, so a user with only private stats reads back without public ones
+	if stats.Public == nil {
+		stats.Public = make(map[string]*Stat)
+	}
+	if stats.Private == nil {
+		stats.Private = make(map[string]*Stat)
 	}
 	now := time.Now().Unix()
 	// Helper to apply a StatUpdate to a Stat
@@ -240,15 +249,18 @@ func (s *NakamaStatsSystem) saveUserStats(ctx context.Context, logger runtime.Lo
 		logger.Error("Failed to marshal user stats: %v", err)
 		return err
 	}
-	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
-		{
-			Collection:      statsStorageCollection,
-			Key:             userStatsStorageKey,
-			UserID:          userID,
-			Value:           string(data),
-			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
-			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
-		},
+	err = retryNakama(ctx, idempotentCall, func() error {
+		_, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+			{
+				Collection:      statsStorageCollection,
+				Key:             userStatsStorageKey,
+				UserID:          userID,
+				Value:           string(data),
+				PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
+				PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
+			},
+		})
+		return err
 	})
 	if err != nil {
 		logger.Error("Failed to write user stats: %v", err)
@@ -265,3 +265,48 @@ func TestRpcStatsGetAndUpdate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, updatedStatsList, fetchedStatsList)
 }
+
+func TestStatsEventsPublisher(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+
+	stats := NewStatsSystem(&StatsConfig{
+		EventStats: map[string]*StatsConfigEventStat{
+			"auctions_won":  {EventName: auctionWonEvent, Public: true},
+			"energy_spent":  {EventName: energySpentEvent, EventId: "lives", AddValue: true},
+			"events_played": {EventName: eventLeaderboardClaimedEvent},
+		},
+	})
+	energy := NewNakamaEnergySystem(&EnergyConfig{
+		Energies: map[string]*EnergyConfigEnergy{
+			"lives":   {StartCount: 5, MaxCount: 5},
+			"tickets": {StartCount: 5, MaxCount: 5},
+		},
+	})
+	p := &pamlogixImpl{systems: map[SystemType]System{SystemTypeStats: stats, SystemTypeEnergy: energy}}
+	stats.SetPamlogix(p)
+	energy.SetPamlogix(p)
+	p.AddPublisher(&StatsEventsPublisher{Stats: stats})
+
+	// Spending energy sends an event per energy, and only the matching energy counts, by the amount spent
+	_, _, err := energy.Spend(ctx, logger, nk, "user1", map[string]int32{"lives": 2, "tickets": 1})
+	if !assert.NoError(t, err) {
+		return
+	}
+	p.SendPublisherEvents(ctx, logger, nk, "user1", []*PublisherEvent{{Name: auctionWonEvent, Id: "auction1"}, {Name: auctionWonEvent, Id: "auction2"}})
+	p.SendPublisherEvents(ctx, logger, nk, "user1", []*PublisherEvent{{Name: "level_reached", Value: "3"}})
+
+	lists, err := stats.List(ctx, logger, nk, "user1", []string{"user1"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	userStats := lists["user1"]
+	if assert.Contains(t, userStats.Private, "energy_spent") {
+		assert.Equal(t, int64(2), userStats.Private["energy_spent"].Value)
+	}
+	if assert.Contains(t, userStats.Public, "auctions_won") {
+		assert.Equal(t, int64(2), userStats.Public["auctions_won"].Value)
+	}
+	assert.NotContains(t, userStats.Private, "events_played")
+}
@@ -0,0 +1,299 @@
+package pamlogix
+
+import (
+	"context"
+	"sync"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"google.golang.org/protobuf/proto"
+)
+
+type storageCacheCtxKey struct{}
+
+// storageCacheUncachedCollections are always read from storage. Lock objects are written by other requests while this
+// one waits on them, so a cached read would never see the lease expire.
+var storageCacheUncachedCollections = map[string]bool{
+	storageLockCollection: true,
+}
+
+type storageCacheObjectKey struct {
+	collection string
+	key        string
+}
+
+type storageCacheListKey struct {
+	callerID   string
+	collection string
+	limit      int
+	cursor     string
+}
+
+type storageCacheListPage struct {
+	objects    []*api.StorageObject
+	nextCursor string
+}
+
+// userStorageCache is what one user's storage looked like when it was last read in the request. A nil object records
+// that the object doesn't exist.
+type userStorageCache struct {
+	objects map[storageCacheObjectKey]*api.StorageObject
+	lists   map[storageCacheListKey]*storageCacheListPage
+}
+
+// storageCache holds the storage objects and list pages read while handling one RPC, keyed by the user owning them,
+// so systems touching the same user's state within a request don't read it again. Any write or delete to a user's
+// collection drops what was cached for it.
+type storageCache struct {
+	mu    sync.Mutex
+	users map[string]*userStorageCache
+}
+
+func newStorageCache() *storageCache {
+	return &storageCache{
+		users: make(map[string]*userStorageCache),
+	}
+}
+
+func withStorageCache(ctx context.Context, cache *storageCache) context.Context {
+	return context.WithValue(ctx, storageCacheCtxKey{}, cache)
+}
+
+func storageCacheFromContext(ctx context.Context) *storageCache {
+	cache, _ := ctx.Value(storageCacheCtxKey{}).(*storageCache)
+	return cache
+}
+
+func (c *storageCache) user(userID string) *userStorageCache {
+	userCache, found := c.users[userID]
+	if !found {
+		userCache = &userStorageCache{
+			objects: make(map[storageCacheObjectKey]*api.StorageObject),
+			lists:   make(map[storageCacheListKey]*storageCacheListPage),
+		}
+		c.users[userID] = userCache
+	}
+	return userCache
+}
+
+// getObject returns the cached object and whether the read is cached at all; a cached read of a missing object
+// returns nil and true.
+func (c *storageCache) getObject(read *runtime.StorageRead) (*api.StorageObject, bool) {
+	if c == nil || storageCacheUncachedCollections[read.Collection] {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	userCache, found := c.users[read.UserID]
+	if !found {
+		return nil, false
+	}
+	object, found := userCache.objects[storageCacheObjectKey{collection: read.Collection, key: read.Key}]
+	if !found {
+		return nil, false
+	}
+	return copyStorageObject(object), true
+}
+
+func (c *storageCache) setObject(read *runtime.StorageRead, object *api.StorageObject) {
+	if c == nil || storageCacheUncachedCollections[read.Collection] {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.user(read.UserID).objects[storageCacheObjectKey{collection: read.Collection, key: read.Key}] = copyStorageObject(object)
+}
+
+func (c *storageCache) getList(userID string, key storageCacheListKey) ([]*api.StorageObject, string, bool) {
+	if c == nil || storageCacheUncachedCollections[key.collection] {
+		return nil, "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	userCache, found := c.users[userID]
+	if !found {
+		return nil, "", false
+	}
+	page, found := userCache.lists[key]
+	if !found {
+		return nil, "", false
+	}
+	return copyStorageObjects(page.objects), page.nextCursor, true
+}
+
+func (c *storageCache) setList(userID string, key storageCacheListKey, objects []*api.StorageObject, nextCursor string) {
+	if c == nil || storageCacheUncachedCollections[key.collection] {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.user(userID).lists[key] = &storageCacheListPage{
+		objects:    copyStorageObjects(objects),
+		nextCursor: nextCursor,
+	}
+}
+
+// invalidate drops the user's cached objects and list pages in the collection, along with list pages over the whole
+// collection since they may include the user's objects.
+func (c *storageCache) invalidate(userID, collection string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, ownerID := range []string{userID, ""} {
+		userCache, found := c.users[ownerID]
+		if !found {
+			continue
+		}
+		for key := range userCache.lists {
+			if key.collection == collection {
+				delete(userCache.lists, key)
+			}
+		}
+		if ownerID != userID {
+			continue
+		}
+		for key := range userCache.objects {
+			if key.collection == collection {
+				delete(userCache.objects, key)
+			}
+		}
+	}
+}
+
+// reset drops everything cached, for when other requests may have changed any of it.
+func (c *storageCache) reset() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.users = make(map[string]*userStorageCache)
+}
+
+func copyStorageObject(object *api.StorageObject) *api.StorageObject {
+	if object == nil {
+		return nil
+	}
+	return proto.Clone(object).(*api.StorageObject)
+}
+
+func copyStorageObjects(objects []*api.StorageObject) []*api.StorageObject {
+	copied := make([]*api.StorageObject, 0, len(objects))
+	for _, object := range objects {
+		copied = append(copied, copyStorageObject(object))
+	}
+	return copied
+}
+
+// storageCacheNakama serves storage reads and lists from the request's storage cache and keeps it current as storage
+// is written and deleted.
+type storageCacheNakama struct {
+	runtime.NakamaModule
+}
+
+func (n *storageCacheNakama) StorageRead(ctx context.Context, reads []*runtime.StorageRead) ([]*api.StorageObject, error) {
+	cache := storageCacheFromContext(ctx)
+	if cache == nil {
+		return n.NakamaModule.StorageRead(ctx, reads)
+	}
+
+	objects := make([]*api.StorageObject, 0, len(reads))
+	var misses []*runtime.StorageRead
+	for _, read := range reads {
+		object, found := cache.getObject(read)
+		if !found {
+			misses = append(misses, read)
+			continue
+		}
+		if object != nil {
+			objects = append(objects, object)
+		}
+	}
+	if len(misses) == 0 {
+		return objects, nil
+	}
+
+	read, err := n.NakamaModule.StorageRead(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	for _, miss := range misses {
+		var found *api.StorageObject
+		for _, object := range read {
+			if object.Collection == miss.Collection && object.Key == miss.Key && object.UserId == miss.UserID {
+				found = object
+				break
+			}
+		}
+		cache.setObject(miss, found)
+	}
+	return append(objects, read...), nil
+}
+
+func (n *storageCacheNakama) StorageList(ctx context.Context, callerID, userID, collection string, limit int, cursor string) ([]*api.StorageObject, string, error) {
+	cache := storageCacheFromContext(ctx)
+	key := storageCacheListKey{callerID: callerID, collection: collection, limit: limit, cursor: cursor}
+	if objects, nextCursor, found := cache.getList(userID, key); found {
+		return objects, nextCursor, nil
+	}
+
+	objects, nextCursor, err := n.NakamaModule.StorageList(ctx, callerID, userID, collection, limit, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	cache.setList(userID, key, objects, nextCursor)
+	return objects, nextCursor, nil
+}
+
+func (n *storageCacheNakama) StorageWrite(ctx context.Context, writes []*runtime.StorageWrite) ([]*api.StorageObjectAck, error) {
+	acks, err := n.NakamaModule.StorageWrite(ctx, writes)
+	invalidateStorageWrites(ctx, writes)
+	return acks, err
+}
+
+func (n *storageCacheNakama) StorageDelete(ctx context.Context, deletes []*runtime.StorageDelete) error {
+	err := n.NakamaModule.StorageDelete(ctx, deletes)
+	invalidateStorageDeletes(ctx, deletes)
+	return err
+}
+
+func (n *storageCacheNakama) MultiUpdate(ctx context.Context, accountUpdates []*runtime.AccountUpdate, storageWrites []*runtime.StorageWrite, storageDeletes []*runtime.StorageDelete, walletUpdates []*runtime.WalletUpdate, updateLedger bool) ([]*api.StorageObjectAck, []*runtime.WalletUpdateResult, error) {
+	acks, results, err := n.NakamaModule.MultiUpdate(ctx, accountUpdates, storageWrites, storageDeletes, walletUpdates, updateLedger)
+	invalidateStorageWrites(ctx, storageWrites)
+	invalidateStorageDeletes(ctx, storageDeletes)
+	return acks, results, err
+}
+
+// invalidateStorageWrites drops what the writes may have changed, whether or not they succeeded. Taking a storage lock
+// drops everything, since the state it guards may have been changed by the previous holder.
+func invalidateStorageWrites(ctx context.Context, writes []*runtime.StorageWrite) {
+	cache := storageCacheFromContext(ctx)
+	for _, write := range writes {
+		if write.Collection == storageLockCollection {
+			cache.reset()
+			continue
+		}
+		cache.invalidate(write.UserID, write.Collection)
+	}
+}
+
+func invalidateStorageDeletes(ctx context.Context, deletes []*runtime.StorageDelete) {
+	cache := storageCacheFromContext(ctx)
+	for _, del := range deletes {
+		cache.invalidate(del.UserID, del.Collection)
+	}
+}
@@ -0,0 +1,118 @@
+package pamlogix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageCache_ReadsObjectOnce(t *testing.T) {
+	nk := NewMockNakama(t)
+	userID := "user1"
+	reads := []*runtime.StorageRead{
+		{Collection: "state", Key: "user_state", UserID: userID},
+		{Collection: "state", Key: "missing", UserID: userID},
+	}
+	nk.On("StorageRead", mock.Anything, reads).Return([]*api.StorageObject{
+		{Collection: "state", Key: "user_state", UserId: userID, Value: `{"a":1}`, Version: "v1"},
+	}, nil).Once()
+
+	cached := &storageCacheNakama{NakamaModule: nk}
+	ctx := withStorageCache(context.Background(), newStorageCache())
+
+	for i := 0; i < 3; i++ {
+		objects, err := cached.StorageRead(ctx, reads)
+		require.NoError(t, err)
+		require.Len(t, objects, 1)
+		assert.Equal(t, `{"a":1}`, objects[0].Value)
+		assert.Equal(t, "v1", objects[0].Version)
+	}
+
+	// Callers can't change the cached object through the returned one
+	objects, _ := cached.StorageRead(ctx, reads)
+	objects[0].Value = `{"a":2}`
+	objects, _ = cached.StorageRead(ctx, reads)
+	assert.Equal(t, `{"a":1}`, objects[0].Value)
+
+	nk.AssertExpectations(t)
+}
+
+func TestStorageCache_InvalidatedByWrite(t *testing.T) {
+	nk := NewMockNakama(t)
+	userID := "user1"
+	read := []*runtime.StorageRead{{Collection: "state", Key: "user_state", UserID: userID}}
+	nk.On("StorageRead", mock.Anything, read).Return([]*api.StorageObject{
+		{Collection: "state", Key: "user_state", UserId: userID, Value: `{}`},
+	}, nil).Twice()
+	nk.On("StorageList", mock.Anything, "", userID, "state", 100, "").Return([]*api.StorageObject{}, "", nil).Twice()
+	nk.On("StorageWrite", mock.Anything, mock.Anything).Return([]*api.StorageObjectAck{}, nil).Once()
+
+	cached := &storageCacheNakama{NakamaModule: nk}
+	ctx := withStorageCache(context.Background(), newStorageCache())
+
+	for i := 0; i < 2; i++ {
+		_, err := cached.StorageRead(ctx, read)
+		require.NoError(t, err)
+		_, _, err = cached.StorageList(ctx, "", userID, "state", 100, "")
+		require.NoError(t, err)
+	}
+
+	_, err := cached.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: "state", Key: "user_state", UserID: userID, Value: `{}`}})
+	require.NoError(t, err)
+
+	// Both the object and the list over its collection are read again after the write
+	_, err = cached.StorageRead(ctx, read)
+	require.NoError(t, err)
+	_, _, err = cached.StorageList(ctx, "", userID, "state", 100, "")
+	require.NoError(t, err)
+
+	nk.AssertExpectations(t)
+}
+
+func TestStorageCache_LocksAreNotCached(t *testing.T) {
+	nk := NewMockNakama(t)
+	userID := "user1"
+	stateRead := []*runtime.StorageRead{{Collection: "state", Key: "user_state", UserID: userID}}
+	lockRead := []*runtime.StorageRead{{Collection: storageLockCollection, Key: "lock"}}
+	nk.On("StorageRead", mock.Anything, stateRead).Return([]*api.StorageObject{}, nil).Twice()
+	nk.On("StorageRead", mock.Anything, lockRead).Return([]*api.StorageObject{}, nil).Twice()
+	nk.On("StorageWrite", mock.Anything, mock.Anything).Return([]*api.StorageObjectAck{{Version: "v1"}}, nil).Once()
+
+	cached := &storageCacheNakama{NakamaModule: nk}
+	ctx := withStorageCache(context.Background(), newStorageCache())
+
+	for i := 0; i < 2; i++ {
+		_, err := cached.StorageRead(ctx, lockRead)
+		require.NoError(t, err)
+	}
+
+	_, err := cached.StorageRead(ctx, stateRead)
+	require.NoError(t, err)
+
+	// State read before taking a lock may have been changed by the previous holder
+	_, err = writeStorageLease(ctx, cached, "lock", "owner", storageLockVersionNone)
+	require.NoError(t, err)
+	_, err = cached.StorageRead(ctx, stateRead)
+	require.NoError(t, err)
+
+	nk.AssertExpectations(t)
+}
+
+func TestStorageCache_WithoutCacheReadsStorage(t *testing.T) {
+	nk := NewMockNakama(t)
+	read := []*runtime.StorageRead{{Collection: "state", Key: "user_state", UserID: "user1"}}
+	nk.On("StorageRead", mock.Anything, read).Return([]*api.StorageObject{}, nil).Twice()
+
+	cached := &storageCacheNakama{NakamaModule: nk}
+	for i := 0; i < 2; i++ {
+		_, err := cached.StorageRead(context.Background(), read)
+		require.NoError(t, err)
+	}
+
+	nk.AssertExpectations(t)
+}
@@ -0,0 +1,146 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	storageLockCollection = "pamlogix_locks"
+
+	// A lease is released when the holder finishes, and expires after this long if the holder's node goes away.
+	storageLockLeaseSec    = 10
+	storageLockAttempts    = 40
+	storageLockRetryWait   = 25 * time.Millisecond
+	storageLockVersionNone = "*"
+)
+
+var ErrStorageLockBusy = runtime.NewError("resource is busy, try again", ABORTED_ERROR_CODE) // ABORTED
+
+// storageLease is the value of a lock object, identifying the holder and when the lease lapses.
+type storageLease struct {
+	Owner         string `json:"owner"`
+	ExpireTimeSec int64  `json:"expire_time_sec"`
+}
+
+// withStorageLock runs fn while holding the named lock. The lock is shared by all Nakama nodes using the same database,
+// so it protects read-modify-write cycles on hot storage objects that are not owned by a single user.
+func withStorageLock(ctx context.Context, nk runtime.NakamaModule, name string, fn func() error) error {
+	release, err := acquireStorageLocks(ctx, nk, name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return fn()
+}
+
+// acquireStorageLocks takes the named locks in a consistent order, so callers locking overlapping sets can't deadlock,
+// and returns a function that releases them all.
+func acquireStorageLocks(ctx context.Context, nk runtime.NakamaModule, names ...string) (release func(), err error) {
+	sorted := make([]string, len(names))
+	copy(sorted, names)
+	sort.Strings(sorted)
+
+	held := make(map[string]string, len(sorted))
+	release = func() {
+		for name, version := range held {
+			releaseStorageLock(ctx, nk, name, version)
+		}
+	}
+
+	for _, name := range sorted {
+		if _, found := held[name]; found {
+			continue
+		}
+		version, err := acquireStorageLock(ctx, nk, name)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		held[name] = version
+	}
+
+	return release, nil
+}
+
+// acquireStorageLock takes a lease on the named lock with a conditional write, retrying while another holder has an
+// unexpired lease. It returns the version of the lock object, needed to release it.
+func acquireStorageLock(ctx context.Context, nk runtime.NakamaModule, name string) (string, error) {
+	owner := uuid.New().String()
+	for attempt := 0; attempt < storageLockAttempts; attempt++ {
+		// Only create the lock object if nobody holds it
+		if version, err := writeStorageLease(ctx, nk, name, owner, storageLockVersionNone); err == nil {
+			return version, nil
+		}
+
+		// Take over a lease left behind by a holder that never released it
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+			{
+				Collection: storageLockCollection,
+				Key:        name,
+			},
+		})
+		if err == nil && len(objects) > 0 {
+			var lease storageLease
+			if err := json.Unmarshal([]byte(objects[0].Value), &lease); err != nil || lease.ExpireTimeSec <= time.Now().Unix() {
+				if version, err := writeStorageLease(ctx, nk, name, owner, objects[0].Version); err == nil {
+					return version, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(storageLockRetryWait):
+		}
+	}
+
+	return "", ErrStorageLockBusy
+}
+
+func writeStorageLease(ctx context.Context, nk runtime.NakamaModule, name, owner, version string) (string, error) {
+	data, err := json.Marshal(&storageLease{
+		Owner:         owner,
+		ExpireTimeSec: time.Now().Unix() + storageLockLeaseSec,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	acks, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      storageLockCollection,
+			Key:             name,
+			Value:           string(data),
+			Version:         version,
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(acks) == 0 {
+		return "", nil
+	}
+	return acks[0].Version, nil
+}
+
+// releaseStorageLock deletes the lock object, provided it still holds the lease written on acquire. A failed release
+// only delays the next holder until the lease expires.
+func releaseStorageLock(ctx context.Context, nk runtime.NakamaModule, name, version string) {
+	_ = nk.StorageDelete(ctx, []*runtime.StorageDelete{
+		{
+			Collection: storageLockCollection,
+			Key:        name,
+			Version:    version,
+		},
+	})
+}
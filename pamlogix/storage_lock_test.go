@@ -0,0 +1,87 @@
+package pamlogix
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func matchLockWrite(name, version string) interface{} {
+	return mock.MatchedBy(func(writes []*runtime.StorageWrite) bool {
+		return len(writes) == 1 && writes[0].Collection == storageLockCollection && writes[0].Key == name && writes[0].Version == version
+	})
+}
+
+func TestWithStorageLock(t *testing.T) {
+	ctx := context.Background()
+	nk := NewMockNakama(t)
+
+	nk.On("StorageWrite", ctx, matchLockWrite("hot", storageLockVersionNone)).Return([]*api.StorageObjectAck{{Version: "v1"}}, nil).Once()
+	nk.On("StorageDelete", ctx, []*runtime.StorageDelete{{Collection: storageLockCollection, Key: "hot", Version: "v1"}}).Return(nil).Once()
+
+	called := false
+	err := withStorageLock(ctx, nk, "hot", func() error {
+		called = true
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.True(t, called)
+	nk.AssertExpectations(t)
+}
+
+func TestAcquireStorageLock_TakesOverExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	nk := NewMockNakama(t)
+
+	expired := fmt.Sprintf(`{"owner":"other","expire_time_sec":%d}`, time.Now().Unix()-1)
+	nk.On("StorageWrite", ctx, matchLockWrite("hot", storageLockVersionNone)).Return([]*api.StorageObjectAck{}, assert.AnError).Once()
+	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{{Key: "hot", Value: expired, Version: "old"}}, nil).Once()
+	nk.On("StorageWrite", ctx, matchLockWrite("hot", "old")).Return([]*api.StorageObjectAck{{Version: "v2"}}, nil).Once()
+
+	version, err := acquireStorageLock(ctx, nk, "hot")
+
+	require.NoError(t, err)
+	assert.Equal(t, "v2", version)
+	nk.AssertExpectations(t)
+}
+
+func TestAcquireStorageLock_WaitsForHeldLease(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*storageLockRetryWait)
+	defer cancel()
+	nk := NewMockNakama(t)
+
+	held := fmt.Sprintf(`{"owner":"other","expire_time_sec":%d}`, time.Now().Unix()+storageLockLeaseSec)
+	nk.On("StorageWrite", ctx, matchLockWrite("hot", storageLockVersionNone)).Return([]*api.StorageObjectAck{}, assert.AnError)
+	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{{Key: "hot", Value: held, Version: "v1"}}, nil)
+
+	_, err := acquireStorageLock(ctx, nk, "hot")
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	nk.AssertNotCalled(t, "StorageWrite", ctx, matchLockWrite("hot", "v1"))
+}
+
+func TestAcquireStorageLocks_ReleasesOnFailure(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*storageLockRetryWait)
+	defer cancel()
+	nk := NewMockNakama(t)
+
+	held := fmt.Sprintf(`{"owner":"other","expire_time_sec":%d}`, time.Now().Unix()+storageLockLeaseSec)
+	nk.On("StorageWrite", ctx, matchLockWrite("a", storageLockVersionNone)).Return([]*api.StorageObjectAck{{Version: "va"}}, nil).Once()
+	nk.On("StorageWrite", ctx, matchLockWrite("b", storageLockVersionNone)).Return([]*api.StorageObjectAck{}, assert.AnError)
+	nk.On("StorageRead", ctx, mock.Anything).Return([]*api.StorageObject{{Key: "b", Value: held, Version: "vb"}}, nil)
+	nk.On("StorageDelete", ctx, []*runtime.StorageDelete{{Collection: storageLockCollection, Key: "a", Version: "va"}}).Return(nil).Once()
+
+	release, err := acquireStorageLocks(ctx, nk, "b", "a")
+
+	require.Error(t, err)
+	assert.Nil(t, release)
+	nk.AssertExpectations(t)
+}
@@ -0,0 +1,57 @@
+package pamlogix
+
+import (
+	"fmt"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// BaseSystemConfigStoragePermissions overrides the permissions the systems write a storage collection's objects with.
+// Read is 0 for no client reads, 1 for the owner only and 2 for anyone. Write is 0 for no client writes and 1 for the
+// owner only. Unset permissions keep the ones the system writes with.
+type BaseSystemConfigStoragePermissions struct {
+	Read  *int `json:"read,omitempty"`
+	Write *int `json:"write,omitempty"`
+}
+
+// validateStoragePermissions rejects permissions Nakama doesn't have, so a typo can't open up a collection.
+func validateStoragePermissions(permissions map[string]*BaseSystemConfigStoragePermissions) error {
+	for collection, permission := range permissions {
+		if permission == nil {
+			continue
+		}
+		if permission.Read != nil && (*permission.Read < runtime.STORAGE_PERMISSION_NO_READ || *permission.Read > runtime.STORAGE_PERMISSION_PUBLIC_READ) {
+			return fmt.Errorf("invalid read permission %d for storage collection %s", *permission.Read, collection)
+		}
+		if permission.Write != nil && (*permission.Write < runtime.STORAGE_PERMISSION_NO_WRITE || *permission.Write > runtime.STORAGE_PERMISSION_OWNER_WRITE) {
+			return fmt.Errorf("invalid write permission %d for storage collection %s", *permission.Write, collection)
+		}
+	}
+	return nil
+}
+
+// storagePermissions returns the permission overrides of the base config, keyed by the collection the systems name.
+func (p *pamlogixImpl) storagePermissions() map[string]*BaseSystemConfigStoragePermissions {
+	baseSystem := p.GetBaseSystem()
+	if baseSystem == nil {
+		return nil
+	}
+	baseConfig, ok := baseSystem.GetConfig().(*BaseSystemConfig)
+	if !ok || baseConfig == nil {
+		return nil
+	}
+	return baseConfig.StoragePermissions
+}
+
+// apply sets the configured permissions on a write.
+func (c *BaseSystemConfigStoragePermissions) apply(write *runtime.StorageWrite) {
+	if c == nil {
+		return
+	}
+	if c.Read != nil {
+		write.PermissionRead = *c.Read
+	}
+	if c.Write != nil {
+		write.PermissionWrite = *c.Write
+	}
+}
@@ -0,0 +1,193 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const storageSweepBatchSize = 100
+
+// storageTTLRule deletes the objects of a collection once the time held in one of their fields has passed, emulating a
+// storage TTL, which Nakama doesn't have.
+type storageTTLRule struct {
+	// Name identifies the rule in logs and sweep results.
+	Name       string
+	Collection string
+	// KeyPrefix limits the rule to objects whose key starts with it. Empty matches every key.
+	KeyPrefix string
+	// ExpiryField is the field of the object's JSON value holding a UNIX time in seconds. When the value is an array the
+	// field is read from each element, and the object expires once every element has. Objects without it never expire.
+	ExpiryField string
+	// TTLSec is added to the field's time, for fields which record when something happened rather than when it expires.
+	TTLSec int64
+}
+
+// storageTTLRuleProvider is implemented by systems which keep storage objects that expire.
+type storageTTLRuleProvider interface {
+	storageTTLRules() []*storageTTLRule
+}
+
+// StorageSweepResult is the number of expired storage objects deleted by a sweep, by rule name.
+type StorageSweepResult struct {
+	Deleted map[string]int `json:"deleted"`
+}
+
+// expired reports whether an object's value has expired at the given time.
+func (r *storageTTLRule) expired(value string, now int64) (bool, error) {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return false, err
+	}
+
+	var expiryTimeSec int64
+	switch decoded := decoded.(type) {
+	case map[string]interface{}:
+		expiryTimeSec = storageTTLFieldTimeSec(decoded, r.ExpiryField)
+	case []interface{}:
+		if len(decoded) == 0 {
+			return true, nil
+		}
+		for _, element := range decoded {
+			fields, ok := element.(map[string]interface{})
+			if !ok {
+				return false, nil
+			}
+			elementTimeSec := storageTTLFieldTimeSec(fields, r.ExpiryField)
+			if elementTimeSec <= 0 {
+				return false, nil
+			}
+			expiryTimeSec = max(expiryTimeSec, elementTimeSec)
+		}
+	}
+	if expiryTimeSec <= 0 {
+		return false, nil
+	}
+	return expiryTimeSec+r.TTLSec <= now, nil
+}
+
+func storageTTLFieldTimeSec(fields map[string]interface{}, field string) int64 {
+	timeSec, ok := fields[field].(float64)
+	if !ok {
+		return 0
+	}
+	return int64(timeSec)
+}
+
+// sweepExpiredStorage deletes the objects the rule has expired across all users and returns how many it deleted.
+// Deletes are conditional on the version listed, so an object updated during the sweep is kept until the next one.
+func sweepExpiredStorage(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, rule *storageTTLRule, now int64) (int, error) {
+	deleted := 0
+	cursor := ""
+	for {
+		objects, nextCursor, err := nk.StorageList(ctx, "", "", rule.Collection, storageSweepBatchSize, cursor)
+		if err != nil {
+			return deleted, err
+		}
+
+		deletes := make([]*runtime.StorageDelete, 0, len(objects))
+		for _, object := range objects {
+			if !strings.HasPrefix(object.Key, rule.KeyPrefix) {
+				continue
+			}
+			expired, err := rule.expired(object.Value, now)
+			if err != nil {
+				logger.Warn("Failed to read expiry of %s object %s: %v", rule.Collection, object.Key, err)
+				continue
+			}
+			if !expired {
+				continue
+			}
+
+			deletes = append(deletes, &runtime.StorageDelete{
+				Collection: rule.Collection,
+				Key:        object.Key,
+				UserID:     object.UserId,
+				Version:    object.Version,
+			})
+		}
+		deleted += deleteStorageBatch(ctx, logger, nk, deletes)
+
+		if nextCursor == "" {
+			return deleted, nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// deleteStorageBatch deletes the objects together, and one at a time if that fails, since a single object changed
+// since it was listed fails the whole batch. It returns how many were deleted.
+func deleteStorageBatch(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, deletes []*runtime.StorageDelete) int {
+	if len(deletes) == 0 {
+		return 0
+	}
+	if err := nk.StorageDelete(ctx, deletes); err == nil {
+		return len(deletes)
+	}
+
+	deleted := 0
+	for _, storageDelete := range deletes {
+		if err := nk.StorageDelete(ctx, []*runtime.StorageDelete{storageDelete}); err != nil {
+			logger.Debug("Skipped deleting expired %s object %s: %v", storageDelete.Collection, storageDelete.Key, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted
+}
+
+// writeStorageBatch writes the objects together, and one at a time if that fails, since a single object changed since
+// it was read fails the whole batch. It returns how many were written.
+func writeStorageBatch(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, writes []*runtime.StorageWrite) int {
+	if len(writes) == 0 {
+		return 0
+	}
+	if _, err := nk.StorageWrite(ctx, writes); err == nil {
+		return len(writes)
+	}
+
+	written := 0
+	for _, storageWrite := range writes {
+		if _, err := nk.StorageWrite(ctx, []*runtime.StorageWrite{storageWrite}); err != nil {
+			logger.Debug("Skipped writing %s object %s: %v", storageWrite.Collection, storageWrite.Key, err)
+			continue
+		}
+		written++
+	}
+	return written
+}
+
+// sweepStorage deletes the expired storage objects of every system. Rules which fail are logged and the sweep
+// carries on with the rest, returning the first error.
+func (p *pamlogixImpl) sweepStorage(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule) (*StorageSweepResult, error) {
+	result := &StorageSweepResult{Deleted: make(map[string]int)}
+	now := time.Now().Unix()
+
+	var sweepErr error
+	for _, system := range p.systems {
+		provider, ok := system.(storageTTLRuleProvider)
+		if !ok {
+			continue
+		}
+		for _, rule := range provider.storageTTLRules() {
+			deleted, err := sweepExpiredStorage(ctx, logger, nk, rule, now)
+			result.Deleted[rule.Name] += deleted
+			if err != nil {
+				logger.Error("Failed to sweep expired %s: %v", rule.Name, err)
+				if sweepErr == nil {
+					sweepErr = err
+				}
+			}
+		}
+	}
+
+	for name, deleted := range result.Deleted {
+		if deleted > 0 {
+			logger.Info("Deleted %d expired %s", deleted, name)
+		}
+	}
+	return result, sweepErr
+}
@@ -0,0 +1,78 @@
+package pamlogix
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageTTLRule_Expired(t *testing.T) {
+	now := time.Now().Unix()
+	rule := &storageTTLRule{ExpiryField: "end_time_sec"}
+
+	cases := map[string]struct {
+		value   string
+		expired bool
+	}{
+		"object past its expiry":     {fmt.Sprintf(`{"end_time_sec":%d}`, now-1), true},
+		"object before its expiry":   {fmt.Sprintf(`{"end_time_sec":%d}`, now+60), false},
+		"object without the field":   {`{"status":"started"}`, false},
+		"array all expired":          {fmt.Sprintf(`[{"end_time_sec":%d},{"end_time_sec":%d}]`, now-60, now-1), true},
+		"array with one still going": {fmt.Sprintf(`[{"end_time_sec":%d},{"end_time_sec":%d}]`, now-60, now+60), false},
+		"array with one never ends":  {fmt.Sprintf(`[{"end_time_sec":%d},{"end_time_sec":0}]`, now-60), false},
+		"empty array":                {`[]`, true},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			expired, err := rule.expired(c.value, now)
+			require.NoError(t, err)
+			assert.Equal(t, c.expired, expired)
+		})
+	}
+
+	// TTLSec counts from the field's time
+	ttlRule := &storageTTLRule{ExpiryField: "timestamp", TTLSec: 3600}
+	expired, err := ttlRule.expired(fmt.Sprintf(`{"timestamp":%d}`, now-60), now)
+	require.NoError(t, err)
+	assert.False(t, expired)
+}
+
+func TestSweepStorage(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	nk := newBenchNakama()
+	p := newBenchPamlogix()
+	now := time.Now().Unix()
+
+	economy := NewNakamaEconomySystem(&EconomyConfig{
+		Placements: map[string]*EconomyConfigPlacement{"rewarded": {}},
+	})
+	economy.SetPamlogix(p)
+	p.systems[SystemTypeEconomy] = economy
+
+	week := int64(placementStatusTTLSec)
+	writes := []*runtime.StorageWrite{
+		{Collection: placementStatusStorageCollection, Key: "user1_rewarded", UserID: "user1", Value: fmt.Sprintf(`{"status":"completed","timestamp":%d}`, now-week-60)},
+		{Collection: placementStatusStorageCollection, Key: "user2_rewarded", UserID: "user2", Value: fmt.Sprintf(`{"status":"completed","timestamp":%d}`, now-60)},
+		{Collection: userModifiersStorageCollection, Key: "user1_reward_modifiers", UserID: "user1", Value: fmt.Sprintf(`[{"id":"xp","end_time_sec":%d}]`, now-60)},
+		{Collection: userModifiersStorageCollection, Key: "user2_reward_modifiers", UserID: "user2", Value: fmt.Sprintf(`[{"id":"xp","end_time_sec":%d}]`, now+60)},
+		{Collection: purchaseIntentsCollection, Key: "purchase_intent:user1:pack", UserID: "user1", Value: fmt.Sprintf(`{"item_id":"pack","expires_at":%d}`, now-60)},
+	}
+	_, err := nk.StorageWrite(ctx, writes)
+	require.NoError(t, err)
+
+	result, err := p.sweepStorage(ctx, logger, nk)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"purchase intents": 1, "placement statuses": 1, "modifiers": 1}, result.Deleted)
+
+	for _, write := range writes {
+		objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{{Collection: write.Collection, Key: write.Key, UserID: write.UserID}})
+		require.NoError(t, err)
+		assert.Equal(t, write.UserID == "user2", len(objects) == 1, "%s/%s", write.Collection, write.Key)
+	}
+}
@@ -8,6 +8,18 @@ import (
 
 var ErrStreakResetInvalid = runtime.NewError("streak reset schedule invalid", INTERNAL_ERROR_CODE)
 
+// StreakClaim is a streak as left by a claim, with the period the claim counts against. A claim is keyed by the period
+// and the streak count it was made at, so a retried or repeated claim finds the earlier one and grants nothing again.
+type StreakClaim struct {
+	*Streak
+	// Start of the reset period the claim belongs to, or the start of the streak when it never resets.
+	PeriodStartTimeSec int64 `json:"period_start_time_sec"`
+	// End of the reset period, or the end of the streak when it never resets. Zero if open ended.
+	PeriodEndTimeSec int64 `json:"period_end_time_sec,omitempty"`
+	// True if rewards were already claimed at this count in this period, by an earlier or concurrent request.
+	AlreadyClaimed bool `json:"already_claimed"`
+}
+
 // StreaksConfig is the data definition for a StreaksSystem type.
 type StreaksConfig struct {
 	Streaks map[string]*StreaksConfigStreak `json:"streaks,omitempty"`
@@ -43,8 +55,9 @@ type StreaksSystem interface {
 	// Update one or more streaks with the indicated counts for the given user.
 	Update(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, streakIDs map[string]int64) (streaks map[string]*Streak, err error)
 
-	// Claim rewards for one or more streaks for the given user.
-	Claim(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, streakIDs []string) (streaks map[string]*Streak, err error)
+	// Claim rewards for one or more streaks for the given user. Claiming again in the same period without further
+	// progress grants nothing and returns the earlier claim.
+	Claim(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, streakIDs []string) (claims map[string]*StreakClaim, err error)
 
 	// Reset progress on selected streaks for the given user.
 	Reset(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, streakIDs []string) (streaks map[string]*Streak, err error)
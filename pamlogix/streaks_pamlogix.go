@@ -52,7 +52,7 @@ func (s *NakamaStreaksSystem) List(ctx context.Context, logger runtime.Logger, n
 	}
 
 	// Get user streaks from storage
-	userStreaks, err := s.getUserStreaks(ctx, logger, nk, userID)
+	userStreaks, _, err := s.getUserStreaks(ctx, logger, nk, userID)
 	if err != nil {
 		logger.Error("Failed to get user streaks: %v", err)
 		return nil, err
@@ -107,7 +107,7 @@ func (s *NakamaStreaksSystem) Update(ctx context.Context, logger runtime.Logger,
 	}
 
 	// Get user streaks from storage
-	userStreaks, err := s.getUserStreaks(ctx, logger, nk, userID)
+	userStreaks, _, err := s.getUserStreaks(ctx, logger, nk, userID)
 	if err != nil {
 		logger.Error("Failed to get user streaks: %v", err)
 		return nil, err
@@ -189,7 +189,7 @@ func (s *NakamaStreaksSystem) Update(ctx context.Context, logger runtime.Logger,
 
 	// Save changes if needed
 	if needsSave {
-		if err := s.saveUserStreaks(ctx, logger, nk, userID, userStreaks); err != nil {
+		if err := s.saveUserStreaks(ctx, logger, nk, userID, userStreaks, ""); err != nil {
 			logger.Error("Failed to save user streaks: %v", err)
 			return nil, err
 		}
@@ -198,8 +198,9 @@ func (s *NakamaStreaksSystem) Update(ctx context.Context, logger runtime.Logger,
 	return streaks, nil
 }
 
-// Claim rewards for one or more streaks for the given user.
-func (s *NakamaStreaksSystem) Claim(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, streakIDs []string) (streaks map[string]*Streak, err error) {
+// Claim rewards for one or more streaks for the given user. Rewards are granted only once the claims are saved, and
+// the save is conditional on the version read, so of two concurrent claims only one grants anything.
+func (s *NakamaStreaksSystem) Claim(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, streakIDs []string) (claims map[string]*StreakClaim, err error) {
 	if s.config == nil {
 		return nil, runtime.NewError("streaks config not loaded", INTERNAL_ERROR_CODE)
 	}
@@ -214,16 +215,22 @@ func (s *NakamaStreaksSystem) Claim(ctx context.Context, logger runtime.Logger,
 	}
 
 	// Get user streaks from storage
-	userStreaks, err := s.getUserStreaks(ctx, logger, nk, userID)
+	userStreaks, version, err := s.getUserStreaks(ctx, logger, nk, userID)
 	if err != nil {
 		logger.Error("Failed to get user streaks: %v", err)
 		return nil, err
 	}
 
 	now := time.Now().Unix()
-	streaks = make(map[string]*Streak)
+	claims = make(map[string]*StreakClaim)
 	needsSave := false
 
+	type streakGrant struct {
+		streakID string
+		reward   *Reward
+	}
+	grants := make([]streakGrant, 0)
+
 	// Process each streak claim
 	for _, streakID := range streakIDs {
 		streakConfig, exists := s.config.Streaks[streakID]
@@ -245,6 +252,12 @@ func (s *NakamaStreaksSystem) Claim(ctx context.Context, logger runtime.Logger,
 		// Apply any scheduled resets
 		userStreak = s.applyScheduledResets(logger, streakConfig, userStreak, now)
 
+		// A repeated claim in the same period without further progress gets the earlier claim back
+		if claim := s.priorClaim(streakID, streakConfig, userStreak, now); claim != nil {
+			claims[streakID] = claim
+			continue
+		}
+
 		// Check if we can claim this streak
 		if !s.canClaimStreak(streakConfig, userStreak, now) {
 			logger.Info("Cannot claim streak %s at this time", streakID)
@@ -280,18 +293,7 @@ func (s *NakamaStreaksSystem) Claim(ctx context.Context, logger runtime.Logger,
 				}
 			}
 
-			// Grant the reward
-			_, _, _, err = economySystem.RewardGrant(ctx, logger, nk, userID, rolledReward, map[string]interface{}{
-				"streak_id": streakID,
-				"type":      "streak_reward",
-			}, false)
-
-			if err != nil {
-				logger.Error("Failed to grant reward for streak %s: %v", streakID, err)
-				continue
-			}
-
-			// Record the claimed reward
+			// Record the claimed reward, to be granted once the claim is saved
 			claimedReward := &StreakReward{
 				CountMin:     rewardConfig.CountMin,
 				CountMax:     rewardConfig.CountMax,
@@ -300,6 +302,7 @@ func (s *NakamaStreaksSystem) Claim(ctx context.Context, logger runtime.Logger,
 			}
 
 			userStreak.ClaimedRewards = append(userStreak.ClaimedRewards, claimedReward)
+			grants = append(grants, streakGrant{streakID: streakID, reward: rolledReward})
 		}
 
 		// Update claim tracking
@@ -308,19 +311,78 @@ func (s *NakamaStreaksSystem) Claim(ctx context.Context, logger runtime.Logger,
 		userStreak.ClaimTimeSec = now
 
 		// Build the streak response
-		streak := s.buildStreakResponse(streakID, streakConfig, userStreak, now)
-		streaks[streakID] = streak
+		periodStart, periodEnd := s.claimPeriod(streakConfig, now)
+		claims[streakID] = &StreakClaim{
+			Streak:             s.buildStreakResponse(streakID, streakConfig, userStreak, now),
+			PeriodStartTimeSec: periodStart,
+			PeriodEndTimeSec:   periodEnd,
+		}
 	}
 
 	// Save changes if needed
 	if needsSave {
-		if err := s.saveUserStreaks(ctx, logger, nk, userID, userStreaks); err != nil {
-			logger.Error("Failed to save user streaks: %v", err)
-			return nil, err
+		if err := s.saveUserStreaks(ctx, logger, nk, userID, userStreaks, version); err != nil {
+			logger.Error("Failed to save user streak claims: %v", err)
+			return s.concurrentClaims(ctx, logger, nk, userID, streakIDs, now, err)
 		}
 	}
 
-	return streaks, nil
+	for _, grant := range grants {
+		_, _, _, err = economySystem.RewardGrant(ctx, logger, nk, userID, grant.reward, map[string]interface{}{
+			"streak_id": grant.streakID,
+			"type":      "streak_reward",
+		}, false)
+		if err != nil {
+			logger.Error("Failed to grant reward for streak %s: %v", grant.streakID, err)
+		}
+	}
+
+	return claims, nil
+}
+
+// concurrentClaims answers a claim whose save was rejected. If another request claimed the same streaks in the meantime
+// its claims are returned, so a double tap reads as one claim rather than an error; otherwise the save error is.
+func (s *NakamaStreaksSystem) concurrentClaims(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, streakIDs []string, now int64, saveErr error) (map[string]*StreakClaim, error) {
+	userStreaks, _, err := s.getUserStreaks(ctx, logger, nk, userID)
+	if err != nil {
+		return nil, saveErr
+	}
+
+	claims := make(map[string]*StreakClaim)
+	for _, streakID := range streakIDs {
+		streakConfig, exists := s.config.Streaks[streakID]
+		if !exists || streakConfig.Disabled {
+			continue
+		}
+		userStreak, streakExists := userStreaks[streakID]
+		if !streakExists {
+			continue
+		}
+		userStreak = s.applyScheduledResets(logger, streakConfig, userStreak, now)
+		if claim := s.priorClaim(streakID, streakConfig, userStreak, now); claim != nil {
+			claims[streakID] = claim
+		}
+	}
+	if len(claims) == 0 {
+		return nil, saveErr
+	}
+
+	return claims, nil
+}
+
+// priorClaim returns the claim already made in the current period at the streak's current count, if there is one.
+func (s *NakamaStreaksSystem) priorClaim(streakID string, config *StreaksConfigStreak, userStreak *SyncStreakUpdate, now int64) *StreakClaim {
+	periodStart, periodEnd := s.claimPeriod(config, now)
+	if userStreak.ClaimTimeSec == 0 || userStreak.ClaimTimeSec < periodStart || userStreak.ClaimCount != userStreak.Count {
+		return nil
+	}
+
+	return &StreakClaim{
+		Streak:             s.buildStreakResponse(streakID, config, userStreak, now),
+		PeriodStartTimeSec: periodStart,
+		PeriodEndTimeSec:   periodEnd,
+		AlreadyClaimed:     true,
+	}
 }
 
 // Reset progress on selected streaks for the given user.
@@ -330,7 +392,7 @@ func (s *NakamaStreaksSystem) Reset(ctx context.Context, logger runtime.Logger,
 	}
 
 	// Get user streaks from storage
-	userStreaks, err := s.getUserStreaks(ctx, logger, nk, userID)
+	userStreaks, _, err := s.getUserStreaks(ctx, logger, nk, userID)
 	if err != nil {
 		logger.Error("Failed to get user streaks: %v", err)
 		return nil, err
@@ -376,7 +438,7 @@ func (s *NakamaStreaksSystem) Reset(ctx context.Context, logger runtime.Logger,
 
 	// Save changes if needed
 	if needsSave {
-		if err := s.saveUserStreaks(ctx, logger, nk, userID, userStreaks); err != nil {
+		if err := s.saveUserStreaks(ctx, logger, nk, userID, userStreaks, ""); err != nil {
 			logger.Error("Failed to save user streaks: %v", err)
 			return nil, err
 		}
@@ -392,8 +454,8 @@ func (s *NakamaStreaksSystem) SetOnClaimReward(fn OnReward[*StreaksConfigStreak]
 
 // Helper functions
 
-// getUserStreaks fetches the stored streak data for a user from Nakama storage.
-func (s *NakamaStreaksSystem) getUserStreaks(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (map[string]*SyncStreakUpdate, error) {
+// getUserStreaks fetches the stored streak data for a user from Nakama storage, along with the version of the object.
+func (s *NakamaStreaksSystem) getUserStreaks(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (map[string]*SyncStreakUpdate, string, error) {
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
 			Collection: streaksStorageCollection,
@@ -404,32 +466,33 @@ func (s *NakamaStreaksSystem) getUserStreaks(ctx context.Context, logger runtime
 
 	if err != nil {
 		logger.Error("Failed to read user streaks: %v", err)
-		return nil, err
+		return nil, "", err
 	}
 
 	streaks := make(map[string]*SyncStreakUpdate)
 
 	// If no data found, return empty map
 	if len(objects) == 0 || objects[0].Value == "" {
-		return streaks, nil
+		return streaks, "", nil
 	}
 
 	// Unmarshal the stored streak data
 	syncStreaks := &SyncStreaks{}
 	if err := json.Unmarshal([]byte(objects[0].Value), syncStreaks); err != nil {
 		logger.Error("Failed to unmarshal user streaks: %v", err)
-		return nil, err
+		return nil, "", err
 	}
 
 	if syncStreaks.Updates != nil {
-		return syncStreaks.Updates, nil
+		return syncStreaks.Updates, objects[0].Version, nil
 	}
 
-	return streaks, nil
+	return streaks, objects[0].Version, nil
 }
 
-// saveUserStreaks stores the updated streak data for a user in Nakama storage.
-func (s *NakamaStreaksSystem) saveUserStreaks(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, streaks map[string]*SyncStreakUpdate) error {
+// saveUserStreaks stores the updated streak data for a user in Nakama storage. A non-empty version makes the write
+// conditional on the object not having changed since it was read.
+func (s *NakamaStreaksSystem) saveUserStreaks(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, streaks map[string]*SyncStreakUpdate, version string) error {
 	// Marshal the streak data
 	syncStreaks := &SyncStreaks{
 		Updates: streaks,
@@ -449,6 +512,7 @@ func (s *NakamaStreaksSystem) saveUserStreaks(ctx context.Context, logger runtim
 			Key:             userStreaksStorageKey,
 			UserID:          userID,
 			Value:           string(data),
+			Version:         version,
 			PermissionRead:  runtime.STORAGE_PERMISSION_OWNER_READ,
 			PermissionWrite: runtime.STORAGE_PERMISSION_OWNER_WRITE,
 		},
@@ -473,10 +537,44 @@ func (s *NakamaStreaksSystem) calculateNextResetTime(cronExpr string, now time.T
 		return 0, err
 	}
 
-	nextReset := sched.Next(now)
+	nextReset := sched.Next(now.UTC())
 	return nextReset.Unix(), nil
 }
 
+// claimPeriod returns the bounds of the period a claim made now counts against: the reset period for streaks with a
+// reset schedule, otherwise the streak's own time window. Schedules are evaluated in UTC, so the period is the same
+// whichever time zone the server or the player is in.
+func (s *NakamaStreaksSystem) claimPeriod(config *StreaksConfigStreak, now int64) (startTimeSec, endTimeSec int64) {
+	if config.ResetCronexpr == "" {
+		return config.StartTimeSec, config.EndTimeSec
+	}
+
+	sched, err := s.cronParser.Parse(config.ResetCronexpr)
+	if err != nil {
+		return config.StartTimeSec, config.EndTimeSec
+	}
+
+	nowTime := time.Unix(now, 0).UTC()
+	next := sched.Next(nowTime)
+	if next.IsZero() {
+		return config.StartTimeSec, config.EndTimeSec
+	}
+
+	// Schedules only step forward, so look further back until a reset at or before now turns up
+	for lookback := time.Hour; lookback <= 400*24*time.Hour; lookback *= 2 {
+		prev := sched.Next(nowTime.Add(-lookback))
+		if prev.IsZero() || prev.After(nowTime) {
+			continue
+		}
+		for following := sched.Next(prev); !following.After(nowTime); following = sched.Next(prev) {
+			prev = following
+		}
+		return prev.Unix(), next.Unix()
+	}
+
+	return config.StartTimeSec, next.Unix()
+}
+
 // applyScheduledResets applies any scheduled resets that should have occurred
 func (s *NakamaStreaksSystem) applyScheduledResets(logger runtime.Logger, config *StreaksConfigStreak, userStreak *SyncStreakUpdate, now int64) *SyncStreakUpdate {
 	if config.ResetCronexpr == "" {
@@ -539,11 +637,7 @@ func (s *NakamaStreaksSystem) buildStreakResponse(streakID string, config *Strea
 	// Calculate previous reset time
 	var prevResetTime int64
 	if config.ResetCronexpr != "" && nextResetTime > 0 {
-		// Approximate previous reset time
-		prevTime, err := s.calculateNextResetTime(config.ResetCronexpr, time.Unix(nextResetTime-86400, 0))
-		if err == nil && prevTime < nextResetTime {
-			prevResetTime = prevTime
-		}
+		prevResetTime, _ = s.claimPeriod(config, now)
 	}
 
 	// Build available rewards
@@ -701,185 +795,5 @@ func (s *NakamaStreaksSystem) getAvailableRewardsForResponse(config *StreaksConf
 
 // convertRewardConfigToAvailableRewards converts EconomyConfigReward to AvailableRewards
 func (s *NakamaStreaksSystem) convertRewardConfigToAvailableRewards(rewardConfig *EconomyConfigReward) *AvailableRewards {
-	if rewardConfig == nil {
-		return nil
-	}
-
-	availableRewards := &AvailableRewards{
-		MaxRolls:       rewardConfig.MaxRolls,
-		TotalWeight:    rewardConfig.TotalWeight,
-		MaxRepeatRolls: rewardConfig.MaxRepeatRolls,
-	}
-
-	// Convert guaranteed rewards
-	if rewardConfig.Guaranteed != nil {
-		availableRewards.Guaranteed = s.convertRewardContentsToAvailableRewardsContents(rewardConfig.Guaranteed)
-	}
-
-	// Convert weighted rewards
-	if len(rewardConfig.Weighted) > 0 {
-		availableRewards.Weighted = make([]*AvailableRewardsContents, len(rewardConfig.Weighted))
-		for i, weighted := range rewardConfig.Weighted {
-			availableRewards.Weighted[i] = s.convertRewardContentsToAvailableRewardsContents(weighted)
-		}
-	}
-
-	return availableRewards
-}
-
-// convertRewardContentsToAvailableRewardsContents converts EconomyConfigRewardContents to AvailableRewardsContents
-func (s *NakamaStreaksSystem) convertRewardContentsToAvailableRewardsContents(contents *EconomyConfigRewardContents) *AvailableRewardsContents {
-	if contents == nil {
-		return nil
-	}
-
-	availableContents := &AvailableRewardsContents{
-		Weight: contents.Weight,
-	}
-
-	// Convert currencies
-	if len(contents.Currencies) > 0 {
-		availableContents.Currencies = make(map[string]*AvailableRewardsCurrency)
-		for k, v := range contents.Currencies {
-			availableContents.Currencies[k] = &AvailableRewardsCurrency{
-				Count: &RewardRangeInt64{
-					Min:      v.Min,
-					Max:      v.Max,
-					Multiple: v.Multiple,
-				},
-			}
-		}
-	}
-
-	// Convert items
-	if len(contents.Items) > 0 {
-		availableContents.Items = make(map[string]*AvailableRewardsItem)
-		for k, v := range contents.Items {
-			item := &AvailableRewardsItem{
-				Count: &RewardRangeInt64{
-					Min:      v.Min,
-					Max:      v.Max,
-					Multiple: v.Multiple,
-				},
-			}
-
-			// Convert string properties
-			if len(v.StringProperties) > 0 {
-				item.StringProperties = make(map[string]*AvailableRewardsStringProperty)
-				for propKey, propVal := range v.StringProperties {
-					stringProp := &AvailableRewardsStringProperty{
-						TotalWeight: propVal.TotalWeight,
-					}
-					if len(propVal.Options) > 0 {
-						stringProp.Options = make(map[string]*AvailableRewardsStringPropertyOption)
-						for optKey, optVal := range propVal.Options {
-							stringProp.Options[optKey] = &AvailableRewardsStringPropertyOption{
-								Weight: optVal.Weight,
-							}
-						}
-					}
-					item.StringProperties[propKey] = stringProp
-				}
-			}
-
-			// Convert numeric properties
-			if len(v.NumericProperties) > 0 {
-				item.NumericProperties = make(map[string]*RewardRangeDouble)
-				for propKey, propVal := range v.NumericProperties {
-					item.NumericProperties[propKey] = &RewardRangeDouble{
-						Min:      propVal.Min,
-						Max:      propVal.Max,
-						Multiple: propVal.Multiple,
-					}
-				}
-			}
-
-			availableContents.Items[k] = item
-		}
-	}
-
-	// Convert energies
-	if len(contents.Energies) > 0 {
-		availableContents.Energies = make(map[string]*AvailableRewardsEnergy)
-		for k, v := range contents.Energies {
-			availableContents.Energies[k] = &AvailableRewardsEnergy{
-				Count: &RewardRangeInt32{
-					Min:      v.Min,
-					Max:      v.Max,
-					Multiple: v.Multiple,
-				},
-			}
-		}
-	}
-
-	// Convert item sets
-	if len(contents.ItemSets) > 0 {
-		availableContents.ItemSets = make([]*AvailableRewardsItemSet, len(contents.ItemSets))
-		for i, itemSet := range contents.ItemSets {
-			availableContents.ItemSets[i] = &AvailableRewardsItemSet{
-				Count: &RewardRangeInt64{
-					Min:      itemSet.Min,
-					Max:      itemSet.Max,
-					Multiple: itemSet.Multiple,
-				},
-				MaxRepeats: itemSet.MaxRepeats,
-				Set:        itemSet.Set,
-			}
-		}
-	}
-
-	// Convert energy modifiers
-	if len(contents.EnergyModifiers) > 0 {
-		availableContents.EnergyModifiers = make([]*AvailableRewardsEnergyModifier, len(contents.EnergyModifiers))
-		for i, modifier := range contents.EnergyModifiers {
-			availableModifier := &AvailableRewardsEnergyModifier{
-				Id:       modifier.Id,
-				Operator: modifier.Operator,
-			}
-			if modifier.Value != nil {
-				availableModifier.Value = &RewardRangeInt64{
-					Min:      modifier.Value.Min,
-					Max:      modifier.Value.Max,
-					Multiple: modifier.Value.Multiple,
-				}
-			}
-			if modifier.DurationSec != nil {
-				availableModifier.DurationSec = &RewardRangeUInt64{
-					Min:      modifier.DurationSec.Min,
-					Max:      modifier.DurationSec.Max,
-					Multiple: modifier.DurationSec.Multiple,
-				}
-			}
-			availableContents.EnergyModifiers[i] = availableModifier
-		}
-	}
-
-	// Convert reward modifiers
-	if len(contents.RewardModifiers) > 0 {
-		availableContents.RewardModifiers = make([]*AvailableRewardsRewardModifier, len(contents.RewardModifiers))
-		for i, modifier := range contents.RewardModifiers {
-			availableModifier := &AvailableRewardsRewardModifier{
-				Id:       modifier.Id,
-				Type:     modifier.Type,
-				Operator: modifier.Operator,
-			}
-			if modifier.Value != nil {
-				availableModifier.Value = &RewardRangeInt64{
-					Min:      modifier.Value.Min,
-					Max:      modifier.Value.Max,
-					Multiple: modifier.Value.Multiple,
-				}
-			}
-			if modifier.DurationSec != nil {
-				availableModifier.DurationSec = &RewardRangeUInt64{
-					Min:      modifier.DurationSec.Min,
-					Max:      modifier.DurationSec.Max,
-					Multiple: modifier.DurationSec.Multiple,
-				}
-			}
-			availableContents.RewardModifiers[i] = availableModifier
-		}
-	}
-
-	return availableContents
+	return rewardConfigToAvailable(rewardConfig)
 }
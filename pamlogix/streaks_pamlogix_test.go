@@ -703,3 +703,90 @@ func TestNakamaStreaksSystem_Claim_NoAvailableRewards(t *testing.T) {
 
 	nk.AssertExpectations(t)
 }
+
+func TestNakamaStreaksSystem_ClaimIsIdempotent(t *testing.T) {
+	coins := func(amount int64) *EconomyConfigReward {
+		return &EconomyConfigReward{Guaranteed: &EconomyConfigRewardContents{
+			Currencies: map[string]*EconomyConfigRewardCurrency{
+				benchCurrency: {EconomyConfigRewardRangeInt64{Min: amount, Max: amount}},
+			},
+		}}
+	}
+	system := NewNakamaStreaksSystem(&StreaksConfig{
+		Streaks: map[string]*StreaksConfigStreak{
+			"daily_login": {
+				Name:          "Daily Login",
+				ResetCronexpr: "0 0 * * *",
+				Rewards: []*StreaksConfigStreakReward{
+					{CountMin: 1, CountMax: 1, Reward: coins(100)},
+					{CountMin: 2, CountMax: 2, Reward: coins(200)},
+				},
+			},
+		},
+	})
+	p := newBenchPamlogix()
+	system.SetPamlogix(p)
+	nk := newBenchNakama()
+	logger := &mockLogger{}
+	ctx := context.Background()
+	userID := "user1"
+
+	_, err := system.Update(ctx, logger, nk, userID, map[string]int64{"daily_login": 1})
+	require.NoError(t, err)
+
+	claims, err := system.Claim(ctx, logger, nk, userID, []string{"daily_login"})
+	require.NoError(t, err)
+	require.Contains(t, claims, "daily_login")
+	assert.False(t, claims["daily_login"].AlreadyClaimed)
+	assert.Less(t, claims["daily_login"].PeriodStartTimeSec, claims["daily_login"].PeriodEndTimeSec)
+
+	// A retry grants nothing and echoes the claim
+	retried, err := system.Claim(ctx, logger, nk, userID, []string{"daily_login"})
+	require.NoError(t, err)
+	require.Contains(t, retried, "daily_login")
+	assert.True(t, retried["daily_login"].AlreadyClaimed)
+	assert.Equal(t, claims["daily_login"].PeriodStartTimeSec, retried["daily_login"].PeriodStartTimeSec)
+	wallet, err := userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), wallet[benchCurrency])
+
+	// A claim racing another one loses the save and gets the winner's claim back instead of granting again
+	_, err = system.Update(ctx, logger, nk, userID, map[string]int64{"daily_login": 1})
+	require.NoError(t, err)
+	var racing map[string]*StreakClaim
+	raced := false
+	system.SetOnClaimReward(func(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, sourceID string, source *StreaksConfigStreak, rewardConfig *EconomyConfigReward, reward *Reward) (*Reward, error) {
+		if !raced {
+			raced = true
+			racing, err = system.Claim(ctx, logger, nk, userID, []string{sourceID})
+			require.NoError(t, err)
+		}
+		return reward, nil
+	})
+	claims, err = system.Claim(ctx, logger, nk, userID, []string{"daily_login"})
+	require.NoError(t, err)
+	require.Contains(t, racing, "daily_login")
+	assert.False(t, racing["daily_login"].AlreadyClaimed)
+	require.Contains(t, claims, "daily_login")
+	assert.True(t, claims["daily_login"].AlreadyClaimed)
+	wallet, err = userWallet(ctx, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), wallet[benchCurrency])
+}
+
+func TestNakamaStreaksSystem_ClaimPeriod(t *testing.T) {
+	system := NewNakamaStreaksSystem(&StreaksConfig{})
+	now := time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC).Unix()
+
+	start, end := system.claimPeriod(&StreaksConfigStreak{ResetCronexpr: "0 0 * * *"}, now)
+	assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC).Unix(), start)
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC).Unix(), end)
+
+	start, end = system.claimPeriod(&StreaksConfigStreak{ResetCronexpr: "0 0 * * 1"}, now)
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC).Unix(), start)
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC).Unix(), end)
+
+	start, end = system.claimPeriod(&StreaksConfigStreak{StartTimeSec: 100, EndTimeSec: 200}, now)
+	assert.Equal(t, int64(100), start)
+	assert.Equal(t, int64(200), end)
+}
@@ -0,0 +1,303 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+const (
+	teamTreasuryCollection = "team_treasury"
+
+	defaultTeamTreasuryLedgerMaxEntries = 100
+)
+
+// Roles of team members, as named in the treasury's spend limits.
+const (
+	TeamRoleSuperadmin = "superadmin"
+	TeamRoleAdmin      = "admin"
+	TeamRoleMember     = "member"
+)
+
+// teamTreasury is a team's treasury as it's stored, with what each member spent from it on the current day.
+type teamTreasury struct {
+	Currencies map[string]int64           `json:"currencies"`
+	Ledger     []*TeamTreasuryLedgerEntry `json:"ledger,omitempty"`
+	// Spent is what each member spent from the treasury on the day starting at SpentDaySec, by currency.
+	Spent         map[string]map[string]int64 `json:"spent,omitempty"`
+	SpentDaySec   int64                       `json:"spent_day_sec,omitempty"`
+	UpdateTimeSec int64                       `json:"update_time_sec"`
+}
+
+// teamRole returns the user's role in the team, or "" if they aren't a member.
+func (t *NakamaTeamsSystem) teamRole(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string) (string, error) {
+	userGroups, _, err := nk.UserGroupsList(ctx, userID, 100, nil, "")
+	if err != nil {
+		logger.Error("Failed to get user groups for role check: %v", err)
+		return "", err
+	}
+
+	for _, userGroup := range userGroups {
+		if userGroup.Group.Id != teamID || userGroup.State == nil {
+			continue
+		}
+		switch userGroup.State.Value {
+		case int32(api.UserGroupList_UserGroup_SUPERADMIN):
+			return TeamRoleSuperadmin, nil
+		case int32(api.UserGroupList_UserGroup_ADMIN):
+			return TeamRoleAdmin, nil
+		case int32(api.UserGroupList_UserGroup_MEMBER):
+			return TeamRoleMember, nil
+		}
+	}
+	return "", nil
+}
+
+func (t *NakamaTeamsSystem) readTreasury(ctx context.Context, nk runtime.NakamaModule, teamID string) (*teamTreasury, error) {
+	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
+		{
+			Collection: teamTreasuryCollection,
+			Key:        teamID,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	treasury := &teamTreasury{}
+	if len(objects) > 0 {
+		if err := json.Unmarshal([]byte(objects[0].Value), treasury); err != nil {
+			return nil, err
+		}
+	}
+	if treasury.Currencies == nil {
+		treasury.Currencies = make(map[string]int64)
+	}
+
+	// Spending limits are daily, so what was spent on an earlier day no longer counts
+	now := time.Now().Unix()
+	if day := now - now%86400; treasury.SpentDaySec != day {
+		treasury.Spent = nil
+		treasury.SpentDaySec = day
+	}
+	if treasury.Spent == nil {
+		treasury.Spent = make(map[string]map[string]int64)
+	}
+	return treasury, nil
+}
+
+func (t *NakamaTeamsSystem) writeTreasury(ctx context.Context, nk runtime.NakamaModule, teamID string, treasury *teamTreasury) error {
+	data, err := json.Marshal(treasury)
+	if err != nil {
+		return err
+	}
+
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
+		{
+			Collection:      teamTreasuryCollection,
+			Key:             teamID,
+			Value:           string(data),
+			PermissionRead:  runtime.STORAGE_PERMISSION_NO_READ,
+			PermissionWrite: runtime.STORAGE_PERMISSION_NO_WRITE,
+		},
+	})
+	return err
+}
+
+// updateTreasury applies the change to the team's treasury while holding its lock, and records the ledger entry the
+// update returns. Unless the user needn't be a member, such as for refunds, the update is given their role.
+func (t *NakamaTeamsSystem) updateTreasury(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string, memberOnly bool, update func(treasury *teamTreasury, role string) (*TeamTreasuryLedgerEntry, error)) (*TeamTreasury, error) {
+	if t.config.Treasury == nil {
+		return nil, ErrTeamTreasuryDisabled
+	}
+	if userID == "" || teamID == "" {
+		return nil, ErrBadInput
+	}
+
+	role, err := t.teamRole(ctx, logger, nk, userID, teamID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	if role == "" && memberOnly {
+		return nil, ErrTeamNotMember
+	}
+
+	var treasury *teamTreasury
+	err = withStorageLock(ctx, nk, teamTreasuryCollection+":"+teamID, func() error {
+		if treasury, err = t.readTreasury(ctx, nk, teamID); err != nil {
+			logger.Error("Failed to read treasury of team %s: %v", teamID, err)
+			return ErrInternal
+		}
+
+		entry, err := update(treasury, role)
+		if err != nil {
+			return err
+		}
+		entry.UserId = userID
+		entry.CreateTimeSec = time.Now().Unix()
+		treasury.UpdateTimeSec = entry.CreateTimeSec
+		treasury.Ledger = append([]*TeamTreasuryLedgerEntry{entry}, treasury.Ledger...)
+		if maxEntries := t.treasuryLedgerMaxEntries(); len(treasury.Ledger) > maxEntries {
+			treasury.Ledger = treasury.Ledger[:maxEntries]
+		}
+
+		if err := t.writeTreasury(ctx, nk, teamID, treasury); err != nil {
+			logger.Error("Failed to write treasury of team %s: %v", teamID, err)
+			return ErrInternal
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t.treasuryForUser(teamID, treasury, userID, role), nil
+}
+
+func (t *NakamaTeamsSystem) treasuryLedgerMaxEntries() int {
+	if t.config.Treasury.LedgerMaxEntries > 0 {
+		return t.config.Treasury.LedgerMaxEntries
+	}
+	return defaultTeamTreasuryLedgerMaxEntries
+}
+
+// treasuryForUser returns the treasury as the member sees it, with what they can still spend today.
+func (t *NakamaTeamsSystem) treasuryForUser(teamID string, treasury *teamTreasury, userID, role string) *TeamTreasury {
+	var allowance map[string]int64
+	if limits := t.config.Treasury.DailySpendLimits[role]; len(limits) > 0 {
+		allowance = make(map[string]int64, len(limits))
+		for currency, limit := range limits {
+			allowance[currency] = max(limit-treasury.Spent[userID][currency], 0)
+		}
+	}
+	ledger := treasury.Ledger
+	if ledger == nil {
+		ledger = make([]*TeamTreasuryLedgerEntry, 0)
+	}
+	return &TeamTreasury{
+		TeamId:         teamID,
+		Currencies:     treasury.Currencies,
+		SpendAllowance: allowance,
+		Ledger:         ledger,
+		UpdateTimeSec:  treasury.UpdateTimeSec,
+	}
+}
+
+func validTreasuryCurrencies(currencies map[string]int64) bool {
+	if len(currencies) == 0 {
+		return false
+	}
+	for _, amount := range currencies {
+		if amount <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *NakamaTeamsSystem) GetTreasury(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string) (*TeamTreasury, error) {
+	if t.config.Treasury == nil {
+		return nil, ErrTeamTreasuryDisabled
+	}
+	if userID == "" || teamID == "" {
+		return nil, ErrBadInput
+	}
+
+	role, err := t.teamRole(ctx, logger, nk, userID, teamID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+	if role == "" {
+		return nil, ErrTeamNotMember
+	}
+
+	treasury, err := t.readTreasury(ctx, nk, teamID)
+	if err != nil {
+		logger.Error("Failed to read treasury of team %s: %v", teamID, err)
+		return nil, ErrInternal
+	}
+	return t.treasuryForUser(teamID, treasury, userID, role), nil
+}
+
+func (t *NakamaTeamsSystem) DepositTreasury(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string, currencies map[string]int64) (*TeamTreasury, error) {
+	if !validTreasuryCurrencies(currencies) {
+		return nil, ErrBadInput
+	}
+	if t.pamlogix == nil || t.pamlogix.GetEconomySystem() == nil {
+		logger.Warn("Cannot deposit into team treasury: no EconomySystem available")
+		return nil, ErrInternal
+	}
+	economySystem := t.pamlogix.GetEconomySystem()
+	metadata := map[string]interface{}{
+		"source":  "team_treasury_deposit",
+		"team_id": teamID,
+	}
+
+	debited := false
+	treasury, err := t.updateTreasury(ctx, logger, nk, userID, teamID, true, func(treasury *teamTreasury, _ string) (*TeamTreasuryLedgerEntry, error) {
+		if _, err := economySystem.Debit(ctx, logger, nk, userID, currencies, nil, metadata); err != nil {
+			return nil, err
+		}
+		debited = true
+		for currency, amount := range currencies {
+			treasury.Currencies[currency] += amount
+		}
+		return &TeamTreasuryLedgerEntry{Currencies: currencies, Source: "deposit"}, nil
+	})
+	if err != nil && debited {
+		// The treasury wasn't saved, so the deposit goes back to the member
+		metadata["source"] = "team_treasury_deposit_reversal"
+		if _, _, _, grantErr := economySystem.Grant(ctx, logger, nk, userID, currencies, nil, nil, nil, metadata); grantErr != nil {
+			logger.Error("Failed to return deposit to user %s after failed deposit into team %s treasury: %v", userID, teamID, grantErr)
+		}
+	}
+	return treasury, err
+}
+
+func (t *NakamaTeamsSystem) SpendTreasury(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string, currencies map[string]int64, source string, metadata map[string]string) (*TeamTreasury, error) {
+	if !validTreasuryCurrencies(currencies) {
+		return nil, ErrBadInput
+	}
+
+	return t.updateTreasury(ctx, logger, nk, userID, teamID, true, func(treasury *teamTreasury, role string) (*TeamTreasuryLedgerEntry, error) {
+		limits := t.config.Treasury.DailySpendLimits[role]
+		spent := treasury.Spent[userID]
+		for currency, amount := range currencies {
+			if limit, found := limits[currency]; !found || spent[currency]+amount > limit {
+				return nil, ErrTeamTreasurySpendLimit
+			}
+			if treasury.Currencies[currency] < amount {
+				return nil, ErrTeamTreasuryInsufficient
+			}
+		}
+
+		if spent == nil {
+			spent = make(map[string]int64, len(currencies))
+			treasury.Spent[userID] = spent
+		}
+		for currency, amount := range currencies {
+			treasury.Currencies[currency] -= amount
+			spent[currency] += amount
+		}
+		return &TeamTreasuryLedgerEntry{Currencies: scaleAmounts(currencies, -1), Source: source, Metadata: metadata}, nil
+	})
+}
+
+func (t *NakamaTeamsSystem) RefundTreasury(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string, currencies map[string]int64, source string, metadata map[string]string) (*TeamTreasury, error) {
+	if !validTreasuryCurrencies(currencies) {
+		return nil, ErrBadInput
+	}
+
+	return t.updateTreasury(ctx, logger, nk, userID, teamID, false, func(treasury *teamTreasury, _ string) (*TeamTreasuryLedgerEntry, error) {
+		spent := treasury.Spent[userID]
+		for currency, amount := range currencies {
+			treasury.Currencies[currency] += amount
+			if spent != nil {
+				spent[currency] = max(spent[currency]-amount, 0)
+			}
+		}
+		return &TeamTreasuryLedgerEntry{Currencies: currencies, Source: source, Metadata: metadata}, nil
+	})
+}
@@ -7,16 +7,175 @@ import (
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
+var (
+	ErrTeamNotFound       = runtime.NewError("team not found", NOT_FOUND_ERROR_CODE)                             // NOT_FOUND
+	ErrTeamNotAdmin       = runtime.NewError("only team admins can edit the team", PERMISSION_DENIED_ERROR_CODE) // PERMISSION_DENIED
+	ErrTeamProfileInvalid = runtime.NewError("team profile invalid", INVALID_ARGUMENT_ERROR_CODE)                // INVALID_ARGUMENT
+	ErrTeamEmblemInvalid  = runtime.NewError("team emblem invalid", INVALID_ARGUMENT_ERROR_CODE)                 // INVALID_ARGUMENT
+	ErrTeamNotMember      = runtime.NewError("not a member of the team", PERMISSION_DENIED_ERROR_CODE)           // PERMISSION_DENIED
+
+	ErrTeamTreasuryDisabled     = runtime.NewError("team treasury disabled", UNIMPLEMENTED_ERROR_CODE)                     // UNIMPLEMENTED
+	ErrTeamTreasuryInsufficient = runtime.NewError("team treasury has insufficient funds", FAILED_PRECONDITION_ERROR_CODE) // FAILED_PRECONDITION
+	ErrTeamTreasurySpendLimit   = runtime.NewError("team treasury spend limit reached", PERMISSION_DENIED_ERROR_CODE)      // PERMISSION_DENIED
+)
+
 // TeamsConfig is the data definition for a TeamsSystem type.
 type TeamsConfig struct {
 	MaxTeamSize int `json:"max_team_size,omitempty"`
+	// ChatMessageLimits validates team chat messages written through WriteChatMessage.
+	ChatMessageLimits *TextLimits `json:"chat_message_limits,omitempty"`
+	// ProfileLimits validates the profile fields team admins set through Update.
+	ProfileLimits *TeamsConfigProfileLimits `json:"profile_limits,omitempty"`
+	// Emblems is the catalog team emblems are composed from. When set, team icons and banners must also be picked from
+	// it, so teams can't show arbitrary images.
+	Emblems *TeamsConfigEmblems `json:"emblems,omitempty"`
+	// Treasury is a shared wallet members deposit into and officers spend from, such as on auction bids. Nil disables
+	// it.
+	Treasury *TeamsConfigTreasury `json:"treasury,omitempty"`
+}
+
+// TeamsConfigTreasury sets who can spend from a team's treasury, and how much.
+type TeamsConfigTreasury struct {
+	// DailySpendLimits caps how much a member can spend from the treasury each UTC day by currency, keyed by their
+	// role: "superadmin", "admin" or "member". Members can't spend currencies their role has no limit for.
+	DailySpendLimits map[string]map[string]int64 `json:"daily_spend_limits,omitempty"`
+	// LedgerMaxEntries is how many of the latest deposits, spends and refunds the ledger keeps. Defaults to 100.
+	LedgerMaxEntries int `json:"ledger_max_entries,omitempty"`
+}
+
+// TeamsConfigEmblems is the catalog of emblem parts, each keyed by the ID teams refer to it by.
+type TeamsConfigEmblems struct {
+	Shapes  map[string]*TeamsConfigEmblemPart `json:"shapes,omitempty"`
+	Colors  map[string]*TeamsConfigEmblemPart `json:"colors,omitempty"`
+	Icons   map[string]*TeamsConfigEmblemPart `json:"icons,omitempty"`
+	Banners map[string]*TeamsConfigEmblemPart `json:"banners,omitempty"`
+}
+
+// TeamsConfigEmblemPart is one entry of the emblem catalog. The value is what clients draw it with, such as an asset
+// name or a hex color.
+type TeamsConfigEmblemPart struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// TeamsConfigProfileLimits bounds the profile a team shows to other players.
+type TeamsConfigProfileLimits struct {
+	// MaxDescriptionBlocks is the most description blocks a team can have. Defaults to 5.
+	MaxDescriptionBlocks int `json:"max_description_blocks,omitempty"`
+	// DescriptionBlockTitle and DescriptionBlockBody validate the text of each description block.
+	DescriptionBlockTitle *TextLimits `json:"description_block_title,omitempty"`
+	DescriptionBlockBody  *TextLimits `json:"description_block_body,omitempty"`
+	// MaxSocialLinks is the most social links a team can have. Defaults to 5.
+	MaxSocialLinks int `json:"max_social_links,omitempty"`
+	// SocialLinkHosts are the hosts social links may point to, e.g. "discord.gg", including their subdomains. When
+	// empty any https link is allowed.
+	SocialLinkHosts []string `json:"social_link_hosts,omitempty"`
+}
+
+// TeamProfile is the part of a team's profile kept in its Nakama group metadata, alongside the icon.
+type TeamProfile struct {
+	Emblem            *TeamEmblem             `json:"emblem,omitempty"`
+	Banner            string                  `json:"banner,omitempty"`
+	Level             int64                   `json:"level,omitempty"`
+	DescriptionBlocks []*TeamDescriptionBlock `json:"description_blocks,omitempty"`
+	SocialLinks       []*TeamSocialLink       `json:"social_links,omitempty"`
+}
+
+// TeamEmblem is a team's emblem, composed of IDs from the emblem catalog. The secondary color is optional.
+type TeamEmblem struct {
+	Shape          string `json:"shape"`
+	Icon           string `json:"icon"`
+	PrimaryColor   string `json:"primary_color"`
+	SecondaryColor string `json:"secondary_color,omitempty"`
+}
+
+// TeamDescriptionBlock is one titled section of a team's description.
+type TeamDescriptionBlock struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+// TeamSocialLink is a link to a team's page on another platform.
+type TeamSocialLink struct {
+	Platform string `json:"platform"`
+	Url      string `json:"url"`
+}
+
+// TeamDetails is a team together with its profile.
+type TeamDetails struct {
+	*Team
+	Profile *TeamProfile `json:"profile"`
+}
+
+// TeamListDetails is a list of teams with the emblem of each, so clients can draw them without reading their metadata.
+type TeamListDetails struct {
+	*TeamList
+	Teams []*TeamListEntry `json:"teams"`
+}
+
+// TeamListEntry is a team in a list, with its emblem.
+type TeamListEntry struct {
+	*Team
+	Emblem *TeamEmblem `json:"emblem,omitempty"`
+}
+
+// TeamGetRequest is the request payload to get a team with its profile.
+type TeamGetRequest struct {
+	Id string `json:"id"`
+}
+
+// TeamUpdateRequest is the request payload to edit a team. Fields which are left out are unchanged, and an empty list
+// clears the description blocks or social links, as an empty emblem clears the emblem.
+type TeamUpdateRequest struct {
+	Id                string                  `json:"id"`
+	Name              *string                 `json:"name,omitempty"`
+	Desc              *string                 `json:"desc,omitempty"`
+	Open              *bool                   `json:"open,omitempty"`
+	Icon              *string                 `json:"icon,omitempty"`
+	Banner            *string                 `json:"banner,omitempty"`
+	Emblem            *TeamEmblem             `json:"emblem,omitempty"`
+	DescriptionBlocks []*TeamDescriptionBlock `json:"description_blocks,omitempty"`
+	SocialLinks       []*TeamSocialLink       `json:"social_links,omitempty"`
+}
+
+// TeamTreasury is a team's shared wallet, with its latest ledger entries first.
+type TeamTreasury struct {
+	TeamId     string           `json:"team_id"`
+	Currencies map[string]int64 `json:"currencies"`
+	// SpendAllowance is what the member asking can still spend from the treasury today, by currency.
+	SpendAllowance map[string]int64           `json:"spend_allowance,omitempty"`
+	Ledger         []*TeamTreasuryLedgerEntry `json:"ledger"`
+	UpdateTimeSec  int64                      `json:"update_time_sec"`
+}
+
+// TeamTreasuryLedgerEntry records a change to a team's treasury. Currencies are positive for deposits and refunds, and
+// negative for spends.
+type TeamTreasuryLedgerEntry struct {
+	UserId        string            `json:"user_id"`
+	Currencies    map[string]int64  `json:"currencies"`
+	Source        string            `json:"source"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	CreateTimeSec int64             `json:"create_time_sec"`
+}
+
+// TeamTreasuryGetRequest is the request payload to get the treasury of the user's team.
+type TeamTreasuryGetRequest struct {
+	Id string `json:"id"`
+}
+
+// TeamTreasuryDepositRequest is the request payload to deposit currencies from the user's wallet into their team's
+// treasury.
+type TeamTreasuryDepositRequest struct {
+	Id         string           `json:"id"`
+	Currencies map[string]int64 `json:"currencies"`
 }
 
 // A TeamsSystem is a gameplay system which wraps the groups system in Nakama server.
 type TeamsSystem interface {
 	System
 
-	// Create makes a new team (i.e. Nakama group) with additional metadata which configures the team.
+	// Create makes a new team (i.e. Nakama group) with additional metadata which configures the team. An emblem can be
+	// given as "emblem" in the setup metadata.
 	Create(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, req *TeamCreateRequest) (team *Team, err error)
 
 	// List will return a list of teams which the user can join.
@@ -27,6 +186,30 @@ type TeamsSystem interface {
 
 	// WriteChatMessage sends a message to the user's team even when they're not connected on a realtime socket.
 	WriteChatMessage(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, req *TeamWriteChatMessageRequest) (resp *ChannelMessageAck, err error)
+
+	// Get returns a team with its profile.
+	Get(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, teamID string) (team *TeamDetails, err error)
+
+	// Update edits a team the user is an admin of, keeping the Nakama group and its metadata in sync.
+	Update(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, req *TeamUpdateRequest) (team *TeamDetails, err error)
+
+	// SetLevel sets a team's level. Clients can't change the level, so game code calls this as the team progresses.
+	SetLevel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, teamID string, level int64) (team *TeamDetails, err error)
+
+	// GetTreasury returns a team's treasury to one of its members.
+	GetTreasury(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string) (treasury *TeamTreasury, err error)
+
+	// DepositTreasury moves currencies from a member's wallet into their team's treasury.
+	DepositTreasury(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string, currencies map[string]int64) (treasury *TeamTreasury, err error)
+
+	// SpendTreasury takes currencies from a team's treasury on behalf of a member, within their role's daily spend
+	// limits. It returns ErrTeamTreasurySpendLimit when the member can't spend that much, and
+	// ErrTeamTreasuryInsufficient when the treasury doesn't hold it.
+	SpendTreasury(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string, currencies map[string]int64, source string, metadata map[string]string) (treasury *TeamTreasury, err error)
+
+	// RefundTreasury returns currencies a member spent from a team's treasury, such as an outbid auction bid. Refunds
+	// made the same day count back towards the member's spend limits.
+	RefundTreasury(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string, currencies map[string]int64, source string, metadata map[string]string) (treasury *TeamTreasury, err error)
 }
 
 // ValidateCreateTeamFn allows custom rules or velocity checks to be added as a precondition on whether a team is
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"net/url"
 	"strings"
 
 	"github.com/heroiclabs/nakama-common/api"
@@ -12,8 +13,24 @@ import (
 
 const (
 	teamsStorageCollection = "teams"
+
+	defaultTeamMaxDescriptionBlocks = 5
+	defaultTeamMaxSocialLinks       = 5
+)
+
+// Keys of the team profile in the Nakama group metadata. Clients can't set them through the setup metadata.
+const (
+	teamMetadataIcon              = "icon"
+	teamMetadataEmblem            = "emblem"
+	teamMetadataBanner            = "banner"
+	teamMetadataLevel             = "level"
+	teamMetadataDescriptionBlocks = "description_blocks"
+	teamMetadataSocialLinks       = "social_links"
 )
 
+// teamSocialLinkPlatformLimits validates the platform name of a social link.
+var teamSocialLinkPlatformLimits = &TextLimits{MaxLength: 32}
+
 // NakamaTeamsSystem implements the TeamsSystem interface using Nakama groups as the backend.
 type NakamaTeamsSystem struct {
 	config             *TeamsConfig
@@ -68,6 +85,10 @@ func (t *NakamaTeamsSystem) Create(ctx context.Context, logger runtime.Logger, n
 		}
 	}
 
+	if err := t.validateIcon(req.Icon); err != nil {
+		return nil, err
+	}
+
 	// Prepare metadata
 	metadata := make(map[string]interface{})
 	if req.SetupMetadata != "" {
@@ -77,8 +98,23 @@ func (t *NakamaTeamsSystem) Create(ctx context.Context, logger runtime.Logger, n
 		}
 	}
 
+	// The emblem is the only part of the profile set up at creation, and only once it's checked against the catalog
+	emblem, err := t.setupEmblem(metadata[teamMetadataEmblem])
+	if err != nil {
+		return nil, err
+	}
+
+	// The profile is only set through the teams system, so it stays in sync with what was validated
+	for _, key := range []string{teamMetadataEmblem, teamMetadataBanner, teamMetadataLevel, teamMetadataDescriptionBlocks, teamMetadataSocialLinks} {
+		delete(metadata, key)
+	}
+	if emblem != nil {
+		metadata[teamMetadataEmblem] = emblem
+	}
+
 	// Add team-specific metadata
-	metadata["icon"] = req.Icon
+	metadata[teamMetadataIcon] = req.Icon
+	metadata[teamMetadataLevel] = 1
 	metadata["created_by"] = userID
 
 	// Determine max team size
@@ -103,18 +139,23 @@ func (t *NakamaTeamsSystem) Create(ctx context.Context, logger runtime.Logger, n
 // List will return a list of teams which the user can join.
 func (t *NakamaTeamsSystem) List(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, req *TeamListRequest) (*TeamList, error) {
 	// Set default limit if not provided
-	limit := req.Limit
-	if limit <= 0 {
-		limit = 10
+	limit, err := pageLimit(int(req.Limit), 10, maxPageLimit)
+	if err != nil {
+		return nil, err
+	}
+	cursor, history, err := decodeNakamaCursor(req.Cursor)
+	if err != nil {
+		return nil, err
 	}
 
 	// Use Nakama's GroupsList to get available groups
 	open := true // Only show open groups for joining
-	groups, cursor, err := nk.GroupsList(ctx, "", req.Location, nil, &open, int(limit), req.Cursor)
+	groups, groupsCursor, err := nk.GroupsList(ctx, "", req.Location, nil, &open, limit, cursor)
 	if err != nil {
 		logger.Error("Failed to list groups: %v", err)
 		return nil, err
 	}
+	nextCursor, prevCursor := nakamaPageCursors(cursor, history, groupsCursor)
 
 	// Convert groups to teams
 	teams := make([]*Team, 0, len(groups))
@@ -124,8 +165,10 @@ func (t *NakamaTeamsSystem) List(ctx context.Context, logger runtime.Logger, nk
 	}
 
 	return &TeamList{
-		Teams:  teams,
-		Cursor: cursor,
+		Teams:      teams,
+		Cursor:     nextCursor,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
 	}, nil
 }
 
@@ -217,6 +260,15 @@ func (t *NakamaTeamsSystem) WriteChatMessage(ctx context.Context, logger runtime
 		return nil, runtime.NewError("user is not a member of this team", PERMISSION_DENIED_ERROR_CODE) // PERMISSION_DENIED
 	}
 
+	var limits *TextLimits
+	if t.config != nil {
+		limits = t.config.ChatMessageLimits
+	}
+	content, err := moderateText(ctx, logger, nk, t.pamlogix, userID, TextFieldTeamChatMessage, req.Content, limits)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build the channel ID for the group
 	channelID, err := nk.ChannelIdBuild(ctx, userID, req.Id, runtime.Group)
 	if err != nil {
@@ -225,7 +277,7 @@ func (t *NakamaTeamsSystem) WriteChatMessage(ctx context.Context, logger runtime
 	}
 
 	// Send the message to the group channel
-	ack, err := nk.ChannelMessageSend(ctx, channelID, map[string]interface{}{"content": req.Content}, "", userID, true)
+	ack, err := nk.ChannelMessageSend(ctx, channelID, map[string]interface{}{"content": content}, "", userID, true)
 	if err != nil {
 		logger.Error("Failed to send channel message: %v", err)
 		return nil, err
@@ -268,6 +320,341 @@ func (t *NakamaTeamsSystem) WriteChatMessage(ctx context.Context, logger runtime
 	}, nil
 }
 
+// Get returns a team with its profile.
+func (t *NakamaTeamsSystem) Get(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, teamID string) (*TeamDetails, error) {
+	if teamID == "" {
+		return nil, runtime.NewError("team id is required", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	group, err := t.getGroup(ctx, logger, nk, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return t.convertGroupToTeamDetails(group), nil
+}
+
+// Update edits a team the user is an admin of. The group's fields and the profile in its metadata are written together
+// in one group update.
+func (t *NakamaTeamsSystem) Update(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, req *TeamUpdateRequest) (*TeamDetails, error) {
+	if req.Id == "" {
+		return nil, runtime.NewError("team id is required", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+	}
+
+	group, err := t.getGroup(ctx, logger, nk, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	isAdmin, err := t.checkTeamAdmin(ctx, logger, nk, userID, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrTeamNotAdmin
+	}
+
+	metadata := teamGroupMetadata(group)
+	name := ""
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, runtime.NewError("team name is required", INVALID_ARGUMENT_ERROR_CODE) // INVALID_ARGUMENT
+		}
+		name = *req.Name
+	}
+	desc := ""
+	if req.Desc != nil {
+		if desc, err = moderateText(ctx, logger, nk, t.pamlogix, userID, TextFieldTeamProfile, *req.Desc, nil); err != nil {
+			return nil, err
+		}
+	}
+	open := group.Open != nil && group.Open.Value
+	if req.Open != nil {
+		open = *req.Open
+	}
+	if req.Icon != nil {
+		if err := t.validateIcon(*req.Icon); err != nil {
+			return nil, err
+		}
+		metadata[teamMetadataIcon] = *req.Icon
+	}
+	if req.Banner != nil {
+		if err := validateText(*req.Banner, nil); err != nil {
+			return nil, err
+		}
+		if err := t.validateBanner(*req.Banner); err != nil {
+			return nil, err
+		}
+		metadata[teamMetadataBanner] = *req.Banner
+	}
+	if req.Emblem != nil {
+		emblem, err := t.validateEmblem(req.Emblem)
+		if err != nil {
+			return nil, err
+		}
+		if emblem == nil {
+			delete(metadata, teamMetadataEmblem)
+		} else {
+			metadata[teamMetadataEmblem] = emblem
+		}
+	}
+	if req.DescriptionBlocks != nil {
+		blocks, err := t.validateDescriptionBlocks(ctx, logger, nk, userID, req.DescriptionBlocks)
+		if err != nil {
+			return nil, err
+		}
+		metadata[teamMetadataDescriptionBlocks] = blocks
+	}
+	if req.SocialLinks != nil {
+		if err := t.validateSocialLinks(req.SocialLinks); err != nil {
+			return nil, err
+		}
+		metadata[teamMetadataSocialLinks] = req.SocialLinks
+	}
+
+	if err := nk.GroupUpdate(ctx, group.Id, userID, name, "", "", desc, "", open, metadata, 0); err != nil {
+		logger.Error("Failed to update Nakama group %s: %v", group.Id, err)
+		return nil, err
+	}
+
+	return t.Get(ctx, logger, nk, group.Id)
+}
+
+// SetLevel sets a team's level in its group metadata.
+func (t *NakamaTeamsSystem) SetLevel(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, teamID string, level int64) (*TeamDetails, error) {
+	if level < 0 {
+		return nil, ErrTeamProfileInvalid
+	}
+
+	group, err := t.getGroup(ctx, logger, nk, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := teamGroupMetadata(group)
+	metadata[teamMetadataLevel] = level
+	open := group.Open != nil && group.Open.Value
+	if err := nk.GroupUpdate(ctx, group.Id, "", "", "", "", "", "", open, metadata, 0); err != nil {
+		logger.Error("Failed to update level of Nakama group %s: %v", group.Id, err)
+		return nil, err
+	}
+
+	return t.Get(ctx, logger, nk, group.Id)
+}
+
+func (t *NakamaTeamsSystem) getGroup(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, teamID string) (*api.Group, error) {
+	groups, err := nk.GroupsGetId(ctx, []string{teamID})
+	if err != nil {
+		logger.Error("Failed to get Nakama group %s: %v", teamID, err)
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, ErrTeamNotFound
+	}
+	return groups[0], nil
+}
+
+// checkTeamAdmin reports whether the user is an admin or the superadmin of the team.
+func (t *NakamaTeamsSystem) checkTeamAdmin(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, teamID string) (bool, error) {
+	userGroups, _, err := nk.UserGroupsList(ctx, userID, 10, nil, "")
+	if err != nil {
+		logger.Error("Failed to get user groups for admin check: %v", err)
+		return false, err
+	}
+
+	for _, userGroup := range userGroups {
+		if userGroup.Group.Id == teamID {
+			return userGroup.State != nil && userGroup.State.Value <= int32(api.UserGroupList_UserGroup_ADMIN), nil
+		}
+	}
+	return false, nil
+}
+
+func (t *NakamaTeamsSystem) profileLimits() *TeamsConfigProfileLimits {
+	if t.config == nil || t.config.ProfileLimits == nil {
+		return &TeamsConfigProfileLimits{}
+	}
+	return t.config.ProfileLimits
+}
+
+// validateDescriptionBlocks checks the blocks against the profile limits and returns them as moderated.
+func (t *NakamaTeamsSystem) validateDescriptionBlocks(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, blocks []*TeamDescriptionBlock) ([]*TeamDescriptionBlock, error) {
+	limits := t.profileLimits()
+	maxBlocks := limits.MaxDescriptionBlocks
+	if maxBlocks <= 0 {
+		maxBlocks = defaultTeamMaxDescriptionBlocks
+	}
+	if len(blocks) > maxBlocks {
+		return nil, ErrTeamProfileInvalid
+	}
+
+	moderated := make([]*TeamDescriptionBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if block == nil || (block.Title == "" && block.Body == "") {
+			return nil, ErrTeamProfileInvalid
+		}
+		title, err := moderateText(ctx, logger, nk, t.pamlogix, userID, TextFieldTeamProfile, block.Title, limits.DescriptionBlockTitle)
+		if err != nil {
+			return nil, err
+		}
+		body, err := moderateText(ctx, logger, nk, t.pamlogix, userID, TextFieldTeamProfile, block.Body, limits.DescriptionBlockBody)
+		if err != nil {
+			return nil, err
+		}
+		moderated = append(moderated, &TeamDescriptionBlock{Title: title, Body: body})
+	}
+	return moderated, nil
+}
+
+// validateSocialLinks checks that each link is an https URL to an allowed host.
+func (t *NakamaTeamsSystem) validateSocialLinks(links []*TeamSocialLink) error {
+	limits := t.profileLimits()
+	maxLinks := limits.MaxSocialLinks
+	if maxLinks <= 0 {
+		maxLinks = defaultTeamMaxSocialLinks
+	}
+	if len(links) > maxLinks {
+		return ErrTeamProfileInvalid
+	}
+
+	for _, link := range links {
+		if link == nil || link.Platform == "" {
+			return ErrTeamProfileInvalid
+		}
+		if err := validateText(link.Platform, teamSocialLinkPlatformLimits); err != nil {
+			return err
+		}
+		parsed, err := url.Parse(link.Url)
+		if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" || parsed.User != nil {
+			return ErrTeamProfileInvalid
+		}
+		if len(limits.SocialLinkHosts) > 0 && !teamSocialLinkHostAllowed(parsed.Hostname(), limits.SocialLinkHosts) {
+			return ErrTeamProfileInvalid
+		}
+	}
+	return nil
+}
+
+// validateEmblem checks that every part of the emblem is in the catalog. An empty emblem is valid, and is returned as
+// nil to clear the team's emblem.
+func (t *NakamaTeamsSystem) validateEmblem(emblem *TeamEmblem) (*TeamEmblem, error) {
+	if emblem == nil || *emblem == (TeamEmblem{}) {
+		return nil, nil
+	}
+
+	catalog := t.emblemCatalog()
+	if catalog == nil {
+		return nil, ErrTeamEmblemInvalid
+	}
+	if !teamEmblemPartExists(catalog.Shapes, emblem.Shape) || !teamEmblemPartExists(catalog.Icons, emblem.Icon) ||
+		!teamEmblemPartExists(catalog.Colors, emblem.PrimaryColor) {
+		return nil, ErrTeamEmblemInvalid
+	}
+	if emblem.SecondaryColor != "" && !teamEmblemPartExists(catalog.Colors, emblem.SecondaryColor) {
+		return nil, ErrTeamEmblemInvalid
+	}
+	return emblem, nil
+}
+
+// setupEmblem validates an emblem given in the setup metadata of a new team.
+func (t *NakamaTeamsSystem) setupEmblem(value interface{}) (*TeamEmblem, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, ErrTeamEmblemInvalid
+	}
+	emblem := &TeamEmblem{}
+	if err := json.Unmarshal(data, emblem); err != nil {
+		return nil, ErrTeamEmblemInvalid
+	}
+	return t.validateEmblem(emblem)
+}
+
+// validateIcon checks a team icon is in the catalog, when teams use one.
+func (t *NakamaTeamsSystem) validateIcon(icon string) error {
+	if catalog := t.emblemCatalog(); catalog != nil && icon != "" && !teamEmblemPartExists(catalog.Icons, icon) {
+		return ErrTeamEmblemInvalid
+	}
+	return nil
+}
+
+// validateBanner checks a team banner is in the catalog, when teams use one.
+func (t *NakamaTeamsSystem) validateBanner(banner string) error {
+	if catalog := t.emblemCatalog(); catalog != nil && banner != "" && !teamEmblemPartExists(catalog.Banners, banner) {
+		return ErrTeamEmblemInvalid
+	}
+	return nil
+}
+
+func (t *NakamaTeamsSystem) emblemCatalog() *TeamsConfigEmblems {
+	if t.config == nil {
+		return nil
+	}
+	return t.config.Emblems
+}
+
+func teamEmblemPartExists(parts map[string]*TeamsConfigEmblemPart, id string) bool {
+	if id == "" {
+		return false
+	}
+	_, found := parts[id]
+	return found
+}
+
+func teamSocialLinkHostAllowed(host string, allowedHosts []string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// teamGroupMetadata returns the group's metadata, or empty metadata if it has none or it can't be parsed.
+func teamGroupMetadata(group *api.Group) map[string]interface{} {
+	metadata := make(map[string]interface{})
+	if group.Metadata != "" {
+		if err := json.Unmarshal([]byte(group.Metadata), &metadata); err != nil {
+			return make(map[string]interface{})
+		}
+	}
+	return metadata
+}
+
+func (t *NakamaTeamsSystem) convertGroupToTeamDetails(group *api.Group) *TeamDetails {
+	profile := &TeamProfile{}
+	if group.Metadata != "" {
+		// Metadata written before profiles existed may not match, which leaves the profile empty
+		_ = json.Unmarshal([]byte(group.Metadata), profile)
+	}
+	return &TeamDetails{
+		Team:    t.convertGroupToTeam(group, ""),
+		Profile: profile,
+	}
+}
+
+// newTeamListDetails adds the emblem of each team in the list from its metadata.
+func newTeamListDetails(list *TeamList) *TeamListDetails {
+	details := &TeamListDetails{
+		TeamList: list,
+		Teams:    make([]*TeamListEntry, 0, len(list.GetTeams())),
+	}
+	for _, team := range list.GetTeams() {
+		entry := &TeamListEntry{Team: team}
+		if team.GetMetadata() != "" {
+			profile := &TeamProfile{}
+			if err := json.Unmarshal([]byte(team.GetMetadata()), profile); err == nil {
+				entry.Emblem = profile.Emblem
+			}
+		}
+		details.Teams = append(details.Teams, entry)
+	}
+	return details
+}
+
 // Helper function to convert Nakama Group to Team
 func (t *NakamaTeamsSystem) convertGroupToTeam(group *api.Group, iconOverride string) *Team {
 	// Parse metadata to extract icon
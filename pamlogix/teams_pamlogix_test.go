@@ -0,0 +1,303 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// groupsNakama keeps Nakama groups and the role of each member in memory.
+type groupsNakama struct {
+	*benchNakama
+	groups map[string]*api.Group
+	roles  map[string]map[string]api.UserGroupList_UserGroup_State
+}
+
+func newGroupsNakama() *groupsNakama {
+	return &groupsNakama{
+		benchNakama: newBenchNakama(),
+		groups:      make(map[string]*api.Group),
+		roles:       make(map[string]map[string]api.UserGroupList_UserGroup_State),
+	}
+}
+
+func (n *groupsNakama) GroupCreate(ctx context.Context, userID, name, creatorID, langTag, description, avatarUrl string, open bool, metadata map[string]interface{}, maxCount int) (*api.Group, error) {
+	value, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	group := &api.Group{
+		Id:          name,
+		CreatorId:   creatorID,
+		Name:        name,
+		Description: description,
+		Metadata:    string(value),
+		Open:        wrapperspb.Bool(open),
+		MaxCount:    int32(maxCount),
+		CreateTime:  timestamppb.Now(),
+		UpdateTime:  timestamppb.Now(),
+	}
+	n.groups[group.Id] = group
+	n.roles[group.Id] = map[string]api.UserGroupList_UserGroup_State{userID: api.UserGroupList_UserGroup_SUPERADMIN}
+	return group, nil
+}
+
+func (n *groupsNakama) GroupsGetId(ctx context.Context, groupIDs []string) ([]*api.Group, error) {
+	groups := make([]*api.Group, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		if group, found := n.groups[groupID]; found {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+func (n *groupsNakama) GroupUpdate(ctx context.Context, id, userID, name, creatorID, langTag, description, avatarUrl string, open bool, metadata map[string]interface{}, maxCount int) error {
+	group := n.groups[id]
+	if name != "" {
+		group.Name = name
+	}
+	if description != "" {
+		group.Description = description
+	}
+	group.Open = wrapperspb.Bool(open)
+	if metadata != nil {
+		value, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		group.Metadata = string(value)
+	}
+	return nil
+}
+
+func (n *groupsNakama) UserGroupsList(ctx context.Context, userID string, limit int, state *int, cursor string) ([]*api.UserGroupList_UserGroup, string, error) {
+	var userGroups []*api.UserGroupList_UserGroup
+	for groupID, roles := range n.roles {
+		if role, found := roles[userID]; found {
+			userGroups = append(userGroups, &api.UserGroupList_UserGroup{Group: n.groups[groupID], State: wrapperspb.Int32(int32(role))})
+		}
+	}
+	return userGroups, "", nil
+}
+
+func TestTeamsUpdate_SyncsGroupMetadata(t *testing.T) {
+	logger := &mockLogger{}
+	nk := newGroupsNakama()
+	ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, "owner")
+	teamsSystem := NewNakamaTeamsSystem(&TeamsConfig{
+		ProfileLimits: &TeamsConfigProfileLimits{SocialLinkHosts: []string{"discord.gg"}},
+	})
+
+	// Setup metadata can't set profile fields
+	_, err := teamsSystem.Create(ctx, logger, nk, &TeamCreateRequest{Name: "dragons", Icon: "icon_a", Open: true, SetupMetadata: `{"level":99,"motto":"fly"}`})
+	require.NoError(t, err)
+	nk.roles["dragons"]["member"] = api.UserGroupList_UserGroup_MEMBER
+
+	closed := false
+	banner := "banner_dragon"
+	team, err := teamsSystem.Update(ctx, logger, nk, "owner", &TeamUpdateRequest{
+		Id:                "dragons",
+		Open:              &closed,
+		Banner:            &banner,
+		DescriptionBlocks: []*TeamDescriptionBlock{{Title: "About", Body: "Daily raids"}},
+		SocialLinks:       []*TeamSocialLink{{Platform: "discord", Url: "https://discord.gg/dragons"}},
+	})
+	require.NoError(t, err)
+	assert.False(t, team.Open)
+	assert.Equal(t, "icon_a", team.Icon)
+	assert.Equal(t, &TeamProfile{
+		Banner:            "banner_dragon",
+		Level:             1,
+		DescriptionBlocks: []*TeamDescriptionBlock{{Title: "About", Body: "Daily raids"}},
+		SocialLinks:       []*TeamSocialLink{{Platform: "discord", Url: "https://discord.gg/dragons"}},
+	}, team.Profile)
+	assert.Contains(t, nk.groups["dragons"].Metadata, `"motto":"fly"`)
+
+	team, err = teamsSystem.SetLevel(ctx, logger, nk, "dragons", 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), team.Profile.Level)
+	assert.Equal(t, "banner_dragon", team.Profile.Banner)
+
+	_, err = teamsSystem.Update(ctx, logger, nk, "member", &TeamUpdateRequest{Id: "dragons", Banner: &banner})
+	assert.ErrorIs(t, err, ErrTeamNotAdmin)
+}
+
+func TestTeamsUpdate_ValidatesProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *TeamUpdateRequest
+	}{
+		{name: "link host not allowed", req: &TeamUpdateRequest{SocialLinks: []*TeamSocialLink{{Platform: "web", Url: "https://evil.example/x"}}}},
+		{name: "link not https", req: &TeamUpdateRequest{SocialLinks: []*TeamSocialLink{{Platform: "discord", Url: "http://discord.gg/x"}}}},
+		{name: "link without platform", req: &TeamUpdateRequest{SocialLinks: []*TeamSocialLink{{Url: "https://discord.gg/x"}}}},
+		{name: "too many blocks", req: &TeamUpdateRequest{DescriptionBlocks: []*TeamDescriptionBlock{{Title: "a"}, {Title: "b"}, {Title: "c"}}}},
+		{name: "empty block", req: &TeamUpdateRequest{DescriptionBlocks: []*TeamDescriptionBlock{{}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &mockLogger{}
+			nk := newGroupsNakama()
+			ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, "owner")
+			teamsSystem := NewNakamaTeamsSystem(&TeamsConfig{
+				ProfileLimits: &TeamsConfigProfileLimits{MaxDescriptionBlocks: 2, SocialLinkHosts: []string{"discord.gg"}},
+			})
+			_, err := teamsSystem.Create(ctx, logger, nk, &TeamCreateRequest{Name: "dragons"})
+			require.NoError(t, err)
+			metadata := nk.groups["dragons"].Metadata
+
+			tt.req.Id = "dragons"
+			_, err = teamsSystem.Update(ctx, logger, nk, "owner", tt.req)
+			assert.ErrorIs(t, err, ErrTeamProfileInvalid)
+			assert.Equal(t, metadata, nk.groups["dragons"].Metadata)
+		})
+	}
+}
+
+func TestTeamsEmblems(t *testing.T) {
+	logger := &mockLogger{}
+	nk := newGroupsNakama()
+	ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, "owner")
+	teamsSystem := NewNakamaTeamsSystem(&TeamsConfig{
+		Emblems: &TeamsConfigEmblems{
+			Shapes:  map[string]*TeamsConfigEmblemPart{"shield": {Value: "shape_shield"}},
+			Colors:  map[string]*TeamsConfigEmblemPart{"red": {Value: "#ff0000"}, "gold": {Value: "#ffd700"}},
+			Icons:   map[string]*TeamsConfigEmblemPart{"dragon": {Value: "icon_dragon"}, "wolf": {Value: "icon_wolf"}},
+			Banners: map[string]*TeamsConfigEmblemPart{"flames": {Value: "banner_flames"}},
+		},
+	})
+
+	// Icons and emblems outside the catalog are rejected
+	_, err := teamsSystem.Create(ctx, logger, nk, &TeamCreateRequest{Name: "dragons", Icon: "https://example.com/x.png"})
+	assert.ErrorIs(t, err, ErrTeamEmblemInvalid)
+	_, err = teamsSystem.Create(ctx, logger, nk, &TeamCreateRequest{Name: "dragons", SetupMetadata: `{"emblem":{"shape":"circle","icon":"dragon","primary_color":"red"}}`})
+	assert.ErrorIs(t, err, ErrTeamEmblemInvalid)
+	assert.Empty(t, nk.groups)
+
+	created, err := teamsSystem.Create(ctx, logger, nk, &TeamCreateRequest{Name: "dragons", Icon: "dragon", SetupMetadata: `{"emblem":{"shape":"shield","icon":"dragon","primary_color":"red"}}`})
+	require.NoError(t, err)
+	team, err := teamsSystem.Get(ctx, logger, nk, created.Id)
+	require.NoError(t, err)
+	assert.Equal(t, &TeamEmblem{Shape: "shield", Icon: "dragon", PrimaryColor: "red"}, team.Profile.Emblem)
+
+	for _, emblem := range []*TeamEmblem{
+		{Shape: "shield", Icon: "dragon"},
+		{Shape: "shield", Icon: "dragon", PrimaryColor: "red", SecondaryColor: "blue"},
+		{Shape: "shield", Icon: "https://example.com/x.png", PrimaryColor: "red"},
+	} {
+		_, err = teamsSystem.Update(ctx, logger, nk, "owner", &TeamUpdateRequest{Id: "dragons", Emblem: emblem})
+		assert.ErrorIs(t, err, ErrTeamEmblemInvalid)
+	}
+	banner := "https://example.com/banner.png"
+	_, err = teamsSystem.Update(ctx, logger, nk, "owner", &TeamUpdateRequest{Id: "dragons", Banner: &banner})
+	assert.ErrorIs(t, err, ErrTeamEmblemInvalid)
+
+	banner = "flames"
+	team, err = teamsSystem.Update(ctx, logger, nk, "owner", &TeamUpdateRequest{
+		Id:     "dragons",
+		Banner: &banner,
+		Emblem: &TeamEmblem{Shape: "shield", Icon: "wolf", PrimaryColor: "red", SecondaryColor: "gold"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "flames", team.Profile.Banner)
+	assert.Equal(t, &TeamEmblem{Shape: "shield", Icon: "wolf", PrimaryColor: "red", SecondaryColor: "gold"}, team.Profile.Emblem)
+
+	// Lists carry the emblem of each team
+	list := newTeamListDetails(&TeamList{Teams: []*Team{team.Team, {Id: "plain"}}})
+	require.Len(t, list.Teams, 2)
+	assert.Equal(t, team.Profile.Emblem, list.Teams[0].Emblem)
+	assert.Nil(t, list.Teams[1].Emblem)
+
+	// An empty emblem clears it
+	team, err = teamsSystem.Update(ctx, logger, nk, "owner", &TeamUpdateRequest{Id: "dragons", Emblem: &TeamEmblem{}})
+	require.NoError(t, err)
+	assert.Nil(t, team.Profile.Emblem)
+}
+
+// newTreasuryPamlogix returns a bench Pamlogix whose teams have treasuries, with officers allowed 50 and members 10
+// coins a day.
+func newTreasuryPamlogix() (*pamlogixImpl, *NakamaTeamsSystem) {
+	p := newBenchPamlogix()
+	teamsSystem := NewNakamaTeamsSystem(&TeamsConfig{
+		Treasury: &TeamsConfigTreasury{
+			DailySpendLimits: map[string]map[string]int64{
+				TeamRoleSuperadmin: {benchCurrency: 50},
+				TeamRoleMember:     {benchCurrency: 10},
+			},
+			LedgerMaxEntries: 3,
+		},
+	})
+	teamsSystem.SetPamlogix(p)
+	p.systems[SystemTypeTeams] = teamsSystem
+	return p, teamsSystem
+}
+
+func TestTeamsTreasury(t *testing.T) {
+	logger := &mockLogger{}
+	nk := newGroupsNakama()
+	ctx := context.WithValue(context.Background(), runtime.RUNTIME_CTX_USER_ID, "owner")
+	_, teamsSystem := newTreasuryPamlogix()
+
+	_, err := teamsSystem.Create(ctx, logger, nk, &TeamCreateRequest{Name: "dragons"})
+	require.NoError(t, err)
+	nk.roles["dragons"]["member"] = api.UserGroupList_UserGroup_MEMBER
+	_, _, err = nk.WalletUpdate(ctx, "owner", map[string]int64{benchCurrency: 100}, nil, false)
+	require.NoError(t, err)
+
+	// Deposits move currency from the member's wallet into the treasury
+	treasury, err := teamsSystem.DepositTreasury(ctx, logger, nk, "owner", "dragons", map[string]int64{benchCurrency: 80})
+	require.NoError(t, err)
+	assert.Equal(t, int64(80), treasury.Currencies[benchCurrency])
+	assert.Equal(t, int64(50), treasury.SpendAllowance[benchCurrency])
+	wallet, err := userWallet(ctx, nk, "owner")
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), wallet[benchCurrency])
+	_, err = teamsSystem.DepositTreasury(ctx, logger, nk, "owner", "dragons", map[string]int64{benchCurrency: 100})
+	assert.Error(t, err)
+
+	// Spending is held to each role's daily limit
+	_, err = teamsSystem.SpendTreasury(ctx, logger, nk, "member", "dragons", map[string]int64{benchCurrency: 11}, "test", nil)
+	assert.ErrorIs(t, err, ErrTeamTreasurySpendLimit)
+	_, err = teamsSystem.SpendTreasury(ctx, logger, nk, "member", "dragons", map[string]int64{"gems": 1}, "test", nil)
+	assert.ErrorIs(t, err, ErrTeamTreasurySpendLimit)
+	treasury, err = teamsSystem.SpendTreasury(ctx, logger, nk, "member", "dragons", map[string]int64{benchCurrency: 10}, "test", map[string]string{"note": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(70), treasury.Currencies[benchCurrency])
+	assert.Equal(t, int64(0), treasury.SpendAllowance[benchCurrency])
+	assert.Equal(t, &TeamTreasuryLedgerEntry{UserId: "member", Currencies: map[string]int64{benchCurrency: -10}, Source: "test", Metadata: map[string]string{"note": "x"}, CreateTimeSec: treasury.Ledger[0].CreateTimeSec}, treasury.Ledger[0])
+
+	// Refunds give back what was spent, including the member's allowance for the day
+	treasury, err = teamsSystem.RefundTreasury(ctx, logger, nk, "member", "dragons", map[string]int64{benchCurrency: 4}, "test_refund", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(74), treasury.Currencies[benchCurrency])
+	assert.Equal(t, int64(4), treasury.SpendAllowance[benchCurrency])
+
+	_, err = teamsSystem.SpendTreasury(ctx, logger, nk, "owner", "dragons", map[string]int64{benchCurrency: 50}, "test", nil)
+	require.NoError(t, err)
+	_, err = teamsSystem.SpendTreasury(ctx, logger, nk, "member", "dragons", map[string]int64{benchCurrency: 4}, "test", nil)
+	require.NoError(t, err)
+	_, err = teamsSystem.DepositTreasury(ctx, logger, nk, "owner", "dragons", map[string]int64{benchCurrency: 5})
+	require.NoError(t, err)
+	_, err = teamsSystem.SpendTreasury(ctx, logger, nk, "member", "dragons", map[string]int64{benchCurrency: 1}, "test", nil)
+	assert.ErrorIs(t, err, ErrTeamTreasurySpendLimit)
+
+	// The ledger keeps the latest entries first
+	treasury, err = teamsSystem.GetTreasury(ctx, logger, nk, "owner", "dragons")
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), treasury.Currencies[benchCurrency])
+	require.Len(t, treasury.Ledger, 3)
+	assert.Equal(t, "deposit", treasury.Ledger[0].Source)
+
+	_, err = teamsSystem.GetTreasury(ctx, logger, nk, "stranger", "dragons")
+	assert.ErrorIs(t, err, ErrTeamNotMember)
+	_, err = teamsSystem.SpendTreasury(ctx, logger, nk, "stranger", "dragons", map[string]int64{benchCurrency: 1}, "test", nil)
+	assert.ErrorIs(t, err, ErrTeamNotMember)
+}
@@ -11,6 +11,9 @@ import (
 const (
 	tutorialsStorageCollection = "tutorials"
 	userTutorialsStorageKey    = "user_tutorials"
+
+	// tutorialCompletedEvent is the publisher event sent when a user completes a tutorial.
+	tutorialCompletedEvent = "tutorial_completed"
 )
 
 // NakamaTutorialsSystem implements the TutorialsSystem interface using Nakama as the backend.
@@ -265,6 +268,7 @@ func (t *NakamaTutorialsSystem) Update(ctx context.Context, logger runtime.Logge
 	}
 
 	// Update tutorial progress
+	wasCompleted := tutorial.State == TutorialState_TUTORIAL_STATE_COMPLETED
 	tutorial.Current = int32(step)
 	tutorial.UpdateTimeSec = time.Now().Unix()
 
@@ -292,6 +296,17 @@ func (t *NakamaTutorialsSystem) Update(ctx context.Context, logger runtime.Logge
 		t.onStepCompleted(ctx, logger, nk, userID, tutorialID, tutorialConfig, resetCount, step, prevStep)
 	}
 
+	if !wasCompleted && tutorial.State == TutorialState_TUTORIAL_STATE_COMPLETED && t.pamlogix != nil {
+		t.pamlogix.SendPublisherEvents(ctx, logger, nk, userID, []*PublisherEvent{{
+			Name:      tutorialCompletedEvent,
+			Id:        tutorialID,
+			Timestamp: tutorial.CompleteTimeSec,
+			System:    t,
+			SourceId:  tutorialID,
+			Source:    tutorialConfig,
+		}})
+	}
+
 	// Return all tutorials
 	return t.Get(ctx, logger, nk, userID)
 }
@@ -342,19 +357,9 @@ func (t *NakamaTutorialsSystem) SetOnStepCompleted(fn func(ctx context.Context,
 
 // getUserTutorials retrieves the user's tutorial progress from storage
 func (t *NakamaTutorialsSystem) getUserTutorials(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string) (map[string]*Tutorial, error) {
-	collection := tutorialsStorageCollection
-	if t.pamlogix != nil && t.pamlogix.(*pamlogixImpl).collectionResolver != nil {
-		resolvedCollection, err := t.pamlogix.(*pamlogixImpl).collectionResolver(ctx, SystemTypeTutorials, collection)
-		if err != nil {
-			logger.Warn("Failed to resolve collection name: %v", err)
-		} else {
-			collection = resolvedCollection
-		}
-	}
-
 	objects, err := nk.StorageRead(ctx, []*runtime.StorageRead{
 		{
-			Collection: collection,
+			Collection: tutorialsStorageCollection,
 			Key:        userTutorialsStorageKey,
 			UserID:     userID,
 		},
@@ -377,16 +382,6 @@ func (t *NakamaTutorialsSystem) getUserTutorials(ctx context.Context, logger run
 
 // saveUserTutorials saves the user's tutorial progress to storage
 func (t *NakamaTutorialsSystem) saveUserTutorials(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, userTutorials map[string]*Tutorial) error {
-	collection := tutorialsStorageCollection
-	if t.pamlogix != nil && t.pamlogix.(*pamlogixImpl).collectionResolver != nil {
-		resolvedCollection, err := t.pamlogix.(*pamlogixImpl).collectionResolver(ctx, SystemTypeTutorials, collection)
-		if err != nil {
-			logger.Warn("Failed to resolve collection name: %v", err)
-		} else {
-			collection = resolvedCollection
-		}
-	}
-
 	data, err := json.Marshal(userTutorials)
 	if err != nil {
 		logger.Error("Failed to marshal user tutorials: %v", err)
@@ -395,7 +390,7 @@ func (t *NakamaTutorialsSystem) saveUserTutorials(ctx context.Context, logger ru
 
 	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{
 		{
-			Collection: collection,
+			Collection: tutorialsStorageCollection,
 			Key:        userTutorialsStorageKey,
 			UserID:     userID,
 			Value:      string(data),
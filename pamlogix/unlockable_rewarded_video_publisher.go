@@ -6,9 +6,15 @@ import (
 	"github.com/heroiclabs/nakama-common/runtime"
 )
 
-// UnlockableRewardedVideoPublisher listens for rewarded video unlock events and unlocks the unlockable.
+// unlockableRewardedVideoPlacementID is the placement which unlocks the unlockable it names when no placements are
+// configured.
+const unlockableRewardedVideoPlacementID = "unlockable_rewarded_video"
+
+// UnlockableRewardedVideoPublisher listens for rewarded video placement events and speeds up or unlocks the unlockable.
 type UnlockableRewardedVideoPublisher struct {
 	Unlockables UnlockablesSystem
+	// Config maps placements to speed-ups. Without it, the default placement purchases the unlock.
+	Config *UnlockablesConfigRewardedVideo
 }
 
 func (p *UnlockableRewardedVideoPublisher) Authenticate(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, created bool) {
@@ -16,22 +22,53 @@ func (p *UnlockableRewardedVideoPublisher) Authenticate(ctx context.Context, log
 }
 
 func (p *UnlockableRewardedVideoPublisher) Send(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, events []*PublisherEvent) {
+	if p.Unlockables == nil || (p.Config != nil && p.Config.Disabled) {
+		return
+	}
+
 	for _, event := range events {
-		if event.Name == "placement_success" {
-			// Check if this placement is for unlockables (by placement_id or other metadata)
-			placementID := ""
-			instanceID := ""
-			if event.Metadata != nil {
-				placementID = event.Metadata["placement_id"]
-				instanceID = event.Metadata["instance_id"]
+		if event.Name != "placement_success" || event.Metadata == nil {
+			continue
+		}
+		placementID := event.Metadata["placement_id"]
+		instanceID := event.Metadata["instance_id"]
+		if instanceID == "" {
+			continue
+		}
+
+		if p.Config == nil || len(p.Config.Placements) == 0 {
+			if placementID != unlockableRewardedVideoPlacementID {
+				continue
 			}
-			// You may want to check placementID against a known unlockable placement ID
-			if placementID == "unlockable_rewarded_video" && instanceID != "" && p.Unlockables != nil {
-				_, err := p.Unlockables.PurchaseUnlock(ctx, logger, nk, userID, instanceID)
-				if err != nil {
-					logger.Error("Failed to instantly unlock unlockable via rewarded video: %v", err)
-				}
+			if _, err := p.Unlockables.PurchaseUnlock(ctx, logger, nk, userID, instanceID); err != nil {
+				logger.Error("Failed to instantly unlock unlockable via rewarded video: %v", err)
 			}
+			continue
+		}
+
+		seconds, found := p.Config.Placements[placementID]
+		if !found {
+			continue
+		}
+		if err := p.advance(ctx, logger, nk, userID, instanceID, seconds); err != nil {
+			logger.Error("Failed to speed up unlockable %s via rewarded video placement %s: %v", instanceID, placementID, err)
+		}
+	}
+}
+
+// advance speeds up the unlock with the built-in system's rewarded video caps. Other systems are advanced directly, or
+// have the unlock purchased when the placement takes off the whole wait.
+func (p *UnlockableRewardedVideoPublisher) advance(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, instanceID string, seconds int64) error {
+	var err error
+	switch unlockables := p.Unlockables.(type) {
+	case *UnlockablesPamlogix:
+		_, err = unlockables.rewardedVideoAdvance(ctx, logger, nk, userID, instanceID, seconds)
+	default:
+		if seconds > 0 {
+			_, err = unlockables.UnlockAdvance(ctx, logger, nk, userID, instanceID, seconds)
+		} else {
+			_, err = unlockables.PurchaseUnlock(ctx, logger, nk, userID, instanceID)
 		}
 	}
+	return err
 }
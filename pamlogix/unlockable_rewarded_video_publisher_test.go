@@ -593,3 +593,90 @@ func TestUnlockableRewardedVideoPublisher_Send_DuplicateEvents(t *testing.T) {
 	// Verify the mock was called correctly
 	mockUnlockables.AssertExpectations(t)
 }
+
+// Test Send method with the publisher disabled in config
+func TestUnlockableRewardedVideoPublisher_Send_Disabled(t *testing.T) {
+	mockUnlockables := &mockUnlockablesSystem{}
+	publisher := &pamlogix.UnlockableRewardedVideoPublisher{
+		Unlockables: mockUnlockables,
+		Config:      &pamlogix.UnlockablesConfigRewardedVideo{Disabled: true},
+	}
+
+	events := []*pamlogix.PublisherEvent{
+		{
+			Name: "placement_success",
+			Metadata: map[string]string{
+				"placement_id": "unlockable_rewarded_video",
+				"instance_id":  "test_instance",
+			},
+		},
+	}
+
+	// Should not call PurchaseUnlock since the publisher is disabled
+	publisher.Send(context.Background(), &testLoggerPublisher{}, nil, "test_user", events)
+
+	mockUnlockables.AssertExpectations(t)
+}
+
+// Test Send method with configured placements speeding up an unlockable up to its cap
+func TestUnlockableRewardedVideoPublisher_Send_ConfiguredPlacements(t *testing.T) {
+	config := &pamlogix.UnlockablesConfig{
+		ActiveSlots: 1,
+		Slots:       2,
+		Unlockables: map[string]*pamlogix.UnlockablesConfigUnlockable{
+			"chest1": {
+				Probability:         10,
+				WaitTimeSec:         900,
+				MaxRewardedVideoSec: 400,
+			},
+		},
+	}
+	unlockablesSystem := pamlogix.NewUnlockablesSystem(config)
+	publisher := &pamlogix.UnlockableRewardedVideoPublisher{
+		Unlockables: unlockablesSystem,
+		Config: &pamlogix.UnlockablesConfigRewardedVideo{
+			Placements: map[string]int64{"unlockable_speed_up": 300},
+		},
+	}
+
+	ctx := context.Background()
+	logger := &testLoggerPublisher{}
+	nk := pamlogix.NewTestUnlockablesNakama(t)
+	userID := "test_user"
+
+	unlockables, err := unlockablesSystem.Create(ctx, logger, nk, userID, "chest1", nil)
+	require.NoError(t, err)
+	instanceID := unlockables.Unlockables[0].InstanceId
+	_, err = unlockablesSystem.UnlockStart(ctx, logger, nk, userID, instanceID)
+	require.NoError(t, err)
+
+	speedUp := &pamlogix.PublisherEvent{
+		Name: "placement_success",
+		Metadata: map[string]string{
+			"placement_id": "unlockable_speed_up",
+			"instance_id":  instanceID,
+		},
+	}
+	// The default placement isn't used once placements are configured
+	defaultPlacement := &pamlogix.PublisherEvent{
+		Name: "placement_success",
+		Metadata: map[string]string{
+			"placement_id": "unlockable_rewarded_video",
+			"instance_id":  instanceID,
+		},
+	}
+
+	publisher.Send(ctx, logger, nk, userID, []*pamlogix.PublisherEvent{speedUp, defaultPlacement})
+	unlockables, err = unlockablesSystem.Get(ctx, logger, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), unlockables.Unlockables[0].AdvanceTimeSec)
+	assert.False(t, unlockables.Unlockables[0].CanClaim)
+
+	// The second speed-up is cut down to what remains of the cap, and the third does nothing
+	publisher.Send(ctx, logger, nk, userID, []*pamlogix.PublisherEvent{speedUp, speedUp})
+	unlockables, err = unlockablesSystem.Get(ctx, logger, nk, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(400), unlockables.Unlockables[0].AdvanceTimeSec)
+	assert.Equal(t, int64(400), unlockables.Unlockables[0].RewardedVideoAdvanceTimeSec)
+	assert.False(t, unlockables.Unlockables[0].CanClaim)
+}
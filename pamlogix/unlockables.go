@@ -14,15 +14,24 @@ type UnlockablesConfig struct {
 	SlotCost         *UnlockablesConfigSlotCost              `json:"slot_cost,omitempty"`
 	Unlockables      map[string]*UnlockablesConfigUnlockable `json:"unlockables,omitempty"`
 	MaxQueuedUnlocks int                                     `json:"max_queued_unlocks,omitempty"`
+	// RewardedVideo configures how rewarded video placements speed up unlockables.
+	RewardedVideo *UnlockablesConfigRewardedVideo `json:"rewarded_video,omitempty"`
 
 	UnlockableProbabilities []string `json:"-"`
 }
 
-type UnlockablesConfigSlotCost struct {
-	Items      map[string]int64 `json:"items,omitempty"`
-	Currencies map[string]int64 `json:"currencies,omitempty"`
+// UnlockablesConfigRewardedVideo configures the UnlockableRewardedVideoPublisher. Without placements, a successful
+// "unlockable_rewarded_video" placement purchases the unlock of the instance it names.
+type UnlockablesConfigRewardedVideo struct {
+	// Disabled stops the publisher from being registered, so placements don't affect unlockables.
+	Disabled bool `json:"disabled,omitempty"`
+	// Placements maps placement IDs to the seconds they take off the wait of the unlockable they name. Zero takes off
+	// all of the remaining wait. Reductions count towards the unlockable's MaxRewardedVideoSec.
+	Placements map[string]int64 `json:"placements,omitempty"`
 }
 
+type UnlockablesConfigSlotCost = Cost
+
 type UnlockablesConfigUnlockable struct {
 	Probability          int                                   `json:"probability,omitempty"`
 	Category             string                                `json:"category,omitempty"`
@@ -34,17 +43,20 @@ type UnlockablesConfigUnlockable struct {
 	Reward               *EconomyConfigReward                  `json:"reward,omitempty"`
 	WaitTimeSec          int                                   `json:"wait_time_sec,omitempty"`
 	AdditionalProperties map[string]string                     `json:"additional_properties,omitempty"`
+	// MaxRewardedVideoSec caps the total seconds rewarded video placements can take off an instance's wait. Zero
+	// doesn't cap them.
+	MaxRewardedVideoSec int64 `json:"max_rewarded_video_sec,omitempty"`
 }
 
-type UnlockablesConfigUnlockableCost struct {
-	Items      map[string]int64 `json:"items,omitempty"`
-	Currencies map[string]int64 `json:"currencies,omitempty"`
-}
+type UnlockablesConfigUnlockableCost = Cost
 
-type UnlockablesConfigUnlockableStartCost struct {
-	Items      map[string]int64 `json:"items,omitempty"`
-	Currencies map[string]int64 `json:"currencies,omitempty"`
-}
+type UnlockablesConfigUnlockableStartCost = Cost
+
+// Grant sources whose item grants can be routed into the unlockables system with InventoryConfigItem.Unlockable.
+const (
+	UnlockableGrantSourceAuction  = "auction"
+	UnlockableGrantSourceDonation = "donation"
+)
 
 // The UnlockablesSystem is a gameplay system which provides slots to store rewards which can be unlocked over time.
 type UnlockablesSystem interface {
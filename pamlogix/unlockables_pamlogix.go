@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"math/rand"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -359,149 +361,6 @@ func (u *UnlockablesPamlogix) updateUnlockProgress(unlockables *UnlockablesList)
 	return updated
 }
 
-// checkUserHasResources checks if a user has sufficient resources to pay a cost
-func (u *UnlockablesPamlogix) checkUserHasResources(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string,
-	items map[string]int64, currencies map[string]int64) (bool, error) {
-
-	// Skip check if no costs
-	if (items == nil || len(items) == 0) && (currencies == nil || len(currencies) == 0) {
-		return true, nil
-	}
-
-	// Check if we have access to the necessary systems
-	if u.pamlogix == nil {
-		logger.Warn("Cannot check resources: no Pamlogix instance available")
-		return false, ErrSystemNotAvailable
-	}
-
-	// Check item costs if any
-	if items != nil && len(items) > 0 {
-		inventorySystem := u.pamlogix.GetInventorySystem()
-		if inventorySystem == nil {
-			logger.Warn("Cannot check item resources: no InventorySystem available")
-			return false, ErrSystemNotAvailable
-		}
-
-		// Get user's inventory
-		inventory, err := inventorySystem.ListInventoryItems(ctx, logger, nk, userID, "")
-		if err != nil {
-			logger.Error("Failed to get user inventory: %v", err)
-			return false, err
-		}
-
-		// Check if user has enough of each required item
-		for itemID, amount := range items {
-			found := false
-			for _, item := range inventory.Items {
-				if item.Id == itemID && item.Count >= amount {
-					found = true
-					break
-				}
-			}
-
-			if !found {
-				logger.Debug("User %s does not have enough of item %s", userID, itemID)
-				return false, nil
-			}
-		}
-	}
-
-	// Check currency costs if any
-	if currencies != nil && len(currencies) > 0 {
-		economySystem := u.pamlogix.GetEconomySystem()
-		if economySystem == nil {
-			logger.Warn("Cannot check currency resources: no EconomySystem available")
-			return false, ErrSystemNotAvailable
-		}
-
-		// Get user's account to check wallet
-		account, err := nk.AccountGetId(ctx, userID)
-		if err != nil {
-			logger.Error("Failed to get user account: %v", err)
-			return false, err
-		}
-
-		// Get wallet from account
-		wallet, err := economySystem.UnmarshalWallet(account)
-		if err != nil {
-			logger.Error("Failed to unmarshal wallet: %v", err)
-			return false, err
-		}
-
-		// Check if user has enough of each required currency
-		for currencyID, amount := range currencies {
-			if wallet[currencyID] < amount {
-				logger.Debug("User %s does not have enough of currency %s", userID, currencyID)
-				return false, nil
-			}
-		}
-	}
-
-	return true, nil
-}
-
-// deductResources deducts the specified resources from a user
-func (u *UnlockablesPamlogix) deductResources(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string,
-	items map[string]int64, currencies map[string]int64) error {
-
-	// Skip if no costs
-	if (items == nil || len(items) == 0) && (currencies == nil || len(currencies) == 0) {
-		return nil
-	}
-
-	// Check if we have access to the necessary systems
-	if u.pamlogix == nil {
-		logger.Warn("Cannot deduct resources: no Pamlogix instance available")
-		return ErrSystemNotAvailable
-	}
-
-	// Deduct items if any
-	if items != nil && len(items) > 0 {
-		inventorySystem := u.pamlogix.GetInventorySystem()
-		if inventorySystem == nil {
-			logger.Warn("Cannot deduct item resources: no InventorySystem available")
-			return ErrSystemNotAvailable
-		}
-
-		// Convert to negative values for subtraction
-		deductItems := make(map[string]int64)
-		for id, amount := range items {
-			deductItems[id] = -amount
-		}
-
-		// Deduct items
-		_, _, _, _, err := inventorySystem.GrantItems(ctx, logger, nk, userID, deductItems, false)
-		if err != nil {
-			logger.Error("Failed to deduct items: %v", err)
-			return err
-		}
-	}
-
-	// Deduct currencies if any
-	if currencies != nil && len(currencies) > 0 {
-		economySystem := u.pamlogix.GetEconomySystem()
-		if economySystem == nil {
-			logger.Warn("Cannot deduct currency resources: no EconomySystem available")
-			return ErrSystemNotAvailable
-		}
-
-		// Convert to negative values for subtraction
-		deductCurrencies := make(map[string]int64)
-		for id, amount := range currencies {
-			deductCurrencies[id] = -amount
-		}
-
-		// Deduct currencies
-		_, _, _, err := economySystem.Grant(ctx, logger, nk, userID, deductCurrencies, nil, nil, nil)
-		if err != nil {
-			logger.Error("Failed to deduct currencies: %v", err)
-			return err
-		}
-	}
-
-	return nil
-}
-
 // Create will place a new unlockable into a slot either randomly, by ID, or optionally using a custom configuration.
 func (u *UnlockablesPamlogix) Create(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, unlockableID string, unlockableConfig *UnlockablesConfigUnlockable) (unlockables *UnlockablesList, err error) {
 	// Validate input parameters
@@ -646,10 +505,22 @@ func (u *UnlockablesPamlogix) UnlockAdvance(ctx context.Context, logger runtime.
 	}
 
 	// Add the time advance to the unlockable
+	u.advanceUnlock(unlockables, unlockable, seconds, time.Now().Unix())
+
+	// Save the updated unlockables
+	if err := u.saveUserUnlockables(ctx, logger, nk, userID, unlockables); err != nil {
+		logger.Error("Failed to save user unlockables: %v", err)
+		return nil, err
+	}
+
+	return unlockables, nil
+}
+
+// advanceUnlock adds time towards a started unlock, completing it and starting queued unlocks if that's enough.
+func (u *UnlockablesPamlogix) advanceUnlock(unlockables *UnlockablesList, unlockable *Unlockable, seconds, now int64) {
 	unlockable.AdvanceTimeSec += seconds
 
 	// Check if the advance completes the unlock
-	now := time.Now().Unix()
 	currentProgress := now - unlockable.UnlockStartTimeSec + unlockable.AdvanceTimeSec
 
 	if currentProgress >= int64(unlockable.WaitTimeSec) {
@@ -665,8 +536,59 @@ func (u *UnlockablesPamlogix) UnlockAdvance(ctx context.Context, logger runtime.
 	if unlockable.CanClaim {
 		u.processQueue(unlockables)
 	}
+}
+
+// rewardedVideoAdvance takes seconds off the wait of a started unlock after a rewarded video, or all of the remaining
+// wait when seconds is zero, up to what remains of the unlockable's MaxRewardedVideoSec.
+func (u *UnlockablesPamlogix) rewardedVideoAdvance(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID, instanceID string, seconds int64) (*UnlockablesList, error) {
+	if err := u.validateUserID(userID); err != nil {
+		return nil, err
+	}
+	if err := u.validateInstanceID(instanceID); err != nil {
+		return nil, err
+	}
+
+	unlockables, err := u.getUserUnlockables(ctx, logger, nk, userID)
+	if err != nil {
+		logger.Error("Failed to get user's unlockables: %v", err)
+		return nil, err
+	}
+
+	idx, unlockable := u.findUnlockableByID(unlockables.Unlockables, instanceID)
+	if idx == -1 || unlockable == nil {
+		logger.Error("Could not find unlockable with instance ID %s for user %s", instanceID, userID)
+		return unlockables, ErrBadInput
+	}
+	if unlockable.UnlockStartTimeSec == 0 {
+		logger.Error("Unlockable %s has not been started for user %s", instanceID, userID)
+		return unlockables, ErrBadInput
+	}
+	if unlockable.CanClaim {
+		return unlockables, nil
+	}
+
+	now := time.Now().Unix()
+	remaining := unlockable.UnlockStartTimeSec + int64(unlockable.WaitTimeSec) - unlockable.AdvanceTimeSec - now
+	if seconds <= 0 || seconds > remaining {
+		seconds = remaining
+	}
+	if unlockableConfig := u.getUnlockableConfig(unlockable.Id); unlockableConfig != nil && unlockableConfig.MaxRewardedVideoSec > 0 {
+		if allowed := unlockableConfig.MaxRewardedVideoSec - unlockable.RewardedVideoAdvanceTimeSec; seconds > allowed {
+			seconds = allowed
+		}
+	}
+	if seconds <= 0 && remaining > 0 {
+		logger.Debug("Unlockable %s of user %s can't be sped up further by rewarded videos", instanceID, userID)
+		return unlockables, ErrBadInput
+	}
+	if seconds < 0 {
+		// The wait is already over, so the advance only completes the unlock
+		seconds = 0
+	}
+
+	unlockable.RewardedVideoAdvanceTimeSec += seconds
+	u.advanceUnlock(unlockables, unlockable, seconds, now)
 
-	// Save the updated unlockables
 	if err := u.saveUserUnlockables(ctx, logger, nk, userID, unlockables); err != nil {
 		logger.Error("Failed to save user unlockables: %v", err)
 		return nil, err
@@ -716,24 +638,15 @@ func (u *UnlockablesPamlogix) UnlockStart(ctx context.Context, logger runtime.Lo
 		return unlockables, ErrBadInput
 	}
 
-	// Check if the user has sufficient resources to pay the start cost
+	// Charge the start cost
 	if unlockable.StartCost != nil {
-		// Check if user has enough resources
-		hasResources, err := u.checkUserHasResources(ctx, logger, nk, userID, unlockable.StartCost.Items, unlockable.StartCost.Currencies)
-		if err != nil {
-			logger.Error("Failed to check user resources: %v", err)
-			return nil, err
-		}
-
-		if !hasResources {
-			logger.Error("User %s does not have enough resources to start unlocking %s", userID, instanceID)
-			return unlockables, ErrEconomyNotEnoughCurrency
-		}
-
-		// Deduct the start cost
-		if err := u.deductResources(ctx, logger, nk, userID, unlockable.StartCost.Items, unlockable.StartCost.Currencies); err != nil {
-			logger.Error("Failed to deduct resources: %v", err)
-			return nil, err
+		startCost := &Cost{Items: unlockable.StartCost.Items, Currencies: unlockable.StartCost.Currencies}
+		if err := chargeCost(ctx, logger, nk, u.pamlogix, userID, startCost, map[string]interface{}{
+			"source":      "unlockable_start",
+			"instance_id": instanceID,
+		}, false); err != nil {
+			logger.Error("User %s could not pay the cost to start unlocking %s: %v", userID, instanceID, err)
+			return unlockables, err
 		}
 	}
 
@@ -827,25 +740,13 @@ func (u *UnlockablesPamlogix) PurchaseUnlock(ctx context.Context, logger runtime
 		}
 	}
 
-	// Check if the user has sufficient resources to pay the unlock cost
-	if costItems != nil || costCurrencies != nil {
-		// Check if user has enough resources
-		hasResources, err := u.checkUserHasResources(ctx, logger, nk, userID, costItems, costCurrencies)
-		if err != nil {
-			logger.Error("Failed to check user resources: %v", err)
-			return nil, err
-		}
-
-		if !hasResources {
-			logger.Error("User %s does not have enough resources to purchase unlock %s", userID, instanceID)
-			return unlockables, ErrEconomyNotEnoughCurrency
-		}
-
-		// Deduct the cost
-		if err := u.deductResources(ctx, logger, nk, userID, costItems, costCurrencies); err != nil {
-			logger.Error("Failed to deduct resources: %v", err)
-			return nil, err
-		}
+	// Charge the unlock cost
+	if err := chargeCost(ctx, logger, nk, u.pamlogix, userID, &Cost{Items: costItems, Currencies: costCurrencies}, map[string]interface{}{
+		"source":      "unlockable_purchase_unlock",
+		"instance_id": instanceID,
+	}, false); err != nil {
+		logger.Error("User %s could not pay the cost to purchase unlock %s: %v", userID, instanceID, err)
+		return unlockables, err
 	}
 
 	// Complete the unlock immediately
@@ -893,24 +794,14 @@ func (u *UnlockablesPamlogix) PurchaseSlot(ctx context.Context, logger runtime.L
 		return unlockables, ErrBadInput
 	}
 
-	// Check if the user has sufficient resources to pay the slot cost
+	// Charge the slot cost
 	if unlockables.SlotCost != nil {
-		// Check if user has enough resources
-		hasResources, err := u.checkUserHasResources(ctx, logger, nk, userID, unlockables.SlotCost.Items, unlockables.SlotCost.Currencies)
-		if err != nil {
-			logger.Error("Failed to check user resources: %v", err)
-			return nil, err
-		}
-
-		if !hasResources {
-			logger.Error("User %s does not have enough resources to purchase a new slot", userID)
-			return unlockables, ErrEconomyNotEnoughCurrency
-		}
-
-		// Deduct the cost
-		if err := u.deductResources(ctx, logger, nk, userID, unlockables.SlotCost.Items, unlockables.SlotCost.Currencies); err != nil {
-			logger.Error("Failed to deduct resources: %v", err)
-			return nil, err
+		slotCost := &Cost{Items: unlockables.SlotCost.Items, Currencies: unlockables.SlotCost.Currencies}
+		if err := chargeCost(ctx, logger, nk, u.pamlogix, userID, slotCost, map[string]interface{}{
+			"source": "unlockable_slot",
+		}, false); err != nil {
+			logger.Error("User %s could not pay the cost to purchase a new slot: %v", userID, err)
+			return unlockables, err
 		}
 	}
 
@@ -1248,3 +1139,117 @@ func (u *UnlockablesPamlogix) QueueSet(ctx context.Context, logger runtime.Logge
 func (u *UnlockablesPamlogix) SetOnClaimReward(fn OnReward[*UnlockablesConfigUnlockable]) {
 	u.onClaimReward = fn
 }
+
+// createQueued creates an unlockable for each of the given unlockable IDs, in order, and adds them to the unlock queue
+// so they start unlocking as active slots free up. It stops at the first unlockable that doesn't fit in a free slot and
+// returns how many were created.
+func (u *UnlockablesPamlogix) createQueued(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, userID string, unlockableIDs []string) (int, error) {
+	if len(unlockableIDs) == 0 {
+		return 0, nil
+	}
+	if u.config == nil {
+		return 0, ErrSystemNotAvailable
+	}
+
+	unlockables, err := u.getUserUnlockables(ctx, logger, nk, userID)
+	if err != nil {
+		return 0, err
+	}
+	u.updateUnlockProgress(unlockables)
+
+	created := 0
+	for _, unlockableID := range unlockableIDs {
+		if len(unlockables.Unlockables) >= int(unlockables.Slots) {
+			break
+		}
+		unlockable := u.createUnlockable(unlockableID, nil)
+		if unlockable == nil {
+			logger.Error("Unlockable %s not found in config", unlockableID)
+			break
+		}
+		unlockables.Unlockables = append(unlockables.Unlockables, unlockable)
+		if int32(len(unlockables.QueuedUnlocks)) < unlockables.MaxQueuedUnlocks {
+			unlockables.QueuedUnlocks = append(unlockables.QueuedUnlocks, unlockable.InstanceId)
+		}
+		created++
+	}
+	if created == 0 {
+		return 0, nil
+	}
+
+	// Start as many of the queued unlockables as there are free active slots
+	for u.processQueue(unlockables) {
+	}
+
+	if err := u.saveUserUnlockables(ctx, logger, nk, userID, unlockables); err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+// routeItemsToUnlockables creates unlockables for the granted items whose inventory config routes grants from the
+// source into the unlockables system, and returns the items that are still to be granted to the inventory. Items that
+// don't fit in the user's unlockable slots stay in the inventory grant.
+func routeItemsToUnlockables(ctx context.Context, logger runtime.Logger, nk runtime.NakamaModule, pl Pamlogix, userID, source string, items map[string]int64) (map[string]int64, error) {
+	if pl == nil || len(items) == 0 {
+		return items, nil
+	}
+	unlockablesSystem, ok := pl.GetUnlockablesSystem().(*UnlockablesPamlogix)
+	if !ok || unlockablesSystem == nil {
+		return items, nil
+	}
+	inventorySystem := pl.GetInventorySystem()
+	if inventorySystem == nil {
+		return items, nil
+	}
+	inventoryConfig, ok := inventorySystem.GetConfig().(*InventoryConfig)
+	if !ok || inventoryConfig == nil {
+		return items, nil
+	}
+
+	itemIDs := make([]string, 0, len(items))
+	for itemID := range items {
+		itemIDs = append(itemIDs, itemID)
+	}
+	sort.Strings(itemIDs)
+
+	// One unlockable per item granted, in a stable order so partial grants are predictable
+	routedItemIDs := make([]string, 0)
+	unlockableIDs := make([]string, 0)
+	for _, itemID := range itemIDs {
+		itemConfig := inventoryConfig.Items[itemID]
+		if itemConfig == nil || itemConfig.Unlockable == nil || itemConfig.Unlockable.Id == "" {
+			continue
+		}
+		if len(itemConfig.Unlockable.Sources) > 0 && !slices.Contains(itemConfig.Unlockable.Sources, source) {
+			continue
+		}
+		for i := int64(0); i < items[itemID]; i++ {
+			routedItemIDs = append(routedItemIDs, itemID)
+			unlockableIDs = append(unlockableIDs, itemConfig.Unlockable.Id)
+		}
+	}
+	if len(unlockableIDs) == 0 {
+		return items, nil
+	}
+
+	created, err := unlockablesSystem.createQueued(ctx, logger, nk, userID, unlockableIDs)
+	if err != nil {
+		return nil, err
+	}
+	if created < len(unlockableIDs) {
+		logger.Warn("Only %d of %d %s items fit in the unlockable slots of user %s, granting the rest to the inventory", created, len(unlockableIDs), source, userID)
+	}
+
+	remaining := make(map[string]int64, len(items))
+	for itemID, count := range items {
+		remaining[itemID] = count
+	}
+	for _, itemID := range routedItemIDs[:created] {
+		remaining[itemID]--
+		if remaining[itemID] <= 0 {
+			delete(remaining, itemID)
+		}
+	}
+	return remaining, nil
+}
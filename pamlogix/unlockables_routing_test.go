@@ -0,0 +1,107 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUnlockableRoutingPamlogix wires inventory, unlockables and auctions systems where won or donated crates become
+// unlockables.
+func newUnlockableRoutingPamlogix() *pamlogixImpl {
+	p := &pamlogixImpl{
+		systems: make(map[SystemType]System),
+	}
+
+	inventorySystem := NewNakamaInventorySystem(&InventoryConfig{
+		Items: map[string]*InventoryConfigItem{
+			"crate":         {Name: "Crate", Stackable: true, Unlockable: &InventoryConfigItemUnlockable{Id: "wooden_crate"}},
+			"auction_crate": {Name: "Auction Crate", Unlockable: &InventoryConfigItemUnlockable{Id: "wooden_crate", Sources: []string{UnlockableGrantSourceAuction}}},
+			"key":           {Name: "Key", Stackable: true},
+		},
+	})
+	unlockablesSystem := NewUnlockablesSystem(&UnlockablesConfig{
+		Slots:            2,
+		ActiveSlots:      1,
+		MaxQueuedUnlocks: 5,
+		Unlockables: map[string]*UnlockablesConfigUnlockable{
+			"wooden_crate": {Name: "Wooden Crate", WaitTimeSec: 600},
+		},
+	})
+	auctionsSystem := NewNakamaAuctionsSystem(&AuctionsConfig{})
+
+	inventorySystem.SetPamlogix(p)
+	unlockablesSystem.(*UnlockablesPamlogix).SetPamlogix(p)
+	auctionsSystem.(*AuctionsPamlogix).SetPamlogix(p)
+	p.systems[SystemTypeInventory] = inventorySystem
+	p.systems[SystemTypeUnlockables] = unlockablesSystem
+	p.systems[SystemTypeAuctions] = auctionsSystem
+
+	return p
+}
+
+func TestRouteItemsToUnlockables(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	p := newUnlockableRoutingPamlogix()
+	nk := newBenchNakama()
+
+	// Only two crates fit in the two slots, the third stays an inventory item
+	remaining, err := routeItemsToUnlockables(ctx, logger, nk, p, "user1", UnlockableGrantSourceDonation, map[string]int64{"crate": 3, "key": 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"crate": 1, "key": 1}, remaining)
+
+	unlockables, err := p.GetUnlockablesSystem().Get(ctx, logger, nk, "user1")
+	require.NoError(t, err)
+	require.Len(t, unlockables.Unlockables, 2)
+	assert.Equal(t, "wooden_crate", unlockables.Unlockables[0].Id)
+	assert.NotZero(t, unlockables.Unlockables[0].UnlockStartTimeSec)
+	assert.Equal(t, []string{unlockables.Unlockables[1].InstanceId}, unlockables.QueuedUnlocks)
+
+	// Items limited to auction grants aren't routed for other sources
+	remaining, err = routeItemsToUnlockables(ctx, logger, nk, p, "user2", UnlockableGrantSourceDonation, map[string]int64{"auction_crate": 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"auction_crate": 1}, remaining)
+}
+
+func TestAuctionClaimBid_RoutesItemsToUnlockables(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	p := newUnlockableRoutingPamlogix()
+	nk := newBenchNakama()
+
+	now := time.Now().Unix()
+	auction := &Auction{
+		Id:     "auction1",
+		UserId: "seller",
+		Reward: &AuctionReward{
+			Items: []*InventoryItem{{Id: "auction_crate", Count: 1}, {Id: "key", Count: 2}},
+		},
+		Bid:          &AuctionBid{UserId: "winner", Bid: &AuctionBidAmount{Currencies: map[string]int64{"coins": 10}}},
+		StartTimeSec: now - 20,
+		EndTimeSec:   now - 10,
+	}
+	data, err := json.Marshal(auction)
+	require.NoError(t, err)
+	_, err = nk.StorageWrite(ctx, []*runtime.StorageWrite{{Collection: AuctionCollectionKey, Key: auction.Id, Value: string(data)}})
+	require.NoError(t, err)
+
+	claim, err := p.GetAuctionsSystem().ClaimBid(ctx, logger, nk, "winner", "auction1")
+	require.NoError(t, err)
+	require.Len(t, claim.Reward.Items, 1)
+	assert.Equal(t, "key", claim.Reward.Items[0].Id)
+	assert.Equal(t, int64(2), claim.Reward.Items[0].Count)
+
+	// The stored auction keeps the full reward
+	assert.Len(t, claim.Auction.Reward.Items, 2)
+
+	unlockables, err := p.GetUnlockablesSystem().Get(ctx, logger, nk, "winner")
+	require.NoError(t, err)
+	require.Len(t, unlockables.Unlockables, 1)
+	assert.Equal(t, "wooden_crate", unlockables.Unlockables[0].Id)
+}
@@ -0,0 +1,104 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+// Version is the pamlogix build version. Release builds set it with
+// -ldflags "-X voidexforge/pamlogix.Version=<version>".
+var Version = "dev"
+
+const (
+	// ProtocolRevision is bumped whenever an RPC's payload or behaviour changes in a way older clients can't handle.
+	ProtocolRevision = 1
+	// MinProtocolRevision is the oldest client protocol revision the server still serves.
+	MinProtocolRevision = 1
+)
+
+// VersionRequest is the request payload of the version handshake. ProtocolRevision is the client SDK's revision, and
+// zero skips the compatibility check.
+type VersionRequest struct {
+	ProtocolRevision int `json:"protocol_revision,omitempty"`
+}
+
+// VersionInfo is what the server runs, so client SDKs can detect incompatibilities at startup and hide systems or
+// RPCs the server doesn't have.
+type VersionInfo struct {
+	Version             string `json:"version"`
+	ProtocolRevision    int    `json:"protocol_revision"`
+	MinProtocolRevision int    `json:"min_protocol_revision"`
+	// Compatible reports whether the server serves the client's protocol revision. It's true when the request names
+	// none.
+	Compatible bool `json:"compatible"`
+	// Namespace prefixes the RPC IDs, as "<namespace>_<rpc id>", when the server runs more than one instance.
+	Namespace string `json:"namespace,omitempty"`
+	// Systems are the names of the loaded systems, e.g. "economy".
+	Systems []string `json:"systems"`
+	// Rpcs are the IDs of the registered RPCs, before any namespace prefix.
+	Rpcs []string `json:"rpcs"`
+	// FeatureFlags are the client features the base system reports, when it's loaded.
+	FeatureFlags map[string]bool `json:"feature_flags,omitempty"`
+}
+
+// versionInfo describes the server for a client with the given protocol revision.
+func (p *pamlogixImpl) versionInfo(clientRevision int) *VersionInfo {
+	info := &VersionInfo{
+		Version:             Version,
+		ProtocolRevision:    ProtocolRevision,
+		MinProtocolRevision: MinProtocolRevision,
+		Compatible:          clientRevision == 0 || clientRevision >= MinProtocolRevision,
+		Namespace:           p.namespace,
+		Systems:             make([]string, 0, len(p.systems)),
+		Rpcs:                append([]string{}, p.rpcIds...),
+	}
+	for systemType := range p.systems {
+		if name, found := systemNames[systemType]; found {
+			info.Systems = append(info.Systems, name)
+		}
+	}
+	sort.Strings(info.Systems)
+	sort.Strings(info.Rpcs)
+	if baseSystem, ok := p.systems[SystemTypeBase].(*BasePamlogix); ok {
+		info.FeatureFlags = baseSystem.FeatureFlags()
+	}
+	return info
+}
+
+// rpcIdsInitializer keeps the ID of every RPC registered through it, for the version handshake.
+type rpcIdsInitializer struct {
+	runtime.Initializer
+	pamlogix *pamlogixImpl
+}
+
+func (i *rpcIdsInitializer) RegisterRpc(id string, fn func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error)) error {
+	if err := i.Initializer.RegisterRpc(id, fn); err != nil {
+		return err
+	}
+	i.pamlogix.rpcIds = append(i.pamlogix.rpcIds, id)
+	return nil
+}
+
+// rpcVersion handles the version handshake RPC. It doesn't need a user session.
+func rpcVersion(p *pamlogixImpl) func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+	return func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		request := &VersionRequest{}
+		if payload != "" {
+			if err := unmarshalRpcJson(p, payload, request); err != nil {
+				logger.Error("Failed to unmarshal VersionRequest: %v", err)
+				return "", ErrPayloadDecode
+			}
+		}
+
+		responseData, err := marshalRpcJson(p, p.versionInfo(request.ProtocolRevision))
+		if err != nil {
+			logger.Error("Failed to marshal version response: %v", err)
+			return "", ErrPayloadEncode
+		}
+
+		return string(responseData), nil
+	}
+}
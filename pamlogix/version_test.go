@@ -0,0 +1,42 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRpcVersion(t *testing.T) {
+	p := newBenchPamlogix()
+	p.namespace = "arena"
+	recorder := &rpcRecordingInitializer{rpcs: make(map[string]func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error))}
+	var initializer runtime.Initializer = &collectionResolverInitializer{Initializer: recorder, pamlogix: p}
+	initializer = &rpcIdsInitializer{Initializer: initializer, pamlogix: p}
+
+	require.NoError(t, initializer.RegisterRpc(RpcIdEconomySummary, rpcEconomySummary_Json(p)))
+	require.NoError(t, initializer.RegisterRpc(RpcIdVersion, rpcVersion(p)))
+
+	rpc, found := recorder.rpcs[NamespacedRpcId("arena", RpcIdVersion)]
+	require.True(t, found)
+
+	payload, err := rpc(context.Background(), &mockLogger{}, nil, newBenchNakama(), "")
+	require.NoError(t, err)
+	var info VersionInfo
+	require.NoError(t, json.Unmarshal([]byte(payload), &info))
+	assert.Equal(t, Version, info.Version)
+	assert.Equal(t, ProtocolRevision, info.ProtocolRevision)
+	assert.True(t, info.Compatible)
+	assert.Equal(t, "arena", info.Namespace)
+	assert.Equal(t, []string{"auctions", "economy", "inventory"}, info.Systems)
+	assert.Equal(t, []string{RpcIdEconomySummary, RpcIdVersion}, info.Rpcs)
+	assert.Nil(t, info.FeatureFlags)
+
+	// A newer client is served too, and falls back on what the server reports having
+	assert.True(t, p.versionInfo(ProtocolRevision+1).Compatible)
+	assert.True(t, p.versionInfo(MinProtocolRevision).Compatible)
+}
@@ -0,0 +1,176 @@
+package pamlogix
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+)
+
+type walletCacheCtxKey struct{}
+
+// walletCache holds the wallets read or updated while handling one RPC, so a grant followed by a wallet read doesn't
+// fetch the whole account again.
+type walletCache struct {
+	mu      sync.Mutex
+	wallets map[string]map[string]int64
+}
+
+func newWalletCache() *walletCache {
+	return &walletCache{
+		wallets: make(map[string]map[string]int64),
+	}
+}
+
+func withWalletCache(ctx context.Context, cache *walletCache) context.Context {
+	return context.WithValue(ctx, walletCacheCtxKey{}, cache)
+}
+
+func walletCacheFromContext(ctx context.Context) *walletCache {
+	cache, _ := ctx.Value(walletCacheCtxKey{}).(*walletCache)
+	return cache
+}
+
+func (c *walletCache) get(userID string) (map[string]int64, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wallet, found := c.wallets[userID]
+	if !found {
+		return nil, false
+	}
+	return copyWallet(wallet), true
+}
+
+func (c *walletCache) set(userID string, wallet map[string]int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.wallets[userID] = copyWallet(wallet)
+}
+
+func (c *walletCache) invalidate(userID string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.wallets, userID)
+}
+
+func copyWallet(wallet map[string]int64) map[string]int64 {
+	copied := make(map[string]int64, len(wallet))
+	for currencyID, amount := range wallet {
+		copied[currencyID] = amount
+	}
+	return copied
+}
+
+// userWallet returns the user's wallet, from the request's wallet cache when it holds one and otherwise by reading
+// the account.
+func userWallet(ctx context.Context, nk runtime.NakamaModule, userID string) (map[string]int64, error) {
+	cache := walletCacheFromContext(ctx)
+	if wallet, found := cache.get(userID); found {
+		return wallet, nil
+	}
+
+	var account *api.Account
+	err := retryNakama(ctx, idempotentCall, func() (err error) {
+		account, err = nk.AccountGetId(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := unmarshalAccountWallet(account)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.set(userID, wallet)
+	return wallet, nil
+}
+
+func unmarshalAccountWallet(account *api.Account) (map[string]int64, error) {
+	if account == nil || account.Wallet == "" {
+		return map[string]int64{}, nil
+	}
+
+	var wallet map[string]int64
+	if err := json.Unmarshal([]byte(account.Wallet), &wallet); err != nil {
+		return nil, err
+	}
+	if wallet == nil {
+		wallet = map[string]int64{}
+	}
+	return wallet, nil
+}
+
+// walletCacheNakama keeps the request's wallet cache current: wallets returned by wallet updates replace the cached
+// ones, and accounts read for any reason seed it.
+type walletCacheNakama struct {
+	runtime.NakamaModule
+}
+
+func (n *walletCacheNakama) AccountGetId(ctx context.Context, userID string) (*api.Account, error) {
+	account, err := n.NakamaModule.AccountGetId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet, err := unmarshalAccountWallet(account); err == nil {
+		walletCacheFromContext(ctx).set(userID, wallet)
+	}
+	return account, nil
+}
+
+func (n *walletCacheNakama) WalletUpdate(ctx context.Context, userID string, changeset map[string]int64, metadata map[string]interface{}, updateLedger bool) (map[string]int64, map[string]int64, error) {
+	updated, previous, err := n.NakamaModule.WalletUpdate(ctx, userID, changeset, metadata, updateLedger)
+	if err != nil {
+		walletCacheFromContext(ctx).invalidate(userID)
+		return nil, nil, err
+	}
+	walletCacheFromContext(ctx).set(userID, updated)
+	return updated, previous, nil
+}
+
+func (n *walletCacheNakama) WalletsUpdate(ctx context.Context, updates []*runtime.WalletUpdate, updateLedger bool) ([]*runtime.WalletUpdateResult, error) {
+	results, err := n.NakamaModule.WalletsUpdate(ctx, updates, updateLedger)
+	cache := walletCacheFromContext(ctx)
+	for _, update := range updates {
+		cache.invalidate(update.UserID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		cache.set(result.UserID, result.Updated)
+	}
+	return results, nil
+}
+
+func (n *walletCacheNakama) MultiUpdate(ctx context.Context, accountUpdates []*runtime.AccountUpdate, storageWrites []*runtime.StorageWrite, storageDeletes []*runtime.StorageDelete, walletUpdates []*runtime.WalletUpdate, updateLedger bool) ([]*api.StorageObjectAck, []*runtime.WalletUpdateResult, error) {
+	acks, results, err := n.NakamaModule.MultiUpdate(ctx, accountUpdates, storageWrites, storageDeletes, walletUpdates, updateLedger)
+	cache := walletCacheFromContext(ctx)
+	for _, update := range walletUpdates {
+		cache.invalidate(update.UserID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, result := range results {
+		cache.set(result.UserID, result.Updated)
+	}
+	return acks, results, nil
+}
@@ -0,0 +1,94 @@
+package pamlogix
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/heroiclabs/nakama-common/api"
+	"github.com/heroiclabs/nakama-common/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletCache_GrantUsesUpdatedWallet(t *testing.T) {
+	economy := NewNakamaEconomySystem(&EconomyConfig{})
+	logger := &mockLogger{}
+	nk := NewMockNakama(t)
+	userID := "user1"
+
+	nk.On("WalletUpdate", mock.Anything, userID, map[string]int64{"gold": 50}, mock.Anything, false).Return(map[string]int64{"gold": 150}, map[string]int64{"gold": 100}, nil).Once()
+	nk.On("StorageRead", mock.Anything, mock.Anything).Return([]*api.StorageObject{}, nil)
+
+	rpc := withRequestCacheRpc(func(ctx context.Context, logger runtime.Logger, db *sql.DB, nk runtime.NakamaModule, payload string) (string, error) {
+		wallet, _, _, err := economy.Grant(ctx, logger, nk, userID, map[string]int64{"gold": 50}, nil, nil, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"gold": 150}, wallet)
+		return "", nil
+	})
+	_, err := rpc(context.Background(), logger, nil, nk, "")
+	require.NoError(t, err)
+
+	nk.AssertNotCalled(t, "AccountGetId", mock.Anything, mock.Anything)
+	nk.AssertExpectations(t)
+}
+
+func TestWalletCache_ReadsAccountOnce(t *testing.T) {
+	nk := NewMockNakama(t)
+	userID := "user1"
+	nk.On("AccountGetId", mock.Anything, userID).Return(&api.Account{Wallet: `{"gems":5}`}, nil).Once()
+
+	cached := &walletCacheNakama{NakamaModule: nk}
+	ctx := withWalletCache(context.Background(), newWalletCache())
+
+	for i := 0; i < 3; i++ {
+		wallet, err := userWallet(ctx, cached, userID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"gems": 5}, wallet)
+	}
+
+	// Callers can't change the cached wallet through the returned map
+	wallet, _ := userWallet(ctx, cached, userID)
+	wallet["gems"] = 100
+	wallet, _ = userWallet(ctx, cached, userID)
+	assert.Equal(t, int64(5), wallet["gems"])
+
+	nk.AssertExpectations(t)
+}
+
+func TestWalletCache_InvalidatedByFailedUpdate(t *testing.T) {
+	nk := NewMockNakama(t)
+	userID := "user1"
+	nk.On("AccountGetId", mock.Anything, userID).Return(&api.Account{Wallet: `{"gems":5}`}, nil).Twice()
+	nk.On("WalletUpdate", mock.Anything, userID, mock.Anything, mock.Anything, false).Return(map[string]int64(nil), map[string]int64(nil), runtime.NewError("wallet update failed", INTERNAL_ERROR_CODE)).Once()
+
+	cached := &walletCacheNakama{NakamaModule: nk}
+	ctx := withWalletCache(context.Background(), newWalletCache())
+
+	_, err := userWallet(ctx, cached, userID)
+	require.NoError(t, err)
+
+	_, _, err = cached.WalletUpdate(ctx, userID, map[string]int64{"gems": -10}, nil, false)
+	require.Error(t, err)
+
+	// The wallet is read again because the failed update may have left it in an unknown state
+	_, err = userWallet(ctx, cached, userID)
+	require.NoError(t, err)
+
+	nk.AssertExpectations(t)
+}
+
+func TestWalletCache_WithoutCacheReadsAccount(t *testing.T) {
+	nk := NewMockNakama(t)
+	userID := "user1"
+	nk.On("AccountGetId", mock.Anything, userID).Return(&api.Account{}, nil).Twice()
+
+	for i := 0; i < 2; i++ {
+		wallet, err := userWallet(context.Background(), nk, userID)
+		require.NoError(t, err)
+		assert.Empty(t, wallet)
+	}
+
+	nk.AssertExpectations(t)
+}